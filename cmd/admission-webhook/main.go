@@ -19,10 +19,16 @@ import (
 	"time"
 
 	"github.com/openshift/generic-admission-server/pkg/cmd"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/operations"
 	"github.com/pingcap/tidb-operator/pkg/version"
+	"github.com/pingcap/tidb-operator/pkg/webhook/conversion"
 	"github.com/pingcap/tidb-operator/pkg/webhook/statefulset"
 	"github.com/pingcap/tidb-operator/pkg/webhook/strategy"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
 	"k8s.io/component-base/logs"
 	"k8s.io/klog/v2"
 )
@@ -31,6 +37,9 @@ var (
 	printVersion         bool
 	extraServiceAccounts string
 	minResyncDuration    time.Duration
+	operationsOptions    operations.Options
+	conversionOptions    conversion.Options
+	conversionPort       int
 )
 
 func init() {
@@ -38,6 +47,14 @@ func init() {
 	// Define the flag "secure-port" to avoid the `flag.Parse()` reporting error
 	// TODO: remove this flag after we don't use the lib "github.com/openshift/generic-admission-server"
 	flag.Int("secure-port", 6443, "The port on which to serve HTTPS with authentication and authorization. If 0, don't serve HTTPS at all.")
+	// Define the flags "tls-cert-file" and "tls-private-key-file" to share the serving certificate with the operations API server
+	flag.StringVar(&operationsOptions.CertFile, "tls-cert-file", "", "File containing the default x509 Certificate for HTTPS.")
+	flag.StringVar(&operationsOptions.KeyFile, "tls-private-key-file", "", "File containing the default x509 private key matching --tls-cert-file.")
+	flag.IntVar(&operationsOptions.SecurePort, "operations-secure-port", 0, "The port on which to serve the operations API of TidbCluster. If 0, don't serve the operations API.")
+	flag.IntVar(&conversionOptions.SecurePort, "conversion-secure-port", 0, "The port on which to serve the conversion webhook of TidbCluster, v1beta1 of TidbCluster is served if it's set. If 0, don't serve the conversion webhook.")
+	flag.StringVar(&conversionOptions.CAFile, "conversion-ca-file", "", "File containing the CA of --tls-cert-file, which is set to the CRD for kube-apiserver to call the conversion webhook.")
+	flag.StringVar(&conversionOptions.ServiceName, "conversion-service", "tidb-admission-webhook", "The name of the Service of the conversion webhook.")
+	flag.IntVar(&conversionPort, "conversion-service-port", 9443, "The port of the Service of the conversion webhook.")
 	flag.BoolVar(&printVersion, "V", false, "Show version and quit")
 	flag.BoolVar(&printVersion, "version", false, "Show version and quit")
 	flag.StringVar(&extraServiceAccounts, "extraServiceAccounts", "", "comma-separated, extra Service Accounts the Webhook should control. The full pattern for each common service account is system:serviceaccount:<namespace>:<serviceaccount-name>")
@@ -72,5 +89,42 @@ func main() {
 	statefulSetAdmissionHook := statefulset.NewStatefulSetAdmissionControl()
 	strategyAdmissionHook := strategy.NewStrategyAdmissionHook(&strategy.Registry)
 
+	if operationsOptions.SecurePort > 0 {
+		cfg, err := rest.InClusterConfig()
+		if err != nil {
+			klog.Fatalf("failed to get config: %v", err)
+		}
+		cli, err := versioned.NewForConfig(cfg)
+		if err != nil {
+			klog.Fatalf("failed to create Clientset: %v", err)
+		}
+		go func() {
+			// the signal handler is set up by the admission server, which exits the process on signals
+			if err := operations.Run(operationsOptions, cli, wait.NeverStop); err != nil {
+				klog.Fatalf("failed to run the operations API server: %v", err)
+			}
+		}()
+	}
+
+	if conversionOptions.SecurePort > 0 {
+		conversionOptions.CertFile = operationsOptions.CertFile
+		conversionOptions.KeyFile = operationsOptions.KeyFile
+		conversionOptions.Namespace = ns
+		conversionOptions.ServicePort = int32(conversionPort)
+		cfg, err := rest.InClusterConfig()
+		if err != nil {
+			klog.Fatalf("failed to get config: %v", err)
+		}
+		extCli, err := apiextensionsclientset.NewForConfig(cfg)
+		if err != nil {
+			klog.Fatalf("failed to create the apiextensions Clientset: %v", err)
+		}
+		go func() {
+			if err := conversion.Run(conversionOptions, extCli, wait.NeverStop); err != nil {
+				klog.Fatalf("failed to run the conversion webhook server: %v", err)
+			}
+		}()
+	}
+
 	cmd.RunAdmissionServer(statefulSetAdmissionHook, strategyAdmissionHook)
 }
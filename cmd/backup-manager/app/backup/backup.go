@@ -125,6 +125,9 @@ func constructOptions(backup *v1alpha1.Backup) ([]string, error) {
 	if config.TimeAgo != "" {
 		args = append(args, fmt.Sprintf("--timeago=%s", config.TimeAgo))
 	}
+	if config.LastBackupTS != "" {
+		args = append(args, fmt.Sprintf("--lastbackupts=%s", config.LastBackupTS))
+	}
 	if config.Checksum != nil {
 		args = append(args, fmt.Sprintf("--checksum=%t", *config.Checksum))
 	}
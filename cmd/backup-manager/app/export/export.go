@@ -14,11 +14,15 @@
 package export
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -29,6 +33,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -56,10 +61,45 @@ func (bo *Options) getBackupRelativePath() string {
 }
 
 func (bo *Options) getDestBucketURI(remotePath string) string {
+	if bo.StorageType == string(v1alpha1.BackupStorageTypeLocal) {
+		// the bucket of local storage is the absolute mount path of the volume
+		return fmt.Sprintf("%s:///%s", bo.StorageType, remotePath)
+	}
 	return fmt.Sprintf("%s://%s", bo.StorageType, remotePath)
 }
 
-func (bo *Options) dumpTidbClusterData(ctx context.Context, bfPath string, backup *v1alpha1.Backup) error {
+var (
+	dumplingTablesRegexp    = regexp.MustCompile(`tables="(\d+)/(\d+)`)
+	dumplingRowsRegexp      = regexp.MustCompile(`"finished rows"=(\d+)`)
+	dumplingTotalRowsRegexp = regexp.MustCompile(`"estimate total rows"=(\d+)`)
+)
+
+// parseDumplingProgress parses the progress from a log line of dumpling like
+// [progress] [tables="1/3 (33.3%)"] ["finished rows"=1000] ["estimate total rows"=3000],
+// it returns nil if the line is not a progress log
+func parseDumplingProgress(line string) *v1alpha1.DumplingProgress {
+	if !strings.Contains(line, "[progress]") {
+		return nil
+	}
+	tables := dumplingTablesRegexp.FindStringSubmatch(line)
+	if tables == nil {
+		return nil
+	}
+	progress := &v1alpha1.DumplingProgress{LastUpdateTime: metav1.Now()}
+	finishedTables, _ := strconv.ParseInt(tables[1], 10, 32)
+	totalTables, _ := strconv.ParseInt(tables[2], 10, 32)
+	progress.FinishedTables = int32(finishedTables)
+	progress.TotalTables = int32(totalTables)
+	if rows := dumplingRowsRegexp.FindStringSubmatch(line); rows != nil {
+		progress.FinishedRows, _ = strconv.ParseInt(rows[1], 10, 64)
+	}
+	if totalRows := dumplingTotalRowsRegexp.FindStringSubmatch(line); totalRows != nil {
+		progress.EstimatedTotalRows, _ = strconv.ParseInt(totalRows[1], 10, 64)
+	}
+	return progress
+}
+
+func (bo *Options) dumpTidbClusterData(ctx context.Context, bfPath string, backup *v1alpha1.Backup, progressFn func(*v1alpha1.DumplingProgress)) error {
 	err := backupUtil.EnsureDirectoryExist(bfPath)
 	if err != nil {
 		return err
@@ -96,9 +136,36 @@ func (bo *Options) dumpTidbClusterData(ctx context.Context, bfPath string, backu
 
 	klog.Infof("The dump process is ready, command \"%s %s\"", binPath, strings.Join(args_redacted, " "))
 
-	output, err := exec.CommandContext(ctx, binPath, args...).CombinedOutput()
+	cmd := exec.CommandContext(ctx, binPath, args...)
+	stdOut, err := cmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("cluster %s, execute dumpling command %v failed, output: %s, err: %v", bo, args, string(output), err)
+		return fmt.Errorf("cluster %s, create stdout pipe failed, err: %v", bo, err)
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cluster %s, start dumpling command %v failed, err: %v", bo, args_redacted, err)
+	}
+
+	// the progress is parsed from the output while dumpling is running
+	var output bytes.Buffer
+	scanner := bufio.NewScanner(stdOut)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		output.WriteString(line)
+		output.WriteByte('\n')
+		if progress := parseDumplingProgress(line); progress != nil {
+			progressFn(progress)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		klog.Warningf("cluster %s, failed to parse the output of dumpling, err: %v", bo, err)
+		// drain the output to not block dumpling
+		io.Copy(&output, stdOut)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("cluster %s, execute dumpling command %v failed, output: %s, err: %v", bo, args_redacted, output.String(), err)
 	}
 	return nil
 }
@@ -0,0 +1,34 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseDumplingProgress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	line := `[2022/03/01 08:00:00.000 +00:00] [INFO] [status.go:37] [progress] [tables="2/3 (66.7%)"] ["finished rows"=12345] ["estimate total rows"=20000] ["finished size"=1.2MB] ["average speed(MiB/s)"=0.5]`
+	progress := parseDumplingProgress(line)
+	g.Expect(progress).NotTo(BeNil())
+	g.Expect(progress.FinishedTables).To(Equal(int32(2)))
+	g.Expect(progress.TotalTables).To(Equal(int32(3)))
+	g.Expect(progress.FinishedRows).To(Equal(int64(12345)))
+	g.Expect(progress.EstimatedTotalRows).To(Equal(int64(20000)))
+
+	g.Expect(parseDumplingProgress(`[2022/03/01 08:00:00.000 +00:00] [INFO] [dump.go:1]["dump data successfully, dumpling will exit now"]`)).To(BeNil())
+}
@@ -163,6 +163,21 @@ func (bm *BackupManager) performBackup(ctx context.Context, backup *v1alpha1.Bac
 	}
 
 	var errs []error
+	if backup.Spec.Local != nil {
+		if err := util.CheckLocalStorage(backup.Spec.Local); err != nil {
+			errs = append(errs, err)
+			klog.Errorf("check local storage of cluster %s backup failed, err: %s", bm, err)
+			uerr := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:    v1alpha1.BackupFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "CheckLocalStorageFailed",
+				Message: err.Error(),
+			}, nil)
+			errs = append(errs, uerr)
+			return errorutils.NewAggregate(errs)
+		}
+	}
+
 	oldTikvGCTime, err := bm.GetTikvGCLifeTime(ctx, db)
 	if err != nil {
 		errs = append(errs, err)
@@ -259,7 +274,15 @@ func (bm *BackupManager) performBackup(ctx context.Context, backup *v1alpha1.Bac
 		return err
 	}
 
-	backupErr := bm.dumpTidbClusterData(ctx, backupFullPath, backup)
+	backupErr := bm.dumpTidbClusterData(ctx, backupFullPath, backup, func(progress *v1alpha1.DumplingProgress) {
+		err := bm.StatusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:   v1alpha1.BackupRunning,
+			Status: corev1.ConditionTrue,
+		}, &controller.BackupUpdateStatus{DumplingProgress: progress})
+		if err != nil {
+			klog.Warningf("update progress of cluster %s backup failed, err: %s", bm, err)
+		}
+	})
 	if oldTikvGCTimeDuration < tikvGCTimeDuration {
 		// use another context to revert `tikv_gc_life_time` back.
 		// `DefaultTerminationGracePeriodSeconds` for a pod is 30, so we use a smaller timeout value here.
@@ -0,0 +1,154 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	kvbackup "github.com/pingcap/kvproto/pkg/backup"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"k8s.io/klog/v2"
+)
+
+// tableChecksum is the checksum of a table recorded in the backup meta
+type tableChecksum struct {
+	db       string
+	table    string
+	checksum v1alpha1.TableChecksum
+}
+
+// parseBackupChecksums returns the checksums of the tables recorded in the backup meta,
+// the tables without checksum, e.g. backed up with `--checksum=false`, are skipped
+func parseBackupChecksums(meta *kvbackup.BackupMeta) ([]tableChecksum, error) {
+	var checksums []tableChecksum
+	for _, schema := range meta.Schemas {
+		// the schema without table is a database
+		if len(schema.Table) == 0 {
+			continue
+		}
+		var dbInfo struct {
+			Name struct {
+				O string `json:"O"`
+			} `json:"db_name"`
+		}
+		if err := json.Unmarshal(schema.Db, &dbInfo); err != nil {
+			return nil, fmt.Errorf("failed to decode database info in backup meta, err: %v", err)
+		}
+		var tableInfo struct {
+			Name struct {
+				O string `json:"O"`
+			} `json:"name"`
+		}
+		if err := json.Unmarshal(schema.Table, &tableInfo); err != nil {
+			return nil, fmt.Errorf("failed to decode table info of database %s in backup meta, err: %v", dbInfo.Name.O, err)
+		}
+		checksum := v1alpha1.TableChecksum{
+			Crc64Xor:   schema.Crc64Xor,
+			TotalKvs:   schema.TotalKvs,
+			TotalBytes: schema.TotalBytes,
+		}
+		if checksum == (v1alpha1.TableChecksum{}) {
+			continue
+		}
+		checksums = append(checksums, tableChecksum{db: dbInfo.Name.O, table: tableInfo.Name.O, checksum: checksum})
+	}
+	return checksums, nil
+}
+
+// isBackupMetaV2 returns whether the files and schemas of the backup are stored in separate meta
+// files, which are introduced by the backup meta v2
+func isBackupMetaV2(meta *kvbackup.BackupMeta) bool {
+	return len(meta.Files) == 0 && len(meta.Schemas) == 0
+}
+
+// checksumMatches returns whether the checksum of the restored table matches the backup. The
+// restored keys are prefixed by the new table ID, so Crc64Xor differs from the backup and is
+// verified by BR with the table IDs rewritten.
+func checksumMatches(expected, actual v1alpha1.TableChecksum) bool {
+	return expected.TotalKvs == actual.TotalKvs && expected.TotalBytes == actual.TotalBytes
+}
+
+// verifyChecksum runs `ADMIN CHECKSUM TABLE` on the restored tables and compares the results with
+// the checksums recorded in the backup meta
+func (rm *Manager) verifyChecksum(ctx context.Context, db *sql.DB, restore *v1alpha1.Restore, meta *kvbackup.BackupMeta) (*v1alpha1.RestoreChecksumVerification, error) {
+	if isBackupMetaV2(meta) {
+		klog.Infof("the schemas of the backup of cluster %s are in the meta files of backup meta v2, the checksums are only verified by BR", rm)
+		return &v1alpha1.RestoreChecksumVerification{}, nil
+	}
+	checksums, err := parseBackupChecksums(meta)
+	if err != nil {
+		return nil, err
+	}
+	// only a part of the backup is restored if the tables are filtered
+	filtered := len(restore.Spec.TableFilter) > 0 || restore.Spec.BR.DB != "" || restore.Spec.BR.Table != ""
+
+	verification := &v1alpha1.RestoreChecksumVerification{}
+	for _, expected := range checksums {
+		name := fmt.Sprintf("%s.%s", expected.db, expected.table)
+		actual, exist, err := adminChecksumTable(ctx, db, expected.db, expected.table)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			if filtered {
+				klog.Infof("table %s of cluster %s is not restored, skip verifying its checksum", name, rm)
+				continue
+			}
+			verification.Mismatches = append(verification.Mismatches, v1alpha1.TableChecksumMismatch{
+				Table:    name,
+				Expected: expected.checksum,
+			})
+			continue
+		}
+		verification.VerifiedTables++
+		if !checksumMatches(expected.checksum, *actual) {
+			klog.Errorf("checksum of table %s of cluster %s mismatches, expected: %+v, actual: %+v", name, rm, expected.checksum, *actual)
+			verification.Mismatches = append(verification.Mismatches, v1alpha1.TableChecksumMismatch{
+				Table:    name,
+				Expected: expected.checksum,
+				Actual:   actual,
+			})
+		}
+	}
+	return verification, nil
+}
+
+// adminChecksumTable returns the checksum of the table, it returns false if the table doesn't exist
+func adminChecksumTable(ctx context.Context, db *sql.DB, dbName, tableName string) (*v1alpha1.TableChecksum, bool, error) {
+	var exist int
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = ? AND table_name = ?", dbName, tableName)
+	if err := row.Scan(&exist); err != nil {
+		return nil, false, fmt.Errorf("query table %s.%s failed, err: %v", dbName, tableName, err)
+	}
+	if exist == 0 {
+		return nil, false, nil
+	}
+
+	var dbCol, tableCol string
+	checksum := &v1alpha1.TableChecksum{}
+	sql := fmt.Sprintf("ADMIN CHECKSUM TABLE %s.%s", quoteName(dbName), quoteName(tableName)) // nolint: gosec
+	row = db.QueryRowContext(ctx, sql)
+	if err := row.Scan(&dbCol, &tableCol, &checksum.Crc64Xor, &checksum.TotalKvs, &checksum.TotalBytes); err != nil {
+		return nil, false, fmt.Errorf("checksum table %s.%s failed, sql: %s, err: %v", dbName, tableName, sql, err)
+	}
+	return checksum, true, nil
+}
+
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	kvbackup "github.com/pingcap/kvproto/pkg/backup"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+)
+
+func TestParseBackupChecksums(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	meta := &kvbackup.BackupMeta{
+		Schemas: []*kvbackup.Schema{
+			{Db: []byte(`{"db_name":{"O":"test","L":"test"}}`)},
+			{
+				Db:         []byte(`{"db_name":{"O":"test","L":"test"}}`),
+				Table:      []byte(`{"id":1,"name":{"O":"T1","L":"t1"}}`),
+				Crc64Xor:   123,
+				TotalKvs:   10,
+				TotalBytes: 1024,
+			},
+			// backed up without checksum
+			{
+				Db:    []byte(`{"db_name":{"O":"test","L":"test"}}`),
+				Table: []byte(`{"id":2,"name":{"O":"t2","L":"t2"}}`),
+			},
+		},
+	}
+	checksums, err := parseBackupChecksums(meta)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(checksums).To(Equal([]tableChecksum{
+		{db: "test", table: "T1", checksum: v1alpha1.TableChecksum{Crc64Xor: 123, TotalKvs: 10, TotalBytes: 1024}},
+	}))
+
+	meta.Schemas[1].Table = []byte("invalid")
+	_, err = parseBackupChecksums(meta)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(isBackupMetaV2(meta)).To(BeFalse())
+	g.Expect(isBackupMetaV2(&kvbackup.BackupMeta{EndVersion: 1})).To(BeTrue())
+}
+
+func TestChecksumMatches(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	expected := v1alpha1.TableChecksum{Crc64Xor: 123, TotalKvs: 10, TotalBytes: 1024}
+	// the keys of the restored table are prefixed by the new table ID
+	g.Expect(checksumMatches(expected, v1alpha1.TableChecksum{Crc64Xor: 456, TotalKvs: 10, TotalBytes: 1024})).To(BeTrue())
+	g.Expect(checksumMatches(expected, v1alpha1.TableChecksum{Crc64Xor: 123, TotalKvs: 9, TotalBytes: 1024})).To(BeFalse())
+	g.Expect(checksumMatches(expected, v1alpha1.TableChecksum{Crc64Xor: 123, TotalKvs: 10, TotalBytes: 1000})).To(BeFalse())
+}
@@ -18,6 +18,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/constants"
@@ -289,12 +290,53 @@ func (rm *Manager) performRestore(ctx context.Context, restore *v1alpha1.Restore
 	}
 	klog.Infof("restore cluster %s from %s succeed", rm, restore.Spec.Type)
 
+	var verification *v1alpha1.RestoreChecksumVerification
+	if restore.Spec.VerifyChecksum && db != nil {
+		meta, err := util.GetBRMetaData(ctx, restore.Spec.StorageProvider)
+		if err == nil {
+			verification, err = rm.verifyChecksum(ctx, db, restore, meta)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			klog.Errorf("verify checksum of cluster %s failed, err: %s", rm, err)
+			uerr := rm.StatusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "VerifyChecksumFailed",
+				Message: err.Error(),
+			}, nil)
+			errs = append(errs, uerr)
+			return errorutils.NewAggregate(errs)
+		}
+		if len(verification.Mismatches) > 0 {
+			var tables []string
+			for _, mismatch := range verification.Mismatches {
+				tables = append(tables, mismatch.Table)
+			}
+			err = fmt.Errorf("checksum of %d tables mismatches: %s", len(tables), strings.Join(tables, ", "))
+			errs = append(errs, err)
+			klog.Errorf("verify checksum of cluster %s failed, err: %s", rm, err)
+			uerr := rm.StatusUpdater.Update(restore, &v1alpha1.RestoreCondition{
+				Type:    v1alpha1.RestoreFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "ChecksumMismatch",
+				Message: err.Error(),
+			}, &controller.RestoreUpdateStatus{
+				ChecksumVerification: verification,
+			})
+			errs = append(errs, uerr)
+			return errorutils.NewAggregate(errs)
+		}
+		klog.Infof("verify checksum of %d tables of cluster %s succeed", verification.VerifiedTables, rm)
+	}
+
 	finish := time.Now()
 	ts := strconv.FormatUint(commitTs, 10)
 	updateStatus := &controller.RestoreUpdateStatus{
-		TimeStarted:   &metav1.Time{Time: started},
-		TimeCompleted: &metav1.Time{Time: finish},
-		CommitTs:      &ts,
+		TimeStarted:          &metav1.Time{Time: started},
+		TimeCompleted:        &metav1.Time{Time: finish},
+		CommitTs:             &ts,
+		ChecksumVerification: verification,
 	}
 	return rm.StatusUpdater.Update(restore, &v1alpha1.RestoreCondition{
 		Type:   v1alpha1.RestoreComplete,
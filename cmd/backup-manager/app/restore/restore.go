@@ -115,7 +115,10 @@ func constructBROptions(restore *v1alpha1.Restore) ([]string, error) {
 	if config.Concurrency != nil {
 		args = append(args, fmt.Sprintf("--concurrency=%d", *config.Concurrency))
 	}
-	if config.Checksum != nil {
+	if restore.Spec.VerifyChecksum {
+		// the checksums calculated by BR are rewritten to the IDs of the restored tables
+		args = append(args, "--checksum=true")
+	} else if config.Checksum != nil {
 		args = append(args, fmt.Sprintf("--checksum=%t", *config.Checksum))
 	}
 	if config.CheckRequirements != nil {
@@ -118,6 +118,34 @@ func GetStoragePath(backup *v1alpha1.Backup) (string, error) {
 	}
 }
 
+// CheckLocalStorage checks the mounted volume of local storage before a backup starts,
+// the volume should be writable and have at least MinFreeSpace free if it's set
+func CheckLocalStorage(local *v1alpha1.LocalStorageProvider) error {
+	dir := path.Join(local.VolumeMount.MountPath, local.Prefix)
+	if err := EnsureDirectoryExist(dir); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, ".preflight-")
+	if err != nil {
+		return fmt.Errorf("dir %s is not writable, err: %v", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	if local.MinFreeSpace == nil {
+		return nil
+	}
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return fmt.Errorf("get free space of %s failed, err: %v", dir, err)
+	}
+	free := int64(stat.Bavail) * int64(stat.Bsize)
+	if free < local.MinFreeSpace.Value() {
+		return fmt.Errorf("free space %d bytes of %s is less than %s", free, dir, local.MinFreeSpace.String())
+	}
+	return nil
+}
+
 // IsFileExist return true if file exist and is a regular file, other cases return false
 func IsFileExist(file string) bool {
 	fi, err := os.Stat(file)
@@ -207,12 +235,36 @@ func ConstructDumplingOptionsForBackup(backup *v1alpha1.Backup) []string {
 		return args
 	}
 
-	if len(config.Dumpling.Options) != 0 {
-		args = append(args, config.Dumpling.Options...)
+	dumpling := config.Dumpling
+	if len(dumpling.Options) != 0 {
+		args = append(args, dumpling.Options...)
 	} else {
-		args = append(args, defaultOptions...)
+		for _, opt := range defaultOptions {
+			// skip the default options overridden by the options in spec
+			if (strings.HasPrefix(opt, "--threads=") && dumpling.Threads != nil) ||
+				(strings.HasPrefix(opt, "--rows=") && dumpling.Rows != nil) {
+				continue
+			}
+			args = append(args, opt)
+		}
 	}
 
+	// the latter flags take precedence over the former ones in dumpling
+	if dumpling.FileType != "" {
+		args = append(args, fmt.Sprintf("--filetype=%s", dumpling.FileType))
+	}
+	if dumpling.FileSize != "" {
+		args = append(args, fmt.Sprintf("--filesize=%s", dumpling.FileSize))
+	}
+	if dumpling.Threads != nil {
+		args = append(args, fmt.Sprintf("--threads=%d", *dumpling.Threads))
+	}
+	if dumpling.Rows != nil {
+		args = append(args, fmt.Sprintf("--rows=%d", *dumpling.Rows))
+	}
+	if dumpling.Consistency != "" {
+		args = append(args, fmt.Sprintf("--consistency=%s", dumpling.Consistency))
+	}
 	return args
 }
 
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
@@ -130,6 +131,40 @@ func TestConstructDumplingOptionsForBackup(t *testing.T) {
 	}
 }
 
+func TestConstructDumplingTypedOptionsForBackup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	backup := newBackup()
+	backup.Spec.Dumpling = &v1alpha1.DumplingConfig{
+		FileType:    "csv",
+		FileSize:    "256MiB",
+		Threads:     pointer.Int32Ptr(8),
+		Consistency: "snapshot",
+	}
+	g.Expect(ConstructDumplingOptionsForBackup(backup)).To(Equal([]string{
+		"--filter", "*.*",
+		"--filter", appconstant.DefaultTableFilter,
+		"--rows=10000",
+		"--filetype=csv",
+		"--filesize=256MiB",
+		"--threads=8",
+		"--consistency=snapshot",
+	}))
+
+	// the typed options are appended to the customized options to take precedence
+	backup.Spec.Dumpling.Options = []string{"--threads=4", "--rows=1000"}
+	backup.Spec.Dumpling.FileType = ""
+	backup.Spec.Dumpling.FileSize = ""
+	backup.Spec.Dumpling.Consistency = ""
+	g.Expect(ConstructDumplingOptionsForBackup(backup)).To(Equal([]string{
+		"--filter", "*.*",
+		"--filter", appconstant.DefaultTableFilter,
+		"--threads=4",
+		"--rows=1000",
+		"--threads=8",
+	}))
+}
+
 func TestConstructBRGlobalOptionsForBackup(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -409,6 +444,32 @@ func TestGetCommitTsFromMetadata(t *testing.T) {
 	g.Expect(commitTs).To(Equal("409054741514944513"))
 }
 
+func TestCheckLocalStorage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tmpdir, err := ioutil.TempDir("", "test-check-local-storage")
+	g.Expect(err).To(Succeed())
+	defer os.RemoveAll(tmpdir)
+
+	local := &v1alpha1.LocalStorageProvider{
+		VolumeMount: corev1.VolumeMount{Name: "nfs", MountPath: tmpdir},
+		Prefix:      "backup",
+	}
+	g.Expect(CheckLocalStorage(local)).To(Succeed())
+	g.Expect(IsDirExist(filepath.Join(tmpdir, "backup"))).To(BeTrue())
+	files, err := ioutil.ReadDir(filepath.Join(tmpdir, "backup"))
+	g.Expect(err).To(Succeed())
+	g.Expect(files).To(BeEmpty())
+
+	minFreeSpace := resource.MustParse("1Ki")
+	local.MinFreeSpace = &minFreeSpace
+	g.Expect(CheckLocalStorage(local)).To(Succeed())
+
+	minFreeSpace = resource.MustParse("1Ei")
+	err = CheckLocalStorage(local)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("is less than 1Ei"))
+}
+
 func TestConstructRcloneArgs(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -21,7 +21,9 @@ import (
 	"os"
 	"os/signal"
 	"reflect"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	asclientset "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned"
@@ -31,8 +33,15 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller/backup"
 	"github.com/pingcap/tidb-operator/pkg/controller/backupschedule"
 	"github.com/pingcap/tidb-operator/pkg/controller/dmcluster"
+	"github.com/pingcap/tidb-operator/pkg/controller/nodemaintenance"
+	"github.com/pingcap/tidb-operator/pkg/controller/orphangc"
+	"github.com/pingcap/tidb-operator/pkg/controller/readonly"
 	"github.com/pingcap/tidb-operator/pkg/controller/restore"
+	"github.com/pingcap/tidb-operator/pkg/controller/restoreschedule"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbcluster"
+	"github.com/pingcap/tidb-operator/pkg/controller/tidbclustermaintenance"
+	"github.com/pingcap/tidb-operator/pkg/controller/tidbclusteroperation"
+	"github.com/pingcap/tidb-operator/pkg/controller/tidbclusterpreflight"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbinitializer"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbmonitor"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbngmonitoring"
@@ -42,8 +51,11 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/upgrader"
 	"github.com/pingcap/tidb-operator/pkg/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -67,6 +79,21 @@ func main() {
 		os.Exit(0)
 	}
 
+	var configLoader *controller.ConfigFileLoader
+	if cliCfg.ConfigFile != "" {
+		configLoader = controller.NewConfigFileLoader(cliCfg, flag.CommandLine)
+		if err := configLoader.Load(); err != nil {
+			klog.Fatalf("failed to load flags from %s: %v", cliCfg.ConfigFile, err)
+		}
+	}
+
+	if cliCfg.PrintRBAC {
+		if err := printRBAC(os.Stdout, cliCfg, features.DefaultFeatureGate); err != nil {
+			klog.Fatalf("failed to print RBAC: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	logs.InitLogs()
 	defer logs.FlushLogs()
 
@@ -121,26 +148,86 @@ func main() {
 		klog.Fatalf("failed to get the generic kube-apiserver client: %v", err)
 	}
 
-	// note that kubeCli here must not be the hijacked one
-	var operatorUpgrader upgrader.Interface
-	if cliCfg.ClusterScoped {
-		operatorUpgrader = upgrader.NewUpgrader(kubeCli, cli, asCli, metav1.NamespaceAll)
-	} else {
-		operatorUpgrader = upgrader.NewUpgrader(kubeCli, cli, asCli, ns)
-	}
-
+	// note that kubeCli of the upgrader must not be the hijacked one
+	upgraderKubeCli := kubeCli
 	if features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
 		// If AdvancedStatefulSet is enabled, we hijack the Kubernetes client to use
 		// AdvancedStatefulSet.
 		kubeCli = helper.NewHijackClient(kubeCli, asCli)
 	}
 
-	deps, err := controller.NewDependencies(ns, cliCfg, cli, kubeCli, genericCli)
+	dynamicCli, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("failed to get the dynamic kube-apiserver client: %v", err)
+	}
+
+	// controllerCtx is cancelled to stop the controllers on exiting, and leaderElectionCtx is
+	// cancelled to release the leader lock after the controllers are stopped
+	controllerCtx, stopControllers := context.WithCancel(context.Background())
+	leaderElectionCtx, stopLeaderElection := context.WithCancel(context.Background())
+	var controllersWg sync.WaitGroup
+
+	// the requests to the clusters are canceled once the controllers are stopped
+	deps, err := controller.NewDependencies(controllerCtx, ns, cliCfg, cli, kubeCli, genericCli, dynamicCli)
 	if err != nil {
 		klog.Fatalf("failed to create Dependencies: %s", err)
 	}
 
+	var operatorUpgrader upgrader.Interface
+	if cliCfg.ClusterScoped {
+		// the CRDs can only be checked and their storage version can only be migrated by the cluster scoped operator
+		extCli, err := apiextensionsclientset.NewForConfig(cfg)
+		if err != nil {
+			klog.Fatalf("failed to get the apiextensions kube-apiserver client: %v", err)
+		}
+		operatorUpgrader = upgrader.NewUpgrader(upgraderKubeCli, cli, asCli, extCli, metav1.NamespaceAll, deps.Recorder)
+	} else {
+		operatorUpgrader = upgrader.NewUpgrader(upgraderKubeCli, cli, asCli, nil, ns, deps.Recorder)
+	}
+
+	// readOnlyServer serves the read-only endpoints on all replicas
+	readOnlyServer := readonly.NewServer(deps, hostName)
+
+	type InformerFactory interface {
+		Start(stopCh <-chan struct{})
+		WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
+	}
+	informerFactories := []InformerFactory{
+		deps.InformerFactory,
+		deps.KubeInformerFactory,
+		deps.LabelFilterKubeInformerFactory,
+	}
+	// startInformers starts the informers which are not started yet and waits for the caches to be synced,
+	// it's called on starting leading, and before the leader election if the informers are prewarmed
+	startInformers := func(stopCh <-chan struct{}) {
+		for _, f := range informerFactories {
+			f.Start(stopCh)
+			for v, synced := range f.WaitForCacheSync(wait.NeverStop) {
+				if !synced {
+					klog.Fatalf("error syncing informer for %v", v)
+				}
+			}
+		}
+		if f := deps.DynamicInformerFactory; f != nil {
+			f.Start(stopCh)
+			for gvr, synced := range f.WaitForCacheSync(wait.NeverStop) {
+				if !synced {
+					klog.Fatalf("error syncing informer for %v", gvr)
+				}
+			}
+		}
+		klog.Info("cache of informer factories sync successfully")
+		readOnlyServer.SetCacheSynced()
+	}
+	if cliCfg.PrewarmInformers {
+		// the informers requested by NewDependencies are started, and the ones requested by the
+		// controllers later are started on starting leading
+		go startInformers(controllerCtx.Done())
+	}
+
 	onStarted := func(ctx context.Context) {
+		readOnlyServer.SetLeader(true)
+
 		// Upgrade before running any controller logic. If it fails, we wait
 		// for process supervisor to restart it again.
 		if err := operatorUpgrader.Upgrade(); err != nil {
@@ -151,10 +238,6 @@ func main() {
 		type Controller interface {
 			Run(int, <-chan struct{})
 		}
-		type InformerFactory interface {
-			Start(stopCh <-chan struct{})
-			WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
-		}
 
 		// Initialize all controllers
 		controllers := []Controller{
@@ -164,37 +247,45 @@ func main() {
 			backup.NewController(deps),
 			restore.NewController(deps),
 			backupschedule.NewController(deps),
+			restoreschedule.NewController(deps),
 			tidbinitializer.NewController(deps),
 			tidbmonitor.NewController(deps),
 			tidbngmonitoring.NewController(deps),
+			tidbclustermaintenance.NewController(deps),
+			tidbclusteroperation.NewController(deps),
+			tidbclusterpreflight.NewController(deps),
+			nodemaintenance.NewController(deps),
 		}
 		if features.DefaultFeatureGate.Enabled(features.AutoScaling) {
 			controllers = append(controllers, autoscaler.NewController(deps))
 		}
+		if cliCfg.OrphanGCPeriod > 0 {
+			controllers = append(controllers, orphangc.NewController(deps))
+		}
 
 		// Start informer factories after all controllers are initialized.
-		informerFactories := []InformerFactory{
-			deps.InformerFactory,
-			deps.KubeInformerFactory,
-			deps.LabelFilterKubeInformerFactory,
-		}
-		for _, f := range informerFactories {
-			f.Start(ctx.Done())
-			for v, synced := range f.WaitForCacheSync(wait.NeverStop) {
-				if !synced {
-					klog.Fatalf("error syncing informer for %v", v)
-				}
-			}
+		if cliCfg.PrewarmInformers {
+			startInformers(controllerCtx.Done())
+		} else {
+			startInformers(ctx.Done())
 		}
-		klog.Info("cache of informer factories sync successfully")
 
 		// Start syncLoop for all controllers
 		for _, controller := range controllers {
 			c := controller
-			go wait.Forever(func() { c.Run(cliCfg.Workers, ctx.Done()) }, cliCfg.WaitDuration)
+			controllersWg.Add(1)
+			go func() {
+				defer controllersWg.Done()
+				wait.Until(func() { c.Run(cliCfg.Workers, controllerCtx.Done()) }, cliCfg.WaitDuration, controllerCtx.Done())
+			}()
 		}
 	}
 	onStopped := func() {
+		readOnlyServer.SetLeader(false)
+		if leaderElectionCtx.Err() != nil {
+			klog.Info("leader lock released")
+			return
+		}
 		klog.Fatal("leader election lost")
 	}
 
@@ -203,30 +294,50 @@ func main() {
 		endPointsName += "-" + helmRelease
 	}
 	// leader election for multiple tidb-controller-manager instances
-	go wait.Forever(func() {
-		leaderelection.RunOrDie(context.TODO(), leaderelection.LeaderElectionConfig{
-			Lock: &resourcelock.EndpointsLock{
-				EndpointsMeta: metav1.ObjectMeta{
-					Namespace: ns,
-					Name:      endPointsName,
+	leaderElectionDone := make(chan struct{})
+	go func() {
+		defer close(leaderElectionDone)
+		wait.Until(func() {
+			leaderelection.RunOrDie(leaderElectionCtx, leaderelection.LeaderElectionConfig{
+				Lock: &resourcelock.EndpointsLock{
+					EndpointsMeta: metav1.ObjectMeta{
+						Namespace: ns,
+						Name:      endPointsName,
+					},
+					Client: kubeCli.CoreV1(),
+					LockConfig: resourcelock.ResourceLockConfig{
+						Identity:      hostName,
+						EventRecorder: &record.FakeRecorder{},
+					},
 				},
-				Client: kubeCli.CoreV1(),
-				LockConfig: resourcelock.ResourceLockConfig{
-					Identity:      hostName,
-					EventRecorder: &record.FakeRecorder{},
+				LeaseDuration: cliCfg.LeaseDuration,
+				RenewDeadline: cliCfg.RenewDeadline,
+				RetryPeriod:   cliCfg.RetryPeriod,
+				// release the lock on exiting, so that the new leader takes over without waiting for the lease to expire
+				ReleaseOnCancel: true,
+				Callbacks: leaderelection.LeaderCallbacks{
+					OnStartedLeading: onStarted,
+					OnStoppedLeading: onStopped,
 				},
-			},
-			LeaseDuration: cliCfg.LeaseDuration,
-			RenewDeadline: cliCfg.RenewDeadline,
-			RetryPeriod:   cliCfg.RetryPeriod,
-			Callbacks: leaderelection.LeaderCallbacks{
-				OnStartedLeading: onStarted,
-				OnStoppedLeading: onStopped,
-			},
-		})
-	}, cliCfg.WaitDuration)
-
-	srv := createHTTPServer()
+			})
+		}, cliCfg.WaitDuration, leaderElectionCtx.Done())
+	}()
+
+	if configLoader != nil {
+		// the changes are recorded in the events of the Pod, whose name is the hostname
+		pod := &corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Namespace: ns, Name: hostName}
+		go configLoader.Watch(time.Minute, func(change controller.ConfigChange) {
+			if change.Applied {
+				klog.Infof("flag %s is changed from %q to %q", change.Flag, change.Old, change.New)
+				deps.Recorder.Eventf(pod, corev1.EventTypeNormal, "ConfigChanged", "flag %s is changed from %q to %q", change.Flag, change.Old, change.New)
+				return
+			}
+			klog.Warningf("flag %s is changed from %q to %q in %s, which takes effect on restarting", change.Flag, change.Old, change.New, cliCfg.ConfigFile)
+			deps.Recorder.Eventf(pod, corev1.EventTypeWarning, "ConfigChangeRequiresRestart", "flag %s is changed from %q to %q, which takes effect on restarting", change.Flag, change.Old, change.New)
+		}, controllerCtx.Done())
+	}
+
+	srv := createHTTPServer(readOnlyServer)
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc,
 		syscall.SIGHUP,
@@ -238,6 +349,15 @@ func main() {
 	go func() {
 		sig := <-sc
 		klog.Infof("got signal %s to exit", sig)
+
+		// stop the controllers and wait for the in-flight syncs to finish before releasing the leader lock
+		stopControllers()
+		if !waitTimeout(&controllersWg, cliCfg.GracefulShutdownTimeout) {
+			klog.Warningf("controllers are not stopped in %v, release the leader lock anyway", cliCfg.GracefulShutdownTimeout)
+		}
+		stopLeaderElection()
+		<-leaderElectionDone
+
 		if err2 := srv.Shutdown(context.Background()); err2 != nil {
 			klog.Fatal("fail to shutdown the HTTP server", err2)
 		}
@@ -249,10 +369,27 @@ func main() {
 	klog.Infof("tidb-controller-manager exited")
 }
 
-func createHTTPServer() *http.Server {
+// waitTimeout waits for the WaitGroup for at most timeout, it returns false if timed out
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func createHTTPServer(readOnlyServer *readonly.Server) *http.Server {
 	serverMux := http.NewServeMux()
 	// HTTP path for prometheus.
 	serverMux.Handle("/metrics", promhttp.Handler())
+	// HTTP paths for health checks and diagnostics, served by non-leader replicas too
+	readOnlyServer.Register(serverMux)
 
 	return &http.Server{
 		Addr:    ":6060",
@@ -108,7 +108,7 @@ func main() {
 		addr := fmt.Sprintf("0.0.0.0:%d", port)
 		klog.Infof("starting TiDB Discovery server, listening on %s", addr)
 		lister := kubeInformerFactory.Core().V1().Secrets().Lister()
-		discoveryServer := server.NewServer(pdapi.NewDefaultPDControl(lister), dmapi.NewDefaultMasterControl(lister), cli, kubeCli)
+		discoveryServer := server.NewServer(pdapi.NewDefaultPDControl(ctx, lister), dmapi.NewDefaultMasterControl(ctx, lister), cli, kubeCli)
 		discoveryServer.ListenAndServe(addr)
 	}, 5*time.Second)
 	go wait.Forever(func() {
@@ -0,0 +1,165 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pingcap/tidb-operator/pkg/sqlprobe"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/version"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/component-base/logs"
+	"k8s.io/klog/v2"
+)
+
+var (
+	printVersion     bool
+	port             int
+	host             string
+	tidbPort         int
+	database         string
+	interval         time.Duration
+	timeout          time.Duration
+	failureThreshold int
+	tlsEnabled       bool
+)
+
+func init() {
+	flag.BoolVar(&printVersion, "V", false, "Show version and quit")
+	flag.BoolVar(&printVersion, "version", false, "Show version and quit")
+	flag.IntVar(&port, "port", sqlprobe.Port, "The port that the metrics and the status of the probe are served on")
+	flag.StringVar(&host, "host", "", "The host of the TiDB service")
+	flag.IntVar(&tidbPort, "tidb-port", 4000, "The port of the TiDB service")
+	flag.StringVar(&database, "database", "tidb_operator", "The database of the health table")
+	flag.DurationVar(&interval, "interval", 10*time.Second, "The time between two probes")
+	flag.DurationVar(&timeout, "timeout", 5*time.Second, "The max time each step of a probe takes")
+	flag.IntVar(&failureThreshold, "failure-threshold", 3, "The number of failed probes in a row to be unhealthy")
+	flag.BoolVar(&tlsEnabled, "tls", false, "Connect to TiDB with the client certificate in "+util.TiDBClientTLSPath)
+	flag.Parse()
+}
+
+func main() {
+	if printVersion {
+		version.PrintVersionInfo()
+		os.Exit(0)
+	}
+	version.LogVersionInfo()
+
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	flag.CommandLine.VisitAll(func(flag *flag.Flag) {
+		klog.V(1).Infof("FLAG: --%s=%q", flag.Name, flag.Value)
+	})
+
+	if host == "" {
+		klog.Fatal("--host is not set")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = os.Getenv("TIDB_USER")
+	if cfg.User == "" {
+		cfg.User = "root"
+	}
+	cfg.Passwd = os.Getenv("TIDB_PASSWORD")
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s:%d", host, tidbPort)
+	cfg.Timeout = timeout
+	if tlsEnabled {
+		if err := registerTLSConfig(); err != nil {
+			klog.Fatalf("failed to load the client certificate: %v", err)
+		}
+		cfg.TLSConfig = "tidb"
+	}
+
+	id, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("failed to get hostname: %v", err)
+	}
+	prober := sqlprobe.NewProber(sqlprobe.Config{
+		DSN:              cfg.FormatDSN(),
+		Database:         database,
+		ID:               id,
+		Interval:         interval,
+		Timeout:          timeout,
+		FailureThreshold: failureThreshold,
+	})
+	sqlprobe.RegisterMetrics()
+
+	stopCh := make(chan struct{})
+	go prober.Run(stopCh)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle(sqlprobe.StatusPath, prober)
+	srv := http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux}
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc,
+		syscall.SIGHUP,
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGQUIT,
+	)
+
+	go func() {
+		sig := <-sc
+		klog.Infof("got signal %s to exit", sig)
+		close(stopCh)
+		if err2 := srv.Shutdown(context.Background()); err2 != nil {
+			klog.Fatal("fail to shutdown the HTTP server", err2)
+		}
+	}()
+
+	klog.Infof("starting SQL probe of %s, listening on %s", cfg.Addr, srv.Addr)
+	if err = srv.ListenAndServe(); err != http.ErrServerClosed {
+		klog.Fatal(err)
+	}
+	klog.Infof("tidb-sql-probe exited")
+}
+
+// registerTLSConfig registers the TLS config of the client certificate of TiDB as "tidb"
+func registerTLSConfig() error {
+	rootCertPool := x509.NewCertPool()
+	pem, err := ioutil.ReadFile(path.Join(util.TiDBClientTLSPath, corev1.ServiceAccountRootCAKey))
+	if err != nil {
+		return err
+	}
+	if ok := rootCertPool.AppendCertsFromPEM(pem); !ok {
+		return fmt.Errorf("failed to append PEM")
+	}
+	cert, err := tls.LoadX509KeyPair(
+		path.Join(util.TiDBClientTLSPath, corev1.TLSCertKey),
+		path.Join(util.TiDBClientTLSPath, corev1.TLSPrivateKeyKey))
+	if err != nil {
+		return err
+	}
+	return mysql.RegisterTLSConfig("tidb", &tls.Config{
+		RootCAs:      rootCertPool,
+		Certificates: []tls.Certificate{cert},
+		ServerName:   host,
+	})
+}
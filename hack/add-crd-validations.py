@@ -0,0 +1,107 @@
+#!/usr/bin/env python
+
+# Copyright 2022 PingCAP, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# This script is used to add the CEL validation rules (x-kubernetes-validations)
+# to the generated v1 CRDs, so that the obviously invalid objects are rejected by
+# kube-apiserver even if the admission webhook is unavailable.
+#
+# The rules are added here instead of by the XValidation markers because they are
+# not supported by the controller-gen we use. They are only enforced by the
+# kube-apiserver with the feature CustomResourceValidationExpressions enabled,
+# i.e. Kubernetes v1.25+ by default.
+#
+# NOTE: the labels of the objects are not accessible in the rules of CRDs, so the
+# immutability of the instance label is still validated by the admission webhook.
+
+
+import argparse
+import logging
+import os
+import yaml
+
+STORAGE_REQUEST_RULE = {
+    'rule': "has(self.requests) && 'storage' in self.requests",
+    'message': 'storage request must not be empty',
+}
+
+BASE_IMAGE_RULE = {
+    'rule': "self != ''",
+    'message': 'baseImage must not be empty',
+}
+
+# CRD file name -> list of (property path of the schema, rule)
+VALIDATIONS = {
+    'pingcap.com_tidbclusters.yaml': [
+        (['spec', 'pd'], STORAGE_REQUEST_RULE),
+        (['spec', 'tikv'], STORAGE_REQUEST_RULE),
+        (['spec', 'pump'], STORAGE_REQUEST_RULE),
+        (['spec', 'pd', 'baseImage'], BASE_IMAGE_RULE),
+        (['spec', 'tikv', 'baseImage'], BASE_IMAGE_RULE),
+        (['spec', 'tidb', 'baseImage'], BASE_IMAGE_RULE),
+    ],
+}
+
+
+# dump strings with double quotes as controller-gen does, so that the
+# generated files are not changed except the added rules
+class CRDDumper(yaml.SafeDumper):
+    def choose_scalar_style(self):
+        style = super(CRDDumper, self).choose_scalar_style()
+        if style == "'":
+            return '"'
+        return style
+
+    def ignore_aliases(self, data):
+        return True
+
+
+def add_validations(schema, path, rule):
+    for name in path:
+        schema = schema['properties'][name]
+    validations = schema.setdefault('x-kubernetes-validations', [])
+    if rule not in validations:
+        # sort the keys as controller-gen does
+        validations.append(dict(sorted(rule.items())))
+
+
+def process(filename, validations):
+    with open(filename) as f:
+        docs = [doc for doc in yaml.safe_load_all(f) if doc is not None]
+    for crd in docs:
+        for version in crd['spec']['versions']:
+            schema = version['schema']['openAPIV3Schema']
+            for path, rule in validations:
+                add_validations(schema, path, rule)
+    with open(filename, 'w') as f:
+        f.write('\n---\n')
+        yaml.dump_all(docs, f, Dumper=CRDDumper, default_flow_style=False,
+                      sort_keys=False, width=1 << 20)
+    logging.info("Added validations to %s" % filename)
+
+
+def main():
+    parser = argparse.ArgumentParser(
+        description='Add CEL validation rules to the v1 CRDs')
+    parser.add_argument('--crd-dir', type=str, required=True,
+                        help='The directory of the v1 CRDs')
+    args = parser.parse_args()
+    logging.basicConfig(level=logging.INFO)
+
+    for name, validations in VALIDATIONS.items():
+        process(os.path.join(args.crd_dir, name), validations)
+
+
+if __name__ == '__main__':
+    main()
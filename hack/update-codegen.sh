@@ -33,5 +33,12 @@ bash "${CODEGEN_PKG}"/generate-groups.sh "deepcopy,client,informer,lister" \
     pingcap:v1alpha1 \
     --output-base $SCRIPT_ROOT \
     --go-header-file ./hack/boilerplate/boilerplate.generatego.txt
+# v1beta1 is only served by the conversion webhook, no client is generated for it
+bash "${CODEGEN_PKG}"/generate-groups.sh "deepcopy" \
+    github.com/pingcap/tidb-operator/pkg/client \
+    github.com/pingcap/tidb-operator/pkg/apis \
+    pingcap:v1beta1 \
+    --output-base $SCRIPT_ROOT \
+    --go-header-file ./hack/boilerplate/boilerplate.generatego.txt
 # then we merge generated code with our code base and clean up
 cp -r github.com/pingcap/tidb-operator/pkg $SCRIPT_ROOT && rm -rf github.com
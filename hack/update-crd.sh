@@ -50,6 +50,9 @@ for file in ${SKIP_CRD_FILES[@]}; do
     rm -f ${CRD_OUTPUT_DIR}/v1/${file}
 done
 
+# add CEL validation rules to v1 CRDs
+python3 hack/add-crd-validations.py --crd-dir ${CRD_OUTPUT_DIR}/v1
+
 # merge all CRDs
 cat ${CRD_OUTPUT_DIR}/v1/*.yaml > ${ROOT}/manifests/crd.yaml
 cat ${CRD_OUTPUT_DIR}/v1beta1/*.yaml > ${ROOT}/manifests/crd_v1beta1.yaml
@@ -46,6 +46,10 @@ const (
 	StoreIDLabelKey string = "tidb.pingcap.com/store-id"
 	// MemberIDLabelKey is member id label key
 	MemberIDLabelKey string = "tidb.pingcap.com/member-id"
+	// ZoneLabelKey is the label key of the zone of the node which the Pod is scheduled to
+	ZoneLabelKey string = "tidb.pingcap.com/zone"
+	// StoreRoleLabelKey is the label key of the role of the replicas held by the TiKV stores in the Pod
+	StoreRoleLabelKey string = "tidb.pingcap.com/store-role"
 
 	// InitLabelKey is the key for TiDB initializer
 	InitLabelKey string = "tidb.pingcap.com/initializer"
@@ -53,15 +57,32 @@ const (
 	// BackupScheduleLabelKey is backup schedule key
 	BackupScheduleLabelKey string = "tidb.pingcap.com/backup-schedule"
 
+	// RestoreScheduleLabelKey is restore schedule key
+	RestoreScheduleLabelKey string = "tidb.pingcap.com/restore-schedule"
+
 	// BackupLabelKey is backup key
 	BackupLabelKey string = "tidb.pingcap.com/backup"
 
 	// RestoreLabelKey is restore key
 	RestoreLabelKey string = "tidb.pingcap.com/restore"
 
+	// MaintenanceLabelKey is TidbClusterMaintenance key
+	MaintenanceLabelKey string = "tidb.pingcap.com/maintenance"
+
+	// PreflightLabelKey is TidbClusterPreflight key
+	PreflightLabelKey string = "tidb.pingcap.com/preflight"
+
 	// BackupProtectionFinalizer is the name of finalizer on backups
 	BackupProtectionFinalizer string = "tidb.pingcap.com/backup-protection"
 
+	// NodeMaintenanceFinalizer is the name of finalizer on NodeMaintenances to revert
+	// the eviction of leaders before they are deleted
+	NodeMaintenanceFinalizer string = "tidb.pingcap.com/node-maintenance"
+
+	// TidbClusterPurgeFinalizer is the name of finalizer on TidbClusters whose deletionPolicy
+	// is Purge to tear down the cluster before it's deleted
+	TidbClusterPurgeFinalizer string = "tidb.pingcap.com/tidbcluster-purge"
+
 	// AutoScalingGroupLabelKey describes the autoscaling group of the TiDB
 	AutoScalingGroupLabelKey = "tidb.pingcap.com/autoscaling-group"
 	// AutoInstanceLabelKey is label key used in autoscaling, it represents the autoscaler name
@@ -87,21 +108,65 @@ const (
 	AnnTiDBPartition string = "tidb.pingcap.com/tidb-partition"
 	// AnnTiKVPartition is pod annotation which TiKV pod should upgrade to
 	AnnTiKVPartition string = "tidb.pingcap.com/tikv-partition"
-	// AnnForceUpgradeKey is tc annotation key to indicate whether force upgrade should be done
+	// AnnForceUpgradeKey is tc annotation key to indicate whether force upgrade should be done.
+	// Deprecated: use a TidbClusterOperation of type ForceUpgrade instead, which removes the annotation after the upgrade.
 	AnnForceUpgradeKey = "tidb.pingcap.com/force-upgrade"
+	// AnnForceVersionChange is tc annotation key to indicate whether the version changes not supported by TiDB,
+	// e.g. downgrading, are allowed
+	AnnForceVersionChange = "tidb.pingcap.com/force-version-change"
+	// AnnDryRun is tc annotation key to indicate whether the tc is reconciled in dry-run mode,
+	// in which the changes are recorded but not applied
+	AnnDryRun = "tidb.pingcap.com/dry-run"
+	// AnnDryRunDiffConfigMap is tc annotation key to indicate whether the diffs computed in
+	// dry-run mode are saved in a ConfigMap
+	AnnDryRunDiffConfigMap = "tidb.pingcap.com/dry-run-diff-configmap"
+	// AnnRolloutStormAck is tc annotation key to acknowledge the RolloutStorm condition, its value
+	// is the id of the storm shown in the condition message. The changes of pod template are
+	// resumed and the rollout budget is reset after the storm is acknowledged
+	AnnRolloutStormAck = "tidb.pingcap.com/rollout-storm-ack"
+	// AnnRemoveComponents is tc annotation key listing the components, separated by commas, to be removed
+	// safely after their specs are removed or their replicas are scaled to 0. Only tiflash and ticdc are supported
+	AnnRemoveComponents = "tidb.pingcap.com/remove-components"
+	// AnnTLSMigration is tc annotation key to acknowledge enabling the TLS between components of a running
+	// cluster, all components are restarted and can't talk to each other until the rolling update is done
+	AnnTLSMigration = "tidb.pingcap.com/tls-migration"
+	// AnnTLSCertManager is tc annotation key to indicate whether the certificates of the cluster are issued
+	// by cert-manager, the missing secrets are waited for without warning events
+	AnnTLSCertManager = "tidb.pingcap.com/tls-cert-manager"
+	// AnnStorageClassMigration is tc annotation key to migrate the PVCs of TiKV and TiFlash to the StorageClasses
+	// in spec, the stores are deleted and recreated one by one with the new PVCs
+	AnnStorageClassMigration = "tidb.pingcap.com/storage-class-migration"
 	// AnnPDDeferDeleting is pd pod annotation key  in pod for defer for deleting pod
 	AnnPDDeferDeleting = "tidb.pingcap.com/pd-defer-deleting"
+	// AnnNativeSidecars is pod template annotation key to record the names of the init containers rendered as
+	// native sidecars, whose restartPolicy is set to Always when the StatefulSet is applied. The names of the
+	// additional containers set in the annotations of a component are rendered as native sidecars as well
+	AnnNativeSidecars = "tidb.pingcap.com/native-sidecars"
 	// AnnSysctlInit is pod annotation key to indicate whether configuring sysctls with init container
 	AnnSysctlInit = "tidb.pingcap.com/sysctl-init"
+	// AnnNodeKernelVersion is pod annotation key to record the kernel version of the node when the sysctls
+	// are applied to the pod, the sysctls are considered drifted if the kernel of the node changes
+	AnnNodeKernelVersion = "tidb.pingcap.com/node-kernel-version"
 	// AnnEvictLeaderBeginTime is pod annotation key to indicate the begin time for evicting region leader
 	AnnEvictLeaderBeginTime = "tidb.pingcap.com/evictLeaderBeginTime"
 	// AnnTiCDCGracefulShutdownBeginTime is pod annotation key to indicate the begin time for graceful shutdown TiCDC
 	AnnTiCDCGracefulShutdownBeginTime = "tidb.pingcap.com/ticdc-graceful-shutdown-begin-time"
+	// AnnTiCDCDrainCheckpoints is pod annotation key to record the checkpoints of the changefeeds when the
+	// TiCDC capture is drained, the graceful shutdown waits for the checkpoints to advance
+	AnnTiCDCDrainCheckpoints = "tidb.pingcap.com/ticdc-drain-checkpoints"
+	// AnnBlockingPDVerification is tc annotation key to verify the PD endpoints by a blocking request to the discovery
+	// service in the start scripts of the clusters across Kubernetes, setting it causes a rolling-update
+	AnnBlockingPDVerification = "tidb.pingcap.com/blocking-pd-verification"
+	// AnnShareClusterClientSecret is tc annotation key to list the namespaces, separated by commas, of the heterogeneous
+	// clusters which are allowed to copy the client certificate of the cluster
+	AnnShareClusterClientSecret = "tidb.pingcap.com/share-cluster-client-secret"
 	// AnnStsLastSyncTimestamp is sts annotation key to indicate the last timestamp the operator sync the sts
 	AnnStsLastSyncTimestamp = "tidb.pingcap.com/sync-timestamp"
 
 	// AnnForceUpgradeVal is tc annotation value to indicate whether force upgrade should be done
 	AnnForceUpgradeVal = "true"
+	// AnnStorageClassMigrationVal is tc annotation value to enable the storage class migration
+	AnnStorageClassMigrationVal = "true"
 	// AnnSysctlInitVal is pod annotation value to indicate whether configuring sysctls with init container
 	AnnSysctlInitVal = "true"
 
@@ -133,8 +198,16 @@ const (
 	TiCDCLabelVal string = "ticdc"
 	// PumpLabelVal is Pump label value
 	PumpLabelVal string = "pump"
+	// TiProxyLabelVal is TiProxy label value
+	TiProxyLabelVal string = "tiproxy"
 	// DiscoveryLabelVal is Discovery label value
 	DiscoveryLabelVal string = "discovery"
+	// SQLProbeLabelVal is SQL probe label value
+	SQLProbeLabelVal string = "sql-probe"
+	// TopologyLabelVal is the label value of the discovery document of the cluster
+	TopologyLabelVal string = "topology"
+	// IdentityLabelVal is the label value of the identities of the cluster kept after the cluster is deleted
+	IdentityLabelVal string = "identity"
 	// TiDBMonitorVal is Monitor label value
 	TiDBMonitorVal string = "monitor"
 
@@ -146,8 +219,14 @@ const (
 	BackupJobLabelVal string = "backup"
 	// BackupScheduleJobLabelVal is backup schedule job label value
 	BackupScheduleJobLabelVal string = "backup-schedule"
+	// RestoreScheduleJobLabelVal is restore schedule job label value
+	RestoreScheduleJobLabelVal string = "restore-schedule"
 	// InitJobLabelVal is TiDB initializer job label value
 	InitJobLabelVal string = "initializer"
+	// MaintenanceJobLabelVal is TidbClusterMaintenance job label value
+	MaintenanceJobLabelVal string = "maintenance"
+	// PreflightJobLabelVal is TidbClusterPreflight job label value
+	PreflightJobLabelVal string = "preflight"
 	// TiDBOperator is ManagedByLabelKey label value
 	TiDBOperator string = "tidb-operator"
 
@@ -226,6 +305,30 @@ func NewBackupSchedule() Label {
 	}
 }
 
+// NewRestoreSchedule initialize a new Label for restores of restore schedule
+func NewRestoreSchedule() Label {
+	return Label{
+		NameLabelKey:      RestoreScheduleJobLabelVal,
+		ManagedByLabelKey: "restore-schedule-operator",
+	}
+}
+
+// NewMaintenance initialize a new Label for Jobs of TidbClusterMaintenance
+func NewMaintenance() Label {
+	return Label{
+		ComponentLabelKey: MaintenanceJobLabelVal,
+		ManagedByLabelKey: TiDBOperator,
+	}
+}
+
+// NewPreflight initialize a new Label for Jobs of TidbClusterPreflight
+func NewPreflight() Label {
+	return Label{
+		ComponentLabelKey: PreflightJobLabelVal,
+		ManagedByLabelKey: TiDBOperator,
+	}
+}
+
 func NewMonitor() Label {
 	return Label{
 		// NameLabelKey is used to be compatible with helm monitor
@@ -301,6 +404,24 @@ func (l Label) Initializer(val string) Label {
 	return l
 }
 
+// Maintenance assigns specific value to maintenance key in label
+func (l Label) Maintenance(val string) Label {
+	l[MaintenanceLabelKey] = val
+	return l
+}
+
+// Preflight assigns specific value to preflight key in label
+func (l Label) Preflight(val string) Label {
+	l[PreflightLabelKey] = val
+	return l
+}
+
+// Zone assigns specific value to zone key in label
+func (l Label) Zone(val string) Label {
+	l[ZoneLabelKey] = val
+	return l
+}
+
 // CleanJob assigns clean to component key in label
 func (l Label) CleanJob() Label {
 	return l.Component(CleanJobLabelVal)
@@ -328,6 +449,12 @@ func (l Label) BackupSchedule(val string) Label {
 	return l
 }
 
+// RestoreSchedule assigns specific value to restore schedule key in label
+func (l Label) RestoreSchedule(val string) Label {
+	l[RestoreScheduleLabelKey] = val
+	return l
+}
+
 // Restore assigns specific value to restore key in label
 func (l Label) Restore(val string) Label {
 	l[RestoreLabelKey] = val
@@ -409,6 +536,21 @@ func (l Label) Discovery() Label {
 	return l.Component(DiscoveryLabelVal)
 }
 
+// SQLProbe assigns sql-probe to component key in label
+func (l Label) SQLProbe() Label {
+	return l.Component(SQLProbeLabelVal)
+}
+
+// Topology assigns topology to component key in label
+func (l Label) Topology() Label {
+	return l.Component(TopologyLabelVal)
+}
+
+// Identity assigns identity to component key in label
+func (l Label) Identity() Label {
+	return l.Component(IdentityLabelVal)
+}
+
 // TiDB assigns tidb to component key in label
 func (l Label) TiDB() Label {
 	return l.Component(TiDBLabelVal)
@@ -449,6 +591,16 @@ func (l Label) IsTiCDC() bool {
 	return l[ComponentLabelKey] == TiCDCLabelVal
 }
 
+// TiProxy assigns tiproxy to component key in label
+func (l Label) TiProxy() Label {
+	return l.Component(TiProxyLabelVal)
+}
+
+// IsTiProxy returns whether label is a TiProxy component
+func (l Label) IsTiProxy() bool {
+	return l[ComponentLabelKey] == TiProxyLabelVal
+}
+
 // Selector gets labels.Selector from label
 func (l Label) Selector() (labels.Selector, error) {
 	return metav1.LabelSelectorAsSelector(l.LabelSelector())
@@ -0,0 +1,79 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// DefaultSinkUserKey is the default key of the user in the Secret of the sink credentials
+	DefaultSinkUserKey = "user"
+	// DefaultSinkPasswordKey is the default key of the password in the Secret of the sink credentials
+	DefaultSinkPasswordKey = "password"
+	// MaskedSinkPassword replaces the passwords in the masked sink URIs
+	MaskedSinkPassword = "xxxxx"
+)
+
+var (
+	// SinkCredentialsSchemes are the schemes of the sink URIs whose credentials can be read from Secrets
+	SinkCredentialsSchemes = sets.NewString("mysql", "tidb", "kafka")
+	// sinkPasswordParams are the query parameters of the sink URIs holding passwords
+	sinkPasswordParams = []string{"sasl-password", "password", "sasl-gssapi-password"}
+)
+
+// GetUserKey returns the key of the user in the Secret
+func (s *SinkCredentialsSecret) GetUserKey() string {
+	if s.UserKey == "" {
+		return DefaultSinkUserKey
+	}
+	return s.UserKey
+}
+
+// GetPasswordKey returns the key of the password in the Secret
+func (s *SinkCredentialsSecret) GetPasswordKey() string {
+	if s.PasswordKey == "" {
+		return DefaultSinkPasswordKey
+	}
+	return s.PasswordKey
+}
+
+// MaskSinkURI replaces the password in the user info and the password query parameters of the
+// sink URI, the URIs in the status, the events and the logs must be masked by it. The URI which
+// can't be parsed is masked entirely.
+func MaskSinkURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return MaskedSinkPassword
+	}
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), MaskedSinkPassword)
+		}
+	}
+	query := u.Query()
+	masked := false
+	for _, param := range sinkPasswordParams {
+		if query.Get(param) != "" {
+			query.Set(param, MaskedSinkPassword)
+			masked = true
+		}
+	}
+	if masked {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
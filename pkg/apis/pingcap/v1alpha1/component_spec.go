@@ -14,6 +14,8 @@
 package v1alpha1
 
 import (
+	"time"
+
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -22,6 +24,9 @@ import (
 
 const (
 	defaultHostNetwork = false
+	// DefaultPVCDeleteTTL is the default time to keep the PVCs of a scaled-in pod
+	// if pvcDeletePolicy is DeferWithTTL
+	DefaultPVCDeleteTTL = 24 * time.Hour
 )
 
 var (
@@ -77,6 +82,10 @@ type ComponentAccessor interface {
 	PodManagementPolicy() apps.PodManagementPolicyType
 	TopologySpreadConstraints() []corev1.TopologySpreadConstraint
 	SuspendAction() *SuspendAction
+	PVCDeletePolicy() PVCDeletePolicy
+	PVCDeleteTTL() time.Duration
+	Architectures() []string
+	EphemeralStorage() *EphemeralStorage
 }
 
 func (tc *TidbCluster) AllComponentSpec() []ComponentAccessor {
@@ -100,6 +109,9 @@ func (tc *TidbCluster) AllComponentSpec() []ComponentAccessor {
 	if tc.Spec.Pump != nil {
 		components = append(components, tc.BasePumpSpec())
 	}
+	if tc.Spec.TiProxy != nil {
+		components = append(components, tc.BaseTiProxySpec())
+	}
 	return components
 }
 
@@ -344,7 +356,7 @@ func (a *componentAccessorImpl) ConfigUpdateStrategy() ConfigUpdateStrategy {
 func (a *componentAccessorImpl) BuildPodSpec() corev1.PodSpec {
 	spec := corev1.PodSpec{
 		SchedulerName:             a.SchedulerName(),
-		Affinity:                  a.Affinity(),
+		Affinity:                  affinityWithArchitectures(a.Affinity(), a.Architectures()),
 		NodeSelector:              a.NodeSelector(),
 		HostNetwork:               a.HostNetwork(),
 		RestartPolicy:             corev1.RestartPolicyAlways,
@@ -458,6 +470,66 @@ func (a *componentAccessorImpl) SuspendAction() *SuspendAction {
 	return action
 }
 
+func (a *componentAccessorImpl) PVCDeletePolicy() PVCDeletePolicy {
+	if a.ComponentSpec == nil || len(a.ComponentSpec.PVCDeletePolicy) == 0 {
+		return PVCDeletePolicyRetain
+	}
+	return a.ComponentSpec.PVCDeletePolicy
+}
+
+func (a *componentAccessorImpl) PVCDeleteTTL() time.Duration {
+	if a.ComponentSpec == nil || a.ComponentSpec.PVCDeleteTTL == nil {
+		return DefaultPVCDeleteTTL
+	}
+	return a.ComponentSpec.PVCDeleteTTL.Duration
+}
+
+func (a *componentAccessorImpl) Architectures() []string {
+	if a.ComponentSpec == nil {
+		return nil
+	}
+	return a.ComponentSpec.Architectures
+}
+
+func (a *componentAccessorImpl) EphemeralStorage() *EphemeralStorage {
+	if a.ComponentSpec == nil {
+		return nil
+	}
+	return a.ComponentSpec.EphemeralStorage
+}
+
+// affinityWithArchitectures returns the affinity which also requires the nodes to be of one of
+// the architectures. The affinity is copied as it may be shared by the components.
+func affinityWithArchitectures(affinity *corev1.Affinity, archs []string) *corev1.Affinity {
+	if len(archs) == 0 {
+		return affinity
+	}
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(required.NodeSelectorTerms) == 0 {
+		required.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	// the terms are ORed, so the requirement is added to each of them
+	for i := range required.NodeSelectorTerms {
+		required.NodeSelectorTerms[i].MatchExpressions = append(required.NodeSelectorTerms[i].MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      corev1.LabelArchStable,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   append([]string(nil), archs...),
+		})
+	}
+	return affinity
+}
+
 func getComponentLabelValue(c MemberType) string {
 	switch c {
 	case PDMemberType:
@@ -472,6 +544,8 @@ func getComponentLabelValue(c MemberType) string {
 		return label.TiCDCLabelVal
 	case PumpMemberType:
 		return label.PumpLabelVal
+	case TiProxyMemberType:
+		return label.TiProxyLabelVal
 	case DiscoveryMemberType:
 		return label.DiscoveryLabelVal
 	case DMDiscoveryMemberType:
@@ -640,6 +714,16 @@ func (tc *TidbCluster) BasePumpSpec() ComponentAccessor {
 	return buildTidbClusterComponentAccessor(PumpMemberType, tc, spec)
 }
 
+// BaseTiProxySpec returns the base spec of TiProxy servers
+func (tc *TidbCluster) BaseTiProxySpec() ComponentAccessor {
+	var spec *ComponentSpec
+	if tc.Spec.TiProxy != nil {
+		spec = &tc.Spec.TiProxy.ComponentSpec
+	}
+
+	return buildTidbClusterComponentAccessor(TiProxyMemberType, tc, spec)
+}
+
 func (dc *DMCluster) BaseDiscoverySpec() ComponentAccessor {
 	return buildDMClusterComponentAccessor(DMDiscoveryMemberType, dc, dc.Spec.Discovery.ComponentSpec)
 }
@@ -41,6 +41,10 @@ const (
 	BackupScheduleKind    = "BackupSchedule"
 	BackupScheduleKindKey = "backupschedule"
 
+	RestoreScheduleName    = "restoreschedules"
+	RestoreScheduleKind    = "RestoreSchedule"
+	RestoreScheduleKindKey = "restoreschedule"
+
 	TiDBMonitorName    = "tidbmonitors"
 	TiDBMonitorKind    = "TidbMonitor"
 	TiDBMonitorKindKey = "tidbmonitor"
@@ -57,6 +61,22 @@ const (
 	TiDBNGMonitoringKind    = "TidbNGMonitoring"
 	TiDBNGMonitoringKindKey = "tidbngmonitoring"
 
+	TidbClusterMaintenanceName    = "tidbclustermaintenances"
+	TidbClusterMaintenanceKind    = "TidbClusterMaintenance"
+	TidbClusterMaintenanceKindKey = "tidbclustermaintenance"
+
+	TidbClusterOperationName    = "tidbclusteroperations"
+	TidbClusterOperationKind    = "TidbClusterOperation"
+	TidbClusterOperationKindKey = "tidbclusteroperation"
+
+	NodeMaintenanceName    = "nodemaintenances"
+	NodeMaintenanceKind    = "NodeMaintenance"
+	NodeMaintenanceKindKey = "nodemaintenance"
+
+	TidbClusterPreflightName    = "tidbclusterpreflights"
+	TidbClusterPreflightKind    = "TidbClusterPreflight"
+	TidbClusterPreflightKindKey = "tidbclusterpreflight"
+
 	SpecPath = "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1."
 )
 
@@ -69,27 +89,37 @@ type CrdKind struct {
 }
 
 type CrdKinds struct {
-	KindsString           string
-	TiDBCluster           CrdKind
-	DMCluster             CrdKind
-	Backup                CrdKind
-	Restore               CrdKind
-	BackupSchedule        CrdKind
-	TiDBMonitor           CrdKind
-	TiDBInitializer       CrdKind
-	TidbClusterAutoScaler CrdKind
-	TiDBNGMonitoring      CrdKind
+	KindsString            string
+	TiDBCluster            CrdKind
+	DMCluster              CrdKind
+	Backup                 CrdKind
+	Restore                CrdKind
+	BackupSchedule         CrdKind
+	RestoreSchedule        CrdKind
+	TiDBMonitor            CrdKind
+	TiDBInitializer        CrdKind
+	TidbClusterAutoScaler  CrdKind
+	TiDBNGMonitoring       CrdKind
+	TidbClusterMaintenance CrdKind
+	TidbClusterOperation   CrdKind
+	NodeMaintenance        CrdKind
+	TidbClusterPreflight   CrdKind
 }
 
 var DefaultCrdKinds = CrdKinds{
-	KindsString:           "",
-	TiDBCluster:           CrdKind{Plural: TiDBClusterName, Kind: TiDBClusterKind, ShortNames: []string{"tc"}, SpecName: SpecPath + TiDBClusterKind},
-	DMCluster:             CrdKind{Plural: DMClusterName, Kind: DMClusterKind, ShortNames: []string{"dc"}, SpecName: SpecPath + DMClusterKind},
-	Backup:                CrdKind{Plural: BackupName, Kind: BackupKind, ShortNames: []string{"bk"}, SpecName: SpecPath + BackupKind},
-	Restore:               CrdKind{Plural: RestoreName, Kind: RestoreKind, ShortNames: []string{"rt"}, SpecName: SpecPath + RestoreKind},
-	BackupSchedule:        CrdKind{Plural: BackupScheduleName, Kind: BackupScheduleKind, ShortNames: []string{"bks"}, SpecName: SpecPath + BackupScheduleKind},
-	TiDBMonitor:           CrdKind{Plural: TiDBMonitorName, Kind: TiDBMonitorKind, ShortNames: []string{"tm"}, SpecName: SpecPath + TiDBMonitorKind},
-	TiDBInitializer:       CrdKind{Plural: TiDBInitializerName, Kind: TiDBInitializerKind, ShortNames: []string{"ti"}, SpecName: SpecPath + TiDBInitializerKind},
-	TidbClusterAutoScaler: CrdKind{Plural: TidbClusterAutoScalerName, Kind: TidbClusterAutoScalerKind, ShortNames: []string{"ta"}, SpecName: SpecPath + TidbClusterAutoScalerKind},
-	TiDBNGMonitoring:      CrdKind{Plural: TiDBNGMonitoringName, Kind: TiDBNGMonitoringKind, ShortNames: []string{"tngm"}, SpecName: SpecPath + TiDBNGMonitoringKind},
+	KindsString:            "",
+	TiDBCluster:            CrdKind{Plural: TiDBClusterName, Kind: TiDBClusterKind, ShortNames: []string{"tc"}, SpecName: SpecPath + TiDBClusterKind},
+	DMCluster:              CrdKind{Plural: DMClusterName, Kind: DMClusterKind, ShortNames: []string{"dc"}, SpecName: SpecPath + DMClusterKind},
+	Backup:                 CrdKind{Plural: BackupName, Kind: BackupKind, ShortNames: []string{"bk"}, SpecName: SpecPath + BackupKind},
+	Restore:                CrdKind{Plural: RestoreName, Kind: RestoreKind, ShortNames: []string{"rt"}, SpecName: SpecPath + RestoreKind},
+	BackupSchedule:         CrdKind{Plural: BackupScheduleName, Kind: BackupScheduleKind, ShortNames: []string{"bks"}, SpecName: SpecPath + BackupScheduleKind},
+	RestoreSchedule:        CrdKind{Plural: RestoreScheduleName, Kind: RestoreScheduleKind, ShortNames: []string{"rts"}, SpecName: SpecPath + RestoreScheduleKind},
+	TiDBMonitor:            CrdKind{Plural: TiDBMonitorName, Kind: TiDBMonitorKind, ShortNames: []string{"tm"}, SpecName: SpecPath + TiDBMonitorKind},
+	TiDBInitializer:        CrdKind{Plural: TiDBInitializerName, Kind: TiDBInitializerKind, ShortNames: []string{"ti"}, SpecName: SpecPath + TiDBInitializerKind},
+	TidbClusterAutoScaler:  CrdKind{Plural: TidbClusterAutoScalerName, Kind: TidbClusterAutoScalerKind, ShortNames: []string{"ta"}, SpecName: SpecPath + TidbClusterAutoScalerKind},
+	TiDBNGMonitoring:       CrdKind{Plural: TiDBNGMonitoringName, Kind: TiDBNGMonitoringKind, ShortNames: []string{"tngm"}, SpecName: SpecPath + TiDBNGMonitoringKind},
+	TidbClusterMaintenance: CrdKind{Plural: TidbClusterMaintenanceName, Kind: TidbClusterMaintenanceKind, ShortNames: []string{"tcm"}, SpecName: SpecPath + TidbClusterMaintenanceKind},
+	TidbClusterOperation:   CrdKind{Plural: TidbClusterOperationName, Kind: TidbClusterOperationKind, ShortNames: []string{"tco"}, SpecName: SpecPath + TidbClusterOperationKind},
+	NodeMaintenance:        CrdKind{Plural: NodeMaintenanceName, Kind: NodeMaintenanceKind, ShortNames: []string{"nm"}, SpecName: SpecPath + NodeMaintenanceKind},
+	TidbClusterPreflight:   CrdKind{Plural: TidbClusterPreflightName, Kind: TidbClusterPreflightKind, ShortNames: []string{"tcpf"}, SpecName: SpecPath + TidbClusterPreflightKind},
 }
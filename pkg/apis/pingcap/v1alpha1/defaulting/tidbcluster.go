@@ -14,8 +14,11 @@
 package defaulting
 
 import (
+	"strings"
+
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
 
@@ -26,6 +29,7 @@ const (
 	defaultBinlogImage  = "pingcap/tidb-binlog"
 	defaultTiFlashImage = "pingcap/tiflash"
 	defaultTiCDCImage   = "pingcap/ticdc"
+	defaultTiProxyImage = "pingcap/tiproxy"
 )
 
 var (
@@ -52,6 +56,9 @@ func SetTidbClusterDefault(tc *v1alpha1.TidbCluster) {
 	if tc.Spec.TiCDC != nil {
 		setTiCDCSpecDefault(tc)
 	}
+	if tc.Spec.TiProxy != nil {
+		setTiProxySpecDefault(tc)
+	}
 }
 
 // setTidbClusterSpecDefault is only managed the property under Spec
@@ -76,6 +83,11 @@ func setTidbClusterSpecDefault(tc *v1alpha1.TidbCluster) {
 			tc.Spec.Cluster.Namespace = tc.GetNamespace()
 		}
 	}
+
+	// the addresses are joined as the --join argument of PD, which doesn't accept trailing slashes
+	for i, address := range tc.Spec.PDAddresses {
+		tc.Spec.PDAddresses[i] = strings.TrimRight(address, "/")
+	}
 }
 
 func setTidbSpecDefault(tc *v1alpha1.TidbCluster) {
@@ -108,6 +120,9 @@ func setTikvSpecDefault(tc *v1alpha1.TidbCluster) {
 	if tc.Spec.TiKV.MaxFailoverCount == nil {
 		tc.Spec.TiKV.MaxFailoverCount = pointer.Int32Ptr(3)
 	}
+	if tc.Spec.TiKV.StoreRelocation != nil && tc.Spec.TiKV.StoreRelocation.PendingThreshold == nil {
+		tc.Spec.TiKV.StoreRelocation.PendingThreshold = &metav1.Duration{Duration: tc.TiKVStoreRelocationPendingThreshold()}
+	}
 }
 
 func setPdSpecDefault(tc *v1alpha1.TidbCluster) {
@@ -146,4 +161,17 @@ func setTiCDCSpecDefault(tc *v1alpha1.TidbCluster) {
 			tc.Spec.TiCDC.BaseImage = defaultTiCDCImage
 		}
 	}
+	if tc.Spec.TiCDC.GracefulShutdownTimeout == nil {
+		tc.Spec.TiCDC.GracefulShutdownTimeout = &metav1.Duration{Duration: tc.TiCDCGracefulShutdownTimeout()}
+	}
+	if tc.Spec.TiCDC.DrainRetryInterval == nil {
+		tc.Spec.TiCDC.DrainRetryInterval = &metav1.Duration{Duration: tc.TiCDCDrainRetryInterval()}
+	}
+}
+
+func setTiProxySpecDefault(tc *v1alpha1.TidbCluster) {
+	// TiProxy is versioned independently, so the base image is defaulted even if no version is set
+	if tc.Spec.TiProxy.Image == "" && tc.Spec.TiProxy.BaseImage == "" {
+		tc.Spec.TiProxy.BaseImage = defaultTiProxyImage
+	}
 }
@@ -79,6 +79,15 @@ func TestSetTidbSpecDefault(t *testing.T) {
 
 }
 
+func TestSetTidbClusterSpecDefaultPDAddresses(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	tc.Spec.PDAddresses = []string{"https://1.2.3.4:2379/", "http://test-pd:2379"}
+	setTidbClusterSpecDefault(tc)
+	g.Expect(tc.Spec.PDAddresses).Should(Equal([]string{"https://1.2.3.4:2379", "http://test-pd:2379"}))
+}
+
 func newTidbCluster() *v1alpha1.TidbCluster {
 	return &v1alpha1.TidbCluster{
 		Spec: v1alpha1.TidbClusterSpec{
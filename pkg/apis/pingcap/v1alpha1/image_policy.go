@@ -0,0 +1,162 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// dockerHubRegistry is the registry of the images without a registry
+const dockerHubRegistry = "docker.io"
+
+// floatingTags are the tags moved to the newer images, the versions of the images can't be
+// told from them
+var floatingTags = sets.NewString("", "latest", "nightly")
+
+// imageReference is the parsed [registry/]repository[:tag][@digest]
+type imageReference struct {
+	// registry is empty if the image is from docker.io implicitly
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+func parseImageReference(image string) imageReference {
+	ref := imageReference{}
+	if i := strings.Index(image, "@"); i >= 0 {
+		ref.digest = image[i+1:]
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		ref.tag = image[i+1:]
+		image = image[:i]
+	}
+	// the first part is the registry if it looks like a host
+	if i := strings.Index(image, "/"); i >= 0 && (strings.ContainsAny(image[:i], ".:") || image[:i] == "localhost") {
+		ref.registry = image[:i]
+		image = image[i+1:]
+	}
+	ref.repository = image
+	return ref
+}
+
+// name returns the name of the image with the registry, without the tag and the digest
+func (r imageReference) name() string {
+	if r.registry == "" {
+		return dockerHubRegistry + "/" + r.repository
+	}
+	return r.registry + "/" + r.repository
+}
+
+func (r imageReference) String() string {
+	image := r.repository
+	if r.registry != "" {
+		image = r.registry + "/" + image
+	}
+	if r.tag != "" {
+		image += ":" + r.tag
+	}
+	if r.digest != "" {
+		image += "@" + r.digest
+	}
+	return image
+}
+
+// ResolveComponentImage resolves the image of the main container of a component, which runs on
+// the nodes selected by the nodeSelector. The image is returned as is if the policy is nil.
+func (p *ImagePolicy) ResolveComponentImage(image string, nodeSelector map[string]string) string {
+	if p == nil || image == "" {
+		return image
+	}
+	ref := parseImageReference(image)
+	// the suffixes are not appended to the images without tags, which are floating anyway
+	if ref.digest == "" && ref.tag != "" {
+		for _, suffix := range []string{p.TagSuffix, p.ArchTagSuffixes[nodeSelector[corev1.LabelArchStable]]} {
+			if suffix != "" && !strings.HasSuffix(ref.tag, suffix) {
+				ref.tag += suffix
+			}
+		}
+	}
+	return p.resolve(ref)
+}
+
+// ResolveImage resolves the image of the helper, init or additional containers, the tag suffixes
+// are not appended. The image is returned as is if the policy is nil.
+func (p *ImagePolicy) ResolveImage(image string) string {
+	if p == nil || image == "" {
+		return image
+	}
+	return p.resolve(parseImageReference(image))
+}
+
+// ResolvePodImages resolves the images of the init containers and containers of the Pod, the tag
+// suffixes are only appended to the image of the main container, no image gets the suffixes if
+// mainContainer is empty. The Pod is unchanged if the policy is nil.
+func (p *ImagePolicy) ResolvePodImages(podSpec *corev1.PodSpec, mainContainer string) {
+	if p == nil {
+		return
+	}
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Image = p.ResolveImage(podSpec.InitContainers[i].Image)
+	}
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if mainContainer != "" && c.Name == mainContainer {
+			c.Image = p.ResolveComponentImage(c.Image, podSpec.NodeSelector)
+		} else {
+			c.Image = p.ResolveImage(c.Image)
+		}
+	}
+}
+
+// resolve replaces the registry by the mirror and pins the image by the digest
+func (p *ImagePolicy) resolve(ref imageReference) string {
+	name := ref.name()
+	prefix := ""
+	for k := range p.RegistryMirrors {
+		if (name == k || strings.HasPrefix(name, k+"/")) && len(k) > len(prefix) {
+			prefix = k
+		}
+	}
+	if prefix != "" {
+		ref.registry = ""
+		ref.repository = strings.TrimSuffix(p.RegistryMirrors[prefix], "/") + strings.TrimPrefix(name, prefix)
+	}
+	if ref.digest == "" {
+		ref.digest = p.Digests[ref.String()]
+	}
+	return ref.String()
+}
+
+// CheckDigestPinned returns an error if DigestPinning is Enforced, and the image of the main
+// container of a component has a floating tag or is not pinned by digest after being resolved
+func (p *ImagePolicy) CheckDigestPinned(image string, nodeSelector map[string]string) error {
+	if p == nil || p.DigestPinning != DigestPinningEnforced {
+		return nil
+	}
+	// the images pinned by digests without tags are allowed
+	if ref := parseImageReference(image); floatingTags.Has(ref.tag) && (ref.tag != "" || ref.digest == "") {
+		return fmt.Errorf("the floating tag %q of image %s is not allowed when digest pinning is enforced", ref.tag, image)
+	}
+	resolved := p.ResolveComponentImage(image, nodeSelector)
+	if parseImageReference(resolved).digest == "" {
+		return fmt.Errorf("image %s is not pinned by digest, pin it in the image or by imagePolicy.digests", resolved)
+	}
+	return nil
+}
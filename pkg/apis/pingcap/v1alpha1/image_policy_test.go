@@ -0,0 +1,176 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResolveComponentImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	digest := "sha256:0123456789abcdef0123456789abcdef"
+	policy := &ImagePolicy{
+		RegistryMirrors: map[string]string{
+			"docker.io":         "registry.local/mirror",
+			"docker.io/pingcap": "registry.local/pingcap",
+			"gcr.io":            "registry.local/gcr/",
+		},
+		TagSuffix:       "-fips",
+		ArchTagSuffixes: map[string]string{"arm64": "-arm64"},
+		Digests: map[string]string{
+			"registry.local/pingcap/tikv:v5.4.0-fips": digest,
+		},
+	}
+	arm64 := map[string]string{corev1.LabelArchStable: "arm64"}
+
+	tests := []struct {
+		name         string
+		policy       *ImagePolicy
+		image        string
+		nodeSelector map[string]string
+		expected     string
+	}{
+		{
+			name:     "nil policy",
+			image:    "pingcap/pd:v5.4.0",
+			expected: "pingcap/pd:v5.4.0",
+		},
+		{
+			name:     "longest prefix of mirrors",
+			policy:   policy,
+			image:    "pingcap/pd:v5.4.0",
+			expected: "registry.local/pingcap/pd:v5.4.0-fips",
+		},
+		{
+			name:     "explicit registry",
+			policy:   policy,
+			image:    "docker.io/busybox:1.34",
+			expected: "registry.local/mirror/busybox:1.34-fips",
+		},
+		{
+			name:     "mirror with trailing slash",
+			policy:   policy,
+			image:    "gcr.io/project/image:v1",
+			expected: "registry.local/gcr/project/image:v1-fips",
+		},
+		{
+			name:     "registry without mirror",
+			policy:   policy,
+			image:    "quay.io/pingcap/pd:v5.4.0",
+			expected: "quay.io/pingcap/pd:v5.4.0-fips",
+		},
+		{
+			name:         "arch suffix",
+			policy:       policy,
+			image:        "pingcap/pd:v5.4.0",
+			nodeSelector: arm64,
+			expected:     "registry.local/pingcap/pd:v5.4.0-fips-arm64",
+		},
+		{
+			name:         "suffixes not appended twice",
+			policy:       policy,
+			image:        "pingcap/pd:v5.4.0-fips-arm64",
+			nodeSelector: arm64,
+			expected:     "registry.local/pingcap/pd:v5.4.0-fips-arm64",
+		},
+		{
+			name:     "no suffix for images without tags",
+			policy:   policy,
+			image:    "pingcap/pd",
+			expected: "registry.local/pingcap/pd",
+		},
+		{
+			name:     "no suffix for images with digests",
+			policy:   policy,
+			image:    "pingcap/pd:v5.4.0@" + digest,
+			expected: "registry.local/pingcap/pd:v5.4.0@" + digest,
+		},
+		{
+			name:     "pinned by digests",
+			policy:   policy,
+			image:    "pingcap/tikv:v5.4.0",
+			expected: "registry.local/pingcap/tikv:v5.4.0-fips@" + digest,
+		},
+		{
+			name:     "registry with port",
+			policy:   &ImagePolicy{RegistryMirrors: map[string]string{"localhost:5000": "registry.local"}},
+			image:    "localhost:5000/pingcap/pd:v5.4.0",
+			expected: "registry.local/pingcap/pd:v5.4.0",
+		},
+	}
+	for _, tt := range tests {
+		g.Expect(tt.policy.ResolveComponentImage(tt.image, tt.nodeSelector)).To(Equal(tt.expected), tt.name)
+	}
+
+	g.Expect(policy.ResolveImage("busybox:1.34")).To(Equal("registry.local/mirror/busybox:1.34"))
+}
+
+func TestCheckDigestPinned(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	digest := "sha256:0123456789abcdef0123456789abcdef"
+	policy := &ImagePolicy{
+		DigestPinning: DigestPinningEnforced,
+		Digests:       map[string]string{"pingcap/pd:v5.4.0": digest},
+	}
+
+	g.Expect(policy.CheckDigestPinned("pingcap/pd:v5.4.0", nil)).To(Succeed())
+	g.Expect(policy.CheckDigestPinned("pingcap/tikv:v5.4.0@"+digest, nil)).To(Succeed())
+	g.Expect(policy.CheckDigestPinned("pingcap/tikv@"+digest, nil)).To(Succeed())
+	g.Expect(policy.CheckDigestPinned("pingcap/tikv:v5.4.0", nil)).NotTo(Succeed())
+	g.Expect(policy.CheckDigestPinned("pingcap/tikv:latest@"+digest, nil)).NotTo(Succeed())
+	g.Expect(policy.CheckDigestPinned("pingcap/tikv", nil)).NotTo(Succeed())
+
+	policy.DigestPinning = ""
+	g.Expect(policy.CheckDigestPinned("pingcap/tikv:latest", nil)).To(Succeed())
+	g.Expect((*ImagePolicy)(nil).CheckDigestPinned("pingcap/tikv:latest", nil)).To(Succeed())
+}
+
+func TestResolvePodImages(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	policy := &ImagePolicy{
+		RegistryMirrors: map[string]string{"docker.io": "registry.local/mirror"},
+		TagSuffix:       "-fips",
+	}
+	newPodSpec := func() *corev1.PodSpec {
+		return &corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.26.2"}},
+			Containers: []corev1.Container{
+				{Name: "tikv", Image: "pingcap/tikv:v5.4.0"},
+				{Name: "slowlog", Image: "busybox:1.26.2"},
+			},
+		}
+	}
+
+	podSpec := newPodSpec()
+	policy.ResolvePodImages(podSpec, "tikv")
+	g.Expect(podSpec.InitContainers[0].Image).To(Equal("registry.local/mirror/busybox:1.26.2"))
+	g.Expect(podSpec.Containers[0].Image).To(Equal("registry.local/mirror/pingcap/tikv:v5.4.0-fips"))
+	g.Expect(podSpec.Containers[1].Image).To(Equal("registry.local/mirror/busybox:1.26.2"))
+
+	// no tag suffixes without the main container
+	podSpec = newPodSpec()
+	policy.ResolvePodImages(podSpec, "")
+	g.Expect(podSpec.Containers[0].Image).To(Equal("registry.local/mirror/pingcap/tikv:v5.4.0"))
+
+	podSpec = newPodSpec()
+	var nilPolicy *ImagePolicy
+	nilPolicy.ResolvePodImages(podSpec, "tikv")
+	g.Expect(podSpec).To(Equal(newPodSpec()))
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeMaintenance prepares the TiDB clusters of all namespaces synced by the
+// operator for the maintenance of a Kubernetes node. The PD leader and the TiKV region leaders
+// are moved out of the node before the node is drained, and the eviction of
+// leaders is reverted after the NodeMaintenance is deleted.
+//
+// +genclient
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName="nm"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`,description="The node under maintenance"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="The current phase of the maintenance"
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,description="The detail of the phase",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type NodeMaintenance struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the maintenance of the node
+	Spec NodeMaintenanceSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the maintenance
+	Status NodeMaintenanceStatus `json:"status,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeMaintenanceList is NodeMaintenance list
+type NodeMaintenanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []NodeMaintenance `json:"items"`
+}
+
+// NodeMaintenancePhase is the phase of a NodeMaintenance
+type NodeMaintenancePhase string
+
+const (
+	// NodeMaintenancePhasePreparing means the leaders or pods are being moved out of the node
+	NodeMaintenancePhasePreparing NodeMaintenancePhase = "Preparing"
+	// NodeMaintenancePhaseReady means the node is ready to be drained
+	NodeMaintenancePhaseReady NodeMaintenancePhase = "Ready"
+	// NodeMaintenancePhaseCompleting means the NodeMaintenance is deleted and
+	// the eviction of leaders is being reverted
+	NodeMaintenancePhaseCompleting NodeMaintenancePhase = "Completing"
+)
+
+// +k8s:openapi-gen=true
+// NodeMaintenanceSpec describes the attributes of a NodeMaintenance
+type NodeMaintenanceSpec struct {
+	// NodeName is the name of the node under maintenance
+	NodeName string `json:"nodeName"`
+
+	// ReschedulePods deletes the pods of the TiDB clusters on the node one by
+	// one after the leaders are moved out, so that they are recreated on other
+	// nodes. The pods are deleted only after the node is cordoned, and it
+	// requires the permission of reading nodes.
+	// Note that the pods using local volumes can not be recreated on other nodes.
+	// +optional
+	ReschedulePods bool `json:"reschedulePods,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// NodeMaintenanceStatus represents the current status of a NodeMaintenance
+type NodeMaintenanceStatus struct {
+	// Phase is the phase of the maintenance
+	Phase NodeMaintenancePhase `json:"phase,omitempty"`
+	// Message is the detail of the phase
+	Message string `json:"message,omitempty"`
+	// EvictedStores are the TiKV stores on the node whose leaders are evicted,
+	// keyed by the namespace and name of the pod, e.g. "tidb/basic-tikv-0"
+	EvictedStores map[string]NodeMaintenanceStore `json:"evictedStores,omitempty"`
+	// RescheduledPods are the namespaces and names of the pods deleted to be recreated on other nodes
+	RescheduledPods []string `json:"rescheduledPods,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// NodeMaintenanceStore is a TiKV store whose leaders are evicted by a NodeMaintenance
+type NodeMaintenanceStore struct {
+	// Namespace is the namespace of the TidbCluster the store belongs to
+	Namespace string `json:"namespace"`
+	// Cluster is the name of the TidbCluster the store belongs to
+	Cluster string `json:"cluster"`
+	// StoreID is the id of the store
+	StoreID string `json:"storeID"`
+	// LeaderCount is the number of region leaders left on the store
+	LeaderCount int32 `json:"leaderCount"`
+	// StartedEviction is whether the eviction of leaders is started by the
+	// NodeMaintenance, only such evictions are ended by it
+	StartedEviction bool `json:"startedEviction,omitempty"`
+}
@@ -58,6 +58,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&BackupScheduleList{},
 		&Restore{},
 		&RestoreList{},
+		&RestoreSchedule{},
+		&RestoreScheduleList{},
 		&DataResource{},
 		&DataResourceList{},
 		&TidbInitializer{},
@@ -70,6 +72,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DMClusterList{},
 		&TidbNGMonitoring{},
 		&TidbNGMonitoringList{},
+		&TidbClusterMaintenance{},
+		&TidbClusterMaintenanceList{},
+		&TidbClusterOperation{},
+		&TidbClusterOperationList{},
+		&NodeMaintenance{},
+		&NodeMaintenanceList{},
+		&TidbClusterPreflight{},
+		&TidbClusterPreflightList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -14,14 +14,17 @@
 package v1alpha1
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
@@ -41,6 +44,42 @@ const (
 	// defaultTiCDCGracefulShutdownTimeout is the timeout limit of graceful
 	// shutdown a TiCDC pod.
 	defaultTiCDCGracefulShutdownTimeout = 10 * time.Minute
+	// defaultTiCDCDrainRetryInterval is the minimum interval between two attempts
+	// to drain a TiCDC capture.
+	defaultTiCDCDrainRetryInterval = 10 * time.Second
+	// defaultTiKVStoreRelocationPendingThreshold is how long a TiKV Pod stays pending
+	// on unavailable nodes before its store is relocated
+	defaultTiKVStoreRelocationPendingThreshold = 30 * time.Minute
+	// defaultLoadBalancerDeregistrationDelay is the time to wait for the load balancers
+	// to deregister a TiDB pod
+	defaultLoadBalancerDeregistrationDelay = 30 * time.Second
+	// defaultTiDBWarmUpTimeout is the max time to run the warm-up statements on a TiDB pod
+	defaultTiDBWarmUpTimeout = 60 * time.Second
+	// defaultResourceReportWindow is the period the resource usage is calculated in
+	defaultResourceReportWindow = "1h"
+	// defaultResourceReportInterval is the interval between two resource reports
+	defaultResourceReportInterval = 10 * time.Minute
+	// defaultRecommendationsWindow is the period the CPU usage is averaged in for the recommendations
+	defaultRecommendationsWindow = "1h"
+	// defaultRecommendationsInterval is the interval between two computations of the recommendations
+	defaultRecommendationsInterval = 10 * time.Minute
+	// defaultUpgradePostCheckTimeout is the default time the cluster takes to be upgraded by spec.upgradePolicy
+	defaultUpgradePostCheckTimeout = time.Hour
+	// defaultSQLProbeInterval is the default time between two probes of spec.sqlProbe
+	defaultSQLProbeInterval = 10 * time.Second
+	// defaultSQLProbeTimeout is the default max time each step of a probe of spec.sqlProbe takes
+	defaultSQLProbeTimeout = 5 * time.Second
+	// defaultSQLProbeFailureThreshold is the default number of failed probes in a row to be unhealthy
+	defaultSQLProbeFailureThreshold = 3
+	// defaultSQLProbeDatabase is the default database of the health table of spec.sqlProbe
+	defaultSQLProbeDatabase = "tidb_operator"
+	// defaultEventThrottlingCooldownPeriod is the default time the limits of spec.eventThrottling
+	// are kept after the events end
+	defaultEventThrottlingCooldownPeriod = 10 * time.Minute
+	// defaultTiDBTargetCPUUtilization is the percentage of the CPU quota of TiDB expected to be used
+	defaultTiDBTargetCPUUtilization = 60
+	// defaultTiKVMinStorageHeadroom is the percentage of the storage of TiKV expected to be available
+	defaultTiKVMinStorageHeadroom = 20
 )
 
 var (
@@ -152,9 +191,234 @@ func (tc *TidbCluster) TiKVEvictLeaderTimeout() time.Duration {
 			return d
 		}
 	}
+	if tc.Spec.OperatorPolicy != nil && tc.Spec.OperatorPolicy.UpgradeWaitTimeout != nil {
+		return tc.Spec.OperatorPolicy.UpgradeWaitTimeout.Duration
+	}
 	return defaultEvictLeaderTimeout
 }
 
+// ResyncPeriod returns the period the cluster is synced at least once, 0 means the resync period
+// of the informer is used
+func (tc *TidbCluster) ResyncPeriod() time.Duration {
+	if tc.Spec.OperatorPolicy != nil && tc.Spec.OperatorPolicy.ResyncPeriod != nil {
+		return tc.Spec.OperatorPolicy.ResyncPeriod.Duration
+	}
+	return 0
+}
+
+// PDRequestTimeout returns the timeout of the requests to PD, 0 means the default timeout
+func (tc *TidbCluster) PDRequestTimeout() time.Duration {
+	if tc.Spec.OperatorPolicy != nil && tc.Spec.OperatorPolicy.PDRequestTimeout != nil {
+		return tc.Spec.OperatorPolicy.PDRequestTimeout.Duration
+	}
+	return 0
+}
+
+// UpgradePostCheckTimeout returns the max time the cluster takes to be upgraded by spec.upgradePolicy
+func (tc *TidbCluster) UpgradePostCheckTimeout() time.Duration {
+	if tc.Spec.UpgradePolicy != nil && tc.Spec.UpgradePolicy.PostCheckTimeout != nil {
+		return tc.Spec.UpgradePolicy.PostCheckTimeout.Duration
+	}
+	return defaultUpgradePostCheckTimeout
+}
+
+// SQLProbeInterval returns the time between two probes of spec.sqlProbe
+func (tc *TidbCluster) SQLProbeInterval() time.Duration {
+	if tc.Spec.SQLProbe != nil && tc.Spec.SQLProbe.Interval != nil {
+		return tc.Spec.SQLProbe.Interval.Duration
+	}
+	return defaultSQLProbeInterval
+}
+
+// SQLProbeTimeout returns the max time each step of a probe of spec.sqlProbe takes
+func (tc *TidbCluster) SQLProbeTimeout() time.Duration {
+	if tc.Spec.SQLProbe != nil && tc.Spec.SQLProbe.Timeout != nil {
+		return tc.Spec.SQLProbe.Timeout.Duration
+	}
+	return defaultSQLProbeTimeout
+}
+
+// SQLProbeFailureThreshold returns the number of failed probes in a row to be unhealthy
+func (tc *TidbCluster) SQLProbeFailureThreshold() int32 {
+	if tc.Spec.SQLProbe != nil && tc.Spec.SQLProbe.FailureThreshold != nil {
+		return *tc.Spec.SQLProbe.FailureThreshold
+	}
+	return defaultSQLProbeFailureThreshold
+}
+
+// SQLProbeDatabase returns the database of the health table of spec.sqlProbe
+func (tc *TidbCluster) SQLProbeDatabase() string {
+	if tc.Spec.SQLProbe != nil && tc.Spec.SQLProbe.Database != "" {
+		return tc.Spec.SQLProbe.Database
+	}
+	return defaultSQLProbeDatabase
+}
+
+// EventThrottlingEvents returns the events throttled by spec.eventThrottling
+func (tc *TidbCluster) EventThrottlingEvents() []ThrottledEvent {
+	if tc.Spec.EventThrottling != nil && len(tc.Spec.EventThrottling.Events) > 0 {
+		return tc.Spec.EventThrottling.Events
+	}
+	return []ThrottledEvent{ThrottledEventScale, ThrottledEventFailover, ThrottledEventRestore}
+}
+
+// EventThrottlingCooldownPeriod returns the time the limits of spec.eventThrottling are kept after the events end
+func (tc *TidbCluster) EventThrottlingCooldownPeriod() time.Duration {
+	if tc.Spec.EventThrottling != nil && tc.Spec.EventThrottling.CooldownPeriod != nil {
+		return tc.Spec.EventThrottling.CooldownPeriod.Duration
+	}
+	return defaultEventThrottlingCooldownPeriod
+}
+
+// DNSMode returns how the DNS names of spec.dns are published
+func (tc *TidbCluster) DNSMode() DNSMode {
+	if tc.Spec.DNS != nil && tc.Spec.DNS.Mode != "" {
+		return tc.Spec.DNS.Mode
+	}
+	return DNSModeAnnotation
+}
+
+// DNSName returns the DNS name of the Service of the component by spec.dns, it's empty if the
+// Service of the component is not published
+func (tc *TidbCluster) DNSName(component MemberType) (string, error) {
+	dns := tc.Spec.DNS
+	if dns == nil {
+		return "", nil
+	}
+	components := dns.Components
+	if len(components) == 0 {
+		components = []MemberType{TiDBMemberType, PDMemberType}
+	}
+	for _, c := range components {
+		if c == component {
+			return RenderDNSName(dns.DomainTemplate, tc.Name, tc.Namespace, component)
+		}
+	}
+	return "", nil
+}
+
+// RenderDNSName renders the DNS name of the Service of a component by the template of spec.dns.domainTemplate
+func RenderDNSName(domainTemplate, cluster, namespace string, component MemberType) (string, error) {
+	tmpl, err := template.New("domain").Option("missingkey=error").Parse(domainTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]string{
+		"Cluster":   cluster,
+		"Namespace": namespace,
+		"Component": component.String(),
+	}); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "."), nil
+}
+
+// TopologyExportConfigMapName returns the name of the ConfigMap holding the discovery document of spec.topologyExport
+func (tc *TidbCluster) TopologyExportConfigMapName() string {
+	if tc.Spec.TopologyExport != nil && tc.Spec.TopologyExport.ConfigMapName != "" {
+		return tc.Spec.TopologyExport.ConfigMapName
+	}
+	return fmt.Sprintf("%s-topology", tc.Name)
+}
+
+// PersistentIdentitySecretName returns the name of the Secret keeping the identities of the cluster
+func (tc *TidbCluster) PersistentIdentitySecretName() string {
+	if tc.Spec.PersistentIdentity != nil && tc.Spec.PersistentIdentity.SecretName != "" {
+		return tc.Spec.PersistentIdentity.SecretName
+	}
+	return fmt.Sprintf("%s-identity", tc.Name)
+}
+
+// TiDBLoadBalancerDeregistrationDelay returns the time to wait for the load balancers to deregister a TiDB pod
+func (tc *TidbCluster) TiDBLoadBalancerDeregistrationDelay() time.Duration {
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.LoadBalancerReadiness != nil && tc.Spec.TiDB.LoadBalancerReadiness.DeregistrationDelay != nil {
+		d, err := time.ParseDuration(*tc.Spec.TiDB.LoadBalancerReadiness.DeregistrationDelay)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultLoadBalancerDeregistrationDelay
+}
+
+// TiDBWarmUpTimeout returns the max time to run the warm-up statements on a TiDB pod
+func (tc *TidbCluster) TiDBWarmUpTimeout() time.Duration {
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.WarmUp != nil && tc.Spec.TiDB.WarmUp.Timeout != nil {
+		d, err := time.ParseDuration(*tc.Spec.TiDB.WarmUp.Timeout)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultTiDBWarmUpTimeout
+}
+
+// ResourceReportWindow returns the period the resource usage is calculated in
+func (tc *TidbCluster) ResourceReportWindow() string {
+	if tc.Spec.ResourceReport != nil && tc.Spec.ResourceReport.Window != "" {
+		return tc.Spec.ResourceReport.Window
+	}
+	return defaultResourceReportWindow
+}
+
+// ResourceReportInterval returns the interval between two resource reports
+func (tc *TidbCluster) ResourceReportInterval() time.Duration {
+	if tc.Spec.ResourceReport != nil && tc.Spec.ResourceReport.Interval != nil {
+		d, err := time.ParseDuration(*tc.Spec.ResourceReport.Interval)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultResourceReportInterval
+}
+
+// RecommendationsWindow returns the period the CPU usage is averaged in for the recommendations
+func (tc *TidbCluster) RecommendationsWindow() string {
+	if tc.Spec.Recommendations != nil && tc.Spec.Recommendations.Window != "" {
+		return tc.Spec.Recommendations.Window
+	}
+	return defaultRecommendationsWindow
+}
+
+// RecommendationsInterval returns the interval between two computations of the recommendations
+func (tc *TidbCluster) RecommendationsInterval() time.Duration {
+	if tc.Spec.Recommendations != nil && tc.Spec.Recommendations.Interval != nil {
+		d, err := time.ParseDuration(*tc.Spec.Recommendations.Interval)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultRecommendationsInterval
+}
+
+// TiDBTargetCPUUtilization returns the percentage of the CPU quota of TiDB expected to be used
+func (tc *TidbCluster) TiDBTargetCPUUtilization() int32 {
+	if tc.Spec.Recommendations != nil && tc.Spec.Recommendations.TiDBTargetCPUUtilization != nil {
+		return *tc.Spec.Recommendations.TiDBTargetCPUUtilization
+	}
+	return defaultTiDBTargetCPUUtilization
+}
+
+// TiKVMinStorageHeadroom returns the percentage of the storage of TiKV expected to be available
+func (tc *TidbCluster) TiKVMinStorageHeadroom() int32 {
+	if tc.Spec.Recommendations != nil && tc.Spec.Recommendations.TiKVMinStorageHeadroom != nil {
+		return *tc.Spec.Recommendations.TiKVMinStorageHeadroom
+	}
+	return defaultTiKVMinStorageHeadroom
+}
+
+// TiKVPodEvictLeaderRequested returns whether the TiKV Pod is listed in `spec.tikv.evictLeader`.
+func (tc *TidbCluster) TiKVPodEvictLeaderRequested(podName string) bool {
+	if tc.Spec.TiKV == nil {
+		return false
+	}
+	for _, name := range tc.Spec.TiKV.EvictLeader {
+		if name == podName {
+			return true
+		}
+	}
+	return false
+}
+
 // TiFlashImage return the image used by TiFlash.
 //
 // If TiFlash isn't specified, return empty string.
@@ -230,6 +494,17 @@ func (tc *TidbCluster) TiCDCImage() string {
 	return image
 }
 
+// TiCDCVersion returns the image tag of TiCDC, latest if the image has no tag
+func (tc *TidbCluster) TiCDCVersion() string {
+	image := tc.TiCDCImage()
+	colonIdx := strings.LastIndexByte(image, ':')
+	if colonIdx >= 0 {
+		return image[colonIdx+1:]
+	}
+
+	return "latest"
+}
+
 // TiCDCGracefulShutdownTimeout returns the timeout of gracefully shutdown
 // a TiCDC pod.
 func (tc *TidbCluster) TiCDCGracefulShutdownTimeout() time.Duration {
@@ -239,6 +514,24 @@ func (tc *TidbCluster) TiCDCGracefulShutdownTimeout() time.Duration {
 	return defaultTiCDCGracefulShutdownTimeout
 }
 
+// TiCDCDrainRetryInterval returns the minimum interval between two attempts
+// to drain a TiCDC capture.
+func (tc *TidbCluster) TiCDCDrainRetryInterval() time.Duration {
+	if tc.Spec.TiCDC != nil && tc.Spec.TiCDC.DrainRetryInterval != nil {
+		return tc.Spec.TiCDC.DrainRetryInterval.Duration
+	}
+	return defaultTiCDCDrainRetryInterval
+}
+
+// TiKVStoreRelocationPendingThreshold returns how long a TiKV Pod stays pending on
+// unavailable nodes before its store is relocated
+func (tc *TidbCluster) TiKVStoreRelocationPendingThreshold() time.Duration {
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.StoreRelocation != nil && tc.Spec.TiKV.StoreRelocation.PendingThreshold != nil {
+		return tc.Spec.TiKV.StoreRelocation.PendingThreshold.Duration
+	}
+	return defaultTiKVStoreRelocationPendingThreshold
+}
+
 // TiDBImage return the image used by TiDB.
 //
 // If TiDB isn't specified, return empty string.
@@ -289,6 +582,29 @@ func (tc *TidbCluster) PumpImage() *string {
 	return &image
 }
 
+// TiProxyImage return the image used by TiProxy.
+//
+// TiProxy is released independently of TiDB, so the version of the cluster is not used
+// and the base image without a tag is used if the version of TiProxy is not specified.
+func (tc *TidbCluster) TiProxyImage() string {
+	if tc.Spec.TiProxy == nil {
+		return ""
+	}
+
+	image := tc.Spec.TiProxy.Image
+	baseImage := tc.Spec.TiProxy.BaseImage
+	// base image takes higher priority
+	if baseImage != "" {
+		version := tc.Spec.TiProxy.Version
+		if version == nil || *version == "" {
+			image = baseImage
+		} else {
+			image = fmt.Sprintf("%s:%s", baseImage, *version)
+		}
+	}
+	return image
+}
+
 func (tc *TidbCluster) HelperImage() string {
 	image := tc.GetHelperSpec().Image
 	if image == nil && tc.Spec.TiDB != nil {
@@ -818,6 +1134,45 @@ func (tc *TidbCluster) IsTLSClusterEnabled() bool {
 	return tc.Spec.TLSCluster != nil && tc.Spec.TLSCluster.Enabled
 }
 
+// IsDryRun returns whether the tc is reconciled in dry-run mode
+func (tc *TidbCluster) IsDryRun() bool {
+	return tc.Annotations[label.AnnDryRun] == "true"
+}
+
+// BlockingPDVerification returns whether the start scripts verify the PD endpoints by a blocking request
+// to the discovery service, it's only enabled by the annotation tidb.pingcap.com/blocking-pd-verification
+// to avoid the rolling-update of the existing clusters
+func (tc *TidbCluster) BlockingPDVerification() bool {
+	return tc.AcrossK8s() && tc.Annotations[label.AnnBlockingPDVerification] == "true"
+}
+
+// ComponentRemoving returns whether the component is being removed, i.e. it's listed in the
+// annotation tidb.pingcap.com/remove-components and its spec is removed or its replicas is 0
+func (tc *TidbCluster) ComponentRemoving(typ MemberType) bool {
+	listed := false
+	for _, c := range strings.Split(tc.Annotations[label.AnnRemoveComponents], ",") {
+		if strings.TrimSpace(c) == typ.String() {
+			listed = true
+			break
+		}
+	}
+	if !listed {
+		return false
+	}
+	switch typ {
+	case TiFlashMemberType:
+		return tc.Spec.TiFlash == nil || tc.Spec.TiFlash.Replicas == 0
+	case TiCDCMemberType:
+		return tc.Spec.TiCDC == nil || tc.Spec.TiCDC.Replicas == 0
+	}
+	return false
+}
+
+// DeriveConfigFromResources returns whether to derive the config of components from their resources
+func (tc *TidbCluster) DeriveConfigFromResources() bool {
+	return tc.Spec.DeriveConfigFromResources != nil && *tc.Spec.DeriveConfigFromResources
+}
+
 func (tc *TidbCluster) NeedToSyncTiDBInitializer() bool {
 	return tc.Spec.TiDB != nil && tc.Spec.TiDB.Initializer != nil && tc.Spec.TiDB.Initializer.CreatePassword && tc.Status.TiDB.PasswordInitialized == nil
 }
@@ -1060,3 +1415,114 @@ func (tc *TidbCluster) IsComponentVolumeResizing(compType MemberType) bool {
 	conds := comp.GetConditions()
 	return meta.IsStatusConditionTrue(conds, ComponentVolumeResizing)
 }
+
+// ComponentRequests returns the cpu, memory and storage requested by all the Pods of the components,
+// including the Pods created by the failover and the sidecars, i.e. the log tailers and the additional
+// containers. The init containers run before the others, so the requests of a Pod are the larger of
+// the summed requests of the containers and the requests of each init container, as counted by
+// ResourceQuota. The storage includes the storage volumes.
+func (tc *TidbCluster) ComponentRequests() (corev1.ResourceList, error) {
+	sum := corev1.ResourceList{}
+	add := func(name corev1.ResourceName, q resource.Quantity, replicas int32) {
+		total := sum[name]
+		if name == corev1.ResourceCPU {
+			total.Add(*resource.NewMilliQuantity(q.MilliValue()*int64(replicas), q.Format))
+		} else {
+			total.Add(*resource.NewQuantity(q.Value()*int64(replicas), q.Format))
+		}
+		sum[name] = total
+	}
+	addComponent := func(spec *ComponentSpec, requests corev1.ResourceList, sidecars []corev1.ResourceList, volumes []StorageVolume, replicas int32, storage bool) error {
+		for _, c := range spec.AdditionalContainers {
+			sidecars = append(sidecars, c.Resources.Requests)
+		}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			var pod resource.Quantity
+			for _, r := range append([]corev1.ResourceList{requests}, sidecars...) {
+				if q, ok := r[name]; ok {
+					pod.Add(q)
+				}
+			}
+			for _, c := range spec.InitContainers {
+				if q, ok := c.Resources.Requests[name]; ok && q.Cmp(pod) > 0 {
+					pod = q.DeepCopy()
+				}
+			}
+			if !pod.IsZero() {
+				add(name, pod, replicas)
+			}
+		}
+		if q, ok := requests[corev1.ResourceStorage]; ok && storage {
+			add(corev1.ResourceStorage, q, replicas)
+		}
+		for _, v := range volumes {
+			q, err := resource.ParseQuantity(v.StorageSize)
+			if err != nil {
+				return fmt.Errorf("invalid storage size %q of storage volume %s: %v", v.StorageSize, v.Name, err)
+			}
+			add(corev1.ResourceStorage, q, replicas)
+		}
+		return nil
+	}
+
+	if spec := tc.Spec.PD; spec != nil {
+		if err := addComponent(&spec.ComponentSpec, spec.Requests, nil, spec.StorageVolumes, tc.PDStsDesiredReplicas(), true); err != nil {
+			return nil, err
+		}
+	}
+	if spec := tc.Spec.TiKV; spec != nil {
+		var sidecars []corev1.ResourceList
+		if spec.LogTailer != nil {
+			if spec.ShouldSeparateRocksDBLog() {
+				sidecars = append(sidecars, spec.LogTailer.Requests)
+			}
+			if spec.ShouldSeparateRaftLog() {
+				sidecars = append(sidecars, spec.LogTailer.Requests)
+			}
+		}
+		if err := addComponent(&spec.ComponentSpec, spec.Requests, sidecars, spec.StorageVolumes, tc.TiKVStsDesiredReplicas(), true); err != nil {
+			return nil, err
+		}
+	}
+	if spec := tc.Spec.TiDB; spec != nil {
+		var sidecars []corev1.ResourceList
+		if spec.ShouldSeparateSlowLog() && spec.SlowLogTailer != nil {
+			sidecars = append(sidecars, spec.SlowLogTailer.Requests)
+		}
+		if err := addComponent(&spec.ComponentSpec, spec.Requests, sidecars, spec.StorageVolumes, tc.TiDBStsDesiredReplicas(), false); err != nil {
+			return nil, err
+		}
+	}
+	if spec := tc.Spec.TiFlash; spec != nil {
+		replicas := tc.TiFlashStsDesiredReplicas()
+		var sidecars []corev1.ResourceList
+		if spec.LogTailer != nil {
+			// serverlog, errorlog and clusterlog
+			sidecars = append(sidecars, spec.LogTailer.Requests, spec.LogTailer.Requests, spec.LogTailer.Requests)
+		}
+		if err := addComponent(&spec.ComponentSpec, spec.Requests, sidecars, nil, replicas, false); err != nil {
+			return nil, err
+		}
+		for _, claim := range spec.StorageClaims {
+			if q, ok := claim.Resources.Requests[corev1.ResourceStorage]; ok {
+				add(corev1.ResourceStorage, q, replicas)
+			}
+		}
+	}
+	if spec := tc.Spec.TiCDC; spec != nil {
+		if err := addComponent(&spec.ComponentSpec, spec.Requests, nil, spec.StorageVolumes, spec.Replicas, false); err != nil {
+			return nil, err
+		}
+	}
+	if spec := tc.Spec.Pump; spec != nil {
+		if err := addComponent(&spec.ComponentSpec, spec.Requests, nil, nil, spec.Replicas, true); err != nil {
+			return nil, err
+		}
+	}
+	if spec := tc.Spec.TiProxy; spec != nil {
+		if err := addComponent(&spec.ComponentSpec, spec.Requests, nil, nil, spec.Replicas, false); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
@@ -384,6 +384,17 @@ func TestComponentAccessor(t *testing.T) {
 			}},
 		},
 	}
+	zoneA := corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}
+	zoneB := corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}
+	nodeAffinity := affinity.DeepCopy()
+	nodeAffinity.NodeAffinity = &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{zoneA}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{zoneB}},
+			},
+		},
+	}
 	toleration1 := corev1.Toleration{
 		Key: "k1",
 	}
@@ -505,6 +516,38 @@ func TestComponentAccessor(t *testing.T) {
 				g.Expect(a.Tolerations()).Should(ConsistOf(toleration2))
 			},
 		},
+		{
+			name:    "architectures without affinity",
+			cluster: &TidbClusterSpec{},
+			component: &ComponentSpec{
+				Architectures: []string{"arm64"},
+			},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				g.Expect(a.BuildPodSpec().Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).Should(Equal([]corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}}},
+				}}))
+			},
+		},
+		{
+			name: "architectures added to each node selector term",
+			cluster: &TidbClusterSpec{
+				Affinity: nodeAffinity,
+			},
+			component: &ComponentSpec{
+				Architectures: []string{"amd64", "arm64"},
+			},
+			expectFn: func(g *GomegaWithT, a ComponentAccessor) {
+				arch := corev1.NodeSelectorRequirement{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64", "arm64"}}
+				affinity := a.BuildPodSpec().Affinity
+				g.Expect(affinity.PodAffinity).Should(Equal(nodeAffinity.PodAffinity))
+				terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+				g.Expect(terms).Should(HaveLen(2))
+				g.Expect(terms[0].MatchExpressions).Should(Equal([]corev1.NodeSelectorRequirement{zoneA, arch}))
+				g.Expect(terms[1].MatchExpressions).Should(Equal([]corev1.NodeSelectorRequirement{zoneB, arch}))
+				// the affinity shared by the components is not modified
+				g.Expect(nodeAffinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions).Should(HaveLen(1))
+			},
+		},
 	}
 
 	for i := range tests {
@@ -686,6 +729,52 @@ func TestTiCDCGracefulShutdownTimeout(t *testing.T) {
 	g.Expect(tc.TiCDCGracefulShutdownTimeout()).To(Equal(time.Minute))
 }
 
+func TestTiCDCDrainRetryInterval(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	g.Expect(tc.TiCDCDrainRetryInterval()).To(Equal(defaultTiCDCDrainRetryInterval))
+
+	tc.Spec.TiCDC = &TiCDCSpec{DrainRetryInterval: &metav1.Duration{Duration: time.Minute}}
+	g.Expect(tc.TiCDCDrainRetryInterval()).To(Equal(time.Minute))
+}
+
+func TestTiKVStoreRelocationPendingThreshold(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	g.Expect(tc.TiKVStoreRelocationPendingThreshold()).To(Equal(defaultTiKVStoreRelocationPendingThreshold))
+
+	tc.Spec.TiKV = &TiKVSpec{StoreRelocation: &TiKVStoreRelocation{Enabled: true}}
+	g.Expect(tc.TiKVStoreRelocationPendingThreshold()).To(Equal(defaultTiKVStoreRelocationPendingThreshold))
+
+	tc.Spec.TiKV.StoreRelocation.PendingThreshold = &metav1.Duration{Duration: time.Hour}
+	g.Expect(tc.TiKVStoreRelocationPendingThreshold()).To(Equal(time.Hour))
+}
+
+func TestOperatorPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	g.Expect(tc.ResyncPeriod()).To(BeZero())
+	g.Expect(tc.PDRequestTimeout()).To(BeZero())
+	g.Expect(tc.TiKVEvictLeaderTimeout()).To(Equal(defaultEvictLeaderTimeout))
+
+	tc.Spec.OperatorPolicy = &OperatorPolicy{
+		ResyncPeriod:       &metav1.Duration{Duration: 5 * time.Minute},
+		PDRequestTimeout:   &metav1.Duration{Duration: 30 * time.Second},
+		UpgradeWaitTimeout: &metav1.Duration{Duration: time.Hour},
+	}
+	g.Expect(tc.ResyncPeriod()).To(Equal(5 * time.Minute))
+	g.Expect(tc.PDRequestTimeout()).To(Equal(30 * time.Second))
+	g.Expect(tc.TiKVEvictLeaderTimeout()).To(Equal(time.Hour))
+
+	// the evict leader timeout of TiKV takes precedence
+	timeout := "10m"
+	tc.Spec.TiKV = &TiKVSpec{EvictLeaderTimeout: &timeout}
+	g.Expect(tc.TiKVEvictLeaderTimeout()).To(Equal(10 * time.Minute))
+}
+
 func TestComponentFunc(t *testing.T) {
 	t.Run("ComponentIsNormal", func(t *testing.T) {
 		g := NewGomegaWithT(t)
@@ -0,0 +1,240 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TidbClusterMaintenance describes a one-shot or scheduled maintenance task
+// executed against a TiDB cluster through the TiKV and PD APIs
+//
+// +genclient
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName="tcm"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="The type of the maintenance task"
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`,description="The cron format string used for scheduling"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="The current phase of the maintenance task"
+// +kubebuilder:printcolumn:name="Progress",type=string,JSONPath=`.status.progress`,description="The progress of the maintenance task"
+// +kubebuilder:printcolumn:name="LastScheduleTime",type=date,JSONPath=`.status.lastScheduleTime`,description="The last time the task was started"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type TidbClusterMaintenance struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the maintenance task
+	Spec TidbClusterMaintenanceSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the maintenance task
+	Status TidbClusterMaintenanceStatus `json:"status,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TidbClusterMaintenanceList is TidbClusterMaintenance list
+type TidbClusterMaintenanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TidbClusterMaintenance `json:"items"`
+}
+
+// MaintenanceType is the type of a maintenance task
+type MaintenanceType string
+
+const (
+	// MaintenanceTypeCompact runs a manual compaction on each selected TiKV store one by one
+	MaintenanceTypeCompact MaintenanceType = "Compact"
+	// MaintenanceTypeScatterRegions asks PD to scatter the regions in a key range
+	MaintenanceTypeScatterRegions MaintenanceType = "ScatterRegions"
+	// MaintenanceTypeFlashbackCleanup restores the PD schedule limits that a
+	// `FLASHBACK CLUSTER` statement disables while it is running, which are left
+	// disabled if the flashback is interrupted
+	MaintenanceTypeFlashbackCleanup MaintenanceType = "FlashbackCleanup"
+	// MaintenanceTypePDDefrag defragments the etcd db of each PD member one by one
+	// to reclaim the space freed by compaction, the leader is defragmented last
+	MaintenanceTypePDDefrag MaintenanceType = "PDDefrag"
+)
+
+// MaintenancePhase is the phase of a maintenance task
+type MaintenancePhase string
+
+const (
+	// MaintenancePhasePending means the task is waiting for its next run
+	MaintenancePhasePending MaintenancePhase = "Pending"
+	// MaintenancePhaseRunning means the task is running
+	MaintenancePhaseRunning MaintenancePhase = "Running"
+	// MaintenancePhaseComplete means the last run of the task completed successfully
+	MaintenancePhaseComplete MaintenancePhase = "Complete"
+	// MaintenancePhaseFailed means the last run of the task failed
+	MaintenancePhaseFailed MaintenancePhase = "Failed"
+)
+
+// +k8s:openapi-gen=true
+// TidbClusterMaintenanceSpec describes the attributes of a maintenance task
+type TidbClusterMaintenanceSpec struct {
+	// Cluster is the TidbCluster the task runs against
+	Cluster TidbClusterRef `json:"cluster"`
+
+	// Type of the maintenance task
+	// +kubebuilder:validation:Enum=Compact;ScatterRegions;FlashbackCleanup;PDDefrag
+	Type MaintenanceType `json:"type"`
+
+	// Schedule is the cron format string used to run the task periodically.
+	// The task runs only once if it is not set.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Pause stops scheduling new runs of the task, a running task is not interrupted
+	// +optional
+	Pause bool `json:"pause,omitempty"`
+
+	// Compact describes the options of the Compact task
+	// +optional
+	Compact *CompactOptions `json:"compact,omitempty"`
+
+	// ScatterRegions describes the options of the ScatterRegions task
+	// +optional
+	ScatterRegions *ScatterRegionsOptions `json:"scatterRegions,omitempty"`
+
+	// FlashbackCleanup describes the options of the FlashbackCleanup task
+	// +optional
+	FlashbackCleanup *FlashbackCleanupOptions `json:"flashbackCleanup,omitempty"`
+
+	// PDDefrag describes the options of the PDDefrag task
+	// +optional
+	PDDefrag *PDDefragOptions `json:"pdDefrag,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// CompactOptions describes the options of the Compact task
+type CompactOptions struct {
+	// Stores are the names of the TiKV pods to compact, all TiKV stores are
+	// compacted if it is empty
+	// +optional
+	Stores []string `json:"stores,omitempty"`
+
+	// DB is the db to compact, one of kv and raft, default to kv
+	// +kubebuilder:validation:Enum=kv;raft
+	// +optional
+	DB string `json:"db,omitempty"`
+
+	// ColumnFamily is the column family to compact, default to default
+	// +optional
+	ColumnFamily string `json:"columnFamily,omitempty"`
+
+	// Threads is the number of threads used by the compaction on each store
+	// +optional
+	Threads *int32 `json:"threads,omitempty"`
+
+	// BottommostLevelCompaction is passed to tikv-ctl, one of skip, force and if-optimized
+	// +kubebuilder:validation:Enum=skip;force;if-optimized
+	// +optional
+	BottommostLevelCompaction string `json:"bottommostLevelCompaction,omitempty"`
+
+	// Resources of the compaction job
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// ScatterRegionsOptions describes the options of the ScatterRegions task
+type ScatterRegionsOptions struct {
+	// StartKey is the start of the raw key range to scatter
+	// +optional
+	StartKey string `json:"startKey,omitempty"`
+
+	// EndKey is the end of the raw key range to scatter, an empty key means the end of the key space
+	// +optional
+	EndKey string `json:"endKey,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// FlashbackCleanupOptions describes the options of the FlashbackCleanup task
+type FlashbackCleanupOptions struct {
+	// ScheduleConfig overrides the PD schedule config items restored by the
+	// task, the PD defaults are used for the items not specified
+	// +optional
+	ScheduleConfig map[string]string `json:"scheduleConfig,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// PDDefragOptions describes the options of the PDDefrag task
+type PDDefragOptions struct {
+	// DBSizeThreshold is the minimum size of the etcd db to defragment, the
+	// members with smaller db are skipped. All members are checked if it is not set.
+	// +optional
+	DBSizeThreshold *resource.Quantity `json:"dbSizeThreshold,omitempty"`
+
+	// MinFreePercent is the minimum percentage of the etcd db freed by compaction,
+	// the members with less free space are skipped as defragmentation reclaims little.
+	// Default to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MinFreePercent *int32 `json:"minFreePercent,omitempty"`
+
+	// Compact compacts the history of the etcd keys until the current revision before
+	// defragmenting, the watchers lagging behind the revision have to resync.
+	// The history is compacted by PD periodically if it is not set.
+	// +optional
+	Compact bool `json:"compact,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterMaintenanceStatus represents the current status of a maintenance task
+type TidbClusterMaintenanceStatus struct {
+	// Phase is the phase of the current or last run
+	Phase MaintenancePhase `json:"phase,omitempty"`
+	// Progress is the human readable progress of the current or last run, e.g. 2/3
+	Progress string `json:"progress,omitempty"`
+	// Message is the detail of the phase
+	Message string `json:"message,omitempty"`
+	// LastScheduleTime is the time the current or last run was started
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// CompletionTime is the time the last run finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Stores is the progress of the Compact task on each store, keyed by the pod name
+	Stores map[string]MaintenanceStoreStatus `json:"stores,omitempty"`
+	// PDMembers is the progress of the PDDefrag task on each PD member, keyed by the member name
+	PDMembers map[string]MaintenancePDMemberStatus `json:"pdMembers,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// MaintenanceStoreStatus is the progress of a maintenance task on a store
+type MaintenanceStoreStatus struct {
+	StoreID string           `json:"storeID,omitempty"`
+	Phase   MaintenancePhase `json:"phase,omitempty"`
+	Message string           `json:"message,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// MaintenancePDMemberStatus is the progress of a maintenance task on a PD member
+type MaintenancePDMemberStatus struct {
+	MemberID string           `json:"memberID,omitempty"`
+	Phase    MaintenancePhase `json:"phase,omitempty"`
+	Message  string           `json:"message,omitempty"`
+	// DBSizeBefore is the size of the etcd db in bytes before the defragmentation
+	DBSizeBefore int64 `json:"dbSizeBefore,omitempty"`
+	// DBSizeAfter is the size of the etcd db in bytes after the defragmentation
+	DBSizeAfter int64 `json:"dbSizeAfter,omitempty"`
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TidbClusterOperation describes an imperative operation on a TiDB cluster, e.g. evicting
+// the region leaders of a TiKV store or recovering the failover Pods. It replaces the
+// annotations and the one-shot fields which trigger the same operations, the operation
+// is executed once and its steps are recorded in the status.
+//
+// +genclient
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName="tco"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="The type of the operation"
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster.name`,description="The TidbCluster the operation applies to"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="The current phase of the operation"
+// +kubebuilder:printcolumn:name="Progress",type=string,JSONPath=`.status.progress`,description="The progress of the running step"
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,description="The detail of the phase",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type TidbClusterOperation struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the operation
+	Spec TidbClusterOperationSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the operation
+	Status TidbClusterOperationStatus `json:"status,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TidbClusterOperationList is TidbClusterOperation list
+type TidbClusterOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TidbClusterOperation `json:"items"`
+}
+
+// TidbClusterOperationType is the type of an operation
+type TidbClusterOperationType string
+
+const (
+	// TidbClusterOperationEvictLeader evicts the region leaders of a TiKV store and restarts its Pod,
+	// the leaders are allowed to move back after the Pod is ready again.
+	// It replaces the annotation `tidb.pingcap.com/evict-leader: delete-pod` of the Pod.
+	TidbClusterOperationEvictLeader TidbClusterOperationType = "EvictLeader"
+	// TidbClusterOperationReplaceDisk moves all the regions out of a TiKV store, deletes the PVCs
+	// and the Pod, and waits for the Pod to join the cluster as a new store with new volumes,
+	// optionally on another node
+	TidbClusterOperationReplaceDisk TidbClusterOperationType = "ReplaceDisk"
+	// TidbClusterOperationRecoverFailover removes the Pods created by the failover of a component
+	// once the failed Pods are healthy again.
+	// It replaces setting `spec.<component>.failover.recoverByUID` by hand.
+	TidbClusterOperationRecoverFailover TidbClusterOperationType = "RecoverFailover"
+	// TidbClusterOperationForceUpgrade upgrades PD forcedly while PD is unavailable.
+	// It replaces the annotation `tidb.pingcap.com/force-upgrade` of the TidbCluster,
+	// which is removed after the upgrade.
+	TidbClusterOperationForceUpgrade TidbClusterOperationType = "ForceUpgrade"
+)
+
+// TidbClusterOperationPhase is the phase of an operation
+type TidbClusterOperationPhase string
+
+const (
+	// TidbClusterOperationPending means the operation is not started yet
+	TidbClusterOperationPending TidbClusterOperationPhase = "Pending"
+	// TidbClusterOperationRunning means the operation is running
+	TidbClusterOperationRunning TidbClusterOperationPhase = "Running"
+	// TidbClusterOperationSucceeded means the operation completed successfully
+	TidbClusterOperationSucceeded TidbClusterOperationPhase = "Succeeded"
+	// TidbClusterOperationFailed means the operation failed and is not retried
+	TidbClusterOperationFailed TidbClusterOperationPhase = "Failed"
+)
+
+// +k8s:openapi-gen=true
+// TidbClusterOperationSpec describes the attributes of an operation
+type TidbClusterOperationSpec struct {
+	// Cluster is the TidbCluster the operation applies to
+	Cluster TidbClusterRef `json:"cluster"`
+
+	// Type of the operation
+	// +kubebuilder:validation:Enum=EvictLeader;ReplaceDisk;RecoverFailover;ForceUpgrade
+	Type TidbClusterOperationType `json:"type"`
+
+	// PodName is the name of the TiKV Pod, it's required by EvictLeader and ReplaceDisk
+	// +optional
+	PodName string `json:"podName,omitempty"`
+
+	// Component is the component whose failover is recovered, one of tikv and tiflash,
+	// it's required by RecoverFailover
+	// +optional
+	Component MemberType `json:"component,omitempty"`
+
+	// ReplaceDisk is the options of the ReplaceDisk operation
+	// +optional
+	ReplaceDisk *ReplaceDiskOptions `json:"replaceDisk,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// ReplaceDiskOptions is the options of the ReplaceDisk operation
+type ReplaceDiskOptions struct {
+	// MoveOutOfNode indicates whether the Pod is recreated on another node, e.g. when the
+	// node is to be removed. The PVCs and the Pod are deleted only after the node is cordoned,
+	// and the operation fails if the new Pod is scheduled to the same node.
+	// +optional
+	MoveOutOfNode bool `json:"moveOutOfNode,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterOperationStatus represents the current status of an operation
+type TidbClusterOperationStatus struct {
+	// Phase is the phase of the operation
+	Phase TidbClusterOperationPhase `json:"phase,omitempty"`
+	// Message is the detail of the phase
+	Message string `json:"message,omitempty"`
+	// Progress is the progress of the running step, e.g. the number of regions left in
+	// the store replaced by the ReplaceDisk operation
+	Progress string `json:"progress,omitempty"`
+	// StartTime is the time the operation was started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the operation finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// PodUID is the UID of the target Pod when the operation started, it's used
+	// to find out whether the Pod is recreated
+	PodUID types.UID `json:"podUID,omitempty"`
+	// StoreID is the ID of the store replaced by the ReplaceDisk operation
+	StoreID string `json:"storeID,omitempty"`
+	// NodeName is the node of the target Pod when the operation started
+	NodeName string `json:"nodeName,omitempty"`
+	// Records is the audit trail of the operation, a record is appended for each step
+	// which changes the cluster
+	Records []TidbClusterOperationRecord `json:"records,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterOperationRecord is a step of an operation
+type TidbClusterOperationRecord struct {
+	// Time is the time the step was taken
+	Time metav1.Time `json:"time"`
+	// Phase is the phase of the operation after the step
+	Phase TidbClusterOperationPhase `json:"phase"`
+	// Message describes the step
+	Message string `json:"message,omitempty"`
+}
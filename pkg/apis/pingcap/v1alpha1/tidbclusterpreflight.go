@@ -0,0 +1,69 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	defaultPreflightMaxClockError     = 500 * time.Millisecond
+	defaultPreflightMaxNetworkLatency = 2 * time.Millisecond
+	defaultPreflightTimeout           = 10 * time.Minute
+	defaultPreflightMaxWriteLatency   = 10 * time.Millisecond
+)
+
+var defaultPreflightStorageSize = resource.MustParse("10Gi")
+
+// MaxClockError returns the max estimated error of the clock of a node
+func (pf *TidbClusterPreflight) MaxClockError() time.Duration {
+	if pf.Spec.MaxClockError != nil {
+		return pf.Spec.MaxClockError.Duration
+	}
+	return defaultPreflightMaxClockError
+}
+
+// MaxNetworkLatency returns the max average round-trip time between two nodes
+func (pf *TidbClusterPreflight) MaxNetworkLatency() time.Duration {
+	if pf.Spec.MaxNetworkLatency != nil {
+		return pf.Spec.MaxNetworkLatency.Duration
+	}
+	return defaultPreflightMaxNetworkLatency
+}
+
+// Timeout returns the max time the checks take
+func (pf *TidbClusterPreflight) Timeout() time.Duration {
+	if pf.Spec.Timeout != nil {
+		return pf.Spec.Timeout.Duration
+	}
+	return defaultPreflightTimeout
+}
+
+// StorageSize returns the size of the volume benchmarked
+func (pf *TidbClusterPreflight) StorageSize() resource.Quantity {
+	if pf.Spec.Storage != nil && pf.Spec.Storage.Size != nil {
+		return *pf.Spec.Storage.Size
+	}
+	return defaultPreflightStorageSize
+}
+
+// MaxWriteLatency returns the max average latency of the writes to the volume benchmarked
+func (pf *TidbClusterPreflight) MaxWriteLatency() time.Duration {
+	if pf.Spec.Storage != nil && pf.Spec.Storage.MaxWriteLatency != nil {
+		return pf.Spec.Storage.MaxWriteLatency.Duration
+	}
+	return defaultPreflightMaxWriteLatency
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TidbClusterPreflight checks the environment before a TiDB cluster is deployed,
+// which catches the common root causes of the issues found after the deployment.
+// A Job is run on each selected node to check the kernel parameters, the clock
+// synchronization, the MTU and the network latency to the other selected nodes,
+// and a Job benchmarks the write latency of a volume of the storage class if the
+// storage check is set. The results are stamped into status.report, and the
+// checks are never run again, create another TidbClusterPreflight to rerun them.
+//
+// +genclient
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName="tcpf"
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="The current phase of the checks"
+// +kubebuilder:printcolumn:name="Result",type=string,JSONPath=`.status.result`,description="The worst result of the checks"
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,description="The detail of the phase",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type TidbClusterPreflight struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the checks to run
+	Spec TidbClusterPreflightSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the checks
+	Status TidbClusterPreflightStatus `json:"status,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TidbClusterPreflightList is TidbClusterPreflight list
+type TidbClusterPreflightList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TidbClusterPreflight `json:"items"`
+}
+
+// PreflightPhase is the phase of a TidbClusterPreflight
+type PreflightPhase string
+
+const (
+	// PreflightPhaseRunning means the check Jobs are running
+	PreflightPhaseRunning PreflightPhase = "Running"
+	// PreflightPhaseComplete means all the checks are done, see the result for whether they pass
+	PreflightPhaseComplete PreflightPhase = "Complete"
+	// PreflightPhaseFailed means the checks can't be done, e.g. the check Jobs don't finish in time
+	PreflightPhaseFailed PreflightPhase = "Failed"
+)
+
+// PreflightResult is the result of a check
+type PreflightResult string
+
+const (
+	// PreflightResultPassed means the environment is as recommended
+	PreflightResultPassed PreflightResult = "Passed"
+	// PreflightResultWarning means the environment works but is not as recommended,
+	// e.g. the kernel parameters are not tuned
+	PreflightResultWarning PreflightResult = "Warning"
+	// PreflightResultFailed means the environment is likely to break the cluster,
+	// e.g. the clock is not synchronized
+	PreflightResultFailed PreflightResult = "Failed"
+)
+
+// +k8s:openapi-gen=true
+// TidbClusterPreflightSpec describes the attributes of a TidbClusterPreflight
+type TidbClusterPreflightSpec struct {
+	// Image is the image of the check Jobs, which must have sh, awk, ping, adjtimex,
+	// and fio if the storage is checked
+	Image string `json:"image"`
+
+	// ImagePullSecrets are the secrets to pull the image
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// NodeSelector selects the nodes to check, all the schedulable nodes are checked if it's empty.
+	// It requires the permission of reading nodes.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the check Jobs, e.g. to check the nodes dedicated to the cluster
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Storage benchmarks the write latency of a volume, the storage is not checked if it's not set
+	// +optional
+	Storage *PreflightStorageCheck `json:"storage,omitempty"`
+
+	// MaxClockError is the max estimated error of the clock of a node.
+	// Defaults to 500ms
+	// +optional
+	MaxClockError *metav1.Duration `json:"maxClockError,omitempty"`
+
+	// MaxNetworkLatency is the max average round-trip time between two nodes.
+	// Defaults to 2ms
+	// +optional
+	MaxNetworkLatency *metav1.Duration `json:"maxNetworkLatency,omitempty"`
+
+	// Timeout is the max time the checks take, the checks not done in time fail.
+	// Defaults to 10m
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// PreflightStorageCheck is the benchmark of the synchronous 4k random writes to a volume
+type PreflightStorageCheck struct {
+	// StorageClassName is the storage class of the volume, the default storage class is used if it's not set
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size is the size of the volume.
+	// Defaults to 10Gi
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// MaxWriteLatency is the max average latency of the writes.
+	// Defaults to 10ms
+	// +optional
+	MaxWriteLatency *metav1.Duration `json:"maxWriteLatency,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterPreflightStatus represents the current status of a TidbClusterPreflight
+type TidbClusterPreflightStatus struct {
+	// Phase is the phase of the checks
+	Phase PreflightPhase `json:"phase,omitempty"`
+	// Result is the worst result of the checks, it's set when the checks are finished
+	Result PreflightResult `json:"result,omitempty"`
+	// Message is the detail of the phase
+	Message string `json:"message,omitempty"`
+	// Nodes are the names of the nodes checked
+	Nodes []string `json:"nodes,omitempty"`
+	// StartTime is the time the checks started
+	// +nullable
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the checks finished
+	// +nullable
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Report is the results of the checks
+	Report []PreflightCheckResult `json:"report,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// PreflightCheckResult is the result of a check on a node or the storage
+type PreflightCheckResult struct {
+	// Check is the name of the check, e.g. sysctl.vm.swappiness, clock, mtu, latency or storage
+	Check string `json:"check"`
+	// Node is the node checked, it's empty for the storage
+	// +optional
+	Node string `json:"node,omitempty"`
+	// Result is the result of the check
+	Result PreflightResult `json:"result"`
+	// Value is the observed value
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Message explains the result if it's not passed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
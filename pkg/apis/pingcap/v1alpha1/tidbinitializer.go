@@ -13,6 +13,12 @@
 
 package v1alpha1
 
+import "time"
+
+const (
+	defaultSettingsSyncInterval = 10 * time.Minute
+)
+
 // GetPermitHost retrieves the permit host from TidbInitializer
 func (ti *TidbInitializer) GetPermitHost() string {
 	var permitHost string
@@ -23,3 +29,19 @@ func (ti *TidbInitializer) GetPermitHost() string {
 	}
 	return permitHost
 }
+
+// SettingsSyncInterval returns the interval to check the global variables and the cluster configs
+func (ti *TidbInitializer) SettingsSyncInterval() time.Duration {
+	if ti.Spec.SettingsSyncInterval != nil {
+		d, err := time.ParseDuration(*ti.Spec.SettingsSyncInterval)
+		if err == nil {
+			return d
+		}
+	}
+	return defaultSettingsSyncInterval
+}
+
+// HasSettings returns whether any global variable or cluster config is specified
+func (ti *TidbInitializer) HasSettings() bool {
+	return len(ti.Spec.GlobalVariables) > 0 || len(ti.Spec.ClusterConfigs) > 0
+}
@@ -99,6 +99,36 @@ type TidbInitializerSpec struct {
 	// Optional: Defaults to nil
 	// +optional
 	TLSClientSecretName *string `json:"tlsClientSecretName,omitempty"`
+
+	// GlobalVariables are the global system variables set by `SET GLOBAL` after the TiDB cluster
+	// is initialized, keyed by the variable name.
+	// The variables are set again if they drift from the desired values.
+	// +optional
+	GlobalVariables map[string]string `json:"globalVariables,omitempty"`
+
+	// ClusterConfigs are the online configs of the components set by `SET CONFIG` after the TiDB
+	// cluster is initialized, they are only supported by TiDB v4.0.0 and later versions.
+	// The configs are set again if they drift from the desired values.
+	// +optional
+	ClusterConfigs []ClusterConfigItem `json:"clusterConfigs,omitempty"`
+
+	// SettingsSyncInterval is the interval to check whether the global variables and the cluster
+	// configs drift from the desired values, in the format of Go Duration.
+	// Defaults to 10m
+	// +optional
+	SettingsSyncInterval *string `json:"settingsSyncInterval,omitempty"`
+}
+
+// ClusterConfigItem is an online config of a component of the TiDB cluster
+// +k8s:openapi-gen=true
+type ClusterConfigItem struct {
+	// Type is the component type of the config
+	// +kubebuilder:validation:Enum=pd;tikv;tidb;tiflash
+	Type string `json:"type"`
+	// Name is the name of the config, e.g. `split.qps-threshold`
+	Name string `json:"name"`
+	// Value is the value of the config
+	Value string `json:"value"`
 }
 
 // +k8s:openapi-gen=true
@@ -107,6 +137,23 @@ type TidbInitializerStatus struct {
 
 	// Phase is a user readable state inferred from the underlying Job status and TidbCluster status
 	Phase InitializePhase `json:"phase,omitempty"`
+
+	// Settings is the status of the global variables and the cluster configs
+	// +optional
+	Settings *InitializerSettingsStatus `json:"settings,omitempty"`
+}
+
+// InitializerSettingsStatus is the status of the global variables and the cluster configs
+// +k8s:openapi-gen=true
+type InitializerSettingsStatus struct {
+	// LastSyncTime is the last time the settings are checked
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+	// Corrected are the settings set again in the last sync as they drifted from the desired values
+	// +optional
+	Corrected []string `json:"corrected,omitempty"`
+	// Message is the error of the last sync
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
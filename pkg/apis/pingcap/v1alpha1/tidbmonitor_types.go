@@ -185,6 +185,11 @@ type TidbMonitorSpec struct {
 	// Optional: Defaults to UTC
 	// +optional
 	Timezone string `json:"timezone,omitempty"`
+
+	// ImagePolicy resolves the images of the TidbMonitor Pod by the registry mirrors and digests,
+	// the tag suffixes and the digest pinning don't apply to the images of the monitoring components.
+	// +optional
+	ImagePolicy *ImagePolicy `json:"imagePolicy,omitempty"`
 }
 
 // PrometheusReloaderSpec is the desired state of prometheus configuration reloader
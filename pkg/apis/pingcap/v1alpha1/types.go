@@ -77,6 +77,8 @@ const (
 	TiCDCMemberType MemberType = "ticdc"
 	// PumpMemberType is pump member type
 	PumpMemberType MemberType = "pump"
+	// TiProxyMemberType is tiproxy member type
+	TiProxyMemberType MemberType = "tiproxy"
 
 	// DMDiscoveryMemberType is discovery member type
 	DMDiscoveryMemberType MemberType = "dm-discovery"
@@ -109,6 +111,33 @@ const (
 	SuspendPhase MemberPhase = "Suspend"
 )
 
+// OperationStep is a finished step of an in-flight operation on a pod
+type OperationStep string
+
+const (
+	// OperationStepMemberDeleted means the PD member of the pod being scaled in is deleted
+	OperationStepMemberDeleted OperationStep = "MemberDeleted"
+	// OperationStepStoreDeleted means the store of the pod being scaled in is deleted
+	OperationStepStoreDeleted OperationStep = "StoreDeleted"
+	// OperationStepLeaderEvictionBegun means the leaders are being evicted from the store of the pod being upgraded
+	OperationStepLeaderEvictionBegun OperationStep = "LeaderEvictionBegun"
+)
+
+// OperationCheckpoint records the last finished step of the in-flight upgrade or scale operation
+// of a component, so that the operation is resumed from the step instead of being redone after
+// tidb-controller-manager restarts, e.g. when it's upgraded.
+type OperationCheckpoint struct {
+	// Phase is the phase of the component the operation belongs to, Upgrade or Scale
+	Phase MemberPhase `json:"phase"`
+	// Ordinal is the ordinal of the pod being operated
+	Ordinal int32 `json:"ordinal"`
+	// Step is the last finished step of the operation on the pod
+	Step OperationStep `json:"step"`
+	// LastTransitionTime is the time the step is finished
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
 // ConfigUpdateStrategy represents the strategy to update configuration
 type ConfigUpdateStrategy string
 
@@ -120,12 +149,26 @@ const (
 	ConfigUpdateStrategyRollingUpdate ConfigUpdateStrategy = "RollingUpdate"
 )
 
+// PVCDeletePolicy represents how to delete the PVCs of the scaled-in pods
+type PVCDeletePolicy string
+
+const (
+	// PVCDeletePolicyRetain retains the PVCs until the ordinal is scaled out again,
+	// or the PVs are reclaimed if enablePVReclaim is true
+	PVCDeletePolicyRetain PVCDeletePolicy = "Retain"
+	// PVCDeletePolicyDelete deletes the PVCs once the scaled-in pod is removed
+	PVCDeletePolicyDelete PVCDeletePolicy = "Delete"
+	// PVCDeletePolicyDeferWithTTL deletes the PVCs after pvcDeleteTTL since the pod is scaled in
+	PVCDeletePolicyDeferWithTTL PVCDeletePolicy = "DeferWithTTL"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // TidbCluster is the control script's spec
 //
 // +k8s:openapi-gen=true
+// +kubebuilder:storageversion
 // +kubebuilder:resource:shortName="tc"
 // +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="PD",type=string,JSONPath=`.status.pd.image`,description="The image for PD cluster"
@@ -200,6 +243,10 @@ type TidbClusterSpec struct {
 	// +optional
 	Pump *PumpSpec `json:"pump,omitempty"`
 
+	// TiProxy cluster spec, TiProxy pools the client connections in front of TiDB
+	// +optional
+	TiProxy *TiProxySpec `json:"tiproxy,omitempty"`
+
 	// Helper spec
 	// +optional
 	Helper *HelperSpec `json:"helper,omitempty"`
@@ -241,6 +288,16 @@ type TidbClusterSpec struct {
 	// +optional
 	EnablePVReclaim *bool `json:"enablePVReclaim,omitempty"`
 
+	// DeletionPolicy determines what is cleaned up before the TidbCluster is deleted.
+	// Purge removes the TiKV and TiFlash stores and the PD members of the cluster from a PD
+	// shared with other clusters, deletes the PVCs of the cluster, whose PVs are then handled by
+	// `pvReclaimPolicy`, and removes the cluster from the TidbMonitors monitoring other clusters too.
+	// The deletion is blocked until the stores are tombstone, set it back to Retain to skip it.
+	// Optional: Defaults to Retain
+	// +kubebuilder:validation:Enum=Retain;Purge
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Whether enable the TLS connection between TiDB server components
 	// Optional: Defaults to nil
 	// +optional
@@ -343,6 +400,757 @@ type TidbClusterSpec struct {
 	// SuspendAction defines the suspend actions for all component.
 	// +optional
 	SuspendAction *SuspendAction `json:"suspendAction,omitempty"`
+
+	// RolloutBudget limits the pod restarts caused by the changes of pod template,
+	// it protects the cluster from the rolling updates triggered repeatedly
+	// +optional
+	RolloutBudget *RolloutBudget `json:"rolloutBudget,omitempty"`
+
+	// DiskWatchdog watches the disk usage of TiKV and TiFlash stores and takes actions
+	// before the disks are full
+	// +optional
+	DiskWatchdog *DiskWatchdog `json:"diskWatchdog,omitempty"`
+
+	// NetworkPolicy generates the NetworkPolicies to restrict the access to the cluster
+	// +optional
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+
+	// DeriveConfigFromResources indicates whether to derive the memory and CPU related config items of
+	// PD, TiKV and TiDB from the resources of their containers, the items explicitly set in `config`
+	// are never overridden. The derived items are reported in the `derivedConfig` of the component status.
+	// Note that it only takes effect for the components whose `config` is set, and changing it causes
+	// rolling updates of those components.
+	// Optional: Defaults to false
+	// +optional
+	DeriveConfigFromResources *bool `json:"deriveConfigFromResources,omitempty"`
+
+	// PodMonitor generates the PodMonitors of prometheus-operator for the components, so that
+	// the Prometheus deployed by prometheus-operator, e.g. kube-prometheus-stack, scrapes the
+	// metrics of the cluster without a TidbMonitor. It takes effect only if the CRDs of
+	// prometheus-operator are installed before tidb-controller-manager starts.
+	// +optional
+	PodMonitor *PodMonitorSpec `json:"podMonitor,omitempty"`
+
+	// ResourceReport reports the requested and used resources of PD, TiKV and TiDB in
+	// status.resourceReport and the metrics of tidb-controller-manager, as the basis of
+	// the chargeback and the right-sizing of the cluster. The usage is queried from the
+	// Prometheus of TidbMonitor.
+	// +optional
+	ResourceReport *ResourceReportSpec `json:"resourceReport,omitempty"`
+
+	// Recommendations computes the recommended replicas of TiDB and the storage headroom of
+	// TiKV in status.recommendations from the metrics in the Prometheus of TidbMonitor.
+	// The recommendations are advisory only, the cluster is never scaled by them, which
+	// helps to evaluate the scaling before enabling the autoscaling.
+	// +optional
+	Recommendations *RecommendationsSpec `json:"recommendations,omitempty"`
+
+	// ImagePolicy resolves the images of all the components, the discovery and the BR jobs of Backup
+	// and Restore before rendering them into the Pods, e.g. to pull the images from the mirrors in
+	// air-gapped environments, to use the FIPS or architecture-specific builds, or to pin the images
+	// by digests.
+	// If you set it for an existing cluster, the cluster will be rolling updated.
+	// +optional
+	ImagePolicy *ImagePolicy `json:"imagePolicy,omitempty"`
+
+	// TopologyProfile configures the cluster for a deployment topology automatically, including
+	// the location labels of PD, the zone labels of the stores, the placement rules and the zone
+	// labels of TiDB for the zone-local follower reads.
+	// If you set it for an existing cluster, the PD cluster will be rolling updated.
+	// +optional
+	TopologyProfile *TopologyProfile `json:"topologyProfile,omitempty"`
+
+	// ResourceQuota creates a ResourceQuota <cluster>-quota in the namespace of the cluster from
+	// the summed requests of the components, and the admission webhook refuses the scale-ups
+	// whose summed requests exceed the max, as a guardrail against the noisy-neighbor clusters.
+	// The other TidbClusters and the other workloads managed by tidb-operator in the namespace
+	// are counted in the quota as well.
+	// +optional
+	ResourceQuota *ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+
+	// OperatorPolicy overrides the timings of tidb-controller-manager for the cluster, e.g. a
+	// large cluster may need longer timeouts and a small cluster may be synced less often.
+	// +optional
+	OperatorPolicy *OperatorPolicy `json:"operatorPolicy,omitempty"`
+
+	// UpgradePolicy upgrades the cluster to the latest patch release of a release channel
+	// automatically in the maintenance windows, by changing spec.version.
+	// +optional
+	UpgradePolicy *UpgradePolicy `json:"upgradePolicy,omitempty"`
+
+	// SQLProbe runs a Deployment which connects to TiDB, writes and reads a health table
+	// periodically and exports the latency and errors as metrics, the SQLProbeHealthy
+	// condition is set by the result of the probes.
+	// +optional
+	SQLProbe *SQLProbeSpec `json:"sqlProbe,omitempty"`
+
+	// EventThrottling lowers the IO rate limits of TiKV and the schedule limits of PD while
+	// the operator performs the actions moving lots of data between the stores, e.g. the
+	// scaling and the failover of TiKV, so that the foreground traffic is less affected.
+	// +optional
+	EventThrottling *EventThrottlingPolicy `json:"eventThrottling,omitempty"`
+
+	// TopologyExport publishes a discovery document of the cluster in a ConfigMap, which lists
+	// the endpoints of all the components and their TLS requirements, so that the external
+	// tools, e.g. backup systems, proxies and DNS automation, can read the topology of the
+	// cluster without knowing the naming conventions of the operator.
+	// +optional
+	TopologyExport *TopologyExportSpec `json:"topologyExport,omitempty"`
+
+	// DNS publishes stable DNS names of the TiDB Service and the PD client Service by external-dns,
+	// the names are rendered from the cluster name and namespace, so they are kept when the
+	// cluster is recreated.
+	// +optional
+	DNS *DNSSpec `json:"dns,omitempty"`
+
+	// PersistentIdentity keeps the identities of the cluster in a Secret which is not owned by
+	// the TidbCluster, so that the TidbCluster deleted and recreated with the PVCs retained
+	// rejoins the existing data without running pd-recover manually.
+	// +optional
+	PersistentIdentity *PersistentIdentitySpec `json:"persistentIdentity,omitempty"`
+}
+
+// RolloutBudget limits the pod restarts caused by the rolling updates.
+// When the budget is exhausted, the changes of pod template are paused and the
+// RolloutStorm condition is raised, the changes are resumed after the storm is
+// acknowledged by the annotation tidb.pingcap.com/rollout-storm-ack, whose value
+// is shown in the message of the condition.
+//
+// +k8s:openapi-gen=true
+type RolloutBudget struct {
+	// MaxRestartsPerHour is the max number of pods restarted by rolling updates in an hour,
+	// each change of pod template counts the replicas of the component
+	// +kubebuilder:validation:Minimum=1
+	MaxRestartsPerHour int32 `json:"maxRestartsPerHour"`
+}
+
+// DiskWatchdog watches the disk usage of TiKV and TiFlash stores reported by PD.
+// When the usage of any store exceeds the threshold, the DiskPressure condition is
+// raised and a warning event is emitted, and the data volumes of the store are
+// expanded if AutoExpand is set.
+//
+// +k8s:openapi-gen=true
+type DiskWatchdog struct {
+	// UsageThresholdPercent is the percentage of the used disk capacity of a store,
+	// above which the store is considered to be under disk pressure
+	// Optional: Defaults to 85
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	UsageThresholdPercent *int32 `json:"usageThresholdPercent,omitempty"`
+
+	// AutoExpand expands the data volumes of the stores under disk pressure.
+	// The storage class of the volumes must support volume expansion.
+	// +optional
+	AutoExpand *DiskAutoExpand `json:"autoExpand,omitempty"`
+
+	// SoftLimit lowers the add-peer store limit in PD of the stores approaching their capacity,
+	// so that fewer regions are moved to them and the writes are not stalled abruptly when
+	// the disks are full.
+	// +optional
+	SoftLimit *StoreSoftLimit `json:"softLimit,omitempty"`
+}
+
+// StoreSoftLimit is the policy to lower the add-peer store limit of the stores approaching
+// their capacity. The original limit of a store is recorded in status.softLimitedStores and
+// restored when its disk usage drops below the threshold or the policy is removed.
+//
+// +k8s:openapi-gen=true
+type StoreSoftLimit struct {
+	// UsageThresholdPercent is the percentage of the used disk capacity of a store,
+	// above which the add-peer limit of the store is lowered. It should be lower than
+	// the usageThresholdPercent of the disk watchdog.
+	// Optional: Defaults to 75
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	UsageThresholdPercent *int32 `json:"usageThresholdPercent,omitempty"`
+
+	// AddPeerRate is the add-peer limit of the stores above the threshold, in the count of
+	// peers per minute. 0 stops adding peers to the stores.
+	// Optional: Defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	AddPeerRate *int32 `json:"addPeerRate,omitempty"`
+}
+
+// DiskAutoExpand is the policy to expand the data volumes of the stores under disk pressure
+//
+// +k8s:openapi-gen=true
+type DiskAutoExpand struct {
+	// StepPercent is the percentage of the current storage request added in each expansion,
+	// a volume is expanded again only after the previous expansion is finished
+	// Optional: Defaults to 20
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	StepPercent *int32 `json:"stepPercent,omitempty"`
+
+	// MaxStorage is the upper limit of the storage request of a data volume
+	MaxStorage resource.Quantity `json:"maxStorage"`
+}
+
+// NetworkPolicySpec describes the NetworkPolicies generated for the tidb cluster.
+// All ports of the components are only accessible from the pods of the cluster and
+// PeerFrom, except that the MySQL port of TiDB is also accessible from SQLFrom.
+//
+// +k8s:openapi-gen=true
+type NetworkPolicySpec struct {
+	// SQLFrom are the sources allowed to access the MySQL port of TiDB,
+	// the port is accessible from any source if it's empty
+	// +optional
+	SQLFrom []networkingv1.NetworkPolicyPeer `json:"sqlFrom,omitempty"`
+
+	// PeerFrom are the sources outside of the cluster allowed to access all the ports
+	// of the cluster, e.g. TidbMonitor, the backup jobs and the heterogeneous clusters
+	// +optional
+	PeerFrom []networkingv1.NetworkPolicyPeer `json:"peerFrom,omitempty"`
+}
+
+// PodMonitorSpec describes the PodMonitors generated for the tidb cluster, one for each component
+// named `<cluster>-<component>`. The prometheus annotations of the pods are kept.
+//
+// +k8s:openapi-gen=true
+type PodMonitorSpec struct {
+	// Labels are added to the PodMonitors, e.g. `release: <release>` to be selected by the
+	// podMonitorSelector of the Prometheus deployed by kube-prometheus-stack
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Interval is the interval to scrape the metrics, e.g. 30s.
+	// Defaults to the global scrape interval of the Prometheus
+	// +optional
+	Interval string `json:"interval,omitempty"`
+}
+
+// ResourceReportSpec describes where and how the resource usage of the cluster is queried.
+// The metrics are expected to be labeled like TidbMonitor does, i.e. with the labels
+// `kubernetes_namespace`, `cluster` and `component`.
+//
+// +k8s:openapi-gen=true
+type ResourceReportSpec struct {
+	// MonitorName is the name of the TidbMonitor in the namespace of the cluster, whose
+	// Prometheus is queried for the resource usage
+	// +optional
+	MonitorName string `json:"monitorName,omitempty"`
+
+	// PrometheusURL is the URL of the Prometheus to query, e.g. http://prometheus.monitoring:9090.
+	// It takes precedence over MonitorName.
+	// +optional
+	PrometheusURL string `json:"prometheusURL,omitempty"`
+
+	// Window is the period the usage is calculated in, in the format of Prometheus duration.
+	// Defaults to 1h
+	// +optional
+	Window string `json:"window,omitempty"`
+
+	// Interval is the interval between two reports, in the format of Go Duration.
+	// Defaults to 10m
+	// +optional
+	Interval *string `json:"interval,omitempty"`
+}
+
+// RightSizingSuggestion is the suggestion of resizing a component according to its usage
+type RightSizingSuggestion string
+
+const (
+	// RightSizingOversized means the usage of both CPU and memory is low, the component
+	// can be downsized
+	RightSizingOversized RightSizingSuggestion = "Oversized"
+	// RightSizingUndersized means the usage of CPU, memory or storage is close to the
+	// requests, the component should be upsized
+	RightSizingUndersized RightSizingSuggestion = "Undersized"
+	// RightSizingFit means the requests of the component fit its usage
+	RightSizingFit RightSizingSuggestion = "Fit"
+)
+
+// ResourceReport is the requested and used resources of the components
+type ResourceReport struct {
+	// LastReportTime is the time of the last report
+	// +nullable
+	LastReportTime metav1.Time `json:"lastReportTime,omitempty"`
+	// Window is the period the usage is calculated in
+	Window string `json:"window,omitempty"`
+	// Components are the reports of the components, keyed by the component name
+	Components map[MemberType]ComponentResourceReport `json:"components,omitempty"`
+	// Message is the error of the last report if it fails
+	Message string `json:"message,omitempty"`
+}
+
+// ComponentResourceReport is the requested and used resources of a component, the
+// resources are the sum of all pods of the component
+type ComponentResourceReport struct {
+	// Replicas is the number of pods of the component
+	Replicas int32 `json:"replicas"`
+	// Requested are the resources requested by the containers of the component, and
+	// the storage requested by the data volumes
+	Requested corev1.ResourceList `json:"requested,omitempty"`
+	// Used are the CPU used on average and the peak memory in the window, and the
+	// current storage used
+	Used corev1.ResourceList `json:"used,omitempty"`
+	// Suggestion is the right-sizing suggestion, it's empty if the requests or the
+	// usage is unknown
+	Suggestion RightSizingSuggestion `json:"suggestion,omitempty"`
+}
+
+// RecommendationsSpec describes where the metrics are queried and the targets the
+// recommendations are computed for. The metrics are expected to be labeled like
+// TidbMonitor does, the same as ResourceReportSpec.
+//
+// +k8s:openapi-gen=true
+type RecommendationsSpec struct {
+	// MonitorName is the name of the TidbMonitor in the namespace of the cluster, whose
+	// Prometheus is queried for the metrics
+	// +optional
+	MonitorName string `json:"monitorName,omitempty"`
+
+	// PrometheusURL is the URL of the Prometheus to query, e.g. http://prometheus.monitoring:9090.
+	// It takes precedence over MonitorName.
+	// +optional
+	PrometheusURL string `json:"prometheusURL,omitempty"`
+
+	// Window is the period the CPU usage is averaged in, in the format of Prometheus duration.
+	// Defaults to 1h
+	// +optional
+	Window string `json:"window,omitempty"`
+
+	// Interval is the interval between two computations, in the format of Go Duration.
+	// Defaults to 10m
+	// +optional
+	Interval *string `json:"interval,omitempty"`
+
+	// TiDBTargetCPUUtilization is the percentage of the CPU quota of TiDB expected to be used
+	// on average, the recommended replicas of TiDB keep the utilization around it.
+	// Defaults to 60
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	TiDBTargetCPUUtilization *int32 `json:"tidbTargetCPUUtilization,omitempty"`
+
+	// TiKVMinStorageHeadroom is the percentage of the storage capacity of TiKV expected to be
+	// available, more TiKV replicas are recommended once the headroom is below it.
+	// Defaults to 20
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	TiKVMinStorageHeadroom *int32 `json:"tikvMinStorageHeadroom,omitempty"`
+}
+
+// Recommendations are the advisory scaling data computed from the metrics
+type Recommendations struct {
+	// LastUpdateTime is the time the recommendations were computed
+	// +nullable
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Window is the period the CPU usage is averaged in
+	Window string `json:"window,omitempty"`
+	// TiDB is the recommended replicas of TiDB, it's nil if the metrics are unknown
+	TiDB *TiDBReplicasRecommendation `json:"tidb,omitempty"`
+	// TiKV is the storage headroom and the recommended replicas of TiKV, it's nil if the
+	// metrics are unknown
+	TiKV *TiKVStorageRecommendation `json:"tikv,omitempty"`
+	// Message is the error of the last computation if it fails
+	Message string `json:"message,omitempty"`
+}
+
+// TiDBReplicasRecommendation is the recommended replicas of TiDB by the CPU usage
+type TiDBReplicasRecommendation struct {
+	// CurrentReplicas is the replicas of TiDB when the recommendation was computed
+	CurrentReplicas int32 `json:"currentReplicas"`
+	// RecommendedReplicas is the replicas to keep the CPU utilization around the target
+	RecommendedReplicas int32 `json:"recommendedReplicas"`
+	// CPUUtilization is the percentage of the CPU quota used on average in the window
+	CPUUtilization int32 `json:"cpuUtilization"`
+}
+
+// TiKVStorageRecommendation is the storage headroom of TiKV and the recommended replicas to keep it
+type TiKVStorageRecommendation struct {
+	// CurrentReplicas is the replicas of TiKV when the recommendation was computed
+	CurrentReplicas int32 `json:"currentReplicas"`
+	// RecommendedReplicas is the replicas to keep the headroom above the minimum, it's never
+	// less than the current replicas
+	RecommendedReplicas int32 `json:"recommendedReplicas"`
+	// Capacity is the storage capacity of all the stores
+	Capacity resource.Quantity `json:"capacity"`
+	// Available is the storage available in all the stores
+	Available resource.Quantity `json:"available"`
+	// Headroom is the percentage of the available storage in the capacity
+	Headroom int32 `json:"headroom"`
+}
+
+// ImagePolicy is how the images of the components are resolved. The tag suffixes are appended
+// first, then the registry is replaced by the mirror and the image is pinned by the digest.
+// The mirrors and the digests also apply to the helper, init and additional containers, while
+// the tag suffixes only apply to the main container of each component.
+//
+// +k8s:openapi-gen=true
+type ImagePolicy struct {
+	// RegistryMirrors maps the registries or the repository prefixes to the mirrors the images
+	// are pulled from, e.g. {"docker.io": "registry.example.com/dockerhub"}. The longest matching
+	// prefix is used, and the images without a registry are from docker.io.
+	// +optional
+	RegistryMirrors map[string]string `json:"registryMirrors,omitempty"`
+
+	// TagSuffix is appended to the tags of the images of the components, e.g. -fips.
+	// It's not appended again if the tag already ends with it.
+	// +optional
+	TagSuffix string `json:"tagSuffix,omitempty"`
+
+	// ArchTagSuffixes maps the architectures to the suffixes appended to the tags of the images
+	// of the components after TagSuffix, e.g. {"arm64": "-arm64"}. The architecture of a component
+	// is the value of the kubernetes.io/arch label in its nodeSelector.
+	// +optional
+	ArchTagSuffixes map[string]string `json:"archTagSuffixes,omitempty"`
+
+	// Digests maps the images, after the suffixes and mirrors are applied, to the digests they
+	// are pinned to, e.g. {"registry.example.com/dockerhub/pingcap/pd:v6.1.0": "sha256:..."}.
+	// +optional
+	Digests map[string]string `json:"digests,omitempty"`
+
+	// DigestPinning is the policy of pinning the images by digests. Enforced rejects the images
+	// of the components which are not pinned by digests, either in the images or by Digests, and
+	// the ones with floating tags like latest, whose versions can't be told from the tags.
+	// +kubebuilder:validation:Enum="";Enforced
+	// +optional
+	DigestPinning DigestPinningPolicy `json:"digestPinning,omitempty"`
+}
+
+type DigestPinningPolicy string
+
+const (
+	// DigestPinningEnforced rejects the images not pinned by digests
+	DigestPinningEnforced DigestPinningPolicy = "Enforced"
+)
+
+// TopologyProfileType is the type of the deployment topology
+type TopologyProfileType string
+
+const (
+	// TopologyProfileThreeDC deploys the cluster across three data centers, which are the zones
+	// of the nodes
+	TopologyProfileThreeDC TopologyProfileType = "three-dc"
+)
+
+// ThreeDCScheme is how the replicas of each region are placed in the three data centers
+type ThreeDCScheme string
+
+const (
+	// ThreeDCScheme221 places five voters, two in the first zone, two in the second zone and a
+	// follower in the third zone which never becomes the leader
+	ThreeDCScheme221 ThreeDCScheme = "2-2-1"
+	// ThreeDCScheme32 places three voters, one in each zone with the follower in the third zone
+	// never becoming the leader, and two learners in the first and the second zones serving the
+	// follower reads
+	ThreeDCScheme32 ThreeDCScheme = "3-2"
+)
+
+// TopologyProfile is the deployment topology of the cluster. For the three-dc profile, PD is
+// configured with the location labels `zone` and `host`, the stores are labeled with the zones
+// of their nodes, and the placement rules of the scheme are maintained by the operator in the
+// rule group `tidb-operator-topology`, the leaders are placed in the first zone. The TiDB Pods
+// are labeled with the zones of their nodes, and the zone labels are set to TiDB servers,
+// so that the reads are served by the replicas in the same zone once the global variable
+// tidb_replica_read is set to closest-replicas. The permission of nodes is required.
+//
+// +k8s:openapi-gen=true
+type TopologyProfile struct {
+	// Type is the type of the topology
+	// +kubebuilder:validation:Enum=three-dc
+	Type TopologyProfileType `json:"type"`
+
+	// Zones are the values of the topology.kubernetes.io/zone label of the nodes in the data
+	// centers, three zones are required for three-dc. The leaders are placed in the first zone.
+	Zones []string `json:"zones"`
+
+	// Scheme is how the replicas are placed in the zones.
+	// Defaults to 2-2-1
+	// +kubebuilder:validation:Enum="";2-2-1;3-2
+	// +optional
+	Scheme ThreeDCScheme `json:"scheme,omitempty"`
+}
+
+// OperatorPolicy is the timings of tidb-controller-manager overridden for the cluster
+//
+// +k8s:openapi-gen=true
+type OperatorPolicy struct {
+	// ResyncPeriod is the period the cluster is synced at least once. The cluster is still
+	// synced on the changes of itself and its StatefulSets.
+	// Defaults to the resync-duration of tidb-controller-manager
+	// +optional
+	ResyncPeriod *metav1.Duration `json:"resyncPeriod,omitempty"`
+
+	// PDRequestTimeout is the timeout of the requests to PD.
+	// Defaults to 5s
+	// +optional
+	PDRequestTimeout *metav1.Duration `json:"pdRequestTimeout,omitempty"`
+
+	// UpgradeWaitTimeout is the max time the upgrade waits for the region leaders to be evicted
+	// from a TiKV store before restarting it, spec.tikv.evictLeaderTimeout takes precedence.
+	// Defaults to 1500m
+	// +optional
+	UpgradeWaitTimeout *metav1.Duration `json:"upgradeWaitTimeout,omitempty"`
+}
+
+// UpgradePolicy upgrades the cluster to the latest release of the channel. The releases of the
+// channels are read from the release metadata, a JSON document like
+// {"channels": {"6.5-lts": ["v6.5.0", "v6.5.1"]}}.
+// Before upgrading, the cluster must be ready, all the components must be normal and use
+// spec.version, i.e. they have the baseImage and no version of their own. After spec.version
+// is changed, the upgrade is complete once all the components are rolled and the cluster is
+// ready again, otherwise it fails after the post-check timeout and the failed version is not
+// tried again. The cluster is never downgraded.
+//
+// +k8s:openapi-gen=true
+type UpgradePolicy struct {
+	// Channel is the release channel to follow, e.g. 6.5-lts
+	Channel string `json:"channel"`
+
+	// ReleaseMetadata is where the releases of the channels are read from
+	ReleaseMetadata ReleaseMetadataSource `json:"releaseMetadata"`
+
+	// MaintenanceWindow is when the upgrades may start, the upgrades may start at any time if
+	// it's not set. The release metadata is checked every 10 minutes, so the windows should be
+	// longer than that. An upgrade started in the window is not interrupted when the window ends.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// PostCheckTimeout is the max time the cluster takes to be upgraded and ready again.
+	// Defaults to 1h
+	// +optional
+	PostCheckTimeout *metav1.Duration `json:"postCheckTimeout,omitempty"`
+}
+
+// ReleaseMetadataSource is the source of the release metadata, only one of them can be set
+//
+// +k8s:openapi-gen=true
+type ReleaseMetadataSource struct {
+	// URL is the HTTPS URL of the release metadata, its host must be in the
+	// release-metadata-hosts flag of the operator
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// ConfigMap is the key of a ConfigMap in the namespace of the cluster which holds the
+	// release metadata, e.g. in air-gapped environments
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+}
+
+// MaintenanceWindow is the period repeated by a cron schedule
+//
+// +k8s:openapi-gen=true
+type MaintenanceWindow struct {
+	// Schedule is the start of the windows in the cron format, e.g. "0 2 * * 6" for 2 AM every Saturday
+	Schedule string `json:"schedule"`
+
+	// Duration is the length of each window, e.g. 4h
+	Duration metav1.Duration `json:"duration"`
+}
+
+// SQLProbeSpec is the synthetic workload probing TiDB, which catches the breakage of the
+// data plane that the readiness of Pods misses, e.g. TiKV losing the quorum of regions.
+// Every interval the probe opens a new connection to the TiDB service, writes a row to
+// the health table and reads it back. The probe is unhealthy once the failures in a row
+// reach the failure threshold, and healthy again after a successful probe.
+//
+// The metrics are served on port 10263 of the probe Pod at /metrics:
+// tidb_sql_probe_duration_seconds, tidb_sql_probe_failures_total and tidb_sql_probe_healthy.
+//
+// +k8s:openapi-gen=true
+type SQLProbeSpec struct {
+	corev1.ResourceRequirements `json:",inline"`
+
+	// Interval is the time between two probes.
+	// Defaults to 10s
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Timeout is the max time each step of a probe takes.
+	// Defaults to 5s
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// FailureThreshold is the number of failed probes in a row to be unhealthy.
+	// Defaults to 3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// Database is the database of the health table, it's created if it doesn't exist.
+	// It must be an identifier of letters, digits, _ and $. Defaults to tidb_operator
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// PasswordSecret is the name of the Secret which holds the `user` and `password` to connect
+	// to TiDB, the user needs the privileges of creating and writing the health table.
+	// Defaults to root without password
+	// +optional
+	PasswordSecret *string `json:"passwordSecret,omitempty"`
+}
+
+// TopologyExportSpec is the ConfigMap holding the discovery document of the cluster.
+// The document is stored as JSON in the topology.json key, see pkg/topology for its
+// schema, and is updated whenever the components or their replicas change.
+//
+// +k8s:openapi-gen=true
+type TopologyExportSpec struct {
+	// ConfigMapName is the name of the ConfigMap.
+	// Defaults to ${cluster_name}-topology
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Labels are the additional labels of the ConfigMap, e.g. to be selected by the tools
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// DNSMode is how the DNS names are published to external-dns
+type DNSMode string
+
+const (
+	// DNSModeAnnotation sets the external-dns.alpha.kubernetes.io/hostname annotation of the Services
+	DNSModeAnnotation DNSMode = "Annotation"
+	// DNSModeDNSEndpoint creates a DNSEndpoint of external-dns for each Service, the addresses of the
+	// load balancer or the cluster IP of the Service are the targets
+	DNSModeDNSEndpoint DNSMode = "DNSEndpoint"
+)
+
+// DNSSpec is the DNS names of the Services published by external-dns.
+//
+// +k8s:openapi-gen=true
+type DNSSpec struct {
+	// DomainTemplate is the Go template of the DNS names, which is rendered with .Cluster, .Namespace
+	// and .Component (tidb or pd), e.g. {{ .Component }}.{{ .Cluster }}.{{ .Namespace }}.db.example.com
+	DomainTemplate string `json:"domainTemplate"`
+
+	// Mode is how the names are published.
+	// Defaults to Annotation
+	// +kubebuilder:validation:Enum:="";"Annotation";"DNSEndpoint"
+	// +optional
+	Mode DNSMode `json:"mode,omitempty"`
+
+	// Components are the components whose Services are published, tidb and pd are supported.
+	// Defaults to both of them
+	// +optional
+	Components []MemberType `json:"components,omitempty"`
+
+	// TTL is the TTL of the DNS records in seconds, the default TTL of the DNS provider is used if it's not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TTL *int64 `json:"ttl,omitempty"`
+}
+
+// PersistentIdentitySpec is the Secret keeping the identities of the cluster, i.e. the cluster ID,
+// the max ID allocated by PD, the member IDs of PD and the store IDs of TiKV and TiFlash.
+// The Secret is kept after the TidbCluster is deleted. If the TidbCluster is recreated with
+// the PVCs of TiKV retained but the data of PD lost, PD starts as a new cluster which the TiKV
+// stores can't join, then the recorded cluster ID and allocated ID are restored to PD the way
+// pd-recover does and the PD pods are restarted. TiKV is not synced until the recorded cluster
+// is restored, so that no new TiKV store bootstraps the new cluster first. If a TiKV store
+// without data, e.g. of a PVC not retained, has bootstrapped the new cluster anyway, the recorded
+// cluster isn't restored and the IdentityMismatch event is emitted, the new cluster has to be
+// deleted with its PD data to restore the recorded one. Delete the Secret before recreating the
+// TidbCluster if the cluster is expected to start from scratch.
+//
+// +k8s:openapi-gen=true
+type PersistentIdentitySpec struct {
+	// SecretName is the name of the Secret.
+	// Defaults to ${cluster_name}-identity
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// ThrottledEvent is an action of the operator which moves lots of data between TiKV stores
+type ThrottledEvent string
+
+const (
+	// ThrottledEventScale is the scaling of TiKV, the regions are rebalanced to or from the stores
+	ThrottledEventScale ThrottledEvent = "Scale"
+	// ThrottledEventFailover is the failover of TiKV, the regions of the failure stores are re-replicated
+	ThrottledEventFailover ThrottledEvent = "Failover"
+	// ThrottledEventRestore is a running Restore to the cluster
+	ThrottledEventRestore ThrottledEvent = "Restore"
+)
+
+// EventThrottlingPolicy is the limits applied while any of the events is in progress.
+// The original values of the limits are recorded in status.eventThrottling before they
+// are lowered, and restored after the cooldown period once all the events end or when
+// the policy is removed.
+//
+// +k8s:openapi-gen=true
+type EventThrottlingPolicy struct {
+	// Events are the events to throttle.
+	// Defaults to all of Scale, Failover and Restore
+	// +optional
+	Events []ThrottledEvent `json:"events,omitempty"`
+
+	// IORateLimit is set to storage.io-rate-limit.max-bytes-per-sec of the TiKV stores, e.g. 100MiB,
+	// which limits the IO of the background jobs like the compactions and the snapshots.
+	// It's not changed if it's empty
+	// +optional
+	IORateLimit string `json:"ioRateLimit,omitempty"`
+
+	// CompactionRateLimit is set to rocksdb.rate-bytes-per-sec of the TiKV stores, e.g. 50MiB.
+	// It's not changed if it's empty
+	// +optional
+	CompactionRateLimit string `json:"compactionRateLimit,omitempty"`
+
+	// RegionScheduleLimit is set to region-schedule-limit of PD.
+	// It's not changed if it's not set
+	// +optional
+	RegionScheduleLimit *uint64 `json:"regionScheduleLimit,omitempty"`
+
+	// ReplicaScheduleLimit is set to replica-schedule-limit of PD.
+	// It's not changed if it's not set
+	// +optional
+	ReplicaScheduleLimit *uint64 `json:"replicaScheduleLimit,omitempty"`
+
+	// CooldownPeriod is the time the limits are kept after all the events end, since the
+	// regions are still being balanced for a while, e.g. after TiKV is scaled out.
+	// Defaults to 10m
+	// +optional
+	CooldownPeriod *metav1.Duration `json:"cooldownPeriod,omitempty"`
+}
+
+// ResourceQuotaSpec is the ResourceQuota and LimitRange created for the cluster.
+//
+// The hard limits of requests.cpu, requests.memory and requests.storage in the ResourceQuota
+// are the summed requests of the Pods of all the components, including the failover Pods, the
+// sidecars and the init containers, plus the headroom, capped by the max. As a ResourceQuota
+// limits the whole namespace, the summed requests of the components of the other TidbClusters
+// and the current requests of the other Pods and PVCs managed by tidb-operator in the namespace,
+// e.g. the discovery, TidbMonitor and the Jobs of the backups, are added to the hard limits.
+// The workloads not managed by tidb-operator are expected to fit in the headroom. A resource
+// is not limited if none of the components requests it.
+//
+// +k8s:openapi-gen=true
+type ResourceQuotaSpec struct {
+	// Max is the maximum of the summed requests of the components, keyed by cpu, memory and
+	// storage. The updates making the summed requests exceed it are refused by the admission
+	// webhook. If they exceed it otherwise, e.g. after the failover, a warning event is emitted.
+	// +optional
+	Max corev1.ResourceList `json:"max,omitempty"`
+
+	// HeadroomPercent is the percentage of the summed requests added to the hard limits, for
+	// the Pods not counted, e.g. the Jobs being created and the workloads not managed by
+	// tidb-operator.
+	// Defaults to 10
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	HeadroomPercent *int32 `json:"headroomPercent,omitempty"`
+
+	// DefaultRequests are the default requests of the containers without requests set by the
+	// LimitRange <cluster>-limits, keyed by cpu and memory, as the Pods without the requests of
+	// a resource limited by the quota are rejected by Kubernetes.
+	// Defaults to 100m cpu and 128Mi memory
+	// +optional
+	DefaultRequests corev1.ResourceList `json:"defaultRequests,omitempty"`
+}
+
+// RolloutBudgetStatus is the usage of the rollout budget in the current window
+type RolloutBudgetStatus struct {
+	// WindowStart is the start time of the current window
+	// +nullable
+	WindowStart metav1.Time `json:"windowStart,omitempty"`
+	// Restarts is the number of pods restarted by rolling updates in the current window
+	Restarts int32 `json:"restarts"`
 }
 
 // TidbClusterStatus represents the current status of a tidb cluster.
@@ -354,11 +1162,230 @@ type TidbClusterStatus struct {
 	Pump       PumpStatus                `json:"pump,omitempty"`
 	TiFlash    TiFlashStatus             `json:"tiflash,omitempty"`
 	TiCDC      TiCDCStatus               `json:"ticdc,omitempty"`
+	TiProxy    TiProxyStatus             `json:"tiproxy,omitempty"`
 	AutoScaler *TidbClusterAutoScalerRef `json:"auto-scaler,omitempty"`
 	// Represents the latest available observations of a tidb cluster's state.
 	// +optional
 	// +nullable
 	Conditions []TidbClusterCondition `json:"conditions,omitempty"`
+	// DryRun records the changes computed by the last dry-run reconciliation
+	// +optional
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+	// SyncDiff records the objects changed by the last sync which applied any change,
+	// it helps to find out why a component keeps rolling
+	// +optional
+	SyncDiff *SyncDiffStatus `json:"syncDiff,omitempty"`
+	// RolloutBudget is the usage of spec.rolloutBudget
+	// +optional
+	RolloutBudget *RolloutBudgetStatus `json:"rolloutBudget,omitempty"`
+	// DeferDeletingPVCs are the PVCs of the scaled-in pods which are pending deletion
+	// +optional
+	DeferDeletingPVCs []DeferDeletingPVC `json:"deferDeletingPVCs,omitempty"`
+	// EffectiveSpec is the spec the operator acted on in the last sync, which is
+	// the spec after defaulting. It helps to find out what is changed by the
+	// defaulting of a new operator version. The configs derived by the operator
+	// are recorded in the derivedConfig of the components.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:XPreserveUnknownFields
+	// +optional
+	EffectiveSpec *TidbClusterSpec `json:"effectiveSpec,omitempty"`
+	// ResourceReport is the last report of spec.resourceReport
+	// +optional
+	ResourceReport *ResourceReport `json:"resourceReport,omitempty"`
+	// Recommendations are the last recommendations computed by spec.recommendations
+	// +optional
+	Recommendations *Recommendations `json:"recommendations,omitempty"`
+	// SoftLimitedStores are the stores whose add-peer limit is lowered by
+	// spec.diskWatchdog.softLimit, keyed by the store IDs
+	// +optional
+	SoftLimitedStores map[string]SoftLimitedStore `json:"softLimitedStores,omitempty"`
+	// PodTemplateChanges explain the last changes of the pod templates of the StatefulSets,
+	// which roll the pods
+	// +optional
+	PodTemplateChanges []PodTemplateChange `json:"podTemplateChanges,omitempty"`
+	// AutoUpgrade is the status of the upgrades by spec.upgradePolicy
+	// +optional
+	AutoUpgrade *AutoUpgradeStatus `json:"autoUpgrade,omitempty"`
+	// EventThrottling is the status of spec.eventThrottling, it's set while the limits are lowered
+	// +optional
+	EventThrottling *EventThrottlingStatus `json:"eventThrottling,omitempty"`
+	// TopologyExport is the status of the discovery document published by spec.topologyExport
+	// +optional
+	TopologyExport *TopologyExportStatus `json:"topologyExport,omitempty"`
+	// PersistentIdentity is the status of the identities kept by spec.persistentIdentity
+	// +optional
+	PersistentIdentity *PersistentIdentityStatus `json:"persistentIdentity,omitempty"`
+	// NetworkPolicies are the names of the NetworkPolicies generated for spec.networkPolicy,
+	// which are deleted after they are no longer desired
+	// +optional
+	NetworkPolicies []string `json:"networkPolicies,omitempty"`
+	// DNSEndpoints are the names of the DNSEndpoints of external-dns generated for spec.dns,
+	// which are deleted after they are no longer desired
+	// +optional
+	DNSEndpoints []string `json:"dnsEndpoints,omitempty"`
+}
+
+// PersistentIdentityStatus is the status of the identities of the cluster kept in a Secret
+type PersistentIdentityStatus struct {
+	// SecretName is the name of the Secret keeping the identities
+	SecretName string `json:"secretName"`
+	// ClusterID is the cluster ID recorded
+	// +optional
+	ClusterID string `json:"clusterID,omitempty"`
+	// LastUpdateTime is the last time the identities were recorded
+	// +nullable
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	// RecoveryTime is the last time the recorded identities were restored to PD
+	// +nullable
+	RecoveryTime *metav1.Time `json:"recoveryTime,omitempty"`
+}
+
+// TopologyExportStatus is the status of the discovery document of the cluster
+type TopologyExportStatus struct {
+	// ConfigMapName is the name of the ConfigMap holding the document
+	ConfigMapName string `json:"configMapName"`
+	// LastUpdateTime is the last time the document was changed
+	// +nullable
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// EventThrottlingStatus is the status of the limits lowered by spec.eventThrottling
+type EventThrottlingStatus struct {
+	// Events are the events in progress
+	// +optional
+	Events []ThrottledEvent `json:"events,omitempty"`
+	// StartTime is the time the limits were lowered
+	StartTime metav1.Time `json:"startTime"`
+	// LastEventTime is the last time any event was in progress, the limits are restored
+	// after the cooldown period since it
+	LastEventTime metav1.Time `json:"lastEventTime"`
+	// OriginalTiKVConfig are the values of the TiKV config items before they were lowered
+	// +optional
+	OriginalTiKVConfig map[string]string `json:"originalTiKVConfig,omitempty"`
+	// OriginalScheduleConfig are the values of the PD schedule config items before they were lowered
+	// +optional
+	OriginalScheduleConfig map[string]string `json:"originalScheduleConfig,omitempty"`
+}
+
+// AutoUpgradePhase is the phase of the upgrade by spec.upgradePolicy
+type AutoUpgradePhase string
+
+const (
+	// AutoUpgradeUpgrading means spec.version is changed and the cluster is being upgraded
+	AutoUpgradeUpgrading AutoUpgradePhase = "Upgrading"
+	// AutoUpgradeComplete means the last upgrade passes the post-checks
+	AutoUpgradeComplete AutoUpgradePhase = "Complete"
+	// AutoUpgradeFailed means the last upgrade fails the post-checks in time
+	AutoUpgradeFailed AutoUpgradePhase = "Failed"
+)
+
+// AutoUpgradeStatus is the status of the upgrades by spec.upgradePolicy
+type AutoUpgradeStatus struct {
+	// Phase is the phase of the last upgrade, it's empty if the cluster is never upgraded
+	// +optional
+	Phase AutoUpgradePhase `json:"phase,omitempty"`
+	// LatestVersion is the latest release of the channel found by the last check
+	// +optional
+	LatestVersion string `json:"latestVersion,omitempty"`
+	// LastCheckTime is the time the release metadata was read last time
+	// +nullable
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+	// FromVersion is the version before the last upgrade
+	// +optional
+	FromVersion string `json:"fromVersion,omitempty"`
+	// TargetVersion is the version of the last upgrade
+	// +optional
+	TargetVersion string `json:"targetVersion,omitempty"`
+	// StartTime is the time the last upgrade started
+	// +nullable
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the last upgrade completed or failed
+	// +nullable
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+	// Message explains why the cluster is not upgraded or why the last upgrade failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PodTemplateChange explains the last change of the pod template of a StatefulSet
+type PodTemplateChange struct {
+	// StatefulSet is the name of the StatefulSet
+	StatefulSet string `json:"statefulSet"`
+	// TemplateHash is the hash of the changed pod template
+	TemplateHash string `json:"templateHash"`
+	// ChangeTime is the time when the change is found
+	// +nullable
+	ChangeTime metav1.Time `json:"changeTime,omitempty"`
+	// Fields are the paths of the changed fields, e.g. spec.containers[tikv].resources.limits[memory]
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+}
+
+// SoftLimitedStore is a store whose add-peer limit is lowered by the disk watchdog
+type SoftLimitedStore struct {
+	PodName string `json:"podName"`
+	// OriginalAddPeerRate is the add-peer limit before it's lowered, which is restored later
+	OriginalAddPeerRate string `json:"originalAddPeerRate"`
+}
+
+// DeferDeletingPVC is a PVC of a scaled-in pod which is pending deletion
+type DeferDeletingPVC struct {
+	// Name of the PVC
+	Name string `json:"name"`
+	// Component the PVC belongs to
+	Component MemberType `json:"component"`
+	// DeferDeletingTime is the time the pod of the PVC is scaled in
+	// +nullable
+	DeferDeletingTime metav1.Time `json:"deferDeletingTime,omitempty"`
+	// DeleteTime is the time the PVC will be deleted after, it's empty if
+	// the PVC is retained according to the pvcDeletePolicy
+	// +optional
+	// +nullable
+	DeleteTime *metav1.Time `json:"deleteTime,omitempty"`
+}
+
+// DryRunStatus is the result of a dry-run reconciliation, in which the
+// changes are computed but not applied
+type DryRunStatus struct {
+	// LastReconcileTime is the time of the last dry-run reconciliation
+	// +nullable
+	LastReconcileTime metav1.Time `json:"lastReconcileTime,omitempty"`
+	// Changes are the changes that would be applied
+	// +optional
+	Changes []DryRunChange `json:"changes,omitempty"`
+}
+
+// DryRunChange is a change skipped by the dry-run reconciliation
+type DryRunChange struct {
+	// Kind of the changed object, e.g. StatefulSet, or PD for the requests to PD API
+	Kind string `json:"kind"`
+	// Name of the changed object
+	Name string `json:"name"`
+	// Action is one of Create, Update, Delete and the name of the PD API
+	Action string `json:"action"`
+}
+
+// SyncDiffStatus is the summary of the changes applied by a sync
+type SyncDiffStatus struct {
+	// LastChangeTime is the time when the changes are applied
+	// +nullable
+	LastChangeTime metav1.Time `json:"lastChangeTime,omitempty"`
+	// Objects are the objects changed by the sync
+	// +optional
+	Objects []ObjectDiff `json:"objects,omitempty"`
+}
+
+// ObjectDiff describes the difference between the desired and the actual object
+type ObjectDiff struct {
+	// Kind of the changed object, e.g. StatefulSet
+	Kind string `json:"kind"`
+	// Name of the changed object
+	Name string `json:"name"`
+	// Action is either Create or Update
+	Action string `json:"action"`
+	// Fields are the paths of the changed fields, e.g. spec.template.spec.containers[tikv].image
+	// +optional
+	Fields []string `json:"fields,omitempty"`
 }
 
 // TidbClusterCondition describes the state of a tidb cluster at a certain point.
@@ -394,6 +1421,30 @@ const (
 	// - All TiKV stores are up.
 	// - All TiFlash stores are up.
 	TidbClusterReady TidbClusterConditionType = "Ready"
+	// TidbClusterRolloutStorm indicates that the rollout budget is exhausted,
+	// the changes of pod template are paused until it's acknowledged.
+	TidbClusterRolloutStorm TidbClusterConditionType = "RolloutStorm"
+	// TidbClusterDiskPressure indicates that the disk usage of any TiKV or TiFlash store
+	// exceeds the threshold of spec.diskWatchdog.
+	TidbClusterDiskPressure TidbClusterConditionType = "DiskPressure"
+	// TidbClusterReferencedClusterReady indicates that the cluster referenced by spec.cluster
+	// of a heterogeneous cluster is found, compatible and ready.
+	TidbClusterReferencedClusterReady TidbClusterConditionType = "ReferencedClusterReady"
+	// TidbClusterValidationFailed indicates that the spec is refused by the strict validation of
+	// the controller, which is the same as the admission webhook, the cluster is not reconciled
+	// until the spec is fixed. It's only set if the controller runs with --strict-validation.
+	TidbClusterValidationFailed TidbClusterConditionType = "ValidationFailed"
+	// TidbClusterSQLProbeHealthy indicates that the synthetic SQL probe of spec.sqlProbe succeeds,
+	// it's Unknown if the status of the probe can't be read.
+	TidbClusterSQLProbeHealthy TidbClusterConditionType = "SQLProbeHealthy"
+	// TidbClusterPerformanceProfileSatisfiable indicates that the hugepages and dedicated CPUs required by
+	// the performance profiles of TiKV and TiFlash can be allocated by any node matching their scheduling
+	// constraints. It's only set if the performance profiles are set and tidb-controller-manager has the
+	// permission for nodes.
+	TidbClusterPerformanceProfileSatisfiable TidbClusterConditionType = "PerformanceProfileSatisfiable"
+	// TidbClusterDeprecatedFieldsUsed indicates that the spec uses the deprecated fields, which still
+	// work but should be replaced, the fields and their replacements are listed in the message.
+	TidbClusterDeprecatedFieldsUsed TidbClusterConditionType = "DeprecatedFieldsUsed"
 )
 
 // The `Type` of the component condition
@@ -481,6 +1532,82 @@ type PDSpec struct {
 	// +optional
 	// +kubebuilder:validation:Enum:="";"v1"
 	StartUpScriptVersion string `json:"startUpScriptVersion,omitempty"`
+
+	// StartScriptHooks are the user scripts sourced at the hook points of the startup script
+	// of PD.
+	// If you set it for an existing cluster, the PD cluster will be rolling updated.
+	// +optional
+	StartScriptHooks *StartScriptHooks `json:"startScriptHooks,omitempty"`
+
+	// HASpread spreads the PD Pods across the topologies by the topology spread constraints,
+	// so that the majority of PD members are not lost when a topology is down. The Pods are
+	// scheduled by kube-scheduler as usual, the topology keys already set in
+	// topologySpreadConstraints are not added again. An event is emitted if the nodes available
+	// to PD Pods are in too few topologies of the first key, which requires the permission for
+	// nodes, i.e. `--cluster-permission-node` for a namespace scoped tidb-controller-manager.
+	// If you set it for an existing cluster, the PD cluster will be rolling updated.
+	// +optional
+	HASpread *PDHASpread `json:"haSpread,omitempty"`
+
+	// ScheduleProfiles are the schedule config items of PD applied in the phases of the cluster,
+	// e.g. a higher replica-schedule-limit while TiKV is scaling and a lower region-schedule-limit
+	// in the business hours. The items are changed by the config API of PD, PD is not restarted.
+	// +optional
+	ScheduleProfiles []PDScheduleProfile `json:"scheduleProfiles,omitempty"`
+
+	// ScheduleDriftPolicy is the action taken when the items set by the schedule profiles are
+	// changed outside the operator, e.g. by pd-ctl. The changes are reported in
+	// status.pd.scheduleProfiles and by events anyway.
+	// Defaults to Revert
+	// +kubebuilder:validation:Enum:="";"Revert";"Keep"
+	// +optional
+	ScheduleDriftPolicy ScheduleDriftPolicy `json:"scheduleDriftPolicy,omitempty"`
+}
+
+// PDScheduleProfile is a set of schedule config items of PD applied while the profile is active.
+// A profile is active while any of its events is in progress and in its window, at least one of
+// them must be set. If an item is set by multiple active profiles, the last profile wins. An item
+// is reverted to the value before it was changed once no active profile sets it. The items also
+// set by spec.eventThrottling are left to it while the throttling is in progress.
+//
+// +k8s:openapi-gen=true
+type PDScheduleProfile struct {
+	// Name is the name of the profile
+	Name string `json:"name"`
+
+	// Events activate the profile while any of them is in progress
+	// +optional
+	Events []ThrottledEvent `json:"events,omitempty"`
+
+	// Window activates the profile in the windows, e.g. the business hours
+	// +optional
+	Window *MaintenanceWindow `json:"window,omitempty"`
+
+	// Config are the schedule config items, e.g. replica-schedule-limit: "64"
+	Config map[string]string `json:"config"`
+}
+
+// ScheduleDriftPolicy is the action taken when the schedule config items set by the schedule
+// profiles are changed outside the operator
+type ScheduleDriftPolicy string
+
+const (
+	// ScheduleDriftPolicyRevert reverts the changed items to the values of the active profiles
+	ScheduleDriftPolicyRevert ScheduleDriftPolicy = "Revert"
+	// ScheduleDriftPolicyKeep keeps the changed items until the active profiles change
+	ScheduleDriftPolicyKeep ScheduleDriftPolicy = "Keep"
+)
+
+// PDHASpread is the config of spreading PD Pods across the topologies
+// +k8s:openapi-gen=true
+type PDHASpread struct {
+	// TopologyKeys are the keys of node labels to spread PD Pods across, the PD Pods are spread
+	// evenly across the topologies of each key, e.g.
+	// ["topology.kubernetes.io/zone", "kubernetes.io/hostname"].
+	// The nodes without these labels are not selected by kube-scheduler.
+	// Defaults to ["kubernetes.io/hostname"]
+	// +optional
+	TopologyKeys []string `json:"topologyKeys,omitempty"`
 }
 
 // TiKVSpec contains details of TiKV members
@@ -565,6 +1692,11 @@ type TiKVSpec struct {
 	// +optional
 	Failover *Failover `json:"failover,omitempty"`
 
+	// StoreRelocation configures the replacement of the stores whose Pods are stuck pending
+	// because the nodes of their local PVs are cordoned or not ready
+	// +optional
+	StoreRelocation *TiKVStoreRelocation `json:"storeRelocation,omitempty"`
+
 	// MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod
 	// +optional
 	MountClusterClientSecret *bool `json:"mountClusterClientSecret,omitempty"`
@@ -578,13 +1710,118 @@ type TiKVSpec struct {
 	// +optional
 	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
 
+	// StoreCapacity overrides the capacity of each store reported to PD, which is derived from the
+	// storage limit by default. It's useful when the data volume is shared with other data, e.g. the
+	// raft logs, so that PD schedules the regions by the capacity really available to the store.
+	// Changing it causes a rolling update of TiKV.
+	// +optional
+	StoreCapacity *resource.Quantity `json:"storeCapacity,omitempty"`
+
 	// StoreLabels configures additional labels for TiKV stores.
 	// +optional
 	StoreLabels []string `json:"storeLabels,omitempty"`
 
+	// StoreRole makes the TiKV stores only hold witness or learner replicas, they are labeled with
+	// `store-role` and the placement rules of PD are managed to place the replicas of the role on them.
+	// It can only be set for a heterogeneous cluster, e.g. to run witness stores in a third AZ.
+	// +kubebuilder:validation:Enum=Witness;Learner
+	// +optional
+	StoreRole TiKVStoreRole `json:"storeRole,omitempty"`
+
 	// EnableNamedStatusPort enables status port(20180) in the Pod spec.
 	// If you set it to `true` for an existing cluster, the TiKV cluster will be rolling updated.
 	EnableNamedStatusPort bool `json:"enableNamedStatusPort,omitempty"`
+
+	// EvictLeader is the list of TiKV Pod names whose region leaders should be evicted.
+	// The operator adds an evict-leader scheduler to PD for the store of each listed Pod,
+	// and removes the scheduler after the Pod is removed from the list.
+	// The progress is reported in `status.tikv.evictLeader`.
+	// +optional
+	EvictLeader []string `json:"evictLeader,omitempty"`
+
+	// PerformanceProfile configures hugepages and dedicated CPUs for latency-sensitive deployments.
+	// If you set it for an existing cluster, the TiKV cluster will be rolling updated.
+	// +optional
+	PerformanceProfile *PerformanceProfile `json:"performanceProfile,omitempty"`
+
+	// SysctlDriftPolicy is the action taken when the sysctls applied to a TiKV Pod drift
+	// from `podSecurityContext.sysctls`, or the kernel of its node changes after they are applied.
+	// The compliance of each Pod is reported in `status.tikv.sysctls`.
+	// Optional: Defaults to Report
+	// +kubebuilder:validation:Enum=Report;RollingRestart
+	// +optional
+	SysctlDriftPolicy SysctlDriftPolicy `json:"sysctlDriftPolicy,omitempty"`
+
+	// StartScriptHooks are the user scripts sourced at the hook points of the startup script
+	// of TiKV.
+	// If you set it for an existing cluster, the TiKV cluster will be rolling updated.
+	// +optional
+	StartScriptHooks *StartScriptHooks `json:"startScriptHooks,omitempty"`
+}
+
+// DeletionPolicy is the policy of cleaning up a TidbCluster on deletion
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain leaves the stores in PD and the PVCs as they are
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyPurge tears down the cluster cleanly before it's deleted
+	DeletionPolicyPurge DeletionPolicy = "Purge"
+)
+
+// SysctlDriftPolicy is the action taken on the drift of sysctls
+type SysctlDriftPolicy string
+
+const (
+	// SysctlDriftPolicyReport only reports the drift in status and events
+	SysctlDriftPolicyReport SysctlDriftPolicy = "Report"
+	// SysctlDriftPolicyRollingRestart recreates the drifted Pods one by one after
+	// evicting their region leaders, so that the sysctls are applied again
+	SysctlDriftPolicyRollingRestart SysctlDriftPolicy = "RollingRestart"
+)
+
+// TiKVStoreRole is the role of the replicas held by the TiKV stores
+type TiKVStoreRole string
+
+const (
+	// TiKVStoreRoleWitness stores hold one witness replica of each region, which votes
+	// in the raft group without the data, so the quorum is kept at a low cost
+	TiKVStoreRoleWitness TiKVStoreRole = "Witness"
+	// TiKVStoreRoleLearner stores hold one learner replica of each region, which
+	// replicates the data without voting
+	TiKVStoreRoleLearner TiKVStoreRole = "Learner"
+
+	// TiKVStoreRoleLabelKey is the key of the TiKV store label of the role
+	TiKVStoreRoleLabelKey = "store-role"
+)
+
+// PerformanceProfile configures the resources of the Pods for latency-sensitive deployments
+// +k8s:openapi-gen=true
+type PerformanceProfile struct {
+	// HugePages configures the hugepages allocated to the main container,
+	// the pages are mounted at /dev/hugepages
+	// +optional
+	HugePages *HugePages `json:"hugePages,omitempty"`
+
+	// DedicatedCPUs rounds the CPU request up to an integer and sets the limits of CPU
+	// and memory equal to the requests, so that the Pod is in the Guaranteed QoS class
+	// and gets exclusive CPUs from the kubelet with the static CPU manager policy.
+	// The kubelet Topology Manager aligns the CPUs and hugepages to the same NUMA node
+	// if it is enabled. The resources of the sidecar containers should be set equally
+	// for their requests and limits too, otherwise the Pod is not Guaranteed.
+	// +optional
+	DedicatedCPUs bool `json:"dedicatedCPUs,omitempty"`
+}
+
+// HugePages is the hugepages allocated to a container
+// +k8s:openapi-gen=true
+type HugePages struct {
+	// PageSize is the size of a huge page
+	// +kubebuilder:validation:Enum="2Mi";"1Gi"
+	PageSize string `json:"pageSize"`
+
+	// Size is the total size of the hugepages
+	Size resource.Quantity `json:"size"`
 }
 
 // TiFlashSpec contains details of TiFlash members
@@ -621,6 +1858,11 @@ type TiFlashSpec struct {
 	// TiFlash supports multiple disks.
 	StorageClaims []StorageClaim `json:"storageClaims"`
 
+	// StoreCapacity overrides the capacity of each store reported to PD, which is derived from the
+	// storage limit by default. Changing it causes a rolling update of TiFlash.
+	// +optional
+	StoreCapacity *resource.Quantity `json:"storeCapacity,omitempty"`
+
 	// Config is the Configuration of TiFlash
 	// +optional
 	Config *TiFlashConfigWraper `json:"config,omitempty"`
@@ -638,54 +1880,185 @@ type TiFlashSpec struct {
 	// +optional
 	RecoverFailover bool `json:"recoverFailover,omitempty"`
 
-	// Failover is the configurations of failover
+	// Failover is the configurations of failover
+	// +optional
+	Failover *Failover `json:"failover,omitempty"`
+
+	// PerformanceProfile configures hugepages and dedicated CPUs for latency-sensitive deployments.
+	// +optional
+	PerformanceProfile *PerformanceProfile `json:"performanceProfile,omitempty"`
+
+	// ComputeResources configures the resource limits of TiFlash, the unset ones are derived
+	// from the resources of the TiFlash container. Nothing is derived if it's not set.
+	// The items set in `config` take precedence over it.
+	// +optional
+	ComputeResources *TiFlashComputeResources `json:"computeResources,omitempty"`
+
+	// StartScriptHooks are the user scripts sourced at the hook points of the startup script
+	// of TiFlash.
+	// If you set it for an existing cluster, the TiFlash cluster will be rolling updated.
+	// +optional
+	StartScriptHooks *StartScriptHooks `json:"startScriptHooks,omitempty"`
+}
+
+// TiFlashComputeResources is the resource limits of TiFlash
+// +k8s:openapi-gen=true
+type TiFlashComputeResources struct {
+	// MaxMemoryUsageForAllQueries is the memory limit for all the queries, i.e.
+	// `profiles.default.max_memory_usage_for_all_queries`.
+	// Defaults to 80% of the memory limit of the container, the rest is left to the storage layer.
+	// +optional
+	MaxMemoryUsageForAllQueries *resource.Quantity `json:"maxMemoryUsageForAllQueries,omitempty"`
+
+	// MaxThreads is the max number of threads to execute a query, i.e. `profiles.default.max_threads`.
+	// Defaults to the CPU limit of the container rounded up, or the number of
+	// dedicated CPUs if `performanceProfile.dedicatedCPUs` is set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxThreads *int32 `json:"maxThreads,omitempty"`
+
+	// BackgroundPoolSize is the number of threads of the background tasks, i.e.
+	// `profiles.default.background_pool_size`.
+	// Defaults to the value of MaxThreads.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BackgroundPoolSize *int32 `json:"backgroundPoolSize,omitempty"`
+}
+
+// TiCDCSpec contains details of TiCDC members
+// +k8s:openapi-gen=true
+type TiCDCSpec struct {
+	ComponentSpec               `json:",inline"`
+	corev1.ResourceRequirements `json:",inline"`
+
+	// Specify a Service Account for TiCDC
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// The desired ready replicas
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// TLSClientSecretNames are the names of secrets that store the
+	// client certificates for the downstream.
+	// +optional
+	TLSClientSecretNames []string `json:"tlsClientSecretNames,omitempty"`
+
+	// Base image of the component, image tag is now allowed during validation
+	// +kubebuilder:default=pingcap/ticdc
+	// +optional
+	BaseImage string `json:"baseImage"`
+
+	// Config is the Configuration of tidbcdc servers
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:XPreserveUnknownFields
+	Config *CDCConfigWraper `json:"config,omitempty"`
+
+	// StorageVolumes configure additional storage for TiCDC pods.
+	// +optional
+	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
+
+	// The storageClassName of the persistent volume for TiCDC data storage.
+	// Defaults to Kubernetes default storage class.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// GracefulShutdownTimeout is the timeout of gracefully shutdown a TiCDC pod.
+	// Encoded in the format of Go Duration.
+	// Defaults to 10m
+	// +optional
+	GracefulShutdownTimeout *metav1.Duration `json:"gracefulShutdownTimeout,omitempty"`
+
+	// DrainRetryInterval is the minimum interval between two attempts to drain a TiCDC capture
+	// during graceful shutdown, it must not be greater than GracefulShutdownTimeout.
+	// Encoded in the format of Go Duration.
+	// Defaults to 10s
+	// +optional
+	DrainRetryInterval *metav1.Duration `json:"drainRetryInterval,omitempty"`
+
+	// SortDirVolumeName is the name of the volume in storageVolumes used as the sort dir of TiCDC.
+	// It's passed to TiCDC by `--data-dir`, or `--sort-dir` for the versions before v6.0.0, which
+	// takes precedence over the config. The sort files left by the last TiCDC server are purged
+	// every time TiCDC starts, as TiCDC may leak them after an unclean restart.
+	// +optional
+	SortDirVolumeName string `json:"sortDirVolumeName,omitempty"`
+
+	// Changefeeds are the changefeeds created by TiDB Operator through the open API of TiCDC.
+	// The credentials of the sinks are read from Secrets and rendered into the sink URIs only
+	// when they are sent to TiCDC, a changefeed is updated when its sink URI or the referenced
+	// Secret is changed. The changefeeds removed from the list are not removed from TiCDC.
+	// +optional
+	Changefeeds []TiCDCChangefeed `json:"changefeeds,omitempty"`
+}
+
+// TiCDCChangefeed is a changefeed managed by TiDB Operator
+// +k8s:openapi-gen=true
+type TiCDCChangefeed struct {
+	// ID is the ID of the changefeed
+	ID string `json:"id"`
+	// Sink is the downstream of the changefeed
+	Sink ChangefeedSink `json:"sink"`
+}
+
+// ChangefeedSink is the downstream of a changefeed
+// +k8s:openapi-gen=true
+type ChangefeedSink struct {
+	// URI is the sink URI of the changefeed without credentials,
+	// e.g. `mysql://downstream-tidb:4000/` or `kafka://kafka:9092/topic?protocol=canal-json`
+	URI string `json:"uri"`
+
+	// CredentialsSecret references the Secret of the credentials of the sink, the user and the
+	// password are rendered as the user info of `mysql` and `tidb` URIs and as `sasl-user` and
+	// `sasl-password` of `kafka` URIs. It must be in the namespace of the TidbCluster.
+	// +optional
+	CredentialsSecret *SinkCredentialsSecret `json:"credentialsSecret,omitempty"`
+}
+
+// SinkCredentialsSecret is the Secret of the credentials of a sink
+// +k8s:openapi-gen=true
+type SinkCredentialsSecret struct {
+	// Name is the name of the Secret
+	Name string `json:"name"`
+	// UserKey is the key of the user in the Secret
+	// Defaults to `user`
 	// +optional
-	Failover *Failover `json:"failover,omitempty"`
+	UserKey string `json:"userKey,omitempty"`
+	// PasswordKey is the key of the password in the Secret
+	// Defaults to `password`
+	// +optional
+	PasswordKey string `json:"passwordKey,omitempty"`
 }
 
-// TiCDCSpec contains details of TiCDC members
+// TiProxySpec contains details of TiProxy members. TiProxy is a SQL proxy between the clients
+// and TiDB, it keeps the client connections alive and migrates them to other TiDB servers when
+// TiDB servers are upgraded or scaled in.
 // +k8s:openapi-gen=true
-type TiCDCSpec struct {
+type TiProxySpec struct {
 	ComponentSpec               `json:",inline"`
 	corev1.ResourceRequirements `json:",inline"`
 
-	// Specify a Service Account for TiCDC
+	// Specify a Service Account for TiProxy
 	ServiceAccount string `json:"serviceAccount,omitempty"`
 
 	// The desired ready replicas
 	// +kubebuilder:validation:Minimum=0
 	Replicas int32 `json:"replicas"`
 
-	// TLSClientSecretNames are the names of secrets that store the
-	// client certificates for the downstream.
-	// +optional
-	TLSClientSecretNames []string `json:"tlsClientSecretNames,omitempty"`
-
 	// Base image of the component, image tag is now allowed during validation
-	// +kubebuilder:default=pingcap/ticdc
+	// TiProxy is versioned independently, the image without a tag is used if version is not set
+	// +kubebuilder:default=pingcap/tiproxy
 	// +optional
 	BaseImage string `json:"baseImage"`
 
-	// Config is the Configuration of tidbcdc servers
-	// +optional
-	// +kubebuilder:validation:Schemaless
-	// +kubebuilder:validation:XPreserveUnknownFields
-	Config *CDCConfigWraper `json:"config,omitempty"`
-
-	// StorageVolumes configure additional storage for TiCDC pods.
-	// +optional
-	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
-
-	// The storageClassName of the persistent volume for TiCDC data storage.
-	// Defaults to Kubernetes default storage class.
+	// Service defines a Kubernetes service of TiProxy, the clients connect to TiDB through it
 	// +optional
-	StorageClassName *string `json:"storageClassName,omitempty"`
+	Service *ServiceSpec `json:"service,omitempty"`
 
-	// GracefulShutdownTimeout is the timeout of gracefully shutdown a TiCDC pod.
-	// Encoded in the format of Go Duration.
-	// Defaults to 10m
+	// Config is the Configuration of TiProxy servers
 	// +optional
-	GracefulShutdownTimeout *metav1.Duration `json:"gracefulShutdownTimeout,omitempty"`
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:XPreserveUnknownFields
+	Config *config.GenericConfig `json:"config,omitempty"`
 }
 
 // TiCDCConfig is the configuration of tidbcdc
@@ -719,11 +2092,48 @@ type LogTailerSpec struct {
 	corev1.ResourceRequirements `json:",inline"`
 }
 
+// StartScriptHooks refers to the user scripts in a ConfigMap that are sourced by the
+// startup script of a component, so that the startup can be customized without
+// overriding the whole script.
+// +k8s:openapi-gen=true
+type StartScriptHooks struct {
+	// ConfigMapName is the name of the ConfigMap holding the hook scripts,
+	// it must be in the namespace of the cluster.
+	ConfigMapName string `json:"configMapName"`
+
+	// PreStart is the key of the script in the ConfigMap that is sourced before
+	// the startup script resolves the start arguments.
+	// +optional
+	PreStart string `json:"preStart,omitempty"`
+
+	// PostDiscovery is the key of the script in the ConfigMap that is sourced after
+	// the start arguments are resolved, e.g. by the discovery service, and right before
+	// the server is started. The arguments can be changed by the script through `ARGS`.
+	// For TiFlash, it's sourced by the init container after the config files are rendered.
+	// +optional
+	PostDiscovery string `json:"postDiscovery,omitempty"`
+}
+
 // InitContainerSpec contains basic spec about a init container
 //
 // +k8s:openapi-gen=true
 type InitContainerSpec struct {
 	corev1.ResourceRequirements `json:",inline"`
+
+	// Image of the init container, must have `sh` installed.
+	// Optional: Defaults to `spec.helper.image`
+	// +optional
+	Image *string `json:"image,omitempty"`
+
+	// ImagePullPolicy of the init container
+	// Optional: Defaults to the default policy of Kubernetes
+	// +optional
+	ImagePullPolicy *corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ExtraCommands are the shell commands run after the built-in ones of the init container,
+	// the init container fails if any of them fails.
+	// +optional
+	ExtraCommands []string `json:"extraCommands,omitempty"`
 }
 
 // StorageClaim contains details of TiFlash storages
@@ -826,6 +2236,77 @@ type TiDBSpec struct {
 	//
 	// +optional
 	Initializer *TiDBInitializer `json:"initializer,omitempty"`
+
+	// LoadBalancerReadiness configures the integration with the external load balancers, e.g.
+	// AWS TargetGroupBinding and GCP NEG, so that the rolling update waits for the load balancers
+	// to deregister a TiDB Pod before it's restarted.
+	// If you set it for an existing cluster, the TiDB cluster will be rolling updated.
+	// +optional
+	LoadBalancerReadiness *LoadBalancerReadiness `json:"loadBalancerReadiness,omitempty"`
+
+	// StartScriptHooks are the user scripts sourced at the hook points of the startup script
+	// of TiDB.
+	// If you set it for an existing cluster, the TiDB cluster will be rolling updated.
+	// +optional
+	StartScriptHooks *StartScriptHooks `json:"startScriptHooks,omitempty"`
+
+	// WarmUp configures the warm-up of the TiDB servers after they are restarted, a restarted
+	// TiDB Pod is not put in service until the warm-up finishes.
+	// If you set it for an existing cluster, the TiDB cluster will be rolling updated.
+	// +optional
+	WarmUp *TiDBWarmUp `json:"warmUp,omitempty"`
+}
+
+// TiDBLoadBalancerServing is the condition type of the readiness gate added to the TiDB Pods
+// if LoadBalancerReadiness is set. The operator sets it to false to take the Pod out of
+// the load balancers before restarting it.
+const TiDBLoadBalancerServing corev1.PodConditionType = "tidb.pingcap.com/load-balancer-serving"
+
+// LoadBalancerReadiness configures the integration with the external load balancers
+// +k8s:openapi-gen=true
+type LoadBalancerReadiness struct {
+	// ReadinessGates are the condition types set by the load balancer controllers, e.g.
+	// `target-health.elbv2.k8s.aws/<target-group-binding>` for AWS TargetGroupBinding and
+	// `cloud.google.com/load-balancer-neg-ready` for GCP NEG. They are added to the readiness
+	// gates of the TiDB Pods, so an upgraded Pod is not ready until the load balancers
+	// report it healthy.
+	// +optional
+	ReadinessGates []corev1.PodConditionType `json:"readinessGates,omitempty"`
+
+	// DeregistrationDelay is the time to wait for the load balancers to deregister a TiDB Pod
+	// after it's taken out of service, in the format of Go Duration. It should be longer than
+	// the deregistration delay of the load balancers.
+	// Defaults to 30s
+	// +optional
+	DeregistrationDelay *string `json:"deregistrationDelay,omitempty"`
+}
+
+// TiDBWarmedUp is the condition type of the readiness gate added to the TiDB Pods if WarmUp
+// is set. The operator sets it to true after the warm-up statements are run on the Pod.
+const TiDBWarmedUp corev1.PodConditionType = "tidb.pingcap.com/warmed-up"
+
+// TiDBWarmUp configures the warm-up of a restarted TiDB server
+// +k8s:openapi-gen=true
+type TiDBWarmUp struct {
+	// SQLs are the statements run on a restarted TiDB server before it's put in service, e.g.
+	// the frequent queries to fill the plan cache and to load the statistics of the hot tables.
+	SQLs []string `json:"sqls"`
+
+	// User is the user to run the statements.
+	// Defaults to root
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// SecretName is the name of the secret containing the password of the user in the key
+	// `password`. The password is empty if it's not set.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Timeout is the max time to run the statements, in the format of Go Duration. The TiDB
+	// server is put in service anyway if the statements fail or time out.
+	// Defaults to 60s
+	// +optional
+	Timeout *string `json:"timeout,omitempty"`
 }
 
 type TiDBInitializer struct {
@@ -837,6 +2318,8 @@ const (
 	TCPProbeType string = "tcp"
 	// CommandProbeType represents the readiness prob method with arbitrary unix `exec` call format commands
 	CommandProbeType string = "command"
+	// HTTPProbeType represents the prob method with HTTP requests to the status API
+	HTTPProbeType string = "http"
 )
 
 // TiDBProbe contains details of probing tidb.
@@ -909,6 +2392,12 @@ type HelperSpec struct {
 	// Optional: Defaults to the cluster-level setting
 	// +optional
 	ImagePullPolicy *corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Initializer is the default configurations of the init containers running with the helper
+	// image, which set kernel parameters and render the config of TiFlash. The initializer of a
+	// component, e.g. `spec.tiflash.initializer`, takes higher priority.
+	// +optional
+	Initializer *InitContainerSpec `json:"initializer,omitempty"`
 }
 
 // TiDBSlowLogTailerSpec represents an optional log tailer sidecar with TiDB
@@ -1079,6 +2568,121 @@ type ComponentSpec struct {
 	// SuspendAction defines the suspend actions for all component.
 	// +optional
 	SuspendAction *SuspendAction `json:"suspendAction,omitempty"`
+
+	// PVCDeletePolicy defines how to delete the PVCs of the scaled-in pods, one of Retain,
+	// Delete and DeferWithTTL
+	// Optional: Defaults to Retain
+	// +kubebuilder:validation:Enum=Retain;Delete;DeferWithTTL
+	// +optional
+	PVCDeletePolicy PVCDeletePolicy `json:"pvcDeletePolicy,omitempty"`
+
+	// PVCDeleteTTL is how long the PVCs of a scaled-in pod are kept if pvcDeletePolicy is DeferWithTTL
+	// Optional: Defaults to 24h
+	// +optional
+	PVCDeleteTTL *metav1.Duration `json:"pvcDeleteTTL,omitempty"`
+
+	// Probes customizes the readiness, liveness and startup probes of the main container.
+	// It's honored by the components of TidbCluster and DMCluster except discovery, and for
+	// TiDB the readiness probe set here takes precedence over `spec.tidb.readinessProbe`.
+	// If you set it for an existing cluster, the component will be rolling updated.
+	// +optional
+	Probes *Probes `json:"probes,omitempty"`
+
+	// Architectures of the nodes that the component can be scheduled to, e.g. amd64 and arm64.
+	// Set it to the architectures supported by the image of the component if the cluster mixes
+	// nodes of different architectures, it's rendered as a required node affinity on the
+	// `kubernetes.io/arch` label.
+	// If you set it for an existing cluster, the component will be rolling updated.
+	// Optional: Defaults to the nodes of any architecture
+	// +optional
+	Architectures []string `json:"architectures,omitempty"`
+
+	// EphemeralStorage configures the ephemeral storage of the main container and the size of its
+	// temporary directory, the Pods using more than the limits are evicted by the kubelet.
+	// If you set it for an existing cluster, the component will be rolling updated.
+	// +optional
+	EphemeralStorage *EphemeralStorage `json:"ephemeralStorage,omitempty"`
+}
+
+// EphemeralStorage configures the ephemeral storage of the main container of a component
+// +k8s:openapi-gen=true
+type EphemeralStorage struct {
+	// Request is the ephemeral-storage request of the main container, it overrides the
+	// ephemeral-storage in the requests of the component
+	// +optional
+	Request *resource.Quantity `json:"request,omitempty"`
+
+	// Limit is the ephemeral-storage limit of the main container, it overrides the
+	// ephemeral-storage in the limits of the component
+	// +optional
+	Limit *resource.Quantity `json:"limit,omitempty"`
+
+	// TmpSizeLimit mounts an emptyDir volume of the size limit at /tmp of the main container.
+	// TiDB spills the intermediate results to tmp-storage-path, and TiCDC sorts the events
+	// in its data dir, both of which are under /tmp by default.
+	// For TiDB, tmp-storage-quota defaults to 80% of the size limit if it's not set.
+	// +optional
+	TmpSizeLimit *resource.Quantity `json:"tmpSizeLimit,omitempty"`
+}
+
+// Probes are the probes of the main container of a component
+// +k8s:openapi-gen=true
+type Probes struct {
+	// Readiness is the readiness probe
+	// +optional
+	Readiness *Probe `json:"readiness,omitempty"`
+
+	// Liveness is the liveness probe, the container is restarted if it fails
+	// +optional
+	Liveness *Probe `json:"liveness,omitempty"`
+
+	// Startup is the startup probe, the other probes are not run until it succeeds.
+	// Use it to give the components that take long to start, e.g. TiKV with a large
+	// number of regions, more time before the liveness probe kicks in.
+	// +optional
+	Startup *Probe `json:"startup,omitempty"`
+}
+
+// Probe describes a probe of the main container of a component
+// +k8s:openapi-gen=true
+type Probe struct {
+	// Type is the method of the probe.
+	//
+	// "tcp" connects to the client port of the component.
+	//
+	// "http" requests the status API of the component, it can not be used when TLS is
+	// enabled for the cluster.
+	//
+	// "command" uses curl to request the status API of TiDB, it's only supported by TiDB.
+	// Optional: Defaults to tcp
+	// +kubebuilder:validation:Enum=tcp;http;command
+	// +optional
+	Type *string `json:"type,omitempty"`
+	// Number of seconds after the container has started before the probe is initiated.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// Number of seconds after which the probe times out.
+	// Default to Kubernetes default (1 second). Minimum value is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// How often (in seconds) to perform the probe.
+	// Default to Kubernetes default (10 seconds). Minimum value is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// Minimum consecutive successes for the probe to be considered successful after having failed.
+	// Must be 1 for liveness and startup probes.
+	// Default to Kubernetes default (1). Minimum value is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+	// Minimum consecutive failures for the probe to be considered failed after having succeeded.
+	// Default to Kubernetes default (3). Minimum value is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
 }
 
 // ServiceSpec specifies the service object in k8s
@@ -1157,6 +2761,38 @@ type TiDBServiceSpec struct {
 	// Optional: Defaults to omitted
 	// +optional
 	AdditionalPorts []corev1.ServicePort `json:"additionalPorts,omitempty"`
+
+	// TopologyAwareRouting configures how to keep the SQL traffic in the zone of the clients
+	// Optional: Defaults to omitted
+	// +optional
+	TopologyAwareRouting *TopologyAwareRouting `json:"topologyAwareRouting,omitempty"`
+}
+
+// TopologyAwareRoutingMode is the mode of topology aware routing
+type TopologyAwareRoutingMode string
+
+const (
+	// TopologyAwareRoutingHints enables the topology aware hints of the TiDB Service,
+	// which requires Kubernetes v1.23 or later
+	TopologyAwareRoutingHints TopologyAwareRoutingMode = "Hints"
+	// TopologyAwareRoutingZoneServices creates a Service `<cluster>-tidb-<zone>` for each zone,
+	// which only selects the TiDB Pods in the zone
+	TopologyAwareRoutingZoneServices TopologyAwareRoutingMode = "ZoneServices"
+)
+
+// TopologyAwareRouting configures how to keep the SQL traffic in the zone of the clients.
+// The TiDB Pods are labeled with `tidb.pingcap.com/zone` after they are scheduled, the value
+// is the `topology.kubernetes.io/zone` label of the node, which requires the permission of nodes.
+// +k8s:openapi-gen=true
+type TopologyAwareRouting struct {
+	// Mode is the mode of topology aware routing
+	// +kubebuilder:validation:Enum=Hints;ZoneServices
+	Mode TopologyAwareRoutingMode `json:"mode"`
+
+	// Zones are the zones to create the Services for in the ZoneServices mode,
+	// the Services of the zones removed from the list are deleted
+	// +optional
+	Zones []string `json:"zones,omitempty"`
 }
 
 // (Deprecated) Service represent service type used in TidbCluster
@@ -1193,6 +2829,48 @@ type PDStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// DerivedConfig contains the config items derived from the resources of the container.
+	// +optional
+	DerivedConfig map[string]string `json:"derivedConfig,omitempty"`
+	// ScheduleProfiles is the status of the schedule config items set by spec.pd.scheduleProfiles
+	// +optional
+	ScheduleProfiles *PDScheduleProfilesStatus `json:"scheduleProfiles,omitempty"`
+	// InFlightOperation is the checkpoint of the in-flight upgrade or scale operation
+	// +optional
+	InFlightOperation *OperationCheckpoint `json:"inFlightOperation,omitempty"`
+}
+
+// PDScheduleProfilesStatus is the status of the schedule config items set by the schedule profiles
+type PDScheduleProfilesStatus struct {
+	// ActiveProfiles are the names of the active profiles
+	// +optional
+	ActiveProfiles []string `json:"activeProfiles,omitempty"`
+	// Applied are the schedule config items set by the active profiles
+	// +optional
+	Applied map[string]string `json:"applied,omitempty"`
+	// Original are the values of the items before they were changed by the profiles,
+	// which are restored once no active profile sets them
+	// +optional
+	Original map[string]string `json:"original,omitempty"`
+	// Drifts are the items set by the profiles but changed outside the operator
+	// +optional
+	Drifts []PDScheduleDrift `json:"drifts,omitempty"`
+	// UnknownItems are the items set by the active profiles but not found in the schedule
+	// config of PD, they are skipped
+	// +optional
+	UnknownItems []string `json:"unknownItems,omitempty"`
+}
+
+// PDScheduleDrift is a schedule config item changed outside the operator
+type PDScheduleDrift struct {
+	// Name is the name of the item
+	Name string `json:"name"`
+	// Expected is the value set by the profiles
+	Expected string `json:"expected"`
+	// Actual is the value in PD
+	Actual string `json:"actual"`
+	// DetectedTime is the time the change was detected
+	DetectedTime metav1.Time `json:"detectedTime"`
 }
 
 // PDMember is PD member
@@ -1248,6 +2926,9 @@ type TiDBStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// DerivedConfig contains the config items derived from the resources of the container.
+	// +optional
+	DerivedConfig map[string]string `json:"derivedConfig,omitempty"`
 }
 
 // TiDBMember is TiDB member
@@ -1269,14 +2950,17 @@ type TiDBFailureMember struct {
 }
 
 var (
-	EvictLeaderAnnKeys = []string{EvictLeaderAnnKey, EvictLeaderAnnKeyForResize}
+	EvictLeaderAnnKeys = []string{EvictLeaderAnnKey, EvictLeaderAnnKeyForResize, EvictLeaderAnnKeyForSysctl}
 )
 
 const (
 	// EvictLeaderAnnKey is the annotation key to evict leader used by user.
+	// Deprecated: use a TidbClusterOperation of type EvictLeader instead.
 	EvictLeaderAnnKey = "tidb.pingcap.com/evict-leader"
 	// EvictLeaderAnnKeyForResize is the annotation key to evict leader user by pvc resizer.
 	EvictLeaderAnnKeyForResize = "tidb.pingcap.com/evict-leader-for-resize"
+	// EvictLeaderAnnKeyForSysctl is the annotation key to evict leader used by the remediation of sysctls drift.
+	EvictLeaderAnnKeyForSysctl = "tidb.pingcap.com/evict-leader-for-sysctl"
 )
 
 // The `Value` of annotation controls the behavior when the leader count drops to zero, the valid value is one of:
@@ -1288,10 +2972,19 @@ const (
 	EvictLeaderValueDeletePod = "delete-pod"
 )
 
+// EvictLeaderSourceSpec is the source of evict-leader requested by `spec.tikv.evictLeader`.
+const EvictLeaderSourceSpec = "spec"
+
 type EvictLeaderStatus struct {
 	PodCreateTime metav1.Time `json:"podCreateTime,omitempty"`
 	BeginTime     metav1.Time `json:"beginTime,omitempty"`
 	Value         string      `json:"value,omitempty"`
+	// Source is the annotation key or `spec` which requests the eviction.
+	// +optional
+	Source string `json:"source,omitempty"`
+	// Completed indicates that all region leaders have been evicted from the store.
+	// +optional
+	Completed bool `json:"completed,omitempty"`
 }
 
 // TiKVStatus is TiKV status
@@ -1313,6 +3006,54 @@ type TiKVStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// DerivedConfig contains the config items derived from the resources of the container.
+	// +optional
+	DerivedConfig map[string]string `json:"derivedConfig,omitempty"`
+	// Sysctls contains the compliance of the sysctls of each Pod, keyed by the Pod name.
+	// It's only reported if `podSecurityContext.sysctls` is set.
+	// +optional
+	Sysctls map[string]PodSysctlStatus `json:"sysctls,omitempty"`
+	// InFlightOperation is the checkpoint of the in-flight upgrade or scale operation
+	// +optional
+	InFlightOperation *OperationCheckpoint `json:"inFlightOperation,omitempty"`
+	// StorageClassMigration is the progress of migrating the PVCs to the StorageClasses in spec
+	// +optional
+	StorageClassMigration *StorageClassMigrationStatus `json:"storageClassMigration,omitempty"`
+}
+
+// StorageClassMigrationPhase is the phase of migrating the PVCs of a pod to the desired StorageClasses
+type StorageClassMigrationPhase string
+
+const (
+	// StorageClassMigrationOffline means the store of the pod is deleted and waiting to become Tombstone
+	StorageClassMigrationOffline StorageClassMigrationPhase = "Offline"
+	// StorageClassMigrationReplacing means the pod and its PVCs are deleted and waiting to be recreated
+	StorageClassMigrationReplacing StorageClassMigrationPhase = "Replacing"
+)
+
+// StorageClassMigrationStatus is the progress of migrating the PVCs of a component to the desired StorageClasses
+type StorageClassMigrationStatus struct {
+	// MigratingPod is the pod being migrated
+	// +optional
+	MigratingPod string `json:"migratingPod,omitempty"`
+	// StoreID is the id of the deleted store of MigratingPod
+	// +optional
+	StoreID string `json:"storeID,omitempty"`
+	// Phase is the phase of MigratingPod
+	// +optional
+	Phase StorageClassMigrationPhase `json:"phase,omitempty"`
+	// MigratedPods are the pods whose PVCs are migrated
+	// +optional
+	MigratedPods []string `json:"migratedPods,omitempty"`
+}
+
+// PodSysctlStatus is the compliance of the sysctls applied to a Pod
+type PodSysctlStatus struct {
+	// Compliant indicates whether the sysctls applied to the Pod are the desired ones
+	Compliant bool `json:"compliant"`
+	// Reason is the reason why the Pod is not compliant
+	// +optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // TiFlashStatus is TiFlash status
@@ -1332,6 +3073,9 @@ type TiFlashStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// StorageClassMigration is the progress of migrating the PVCs to the StorageClasses in spec
+	// +optional
+	StorageClassMigration *StorageClassMigrationStatus `json:"storageClassMigration,omitempty"`
 }
 
 // TiCDCStatus is TiCDC status
@@ -1346,6 +3090,31 @@ type TiCDCStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Changefeeds are the status of spec.ticdc.changefeeds, keyed by the IDs of the changefeeds
+	// +optional
+	Changefeeds map[string]TiCDCChangefeedStatus `json:"changefeeds,omitempty"`
+}
+
+// TiCDCChangefeedStatus is the status of a changefeed managed by TiDB Operator
+type TiCDCChangefeedStatus struct {
+	// SinkURI is the sink URI of the changefeed with the credentials masked
+	SinkURI string `json:"sinkURI,omitempty"`
+	// SinkCredentialsDigest is the sha256 sum of the sink URI sent to TiCDC, including the credentials
+	SinkCredentialsDigest string `json:"sinkCredentialsDigest,omitempty"`
+	// Message is the reason why the changefeed is not created or updated
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// TiProxyStatus is TiProxy status
+type TiProxyStatus struct {
+	Synced      bool                    `json:"synced,omitempty"`
+	Phase       MemberPhase             `json:"phase,omitempty"`
+	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
+	// Represents the latest available observations of a component's state.
+	// +optional
+	// +nullable
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // TiCDCCapture is TiCDC Capture status
@@ -1355,6 +3124,33 @@ type TiCDCCapture struct {
 	Version string `json:"version,omitempty"`
 	IsOwner bool   `json:"isOwner,omitempty"`
 	Ready   bool   `json:"ready,omitempty"`
+	// GracefulShutdown is the progress of gracefully shutting down the capture during
+	// scale-in or upgrade, it's nil if the capture is not being shut down
+	// +optional
+	GracefulShutdown *TiCDCGracefulShutdownStatus `json:"gracefulShutdown,omitempty"`
+}
+
+// TiCDCGracefulShutdownPhase is the step of gracefully shutting down a TiCDC capture
+type TiCDCGracefulShutdownPhase string
+
+const (
+	// TiCDCResigningOwner means the ownership is being removed from the capture
+	TiCDCResigningOwner TiCDCGracefulShutdownPhase = "ResigningOwner"
+	// TiCDCDraining means the tables are being moved out of the capture
+	TiCDCDraining TiCDCGracefulShutdownPhase = "Draining"
+	// TiCDCWaitingForCheckpoints means the capture is drained and the checkpoints of the
+	// changefeeds are expected to advance
+	TiCDCWaitingForCheckpoints TiCDCGracefulShutdownPhase = "WaitingForCheckpoints"
+)
+
+// TiCDCGracefulShutdownStatus is the progress of gracefully shutting down a TiCDC capture
+type TiCDCGracefulShutdownStatus struct {
+	Phase TiCDCGracefulShutdownPhase `json:"phase,omitempty"`
+	// RemainingTables is the number of the tables not moved out of the capture yet
+	RemainingTables int32 `json:"remainingTables,omitempty"`
+	// LastDrainTime is the last time the capture was requested to be drained
+	// +nullable
+	LastDrainTime *metav1.Time `json:"lastDrainTime,omitempty"`
 }
 
 // TiKVStores is either Up/Down/Offline/Tombstone
@@ -1537,6 +3333,11 @@ type LocalStorageProvider struct {
 	Volume      corev1.Volume      `json:"volume"`
 	VolumeMount corev1.VolumeMount `json:"volumeMount"`
 	Prefix      string             `json:"prefix,omitempty"`
+	// MinFreeSpace is the free space of the volume required before a backup starts,
+	// the backup fails in the preflight check if the volume doesn't have enough space.
+	// The volume is only checked to be writable if it's not set.
+	// +optional
+	MinFreeSpace *resource.Quantity `json:"minFreeSpace,omitempty"`
 }
 
 // S3StorageProvider represents a S3 compliant storage for storing backups.
@@ -1769,9 +3570,45 @@ type BackupSpec struct {
 // DumplingConfig contains config for dumpling
 type DumplingConfig struct {
 	// Options means options for backup data to remote storage with dumpling.
+	// FileType, FileSize, Threads, Rows and Consistency take precedence over the same flags in it.
 	Options []string `json:"options,omitempty"`
 	// Deprecated. Please use `Spec.TableFilter` instead. TableFilter means Table filter expression for 'db.table' matching
 	TableFilter []string `json:"tableFilter,omitempty"`
+	// FileType is the format of the exported data files, sql or csv. Defaults to sql
+	// +kubebuilder:validation:Enum=sql;csv
+	// +optional
+	FileType string `json:"fileType,omitempty"`
+	// FileSize is the size limit of a data file, e.g. 256MiB. The data files are not split
+	// by size if it's not set
+	// +optional
+	FileSize string `json:"fileSize,omitempty"`
+	// Threads is the number of the tables or chunks exported concurrently. Defaults to 16
+	// +optional
+	Threads *int32 `json:"threads,omitempty"`
+	// Rows splits a table into chunks of the number of rows, so the chunks of a table
+	// are exported concurrently. Defaults to 10000
+	// +optional
+	Rows *int64 `json:"rows,omitempty"`
+	// Consistency is the consistency control of the export, one of auto, snapshot, lock,
+	// flush and none. Defaults to auto
+	// +kubebuilder:validation:Enum=auto;snapshot;lock;flush;none
+	// +optional
+	Consistency string `json:"consistency,omitempty"`
+}
+
+// DumplingProgress is the progress of a dumpling export parsed from the output of dumpling
+type DumplingProgress struct {
+	// FinishedTables is the number of the tables exported
+	FinishedTables int32 `json:"finishedTables"`
+	// TotalTables is the number of the tables to export
+	TotalTables int32 `json:"totalTables"`
+	// FinishedRows is the number of the rows exported
+	FinishedRows int64 `json:"finishedRows"`
+	// EstimatedTotalRows is the estimated number of the rows to export
+	EstimatedTotalRows int64 `json:"estimatedTotalRows"`
+	// LastUpdateTime is the time the progress is reported by dumpling
+	// +nullable
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -1795,6 +3632,9 @@ type BRConfig struct {
 	RateLimit *uint `json:"rateLimit,omitempty"`
 	// TimeAgo is the history version of the backup task, e.g. 1m, 1h
 	TimeAgo string `json:"timeAgo,omitempty"`
+	// LastBackupTS is the commit ts of the last backup, an incremental backup of the changes
+	// since it is taken if it is set
+	LastBackupTS string `json:"lastBackupTS,omitempty"`
 	// Checksum specifies whether to run checksum after backup
 	Checksum *bool `json:"checksum,omitempty"`
 	// CheckRequirements specifies whether to check requirements
@@ -1859,6 +3699,10 @@ type BackupStatus struct {
 	BackupSize int64 `json:"backupSize,omitempty"`
 	// CommitTs is the snapshot time point of tidb cluster.
 	CommitTs string `json:"commitTs,omitempty"`
+	// DumplingProgress is the progress of the dumpling export, it's only set for the
+	// backups of dumpling
+	// +optional
+	DumplingProgress *DumplingProgress `json:"dumplingProgress,omitempty"`
 	// Phase is a user readable state inferred from the underlying Backup conditions
 	Phase BackupConditionType `json:"phase,omitempty"`
 	// +nullable
@@ -1912,6 +3756,13 @@ type BackupScheduleSpec struct {
 	MaxBackups *int32 `json:"maxBackups,omitempty"`
 	// MaxReservedTime is to specify how long backups we want to keep.
 	MaxReservedTime *string `json:"maxReservedTime,omitempty"`
+	// MaxIncrementalBackups is the max number of incremental backups taken after a full backup,
+	// a full backup is taken once it is reached. Only BR supports incremental backups.
+	// 0 or unset means all the backups are full backups.
+	// Note that the incremental backups depend on the backups before them, which should not be
+	// removed by MaxBackups or MaxReservedTime while they are still needed.
+	// +optional
+	MaxIncrementalBackups *int32 `json:"maxIncrementalBackups,omitempty"`
 	// BackupTemplate is the specification of the backup structure to get scheduled.
 	BackupTemplate BackupSpec `json:"backupTemplate"`
 	// The storageClassName of the persistent volume for Backup data storage if not storage class name set in BackupSpec.
@@ -1938,6 +3789,79 @@ type BackupScheduleStatus struct {
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// RestoreSchedule refreshes a standby cluster periodically by restoring the backups of the
+// primary cluster taken by a BackupSchedule. The standby cluster is restored from a full backup
+// first, then the incremental backups based on it are applied in order.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:shortName="rts"
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`,description="The cron format string used for refreshing the standby cluster"
+// +kubebuilder:printcolumn:name="BackupSchedule",type=string,JSONPath=`.spec.backupScheduleName`,description="The backup schedule whose backups are restored"
+// +kubebuilder:printcolumn:name="LastRestore",type=string,JSONPath=`.status.lastRestore`,description="The last restore CR name",priority=1
+// +kubebuilder:printcolumn:name="StandbyTS",type=string,JSONPath=`.status.standbyTS`,description="The commit ts of the data of the standby cluster"
+// +kubebuilder:printcolumn:name="StandbyTime",type=date,JSONPath=`.status.standbyTime`,description="The physical time of the commit ts of the standby cluster",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type RestoreSchedule struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec RestoreScheduleSpec `json:"spec"`
+	// +k8s:openapi-gen=false
+	Status RestoreScheduleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// RestoreScheduleList contains a list of RestoreSchedule.
+type RestoreScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []RestoreSchedule `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// RestoreScheduleSpec contains the specification of refreshing a standby cluster.
+type RestoreScheduleSpec struct {
+	// Schedule specifies the cron string used for refreshing the standby cluster.
+	// All the backups completed before a scheduled time are restored in the refresh.
+	Schedule string `json:"schedule"`
+	// Pause means paused restoreSchedule
+	Pause bool `json:"pause,omitempty"`
+	// BackupScheduleName is the name of the BackupSchedule in the same namespace,
+	// whose BR backups are restored to the standby cluster. Once no backup is based on the
+	// data of the standby cluster, e.g. a new full backup is taken after MaxIncrementalBackups,
+	// the latest full backup is restored over the standby cluster and its chain is followed.
+	BackupScheduleName string `json:"backupScheduleName"`
+	// MaxRestores is the number of the restores to keep, the older restores are deleted.
+	// Defaults to 10.
+	// +optional
+	MaxRestores *int32 `json:"maxRestores,omitempty"`
+	// RestoreTemplate is the specification of the restores to the standby cluster,
+	// the storage of the restores is taken from the backups.
+	RestoreTemplate RestoreSpec `json:"restoreTemplate"`
+}
+
+// RestoreScheduleStatus represents the current state of a RestoreSchedule.
+type RestoreScheduleStatus struct {
+	// LastRestore is the name of the last restore.
+	LastRestore string `json:"lastRestore,omitempty"`
+	// LastRestoreBackup is the name of the backup restored by the last restore.
+	LastRestoreBackup string `json:"lastRestoreBackup,omitempty"`
+	// LastScheduleTime is the last time the refresh was scheduled.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// StandbyTS is the commit ts of the data of the standby cluster,
+	// i.e. the commit ts of the last backup restored.
+	StandbyTS string `json:"standbyTS,omitempty"`
+	// StandbyTime is the physical time of StandbyTS.
+	StandbyTime *metav1.Time `json:"standbyTime,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // Restore represents the restoration of backup of a tidb cluster.
 //
 // +k8s:openapi-gen=true
@@ -2067,6 +3991,43 @@ type RestoreSpec struct {
 
 	// PriorityClassName of Restore Job Pods
 	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// VerifyChecksum makes BR verify the checksums of the restored tables against the backup
+	// with `--checksum=true`, which rewrites the table IDs of the restored keys. After the
+	// restore completes, `ADMIN CHECKSUM TABLE` is also run on the restored tables and the
+	// numbers of KVs and bytes are compared with the ones in the backup metadata, the Restore
+	// fails if any of them mismatches. The per-table comparison is skipped for the backup
+	// metadata v2, whose schemas are stored in separate meta files. It's only supported by BR
+	// and requires `to`.
+	// +optional
+	VerifyChecksum bool `json:"verifyChecksum,omitempty"`
+}
+
+// TableChecksum is the checksum of a table
+type TableChecksum struct {
+	Crc64Xor   uint64 `json:"crc64Xor"`
+	TotalKvs   uint64 `json:"totalKvs"`
+	TotalBytes uint64 `json:"totalBytes"`
+}
+
+// TableChecksumMismatch is a restored table whose checksum mismatches the backup
+type TableChecksumMismatch struct {
+	// Table is the name of the table in the format of `db`.`table`
+	Table string `json:"table"`
+	// Expected is the checksum in the backup metadata
+	Expected TableChecksum `json:"expected"`
+	// Actual is the checksum of the restored table, it's nil if the table is not restored
+	// +optional
+	Actual *TableChecksum `json:"actual,omitempty"`
+}
+
+// RestoreChecksumVerification is the result of the checksum verification of the restored tables
+type RestoreChecksumVerification struct {
+	// VerifiedTables is the number of the tables whose checksums are verified
+	VerifiedTables int32 `json:"verifiedTables"`
+	// Mismatches are the tables whose checksums mismatch the backup
+	// +optional
+	Mismatches []TableChecksumMismatch `json:"mismatches,omitempty"`
 }
 
 // RestoreStatus represents the current status of a tidb cluster restore.
@@ -2079,6 +4040,9 @@ type RestoreStatus struct {
 	TimeCompleted metav1.Time `json:"timeCompleted,omitempty"`
 	// CommitTs is the snapshot time point of tidb cluster.
 	CommitTs string `json:"commitTs,omitempty"`
+	// ChecksumVerification is the result of spec.verifyChecksum
+	// +optional
+	ChecksumVerification *RestoreChecksumVerification `json:"checksumVerification,omitempty"`
 	// Phase is a user readable state inferred from the underlying Restore conditions
 	Phase RestoreConditionType `json:"phase,omitempty"`
 	// +nullable
@@ -2287,6 +4251,12 @@ type DMClusterSpec struct {
 	// SuspendAction defines the suspend actions for all component.
 	// +optional
 	SuspendAction *SuspendAction `json:"suspendAction,omitempty"`
+
+	// ImagePolicy resolves the images of DM master, DM worker and discovery before rendering them
+	// into the Pods, the same as the imagePolicy of TidbCluster.
+	// If you set it for an existing cluster, the cluster will be rolling updated.
+	// +optional
+	ImagePolicy *ImagePolicy `json:"imagePolicy,omitempty"`
 }
 
 // DMClusterStatus represents the current status of a dm cluster.
@@ -2595,3 +4565,21 @@ type Failover struct {
 	// +optional
 	RecoverByUID types.UID `json:"recoverByUID,omitempty"`
 }
+
+// TiKVStoreRelocation configures the replacement of the TiKV stores whose Pods can't be
+// scheduled because the only nodes their local PVs can be attached to are cordoned or not ready.
+// A store is replaced by a TidbClusterOperation of type ReplaceDisk, which moves the regions
+// out of the store and recreates the Pod with new volumes on another node.
+// +k8s:openapi-gen=true
+type TiKVStoreRelocation struct {
+	// Enabled indicates whether the stores are replaced automatically, only a warning
+	// event is emitted for the stuck Pods if it's false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// PendingThreshold is how long a Pod stays pending before its store is replaced,
+	// in the format of Go Duration.
+	// Defaults to 30m
+	// +optional
+	PendingThreshold *metav1.Duration `json:"pendingThreshold,omitempty"`
+}
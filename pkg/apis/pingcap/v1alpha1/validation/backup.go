@@ -0,0 +1,303 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	// bucketNameRegexp matches the bucket names of AWS S3 and GCS
+	bucketNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*[a-z0-9]$`)
+	// containerNameRegexp matches the container names of Azure Blob Storage
+	containerNameRegexp = regexp.MustCompile(`^[a-z0-9](-?[a-z0-9])*$`)
+)
+
+// ValidateBackup validates the fields of a Backup which don't depend on the cluster to back up, the
+// others are validated by the backup manager before creating the Job
+func ValidateBackup(backup *v1alpha1.Backup) field.ErrorList {
+	allErrs := field.ErrorList{}
+	spec := &backup.Spec
+	fldPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validateStorageProvider(&spec.StorageProvider, fldPath)...)
+	if spec.BR == nil {
+		allErrs = append(allErrs, validateAccessConfig(spec.From, true, fldPath.Child("from"))...)
+		if spec.StorageSize == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("storageSize"), "storageSize must be set to back up with dumpling"))
+		}
+		if spec.Dumpling != nil {
+			allErrs = append(allErrs, validateDumplingConfig(spec.Dumpling, fldPath.Child("dumpling"))...)
+		}
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateAccessConfig(spec.From, false, fldPath.Child("from"))...)
+	allErrs = append(allErrs, validateBRConfig(spec.BR, spec.Type, spec.TableFilter, fldPath)...)
+	if spec.Dumpling != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("dumpling"), "dumpling can't be used with br"))
+	}
+	return allErrs
+}
+
+// ValidateRestore validates the fields of a Restore which don't depend on the cluster to restore, the
+// others are validated by the restore manager before creating the Job
+func ValidateRestore(restore *v1alpha1.Restore) field.ErrorList {
+	allErrs := field.ErrorList{}
+	spec := &restore.Spec
+	fldPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validateStorageProvider(&spec.StorageProvider, fldPath)...)
+	if spec.BR == nil {
+		allErrs = append(allErrs, validateAccessConfig(spec.To, true, fldPath.Child("to"))...)
+		if spec.StorageSize == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("storageSize"), "storageSize must be set to restore with TiDB Lightning"))
+		}
+		if spec.VerifyChecksum {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("verifyChecksum"), "verifyChecksum is only supported by br"))
+		}
+		return allErrs
+	}
+
+	// the checksums of the restored tables are calculated by TiDB
+	allErrs = append(allErrs, validateAccessConfig(spec.To, spec.VerifyChecksum, fldPath.Child("to"))...)
+	allErrs = append(allErrs, validateBRConfig(spec.BR, spec.Type, spec.TableFilter, fldPath)...)
+	return allErrs
+}
+
+// validateStorageProvider validates that exactly one backend is set and the bucket, the prefix and the
+// secret of it are valid
+func validateStorageProvider(provider *v1alpha1.StorageProvider, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	var backends []string
+	if provider.S3 != nil {
+		backends = append(backends, "s3")
+		allErrs = append(allErrs, validateS3StorageProvider(provider.S3, fldPath.Child("s3"))...)
+	}
+	if provider.Gcs != nil {
+		backends = append(backends, "gcs")
+		allErrs = append(allErrs, validateGcsStorageProvider(provider.Gcs, fldPath.Child("gcs"))...)
+	}
+	if provider.Azblob != nil {
+		backends = append(backends, "azblob")
+		allErrs = append(allErrs, validateAzblobStorageProvider(provider.Azblob, fldPath.Child("azblob"))...)
+	}
+	if provider.Local != nil {
+		backends = append(backends, "local")
+		allErrs = append(allErrs, validateLocalStorageProvider(provider.Local, fldPath.Child("local"))...)
+	}
+	switch len(backends) {
+	case 0:
+		allErrs = append(allErrs, field.Required(fldPath, "one of s3, gcs, azblob and local must be set"))
+	case 1:
+	default:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "only one of s3, gcs, azblob and local can be set, but got "+strings.Join(backends, ", ")))
+	}
+	return allErrs
+}
+
+func validateS3StorageProvider(s3 *v1alpha1.S3StorageProvider, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	// the bucket can be omitted if the full path is set
+	if s3.Bucket == "" && s3.Path == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("bucket"), "bucket must be set"))
+	}
+	if s3.Bucket != "" {
+		// the S3 compatible storages may accept the bucket names rejected by AWS
+		if s3.Provider == v1alpha1.S3StorageProviderTypeAWS {
+			allErrs = append(allErrs, validateBucketName(s3.Bucket, bucketNameRegexp, 63, fldPath.Child("bucket"))...)
+		} else if strings.ContainsAny(s3.Bucket, "/ ") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("bucket"), s3.Bucket, "must not contain '/' or spaces"))
+		}
+	}
+	if s3.Endpoint != "" {
+		if u, err := url.Parse(s3.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("endpoint"), s3.Endpoint, "must be an absolute URL, e.g. http://minio:9000"))
+		}
+	}
+	allErrs = append(allErrs, validateStoragePrefix(s3.Prefix, fldPath.Child("prefix"))...)
+	allErrs = append(allErrs, validateStorageSecretName(s3.SecretName, fldPath.Child("secretName"))...)
+	return allErrs
+}
+
+func validateGcsStorageProvider(gcs *v1alpha1.GcsStorageProvider, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if gcs.ProjectId == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("projectId"), "projectId must be set"))
+	}
+	if gcs.Bucket == "" && gcs.Path == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("bucket"), "bucket must be set"))
+	}
+	if gcs.Bucket != "" {
+		allErrs = append(allErrs, validateBucketName(gcs.Bucket, bucketNameRegexp, 222, fldPath.Child("bucket"))...)
+	}
+	allErrs = append(allErrs, validateStoragePrefix(gcs.Prefix, fldPath.Child("prefix"))...)
+	allErrs = append(allErrs, validateStorageSecretName(gcs.SecretName, fldPath.Child("secretName"))...)
+	return allErrs
+}
+
+func validateAzblobStorageProvider(azblob *v1alpha1.AzblobStorageProvider, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if azblob.Container == "" && azblob.Path == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("container"), "container must be set"))
+	}
+	if azblob.Container != "" {
+		allErrs = append(allErrs, validateBucketName(azblob.Container, containerNameRegexp, 63, fldPath.Child("container"))...)
+	}
+	allErrs = append(allErrs, validateStoragePrefix(azblob.Prefix, fldPath.Child("prefix"))...)
+	allErrs = append(allErrs, validateStorageSecretName(azblob.SecretName, fldPath.Child("secretName"))...)
+	return allErrs
+}
+
+func validateLocalStorageProvider(local *v1alpha1.LocalStorageProvider, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if local.VolumeMount.Name != local.Volume.Name {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("volumeMount", "name"), local.VolumeMount.Name, "must be the same as volume.name"))
+	}
+	mountPath := local.VolumeMount.MountPath
+	switch {
+	case mountPath == "":
+		allErrs = append(allErrs, field.Required(fldPath.Child("volumeMount", "mountPath"), "mountPath must be set"))
+	case !path.IsAbs(mountPath) || strings.Contains(mountPath, ":"):
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("volumeMount", "mountPath"), mountPath, "must be an absolute path without ':'"))
+	}
+	if local.MinFreeSpace != nil && local.MinFreeSpace.Sign() < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minFreeSpace"), local.MinFreeSpace.String(), "must not be negative"))
+	}
+	allErrs = append(allErrs, validateStoragePrefix(local.Prefix, fldPath.Child("prefix"))...)
+	return allErrs
+}
+
+// validateBucketName validates the name of a bucket or a container, see the naming rules of the cloud providers
+func validateBucketName(name string, re *regexp.Regexp, maxLen int, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(name) < 3 || len(name) > maxLen {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, fmt.Sprintf("must be 3 to %d characters", maxLen)))
+	}
+	if !re.MatchString(name) || strings.Contains(name, "..") {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, fmt.Sprintf("must match the regex %s", re.String())))
+	}
+	return allErrs
+}
+
+// validateStoragePrefix validates that the prefix doesn't escape from the bucket
+func validateStoragePrefix(prefix string, fldPath *field.Path) field.ErrorList {
+	if prefix == "" {
+		return field.ErrorList{}
+	}
+	return validatePathNoBacksteps(prefix, fldPath)
+}
+
+func validateStorageSecretName(name string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if name == "" {
+		// the credentials may be provided by IAM roles or the environment
+		return allErrs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+	return allErrs
+}
+
+// validateAccessConfig validates the config to access TiDB, required is true if TiDB must be accessed
+func validateAccessConfig(config *v1alpha1.TiDBAccessConfig, required bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if config == nil {
+		if required {
+			allErrs = append(allErrs, field.Required(fldPath, "the config to access TiDB must be set"))
+		}
+		return allErrs
+	}
+	if config.Host == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("host"), "host must be set"))
+	}
+	if config.SecretName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("secretName"), "secretName must be set"))
+	} else {
+		allErrs = append(allErrs, validateStorageSecretName(config.SecretName, fldPath.Child("secretName"))...)
+	}
+	if config.TLSClientSecretName != nil {
+		allErrs = append(allErrs, validateStorageSecretName(*config.TLSClientSecretName, fldPath.Child("tlsClientSecretName"))...)
+	}
+	return allErrs
+}
+
+// validateBRConfig validates the cluster reference of BR and the databases and tables to back up or restore
+func validateBRConfig(br *v1alpha1.BRConfig, typ v1alpha1.BackupType, tableFilter []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	brPath := fldPath.Child("br")
+	if br.Cluster == "" {
+		allErrs = append(allErrs, field.Required(brPath.Child("cluster"), "cluster must be set"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(br.Cluster) {
+			allErrs = append(allErrs, field.Invalid(brPath.Child("cluster"), br.Cluster, msg))
+		}
+	}
+	if br.ClusterNamespace != "" {
+		for _, msg := range validation.IsDNS1123Label(br.ClusterNamespace) {
+			allErrs = append(allErrs, field.Invalid(brPath.Child("clusterNamespace"), br.ClusterNamespace, msg))
+		}
+	}
+
+	typePath := fldPath.Child("backupType")
+	switch typ {
+	case "", v1alpha1.BackupTypeFull:
+	case v1alpha1.BackupTypeDB, v1alpha1.BackupTypeTable:
+		if br.DB == "" {
+			allErrs = append(allErrs, field.Required(brPath.Child("db"), "db must be set for backupType "+string(typ)))
+		}
+		if typ == v1alpha1.BackupTypeTable && br.Table == "" {
+			allErrs = append(allErrs, field.Required(brPath.Child("table"), "table must be set for backupType table"))
+		}
+		// BR only accepts the table filter for the full backup and restore
+		if len(tableFilter) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("tableFilter"), "tableFilter can't be used with backupType "+string(typ)))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(typePath, typ,
+			[]string{string(v1alpha1.BackupTypeFull), string(v1alpha1.BackupTypeDB), string(v1alpha1.BackupTypeTable)}))
+	}
+	return allErrs
+}
+
+// validateDumplingConfig validates the options of dumpling
+func validateDumplingConfig(dumpling *v1alpha1.DumplingConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch dumpling.FileType {
+	case "", "sql", "csv":
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("fileType"), dumpling.FileType, []string{"sql", "csv"}))
+	}
+	if dumpling.Threads != nil && *dumpling.Threads <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("threads"), *dumpling.Threads, "must be greater than 0"))
+	}
+	if dumpling.Rows != nil && *dumpling.Rows <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rows"), *dumpling.Rows, "must be greater than 0"))
+	}
+	switch dumpling.Consistency {
+	case "", "auto", "snapshot", "lock", "flush", "none":
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("consistency"), dumpling.Consistency, []string{"auto", "snapshot", "lock", "flush", "none"}))
+	}
+	return allErrs
+}
@@ -0,0 +1,201 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func errorFields(errs field.ErrorList) []string {
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	return fields
+}
+
+func TestValidateBackup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newBackup := func() *v1alpha1.Backup {
+		return &v1alpha1.Backup{
+			Spec: v1alpha1.BackupSpec{
+				StorageProvider: v1alpha1.StorageProvider{
+					S3: &v1alpha1.S3StorageProvider{
+						Provider:   v1alpha1.S3StorageProviderTypeAWS,
+						Bucket:     "tidb-backup",
+						Prefix:     "daily/2022",
+						SecretName: "s3-secret",
+					},
+				},
+				BR: &v1alpha1.BRConfig{Cluster: "basic", ClusterNamespace: "tidb"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(backup *v1alpha1.Backup)
+		fields []string
+	}{
+		{
+			name:   "valid br backup",
+			modify: func(backup *v1alpha1.Backup) {},
+		},
+		{
+			name: "valid dumpling backup",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.BR = nil
+				backup.Spec.From = &v1alpha1.TiDBAccessConfig{Host: "basic-tidb", SecretName: "backup-secret"}
+				backup.Spec.StorageSize = "10Gi"
+				backup.Spec.Dumpling = &v1alpha1.DumplingConfig{FileType: "csv", Threads: pointer.Int32Ptr(8)}
+			},
+		},
+		{
+			name: "no storage provider",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.S3 = nil
+			},
+			fields: []string{"spec"},
+		},
+		{
+			name: "multiple storage providers",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.Gcs = &v1alpha1.GcsStorageProvider{ProjectId: "tidb", Bucket: "tidb-backup"}
+			},
+			fields: []string{"spec"},
+		},
+		{
+			name: "invalid s3 bucket, prefix and secret",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.S3.Bucket = "TiDB_Backup"
+				backup.Spec.S3.Prefix = "../backup"
+				backup.Spec.S3.SecretName = "S3"
+				backup.Spec.S3.Endpoint = "minio:9000"
+			},
+			fields: []string{"spec.s3.bucket", "spec.s3.prefix", "spec.s3.secretName", "spec.s3.endpoint"},
+		},
+		{
+			name: "s3 compatible storage",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.S3.Provider = v1alpha1.S3StorageProviderTypeCeph
+				backup.Spec.S3.Bucket = "TiDB_Backup"
+				backup.Spec.S3.Endpoint = "http://ceph:7480"
+			},
+		},
+		{
+			name: "invalid gcs and azblob",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.S3 = nil
+				backup.Spec.Gcs = &v1alpha1.GcsStorageProvider{Bucket: "tidb..backup"}
+			},
+			fields: []string{"spec.gcs.projectId", "spec.gcs.bucket"},
+		},
+		{
+			name: "invalid azblob container",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.S3 = nil
+				backup.Spec.Azblob = &v1alpha1.AzblobStorageProvider{Container: "tidb--backup"}
+			},
+			fields: []string{"spec.azblob.container"},
+		},
+		{
+			name: "invalid local storage",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.S3 = nil
+				backup.Spec.Local = &v1alpha1.LocalStorageProvider{
+					Volume:      corev1.Volume{Name: "nfs"},
+					VolumeMount: corev1.VolumeMount{Name: "backup", MountPath: "backup"},
+				}
+			},
+			fields: []string{"spec.local.volumeMount.name", "spec.local.volumeMount.mountPath"},
+		},
+		{
+			name: "invalid cluster reference",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.BR.Cluster = ""
+				backup.Spec.BR.ClusterNamespace = "TiDB"
+				backup.Spec.From = &v1alpha1.TiDBAccessConfig{}
+			},
+			fields: []string{"spec.br.cluster", "spec.br.clusterNamespace", "spec.from.host", "spec.from.secretName"},
+		},
+		{
+			name: "table backup",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.Type = v1alpha1.BackupTypeTable
+				backup.Spec.BR.DB = "test"
+				backup.Spec.TableFilter = []string{"test.*"}
+			},
+			fields: []string{"spec.br.table", "spec.tableFilter"},
+		},
+		{
+			name: "unsupported backup type",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.Type = "incremental"
+			},
+			fields: []string{"spec.backupType"},
+		},
+		{
+			name: "br with dumpling",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.Dumpling = &v1alpha1.DumplingConfig{}
+			},
+			fields: []string{"spec.dumpling"},
+		},
+		{
+			name: "invalid dumpling backup",
+			modify: func(backup *v1alpha1.Backup) {
+				backup.Spec.BR = nil
+				backup.Spec.Dumpling = &v1alpha1.DumplingConfig{FileType: "parquet", Rows: pointer.Int64Ptr(0), Consistency: "weak"}
+			},
+			fields: []string{"spec.from", "spec.storageSize", "spec.dumpling.fileType", "spec.dumpling.rows", "spec.dumpling.consistency"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backup := newBackup()
+			tt.modify(backup)
+			g.Expect(errorFields(ValidateBackup(backup))).To(ConsistOf(tt.fields))
+		})
+	}
+}
+
+func TestValidateRestore(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	restore := &v1alpha1.Restore{
+		Spec: v1alpha1.RestoreSpec{
+			StorageProvider: v1alpha1.StorageProvider{
+				Gcs: &v1alpha1.GcsStorageProvider{ProjectId: "tidb", Bucket: "tidb-backup", Prefix: "daily"},
+			},
+			BR: &v1alpha1.BRConfig{Cluster: "basic"},
+		},
+	}
+	g.Expect(ValidateRestore(restore)).To(BeEmpty())
+
+	// the checksums are calculated by TiDB
+	restore.Spec.VerifyChecksum = true
+	g.Expect(errorFields(ValidateRestore(restore))).To(ConsistOf("spec.to"))
+	restore.Spec.To = &v1alpha1.TiDBAccessConfig{Host: "basic-tidb", SecretName: "restore-secret"}
+	g.Expect(ValidateRestore(restore)).To(BeEmpty())
+
+	restore.Spec.BR = nil
+	g.Expect(errorFields(ValidateRestore(restore))).To(ConsistOf("spec.storageSize", "spec.verifyChecksum"))
+}
@@ -16,11 +16,15 @@ package validation
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,9 +32,14 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/prometheus/common/model"
+	"github.com/robfig/cron"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	utilnet "k8s.io/utils/net"
@@ -46,6 +55,9 @@ func ValidateTidbCluster(tc *v1alpha1.TidbCluster) field.ErrorList {
 	allErrs = append(allErrs, validateAnnotations(tc.ObjectMeta.Annotations, fldPath.Child("annotations"))...)
 	// validate spec
 	allErrs = append(allErrs, validateTiDBClusterSpec(&tc.Spec, field.NewPath("spec"))...)
+	if tc.Spec.ImagePolicy != nil {
+		allErrs = append(allErrs, validateImagePolicy(tc, field.NewPath("spec"))...)
+	}
 	return allErrs
 }
 
@@ -59,6 +71,9 @@ func ValidateDMCluster(dc *v1alpha1.DMCluster) field.ErrorList {
 	allErrs = append(allErrs, validateDMAnnotations(dc.ObjectMeta.Annotations, fldPath.Child("annotations"))...)
 	// validate spec
 	allErrs = append(allErrs, validateDMClusterSpec(&dc.Spec, field.NewPath("spec"))...)
+	if dc.Spec.ImagePolicy != nil {
+		allErrs = append(allErrs, validateDMImagePolicy(dc, field.NewPath("spec"))...)
+	}
 	return allErrs
 }
 
@@ -71,6 +86,107 @@ func ValidateTiDBNGMonitoring(tngm *v1alpha1.TidbNGMonitoring) field.ErrorList {
 	return allErrs
 }
 
+// ValidateTidbClusterMaintenance validates a TidbClusterMaintenance
+func ValidateTidbClusterMaintenance(tcm *v1alpha1.TidbClusterMaintenance) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateTidbClusterMaintenanceSpec(&tcm.Spec, field.NewPath("spec"))...)
+
+	return allErrs
+}
+
+// ValidateNodeMaintenance validates a NodeMaintenance
+func ValidateNodeMaintenance(nm *v1alpha1.NodeMaintenance) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if nm.Spec.NodeName == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "nodeName"), "must specify the node under maintenance"))
+	}
+
+	return allErrs
+}
+
+// ValidateTidbClusterOperation validates a TidbClusterOperation
+func ValidateTidbClusterOperation(tco *v1alpha1.TidbClusterOperation) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec")
+
+	spec := &tco.Spec
+	allErrs = append(allErrs, validateClusterRef(spec.Cluster.Namespace, spec.Cluster.Name, fldPath.Child("cluster"))...)
+	// the operation is granted by the RBAC of its own namespace, it must not operate the clusters in other namespaces
+	if spec.Cluster.Namespace != "" && spec.Cluster.Namespace != tco.Namespace {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cluster", "namespace"), spec.Cluster.Namespace,
+			fmt.Sprintf("must be empty or the namespace of the operation %q", tco.Namespace)))
+	}
+	switch spec.Type {
+	case v1alpha1.TidbClusterOperationEvictLeader, v1alpha1.TidbClusterOperationReplaceDisk:
+		if spec.PodName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("podName"), fmt.Sprintf("must specify the TiKV Pod of %s", spec.Type)))
+		}
+		if spec.Component != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("component"), fmt.Sprintf("not supported by %s", spec.Type)))
+		}
+	case v1alpha1.TidbClusterOperationRecoverFailover:
+		switch spec.Component {
+		case v1alpha1.TiKVMemberType, v1alpha1.TiFlashMemberType:
+		case "":
+			allErrs = append(allErrs, field.Required(fldPath.Child("component"), "must specify the component to recover"))
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("component"), spec.Component,
+				[]string{string(v1alpha1.TiKVMemberType), string(v1alpha1.TiFlashMemberType)}))
+		}
+		if spec.PodName != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("podName"), fmt.Sprintf("not supported by %s", spec.Type)))
+		}
+	case v1alpha1.TidbClusterOperationForceUpgrade:
+		if spec.PodName != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("podName"), fmt.Sprintf("not supported by %s", spec.Type)))
+		}
+		if spec.Component != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("component"), fmt.Sprintf("not supported by %s", spec.Type)))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), spec.Type, []string{
+			string(v1alpha1.TidbClusterOperationEvictLeader),
+			string(v1alpha1.TidbClusterOperationReplaceDisk),
+			string(v1alpha1.TidbClusterOperationRecoverFailover),
+			string(v1alpha1.TidbClusterOperationForceUpgrade),
+		}))
+	}
+	if spec.ReplaceDisk != nil && spec.Type != v1alpha1.TidbClusterOperationReplaceDisk {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("replaceDisk"), fmt.Sprintf("not supported by %s", spec.Type)))
+	}
+
+	return allErrs
+}
+
+// ValidateTidbClusterPreflight validates a TidbClusterPreflight
+func ValidateTidbClusterPreflight(pf *v1alpha1.TidbClusterPreflight) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec")
+
+	spec := &pf.Spec
+	if spec.Image == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("image"), "must specify the image of the check Jobs"))
+	}
+	validate := func(d *metav1.Duration, fldPath *field.Path) {
+		if d != nil && d.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, d.Duration.String(), "must be positive"))
+		}
+	}
+	validate(spec.MaxClockError, fldPath.Child("maxClockError"))
+	validate(spec.MaxNetworkLatency, fldPath.Child("maxNetworkLatency"))
+	validate(spec.Timeout, fldPath.Child("timeout"))
+	if storage := spec.Storage; storage != nil {
+		if storage.Size != nil && storage.Size.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storage", "size"), storage.Size.String(), "must be positive"))
+		}
+		validate(storage.MaxWriteLatency, fldPath.Child("storage", "maxWriteLatency"))
+	}
+
+	return allErrs
+}
+
 func ValidateTidbMonitor(monitor *v1alpha1.TidbMonitor) field.ErrorList {
 	allErrs := field.ErrorList{}
 	// validate monitor service
@@ -84,6 +200,113 @@ func ValidateTidbMonitor(monitor *v1alpha1.TidbMonitor) field.ErrorList {
 	if monitor.Spec.Persistent {
 		allErrs = append(allErrs, validateStorageInfo(monitor.Spec.Storage, field.NewPath("spec"))...)
 	}
+	allErrs = append(allErrs, validateTidbMonitorSpec(&monitor.Spec, field.NewPath("spec"))...)
+	if monitor.Spec.ImagePolicy != nil {
+		allErrs = append(allErrs, validateImagePolicyMappings(monitor.Spec.ImagePolicy, field.NewPath("spec", "imagePolicy"))...)
+	}
+	return allErrs
+}
+
+// validateTidbMonitorSpec validates the fields of TidbMonitor which are used to generate the configurations
+// of Prometheus, Thanos and Grafana, they fail to start with invalid configurations otherwise
+func validateTidbMonitorSpec(spec *v1alpha1.TidbMonitorSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(spec.Clusters) == 0 && (spec.DM == nil || len(spec.DM.Clusters) == 0) {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clusters"), "at least one TidbCluster or DMCluster must be monitored"))
+	}
+	for i, ref := range spec.Clusters {
+		allErrs = append(allErrs, validateClusterRef(ref.Namespace, ref.Name, fldPath.Child("clusters").Index(i))...)
+	}
+	if spec.DM != nil {
+		for i, ref := range spec.DM.Clusters {
+			allErrs = append(allErrs, validateClusterRef(ref.Namespace, ref.Name, fldPath.Child("dm", "clusters").Index(i))...)
+		}
+	}
+	if spec.Shards != nil && *spec.Shards < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("shards"), *spec.Shards, "must be greater than or equal to 1"))
+	}
+	if spec.Replicas != nil && *spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *spec.Replicas, "must be greater than or equal to 0"))
+	}
+	for k, v := range spec.ExternalLabels {
+		if !model.LabelName(k).IsValid() {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("externalLabels"), k, "must be a valid Prometheus label name"))
+		}
+		if !model.LabelValue(v).IsValid() {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("externalLabels").Key(k), v, "must be a valid UTF-8 string"))
+		}
+	}
+	if name := spec.ReplicaExternalLabelName; name != nil && *name != "" {
+		if !model.LabelName(*name).IsValid() {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("replicaExternalLabelName"), *name, "must be a valid Prometheus label name"))
+		} else if _, ok := spec.ExternalLabels[*name]; ok {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("replicaExternalLabelName"), *name, "must not be one of externalLabels"))
+		}
+	}
+	allErrs = append(allErrs, validateRemoteWrites(spec.Prometheus.RemoteWrite, fldPath.Child("prometheus", "remoteWrite"))...)
+	if spec.Thanos != nil && spec.Thanos.ObjectStorageConfig != nil && spec.Thanos.ObjectStorageConfigFile == nil {
+		allErrs = append(allErrs, validateSecretKeySelector(spec.Thanos.ObjectStorageConfig, fldPath.Child("thanos", "objectStorageConfig"))...)
+	}
+	if spec.Grafana != nil {
+		grafanaPath := fldPath.Child("grafana")
+		if spec.Grafana.UsernameSecret != nil {
+			allErrs = append(allErrs, validateSecretKeySelector(spec.Grafana.UsernameSecret, grafanaPath.Child("usernameSecret"))...)
+		}
+		if spec.Grafana.PasswordSecret != nil {
+			allErrs = append(allErrs, validateSecretKeySelector(spec.Grafana.PasswordSecret, grafanaPath.Child("passwordSecret"))...)
+		}
+	}
+	return allErrs
+}
+
+// validateClusterRef validates the reference to a TidbCluster or DMCluster, the namespace defaults to the
+// namespace of the TidbMonitor if it's empty
+func validateClusterRef(namespace, name string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), "name must not be empty"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), name, msg))
+		}
+	}
+	if namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), namespace, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateRemoteWrites validates the URLs, the queue names and the basic auth of the remote writes of Prometheus
+func validateRemoteWrites(remoteWrites []*v1alpha1.RemoteWriteSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.NewString()
+	for i, rw := range remoteWrites {
+		idxPath := fldPath.Index(i)
+		if rw == nil {
+			allErrs = append(allErrs, field.Required(idxPath, "remote write must not be null"))
+			continue
+		}
+		if u, err := url.Parse(rw.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("url"), rw.URL, "must be an absolute http or https URL"))
+		}
+		if rw.ProxyURL != nil && *rw.ProxyURL != "" {
+			if u, err := url.Parse(*rw.ProxyURL); err != nil || u.Scheme == "" || u.Host == "" {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("proxyUrl"), *rw.ProxyURL, "must be an absolute URL"))
+			}
+		}
+		if rw.Name != "" {
+			if names.Has(rw.Name) {
+				allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), rw.Name))
+			}
+			names.Insert(rw.Name)
+		}
+		if rw.BasicAuth != nil {
+			allErrs = append(allErrs, validateSecretKeySelector(&rw.BasicAuth.Username, idxPath.Child("basicAuth", "username"))...)
+			allErrs = append(allErrs, validateSecretKeySelector(&rw.BasicAuth.Password, idxPath.Child("basicAuth", "password"))...)
+		}
+	}
 	return allErrs
 }
 
@@ -114,6 +337,7 @@ func validateTiDBClusterSpec(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path
 	}
 	if spec.TiKV != nil {
 		allErrs = append(allErrs, validateTiKVSpec(spec.TiKV, fldPath.Child("tikv"))...)
+		allErrs = append(allErrs, validateTiKVStoreRole(spec, fldPath.Child("tikv", "storeRole"))...)
 	}
 	if spec.TiDB != nil {
 		allErrs = append(allErrs, validateTiDBSpec(spec.TiDB, fldPath.Child("tidb"))...)
@@ -127,233 +351,1742 @@ func validateTiDBClusterSpec(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path
 	if spec.TiCDC != nil {
 		allErrs = append(allErrs, validateTiCDCSpec(spec.TiCDC, fldPath.Child("ticdc"))...)
 	}
+	if spec.TiProxy != nil {
+		allErrs = append(allErrs, validateTiProxySpec(spec.TiProxy, fldPath.Child("tiproxy"))...)
+	}
 	if spec.PDAddresses != nil {
-		allErrs = append(allErrs, validatePDAddresses(spec.PDAddresses, fldPath.Child("pdAddresses"))...)
+		tlsEnabled := spec.TLSCluster != nil && spec.TLSCluster.Enabled
+		allErrs = append(allErrs, validatePDAddresses(spec.PDAddresses, tlsEnabled, fldPath.Child("pdAddresses"))...)
+	}
+	if spec.Helper != nil {
+		allErrs = append(allErrs, validateHelperSpec(spec.Helper, fldPath.Child("helper"))...)
+	}
+	allErrs = append(allErrs, validateComponentProbes(spec, fldPath)...)
+	if spec.PodMonitor != nil {
+		allErrs = append(allErrs, validatePodMonitor(spec.PodMonitor, fldPath.Child("podMonitor"))...)
+	}
+	if spec.ResourceReport != nil {
+		allErrs = append(allErrs, validateResourceReport(spec.ResourceReport, fldPath.Child("resourceReport"))...)
+	}
+	if spec.Recommendations != nil {
+		allErrs = append(allErrs, validateRecommendations(spec.Recommendations, fldPath.Child("recommendations"))...)
+	}
+	if spec.TopologyProfile != nil {
+		allErrs = append(allErrs, validateTopologyProfile(spec, fldPath.Child("topologyProfile"))...)
+	}
+	if spec.ResourceQuota != nil {
+		allErrs = append(allErrs, validateResourceQuota(spec.ResourceQuota, fldPath.Child("resourceQuota"))...)
+	}
+	if spec.OperatorPolicy != nil {
+		allErrs = append(allErrs, validateOperatorPolicy(spec.OperatorPolicy, fldPath.Child("operatorPolicy"))...)
+	}
+	if spec.UpgradePolicy != nil {
+		allErrs = append(allErrs, validateUpgradePolicy(spec.UpgradePolicy, fldPath.Child("upgradePolicy"))...)
+	}
+	if spec.SQLProbe != nil {
+		if spec.TiDB == nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("sqlProbe"), "the SQL probe requires spec.tidb"))
+		}
+		allErrs = append(allErrs, validateSQLProbe(spec.SQLProbe, fldPath.Child("sqlProbe"))...)
+	}
+	if spec.EventThrottling != nil {
+		if spec.TiKV == nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("eventThrottling"), "the event throttling requires spec.tikv"))
+		}
+		allErrs = append(allErrs, validateEventThrottling(spec.EventThrottling, fldPath.Child("eventThrottling"))...)
+	}
+	if spec.TopologyExport != nil {
+		allErrs = append(allErrs, validateTopologyExport(spec.TopologyExport, fldPath.Child("topologyExport"))...)
+	}
+	if spec.DNS != nil {
+		allErrs = append(allErrs, validateDNS(spec.DNS, fldPath.Child("dns"))...)
 	}
+	if spec.PersistentIdentity != nil {
+		if spec.PD == nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("persistentIdentity"), "the persistent identity requires spec.pd"))
+		}
+		allErrs = append(allErrs, validatePersistentIdentity(spec.PersistentIdentity, fldPath.Child("persistentIdentity"))...)
+	}
+	if spec.DiskWatchdog != nil {
+		allErrs = append(allErrs, validateDiskWatchdog(spec.DiskWatchdog, fldPath.Child("diskWatchdog"))...)
+	}
+	allErrs = append(allErrs, validateScheduling(spec.NodeSelector, spec.Affinity, spec.Tolerations, fldPath)...)
+	allErrs = append(allErrs, validateComponentResources(spec, fldPath)...)
 	return allErrs
 }
 
-func validateDiscoverySpec(spec v1alpha1.DiscoverySpec, fldPath *field.Path) field.ErrorList {
+// validateTLSClient validates that the settings of the TLS between TiDB and MySQL clients are only
+// set when tidb.tlsClient is enabled, as they're ignored silently otherwise. It's only run for the new
+// clusters and the changes of the settings, as the existing clusters may have the ignored settings.
+func validateTLSClient(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	if spec.ComponentSpec != nil {
-		allErrs = append(allErrs, validateComponentSpec(spec.ComponentSpec, fldPath)...)
+	tlsClientEnabled := spec.TiDB != nil && spec.TiDB.IsTLSClientEnabled()
+	if spec.TiDB != nil && spec.TiDB.TLSClient != nil && !tlsClientEnabled {
+		tlsClientPath := fldPath.Child("tidb", "tlsClient")
+		if spec.TiDB.TLSClient.DisableClientAuthn {
+			allErrs = append(allErrs, field.Invalid(tlsClientPath.Child("disableClientAuthn"), true, "must not be set if tlsClient is not enabled"))
+		}
+		if spec.TiDB.TLSClient.SkipInternalClientCA {
+			allErrs = append(allErrs, field.Invalid(tlsClientPath.Child("skipInternalClientCA"), true, "must not be set if tlsClient is not enabled"))
+		}
+	}
+	if spec.PD != nil && spec.PD.TLSClientSecretName != nil {
+		secretPath := fldPath.Child("pd", "tlsClientSecretName")
+		name := *spec.PD.TLSClientSecretName
+		if !tlsClientEnabled {
+			allErrs = append(allErrs, field.Invalid(secretPath, name, "the MySQL client secret is only used if tidb.tlsClient is enabled"))
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			allErrs = append(allErrs, field.Invalid(secretPath, name, msg))
+		}
 	}
 	return allErrs
 }
 
-func validatePDSpec(spec *v1alpha1.PDSpec, fldPath *field.Path) field.ErrorList {
+// tlsClientChanged returns whether the settings of the TLS between TiDB and MySQL clients are changed
+func tlsClientChanged(old, spec *v1alpha1.TidbClusterSpec) bool {
+	tlsClient := func(spec *v1alpha1.TidbClusterSpec) *v1alpha1.TiDBTLSClient {
+		if spec.TiDB == nil {
+			return nil
+		}
+		return spec.TiDB.TLSClient
+	}
+	secretName := func(spec *v1alpha1.TidbClusterSpec) *string {
+		if spec.PD == nil {
+			return nil
+		}
+		return spec.PD.TLSClientSecretName
+	}
+	return !reflect.DeepEqual(tlsClient(old), tlsClient(spec)) || !reflect.DeepEqual(secretName(old), secretName(spec))
+}
+
+// validateResourceReport validates the Prometheus to query and the window and interval of the reports
+func validateResourceReport(spec *v1alpha1.ResourceReportSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	allErrs = append(allErrs, validateRequestsStorage(spec.ResourceRequirements.Requests, fldPath)...)
-	if len(spec.StorageVolumes) > 0 {
-		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	if spec.PrometheusURL != "" {
+		if u, err := url.Parse(spec.PrometheusURL); err != nil || u.Scheme == "" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("prometheusURL"), spec.PrometheusURL, "must be an absolute URL, e.g. http://prometheus:9090"))
+		}
+	} else if spec.MonitorName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("monitorName"), "either monitorName or prometheusURL must be set"))
 	}
-	if spec.Service != nil {
-		allErrs = append(allErrs, validateService(spec.Service, fldPath)...)
+	if spec.Window != "" {
+		allErrs = append(allErrs, validatePromDurationStr(&spec.Window, fldPath.Child("window"))...)
 	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.Interval, fldPath.Child("interval"))...)
 	return allErrs
 }
 
-func validatePDAddresses(arrayOfAddresses []string, fldPath *field.Path) field.ErrorList {
+// validateRecommendations validates the Prometheus to query, the window and interval of the
+// computations and the targets of the recommendations
+func validateRecommendations(spec *v1alpha1.RecommendationsSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	for i, address := range arrayOfAddresses {
-		idxPath := fldPath.Index(i)
-		u, err := url.Parse(address)
-		example := " PD address format example: http://{ADDRESS}:{PORT}"
-		if err != nil {
-			allErrs = append(allErrs, field.Invalid(idxPath, address, err.Error()+example))
-		} else if u.Scheme != "http" {
-			allErrs = append(allErrs, field.Invalid(idxPath, address, "Support 'http' scheme only."+example))
+	if spec.PrometheusURL != "" {
+		if u, err := url.Parse(spec.PrometheusURL); err != nil || u.Scheme == "" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("prometheusURL"), spec.PrometheusURL, "must be an absolute URL, e.g. http://prometheus:9090"))
 		}
+	} else if spec.MonitorName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("monitorName"), "either monitorName or prometheusURL must be set"))
+	}
+	if spec.Window != "" {
+		allErrs = append(allErrs, validatePromDurationStr(&spec.Window, fldPath.Child("window"))...)
+	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.Interval, fldPath.Child("interval"))...)
+	if v := spec.TiDBTargetCPUUtilization; v != nil && (*v < 1 || *v > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("tidbTargetCPUUtilization"), *v, "must be in the range of [1, 100]"))
+	}
+	if v := spec.TiKVMinStorageHeadroom; v != nil && (*v < 0 || *v > 99) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("tikvMinStorageHeadroom"), *v, "must be in the range of [0, 99]"))
 	}
 	return allErrs
 }
 
-func validateTiKVSpec(spec *v1alpha1.TiKVSpec, fldPath *field.Path) field.ErrorList {
+// imageDigestRegexp matches the digests of images, e.g. sha256:<64 hex digits>
+var imageDigestRegexp = regexp.MustCompile(`^[a-z0-9]+:[a-f0-9]{32,}$`)
+
+// validateImagePolicy validates the mirrors and digests of the image policy, and checks the
+// images of the components are pinned by digests if it's enforced
+func validateImagePolicy(tc *v1alpha1.TidbCluster, fldPath *field.Path) field.ErrorList {
+	policy := tc.Spec.ImagePolicy
+	allErrs := validateImagePolicyMappings(policy, fldPath.Child("imagePolicy"))
+
+	pumpImage := ""
+	if tc.PumpImage() != nil {
+		pumpImage = *tc.PumpImage()
+	}
+	for _, c := range []struct {
+		name  string
+		image string
+		spec  v1alpha1.ComponentAccessor
+	}{
+		{"pd", tc.PDImage(), tc.BasePDSpec()},
+		{"tikv", tc.TiKVImage(), tc.BaseTiKVSpec()},
+		{"tidb", tc.TiDBImage(), tc.BaseTiDBSpec()},
+		{"tiflash", tc.TiFlashImage(), tc.BaseTiFlashSpec()},
+		{"ticdc", tc.TiCDCImage(), tc.BaseTiCDCSpec()},
+		{"pump", pumpImage, tc.BasePumpSpec()},
+		{"tiproxy", tc.TiProxyImage(), tc.BaseTiProxySpec()},
+	} {
+		if c.image == "" {
+			continue
+		}
+		if err := policy.CheckDigestPinned(c.image, c.spec.NodeSelector()); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(c.name), c.image, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateDMImagePolicy validates the image policy of DMCluster as validateImagePolicy does
+func validateDMImagePolicy(dc *v1alpha1.DMCluster, fldPath *field.Path) field.ErrorList {
+	policy := dc.Spec.ImagePolicy
+	allErrs := validateImagePolicyMappings(policy, fldPath.Child("imagePolicy"))
+	if err := policy.CheckDigestPinned(dc.MasterImage(), dc.BaseMasterSpec().NodeSelector()); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("master"), dc.MasterImage(), err.Error()))
+	}
+	if dc.Spec.Worker != nil {
+		if err := policy.CheckDigestPinned(dc.WorkerImage(), dc.BaseWorkerSpec().NodeSelector()); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("worker"), dc.WorkerImage(), err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateImagePolicyMappings validates the mirrors and digests of the image policy
+func validateImagePolicyMappings(policy *v1alpha1.ImagePolicy, policyPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	allErrs = append(allErrs, validateRequestsStorage(spec.ResourceRequirements.Requests, fldPath)...)
-	if len(spec.DataSubDir) > 0 {
-		allErrs = append(allErrs, validateLocalDescendingPath(spec.DataSubDir, fldPath.Child("dataSubDir"))...)
+	for k, v := range policy.RegistryMirrors {
+		if k == "" || v == "" || strings.ContainsAny(k+v, "@") {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("registryMirrors").Key(k), v, "the registries and the mirrors must be non-empty and without digests"))
+		}
 	}
-	if len(spec.StorageVolumes) > 0 {
-		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	for k, v := range policy.Digests {
+		if !imageDigestRegexp.MatchString(v) {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("digests").Key(k), v, "must be a digest like sha256:<hex>"))
+		}
 	}
-	if spec.ShouldSeparateRaftLog() && spec.RaftLogVolumeName != "" {
-		allErrs = append(allErrs, validateVolumeName(spec.RaftLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
+	return allErrs
+}
+
+// validatePodMonitor validates the labels and the scrape interval of the PodMonitors
+func validatePodMonitor(spec *v1alpha1.PodMonitorSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for k, v := range spec.Labels {
+		for _, msg := range validation.IsQualifiedName(k) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("labels"), k, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(v) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("labels").Key(k), v, msg))
+		}
 	}
-	if spec.ShouldSeparateRocksDBLog() && spec.RocksDBLogVolumeName != "" {
-		allErrs = append(allErrs, validateVolumeName(spec.RocksDBLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
+	if spec.Interval != "" {
+		allErrs = append(allErrs, validatePromDurationStr(&spec.Interval, fldPath.Child("interval"))...)
 	}
-	allErrs = append(allErrs, validateTimeDurationStr(spec.EvictLeaderTimeout, fldPath.Child("evictLeaderTimeout"))...)
 	return allErrs
 }
 
-func validateTiFlashSpec(spec *v1alpha1.TiFlashSpec, fldPath *field.Path) field.ErrorList {
+// validateComponentProbes validates the probes of the components that honor `probes`
+func validateComponentProbes(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	allErrs = append(allErrs, validateTiFlashConfig(spec.Config, fldPath)...)
-	if len(spec.StorageClaims) < 1 {
-		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec.StorageClaims"),
-			spec.StorageClaims, "storageClaims should be configured at least one item."))
+	tlsCluster := spec.TLSCluster != nil && spec.TLSCluster.Enabled
+	if spec.PD != nil {
+		allErrs = append(allErrs, validateProbes(spec.PD.Probes, tlsCluster, false, fldPath.Child("pd", "probes"))...)
+	}
+	if spec.TiKV != nil {
+		allErrs = append(allErrs, validateProbes(spec.TiKV.Probes, tlsCluster, false, fldPath.Child("tikv", "probes"))...)
+	}
+	if spec.TiDB != nil {
+		allErrs = append(allErrs, validateProbes(spec.TiDB.Probes, tlsCluster, true, fldPath.Child("tidb", "probes"))...)
+	}
+	if spec.TiFlash != nil {
+		allErrs = append(allErrs, validateProbes(spec.TiFlash.Probes, tlsCluster, false, fldPath.Child("tiflash", "probes"))...)
+	}
+	if spec.TiCDC != nil {
+		allErrs = append(allErrs, validateProbes(spec.TiCDC.Probes, tlsCluster, false, fldPath.Child("ticdc", "probes"))...)
+	}
+	if spec.TiProxy != nil {
+		allErrs = append(allErrs, validateProbes(spec.TiProxy.Probes, tlsCluster, false, fldPath.Child("tiproxy", "probes"))...)
+	}
+	if spec.Pump != nil {
+		allErrs = append(allErrs, validateProbes(spec.Pump.Probes, tlsCluster, false, fldPath.Child("pump", "probes"))...)
+	}
+	return allErrs
+}
+
+// validateProbesUnsupported rejects the probes of the components that don't honor `probes`
+func validateProbesUnsupported(spec *v1alpha1.ComponentSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec != nil && spec.Probes != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("probes"), "probes are not supported by the component"))
+	}
+	return allErrs
+}
+
+func validateProbes(probes *v1alpha1.Probes, tlsCluster, commandSupported bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if probes == nil {
+		return allErrs
+	}
+	allErrs = append(allErrs, validateProbe(probes.Readiness, tlsCluster, commandSupported, false, fldPath.Child("readiness"))...)
+	allErrs = append(allErrs, validateProbe(probes.Liveness, tlsCluster, commandSupported, true, fldPath.Child("liveness"))...)
+	allErrs = append(allErrs, validateProbe(probes.Startup, tlsCluster, commandSupported, true, fldPath.Child("startup"))...)
+	return allErrs
+}
+
+// validateProbe validates a probe, singleSuccess is true for the liveness and startup probes
+// whose successThreshold must be 1
+func validateProbe(probe *v1alpha1.Probe, tlsCluster, commandSupported, singleSuccess bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if probe == nil {
+		return allErrs
+	}
+	if probe.Type != nil {
+		supported := []string{v1alpha1.TCPProbeType, v1alpha1.HTTPProbeType}
+		if commandSupported {
+			supported = append(supported, v1alpha1.CommandProbeType)
+		}
+		switch {
+		case !sets.NewString(supported...).Has(*probe.Type):
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), *probe.Type, supported))
+		case *probe.Type == v1alpha1.HTTPProbeType && tlsCluster:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("type"), *probe.Type, "http probe can not be used when TLS is enabled for the cluster"))
+		}
+	}
+	for _, f := range []struct {
+		name  string
+		value *int32
+	}{
+		{"timeoutSeconds", probe.TimeoutSeconds},
+		{"periodSeconds", probe.PeriodSeconds},
+		{"successThreshold", probe.SuccessThreshold},
+		{"failureThreshold", probe.FailureThreshold},
+	} {
+		if f.value != nil && *f.value < 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(f.name), *f.value, "must be greater than or equal to 1"))
+		}
+	}
+	if probe.InitialDelaySeconds != nil && *probe.InitialDelaySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("initialDelaySeconds"), *probe.InitialDelaySeconds, "must be greater than or equal to 0"))
+	}
+	if singleSuccess && probe.SuccessThreshold != nil && *probe.SuccessThreshold != 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("successThreshold"), *probe.SuccessThreshold, "must be 1 for liveness and startup probes"))
+	}
+	if probe.TimeoutSeconds != nil && probe.PeriodSeconds != nil && *probe.TimeoutSeconds > *probe.PeriodSeconds {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeoutSeconds"), *probe.TimeoutSeconds, "must not be greater than periodSeconds"))
+	}
+	return allErrs
+}
+
+func validateHelperSpec(spec *v1alpha1.HelperSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Image != nil && len(*spec.Image) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("image"), "empty image"))
+	}
+	allErrs = append(allErrs, validateInitContainerSpec(spec.Initializer, fldPath.Child("initializer"))...)
+	return allErrs
+}
+
+func validateInitContainerSpec(spec *v1alpha1.InitContainerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec == nil {
+		return allErrs
+	}
+	if spec.Image != nil && len(*spec.Image) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("image"), "empty image"))
+	}
+	if spec.ImagePullPolicy != nil {
+		switch *spec.ImagePullPolicy {
+		case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("imagePullPolicy"), *spec.ImagePullPolicy,
+				[]string{string(corev1.PullAlways), string(corev1.PullIfNotPresent), string(corev1.PullNever)}))
+		}
+	}
+	for i, cmd := range spec.ExtraCommands {
+		if len(strings.TrimSpace(cmd)) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("extraCommands").Index(i), "empty command"))
+		}
+	}
+	allErrs = append(allErrs, validateResourceRequirements(spec.ResourceRequirements, fldPath)...)
+	return allErrs
+}
+
+// maxLimitRequestRatio is the max ratio of the cpu or memory limit to the request, a larger ratio is
+// likely a typo of the unit, e.g. 4Mi instead of 4Gi
+const maxLimitRequestRatio = 100
+
+// validateResourceRequirements rejects the requests greater than the limits, which are refused by
+// the API server when the StatefulSet creates Pods
+func validateResourceRequirements(req corev1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for name, request := range req.Requests {
+		limit, ok := req.Limits[name]
+		if ok && request.Cmp(limit) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("requests").Key(string(name)), request.String(),
+				fmt.Sprintf("must be less than or equal to %s limit %s", name, limit.String())))
+		}
+	}
+	return allErrs
+}
+
+// validateLimitRequestRatio rejects the limits far greater than the requests
+func validateLimitRequestRatio(req corev1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for name, request := range req.Requests {
+		limit, ok := req.Limits[name]
+		if !ok {
+			continue
+		}
+		var r, l int64
+		switch name {
+		case corev1.ResourceCPU:
+			r, l = request.MilliValue(), limit.MilliValue()
+		case corev1.ResourceMemory:
+			r, l = request.Value(), limit.Value()
+		default:
+			continue
+		}
+		if r > 0 && l/r > maxLimitRequestRatio {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("limits").Key(string(name)), limit.String(),
+				fmt.Sprintf("must not be more than %d times %s request %s", maxLimitRequestRatio, name, request.String())))
+		}
+	}
+	return allErrs
+}
+
+// componentResources returns the resource requirements of the components by their names
+func componentResources(spec *v1alpha1.TidbClusterSpec) map[string]corev1.ResourceRequirements {
+	resources := map[string]corev1.ResourceRequirements{}
+	if spec.PD != nil {
+		resources["pd"] = spec.PD.ResourceRequirements
+	}
+	if spec.TiKV != nil {
+		resources["tikv"] = spec.TiKV.ResourceRequirements
+	}
+	if spec.TiDB != nil {
+		resources["tidb"] = spec.TiDB.ResourceRequirements
+	}
+	if spec.TiFlash != nil {
+		resources["tiflash"] = spec.TiFlash.ResourceRequirements
+	}
+	if spec.TiCDC != nil {
+		resources["ticdc"] = spec.TiCDC.ResourceRequirements
+	}
+	if spec.Pump != nil {
+		resources["pump"] = spec.Pump.ResourceRequirements
+	}
+	if spec.TiProxy != nil {
+		resources["tiproxy"] = spec.TiProxy.ResourceRequirements
+	}
+	return resources
+}
+
+// validateComponentResources validates the resource requirements of the components
+func validateComponentResources(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	resources := componentResources(spec)
+	for _, name := range sortedKeys(resources) {
+		allErrs = append(allErrs, validateResourceRequirements(resources[name], fldPath.Child(name))...)
+	}
+	return allErrs
+}
+
+// validateComponentLimitRequestRatios validates the ratios of the limits to the requests of the components
+// when a cluster is created, or when the resource requirements of a component are changed, so that the
+// existing clusters are not refused for the unchanged resources
+func validateComponentLimitRequestRatios(old, spec *v1alpha1.TidbClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	var oldResources map[string]corev1.ResourceRequirements
+	if old != nil {
+		oldResources = componentResources(old)
+	}
+	resources := componentResources(spec)
+	for _, name := range sortedKeys(resources) {
+		if oldReq, ok := oldResources[name]; ok && apiequality.Semantic.DeepEqual(oldReq, resources[name]) {
+			continue
+		}
+		allErrs = append(allErrs, validateLimitRequestRatio(resources[name], fldPath.Child(name))...)
+	}
+	return allErrs
+}
+
+// sortedKeys returns the keys of the resources in order
+func sortedKeys(resources map[string]corev1.ResourceRequirements) []string {
+	keys := make([]string, 0, len(resources))
+	for k := range resources {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateDiscoverySpec(spec v1alpha1.DiscoverySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.ComponentSpec != nil {
+		allErrs = append(allErrs, validateComponentSpec(spec.ComponentSpec, v1alpha1.DiscoveryMemberType, fldPath)...)
+	}
+	allErrs = append(allErrs, validateProbesUnsupported(spec.ComponentSpec, fldPath)...)
+	return allErrs
+}
+
+func validatePDSpec(spec *v1alpha1.PDSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.PDMemberType, fldPath)...)
+	allErrs = append(allErrs, validateRequestsStorage(spec.ResourceRequirements.Requests, fldPath)...)
+	if len(spec.StorageVolumes) > 0 {
+		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	}
+	allErrs = append(allErrs, validateMountPaths(spec.StorageVolumes, spec.AdditionalVolumeMounts, reservedMountPathsOf[v1alpha1.PDMemberType], fldPath)...)
+	if spec.Service != nil {
+		allErrs = append(allErrs, validateService(spec.Service, fldPath)...)
+	}
+	allErrs = append(allErrs, validateStartScriptHooks(spec.StartScriptHooks, fldPath.Child("startScriptHooks"))...)
+	allErrs = append(allErrs, validateScheduleProfiles(spec.ScheduleProfiles, fldPath.Child("scheduleProfiles"))...)
+	return allErrs
+}
+
+// validatePDAddresses validates the external PD addresses, the scheme must be https if the TLS between
+// components is enabled and http otherwise, the trailing slashes are trimmed by defaulting
+func validatePDAddresses(arrayOfAddresses []string, tlsEnabled bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	scheme := "http"
+	if tlsEnabled {
+		scheme = "https"
+	}
+	example := fmt.Sprintf(" PD address format example: %s://{ADDRESS}:{PORT}", scheme)
+	for i, address := range arrayOfAddresses {
+		idxPath := fldPath.Index(i)
+		u, err := url.Parse(address)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath, address, err.Error()+example))
+			continue
+		}
+		switch {
+		case u.Scheme != scheme:
+			msg := "Support 'http' scheme only if TLS between components is not enabled."
+			if tlsEnabled {
+				msg = "Support 'https' scheme only if TLS between components is enabled."
+			}
+			allErrs = append(allErrs, field.Invalid(idxPath, address, msg+example))
+		case u.Hostname() == "":
+			allErrs = append(allErrs, field.Invalid(idxPath, address, "Host is required."+example))
+		case u.Port() == "":
+			allErrs = append(allErrs, field.Invalid(idxPath, address, "Port is required."+example))
+		case strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "":
+			allErrs = append(allErrs, field.Invalid(idxPath, address, "Path, query and fragment are not allowed."+example))
+		default:
+			for _, msg := range validation.IsValidPortNum(portNum(u.Port())) {
+				allErrs = append(allErrs, field.Invalid(idxPath, address, msg+"."+example))
+			}
+		}
+	}
+	return allErrs
+}
+
+// portNum returns the port number of a port string, it returns 0 if the string is not a number
+func portNum(port string) int {
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func validateTiKVSpec(spec *v1alpha1.TiKVSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.TiKVMemberType, fldPath)...)
+	allErrs = append(allErrs, validateRequestsStorage(spec.ResourceRequirements.Requests, fldPath)...)
+	if len(spec.DataSubDir) > 0 {
+		allErrs = append(allErrs, validateLocalDescendingPath(spec.DataSubDir, fldPath.Child("dataSubDir"))...)
+	}
+	if len(spec.StorageVolumes) > 0 {
+		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	}
+	allErrs = append(allErrs, validateMountPaths(spec.StorageVolumes, spec.AdditionalVolumeMounts, reservedMountPathsOf[v1alpha1.TiKVMemberType], fldPath)...)
+	if spec.ShouldSeparateRaftLog() && spec.RaftLogVolumeName != "" {
+		allErrs = append(allErrs, validateVolumeName(spec.RaftLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
+	}
+	if spec.ShouldSeparateRocksDBLog() && spec.RocksDBLogVolumeName != "" {
+		allErrs = append(allErrs, validateVolumeName(spec.RocksDBLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
+	}
+	allErrs = append(allErrs, validateTimeDurationStr(spec.EvictLeaderTimeout, fldPath.Child("evictLeaderTimeout"))...)
+	allErrs = append(allErrs, validatePodNames(spec.EvictLeader, fldPath.Child("evictLeader"))...)
+	allErrs = append(allErrs, validatePerformanceProfile(spec.PerformanceProfile, spec.ResourceRequirements, fldPath.Child("performanceProfile"))...)
+	allErrs = append(allErrs, validateStartScriptHooks(spec.StartScriptHooks, fldPath.Child("startScriptHooks"))...)
+	allErrs = append(allErrs, validateTiKVStoreRelocation(spec.StoreRelocation, fldPath.Child("storeRelocation"))...)
+	allErrs = append(allErrs, validateStoreCapacity(spec.StoreCapacity, fldPath.Child("storeCapacity"))...)
+	return allErrs
+}
+
+// validateStoreCapacity validates the capacity override of the stores is positive
+func validateStoreCapacity(capacity *resource.Quantity, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if capacity != nil && capacity.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, capacity.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// minTiKVStoreRelocationPendingThreshold is the lower bound of the pending threshold of the store relocation,
+// so that the stores are not replaced when the nodes are rebooted or upgraded
+const minTiKVStoreRelocationPendingThreshold = 5 * time.Minute
+
+func validateTiKVStoreRelocation(relocation *v1alpha1.TiKVStoreRelocation, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if relocation == nil || relocation.PendingThreshold == nil {
+		return allErrs
+	}
+	if t := relocation.PendingThreshold.Duration; t < minTiKVStoreRelocationPendingThreshold {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("pendingThreshold"), t.String(),
+			fmt.Sprintf("must not be less than %s", minTiKVStoreRelocationPendingThreshold)))
+	}
+	return allErrs
+}
+
+// validatePerformanceProfile validates the performance profile against the resource requirements of the component
+func validatePerformanceProfile(profile *v1alpha1.PerformanceProfile, req corev1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if profile == nil {
+		return allErrs
+	}
+	_, hasCPU := req.Requests[corev1.ResourceCPU]
+	if _, ok := req.Limits[corev1.ResourceCPU]; ok {
+		hasCPU = true
+	}
+	_, hasMemory := req.Requests[corev1.ResourceMemory]
+	if _, ok := req.Limits[corev1.ResourceMemory]; ok {
+		hasMemory = true
+	}
+
+	if hugePages := profile.HugePages; hugePages != nil {
+		hugePagesPath := fldPath.Child("hugePages")
+		if hugePages.PageSize != "2Mi" && hugePages.PageSize != "1Gi" {
+			allErrs = append(allErrs, field.NotSupported(hugePagesPath.Child("pageSize"), hugePages.PageSize, []string{"2Mi", "1Gi"}))
+		} else if pageSize := resource.MustParse(hugePages.PageSize); hugePages.Size.Sign() <= 0 || hugePages.Size.Value()%pageSize.Value() != 0 {
+			allErrs = append(allErrs, field.Invalid(hugePagesPath.Child("size"), hugePages.Size.String(), "must be a positive multiple of pageSize"))
+		}
+		// the kubelet rejects the Pods requesting hugepages without requesting cpu or memory
+		if !hasCPU && !hasMemory {
+			allErrs = append(allErrs, field.Required(hugePagesPath, "requests of cpu or memory are required to request hugepages"))
+		}
+	}
+	if profile.DedicatedCPUs {
+		if !hasCPU {
+			allErrs = append(allErrs, field.Required(fldPath.Child("dedicatedCPUs"), "requests of cpu are required to dedicate CPUs"))
+		}
+		if !hasMemory {
+			allErrs = append(allErrs, field.Required(fldPath.Child("dedicatedCPUs"), "requests of memory are required to dedicate CPUs"))
+		}
+	}
+	return allErrs
+}
+
+// validatePodNames validates that the names of pods are valid and unique
+func validatePodNames(podNames []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	seen := map[string]struct{}{}
+	for i, podName := range podNames {
+		idxPath := fldPath.Index(i)
+		for _, msg := range validation.IsDNS1123Subdomain(podName) {
+			allErrs = append(allErrs, field.Invalid(idxPath, podName, msg))
+		}
+		if _, ok := seen[podName]; ok {
+			allErrs = append(allErrs, field.Duplicate(idxPath, podName))
+		}
+		seen[podName] = struct{}{}
+	}
+	return allErrs
+}
+
+func validateTiFlashSpec(spec *v1alpha1.TiFlashSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.TiFlashMemberType, fldPath)...)
+	allErrs = append(allErrs, validateTiFlashConfig(spec.Config, fldPath)...)
+	allErrs = append(allErrs, validateInitContainerSpec(spec.Initializer, fldPath.Child("initializer"))...)
+	allErrs = append(allErrs, validateStoreCapacity(spec.StoreCapacity, fldPath.Child("storeCapacity"))...)
+	if len(spec.StorageClaims) < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec.StorageClaims"),
+			spec.StorageClaims, "storageClaims should be configured at least one item."))
+	}
+	// the storage claims are mounted to /data0, /data1, ...
+	tiflashReserved := reservedMountPathsOf[v1alpha1.TiFlashMemberType]
+	for i := range spec.StorageClaims {
+		tiflashReserved = append(tiflashReserved, fmt.Sprintf("/data%d", i))
+	}
+	allErrs = append(allErrs, validateMountPaths(nil, spec.AdditionalVolumeMounts, tiflashReserved, fldPath)...)
+	allErrs = append(allErrs, validatePerformanceProfile(spec.PerformanceProfile, spec.ResourceRequirements, fldPath.Child("performanceProfile"))...)
+	allErrs = append(allErrs, validateStartScriptHooks(spec.StartScriptHooks, fldPath.Child("startScriptHooks"))...)
+	return allErrs
+}
+
+func validateTiCDCSpec(spec *v1alpha1.TiCDCSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.TiCDCMemberType, fldPath)...)
+	if spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), spec.Replicas, "must be greater than or equal to 0"))
+	}
+	if len(spec.StorageVolumes) > 0 {
+		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	}
+	allErrs = append(allErrs, validateMountPaths(spec.StorageVolumes, spec.AdditionalVolumeMounts, reservedMountPathsOf[v1alpha1.TiCDCMemberType], fldPath)...)
+	allErrs = append(allErrs, validateTiCDCGracefulShutdown(spec, fldPath)...)
+	if spec.SortDirVolumeName != "" {
+		allErrs = append(allErrs, validateTiCDCSortDirVolumeName(spec, fldPath.Child("sortDirVolumeName"))...)
+	}
+	allErrs = append(allErrs, validateTiCDCChangefeeds(spec.Changefeeds, fldPath.Child("changefeeds"))...)
+	return allErrs
+}
+
+// validateTiCDCChangefeeds validates the IDs and the sinks of the changefeeds, the sink URIs in the
+// errors are masked
+func validateTiCDCChangefeeds(changefeeds []v1alpha1.TiCDCChangefeed, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	ids := sets.NewString()
+	for i, changefeed := range changefeeds {
+		idxPath := fldPath.Index(i)
+		if changefeed.ID == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("id"), "the id of the changefeed must be set"))
+		} else if ids.Has(changefeed.ID) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("id"), changefeed.ID))
+		}
+		ids.Insert(changefeed.ID)
+
+		uriPath := idxPath.Child("sink", "uri")
+		masked := v1alpha1.MaskSinkURI(changefeed.Sink.URI)
+		u, err := url.Parse(changefeed.Sink.URI)
+		if err != nil || u.Scheme == "" {
+			allErrs = append(allErrs, field.Invalid(uriPath, masked, "must be a valid URI with a scheme"))
+			continue
+		}
+		secret := changefeed.Sink.CredentialsSecret
+		if secret == nil {
+			continue
+		}
+		secretPath := idxPath.Child("sink", "credentialsSecret")
+		if secret.Name == "" {
+			allErrs = append(allErrs, field.Required(secretPath.Child("name"), "the name of the secret must be set"))
+		}
+		if !v1alpha1.SinkCredentialsSchemes.Has(u.Scheme) {
+			allErrs = append(allErrs, field.NotSupported(uriPath.Child("scheme"), u.Scheme, v1alpha1.SinkCredentialsSchemes.List()))
+		}
+		if masked != changefeed.Sink.URI {
+			allErrs = append(allErrs, field.Invalid(uriPath, masked, "the password must not be set in the URI if credentialsSecret is set"))
+		}
+	}
+	return allErrs
+}
+
+// validateTiCDCSortDirVolumeName validates the sort dir is a volume in storageVolumes with a mount path
+func validateTiCDCSortDirVolumeName(spec *v1alpha1.TiCDCSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, volume := range spec.StorageVolumes {
+		if volume.Name != spec.SortDirVolumeName {
+			continue
+		}
+		if volume.MountPath == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath, spec.SortDirVolumeName, "the mountPath of the volume must be set"))
+		}
+		return allErrs
+	}
+	allErrs = append(allErrs, field.Invalid(fldPath, spec.SortDirVolumeName, "can not find the volume in storageVolumes"))
+	return allErrs
+}
+
+const (
+	// maxTiCDCGracefulShutdownTimeout is the upper bound of the graceful shutdown timeout of a TiCDC pod,
+	// the scale-in or upgrade is blocked by the shutdown for at most the timeout
+	maxTiCDCGracefulShutdownTimeout = 24 * time.Hour
+	// maxTiCDCDrainRetryInterval is the upper bound of the interval between two drain attempts
+	maxTiCDCDrainRetryInterval = 10 * time.Minute
+)
+
+// validateTiCDCGracefulShutdown validates the graceful shutdown timeout and the drain retry interval,
+// both must be positive and the drain must be retried at least once before the timeout
+func validateTiCDCGracefulShutdown(spec *v1alpha1.TiCDCSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if t := spec.GracefulShutdownTimeout; t != nil {
+		if t.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("gracefulShutdownTimeout"), t.Duration.String(), "must be positive"))
+		} else if t.Duration > maxTiCDCGracefulShutdownTimeout {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("gracefulShutdownTimeout"), t.Duration.String(),
+				fmt.Sprintf("must not be greater than %s", maxTiCDCGracefulShutdownTimeout)))
+		}
+	}
+	if i := spec.DrainRetryInterval; i != nil {
+		if i.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("drainRetryInterval"), i.Duration.String(), "must be positive"))
+		} else if i.Duration > maxTiCDCDrainRetryInterval {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("drainRetryInterval"), i.Duration.String(),
+				fmt.Sprintf("must not be greater than %s", maxTiCDCDrainRetryInterval)))
+		} else if t := spec.GracefulShutdownTimeout; t != nil && t.Duration > 0 && i.Duration > t.Duration {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("drainRetryInterval"), i.Duration.String(),
+				"must not be greater than gracefulShutdownTimeout"))
+		}
+	}
+	return allErrs
+}
+
+func validateTiFlashConfig(config *v1alpha1.TiFlashConfigWraper, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if config == nil {
+		return allErrs
+	}
+
+	if config.Common != nil {
+		if v := config.Common.Get("flash.overlap_threshold"); v != nil {
+			if value, err := v.AsFloat(); err == nil {
+				if value < 0 || value > 1 {
+					allErrs = append(allErrs, field.Invalid(path.Child("config.config.flash.overlap_threshold"),
+						value,
+						"overlap_threshold must be in the range of [0,1]."))
+				}
+			} else {
+				allErrs = append(allErrs, field.Invalid(path.Child("config.config.flash.overlap_threshold"),
+					v.Interface(),
+					fmt.Sprintf("should be float type, but is: %v", reflect.TypeOf(v.Interface())),
+				))
+			}
+		}
+
+		var fields = []string{
+			"flash.flash_cluster.log",
+			"flash.proxy.log-file",
+			"logger.log",
+			"logger.errorlog",
+		}
+		for _, pathField := range fields {
+			if v := config.Common.Get(pathField); v != nil {
+				if value, err := v.AsString(); err == nil {
+					splitPath := strings.Split(value, string(os.PathSeparator))
+					// The log path should be at least /dir/base.log
+					if len(splitPath) < 3 {
+						allErrs = append(allErrs, field.Invalid(path.Child("config.config."+pathField),
+							value,
+							"log path should include at least one level dir."))
+					}
+				} else {
+					allErrs = append(allErrs, field.Invalid(path.Child("config.config"+pathField),
+						v.Interface(),
+						fmt.Sprintf("should be string type, but is: %v", reflect.TypeOf(v.Interface())),
+					))
+				}
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateTiDBSpec(spec *v1alpha1.TiDBSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.TiDBMemberType, fldPath)...)
+	// TiDB can be scaled to 0 to save the cost of a rarely used cluster, its Services and
+	// ConfigMaps are retained
+	if spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), spec.Replicas, "must be greater than or equal to 0"))
+	}
+	if spec.Service != nil {
+		allErrs = append(allErrs, validateService(&spec.Service.ServiceSpec, fldPath)...)
+		allErrs = append(allErrs, validateTopologyAwareRouting(spec.Service.TopologyAwareRouting, fldPath.Child("service", "topologyAwareRouting"))...)
+	}
+	if len(spec.StorageVolumes) > 0 {
+		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	}
+	tidbReserved := reservedMountPathsOf[v1alpha1.TiDBMemberType]
+	if spec.ShouldSeparateSlowLog() && spec.SlowLogVolumeName == "" {
+		tidbReserved = append([]string{"/var/log/tidb"}, tidbReserved...)
+	}
+	allErrs = append(allErrs, validateMountPaths(spec.StorageVolumes, spec.AdditionalVolumeMounts, tidbReserved, fldPath)...)
+	if spec.ShouldSeparateSlowLog() && spec.SlowLogVolumeName != "" {
+		allErrs = append(allErrs, validateVolumeName(spec.SlowLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
+	}
+	if spec.LoadBalancerReadiness != nil {
+		allErrs = append(allErrs, validateTimeDurationStr(spec.LoadBalancerReadiness.DeregistrationDelay, fldPath.Child("loadBalancerReadiness", "deregistrationDelay"))...)
+	}
+	allErrs = append(allErrs, validateStartScriptHooks(spec.StartScriptHooks, fldPath.Child("startScriptHooks"))...)
+	if spec.WarmUp != nil {
+		allErrs = append(allErrs, validateTiDBWarmUp(spec.WarmUp, fldPath.Child("warmUp"))...)
+	}
+	if spec.EphemeralStorage != nil && spec.EphemeralStorage.TmpSizeLimit != nil && spec.Config != nil {
+		if v := spec.Config.Get("tmp-storage-quota"); v != nil {
+			if quota, err := v.AsInt(); err == nil && quota > spec.EphemeralStorage.TmpSizeLimit.Value() {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("config", "tmp-storage-quota"), quota,
+					fmt.Sprintf("must not exceed ephemeralStorage.tmpSizeLimit %s", spec.EphemeralStorage.TmpSizeLimit.String())))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateTiDBWarmUp validates the warm-up statements and the secret of the password
+func validateTiDBWarmUp(warmUp *v1alpha1.TiDBWarmUp, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(warmUp.SQLs) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("sqls"), "no warm-up statements"))
+	}
+	for i, sql := range warmUp.SQLs {
+		if strings.TrimSpace(sql) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sqls").Index(i), sql, "empty statement"))
+		}
+	}
+	if warmUp.SecretName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(warmUp.SecretName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("secretName"), warmUp.SecretName, msg))
+		}
+	}
+	allErrs = append(allErrs, validateTimeDurationStr(warmUp.Timeout, fldPath.Child("timeout"))...)
+	return allErrs
+}
+
+// validateStartScriptHooks validates the ConfigMap reference of the hooks of the startup script
+func validateStartScriptHooks(hooks *v1alpha1.StartScriptHooks, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if hooks == nil {
+		return allErrs
+	}
+	if len(hooks.ConfigMapName) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("configMapName"), "empty ConfigMap name"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(hooks.ConfigMapName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("configMapName"), hooks.ConfigMapName, msg))
+		}
+	}
+	if len(hooks.PreStart) == 0 && len(hooks.PostDiscovery) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "at least one of preStart and postDiscovery must be set"))
+	}
+	if len(hooks.PreStart) > 0 {
+		for _, msg := range validation.IsConfigMapKey(hooks.PreStart) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("preStart"), hooks.PreStart, msg))
+		}
+	}
+	if len(hooks.PostDiscovery) > 0 {
+		for _, msg := range validation.IsConfigMapKey(hooks.PostDiscovery) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("postDiscovery"), hooks.PostDiscovery, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateTopologyAwareRouting validates the zones, which are used as the suffix of the Service names
+func validateTopologyAwareRouting(routing *v1alpha1.TopologyAwareRouting, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if routing == nil {
+		return allErrs
+	}
+	switch routing.Mode {
+	case v1alpha1.TopologyAwareRoutingHints:
+	case v1alpha1.TopologyAwareRoutingZoneServices:
+		if len(routing.Zones) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("zones"), "zones are required in ZoneServices mode"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), routing.Mode,
+			[]string{string(v1alpha1.TopologyAwareRoutingHints), string(v1alpha1.TopologyAwareRoutingZoneServices)}))
+	}
+	for i, zone := range routing.Zones {
+		for _, msg := range validation.IsDNS1123Label(zone) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("zones").Index(i), zone, msg))
+		}
+	}
+	return allErrs
+}
+
+func validateTiProxySpec(spec *v1alpha1.TiProxySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.TiProxyMemberType, fldPath)...)
+	if spec.Service != nil {
+		allErrs = append(allErrs, validateService(spec.Service, fldPath)...)
+	}
+	return allErrs
+}
+
+func validatePumpSpec(spec *v1alpha1.PumpSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.PumpMemberType, fldPath)...)
+	allErrs = append(allErrs, validateMountPaths(nil, spec.AdditionalVolumeMounts, reservedMountPathsOf[v1alpha1.PumpMemberType], fldPath)...)
+	// fix pump spec
+	if _, ok := spec.ResourceRequirements.Requests["storage"]; !ok {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec.ResourceRequirements.Requests"),
+			spec.ResourceRequirements.Requests, "spec.ResourceRequirements.Requests[storage]: Required value."))
+	}
+	return allErrs
+}
+
+func validateDMClusterSpec(spec *v1alpha1.DMClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Version != "" {
+		clusterVersionLT2, _ := clusterVersionLessThan2(spec.Version)
+		if clusterVersionLT2 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), spec.Version, "dm cluster version can't set to v1.x.y"))
+		}
+	}
+	allErrs = append(allErrs, validateDMDiscoverySpec(spec.Discovery, fldPath.Child("discovery"))...)
+	allErrs = append(allErrs, validateMasterSpec(&spec.Master, fldPath.Child("master"))...)
+	if spec.Worker != nil {
+		allErrs = append(allErrs, validateWorkerSpec(spec.Worker, fldPath.Child("worker"))...)
+	}
+	tlsCluster := spec.TLSCluster != nil && spec.TLSCluster.Enabled
+	allErrs = append(allErrs, validateProbes(spec.Master.Probes, tlsCluster, false, fldPath.Child("master", "probes"))...)
+	if spec.Worker != nil {
+		allErrs = append(allErrs, validateProbes(spec.Worker.Probes, tlsCluster, false, fldPath.Child("worker", "probes"))...)
+	}
+	return allErrs
+}
+
+func validateDMDiscoverySpec(spec v1alpha1.DMDiscoverySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.ComponentSpec != nil {
+		allErrs = append(allErrs, validateComponentSpec(spec.ComponentSpec, v1alpha1.DMDiscoveryMemberType, fldPath)...)
+	}
+	allErrs = append(allErrs, validateProbesUnsupported(spec.ComponentSpec, fldPath)...)
+	return allErrs
+}
+
+func validateMasterSpec(spec *v1alpha1.MasterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.DMMasterMemberType, fldPath)...)
+	// make sure that storageSize for dm-master is assigned
+	if spec.Replicas > 0 && spec.StorageSize == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("storageSize"), "storageSize must not be empty"))
+	}
+	return allErrs
+}
+
+func validateWorkerSpec(spec *v1alpha1.WorkerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.DMWorkerMemberType, fldPath)...)
+	return allErrs
+}
+
+func validateTidbNGMonitorinSpec(spec *v1alpha1.TidbNGMonitoringSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(spec.Clusters) < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("clusters"), len(spec.Clusters), "must have at least one item"))
+	}
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.NGMonitoringMemberType, fldPath)...)
+	allErrs = append(allErrs, validateProbesUnsupported(&spec.ComponentSpec, fldPath)...)
+	allErrs = append(allErrs, validateNGMonitoringSpec(&spec.NGMonitoring, fldPath.Child("ngMonitoring"))...)
+
+	return allErrs
+}
+
+func validateTidbClusterMaintenanceSpec(spec *v1alpha1.TidbClusterMaintenanceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.Cluster.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("cluster", "name"), "must specify the TidbCluster"))
+	}
+	switch spec.Type {
+	case v1alpha1.MaintenanceTypeCompact:
+		if spec.Compact != nil {
+			allErrs = append(allErrs, validatePodNames(spec.Compact.Stores, fldPath.Child("compact", "stores"))...)
+			if spec.Compact.Threads != nil && *spec.Compact.Threads <= 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("compact", "threads"), *spec.Compact.Threads, "must be greater than 0"))
+			}
+		}
+	case v1alpha1.MaintenanceTypeScatterRegions:
+		if opts := spec.ScatterRegions; opts != nil && opts.EndKey != "" && opts.StartKey >= opts.EndKey {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("scatterRegions", "endKey"), opts.EndKey, "must be greater than startKey"))
+		}
+	case v1alpha1.MaintenanceTypeFlashbackCleanup:
+	case v1alpha1.MaintenanceTypePDDefrag:
+		if opts := spec.PDDefrag; opts != nil {
+			if opts.DBSizeThreshold != nil && opts.DBSizeThreshold.Sign() < 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("pdDefrag", "dbSizeThreshold"), opts.DBSizeThreshold.String(), "must not be negative"))
+			}
+			if opts.MinFreePercent != nil && (*opts.MinFreePercent < 0 || *opts.MinFreePercent > 100) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("pdDefrag", "minFreePercent"), *opts.MinFreePercent, "must be between 0 and 100"))
+			}
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), spec.Type, []string{
+			string(v1alpha1.MaintenanceTypeCompact),
+			string(v1alpha1.MaintenanceTypeScatterRegions),
+			string(v1alpha1.MaintenanceTypeFlashbackCleanup),
+			string(v1alpha1.MaintenanceTypePDDefrag),
+		}))
+	}
+
+	return allErrs
+}
+
+func validateNGMonitoringSpec(spec *v1alpha1.NGMonitoringSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, v1alpha1.NGMonitoringMemberType, fldPath)...)
+	allErrs = append(allErrs, validateProbesUnsupported(&spec.ComponentSpec, fldPath)...)
+	if len(spec.StorageVolumes) > 0 {
+		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	}
+	allErrs = append(allErrs, validateMountPaths(spec.StorageVolumes, spec.AdditionalVolumeMounts, reservedMountPathsOf[v1alpha1.NGMonitoringMemberType], fldPath)...)
+
+	return allErrs
+}
+
+// validateTiKVStoreRole validates the role of the TiKV stores, which can only be set for a heterogeneous
+// cluster, as the stores of the main cluster hold the data
+func validateTiKVStoreRole(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	role := spec.TiKV.StoreRole
+	switch role {
+	case "":
+		return allErrs
+	case v1alpha1.TiKVStoreRoleWitness, v1alpha1.TiKVStoreRoleLearner:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath, role, []string{
+			string(v1alpha1.TiKVStoreRoleWitness),
+			string(v1alpha1.TiKVStoreRoleLearner),
+		}))
+		return allErrs
+	}
+	if spec.Cluster == nil || spec.Cluster.Name == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath, role, "can only be set for a heterogeneous cluster with spec.cluster"))
+	}
+	return allErrs
+}
+
+// validateTopologyProfile validates the zones and the scheme of the topology profile, which can only be set
+// for the main cluster, and the location labels of PD must contain zone if they are set explicitly
+func validateTopologyProfile(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	profile := spec.TopologyProfile
+	if profile.Type != v1alpha1.TopologyProfileThreeDC {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), profile.Type, []string{string(v1alpha1.TopologyProfileThreeDC)}))
+		return allErrs
+	}
+	if spec.Cluster != nil && spec.Cluster.Name != "" {
+		allErrs = append(allErrs, field.Invalid(fldPath, profile.Type, "can not be set for a heterogeneous cluster with spec.cluster"))
+	}
+
+	zones := sets.NewString()
+	for i, zone := range profile.Zones {
+		if zone == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("zones").Index(i), "zone must be non-empty"))
+		} else if zones.Has(zone) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("zones").Index(i), zone))
+		}
+		zones.Insert(zone)
+	}
+	if len(profile.Zones) != 3 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("zones"), profile.Zones, "three zones are required for three-dc"))
+	}
+
+	switch profile.Scheme {
+	case "", v1alpha1.ThreeDCScheme221, v1alpha1.ThreeDCScheme32:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("scheme"), profile.Scheme, []string{
+			string(v1alpha1.ThreeDCScheme221),
+			string(v1alpha1.ThreeDCScheme32),
+		}))
+	}
+
+	if spec.PD != nil && spec.PD.Config != nil {
+		if v := spec.PD.Config.Get("replication.location-labels"); v != nil {
+			labels, err := v.AsStringSlice()
+			if err != nil || !sets.NewString(labels...).Has("zone") {
+				allErrs = append(allErrs, field.Invalid(fldPath.Root().Child("pd", "config"), labels, "must contain zone for the topology profile"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateOperatorPolicy validates the durations of the operator policy are positive
+func validateOperatorPolicy(policy *v1alpha1.OperatorPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	validate := func(d *metav1.Duration, fldPath *field.Path) {
+		if d != nil && d.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, d.Duration.String(), "must be positive"))
+		}
+	}
+	validate(policy.ResyncPeriod, fldPath.Child("resyncPeriod"))
+	validate(policy.PDRequestTimeout, fldPath.Child("pdRequestTimeout"))
+	validate(policy.UpgradeWaitTimeout, fldPath.Child("upgradeWaitTimeout"))
+	return allErrs
+}
+
+// validateUpgradePolicy validates exactly one source of the release metadata is set and the
+// maintenance window is a valid cron schedule
+func validateUpgradePolicy(policy *v1alpha1.UpgradePolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if policy.Channel == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("channel"), "channel must be set"))
+	}
+	source := policy.ReleaseMetadata
+	sourcePath := fldPath.Child("releaseMetadata")
+	switch {
+	case source.URL == "" && source.ConfigMap == nil:
+		allErrs = append(allErrs, field.Required(sourcePath, "one of url and configMap must be set"))
+	case source.URL != "" && source.ConfigMap != nil:
+		allErrs = append(allErrs, field.Invalid(sourcePath, source.URL, "only one of url and configMap can be set"))
+	case source.URL != "":
+		// the hosts allowed are restricted by the release-metadata-hosts flag of the operator
+		if u, err := url.Parse(source.URL); err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+			allErrs = append(allErrs, field.Invalid(sourcePath.Child("url"), source.URL, "must be an HTTPS URL without user info"))
+		}
+	default:
+		if source.ConfigMap.Name == "" || source.ConfigMap.Key == "" {
+			allErrs = append(allErrs, field.Required(sourcePath.Child("configMap"), "name and key must be set"))
+		}
+	}
+	if policy.MaintenanceWindow != nil {
+		allErrs = append(allErrs, validateMaintenanceWindow(policy.MaintenanceWindow, fldPath.Child("maintenanceWindow"))...)
+	}
+	if d := policy.PostCheckTimeout; d != nil && d.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("postCheckTimeout"), d.Duration.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+func validateMaintenanceWindow(window *v1alpha1.MaintenanceWindow, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if _, err := cron.ParseStandard(window.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), window.Schedule, err.Error()))
+	}
+	if window.Duration.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("duration"), window.Duration.Duration.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// validateScheduleProfiles validates the profiles have unique names, config items and triggers
+func validateScheduleProfiles(profiles []v1alpha1.PDScheduleProfile, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	events := []string{string(v1alpha1.ThrottledEventScale), string(v1alpha1.ThrottledEventFailover), string(v1alpha1.ThrottledEventRestore)}
+	names := sets.NewString()
+	for i, profile := range profiles {
+		idxPath := fldPath.Index(i)
+		switch {
+		case profile.Name == "":
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "name must be set"))
+		case names.Has(profile.Name):
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), profile.Name))
+		}
+		names.Insert(profile.Name)
+		if len(profile.Events) == 0 && profile.Window == nil {
+			allErrs = append(allErrs, field.Required(idxPath, "one of events and window must be set"))
+		}
+		for j, event := range profile.Events {
+			if !sets.NewString(events...).Has(string(event)) {
+				allErrs = append(allErrs, field.NotSupported(idxPath.Child("events").Index(j), event, events))
+			}
+		}
+		if profile.Window != nil {
+			allErrs = append(allErrs, validateMaintenanceWindow(profile.Window, idxPath.Child("window"))...)
+		}
+		if len(profile.Config) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("config"), "config must be set"))
+		}
+		for name := range profile.Config {
+			if name == "" {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("config"), name, "the name of a config item must not be empty"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateSQLProbe validates the durations and the failure threshold of the SQL probe are positive
+// sqlIdentifierRegexp matches the unquoted identifiers of MySQL, which are safe in the SQL statements of the probe
+var sqlIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]{0,63}$`)
+
+func validateSQLProbe(spec *v1alpha1.SQLProbeSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	validate := func(d *metav1.Duration, fldPath *field.Path) {
+		if d != nil && d.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, d.Duration.String(), "must be positive"))
+		}
+	}
+	validate(spec.Interval, fldPath.Child("interval"))
+	validate(spec.Timeout, fldPath.Child("timeout"))
+	if spec.FailureThreshold != nil && *spec.FailureThreshold < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("failureThreshold"), *spec.FailureThreshold, "must be at least 1"))
+	}
+	if spec.PasswordSecret != nil && *spec.PasswordSecret == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("passwordSecret"), "", "must not be empty"))
+	}
+	if spec.Database != "" && !sqlIdentifierRegexp.MatchString(spec.Database) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("database"), spec.Database, "must be an identifier of letters, digits, _ and $ of at most 64 characters"))
+	}
+	return allErrs
+}
+
+// tikvReadableSizeRegexp matches the sizes in the config of TiKV, e.g. 100MiB
+var tikvReadableSizeRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?([KMGTP]i?)?B$`)
+
+// validateEventThrottling validates the events are known and the limits are sizes of TiKV
+func validateEventThrottling(spec *v1alpha1.EventThrottlingPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	events := []string{string(v1alpha1.ThrottledEventScale), string(v1alpha1.ThrottledEventFailover), string(v1alpha1.ThrottledEventRestore)}
+	for i, event := range spec.Events {
+		if !sets.NewString(events...).Has(string(event)) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("events").Index(i), event, events))
+		}
+	}
+	validateSize := func(size string, fldPath *field.Path) {
+		if size != "" && !tikvReadableSizeRegexp.MatchString(size) {
+			allErrs = append(allErrs, field.Invalid(fldPath, size, "must be a size like 100MiB"))
+		}
+	}
+	validateSize(spec.IORateLimit, fldPath.Child("ioRateLimit"))
+	validateSize(spec.CompactionRateLimit, fldPath.Child("compactionRateLimit"))
+	if spec.CooldownPeriod != nil && spec.CooldownPeriod.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cooldownPeriod"), spec.CooldownPeriod.Duration.String(), "must be non-negative"))
+	}
+	return allErrs
+}
+
+// validateTopologyExport validates the name and the labels of the ConfigMap
+func validateTopologyExport(spec *v1alpha1.TopologyExportSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.ConfigMapName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.ConfigMapName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("configMapName"), spec.ConfigMapName, msg))
+		}
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabels(spec.Labels, fldPath.Child("labels"))...)
+	return allErrs
+}
+
+// validateDNS validates the components are supported and the template renders distinct valid DNS names for them
+func validateDNS(spec *v1alpha1.DNSSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	supported := []string{v1alpha1.TiDBMemberType.String(), v1alpha1.PDMemberType.String()}
+	components := spec.Components
+	if len(components) == 0 {
+		components = []v1alpha1.MemberType{v1alpha1.TiDBMemberType, v1alpha1.PDMemberType}
+	}
+	for i, component := range spec.Components {
+		if !sets.NewString(supported...).Has(component.String()) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("components").Index(i), component, supported))
+		}
+	}
+	if spec.DomainTemplate == "" {
+		return append(allErrs, field.Required(fldPath.Child("domainTemplate"), "domainTemplate must be set"))
+	}
+
+	names := sets.NewString()
+	for _, component := range components {
+		// the template is rendered with a sample cluster to check the names
+		name, err := v1alpha1.RenderDNSName(spec.DomainTemplate, "basic", "default", component)
+		if err != nil {
+			return append(allErrs, field.Invalid(fldPath.Child("domainTemplate"), spec.DomainTemplate, err.Error()))
+		}
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			return append(allErrs, field.Invalid(fldPath.Child("domainTemplate"), spec.DomainTemplate, strings.Join(msgs, ", ")))
+		}
+		names.Insert(name)
+	}
+	if names.Len() != len(components) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("domainTemplate"), spec.DomainTemplate, "must render distinct names for the components, e.g. by .Component"))
 	}
 	return allErrs
 }
 
-func validateTiCDCSpec(spec *v1alpha1.TiCDCSpec, fldPath *field.Path) field.ErrorList {
+// validatePersistentIdentity validates the name of the Secret
+func validatePersistentIdentity(spec *v1alpha1.PersistentIdentitySpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	if len(spec.StorageVolumes) > 0 {
-		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	if spec.SecretName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.SecretName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("secretName"), spec.SecretName, msg))
+		}
 	}
 	return allErrs
 }
 
-func validateTiFlashConfig(config *v1alpha1.TiFlashConfigWraper, path *field.Path) field.ErrorList {
+// validateDiskWatchdog validates the soft limit takes effect before the disk pressure
+func validateDiskWatchdog(spec *v1alpha1.DiskWatchdog, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	if config == nil {
+	softLimit := spec.SoftLimit
+	if softLimit == nil {
 		return allErrs
 	}
+	if softLimit.AddPeerRate != nil && *softLimit.AddPeerRate < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("softLimit", "addPeerRate"), *softLimit.AddPeerRate, "must be non-negative"))
+	}
+	if softLimit.UsageThresholdPercent != nil && spec.UsageThresholdPercent != nil &&
+		*softLimit.UsageThresholdPercent >= *spec.UsageThresholdPercent {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("softLimit", "usageThresholdPercent"), *softLimit.UsageThresholdPercent,
+			fmt.Sprintf("must be lower than usageThresholdPercent %d", *spec.UsageThresholdPercent)))
+	}
+	return allErrs
+}
 
-	if config.Common != nil {
-		if v := config.Common.Get("flash.overlap_threshold"); v != nil {
-			if value, err := v.AsFloat(); err == nil {
-				if value < 0 || value > 1 {
-					allErrs = append(allErrs, field.Invalid(path.Child("config.config.flash.overlap_threshold"),
-						value,
-						"overlap_threshold must be in the range of [0,1]."))
-				}
-			} else {
-				allErrs = append(allErrs, field.Invalid(path.Child("config.config.flash.overlap_threshold"),
-					v.Interface(),
-					fmt.Sprintf("should be float type, but is: %v", reflect.TypeOf(v.Interface())),
-				))
-			}
-		}
-
-		var fields = []string{
-			"flash.flash_cluster.log",
-			"flash.proxy.log-file",
-			"logger.log",
-			"logger.errorlog",
+// validateResourceQuota validates the resources of the max and the default requests are supported
+// and the quantities are not negative
+func validateResourceQuota(spec *v1alpha1.ResourceQuotaSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	validateList := func(list corev1.ResourceList, supported []corev1.ResourceName, fldPath *field.Path) {
+		names := make([]string, 0, len(supported))
+		for _, name := range supported {
+			names = append(names, string(name))
 		}
-		for _, pathField := range fields {
-			if v := config.Common.Get(pathField); v != nil {
-				if value, err := v.AsString(); err == nil {
-					splitPath := strings.Split(value, string(os.PathSeparator))
-					// The log path should be at least /dir/base.log
-					if len(splitPath) < 3 {
-						allErrs = append(allErrs, field.Invalid(path.Child("config.config."+pathField),
-							value,
-							"log path should include at least one level dir."))
-					}
-				} else {
-					allErrs = append(allErrs, field.Invalid(path.Child("config.config"+pathField),
-						v.Interface(),
-						fmt.Sprintf("should be string type, but is: %v", reflect.TypeOf(v.Interface())),
-					))
-				}
+		for name, q := range list {
+			if !sets.NewString(names...).Has(string(name)) {
+				allErrs = append(allErrs, field.NotSupported(fldPath.Key(string(name)), name, names))
+			} else if q.Sign() < 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Key(string(name)), q.String(), "must be non-negative"))
 			}
 		}
 	}
-
+	validateList(spec.Max, []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceStorage}, fldPath.Child("max"))
+	validateList(spec.DefaultRequests, []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}, fldPath.Child("defaultRequests"))
+	if spec.HeadroomPercent != nil && *spec.HeadroomPercent < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("headroomPercent"), *spec.HeadroomPercent, "must be non-negative"))
+	}
 	return allErrs
 }
 
-func validateTiDBSpec(spec *v1alpha1.TiDBSpec, fldPath *field.Path) field.ErrorList {
+// ValidateResourceQuotaMax refuses the TidbCluster whose summed requests of the components, including the
+// failover Pods and the sidecars, exceed the max of spec.resourceQuota and grow from the old TidbCluster.
+// It's validated by the admission webhook only, so the sync of the cluster is not blocked if the summed
+// requests exceed the max, e.g. after the failover.
+func ValidateResourceQuotaMax(old, tc *v1alpha1.TidbCluster) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	if spec.Service != nil {
-		allErrs = append(allErrs, validateService(&spec.Service.ServiceSpec, fldPath)...)
+	if tc.Spec.ResourceQuota == nil || len(tc.Spec.ResourceQuota.Max) == 0 {
+		return allErrs
 	}
-	if len(spec.StorageVolumes) > 0 {
-		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	requested, err := tc.ComponentRequests()
+	if err != nil {
+		// the invalid storage sizes are refused by the validation of the components
+		return allErrs
 	}
-	if spec.ShouldSeparateSlowLog() && spec.SlowLogVolumeName != "" {
-		allErrs = append(allErrs, validateVolumeName(spec.SlowLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
+	var oldRequested corev1.ResourceList
+	if old != nil {
+		if oldRequested, err = old.ComponentRequests(); err != nil {
+			oldRequested = nil
+		}
+	}
+	fldPath := field.NewPath("spec", "resourceQuota", "max")
+	for name, max := range tc.Spec.ResourceQuota.Max {
+		q, ok := requested[name]
+		if !ok || q.Cmp(max) <= 0 {
+			continue
+		}
+		if oldQ, ok := oldRequested[name]; ok && q.Cmp(oldQ) <= 0 {
+			continue
+		}
+		allErrs = append(allErrs, field.Forbidden(fldPath.Key(string(name)),
+			fmt.Sprintf("the summed %s requests %s of the components exceed the max %s, reduce the replicas or the requests", name, q.String(), max.String())))
 	}
 	return allErrs
 }
 
-func validatePumpSpec(spec *v1alpha1.PumpSpec, fldPath *field.Path) field.ErrorList {
+func validateComponentSpec(spec *v1alpha1.ComponentSpec, typ v1alpha1.MemberType, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	// fix pump spec
-	if _, ok := spec.ResourceRequirements.Requests["storage"]; !ok {
-		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec.ResourceRequirements.Requests"),
-			spec.ResourceRequirements.Requests, "spec.ResourceRequirements.Requests[storage]: Required value."))
+	// TODO validate other fields
+	allErrs = append(allErrs, validateEnv(spec.Env, fldPath.Child("env"))...)
+	allErrs = append(allErrs, validateAdditionalContainers(spec.AdditionalContainers, managedContainersOf[typ], fldPath.Child("additionalContainers"))...)
+	allErrs = append(allErrs, validatePVCDeletePolicy(spec, fldPath)...)
+	allErrs = append(allErrs, validateScheduling(spec.NodeSelector, spec.Affinity, spec.Tolerations, fldPath)...)
+	allErrs = append(allErrs, validateArchitectures(spec.Architectures, spec.NodeSelector, fldPath.Child("architectures"))...)
+	if spec.EphemeralStorage != nil {
+		allErrs = append(allErrs, validateEphemeralStorage(spec.EphemeralStorage, spec.AdditionalVolumeMounts, fldPath)...)
 	}
 	return allErrs
 }
 
-func validateDMClusterSpec(spec *v1alpha1.DMClusterSpec, fldPath *field.Path) field.ErrorList {
+// validateEphemeralStorage validates the quantities of the ephemeral storage are positive and the
+// request doesn't exceed the limit, /tmp can't be mounted by the additional volumes if its size is limited
+func validateEphemeralStorage(spec *v1alpha1.EphemeralStorage, volumeMounts []corev1.VolumeMount, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	if spec.Version != "" {
-		clusterVersionLT2, _ := clusterVersionLessThan2(spec.Version)
-		if clusterVersionLT2 {
-			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), spec.Version, "dm cluster version can't set to v1.x.y"))
+	specPath := fldPath.Child("ephemeralStorage")
+	for _, q := range []struct {
+		name     string
+		quantity *resource.Quantity
+	}{
+		{"request", spec.Request},
+		{"limit", spec.Limit},
+		{"tmpSizeLimit", spec.TmpSizeLimit},
+	} {
+		if q.quantity != nil && q.quantity.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child(q.name), q.quantity.String(), "must be positive"))
 		}
 	}
-	allErrs = append(allErrs, validateDMDiscoverySpec(spec.Discovery, fldPath.Child("discovery"))...)
-	allErrs = append(allErrs, validateMasterSpec(&spec.Master, fldPath.Child("master"))...)
-	if spec.Worker != nil {
-		allErrs = append(allErrs, validateWorkerSpec(spec.Worker, fldPath.Child("worker"))...)
+	if spec.Request != nil && spec.Limit != nil && spec.Request.Cmp(*spec.Limit) > 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("request"), spec.Request.String(), "must be less than or equal to the limit"))
+	}
+	if spec.TmpSizeLimit != nil {
+		for i, mount := range volumeMounts {
+			if path.Clean(mount.MountPath) == "/tmp" {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("additionalVolumeMounts").Index(i).Child("mountPath"),
+					"/tmp is mounted by tidb-operator if ephemeralStorage.tmpSizeLimit is set"))
+			}
+		}
 	}
 	return allErrs
 }
 
-func validateDMDiscoverySpec(spec v1alpha1.DMDiscoverySpec, fldPath *field.Path) field.ErrorList {
+// validateArchitectures validates the architectures are valid label values without duplicates,
+// and contain the architecture selected by nodeSelector if any
+func validateArchitectures(archs []string, nodeSelector map[string]string, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	if spec.ComponentSpec != nil {
-		allErrs = append(allErrs, validateComponentSpec(spec.ComponentSpec, fldPath)...)
+	if len(archs) == 0 {
+		return allErrs
+	}
+	seen := sets.NewString()
+	for i, arch := range archs {
+		if arch == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i), "the architecture must not be empty"))
+			continue
+		}
+		for _, msg := range validation.IsValidLabelValue(arch) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), arch, msg))
+		}
+		if seen.Has(arch) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), arch))
+		}
+		seen.Insert(arch)
+	}
+	if arch, ok := nodeSelector[corev1.LabelArchStable]; ok && !seen.Has(arch) {
+		allErrs = append(allErrs, field.Invalid(fldPath, archs, fmt.Sprintf("must contain %q selected by nodeSelector", arch)))
 	}
 	return allErrs
 }
 
-func validateMasterSpec(spec *v1alpha1.MasterSpec, fldPath *field.Path) field.ErrorList {
+// validateScheduling validates the label keys and values referenced by nodeSelector, affinity and tolerations,
+// and rejects the required node affinity terms which can't be satisfied by any node
+func validateScheduling(nodeSelector map[string]string, affinity *corev1.Affinity, tolerations []corev1.Toleration, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	// make sure that storageSize for dm-master is assigned
-	if spec.Replicas > 0 && spec.StorageSize == "" {
-		allErrs = append(allErrs, field.Required(fldPath.Child("storageSize"), "storageSize must not be empty"))
+	for k, v := range nodeSelector {
+		for _, msg := range validation.IsQualifiedName(k) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nodeSelector"), k, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(v) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nodeSelector").Key(k), v, msg))
+		}
+	}
+	if affinity != nil {
+		allErrs = append(allErrs, validateNodeAffinity(affinity.NodeAffinity, nodeSelector, fldPath.Child("affinity", "nodeAffinity"))...)
+		if affinity.PodAffinity != nil {
+			allErrs = append(allErrs, validatePodAffinityTerms(affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+				affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution, fldPath.Child("affinity", "podAffinity"))...)
+		}
+		if affinity.PodAntiAffinity != nil {
+			allErrs = append(allErrs, validatePodAffinityTerms(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+				affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, fldPath.Child("affinity", "podAntiAffinity"))...)
+		}
+	}
+	for i, t := range tolerations {
+		allErrs = append(allErrs, validateToleration(t, fldPath.Child("tolerations").Index(i))...)
 	}
 	return allErrs
 }
 
-func validateWorkerSpec(spec *v1alpha1.WorkerSpec, fldPath *field.Path) field.ErrorList {
+// validateNodeAffinity validates the node selector requirements, a required term is contradictory if no
+// node labels can satisfy both the term and nodeSelector, e.g. `zone In [a]` and `zone NotIn [a]`
+func validateNodeAffinity(affinity *corev1.NodeAffinity, nodeSelector map[string]string, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
+	if affinity == nil {
+		return allErrs
+	}
+	if required := affinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+		termsPath := fldPath.Child("requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+		for i, term := range required.NodeSelectorTerms {
+			termPath := termsPath.Index(i)
+			errs := validateNodeSelectorRequirements(term.MatchExpressions, termPath.Child("matchExpressions"))
+			allErrs = append(allErrs, errs...)
+			if len(errs) == 0 {
+				if reason := contradictoryRequirements(term.MatchExpressions, nodeSelector); reason != "" {
+					allErrs = append(allErrs, field.Invalid(termPath, term.MatchExpressions, fmt.Sprintf("the term can't be satisfied by any node: %s", reason)))
+				}
+			}
+		}
+	}
+	for i, term := range affinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		termPath := fldPath.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+		if term.Weight < 1 || term.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(termPath.Child("weight"), term.Weight, "must be in the range 1-100"))
+		}
+		allErrs = append(allErrs, validateNodeSelectorRequirements(term.Preference.MatchExpressions, termPath.Child("preference", "matchExpressions"))...)
+	}
 	return allErrs
 }
 
-func validateTidbNGMonitorinSpec(spec *v1alpha1.TidbNGMonitoringSpec, fldPath *field.Path) field.ErrorList {
+func validateNodeSelectorRequirements(reqs []corev1.NodeSelectorRequirement, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
+	for i, req := range reqs {
+		idxPath := fldPath.Index(i)
+		for _, msg := range validation.IsQualifiedName(req.Key) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("key"), req.Key, msg))
+		}
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn:
+			if len(req.Values) == 0 {
+				allErrs = append(allErrs, field.Required(idxPath.Child("values"), "must be specified when operator is In or NotIn"))
+			}
+		case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+			if len(req.Values) > 0 {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("values"), "may not be specified when operator is Exists or DoesNotExist"))
+			}
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if len(req.Values) != 1 {
+				allErrs = append(allErrs, field.Required(idxPath.Child("values"), "must be specified single value when operator is Gt or Lt"))
+			} else if _, err := strconv.ParseInt(req.Values[0], 10, 64); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("values"), req.Values[0], "must be an integer when operator is Gt or Lt"))
+			}
+			continue
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("operator"), req.Operator, []string{
+				string(corev1.NodeSelectorOpIn), string(corev1.NodeSelectorOpNotIn), string(corev1.NodeSelectorOpExists),
+				string(corev1.NodeSelectorOpDoesNotExist), string(corev1.NodeSelectorOpGt), string(corev1.NodeSelectorOpLt)}))
+			continue
+		}
+		for _, v := range req.Values {
+			for _, msg := range validation.IsValidLabelValue(v) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("values"), v, msg))
+			}
+		}
+	}
+	return allErrs
+}
 
-	if len(spec.Clusters) < 1 {
-		allErrs = append(allErrs, field.Invalid(fldPath.Child("clusters"), len(spec.Clusters), "must have at least one item"))
+// contradictoryRequirements returns the reason if the requirements of a node selector term, which are ANDed,
+// and nodeSelector can't be satisfied at the same time, the requirements must be valid
+func contradictoryRequirements(reqs []corev1.NodeSelectorRequirement, nodeSelector map[string]string) string {
+	type constraint struct {
+		allowed   sets.String // nil if any value is allowed
+		forbidden sets.String
+		exists    bool
+		notExists bool
+		min, max  int64 // the value must be in (min, max) if hasMinMax
+		hasMinMax bool
+	}
+	constraints := map[string]*constraint{}
+	get := func(key string) *constraint {
+		if c, ok := constraints[key]; ok {
+			return c
+		}
+		c := &constraint{forbidden: sets.NewString(), min: math.MinInt64, max: math.MaxInt64}
+		constraints[key] = c
+		return c
+	}
+	allow := func(c *constraint, values ...string) {
+		if c.allowed == nil {
+			c.allowed = sets.NewString(values...)
+		} else {
+			c.allowed = c.allowed.Intersection(sets.NewString(values...))
+		}
+	}
+	for k, v := range nodeSelector {
+		c := get(k)
+		c.exists = true
+		allow(c, v)
+	}
+	for _, req := range reqs {
+		c := get(req.Key)
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn:
+			c.exists = true
+			allow(c, req.Values...)
+		case corev1.NodeSelectorOpNotIn:
+			c.forbidden.Insert(req.Values...)
+		case corev1.NodeSelectorOpExists:
+			c.exists = true
+		case corev1.NodeSelectorOpDoesNotExist:
+			c.notExists = true
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			v, _ := strconv.ParseInt(req.Values[0], 10, 64)
+			c.exists, c.hasMinMax = true, true
+			if req.Operator == corev1.NodeSelectorOpGt && v > c.min {
+				c.min = v
+			}
+			if req.Operator == corev1.NodeSelectorOpLt && v < c.max {
+				c.max = v
+			}
+		}
 	}
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	allErrs = append(allErrs, validateNGMonitoringSpec(&spec.NGMonitoring, fldPath.Child("ngMonitoring"))...)
 
-	return allErrs
+	keys := make([]string, 0, len(constraints))
+	for k := range constraints {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c := constraints[k]
+		if c.exists && c.notExists {
+			return fmt.Sprintf("label %s must and must not exist", k)
+		}
+		if c.allowed != nil && c.allowed.Difference(c.forbidden).Len() == 0 {
+			return fmt.Sprintf("no value of label %s is allowed", k)
+		}
+		if c.hasMinMax && c.max-c.min <= 1 {
+			return fmt.Sprintf("no integer value of label %s is greater than %d and less than %d", k, c.min, c.max)
+		}
+	}
+	return ""
 }
 
-func validateNGMonitoringSpec(spec *v1alpha1.NGMonitoringSpec, fldPath *field.Path) field.ErrorList {
+func validatePodAffinityTerms(required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-
-	allErrs = append(allErrs, validateComponentSpec(&spec.ComponentSpec, fldPath)...)
-	if len(spec.StorageVolumes) > 0 {
-		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
+	validateTerm := func(term corev1.PodAffinityTerm, termPath *field.Path) {
+		if term.LabelSelector != nil {
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelector(term.LabelSelector, termPath.Child("labelSelector"))...)
+		}
+		if term.TopologyKey == "" {
+			allErrs = append(allErrs, field.Required(termPath.Child("topologyKey"), "can not be empty"))
+		}
+		for _, msg := range validation.IsQualifiedName(term.TopologyKey) {
+			allErrs = append(allErrs, field.Invalid(termPath.Child("topologyKey"), term.TopologyKey, msg))
+		}
+	}
+	for i, term := range required {
+		validateTerm(term, fldPath.Child("requiredDuringSchedulingIgnoredDuringExecution").Index(i))
+	}
+	for i, term := range preferred {
+		termPath := fldPath.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+		if term.Weight < 1 || term.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(termPath.Child("weight"), term.Weight, "must be in the range 1-100"))
+		}
+		validateTerm(term.PodAffinityTerm, termPath.Child("podAffinityTerm"))
 	}
+	return allErrs
+}
 
+func validateToleration(t corev1.Toleration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if t.Key != "" {
+		for _, msg := range validation.IsQualifiedName(t.Key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), t.Key, msg))
+		}
+	} else if t.Operator != corev1.TolerationOpExists {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("operator"), t.Operator, "operator must be Exists when key is empty"))
+	}
+	switch t.Operator {
+	case corev1.TolerationOpEqual, "":
+		for _, msg := range validation.IsValidLabelValue(t.Value) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), t.Value, msg))
+		}
+	case corev1.TolerationOpExists:
+		if t.Value != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), t.Value, "value must be empty when operator is Exists"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("operator"), t.Operator,
+			[]string{string(corev1.TolerationOpEqual), string(corev1.TolerationOpExists)}))
+	}
+	switch t.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("effect"), t.Effect,
+			[]string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}))
+	}
 	return allErrs
 }
 
-func validateComponentSpec(spec *v1alpha1.ComponentSpec, fldPath *field.Path) field.ErrorList {
+// validatePVCDeletePolicy validates pvcDeletePolicy and pvcDeleteTTL
+func validatePVCDeletePolicy(spec *v1alpha1.ComponentSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	// TODO validate other fields
-	allErrs = append(allErrs, validateEnv(spec.Env, fldPath.Child("env"))...)
-	allErrs = append(allErrs, validateAdditionalContainers(spec.AdditionalContainers, fldPath.Child("additionalContainers"))...)
+	switch spec.PVCDeletePolicy {
+	case "", v1alpha1.PVCDeletePolicyRetain, v1alpha1.PVCDeletePolicyDelete, v1alpha1.PVCDeletePolicyDeferWithTTL:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("pvcDeletePolicy"), spec.PVCDeletePolicy,
+			[]string{string(v1alpha1.PVCDeletePolicyRetain), string(v1alpha1.PVCDeletePolicyDelete), string(v1alpha1.PVCDeletePolicyDeferWithTTL)}))
+	}
+	if spec.PVCDeleteTTL != nil && spec.PVCDeleteTTL.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("pvcDeleteTTL"), spec.PVCDeleteTTL.Duration.String(), "must be positive"))
+	}
 	return allErrs
 }
 
@@ -385,6 +2118,75 @@ func validateStorageVolumes(storageVolumes []v1alpha1.StorageVolume, fldPath *fi
 	return allErrs
 }
 
+// systemMountPaths can't be used as the mount paths of any volume
+var systemMountPaths = []string{"/", "/dev", "/proc", "/sys"}
+
+// reservedMountPathsOf are the mount paths of the volumes mounted by tidb-operator into the main containers of
+// the components, see the member managers for details
+var reservedMountPathsOf = map[v1alpha1.MemberType][]string{
+	v1alpha1.PDMemberType: {
+		"/var/lib/pd", "/etc/pd", "/usr/local/bin", "/etc/podinfo",
+		"/var/lib/pd-tls", "/var/lib/cluster-client-tls", "/var/lib/tidb-client-tls",
+	},
+	v1alpha1.TiKVMemberType: {
+		"/var/lib/tikv", "/etc/tikv", "/usr/local/bin", "/etc/podinfo",
+		"/var/lib/tikv-tls", "/var/lib/cluster-client-tls",
+	},
+	v1alpha1.TiDBMemberType: {
+		"/etc/tidb", "/usr/local/bin", "/etc/podinfo",
+		"/var/lib/tidb-tls", "/var/lib/tidb-server-tls", "/var/lib/cluster-client-tls",
+	},
+	v1alpha1.TiFlashMemberType: {
+		"/etc/tiflash", "/etc/podinfo", "/var/lib/tiflash-tls",
+	},
+	v1alpha1.TiCDCMemberType: {
+		"/etc/ticdc", "/var/lib/ticdc-tls", "/var/lib/sink-tls", "/var/lib/cluster-client-tls",
+	},
+	v1alpha1.PumpMemberType: {
+		"/data", "/etc/pump", "/var/lib/pump-tls",
+	},
+	v1alpha1.NGMonitoringMemberType: {
+		"/var/lib/ng-monitoring", "/etc/ng-monitoring", "/var/lib/tc-client-tls",
+	},
+}
+
+// validateMountPaths validates the mount paths of the storage volumes and the additional volume mounts, which are
+// mounted into the main container of the component together with the volumes mounted by tidb-operator. The paths
+// must be absolute and unique, and must not be the reserved paths, otherwise the data directories may be shadowed
+// silently or the Pods are rejected by kube-apiserver.
+func validateMountPaths(storageVolumes []v1alpha1.StorageVolume, volumeMounts []corev1.VolumeMount, reserved []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	system := sets.NewString(systemMountPaths...)
+	managed := sets.NewString(reserved...)
+	seen := sets.NewString()
+	check := func(mountPath string, fldPath *field.Path) {
+		if !path.IsAbs(mountPath) {
+			allErrs = append(allErrs, field.Invalid(fldPath, mountPath, "must be an absolute path"))
+			return
+		}
+		cleaned := path.Clean(mountPath)
+		switch {
+		case system.Has(cleaned):
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("%s is a system path", mountPath)))
+		case managed.Has(cleaned):
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("%s is reserved for the volume mounted by tidb-operator", mountPath)))
+		case seen.Has(cleaned):
+			allErrs = append(allErrs, field.Duplicate(fldPath, mountPath))
+		}
+		seen.Insert(cleaned)
+	}
+	for i, vol := range storageVolumes {
+		// the volume mount is not generated if the mount path is empty
+		if vol.MountPath != "" {
+			check(vol.MountPath, fldPath.Child("storageVolumes").Index(i).Child("mountPath"))
+		}
+	}
+	for i, mount := range volumeMounts {
+		check(mount.MountPath, fldPath.Child("additionalVolumeMounts").Index(i).Child("mountPath"))
+	}
+	return allErrs
+}
+
 func validateVolumeName(volumeName string, storageVolumes []v1alpha1.StorageVolume, additionalVolumes []corev1.Volume, additionalVolumeMounts []corev1.VolumeMount, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for _, volume := range storageVolumes {
@@ -503,6 +2305,9 @@ func ValidateCreateTidbCluster(tc *v1alpha1.TidbCluster) field.ErrorList {
 	// basic validation
 	allErrs = append(allErrs, ValidateTidbCluster(tc)...)
 	allErrs = append(allErrs, validateNewTidbClusterSpec(&tc.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateTLSClient(&tc.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateComponentLimitRequestRatios(nil, &tc.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, ValidateResourceQuotaMax(nil, tc)...)
 	return allErrs
 }
 
@@ -516,9 +2321,200 @@ func ValidateUpdateTidbCluster(old, tc *v1alpha1.TidbCluster) field.ErrorList {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("labels"), tc.Labels,
 			"The instance must not be mutate or set value other than the cluster name"))
 	}
-	allErrs = append(allErrs, validateUpdatePDConfig(old.Spec.PD.Config, tc.Spec.PD.Config, field.NewPath("spec.pd.config"))...)
+	if old.Spec.PD != nil && tc.Spec.PD != nil {
+		allErrs = append(allErrs, validateUpdatePDConfig(old.Spec.PD.Config, tc.Spec.PD.Config, field.NewPath("spec.pd.config"))...)
+	}
 	allErrs = append(allErrs, disallowUsingLegacyAPIInNewCluster(old, tc)...)
+	allErrs = append(allErrs, validateEnablingTLSCluster(old, tc)...)
+	allErrs = append(allErrs, validateStorageNotShrunk(old, tc)...)
+	allErrs = append(allErrs, validateVersionChange(old, tc)...)
+	if tlsClientChanged(&old.Spec, &tc.Spec) {
+		allErrs = append(allErrs, validateTLSClient(&tc.Spec, field.NewPath("spec"))...)
+	}
+	allErrs = append(allErrs, validateComponentLimitRequestRatios(&old.Spec, &tc.Spec, field.NewPath("spec"))...)
+	if old.Spec.TiKV != nil && tc.Spec.TiKV != nil && old.Spec.TiKV.StoreRole != tc.Spec.TiKV.StoreRole {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "tikv", "storeRole"),
+			"the role of the existing stores can't be changed, please create a new cluster of the role instead"))
+	}
+
+	return allErrs
+}
+
+// componentVersion is the version of a component parsed from its image, the version is nil if it's not a
+// semantic version, e.g. latest or nightly
+type componentVersion struct {
+	typ     v1alpha1.MemberType
+	path    *field.Path
+	version *semver.Version
+}
+
+// componentVersions returns the versions of the components released with TiDB, TiProxy is not included
+// as it's versioned independently
+func componentVersions(tc *v1alpha1.TidbCluster) map[v1alpha1.MemberType]componentVersion {
+	versions := map[v1alpha1.MemberType]componentVersion{}
+	add := func(typ v1alpha1.MemberType, override *string, image string) {
+		path := field.NewPath("spec", "version")
+		if override != nil {
+			path = field.NewPath("spec", typ.String(), "version")
+		}
+		tag := "latest"
+		if i := strings.LastIndexByte(image, ':'); i >= 0 {
+			tag = image[i+1:]
+		}
+		v, err := semver.NewVersion(tag)
+		if err != nil {
+			v = nil
+		}
+		versions[typ] = componentVersion{typ: typ, path: path, version: v}
+	}
+	if tc.Spec.PD != nil {
+		add(v1alpha1.PDMemberType, tc.Spec.PD.Version, tc.PDImage())
+	}
+	if tc.Spec.TiKV != nil {
+		add(v1alpha1.TiKVMemberType, tc.Spec.TiKV.Version, tc.TiKVImage())
+	}
+	if tc.Spec.TiDB != nil {
+		add(v1alpha1.TiDBMemberType, tc.Spec.TiDB.Version, tc.TiDBImage())
+	}
+	if tc.Spec.TiFlash != nil {
+		add(v1alpha1.TiFlashMemberType, tc.Spec.TiFlash.Version, tc.TiFlashImage())
+	}
+	if tc.Spec.TiCDC != nil {
+		add(v1alpha1.TiCDCMemberType, tc.Spec.TiCDC.Version, tc.TiCDCImage())
+	}
+	if tc.Spec.Pump != nil {
+		add(v1alpha1.PumpMemberType, tc.Spec.Pump.Version, *tc.PumpImage())
+	}
+	return versions
+}
+
+// validateVersionChange forbids the version changes of the components which are not supported by TiDB unless
+// the annotation tidb.pingcap.com/force-version-change is "true":
+//
+//   - downgrading any component
+//   - upgrading any component across more than one major version, e.g. from v5.4.0 to v7.1.0
+//   - a component newer than PD or in a different major version from PD, as PD is upgraded first and the
+//     other components must be able to talk to it
+//
+// The versions which are not semantic versions are not checked.
+func validateVersionChange(old, tc *v1alpha1.TidbCluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if tc.Annotations[label.AnnForceVersionChange] == "true" {
+		return allErrs
+	}
+	hint := fmt.Sprintf("set annotation %s to \"true\" to force it", label.AnnForceVersionChange)
+
+	oldVersions := componentVersions(old)
+	newVersions := componentVersions(tc)
+	pd := newVersions[v1alpha1.PDMemberType]
+	for _, typ := range []v1alpha1.MemberType{v1alpha1.PDMemberType, v1alpha1.TiKVMemberType, v1alpha1.TiDBMemberType,
+		v1alpha1.TiFlashMemberType, v1alpha1.TiCDCMemberType, v1alpha1.PumpMemberType} {
+		oldVer, newVer := oldVersions[typ], newVersions[typ]
+		if oldVer.version == nil || newVer.version == nil || oldVer.version.Equal(newVer.version) {
+			continue
+		}
+		from, to := oldVer.version.Original(), newVer.version.Original()
+		switch {
+		case newVer.version.LessThan(oldVer.version):
+			allErrs = append(allErrs, field.Forbidden(newVer.path,
+				fmt.Sprintf("downgrading %s from %s to %s is not supported, %s", typ, from, to, hint)))
+		case newVer.version.Major() > oldVer.version.Major()+1:
+			allErrs = append(allErrs, field.Forbidden(newVer.path,
+				fmt.Sprintf("upgrading %s from %s to %s skips major version %d, upgrade to the latest v%d.x first, %s",
+					typ, from, to, oldVer.version.Major()+1, oldVer.version.Major()+1, hint)))
+		case typ != v1alpha1.PDMemberType && pd.version != nil:
+			if newVer.version.GreaterThan(pd.version) || newVer.version.Major() != pd.version.Major() {
+				allErrs = append(allErrs, field.Forbidden(newVer.path,
+					fmt.Sprintf("%s %s must not be newer than or in a different major version from PD %s, upgrade PD first, %s",
+						typ, to, pd.version.Original(), hint)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateStorageNotShrunk forbids shrinking the storage of the components, the PVCs can't be shrunk
+// and the StatefulSets would be stuck
+func validateStorageNotShrunk(old, tc *v1alpha1.TidbCluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	path := field.NewPath("spec")
+	if old.Spec.PD != nil && tc.Spec.PD != nil {
+		allErrs = append(allErrs, validateStorageRequestNotShrunk(old.Spec.PD.Requests, tc.Spec.PD.Requests, path.Child("pd", "requests"))...)
+		allErrs = append(allErrs, validateStorageVolumesNotShrunk(old.Spec.PD.StorageVolumes, tc.Spec.PD.StorageVolumes, path.Child("pd", "storageVolumes"))...)
+	}
+	if old.Spec.TiKV != nil && tc.Spec.TiKV != nil {
+		allErrs = append(allErrs, validateStorageRequestNotShrunk(old.Spec.TiKV.Requests, tc.Spec.TiKV.Requests, path.Child("tikv", "requests"))...)
+		allErrs = append(allErrs, validateStorageVolumesNotShrunk(old.Spec.TiKV.StorageVolumes, tc.Spec.TiKV.StorageVolumes, path.Child("tikv", "storageVolumes"))...)
+	}
+	if old.Spec.TiDB != nil && tc.Spec.TiDB != nil {
+		allErrs = append(allErrs, validateStorageVolumesNotShrunk(old.Spec.TiDB.StorageVolumes, tc.Spec.TiDB.StorageVolumes, path.Child("tidb", "storageVolumes"))...)
+	}
+	if old.Spec.TiFlash != nil && tc.Spec.TiFlash != nil {
+		claimsPath := path.Child("tiflash", "storageClaims")
+		for i := range tc.Spec.TiFlash.StorageClaims {
+			if i >= len(old.Spec.TiFlash.StorageClaims) {
+				break
+			}
+			allErrs = append(allErrs, validateStorageRequestNotShrunk(old.Spec.TiFlash.StorageClaims[i].Resources.Requests,
+				tc.Spec.TiFlash.StorageClaims[i].Resources.Requests, claimsPath.Index(i).Child("resources", "requests"))...)
+		}
+	}
+	if old.Spec.TiCDC != nil && tc.Spec.TiCDC != nil {
+		allErrs = append(allErrs, validateStorageVolumesNotShrunk(old.Spec.TiCDC.StorageVolumes, tc.Spec.TiCDC.StorageVolumes, path.Child("ticdc", "storageVolumes"))...)
+	}
+	if old.Spec.Pump != nil && tc.Spec.Pump != nil {
+		allErrs = append(allErrs, validateStorageRequestNotShrunk(old.Spec.Pump.Requests, tc.Spec.Pump.Requests, path.Child("pump", "requests"))...)
+	}
+	return allErrs
+}
+
+func validateStorageRequestNotShrunk(old, new corev1.ResourceList, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	oldSize, ok := old[corev1.ResourceStorage]
+	if !ok {
+		return allErrs
+	}
+	if newSize, ok := new[corev1.ResourceStorage]; ok && newSize.Cmp(oldSize) < 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Key(string(corev1.ResourceStorage)),
+			fmt.Sprintf("storage can't be shrunk from %s to %s", oldSize.String(), newSize.String())))
+	}
+	return allErrs
+}
+
+func validateStorageVolumesNotShrunk(old, new []v1alpha1.StorageVolume, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	oldSizes := map[string]resource.Quantity{}
+	for _, vol := range old {
+		if size, err := resource.ParseQuantity(vol.StorageSize); err == nil {
+			oldSizes[vol.Name] = size
+		}
+	}
+	for i, vol := range new {
+		oldSize, ok := oldSizes[vol.Name]
+		if !ok {
+			continue
+		}
+		if newSize, err := resource.ParseQuantity(vol.StorageSize); err == nil && newSize.Cmp(oldSize) < 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("storageSize"),
+				fmt.Sprintf("storage of volume %s can't be shrunk from %s to %s", vol.Name, oldSize.String(), newSize.String())))
+		}
+	}
+	return allErrs
+}
 
+// validateEnablingTLSCluster forbids enabling the TLS between components of a running cluster unless the
+// migration is acknowledged by the annotation tidb.pingcap.com/tls-migration, the components can't talk
+// to each other until all of them are restarted
+func validateEnablingTLSCluster(old, tc *v1alpha1.TidbCluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if old.IsTLSClusterEnabled() || !tc.IsTLSClusterEnabled() {
+		return allErrs
+	}
+	running := old.Status.PD.StatefulSet != nil || old.Status.TiKV.StatefulSet != nil || old.Status.TiDB.StatefulSet != nil
+	if running && tc.Annotations[label.AnnTLSMigration] != "true" {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "tlsCluster", "enabled"),
+			fmt.Sprintf("enabling TLS between components of a running cluster causes unavailability until all components are restarted, set annotation %s to \"true\" to acknowledge it", label.AnnTLSMigration)))
+	}
 	return allErrs
 }
 
@@ -629,6 +2625,21 @@ func validateDeleteSlots(annotations map[string]string, key string, fldPath *fie
 			if err != nil {
 				msg := fmt.Sprintf("value of %q annotation must be a JSON list of int32", key)
 				allErrs = append(allErrs, field.Invalid(fldPath, value, msg))
+				return allErrs
+			}
+			// negative or duplicated slots make the ordinals computed by the operator
+			// differ from the ones of the statefulset, which stalls the rolling upgrade
+			seen := map[int32]struct{}{}
+			for _, slot := range slice {
+				if slot < 0 {
+					allErrs = append(allErrs, field.Invalid(fldPath, value, fmt.Sprintf("delete slot %d must be non-negative", slot)))
+					continue
+				}
+				if _, ok := seen[slot]; ok {
+					allErrs = append(allErrs, field.Duplicate(fldPath, slot))
+					continue
+				}
+				seen[slot] = struct{}{}
 			}
 		}
 	}
@@ -712,13 +2723,107 @@ func clusterVersionLessThan2(version string) (bool, error) {
 	return v.Major() < 2, nil
 }
 
-func validateAdditionalContainers(containers []corev1.Container, fldPath *field.Path) field.ErrorList {
+// managedContainers are the containers in the Pods of a component created by tidb-operator
+type managedContainers struct {
+	// containers can be patched by the additional containers of the same names
+	containers []string
+	// initContainers can't be used as the names of the additional containers
+	initContainers []string
+	// ports are the container ports of the component
+	ports []int32
+}
+
+// managedContainersOf are the managed containers of the components, see the member managers for details
+var managedContainersOf = map[v1alpha1.MemberType]managedContainers{
+	v1alpha1.PDMemberType: {
+		containers:     []string{v1alpha1.PDMemberType.String()},
+		initContainers: []string{"init"},
+		ports:          []int32{2379, 2380},
+	},
+	v1alpha1.TiKVMemberType: {
+		containers:     []string{v1alpha1.TiKVMemberType.String(), v1alpha1.ContainerRocksDBLogTailer.String(), v1alpha1.ContainerRaftLogTailer.String()},
+		initContainers: []string{"init"},
+		ports:          []int32{20160, 20180},
+	},
+	v1alpha1.TiDBMemberType: {
+		containers:     []string{v1alpha1.TiDBMemberType.String(), v1alpha1.ContainerSlowLogTailer.String()},
+		initContainers: []string{"init"},
+		ports:          []int32{4000, 10080},
+	},
+	v1alpha1.TiFlashMemberType: {
+		containers:     []string{v1alpha1.TiFlashMemberType.String(), "serverlog", "errorlog", "clusterlog"},
+		initContainers: []string{"init", "sysctl"},
+		ports:          []int32{3930, 20170, 9000, 8123, 9009, 8234},
+	},
+	v1alpha1.TiCDCMemberType: {
+		containers: []string{v1alpha1.TiCDCMemberType.String()},
+		ports:      []int32{8301},
+	},
+	v1alpha1.PumpMemberType: {
+		containers: []string{v1alpha1.PumpMemberType.String()},
+		ports:      []int32{8250},
+	},
+	v1alpha1.TiProxyMemberType: {
+		containers: []string{v1alpha1.TiProxyMemberType.String()},
+		ports:      []int32{6000, 3080},
+	},
+	v1alpha1.DMMasterMemberType: {
+		containers: []string{v1alpha1.DMMasterMemberType.String()},
+		ports:      []int32{8261, 8291},
+	},
+	v1alpha1.DMWorkerMemberType: {
+		containers: []string{v1alpha1.DMWorkerMemberType.String()},
+		ports:      []int32{8262},
+	},
+}
+
+// validateAdditionalContainers validates the additional containers, which are merged into the managed
+// containers by MergePatchContainers if they have the same names:
+//
+//   - the names must be unique DNS labels and must not be the names of the managed init containers
+//   - the image can only be omitted if the container patches a managed container
+//   - the ports of the containers which are not patches must not conflict with the ports of the component
+func validateAdditionalContainers(containers []corev1.Container, managed managedContainers, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
+	patchable := sets.NewString(managed.containers...)
+	initContainers := sets.NewString(managed.initContainers...)
+	ports := sets.NewInt32(managed.ports...)
+	names := sets.NewString()
 
 	for i, container := range containers {
 		idxPath := fldPath.Index(i)
+		if container.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "empty name"))
+		} else {
+			for _, msg := range validation.IsDNS1123Label(container.Name) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), container.Name, msg))
+			}
+		}
+		if names.Has(container.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), container.Name))
+		}
+		names.Insert(container.Name)
+		if initContainers.Has(container.Name) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), container.Name,
+				fmt.Sprintf("must not be the name of the init containers %v", managed.initContainers)))
+		}
+
+		if patchable.Has(container.Name) {
+			// the ports are merged into the managed container
+			continue
+		}
 		if len(container.Image) == 0 {
-			allErrs = append(allErrs, field.Required(idxPath.Child("image"), "empty image"))
+			msg := "empty image"
+			if len(managed.containers) > 0 {
+				msg = fmt.Sprintf("empty image, the container doesn't patch any of the containers %v", managed.containers)
+			}
+			allErrs = append(allErrs, field.Required(idxPath.Child("image"), msg))
+		}
+		for j, port := range container.Ports {
+			if ports.Has(port.ContainerPort) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("ports").Index(j).Child("containerPort"), port.ContainerPort,
+					fmt.Sprintf("conflicts with the ports of the component %v", managed.ports)))
+			}
 		}
 	}
 
@@ -80,6 +80,25 @@ func (in *AutoRule) DeepCopy() *AutoRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoUpgradeStatus) DeepCopyInto(out *AutoUpgradeStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoUpgradeStatus.
+func (in *AutoUpgradeStatus) DeepCopy() *AutoUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzblobStorageProvider) DeepCopyInto(out *AzblobStorageProvider) {
 	*out = *in
@@ -299,6 +318,11 @@ func (in *BackupScheduleSpec) DeepCopyInto(out *BackupScheduleSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MaxIncrementalBackups != nil {
+		in, out := &in.MaxIncrementalBackups, &out.MaxIncrementalBackups
+		*out = new(int32)
+		**out = **in
+	}
 	in.BackupTemplate.DeepCopyInto(&out.BackupTemplate)
 	if in.StorageClassName != nil {
 		in, out := &in.StorageClassName, &out.StorageClassName
@@ -434,6 +458,11 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 	*out = *in
 	in.TimeStarted.DeepCopyInto(&out.TimeStarted)
 	in.TimeCompleted.DeepCopyInto(&out.TimeCompleted)
+	if in.DumplingProgress != nil {
+		in, out := &in.DumplingProgress, &out.DumplingProgress
+		*out = new(DumplingProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]BackupCondition, len(*in))
@@ -614,6 +643,27 @@ func (in *CDCConfigWraper) DeepCopy() *CDCConfigWraper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangefeedSink) DeepCopyInto(out *ChangefeedSink) {
+	*out = *in
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(SinkCredentialsSecret)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangefeedSink.
+func (in *ChangefeedSink) DeepCopy() *ChangefeedSink {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangefeedSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CleanOption) DeepCopyInto(out *CleanOption) {
 	*out = *in
@@ -631,6 +681,22 @@ func (in *CleanOption) DeepCopy() *CleanOption {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterConfigItem) DeepCopyInto(out *ClusterConfigItem) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConfigItem.
+func (in *ClusterConfigItem) DeepCopy() *ClusterConfigItem {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterConfigItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterRef) DeepCopyInto(out *ClusterRef) {
 	*out = *in
@@ -773,6 +839,63 @@ func (in *CommonConfig) DeepCopy() *CommonConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompactOptions) DeepCopyInto(out *CompactOptions) {
+	*out = *in
+	if in.Stores != nil {
+		in, out := &in.Stores, &out.Stores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompactOptions.
+func (in *CompactOptions) DeepCopy() *CompactOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(CompactOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentResourceReport) DeepCopyInto(out *ComponentResourceReport) {
+	*out = *in
+	if in.Requested != nil {
+		in, out := &in.Requested, &out.Requested
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentResourceReport.
+func (in *ComponentResourceReport) DeepCopy() *ComponentResourceReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentResourceReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 	*out = *in
@@ -911,6 +1034,26 @@ func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 		*out = new(SuspendAction)
 		**out = **in
 	}
+	if in.PVCDeleteTTL != nil {
+		in, out := &in.PVCDeleteTTL, &out.PVCDeleteTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(Probes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EphemeralStorage != nil {
+		in, out := &in.EphemeralStorage, &out.EphemeralStorage
+		*out = new(EphemeralStorage)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1209,6 +1352,11 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 		*out = new(SuspendAction)
 		**out = **in
 	}
+	if in.ImagePolicy != nil {
+		in, out := &in.ImagePolicy, &out.ImagePolicy
+		*out = new(ImagePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1343,6 +1491,32 @@ func (in *DMSecurityConfig) DeepCopy() *DMSecurityConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSSpec) DeepCopyInto(out *DNSSpec) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]MemberType, len(*in))
+		copy(*out, *in)
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSSpec.
+func (in *DNSSpec) DeepCopy() *DNSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DashboardConfig) DeepCopyInto(out *DashboardConfig) {
 	*out = *in
@@ -1463,6 +1637,27 @@ func (in *DataResourceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeferDeletingPVC) DeepCopyInto(out *DeferDeletingPVC) {
+	*out = *in
+	in.DeferDeletingTime.DeepCopyInto(&out.DeferDeletingTime)
+	if in.DeleteTime != nil {
+		in, out := &in.DeleteTime, &out.DeleteTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeferDeletingPVC.
+func (in *DeferDeletingPVC) DeepCopy() *DeferDeletingPVC {
+	if in == nil {
+		return nil
+	}
+	out := new(DeferDeletingPVC)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentStorageStatus) DeepCopyInto(out *DeploymentStorageStatus) {
 	*out = *in
@@ -1502,193 +1697,415 @@ func (in *DiscoverySpec) DeepCopy() *DiscoverySpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DumplingConfig) DeepCopyInto(out *DumplingConfig) {
+func (in *DiskAutoExpand) DeepCopyInto(out *DiskAutoExpand) {
 	*out = *in
-	if in.Options != nil {
-		in, out := &in.Options, &out.Options
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.StepPercent != nil {
+		in, out := &in.StepPercent, &out.StepPercent
+		*out = new(int32)
+		**out = **in
 	}
-	if in.TableFilter != nil {
-		in, out := &in.TableFilter, &out.TableFilter
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.MaxStorage = in.MaxStorage.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskAutoExpand.
+func (in *DiskAutoExpand) DeepCopy() *DiskAutoExpand {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskAutoExpand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskWatchdog) DeepCopyInto(out *DiskWatchdog) {
+	*out = *in
+	if in.UsageThresholdPercent != nil {
+		in, out := &in.UsageThresholdPercent, &out.UsageThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AutoExpand != nil {
+		in, out := &in.AutoExpand, &out.AutoExpand
+		*out = new(DiskAutoExpand)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SoftLimit != nil {
+		in, out := &in.SoftLimit, &out.SoftLimit
+		*out = new(StoreSoftLimit)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DumplingConfig.
-func (in *DumplingConfig) DeepCopy() *DumplingConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskWatchdog.
+func (in *DiskWatchdog) DeepCopy() *DiskWatchdog {
 	if in == nil {
 		return nil
 	}
-	out := new(DumplingConfig)
+	out := new(DiskWatchdog)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmptyStruct) DeepCopyInto(out *EmptyStruct) {
+func (in *DryRunChange) DeepCopyInto(out *DryRunChange) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmptyStruct.
-func (in *EmptyStruct) DeepCopy() *EmptyStruct {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunChange.
+func (in *DryRunChange) DeepCopy() *DryRunChange {
 	if in == nil {
 		return nil
 	}
-	out := new(EmptyStruct)
+	out := new(DryRunChange)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EvictLeaderStatus) DeepCopyInto(out *EvictLeaderStatus) {
+func (in *DryRunStatus) DeepCopyInto(out *DryRunStatus) {
 	*out = *in
-	in.PodCreateTime.DeepCopyInto(&out.PodCreateTime)
-	in.BeginTime.DeepCopyInto(&out.BeginTime)
+	in.LastReconcileTime.DeepCopyInto(&out.LastReconcileTime)
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]DryRunChange, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictLeaderStatus.
-func (in *EvictLeaderStatus) DeepCopy() *EvictLeaderStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunStatus.
+func (in *DryRunStatus) DeepCopy() *DryRunStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(EvictLeaderStatus)
+	out := new(DryRunStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Experimental) DeepCopyInto(out *Experimental) {
+func (in *DumplingConfig) DeepCopyInto(out *DumplingConfig) {
 	*out = *in
-	if in.AllowAutoRandom != nil {
-		in, out := &in.AllowAutoRandom, &out.AllowAutoRandom
-		*out = new(bool)
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TableFilter != nil {
+		in, out := &in.TableFilter, &out.TableFilter
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(int32)
 		**out = **in
 	}
-	if in.AllowsExpressionIndex != nil {
-		in, out := &in.AllowsExpressionIndex, &out.AllowsExpressionIndex
-		*out = new(bool)
+	if in.Rows != nil {
+		in, out := &in.Rows, &out.Rows
+		*out = new(int64)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Experimental.
-func (in *Experimental) DeepCopy() *Experimental {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DumplingConfig.
+func (in *DumplingConfig) DeepCopy() *DumplingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(Experimental)
+	out := new(DumplingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExternalConfig) DeepCopyInto(out *ExternalConfig) {
+func (in *DumplingProgress) DeepCopyInto(out *DumplingProgress) {
 	*out = *in
-	in.Endpoint.DeepCopyInto(&out.Endpoint)
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalConfig.
-func (in *ExternalConfig) DeepCopy() *ExternalConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DumplingProgress.
+func (in *DumplingProgress) DeepCopy() *DumplingProgress {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalConfig)
+	out := new(DumplingProgress)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExternalEndpoint) DeepCopyInto(out *ExternalEndpoint) {
+func (in *EmptyStruct) DeepCopyInto(out *EmptyStruct) {
 	*out = *in
-	if in.TLSSecret != nil {
-		in, out := &in.TLSSecret, &out.TLSSecret
-		*out = new(SecretRef)
-		**out = **in
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalEndpoint.
-func (in *ExternalEndpoint) DeepCopy() *ExternalEndpoint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmptyStruct.
+func (in *EmptyStruct) DeepCopy() *EmptyStruct {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalEndpoint)
+	out := new(EmptyStruct)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Failover) DeepCopyInto(out *Failover) {
+func (in *EphemeralStorage) DeepCopyInto(out *EphemeralStorage) {
 	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Limit != nil {
+		in, out := &in.Limit, &out.Limit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.TmpSizeLimit != nil {
+		in, out := &in.TmpSizeLimit, &out.TmpSizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Failover.
-func (in *Failover) DeepCopy() *Failover {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralStorage.
+func (in *EphemeralStorage) DeepCopy() *EphemeralStorage {
 	if in == nil {
 		return nil
 	}
-	out := new(Failover)
+	out := new(EphemeralStorage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FileLogConfig) DeepCopyInto(out *FileLogConfig) {
+func (in *EventThrottlingPolicy) DeepCopyInto(out *EventThrottlingPolicy) {
 	*out = *in
-	if in.Filename != nil {
-		in, out := &in.Filename, &out.Filename
-		*out = new(string)
-		**out = **in
-	}
-	if in.LogRotate != nil {
-		in, out := &in.LogRotate, &out.LogRotate
-		*out = new(bool)
-		**out = **in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]ThrottledEvent, len(*in))
+		copy(*out, *in)
 	}
-	if in.MaxSize != nil {
-		in, out := &in.MaxSize, &out.MaxSize
-		*out = new(int)
+	if in.RegionScheduleLimit != nil {
+		in, out := &in.RegionScheduleLimit, &out.RegionScheduleLimit
+		*out = new(uint64)
 		**out = **in
 	}
-	if in.MaxDays != nil {
-		in, out := &in.MaxDays, &out.MaxDays
-		*out = new(int)
+	if in.ReplicaScheduleLimit != nil {
+		in, out := &in.ReplicaScheduleLimit, &out.ReplicaScheduleLimit
+		*out = new(uint64)
 		**out = **in
 	}
-	if in.MaxBackups != nil {
-		in, out := &in.MaxBackups, &out.MaxBackups
-		*out = new(int)
+	if in.CooldownPeriod != nil {
+		in, out := &in.CooldownPeriod, &out.CooldownPeriod
+		*out = new(metav1.Duration)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileLogConfig.
-func (in *FileLogConfig) DeepCopy() *FileLogConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventThrottlingPolicy.
+func (in *EventThrottlingPolicy) DeepCopy() *EventThrottlingPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(FileLogConfig)
+	out := new(EventThrottlingPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Flash) DeepCopyInto(out *Flash) {
+func (in *EventThrottlingStatus) DeepCopyInto(out *EventThrottlingStatus) {
 	*out = *in
-	if in.TiDBStatusAddr != nil {
-		in, out := &in.TiDBStatusAddr, &out.TiDBStatusAddr
-		*out = new(string)
-		**out = **in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]ThrottledEvent, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastEventTime.DeepCopyInto(&out.LastEventTime)
+	if in.OriginalTiKVConfig != nil {
+		in, out := &in.OriginalTiKVConfig, &out.OriginalTiKVConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.OriginalScheduleConfig != nil {
+		in, out := &in.OriginalScheduleConfig, &out.OriginalScheduleConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventThrottlingStatus.
+func (in *EventThrottlingStatus) DeepCopy() *EventThrottlingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EventThrottlingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictLeaderStatus) DeepCopyInto(out *EvictLeaderStatus) {
+	*out = *in
+	in.PodCreateTime.DeepCopyInto(&out.PodCreateTime)
+	in.BeginTime.DeepCopyInto(&out.BeginTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictLeaderStatus.
+func (in *EvictLeaderStatus) DeepCopy() *EvictLeaderStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictLeaderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Experimental) DeepCopyInto(out *Experimental) {
+	*out = *in
+	if in.AllowAutoRandom != nil {
+		in, out := &in.AllowAutoRandom, &out.AllowAutoRandom
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowsExpressionIndex != nil {
+		in, out := &in.AllowsExpressionIndex, &out.AllowsExpressionIndex
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Experimental.
+func (in *Experimental) DeepCopy() *Experimental {
+	if in == nil {
+		return nil
+	}
+	out := new(Experimental)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalConfig) DeepCopyInto(out *ExternalConfig) {
+	*out = *in
+	in.Endpoint.DeepCopyInto(&out.Endpoint)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalConfig.
+func (in *ExternalConfig) DeepCopy() *ExternalConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalEndpoint) DeepCopyInto(out *ExternalEndpoint) {
+	*out = *in
+	if in.TLSSecret != nil {
+		in, out := &in.TLSSecret, &out.TLSSecret
+		*out = new(SecretRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalEndpoint.
+func (in *ExternalEndpoint) DeepCopy() *ExternalEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Failover) DeepCopyInto(out *Failover) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Failover.
+func (in *Failover) DeepCopy() *Failover {
+	if in == nil {
+		return nil
+	}
+	out := new(Failover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileLogConfig) DeepCopyInto(out *FileLogConfig) {
+	*out = *in
+	if in.Filename != nil {
+		in, out := &in.Filename, &out.Filename
+		*out = new(string)
+		**out = **in
+	}
+	if in.LogRotate != nil {
+		in, out := &in.LogRotate, &out.LogRotate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxDays != nil {
+		in, out := &in.MaxDays, &out.MaxDays
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxBackups != nil {
+		in, out := &in.MaxBackups, &out.MaxBackups
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileLogConfig.
+func (in *FileLogConfig) DeepCopy() *FileLogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FileLogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Flash) DeepCopyInto(out *Flash) {
+	*out = *in
+	if in.TiDBStatusAddr != nil {
+		in, out := &in.TiDBStatusAddr, &out.TiDBStatusAddr
+		*out = new(string)
+		**out = **in
 	}
 	if in.ServiceAddr != nil {
 		in, out := &in.ServiceAddr, &out.ServiceAddr
@@ -2065,6 +2482,29 @@ func (in *FlashUser) DeepCopy() *FlashUser {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlashbackCleanupOptions) DeepCopyInto(out *FlashbackCleanupOptions) {
+	*out = *in
+	if in.ScheduleConfig != nil {
+		in, out := &in.ScheduleConfig, &out.ScheduleConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlashbackCleanupOptions.
+func (in *FlashbackCleanupOptions) DeepCopy() *FlashbackCleanupOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(FlashbackCleanupOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GcsStorageProvider) DeepCopyInto(out *GcsStorageProvider) {
 	*out = *in
@@ -2141,6 +2581,11 @@ func (in *HelperSpec) DeepCopyInto(out *HelperSpec) {
 		*out = new(v1.PullPolicy)
 		**out = **in
 	}
+	if in.Initializer != nil {
+		in, out := &in.Initializer, &out.Initializer
+		*out = new(InitContainerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2154,6 +2599,60 @@ func (in *HelperSpec) DeepCopy() *HelperSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HugePages) DeepCopyInto(out *HugePages) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HugePages.
+func (in *HugePages) DeepCopy() *HugePages {
+	if in == nil {
+		return nil
+	}
+	out := new(HugePages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePolicy) DeepCopyInto(out *ImagePolicy) {
+	*out = *in
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ArchTagSuffixes != nil {
+		in, out := &in.ArchTagSuffixes, &out.ArchTagSuffixes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Digests != nil {
+		in, out := &in.Digests, &out.Digests
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePolicy.
+func (in *ImagePolicy) DeepCopy() *ImagePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressSpec) DeepCopyInto(out *IngressSpec) {
 	*out = *in
@@ -2193,6 +2692,21 @@ func (in *IngressSpec) DeepCopy() *IngressSpec {
 func (in *InitContainerSpec) DeepCopyInto(out *InitContainerSpec) {
 	*out = *in
 	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.ImagePullPolicy != nil {
+		in, out := &in.ImagePullPolicy, &out.ImagePullPolicy
+		*out = new(v1.PullPolicy)
+		**out = **in
+	}
+	if in.ExtraCommands != nil {
+		in, out := &in.ExtraCommands, &out.ExtraCommands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2207,31 +2721,53 @@ func (in *InitContainerSpec) DeepCopy() *InitContainerSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InitializerSpec) DeepCopyInto(out *InitializerSpec) {
+func (in *InitializerSettingsStatus) DeepCopyInto(out *InitializerSettingsStatus) {
 	*out = *in
-	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
-	if in.Envs != nil {
-		in, out := &in.Envs, &out.Envs
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.Corrected != nil {
+		in, out := &in.Corrected, &out.Corrected
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitializerSpec.
-func (in *InitializerSpec) DeepCopy() *InitializerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitializerSettingsStatus.
+func (in *InitializerSettingsStatus) DeepCopy() *InitializerSettingsStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(InitializerSpec)
+	out := new(InitializerSettingsStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Interval) DeepCopyInto(out *Interval) {
+func (in *InitializerSpec) DeepCopyInto(out *InitializerSpec) {
+	*out = *in
+	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitializerSpec.
+func (in *InitializerSpec) DeepCopy() *InitializerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InitializerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Interval) DeepCopyInto(out *Interval) {
 	*out = *in
 	if in.Duration != nil {
 		in, out := &in.Duration, &out.Duration
@@ -2297,11 +2833,42 @@ func (in *IsolationRead) DeepCopy() *IsolationRead {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerReadiness) DeepCopyInto(out *LoadBalancerReadiness) {
+	*out = *in
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]v1.PodConditionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeregistrationDelay != nil {
+		in, out := &in.DeregistrationDelay, &out.DeregistrationDelay
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerReadiness.
+func (in *LoadBalancerReadiness) DeepCopy() *LoadBalancerReadiness {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerReadiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalStorageProvider) DeepCopyInto(out *LocalStorageProvider) {
 	*out = *in
 	in.Volume.DeepCopyInto(&out.Volume)
 	in.VolumeMount.DeepCopyInto(&out.VolumeMount)
+	if in.MinFreeSpace != nil {
+		in, out := &in.MinFreeSpace, &out.MinFreeSpace
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	return
 }
 
@@ -2408,6 +2975,55 @@ func (in *LogTailerSpec) DeepCopy() *LogTailerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePDMemberStatus) DeepCopyInto(out *MaintenancePDMemberStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePDMemberStatus.
+func (in *MaintenancePDMemberStatus) DeepCopy() *MaintenancePDMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePDMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceStoreStatus) DeepCopyInto(out *MaintenanceStoreStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceStoreStatus.
+func (in *MaintenanceStoreStatus) DeepCopy() *MaintenanceStoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceStoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MasterConfig) DeepCopyInto(out *MasterConfig) {
 	*out = *in
@@ -2804,6 +3420,36 @@ func (in *NGMonitoringStatus) DeepCopy() *NGMonitoringStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicySpec) DeepCopyInto(out *NetworkPolicySpec) {
+	*out = *in
+	if in.SQLFrom != nil {
+		in, out := &in.SQLFrom, &out.SQLFrom
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PeerFrom != nil {
+		in, out := &in.PeerFrom, &out.PeerFrom
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicySpec.
+func (in *NetworkPolicySpec) DeepCopy() *NetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Networks) DeepCopyInto(out *Networks) {
 	*out = *in
@@ -2825,6 +3471,148 @@ func (in *Networks) DeepCopy() *Networks {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenance) DeepCopyInto(out *NodeMaintenance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenance.
+func (in *NodeMaintenance) DeepCopy() *NodeMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeMaintenance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceList) DeepCopyInto(out *NodeMaintenanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeMaintenance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceList.
+func (in *NodeMaintenanceList) DeepCopy() *NodeMaintenanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeMaintenanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceSpec) DeepCopyInto(out *NodeMaintenanceSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceSpec.
+func (in *NodeMaintenanceSpec) DeepCopy() *NodeMaintenanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceStatus) DeepCopyInto(out *NodeMaintenanceStatus) {
+	*out = *in
+	if in.EvictedStores != nil {
+		in, out := &in.EvictedStores, &out.EvictedStores
+		*out = make(map[string]NodeMaintenanceStore, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RescheduledPods != nil {
+		in, out := &in.RescheduledPods, &out.RescheduledPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceStatus.
+func (in *NodeMaintenanceStatus) DeepCopy() *NodeMaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceStore) DeepCopyInto(out *NodeMaintenanceStore) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceStore.
+func (in *NodeMaintenanceStore) DeepCopy() *NodeMaintenanceStore {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectDiff) DeepCopyInto(out *ObjectDiff) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectDiff.
+func (in *ObjectDiff) DeepCopy() *ObjectDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObservedStorageVolumeStatus) DeepCopyInto(out *ObservedStorageVolumeStatus) {
 	*out = *in
@@ -2956,6 +3744,54 @@ func (in *OpenTracingSampler) DeepCopy() *OpenTracingSampler {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationCheckpoint) DeepCopyInto(out *OperationCheckpoint) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationCheckpoint.
+func (in *OperationCheckpoint) DeepCopy() *OperationCheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationCheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorPolicy) DeepCopyInto(out *OperatorPolicy) {
+	*out = *in
+	if in.ResyncPeriod != nil {
+		in, out := &in.ResyncPeriod, &out.ResyncPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PDRequestTimeout != nil {
+		in, out := &in.PDRequestTimeout, &out.PDRequestTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.UpgradeWaitTimeout != nil {
+		in, out := &in.UpgradeWaitTimeout, &out.UpgradeWaitTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorPolicy.
+func (in *OperatorPolicy) DeepCopy() *OperatorPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDConfig) DeepCopyInto(out *PDConfig) {
 	*out = *in
@@ -3126,6 +3962,32 @@ func (in *PDConfigWraper) DeepCopy() *PDConfigWraper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDDefragOptions) DeepCopyInto(out *PDDefragOptions) {
+	*out = *in
+	if in.DBSizeThreshold != nil {
+		in, out := &in.DBSizeThreshold, &out.DBSizeThreshold
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MinFreePercent != nil {
+		in, out := &in.MinFreePercent, &out.MinFreePercent
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDDefragOptions.
+func (in *PDDefragOptions) DeepCopy() *PDDefragOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PDDefragOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDFailureMember) DeepCopyInto(out *PDFailureMember) {
 	*out = *in
@@ -3182,6 +4044,27 @@ func (in PDLabelPropertyConfig) DeepCopy() PDLabelPropertyConfig {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDHASpread) DeepCopyInto(out *PDHASpread) {
+	*out = *in
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDHASpread.
+func (in *PDHASpread) DeepCopy() *PDHASpread {
+	if in == nil {
+		return nil
+	}
+	out := new(PDHASpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDLogConfig) DeepCopyInto(out *PDLogConfig) {
 	*out = *in
@@ -3523,6 +4406,103 @@ func (in *PDScheduleConfig) DeepCopy() *PDScheduleConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDScheduleDrift) DeepCopyInto(out *PDScheduleDrift) {
+	*out = *in
+	in.DetectedTime.DeepCopyInto(&out.DetectedTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDScheduleDrift.
+func (in *PDScheduleDrift) DeepCopy() *PDScheduleDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(PDScheduleDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDScheduleProfile) DeepCopyInto(out *PDScheduleProfile) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]ThrottledEvent, len(*in))
+		copy(*out, *in)
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(MaintenanceWindow)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDScheduleProfile.
+func (in *PDScheduleProfile) DeepCopy() *PDScheduleProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(PDScheduleProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDScheduleProfilesStatus) DeepCopyInto(out *PDScheduleProfilesStatus) {
+	*out = *in
+	if in.ActiveProfiles != nil {
+		in, out := &in.ActiveProfiles, &out.ActiveProfiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Applied != nil {
+		in, out := &in.Applied, &out.Applied
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Original != nil {
+		in, out := &in.Original, &out.Original
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Drifts != nil {
+		in, out := &in.Drifts, &out.Drifts
+		*out = make([]PDScheduleDrift, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnknownItems != nil {
+		in, out := &in.UnknownItems, &out.UnknownItems
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDScheduleProfilesStatus.
+func (in *PDScheduleProfilesStatus) DeepCopy() *PDScheduleProfilesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PDScheduleProfilesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDSchedulerConfig) DeepCopyInto(out *PDSchedulerConfig) {
 	*out = *in
@@ -3685,6 +4665,23 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.StartScriptHooks != nil {
+		in, out := &in.StartScriptHooks, &out.StartScriptHooks
+		*out = new(StartScriptHooks)
+		**out = **in
+	}
+	if in.HASpread != nil {
+		in, out := &in.HASpread, &out.HASpread
+		*out = new(PDHASpread)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduleProfiles != nil {
+		in, out := &in.ScheduleProfiles, &out.ScheduleProfiles
+		*out = make([]PDScheduleProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -3757,6 +4754,23 @@ func (in *PDStatus) DeepCopyInto(out *PDStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DerivedConfig != nil {
+		in, out := &in.DerivedConfig, &out.DerivedConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ScheduleProfiles != nil {
+		in, out := &in.ScheduleProfiles, &out.ScheduleProfiles
+		*out = new(PDScheduleProfilesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InFlightOperation != nil {
+		in, out := &in.InFlightOperation, &out.InFlightOperation
+		*out = new(OperationCheckpoint)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3919,6 +4933,67 @@ func (in *Performance) DeepCopy() *Performance {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerformanceProfile) DeepCopyInto(out *PerformanceProfile) {
+	*out = *in
+	if in.HugePages != nil {
+		in, out := &in.HugePages, &out.HugePages
+		*out = new(HugePages)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceProfile.
+func (in *PerformanceProfile) DeepCopy() *PerformanceProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(PerformanceProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentIdentitySpec) DeepCopyInto(out *PersistentIdentitySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentIdentitySpec.
+func (in *PersistentIdentitySpec) DeepCopy() *PersistentIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentIdentityStatus) DeepCopyInto(out *PersistentIdentityStatus) {
+	*out = *in
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RecoveryTime != nil {
+		in, out := &in.RecoveryTime, &out.RecoveryTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentIdentityStatus.
+func (in *PersistentIdentityStatus) DeepCopy() *PersistentIdentityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentIdentityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PessimisticTxn) DeepCopyInto(out *PessimisticTxn) {
 	*out = *in
@@ -4003,173 +5078,358 @@ func (in *Plugin) DeepCopy() *Plugin {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PreparedPlanCache) DeepCopyInto(out *PreparedPlanCache) {
+func (in *PodMonitorSpec) DeepCopyInto(out *PodMonitorSpec) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Capacity != nil {
-		in, out := &in.Capacity, &out.Capacity
-		*out = new(uint)
-		**out = **in
-	}
-	if in.MemoryGuardRatio != nil {
-		in, out := &in.MemoryGuardRatio, &out.MemoryGuardRatio
-		*out = new(float64)
-		**out = **in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreparedPlanCache.
-func (in *PreparedPlanCache) DeepCopy() *PreparedPlanCache {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodMonitorSpec.
+func (in *PodMonitorSpec) DeepCopy() *PodMonitorSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PreparedPlanCache)
+	out := new(PodMonitorSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Profile) DeepCopyInto(out *Profile) {
+func (in *PodTemplateChange) DeepCopyInto(out *PodTemplateChange) {
 	*out = *in
-	if in.Readonly != nil {
-		in, out := &in.Readonly, &out.Readonly
-		*out = new(int32)
-		**out = **in
-	}
-	if in.MaxMemoryUsage != nil {
-		in, out := &in.MaxMemoryUsage, &out.MaxMemoryUsage
-		*out = new(int64)
-		**out = **in
-	}
-	if in.UseUncompressedCache != nil {
-		in, out := &in.UseUncompressedCache, &out.UseUncompressedCache
-		*out = new(int32)
-		**out = **in
-	}
-	if in.LoadBalancing != nil {
-		in, out := &in.LoadBalancing, &out.LoadBalancing
-		*out = new(string)
-		**out = **in
+	in.ChangeTime.DeepCopyInto(&out.ChangeTime)
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Profile.
-func (in *Profile) DeepCopy() *Profile {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodTemplateChange.
+func (in *PodTemplateChange) DeepCopy() *PodTemplateChange {
 	if in == nil {
 		return nil
 	}
-	out := new(Profile)
+	out := new(PodTemplateChange)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusConfiguration) DeepCopyInto(out *PrometheusConfiguration) {
+func (in *PodSysctlStatus) DeepCopyInto(out *PodSysctlStatus) {
 	*out = *in
-	if in.ConfigMapRef != nil {
-		in, out := &in.ConfigMapRef, &out.ConfigMapRef
-		*out = new(ConfigMapRef)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CommandOptions != nil {
-		in, out := &in.CommandOptions, &out.CommandOptions
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.RuleConfigRef != nil {
-		in, out := &in.RuleConfigRef, &out.RuleConfigRef
-		*out = new(ConfigMapRef)
-		(*in).DeepCopyInto(*out)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusConfiguration.
-func (in *PrometheusConfiguration) DeepCopy() *PrometheusConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSysctlStatus.
+func (in *PodSysctlStatus) DeepCopy() *PodSysctlStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusConfiguration)
+	out := new(PodSysctlStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusReloaderSpec) DeepCopyInto(out *PrometheusReloaderSpec) {
+func (in *PreflightCheckResult) DeepCopyInto(out *PreflightCheckResult) {
 	*out = *in
-	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusReloaderSpec.
-func (in *PrometheusReloaderSpec) DeepCopy() *PrometheusReloaderSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightCheckResult.
+func (in *PreflightCheckResult) DeepCopy() *PreflightCheckResult {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusReloaderSpec)
+	out := new(PreflightCheckResult)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
+func (in *PreflightStorageCheck) DeepCopyInto(out *PreflightStorageCheck) {
 	*out = *in
-	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
-	in.Service.DeepCopyInto(&out.Service)
-	if in.RetentionTime != nil {
-		in, out := &in.RetentionTime, &out.RetentionTime
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
 		*out = new(string)
 		**out = **in
 	}
-	if in.Ingress != nil {
-		in, out := &in.Ingress, &out.Ingress
-		*out = new(IngressSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Config != nil {
-		in, out := &in.Config, &out.Config
-		*out = new(PrometheusConfiguration)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.RemoteWrite != nil {
-		in, out := &in.RemoteWrite, &out.RemoteWrite
-		*out = make([]*RemoteWriteSpec, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(RemoteWriteSpec)
-				(*in).DeepCopyInto(*out)
-			}
-		}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
 	}
-	if in.AdditionalVolumeMounts != nil {
-		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.MaxWriteLatency != nil {
+		in, out := &in.MaxWriteLatency, &out.MaxWriteLatency
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusSpec.
-func (in *PrometheusSpec) DeepCopy() *PrometheusSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightStorageCheck.
+func (in *PreflightStorageCheck) DeepCopy() *PreflightStorageCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusSpec)
+	out := new(PreflightStorageCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
+func (in *PreparedPlanCache) DeepCopyInto(out *PreparedPlanCache) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(uint)
+		**out = **in
+	}
+	if in.MemoryGuardRatio != nil {
+		in, out := &in.MemoryGuardRatio, &out.MemoryGuardRatio
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreparedPlanCache.
+func (in *PreparedPlanCache) DeepCopy() *PreparedPlanCache {
+	if in == nil {
+		return nil
+	}
+	out := new(PreparedPlanCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Probe) DeepCopyInto(out *Probe) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(string)
+		**out = **in
+	}
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Probe.
+func (in *Probe) DeepCopy() *Probe {
+	if in == nil {
+		return nil
+	}
+	out := new(Probe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Probes) DeepCopyInto(out *Probes) {
+	*out = *in
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Probes.
+func (in *Probes) DeepCopy() *Probes {
+	if in == nil {
+		return nil
+	}
+	out := new(Probes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Profile) DeepCopyInto(out *Profile) {
+	*out = *in
+	if in.Readonly != nil {
+		in, out := &in.Readonly, &out.Readonly
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxMemoryUsage != nil {
+		in, out := &in.MaxMemoryUsage, &out.MaxMemoryUsage
+		*out = new(int64)
+		**out = **in
+	}
+	if in.UseUncompressedCache != nil {
+		in, out := &in.UseUncompressedCache, &out.UseUncompressedCache
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LoadBalancing != nil {
+		in, out := &in.LoadBalancing, &out.LoadBalancing
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Profile.
+func (in *Profile) DeepCopy() *Profile {
+	if in == nil {
+		return nil
+	}
+	out := new(Profile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusConfiguration) DeepCopyInto(out *PrometheusConfiguration) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ConfigMapRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommandOptions != nil {
+		in, out := &in.CommandOptions, &out.CommandOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RuleConfigRef != nil {
+		in, out := &in.RuleConfigRef, &out.RuleConfigRef
+		*out = new(ConfigMapRef)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusConfiguration.
+func (in *PrometheusConfiguration) DeepCopy() *PrometheusConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusReloaderSpec) DeepCopyInto(out *PrometheusReloaderSpec) {
+	*out = *in
+	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusReloaderSpec.
+func (in *PrometheusReloaderSpec) DeepCopy() *PrometheusReloaderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusReloaderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
+	*out = *in
+	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
+	in.Service.DeepCopyInto(&out.Service)
+	if in.RetentionTime != nil {
+		in, out := &in.RetentionTime, &out.RetentionTime
+		*out = new(string)
+		**out = **in
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(IngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(PrometheusConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		*out = make([]*RemoteWriteSpec, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RemoteWriteSpec)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.AdditionalVolumeMounts != nil {
+		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusSpec.
+func (in *PrometheusSpec) DeepCopy() *PrometheusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
 	*out = *in
 	if in.LogLevel != nil {
 		in, out := &in.LogLevel, &out.LogLevel
@@ -4440,48 +5700,127 @@ func (in *Quota) DeepCopy() *Quota {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RelabelConfig) DeepCopyInto(out *RelabelConfig) {
+func (in *Recommendations) DeepCopyInto(out *Recommendations) {
 	*out = *in
-	if in.SourceLabels != nil {
-		in, out := &in.SourceLabels, &out.SourceLabels
-		*out = make(model.LabelNames, len(*in))
-		copy(*out, *in)
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.TiDB != nil {
+		in, out := &in.TiDB, &out.TiDB
+		*out = new(TiDBReplicasRecommendation)
+		**out = **in
+	}
+	if in.TiKV != nil {
+		in, out := &in.TiKV, &out.TiKV
+		*out = new(TiKVStorageRecommendation)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelabelConfig.
-func (in *RelabelConfig) DeepCopy() *RelabelConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Recommendations.
+func (in *Recommendations) DeepCopy() *Recommendations {
 	if in == nil {
 		return nil
 	}
-	out := new(RelabelConfig)
+	out := new(Recommendations)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReloaderSpec) DeepCopyInto(out *ReloaderSpec) {
+func (in *RecommendationsSpec) DeepCopyInto(out *RecommendationsSpec) {
 	*out = *in
-	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
-	in.Service.DeepCopyInto(&out.Service)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(string)
+		**out = **in
+	}
+	if in.TiDBTargetCPUUtilization != nil {
+		in, out := &in.TiDBTargetCPUUtilization, &out.TiDBTargetCPUUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TiKVMinStorageHeadroom != nil {
+		in, out := &in.TiKVMinStorageHeadroom, &out.TiKVMinStorageHeadroom
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReloaderSpec.
-func (in *ReloaderSpec) DeepCopy() *ReloaderSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendationsSpec.
+func (in *RecommendationsSpec) DeepCopy() *RecommendationsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ReloaderSpec)
+	out := new(RecommendationsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RemoteWriteSpec) DeepCopyInto(out *RemoteWriteSpec) {
+func (in *RelabelConfig) DeepCopyInto(out *RelabelConfig) {
 	*out = *in
-	if in.RemoteTimeout != nil {
+	if in.SourceLabels != nil {
+		in, out := &in.SourceLabels, &out.SourceLabels
+		*out = make(model.LabelNames, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelabelConfig.
+func (in *RelabelConfig) DeepCopy() *RelabelConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RelabelConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseMetadataSource) DeepCopyInto(out *ReleaseMetadataSource) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseMetadataSource.
+func (in *ReleaseMetadataSource) DeepCopy() *ReleaseMetadataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseMetadataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReloaderSpec) DeepCopyInto(out *ReloaderSpec) {
+	*out = *in
+	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
+	in.Service.DeepCopyInto(&out.Service)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReloaderSpec.
+func (in *ReloaderSpec) DeepCopy() *ReloaderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReloaderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteWriteSpec) DeepCopyInto(out *RemoteWriteSpec) {
+	*out = *in
+	if in.RemoteTimeout != nil {
 		in, out := &in.RemoteTimeout, &out.RemoteTimeout
 		*out = new(model.Duration)
 		**out = **in
@@ -4538,6 +5877,102 @@ func (in *RemoteWriteSpec) DeepCopy() *RemoteWriteSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceDiskOptions) DeepCopyInto(out *ReplaceDiskOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceDiskOptions.
+func (in *ReplaceDiskOptions) DeepCopy() *ReplaceDiskOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceDiskOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceQuotaSpec) DeepCopyInto(out *ResourceQuotaSpec) {
+	*out = *in
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.HeadroomPercent != nil {
+		in, out := &in.HeadroomPercent, &out.HeadroomPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DefaultRequests != nil {
+		in, out := &in.DefaultRequests, &out.DefaultRequests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuotaSpec.
+func (in *ResourceQuotaSpec) DeepCopy() *ResourceQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceReport) DeepCopyInto(out *ResourceReport) {
+	*out = *in
+	in.LastReportTime.DeepCopyInto(&out.LastReportTime)
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[MemberType]ComponentResourceReport, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceReport.
+func (in *ResourceReport) DeepCopy() *ResourceReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceReportSpec) DeepCopyInto(out *ResourceReportSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceReportSpec.
+func (in *ResourceReportSpec) DeepCopy() *ResourceReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Restore) DeepCopyInto(out *Restore) {
 	*out = *in
@@ -4566,6 +6001,29 @@ func (in *Restore) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreChecksumVerification) DeepCopyInto(out *RestoreChecksumVerification) {
+	*out = *in
+	if in.Mismatches != nil {
+		in, out := &in.Mismatches, &out.Mismatches
+		*out = make([]TableChecksumMismatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreChecksumVerification.
+func (in *RestoreChecksumVerification) DeepCopy() *RestoreChecksumVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreChecksumVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreCondition) DeepCopyInto(out *RestoreCondition) {
 	*out = *in
@@ -4616,6 +6074,113 @@ func (in *RestoreList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSchedule) DeepCopyInto(out *RestoreSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSchedule.
+func (in *RestoreSchedule) DeepCopy() *RestoreSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RestoreSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreScheduleList) DeepCopyInto(out *RestoreScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RestoreSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreScheduleList.
+func (in *RestoreScheduleList) DeepCopy() *RestoreScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RestoreScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreScheduleSpec) DeepCopyInto(out *RestoreScheduleSpec) {
+	*out = *in
+	if in.MaxRestores != nil {
+		in, out := &in.MaxRestores, &out.MaxRestores
+		*out = new(int32)
+		**out = **in
+	}
+	in.RestoreTemplate.DeepCopyInto(&out.RestoreTemplate)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreScheduleSpec.
+func (in *RestoreScheduleSpec) DeepCopy() *RestoreScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreScheduleStatus) DeepCopyInto(out *RestoreScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StandbyTime != nil {
+		in, out := &in.StandbyTime, &out.StandbyTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreScheduleStatus.
+func (in *RestoreScheduleStatus) DeepCopy() *RestoreScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 	*out = *in
@@ -4693,6 +6258,11 @@ func (in *RestoreStatus) DeepCopyInto(out *RestoreStatus) {
 	*out = *in
 	in.TimeStarted.DeepCopyInto(&out.TimeStarted)
 	in.TimeCompleted.DeepCopyInto(&out.TimeCompleted)
+	if in.ChecksumVerification != nil {
+		in, out := &in.ChecksumVerification, &out.ChecksumVerification
+		*out = new(RestoreChecksumVerification)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]RestoreCondition, len(*in))
@@ -4714,61 +6284,147 @@ func (in *RestoreStatus) DeepCopy() *RestoreStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *S3StorageProvider) DeepCopyInto(out *S3StorageProvider) {
+func (in *RolloutBudget) DeepCopyInto(out *RolloutBudget) {
 	*out = *in
-	if in.Options != nil {
-		in, out := &in.Options, &out.Options
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3StorageProvider.
-func (in *S3StorageProvider) DeepCopy() *S3StorageProvider {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBudget.
+func (in *RolloutBudget) DeepCopy() *RolloutBudget {
 	if in == nil {
 		return nil
 	}
-	out := new(S3StorageProvider)
+	out := new(RolloutBudget)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SafeTLSConfig) DeepCopyInto(out *SafeTLSConfig) {
+func (in *RolloutBudgetStatus) DeepCopyInto(out *RolloutBudgetStatus) {
 	*out = *in
-	in.CA.DeepCopyInto(&out.CA)
-	in.Cert.DeepCopyInto(&out.Cert)
-	if in.KeySecret != nil {
-		in, out := &in.KeySecret, &out.KeySecret
-		*out = new(v1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
-	}
+	in.WindowStart.DeepCopyInto(&out.WindowStart)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SafeTLSConfig.
-func (in *SafeTLSConfig) DeepCopy() *SafeTLSConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBudgetStatus.
+func (in *RolloutBudgetStatus) DeepCopy() *RolloutBudgetStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SafeTLSConfig)
+	out := new(RolloutBudgetStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretOrConfigMap) DeepCopyInto(out *SecretOrConfigMap) {
+func (in *S3StorageProvider) DeepCopyInto(out *S3StorageProvider) {
 	*out = *in
-	if in.Secret != nil {
-		in, out := &in.Secret, &out.Secret
-		*out = new(v1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.ConfigMap != nil {
-		in, out := &in.ConfigMap, &out.ConfigMap
-		*out = new(v1.ConfigMapKeySelector)
-		(*in).DeepCopyInto(*out)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3StorageProvider.
+func (in *S3StorageProvider) DeepCopy() *S3StorageProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(S3StorageProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLProbeSpec) DeepCopyInto(out *SQLProbeSpec) {
+	*out = *in
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLProbeSpec.
+func (in *SQLProbeSpec) DeepCopy() *SQLProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SafeTLSConfig) DeepCopyInto(out *SafeTLSConfig) {
+	*out = *in
+	in.CA.DeepCopyInto(&out.CA)
+	in.Cert.DeepCopyInto(&out.Cert)
+	if in.KeySecret != nil {
+		in, out := &in.KeySecret, &out.KeySecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SafeTLSConfig.
+func (in *SafeTLSConfig) DeepCopy() *SafeTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SafeTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScatterRegionsOptions) DeepCopyInto(out *ScatterRegionsOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScatterRegionsOptions.
+func (in *ScatterRegionsOptions) DeepCopy() *ScatterRegionsOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ScatterRegionsOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretOrConfigMap) DeepCopyInto(out *SecretOrConfigMap) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -4926,6 +6582,54 @@ func (in *ServiceSpec) DeepCopy() *ServiceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkCredentialsSecret) DeepCopyInto(out *SinkCredentialsSecret) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkCredentialsSecret.
+func (in *SinkCredentialsSecret) DeepCopy() *SinkCredentialsSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkCredentialsSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoftLimitedStore) DeepCopyInto(out *SoftLimitedStore) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoftLimitedStore.
+func (in *SoftLimitedStore) DeepCopy() *SoftLimitedStore {
+	if in == nil {
+		return nil
+	}
+	out := new(SoftLimitedStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartScriptHooks) DeepCopyInto(out *StartScriptHooks) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartScriptHooks.
+func (in *StartScriptHooks) DeepCopy() *StartScriptHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(StartScriptHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Status) DeepCopyInto(out *Status) {
 	*out = *in
@@ -5030,6 +6734,27 @@ func (in *StorageClaim) DeepCopy() *StorageClaim {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassMigrationStatus) DeepCopyInto(out *StorageClassMigrationStatus) {
+	*out = *in
+	if in.MigratedPods != nil {
+		in, out := &in.MigratedPods, &out.MigratedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassMigrationStatus.
+func (in *StorageClassMigrationStatus) DeepCopy() *StorageClassMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageProvider) DeepCopyInto(out *StorageProvider) {
 	*out = *in
@@ -5104,6 +6829,32 @@ func (in *StorageVolumeStatus) DeepCopy() *StorageVolumeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreSoftLimit) DeepCopyInto(out *StoreSoftLimit) {
+	*out = *in
+	if in.UsageThresholdPercent != nil {
+		in, out := &in.UsageThresholdPercent, &out.UsageThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AddPeerRate != nil {
+		in, out := &in.AddPeerRate, &out.AddPeerRate
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreSoftLimit.
+func (in *StoreSoftLimit) DeepCopy() *StoreSoftLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreSoftLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SuspendAction) DeepCopyInto(out *SuspendAction) {
 	*out = *in
@@ -5120,6 +6871,30 @@ func (in *SuspendAction) DeepCopy() *SuspendAction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncDiffStatus) DeepCopyInto(out *SyncDiffStatus) {
+	*out = *in
+	in.LastChangeTime.DeepCopyInto(&out.LastChangeTime)
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]ObjectDiff, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncDiffStatus.
+func (in *SyncDiffStatus) DeepCopy() *SyncDiffStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncDiffStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSCluster) DeepCopyInto(out *TLSCluster) {
 	*out = *in
@@ -5153,6 +6928,44 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TableChecksum) DeepCopyInto(out *TableChecksum) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TableChecksum.
+func (in *TableChecksum) DeepCopy() *TableChecksum {
+	if in == nil {
+		return nil
+	}
+	out := new(TableChecksum)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TableChecksumMismatch) DeepCopyInto(out *TableChecksumMismatch) {
+	*out = *in
+	out.Expected = in.Expected
+	if in.Actual != nil {
+		in, out := &in.Actual, &out.Actual
+		*out = new(TableChecksum)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TableChecksumMismatch.
+func (in *TableChecksumMismatch) DeepCopy() *TableChecksumMismatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TableChecksumMismatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThanosSpec) DeepCopyInto(out *ThanosSpec) {
 	*out = *in
@@ -5205,6 +7018,11 @@ func (in *ThanosSpec) DeepCopy() *ThanosSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiCDCCapture) DeepCopyInto(out *TiCDCCapture) {
 	*out = *in
+	if in.GracefulShutdown != nil {
+		in, out := &in.GracefulShutdown, &out.GracefulShutdown
+		*out = new(TiCDCGracefulShutdownStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5218,6 +7036,39 @@ func (in *TiCDCCapture) DeepCopy() *TiCDCCapture {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiCDCChangefeed) DeepCopyInto(out *TiCDCChangefeed) {
+	*out = *in
+	in.Sink.DeepCopyInto(&out.Sink)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCChangefeed.
+func (in *TiCDCChangefeed) DeepCopy() *TiCDCChangefeed {
+	if in == nil {
+		return nil
+	}
+	out := new(TiCDCChangefeed)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiCDCChangefeedStatus) DeepCopyInto(out *TiCDCChangefeedStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCChangefeedStatus.
+func (in *TiCDCChangefeedStatus) DeepCopy() *TiCDCChangefeedStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TiCDCChangefeedStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiCDCConfig) DeepCopyInto(out *TiCDCConfig) {
 	*out = *in
@@ -5254,6 +7105,26 @@ func (in *TiCDCConfig) DeepCopy() *TiCDCConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiCDCGracefulShutdownStatus) DeepCopyInto(out *TiCDCGracefulShutdownStatus) {
+	*out = *in
+	if in.LastDrainTime != nil {
+		in, out := &in.LastDrainTime, &out.LastDrainTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCGracefulShutdownStatus.
+func (in *TiCDCGracefulShutdownStatus) DeepCopy() *TiCDCGracefulShutdownStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TiCDCGracefulShutdownStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiCDCSpec) DeepCopyInto(out *TiCDCSpec) {
 	*out = *in
@@ -5286,6 +7157,18 @@ func (in *TiCDCSpec) DeepCopyInto(out *TiCDCSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.DrainRetryInterval != nil {
+		in, out := &in.DrainRetryInterval, &out.DrainRetryInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Changefeeds != nil {
+		in, out := &in.Changefeeds, &out.Changefeeds
+		*out = make([]TiCDCChangefeed, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -5311,7 +7194,7 @@ func (in *TiCDCStatus) DeepCopyInto(out *TiCDCStatus) {
 		in, out := &in.Captures, &out.Captures
 		*out = make(map[string]TiCDCCapture, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	if in.Volumes != nil {
@@ -5336,6 +7219,13 @@ func (in *TiCDCStatus) DeepCopyInto(out *TiCDCStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Changefeeds != nil {
+		in, out := &in.Changefeeds, &out.Changefeeds
+		*out = make(map[string]TiCDCChangefeedStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -5715,10 +7605,26 @@ func (in *TiDBProbe) DeepCopy() *TiDBProbe {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TiDBServiceSpec) DeepCopyInto(out *TiDBServiceSpec) {
+func (in *TiDBReplicasRecommendation) DeepCopyInto(out *TiDBReplicasRecommendation) {
 	*out = *in
-	in.ServiceSpec.DeepCopyInto(&out.ServiceSpec)
-	if in.ExternalTrafficPolicy != nil {
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBReplicasRecommendation.
+func (in *TiDBReplicasRecommendation) DeepCopy() *TiDBReplicasRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBReplicasRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBServiceSpec) DeepCopyInto(out *TiDBServiceSpec) {
+	*out = *in
+	in.ServiceSpec.DeepCopyInto(&out.ServiceSpec)
+	if in.ExternalTrafficPolicy != nil {
 		in, out := &in.ExternalTrafficPolicy, &out.ExternalTrafficPolicy
 		*out = new(v1.ServiceExternalTrafficPolicyType)
 		**out = **in
@@ -5745,6 +7651,11 @@ func (in *TiDBServiceSpec) DeepCopyInto(out *TiDBServiceSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TopologyAwareRouting != nil {
+		in, out := &in.TopologyAwareRouting, &out.TopologyAwareRouting
+		*out = new(TopologyAwareRouting)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5857,6 +7768,21 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(TiDBInitializer)
 		**out = **in
 	}
+	if in.LoadBalancerReadiness != nil {
+		in, out := &in.LoadBalancerReadiness, &out.LoadBalancerReadiness
+		*out = new(LoadBalancerReadiness)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartScriptHooks != nil {
+		in, out := &in.StartScriptHooks, &out.StartScriptHooks
+		*out = new(StartScriptHooks)
+		**out = **in
+	}
+	if in.WarmUp != nil {
+		in, out := &in.WarmUp, &out.WarmUp
+		*out = new(TiDBWarmUp)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5919,6 +7845,13 @@ func (in *TiDBStatus) DeepCopyInto(out *TiDBStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DerivedConfig != nil {
+		in, out := &in.DerivedConfig, &out.DerivedConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -5948,6 +7881,32 @@ func (in *TiDBTLSClient) DeepCopy() *TiDBTLSClient {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBWarmUp) DeepCopyInto(out *TiDBWarmUp) {
+	*out = *in
+	if in.SQLs != nil {
+		in, out := &in.SQLs, &out.SQLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBWarmUp.
+func (in *TiDBWarmUp) DeepCopy() *TiDBWarmUp {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBWarmUp)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiFlashCommonConfigWraper) DeepCopyInto(out *TiFlashCommonConfigWraper) {
 	*out = *in
@@ -5968,6 +7927,37 @@ func (in *TiFlashCommonConfigWraper) DeepCopy() *TiFlashCommonConfigWraper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiFlashComputeResources) DeepCopyInto(out *TiFlashComputeResources) {
+	*out = *in
+	if in.MaxMemoryUsageForAllQueries != nil {
+		in, out := &in.MaxMemoryUsageForAllQueries, &out.MaxMemoryUsageForAllQueries
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxThreads != nil {
+		in, out := &in.MaxThreads, &out.MaxThreads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackgroundPoolSize != nil {
+		in, out := &in.BackgroundPoolSize, &out.BackgroundPoolSize
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiFlashComputeResources.
+func (in *TiFlashComputeResources) DeepCopy() *TiFlashComputeResources {
+	if in == nil {
+		return nil
+	}
+	out := new(TiFlashComputeResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiFlashConfig) DeepCopyInto(out *TiFlashConfig) {
 	*out = *in
@@ -6062,6 +8052,11 @@ func (in *TiFlashSpec) DeepCopyInto(out *TiFlashSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.StoreCapacity != nil {
+		in, out := &in.StoreCapacity, &out.StoreCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = new(TiFlashConfigWraper)
@@ -6082,6 +8077,21 @@ func (in *TiFlashSpec) DeepCopyInto(out *TiFlashSpec) {
 		*out = new(Failover)
 		**out = **in
 	}
+	if in.PerformanceProfile != nil {
+		in, out := &in.PerformanceProfile, &out.PerformanceProfile
+		*out = new(PerformanceProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComputeResources != nil {
+		in, out := &in.ComputeResources, &out.ComputeResources
+		*out = new(TiFlashComputeResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartScriptHooks != nil {
+		in, out := &in.StartScriptHooks, &out.StartScriptHooks
+		*out = new(StartScriptHooks)
+		**out = **in
+	}
 	return
 }
 
@@ -6153,6 +8163,11 @@ func (in *TiFlashStatus) DeepCopyInto(out *TiFlashStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.StorageClassMigration != nil {
+		in, out := &in.StorageClassMigration, &out.StorageClassMigration
+		*out = new(StorageClassMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7938,6 +9953,11 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = new(Failover)
 		**out = **in
 	}
+	if in.StoreRelocation != nil {
+		in, out := &in.StoreRelocation, &out.StoreRelocation
+		*out = new(TiKVStoreRelocation)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MountClusterClientSecret != nil {
 		in, out := &in.MountClusterClientSecret, &out.MountClusterClientSecret
 		*out = new(bool)
@@ -7955,11 +9975,31 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.StoreCapacity != nil {
+		in, out := &in.StoreCapacity, &out.StoreCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	if in.StoreLabels != nil {
 		in, out := &in.StoreLabels, &out.StoreLabels
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.EvictLeader != nil {
+		in, out := &in.EvictLeader, &out.EvictLeader
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PerformanceProfile != nil {
+		in, out := &in.PerformanceProfile, &out.PerformanceProfile
+		*out = new(PerformanceProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartScriptHooks != nil {
+		in, out := &in.StartScriptHooks, &out.StartScriptHooks
+		*out = new(StartScriptHooks)
+		**out = **in
+	}
 	return
 }
 
@@ -8046,6 +10086,30 @@ func (in *TiKVStatus) DeepCopyInto(out *TiKVStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DerivedConfig != nil {
+		in, out := &in.DerivedConfig, &out.DerivedConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]PodSysctlStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.InFlightOperation != nil {
+		in, out := &in.InFlightOperation, &out.InFlightOperation
+		*out = new(OperationCheckpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageClassMigration != nil {
+		in, out := &in.StorageClassMigration, &out.StorageClassMigration
+		*out = new(StorageClassMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -8166,6 +10230,24 @@ func (in *TiKVStorageReadPoolConfig) DeepCopy() *TiKVStorageReadPoolConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVStorageRecommendation) DeepCopyInto(out *TiKVStorageRecommendation) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+	out.Available = in.Available.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVStorageRecommendation.
+func (in *TiKVStorageRecommendation) DeepCopy() *TiKVStorageRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVStorageRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVStore) DeepCopyInto(out *TiKVStore) {
 	*out = *in
@@ -8183,6 +10265,27 @@ func (in *TiKVStore) DeepCopy() *TiKVStore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVStoreRelocation) DeepCopyInto(out *TiKVStoreRelocation) {
+	*out = *in
+	if in.PendingThreshold != nil {
+		in, out := &in.PendingThreshold, &out.PendingThreshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVStoreRelocation.
+func (in *TiKVStoreRelocation) DeepCopy() *TiKVStoreRelocation {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVStoreRelocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVTitanCfConfig) DeepCopyInto(out *TiKVTitanCfConfig) {
 	*out = *in
@@ -8331,6 +10434,61 @@ func (in *TiKVUnifiedReadPoolConfig) DeepCopy() *TiKVUnifiedReadPoolConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiProxySpec) DeepCopyInto(out *TiProxySpec) {
+	*out = *in
+	in.ComponentSpec.DeepCopyInto(&out.ComponentSpec)
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiProxySpec.
+func (in *TiProxySpec) DeepCopy() *TiProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TiProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiProxyStatus) DeepCopyInto(out *TiProxyStatus) {
+	*out = *in
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(appsv1.StatefulSetStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiProxyStatus.
+func (in *TiProxyStatus) DeepCopy() *TiProxyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TiProxyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TidbAutoScalerSpec) DeepCopyInto(out *TidbAutoScalerSpec) {
 	*out = *in
@@ -8530,29 +10688,357 @@ func (in *TidbClusterAutoScalerStatus) DeepCopy() *TidbClusterAutoScalerStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TidbClusterCondition) DeepCopyInto(out *TidbClusterCondition) {
 	*out = *in
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterCondition.
+func (in *TidbClusterCondition) DeepCopy() *TidbClusterCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterList) DeepCopyInto(out *TidbClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TidbCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterList.
+func (in *TidbClusterList) DeepCopy() *TidbClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterMaintenance) DeepCopyInto(out *TidbClusterMaintenance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterMaintenance.
+func (in *TidbClusterMaintenance) DeepCopy() *TidbClusterMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterMaintenance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterMaintenanceList) DeepCopyInto(out *TidbClusterMaintenanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TidbClusterMaintenance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterMaintenanceList.
+func (in *TidbClusterMaintenanceList) DeepCopy() *TidbClusterMaintenanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterMaintenanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterMaintenanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterMaintenanceSpec) DeepCopyInto(out *TidbClusterMaintenanceSpec) {
+	*out = *in
+	out.Cluster = in.Cluster
+	if in.Compact != nil {
+		in, out := &in.Compact, &out.Compact
+		*out = new(CompactOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScatterRegions != nil {
+		in, out := &in.ScatterRegions, &out.ScatterRegions
+		*out = new(ScatterRegionsOptions)
+		**out = **in
+	}
+	if in.FlashbackCleanup != nil {
+		in, out := &in.FlashbackCleanup, &out.FlashbackCleanup
+		*out = new(FlashbackCleanupOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PDDefrag != nil {
+		in, out := &in.PDDefrag, &out.PDDefrag
+		*out = new(PDDefragOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterMaintenanceSpec.
+func (in *TidbClusterMaintenanceSpec) DeepCopy() *TidbClusterMaintenanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterMaintenanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterMaintenanceStatus) DeepCopyInto(out *TidbClusterMaintenanceStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Stores != nil {
+		in, out := &in.Stores, &out.Stores
+		*out = make(map[string]MaintenanceStoreStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PDMembers != nil {
+		in, out := &in.PDMembers, &out.PDMembers
+		*out = make(map[string]MaintenancePDMemberStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterMaintenanceStatus.
+func (in *TidbClusterMaintenanceStatus) DeepCopy() *TidbClusterMaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterMaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterOperation) DeepCopyInto(out *TidbClusterOperation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterOperation.
+func (in *TidbClusterOperation) DeepCopy() *TidbClusterOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterOperationList) DeepCopyInto(out *TidbClusterOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TidbClusterOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterOperationList.
+func (in *TidbClusterOperationList) DeepCopy() *TidbClusterOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterOperationRecord) DeepCopyInto(out *TidbClusterOperationRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterOperationRecord.
+func (in *TidbClusterOperationRecord) DeepCopy() *TidbClusterOperationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterOperationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterOperationSpec) DeepCopyInto(out *TidbClusterOperationSpec) {
+	*out = *in
+	out.Cluster = in.Cluster
+	if in.ReplaceDisk != nil {
+		in, out := &in.ReplaceDisk, &out.ReplaceDisk
+		*out = new(ReplaceDiskOptions)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterOperationSpec.
+func (in *TidbClusterOperationSpec) DeepCopy() *TidbClusterOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterOperationStatus) DeepCopyInto(out *TidbClusterOperationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Records != nil {
+		in, out := &in.Records, &out.Records
+		*out = make([]TidbClusterOperationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterOperationStatus.
+func (in *TidbClusterOperationStatus) DeepCopy() *TidbClusterOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterPreflight) DeepCopyInto(out *TidbClusterPreflight) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterCondition.
-func (in *TidbClusterCondition) DeepCopy() *TidbClusterCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterPreflight.
+func (in *TidbClusterPreflight) DeepCopy() *TidbClusterPreflight {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterCondition)
+	out := new(TidbClusterPreflight)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterPreflight) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterList) DeepCopyInto(out *TidbClusterList) {
+func (in *TidbClusterPreflightList) DeepCopyInto(out *TidbClusterPreflightList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]TidbCluster, len(*in))
+		*out = make([]TidbClusterPreflight, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -8560,24 +11046,113 @@ func (in *TidbClusterList) DeepCopyInto(out *TidbClusterList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterList.
-func (in *TidbClusterList) DeepCopy() *TidbClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterPreflightList.
+func (in *TidbClusterPreflightList) DeepCopy() *TidbClusterPreflightList {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterList)
+	out := new(TidbClusterPreflightList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TidbClusterList) DeepCopyObject() runtime.Object {
+func (in *TidbClusterPreflightList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterPreflightSpec) DeepCopyInto(out *TidbClusterPreflightSpec) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(PreflightStorageCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxClockError != nil {
+		in, out := &in.MaxClockError, &out.MaxClockError
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxNetworkLatency != nil {
+		in, out := &in.MaxNetworkLatency, &out.MaxNetworkLatency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterPreflightSpec.
+func (in *TidbClusterPreflightSpec) DeepCopy() *TidbClusterPreflightSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterPreflightSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterPreflightStatus) DeepCopyInto(out *TidbClusterPreflightStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = make([]PreflightCheckResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterPreflightStatus.
+func (in *TidbClusterPreflightStatus) DeepCopy() *TidbClusterPreflightStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterPreflightStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TidbClusterRef) DeepCopyInto(out *TidbClusterRef) {
 	*out = *in
@@ -8628,6 +11203,11 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = new(PumpSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TiProxy != nil {
+		in, out := &in.TiProxy, &out.TiProxy
+		*out = new(TiProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Helper != nil {
 		in, out := &in.Helper, &out.Helper
 		*out = new(HelperSpec)
@@ -8736,6 +11316,91 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = new(SuspendAction)
 		**out = **in
 	}
+	if in.RolloutBudget != nil {
+		in, out := &in.RolloutBudget, &out.RolloutBudget
+		*out = new(RolloutBudget)
+		**out = **in
+	}
+	if in.DiskWatchdog != nil {
+		in, out := &in.DiskWatchdog, &out.DiskWatchdog
+		*out = new(DiskWatchdog)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(NetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeriveConfigFromResources != nil {
+		in, out := &in.DeriveConfigFromResources, &out.DeriveConfigFromResources
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PodMonitor != nil {
+		in, out := &in.PodMonitor, &out.PodMonitor
+		*out = new(PodMonitorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceReport != nil {
+		in, out := &in.ResourceReport, &out.ResourceReport
+		*out = new(ResourceReportSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = new(RecommendationsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePolicy != nil {
+		in, out := &in.ImagePolicy, &out.ImagePolicy
+		*out = new(ImagePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologyProfile != nil {
+		in, out := &in.TopologyProfile, &out.TopologyProfile
+		*out = new(TopologyProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceQuota != nil {
+		in, out := &in.ResourceQuota, &out.ResourceQuota
+		*out = new(ResourceQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OperatorPolicy != nil {
+		in, out := &in.OperatorPolicy, &out.OperatorPolicy
+		*out = new(OperatorPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradePolicy != nil {
+		in, out := &in.UpgradePolicy, &out.UpgradePolicy
+		*out = new(UpgradePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SQLProbe != nil {
+		in, out := &in.SQLProbe, &out.SQLProbe
+		*out = new(SQLProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EventThrottling != nil {
+		in, out := &in.EventThrottling, &out.EventThrottling
+		*out = new(EventThrottlingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologyExport != nil {
+		in, out := &in.TopologyExport, &out.TopologyExport
+		*out = new(TopologyExportSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersistentIdentity != nil {
+		in, out := &in.PersistentIdentity, &out.PersistentIdentity
+		*out = new(PersistentIdentitySpec)
+		**out = **in
+	}
 	return
 }
 
@@ -8758,6 +11423,7 @@ func (in *TidbClusterStatus) DeepCopyInto(out *TidbClusterStatus) {
 	in.Pump.DeepCopyInto(&out.Pump)
 	in.TiFlash.DeepCopyInto(&out.TiFlash)
 	in.TiCDC.DeepCopyInto(&out.TiCDC)
+	in.TiProxy.DeepCopyInto(&out.TiProxy)
 	if in.AutoScaler != nil {
 		in, out := &in.AutoScaler, &out.AutoScaler
 		*out = new(TidbClusterAutoScalerRef)
@@ -8770,6 +11436,87 @@ func (in *TidbClusterStatus) DeepCopyInto(out *TidbClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(DryRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncDiff != nil {
+		in, out := &in.SyncDiff, &out.SyncDiff
+		*out = new(SyncDiffStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutBudget != nil {
+		in, out := &in.RolloutBudget, &out.RolloutBudget
+		*out = new(RolloutBudgetStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeferDeletingPVCs != nil {
+		in, out := &in.DeferDeletingPVCs, &out.DeferDeletingPVCs
+		*out = make([]DeferDeletingPVC, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EffectiveSpec != nil {
+		in, out := &in.EffectiveSpec, &out.EffectiveSpec
+		*out = new(TidbClusterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceReport != nil {
+		in, out := &in.ResourceReport, &out.ResourceReport
+		*out = new(ResourceReport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = new(Recommendations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SoftLimitedStores != nil {
+		in, out := &in.SoftLimitedStores, &out.SoftLimitedStores
+		*out = make(map[string]SoftLimitedStore, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodTemplateChanges != nil {
+		in, out := &in.PodTemplateChanges, &out.PodTemplateChanges
+		*out = make([]PodTemplateChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AutoUpgrade != nil {
+		in, out := &in.AutoUpgrade, &out.AutoUpgrade
+		*out = new(AutoUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EventThrottling != nil {
+		in, out := &in.EventThrottling, &out.EventThrottling
+		*out = new(EventThrottlingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologyExport != nil {
+		in, out := &in.TopologyExport, &out.TopologyExport
+		*out = new(TopologyExportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersistentIdentity != nil {
+		in, out := &in.PersistentIdentity, &out.PersistentIdentity
+		*out = new(PersistentIdentityStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicies != nil {
+		in, out := &in.NetworkPolicies, &out.NetworkPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSEndpoints != nil {
+		in, out := &in.DNSEndpoints, &out.DNSEndpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -8893,6 +11640,23 @@ func (in *TidbInitializerSpec) DeepCopyInto(out *TidbInitializerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.GlobalVariables != nil {
+		in, out := &in.GlobalVariables, &out.GlobalVariables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ClusterConfigs != nil {
+		in, out := &in.ClusterConfigs, &out.ClusterConfigs
+		*out = make([]ClusterConfigItem, len(*in))
+		copy(*out, *in)
+	}
+	if in.SettingsSyncInterval != nil {
+		in, out := &in.SettingsSyncInterval, &out.SettingsSyncInterval
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -8910,6 +11674,11 @@ func (in *TidbInitializerSpec) DeepCopy() *TidbInitializerSpec {
 func (in *TidbInitializerStatus) DeepCopyInto(out *TidbInitializerStatus) {
 	*out = *in
 	in.JobStatus.DeepCopyInto(&out.JobStatus)
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(InitializerSettingsStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -9130,6 +11899,11 @@ func (in *TidbMonitorSpec) DeepCopyInto(out *TidbMonitorSpec) {
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ImagePolicy != nil {
+		in, out := &in.ImagePolicy, &out.ImagePolicy
+		*out = new(ImagePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -9309,6 +12083,91 @@ func (in *TikvAutoScalerStatus) DeepCopy() *TikvAutoScalerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyAwareRouting) DeepCopyInto(out *TopologyAwareRouting) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyAwareRouting.
+func (in *TopologyAwareRouting) DeepCopy() *TopologyAwareRouting {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyAwareRouting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyExportSpec) DeepCopyInto(out *TopologyExportSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyExportSpec.
+func (in *TopologyExportSpec) DeepCopy() *TopologyExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyExportStatus) DeepCopyInto(out *TopologyExportStatus) {
+	*out = *in
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyExportStatus.
+func (in *TopologyExportStatus) DeepCopy() *TopologyExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyProfile) DeepCopyInto(out *TopologyProfile) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyProfile.
+func (in *TopologyProfile) DeepCopy() *TopologyProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TopologySpreadConstraint) DeepCopyInto(out *TopologySpreadConstraint) {
 	*out = *in
@@ -9375,6 +12234,33 @@ func (in *UnjoinedMember) DeepCopy() *UnjoinedMember {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePolicy) DeepCopyInto(out *UpgradePolicy) {
+	*out = *in
+	in.ReleaseMetadata.DeepCopyInto(&out.ReleaseMetadata)
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		**out = **in
+	}
+	if in.PostCheckTimeout != nil {
+		in, out := &in.PostCheckTimeout, &out.PostCheckTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePolicy.
+func (in *UpgradePolicy) DeepCopy() *UpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *User) DeepCopyInto(out *User) {
 	*out = *in
@@ -0,0 +1,470 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+)
+
+// ConvertTo converts this TidbCluster to the hub version v1alpha1, the fields saved in
+// the annotation ConversionDataAnnotation are restored and the annotation is removed.
+func (tc *TidbCluster) ConvertTo(hub *v1alpha1.TidbCluster) error {
+	spec := v1alpha1.TidbClusterSpec{}
+	if data, ok := tc.Annotations[ConversionDataAnnotation]; ok {
+		if err := json.Unmarshal([]byte(data), &spec); err != nil {
+			return fmt.Errorf("failed to decode the annotation %s of TidbCluster %s/%s, error: %v", ConversionDataAnnotation, tc.Namespace, tc.Name, err)
+		}
+	}
+	if err := tc.Spec.convertTo(&spec); err != nil {
+		return fmt.Errorf("failed to convert TidbCluster %s/%s, error: %v", tc.Namespace, tc.Name, err)
+	}
+
+	hub.TypeMeta = tc.TypeMeta
+	hub.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	hub.ObjectMeta = *tc.ObjectMeta.DeepCopy()
+	delete(hub.Annotations, ConversionDataAnnotation)
+	if len(hub.Annotations) == 0 {
+		hub.Annotations = nil
+	}
+	hub.Spec = spec
+	hub.Status = *tc.Status.DeepCopy()
+	return nil
+}
+
+// ConvertFrom converts from the hub version v1alpha1 to this version. The deprecated images
+// are translated to image and version, the fields which can't be represented by this version
+// are saved in the annotation ConversionDataAnnotation.
+func (tc *TidbCluster) ConvertFrom(hub *v1alpha1.TidbCluster) error {
+	rest := hub.Spec.DeepCopy()
+	convertDeprecatedImages(rest)
+	expected := rest.DeepCopy()
+
+	tc.TypeMeta = hub.TypeMeta
+	tc.APIVersion = SchemeGroupVersion.String()
+	tc.ObjectMeta = *hub.ObjectMeta.DeepCopy()
+	delete(tc.Annotations, ConversionDataAnnotation)
+	tc.Spec = convertFrom(rest)
+	tc.Status = *hub.Status.DeepCopy()
+
+	restored := v1alpha1.TidbClusterSpec{}
+	if err := tc.Spec.convertTo(&restored); err == nil && apiequality.Semantic.DeepEqual(&restored, expected) {
+		if len(tc.Annotations) == 0 {
+			tc.Annotations = nil
+		}
+		return nil
+	}
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return fmt.Errorf("failed to encode the conversion data of TidbCluster %s/%s, error: %v", hub.Namespace, hub.Name, err)
+	}
+	if tc.Annotations == nil {
+		tc.Annotations = map[string]string{}
+	}
+	tc.Annotations[ConversionDataAnnotation] = string(data)
+	return nil
+}
+
+// hubComponent points to the fields of a v1alpha1 component which are modeled by ComponentSpec
+type hubComponent struct {
+	spec             *v1alpha1.ComponentSpec
+	resources        *corev1.ResourceRequirements
+	serviceAccount   *string
+	replicas         *int32
+	baseImage        *string
+	storageRequests  *corev1.ResourceList
+	storageClassName **string
+	storageVolumes   *[]v1alpha1.StorageVolume
+}
+
+// convertFrom moves the modeled fields of rest to the returned spec, what's left in rest
+// can't be represented by v1beta1
+func convertFrom(rest *v1alpha1.TidbClusterSpec) TidbClusterSpec {
+	spec := TidbClusterSpec{
+		Version:              rest.Version,
+		Paused:               rest.Paused,
+		Timezone:             rest.Timezone,
+		ClusterDomain:        rest.ClusterDomain,
+		TLSCluster:           rest.TLSCluster,
+		ConfigUpdateStrategy: rest.ConfigUpdateStrategy,
+		PVReclaimPolicy:      rest.PVReclaimPolicy,
+		EnablePVReclaim:      rest.EnablePVReclaim,
+	}
+	rest.Version = ""
+	rest.Paused = false
+	rest.Timezone = ""
+	rest.ClusterDomain = ""
+	rest.TLSCluster = nil
+	rest.ConfigUpdateStrategy = ""
+	rest.PVReclaimPolicy = nil
+	rest.EnablePVReclaim = nil
+
+	if pd := rest.PD; pd != nil {
+		spec.PD = &PDSpec{
+			ComponentSpec: componentFrom(hubComponent{&pd.ComponentSpec, &pd.ResourceRequirements, &pd.ServiceAccount, &pd.Replicas,
+				&pd.BaseImage, &pd.ResourceRequirements.Requests, &pd.StorageClassName, &pd.StorageVolumes}),
+			Service: pd.Service,
+		}
+		pd.Service = nil
+		if pd.Config != nil && pd.Config.GenericConfig != nil {
+			spec.PD.Config = pd.Config.GenericConfig
+			pd.Config = nil
+		}
+	}
+	if tikv := rest.TiKV; tikv != nil {
+		spec.TiKV = &TiKVSpec{
+			ComponentSpec: componentFrom(hubComponent{&tikv.ComponentSpec, &tikv.ResourceRequirements, &tikv.ServiceAccount, &tikv.Replicas,
+				&tikv.BaseImage, &tikv.ResourceRequirements.Requests, &tikv.StorageClassName, &tikv.StorageVolumes}),
+		}
+		if tikv.Config != nil && tikv.Config.GenericConfig != nil {
+			spec.TiKV.Config = tikv.Config.GenericConfig
+			tikv.Config = nil
+		}
+	}
+	if tidb := rest.TiDB; tidb != nil {
+		spec.TiDB = &TiDBSpec{
+			ComponentSpec: componentFrom(hubComponent{&tidb.ComponentSpec, &tidb.ResourceRequirements, &tidb.ServiceAccount, &tidb.Replicas,
+				&tidb.BaseImage, &tidb.ResourceRequirements.Requests, &tidb.StorageClassName, &tidb.StorageVolumes}),
+			Service: tidb.Service,
+		}
+		tidb.Service = nil
+		if tidb.Config != nil && tidb.Config.GenericConfig != nil {
+			spec.TiDB.Config = tidb.Config.GenericConfig
+			tidb.Config = nil
+		}
+	}
+	if tiflash := rest.TiFlash; tiflash != nil {
+		// only the first storage claim is the data volume
+		var storageRequests *corev1.ResourceList
+		var storageClassName **string
+		if len(tiflash.StorageClaims) > 0 {
+			storageRequests = &tiflash.StorageClaims[0].Resources.Requests
+			storageClassName = &tiflash.StorageClaims[0].StorageClassName
+		}
+		spec.TiFlash = &TiFlashSpec{
+			ComponentSpec: componentFrom(hubComponent{&tiflash.ComponentSpec, &tiflash.ResourceRequirements, &tiflash.ServiceAccount, &tiflash.Replicas,
+				&tiflash.BaseImage, storageRequests, storageClassName, nil}),
+		}
+		if c := tiflash.Config; c != nil && c.Common != nil && c.Common.GenericConfig != nil {
+			spec.TiFlash.Config = c.Common.GenericConfig
+			c.Common = nil
+		}
+	}
+	if ticdc := rest.TiCDC; ticdc != nil {
+		spec.TiCDC = &TiCDCSpec{
+			ComponentSpec: componentFrom(hubComponent{&ticdc.ComponentSpec, &ticdc.ResourceRequirements, &ticdc.ServiceAccount, &ticdc.Replicas,
+				&ticdc.BaseImage, &ticdc.ResourceRequirements.Requests, &ticdc.StorageClassName, &ticdc.StorageVolumes}),
+		}
+		if ticdc.Config != nil && ticdc.Config.GenericConfig != nil {
+			spec.TiCDC.Config = ticdc.Config.GenericConfig
+			ticdc.Config = nil
+		}
+	}
+	if pump := rest.Pump; pump != nil {
+		spec.Pump = &PumpSpec{
+			ComponentSpec: componentFrom(hubComponent{&pump.ComponentSpec, &pump.ResourceRequirements, &pump.ServiceAccount, &pump.Replicas,
+				&pump.BaseImage, &pump.ResourceRequirements.Requests, &pump.StorageClassName, nil}),
+		}
+		spec.Pump.Config = pump.Config
+		pump.Config = nil
+	}
+	return spec
+}
+
+func componentFrom(c hubComponent) ComponentSpec {
+	spec := ComponentSpec{
+		Replicas: *c.replicas,
+		Image:    *c.baseImage,
+		Version:  c.spec.Version,
+	}
+	*c.replicas = 0
+	*c.baseImage = ""
+	c.spec.Version = nil
+
+	if c.storageRequests != nil {
+		if size, ok := (*c.storageRequests)[corev1.ResourceStorage]; ok {
+			spec.Storage.Size = &size
+			delete(*c.storageRequests, corev1.ResourceStorage)
+			if len(*c.storageRequests) == 0 {
+				*c.storageRequests = nil
+			}
+		}
+	}
+	if c.storageClassName != nil {
+		spec.Storage.StorageClassName = *c.storageClassName
+		*c.storageClassName = nil
+	}
+	if c.storageVolumes != nil {
+		spec.Storage.Volumes = *c.storageVolumes
+		*c.storageVolumes = nil
+	}
+
+	spec.Pod = PodOverlay{
+		Resources:                     *c.resources,
+		ServiceAccount:                *c.serviceAccount,
+		ImagePullPolicy:               c.spec.ImagePullPolicy,
+		ImagePullSecrets:              c.spec.ImagePullSecrets,
+		HostNetwork:                   c.spec.HostNetwork,
+		Affinity:                      c.spec.Affinity,
+		PriorityClassName:             c.spec.PriorityClassName,
+		SchedulerName:                 c.spec.SchedulerName,
+		NodeSelector:                  c.spec.NodeSelector,
+		Annotations:                   c.spec.Annotations,
+		Labels:                        c.spec.Labels,
+		Tolerations:                   c.spec.Tolerations,
+		PodSecurityContext:            c.spec.PodSecurityContext,
+		Env:                           c.spec.Env,
+		EnvFrom:                       c.spec.EnvFrom,
+		InitContainers:                c.spec.InitContainers,
+		AdditionalContainers:          c.spec.AdditionalContainers,
+		AdditionalVolumes:             c.spec.AdditionalVolumes,
+		AdditionalVolumeMounts:        c.spec.AdditionalVolumeMounts,
+		TerminationGracePeriodSeconds: c.spec.TerminationGracePeriodSeconds,
+		TopologySpreadConstraints:     c.spec.TopologySpreadConstraints,
+	}
+	*c.resources = corev1.ResourceRequirements{}
+	*c.serviceAccount = ""
+	c.spec.ImagePullPolicy = nil
+	c.spec.ImagePullSecrets = nil
+	c.spec.HostNetwork = nil
+	c.spec.Affinity = nil
+	c.spec.PriorityClassName = nil
+	c.spec.SchedulerName = nil
+	c.spec.NodeSelector = nil
+	c.spec.Annotations = nil
+	c.spec.Labels = nil
+	c.spec.Tolerations = nil
+	c.spec.PodSecurityContext = nil
+	c.spec.Env = nil
+	c.spec.EnvFrom = nil
+	c.spec.InitContainers = nil
+	c.spec.AdditionalContainers = nil
+	c.spec.AdditionalVolumes = nil
+	c.spec.AdditionalVolumeMounts = nil
+	c.spec.TerminationGracePeriodSeconds = nil
+	c.spec.TopologySpreadConstraints = nil
+	return spec
+}
+
+// convertTo sets the modeled fields of hub, the other fields of hub are kept
+func (s *TidbClusterSpec) convertTo(hub *v1alpha1.TidbClusterSpec) error {
+	in := s.DeepCopy()
+	hub.Version = in.Version
+	hub.Paused = in.Paused
+	hub.Timezone = in.Timezone
+	hub.ClusterDomain = in.ClusterDomain
+	hub.TLSCluster = in.TLSCluster
+	hub.ConfigUpdateStrategy = in.ConfigUpdateStrategy
+	hub.PVReclaimPolicy = in.PVReclaimPolicy
+	hub.EnablePVReclaim = in.EnablePVReclaim
+
+	if in.PD == nil {
+		hub.PD = nil
+	} else {
+		if hub.PD == nil {
+			hub.PD = &v1alpha1.PDSpec{}
+		}
+		pd := hub.PD
+		if err := componentTo("PD", &in.PD.ComponentSpec, hubComponent{&pd.ComponentSpec, &pd.ResourceRequirements, &pd.ServiceAccount, &pd.Replicas,
+			&pd.BaseImage, &pd.ResourceRequirements.Requests, &pd.StorageClassName, &pd.StorageVolumes}); err != nil {
+			return err
+		}
+		pd.Service = in.PD.Service
+		if in.PD.Config != nil {
+			pd.Config = &v1alpha1.PDConfigWraper{GenericConfig: in.PD.Config}
+		}
+	}
+	if in.TiKV == nil {
+		hub.TiKV = nil
+	} else {
+		if hub.TiKV == nil {
+			hub.TiKV = &v1alpha1.TiKVSpec{}
+		}
+		tikv := hub.TiKV
+		if err := componentTo("TiKV", &in.TiKV.ComponentSpec, hubComponent{&tikv.ComponentSpec, &tikv.ResourceRequirements, &tikv.ServiceAccount, &tikv.Replicas,
+			&tikv.BaseImage, &tikv.ResourceRequirements.Requests, &tikv.StorageClassName, &tikv.StorageVolumes}); err != nil {
+			return err
+		}
+		if in.TiKV.Config != nil {
+			tikv.Config = &v1alpha1.TiKVConfigWraper{GenericConfig: in.TiKV.Config}
+		}
+	}
+	if in.TiDB == nil {
+		hub.TiDB = nil
+	} else {
+		if hub.TiDB == nil {
+			hub.TiDB = &v1alpha1.TiDBSpec{}
+		}
+		tidb := hub.TiDB
+		if err := componentTo("TiDB", &in.TiDB.ComponentSpec, hubComponent{&tidb.ComponentSpec, &tidb.ResourceRequirements, &tidb.ServiceAccount, &tidb.Replicas,
+			&tidb.BaseImage, &tidb.ResourceRequirements.Requests, &tidb.StorageClassName, &tidb.StorageVolumes}); err != nil {
+			return err
+		}
+		tidb.Service = in.TiDB.Service
+		if in.TiDB.Config != nil {
+			tidb.Config = &v1alpha1.TiDBConfigWraper{GenericConfig: in.TiDB.Config}
+		}
+	}
+	if in.TiFlash == nil {
+		hub.TiFlash = nil
+	} else {
+		if hub.TiFlash == nil {
+			hub.TiFlash = &v1alpha1.TiFlashSpec{}
+		}
+		tiflash := hub.TiFlash
+		storage := in.TiFlash.Storage
+		if len(tiflash.StorageClaims) == 0 && (storage.Size != nil || storage.StorageClassName != nil) {
+			tiflash.StorageClaims = []v1alpha1.StorageClaim{{}}
+		}
+		var storageRequests *corev1.ResourceList
+		var storageClassName **string
+		if len(tiflash.StorageClaims) > 0 {
+			storageRequests = &tiflash.StorageClaims[0].Resources.Requests
+			storageClassName = &tiflash.StorageClaims[0].StorageClassName
+		}
+		if err := componentTo("TiFlash", &in.TiFlash.ComponentSpec, hubComponent{&tiflash.ComponentSpec, &tiflash.ResourceRequirements, &tiflash.ServiceAccount, &tiflash.Replicas,
+			&tiflash.BaseImage, storageRequests, storageClassName, nil}); err != nil {
+			return err
+		}
+		if in.TiFlash.Config != nil {
+			if tiflash.Config == nil {
+				tiflash.Config = &v1alpha1.TiFlashConfigWraper{}
+			}
+			tiflash.Config.Common = &v1alpha1.TiFlashCommonConfigWraper{GenericConfig: in.TiFlash.Config}
+		}
+	}
+	if in.TiCDC == nil {
+		hub.TiCDC = nil
+	} else {
+		if hub.TiCDC == nil {
+			hub.TiCDC = &v1alpha1.TiCDCSpec{}
+		}
+		ticdc := hub.TiCDC
+		if err := componentTo("TiCDC", &in.TiCDC.ComponentSpec, hubComponent{&ticdc.ComponentSpec, &ticdc.ResourceRequirements, &ticdc.ServiceAccount, &ticdc.Replicas,
+			&ticdc.BaseImage, &ticdc.ResourceRequirements.Requests, &ticdc.StorageClassName, &ticdc.StorageVolumes}); err != nil {
+			return err
+		}
+		if in.TiCDC.Config != nil {
+			ticdc.Config = &v1alpha1.CDCConfigWraper{GenericConfig: in.TiCDC.Config}
+		}
+	}
+	if in.Pump == nil {
+		hub.Pump = nil
+	} else {
+		if hub.Pump == nil {
+			hub.Pump = &v1alpha1.PumpSpec{}
+		}
+		pump := hub.Pump
+		if err := componentTo("Pump", &in.Pump.ComponentSpec, hubComponent{&pump.ComponentSpec, &pump.ResourceRequirements, &pump.ServiceAccount, &pump.Replicas,
+			&pump.BaseImage, &pump.ResourceRequirements.Requests, &pump.StorageClassName, nil}); err != nil {
+			return err
+		}
+		if in.Pump.Config != nil {
+			pump.Config = in.Pump.Config
+		}
+	}
+	return nil
+}
+
+func componentTo(component string, in *ComponentSpec, c hubComponent) error {
+	if c.storageVolumes == nil && len(in.Storage.Volumes) > 0 {
+		return fmt.Errorf("storage.volumes is not supported by %s", component)
+	}
+
+	*c.replicas = in.Replicas
+	*c.baseImage = in.Image
+	c.spec.Version = in.Version
+
+	pod := in.Pod
+	*c.resources = pod.Resources
+	*c.serviceAccount = pod.ServiceAccount
+	c.spec.ImagePullPolicy = pod.ImagePullPolicy
+	c.spec.ImagePullSecrets = pod.ImagePullSecrets
+	c.spec.HostNetwork = pod.HostNetwork
+	c.spec.Affinity = pod.Affinity
+	c.spec.PriorityClassName = pod.PriorityClassName
+	c.spec.SchedulerName = pod.SchedulerName
+	c.spec.NodeSelector = pod.NodeSelector
+	c.spec.Annotations = pod.Annotations
+	c.spec.Labels = pod.Labels
+	c.spec.Tolerations = pod.Tolerations
+	c.spec.PodSecurityContext = pod.PodSecurityContext
+	c.spec.Env = pod.Env
+	c.spec.EnvFrom = pod.EnvFrom
+	c.spec.InitContainers = pod.InitContainers
+	c.spec.AdditionalContainers = pod.AdditionalContainers
+	c.spec.AdditionalVolumes = pod.AdditionalVolumes
+	c.spec.AdditionalVolumeMounts = pod.AdditionalVolumeMounts
+	c.spec.TerminationGracePeriodSeconds = pod.TerminationGracePeriodSeconds
+	c.spec.TopologySpreadConstraints = pod.TopologySpreadConstraints
+
+	// set the storage after the resources as they may be the same requests
+	if in.Storage.Size != nil && c.storageRequests != nil {
+		if *c.storageRequests == nil {
+			*c.storageRequests = corev1.ResourceList{}
+		}
+		(*c.storageRequests)[corev1.ResourceStorage] = *in.Storage.Size
+	}
+	if c.storageClassName != nil {
+		*c.storageClassName = in.Storage.StorageClassName
+	}
+	if c.storageVolumes != nil {
+		*c.storageVolumes = in.Storage.Volumes
+	}
+	return nil
+}
+
+// convertDeprecatedImages translates the deprecated image of the components to the base
+// image and the version, the images with digests are kept as they can't be translated
+func convertDeprecatedImages(spec *v1alpha1.TidbClusterSpec) {
+	if spec.PD != nil {
+		convertDeprecatedImage(&spec.PD.ComponentSpec, &spec.PD.BaseImage)
+	}
+	if spec.TiKV != nil {
+		convertDeprecatedImage(&spec.TiKV.ComponentSpec, &spec.TiKV.BaseImage)
+	}
+	if spec.TiDB != nil {
+		convertDeprecatedImage(&spec.TiDB.ComponentSpec, &spec.TiDB.BaseImage)
+	}
+	if spec.TiFlash != nil {
+		convertDeprecatedImage(&spec.TiFlash.ComponentSpec, &spec.TiFlash.BaseImage)
+	}
+	if spec.TiCDC != nil {
+		convertDeprecatedImage(&spec.TiCDC.ComponentSpec, &spec.TiCDC.BaseImage)
+	}
+	if spec.Pump != nil {
+		convertDeprecatedImage(&spec.Pump.ComponentSpec, &spec.Pump.BaseImage)
+	}
+}
+
+func convertDeprecatedImage(spec *v1alpha1.ComponentSpec, baseImage *string) {
+	// the base image takes higher priority, the deprecated image is ignored
+	if *baseImage != "" || spec.Image == "" || strings.Contains(spec.Image, "@") {
+		return
+	}
+	repo, tag := spec.Image, ""
+	// the colon before the last slash belongs to the port of the registry
+	if i := strings.LastIndex(spec.Image, ":"); i > strings.LastIndex(spec.Image, "/") {
+		repo, tag = spec.Image[:i], spec.Image[i+1:]
+	}
+	*baseImage = repo
+	spec.Version = &tag
+	spec.Image = ""
+}
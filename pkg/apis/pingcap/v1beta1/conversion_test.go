@@ -0,0 +1,225 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+)
+
+func newHubTidbCluster() *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TidbCluster",
+			APIVersion: "pingcap.com/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "basic",
+			Namespace:   "default",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			Version:    "v5.4.0",
+			Timezone:   "UTC",
+			TLSCluster: &v1alpha1.TLSCluster{Enabled: true},
+			PD: &v1alpha1.PDSpec{
+				Replicas:  3,
+				BaseImage: "pingcap/pd",
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:     resource.MustParse("1"),
+						corev1.ResourceStorage: resource.MustParse("10Gi"),
+					},
+				},
+				StorageClassName: pointer.StringPtr("local-storage"),
+				Config:           v1alpha1.NewPDConfig(),
+				Service:          &v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort},
+			},
+			TiKV: &v1alpha1.TiKVSpec{
+				Replicas:  3,
+				BaseImage: "pingcap/tikv",
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("100Gi"),
+					},
+				},
+				StorageVolumes: []v1alpha1.StorageVolume{{Name: "raft", StorageSize: "1Gi", MountPath: "/raft"}},
+			},
+			TiFlash: &v1alpha1.TiFlashSpec{
+				Replicas:  1,
+				BaseImage: "pingcap/tiflash",
+				StorageClaims: []v1alpha1.StorageClaim{{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: resource.MustParse("100Gi"),
+						},
+					},
+				}},
+			},
+		},
+	}
+}
+
+func TestConvertFrom(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name     string
+		update   func(*v1alpha1.TidbCluster)
+		expectFn func(*GomegaWithT, *TidbCluster)
+	}
+	tests := []testcase{
+		{
+			name:   "all the fields are modeled",
+			update: func(tc *v1alpha1.TidbCluster) {},
+			expectFn: func(g *GomegaWithT, tc *TidbCluster) {
+				g.Expect(tc.APIVersion).To(Equal("pingcap.com/v1beta1"))
+				g.Expect(tc.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+				g.Expect(tc.Spec.PD.Image).To(Equal("pingcap/pd"))
+				g.Expect(tc.Spec.PD.Storage.Size.String()).To(Equal("10Gi"))
+				g.Expect(*tc.Spec.PD.Storage.StorageClassName).To(Equal("local-storage"))
+				g.Expect(tc.Spec.PD.Pod.Resources.Requests).To(HaveLen(1))
+				g.Expect(tc.Spec.PD.Service.Type).To(Equal(corev1.ServiceTypeNodePort))
+				g.Expect(tc.Spec.TiKV.Storage.Volumes).To(HaveLen(1))
+				g.Expect(tc.Spec.TiFlash.Storage.Size.String()).To(Equal("100Gi"))
+			},
+		},
+		{
+			name: "the deprecated image is translated",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.BaseImage = ""
+				tc.Spec.TiKV.Image = "localhost:5000/pingcap/tikv:v5.3.0"
+				tc.Spec.PD.BaseImage = ""
+				tc.Spec.PD.Image = "pingcap/pd"
+			},
+			expectFn: func(g *GomegaWithT, tc *TidbCluster) {
+				g.Expect(tc.Annotations).NotTo(HaveKey(ConversionDataAnnotation))
+				g.Expect(tc.Spec.TiKV.Image).To(Equal("localhost:5000/pingcap/tikv"))
+				g.Expect(*tc.Spec.TiKV.Version).To(Equal("v5.3.0"))
+				g.Expect(tc.Spec.PD.Image).To(Equal("pingcap/pd"))
+				g.Expect(*tc.Spec.PD.Version).To(Equal(""))
+			},
+		},
+		{
+			name: "the image with digest is kept in the conversion data",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.BaseImage = ""
+				tc.Spec.TiKV.Image = "pingcap/tikv@sha256:0123456789abcdef"
+			},
+			expectFn: func(g *GomegaWithT, tc *TidbCluster) {
+				g.Expect(tc.Annotations).To(HaveKey(ConversionDataAnnotation))
+				g.Expect(tc.Spec.TiKV.Image).To(Equal(""))
+			},
+		},
+		{
+			name: "the fields not modeled are kept in the conversion data",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.StoreLabels = []string{"zone"}
+				tc.Spec.TiFlash.StorageClaims = append(tc.Spec.TiFlash.StorageClaims, v1alpha1.StorageClaim{})
+			},
+			expectFn: func(g *GomegaWithT, tc *TidbCluster) {
+				g.Expect(tc.Annotations).To(HaveKey(ConversionDataAnnotation))
+				g.Expect(tc.Annotations[ConversionDataAnnotation]).To(ContainSubstring("storeLabels"))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.name)
+
+		hub := newHubTidbCluster()
+		test.update(hub)
+		tc := &TidbCluster{}
+		g.Expect(tc.ConvertFrom(hub)).To(Succeed())
+		test.expectFn(g, tc)
+
+		// convert back
+		restored := &v1alpha1.TidbCluster{}
+		g.Expect(tc.ConvertTo(restored)).To(Succeed())
+		g.Expect(restored.Annotations).To(Equal(hub.Annotations))
+		g.Expect(restored.PDImage()).To(Equal(hub.PDImage()))
+		g.Expect(restored.TiKVImage()).To(Equal(hub.TiKVImage()))
+		restored.Spec.PD.Image, restored.Spec.PD.BaseImage, restored.Spec.PD.Version = hub.Spec.PD.Image, hub.Spec.PD.BaseImage, hub.Spec.PD.Version
+		restored.Spec.TiKV.Image, restored.Spec.TiKV.BaseImage, restored.Spec.TiKV.Version = hub.Spec.TiKV.Image, hub.Spec.TiKV.BaseImage, hub.Spec.TiKV.Version
+		g.Expect(apiequality.Semantic.DeepEqual(restored, hub)).To(BeTrue())
+	}
+}
+
+func TestConvertToUnsupported(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &TidbCluster{
+		Spec: TidbClusterSpec{
+			Pump: &PumpSpec{
+				ComponentSpec: ComponentSpec{
+					Storage: StorageSpec{Volumes: []v1alpha1.StorageVolume{{Name: "log", StorageSize: "1Gi"}}},
+				},
+			},
+		},
+	}
+	err := tc.ConvertTo(&v1alpha1.TidbCluster{})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("not supported by Pump"))
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	f := fuzz.New().NilChance(0.3).Funcs(
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1000), resource.DecimalSI)
+		},
+		func(l *corev1.ResourceList, c fuzz.Continue) {
+			// the storage request is specified by storage.size
+			*l = corev1.ResourceList{corev1.ResourceCPU: *resource.NewQuantity(c.Int63n(1000), resource.DecimalSI)}
+		},
+		func(cfg *config.GenericConfig, c fuzz.Continue) {
+			*cfg = *config.New(map[string]interface{}{"log": map[string]interface{}{"level": c.RandString()}})
+		},
+		func(m *metav1.ObjectMeta, c fuzz.Continue) {
+			m.Name = c.RandString()
+			m.Namespace = c.RandString()
+			c.Fuzz(&m.Labels)
+		},
+		func(s *v1alpha1.TidbClusterStatus, c fuzz.Continue) {},
+		func(s *StorageSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(s)
+			// the additional volumes are set by the tests of the components supporting them
+			s.Volumes = nil
+		},
+	)
+
+	for i := 0; i < 100; i++ {
+		tc := &TidbCluster{}
+		f.Fuzz(tc)
+
+		hub := &v1alpha1.TidbCluster{}
+		g.Expect(tc.ConvertTo(hub)).To(Succeed())
+		restored := &TidbCluster{}
+		g.Expect(restored.ConvertFrom(hub)).To(Succeed())
+		restored.TypeMeta = tc.TypeMeta
+		g.Expect(restored.Annotations).NotTo(HaveKey(ConversionDataAnnotation))
+		g.Expect(apiequality.Semantic.DeepEqual(restored, tc)).To(BeTrue(), "round trip of %+v", tc.Spec)
+	}
+}
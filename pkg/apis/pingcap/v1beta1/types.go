@@ -0,0 +1,325 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+)
+
+const (
+	// ConversionDataAnnotation keeps the fields of the hub version v1alpha1 which can't be
+	// represented by v1beta1, they are restored when the object is converted back
+	ConversionDataAnnotation = "pingcap.com/conversion-data"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TidbCluster is the control script's spec, every component of it is specified in the same shape.
+// It's only served when the conversion webhook is enabled, the objects are stored in v1alpha1.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:unservedversion
+// +kubebuilder:resource:shortName="tc"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="PD",type=string,JSONPath=`.status.pd.image`,description="The image for PD cluster"
+// +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.pd.storage.size`,description="The storage size specified for PD node"
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.pd.statefulSet.readyReplicas`,description="The desired replicas number of PD cluster"
+// +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.pd.replicas`,description="The desired replicas number of PD cluster"
+// +kubebuilder:printcolumn:name="TiKV",type=string,JSONPath=`.status.tikv.image`,description="The image for TiKV cluster"
+// +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.tikv.storage.size`,description="The storage size specified for TiKV node"
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.tikv.statefulSet.readyReplicas`,description="The ready replicas number of TiKV cluster"
+// +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.tikv.replicas`,description="The desired replicas number of TiKV cluster"
+// +kubebuilder:printcolumn:name="TiDB",type=string,JSONPath=`.status.tidb.image`,description="The image for TiDB cluster"
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.tidb.statefulSet.readyReplicas`,description="The ready replicas number of TiDB cluster"
+// +kubebuilder:printcolumn:name="Desire",type=integer,JSONPath=`.spec.tidb.replicas`,description="The desired replicas number of TiDB cluster"
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type TidbCluster struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the behavior of a tidb cluster
+	Spec TidbClusterSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the tidb cluster, it's the same as v1alpha1
+	Status v1alpha1.TidbClusterStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TidbClusterList is TidbCluster list
+// +k8s:openapi-gen=true
+type TidbClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TidbCluster `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterSpec describes the attributes that a user creates on a tidb cluster
+type TidbClusterSpec struct {
+	// TiDB cluster version
+	Version string `json:"version"`
+
+	// Indicates that the tidb cluster is paused and will not be processed by
+	// the controller.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Time zone of TiDB cluster Pods
+	// Optional: Defaults to UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// ClusterDomain is the Kubernetes Cluster Domain of TiDB cluster
+	// Optional: Defaults to ""
+	// +optional
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	// Whether enable the TLS connection between TiDB server components
+	// Optional: Defaults to nil
+	// +optional
+	TLSCluster *v1alpha1.TLSCluster `json:"tlsCluster,omitempty"`
+
+	// ConfigUpdateStrategy determines how the configuration change is applied to the cluster.
+	// +optional
+	ConfigUpdateStrategy v1alpha1.ConfigUpdateStrategy `json:"configUpdateStrategy,omitempty"`
+
+	// Persistent volume reclaim policy applied to the PVs that consumed by TiDB cluster
+	// +kubebuilder:default=Retain
+	// +optional
+	PVReclaimPolicy *corev1.PersistentVolumeReclaimPolicy `json:"pvReclaimPolicy,omitempty"`
+
+	// Whether enable PVC reclaim for orphan PVC left by statefulset scale-in
+	// Optional: Defaults to false
+	// +optional
+	EnablePVReclaim *bool `json:"enablePVReclaim,omitempty"`
+
+	// PD cluster spec
+	// +optional
+	PD *PDSpec `json:"pd,omitempty"`
+
+	// TiKV cluster spec
+	// +optional
+	TiKV *TiKVSpec `json:"tikv,omitempty"`
+
+	// TiDB cluster spec
+	// +optional
+	TiDB *TiDBSpec `json:"tidb,omitempty"`
+
+	// TiFlash cluster spec
+	// +optional
+	TiFlash *TiFlashSpec `json:"tiflash,omitempty"`
+
+	// TiCDC cluster spec
+	// +optional
+	TiCDC *TiCDCSpec `json:"ticdc,omitempty"`
+
+	// Pump cluster spec
+	// +optional
+	Pump *PumpSpec `json:"pump,omitempty"`
+}
+
+// ComponentSpec is the spec shared by all the components
+// +k8s:openapi-gen=true
+type ComponentSpec struct {
+	// The desired ready replicas
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// Image of the component without tag, the tag is Version or spec.version.
+	// The default image of the component is used if it's empty.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Version of the component. Override the cluster-level version if non-empty
+	// Optional: Defaults to cluster-level setting
+	// +optional
+	Version *string `json:"version,omitempty"`
+
+	// Storage is the data volume and the additional volumes of the component
+	// +optional
+	Storage StorageSpec `json:"storage,omitempty"`
+
+	// Config is the config file of the component
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:XPreserveUnknownFields
+	// +optional
+	Config *config.GenericConfig `json:"config,omitempty"`
+
+	// Pod overrides the template of the pods of the component
+	// +optional
+	Pod PodOverlay `json:"pod,omitempty"`
+}
+
+// StorageSpec is the storage of a component
+// +k8s:openapi-gen=true
+type StorageSpec struct {
+	// Size of the data volume
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// The storageClassName of the data volume
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Volumes configures the additional volumes
+	// +optional
+	Volumes []v1alpha1.StorageVolume `json:"volumes,omitempty"`
+}
+
+// PodOverlay overrides the template of the pods
+// +k8s:openapi-gen=true
+type PodOverlay struct {
+	// Resources of the main container, the storage request is specified by storage.size
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Specify a Service Account for the component
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// ImagePullPolicy of the component. Override the cluster-level imagePullPolicy if present
+	// +optional
+	ImagePullPolicy *corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Whether Hostnetwork of the component is enabled. Override the cluster-level setting if present
+	// +optional
+	HostNetwork *bool `json:"hostNetwork,omitempty"`
+
+	// Affinity of the component. Override the cluster-level setting if present.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PriorityClassName of the component. Override the cluster-level one if present
+	// +optional
+	PriorityClassName *string `json:"priorityClassName,omitempty"`
+
+	// SchedulerName of the component. Override the cluster-level one if present
+	// +optional
+	SchedulerName *string `json:"schedulerName,omitempty"`
+
+	// NodeSelector of the component. Merged into the cluster-level nodeSelector if non-empty
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Annotations for the component. Merge into the cluster-level annotations if non-empty
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels for the component. Merge into the cluster-level labels if non-empty
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Tolerations of the component. Override the cluster-level tolerations if non-empty
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// PodSecurityContext of the component
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// List of environment variables to set in the container
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Extend the use scenarios for env
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// Init containers of the components
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
+	// Additional containers of the component.
+	// +optional
+	AdditionalContainers []corev1.Container `json:"additionalContainers,omitempty"`
+
+	// Additional volumes of component pod.
+	// +optional
+	AdditionalVolumes []corev1.Volume `json:"additionalVolumes,omitempty"`
+
+	// Additional volume mounts of component pod.
+	// +optional
+	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
+
+	// Optional duration in seconds the pod needs to terminate gracefully.
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// TopologySpreadConstraints describes how a group of pods ought to spread across topology
+	// domains. Override the cluster-level setting if present.
+	// +optional
+	TopologySpreadConstraints []v1alpha1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// PDSpec contains details of PD members
+// +k8s:openapi-gen=true
+type PDSpec struct {
+	ComponentSpec `json:",inline"`
+
+	// Service defines a Kubernetes service of PD cluster.
+	// Optional: Defaults to `.spec.services` in favor of backward compatibility
+	// +optional
+	Service *v1alpha1.ServiceSpec `json:"service,omitempty"`
+}
+
+// TiKVSpec contains details of TiKV members
+// +k8s:openapi-gen=true
+type TiKVSpec struct {
+	ComponentSpec `json:",inline"`
+}
+
+// TiDBSpec contains details of TiDB members
+// +k8s:openapi-gen=true
+type TiDBSpec struct {
+	ComponentSpec `json:",inline"`
+
+	// Service defines a Kubernetes service of TiDB cluster.
+	// Optional: No kubernetes service will be created by default.
+	// +optional
+	Service *v1alpha1.TiDBServiceSpec `json:"service,omitempty"`
+}
+
+// TiFlashSpec contains details of TiFlash members, the storage is the first storage claim
+// and storage.volumes is not supported
+// +k8s:openapi-gen=true
+type TiFlashSpec struct {
+	ComponentSpec `json:",inline"`
+}
+
+// TiCDCSpec contains details of TiCDC members
+// +k8s:openapi-gen=true
+type TiCDCSpec struct {
+	ComponentSpec `json:",inline"`
+}
+
+// PumpSpec contains details of Pump members, storage.volumes is not supported
+// +k8s:openapi-gen=true
+type PumpSpec struct {
+	ComponentSpec `json:",inline"`
+}
@@ -36,3 +36,9 @@ type BackupScheduleManager interface {
 	// Sync	implements the logic for syncing BackupSchedule.
 	Sync(backup *v1alpha1.BackupSchedule) error
 }
+
+// RestoreScheduleManager implements the logic for manage restoreSchedule.
+type RestoreScheduleManager interface {
+	// Sync	implements the logic for syncing RestoreSchedule.
+	Sync(rs *v1alpha1.RestoreSchedule) error
+}
@@ -214,6 +214,45 @@ func TestBackupManagerDumpling(t *testing.T) {
 	g.Expect(job.Spec.Template.Spec.Containers[0].Env).NotTo(gomega.ContainElement(env2No))
 }
 
+func TestBackupManagerDumplingLocal(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	bm := NewBackupManager(deps).(*backupManager)
+
+	backup := validDumplingBackup()
+	backup.Spec.StorageProvider = v1alpha1.StorageProvider{
+		Local: &v1alpha1.LocalStorageProvider{
+			Volume: corev1.Volume{
+				Name: "nfs",
+				VolumeSource: corev1.VolumeSource{
+					NFS: &corev1.NFSVolumeSource{Server: "192.168.0.2", Path: "/data"},
+				},
+			},
+			VolumeMount: corev1.VolumeMount{Name: "nfs", MountPath: "/nfs"},
+			Prefix:      "backup",
+		},
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Create(context.TODO(), backup, metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	helper.CreateSecret(backup)
+
+	err = bm.syncBackupJob(backup)
+	g.Expect(err).Should(BeNil())
+	job, err := deps.KubeClientset.BatchV1().Jobs(backup.Namespace).Get(context.TODO(), backup.GetBackupJobName(), metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+
+	// the archived data is copied to the mounted volume
+	podSpec := job.Spec.Template.Spec
+	g.Expect(podSpec.Volumes).To(ContainElement(backup.Spec.Local.Volume))
+	g.Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(backup.Spec.Local.VolumeMount))
+	g.Expect(podSpec.Containers[0].Args).To(ContainElement("--bucket=/nfs"))
+	g.Expect(podSpec.Containers[0].Args).To(ContainElement("--storageType=local"))
+}
+
 func TestBackupManagerBR(t *testing.T) {
 	g := NewGomegaWithT(t)
 	helper := newHelper(t)
@@ -68,7 +68,12 @@ func (bm *backupScheduleManager) Sync(bs *v1alpha1.BackupSchedule) error {
 		return nil
 	}
 
-	backup, err := createBackup(bm.deps.BackupControl, bs, *scheduledTime)
+	lastBackupTS, err := bm.getIncrementalBackupBase(bs)
+	if err != nil {
+		return err
+	}
+
+	backup, err := createBackup(bm.deps.BackupControl, bs, *scheduledTime, lastBackupTS)
 	if err != nil {
 		return err
 	}
@@ -124,6 +129,42 @@ func (bm *backupScheduleManager) canPerformNextBackup(bs *v1alpha1.BackupSchedul
 	return controller.RequeueErrorf("backup schedule %s/%s, the last backup %s is still running", ns, bsName, bs.Status.LastBackup)
 }
 
+// getIncrementalBackupBase returns the commit ts of the last complete backup which the next backup
+// is based on, it returns an empty string if the next backup should be a full backup.
+func (bm *backupScheduleManager) getIncrementalBackupBase(bs *v1alpha1.BackupSchedule) (string, error) {
+	if bs.Spec.BackupTemplate.BR == nil || bs.Spec.MaxIncrementalBackups == nil || *bs.Spec.MaxIncrementalBackups <= 0 {
+		return "", nil
+	}
+
+	backupsList, err := bm.getBackupList(bs)
+	if err != nil {
+		return "", err
+	}
+	sort.Sort(byCreateTimeDesc(backupsList))
+
+	var lastBackupTS string
+	var incrementalBackups int32
+	for _, backup := range backupsList {
+		// the failed backups are skipped, the next backup is based on the last complete one
+		if !v1alpha1.IsBackupComplete(backup) || backup.Status.CommitTs == "" {
+			continue
+		}
+		if lastBackupTS == "" {
+			lastBackupTS = backup.Status.CommitTs
+		}
+		if backup.Spec.BR == nil || backup.Spec.BR.LastBackupTS == "" {
+			// reach the full backup of the last backup chain
+			if incrementalBackups >= *bs.Spec.MaxIncrementalBackups {
+				return "", nil
+			}
+			return lastBackupTS, nil
+		}
+		incrementalBackups++
+	}
+	// the full backup has been deleted or not taken yet
+	return "", nil
+}
+
 // getLastScheduledTime return the newest time need to be scheduled according last backup time.
 // the return time is not before now and return nil if there's no such time.
 func getLastScheduledTime(bs *v1alpha1.BackupSchedule, nowFn nowFn) (*time.Time, error) {
@@ -191,7 +232,7 @@ func getLastScheduledTime(bs *v1alpha1.BackupSchedule, nowFn nowFn) (*time.Time,
 	return &scheduledTime, nil
 }
 
-func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time) *v1alpha1.Backup {
+func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time, lastBackupTS string) *v1alpha1.Backup {
 	ns := bs.GetNamespace()
 	bsName := bs.GetName()
 
@@ -224,6 +265,7 @@ func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time) *v1alpha1.Bac
 		} else if backupSpec.Local != nil {
 			backupSpec.Local.Prefix = path.Join(backupSpec.Local.Prefix, backupPrefix)
 		}
+		backupSpec.BR.LastBackupTS = lastBackupTS
 	}
 
 	if bs.Spec.ImagePullSecrets != nil {
@@ -247,8 +289,8 @@ func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time) *v1alpha1.Bac
 	return backup
 }
 
-func createBackup(bkController controller.BackupControlInterface, bs *v1alpha1.BackupSchedule, timestamp time.Time) (*v1alpha1.Backup, error) {
-	bk := buildBackup(bs, timestamp)
+func createBackup(bkController controller.BackupControlInterface, bs *v1alpha1.BackupSchedule, timestamp time.Time, lastBackupTS string) (*v1alpha1.Backup, error) {
+	bk := buildBackup(bs, timestamp, lastBackupTS)
 	return bkController.CreateBackup(bk)
 }
 
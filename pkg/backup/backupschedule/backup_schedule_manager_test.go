@@ -198,14 +198,14 @@ func TestBuildBackup(t *testing.T) {
 	}
 
 	// test BR == nil
-	get = buildBackup(bs, now)
+	get = buildBackup(bs, now, "")
 	if diff := cmp.Diff(bk, get); diff != "" {
 		t.Errorf("unexpected (-want, +got): %s", diff)
 	}
 	// should keep StorageSize from BackupSchedule
 	bs.Spec.StorageSize = "9527G"
 	bk.Spec.StorageSize = bs.Spec.StorageSize
-	get = buildBackup(bs, now)
+	get = buildBackup(bs, now, "")
 	if diff := cmp.Diff(bk, get); diff != "" {
 		t.Errorf("unexpected (-want, +got): %s", diff)
 	}
@@ -214,10 +214,70 @@ func TestBuildBackup(t *testing.T) {
 	bs.Spec.BackupTemplate.BR = &v1alpha1.BRConfig{}
 	bk.Spec.BR = bs.Spec.BackupTemplate.BR.DeepCopy()
 	bk.Spec.StorageSize = "" // no use for BR
-	get = buildBackup(bs, now)
+	get = buildBackup(bs, now, "")
 	if diff := cmp.Diff(bk, get); diff != "" {
 		t.Errorf("unexpected (-want, +got): %s", diff)
 	}
+
+	// test incremental backup
+	bk.Spec.BR.LastBackupTS = "400000000000000000"
+	get = buildBackup(bs, now, "400000000000000000")
+	if diff := cmp.Diff(bk, get); diff != "" {
+		t.Errorf("unexpected (-want, +got): %s", diff)
+	}
+}
+
+func TestGetIncrementalBackupBase(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.close()
+	m := NewBackupScheduleManager(helper.deps).(*backupScheduleManager)
+
+	bs := &v1alpha1.BackupSchedule{}
+	bs.Namespace = "ns"
+	bs.Name = "bsname"
+	bs.Spec.BackupTemplate.BR = &v1alpha1.BRConfig{}
+
+	now := time.Now()
+	newBackup := func(hoursAgo int, lastBackupTS, commitTs string, complete bool) {
+		bk := &v1alpha1.Backup{}
+		bk.Namespace = bs.Namespace
+		bk.Name = bs.GetBackupCRDName(now.Add(-time.Duration(hoursAgo) * time.Hour))
+		bk.Labels = label.NewBackupSchedule().Instance(bs.Name).BackupSchedule(bs.Name).Labels()
+		bk.CreationTimestamp = metav1.Time{Time: now.Add(-time.Duration(hoursAgo) * time.Hour)}
+		bk.Spec.BR = &v1alpha1.BRConfig{LastBackupTS: lastBackupTS}
+		bk.Status.CommitTs = commitTs
+		if complete {
+			bk.Status.Conditions = []v1alpha1.BackupCondition{{Type: v1alpha1.BackupComplete, Status: v1.ConditionTrue}}
+		} else {
+			bk.Status.Conditions = []v1alpha1.BackupCondition{{Type: v1alpha1.BackupFailed, Status: v1.ConditionTrue}}
+		}
+		helper.createBackup(bk)
+	}
+
+	// all the backups are full backups if MaxIncrementalBackups is not set
+	newBackup(4, "", "100", true)
+	lastBackupTS, err := m.getIncrementalBackupBase(bs)
+	g.Expect(err).Should(BeNil())
+	g.Expect(lastBackupTS).Should(BeEmpty())
+
+	bs.Spec.MaxIncrementalBackups = pointer.Int32Ptr(2)
+	lastBackupTS, err = m.getIncrementalBackupBase(bs)
+	g.Expect(err).Should(BeNil())
+	g.Expect(lastBackupTS).Should(Equal("100"))
+
+	// the failed backups are skipped
+	newBackup(3, "100", "200", true)
+	newBackup(2, "200", "", false)
+	lastBackupTS, err = m.getIncrementalBackupBase(bs)
+	g.Expect(err).Should(BeNil())
+	g.Expect(lastBackupTS).Should(Equal("200"))
+
+	// a full backup is taken after MaxIncrementalBackups incremental backups
+	newBackup(1, "200", "300", true)
+	lastBackupTS, err = m.getIncrementalBackupBase(bs)
+	g.Expect(err).Should(BeNil())
+	g.Expect(lastBackupTS).Should(BeEmpty())
 }
 
 type helper struct {
@@ -476,6 +476,9 @@ func (rm *restoreManager) makeRestoreJob(restore *v1alpha1.Restore) (*batchv1.Jo
 		},
 	}
 
+	// the images are resolved by the image policy of the cluster to restore, e.g. to pull them from the mirrors
+	tc.Spec.ImagePolicy.ResolvePodImages(&podSpec.Spec, "")
+
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        restore.GetRestoreJobName(),
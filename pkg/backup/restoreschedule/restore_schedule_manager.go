@@ -0,0 +1,340 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restoreschedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/robfig/cron"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultMaxRestores is the default number of the restores to keep
+	defaultMaxRestores = 10
+	// physicalShiftBits is the number of the logical bits of a TSO
+	physicalShiftBits = 18
+)
+
+type nowFn func() time.Time
+
+type restoreScheduleManager struct {
+	deps *controller.Dependencies
+	now  nowFn
+}
+
+// NewRestoreScheduleManager returns a manager which refreshes the standby cluster of a RestoreSchedule.
+// A refresh is started at each scheduled time, it restores the backups of the BackupSchedule completed
+// before the scheduled time one by one: the latest full backup if the standby cluster is not restored
+// yet, then the incremental backups based on the commit ts of the standby cluster. Once the backup chain
+// of the standby cluster ends, e.g. a new full backup is taken after MaxIncrementalBackups, the latest
+// full backup is restored and the new chain is followed.
+func NewRestoreScheduleManager(deps *controller.Dependencies) backup.RestoreScheduleManager {
+	return &restoreScheduleManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (rm *restoreScheduleManager) Sync(rs *v1alpha1.RestoreSchedule) error {
+	defer rm.restoreGC(rs)
+
+	if rs.Spec.Pause {
+		return controller.IgnoreErrorf("restoreSchedule %s/%s has been paused", rs.GetNamespace(), rs.GetName())
+	}
+
+	lastRestoreFailed, err := rm.syncLastRestore(rs)
+	if err != nil {
+		return err
+	}
+
+	scheduledTime, err := getLastScheduledTime(rs, rm.now)
+	if err != nil {
+		return err
+	}
+	if scheduledTime != nil {
+		rs.Status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
+	} else if lastRestoreFailed || rs.Status.LastScheduleTime == nil {
+		// the failed restore is retried in the next refresh
+		return nil
+	}
+
+	bk, err := rm.getNextBackup(rs)
+	if err != nil {
+		return err
+	}
+	if bk == nil {
+		return nil
+	}
+
+	restore, err := rm.createRestore(rs, bk)
+	if err != nil {
+		return err
+	}
+	rs.Status.LastRestore = restore.GetName()
+	rs.Status.LastRestoreBackup = bk.GetName()
+	return nil
+}
+
+// syncLastRestore updates the commit ts of the standby cluster if the last restore is complete,
+// it returns true if the last restore is failed.
+func (rm *restoreScheduleManager) syncLastRestore(rs *v1alpha1.RestoreSchedule) (bool, error) {
+	ns := rs.GetNamespace()
+	rsName := rs.GetName()
+
+	if rs.Status.LastRestore == "" {
+		return false, nil
+	}
+	restore, err := rm.deps.RestoreLister.Restores(ns).Get(rs.Status.LastRestore)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("restore schedule %s/%s, get restore %s failed, err: %v", ns, rsName, rs.Status.LastRestore, err)
+	}
+
+	if v1alpha1.IsRestoreComplete(restore) {
+		if restore.Status.CommitTs != rs.Status.StandbyTS {
+			rs.Status.StandbyTS = restore.Status.CommitTs
+			rs.Status.StandbyTime = tsToTime(restore.Status.CommitTs)
+			klog.Infof("restore schedule %s/%s, standby cluster is refreshed to %s by restore %s", ns, rsName, rs.Status.StandbyTS, restore.GetName())
+		}
+		return false, nil
+	}
+	if v1alpha1.IsRestoreFailed(restore) {
+		return true, nil
+	}
+	return false, controller.RequeueErrorf("restore schedule %s/%s, the last restore %s is still running", ns, rsName, rs.Status.LastRestore)
+}
+
+// getNextBackup returns the next backup to restore in the refresh, it returns nil if the standby
+// cluster has caught up with the backups completed before the last scheduled time.
+func (rm *restoreScheduleManager) getNextBackup(rs *v1alpha1.RestoreSchedule) (*v1alpha1.Backup, error) {
+	ns := rs.GetNamespace()
+	rsName := rs.GetName()
+	bsName := rs.Spec.BackupScheduleName
+
+	selector, err := label.NewBackupSchedule().Instance(bsName).BackupSchedule(bsName).Selector()
+	if err != nil {
+		return nil, fmt.Errorf("generate backup schedule %s/%s label selector failed, err: %v", ns, bsName, err)
+	}
+	backups, err := rm.deps.BackupLister.Backups(ns).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("restore schedule %s/%s, list backups of backup schedule %s failed, err: %v", ns, rsName, bsName, err)
+	}
+	sort.Sort(byCreateTimeDesc(backups))
+
+	var newer bool
+	var newerFullBackup *v1alpha1.Backup
+	for _, bk := range backups {
+		if !v1alpha1.IsBackupComplete(bk) || bk.Spec.BR == nil || bk.Status.CommitTs == "" {
+			continue
+		}
+		if bk.CreationTimestamp.After(rs.Status.LastScheduleTime.Time) {
+			continue
+		}
+		if bk.Status.CommitTs == rs.Status.StandbyTS {
+			// caught up with the backups
+			break
+		}
+		if rs.Status.StandbyTS == "" {
+			// restore the latest full backup to the standby cluster at first
+			if bk.Spec.BR.LastBackupTS == "" {
+				return bk, nil
+			}
+			continue
+		}
+		if bk.Spec.BR.LastBackupTS == rs.Status.StandbyTS {
+			return bk, nil
+		}
+		if bk.Spec.BR.LastBackupTS == "" && newerFullBackup == nil {
+			newerFullBackup = bk
+		}
+		newer = true
+	}
+	if newerFullBackup != nil {
+		// the chain of the standby cluster ends, continue with the latest chain
+		klog.Infof("restore schedule %s/%s, no backup is based on the commit ts %s of the standby cluster, restore the full backup %s of a new backup chain",
+			ns, rsName, rs.Status.StandbyTS, newerFullBackup.GetName())
+		rm.deps.Recorder.Eventf(rs, corev1.EventTypeNormal, "NewBackupChain", "restore the full backup %s of a new backup chain", newerFullBackup.GetName())
+		return newerFullBackup, nil
+	}
+	if newer {
+		// the incremental backups can only be restored on the data they are based on
+		msg := fmt.Sprintf("no backup is based on the commit ts %s of the standby cluster, the standby cluster should be rebuilt from a new full backup", rs.Status.StandbyTS)
+		klog.Warningf("restore schedule %s/%s, %s", ns, rsName, msg)
+		rm.deps.Recorder.Event(rs, corev1.EventTypeWarning, "StandbyOutdated", msg)
+	}
+	return nil, nil
+}
+
+func buildRestore(rs *v1alpha1.RestoreSchedule, bk *v1alpha1.Backup) *v1alpha1.Restore {
+	ns := rs.GetNamespace()
+	rsName := rs.GetName()
+
+	restoreSpec := *rs.Spec.RestoreTemplate.DeepCopy()
+	restoreSpec.StorageProvider = *bk.Spec.StorageProvider.DeepCopy()
+
+	rsLabel := util.CombineStringMap(label.NewRestoreSchedule().Instance(rsName).RestoreSchedule(rsName), rs.Labels)
+	return &v1alpha1.Restore{
+		Spec: restoreSpec,
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   ns,
+			Name:        fmt.Sprintf("%s-%s", rsName, bk.GetName()),
+			Labels:      rsLabel,
+			Annotations: rs.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				controller.GetRestoreScheduleOwnerRef(rs),
+			},
+		},
+	}
+}
+
+func (rm *restoreScheduleManager) createRestore(rs *v1alpha1.RestoreSchedule, bk *v1alpha1.Backup) (*v1alpha1.Restore, error) {
+	ns := rs.GetNamespace()
+	rsName := rs.GetName()
+
+	restore := buildRestore(rs, bk)
+	created, err := rm.deps.Clientset.PingcapV1alpha1().Restores(ns).Create(context.TODO(), restore, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			// retry the failed restore of the backup
+			if err := rm.deps.Clientset.PingcapV1alpha1().Restores(ns).Delete(context.TODO(), restore.GetName(), metav1.DeleteOptions{}); err != nil {
+				return nil, fmt.Errorf("restore schedule %s/%s, delete the failed restore %s failed, err: %v", ns, rsName, restore.GetName(), err)
+			}
+			return nil, controller.RequeueErrorf("restore schedule %s/%s, the failed restore %s is deleted for retrying", ns, rsName, restore.GetName())
+		}
+		rm.deps.Recorder.Eventf(rs, corev1.EventTypeWarning, "FailedCreate", "create Restore %s for backup %s failed: %v", restore.GetName(), bk.GetName(), err)
+		return nil, fmt.Errorf("restore schedule %s/%s, create restore for backup %s failed, err: %v", ns, rsName, bk.GetName(), err)
+	}
+	klog.Infof("restore schedule %s/%s, create restore %s for backup %s successfully", ns, rsName, created.GetName(), bk.GetName())
+	rm.deps.Recorder.Eventf(rs, corev1.EventTypeNormal, "SuccessfulCreate", "create Restore %s for backup %s successful", created.GetName(), bk.GetName())
+	return created, nil
+}
+
+// restoreGC deletes the oldest restores if there are more than MaxRestores restores
+func (rm *restoreScheduleManager) restoreGC(rs *v1alpha1.RestoreSchedule) {
+	ns := rs.GetNamespace()
+	rsName := rs.GetName()
+
+	maxRestores := defaultMaxRestores
+	if rs.Spec.MaxRestores != nil && *rs.Spec.MaxRestores > 0 {
+		maxRestores = int(*rs.Spec.MaxRestores)
+	}
+
+	selector, err := label.NewRestoreSchedule().Instance(rsName).RestoreSchedule(rsName).Selector()
+	if err != nil {
+		klog.Errorf("generate restore schedule %s/%s label selector failed, err: %v", ns, rsName, err)
+		return
+	}
+	restores, err := rm.deps.RestoreLister.Restores(ns).List(selector)
+	if err != nil {
+		klog.Errorf("get restore schedule %s/%s restore list failed, selector: %s, err: %v", ns, rsName, selector, err)
+		return
+	}
+	sort.Slice(restores, func(i, j int) bool {
+		return restores[j].CreationTimestamp.Before(&restores[i].CreationTimestamp)
+	})
+
+	for i, restore := range restores {
+		if i < maxRestores || restore.GetName() == rs.Status.LastRestore {
+			continue
+		}
+		if err := rm.deps.Clientset.PingcapV1alpha1().Restores(ns).Delete(context.TODO(), restore.GetName(), metav1.DeleteOptions{}); err != nil {
+			klog.Errorf("restore schedule %s/%s gc restore %s failed, err %v", ns, rsName, restore.GetName(), err)
+			return
+		}
+		klog.Infof("restore schedule %s/%s gc restore %s success", ns, rsName, restore.GetName())
+	}
+}
+
+// getLastScheduledTime returns the newest scheduled time after the last scheduled time,
+// it returns nil if there's no such time.
+func getLastScheduledTime(rs *v1alpha1.RestoreSchedule, nowFn nowFn) (*time.Time, error) {
+	ns := rs.GetNamespace()
+	rsName := rs.GetName()
+
+	sched, err := cron.ParseStandard(rs.Spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("parse restore schedule %s/%s cron format %s failed, err: %v", ns, rsName, rs.Spec.Schedule, err)
+	}
+
+	earliestTime := rs.CreationTimestamp.Time
+	if rs.Status.LastScheduleTime != nil {
+		earliestTime = rs.Status.LastScheduleTime.Time
+	}
+	now := nowFn()
+	if earliestTime.After(now) {
+		klog.Errorf("restore schedule %s/%s timestamp fallback, lastScheduleTime: %s, now: %s",
+			ns, rsName, earliestTime.Format(time.RFC3339), now.Format(time.RFC3339))
+		return nil, nil
+	}
+
+	var scheduledTime *time.Time
+	for t := sched.Next(earliestTime); !t.After(now); t = sched.Next(t) {
+		t := t
+		scheduledTime = &t
+	}
+	return scheduledTime, nil
+}
+
+// tsToTime returns the physical time of the TSO
+func tsToTime(ts string) *metav1.Time {
+	tso, err := strconv.ParseUint(ts, 10, 64)
+	if err != nil {
+		return nil
+	}
+	ms := int64(tso >> physicalShiftBits)
+	return &metav1.Time{Time: time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))}
+}
+
+type byCreateTimeDesc []*v1alpha1.Backup
+
+func (b byCreateTimeDesc) Len() int      { return len(b) }
+func (b byCreateTimeDesc) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byCreateTimeDesc) Less(i, j int) bool {
+	return b[j].ObjectMeta.CreationTimestamp.Before(&b[i].ObjectMeta.CreationTimestamp)
+}
+
+var _ backup.RestoreScheduleManager = &restoreScheduleManager{}
+
+type FakeRestoreScheduleManager struct {
+	err error
+}
+
+func NewFakeRestoreScheduleManager() *FakeRestoreScheduleManager {
+	return &FakeRestoreScheduleManager{}
+}
+
+func (m *FakeRestoreScheduleManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeRestoreScheduleManager) Sync(_ *v1alpha1.RestoreSchedule) error {
+	return m.err
+}
+
+var _ backup.RestoreScheduleManager = &FakeRestoreScheduleManager{}
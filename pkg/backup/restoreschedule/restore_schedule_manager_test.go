@@ -0,0 +1,166 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restoreschedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRestoreScheduleManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewSimpleClientDependencies()
+	stop := make(chan struct{})
+	defer close(stop)
+	deps.InformerFactory.Start(stop)
+	deps.InformerFactory.WaitForCacheSync(stop)
+
+	m := NewRestoreScheduleManager(deps).(*restoreScheduleManager)
+	now := time.Date(2022, 1, 1, 12, 30, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	rs := &v1alpha1.RestoreSchedule{}
+	rs.Namespace = "ns"
+	rs.Name = "standby"
+	rs.CreationTimestamp = metav1.Time{Time: now.Add(-3 * time.Hour)}
+	rs.Spec.Schedule = "0 * * * *"
+	rs.Spec.BackupScheduleName = "primary"
+	rs.Spec.RestoreTemplate.BR = &v1alpha1.BRConfig{Cluster: "standby"}
+
+	createBackup := func(name string, created time.Time, lastBackupTS, commitTs string) {
+		bk := &v1alpha1.Backup{}
+		bk.Namespace = rs.Namespace
+		bk.Name = name
+		bk.Labels = label.NewBackupSchedule().Instance("primary").BackupSchedule("primary").Labels()
+		bk.CreationTimestamp = metav1.Time{Time: created}
+		bk.Spec.BR = &v1alpha1.BRConfig{Cluster: "primary", LastBackupTS: lastBackupTS}
+		bk.Spec.S3 = &v1alpha1.S3StorageProvider{Bucket: "backup", Prefix: name}
+		bk.Status.CommitTs = commitTs
+		bk.Status.Conditions = []v1alpha1.BackupCondition{{Type: v1alpha1.BackupComplete, Status: corev1.ConditionTrue}}
+		_, err := deps.Clientset.PingcapV1alpha1().Backups(bk.Namespace).Create(context.TODO(), bk, metav1.CreateOptions{})
+		g.Expect(err).Should(BeNil())
+		g.Eventually(func() error {
+			_, err := deps.BackupLister.Backups(bk.Namespace).Get(bk.Name)
+			return err
+		}, time.Second*10).Should(BeNil())
+	}
+	finishRestore := func(name string, condition v1alpha1.RestoreConditionType, commitTs string) {
+		restore, err := deps.Clientset.PingcapV1alpha1().Restores(rs.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		g.Expect(err).Should(BeNil())
+		restore.Status.CommitTs = commitTs
+		restore.Status.Conditions = []v1alpha1.RestoreCondition{{Type: condition, Status: corev1.ConditionTrue}}
+		_, err = deps.Clientset.PingcapV1alpha1().Restores(rs.Namespace).Update(context.TODO(), restore, metav1.UpdateOptions{})
+		g.Expect(err).Should(BeNil())
+		g.Eventually(func() bool {
+			restore, err := deps.RestoreLister.Restores(rs.Namespace).Get(name)
+			return err == nil && len(restore.Status.Conditions) > 0
+		}, time.Second*10).Should(BeTrue())
+	}
+	waitRestore := func(name string) *v1alpha1.Restore {
+		var restore *v1alpha1.Restore
+		g.Eventually(func() error {
+			var err error
+			restore, err = deps.RestoreLister.Restores(rs.Namespace).Get(name)
+			return err
+		}, time.Second*10).Should(BeNil())
+		return restore
+	}
+
+	createBackup("primary-full", now.Add(-5*time.Hour), "", "100")
+	createBackup("primary-inc1", now.Add(-4*time.Hour), "100", "200")
+	createBackup("primary-inc2", now.Add(10*time.Minute), "200", "300")
+
+	// the full backup is restored at first
+	g.Expect(m.Sync(rs)).Should(Succeed())
+	g.Expect(rs.Status.LastScheduleTime.Time).Should(Equal(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)))
+	g.Expect(rs.Status.LastRestore).Should(Equal("standby-primary-full"))
+	g.Expect(rs.Status.LastRestoreBackup).Should(Equal("primary-full"))
+	restore := waitRestore("standby-primary-full")
+	g.Expect(restore.Spec.BR.Cluster).Should(Equal("standby"))
+	g.Expect(restore.Spec.S3.Prefix).Should(Equal("primary-full"))
+	g.Expect(restore.Labels).Should(HaveKeyWithValue(label.RestoreScheduleLabelKey, "standby"))
+
+	// wait for the running restore
+	err := m.Sync(rs)
+	g.Expect(err).Should(BeAssignableToTypeOf(&controller.RequeueError{}))
+
+	// the incremental backup is restored in the same refresh
+	finishRestore("standby-primary-full", v1alpha1.RestoreComplete, "100")
+	g.Expect(m.Sync(rs)).Should(Succeed())
+	g.Expect(rs.Status.StandbyTS).Should(Equal("100"))
+	g.Expect(rs.Status.StandbyTime).ShouldNot(BeNil())
+	g.Expect(rs.Status.LastRestore).Should(Equal("standby-primary-inc1"))
+	waitRestore("standby-primary-inc1")
+
+	// the backups completed after the scheduled time are restored in the next refresh
+	finishRestore("standby-primary-inc1", v1alpha1.RestoreComplete, "200")
+	g.Expect(m.Sync(rs)).Should(Succeed())
+	g.Expect(rs.Status.StandbyTS).Should(Equal("200"))
+	g.Expect(rs.Status.LastRestore).Should(Equal("standby-primary-inc1"))
+
+	now = now.Add(time.Hour)
+	g.Expect(m.Sync(rs)).Should(Succeed())
+	g.Expect(rs.Status.LastRestore).Should(Equal("standby-primary-inc2"))
+	waitRestore("standby-primary-inc2")
+
+	// the failed restore is retried in the next refresh
+	finishRestore("standby-primary-inc2", v1alpha1.RestoreFailed, "")
+	g.Expect(m.Sync(rs)).Should(Succeed())
+	g.Expect(rs.Status.StandbyTS).Should(Equal("200"))
+
+	now = now.Add(time.Hour)
+	err = m.Sync(rs)
+	g.Expect(err).Should(BeAssignableToTypeOf(&controller.RequeueError{}))
+	g.Eventually(func() error {
+		_, err := deps.RestoreLister.Restores(rs.Namespace).Get("standby-primary-inc2")
+		return err
+	}, time.Second*10).ShouldNot(BeNil())
+	g.Expect(m.Sync(rs)).Should(Succeed())
+	restore = waitRestore("standby-primary-inc2")
+	g.Expect(restore.Status.Conditions).Should(BeEmpty())
+
+	// a new chain is started after MaxIncrementalBackups of the backup schedule
+	finishRestore("standby-primary-inc2", v1alpha1.RestoreComplete, "300")
+	createBackup("primary-full2", now, "", "400")
+	createBackup("primary-full2-inc1", now.Add(5*time.Minute), "400", "500")
+	now = now.Add(time.Hour)
+	g.Expect(m.Sync(rs)).Should(Succeed())
+	g.Expect(rs.Status.StandbyTS).Should(Equal("300"))
+	g.Expect(rs.Status.LastRestore).Should(Equal("standby-primary-full2"))
+	waitRestore("standby-primary-full2")
+
+	finishRestore("standby-primary-full2", v1alpha1.RestoreComplete, "400")
+	g.Expect(m.Sync(rs)).Should(Succeed())
+	g.Expect(rs.Status.StandbyTS).Should(Equal("400"))
+	g.Expect(rs.Status.LastRestore).Should(Equal("standby-primary-full2-inc1"))
+}
+
+func TestTsToTime(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	physical := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := uint64(physical.UnixNano()/int64(time.Millisecond)) << physicalShiftBits
+	g.Expect(tsToTime("invalid")).Should(BeNil())
+	g.Expect(tsToTime(fmt.Sprintf("%d", ts+1)).Time.Equal(physical)).Should(BeTrue())
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver"
+	"github.com/dustin/go-humanize"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/constants"
 	corev1 "k8s.io/api/core/v1"
@@ -378,6 +379,9 @@ func GetBackupBucketName(backup *v1alpha1.Backup) (string, string, error) {
 		bucketName = backup.Spec.S3.Bucket
 	case v1alpha1.BackupStorageTypeGcs:
 		bucketName = backup.Spec.Gcs.Bucket
+	case v1alpha1.BackupStorageTypeLocal:
+		// the data is stored in the mounted volume
+		bucketName = backup.Spec.Local.VolumeMount.MountPath
 	default:
 		return bucketName, "UnsupportedStorageType", fmt.Errorf("backup %s/%s unsupported storage type %s", ns, name, storageType)
 	}
@@ -396,6 +400,8 @@ func GetBackupPrefixName(backup *v1alpha1.Backup) (string, string, error) {
 		prefix = backup.Spec.S3.Prefix
 	case v1alpha1.BackupStorageTypeGcs:
 		prefix = backup.Spec.Gcs.Prefix
+	case v1alpha1.BackupStorageTypeLocal:
+		prefix = backup.Spec.Local.Prefix
 	default:
 		return prefix, "UnsupportedStorageType", fmt.Errorf("backup %s/%s unsupported storage type %s", ns, name, storageType)
 	}
@@ -468,6 +474,16 @@ func ValidateBackup(backup *v1alpha1.Backup, tikvImage string) error {
 		if backup.Spec.StorageSize == "" {
 			return fmt.Errorf("missing StorageSize config in spec of %s/%s", ns, name)
 		}
+		if backup.Spec.Local != nil {
+			if err := validateLocal(ns, name, backup.Spec.Local); err != nil {
+				return err
+			}
+		}
+		if backup.Spec.Dumpling != nil {
+			if err := validateDumpling(ns, name, backup.Spec.Dumpling); err != nil {
+				return err
+			}
+		}
 	} else {
 		if !canSkipSetGCLifeTime(tikvImage) {
 			if reason := validateAccessConfig(backup.Spec.From); reason != "" {
@@ -523,6 +539,9 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 		if restore.Spec.StorageSize == "" {
 			return fmt.Errorf("missing StorageSize config in spec of %s/%s", ns, name)
 		}
+		if restore.Spec.VerifyChecksum {
+			return fmt.Errorf("verifyChecksum is only supported by BR in spec of %s/%s", ns, name)
+		}
 	} else {
 		if !canSkipSetGCLifeTime(tikvImage) {
 			if reason := validateAccessConfig(restore.Spec.To); reason != "" {
@@ -548,6 +567,14 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 			return fmt.Errorf("table should be configured for BR with restore type table in spec of %s/%s", ns, name)
 		}
 
+		// the checksums of the restored tables are calculated by tidb
+		if restore.Spec.VerifyChecksum && validateAccessConfig(restore.Spec.To) != "" {
+			return fmt.Errorf("cluster config should be configured to verify checksum in spec of %s/%s", ns, name)
+		}
+		if restore.Spec.VerifyChecksum && restore.Spec.BR.Checksum != nil && !*restore.Spec.BR.Checksum {
+			return fmt.Errorf("br.checksum can't be false if verifyChecksum is enabled in spec of %s/%s", ns, name)
+		}
+
 		// validate storage providers
 		if restore.Spec.S3 != nil {
 			if err := validateS3(ns, name, restore.Spec.S3); err != nil {
@@ -598,16 +625,44 @@ func validateGcs(ns, name string, gcs *v1alpha1.GcsStorageProvider) error {
 	return nil
 }
 
+func validateDumpling(ns, name string, dumpling *v1alpha1.DumplingConfig) error {
+	switch dumpling.FileType {
+	case "", "sql", "csv":
+	default:
+		return fmt.Errorf("invalid file type %s for dumpling in spec of %s/%s", dumpling.FileType, ns, name)
+	}
+	if dumpling.FileSize != "" {
+		if _, err := humanize.ParseBytes(dumpling.FileSize); err != nil {
+			return fmt.Errorf("invalid file size %s for dumpling in spec of %s/%s: %v", dumpling.FileSize, ns, name, err)
+		}
+	}
+	if dumpling.Threads != nil && *dumpling.Threads <= 0 {
+		return fmt.Errorf("threads should be positive for dumpling in spec of %s/%s", ns, name)
+	}
+	if dumpling.Rows != nil && *dumpling.Rows <= 0 {
+		return fmt.Errorf("rows should be positive for dumpling in spec of %s/%s", ns, name)
+	}
+	switch dumpling.Consistency {
+	case "", "auto", "snapshot", "lock", "flush", "none":
+	default:
+		return fmt.Errorf("invalid consistency %s for dumpling in spec of %s/%s", dumpling.Consistency, ns, name)
+	}
+	return nil
+}
+
 func validateLocal(ns, name string, local *v1alpha1.LocalStorageProvider) error {
-	configuredForBR := fmt.Sprintf("configured for BR in spec of %s/%s", ns, name)
+	configured := fmt.Sprintf("configured in spec of %s/%s", ns, name)
 	if local.VolumeMount.Name != local.Volume.Name {
-		return fmt.Errorf("Spec.Local.Volume.Name != Spec.Local.VolumeMount.Name is %s", configuredForBR)
+		return fmt.Errorf("Spec.Local.Volume.Name != Spec.Local.VolumeMount.Name is %s", configured)
 	}
 	if local.VolumeMount.MountPath == "" {
-		return fmt.Errorf("empty Spec.Local.VolumeMount.MountPath is %s", configuredForBR)
+		return fmt.Errorf("empty Spec.Local.VolumeMount.MountPath is %s", configured)
 	}
 	if strings.Contains(local.VolumeMount.MountPath, ":") {
-		return fmt.Errorf("Spec.Local.VolumeMount.MountPath cannot contain ':' %s", configuredForBR)
+		return fmt.Errorf("Spec.Local.VolumeMount.MountPath cannot contain ':' %s", configured)
+	}
+	if local.MinFreeSpace != nil && local.MinFreeSpace.Sign() < 0 {
+		return fmt.Errorf("negative Spec.Local.MinFreeSpace is %s", configured)
 	}
 	return nil
 }
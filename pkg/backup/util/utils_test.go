@@ -22,9 +22,11 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/constants"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 )
 
 func TestCheckAllKeysExistInSecret(t *testing.T) {
@@ -490,6 +492,34 @@ func TestValidateBackup(t *testing.T) {
 	backup.Spec.StorageSize = "1m"
 	match("")
 
+	backup.Spec.Local = &v1alpha1.LocalStorageProvider{
+		Volume:      corev1.Volume{Name: "nfs"},
+		VolumeMount: corev1.VolumeMount{Name: "nfs"},
+	}
+	match("empty Spec.Local.VolumeMount.MountPath is configured in spec of")
+	backup.Spec.Local.VolumeMount.MountPath = "/nfs"
+	minFreeSpace := resource.MustParse("-1Gi")
+	backup.Spec.Local.MinFreeSpace = &minFreeSpace
+	match("negative Spec.Local.MinFreeSpace")
+	minFreeSpace = resource.MustParse("10Gi")
+	match("")
+	backup.Spec.Local = nil
+
+	backup.Spec.Dumpling = &v1alpha1.DumplingConfig{FileType: "parquet"}
+	match("invalid file type parquet for dumpling")
+	backup.Spec.Dumpling.FileType = "csv"
+	backup.Spec.Dumpling.FileSize = "256MB/s"
+	match("invalid file size 256MB/s for dumpling")
+	backup.Spec.Dumpling.FileSize = "256MiB"
+	backup.Spec.Dumpling.Threads = pointer.Int32Ptr(0)
+	match("threads should be positive for dumpling")
+	backup.Spec.Dumpling.Threads = pointer.Int32Ptr(8)
+	backup.Spec.Dumpling.Consistency = "serializable"
+	match("invalid consistency serializable for dumpling")
+	backup.Spec.Dumpling.Consistency = "snapshot"
+	match("")
+	backup.Spec.Dumpling = nil
+
 	// start BR != nil case
 	backup.Spec.BR = &v1alpha1.BRConfig{}
 	match("cluster should be configured for BR in spec")
@@ -581,6 +611,19 @@ func TestValidateRestore(t *testing.T) {
 
 	restore.Spec.S3.Endpoint = "s3://localhost:80"
 	match("")
+
+	restore.Spec.VerifyChecksum = true
+	match("")
+	to := restore.Spec.To
+	restore.Spec.To = nil
+	match("cluster config should be configured to verify checksum in spec of")
+	restore.Spec.To = to
+	checksum := false
+	restore.Spec.BR.Checksum = &checksum
+	match("br.checksum can't be false if verifyChecksum is enabled in spec of")
+	restore.Spec.BR.Checksum = nil
+	restore.Spec.BR = nil
+	match("verifyChecksum is only supported by BR in spec of")
 }
 
 func TestGetImageTag(t *testing.T) {
@@ -278,6 +278,73 @@ func (c *Client) offline(addr string, nodeID string) error {
 	return nil
 }
 
+// commitTSStatus is the status of pump or drainer with the max commit ts,
+// which is the ts of the latest binlog written by a pump or consumed by a drainer.
+type commitTSStatus struct {
+	NodeID      string `json:"nodeId"`
+	Host        string `json:"host"`
+	State       string `json:"state"`
+	MaxCommitTS int64  `json:"maxCommitTS"`
+}
+
+func (c *Client) commitTSStatus(ctx context.Context, ty string) ([]*commitTSStatus, error) {
+	key := fmt.Sprintf("/tidb-binlog/v1/%s", ty)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := c.etcdClient.KV.Get(ctx, key, clientv3.WithPrefix())
+	if err != nil {
+		return nil, errors.AddStack(err)
+	}
+
+	var status []*commitTSStatus
+	for _, kv := range resp.Kvs {
+		var s commitTSStatus
+		if err := json.Unmarshal(kv.Value, &s); err != nil {
+			return nil, errors.Annotatef(err, "key: %s, data: %s", string(kv.Key), string(kv.Value))
+		}
+		status = append(status, &s)
+	}
+	return status, nil
+}
+
+// IsPumpConsumed returns whether all binlogs written by the pump have been consumed by
+// the online drainers, it's safe to remove the pump only if it's true.
+func (c *Client) IsPumpConsumed(ctx context.Context, addr string) (bool, error) {
+	pumps, err := c.commitTSStatus(ctx, "pumps")
+	if err != nil {
+		return false, err
+	}
+	var pump *commitTSStatus
+	for _, p := range pumps {
+		if p.Host == addr {
+			pump = p
+			break
+		}
+	}
+	if pump == nil {
+		return false, errors.Errorf("pumps node for address %s not found", addr)
+	}
+	drainers, err := c.commitTSStatus(ctx, "drainers")
+	if err != nil {
+		return false, err
+	}
+	return isPumpConsumed(pump, drainers), nil
+}
+
+func isPumpConsumed(pump *commitTSStatus, drainers []*commitTSStatus) bool {
+	for _, d := range drainers {
+		// paused or offline drainers don't consume binlogs
+		if d.State != "online" {
+			continue
+		}
+		if d.MaxCommitTS < pump.MaxCommitTS {
+			return false
+		}
+	}
+	return true
+}
+
 // OfflinePump offline a pump.
 func (c *Client) OfflinePump(ctx context.Context, addr string) error {
 	nodeID, err := c.nodeID(ctx, addr, "pumps")
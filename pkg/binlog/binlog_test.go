@@ -0,0 +1,63 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binlog
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestIsPumpConsumed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pump := &commitTSStatus{NodeID: "pump-0", State: "offline", MaxCommitTS: 100}
+	tests := []struct {
+		name     string
+		drainers []*commitTSStatus
+		expect   bool
+	}{
+		{
+			name:   "no drainer",
+			expect: true,
+		},
+		{
+			name: "consumed by online drainers",
+			drainers: []*commitTSStatus{
+				{NodeID: "drainer-0", State: "online", MaxCommitTS: 100},
+				{NodeID: "drainer-1", State: "online", MaxCommitTS: 101},
+			},
+			expect: true,
+		},
+		{
+			name: "not consumed by an online drainer",
+			drainers: []*commitTSStatus{
+				{NodeID: "drainer-0", State: "online", MaxCommitTS: 100},
+				{NodeID: "drainer-1", State: "online", MaxCommitTS: 99},
+			},
+			expect: false,
+		},
+		{
+			name: "paused drainer is ignored",
+			drainers: []*commitTSStatus{
+				{NodeID: "drainer-0", State: "online", MaxCommitTS: 100},
+				{NodeID: "drainer-1", State: "paused", MaxCommitTS: 50},
+			},
+			expect: true,
+		},
+	}
+	for _, tt := range tests {
+		g.Expect(isPumpConsumed(pump, tt.drainers)).To(Equal(tt.expect), tt.name)
+	}
+}
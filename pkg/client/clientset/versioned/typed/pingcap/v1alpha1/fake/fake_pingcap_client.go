@@ -41,10 +41,18 @@ func (c *FakePingcapV1alpha1) DataResources(namespace string) v1alpha1.DataResou
 	return &FakeDataResources{c, namespace}
 }
 
+func (c *FakePingcapV1alpha1) NodeMaintenances(namespace string) v1alpha1.NodeMaintenanceInterface {
+	return &FakeNodeMaintenances{c, namespace}
+}
+
 func (c *FakePingcapV1alpha1) Restores(namespace string) v1alpha1.RestoreInterface {
 	return &FakeRestores{c, namespace}
 }
 
+func (c *FakePingcapV1alpha1) RestoreSchedules(namespace string) v1alpha1.RestoreScheduleInterface {
+	return &FakeRestoreSchedules{c, namespace}
+}
+
 func (c *FakePingcapV1alpha1) TidbClusters(namespace string) v1alpha1.TidbClusterInterface {
 	return &FakeTidbClusters{c, namespace}
 }
@@ -53,6 +61,18 @@ func (c *FakePingcapV1alpha1) TidbClusterAutoScalers(namespace string) v1alpha1.
 	return &FakeTidbClusterAutoScalers{c, namespace}
 }
 
+func (c *FakePingcapV1alpha1) TidbClusterMaintenances(namespace string) v1alpha1.TidbClusterMaintenanceInterface {
+	return &FakeTidbClusterMaintenances{c, namespace}
+}
+
+func (c *FakePingcapV1alpha1) TidbClusterOperations(namespace string) v1alpha1.TidbClusterOperationInterface {
+	return &FakeTidbClusterOperations{c, namespace}
+}
+
+func (c *FakePingcapV1alpha1) TidbClusterPreflights(namespace string) v1alpha1.TidbClusterPreflightInterface {
+	return &FakeTidbClusterPreflights{c, namespace}
+}
+
 func (c *FakePingcapV1alpha1) TidbInitializers(namespace string) v1alpha1.TidbInitializerInterface {
 	return &FakeTidbInitializers{c, namespace}
 }
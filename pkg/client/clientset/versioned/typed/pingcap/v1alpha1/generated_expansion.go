@@ -23,12 +23,22 @@ type DMClusterExpansion interface{}
 
 type DataResourceExpansion interface{}
 
+type NodeMaintenanceExpansion interface{}
+
 type RestoreExpansion interface{}
 
+type RestoreScheduleExpansion interface{}
+
 type TidbClusterExpansion interface{}
 
 type TidbClusterAutoScalerExpansion interface{}
 
+type TidbClusterMaintenanceExpansion interface{}
+
+type TidbClusterOperationExpansion interface{}
+
+type TidbClusterPreflightExpansion interface{}
+
 type TidbInitializerExpansion interface{}
 
 type TidbMonitorExpansion interface{}
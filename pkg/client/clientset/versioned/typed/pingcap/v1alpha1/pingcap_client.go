@@ -27,9 +27,14 @@ type PingcapV1alpha1Interface interface {
 	BackupSchedulesGetter
 	DMClustersGetter
 	DataResourcesGetter
+	NodeMaintenancesGetter
 	RestoresGetter
+	RestoreSchedulesGetter
 	TidbClustersGetter
 	TidbClusterAutoScalersGetter
+	TidbClusterMaintenancesGetter
+	TidbClusterOperationsGetter
+	TidbClusterPreflightsGetter
 	TidbInitializersGetter
 	TidbMonitorsGetter
 	TidbNGMonitoringsGetter
@@ -56,10 +61,18 @@ func (c *PingcapV1alpha1Client) DataResources(namespace string) DataResourceInte
 	return newDataResources(c, namespace)
 }
 
+func (c *PingcapV1alpha1Client) NodeMaintenances(namespace string) NodeMaintenanceInterface {
+	return newNodeMaintenances(c, namespace)
+}
+
 func (c *PingcapV1alpha1Client) Restores(namespace string) RestoreInterface {
 	return newRestores(c, namespace)
 }
 
+func (c *PingcapV1alpha1Client) RestoreSchedules(namespace string) RestoreScheduleInterface {
+	return newRestoreSchedules(c, namespace)
+}
+
 func (c *PingcapV1alpha1Client) TidbClusters(namespace string) TidbClusterInterface {
 	return newTidbClusters(c, namespace)
 }
@@ -68,6 +81,18 @@ func (c *PingcapV1alpha1Client) TidbClusterAutoScalers(namespace string) TidbClu
 	return newTidbClusterAutoScalers(c, namespace)
 }
 
+func (c *PingcapV1alpha1Client) TidbClusterMaintenances(namespace string) TidbClusterMaintenanceInterface {
+	return newTidbClusterMaintenances(c, namespace)
+}
+
+func (c *PingcapV1alpha1Client) TidbClusterOperations(namespace string) TidbClusterOperationInterface {
+	return newTidbClusterOperations(c, namespace)
+}
+
+func (c *PingcapV1alpha1Client) TidbClusterPreflights(namespace string) TidbClusterPreflightInterface {
+	return newTidbClusterPreflights(c, namespace)
+}
+
 func (c *PingcapV1alpha1Client) TidbInitializers(namespace string) TidbInitializerInterface {
 	return newTidbInitializers(c, namespace)
 }
@@ -29,12 +29,22 @@ type Interface interface {
 	DMClusters() DMClusterInformer
 	// DataResources returns a DataResourceInformer.
 	DataResources() DataResourceInformer
+	// NodeMaintenances returns a NodeMaintenanceInformer.
+	NodeMaintenances() NodeMaintenanceInformer
 	// Restores returns a RestoreInformer.
 	Restores() RestoreInformer
+	// RestoreSchedules returns a RestoreScheduleInformer.
+	RestoreSchedules() RestoreScheduleInformer
 	// TidbClusters returns a TidbClusterInformer.
 	TidbClusters() TidbClusterInformer
 	// TidbClusterAutoScalers returns a TidbClusterAutoScalerInformer.
 	TidbClusterAutoScalers() TidbClusterAutoScalerInformer
+	// TidbClusterMaintenances returns a TidbClusterMaintenanceInformer.
+	TidbClusterMaintenances() TidbClusterMaintenanceInformer
+	// TidbClusterOperations returns a TidbClusterOperationInformer.
+	TidbClusterOperations() TidbClusterOperationInformer
+	// TidbClusterPreflights returns a TidbClusterPreflightInformer.
+	TidbClusterPreflights() TidbClusterPreflightInformer
 	// TidbInitializers returns a TidbInitializerInformer.
 	TidbInitializers() TidbInitializerInformer
 	// TidbMonitors returns a TidbMonitorInformer.
@@ -74,11 +84,21 @@ func (v *version) DataResources() DataResourceInformer {
 	return &dataResourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// NodeMaintenances returns a NodeMaintenanceInformer.
+func (v *version) NodeMaintenances() NodeMaintenanceInformer {
+	return &nodeMaintenanceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Restores returns a RestoreInformer.
 func (v *version) Restores() RestoreInformer {
 	return &restoreInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// RestoreSchedules returns a RestoreScheduleInformer.
+func (v *version) RestoreSchedules() RestoreScheduleInformer {
+	return &restoreScheduleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // TidbClusters returns a TidbClusterInformer.
 func (v *version) TidbClusters() TidbClusterInformer {
 	return &tidbClusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -89,6 +109,21 @@ func (v *version) TidbClusterAutoScalers() TidbClusterAutoScalerInformer {
 	return &tidbClusterAutoScalerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// TidbClusterMaintenances returns a TidbClusterMaintenanceInformer.
+func (v *version) TidbClusterMaintenances() TidbClusterMaintenanceInformer {
+	return &tidbClusterMaintenanceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// TidbClusterOperations returns a TidbClusterOperationInformer.
+func (v *version) TidbClusterOperations() TidbClusterOperationInformer {
+	return &tidbClusterOperationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// TidbClusterPreflights returns a TidbClusterPreflightInformer.
+func (v *version) TidbClusterPreflights() TidbClusterPreflightInformer {
+	return &tidbClusterPreflightInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // TidbInitializers returns a TidbInitializerInformer.
 func (v *version) TidbInitializers() TidbInitializerInformer {
 	return &tidbInitializerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
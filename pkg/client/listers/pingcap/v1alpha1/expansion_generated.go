@@ -47,6 +47,14 @@ type DataResourceListerExpansion interface{}
 // DataResourceNamespaceLister.
 type DataResourceNamespaceListerExpansion interface{}
 
+// NodeMaintenanceListerExpansion allows custom methods to be added to
+// NodeMaintenanceLister.
+type NodeMaintenanceListerExpansion interface{}
+
+// NodeMaintenanceNamespaceListerExpansion allows custom methods to be added to
+// NodeMaintenanceNamespaceLister.
+type NodeMaintenanceNamespaceListerExpansion interface{}
+
 // RestoreListerExpansion allows custom methods to be added to
 // RestoreLister.
 type RestoreListerExpansion interface{}
@@ -55,6 +63,14 @@ type RestoreListerExpansion interface{}
 // RestoreNamespaceLister.
 type RestoreNamespaceListerExpansion interface{}
 
+// RestoreScheduleListerExpansion allows custom methods to be added to
+// RestoreScheduleLister.
+type RestoreScheduleListerExpansion interface{}
+
+// RestoreScheduleNamespaceListerExpansion allows custom methods to be added to
+// RestoreScheduleNamespaceLister.
+type RestoreScheduleNamespaceListerExpansion interface{}
+
 // TidbClusterListerExpansion allows custom methods to be added to
 // TidbClusterLister.
 type TidbClusterListerExpansion interface{}
@@ -71,6 +87,30 @@ type TidbClusterAutoScalerListerExpansion interface{}
 // TidbClusterAutoScalerNamespaceLister.
 type TidbClusterAutoScalerNamespaceListerExpansion interface{}
 
+// TidbClusterMaintenanceListerExpansion allows custom methods to be added to
+// TidbClusterMaintenanceLister.
+type TidbClusterMaintenanceListerExpansion interface{}
+
+// TidbClusterMaintenanceNamespaceListerExpansion allows custom methods to be added to
+// TidbClusterMaintenanceNamespaceLister.
+type TidbClusterMaintenanceNamespaceListerExpansion interface{}
+
+// TidbClusterOperationListerExpansion allows custom methods to be added to
+// TidbClusterOperationLister.
+type TidbClusterOperationListerExpansion interface{}
+
+// TidbClusterOperationNamespaceListerExpansion allows custom methods to be added to
+// TidbClusterOperationNamespaceLister.
+type TidbClusterOperationNamespaceListerExpansion interface{}
+
+// TidbClusterPreflightListerExpansion allows custom methods to be added to
+// TidbClusterPreflightLister.
+type TidbClusterPreflightListerExpansion interface{}
+
+// TidbClusterPreflightNamespaceListerExpansion allows custom methods to be added to
+// TidbClusterPreflightNamespaceLister.
+type TidbClusterPreflightNamespaceListerExpansion interface{}
+
 // TidbInitializerListerExpansion allows custom methods to be added to
 // TidbInitializerLister.
 type TidbInitializerListerExpansion interface{}
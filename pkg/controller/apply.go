@@ -0,0 +1,225 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	asapps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/features"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+// FieldManager is the field manager of the objects applied by tidb-operator
+const FieldManager = "tidb-operator"
+
+// ServerSideApplyEnabled returns whether the managed objects are reconciled by server-side apply
+func ServerSideApplyEnabled() bool {
+	return features.DefaultFeatureGate.Enabled(features.ServerSideApply)
+}
+
+// HasNativeSidecars returns whether the pod template of the StatefulSet has native sidecar containers.
+// The restartPolicy of init containers is not in the typed API of the client, so such StatefulSets
+// are always reconciled by server-side apply.
+func HasNativeSidecars(set *apps.StatefulSet) bool {
+	return set.Spec.Template.Annotations[label.AnnNativeSidecars] != ""
+}
+
+// ApplyPatchOptions returns the options of applying objects. The apply patches only contain the
+// fields tidb-operator wants, see ApplyPatch, so the conflicts are only forced on the fields owned
+// by tidb-operator, e.g. the spec it generates is changed by kubectl edit.
+func ApplyPatchOptions() metav1.PatchOptions {
+	return metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        pointer.BoolPtr(true),
+	}
+}
+
+// ApplyPatch returns the apply patch of obj, which only contains the fields tidb-operator wants:
+//   - the spec, data and other top level fields generated by tidb-operator, the fields populated
+//     by the api-server, e.g. status, resourceVersion and managedFields, are removed
+//   - the controller reference
+//   - the labels and annotations which are added or changed compared with live, and the unchanged
+//     ones owned by tidb-operator according to the managedFields of live
+//
+// obj is usually the live object with the desired changes merged, so the labels and annotations
+// set by other controllers and users are left out of the patch and stay with their field managers.
+// live is nil if the object doesn't exist.
+func ApplyPatch(obj runtime.Object, live metav1.Object) ([]byte, error) {
+	gvk, err := InferObjectKind(obj)
+	if err != nil {
+		return nil, err
+	}
+	return applyPatch(obj, gvk, live)
+}
+
+// AdvancedStatefulSetApplyPatch returns the apply patch of the StatefulSet as an Advanced StatefulSet,
+// the hijacked client forwards the patch of the StatefulSet to the Advanced StatefulSet as is.
+func AdvancedStatefulSetApplyPatch(set *apps.StatefulSet, live metav1.Object) ([]byte, error) {
+	return applyPatch(set, asapps.SchemeGroupVersion.WithKind("StatefulSet"), live)
+}
+
+func applyPatch(obj runtime.Object, gvk schema.GroupVersionKind, live metav1.Object) ([]byte, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u["apiVersion"], u["kind"] = gvk.GroupVersion().String(), gvk.Kind
+	delete(u, "status")
+	if gvk.Group == asapps.GroupName {
+		// the restartPolicy of init containers is dropped by Advanced StatefulSet, the native sidecars
+		// would never exit and block the main containers
+		if names, _, _ := unstructured.NestedString(u, "spec", "template", "metadata", "annotations", label.AnnNativeSidecars); names != "" {
+			return nil, fmt.Errorf("native sidecars %s are not supported by Advanced StatefulSet %s", names, accessor.GetName())
+		}
+	} else if err := setNativeSidecars(u); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{"name": accessor.GetName()}
+	if ns := accessor.GetNamespace(); ns != "" {
+		metadata["namespace"] = ns
+	}
+	var liveLabels, liveAnnotations map[string]string
+	var owned managedKeys
+	if live != nil {
+		liveLabels, liveAnnotations = live.GetLabels(), live.GetAnnotations()
+		owned = ownedMetadataKeys(live.GetManagedFields())
+	}
+	if labels := appliedKeys(accessor.GetLabels(), liveLabels, owned.labels); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if annotations := appliedKeys(accessor.GetAnnotations(), liveAnnotations, owned.annotations); len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	if ref := metav1.GetControllerOf(accessor); ref != nil {
+		ownerRef, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ref)
+		if err != nil {
+			return nil, err
+		}
+		metadata["ownerReferences"] = []interface{}{ownerRef}
+	}
+	u["metadata"] = metadata
+	pruneNull(u)
+	return json.Marshal(u)
+}
+
+// appliedKeys returns the keys of desired which are added or changed compared with live, or owned
+// by tidb-operator
+func appliedKeys(desired, live map[string]string, owned sets.String) map[string]interface{} {
+	applied := map[string]interface{}{}
+	for k, v := range desired {
+		if lv, ok := live[k]; !ok || lv != v || owned.Has(k) {
+			applied[k] = v
+		}
+	}
+	return applied
+}
+
+// legacyFieldManager is the field manager of the objects updated by tidb-controller-manager before
+// server-side apply is enabled, it's derived from the user agent by the api-server
+const legacyFieldManager = "tidb-controller-manager"
+
+type managedKeys struct {
+	labels      sets.String
+	annotations sets.String
+}
+
+// ownedMetadataKeys returns the labels and annotations owned by tidb-operator in the managedFields
+func ownedMetadataKeys(entries []metav1.ManagedFieldsEntry) managedKeys {
+	owned := managedKeys{labels: sets.NewString(), annotations: sets.NewString()}
+	for _, entry := range entries {
+		if entry.Manager != FieldManager && entry.Manager != legacyFieldManager {
+			continue
+		}
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			klog.Warningf("failed to parse the managed fields of %s: %v", entry.Manager, err)
+			continue
+		}
+		metadata, _ := fields["f:metadata"].(map[string]interface{})
+		for field, keys := range map[string]sets.String{"f:labels": owned.labels, "f:annotations": owned.annotations} {
+			set, _ := metadata[field].(map[string]interface{})
+			for k := range set {
+				if strings.HasPrefix(k, "f:") {
+					keys.Insert(strings.TrimPrefix(k, "f:"))
+				}
+			}
+		}
+	}
+	return owned
+}
+
+// pruneNull removes the null values in u, e.g. the null creationTimestamp of the pod template,
+// which would otherwise be applied as fields of tidb-operator
+func pruneNull(u map[string]interface{}) {
+	for k, v := range u {
+		switch val := v.(type) {
+		case nil:
+			delete(u, k)
+		case map[string]interface{}:
+			pruneNull(val)
+		case []interface{}:
+			for _, item := range val {
+				if m, ok := item.(map[string]interface{}); ok {
+					pruneNull(m)
+				}
+			}
+		}
+	}
+}
+
+// setNativeSidecars sets the restartPolicy of the init containers recorded in the pod template
+// annotation to Always
+func setNativeSidecars(u map[string]interface{}) error {
+	names, found, err := unstructured.NestedString(u, "spec", "template", "metadata", "annotations", label.AnnNativeSidecars)
+	if err != nil || !found || names == "" {
+		return err
+	}
+	sidecars := sets.NewString(strings.Split(names, ",")...)
+	containers, _, err := unstructured.NestedSlice(u, "spec", "template", "spec", "initContainers")
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := container["name"].(string); ok && sidecars.Has(name) {
+			container["restartPolicy"] = string(corev1.RestartPolicyAlways)
+		}
+	}
+	return unstructured.SetNestedSlice(u, containers, "spec", "template", "spec", "initContainers")
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+)
+
+func TestApplyPatch(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-tidb",
+			Namespace:       metav1.NamespaceDefault,
+			ResourceVersion: "100",
+			UID:             "uid",
+			Labels:          map[string]string{"app.kubernetes.io/component": "tidb"},
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}},
+		},
+	}
+	data, err := ApplyPatch(svc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	patch := map[string]interface{}{}
+	g.Expect(json.Unmarshal(data, &patch)).To(Succeed())
+	g.Expect(patch).To(HaveKeyWithValue("apiVersion", "v1"))
+	g.Expect(patch).To(HaveKeyWithValue("kind", "Service"))
+	g.Expect(patch).NotTo(HaveKey("status"))
+	meta := patch["metadata"].(map[string]interface{})
+	g.Expect(meta).To(HaveKeyWithValue("name", "test-tidb"))
+	g.Expect(meta).To(HaveKey("labels"))
+	g.Expect(meta).NotTo(HaveKey("resourceVersion"))
+	g.Expect(meta).NotTo(HaveKey("uid"))
+	g.Expect(meta).NotTo(HaveKey("managedFields"))
+
+	// the object is not mutated
+	g.Expect(svc.ResourceVersion).To(Equal("100"))
+}
+
+func TestApplyPatchOnlyAppliesOwnedMetadata(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	live := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-tidb",
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				"app.kubernetes.io/component": "tidb",
+				"team":                        "db",
+			},
+			Annotations: map[string]string{
+				"pingcap.com/last-applied-hash": "1",
+				"cloud.example.com/lb-id":       "lb-1",
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{
+					Manager:  FieldManager,
+					FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:app.kubernetes.io/component":{}},"f:annotations":{"f:pingcap.com/last-applied-hash":{}}}}`)},
+				},
+				{
+					Manager:  "kubectl",
+					FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:team":{}}}}`)},
+				},
+				{
+					Manager:  "cloud-controller-manager",
+					FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:cloud.example.com/lb-id":{}}}}`)},
+				},
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "pingcap.com/v1alpha1", Kind: "TidbCluster", Name: "test", UID: "uid", Controller: pointer.BoolPtr(true)},
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: "other"},
+			},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: "10.0.0.1"},
+	}
+	// the live object with the desired changes merged
+	svc := live.DeepCopy()
+	svc.Annotations["pingcap.com/last-applied-hash"] = "2"
+	svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"] = "nlb"
+
+	data, err := ApplyPatch(svc, live)
+	g.Expect(err).NotTo(HaveOccurred())
+	patch := map[string]interface{}{}
+	g.Expect(json.Unmarshal(data, &patch)).To(Succeed())
+	meta := patch["metadata"].(map[string]interface{})
+	g.Expect(meta["labels"]).To(Equal(map[string]interface{}{"app.kubernetes.io/component": "tidb"}))
+	g.Expect(meta["annotations"]).To(Equal(map[string]interface{}{
+		"pingcap.com/last-applied-hash":                     "2",
+		"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+	}))
+	g.Expect(meta["ownerReferences"]).To(HaveLen(1))
+	g.Expect(patch["spec"]).To(HaveKeyWithValue("clusterIP", "10.0.0.1"))
+}
+
+func TestAdvancedStatefulSetApplyPatch(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-tikv",
+			Namespace:       metav1.NamespaceDefault,
+			ResourceVersion: "100",
+		},
+	}
+	data, err := AdvancedStatefulSetApplyPatch(set, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	patch := map[string]interface{}{}
+	g.Expect(json.Unmarshal(data, &patch)).To(Succeed())
+	g.Expect(patch).To(HaveKeyWithValue("apiVersion", "apps.pingcap.com/v1"))
+	g.Expect(patch).To(HaveKeyWithValue("kind", "StatefulSet"))
+	g.Expect(patch["metadata"]).NotTo(HaveKey("resourceVersion"))
+}
+
+func TestApplyPatchNativeSidecars(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-tidb",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: apps.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{label.AnnNativeSidecars: "slowlog"},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "init"}, {Name: "slowlog"}},
+					Containers:     []corev1.Container{{Name: "tidb"}},
+				},
+			},
+		},
+	}
+	g.Expect(HasNativeSidecars(set)).To(BeTrue())
+	data, err := ApplyPatch(set, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	patch := &unstructured.Unstructured{}
+	g.Expect(json.Unmarshal(data, &patch.Object)).To(Succeed())
+	initContainers, _, err := unstructured.NestedSlice(patch.Object, "spec", "template", "spec", "initContainers")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(initContainers).To(HaveLen(2))
+	g.Expect(initContainers[0]).NotTo(HaveKey("restartPolicy"))
+	g.Expect(initContainers[1]).To(HaveKeyWithValue("restartPolicy", "Always"))
+
+	// the restartPolicy is dropped by Advanced StatefulSet
+	_, err = AdvancedStatefulSetApplyPatch(set, nil)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(HasNativeSidecars(&apps.StatefulSet{})).To(BeFalse())
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
@@ -47,6 +48,8 @@ type BackupUpdateStatus struct {
 	BackupSize *int64
 	// CommitTs is the snapshot time point of tidb cluster.
 	CommitTs *string
+	// DumplingProgress is the progress of the dumpling export.
+	DumplingProgress *v1alpha1.DumplingProgress
 }
 
 // BackupConditionUpdaterInterface enables updating Backup conditions.
@@ -78,9 +81,9 @@ func (u *realBackupConditionUpdater) Update(backup *v1alpha1.Backup, condition *
 	var isUpdate bool
 	// try best effort to guarantee backup is updated.
 	err := retry.OnError(retry.DefaultRetry, func(e error) bool { return e != nil }, func() error {
-		updateBackupStatus(&backup.Status, newStatus)
+		isStatusUpdate := updateBackupStatus(&backup.Status, newStatus)
 		isUpdate = v1alpha1.UpdateBackupCondition(&backup.Status, condition)
-		if isUpdate {
+		if isUpdate || isStatusUpdate {
 			_, updateErr := u.cli.PingcapV1alpha1().Backups(ns).Update(context.TODO(), backup, metav1.UpdateOptions{})
 			if updateErr == nil {
 				klog.Infof("Backup: [%s/%s] updated successfully", ns, backupName)
@@ -101,29 +104,42 @@ func (u *realBackupConditionUpdater) Update(backup *v1alpha1.Backup, condition *
 }
 
 // updateBackupStatus updates existing Backup status
-// from the fields in BackupUpdateStatus.
-func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateStatus) {
+// from the fields in BackupUpdateStatus, it returns whether the status is changed.
+func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateStatus) bool {
 	if newStatus == nil {
-		return
+		return false
 	}
-	if newStatus.BackupPath != nil {
+	isUpdate := false
+	if newStatus.BackupPath != nil && status.BackupPath != *newStatus.BackupPath {
 		status.BackupPath = *newStatus.BackupPath
+		isUpdate = true
 	}
-	if newStatus.TimeStarted != nil {
+	if newStatus.TimeStarted != nil && !status.TimeStarted.Equal(newStatus.TimeStarted) {
 		status.TimeStarted = *newStatus.TimeStarted
+		isUpdate = true
 	}
-	if newStatus.TimeCompleted != nil {
+	if newStatus.TimeCompleted != nil && !status.TimeCompleted.Equal(newStatus.TimeCompleted) {
 		status.TimeCompleted = *newStatus.TimeCompleted
+		isUpdate = true
 	}
-	if newStatus.BackupSizeReadable != nil {
+	if newStatus.BackupSizeReadable != nil && status.BackupSizeReadable != *newStatus.BackupSizeReadable {
 		status.BackupSizeReadable = *newStatus.BackupSizeReadable
+		isUpdate = true
 	}
-	if newStatus.BackupSize != nil {
+	if newStatus.BackupSize != nil && status.BackupSize != *newStatus.BackupSize {
 		status.BackupSize = *newStatus.BackupSize
+		isUpdate = true
 	}
-	if newStatus.CommitTs != nil {
+	if newStatus.CommitTs != nil && status.CommitTs != *newStatus.CommitTs {
 		status.CommitTs = *newStatus.CommitTs
+		isUpdate = true
 	}
+	// the progress is updated without a change of the conditions
+	if newStatus.DumplingProgress != nil && !apiequality.Semantic.DeepEqual(status.DumplingProgress, newStatus.DumplingProgress) {
+		status.DumplingProgress = newStatus.DumplingProgress
+		isUpdate = true
+	}
+	return isUpdate
 }
 
 var _ BackupConditionUpdaterInterface = &realBackupConditionUpdater{}
@@ -29,6 +29,7 @@ func TestUpdateBackupStatus(t *testing.T) {
 		status       *v1alpha1.BackupStatus
 		updateStatus *BackupUpdateStatus
 		expectStatus *v1alpha1.BackupStatus
+		expectUpdate bool
 	}{
 		{
 			name:         "updateStatus is nil",
@@ -47,12 +48,32 @@ func TestUpdateBackupStatus(t *testing.T) {
 			status:       newBackupStatus(),
 			updateStatus: newUpdateBackupStatus(),
 			expectStatus: newExpectBackupStatus(),
+			expectUpdate: true,
+		},
+		{
+			name:         "fields are not changed",
+			status:       newExpectBackupStatus(),
+			updateStatus: newUpdateBackupStatus(),
+			expectStatus: newExpectBackupStatus(),
+		},
+		{
+			name:   "dumpling progress is updated",
+			status: newBackupStatus(),
+			updateStatus: &BackupUpdateStatus{
+				DumplingProgress: &v1alpha1.DumplingProgress{FinishedTables: 1, TotalTables: 2},
+			},
+			expectStatus: func() *v1alpha1.BackupStatus {
+				s := newBackupStatus()
+				s.DumplingProgress = &v1alpha1.DumplingProgress{FinishedTables: 1, TotalTables: 2}
+				return s
+			}(),
+			expectUpdate: true,
 		},
 	}
 
 	for _, test := range tests {
 		t.Logf("test: %+v", test.name)
-		updateBackupStatus(test.status, test.updateStatus)
+		g.Expect(updateBackupStatus(test.status, test.updateStatus)).Should(Equal(test.expectUpdate))
 		g.Expect(*test.status).Should(Equal(*test.expectStatus))
 	}
 }
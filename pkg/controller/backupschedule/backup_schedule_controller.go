@@ -70,17 +70,13 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	klog.Info("Starting backup schedule controller")
 	defer klog.Info("Shutting down backup schedule controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
+	// the number of the workers follows the workers flag reloaded from the config file
+	wg := controller.RunWorkers(workers, c.deps.CLIConfig, c.processNextWorkItem, stopCh)
 
 	<-stopCh
-}
-
-// worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
+	// wait for the in-flight syncs to finish, so that no operation is left half-done
+	c.queue.ShutDown()
+	wg.Wait()
 }
 
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
@@ -118,6 +114,10 @@ func (c *Controller) sync(key string) error {
 	if err != nil {
 		return err
 	}
+	if !c.deps.NamespaceSelector.Selected(ns) {
+		klog.V(4).Infof("BackupSchedule %q is not in the selected namespaces, skip syncing", key)
+		return nil
+	}
 	bs, err := c.deps.BackupScheduleLister.BackupSchedules(ns).Get(name)
 	if errors.IsNotFound(err) {
 		klog.Infof("BackupSchedule has been deleted %v", key)
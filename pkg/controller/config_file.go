@@ -0,0 +1,198 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/features"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// DynamicFlags are the flags reloaded from the config file without restarting, they are read by
+// the accessors of CLIConfig, e.g. IsAutoFailover. The changes of the other flags in the file take
+// effect on restarting.
+//
+// The workers of the controllers follow workers, see RunWorkers, and the TidbClusters are requeued
+// after each sync by resync-duration, while the other resources keep the resync duration of the
+// informers on starting.
+// The features flag is partially dynamic, see features.FeatureSpec.
+var DynamicFlags = sets.NewString(
+	"workers",
+	"resync-duration",
+	"auto-failover",
+	"pd-failover-period",
+	"tikv-failover-period",
+	"tidb-failover-period",
+	"tiflash-failover-period",
+	"dm-master-failover-period",
+	"dm-worker-failover-period",
+	"orphan-gc-delete",
+)
+
+// ConfigChange is a change of a flag found by reloading the config file
+type ConfigChange struct {
+	Flag string
+	Old  string
+	New  string
+	// Applied is false if the flag is not dynamic and the change takes effect on restarting
+	Applied bool
+}
+
+// ConfigFileLoader loads the flags from CLIConfig.ConfigFile
+type ConfigFileLoader struct {
+	cfg *CLIConfig
+	fs  *flag.FlagSet
+	// last is the pairs in the file loaded last time
+	last map[string]string
+}
+
+// NewConfigFileLoader returns a ConfigFileLoader setting the flags of fs, which must be bound to cfg
+func NewConfigFileLoader(cfg *CLIConfig, fs *flag.FlagSet) *ConfigFileLoader {
+	return &ConfigFileLoader{
+		cfg:  cfg,
+		fs:   fs,
+		last: map[string]string{},
+	}
+}
+
+// Load sets all the flags in the file. It's called after parsing the command line on starting, so
+// the flags in the file take precedence.
+func (l *ConfigFileLoader) Load() error {
+	pairs, err := l.parse()
+	if err != nil {
+		return err
+	}
+	for _, name := range sets.StringKeySet(pairs).List() {
+		if err := l.fs.Set(name, pairs[name]); err != nil {
+			return fmt.Errorf("invalid value of %s=%s in %s, err: %v", name, pairs[name], l.cfg.ConfigFile, err)
+		}
+	}
+	l.last = pairs
+	return nil
+}
+
+// Reload sets the dynamic flags changed since the last load, the dynamic flags removed from the file
+// are reset to the defaults. The changes of the other flags are returned but not applied. The features
+// flag is reloaded by the feature gate, which applies the changes of the dynamic features only.
+func (l *ConfigFileLoader) Reload() ([]ConfigChange, error) {
+	pairs, err := l.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ConfigChange
+	var errs []string
+	names := sets.StringKeySet(pairs).Union(sets.StringKeySet(l.last))
+	for _, name := range names.List() {
+		value, ok := pairs[name]
+		if last, loaded := l.last[name]; ok == loaded && value == last {
+			continue
+		}
+		f := l.fs.Lookup(name)
+		if gate, isGate := f.Value.(features.FeatureGate); isGate {
+			// the features removed from the file are reset to the defaults by the feature gate
+			featureChanges, err := gate.Reload(l.last[name], value)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("invalid value of %s=%s, err: %v", name, value, err))
+				pairs[name] = l.last[name]
+				continue
+			}
+			for _, c := range featureChanges {
+				changes = append(changes, ConfigChange{
+					Flag:    name,
+					Old:     fmt.Sprintf("%s=%t", c.Feature, c.Old),
+					New:     fmt.Sprintf("%s=%t", c.Feature, c.New),
+					Applied: c.Applied,
+				})
+			}
+			continue
+		}
+		if !ok {
+			value = f.DefValue
+		}
+		if !DynamicFlags.Has(name) {
+			changes = append(changes, ConfigChange{Flag: name, Old: f.Value.String(), New: value})
+			continue
+		}
+
+		l.cfg.lock.Lock()
+		old := f.Value.String()
+		err := f.Value.Set(value)
+		l.cfg.lock.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value of %s=%s, err: %v", name, value, err))
+			// keep the last value so that the error is reported again
+			pairs[name] = l.last[name]
+			continue
+		}
+		if f.Value.String() != old {
+			changes = append(changes, ConfigChange{Flag: name, Old: old, New: f.Value.String(), Applied: true})
+		}
+	}
+	l.last = pairs
+	if len(errs) > 0 {
+		return changes, fmt.Errorf("failed to reload %s: %s", l.cfg.ConfigFile, strings.Join(errs, "; "))
+	}
+	return changes, nil
+}
+
+// Watch reloads the file every period until stopCh is closed, onChange is called for each change.
+// The file is usually mounted from a ConfigMap, which is updated by kubelet on changing.
+func (l *ConfigFileLoader) Watch(period time.Duration, onChange func(ConfigChange), stopCh <-chan struct{}) {
+	wait.Until(func() {
+		changes, err := l.Reload()
+		for _, change := range changes {
+			onChange(change)
+		}
+		if err != nil {
+			klog.Errorf("%v", err)
+		}
+	}, period, stopCh)
+}
+
+// parse parses the flag=value pairs separated by new lines, the empty lines and the lines starting
+// with # are ignored
+func (l *ConfigFileLoader) parse() (map[string]string, error) {
+	data, err := ioutil.ReadFile(l.cfg.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	pairs := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		arr := strings.SplitN(line, "=", 2)
+		name := strings.TrimLeft(strings.TrimSpace(arr[0]), "-")
+		if len(arr) != 2 {
+			return nil, fmt.Errorf("missing value for %s in %s", name, l.cfg.ConfigFile)
+		}
+		if l.fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("unknown flag %s in %s", name, l.cfg.ConfigFile)
+		}
+		if name == "config-file" {
+			return nil, fmt.Errorf("flag %s can't be set in %s", name, l.cfg.ConfigFile)
+		}
+		pairs[name] = strings.TrimSpace(arr[1])
+	}
+	return pairs, nil
+}
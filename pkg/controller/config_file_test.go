@@ -0,0 +1,114 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/features"
+)
+
+func TestConfigFileLoader(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "config-file")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	writeFile := func(content string) {
+		g.Expect(ioutil.WriteFile(filepath.Join(dir, "config"), []byte(content), 0644)).To(Succeed())
+	}
+
+	cfg := DefaultCLIConfig()
+	cfg.ConfigFile = filepath.Join(dir, "config")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.IntVar(&cfg.Workers, "workers", cfg.Workers, "")
+	fs.BoolVar(&cfg.AutoFailover, "auto-failover", cfg.AutoFailover, "")
+	fs.DurationVar(&cfg.TiKVFailoverPeriod, "tikv-failover-period", cfg.TiKVFailoverPeriod, "")
+	fs.BoolVar(&cfg.DryRun, "dry-run", cfg.DryRun, "")
+	fs.StringVar(&cfg.ConfigFile, "config-file", cfg.ConfigFile, "")
+	gate := features.NewDefaultFeatureGate()
+	fs.Var(gate, "features", "")
+	loader := NewConfigFileLoader(cfg, fs)
+
+	writeFile("# comment\nworkers=10\n-tikv-failover-period = 10m\n")
+	g.Expect(loader.Load()).To(Succeed())
+	g.Expect(cfg.Workers).To(Equal(10))
+	g.Expect(cfg.GetFailoverPeriod(v1alpha1.TiKVMemberType)).To(Equal(10 * time.Minute))
+
+	// nothing is changed
+	changes, err := loader.Reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changes).To(BeEmpty())
+
+	// the dynamic flags are applied, and the removed ones are reset to the defaults
+	writeFile("workers=20\nauto-failover=false\ndry-run=true\n")
+	changes, err = loader.Reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changes).To(Equal([]ConfigChange{
+		{Flag: "auto-failover", Old: "true", New: "false", Applied: true},
+		{Flag: "dry-run", Old: "false", New: "true"},
+		{Flag: "tikv-failover-period", Old: "10m0s", New: "5m0s", Applied: true},
+		{Flag: "workers", Old: "10", New: "20", Applied: true},
+	}))
+	g.Expect(cfg.IsAutoFailover()).To(BeFalse())
+	g.Expect(cfg.GetFailoverPeriod(v1alpha1.TiKVMemberType)).To(Equal(5 * time.Minute))
+	g.Expect(cfg.GetWorkers()).To(Equal(20))
+	g.Expect(cfg.DryRun).To(BeFalse())
+
+	// the change of a flag requiring restarting is reported once
+	changes, err = loader.Reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changes).To(BeEmpty())
+
+	// only the changes of the dynamic features are applied
+	writeFile("workers=20\nauto-failover=false\ndry-run=true\nfeatures=ServerSideApply=true,AutoScaling=true\n")
+	changes, err = loader.Reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changes).To(Equal([]ConfigChange{
+		{Flag: "features", Old: "AutoScaling=false", New: "AutoScaling=true"},
+		{Flag: "features", Old: "ServerSideApply=false", New: "ServerSideApply=true", Applied: true},
+	}))
+	g.Expect(gate.Enabled(features.ServerSideApply)).To(BeTrue())
+	g.Expect(gate.Enabled(features.AutoScaling)).To(BeFalse())
+
+	// the dynamic features removed from the file are reset to the defaults
+	writeFile("workers=20\nauto-failover=false\ndry-run=true\n")
+	changes, err = loader.Reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changes).To(Equal([]ConfigChange{
+		{Flag: "features", Old: "ServerSideApply=true", New: "ServerSideApply=false", Applied: true},
+	}))
+	g.Expect(gate.Enabled(features.ServerSideApply)).To(BeFalse())
+
+	// the invalid value is reported until it's fixed
+	writeFile("workers=20\nauto-failover=maybe\ndry-run=true\n")
+	_, err = loader.Reload()
+	g.Expect(err).To(HaveOccurred())
+	_, err = loader.Reload()
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(cfg.IsAutoFailover()).To(BeFalse())
+
+	for _, content := range []string{"unknown=1\n", "workers\n", "config-file=/tmp/config\n"} {
+		writeFile(content)
+		_, err = loader.Reload()
+		g.Expect(err).To(HaveOccurred(), content)
+	}
+}
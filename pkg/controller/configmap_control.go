@@ -19,8 +19,10 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -67,6 +69,27 @@ func (c *realConfigMapControl) CreateConfigMap(owner runtime.Object, cm *corev1.
 func (c *realConfigMapControl) UpdateConfigMap(owner runtime.Object, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
 	ns := cm.GetNamespace()
 	cmName := cm.GetName()
+	if ServerSideApplyEnabled() {
+		// ConfigMaps are not cached, the live one is read to leave the labels and annotations
+		// of others out of the apply patch
+		var live metav1.Object
+		if current, err := c.kubeCli.CoreV1().ConfigMaps(ns).Get(context.Background(), cmName, metav1.GetOptions{}); err == nil {
+			live = current
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		data, err := ApplyPatch(cm, live)
+		if err != nil {
+			return nil, err
+		}
+		updatedCm, err := c.kubeCli.CoreV1().ConfigMaps(ns).Patch(context.Background(), cmName, types.ApplyPatchType, data, ApplyPatchOptions())
+		if err != nil {
+			return nil, err
+		}
+		klog.Infof("apply ConfigMap: [%s/%s] successfully", ns, cmName)
+		return updatedCm, nil
+	}
+
 	cmData := cm.Data
 
 	var updatedCm *corev1.ConfigMap
@@ -15,18 +15,22 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	stderrs "errors"
 	"fmt"
 	"regexp"
 
 	"github.com/dustin/go-humanize"
+	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -53,6 +57,9 @@ var (
 	// backupScheduleControllerKind contains the schema.GroupVersionKind for backupschedule controller type.
 	backupScheduleControllerKind = v1alpha1.SchemeGroupVersion.WithKind("BackupSchedule")
 
+	// restoreScheduleControllerKind contains the schema.GroupVersionKind for restoreschedule controller type.
+	restoreScheduleControllerKind = v1alpha1.SchemeGroupVersion.WithKind("RestoreSchedule")
+
 	// tidbMonitorControllerkind cotnains the schema.GroupVersionKind for TidbMonitor controller type.
 	tidbMonitorControllerkind = v1alpha1.SchemeGroupVersion.WithKind("TidbMonitor")
 
@@ -61,6 +68,12 @@ var (
 
 	// tidbNGMonitoringKind cotnains the schema.GroupVersionKind for TidbNGMonitoring controller type.
 	tidbNGMonitoringKind = v1alpha1.SchemeGroupVersion.WithKind("TidbNGMonitoring")
+
+	// tidbClusterMaintenanceKind cotnains the schema.GroupVersionKind for TidbClusterMaintenance controller type.
+	tidbClusterMaintenanceKind = v1alpha1.SchemeGroupVersion.WithKind("TidbClusterMaintenance")
+
+	// tidbClusterPreflightKind cotnains the schema.GroupVersionKind for TidbClusterPreflight controller type.
+	tidbClusterPreflightKind = v1alpha1.SchemeGroupVersion.WithKind("TidbClusterPreflight")
 )
 
 // RequeueError is used to requeue the item, this error type should't be considered as a real error
@@ -173,6 +186,20 @@ func GetBackupScheduleOwnerRef(bs *v1alpha1.BackupSchedule) metav1.OwnerReferenc
 	}
 }
 
+// GetRestoreScheduleOwnerRef returns RestoreSchedule's OwnerReference
+func GetRestoreScheduleOwnerRef(rs *v1alpha1.RestoreSchedule) metav1.OwnerReference {
+	controller := true
+	blockOwnerDeletion := true
+	return metav1.OwnerReference{
+		APIVersion:         restoreScheduleControllerKind.GroupVersion().String(),
+		Kind:               restoreScheduleControllerKind.Kind,
+		Name:               rs.GetName(),
+		UID:                rs.GetUID(),
+		Controller:         &controller,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}
+}
+
 func GetTiDBMonitorOwnerRef(monitor *v1alpha1.TidbMonitor) metav1.OwnerReference {
 	controller := true
 	blockOwnerDeletion := true
@@ -212,6 +239,32 @@ func GetTiDBNGMonitoringOwnerRef(tngm *v1alpha1.TidbNGMonitoring) metav1.OwnerRe
 	}
 }
 
+func GetTiDBClusterMaintenanceOwnerRef(tcm *v1alpha1.TidbClusterMaintenance) metav1.OwnerReference {
+	controller := true
+	blockOwnerDeletion := true
+	return metav1.OwnerReference{
+		APIVersion:         tidbClusterMaintenanceKind.GroupVersion().String(),
+		Kind:               tidbClusterMaintenanceKind.Kind,
+		Name:               tcm.GetName(),
+		UID:                tcm.GetUID(),
+		Controller:         &controller,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}
+}
+
+func GetTidbClusterPreflightOwnerRef(pf *v1alpha1.TidbClusterPreflight) metav1.OwnerReference {
+	controller := true
+	blockOwnerDeletion := true
+	return metav1.OwnerReference{
+		APIVersion:         tidbClusterPreflightKind.GroupVersion().String(),
+		Kind:               tidbClusterPreflightKind.Kind,
+		Name:               pf.GetName(),
+		UID:                pf.GetUID(),
+		Controller:         &controller,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}
+}
+
 // GetServiceType returns member's service type
 func GetServiceType(services []v1alpha1.Service, serviceName string) corev1.ServiceType {
 	for _, svc := range services {
@@ -255,6 +308,15 @@ func TiKVCapacity(limits corev1.ResourceList) string {
 	return fmt.Sprintf("%dMB", i/humanize.MiByte)
 }
 
+// StoreCapacity returns the capacity of a TiKV or TiFlash store in the format of TiKVCapacity,
+// the override takes precedence over the storage limit
+func StoreCapacity(override *resource.Quantity, limits corev1.ResourceList) string {
+	if override != nil {
+		return TiKVCapacity(corev1.ResourceList{corev1.ResourceStorage: *override})
+	}
+	return TiKVCapacity(limits)
+}
+
 // MemberName return a component member name
 func MemberName(clusterName string, member v1alpha1.MemberType) string {
 	return fmt.Sprintf("%s-%s", clusterName, member)
@@ -290,6 +352,11 @@ func TiCDCMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-ticdc", clusterName)
 }
 
+// DryRunDiffConfigMapName returns the name of the ConfigMap which saves the diffs computed in dry-run mode
+func DryRunDiffConfigMapName(clusterName string) string {
+	return fmt.Sprintf("%s-dry-run", clusterName)
+}
+
 // TiFlashPeerMemberName returns tiflash peer service name
 func TiFlashPeerMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-tiflash-peer", clusterName)
@@ -310,11 +377,26 @@ func TiDBPeerMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-tidb-peer", clusterName)
 }
 
+// TiDBZoneServiceName returns the name of the TiDB service for the zone
+func TiDBZoneServiceName(clusterName, zone string) string {
+	return fmt.Sprintf("%s-tidb-%s", clusterName, zone)
+}
+
 // PumpMemberName returns pump member name
 func PumpMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-pump", clusterName)
 }
 
+// TiProxyMemberName returns tiproxy member name
+func TiProxyMemberName(clusterName string) string {
+	return fmt.Sprintf("%s-tiproxy", clusterName)
+}
+
+// TiProxyPeerMemberName returns tiproxy peer service name
+func TiProxyPeerMemberName(clusterName string) string {
+	return fmt.Sprintf("%s-tiproxy-peer", clusterName)
+}
+
 // TiDBInitializerMemberName returns TiDBInitializer member name
 func TiDBInitializerMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-tidb-initializer", clusterName)
@@ -331,6 +413,11 @@ func DiscoveryMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-discovery", clusterName)
 }
 
+// SQLProbeMemberName returns the name of the SQL probe
+func SQLProbeMemberName(clusterName string) string {
+	return fmt.Sprintf("%s-sql-probe", clusterName)
+}
+
 // DMMasterMemberName returns dm-master member name
 func DMMasterMemberName(clusterName string) string {
 	return fmt.Sprintf("%s-dm-master", clusterName)
@@ -573,6 +660,15 @@ func EmptyClone(obj client.Object) (client.Object, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the kinds not registered in the scheme, e.g. the CRDs of other operators, are
+	// handled as unstructured objects
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		inst := &unstructured.Unstructured{}
+		inst.SetGroupVersionKind(gvk)
+		inst.SetName(meta.GetName())
+		inst.SetNamespace(meta.GetNamespace())
+		return inst, nil
+	}
 	inst, err := scheme.Scheme.New(gvk)
 	if err != nil {
 		return nil, err
@@ -623,3 +719,41 @@ func GuaranteedUpdate(cli client.Client, obj client.Object, updateFunc func() er
 		return cli.Update(context.TODO(), obj)
 	})
 }
+
+// StatusMergePatch returns a JSON merge patch which changes the status of an object from
+// oldStatus to newStatus, the patch only contains the fields changed from oldStatus, so it
+// can be applied to the latest object without overwriting the fields changed by others.
+func StatusMergePatch(oldStatus, newStatus interface{}) ([]byte, error) {
+	oldData, err := json.Marshal(map[string]interface{}{"status": oldStatus})
+	if err != nil {
+		return nil, err
+	}
+	newData, err := json.Marshal(map[string]interface{}{"status": newStatus})
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.CreateMergePatch(oldData, newData)
+}
+
+// MetaPatch returns a JSON patch which sets the labels and annotations of obj as a whole, the
+// resourceVersion of obj is kept in the patch, so that the patch fails with a conflict if the object
+// has been changed since it's read. The Pods and PVCs are patched by it instead of being updated,
+// because the fields not used by tidb-operator are stripped from the cached ones, see stripObject.
+func MetaPatch(obj metav1.Object) ([]byte, error) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	patch := []map[string]interface{}{
+		{"op": "add", "path": "/metadata/labels", "value": labels},
+		{"op": "add", "path": "/metadata/annotations", "value": annotations},
+	}
+	if rv := obj.GetResourceVersion(); rv != "" {
+		patch = append(patch, map[string]interface{}{"op": "add", "path": "/metadata/resourceVersion", "value": rv})
+	}
+	return json.Marshal(patch)
+}
@@ -231,6 +231,18 @@ func TestTiKVCapacity(t *testing.T) {
 	}
 }
 
+func TestStoreCapacity(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	limits := corev1.ResourceList{
+		corev1.ResourceStorage: resource.MustParse("100Gi"),
+	}
+	g.Expect(StoreCapacity(nil, limits)).To(Equal("100GB"))
+	override := resource.MustParse("80Gi")
+	g.Expect(StoreCapacity(&override, limits)).To(Equal("80GB"))
+	g.Expect(StoreCapacity(&override, nil)).To(Equal("80GB"))
+}
+
 func TestPDMemberName(t *testing.T) {
 	g := NewGomegaWithT(t)
 	g.Expect(PDMemberName("demo")).To(Equal("demo-pd"))
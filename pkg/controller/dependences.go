@@ -14,8 +14,10 @@
 package controller
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -29,8 +31,14 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/tiflashapi"
 	"github.com/pingcap/tidb-operator/pkg/tikvapi"
 	utildiscovery "github.com/pingcap/tidb-operator/pkg/util/discovery"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	kubefake "k8s.io/client-go/kubernetes/fake"
@@ -41,15 +49,22 @@ import (
 	extensionslister "k8s.io/client-go/listers/extensions/v1beta1"
 	networklister "k8s.io/client-go/listers/networking/v1"
 	storagelister "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	controllerfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
+// PodMonitorGVR is the resource of PodMonitors of prometheus-operator
+var PodMonitorGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "podmonitors"}
+
 // CLIConfig is used save all configuration read from command line parameters
 type CLIConfig struct {
 	PrintVersion bool
+	// PrintRBAC prints the RBAC objects required by the config and the
+	// enabled features, see RBACRules
+	PrintRBAC bool
 	// The number of workers that are allowed to sync concurrently.
 	// Larger number = more responsive management, but more CPU
 	// (and network) load
@@ -73,6 +88,9 @@ type CLIConfig struct {
 	RenewDeadline         time.Duration
 	RetryPeriod           time.Duration
 	WaitDuration          time.Duration
+	// GracefulShutdownTimeout is the max duration to wait for the in-flight syncs
+	// to finish before releasing the leader lock on exiting
+	GracefulShutdownTimeout time.Duration
 	// ResyncDuration is the resync time of informer
 	ResyncDuration time.Duration
 	// Defines whether tidb operator run in test mode, test mode is
@@ -83,28 +101,62 @@ type CLIConfig struct {
 	// Selector is used to filter CR labels to decide
 	// what resources should be watched and synced by controller
 	Selector string
+	// DryRun makes all TidbClusters reconciled in dry-run mode, in which
+	// the changes are recorded but not applied
+	DryRun bool
+	// NamespaceSelector is used to filter the namespaces whose objects are watched
+	// and synced by controller, it only works if ClusterScoped is true
+	NamespaceSelector string
+	// WatchManagedOnly makes controller only watch the Pods and PVCs managed by
+	// tidb-operator, i.e. with the label app.kubernetes.io/managed-by=tidb-operator
+	WatchManagedOnly bool
+	// SelectiveSyncPeriod enables skipping the sync of the components which are not
+	// changed, they are still synced at least once per period. 0 disables it.
+	SelectiveSyncPeriod time.Duration
+	// OrphanGCPeriod is the period to look for the objects left by the deleted TidbClusters
+	// or the components removed from TidbClusters. 0 disables it.
+	OrphanGCPeriod time.Duration
+	// OrphanGCDelete makes the orphan objects deleted instead of only reported
+	OrphanGCDelete bool
+	// StrictValidation makes the controller validate TidbClusters as the admission webhook does,
+	// for the installations without the webhook. The invalid changes are refused and reported in
+	// the ValidationFailed condition.
+	StrictValidation bool
+	// PrewarmInformers makes the informers started before being elected as the leader, so that
+	// the caches are ready on leader failover and non-leader replicas serve the read-only endpoints
+	PrewarmInformers bool
+	// ConfigFile is the file of flag=value pairs overriding the command line flags, the
+	// dynamic flags in it are reloaded periodically, see DynamicFlags
+	ConfigFile string
+	// ReleaseMetadataHosts is the comma separated hosts from which the release metadata of
+	// spec.upgradePolicy can be read by URL. The URLs of other hosts are refused.
+	ReleaseMetadataHosts string
+
+	// lock protects the dynamic flags changed by reloading ConfigFile
+	lock sync.RWMutex
 }
 
 // DefaultCLIConfig returns the default command line configuration
 func DefaultCLIConfig() *CLIConfig {
 	return &CLIConfig{
-		Workers:                5,
-		ClusterScoped:          true,
-		AutoFailover:           true,
-		PDFailoverPeriod:       5 * time.Minute,
-		TiKVFailoverPeriod:     5 * time.Minute,
-		TiDBFailoverPeriod:     5 * time.Minute,
-		TiFlashFailoverPeriod:  5 * time.Minute,
-		MasterFailoverPeriod:   5 * time.Minute,
-		WorkerFailoverPeriod:   5 * time.Minute,
-		LeaseDuration:          15 * time.Second,
-		RenewDeadline:          10 * time.Second,
-		RetryPeriod:            2 * time.Second,
-		WaitDuration:           5 * time.Second,
-		ResyncDuration:         30 * time.Second,
-		TiDBBackupManagerImage: "pingcap/tidb-backup-manager:latest",
-		TiDBDiscoveryImage:     "pingcap/tidb-operator:latest",
-		Selector:               "",
+		Workers:                 5,
+		ClusterScoped:           true,
+		AutoFailover:            true,
+		PDFailoverPeriod:        5 * time.Minute,
+		TiKVFailoverPeriod:      5 * time.Minute,
+		TiDBFailoverPeriod:      5 * time.Minute,
+		TiFlashFailoverPeriod:   5 * time.Minute,
+		MasterFailoverPeriod:    5 * time.Minute,
+		WorkerFailoverPeriod:    5 * time.Minute,
+		LeaseDuration:           15 * time.Second,
+		RenewDeadline:           10 * time.Second,
+		RetryPeriod:             2 * time.Second,
+		WaitDuration:            5 * time.Second,
+		GracefulShutdownTimeout: 20 * time.Second,
+		ResyncDuration:          30 * time.Second,
+		TiDBBackupManagerImage:  "pingcap/tidb-backup-manager:latest",
+		TiDBDiscoveryImage:      "pingcap/tidb-operator:latest",
+		Selector:                "",
 	}
 }
 
@@ -112,6 +164,7 @@ func DefaultCLIConfig() *CLIConfig {
 func (c *CLIConfig) AddFlag(_ *flag.FlagSet) {
 	flag.BoolVar(&c.PrintVersion, "V", false, "Show version and quit")
 	flag.BoolVar(&c.PrintVersion, "version", false, "Show version and quit")
+	flag.BoolVar(&c.PrintRBAC, "print-rbac", false, "Print the least-privilege Role and ClusterRole required by the flags and features, and quit")
 	flag.IntVar(&c.Workers, "workers", c.Workers, "The number of workers that are allowed to sync concurrently. Larger number = more responsive management, but more CPU (and network) load")
 	flag.BoolVar(&c.ClusterScoped, "cluster-scoped", c.ClusterScoped, "Whether tidb-operator should manage kubernetes cluster wide TiDB Clusters")
 	flag.BoolVar(&c.ClusterPermissionNode, "cluster-permission-node", c.ClusterPermissionNode, "Whether tidb-operator should have node permissions even if cluster-scoped is false")
@@ -125,11 +178,22 @@ func (c *CLIConfig) AddFlag(_ *flag.FlagSet) {
 	flag.DurationVar(&c.MasterFailoverPeriod, "dm-master-failover-period", c.MasterFailoverPeriod, "dm-master failover period")
 	flag.DurationVar(&c.WorkerFailoverPeriod, "dm-worker-failover-period", c.WorkerFailoverPeriod, "dm-worker failover period")
 	flag.DurationVar(&c.ResyncDuration, "resync-duration", c.ResyncDuration, "Resync time of informer")
+	flag.DurationVar(&c.GracefulShutdownTimeout, "graceful-shutdown-timeout", c.GracefulShutdownTimeout, "The max duration to wait for the in-flight syncs to finish before releasing the leader lock on exiting")
 	flag.BoolVar(&c.TestMode, "test-mode", false, "whether tidb-operator run in test mode")
 	flag.StringVar(&c.TiDBBackupManagerImage, "tidb-backup-manager-image", c.TiDBBackupManagerImage, "The image of backup manager tool")
 	// TODO: actually we just want to use the same image with tidb-controller-manager, but DownwardAPI cannot get image ID, see if there is any better solution
 	flag.StringVar(&c.TiDBDiscoveryImage, "tidb-discovery-image", c.TiDBDiscoveryImage, "The image of the tidb discovery service")
 	flag.StringVar(&c.Selector, "selector", c.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='")
+	flag.StringVar(&c.NamespaceSelector, "namespace-selector", c.NamespaceSelector, "Selector (label query) to filter the namespaces whose objects are watched and synced, only works if cluster-scoped is true")
+	flag.BoolVar(&c.WatchManagedOnly, "watch-managed-only", c.WatchManagedOnly, "Whether to only watch the Pods and PVCs managed by tidb-operator to reduce the memory usage")
+	flag.BoolVar(&c.DryRun, "dry-run", c.DryRun, "Whether to reconcile all TidbClusters in dry-run mode, in which the changes are recorded in status, events and ConfigMaps but not applied")
+	flag.DurationVar(&c.SelectiveSyncPeriod, "selective-sync-period", c.SelectiveSyncPeriod, "If positive, TiFlash, TiCDC and Pump are synced only if their spec or relevant status is changed, or at least once per period. 0 means syncing all components every time")
+	flag.DurationVar(&c.OrphanGCPeriod, "orphan-gc-period", c.OrphanGCPeriod, "If positive, the Services, ConfigMaps, StatefulSets, PVCs and Secrets left by the deleted TidbClusters or their removed components are looked for once per period. 0 disables it")
+	flag.BoolVar(&c.StrictValidation, "strict-validation", c.StrictValidation, "Whether to validate TidbClusters as the admission webhook does and refuse to reconcile the invalid changes, for the installations without the webhook")
+	flag.BoolVar(&c.PrewarmInformers, "prewarm-informers", c.PrewarmInformers, "Whether to start the informers before being elected as the leader, so that the new leader doesn't wait for the caches to be built and non-leader replicas serve the read-only endpoints, at the cost of the memory of the caches on all replicas")
+	flag.StringVar(&c.ConfigFile, "config-file", c.ConfigFile, "The file of flag=value pairs separated by new lines, which overrides the command line flags. The changes of the dynamic flags in it, i.e. workers, resync-duration, auto-failover, the failover periods, orphan-gc-delete and the dynamic features, take effect without restarting")
+	flag.StringVar(&c.ReleaseMetadataHosts, "release-metadata-hosts", c.ReleaseMetadataHosts, "The comma separated hosts from which the release metadata of spec.upgradePolicy can be read by HTTPS URL. Empty means only the release metadata in ConfigMaps can be used")
+	flag.BoolVar(&c.OrphanGCDelete, "orphan-gc-delete", c.OrphanGCDelete, "Whether to delete the orphan objects found by orphan-gc-period instead of only reporting them. PVCs are only deleted if enablePVReclaim of their TidbCluster is true")
 
 	// see https://pkg.go.dev/k8s.io/client-go/tools/leaderelection#LeaderElectionConfig for the config
 	flag.DurationVar(&c.LeaseDuration, "leader-lease-duration", c.LeaseDuration, "leader-lease-duration is the duration that non-leader candidates will wait to force acquire leadership")
@@ -137,6 +201,60 @@ func (c *CLIConfig) AddFlag(_ *flag.FlagSet) {
 	flag.DurationVar(&c.RetryPeriod, "leader-retry-period", c.RetryPeriod, "leader-retry-period is the duration the LeaderElector clients should wait between tries of actions")
 }
 
+// IsAutoFailover returns whether auto failover is enabled, it may be changed by reloading the config file
+func (c *CLIConfig) IsAutoFailover() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.AutoFailover
+}
+
+// GetFailoverPeriod returns the failover period of the member type, it may be changed by reloading
+// the config file
+func (c *CLIConfig) GetFailoverPeriod(memberType v1alpha1.MemberType) time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		return c.PDFailoverPeriod
+	case v1alpha1.TiKVMemberType:
+		return c.TiKVFailoverPeriod
+	case v1alpha1.TiDBMemberType:
+		return c.TiDBFailoverPeriod
+	case v1alpha1.TiFlashMemberType:
+		return c.TiFlashFailoverPeriod
+	case v1alpha1.DMMasterMemberType:
+		return c.MasterFailoverPeriod
+	case v1alpha1.DMWorkerMemberType:
+		return c.WorkerFailoverPeriod
+	default:
+		return 0
+	}
+}
+
+// GetWorkers returns the number of the workers of each controller, it may be changed by reloading the
+// config file
+func (c *CLIConfig) GetWorkers() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.Workers
+}
+
+// GetResyncDuration returns the period the TidbClusters are synced at least once, it may be changed by
+// reloading the config file. The informers keep the resync duration on starting.
+func (c *CLIConfig) GetResyncDuration() time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.ResyncDuration
+}
+
+// IsOrphanGCDelete returns whether the orphan objects are deleted, it may be changed by reloading
+// the config file
+func (c *CLIConfig) IsOrphanGCDelete() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.OrphanGCDelete
+}
+
 // HasNodePermission returns whether the user has permission for node operations.
 func (c *CLIConfig) HasNodePermission() bool {
 	return c.ClusterScoped || c.ClusterPermissionNode
@@ -189,37 +307,60 @@ type Dependencies struct {
 	KubeInformerFactory            kubeinformers.SharedInformerFactory
 	LabelFilterKubeInformerFactory kubeinformers.SharedInformerFactory
 	Recorder                       record.EventRecorder
+	// NamespaceSelector selects the namespaces to sync, it's nil if all namespaces are synced
+	NamespaceSelector *NamespaceSelector
+	// Namespace is the namespace tidb-controller-manager runs in
+	Namespace string
+	// PodMonitorSupported indicates whether the PodMonitor CRD of prometheus-operator is installed
+	PodMonitorSupported bool
+	// DynamicInformerFactory watches the custom resources not registered in the scheme, e.g. PodMonitors,
+	// it's nil if none of them is installed
+	DynamicInformerFactory dynamicinformer.DynamicSharedInformerFactory
+	// DNSEndpointSupported indicates whether the DNSEndpoint CRD of external-dns is installed
+	DNSEndpointSupported bool
+	// NativeSidecarSupported indicates whether the api-server enables native sidecar containers,
+	// i.e. the init containers whose restartPolicy is Always
+	NativeSidecarSupported bool
 
 	// Listers
-	ServiceLister               corelisterv1.ServiceLister
-	EndpointLister              corelisterv1.EndpointsLister
-	PVCLister                   corelisterv1.PersistentVolumeClaimLister
-	PVLister                    corelisterv1.PersistentVolumeLister
-	PodLister                   corelisterv1.PodLister
-	NodeLister                  corelisterv1.NodeLister
-	SecretLister                corelisterv1.SecretLister
-	ConfigMapLister             corelisterv1.ConfigMapLister
-	StatefulSetLister           appslisters.StatefulSetLister
-	DeploymentLister            appslisters.DeploymentLister
-	JobLister                   batchlisters.JobLister
-	IngressLister               networklister.IngressLister
-	IngressV1Beta1Lister        extensionslister.IngressLister // in order to be compatibility with kubernetes which less than v1.19
-	StorageClassLister          storagelister.StorageClassLister
-	TiDBClusterLister           listers.TidbClusterLister
-	TiDBClusterAutoScalerLister listers.TidbClusterAutoScalerLister
-	DMClusterLister             listers.DMClusterLister
-	BackupLister                listers.BackupLister
-	RestoreLister               listers.RestoreLister
-	BackupScheduleLister        listers.BackupScheduleLister
-	TiDBInitializerLister       listers.TidbInitializerLister
-	TiDBMonitorLister           listers.TidbMonitorLister
-	TiDBNGMonitoringLister      listers.TidbNGMonitoringLister
+	ServiceLister                corelisterv1.ServiceLister
+	EndpointLister               corelisterv1.EndpointsLister
+	PVCLister                    corelisterv1.PersistentVolumeClaimLister
+	PVLister                     corelisterv1.PersistentVolumeLister // nil if there is no permission for persistent volumes
+	PodLister                    corelisterv1.PodLister
+	NodeLister                   corelisterv1.NodeLister // nil if there is no permission for nodes
+	SecretLister                 corelisterv1.SecretLister
+	ConfigMapLister              corelisterv1.ConfigMapLister
+	ResourceQuotaLister          corelisterv1.ResourceQuotaLister
+	LimitRangeLister             corelisterv1.LimitRangeLister
+	StatefulSetLister            appslisters.StatefulSetLister
+	DeploymentLister             appslisters.DeploymentLister
+	JobLister                    batchlisters.JobLister
+	IngressLister                networklister.IngressLister
+	IngressV1Beta1Lister         extensionslister.IngressLister   // in order to be compatibility with kubernetes which less than v1.19
+	StorageClassLister           storagelister.StorageClassLister // nil if there is no permission for storage classes
+	TiDBClusterLister            listers.TidbClusterLister
+	TiDBClusterAutoScalerLister  listers.TidbClusterAutoScalerLister
+	DMClusterLister              listers.DMClusterLister
+	BackupLister                 listers.BackupLister
+	RestoreLister                listers.RestoreLister
+	BackupScheduleLister         listers.BackupScheduleLister
+	RestoreScheduleLister        listers.RestoreScheduleLister
+	TiDBInitializerLister        listers.TidbInitializerLister
+	TiDBMonitorLister            listers.TidbMonitorLister
+	TiDBNGMonitoringLister       listers.TidbNGMonitoringLister
+	TiDBClusterMaintenanceLister listers.TidbClusterMaintenanceLister
+	TiDBClusterOperationLister   listers.TidbClusterOperationLister
+	TiDBClusterPreflightLister   listers.TidbClusterPreflightLister
+	NodeMaintenanceLister        listers.NodeMaintenanceLister
+	PodMonitorLister             cache.GenericLister // nil if PodMonitorSupported is false
 
 	// Controls
 	Controls
 }
 
 func newRealControls(
+	ctx context.Context,
 	cliCfg *CLIConfig,
 	clientset versioned.Interface,
 	kubeClientset kubernetes.Interface,
@@ -230,10 +371,10 @@ func newRealControls(
 	// Shared variables to construct `Dependencies` and some of its fields
 	var (
 		secretLister      = kubeInformerFactory.Core().V1().Secrets().Lister()
-		pdControl         = pdapi.NewDefaultPDControl(secretLister)
-		tikvControl       = tikvapi.NewDefaultTiKVControl(secretLister)
-		tiflashControl    = tiflashapi.NewDefaultTiFlashControl(secretLister)
-		masterControl     = dmapi.NewDefaultMasterControl(secretLister)
+		pdControl         = pdapi.NewDefaultPDControl(ctx, secretLister)
+		tikvControl       = tikvapi.NewDefaultTiKVControl(ctx, secretLister)
+		tiflashControl    = tiflashapi.NewDefaultTiFlashControl(ctx, secretLister)
+		masterControl     = dmapi.NewDefaultMasterControl(ctx, secretLister)
 		genericCtrl       = NewRealGenericControl(genericCli, recorder)
 		tidbClusterLister = informerFactory.Pingcap().V1alpha1().TidbClusters().Lister()
 		dmClusterLister   = informerFactory.Pingcap().V1alpha1().DMClusters().Lister()
@@ -256,7 +397,7 @@ func newRealControls(
 		PVCControl:         NewRealPVCControl(kubeClientset, recorder, pvcLister),
 		GeneralPVCControl:  NewRealGeneralPVCControl(kubeClientset, recorder),
 		GenericControl:     genericCtrl,
-		PodControl:         NewRealPodControl(kubeClientset, pdapi.NewDefaultPDControl(secretLister), podLister, recorder),
+		PodControl:         NewRealPodControl(kubeClientset, pdControl, podLister, recorder),
 		TypedControl:       NewTypedControl(genericCtrl),
 		PDControl:          pdControl,
 		TiKVControl:        tikvControl,
@@ -264,8 +405,8 @@ func newRealControls(
 		DMMasterControl:    masterControl,
 		TiDBClusterControl: NewRealTidbClusterControl(clientset, tidbClusterLister, recorder),
 		DMClusterControl:   NewRealDMClusterControl(clientset, dmClusterLister, recorder),
-		CDCControl:         NewDefaultTiCDCControl(secretLister),
-		TiDBControl:        NewDefaultTiDBControl(secretLister),
+		CDCControl:         NewDefaultTiCDCControl(ctx, secretLister),
+		TiDBControl:        NewDefaultTiDBControl(ctx, secretLister),
 		BackupControl:      NewRealBackupControl(clientset, recorder),
 		SecretControl:      NewRealSecretControl(kubeClientset, secretLister, recorder),
 	}
@@ -314,6 +455,23 @@ func newDependencies(
 		ingv1beta1Lister = kubeInformerFactory.Extensions().V1beta1().Ingresses().Lister()
 	}
 
+	podMonitorSupported, err := utildiscovery.IsAPIGroupVersionResourceSupported(kubeClientset.Discovery(), "monitoring.coreos.com/v1", "podmonitors")
+	if err != nil {
+		klog.Warningf("failed to check resource monitoring.coreos.com/v1/podmonitors, skip generating PodMonitors: %s", err)
+		podMonitorSupported = false
+	}
+	dnsEndpointSupported, err := utildiscovery.IsAPIGroupVersionResourceSupported(kubeClientset.Discovery(), "externaldns.k8s.io/v1alpha1", "dnsendpoints")
+	if err != nil {
+		klog.Warningf("failed to check resource externaldns.k8s.io/v1alpha1/dnsendpoints, skip generating DNSEndpoints: %s", err)
+		dnsEndpointSupported = false
+	}
+	// native sidecar containers are alpha and disabled by default in v1.28, which can't be detected
+	nativeSidecarSupported, err := utildiscovery.IsServerVersionAtLeast(kubeClientset.Discovery(), "v1.29.0")
+	if err != nil {
+		klog.Warningf("failed to get the version of api-server, skip rendering native sidecar containers: %s", err)
+		nativeSidecarSupported = false
+	}
+
 	return &Dependencies{
 		CLIConfig:                      cliCfg,
 		InformerFactory:                informerFactory,
@@ -323,36 +481,47 @@ func newDependencies(
 		KubeInformerFactory:            kubeInformerFactory,
 		LabelFilterKubeInformerFactory: labelFilterKubeInformerFactory,
 		Recorder:                       recorder,
+		PodMonitorSupported:            podMonitorSupported,
+		DNSEndpointSupported:           dnsEndpointSupported,
+		NativeSidecarSupported:         nativeSidecarSupported,
 
 		// Listers
-		ServiceLister:               kubeInformerFactory.Core().V1().Services().Lister(),
-		EndpointLister:              kubeInformerFactory.Core().V1().Endpoints().Lister(),
-		PVCLister:                   kubeInformerFactory.Core().V1().PersistentVolumeClaims().Lister(),
-		PVLister:                    pvLister,
-		PodLister:                   kubeInformerFactory.Core().V1().Pods().Lister(),
-		NodeLister:                  nodeLister,
-		SecretLister:                kubeInformerFactory.Core().V1().Secrets().Lister(),
-		ConfigMapLister:             labelFilterKubeInformerFactory.Core().V1().ConfigMaps().Lister(),
-		StatefulSetLister:           kubeInformerFactory.Apps().V1().StatefulSets().Lister(),
-		DeploymentLister:            kubeInformerFactory.Apps().V1().Deployments().Lister(),
-		StorageClassLister:          scLister,
-		JobLister:                   kubeInformerFactory.Batch().V1().Jobs().Lister(),
-		IngressLister:               ingLister,
-		IngressV1Beta1Lister:        ingv1beta1Lister,
-		TiDBClusterLister:           informerFactory.Pingcap().V1alpha1().TidbClusters().Lister(),
-		TiDBClusterAutoScalerLister: informerFactory.Pingcap().V1alpha1().TidbClusterAutoScalers().Lister(),
-		DMClusterLister:             informerFactory.Pingcap().V1alpha1().DMClusters().Lister(),
-		BackupLister:                informerFactory.Pingcap().V1alpha1().Backups().Lister(),
-		RestoreLister:               informerFactory.Pingcap().V1alpha1().Restores().Lister(),
-		BackupScheduleLister:        informerFactory.Pingcap().V1alpha1().BackupSchedules().Lister(),
-		TiDBInitializerLister:       informerFactory.Pingcap().V1alpha1().TidbInitializers().Lister(),
-		TiDBMonitorLister:           informerFactory.Pingcap().V1alpha1().TidbMonitors().Lister(),
-		TiDBNGMonitoringLister:      informerFactory.Pingcap().V1alpha1().TidbNGMonitorings().Lister(),
+		ServiceLister:                kubeInformerFactory.Core().V1().Services().Lister(),
+		EndpointLister:               kubeInformerFactory.Core().V1().Endpoints().Lister(),
+		PVCLister:                    kubeInformerFactory.Core().V1().PersistentVolumeClaims().Lister(),
+		PVLister:                     pvLister,
+		PodLister:                    kubeInformerFactory.Core().V1().Pods().Lister(),
+		NodeLister:                   nodeLister,
+		SecretLister:                 kubeInformerFactory.Core().V1().Secrets().Lister(),
+		ConfigMapLister:              labelFilterKubeInformerFactory.Core().V1().ConfigMaps().Lister(),
+		ResourceQuotaLister:          labelFilterKubeInformerFactory.Core().V1().ResourceQuotas().Lister(),
+		LimitRangeLister:             labelFilterKubeInformerFactory.Core().V1().LimitRanges().Lister(),
+		StatefulSetLister:            kubeInformerFactory.Apps().V1().StatefulSets().Lister(),
+		DeploymentLister:             kubeInformerFactory.Apps().V1().Deployments().Lister(),
+		StorageClassLister:           scLister,
+		JobLister:                    kubeInformerFactory.Batch().V1().Jobs().Lister(),
+		IngressLister:                ingLister,
+		IngressV1Beta1Lister:         ingv1beta1Lister,
+		TiDBClusterLister:            informerFactory.Pingcap().V1alpha1().TidbClusters().Lister(),
+		TiDBClusterAutoScalerLister:  informerFactory.Pingcap().V1alpha1().TidbClusterAutoScalers().Lister(),
+		DMClusterLister:              informerFactory.Pingcap().V1alpha1().DMClusters().Lister(),
+		BackupLister:                 informerFactory.Pingcap().V1alpha1().Backups().Lister(),
+		RestoreLister:                informerFactory.Pingcap().V1alpha1().Restores().Lister(),
+		BackupScheduleLister:         informerFactory.Pingcap().V1alpha1().BackupSchedules().Lister(),
+		RestoreScheduleLister:        informerFactory.Pingcap().V1alpha1().RestoreSchedules().Lister(),
+		TiDBInitializerLister:        informerFactory.Pingcap().V1alpha1().TidbInitializers().Lister(),
+		TiDBMonitorLister:            informerFactory.Pingcap().V1alpha1().TidbMonitors().Lister(),
+		TiDBNGMonitoringLister:       informerFactory.Pingcap().V1alpha1().TidbNGMonitorings().Lister(),
+		TiDBClusterMaintenanceLister: informerFactory.Pingcap().V1alpha1().TidbClusterMaintenances().Lister(),
+		TiDBClusterOperationLister:   informerFactory.Pingcap().V1alpha1().TidbClusterOperations().Lister(),
+		TiDBClusterPreflightLister:   informerFactory.Pingcap().V1alpha1().TidbClusterPreflights().Lister(),
+		NodeMaintenanceLister:        informerFactory.Pingcap().V1alpha1().NodeMaintenances().Lister(),
 	}, nil
 }
 
-// NewDependencies is used to construct the dependencies
-func NewDependencies(ns string, cliCfg *CLIConfig, clientset versioned.Interface, kubeClientset kubernetes.Interface, genericCli client.Client) (*Dependencies, error) {
+// NewDependencies is used to construct the dependencies, the requests sent by the controls to
+// the clusters are canceled once ctx is done
+func NewDependencies(ctx context.Context, ns string, cliCfg *CLIConfig, clientset versioned.Interface, kubeClientset kubernetes.Interface, genericCli client.Client, dynamicCli dynamic.Interface) (*Dependencies, error) {
 	var (
 		options     []informers.SharedInformerOption
 		kubeoptions []kubeinformers.SharedInformerOption
@@ -361,15 +530,15 @@ func NewDependencies(ns string, cliCfg *CLIConfig, clientset versioned.Interface
 		options = append(options, informers.WithNamespace(ns))
 		kubeoptions = append(kubeoptions, kubeinformers.WithNamespace(ns))
 	}
-	tweakListOptionsFunc := func(options *metav1.ListOptions) {
+	managedByTweakListOptionsFunc := func(options *metav1.ListOptions) {
 		if len(options.LabelSelector) > 0 {
 			options.LabelSelector += ",app.kubernetes.io/managed-by=tidb-operator"
 		} else {
 			options.LabelSelector = "app.kubernetes.io/managed-by=tidb-operator"
 		}
 	}
-	labelKubeOptions := append(kubeoptions, kubeinformers.WithTweakListOptions(tweakListOptionsFunc))
-	tweakListOptionsFunc = func(options *metav1.ListOptions) {
+	labelKubeOptions := append(kubeoptions, kubeinformers.WithTweakListOptions(managedByTweakListOptionsFunc))
+	tweakListOptionsFunc := func(options *metav1.ListOptions) {
 		if len(cliCfg.Selector) > 0 {
 			options.LabelSelector = cliCfg.Selector
 		}
@@ -380,6 +549,55 @@ func NewDependencies(ns string, cliCfg *CLIConfig, clientset versioned.Interface
 	informerFactory := informers.NewSharedInformerFactoryWithOptions(clientset, cliCfg.ResyncDuration, options...)
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClientset, cliCfg.ResyncDuration, kubeoptions...)
 	labelFilterKubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClientset, cliCfg.ResyncDuration, labelKubeOptions...)
+	informerNamespace := metav1.NamespaceAll
+	if !cliCfg.ClusterScoped {
+		informerNamespace = ns
+	}
+	var nsSelector *NamespaceSelector
+	if cliCfg.ClusterScoped && len(cliCfg.NamespaceSelector) > 0 {
+		selector, err := labels.Parse(cliCfg.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse namespace selector %q: %v", cliCfg.NamespaceSelector, err)
+		}
+		nsSelector = NewNamespaceSelector(kubeInformerFactory.Core().V1().Namespaces(), selector)
+		// the other namespaced resources are scoped by the selector too
+		core, apps, batch := kubeClientset.CoreV1().RESTClient(), kubeClientset.AppsV1().RESTClient(), kubeClientset.BatchV1().RESTClient()
+		registerScopedKubeInformers(kubeInformerFactory, []scopedResource{
+			{&corev1.Service{}, core, "services"},
+			{&corev1.Endpoints{}, core, "endpoints"},
+			{&corev1.Secret{}, core, "secrets"},
+			{&appsv1.StatefulSet{}, apps, "statefulsets"},
+			{&appsv1.Deployment{}, apps, "deployments"},
+			{&batchv1.Job{}, batch, "jobs"},
+		}, informerNamespace, nil, nsSelector)
+		registerScopedKubeInformers(labelFilterKubeInformerFactory, []scopedResource{
+			{&corev1.ConfigMap{}, core, "configmaps"},
+			{&corev1.ResourceQuota{}, core, "resourcequotas"},
+			{&corev1.LimitRange{}, core, "limitranges"},
+		}, informerNamespace, managedByTweakListOptionsFunc, nsSelector)
+		pingcap := clientset.PingcapV1alpha1().RESTClient()
+		registerScopedInformers(informerFactory, []scopedResource{
+			{&v1alpha1.TidbCluster{}, pingcap, "tidbclusters"},
+			{&v1alpha1.TidbClusterAutoScaler{}, pingcap, "tidbclusterautoscalers"},
+			{&v1alpha1.DMCluster{}, pingcap, "dmclusters"},
+			{&v1alpha1.Backup{}, pingcap, "backups"},
+			{&v1alpha1.Restore{}, pingcap, "restores"},
+			{&v1alpha1.BackupSchedule{}, pingcap, "backupschedules"},
+			{&v1alpha1.RestoreSchedule{}, pingcap, "restoreschedules"},
+			{&v1alpha1.TidbInitializer{}, pingcap, "tidbinitializers"},
+			{&v1alpha1.TidbMonitor{}, pingcap, "tidbmonitors"},
+			{&v1alpha1.TidbNGMonitoring{}, pingcap, "tidbngmonitorings"},
+			{&v1alpha1.TidbClusterMaintenance{}, pingcap, "tidbclustermaintenances"},
+			{&v1alpha1.TidbClusterOperation{}, pingcap, "tidbclusteroperations"},
+			{&v1alpha1.TidbClusterPreflight{}, pingcap, "tidbclusterpreflights"},
+			{&v1alpha1.NodeMaintenance{}, pingcap, "nodemaintenances"},
+		}, informerNamespace, tweakListOptionsFunc, nsSelector)
+	}
+	var podTweakListOptionsFunc func(*metav1.ListOptions)
+	if cliCfg.WatchManagedOnly {
+		podTweakListOptionsFunc = managedByTweakListOptionsFunc
+	}
+	registerStrippedInformers(kubeInformerFactory, informerNamespace, podTweakListOptionsFunc, nsSelector)
 
 	// Initialize the event recorder
 	eventBroadcaster := record.NewBroadcasterWithCorrelatorOptions(record.CorrelatorOptions{QPS: 1})
@@ -391,7 +609,14 @@ func NewDependencies(ns string, cliCfg *CLIConfig, clientset versioned.Interface
 	if err != nil {
 		return nil, err
 	}
-	deps.Controls = newRealControls(cliCfg, clientset, kubeClientset, genericCli, informerFactory, kubeInformerFactory, recorder)
+	deps.NamespaceSelector = nsSelector
+	deps.Namespace = ns
+	if deps.PodMonitorSupported {
+		// only the PodMonitors generated by tidb-operator are watched
+		deps.DynamicInformerFactory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicCli, cliCfg.ResyncDuration, informerNamespace, managedByTweakListOptionsFunc)
+		deps.PodMonitorLister = deps.DynamicInformerFactory.ForResource(PodMonitorGVR).Lister()
+	}
+	deps.Controls = newRealControls(ctx, cliCfg, clientset, kubeClientset, genericCli, informerFactory, kubeInformerFactory, recorder)
 	return deps, nil
 }
 
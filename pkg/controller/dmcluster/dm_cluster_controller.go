@@ -23,6 +23,7 @@ import (
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
 	"github.com/pingcap/tidb-operator/pkg/manager/suspender"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -74,7 +75,13 @@ func NewController(deps *controller.Dependencies) *Controller {
 		UpdateFunc: func(old, cur interface{}) {
 			c.enqueueDMCluster(cur)
 		},
-		DeleteFunc: c.enqueueDMCluster,
+		DeleteFunc: func(obj interface{}) {
+			// the limiter of the requests to the deleted cluster is released
+			if key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
+				httputil.ForgetCluster(key)
+			}
+			c.enqueueDMCluster(obj)
+		},
 	})
 	statefulsetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.addStatefulSet,
@@ -94,17 +101,13 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	klog.Info("Starting dmcluster controller")
 	defer klog.Info("Shutting down dmcluster controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
+	// the number of the workers follows the workers flag reloaded from the config file
+	wg := controller.RunWorkers(workers, c.deps.CLIConfig, c.processNextWorkItem, stopCh)
 
 	<-stopCh
-}
-
-// worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
+	// wait for the in-flight syncs to finish, so that no operation is left half-done
+	c.queue.ShutDown()
+	wg.Wait()
 }
 
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
@@ -139,6 +142,10 @@ func (c *Controller) sync(key string) error {
 	if err != nil {
 		return err
 	}
+	if !c.deps.NamespaceSelector.Selected(ns) {
+		klog.V(4).Infof("DMCluster %q is not in the selected namespaces, skip syncing", key)
+		return nil
+	}
 	dc, err := c.deps.DMClusterLister.DMClusters(ns).Get(name)
 	if errors.IsNotFound(err) {
 		klog.Infof("DMCluster has been deleted %v", key)
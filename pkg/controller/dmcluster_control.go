@@ -21,7 +21,9 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	tcinformers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -52,6 +54,10 @@ func NewRealDMClusterControl(cli versioned.Interface,
 }
 
 func (c *realDMClusterControl) UpdateDMCluster(dc *v1alpha1.DMCluster, newStatus *v1alpha1.DMClusterStatus, oldStatus *v1alpha1.DMClusterStatus) (*v1alpha1.DMCluster, error) {
+	if c.onlyStatusChanged(dc) {
+		return c.patchDMClusterStatus(dc, newStatus, oldStatus)
+	}
+
 	ns := dc.GetNamespace()
 	dcName := dc.GetName()
 
@@ -84,6 +90,42 @@ func (c *realDMClusterControl) UpdateDMCluster(dc *v1alpha1.DMCluster, newStatus
 	return updateDC, err
 }
 
+// onlyStatusChanged returns whether only the status of dc is changed compared with the cached one
+func (c *realDMClusterControl) onlyStatusChanged(dc *v1alpha1.DMCluster) bool {
+	if c.dcLister == nil {
+		return false
+	}
+	cached, err := c.dcLister.DMClusters(dc.GetNamespace()).Get(dc.GetName())
+	if err != nil {
+		return false
+	}
+	return cached.ResourceVersion == dc.ResourceVersion &&
+		apiequality.Semantic.DeepEqual(cached.Spec, dc.Spec) &&
+		apiequality.Semantic.DeepEqual(cached.Labels, dc.Labels) &&
+		apiequality.Semantic.DeepEqual(cached.Annotations, dc.Annotations)
+}
+
+// patchDMClusterStatus patches the changes from oldStatus to newStatus instead of updating the whole
+// DMCluster, so that the fields changed by others are not overwritten. The patch has no resourceVersion
+// precondition, because it only contains the fields changed by this sync, which are expected to be
+// set to newStatus whatever the latest DMCluster is.
+func (c *realDMClusterControl) patchDMClusterStatus(dc *v1alpha1.DMCluster, newStatus, oldStatus *v1alpha1.DMClusterStatus) (*v1alpha1.DMCluster, error) {
+	ns := dc.GetNamespace()
+	dcName := dc.GetName()
+
+	data, err := StatusMergePatch(oldStatus, newStatus)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := c.cli.PingcapV1alpha1().DMClusters(ns).Patch(context.TODO(), dcName, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		klog.Errorf("failed to patch DMCluster: [%s/%s] status, error: %v", ns, dcName, err)
+		return nil, err
+	}
+	klog.V(4).Infof("DMCluster: [%s/%s] status patched successfully", ns, dcName)
+	return patched, nil
+}
+
 // FakeDMClusterControl is a fake DMClusterControlInterface
 type FakeDMClusterControl struct {
 	DcLister               listers.DMClusterLister
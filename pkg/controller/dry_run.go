@@ -0,0 +1,571 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
+
+	"github.com/google/go-cmp/cmp"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ChangeActionCreate means the object is created
+	ChangeActionCreate = "Create"
+	// ChangeActionUpdate means the object is updated
+	ChangeActionUpdate = "Update"
+	// ChangeActionDelete means the object is deleted
+	ChangeActionDelete = "Delete"
+)
+
+type dryRunRecord struct {
+	changes []v1alpha1.DryRunChange
+	diffs   []string
+}
+
+// DryRunRecorder collects the changes which are skipped in dry-run mode,
+// the changes are grouped by the controller object.
+type DryRunRecorder struct {
+	lock    sync.Mutex
+	records map[string]*dryRunRecord
+}
+
+// NewDryRunRecorder returns an empty DryRunRecorder
+func NewDryRunRecorder() *DryRunRecorder {
+	return &DryRunRecorder{records: map[string]*dryRunRecord{}}
+}
+
+// Record records a change of the controller in namespace ns
+func (r *DryRunRecorder) Record(ns, controllerName string, change v1alpha1.DryRunChange, diff string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	klog.Infof("dry-run: %s/%s would %s %s %s", ns, controllerName, change.Action, change.Kind, change.Name)
+	key := fmt.Sprintf("%s/%s", ns, controllerName)
+	rec, ok := r.records[key]
+	if !ok {
+		rec = &dryRunRecord{}
+		r.records[key] = rec
+	}
+	rec.changes = append(rec.changes, change)
+	if diff != "" {
+		rec.diffs = append(rec.diffs, fmt.Sprintf("%s %s %s:\n%s", change.Action, change.Kind, change.Name, diff))
+	}
+}
+
+// Take returns the changes and the diffs recorded for the controller and
+// resets them
+func (r *DryRunRecorder) Take(ns, controllerName string) ([]v1alpha1.DryRunChange, string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := fmt.Sprintf("%s/%s", ns, controllerName)
+	rec, ok := r.records[key]
+	if !ok {
+		return nil, ""
+	}
+	delete(r.records, key)
+	return rec.changes, strings.Join(rec.diffs, "\n")
+}
+
+func (r *DryRunRecorder) recordFor(controller runtime.Object, obj runtime.Object, action string, diff string) {
+	accessor, err := meta.Accessor(controller)
+	if err != nil {
+		klog.Errorf("dry-run: failed to access the controller %T: %v", controller, err)
+		return
+	}
+	name := ""
+	if objAccessor, err := meta.Accessor(obj); err == nil {
+		name = objAccessor.GetName()
+	}
+	r.Record(accessor.GetNamespace(), accessor.GetName(), v1alpha1.DryRunChange{
+		Kind:   kindOf(obj),
+		Name:   name,
+		Action: action,
+	}, diff)
+}
+
+func kindOf(obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
+
+// NewDryRunDependencies returns a copy of deps whose controls only record
+// the changes to recorder instead of applying them, the reads are still
+// served by the listers and the real controls.
+func NewDryRunDependencies(deps *Dependencies, recorder *DryRunRecorder) *Dependencies {
+	dryRunDeps := *deps
+	genericControl := &dryRunGenericControl{GenericControlInterface: deps.GenericControl, recorder: recorder}
+
+	dryRunDeps.StatefulSetControl = &dryRunStatefulSetControl{setLister: deps.StatefulSetLister, recorder: recorder}
+	dryRunDeps.ServiceControl = &dryRunServiceControl{svcLister: deps.ServiceLister, recorder: recorder}
+	dryRunDeps.ConfigMapControl = &dryRunConfigMapControl{ConfigMapControlInterface: deps.ConfigMapControl, recorder: recorder}
+	dryRunDeps.PVCControl = &dryRunPVCControl{PVCControlInterface: deps.PVCControl, recorder: recorder}
+	dryRunDeps.PVControl = &dryRunPVControl{PVControlInterface: deps.PVControl, recorder: recorder}
+	dryRunDeps.PodControl = &dryRunPodControl{podLister: deps.PodLister, recorder: recorder}
+	dryRunDeps.GenericControl = genericControl
+	dryRunDeps.TypedControl = NewTypedControl(genericControl)
+	dryRunDeps.TiDBClusterControl = &dryRunTidbClusterControl{recorder: recorder}
+	dryRunDeps.CDCControl = &dryRunTiCDCControl{TiCDCControlInterface: deps.CDCControl, recorder: recorder}
+	dryRunDeps.PDControl = &dryRunPDControl{PDControlInterface: deps.PDControl, recorder: recorder}
+	dryRunDeps.TiKVControl = &dryRunTiKVControl{TiKVControlInterface: deps.TiKVControl, recorder: recorder}
+	return &dryRunDeps
+}
+
+type dryRunStatefulSetControl struct {
+	setLister appslisters.StatefulSetLister
+	recorder  *DryRunRecorder
+}
+
+func (c *dryRunStatefulSetControl) CreateStatefulSet(controller runtime.Object, set *apps.StatefulSet) error {
+	c.recorder.recordFor(controller, set, ChangeActionCreate, "")
+	return nil
+}
+
+func (c *dryRunStatefulSetControl) UpdateStatefulSet(controller runtime.Object, set *apps.StatefulSet) (*apps.StatefulSet, error) {
+	diff := ""
+	if existing, err := c.setLister.StatefulSets(set.Namespace).Get(set.Name); err == nil {
+		diff = cmp.Diff(existing.Spec, set.Spec)
+	}
+	c.recorder.recordFor(controller, set, ChangeActionUpdate, diff)
+	return set, nil
+}
+
+func (c *dryRunStatefulSetControl) DeleteStatefulSet(controller runtime.Object, set *apps.StatefulSet) error {
+	c.recorder.recordFor(controller, set, ChangeActionDelete, "")
+	return nil
+}
+
+type dryRunServiceControl struct {
+	svcLister corelisterv1.ServiceLister
+	recorder  *DryRunRecorder
+}
+
+func (c *dryRunServiceControl) CreateService(controller runtime.Object, svc *corev1.Service) error {
+	c.recorder.recordFor(controller, svc, ChangeActionCreate, "")
+	return nil
+}
+
+func (c *dryRunServiceControl) UpdateService(controller runtime.Object, svc *corev1.Service) (*corev1.Service, error) {
+	diff := ""
+	if existing, err := c.svcLister.Services(svc.Namespace).Get(svc.Name); err == nil {
+		diff = cmp.Diff(existing.Spec, svc.Spec)
+	}
+	c.recorder.recordFor(controller, svc, ChangeActionUpdate, diff)
+	return svc, nil
+}
+
+func (c *dryRunServiceControl) DeleteService(controller runtime.Object, svc *corev1.Service) error {
+	c.recorder.recordFor(controller, svc, ChangeActionDelete, "")
+	return nil
+}
+
+type dryRunConfigMapControl struct {
+	ConfigMapControlInterface
+	recorder *DryRunRecorder
+}
+
+func (c *dryRunConfigMapControl) CreateConfigMap(controller runtime.Object, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	c.recorder.recordFor(controller, cm, ChangeActionCreate, "")
+	return cm, nil
+}
+
+func (c *dryRunConfigMapControl) UpdateConfigMap(controller runtime.Object, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	diff := ""
+	if existing, err := c.ConfigMapControlInterface.GetConfigMap(controller, cm); err == nil {
+		diff = cmp.Diff(existing.Data, cm.Data)
+	}
+	c.recorder.recordFor(controller, cm, ChangeActionUpdate, diff)
+	return cm, nil
+}
+
+func (c *dryRunConfigMapControl) DeleteConfigMap(controller runtime.Object, cm *corev1.ConfigMap) error {
+	c.recorder.recordFor(controller, cm, ChangeActionDelete, "")
+	return nil
+}
+
+type dryRunPVCControl struct {
+	PVCControlInterface
+	recorder *DryRunRecorder
+}
+
+func (c *dryRunPVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.PersistentVolumeClaim, _ *corev1.Pod) (*corev1.PersistentVolumeClaim, error) {
+	c.recorder.recordFor(controller, pvc, ChangeActionUpdate, "")
+	return pvc, nil
+}
+
+func (c *dryRunPVCControl) UpdatePVC(controller runtime.Object, pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	diff := ""
+	if existing, err := c.PVCControlInterface.GetPVC(pvc.Name, pvc.Namespace); err == nil {
+		diff = cmp.Diff(existing.Spec, pvc.Spec)
+	}
+	c.recorder.recordFor(controller, pvc, ChangeActionUpdate, diff)
+	return pvc, nil
+}
+
+func (c *dryRunPVCControl) DeletePVC(controller runtime.Object, pvc *corev1.PersistentVolumeClaim) error {
+	c.recorder.recordFor(controller, pvc, ChangeActionDelete, "")
+	return nil
+}
+
+func (c *dryRunPVCControl) CreatePVC(controller runtime.Object, pvc *corev1.PersistentVolumeClaim) error {
+	c.recorder.recordFor(controller, pvc, ChangeActionCreate, "")
+	return nil
+}
+
+type dryRunPVControl struct {
+	PVControlInterface
+	recorder *DryRunRecorder
+}
+
+func (c *dryRunPVControl) PatchPVReclaimPolicy(controller runtime.Object, pv *corev1.PersistentVolume, reclaimPolicy corev1.PersistentVolumeReclaimPolicy) error {
+	c.recorder.recordFor(controller, pv, ChangeActionUpdate, cmp.Diff(pv.Spec.PersistentVolumeReclaimPolicy, reclaimPolicy))
+	return nil
+}
+
+func (c *dryRunPVControl) UpdateMetaInfo(controller runtime.Object, pv *corev1.PersistentVolume) (*corev1.PersistentVolume, error) {
+	c.recorder.recordFor(controller, pv, ChangeActionUpdate, "")
+	return pv, nil
+}
+
+func (c *dryRunPVControl) PatchPVClaimRef(controller runtime.Object, pv *corev1.PersistentVolume, pvcName string) error {
+	c.recorder.recordFor(controller, pv, ChangeActionUpdate, "")
+	return nil
+}
+
+func (c *dryRunPVControl) CreatePV(controller runtime.Object, pv *corev1.PersistentVolume) error {
+	c.recorder.recordFor(controller, pv, ChangeActionCreate, "")
+	return nil
+}
+
+type dryRunPodControl struct {
+	podLister corelisterv1.PodLister
+	recorder  *DryRunRecorder
+}
+
+func (c *dryRunPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Pod) (*corev1.Pod, error) {
+	c.recorder.recordFor(tc, pod, ChangeActionUpdate, "")
+	return pod, nil
+}
+
+func (c *dryRunPodControl) DeletePod(controller runtime.Object, pod *corev1.Pod) error {
+	c.recorder.recordFor(controller, pod, ChangeActionDelete, "")
+	return nil
+}
+
+func (c *dryRunPodControl) UpdatePod(controller runtime.Object, pod *corev1.Pod) (*corev1.Pod, error) {
+	diff := ""
+	if existing, err := c.podLister.Pods(pod.Namespace).Get(pod.Name); err == nil {
+		diff = cmp.Diff(existing.ObjectMeta, pod.ObjectMeta)
+	}
+	c.recorder.recordFor(controller, pod, ChangeActionUpdate, diff)
+	return pod, nil
+}
+
+func (c *dryRunPodControl) UpdatePodCondition(controller runtime.Object, pod *corev1.Pod, condition corev1.PodCondition) (*corev1.Pod, error) {
+	diff := fmt.Sprintf("set condition %s to %s", condition.Type, condition.Status)
+	c.recorder.recordFor(controller, pod, ChangeActionUpdate, diff)
+	return pod, nil
+}
+
+type dryRunGenericControl struct {
+	GenericControlInterface
+	recorder *DryRunRecorder
+}
+
+func (c *dryRunGenericControl) CreateOrUpdate(controller, obj client.Object, mergeFn MergeFn, setOwnerFlag bool) (runtime.Object, error) {
+	existing, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a client.Object", obj)
+	}
+	exist, err := c.GenericControlInterface.Exist(client.ObjectKeyFromObject(obj), existing)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		c.recorder.recordFor(controller, obj, ChangeActionCreate, "")
+		return obj, nil
+	}
+
+	mutated := existing.DeepCopyObject().(client.Object)
+	if err := mergeFn(mutated, obj); err != nil {
+		return nil, err
+	}
+	if !apiequality.Semantic.DeepEqual(existing, mutated) {
+		c.recorder.recordFor(controller, obj, ChangeActionUpdate, cmp.Diff(existing, mutated))
+	}
+	return mutated, nil
+}
+
+func (c *dryRunGenericControl) Create(controller, obj client.Object, setOwnerFlag bool) error {
+	c.recorder.recordFor(controller, obj, ChangeActionCreate, "")
+	return nil
+}
+
+func (c *dryRunGenericControl) UpdateStatus(obj client.Object) error {
+	// status is observed state, it is not recorded as a change
+	return nil
+}
+
+func (c *dryRunGenericControl) Delete(controller, obj client.Object) error {
+	c.recorder.recordFor(controller, obj, ChangeActionDelete, "")
+	return nil
+}
+
+type dryRunTidbClusterControl struct {
+	recorder *DryRunRecorder
+}
+
+func (c *dryRunTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster, _, _ *v1alpha1.TidbClusterStatus) (*v1alpha1.TidbCluster, error) {
+	// the status of tc is updated by the caller of the dry-run reconciliation
+	return tc, nil
+}
+
+func (c *dryRunTidbClusterControl) Create(tc *v1alpha1.TidbCluster) error {
+	c.recorder.recordFor(tc, tc, ChangeActionCreate, "")
+	return nil
+}
+
+func (c *dryRunTidbClusterControl) Patch(tc *v1alpha1.TidbCluster, data []byte, subresources ...string) (*v1alpha1.TidbCluster, error) {
+	c.recorder.recordFor(tc, tc, ChangeActionUpdate, string(data))
+	return tc, nil
+}
+
+type dryRunTiCDCControl struct {
+	TiCDCControlInterface
+	recorder *DryRunRecorder
+}
+
+func (c *dryRunTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error) {
+	c.recorder.Record(tc.Namespace, tc.Name, v1alpha1.DryRunChange{
+		Kind:   "TiCDC",
+		Name:   TiCDCMemberName(tc.Name) + fmt.Sprintf("-%d", ordinal),
+		Action: "DrainCapture",
+	}, "")
+	return 0, false, nil
+}
+
+func (c *dryRunTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	c.recorder.Record(tc.Namespace, tc.Name, v1alpha1.DryRunChange{
+		Kind:   "TiCDC",
+		Name:   TiCDCMemberName(tc.Name) + fmt.Sprintf("-%d", ordinal),
+		Action: "ResignOwner",
+	}, "")
+	return true, nil
+}
+
+type dryRunPDControl struct {
+	pdapi.PDControlInterface
+	recorder *DryRunRecorder
+}
+
+func (c *dryRunPDControl) GetPDClient(namespace pdapi.Namespace, tcName string, tlsEnabled bool, opts ...pdapi.Option) pdapi.PDClient {
+	return &dryRunPDClient{
+		PDClient:  c.PDControlInterface.GetPDClient(namespace, tcName, tlsEnabled, opts...),
+		namespace: string(namespace),
+		tcName:    tcName,
+		recorder:  c.recorder,
+	}
+}
+
+func (c *dryRunPDControl) GetPDEtcdClient(namespace pdapi.Namespace, tcName string, tlsEnabled bool, opts ...pdapi.Option) (pdapi.PDEtcdClient, error) {
+	etcdClient, err := c.PDControlInterface.GetPDEtcdClient(namespace, tcName, tlsEnabled, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &dryRunPDEtcdClient{
+		PDEtcdClient: etcdClient,
+		namespace:    string(namespace),
+		tcName:       tcName,
+		recorder:     c.recorder,
+	}, nil
+}
+
+type dryRunPDClient struct {
+	pdapi.PDClient
+	namespace string
+	tcName    string
+	recorder  *DryRunRecorder
+}
+
+func (c *dryRunPDClient) record(name, action, diff string) {
+	c.recorder.Record(c.namespace, c.tcName, v1alpha1.DryRunChange{Kind: "PD", Name: name, Action: action}, diff)
+}
+
+func (c *dryRunPDClient) SetStoreLabels(storeID uint64, labels map[string]string) (bool, error) {
+	c.record(fmt.Sprintf("store-%d", storeID), "SetStoreLabels", fmt.Sprintf("%v", labels))
+	return true, nil
+}
+
+func (c *dryRunPDClient) UpdateReplicationConfig(config pdapi.PDReplicationConfig) error {
+	c.record("replication", "UpdateReplicationConfig", fmt.Sprintf("%+v", config))
+	return nil
+}
+
+func (c *dryRunPDClient) DeleteStore(storeID uint64) error {
+	c.record(fmt.Sprintf("store-%d", storeID), "DeleteStore", "")
+	return nil
+}
+
+func (c *dryRunPDClient) SetStoreState(storeID uint64, state string) error {
+	c.record(fmt.Sprintf("store-%d", storeID), "SetStoreState", state)
+	return nil
+}
+
+func (c *dryRunPDClient) DeleteMember(name string) error {
+	c.record(name, "DeleteMember", "")
+	return nil
+}
+
+func (c *dryRunPDClient) DeleteMemberByID(memberID uint64) error {
+	c.record(fmt.Sprintf("member-%d", memberID), "DeleteMember", "")
+	return nil
+}
+
+func (c *dryRunPDClient) BeginEvictLeader(storeID uint64) error {
+	c.record(fmt.Sprintf("store-%d", storeID), "BeginEvictLeader", "")
+	return nil
+}
+
+func (c *dryRunPDClient) EndEvictLeader(storeID uint64) error {
+	c.record(fmt.Sprintf("store-%d", storeID), "EndEvictLeader", "")
+	return nil
+}
+
+func (c *dryRunPDClient) TransferPDLeader(name string) error {
+	c.record(name, "TransferPDLeader", "")
+	return nil
+}
+
+func (c *dryRunPDClient) ScatterRegions(startKey, endKey string) error {
+	c.record("regions", "ScatterRegions", fmt.Sprintf("[%q, %q)", startKey, endKey))
+	return nil
+}
+
+func (c *dryRunPDClient) UpdateScheduleConfig(config map[string]interface{}) error {
+	c.record("schedule", "UpdateScheduleConfig", fmt.Sprintf("%v", config))
+	return nil
+}
+
+func (c *dryRunPDClient) SetPlacementRule(rule *pdapi.PlacementRule) error {
+	c.record(rule.GroupID+"/"+rule.ID, "SetPlacementRule", fmt.Sprintf("%+v", *rule))
+	return nil
+}
+
+func (c *dryRunPDClient) SetPlacementRuleGroup(group *pdapi.PlacementRuleGroup) error {
+	c.record(group.ID, "SetPlacementRuleGroup", fmt.Sprintf("%+v", *group))
+	return nil
+}
+
+func (c *dryRunPDClient) DeletePlacementRule(groupID, ruleID string) error {
+	c.record(groupID+"/"+ruleID, "DeletePlacementRule", "")
+	return nil
+}
+
+func (c *dryRunPDClient) SetStoreLimit(storeID uint64, limitType pdapi.StoreLimitType, rate float64) error {
+	c.record(fmt.Sprintf("store-%d", storeID), "SetStoreLimit", fmt.Sprintf("%s=%v", limitType, rate))
+	return nil
+}
+
+type dryRunTiKVControl struct {
+	tikvapi.TiKVControlInterface
+	recorder *DryRunRecorder
+}
+
+func (c *dryRunTiKVControl) GetTiKVPodClient(namespace string, tcName string, podName string, tlsEnabled bool) tikvapi.TiKVClient {
+	return &dryRunTiKVClient{
+		TiKVClient: c.TiKVControlInterface.GetTiKVPodClient(namespace, tcName, podName, tlsEnabled),
+		namespace:  namespace,
+		tcName:     tcName,
+		podName:    podName,
+		recorder:   c.recorder,
+	}
+}
+
+type dryRunTiKVClient struct {
+	tikvapi.TiKVClient
+	namespace string
+	tcName    string
+	podName   string
+	recorder  *DryRunRecorder
+}
+
+func (c *dryRunTiKVClient) SetConfigItems(items map[string]string) error {
+	c.recorder.Record(c.namespace, c.tcName, v1alpha1.DryRunChange{Kind: "TiKV", Name: c.podName, Action: "SetConfigItems"}, fmt.Sprintf("%v", items))
+	return nil
+}
+
+type dryRunPDEtcdClient struct {
+	pdapi.PDEtcdClient
+	namespace string
+	tcName    string
+	recorder  *DryRunRecorder
+}
+
+func (c *dryRunPDEtcdClient) PutKey(key, value string) error {
+	c.recorder.Record(c.namespace, c.tcName, v1alpha1.DryRunChange{Kind: "PDEtcd", Name: key, Action: "Put"}, value)
+	return nil
+}
+
+func (c *dryRunPDEtcdClient) PutTTLKey(key, value string, ttl int64) error {
+	c.recorder.Record(c.namespace, c.tcName, v1alpha1.DryRunChange{Kind: "PDEtcd", Name: key, Action: "Put"}, value)
+	return nil
+}
+
+func (c *dryRunPDEtcdClient) DeleteKey(key string) error {
+	c.recorder.Record(c.namespace, c.tcName, v1alpha1.DryRunChange{Kind: "PDEtcd", Name: key, Action: ChangeActionDelete}, "")
+	return nil
+}
+
+func (c *dryRunPDEtcdClient) PutKeysIfNotExist(guards []string, kvs []*pdapi.KeyValue) (bool, error) {
+	for _, kv := range kvs {
+		c.recorder.Record(c.namespace, c.tcName, v1alpha1.DryRunChange{Kind: "PDEtcd", Name: kv.Key, Action: "Put"}, fmt.Sprintf("%x", kv.Value))
+	}
+	return true, nil
+}
+
+func (c *dryRunPDEtcdClient) Defragment(endpoint string) error {
+	c.recorder.Record(c.namespace, c.tcName, v1alpha1.DryRunChange{Kind: "PDEtcd", Name: endpoint, Action: "Defragment"}, "")
+	return nil
+}
+
+func (c *dryRunPDEtcdClient) Compact(revision int64) error {
+	c.recorder.Record(c.namespace, c.tcName, v1alpha1.DryRunChange{Kind: "PDEtcd", Name: strconv.FormatInt(revision, 10), Action: "Compact"}, "")
+	return nil
+}
+
+var _ StatefulSetControlInterface = &dryRunStatefulSetControl{}
+var _ ServiceControlInterface = &dryRunServiceControl{}
+var _ PodControlInterface = &dryRunPodControl{}
+var _ GenericControlInterface = &dryRunGenericControl{}
+var _ TidbClusterControlInterface = &dryRunTidbClusterControl{}
+var _ pdapi.PDControlInterface = &dryRunPDControl{}
@@ -0,0 +1,75 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDryRunDependencies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := NewFakeDependencies()
+	recorder := NewDryRunRecorder()
+	dryRunDeps := NewDryRunDependencies(deps, recorder)
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Namespace: metav1.NamespaceDefault},
+		Data:       map[string]string{"a": "1"},
+	}
+	_, err := dryRunDeps.TypedControl.CreateOrUpdateConfigMap(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	exist, err := deps.TypedControl.Exist(client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeFalse())
+
+	// the existing object is merged but not updated
+	_, err = deps.TypedControl.CreateOrUpdateConfigMap(tc, cm.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	cm.Data["a"] = "2"
+	_, err = dryRunDeps.TypedControl.CreateOrUpdateConfigMap(tc, cm.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	existing := &corev1.ConfigMap{}
+	_, err = deps.TypedControl.Exist(client.ObjectKeyFromObject(cm), existing)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(existing.Data["a"]).To(Equal("1"))
+
+	pdClient := NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		t.Fatalf("store should not be deleted in dry-run mode")
+		return nil, nil
+	})
+	g.Expect(GetPDClient(dryRunDeps.PDControl, tc).DeleteStore(1)).To(Succeed())
+
+	changes, diff := recorder.Take(tc.Namespace, tc.Name)
+	g.Expect(changes).To(Equal([]v1alpha1.DryRunChange{
+		{Kind: "ConfigMap", Name: "test-cm", Action: ChangeActionCreate},
+		{Kind: "ConfigMap", Name: "test-cm", Action: ChangeActionUpdate},
+		{Kind: "PD", Name: "store-1", Action: "DeleteStore"},
+	}))
+	g.Expect(diff).To(ContainSubstring("Update ConfigMap test-cm"))
+
+	changes, _ = recorder.Take(tc.Namespace, tc.Name)
+	g.Expect(changes).To(BeEmpty())
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/pingcap/tidb-operator/pkg/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
@@ -60,6 +61,9 @@ func DeploymentPodSpecChanged(newDep *appsv1.Deployment, oldDep *appsv1.Deployme
 
 // SetServiceLastAppliedConfigAnnotation set last applied config info to Service's annotation
 func SetServiceLastAppliedConfigAnnotation(svc *corev1.Service) error {
+	if ServerSideApplyEnabled() {
+		return util.SetLastAppliedConfig(svc, svc.Spec)
+	}
 	b, err := json.Marshal(svc.Spec)
 	if err != nil {
 		return err
@@ -73,6 +77,9 @@ func SetServiceLastAppliedConfigAnnotation(svc *corev1.Service) error {
 
 // ServiceEqual compares the new Service's spec with old Service's last applied config
 func ServiceEqual(newSvc, oldSvc *corev1.Service) (bool, error) {
+	if equal, ok, err := util.LastAppliedHashEqual(oldSvc, newSvc.Spec); ok {
+		return equal, err
+	}
 	oldSpec := corev1.ServiceSpec{}
 	if lastAppliedConfig, ok := oldSvc.Annotations[LastAppliedConfigAnnotation]; ok {
 		err := json.Unmarshal([]byte(lastAppliedConfig), &oldSpec)
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -62,6 +63,12 @@ type TypedControlInterface interface {
 	CreateOrUpdateIngress(controller client.Object, ingress *networkingv1.Ingress) (*networkingv1.Ingress, error)
 	// CreateOrUpdateIngressV1beta1 create the desired v1beta1 ingress or update the current one to desired state if already existed
 	CreateOrUpdateIngressV1beta1(controller client.Object, ingress *extensionsv1beta1.Ingress) (*extensionsv1beta1.Ingress, error)
+	// CreateOrUpdateNetworkPolicy create the desired network policy or update the current one to desired state if already existed
+	CreateOrUpdateNetworkPolicy(controller client.Object, np *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error)
+	// CreateOrUpdateResourceQuota create the desired resource quota or update the current one to desired state if already existed
+	CreateOrUpdateResourceQuota(controller client.Object, quota *corev1.ResourceQuota) (*corev1.ResourceQuota, error)
+	// CreateOrUpdateLimitRange create the desired limit range or update the current one to desired state if already existed
+	CreateOrUpdateLimitRange(controller client.Object, lr *corev1.LimitRange) (*corev1.LimitRange, error)
 	// UpdateStatus update the /status subresource of the object
 	UpdateStatus(newStatus client.Object) error
 	// Delete delete the given object from the cluster
@@ -231,6 +238,51 @@ func (w *typedWrapper) CreateOrUpdateRoleBinding(controller client.Object, rb *r
 	return result.(*rbacv1.RoleBinding), err
 }
 
+func (w *typedWrapper) CreateOrUpdateNetworkPolicy(controller client.Object, np *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error) {
+	result, err := w.GenericControlInterface.CreateOrUpdate(controller, np, func(existing, desired client.Object) error {
+		existingNP := existing.(*networkingv1.NetworkPolicy)
+		desiredNP := desired.(*networkingv1.NetworkPolicy)
+
+		existingNP.Labels = desiredNP.Labels
+		existingNP.Spec = desiredNP.Spec
+		return nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*networkingv1.NetworkPolicy), err
+}
+
+func (w *typedWrapper) CreateOrUpdateResourceQuota(controller client.Object, quota *corev1.ResourceQuota) (*corev1.ResourceQuota, error) {
+	result, err := w.GenericControlInterface.CreateOrUpdate(controller, quota, func(existing, desired client.Object) error {
+		existingQuota := existing.(*corev1.ResourceQuota)
+		desiredQuota := desired.(*corev1.ResourceQuota)
+
+		existingQuota.Labels = desiredQuota.Labels
+		existingQuota.Spec = desiredQuota.Spec
+		return nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*corev1.ResourceQuota), err
+}
+
+func (w *typedWrapper) CreateOrUpdateLimitRange(controller client.Object, lr *corev1.LimitRange) (*corev1.LimitRange, error) {
+	result, err := w.GenericControlInterface.CreateOrUpdate(controller, lr, func(existing, desired client.Object) error {
+		existingLR := existing.(*corev1.LimitRange)
+		desiredLR := desired.(*corev1.LimitRange)
+
+		existingLR.Labels = desiredLR.Labels
+		existingLR.Spec = desiredLR.Spec
+		return nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*corev1.LimitRange), err
+}
+
 func (w *typedWrapper) CreateOrUpdateServiceAccount(controller client.Object, sa *corev1.ServiceAccount) (*corev1.ServiceAccount, error) {
 	result, err := w.GenericControlInterface.CreateOrUpdate(controller, sa, func(existing, desired client.Object) error {
 		existingSA := existing.(*corev1.ServiceAccount)
@@ -457,6 +509,10 @@ func (c *realGenericControlInterface) CreateOrUpdate(controller, obj client.Obje
 		}
 	}
 
+	if ServerSideApplyEnabled() {
+		return c.apply(desired, mergeFn)
+	}
+
 	// 1. try to create and see if there is any conflicts
 	err := c.client.Create(context.TODO(), desired)
 	if errors.IsAlreadyExists(err) {
@@ -501,6 +557,44 @@ func (c *realGenericControlInterface) CreateOrUpdate(controller, obj client.Obje
 	return desired, err
 }
 
+// apply applies the fields of desired tidb-operator wants by server-side apply. If the object exists,
+// they are the fields copied by mergeFn to an empty object, so the fields mergeFn doesn't copy, e.g.
+// the nodePorts of a Service or the storageClassName of a PVC, are left to their field managers.
+// Otherwise the desired object is applied as a whole to create it.
+func (c *realGenericControlInterface) apply(desired client.Object, mergeFn MergeFn) (runtime.Object, error) {
+	live, err := EmptyClone(desired)
+	if err != nil {
+		return nil, err
+	}
+	applied := desired
+	if err := c.client.Get(context.TODO(), client.ObjectKeyFromObject(desired), live); err == nil {
+		if applied, err = EmptyClone(desired); err != nil {
+			return nil, err
+		}
+		applied.SetLabels(map[string]string{})
+		applied.SetAnnotations(map[string]string{})
+		applied.SetOwnerReferences(desired.GetOwnerReferences())
+		if err := mergeFn(applied, desired); err != nil {
+			return nil, err
+		}
+		// the last applied config is only used to compare the objects before updating them
+		annotations := applied.GetAnnotations()
+		delete(annotations, LastAppliedConfigAnnotation)
+		applied.SetAnnotations(annotations)
+	} else if errors.IsNotFound(err) {
+		live = nil
+	} else {
+		return nil, err
+	}
+
+	data, err := ApplyPatch(applied, live)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.Patch(context.TODO(), applied, client.RawPatch(types.ApplyPatchType, data), client.FieldOwner(FieldManager), client.ForceOwnership)
+	return applied, err
+}
+
 // Create create an object to the Kubernetes cluster for controller
 func (c *realGenericControlInterface) Create(controller, obj client.Object, setOwnerFlag bool) error {
 	// controller-runtime/client will mutate the object pointer in-place,
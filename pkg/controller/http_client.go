@@ -14,6 +14,7 @@
 package controller
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -21,18 +22,23 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	v1 "k8s.io/api/core/v1"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 )
 
 type httpClient struct {
+	// ctx is the context of the requests, it's done when tidb-operator is shutting down
+	ctx          context.Context
 	secretLister corelisterv1.SecretLister
 }
 
+// getHTTPClient returns the client whose requests share the limiter of the cluster
 func (c *httpClient) getHTTPClient(tc *v1alpha1.TidbCluster) (*http.Client, error) {
 	httpClient := &http.Client{Timeout: timeout}
+	cluster := fmt.Sprintf("%s/%s", tc.Namespace, tc.Name)
 	if !tc.IsTLSClusterEnabled() {
-		return httpClient, nil
+		return httputil.LimitCluster(httpClient, cluster), nil
 	}
 
 	tcName := tc.Name
@@ -62,5 +68,5 @@ func (c *httpClient) getHTTPClient(tc *v1alpha1.TidbCluster) (*http.Client, erro
 	}
 	httpClient.Transport = &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}
 
-	return httpClient, nil
+	return httputil.LimitCluster(httpClient, cluster), nil
 }
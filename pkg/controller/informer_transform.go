@@ -0,0 +1,242 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// stripObject removes the fields not used by tidb-operator from obj before it's stored in the
+// informer cache. The fields are left as is on the api-server. The cached Pods and PVCs must not be
+// updated as a whole because of it, only their labels and annotations are written, see MetaPatch.
+func stripObject(obj runtime.Object) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		// the images, the commands and the resources of the containers, the claims of the volumes,
+		// the scheduling constraints and the states of the containers are used
+		stripContainers(o.Spec.InitContainers)
+		stripContainers(o.Spec.Containers)
+		o.Spec.EphemeralContainers = nil
+		for i := range o.Spec.Volumes {
+			o.Spec.Volumes[i].VolumeSource = corev1.VolumeSource{PersistentVolumeClaim: o.Spec.Volumes[i].PersistentVolumeClaim}
+		}
+		o.Spec.ImagePullSecrets = nil
+		o.Spec.HostAliases = nil
+		o.Spec.DNSConfig = nil
+		o.Spec.ReadinessGates = nil
+		stripContainerStatuses(o.Status.InitContainerStatuses)
+		stripContainerStatuses(o.Status.ContainerStatuses)
+		o.Status.EphemeralContainerStatuses = nil
+	case *corev1.PersistentVolumeClaim:
+		// the claims are matched to volumes by the provisioners, only the storage class, the volume,
+		// the requests and the capacity are used
+		o.Spec.Selector = nil
+		o.Spec.DataSource = nil
+		o.Status.AccessModes = nil
+	case *corev1.Node:
+		// only the labels, the conditions and the allocatable resources of the nodes are used,
+		// and the nodes are never updated by tidb-operator
+		o.Status.Images = nil
+		o.Status.VolumesInUse = nil
+		o.Status.VolumesAttached = nil
+	}
+}
+
+func stripContainers(containers []corev1.Container) {
+	for i := range containers {
+		c := &containers[i]
+		c.Env = nil
+		c.EnvFrom = nil
+		c.VolumeMounts = nil
+		c.VolumeDevices = nil
+		c.LivenessProbe = nil
+		c.ReadinessProbe = nil
+		c.StartupProbe = nil
+		c.Lifecycle = nil
+	}
+}
+
+func stripContainerStatuses(statuses []corev1.ContainerStatus) {
+	for i := range statuses {
+		statuses[i].LastTerminationState = corev1.ContainerState{}
+		statuses[i].ImageID = ""
+	}
+}
+
+// strippingListWatch strips the objects listed and watched by the ListerWatcher, the objects
+// not in the selected namespaces are dropped, and they are re-listed once the selection changes
+type strippingListWatch struct {
+	cache.ListerWatcher
+	nsSelector *NamespaceSelector
+	// changed is closed once the selected namespaces are changed since the last list
+	changed <-chan struct{}
+}
+
+func (lw *strippingListWatch) selected(obj runtime.Object) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return true
+	}
+	return accessor.GetNamespace() == "" || lw.nsSelector.Selected(accessor.GetNamespace())
+}
+
+func (lw *strippingListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	if lw.nsSelector != nil {
+		lw.nsSelector.waitForCacheSync()
+		lw.changed = lw.nsSelector.changedCh()
+	}
+	list, err := lw.ListerWatcher.List(options)
+	if err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]runtime.Object, 0, len(items))
+	for _, obj := range items {
+		if !lw.selected(obj) {
+			continue
+		}
+		stripObject(obj)
+		selected = append(selected, obj)
+	}
+	return list, meta.SetList(list, selected)
+}
+
+func (lw *strippingListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w, err := lw.ListerWatcher.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+	w = watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		if in.Type == watch.Error {
+			return in, true
+		}
+		if !lw.selected(in.Object) {
+			return in, false
+		}
+		stripObject(in.Object)
+		return in, true
+	})
+	if lw.nsSelector != nil {
+		w = relistOnChange(w, lw.changed)
+	}
+	return w, nil
+}
+
+// registerStrippedInformers registers the informers of the high-cardinality resources, i.e. Pods,
+// PVCs and Nodes, to the factory, which strip the unused fields of the objects to reduce the memory
+// usage. The Pods and PVCs are also filtered by tweakListOptions and nsSelector if they are not nil.
+// It must be called before the informers are created by the factory.
+func registerStrippedInformers(factory kubeinformers.SharedInformerFactory, ns string, tweakListOptions func(*metav1.ListOptions), nsSelector *NamespaceSelector) {
+	tweak := func(options *metav1.ListOptions) {
+		if tweakListOptions != nil {
+			tweakListOptions(options)
+		}
+	}
+	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	factory.InformerFor(&corev1.Pod{}, func(cli kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		lw := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				tweak(&options)
+				return cli.CoreV1().Pods(ns).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				tweak(&options)
+				return cli.CoreV1().Pods(ns).Watch(context.TODO(), options)
+			},
+		}
+		return cache.NewSharedIndexInformer(&strippingListWatch{ListerWatcher: lw, nsSelector: nsSelector}, &corev1.Pod{}, resync, indexers)
+	})
+	factory.InformerFor(&corev1.PersistentVolumeClaim{}, func(cli kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		lw := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				tweak(&options)
+				return cli.CoreV1().PersistentVolumeClaims(ns).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				tweak(&options)
+				return cli.CoreV1().PersistentVolumeClaims(ns).Watch(context.TODO(), options)
+			},
+		}
+		return cache.NewSharedIndexInformer(&strippingListWatch{ListerWatcher: lw, nsSelector: nsSelector}, &corev1.PersistentVolumeClaim{}, resync, indexers)
+	})
+	factory.InformerFor(&corev1.Node{}, func(cli kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		lw := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return cli.CoreV1().Nodes().List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return cli.CoreV1().Nodes().Watch(context.TODO(), options)
+			},
+		}
+		return cache.NewSharedIndexInformer(&strippingListWatch{ListerWatcher: lw}, &corev1.Node{}, resync, indexers)
+	})
+}
+
+// scopedResource is a namespaced resource cached by an informer factory
+type scopedResource struct {
+	obj      runtime.Object
+	client   cache.Getter
+	resource string
+}
+
+// registerScopedKubeInformers registers the informers of the kube resources to the factory, which
+// only cache the objects in the namespaces selected by nsSelector and filtered by tweakListOptions.
+// It must be called before the informers are created by the factory.
+func registerScopedKubeInformers(factory kubeinformers.SharedInformerFactory, resources []scopedResource, ns string, tweakListOptions func(*metav1.ListOptions), nsSelector *NamespaceSelector) {
+	for i := range resources {
+		r := resources[i]
+		factory.InformerFor(r.obj, func(_ kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+			return newScopedInformer(r, ns, tweakListOptions, nsSelector, resync)
+		})
+	}
+}
+
+// registerScopedInformers registers the informers of the pingcap resources to the factory, see
+// registerScopedKubeInformers
+func registerScopedInformers(factory informers.SharedInformerFactory, resources []scopedResource, ns string, tweakListOptions func(*metav1.ListOptions), nsSelector *NamespaceSelector) {
+	for i := range resources {
+		r := resources[i]
+		factory.InformerFor(r.obj, func(_ versioned.Interface, resync time.Duration) cache.SharedIndexInformer {
+			return newScopedInformer(r, ns, tweakListOptions, nsSelector, resync)
+		})
+	}
+}
+
+func newScopedInformer(r scopedResource, ns string, tweakListOptions func(*metav1.ListOptions), nsSelector *NamespaceSelector, resync time.Duration) cache.SharedIndexInformer {
+	lw := cache.NewFilteredListWatchFromClient(r.client, r.resource, ns, func(options *metav1.ListOptions) {
+		if tweakListOptions != nil {
+			tweakListOptions(options)
+		}
+	})
+	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	return cache.NewSharedIndexInformer(&strippingListWatch{ListerWatcher: lw, nsSelector: nsSelector}, r.obj, resync, indexers)
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStrippedInformers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "node-1",
+			Labels:        map[string]string{corev1.LabelTopologyZone: "zone-a"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubelet"}},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")},
+			Images:      []corev1.ContainerImage{{Names: []string{"pingcap/tikv:latest"}}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "test-tikv-0",
+			Namespace:     metav1.NamespaceDefault,
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubelet"}},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{{
+				Name:         "tikv",
+				Image:        "pingcap/tikv:latest",
+				Command:      []string{"/bin/sh", "/usr/local/bin/tikv_start_script.sh"},
+				Env:          []corev1.EnvVar{{Name: "TZ", Value: "UTC"}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "tikv", MountPath: "/var/lib/tikv"}},
+			}},
+			Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "test-tikv"},
+				}}},
+				{Name: "tikv", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "tikv-test-tikv-0",
+				}}},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "tikv",
+				RestartCount:         1,
+				ImageID:              "docker-pullable://pingcap/tikv@sha256:0",
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+			}},
+		},
+	}
+	cli := kubefake.NewSimpleClientset(node, pod)
+	factory := kubeinformers.NewSharedInformerFactory(cli, time.Minute)
+	registerStrippedInformers(factory, metav1.NamespaceAll, nil, nil)
+
+	nodeLister := factory.Core().V1().Nodes().Lister()
+	podLister := factory.Core().V1().Pods().Lister()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	cached, err := nodeLister.Get("node-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cached.Labels).To(Equal(node.Labels))
+	g.Expect(cached.Status.Allocatable).To(Equal(node.Status.Allocatable))
+	g.Expect(cached.ManagedFields).To(BeNil())
+	g.Expect(cached.Status.Images).To(BeNil())
+
+	cachedPod, err := podLister.Pods(metav1.NamespaceDefault).Get("test-tikv-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cachedPod.ManagedFields).To(BeNil())
+	g.Expect(cachedPod.Spec.NodeName).To(Equal("node-1"))
+	g.Expect(cachedPod.Spec.Containers).To(Equal([]corev1.Container{{
+		Name:    "tikv",
+		Image:   "pingcap/tikv:latest",
+		Command: []string{"/bin/sh", "/usr/local/bin/tikv_start_script.sh"},
+	}}))
+	g.Expect(cachedPod.Spec.Volumes).To(Equal([]corev1.Volume{
+		{Name: "config"},
+		pod.Spec.Volumes[1],
+	}))
+	g.Expect(cachedPod.Status.ContainerStatuses).To(Equal([]corev1.ContainerStatus{{Name: "tikv", RestartCount: 1}}))
+}
+
+func TestStrippedInformersWithNamespaceSelector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cli := kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant": "tidb"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-a", Namespace: "tenant-a"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-b", Namespace: "tenant-b"}},
+	)
+	factory := kubeinformers.NewSharedInformerFactory(cli, time.Minute)
+	selector, err := labels.Parse("tenant=tidb")
+	g.Expect(err).NotTo(HaveOccurred())
+	nsSelector := NewNamespaceSelector(factory.Core().V1().Namespaces(), selector)
+	registerStrippedInformers(factory, metav1.NamespaceAll, nil, nsSelector)
+
+	podLister := factory.Core().V1().Pods().Lister()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	g.Expect(nsSelector.Selected("tenant-a")).To(BeTrue())
+	g.Expect(nsSelector.Selected("tenant-b")).To(BeFalse())
+	pods, err := podLister.List(labels.Everything())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pods).To(HaveLen(1))
+	g.Expect(pods[0].Name).To(Equal("pod-a"))
+
+	// the pods are re-listed once the selected namespaces are changed
+	_, err = cli.CoreV1().Namespaces().Update(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}}, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = cli.CoreV1().Namespaces().Update(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b", Labels: map[string]string{"tenant": "tidb"}}}, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(func() []string {
+		pods, err := podLister.List(labels.Everything())
+		g.Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		return names
+	}, 10*time.Second, 100*time.Millisecond).Should(ConsistOf("pod-b"))
+
+	// all namespaces are selected by default
+	var all *NamespaceSelector
+	g.Expect(all.Selected("tenant-b")).To(BeTrue())
+}
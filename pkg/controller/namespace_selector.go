@@ -0,0 +1,156 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// NamespaceSelector selects the namespaces whose objects are watched and synced by tidb-operator.
+// The selection follows the labels of the namespaces, the informers scoped by it re-list the
+// objects once a namespace is selected or unselected, see strippingListWatch.
+type NamespaceSelector struct {
+	nsLister corelisterv1.NamespaceLister
+	synced   cache.InformerSynced
+	selector labels.Selector
+
+	lock sync.Mutex
+	// changed is closed and renewed when the selected namespaces are changed
+	changed chan struct{}
+}
+
+// NewNamespaceSelector returns a NamespaceSelector which selects the namespaces matching selector,
+// it must be called before the informer of namespaces is started
+func NewNamespaceSelector(nsInformer coreinformers.NamespaceInformer, selector labels.Selector) *NamespaceSelector {
+	s := &NamespaceSelector{
+		nsLister: nsInformer.Lister(),
+		synced:   nsInformer.Informer().HasSynced,
+		selector: selector,
+		changed:  make(chan struct{}),
+	}
+	nsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			// the objects of a new namespace may be dropped if they are watched before the namespace
+			if ns, ok := obj.(*corev1.Namespace); ok && s.synced() && s.matches(ns) {
+				s.notify(ns.Name)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNs, ok := oldObj.(*corev1.Namespace)
+			if !ok {
+				return
+			}
+			newNs, ok := newObj.(*corev1.Namespace)
+			if !ok {
+				return
+			}
+			if s.matches(oldNs) != s.matches(newNs) {
+				s.notify(newNs.Name)
+			}
+		},
+	})
+	return s
+}
+
+// Selected returns whether the namespace is selected, all namespaces are selected if s is nil
+func (s *NamespaceSelector) Selected(ns string) bool {
+	if s == nil {
+		return true
+	}
+	namespace, err := s.nsLister.Get(ns)
+	if err != nil {
+		// the objects are re-listed once the namespace is added to the cache
+		klog.V(4).Infof("failed to get namespace %s from the cache, treat it as not selected, error: %v", ns, err)
+		return false
+	}
+	return s.matches(namespace)
+}
+
+func (s *NamespaceSelector) matches(ns *corev1.Namespace) bool {
+	return s.selector.Matches(labels.Set(ns.Labels))
+}
+
+func (s *NamespaceSelector) notify(ns string) {
+	klog.Infof("the selection of namespace %s is changed, re-list the objects", ns)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *NamespaceSelector) changedCh() <-chan struct{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.changed
+}
+
+// waitForCacheSync blocks until the namespaces are synced, so that the objects listed are
+// not dropped because their namespaces are not in the cache yet
+func (s *NamespaceSelector) waitForCacheSync() {
+	_ = wait.PollImmediateInfinite(100*time.Millisecond, func() (bool, error) {
+		return s.synced(), nil
+	})
+}
+
+// relistOnChange returns a watch which forwards the events of w until changed is closed, i.e. the
+// selected namespaces are changed, then it ends with an expired error, which makes the reflector
+// re-list the objects to add the objects of the newly selected namespaces and to remove the others.
+func relistOnChange(w watch.Interface, changed <-chan struct{}) watch.Interface {
+	ch := make(chan watch.Event)
+	proxy := watch.NewProxyWatcher(ch)
+	go func() {
+		defer close(ch)
+		defer w.Stop()
+		for {
+			select {
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+				select {
+				case ch <- event:
+				case <-proxy.StopChan():
+					return
+				}
+			case <-changed:
+				expired := &metav1.Status{
+					Status:  metav1.StatusFailure,
+					Code:    http.StatusGone,
+					Reason:  metav1.StatusReasonExpired,
+					Message: "the selected namespaces are changed",
+				}
+				select {
+				case ch <- watch.Event{Type: watch.Error, Object: expired}:
+				case <-proxy.StopChan():
+				}
+				return
+			case <-proxy.StopChan():
+				return
+			}
+		}
+	}()
+	return proxy
+}
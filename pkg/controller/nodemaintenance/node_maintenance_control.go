@@ -0,0 +1,204 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodemaintenance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	v1alpha1validation "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/util/slice"
+)
+
+// ControlInterface provide function about control NodeMaintenance
+type ControlInterface interface {
+	// Reconcile a NodeMaintenance
+	Reconcile(*v1alpha1.NodeMaintenance) error
+
+	// Update the status of a NodeMaintenance
+	Update(*v1alpha1.NodeMaintenance) (*v1alpha1.NodeMaintenance, error)
+}
+
+func NewDefaultNodeMaintenanceControl(
+	deps *controller.Dependencies,
+	maintenanceMnger manager.NodeMaintenanceManager,
+	recorder record.EventRecorder,
+) *defaultNodeMaintenanceControl {
+	return &defaultNodeMaintenanceControl{
+		deps:             deps,
+		recorder:         recorder,
+		maintenanceMnger: maintenanceMnger,
+	}
+}
+
+type defaultNodeMaintenanceControl struct {
+	deps     *controller.Dependencies
+	recorder record.EventRecorder
+
+	maintenanceMnger manager.NodeMaintenanceManager
+}
+
+func (c *defaultNodeMaintenanceControl) Reconcile(nm *v1alpha1.NodeMaintenance) error {
+	if !c.validate(nm) {
+		return nil // fatal error, no need to retry on invalid object
+	}
+
+	nm, err := c.addFinalizer(nm)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	oldStatus := nm.Status.DeepCopy()
+
+	err = c.maintenanceMnger.Sync(nm)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if oldStatus.Phase != nm.Status.Phase {
+		switch nm.Status.Phase {
+		case v1alpha1.NodeMaintenancePhasePreparing:
+			c.recorder.Eventf(nm, v1.EventTypeNormal, "MaintenancePreparing", "moving leaders out of node %s", nm.Spec.NodeName)
+		case v1alpha1.NodeMaintenancePhaseReady:
+			c.recorder.Eventf(nm, v1.EventTypeNormal, "MaintenanceReady", "node %s is ready for maintenance", nm.Spec.NodeName)
+		case v1alpha1.NodeMaintenancePhaseCompleting:
+			c.recorder.Eventf(nm, v1.EventTypeNormal, "MaintenanceCompleting", "ending the eviction of leaders on node %s", nm.Spec.NodeName)
+		}
+	}
+
+	if !apiequality.Semantic.DeepEqual(&nm.Status, oldStatus) {
+		if _, err := c.Update(nm.DeepCopy()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		if err := c.removeFinalizer(nm); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errorutils.NewAggregate(errs)
+}
+
+// addFinalizer adds the finalizer to the NodeMaintenance, so that the eviction
+// of leaders is ended before it's removed
+func (c *defaultNodeMaintenanceControl) addFinalizer(nm *v1alpha1.NodeMaintenance) (*v1alpha1.NodeMaintenance, error) {
+	if nm.DeletionTimestamp != nil || slice.ContainsString(nm.Finalizers, label.NodeMaintenanceFinalizer, nil) {
+		return nm, nil
+	}
+	nm.Finalizers = append(nm.Finalizers, label.NodeMaintenanceFinalizer)
+	updated, err := c.deps.Clientset.PingcapV1alpha1().NodeMaintenances(nm.Namespace).Update(context.TODO(), nm, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("add NodeMaintenance %s/%s finalizer failed, err: %v", nm.Namespace, nm.Name, err)
+	}
+	return updated, nil
+}
+
+func (c *defaultNodeMaintenanceControl) removeFinalizer(nm *v1alpha1.NodeMaintenance) error {
+	if nm.DeletionTimestamp == nil || len(nm.Status.EvictedStores) > 0 || !slice.ContainsString(nm.Finalizers, label.NodeMaintenanceFinalizer, nil) {
+		return nil
+	}
+	latest, err := c.deps.Clientset.PingcapV1alpha1().NodeMaintenances(nm.Namespace).Get(context.TODO(), nm.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	latest.Finalizers = slice.RemoveString(latest.Finalizers, label.NodeMaintenanceFinalizer, nil)
+	if _, err := c.deps.Clientset.PingcapV1alpha1().NodeMaintenances(nm.Namespace).Update(context.TODO(), latest, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("remove NodeMaintenance %s/%s finalizer failed, err: %v", nm.Namespace, nm.Name, err)
+	}
+	klog.Infof("remove NodeMaintenance %s/%s finalizer success", nm.Namespace, nm.Name)
+	return nil
+}
+
+func (c *defaultNodeMaintenanceControl) Update(nm *v1alpha1.NodeMaintenance) (*v1alpha1.NodeMaintenance, error) {
+	var (
+		ns     string                          = nm.GetNamespace()
+		name   string                          = nm.GetName()
+		status *v1alpha1.NodeMaintenanceStatus = nm.Status.DeepCopy()
+		update *v1alpha1.NodeMaintenance
+	)
+
+	// don't wait due to limited number of clients, but backoff after the default number of steps
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var updateErr error
+
+		update, updateErr = c.deps.Clientset.PingcapV1alpha1().NodeMaintenances(ns).UpdateStatus(context.TODO(), nm, metav1.UpdateOptions{})
+		if updateErr == nil {
+			klog.Infof("NodeMaintenance: [%s/%s] updated successfully", ns, name)
+			return nil
+		}
+
+		klog.V(4).Infof("failed to update NodeMaintenance: [%s/%s], error: %v", ns, name, updateErr)
+
+		if updated, err := c.deps.NodeMaintenanceLister.NodeMaintenances(ns).Get(name); err == nil {
+			// make a copy so we don't mutate the shared cache
+			nm = updated.DeepCopy()
+			nm.Status = *status
+		} else {
+			utilruntime.HandleError(fmt.Errorf("error getting updated NodeMaintenance %s/%s from lister: %v", ns, name, err))
+		}
+
+		return updateErr
+	})
+	if err != nil {
+		klog.Errorf("failed to update NodeMaintenance: [%s/%s], error: %v", ns, name, err)
+	}
+	return update, err
+}
+
+func (c *defaultNodeMaintenanceControl) validate(nm *v1alpha1.NodeMaintenance) bool {
+	errs := v1alpha1validation.ValidateNodeMaintenance(nm)
+	if len(errs) > 0 {
+		aggregatedErr := errs.ToAggregate()
+		klog.Errorf("node maintenance %s/%s is not valid and must be fixed first, aggregated error: %v", nm.GetNamespace(), nm.GetName(), aggregatedErr)
+		c.recorder.Event(nm, v1.EventTypeWarning, "FailedValidation", aggregatedErr.Error())
+		return false
+	}
+	return true
+}
+
+type FakeNodeMaintenanceControl struct {
+	reconcile func(*v1alpha1.NodeMaintenance) error
+}
+
+func (c *FakeNodeMaintenanceControl) MockReconcile(reconcile func(*v1alpha1.NodeMaintenance) error) {
+	c.reconcile = reconcile
+}
+
+func (c *FakeNodeMaintenanceControl) Reconcile(nm *v1alpha1.NodeMaintenance) error {
+	if c.reconcile != nil {
+		return c.reconcile(nm)
+	}
+	return nil
+}
+
+func (c *FakeNodeMaintenanceControl) Update(nm *v1alpha1.NodeMaintenance) (*v1alpha1.NodeMaintenance, error) {
+	return nm, nil
+}
@@ -0,0 +1,287 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orphangc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// Controller looks for the Services, ConfigMaps, StatefulSets, PVCs and Secrets managed by
+// tidb-operator which are left by the deleted TidbClusters or the components removed from
+// TidbClusters, e.g. the StatefulSet of TiFlash after `spec.tiflash` is removed, and reports
+// or deletes them periodically.
+type Controller struct {
+	deps *controller.Dependencies
+	// kinds are the kinds of objects collected
+	kinds []orphanKind
+}
+
+// orphanKind lists and deletes the objects of a kind
+type orphanKind struct {
+	kind   string
+	list   func(selector labels.Selector) ([]metav1.Object, error)
+	delete func(ns, name string, uid types.UID) error
+}
+
+// orphan is an object whose TidbCluster or component doesn't exist
+type orphan struct {
+	kind string
+	obj  metav1.Object
+	// tc is the TidbCluster of the object, it's nil if the TidbCluster is deleted
+	tc *v1alpha1.TidbCluster
+}
+
+// NewController returns a Controller
+func NewController(deps *controller.Dependencies) *Controller {
+	return &Controller{
+		deps:  deps,
+		kinds: newOrphanKinds(deps),
+	}
+}
+
+func (c *Controller) Run(_ int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	klog.Info("Starting orphan gc controller")
+	defer klog.Info("Shutting down orphan gc controller")
+
+	wait.Until(c.collect, c.deps.CLIConfig.OrphanGCPeriod, stopCh)
+}
+
+// collect finds the orphan objects and reports or deletes them
+func (c *Controller) collect() {
+	orphans, err := c.findOrphans()
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("orphan gc: failed to find orphan objects, error: %v", err))
+		return
+	}
+
+	metrics.OrphanObjects.Reset()
+	for _, o := range orphans {
+		ns, name := o.obj.GetNamespace(), o.obj.GetName()
+		instance := o.obj.GetLabels()[label.InstanceLabelKey]
+		component := o.obj.GetLabels()[label.ComponentLabelKey]
+		if c.shouldDelete(o) {
+			if err := c.deleteOrphan(o); err != nil {
+				utilruntime.HandleError(fmt.Errorf("orphan gc: failed to delete %s %s/%s, error: %v", o.kind, ns, name, err))
+			} else {
+				klog.Infof("orphan gc: %s %s/%s of %s %s is deleted", o.kind, ns, name, component, instance)
+				if o.tc != nil {
+					c.deps.Recorder.Eventf(o.tc, corev1.EventTypeNormal, "OrphanDeleted", "%s %s of removed component %s is deleted", o.kind, name, component)
+				}
+				continue
+			}
+		}
+		klog.Warningf("orphan gc: %s %s/%s of %s %s is orphan", o.kind, ns, name, component, instance)
+		if o.tc != nil {
+			c.deps.Recorder.Eventf(o.tc, corev1.EventTypeWarning, "OrphanFound", "%s %s of removed component %s is orphan", o.kind, name, component)
+		}
+		metrics.OrphanObjects.WithLabelValues(ns, instance, component, o.kind).Inc()
+	}
+}
+
+// findOrphans returns the orphan objects which are created more than one period ago
+func (c *Controller) findOrphans() ([]orphan, error) {
+	selector, err := label.New().Selector()
+	if err != nil {
+		return nil, err
+	}
+	createdBefore := time.Now().Add(-c.deps.CLIConfig.OrphanGCPeriod)
+
+	var orphans []orphan
+	for _, kind := range c.kinds {
+		objs, err := kind.list(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s, error: %v", kind.kind, err)
+		}
+		for _, obj := range objs {
+			if obj.GetDeletionTimestamp() != nil || obj.GetCreationTimestamp().Time.After(createdBefore) {
+				continue
+			}
+			if !c.deps.NamespaceSelector.Selected(obj.GetNamespace()) {
+				continue
+			}
+			isOrphan, tc, err := c.isOrphan(obj)
+			if err != nil {
+				return nil, err
+			}
+			if isOrphan {
+				orphans = append(orphans, orphan{kind: kind.kind, obj: obj, tc: tc})
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// isOrphan returns whether obj is orphan and the TidbCluster of it if the TidbCluster exists
+func (c *Controller) isOrphan(obj metav1.Object) (bool, *v1alpha1.TidbCluster, error) {
+	instance := obj.GetLabels()[label.InstanceLabelKey]
+	component := obj.GetLabels()[label.ComponentLabelKey]
+	if instance == "" {
+		return false, nil, nil
+	}
+	hasComponent, known := componentChecks[component]
+	if !known {
+		// the objects of other components, e.g. the Jobs of backups, have their own owners
+		return false, nil, nil
+	}
+
+	tc, err := c.deps.TiDBClusterLister.TidbClusters(obj.GetNamespace()).Get(instance)
+	if errors.IsNotFound(err) {
+		return true, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	if ref := metav1.GetControllerOf(obj); ref != nil && ref.Kind == v1alpha1.TiDBClusterKind && ref.UID != tc.UID {
+		// the TidbCluster is recreated with the same name
+		return true, nil, nil
+	}
+	return !hasComponent(tc), tc, nil
+}
+
+// shouldDelete returns whether the orphan should be deleted, the PVCs are only deleted if
+// enablePVReclaim of the TidbCluster is true, because they may be retained deliberately
+func (c *Controller) shouldDelete(o orphan) bool {
+	if !c.deps.CLIConfig.IsOrphanGCDelete() {
+		return false
+	}
+	if o.kind == pvcKind {
+		return o.tc != nil && o.tc.IsPVReclaimEnabled()
+	}
+	return true
+}
+
+func (c *Controller) deleteOrphan(o orphan) error {
+	for _, kind := range c.kinds {
+		if kind.kind == o.kind {
+			err := kind.delete(o.obj.GetNamespace(), o.obj.GetName(), o.obj.GetUID())
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+	}
+	return fmt.Errorf("unknown kind %s", o.kind)
+}
+
+// componentChecks returns whether a component is in the spec of a TidbCluster by the value of
+// the component label, the components which are not listed are never collected
+var componentChecks = map[string]func(tc *v1alpha1.TidbCluster) bool{
+	label.DiscoveryLabelVal: func(tc *v1alpha1.TidbCluster) bool { return true },
+	label.PDLabelVal:        func(tc *v1alpha1.TidbCluster) bool { return tc.Spec.PD != nil },
+	label.TiKVLabelVal:      func(tc *v1alpha1.TidbCluster) bool { return tc.Spec.TiKV != nil },
+	label.TiDBLabelVal:      func(tc *v1alpha1.TidbCluster) bool { return tc.Spec.TiDB != nil },
+	label.TiFlashLabelVal:   func(tc *v1alpha1.TidbCluster) bool { return tc.Spec.TiFlash != nil },
+	label.TiCDCLabelVal:     func(tc *v1alpha1.TidbCluster) bool { return tc.Spec.TiCDC != nil },
+	label.PumpLabelVal:      func(tc *v1alpha1.TidbCluster) bool { return tc.Spec.Pump != nil },
+	label.TiProxyLabelVal:   func(tc *v1alpha1.TidbCluster) bool { return tc.Spec.TiProxy != nil },
+}
+
+const pvcKind = "PersistentVolumeClaim"
+
+func newOrphanKinds(deps *controller.Dependencies) []orphanKind {
+	deleteOptions := func(uid types.UID) metav1.DeleteOptions {
+		return metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}}
+	}
+	kubeCli := deps.KubeClientset
+	return []orphanKind{
+		{
+			kind: "Service",
+			list: func(selector labels.Selector) ([]metav1.Object, error) {
+				objs, err := deps.ServiceLister.List(selector)
+				var ret []metav1.Object
+				for _, obj := range objs {
+					ret = append(ret, obj)
+				}
+				return ret, err
+			},
+			delete: func(ns, name string, uid types.UID) error {
+				return kubeCli.CoreV1().Services(ns).Delete(context.TODO(), name, deleteOptions(uid))
+			},
+		},
+		{
+			kind: "ConfigMap",
+			list: func(selector labels.Selector) ([]metav1.Object, error) {
+				objs, err := deps.ConfigMapLister.List(selector)
+				var ret []metav1.Object
+				for _, obj := range objs {
+					ret = append(ret, obj)
+				}
+				return ret, err
+			},
+			delete: func(ns, name string, uid types.UID) error {
+				return kubeCli.CoreV1().ConfigMaps(ns).Delete(context.TODO(), name, deleteOptions(uid))
+			},
+		},
+		{
+			kind: "StatefulSet",
+			list: func(selector labels.Selector) ([]metav1.Object, error) {
+				objs, err := deps.StatefulSetLister.List(selector)
+				var ret []metav1.Object
+				for _, obj := range objs {
+					ret = append(ret, obj)
+				}
+				return ret, err
+			},
+			delete: func(ns, name string, uid types.UID) error {
+				return kubeCli.AppsV1().StatefulSets(ns).Delete(context.TODO(), name, deleteOptions(uid))
+			},
+		},
+		{
+			kind: pvcKind,
+			list: func(selector labels.Selector) ([]metav1.Object, error) {
+				objs, err := deps.PVCLister.List(selector)
+				var ret []metav1.Object
+				for _, obj := range objs {
+					ret = append(ret, obj)
+				}
+				return ret, err
+			},
+			delete: func(ns, name string, uid types.UID) error {
+				return kubeCli.CoreV1().PersistentVolumeClaims(ns).Delete(context.TODO(), name, deleteOptions(uid))
+			},
+		},
+		{
+			kind: "Secret",
+			list: func(selector labels.Selector) ([]metav1.Object, error) {
+				objs, err := deps.SecretLister.List(selector)
+				var ret []metav1.Object
+				for _, obj := range objs {
+					ret = append(ret, obj)
+				}
+				return ret, err
+			},
+			delete: func(ns, name string, uid types.UID) error {
+				return kubeCli.CoreV1().Secrets(ns).Delete(context.TODO(), name, deleteOptions(uid))
+			},
+		},
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orphangc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOrphanGCCollect(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	deps.CLIConfig.OrphanGCPeriod = time.Hour
+	deps.CLIConfig.OrphanGCDelete = true
+	c := NewController(deps)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test", UID: "test"},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{},
+			TiKV: &v1alpha1.TiKVSpec{},
+			TiDB: &v1alpha1.TiDBSpec{},
+		},
+	}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+
+	created := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	meta := func(name, instance string, l label.Label) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Namespace:         corev1.NamespaceDefault,
+			Name:              name,
+			Labels:            l.Instance(instance).Labels(),
+			CreationTimestamp: created,
+		}
+	}
+	addSts := func(set *apps.StatefulSet) {
+		g.Expect(deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(set)).To(Succeed())
+		_, err := deps.KubeClientset.AppsV1().StatefulSets(set.Namespace).Create(context.TODO(), set, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	addSts(&apps.StatefulSet{ObjectMeta: meta("test-tikv", "test", label.New().TiKV())})
+	// TiFlash is removed from the cluster
+	addSts(&apps.StatefulSet{ObjectMeta: meta("test-tiflash", "test", label.New().TiFlash())})
+	// the StatefulSet is just created
+	newSet := &apps.StatefulSet{ObjectMeta: meta("test-ticdc", "test", label.New().TiCDC())}
+	newSet.CreationTimestamp = metav1.Now()
+	addSts(newSet)
+
+	// the cluster is deleted
+	cm := &corev1.ConfigMap{ObjectMeta: meta("deleted-pd", "deleted", label.New().PD())}
+	g.Expect(deps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(cm)).To(Succeed())
+	_, err := deps.KubeClientset.CoreV1().ConfigMaps(cm.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the PVC is not deleted as enablePVReclaim is false
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: meta("tiflash-test-tiflash-0", "test", label.New().TiFlash())}
+	g.Expect(deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)).To(Succeed())
+	_, err = deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	c.collect()
+
+	sets, err := deps.KubeClientset.AppsV1().StatefulSets(corev1.NamespaceDefault).List(context.TODO(), metav1.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	var names []string
+	for _, set := range sets.Items {
+		names = append(names, set.Name)
+	}
+	g.Expect(names).To(ConsistOf("test-tikv", "test-ticdc"))
+	_, err = deps.KubeClientset.CoreV1().ConfigMaps(cm.Namespace).Get(context.TODO(), cm.Name, metav1.GetOptions{})
+	g.Expect(err).To(HaveOccurred())
+	_, err = deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(context.TODO(), pvc.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestOrphanGCReport(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	deps.CLIConfig.OrphanGCPeriod = time.Hour
+	c := NewController(deps)
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         corev1.NamespaceDefault,
+		Name:              "deleted-discovery",
+		Labels:            label.New().Instance("deleted").Discovery().Labels(),
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+	}}
+	g.Expect(deps.KubeInformerFactory.Core().V1().Services().Informer().GetIndexer().Add(svc)).To(Succeed())
+
+	orphans, err := c.findOrphans()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(orphans).To(HaveLen(1))
+	g.Expect(orphans[0].kind).To(Equal("Service"))
+	g.Expect(orphans[0].tc).To(BeNil())
+	g.Expect(c.shouldDelete(orphans[0])).To(BeFalse())
+}
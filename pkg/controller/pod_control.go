@@ -15,6 +15,7 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -25,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -41,6 +43,8 @@ type PodControlInterface interface {
 	UpdateMetaInfo(*v1alpha1.TidbCluster, *corev1.Pod) (*corev1.Pod, error)
 	DeletePod(runtime.Object, *corev1.Pod) error
 	UpdatePod(runtime.Object, *corev1.Pod) (*corev1.Pod, error)
+	// UpdatePodCondition sets the condition in the status of the Pod
+	UpdatePodCondition(runtime.Object, *corev1.Pod, corev1.PodCondition) (*corev1.Pod, error)
 }
 
 type realPodControl struct {
@@ -82,7 +86,7 @@ func (c *realPodControl) UpdatePod(controller runtime.Object, pod *corev1.Pod) (
 	// don't wait due to limited number of clients, but backoff after the default number of steps
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var updateErr error
-		updatePod, updateErr = c.kubeCli.CoreV1().Pods(namespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
+		updatePod, updateErr = c.patchMeta(pod)
 		if updateErr == nil {
 			klog.Infof("Pod: [%s/%s] updated successfully, %s: [%s/%s]", namespace, podName, kind, namespace, name)
 			return nil
@@ -103,6 +107,54 @@ func (c *realPodControl) UpdatePod(controller runtime.Object, pod *corev1.Pod) (
 	return updatePod, err
 }
 
+func (c *realPodControl) UpdatePodCondition(controller runtime.Object, pod *corev1.Pod, condition corev1.PodCondition) (*corev1.Pod, error) {
+	controllerMo, ok := controller.(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a metav1.Object, cannot call setControllerReference", controller)
+	}
+	kind := controller.GetObjectKind().GroupVersionKind().Kind
+	name := controllerMo.GetName()
+	namespace := controllerMo.GetNamespace()
+	podName := pod.GetName()
+
+	status := pod.Status.DeepCopy()
+	SetPodCondition(status, condition)
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == condition.Type {
+			condition = status.Conditions[i]
+		}
+	}
+	// the conditions are merged by type, so only the condition is sent to leave the others as is
+	data, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{"conditions": []corev1.PodCondition{condition}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	updatePod, err := c.kubeCli.CoreV1().Pods(namespace).Patch(context.TODO(), podName, types.StrategicMergePatchType, data, metav1.PatchOptions{}, "status")
+	if err != nil {
+		klog.Errorf("failed to update the status of Pod: [%s/%s], error: %v", namespace, podName, err)
+		return nil, err
+	}
+	klog.Infof("Pod: [%s/%s] condition %s is set to %s, %s: [%s/%s]", namespace, podName, condition.Type, condition.Status, kind, namespace, name)
+	return updatePod, nil
+}
+
+// SetPodCondition sets the condition in status, the LastTransitionTime is kept if the status of the condition is not changed
+func SetPodCondition(status *corev1.PodStatus, condition corev1.PodCondition) {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type != condition.Type {
+			continue
+		}
+		if status.Conditions[i].Status == condition.Status {
+			condition.LastTransitionTime = status.Conditions[i].LastTransitionTime
+		}
+		status.Conditions[i] = condition
+		return
+	}
+	status.Conditions = append(status.Conditions, condition)
+}
+
 func (c *realPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Pod) (*corev1.Pod, error) {
 	ns := pod.GetNamespace()
 	podName := pod.GetName()
@@ -177,7 +229,7 @@ func (c *realPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Po
 	var updatePod *corev1.Pod
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
-		updatePod, updateErr = c.kubeCli.CoreV1().Pods(ns).Update(context.TODO(), pod, metav1.UpdateOptions{})
+		updatePod, updateErr = c.patchMeta(pod)
 		if updateErr == nil {
 			klog.V(4).Infof("update pod %s/%s with cluster labels %v successfully, TidbCluster: %s", ns, podName, labels, tcName)
 			return nil
@@ -197,6 +249,16 @@ func (c *realPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Po
 	return updatePod, err
 }
 
+// patchMeta writes the labels and annotations of the Pod, the cached Pods are not updated as a
+// whole because their unused fields are stripped
+func (c *realPodControl) patchMeta(pod *corev1.Pod) (*corev1.Pod, error) {
+	data, err := MetaPatch(pod)
+	if err != nil {
+		return nil, err
+	}
+	return c.kubeCli.CoreV1().Pods(pod.GetNamespace()).Patch(context.TODO(), pod.GetName(), types.JSONPatchType, data, metav1.PatchOptions{})
+}
+
 func (c *realPodControl) DeletePod(controller runtime.Object, pod *corev1.Pod) error {
 	controllerMo, ok := controller.(metav1.Object)
 	if !ok {
@@ -348,4 +410,16 @@ func (c *FakePodControl) UpdatePod(_ runtime.Object, pod *corev1.Pod) (*corev1.P
 	return pod, c.PodIndexer.Update(pod)
 }
 
+func (c *FakePodControl) UpdatePodCondition(_ runtime.Object, pod *corev1.Pod, condition corev1.PodCondition) (*corev1.Pod, error) {
+	defer c.updatePodTracker.Inc()
+	if c.updatePodTracker.ErrorReady() {
+		defer c.updatePodTracker.Reset()
+		return nil, c.updatePodTracker.GetError()
+	}
+
+	pod = pod.DeepCopy()
+	SetPodCondition(&pod.Status, condition)
+	return pod, c.PodIndexer.Update(pod)
+}
+
 var _ PodControlInterface = &FakePodControl{}
@@ -14,10 +14,12 @@
 package controller
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -25,10 +27,12 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
 	kubefake "k8s.io/client-go/kubernetes/fake"
@@ -100,13 +104,13 @@ func TestPodControlUpdateMetaInfo(t *testing.T) {
 					"333": {PodName: TestPodName, ID: "333"},
 				}
 				conflict := false
-				fakeClient.AddReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
-					update := action.(core.UpdateAction)
+				fakeClient.AddReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
 					if !conflict {
 						conflict = true
 						return true, oldPod, apierrors.NewConflict(action.GetResource().GroupResource(), pod.Name, errors.New("conflict"))
 					}
-					return true, update.GetObject(), nil
+					patched, err := applyPodPatch(oldPod, action)
+					return true, patched, err
 				})
 			},
 			expectFn: func(g *GomegaWithT, b bool) {
@@ -193,7 +197,7 @@ func TestPodControlUpdateMetaInfoSuccess(t *testing.T) {
 		return storesInfo, nil
 	})
 
-	fakeClient.AddReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
+	fakeClient.AddReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, nil
 	})
 	_, err := control.UpdateMetaInfo(tc, pod)
@@ -236,7 +240,7 @@ func TestPodControlUpdateMetaInfoGetClusterFailed(t *testing.T) {
 		return storesInfo, nil
 	})
 
-	fakeClient.AddReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
+	fakeClient.AddReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, nil
 	})
 	_, err := control.UpdateMetaInfo(tc, pod)
@@ -282,7 +286,7 @@ func TestPodControlUpdateMetaInfoUpdatePodFailed(t *testing.T) {
 		return storesInfo, nil
 	})
 
-	fakeClient.AddReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
+	fakeClient.AddReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, apierrors.NewInternalError(errors.New("API server down"))
 	})
 	_, err := control.UpdateMetaInfo(tc, pod)
@@ -297,9 +301,9 @@ func TestPodControlUpdatePod(t *testing.T) {
 	pod.Labels = map[string]string{"a": "b"}
 	fakeClient, pdControl, podLister, _, recorder := newFakeClientRecorderAndPDControl()
 	control := NewRealPodControl(fakeClient, pdControl, podLister, recorder)
-	fakeClient.AddReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
-		update := action.(core.UpdateAction)
-		return true, update.GetObject(), nil
+	fakeClient.AddReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		patched, err := applyPodPatch(newPod(tc), action)
+		return true, patched, err
 	})
 	updatePod, err := control.UpdatePod(tc, pod)
 	g.Expect(err).To(Succeed())
@@ -319,13 +323,13 @@ func TestPodControlUpdatePodConflictSuccess(t *testing.T) {
 	err := podIndexer.Add(oldPod)
 	g.Expect(err).To(Succeed())
 	conflict := false
-	fakeClient.AddReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
-		update := action.(core.UpdateAction)
+	fakeClient.AddReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		if !conflict {
 			conflict = true
 			return true, oldPod, apierrors.NewConflict(action.GetResource().GroupResource(), pod.Name, errors.New("conflict"))
 		}
-		return true, update.GetObject(), nil
+		patched, err := applyPodPatch(oldPod, action)
+		return true, patched, err
 	})
 	updatePod, err := control.UpdatePod(tc, pod)
 	g.Expect(err).To(Succeed())
@@ -333,6 +337,61 @@ func TestPodControlUpdatePodConflictSuccess(t *testing.T) {
 	g.Expect(updatePod.Labels["a"]).To(Equal("b"))
 }
 
+func TestPodControlUpdatePodCondition(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbCluster()
+	pod := newPod(tc)
+	fakeClient, pdControl, podLister, _, recorder := newFakeClientRecorderAndPDControl()
+	control := NewRealPodControl(fakeClient, pdControl, podLister, recorder)
+	ready := corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionTrue}
+	pod.Status.Conditions = []corev1.PodCondition{ready}
+	var subresource string
+	fakeClient.AddReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		subresource = action.GetSubresource()
+		patched, err := applyPodPatch(pod, action)
+		return true, patched, err
+	})
+	condition := corev1.PodCondition{Type: v1alpha1.TiDBWarmedUp, Status: corev1.ConditionTrue}
+	updatePod, err := control.UpdatePodCondition(tc, pod, condition)
+	g.Expect(err).To(Succeed())
+	// the conditions set by others are kept
+	g.Expect(updatePod.Status.Conditions).To(ConsistOf(ready, condition))
+	g.Expect(pod.Status.Conditions).To(ConsistOf(ready))
+	// the condition is set by the status subresource, which is granted by the "pods/status" rule
+	g.Expect(subresource).To(Equal("status"))
+	g.Expect(baseRBACRules).To(ContainElement(rbacv1.PolicyRule{
+		APIGroups: []string{""},
+		Resources: []string{"pods/status"},
+		Verbs:     []string{"update", "patch"},
+	}))
+}
+
+// applyPodPatch applies the patch of the action to a copy of the pod
+func applyPodPatch(pod *corev1.Pod, action core.Action) (*corev1.Pod, error) {
+	patch := action.(core.PatchAction)
+	data, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	switch patch.GetPatchType() {
+	case types.JSONPatchType:
+		p, err := jsonpatch.DecodePatch(patch.GetPatch())
+		if err != nil {
+			return nil, err
+		}
+		data, err = p.Apply(data)
+	case types.StrategicMergePatchType:
+		data, err = strategicpatch.StrategicMergePatch(data, patch.GetPatch(), corev1.Pod{})
+	default:
+		err = fmt.Errorf("unexpected patch type %s", patch.GetPatchType())
+	}
+	if err != nil {
+		return nil, err
+	}
+	patched := &corev1.Pod{}
+	return patched, json.Unmarshal(data, patched)
+}
+
 func newFakeClientRecorderAndPDControl() (*fake.Clientset, *pdapi.FakePDControl, corelisters.PodLister, cache.Indexer, *record.FakeRecorder) {
 	fakeClient := &fake.Clientset{}
 	kubeCli := kubefake.NewSimpleClientset()
@@ -14,9 +14,11 @@
 package controller
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -41,9 +43,9 @@ func TestPVCControlUpdateMetaInfoSuccess(t *testing.T) {
 	fakeClient, pvcLister, _, recorder := newFakeClientAndRecorder()
 	control := NewRealPVCControl(fakeClient, recorder, pvcLister)
 
-	fakeClient.AddReactor("update", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
-		update := action.(core.UpdateAction)
-		return true, update.GetObject(), nil
+	fakeClient.AddReactor("patch", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+		patched, err := applyPVCPatch(newPVC(tc), action)
+		return true, patched, err
 	})
 	updatePVC, err := control.UpdateMetaInfo(tc, pvc, pod)
 	g.Expect(err).To(Succeed())
@@ -57,7 +59,7 @@ func TestPVCControlUpdateMetaInfoFailed(t *testing.T) {
 	pod := newPod(tc)
 	fakeClient, pvcLister, _, recorder := newFakeClientAndRecorder()
 	control := NewRealPVCControl(fakeClient, recorder, pvcLister)
-	fakeClient.AddReactor("update", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+	fakeClient.AddReactor("patch", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, apierrors.NewInternalError(errors.New("API server down"))
 	})
 	_, err := control.UpdateMetaInfo(tc, pvc, pod)
@@ -74,13 +76,13 @@ func TestPVCControlUpdateMetaInfoConflictSuccess(t *testing.T) {
 	pvcIndexer.Add(oldPVC)
 	control := NewRealPVCControl(fakeClient, recorder, pvcLister)
 	conflict := false
-	fakeClient.AddReactor("update", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
-		update := action.(core.UpdateAction)
+	fakeClient.AddReactor("patch", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		if !conflict {
 			conflict = true
 			return true, oldPVC, apierrors.NewConflict(action.GetResource().GroupResource(), pvc.Name, errors.New("conflict"))
 		}
-		return true, update.GetObject(), nil
+		patched, err := applyPVCPatch(oldPVC, action)
+		return true, patched, err
 	})
 	updatePVC, err := control.UpdateMetaInfo(tc, pvc, pod)
 	g.Expect(err).To(Succeed())
@@ -95,9 +97,9 @@ func TestPVCControlUpdatePVCSuccess(t *testing.T) {
 	fakeClient, pvcLister, _, recorder := newFakeClientAndRecorder()
 	control := NewRealPVCControl(fakeClient, recorder, pvcLister)
 
-	fakeClient.AddReactor("update", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
-		update := action.(core.UpdateAction)
-		return true, update.GetObject(), nil
+	fakeClient.AddReactor("patch", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+		patched, err := applyPVCPatch(newPVC(tc), action)
+		return true, patched, err
 	})
 	updatePVC, err := control.UpdatePVC(tc, pvc)
 	g.Expect(err).To(Succeed())
@@ -110,7 +112,7 @@ func TestPVCControlUpdatePVCFailed(t *testing.T) {
 	pvc := newPVC(tc)
 	fakeClient, pvcLister, _, recorder := newFakeClientAndRecorder()
 	control := NewRealPVCControl(fakeClient, recorder, pvcLister)
-	fakeClient.AddReactor("update", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+	fakeClient.AddReactor("patch", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, apierrors.NewInternalError(errors.New("API server down"))
 	})
 	_, err := control.UpdatePVC(tc, pvc)
@@ -127,19 +129,36 @@ func TestPVCControlUpdatePVCConflictSuccess(t *testing.T) {
 	pvcIndexer.Add(oldPVC)
 	control := NewRealPVCControl(fakeClient, recorder, pvcLister)
 	conflict := false
-	fakeClient.AddReactor("update", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
-		update := action.(core.UpdateAction)
+	fakeClient.AddReactor("patch", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		if !conflict {
 			conflict = true
 			return true, oldPVC, apierrors.NewConflict(action.GetResource().GroupResource(), pvc.Name, errors.New("conflict"))
 		}
-		return true, update.GetObject(), nil
+		patched, err := applyPVCPatch(oldPVC, action)
+		return true, patched, err
 	})
 	updatePVC, err := control.UpdatePVC(tc, pvc)
 	g.Expect(err).To(Succeed())
 	g.Expect(updatePVC.Annotations["a"]).To(Equal("b"))
 }
 
+// applyPVCPatch applies the JSON patch of the action to a copy of the pvc
+func applyPVCPatch(pvc *corev1.PersistentVolumeClaim, action core.Action) (*corev1.PersistentVolumeClaim, error) {
+	p, err := jsonpatch.DecodePatch(action.(core.PatchAction).GetPatch())
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(pvc)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = p.Apply(data); err != nil {
+		return nil, err
+	}
+	patched := &corev1.PersistentVolumeClaim{}
+	return patched, json.Unmarshal(data, patched)
+}
+
 func newFakeClientAndRecorder() (*fake.Clientset, corelisters.PersistentVolumeClaimLister, cache.Indexer, *record.FakeRecorder) {
 	kubeCli := &fake.Clientset{}
 	recorder := record.NewFakeRecorder(10)
@@ -0,0 +1,101 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/features"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+var (
+	// baseRBACRules are required by tidb-controller-manager in any case
+	baseRBACRules = []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"services", "events"}, Verbs: []string{"*"}},
+		{APIGroups: []string{""}, Resources: []string{"endpoints", "configmaps"}, Verbs: []string{"create", "get", "list", "watch", "update", "delete"}},
+		{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"create", "get", "update", "delete"}},
+		{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"get", "list", "watch", "create", "update", "delete"}},
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"create", "update", "get", "list", "watch", "delete"}},
+		{APIGroups: []string{""}, Resources: []string{"persistentvolumeclaims"}, Verbs: []string{"get", "list", "watch", "create", "update", "delete", "patch"}},
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch", "update", "delete"}},
+		{APIGroups: []string{""}, Resources: []string{"pods/status"}, Verbs: []string{"update", "patch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"statefulsets", "deployments", "controllerrevisions"}, Verbs: []string{"*"}},
+		{APIGroups: []string{"extensions", "networking.k8s.io"}, Resources: []string{"ingresses"}, Verbs: []string{"*"}},
+		{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"networkpolicies"}, Verbs: []string{"get", "create", "update", "delete"}},
+		{APIGroups: []string{""}, Resources: []string{"resourcequotas", "limitranges"}, Verbs: []string{"get", "list", "watch", "create", "update", "delete"}},
+		{APIGroups: []string{"monitoring.coreos.com"}, Resources: []string{"podmonitors"}, Verbs: []string{"get", "list", "watch", "create", "update", "delete"}},
+		{APIGroups: []string{"externaldns.k8s.io"}, Resources: []string{"dnsendpoints"}, Verbs: []string{"get", "create", "update", "delete"}},
+		{APIGroups: []string{"pingcap.com"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+	}
+
+	advancedStatefulSetRBACRule = rbacv1.PolicyRule{APIGroups: []string{"apps.pingcap.com"}, Resources: []string{"statefulsets", "statefulsets/status"}, Verbs: []string{"*"}}
+	namespaceRBACRule           = rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "list", "watch"}}
+	nodeRBACRule                = rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get", "list", "watch"}}
+	pvRBACRule                  = rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"persistentvolumes"}, Verbs: []string{"get", "list", "watch", "patch", "update"}}
+	scRBACRule                  = rbacv1.PolicyRule{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses"}, Verbs: []string{"get", "list", "watch"}}
+	metricsRBACRule             = rbacv1.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}}
+)
+
+// RBACRules returns the least-privilege RBAC rules required by tidb-controller-manager
+// running with the config and the feature gate, the optional permissions are only
+// included if the features using them are enabled.
+//
+// The namespaced rules are granted by a Role in the namespace of tidb-operator, and the
+// cluster rules by a ClusterRole. If ClusterScoped is true, all rules are cluster rules
+// and the namespaced rules are empty.
+//
+// The optional permissions are:
+//   - nodes: labeling the stores and TiDB pods with the topology of the nodes, and
+//     checking the nodes for the performance profiles, and checking whether the nodes
+//     are cordoned before rescheduling the pods of NodeMaintenances
+//   - persistentvolumes: setting the reclaim policy and the meta info of the PVs
+//   - storageclasses: checking whether the volumes can be expanded by the PVC resizer
+//
+// Without them, tidb-controller-manager skips the relevant operations instead of failing.
+func (c *CLIConfig) RBACRules(fg features.FeatureGate) (namespaced []rbacv1.PolicyRule, cluster []rbacv1.PolicyRule) {
+	namespaced = append(namespaced, baseRBACRules...)
+	if fg.Enabled(features.AdvancedStatefulSet) {
+		namespaced = append(namespaced, advancedStatefulSetRBACRule)
+	}
+
+	// the rules to create the RBAC objects of discovery and TidbMonitor, tidb-controller-manager
+	// is allowed to escalate its privileges to them, which never have privileges over it
+	rbacResources := []string{"roles"}
+	bindingResources := []string{"rolebindings"}
+	if c.ClusterScoped {
+		rbacResources = append(rbacResources, "clusterroles")
+		bindingResources = append(bindingResources, "clusterrolebindings")
+	}
+	namespaced = append(namespaced,
+		rbacv1.PolicyRule{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: rbacResources, Verbs: []string{"escalate", "create", "get", "update", "delete"}},
+		rbacv1.PolicyRule{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: bindingResources, Verbs: []string{"create", "get", "update", "delete"}},
+	)
+
+	if c.ClusterScoped {
+		cluster, namespaced = namespaced, nil
+		cluster = append(cluster, metricsRBACRule)
+		if c.NamespaceSelector != "" {
+			cluster = append(cluster, namespaceRBACRule)
+		}
+	}
+	if c.HasNodePermission() {
+		cluster = append(cluster, nodeRBACRule)
+	}
+	if c.HasPVPermission() {
+		cluster = append(cluster, pvRBACRule)
+	}
+	if c.HasSCPermission() {
+		cluster = append(cluster, scRBACRule)
+	}
+	return namespaced, cluster
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestRBACRules(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	hasResource := func(rules []rbacv1.PolicyRule, resource string) bool {
+		for _, rule := range rules {
+			for _, r := range rule.Resources {
+				if r == resource {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	tests := []struct {
+		name          string
+		setup         func(cfg *CLIConfig)
+		features      string
+		namespaced    []string
+		notNamespaced []string
+		cluster       []string
+		notCluster    []string
+	}{
+		{
+			name:       "cluster scoped",
+			setup:      func(cfg *CLIConfig) {},
+			cluster:    []string{"pods", "pods/status", "resourcequotas", "limitranges", "tidbclusters", "clusterroles", "nodes", "persistentvolumes", "storageclasses"},
+			notCluster: []string{"namespaces", "statefulsets/status"},
+		},
+		{
+			name: "cluster scoped with namespace selector and advanced statefulset",
+			setup: func(cfg *CLIConfig) {
+				cfg.NamespaceSelector = "tenant=a"
+			},
+			features: "AdvancedStatefulSet=true",
+			cluster:  []string{"namespaces", "statefulsets/status"},
+		},
+		{
+			name: "namespace scoped",
+			setup: func(cfg *CLIConfig) {
+				cfg.ClusterScoped = false
+			},
+			namespaced:    []string{"pods", "pods/status", "resourcequotas", "limitranges", "roles"},
+			notNamespaced: []string{"clusterroles", "nodes"},
+			notCluster:    []string{"nodes", "persistentvolumes", "storageclasses"},
+		},
+		{
+			name: "namespace scoped with node permission",
+			setup: func(cfg *CLIConfig) {
+				cfg.ClusterScoped = false
+				cfg.ClusterPermissionNode = true
+			},
+			namespaced: []string{"pods"},
+			cluster:    []string{"nodes"},
+			notCluster: []string{"pods", "persistentvolumes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultCLIConfig()
+			tt.setup(cfg)
+			fg := features.NewDefaultFeatureGate()
+			if tt.features != "" {
+				g.Expect(fg.Set(tt.features)).To(Succeed())
+			}
+
+			namespaced, cluster := cfg.RBACRules(fg)
+			if cfg.ClusterScoped {
+				g.Expect(namespaced).To(BeEmpty())
+			}
+			for _, r := range tt.namespaced {
+				g.Expect(hasResource(namespaced, r)).To(BeTrue(), "namespaced rules should include %s", r)
+			}
+			for _, r := range tt.notNamespaced {
+				g.Expect(hasResource(namespaced, r)).To(BeFalse(), "namespaced rules should not include %s", r)
+			}
+			for _, r := range tt.cluster {
+				g.Expect(hasResource(cluster, r)).To(BeTrue(), "cluster rules should include %s", r)
+			}
+			for _, r := range tt.notCluster {
+				g.Expect(hasResource(cluster, r)).To(BeFalse(), "cluster rules should not include %s", r)
+			}
+		})
+	}
+}
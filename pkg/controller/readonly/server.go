@@ -0,0 +1,164 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readonly
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// Server serves the read-only endpoints of tidb-controller-manager from the informer caches.
+// The endpoints are served by all replicas whether they're the leader or not, so that the
+// diagnostics don't depend on which replica is elected.
+type Server struct {
+	deps     *controller.Dependencies
+	identity string
+	// leader and synced are 1 if true, atomic.Bool is not available in go 1.13
+	leader int32
+	synced int32
+}
+
+// LeaderInfo is the response of /leader
+type LeaderInfo struct {
+	Identity string `json:"identity"`
+	Leader   bool   `json:"leader"`
+}
+
+// TidbClusterInfo is the summary of a TidbCluster in the response of /diagnostics/tidbclusters
+type TidbClusterInfo struct {
+	Namespace string                         `json:"namespace"`
+	Name      string                         `json:"name"`
+	Ready     corev1.ConditionStatus         `json:"ready"`
+	Reason    string                         `json:"reason,omitempty"`
+	Message   string                         `json:"message,omitempty"`
+	Phases    map[v1alpha1.MemberType]string `json:"phases,omitempty"`
+}
+
+// NewServer returns a Server, identity is the identity of the replica in leader election
+func NewServer(deps *controller.Dependencies, identity string) *Server {
+	return &Server{
+		deps:     deps,
+		identity: identity,
+	}
+}
+
+// SetLeader records whether the replica is the leader
+func (s *Server) SetLeader(leader bool) {
+	if leader {
+		atomic.StoreInt32(&s.leader, 1)
+		metrics.Leader.Set(1)
+	} else {
+		atomic.StoreInt32(&s.leader, 0)
+		metrics.Leader.Set(0)
+	}
+}
+
+// IsLeader returns whether the replica is the leader
+func (s *Server) IsLeader() bool {
+	return atomic.LoadInt32(&s.leader) == 1
+}
+
+// SetCacheSynced records that the informer caches are synced
+func (s *Server) SetCacheSynced() {
+	atomic.StoreInt32(&s.synced, 1)
+}
+
+// CacheSynced returns whether the informer caches are synced
+func (s *Server) CacheSynced() bool {
+	return atomic.LoadInt32(&s.synced) == 1
+}
+
+// Register registers the endpoints to mux:
+//
+//   - /healthz: the process is alive
+//   - /readyz: the informer caches are synced
+//   - /leader: whether the replica is the leader
+//   - /diagnostics/tidbclusters: the readiness and the phases of the components of the TidbClusters
+func (s *Server) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !s.CacheSynced() {
+			http.Error(w, "informer caches are not synced", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/leader", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, LeaderInfo{Identity: s.identity, Leader: s.IsLeader()})
+	})
+	mux.HandleFunc("/diagnostics/tidbclusters", s.serveTidbClusters)
+}
+
+func (s *Server) serveTidbClusters(w http.ResponseWriter, _ *http.Request) {
+	if !s.CacheSynced() {
+		http.Error(w, "informer caches are not synced", http.StatusServiceUnavailable)
+		return
+	}
+	tcs, err := s.deps.TiDBClusterLister.List(labels.Everything())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	infos := make([]TidbClusterInfo, 0, len(tcs))
+	for _, tc := range tcs {
+		infos = append(infos, newTidbClusterInfo(tc))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Namespace != infos[j].Namespace {
+			return infos[i].Namespace < infos[j].Namespace
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	writeJSON(w, infos)
+}
+
+func newTidbClusterInfo(tc *v1alpha1.TidbCluster) TidbClusterInfo {
+	info := TidbClusterInfo{
+		Namespace: tc.Namespace,
+		Name:      tc.Name,
+		Ready:     corev1.ConditionUnknown,
+		Phases:    map[v1alpha1.MemberType]string{},
+	}
+	if cond := utiltidbcluster.GetTidbClusterReadyCondition(tc.Status); cond != nil {
+		info.Ready = cond.Status
+		info.Reason = cond.Reason
+		info.Message = cond.Message
+	}
+	for _, c := range tc.AllComponentStatus() {
+		info.Phases[c.MemberType()] = string(c.GetPhase())
+	}
+	return info
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.Errorf("failed to write response: %v", err)
+	}
+}
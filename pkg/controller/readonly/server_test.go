@@ -0,0 +1,88 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readonly
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	s := NewServer(deps, "tidb-controller-manager-0")
+	mux := http.NewServeMux()
+	s.Register(mux)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	g.Expect(get("/healthz").Code).To(Equal(http.StatusOK))
+	g.Expect(get("/readyz").Code).To(Equal(http.StatusServiceUnavailable))
+	g.Expect(get("/diagnostics/tidbclusters").Code).To(Equal(http.StatusServiceUnavailable))
+
+	var leader LeaderInfo
+	w := get("/leader")
+	g.Expect(json.Unmarshal(w.Body.Bytes(), &leader)).To(Succeed())
+	g.Expect(leader).To(Equal(LeaderInfo{Identity: "tidb-controller-manager-0", Leader: false}))
+	s.SetLeader(true)
+	w = get("/leader")
+	g.Expect(json.Unmarshal(w.Body.Bytes(), &leader)).To(Succeed())
+	g.Expect(leader.Leader).To(BeTrue())
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "basic"},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{},
+			TiKV: &v1alpha1.TiKVSpec{},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			PD:   v1alpha1.PDStatus{Phase: v1alpha1.NormalPhase},
+			TiKV: v1alpha1.TiKVStatus{Phase: v1alpha1.ScalePhase},
+			Conditions: []v1alpha1.TidbClusterCondition{
+				{Type: v1alpha1.TidbClusterReady, Status: corev1.ConditionFalse, Reason: "TiKVStoreNotUp"},
+			},
+		},
+	}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+
+	s.SetCacheSynced()
+	g.Expect(get("/readyz").Code).To(Equal(http.StatusOK))
+	var infos []TidbClusterInfo
+	w = get("/diagnostics/tidbclusters")
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+	g.Expect(json.Unmarshal(w.Body.Bytes(), &infos)).To(Succeed())
+	g.Expect(infos).To(Equal([]TidbClusterInfo{{
+		Namespace: corev1.NamespaceDefault,
+		Name:      "basic",
+		Ready:     corev1.ConditionFalse,
+		Reason:    "TiKVStoreNotUp",
+		Phases: map[v1alpha1.MemberType]string{
+			v1alpha1.PDMemberType:   string(v1alpha1.NormalPhase),
+			v1alpha1.TiKVMemberType: string(v1alpha1.ScalePhase),
+		},
+	}}))
+}
@@ -71,17 +71,13 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	klog.Info("Starting restore controller")
 	defer klog.Info("Shutting down restore controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
+	// the number of the workers follows the workers flag reloaded from the config file
+	wg := controller.RunWorkers(workers, c.deps.CLIConfig, c.processNextWorkItem, stopCh)
 
 	<-stopCh
-}
-
-// worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
+	// wait for the in-flight syncs to finish, so that no operation is left half-done
+	c.queue.ShutDown()
+	wg.Wait()
 }
 
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
@@ -119,6 +115,10 @@ func (c *Controller) sync(key string) error {
 	if err != nil {
 		return err
 	}
+	if !c.deps.NamespaceSelector.Selected(ns) {
+		klog.V(4).Infof("Restore %q is not in the selected namespaces, skip syncing", key)
+		return nil
+	}
 	restore, err := c.deps.RestoreLister.Restores(ns).Get(name)
 	if errors.IsNotFound(err) {
 		klog.Infof("Restore has been deleted %v", key)
@@ -0,0 +1,113 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// RestoreScheduleStatusUpdaterInterface is an interface used to update the RestoreScheduleStatus associated with a RestoreSchedule.
+// For any use other than testing, clients should create an instance using NewRealRestoreScheduleStatusUpdater.
+type RestoreScheduleStatusUpdaterInterface interface {
+	// UpdateRestoreScheduleStatus sets the restoreSchedule's Status to status. Implementations are required to retry on conflicts,
+	// but fail on other errors. If the returned error is nil restoreSchedule's Status has been successfully set to status.
+	UpdateRestoreScheduleStatus(*v1alpha1.RestoreSchedule, *v1alpha1.RestoreScheduleStatus, *v1alpha1.RestoreScheduleStatus) error
+}
+
+// returns a RestoreScheduleStatusUpdaterInterface that updates the Status of a RestoreSchedule,
+// using the supplied client and rsLister.
+func NewRealRestoreScheduleStatusUpdater(deps *Dependencies) RestoreScheduleStatusUpdaterInterface {
+	return &realRestoreScheduleStatusUpdater{
+		deps: deps,
+	}
+}
+
+type realRestoreScheduleStatusUpdater struct {
+	deps *Dependencies
+}
+
+func (u *realRestoreScheduleStatusUpdater) UpdateRestoreScheduleStatus(
+	rs *v1alpha1.RestoreSchedule,
+	newStatus *v1alpha1.RestoreScheduleStatus,
+	oldStatus *v1alpha1.RestoreScheduleStatus) error {
+
+	ns := rs.GetNamespace()
+	rsName := rs.GetName()
+	// don't wait due to limited number of clients, but backoff after the default number of steps
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		_, updateErr := u.deps.Clientset.PingcapV1alpha1().RestoreSchedules(ns).Update(context.TODO(), rs, metav1.UpdateOptions{})
+		if updateErr == nil {
+			klog.Infof("RestoreSchedule: [%s/%s] updated successfully", ns, rsName)
+			return nil
+		}
+		if updated, err := u.deps.RestoreScheduleLister.RestoreSchedules(ns).Get(rsName); err == nil {
+			// make a copy so we don't mutate the shared cache
+			rs = updated.DeepCopy()
+			rs.Status = *newStatus
+		} else {
+			utilruntime.HandleError(fmt.Errorf("error getting updated restoreSchedule %s/%s from lister: %v", ns, rsName, err))
+		}
+
+		return updateErr
+	})
+	return err
+}
+
+var _ RestoreScheduleStatusUpdaterInterface = &realRestoreScheduleStatusUpdater{}
+
+// FakeRestoreScheduleStatusUpdater is a fake RestoreScheduleStatusUpdaterInterface
+type FakeRestoreScheduleStatusUpdater struct {
+	RsLister        listers.RestoreScheduleLister
+	RsIndexer       cache.Indexer
+	updateRsTracker RequestTracker
+}
+
+// NewFakeRestoreScheduleStatusUpdater returns a FakeRestoreScheduleStatusUpdater
+func NewFakeRestoreScheduleStatusUpdater(rsInformer informers.RestoreScheduleInformer) *FakeRestoreScheduleStatusUpdater {
+	return &FakeRestoreScheduleStatusUpdater{
+		rsInformer.Lister(),
+		rsInformer.Informer().GetIndexer(),
+		RequestTracker{},
+	}
+}
+
+// SetUpdateRestoreScheduleError sets the error attributes of updateRsTracker
+func (u *FakeRestoreScheduleStatusUpdater) SetUpdateRestoreScheduleError(err error, after int) {
+	u.updateRsTracker.err = err
+	u.updateRsTracker.after = after
+	u.updateRsTracker.SetError(err).SetAfter(after)
+}
+
+// UpdateRestoreSchedule updates the RestoreSchedule
+func (u *FakeRestoreScheduleStatusUpdater) UpdateRestoreScheduleStatus(rs *v1alpha1.RestoreSchedule, _ *v1alpha1.RestoreScheduleStatus, _ *v1alpha1.RestoreScheduleStatus) error {
+	defer u.updateRsTracker.Inc()
+	if u.updateRsTracker.ErrorReady() {
+		defer u.updateRsTracker.Reset()
+		return u.updateRsTracker.GetError()
+	}
+
+	return u.RsIndexer.Update(rs)
+}
+
+var _ RestoreScheduleStatusUpdaterInterface = &FakeRestoreScheduleStatusUpdater{}
@@ -39,6 +39,8 @@ type RestoreUpdateStatus struct {
 	TimeCompleted *metav1.Time
 	// CommitTs is the snapshot time point of tidb cluster.
 	CommitTs *string
+	// ChecksumVerification is the result of the checksum verification.
+	ChecksumVerification *v1alpha1.RestoreChecksumVerification
 }
 
 // RestoreConditionUpdaterInterface enables updating Restore conditions.
@@ -107,6 +109,9 @@ func updateRestoreStatus(status *v1alpha1.RestoreStatus, newStatus *RestoreUpdat
 	if newStatus.CommitTs != nil {
 		status.CommitTs = *newStatus.CommitTs
 	}
+	if newStatus.ChecksumVerification != nil {
+		status.ChecksumVerification = newStatus.ChecksumVerification
+	}
 }
 
 var _ RestoreConditionUpdaterInterface = &realRestoreConditionUpdater{}
@@ -0,0 +1,101 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restoreschedule
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup"
+	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ControlInterface implements the control logic for updating RestoreSchedule
+// It is implemented as an interface to allow for extensions that provide different semantics.
+// Currently, there is only one implementation.
+type ControlInterface interface {
+	// UpdateRestoreSchedule implements the control logic for restore schedule
+	UpdateRestoreSchedule(restoreSchedule *v1alpha1.RestoreSchedule) error
+}
+
+// NewDefaultRestoreScheduleControl returns a new instance of the default implementation RestoreScheduleControlInterface that
+// implements the documented semantics for RestoreSchedule.
+func NewDefaultRestoreScheduleControl(statusUpdater controller.RestoreScheduleStatusUpdaterInterface, rsManager backup.RestoreScheduleManager) ControlInterface {
+	return &defaultRestoreScheduleControl{
+		statusUpdater: statusUpdater,
+		rsManager:     rsManager,
+	}
+}
+
+type defaultRestoreScheduleControl struct {
+	statusUpdater controller.RestoreScheduleStatusUpdaterInterface
+	rsManager     backup.RestoreScheduleManager
+}
+
+// UpdateRestoreSchedule executes the core logic loop for a RestoreSchedule.
+func (c *defaultRestoreScheduleControl) UpdateRestoreSchedule(rs *v1alpha1.RestoreSchedule) error {
+	var errs []error
+	oldStatus := rs.Status.DeepCopy()
+
+	if err := c.updateRestoreSchedule(rs); err != nil {
+		errs = append(errs, err)
+	}
+	if apiequality.Semantic.DeepEqual(&rs.Status, oldStatus) {
+		return errorutils.NewAggregate(errs)
+	}
+	if err := c.statusUpdater.UpdateRestoreScheduleStatus(rs.DeepCopy(), &rs.Status, oldStatus); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errorutils.NewAggregate(errs)
+}
+
+func (c *defaultRestoreScheduleControl) updateRestoreSchedule(rs *v1alpha1.RestoreSchedule) error {
+	return c.rsManager.Sync(rs)
+}
+
+var _ ControlInterface = &defaultRestoreScheduleControl{}
+
+// FakeRestoreScheduleControl is a fake RestoreScheduleControlInterface
+type FakeRestoreScheduleControl struct {
+	rsIndexer       cache.Indexer
+	updateRsTracker controller.RequestTracker
+}
+
+// NewFakeRestoreScheduleControl returns a FakeRestoreScheduleControl
+func NewFakeRestoreScheduleControl(rsInformer informers.RestoreScheduleInformer) *FakeRestoreScheduleControl {
+	return &FakeRestoreScheduleControl{
+		rsIndexer: rsInformer.Informer().GetIndexer(),
+	}
+}
+
+// SetUpdateRestoreScheduleError sets the error attributes of updateRestoreScheduleTracker
+func (fbc *FakeRestoreScheduleControl) SetUpdateRestoreScheduleError(err error, after int) {
+	fbc.updateRsTracker.SetError(err).SetAfter(after)
+}
+
+// UpdateRestoreSchedule updates the restoreSchedule to rsIndexer
+func (fbc *FakeRestoreScheduleControl) UpdateRestoreSchedule(rs *v1alpha1.RestoreSchedule) error {
+	defer fbc.updateRsTracker.Inc()
+	if fbc.updateRsTracker.ErrorReady() {
+		defer fbc.updateRsTracker.Reset()
+		return fbc.updateRsTracker.GetError()
+	}
+
+	return fbc.rsIndexer.Add(rs)
+}
+
+var _ ControlInterface = &FakeRestoreScheduleControl{}
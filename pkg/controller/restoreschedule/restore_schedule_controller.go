@@ -0,0 +1,152 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restoreschedule
+
+import (
+	"fmt"
+	"time"
+
+	perrors "github.com/pingcap/errors"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/restoreschedule"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// Controller controls restoreSchedules.
+type Controller struct {
+	deps *controller.Dependencies
+	// control returns an interface capable of syncing a restoreSchedule.
+	// Abstracted out for testing.
+	control ControlInterface
+	// restoreSchedules that need to be synced.
+	queue workqueue.RateLimitingInterface
+}
+
+// NewController creates a restoreSchedule controller.
+func NewController(deps *controller.Dependencies) *Controller {
+	c := &Controller{
+		deps:    deps,
+		control: NewDefaultRestoreScheduleControl(controller.NewRealRestoreScheduleStatusUpdater(deps), restoreschedule.NewRestoreScheduleManager(deps)),
+		queue: workqueue.NewNamedRateLimitingQueue(
+			controller.NewControllerRateLimiter(1*time.Second, 100*time.Second),
+			"restoreSchedule",
+		),
+	}
+
+	restoreScheduleInformer := deps.InformerFactory.Pingcap().V1alpha1().RestoreSchedules()
+	restoreScheduleInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueRestoreSchedule,
+		UpdateFunc: func(old, cur interface{}) {
+			c.enqueueRestoreSchedule(cur)
+		},
+		DeleteFunc: c.enqueueRestoreSchedule,
+	})
+	// the standby cluster is refreshed by the next backup once the last restore finishes
+	restoreInformer := deps.InformerFactory.Pingcap().V1alpha1().Restores()
+	controller.WatchForController(restoreInformer.Informer(), c.queue, func(ns, name string) (runtime.Object, error) {
+		return c.deps.RestoreScheduleLister.RestoreSchedules(ns).Get(name)
+	}, map[string]string{label.NameLabelKey: label.RestoreScheduleJobLabelVal})
+
+	return c
+}
+
+// Run runs the restore schedule controller.
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Starting restore schedule controller")
+	defer klog.Info("Shutting down restore schedule controller")
+
+	// the number of the workers follows the workers flag reloaded from the config file
+	wg := controller.RunWorkers(workers, c.deps.CLIConfig, c.processNextWorkItem, stopCh)
+
+	<-stopCh
+	// wait for the in-flight syncs to finish, so that no operation is left half-done
+	c.queue.ShutDown()
+	wg.Wait()
+}
+
+// processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
+// invoked concurrently with the same key.
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	if err := c.sync(key.(string)); err != nil {
+		if perrors.Find(err, controller.IsRequeueError) != nil {
+			klog.Infof("RestoreSchedule: %v, still need sync: %v, requeuing", key.(string), err)
+			c.queue.AddRateLimited(key)
+		} else if perrors.Find(err, controller.IsIgnoreError) != nil {
+			klog.V(4).Infof("RestoreSchedule: %v, ignore err: %v, waiting for the next sync", key.(string), err)
+		} else {
+			utilruntime.HandleError(fmt.Errorf("RestoreSchedule: %v, sync failed, err: %v, requeuing", key.(string), err))
+			c.queue.AddRateLimited(key)
+		}
+	} else {
+		c.queue.Forget(key)
+	}
+	return true
+}
+
+// sync syncs the given restoreSchedule.
+func (c *Controller) sync(key string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing RestoreSchedule %q (%v)", key, time.Since(startTime))
+	}()
+
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if !c.deps.NamespaceSelector.Selected(ns) {
+		klog.V(4).Infof("RestoreSchedule %q is not in the selected namespaces, skip syncing", key)
+		return nil
+	}
+	rs, err := c.deps.RestoreScheduleLister.RestoreSchedules(ns).Get(name)
+	if errors.IsNotFound(err) {
+		klog.Infof("RestoreSchedule has been deleted %v", key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.syncRestoreSchedule(rs.DeepCopy())
+}
+
+func (c *Controller) syncRestoreSchedule(rs *v1alpha1.RestoreSchedule) error {
+	return c.control.UpdateRestoreSchedule(rs)
+}
+
+// enqueueRestoreSchedule enqueues the given restoreSchedule in the work queue.
+func (c *Controller) enqueueRestoreSchedule(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("cound't get key for object %+v: %v", obj, err))
+		return
+	}
+	c.queue.Add(key)
+}
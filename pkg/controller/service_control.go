@@ -19,8 +19,10 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -78,6 +80,27 @@ func (c *realServiceControl) UpdateService(controller runtime.Object, svc *corev
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
 	svcName := svc.GetName()
+	if ServerSideApplyEnabled() {
+		// the live Service is read from the cache to leave the labels and annotations
+		// of others out of the apply patch
+		var live metav1.Object
+		if cached, err := c.svcLister.Services(namespace).Get(svcName); err == nil {
+			live = cached
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		data, err := ApplyPatch(svc, live)
+		if err != nil {
+			return nil, err
+		}
+		updateSvc, err := c.kubeCli.CoreV1().Services(namespace).Patch(context.TODO(), svcName, types.ApplyPatchType, data, ApplyPatchOptions())
+		if err != nil {
+			return nil, err
+		}
+		klog.Infof("apply Service: [%s/%s] successfully, kind: %s, name: %s", namespace, svcName, kind, name)
+		return updateSvc, nil
+	}
+
 	svcSpec := svc.Spec.DeepCopy()
 
 	var updateSvc *corev1.Service
@@ -18,11 +18,13 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/pingcap/tidb-operator/pkg/features"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	appsinformers "k8s.io/client-go/informers/apps/v1"
 	"k8s.io/client-go/kubernetes"
@@ -61,7 +63,17 @@ func (c *realStatefulSetControl) CreateStatefulSet(controller runtime.Object, se
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
 
-	_, err := c.kubeCli.AppsV1().StatefulSets(namespace).Create(context.TODO(), set, metav1.CreateOptions{})
+	var err error
+	if HasNativeSidecars(set) {
+		// the restartPolicy of the native sidecars is only kept in the apply patch
+		var data []byte
+		if data, err = ApplyPatch(set, nil); err != nil {
+			return err
+		}
+		_, err = c.kubeCli.AppsV1().StatefulSets(namespace).Patch(context.TODO(), set.Name, types.ApplyPatchType, data, ApplyPatchOptions())
+	} else {
+		_, err = c.kubeCli.AppsV1().StatefulSets(namespace).Create(context.TODO(), set, metav1.CreateOptions{})
+	}
 	// sink already exists errors
 	if apierrors.IsAlreadyExists(err) {
 		return err
@@ -81,6 +93,34 @@ func (c *realStatefulSetControl) UpdateStatefulSet(controller runtime.Object, se
 	namespace := controllerMo.GetNamespace()
 
 	setName := set.GetName()
+	if ServerSideApplyEnabled() || HasNativeSidecars(set) {
+		// the live StatefulSet is read from the cache to leave the labels and annotations
+		// of others out of the apply patch
+		var live metav1.Object
+		if cached, err := c.setLister.StatefulSets(namespace).Get(setName); err == nil {
+			live = cached
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		var data []byte
+		var err error
+		if features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
+			data, err = AdvancedStatefulSetApplyPatch(set, live)
+		} else {
+			data, err = ApplyPatch(set, live)
+		}
+		if err != nil {
+			return nil, err
+		}
+		updatedSS, err := c.kubeCli.AppsV1().StatefulSets(namespace).Patch(context.TODO(), setName, types.ApplyPatchType, data, ApplyPatchOptions())
+		if err != nil {
+			klog.Errorf("failed to apply %s: [%s/%s]'s StatefulSet: [%s/%s], error: %v", kind, namespace, name, namespace, setName, err)
+			return nil, err
+		}
+		klog.Infof("%s: [%s/%s]'s StatefulSet: [%s/%s] applied successfully", kind, namespace, name, namespace, setName)
+		return updatedSS, nil
+	}
+
 	setSpec := set.Spec.DeepCopy()
 	setLabels := set.Labels
 	setAnnotations := set.Annotations
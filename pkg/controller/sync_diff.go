@@ -0,0 +1,310 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SyncDiffRecorder collects the objects changed by the syncs, the changes
+// are grouped by the controller object.
+type SyncDiffRecorder struct {
+	lock  sync.Mutex
+	diffs map[string][]v1alpha1.ObjectDiff
+}
+
+// NewSyncDiffRecorder returns an empty SyncDiffRecorder
+func NewSyncDiffRecorder() *SyncDiffRecorder {
+	return &SyncDiffRecorder{diffs: map[string][]v1alpha1.ObjectDiff{}}
+}
+
+// Take returns the diffs recorded for the controller and resets them
+func (r *SyncDiffRecorder) Take(ns, controllerName string) []v1alpha1.ObjectDiff {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := fmt.Sprintf("%s/%s", ns, controllerName)
+	diffs := r.diffs[key]
+	delete(r.diffs, key)
+	return diffs
+}
+
+func (r *SyncDiffRecorder) record(controller runtime.Object, obj runtime.Object, action string, fields []string) {
+	accessor, err := meta.Accessor(controller)
+	if err != nil {
+		klog.Errorf("sync diff: failed to access the controller %T: %v", controller, err)
+		return
+	}
+	name := ""
+	if objAccessor, err := meta.Accessor(obj); err == nil {
+		name = objAccessor.GetName()
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := fmt.Sprintf("%s/%s", accessor.GetNamespace(), accessor.GetName())
+	r.diffs[key] = append(r.diffs[key], v1alpha1.ObjectDiff{
+		Kind:   kindOf(obj),
+		Name:   name,
+		Action: action,
+		Fields: fields,
+	})
+}
+
+// NewSyncDiffDependencies returns a copy of deps whose controls record the
+// summary of the changes they apply to recorder.
+func NewSyncDiffDependencies(deps *Dependencies, recorder *SyncDiffRecorder) *Dependencies {
+	syncDiffDeps := *deps
+	genericControl := &syncDiffGenericControl{GenericControlInterface: deps.GenericControl, recorder: recorder}
+
+	syncDiffDeps.StatefulSetControl = &syncDiffStatefulSetControl{
+		StatefulSetControlInterface: deps.StatefulSetControl,
+		setLister:                   deps.StatefulSetLister,
+		recorder:                    recorder,
+	}
+	syncDiffDeps.ServiceControl = &syncDiffServiceControl{
+		ServiceControlInterface: deps.ServiceControl,
+		svcLister:               deps.ServiceLister,
+		recorder:                recorder,
+	}
+	syncDiffDeps.GenericControl = genericControl
+	syncDiffDeps.TypedControl = NewTypedControl(genericControl)
+	return &syncDiffDeps
+}
+
+type syncDiffStatefulSetControl struct {
+	StatefulSetControlInterface
+	setLister appslisters.StatefulSetLister
+	recorder  *SyncDiffRecorder
+}
+
+func (c *syncDiffStatefulSetControl) CreateStatefulSet(controller runtime.Object, set *apps.StatefulSet) error {
+	if err := c.StatefulSetControlInterface.CreateStatefulSet(controller, set); err != nil {
+		return err
+	}
+	c.recorder.record(controller, set, ChangeActionCreate, nil)
+	return nil
+}
+
+func (c *syncDiffStatefulSetControl) UpdateStatefulSet(controller runtime.Object, set *apps.StatefulSet) (*apps.StatefulSet, error) {
+	var fields []string
+	if existing, err := c.setLister.StatefulSets(set.Namespace).Get(set.Name); err == nil {
+		fields = objectDiffFields(existing, set)
+	}
+	updated, err := c.StatefulSetControlInterface.UpdateStatefulSet(controller, set)
+	if err != nil {
+		return updated, err
+	}
+	c.recorder.record(controller, set, ChangeActionUpdate, fields)
+	return updated, nil
+}
+
+type syncDiffServiceControl struct {
+	ServiceControlInterface
+	svcLister corelisterv1.ServiceLister
+	recorder  *SyncDiffRecorder
+}
+
+func (c *syncDiffServiceControl) CreateService(controller runtime.Object, svc *corev1.Service) error {
+	if err := c.ServiceControlInterface.CreateService(controller, svc); err != nil {
+		return err
+	}
+	c.recorder.record(controller, svc, ChangeActionCreate, nil)
+	return nil
+}
+
+func (c *syncDiffServiceControl) UpdateService(controller runtime.Object, svc *corev1.Service) (*corev1.Service, error) {
+	var fields []string
+	if existing, err := c.svcLister.Services(svc.Namespace).Get(svc.Name); err == nil {
+		fields = objectDiffFields(existing, svc)
+	}
+	updated, err := c.ServiceControlInterface.UpdateService(controller, svc)
+	if err != nil {
+		return updated, err
+	}
+	c.recorder.record(controller, svc, ChangeActionUpdate, fields)
+	return updated, nil
+}
+
+type syncDiffGenericControl struct {
+	GenericControlInterface
+	recorder *SyncDiffRecorder
+}
+
+func (c *syncDiffGenericControl) CreateOrUpdate(controller, obj client.Object, mergeFn MergeFn, setOwnerFlag bool) (runtime.Object, error) {
+	existing, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a client.Object", obj)
+	}
+	exist, err := c.GenericControlInterface.Exist(client.ObjectKeyFromObject(obj), existing)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.GenericControlInterface.CreateOrUpdate(controller, obj, mergeFn, setOwnerFlag)
+	if err != nil {
+		return result, err
+	}
+	if !exist {
+		c.recorder.record(controller, obj, ChangeActionCreate, nil)
+	} else if fields := objectDiffFields(existing, result); len(fields) > 0 {
+		c.recorder.record(controller, obj, ChangeActionUpdate, fields)
+	}
+	return result, nil
+}
+
+// objectDiffFields returns the paths of the fields which differ between the
+// actual object and the desired object, the object meta maintained by the
+// api-server is ignored. For StatefulSets, the changes of the containers
+// are listed respectively.
+func objectDiffFields(actual, desired runtime.Object) []string {
+	var fields []string
+	actualMeta, err1 := meta.Accessor(actual)
+	desiredMeta, err2 := meta.Accessor(desired)
+	if err1 == nil && err2 == nil {
+		fields = append(fields, mapDiffFields("metadata.labels", actualMeta.GetLabels(), desiredMeta.GetLabels())...)
+		fields = append(fields, mapDiffFields("metadata.annotations", actualMeta.GetAnnotations(), desiredMeta.GetAnnotations())...)
+		if !apiequality.Semantic.DeepEqual(actualMeta.GetOwnerReferences(), desiredMeta.GetOwnerReferences()) {
+			fields = append(fields, "metadata.ownerReferences")
+		}
+	}
+
+	switch desiredObj := desired.(type) {
+	case *apps.StatefulSet:
+		actualObj, ok := actual.(*apps.StatefulSet)
+		if !ok {
+			break
+		}
+		return append(fields, statefulSetSpecDiffFields(&actualObj.Spec, &desiredObj.Spec)...)
+	case *corev1.Service:
+		actualObj, ok := actual.(*corev1.Service)
+		if !ok {
+			break
+		}
+		return append(fields, structDiffFields("spec", &actualObj.Spec, &desiredObj.Spec)...)
+	case *corev1.ConfigMap:
+		actualObj, ok := actual.(*corev1.ConfigMap)
+		if !ok {
+			break
+		}
+		return append(fields, mapDiffFields("data", actualObj.Data, desiredObj.Data)...)
+	}
+	return append(fields, structDiffFields("", actual, desired, "TypeMeta", "ObjectMeta", "Status")...)
+}
+
+func statefulSetSpecDiffFields(actual, desired *apps.StatefulSetSpec) []string {
+	var fields []string
+	fields = append(fields, structDiffFields("spec", actual, desired, "Template")...)
+	fields = append(fields, mapDiffFields("spec.template.metadata.labels", actual.Template.Labels, desired.Template.Labels)...)
+	fields = append(fields, mapDiffFields("spec.template.metadata.annotations", actual.Template.Annotations, desired.Template.Annotations)...)
+	fields = append(fields, containersDiffFields("spec.template.spec.initContainers", actual.Template.Spec.InitContainers, desired.Template.Spec.InitContainers)...)
+	fields = append(fields, containersDiffFields("spec.template.spec.containers", actual.Template.Spec.Containers, desired.Template.Spec.Containers)...)
+	fields = append(fields, structDiffFields("spec.template.spec", &actual.Template.Spec, &desired.Template.Spec, "InitContainers", "Containers")...)
+	return fields
+}
+
+func containersDiffFields(path string, actual, desired []corev1.Container) []string {
+	actualContainers := map[string]*corev1.Container{}
+	for i := range actual {
+		actualContainers[actual[i].Name] = &actual[i]
+	}
+	var fields []string
+	for i := range desired {
+		containerPath := fmt.Sprintf("%s[%s]", path, desired[i].Name)
+		a, ok := actualContainers[desired[i].Name]
+		if !ok {
+			fields = append(fields, containerPath)
+			continue
+		}
+		delete(actualContainers, desired[i].Name)
+		fields = append(fields, structDiffFields(containerPath, a, &desired[i])...)
+	}
+	for name := range actualContainers {
+		fields = append(fields, fmt.Sprintf("%s[%s]", path, name))
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// mapDiffFields returns the keys which are added, removed or changed
+func mapDiffFields(path string, actual, desired map[string]string) []string {
+	var fields []string
+	for k, v := range desired {
+		if av, ok := actual[k]; !ok || av != v {
+			fields = append(fields, fmt.Sprintf("%s[%s]", path, k))
+		}
+	}
+	for k := range actual {
+		if _, ok := desired[k]; !ok {
+			fields = append(fields, fmt.Sprintf("%s[%s]", path, k))
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// structDiffFields returns the json names of the top level fields which
+// differ between actual and desired, both of which must be the same struct
+// or the pointer to it
+func structDiffFields(path string, actual, desired interface{}, ignored ...string) []string {
+	av := reflect.Indirect(reflect.ValueOf(actual))
+	dv := reflect.Indirect(reflect.ValueOf(desired))
+	if av.Kind() != reflect.Struct || av.Type() != dv.Type() {
+		return nil
+	}
+
+	var fields []string
+	for i := 0; i < av.NumField(); i++ {
+		field := av.Type().Field(i)
+		if field.PkgPath != "" || containsString(ignored, field.Name) {
+			continue
+		}
+		if apiequality.Semantic.DeepEqual(av.Field(i).Interface(), dv.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
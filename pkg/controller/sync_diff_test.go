@@ -0,0 +1,107 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestObjectDiffFields(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-tikv",
+			Namespace:       metav1.NamespaceDefault,
+			ResourceVersion: "1",
+			Annotations:     map[string]string{LastAppliedConfigAnnotation: "a"},
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "tikv", Image: "tikv:v5.4.0"},
+						{Name: "log", Image: "busybox"},
+					},
+				},
+			},
+		},
+	}
+	desired := set.DeepCopy()
+	desired.ResourceVersion = "2"
+	g.Expect(objectDiffFields(set, desired)).To(BeEmpty())
+
+	desired.Annotations[LastAppliedConfigAnnotation] = "b"
+	desired.Spec.Replicas = pointer.Int32Ptr(4)
+	desired.Spec.Template.Spec.Containers[0].Image = "tikv:v6.0.0"
+	desired.Spec.Template.Spec.Containers = desired.Spec.Template.Spec.Containers[:1]
+	desired.Spec.Template.Spec.NodeSelector = map[string]string{"zone": "a"}
+	g.Expect(objectDiffFields(set, desired)).To(Equal([]string{
+		"metadata.annotations[pingcap.com/last-applied-configuration]",
+		"spec.replicas",
+		"spec.template.spec.containers[log]",
+		"spec.template.spec.containers[tikv].image",
+		"spec.template.spec.nodeSelector",
+	}))
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tikv"},
+		Data:       map[string]string{"config-file": "a", "startup-script": "b"},
+	}
+	desiredCM := cm.DeepCopy()
+	desiredCM.Data["config-file"] = "c"
+	delete(desiredCM.Data, "startup-script")
+	g.Expect(objectDiffFields(cm, desiredCM)).To(Equal([]string{
+		"data[config-file]",
+		"data[startup-script]",
+	}))
+}
+
+func TestSyncDiffDependencies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := NewFakeDependencies()
+	recorder := NewSyncDiffRecorder()
+	syncDiffDeps := NewSyncDiffDependencies(deps, recorder)
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Namespace: metav1.NamespaceDefault},
+		Data:       map[string]string{"a": "1"},
+	}
+
+	_, err := syncDiffDeps.TypedControl.CreateOrUpdateConfigMap(tc, cm.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	// nothing is changed
+	_, err = syncDiffDeps.TypedControl.CreateOrUpdateConfigMap(tc, cm.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	cm.Data["a"] = "2"
+	_, err = syncDiffDeps.TypedControl.CreateOrUpdateConfigMap(tc, cm.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(recorder.Take(tc.Namespace, tc.Name)).To(Equal([]v1alpha1.ObjectDiff{
+		{Kind: "ConfigMap", Name: "test-cm", Action: ChangeActionCreate},
+		{Kind: "ConfigMap", Name: "test-cm", Action: ChangeActionUpdate, Fields: []string{"data[a]"}},
+	}))
+	g.Expect(recorder.Take(tc.Namespace, tc.Name)).To(BeEmpty())
+}
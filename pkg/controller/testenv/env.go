@@ -0,0 +1,261 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testenv exposes the fake dependencies used by the unit tests of
+// tidb-operator, so that the automation built on top of the operator can be
+// tested against the same fakes. The API of this package is kept stable.
+package testenv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/controller/tidbcluster"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+	"github.com/pingcap/tidb-operator/pkg/manager/meta"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// FakeMemberManagers groups the fake member managers of a TidbCluster,
+// the managers not listed here always succeed
+type FakeMemberManagers struct {
+	PD        *member.FakePDMemberManager
+	TiKV      *member.FakeTiKVMemberManager
+	TiDB      *member.FakeTiDBMemberManager
+	TiFlash   *member.FakeTiFlashMemberManager
+	TiCDC     *member.FakeTiCDCMemberManager
+	TiProxy   *member.FakeTiProxyMemberManager
+	Pump      *member.FakePumpMemberManager
+	Discovery *member.FakeDiscoveryManager
+	Status    *member.FakeTidbClusterStatusManager
+}
+
+// NewFakeMemberManagers returns the fake member managers which succeed by default
+func NewFakeMemberManagers() *FakeMemberManagers {
+	return &FakeMemberManagers{
+		PD:        member.NewFakePDMemberManager(),
+		TiKV:      member.NewFakeTiKVMemberManager(),
+		TiDB:      member.NewFakeTiDBMemberManager(),
+		TiFlash:   member.NewFakeTiFlashMemberManager(),
+		TiCDC:     member.NewFakeTiCDCMemberManager(),
+		TiProxy:   member.NewFakeTiProxyMemberManager(),
+		Pump:      member.NewFakePumpMemberManager(),
+		Discovery: member.NewFakeDiscoveryManger(),
+		Status:    member.NewFakeTidbClusterStatusManager(),
+	}
+}
+
+// Env is a fake environment of the controllers. The objects are stored in
+// the fake clientsets, and every write through the clientsets, including the
+// writes of the code under test, is applied to the informer caches before
+// the request returns, so the listers always read the latest objects.
+type Env struct {
+	Deps          *controller.Dependencies
+	Clientset     *fake.Clientset
+	KubeClientset *kubefake.Clientset
+	Recorder      *record.FakeRecorder
+	Managers      *FakeMemberManagers
+	// Control is the tidbcluster control driving the fake member managers in
+	// Managers, the status of the TidbCluster is written by the real
+	// TidbClusterControl through Clientset
+	Control tidbcluster.ControlInterface
+
+	pdClients map[string]*pdapi.FakePDClient
+}
+
+// NewEnv returns a new fake environment
+func NewEnv() *Env {
+	deps := controller.NewFakeDependencies()
+	deps.TiDBClusterControl = controller.NewRealTidbClusterControl(deps.Clientset, deps.TiDBClusterLister, deps.Recorder)
+	e := &Env{
+		Deps:          deps,
+		Clientset:     deps.Clientset.(*fake.Clientset),
+		KubeClientset: deps.KubeClientset.(*kubefake.Clientset),
+		Recorder:      deps.Recorder.(*record.FakeRecorder),
+		Managers:      NewFakeMemberManagers(),
+		pdClients:     map[string]*pdapi.FakePDClient{},
+	}
+	syncInformers(&e.Clientset.Fake, e.Clientset.Tracker(), func(gvr schema.GroupVersionResource) []cache.SharedIndexInformer {
+		informer, err := deps.InformerFactory.ForResource(gvr)
+		if err != nil {
+			return nil
+		}
+		return []cache.SharedIndexInformer{informer.Informer()}
+	})
+	syncInformers(&e.KubeClientset.Fake, e.KubeClientset.Tracker(), func(gvr schema.GroupVersionResource) []cache.SharedIndexInformer {
+		var informers []cache.SharedIndexInformer
+		for _, factory := range []kubeinformers.SharedInformerFactory{deps.KubeInformerFactory, deps.LabelFilterKubeInformerFactory} {
+			if informer, err := factory.ForResource(gvr); err == nil {
+				informers = append(informers, informer.Informer())
+			}
+		}
+		return informers
+	})
+	e.Control = e.newTidbClusterControl()
+	return e
+}
+
+func (e *Env) newTidbClusterControl() tidbcluster.ControlInterface {
+	m := e.Managers
+	return tidbcluster.NewDefaultTidbClusterControl(
+		e.Deps.TiDBClusterControl,
+		m.PD,
+		m.TiKV,
+		m.TiDB,
+		meta.NewFakeReclaimPolicyManager(),
+		meta.NewFakeMetaManager(),
+		member.NewFakeOrphanPodsCleaner(),
+		member.NewFakePVCCleaner(),
+		member.NewFakePVCResizer(),
+		member.NewFakePVCMigrator(),
+		member.NewFakeDiskWatchdog(),
+		member.NewFakeHeterogeneousManager(),
+		member.NewFakeTLSSecretChecker(),
+		member.NewFakeSchedulingChecker(),
+		member.NewFakeTiKVStoreRelocator(),
+		member.NewFakeTidbClusterPurger(),
+		m.Pump,
+		m.TiFlash,
+		m.TiCDC,
+		m.TiProxy,
+		m.Discovery,
+		member.NewFakeNetworkPolicyManager(),
+		member.NewFakePodMonitorManager(),
+		member.NewFakeResourceQuotaManager(),
+		member.NewFakeResourceReportManager(),
+		member.NewFakeRecommendationManager(),
+		member.NewFakeAutoUpgradeManager(),
+		member.NewFakeSQLProbeManager(),
+		member.NewFakeEventThrottlingManager(),
+		member.NewFakePDScheduleProfileManager(),
+		member.NewFakeTopologyExportManager(),
+		member.NewFakeExternalDNSManager(),
+		member.NewFakeIdentityManager(),
+		m.Status,
+		tidbcluster.NewTidbClusterConditionUpdater(),
+		nil,
+		nil,
+		false,
+		e.Deps.Recorder,
+	)
+}
+
+// syncInformers applies the writes handled by the tracker of the fake
+// clientset to the indexers of the informers of the resource. The reactors
+// prepended later, e.g. by the injected faults, still run before it.
+func syncInformers(f *core.Fake, tracker core.ObjectTracker, informersFor func(schema.GroupVersionResource) []cache.SharedIndexInformer) {
+	reaction := core.ObjectReaction(tracker)
+	f.PrependReactor("*", "*", func(action core.Action) (bool, runtime.Object, error) {
+		handled, obj, err := reaction(action)
+		if err != nil {
+			return handled, obj, err
+		}
+		switch action.GetVerb() {
+		case "create", "update", "patch":
+			if obj == nil {
+				break
+			}
+			for _, informer := range informersFor(action.GetResource()) {
+				if err := informer.GetIndexer().Update(obj); err != nil {
+					return true, nil, err
+				}
+			}
+		case "delete":
+			key := action.(core.DeleteAction).GetName()
+			if len(action.GetNamespace()) > 0 {
+				key = action.GetNamespace() + "/" + key
+			}
+			for _, informer := range informersFor(action.GetResource()) {
+				indexer := informer.GetIndexer()
+				old, exists, err := indexer.GetByKey(key)
+				if err != nil {
+					return true, nil, err
+				}
+				if exists {
+					if err := indexer.Delete(old); err != nil {
+						return true, nil, err
+					}
+				}
+			}
+		}
+		return handled, obj, err
+	})
+}
+
+// PDClient returns the fake PD client used by the controllers for the TidbCluster
+func (e *Env) PDClient(tc *v1alpha1.TidbCluster) *pdapi.FakePDClient {
+	key := fmt.Sprintf("%s/%s", tc.GetNamespace(), tc.GetName())
+	if c, ok := e.pdClients[key]; ok {
+		return c
+	}
+	c := controller.NewFakePDClient(e.Deps.PDControl.(*pdapi.FakePDControl), tc)
+	e.pdClients[key] = c
+	return c
+}
+
+// AddTidbCluster creates the TidbCluster in the fake clientset
+func (e *Env) AddTidbCluster(tc *v1alpha1.TidbCluster) error {
+	_, err := e.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	return err
+}
+
+// TidbCluster returns the latest TidbCluster in the fake clientset
+func (e *Env) TidbCluster(ns, name string) (*v1alpha1.TidbCluster, error) {
+	return e.Clientset.PingcapV1alpha1().TidbClusters(ns).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// SyncTidbCluster reconciles the TidbCluster by Control as the tidbcluster controller does
+func (e *Env) SyncTidbCluster(ns, name string) error {
+	tc, err := e.Deps.TiDBClusterLister.TidbClusters(ns).Get(name)
+	if err != nil {
+		return err
+	}
+	return e.Control.UpdateTidbCluster(tc.DeepCopy())
+}
+
+// AddPod creates the pod in the fake clientset
+func (e *Env) AddPod(pod *corev1.Pod) error {
+	_, err := e.KubeClientset.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	return err
+}
+
+// DeletePod deletes the pod from the fake clientset
+func (e *Env) DeletePod(ns, name string) error {
+	return e.KubeClientset.CoreV1().Pods(ns).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// Events drains the events recorded so far
+func (e *Env) Events() []string {
+	var events []string
+	for {
+		select {
+		case event := <-e.Recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
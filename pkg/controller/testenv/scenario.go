@@ -0,0 +1,188 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testenv
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+)
+
+// Fault is a failure injected into the Env before a step of a Scenario is
+// reconciled, it is reverted after the reconciliation.
+type Fault interface {
+	Inject(env *Env) error
+	Revert(env *Env) error
+	String() string
+}
+
+type pdAPIFailure struct {
+	tc         *v1alpha1.TidbCluster
+	actionType pdapi.ActionType
+	err        error
+
+	origin pdapi.Reaction
+}
+
+// PDAPIFailure makes the PD API of the TidbCluster return err
+func PDAPIFailure(tc *v1alpha1.TidbCluster, actionType pdapi.ActionType, err error) Fault {
+	return &pdAPIFailure{tc: tc, actionType: actionType, err: err}
+}
+
+func (f *pdAPIFailure) Inject(env *Env) error {
+	pdClient := env.PDClient(f.tc)
+	f.origin = pdClient.Reaction(f.actionType)
+	pdClient.AddReaction(f.actionType, func(action *pdapi.Action) (interface{}, error) {
+		return nil, f.err
+	})
+	return nil
+}
+
+func (f *pdAPIFailure) Revert(env *Env) error {
+	pdClient := env.PDClient(f.tc)
+	if f.origin == nil {
+		pdClient.RemoveReaction(f.actionType)
+	} else {
+		pdClient.AddReaction(f.actionType, f.origin)
+	}
+	return nil
+}
+
+func (f *pdAPIFailure) String() string {
+	return fmt.Sprintf("PD API %s of %s/%s fails with %q", f.actionType, f.tc.Namespace, f.tc.Name, f.err)
+}
+
+type podDeletion struct {
+	namespace string
+	name      string
+}
+
+// PodDeletion deletes the pod before the reconciliation, e.g. to simulate an eviction
+func PodDeletion(namespace, name string) Fault {
+	return &podDeletion{namespace: namespace, name: name}
+}
+
+func (f *podDeletion) Inject(env *Env) error {
+	return env.DeletePod(f.namespace, f.name)
+}
+
+func (f *podDeletion) Revert(_ *Env) error {
+	// the deleted pod is expected to be recreated by the controllers
+	return nil
+}
+
+func (f *podDeletion) String() string {
+	return fmt.Sprintf("pod %s/%s is deleted", f.namespace, f.name)
+}
+
+type apiServerError struct {
+	verb     string
+	resource string
+	err      error
+
+	originChain     []core.Reactor
+	originKubeChain []core.Reactor
+}
+
+// APIServerError makes the requests of the verb on the resource fail with err,
+// both of the operator clientset and the kubernetes clientset are affected,
+// "*" matches any verb or resource
+func APIServerError(verb, resource string, err error) Fault {
+	return &apiServerError{verb: verb, resource: resource, err: err}
+}
+
+func (f *apiServerError) Inject(env *Env) error {
+	f.originChain = append([]core.Reactor(nil), env.Clientset.ReactionChain...)
+	f.originKubeChain = append([]core.Reactor(nil), env.KubeClientset.ReactionChain...)
+	reaction := func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, f.err
+	}
+	env.Clientset.PrependReactor(f.verb, f.resource, reaction)
+	env.KubeClientset.PrependReactor(f.verb, f.resource, reaction)
+	return nil
+}
+
+func (f *apiServerError) Revert(env *Env) error {
+	env.Clientset.ReactionChain = f.originChain
+	env.KubeClientset.ReactionChain = f.originKubeChain
+	return nil
+}
+
+func (f *apiServerError) String() string {
+	return fmt.Sprintf("API server fails to %s %s with %q", f.verb, f.resource, f.err)
+}
+
+// Step is a reconciliation under the given faults
+type Step struct {
+	Name string
+	// Faults are injected before Reconcile and reverted after it
+	Faults []Fault
+	// Reconcile runs the automation under test
+	Reconcile func(env *Env) error
+	// Verify checks the result of the step, err is returned by Reconcile
+	Verify func(env *Env, err error) error
+}
+
+// Scenario is a sequence of steps run against the same Env
+type Scenario struct {
+	Name  string
+	Steps []Step
+}
+
+// Run runs the steps of the scenario in order and returns the first failure
+func (s *Scenario) Run(env *Env) error {
+	for i := range s.Steps {
+		if err := s.runStep(env, &s.Steps[i]); err != nil {
+			return fmt.Errorf("scenario %q step %d %q: %v", s.Name, i, s.Steps[i].Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Scenario) runStep(env *Env, step *Step) error {
+	var injected []Fault
+	revert := func() error {
+		for i := len(injected) - 1; i >= 0; i-- {
+			if err := injected[i].Revert(env); err != nil {
+				return fmt.Errorf("failed to revert fault (%s): %v", injected[i], err)
+			}
+		}
+		return nil
+	}
+
+	for _, fault := range step.Faults {
+		if err := fault.Inject(env); err != nil {
+			revert()
+			return fmt.Errorf("failed to inject fault (%s): %v", fault, err)
+		}
+		injected = append(injected, fault)
+	}
+
+	var reconcileErr error
+	if step.Reconcile != nil {
+		reconcileErr = step.Reconcile(env)
+	}
+	if err := revert(); err != nil {
+		return err
+	}
+
+	if step.Verify != nil {
+		return step.Verify(env, reconcileErr)
+	}
+	return reconcileErr
+}
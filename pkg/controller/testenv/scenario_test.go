@@ -0,0 +1,206 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testenv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScenarioRun(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	env := NewEnv()
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+	}
+	g.Expect(env.AddTidbCluster(tc)).To(Succeed())
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tikv-0", Namespace: metav1.NamespaceDefault},
+	}
+	g.Expect(env.AddPod(pod)).To(Succeed())
+	env.PDClient(tc).AddReaction(pdapi.GetHealthActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.HealthInfo{}, nil
+	})
+
+	checkHealth := func(env *Env) error {
+		_, err := controller.GetPDClient(env.Deps.PDControl, tc).GetHealth()
+		return err
+	}
+	updateTC := func(env *Env) error {
+		_, err := env.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(context.TODO(), tc, metav1.UpdateOptions{})
+		return err
+	}
+
+	scenario := &Scenario{
+		Name: "faults",
+		Steps: []Step{
+			{
+				Name:      "pd unavailable",
+				Faults:    []Fault{PDAPIFailure(tc, pdapi.GetHealthActionType, fmt.Errorf("unavailable"))},
+				Reconcile: checkHealth,
+				Verify: func(env *Env, err error) error {
+					if err == nil {
+						return fmt.Errorf("expect PD API to fail")
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "pd recovered",
+				Reconcile: checkHealth,
+			},
+			{
+				Name:      "api server error",
+				Faults:    []Fault{APIServerError("update", "tidbclusters", errors.NewInternalError(fmt.Errorf("etcd timeout")))},
+				Reconcile: updateTC,
+				Verify: func(env *Env, err error) error {
+					if !errors.IsInternalError(err) {
+						return fmt.Errorf("expect an internal error, got %v", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "api server recovered",
+				Faults:    []Fault{PodDeletion(pod.Namespace, pod.Name)},
+				Reconcile: updateTC,
+				Verify: func(env *Env, err error) error {
+					if err != nil {
+						return err
+					}
+					_, err = env.Deps.PodLister.Pods(pod.Namespace).Get(pod.Name)
+					if !errors.IsNotFound(err) {
+						return fmt.Errorf("expect pod to be deleted, got %v", err)
+					}
+					return nil
+				},
+			},
+		},
+	}
+	g.Expect(scenario.Run(env)).To(Succeed())
+
+	failed := &Scenario{
+		Name: "failed",
+		Steps: []Step{
+			{
+				Name:      "pd unavailable",
+				Faults:    []Fault{PDAPIFailure(tc, pdapi.GetHealthActionType, fmt.Errorf("unavailable"))},
+				Reconcile: checkHealth,
+			},
+		},
+	}
+	err := failed.Run(env)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring(`scenario "failed" step 0 "pd unavailable": unavailable`))
+}
+
+func TestScenarioSyncTidbCluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	env := NewEnv()
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			Version: "v5.4.0",
+			PD: &v1alpha1.PDSpec{
+				Replicas:  3,
+				BaseImage: "pingcap/pd",
+				Config:    v1alpha1.NewPDConfig(),
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10G")},
+				},
+			},
+			TiKV: &v1alpha1.TiKVSpec{
+				Replicas:  3,
+				BaseImage: "pingcap/tikv",
+				Config:    v1alpha1.NewTiKVConfig(),
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10G")},
+				},
+			},
+			TiDB: &v1alpha1.TiDBSpec{
+				Replicas:  2,
+				BaseImage: "pingcap/tidb",
+				Config:    v1alpha1.NewTiDBConfig(),
+			},
+		},
+	}
+	g.Expect(env.AddTidbCluster(tc)).To(Succeed())
+	sync := func(env *Env) error {
+		return env.SyncTidbCluster(tc.Namespace, tc.Name)
+	}
+
+	scenario := &Scenario{
+		Name: "sync",
+		Steps: []Step{
+			{
+				Name:      "status can't be written",
+				Faults:    []Fault{APIServerError("*", "tidbclusters", errors.NewInternalError(fmt.Errorf("etcd timeout")))},
+				Reconcile: sync,
+				Verify: func(env *Env, err error) error {
+					if err == nil || !strings.Contains(err.Error(), "etcd timeout") {
+						return fmt.Errorf("expect the status update to fail, got %v", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name: "pd member manager fails",
+				Reconcile: func(env *Env) error {
+					env.Managers.PD.SetSyncError(fmt.Errorf("pd unavailable"))
+					defer env.Managers.PD.SetSyncError(nil)
+					return sync(env)
+				},
+				Verify: func(env *Env, err error) error {
+					if err == nil || !strings.Contains(err.Error(), "pd unavailable") {
+						return fmt.Errorf("expect the error of the pd member manager, got %v", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "synced",
+				Reconcile: sync,
+				Verify: func(env *Env, err error) error {
+					if err != nil {
+						return err
+					}
+					// the status written by the control is read from the informer cache
+					cached, err := env.Deps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+					if err != nil {
+						return err
+					}
+					if utiltidbcluster.GetTidbClusterCondition(cached.Status, v1alpha1.TidbClusterReady) == nil {
+						return fmt.Errorf("expect the Ready condition to be written, got %+v", cached.Status.Conditions)
+					}
+					return nil
+				},
+			},
+		},
+	}
+	g.Expect(scenario.Run(env)).To(Succeed())
+}
@@ -15,10 +15,12 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -39,6 +41,40 @@ type captureInfo struct {
 	AdvertiseAddr string `json:"address"`
 }
 
+const (
+	// ChangefeedStateNormal is the state of a changefeed which is replicating normally
+	ChangefeedStateNormal = "normal"
+	// ChangefeedStateStopped is the state of a changefeed which is paused
+	ChangefeedStateStopped = "stopped"
+)
+
+// ChangefeedInfo is the brief information of a changefeed
+type ChangefeedInfo struct {
+	Namespace      string `json:"namespace,omitempty"`
+	ID             string `json:"id"`
+	State          string `json:"state"`
+	CheckpointTSO  uint64 `json:"checkpoint_tso,omitempty"`
+	CheckpointTime string `json:"checkpoint_time,omitempty"`
+}
+
+// listCapturesResp is response of the open API v2 `ListCaptures`
+type listCapturesResp struct {
+	Total int           `json:"total"`
+	Items []captureInfo `json:"items"`
+}
+
+// listChangefeedsResp is response of the open API v2 `ListChangefeeds`
+type listChangefeedsResp struct {
+	Total int              `json:"total"`
+	Items []ChangefeedInfo `json:"items"`
+}
+
+// changefeedConfig is request of the open API v2 `CreateChangefeed` and `UpdateChangefeed`
+type changefeedConfig struct {
+	ID      string `json:"changefeed_id,omitempty"`
+	SinkURI string `json:"sink_uri"`
+}
+
 // drainCaptureRequest is request for manual `DrainCapture`
 type drainCaptureRequest struct {
 	CaptureID string `json:"capture_id"`
@@ -62,6 +98,18 @@ type TiCDCControlInterface interface {
 	// otherwise caller should retry resign owner.
 	// If there is only one capture, it always return true.
 	ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) (ok bool, err error)
+	// IsCaptureAlive returns whether the capture is alive in the TiCDC cluster.
+	// If the TiCDC does not support the captures API, it always return true.
+	IsCaptureAlive(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error)
+	// ListChangefeeds returns the changefeeds of the TiCDC cluster.
+	ListChangefeeds(tc *v1alpha1.TidbCluster, ordinal int32) ([]ChangefeedInfo, error)
+	// GetChangefeed returns the changefeed of the id, nil is returned if it is not found.
+	GetChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, id string) (*ChangefeedInfo, error)
+	// CreateChangefeed creates a changefeed replicating to the sink URI by the open API v2.
+	CreateChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, id, sinkURI string) error
+	// UpdateChangefeedSink updates the sink URI of the changefeed by the open API v2, the changefeed
+	// is paused during the update and resumed after it unless it is already paused.
+	UpdateChangefeedSink(tc *v1alpha1.TidbCluster, ordinal int32, id, sinkURI string) error
 }
 
 // defaultTiCDCControl is default implementation of TiCDCControlInterface.
@@ -71,9 +119,9 @@ type defaultTiCDCControl struct {
 	testURL string
 }
 
-// NewDefaultTiCDCControl returns a defaultTiCDCControl instance
-func NewDefaultTiCDCControl(secretLister corelisterv1.SecretLister) *defaultTiCDCControl {
-	return &defaultTiCDCControl{httpClient: httpClient{secretLister: secretLister}}
+// NewDefaultTiCDCControl returns a defaultTiCDCControl instance whose requests are canceled once ctx is done
+func NewDefaultTiCDCControl(ctx context.Context, secretLister corelisterv1.SecretLister) *defaultTiCDCControl {
+	return &defaultTiCDCControl{httpClient: httpClient{ctx: ctx, secretLister: secretLister}}
 }
 
 func (c *defaultTiCDCControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error) {
@@ -84,7 +132,7 @@ func (c *defaultTiCDCControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32)
 
 	baseURL := c.getBaseURL(tc, ordinal)
 	url := fmt.Sprintf("%s/status", baseURL)
-	body, err := getBodyOK(httpClient, url)
+	body, err := getBodyOK(c.ctx, httpClient, url)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +151,7 @@ func (c *defaultTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int
 
 	baseURL := c.getBaseURL(tc, ordinal)
 
-	captures, retry, err := getCaptures(httpClient, baseURL)
+	captures, retry, err := getCaptures(c.ctx, httpClient, baseURL)
 	if err != nil {
 		klog.Warningf("ticdc control: drain capture is failed, error: %v", err)
 		return 0, false, err
@@ -137,7 +185,7 @@ func (c *defaultTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int
 	if err != nil {
 		return 0, false, fmt.Errorf("ticdc drain capture failed, new request error: %v", err)
 	}
-	res, err := httpClient.Do(req)
+	res, err := httputil.Do(c.ctx, httpClient, req)
 	if err != nil {
 		return 0, false, fmt.Errorf("ticdc drain capture failed, request error: %v", err)
 	}
@@ -174,7 +222,7 @@ func (c *defaultTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int3
 	}
 
 	baseURL := c.getBaseURL(tc, ordinal)
-	captures, retry, err := getCaptures(httpClient, baseURL)
+	captures, retry, err := getCaptures(c.ctx, httpClient, baseURL)
 	if err != nil {
 		klog.Warningf("ticdc control: resign owner failed, error: %v", err)
 		return false, err
@@ -200,7 +248,11 @@ func (c *defaultTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int3
 		return true, nil
 	}
 
-	res, err := httpClient.Post(baseURL+"/api/v1/owner/resign", "", nil)
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/api/v1/owner/resign", nil)
+	if err != nil {
+		return false, fmt.Errorf("ticdc resign owner failed, new request error: %v", err)
+	}
+	res, err := httputil.Do(c.ctx, httpClient, req)
 	if err != nil {
 		return false, fmt.Errorf("ticdc resign owner failed, request error: %v", err)
 	}
@@ -218,6 +270,137 @@ func (c *defaultTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int3
 	return false, nil
 }
 
+func (c *defaultTiCDCControl) IsCaptureAlive(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return false, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	var captures []captureInfo
+	v2 := listCapturesResp{}
+	found, err := getJSONWithRetry(c.ctx, httpClient, baseURL+"/api/v2/captures", &v2)
+	if err != nil {
+		return false, fmt.Errorf("ticdc get captures failed, error: %v", err)
+	}
+	captures = v2.Items
+	if !found {
+		found, err = getJSONWithRetry(c.ctx, httpClient, baseURL+"/api/v1/captures", &captures)
+		if err != nil {
+			return false, fmt.Errorf("ticdc get captures failed, error: %v", err)
+		}
+		if !found {
+			// It is likely the TiCDC does not support the API, regard the capture as alive.
+			return true, nil
+		}
+	}
+
+	this, _ := getOrdinalAndOwnerCaptureInfo(tc, ordinal, captures)
+	return this != nil, nil
+}
+
+func (c *defaultTiCDCControl) ListChangefeeds(tc *v1alpha1.TidbCluster, ordinal int32) ([]ChangefeedInfo, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	v2 := listChangefeedsResp{}
+	found, err := getJSONWithRetry(c.ctx, httpClient, baseURL+"/api/v2/changefeeds", &v2)
+	if err != nil {
+		return nil, fmt.Errorf("ticdc list changefeeds failed, error: %v", err)
+	}
+	if found {
+		return v2.Items, nil
+	}
+	var changefeeds []ChangefeedInfo
+	found, err = getJSONWithRetry(c.ctx, httpClient, baseURL+"/api/v1/changefeeds", &changefeeds)
+	if err != nil {
+		return nil, fmt.Errorf("ticdc list changefeeds failed, error: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("ticdc list changefeeds failed, %s does not support the API", baseURL)
+	}
+	return changefeeds, nil
+}
+
+func (c *defaultTiCDCControl) GetChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, id string) (*ChangefeedInfo, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	for _, version := range []string{"v2", "v1"} {
+		changefeed := &ChangefeedInfo{}
+		found, err := getJSONWithRetry(c.ctx, httpClient, fmt.Sprintf("%s/api/%s/changefeeds/%s", baseURL, version, url.PathEscape(id)), changefeed)
+		if err != nil {
+			return nil, fmt.Errorf("ticdc get changefeed %s failed, error: %v", id, err)
+		}
+		if found {
+			return changefeed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *defaultTiCDCControl) CreateChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, id, sinkURI string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(changefeedConfig{ID: id, SinkURI: sinkURI})
+	if err != nil {
+		return fmt.Errorf("ticdc create changefeed %s failed, marshal request error: %v", id, err)
+	}
+	baseURL := c.getBaseURL(tc, ordinal)
+	if _, err := httputil.PostBodyOK(c.ctx, httpClient, baseURL+"/api/v2/changefeeds", bytes.NewReader(payload)); err != nil {
+		return fmt.Errorf("ticdc create changefeed %s failed, error: %v", id, err)
+	}
+	return nil
+}
+
+func (c *defaultTiCDCControl) UpdateChangefeedSink(tc *v1alpha1.TidbCluster, ordinal int32, id, sinkURI string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	changefeedURL := fmt.Sprintf("%s/api/v2/changefeeds/%s", baseURL, url.PathEscape(id))
+	changefeed := &ChangefeedInfo{}
+	found, err := getJSONWithRetry(c.ctx, httpClient, changefeedURL, changefeed)
+	if err != nil {
+		return fmt.Errorf("ticdc get changefeed %s failed, error: %v", id, err)
+	}
+	if !found {
+		return fmt.Errorf("ticdc update changefeed %s failed, it is not found", id)
+	}
+
+	// TiCDC only accepts a new sink URI for a paused changefeed
+	pausedByUser := changefeed.State == ChangefeedStateStopped
+	if !pausedByUser {
+		if _, err := httputil.PostBodyOK(c.ctx, httpClient, changefeedURL+"/pause", nil); err != nil {
+			return fmt.Errorf("ticdc pause changefeed %s failed, error: %v", id, err)
+		}
+	}
+	payload, err := json.Marshal(changefeedConfig{SinkURI: sinkURI})
+	if err != nil {
+		return fmt.Errorf("ticdc update changefeed %s failed, marshal request error: %v", id, err)
+	}
+	if _, err := httputil.DoBodyOK(c.ctx, httpClient, changefeedURL, http.MethodPut, bytes.NewReader(payload)); err != nil {
+		return fmt.Errorf("ticdc update changefeed %s failed, error: %v", id, err)
+	}
+	if !pausedByUser {
+		if _, err := httputil.PostBodyOK(c.ctx, httpClient, changefeedURL+"/resume", nil); err != nil {
+			return fmt.Errorf("ticdc resume changefeed %s failed, error: %v", id, err)
+		}
+	}
+	return nil
+}
+
 func (c *defaultTiCDCControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32) string {
 	if c.testURL != "" {
 		return c.testURL
@@ -239,8 +422,12 @@ func getCaptureAdvertiseAddressPrefix(tc *v1alpha1.TidbCluster, ordinal int32) s
 	return fmt.Sprintf("%s.%s.%s", hostName, TiCDCPeerMemberName(tcName), ns)
 }
 
-func getCaptures(httpClient *http.Client, baseURL string) ([]captureInfo, bool, error) {
-	res, err := httpClient.Get(baseURL + "/api/v1/captures")
+func getCaptures(ctx context.Context, httpClient *http.Client, baseURL string) ([]captureInfo, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/api/v1/captures", nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("ticdc get captures failed, new request error: %v", err)
+	}
+	res, err := httputil.Do(ctx, httpClient, req)
 	if err != nil {
 		return nil, false, fmt.Errorf("ticdc get captures failed, request error: %v", err)
 	}
@@ -267,6 +454,20 @@ func getCaptures(httpClient *http.Client, baseURL string) ([]captureInfo, bool,
 	return resp, false, nil
 }
 
+// getJSONWithRetry gets the apiURL and decodes the response into v, it retries if the request
+// is failed by transient errors, e.g. the TiCDC is unavailable. It returns false if the apiURL is not found, which is likely
+// that the TiCDC does not support the API.
+func getJSONWithRetry(ctx context.Context, httpClient *http.Client, apiURL string, v interface{}) (bool, error) {
+	body, err := httputil.GetBodyOK(ctx, httpClient, apiURL)
+	if err != nil {
+		if statusErr, ok := err.(*httputil.StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, json.Unmarshal(body, v)
+}
+
 func getOrdinalAndOwnerCaptureInfo(
 	tc *v1alpha1.TidbCluster, ordinal int32, captures []captureInfo,
 ) (this, owner *captureInfo) {
@@ -285,7 +486,11 @@ func getOrdinalAndOwnerCaptureInfo(
 
 // FakeTiCDCControl is a fake implementation of TiCDCControlInterface.
 type FakeTiCDCControl struct {
-	getStatus func(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error)
+	getStatus       func(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error)
+	listChangefeeds func(tc *v1alpha1.TidbCluster, ordinal int32) ([]ChangefeedInfo, error)
+	// Changefeeds are the sink URIs of the changefeeds created or updated, keyed by the IDs
+	Changefeeds    map[string]string
+	changefeedsErr error
 }
 
 // NewFakeTiCDCControl returns a FakeTiCDCControl instance
@@ -312,3 +517,56 @@ func (c *FakeTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int32)
 func (c *FakeTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) (ok bool, err error) {
 	return true, nil
 }
+
+func (c *FakeTiCDCControl) IsCaptureAlive(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	return true, nil
+}
+
+// MockListChangefeeds mocks the changefeeds returned by ListChangefeeds
+func (c *FakeTiCDCControl) MockListChangefeeds(mockfunc func(tc *v1alpha1.TidbCluster, ordinal int32) ([]ChangefeedInfo, error)) {
+	c.listChangefeeds = mockfunc
+}
+
+func (c *FakeTiCDCControl) ListChangefeeds(tc *v1alpha1.TidbCluster, ordinal int32) ([]ChangefeedInfo, error) {
+	if c.listChangefeeds == nil {
+		return nil, nil
+	}
+	return c.listChangefeeds(tc, ordinal)
+}
+
+func (c *FakeTiCDCControl) GetChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, id string) (*ChangefeedInfo, error) {
+	if c.changefeedsErr != nil {
+		return nil, c.changefeedsErr
+	}
+	if _, ok := c.Changefeeds[id]; !ok {
+		return nil, nil
+	}
+	return &ChangefeedInfo{ID: id, State: ChangefeedStateNormal}, nil
+}
+
+// SetChangefeedsError sets the error returned by the requests of the changefeeds
+func (c *FakeTiCDCControl) SetChangefeedsError(err error) {
+	c.changefeedsErr = err
+}
+
+func (c *FakeTiCDCControl) CreateChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, id, sinkURI string) error {
+	if c.changefeedsErr != nil {
+		return c.changefeedsErr
+	}
+	if c.Changefeeds == nil {
+		c.Changefeeds = map[string]string{}
+	}
+	c.Changefeeds[id] = sinkURI
+	return nil
+}
+
+func (c *FakeTiCDCControl) UpdateChangefeedSink(tc *v1alpha1.TidbCluster, ordinal int32, id, sinkURI string) error {
+	if c.changefeedsErr != nil {
+		return c.changefeedsErr
+	}
+	if _, ok := c.Changefeeds[id]; !ok {
+		return fmt.Errorf("changefeed %s is not found", id)
+	}
+	c.Changefeeds[id] = sinkURI
+	return nil
+}
@@ -14,6 +14,7 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -21,9 +22,11 @@ import (
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/tidbapi"
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	"github.com/pingcap/tidb/config"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -45,6 +48,8 @@ type TiDBControlInterface interface {
 	GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBInfo, error)
 	// GetSettings return the TiDB instance settings
 	GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error)
+	// GetTiDBPodClient returns the typed client of the status api of the tidb pod
+	GetTiDBPodClient(tc *v1alpha1.TidbCluster, podName string) tidbapi.TiDBClient
 }
 
 // defaultTiDBControl is default implementation of TiDBControlInterface.
@@ -54,9 +59,9 @@ type defaultTiDBControl struct {
 	testURL string
 }
 
-// NewDefaultTiDBControl returns a defaultTiDBControl instance
-func NewDefaultTiDBControl(secretLister corelisterv1.SecretLister) *defaultTiDBControl {
-	return &defaultTiDBControl{httpClient: httpClient{secretLister: secretLister}}
+// NewDefaultTiDBControl returns a defaultTiDBControl instance whose requests are canceled once ctx is done
+func NewDefaultTiDBControl(ctx context.Context, secretLister corelisterv1.SecretLister) *defaultTiDBControl {
+	return &defaultTiDBControl{httpClient: httpClient{ctx: ctx, secretLister: secretLister}}
 }
 
 func (c *defaultTiDBControl) GetHealth(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
@@ -67,7 +72,7 @@ func (c *defaultTiDBControl) GetHealth(tc *v1alpha1.TidbCluster, ordinal int32)
 
 	baseURL := c.getBaseURL(tc, ordinal)
 	url := fmt.Sprintf("%s/status", baseURL)
-	_, err = getBodyOK(httpClient, url)
+	_, err = getBodyOK(c.ctx, httpClient, url)
 	return err == nil, nil
 }
 
@@ -83,7 +88,7 @@ func (c *defaultTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*
 	if err != nil {
 		return nil, err
 	}
-	res, err := httpClient.Do(req)
+	res, err := httputil.Do(c.ctx, httpClient, req)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +121,7 @@ func (c *defaultTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32
 	if err != nil {
 		return nil, err
 	}
-	res, err := httpClient.Do(req)
+	res, err := httputil.Do(c.ctx, httpClient, req)
 	if err != nil {
 		return nil, err
 	}
@@ -137,8 +142,21 @@ func (c *defaultTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32
 	return &info, nil
 }
 
-func getBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
-	res, err := httpClient.Get(apiURL)
+func (c *defaultTiDBControl) GetTiDBPodClient(tc *v1alpha1.TidbCluster, podName string) tidbapi.TiDBClient {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		klog.Errorf("Unable to get http client for TiDB cluster %s/%s, tidb client may not work: %v", tc.GetNamespace(), tc.GetName(), err)
+		httpClient = httputil.LimitCluster(&http.Client{Timeout: timeout}, fmt.Sprintf("%s/%s", tc.GetNamespace(), tc.GetName()))
+	}
+	return tidbapi.NewTiDBClientWithHTTPClient(c.ctx, c.getPodBaseURL(tc, podName), httpClient)
+}
+
+func getBodyOK(ctx context.Context, httpClient *http.Client, apiURL string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := httputil.Do(ctx, httpClient, req)
 	if err != nil {
 		return nil, err
 	}
@@ -156,6 +174,10 @@ func getBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
 }
 
 func (c *defaultTiDBControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32) string {
+	return c.getPodBaseURL(tc, fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal))
+}
+
+func (c *defaultTiDBControl) getPodBaseURL(tc *v1alpha1.TidbCluster, podName string) string {
 	if c.testURL != "" {
 		return c.testURL
 	}
@@ -163,9 +185,8 @@ func (c *defaultTiDBControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32)
 	tcName := tc.GetName()
 	ns := tc.GetNamespace()
 	scheme := tc.Scheme()
-	hostName := fmt.Sprintf("%s-%d", TiDBMemberName(tcName), ordinal)
 
-	return fmt.Sprintf("%s://%s.%s.%s:10080", scheme, hostName, TiDBPeerMemberName(tcName), ns)
+	return fmt.Sprintf("%s://%s.%s.%s:10080", scheme, podName, TiDBPeerMemberName(tcName), ns)
 }
 
 // FakeTiDBControl is a fake implementation of TiDBControlInterface.
@@ -174,6 +195,7 @@ type FakeTiDBControl struct {
 	tiDBInfo     *DBInfo
 	getInfoError error
 	tidbConfig   *config.Config
+	podClients   map[string]tidbapi.TiDBClient
 }
 
 // NewFakeTiDBControl returns a FakeTiDBControl instance
@@ -204,3 +226,20 @@ func (c *FakeTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBI
 func (c *FakeTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error) {
 	return c.tidbConfig, c.getInfoError
 }
+
+// SetTiDBPodClient sets the client returned by GetTiDBPodClient for the tidb pod
+func (c *FakeTiDBControl) SetTiDBPodClient(podName string, client tidbapi.TiDBClient) {
+	if c.podClients == nil {
+		c.podClients = map[string]tidbapi.TiDBClient{}
+	}
+	c.podClients[podName] = client
+}
+
+// GetTiDBPodClient returns the client set by SetTiDBPodClient, a FakeTiDBClient without
+// any reaction is returned if it's not set
+func (c *FakeTiDBControl) GetTiDBPodClient(tc *v1alpha1.TidbCluster, podName string) tidbapi.TiDBClient {
+	if client, ok := c.podClients[podName]; ok {
+		return client
+	}
+	return tidbapi.NewFakeTiDBClient()
+}
@@ -14,6 +14,7 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -134,7 +135,7 @@ func TestHealth(t *testing.T) {
 		fakeClient := &fake.Clientset{}
 		tc := getTidbCluster()
 		informer := kubeinformers.NewSharedInformerFactory(fakeClient, 0)
-		control := NewDefaultTiDBControl(informer.Core().V1().Secrets().Lister())
+		control := NewDefaultTiDBControl(context.Background(), informer.Core().V1().Secrets().Lister())
 		control.testURL = svc.URL
 		result, err := control.GetHealth(tc, 0)
 		g.Expect(err).NotTo(HaveOccurred())
@@ -197,7 +198,7 @@ func TestInfo(t *testing.T) {
 
 		fakeClient := &fake.Clientset{}
 		informer := kubeinformers.NewSharedInformerFactory(fakeClient, 0)
-		control := NewDefaultTiDBControl(informer.Core().V1().Secrets().Lister())
+		control := NewDefaultTiDBControl(context.Background(), informer.Core().V1().Secrets().Lister())
 		control.testURL = svc.URL
 		tc := getTidbCluster()
 		result, err := control.GetInfo(tc, 0)
@@ -257,7 +258,7 @@ func TestSettings(t *testing.T) {
 
 		fakeClient := &fake.Clientset{}
 		informer := kubeinformers.NewSharedInformerFactory(fakeClient, 0)
-		control := NewDefaultTiDBControl(informer.Core().V1().Secrets().Lister())
+		control := NewDefaultTiDBControl(context.Background(), informer.Core().V1().Secrets().Lister())
 		control.testURL = svc.URL
 		tc := getTidbCluster()
 		result, err := control.GetSettings(tc, 0)
@@ -312,7 +313,7 @@ func TestGetHTTPClient(t *testing.T) {
 			},
 		})
 		g.Expect(err).Should(BeNil())
-		control := NewDefaultTiDBControl(informer.Core().V1().Secrets().Lister())
+		control := NewDefaultTiDBControl(context.Background(), informer.Core().V1().Secrets().Lister())
 		tc := getTidbCluster()
 		c.updateTC(tc)
 		httpClient, err := control.getHTTPClient(tc)
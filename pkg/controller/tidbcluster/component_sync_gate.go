@@ -0,0 +1,170 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbcluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/klog/v2"
+)
+
+// ComponentSyncGate skips syncing a component if neither its spec nor the status it depends on
+// is changed since the last successful sync. A component is always synced if it's not in Normal
+// phase, and it's synced at least once per period so that its status from PD is refreshed.
+//
+// Only TiFlash, TiCDC and Pump are gated, PD, TiKV and TiDB are always synced because the
+// other components depend on their status.
+type ComponentSyncGate struct {
+	stsLister appslisters.StatefulSetLister
+	period    time.Duration
+
+	lock    sync.Mutex
+	records map[string]componentSyncRecord
+}
+
+type componentSyncRecord struct {
+	hash     string
+	syncTime time.Time
+}
+
+// componentSyncInput is the input of a component sync, the sync is skipped if it's not changed
+type componentSyncInput struct {
+	Spec                *v1alpha1.TidbClusterSpec `json:"spec"`
+	Annotations         map[string]string         `json:"annotations,omitempty"`
+	PDAvailable         bool                      `json:"pdAvailable"`
+	TiKVAvailable       bool                      `json:"tikvAvailable"`
+	PDPhase             v1alpha1.MemberPhase      `json:"pdPhase,omitempty"`
+	TiKVPhase           v1alpha1.MemberPhase      `json:"tikvPhase,omitempty"`
+	StatefulSetRevision string                    `json:"statefulSetRevision,omitempty"`
+}
+
+// NewComponentSyncGate returns a ComponentSyncGate which syncs each component at least once per period
+func NewComponentSyncGate(stsLister appslisters.StatefulSetLister, period time.Duration) *ComponentSyncGate {
+	return &ComponentSyncGate{
+		stsLister: stsLister,
+		period:    period,
+		records:   map[string]componentSyncRecord{},
+	}
+}
+
+// Sync syncs the component with m unless it can be skipped, all components are synced if g is nil
+func (g *ComponentSyncGate) Sync(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, m manager.Manager) error {
+	if g == nil {
+		return m.Sync(tc)
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", tc.GetNamespace(), tc.GetName(), memberType)
+	hash, err := g.hash(tc, memberType)
+	if err != nil {
+		klog.Warningf("TidbCluster: [%s/%s], failed to hash the sync input of %s, sync it anyway, error: %v", tc.GetNamespace(), tc.GetName(), memberType, err)
+	}
+	if hash != "" && !g.needSync(key, hash) {
+		klog.V(4).Infof("TidbCluster: [%s/%s], %s is not changed since the last sync, skip syncing", tc.GetNamespace(), tc.GetName(), memberType)
+		return nil
+	}
+
+	if err := m.Sync(tc); err != nil {
+		g.forget(key)
+		return err
+	}
+	if hash != "" {
+		g.record(key, hash)
+	}
+	return nil
+}
+
+func (g *ComponentSyncGate) needSync(key, hash string) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	record, ok := g.records[key]
+	return !ok || record.hash != hash || time.Since(record.syncTime) >= g.period
+}
+
+func (g *ComponentSyncGate) record(key, hash string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.records[key] = componentSyncRecord{hash: hash, syncTime: time.Now()}
+}
+
+func (g *ComponentSyncGate) forget(key string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	delete(g.records, key)
+}
+
+// hash returns the hash of the sync input of the component, it returns an empty string if
+// the component can not be skipped
+func (g *ComponentSyncGate) hash(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) (string, error) {
+	spec := tc.Spec.DeepCopy()
+	spec.PD, spec.TiDB, spec.TiKV, spec.TiFlash, spec.TiCDC, spec.Pump = nil, nil, nil, nil, nil, nil
+
+	var stsName string
+	switch memberType {
+	case v1alpha1.TiFlashMemberType:
+		status := tc.Status.TiFlash
+		if tc.Spec.TiFlash == nil || status.Phase != v1alpha1.NormalPhase || !status.Synced || len(status.FailureStores) > 0 {
+			return "", nil
+		}
+		spec.TiFlash = tc.Spec.TiFlash
+		stsName = controller.TiFlashMemberName(tc.GetName())
+	case v1alpha1.TiCDCMemberType:
+		status := tc.Status.TiCDC
+		if tc.Spec.TiCDC == nil || status.Phase != v1alpha1.NormalPhase || !status.Synced {
+			return "", nil
+		}
+		spec.TiCDC = tc.Spec.TiCDC
+		stsName = controller.TiCDCMemberName(tc.GetName())
+	case v1alpha1.PumpMemberType:
+		if tc.Spec.Pump == nil || tc.Status.Pump.Phase != v1alpha1.NormalPhase {
+			return "", nil
+		}
+		spec.Pump = tc.Spec.Pump
+		stsName = controller.PumpMemberName(tc.GetName())
+	default:
+		return "", nil
+	}
+
+	input := componentSyncInput{
+		Spec:          spec,
+		Annotations:   tc.GetAnnotations(),
+		PDAvailable:   tc.PDIsAvailable(),
+		TiKVAvailable: tc.TiKVIsAvailable(),
+		PDPhase:       tc.Status.PD.Phase,
+		TiKVPhase:     tc.Status.TiKV.Phase,
+	}
+	// the status of the StatefulSet is updated if any of its Pods becomes ready or unready
+	sts, err := g.stsLister.StatefulSets(tc.GetNamespace()).Get(stsName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	input.StatefulSetRevision = sts.ResourceVersion
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	return v1alpha1.HashContents(data), nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbcluster
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type countingManager struct {
+	syncs int
+}
+
+func (m *countingManager) Sync(_ *v1alpha1.TidbCluster) error {
+	m.syncs++
+	return nil
+}
+
+func TestComponentSyncGate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	stsIndexer := deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer()
+	gate := NewComponentSyncGate(deps.StatefulSetLister, time.Hour)
+	m := &countingManager{}
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiFlash: &v1alpha1.TiFlashSpec{Replicas: 1},
+		},
+	}
+	sts := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: controller.TiFlashMemberName("test"), Namespace: metav1.NamespaceDefault, ResourceVersion: "1"},
+	}
+	stsIndexer.Add(sts)
+
+	// the component is always synced until it's in normal phase
+	g.Expect(gate.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(gate.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(m.syncs).To(Equal(2))
+
+	tc.Status.TiFlash.Phase = v1alpha1.NormalPhase
+	tc.Status.TiFlash.Synced = true
+	g.Expect(gate.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(gate.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(m.syncs).To(Equal(3))
+
+	// the changes of other components are ignored
+	tc.Spec.TiCDC = &v1alpha1.TiCDCSpec{Replicas: 1}
+	g.Expect(gate.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(m.syncs).To(Equal(3))
+
+	// the component is synced if its spec is changed
+	tc.Spec.TiFlash.Replicas = 2
+	g.Expect(gate.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(m.syncs).To(Equal(4))
+
+	// the component is synced if its StatefulSet is changed
+	sts = sts.DeepCopy()
+	sts.ResourceVersion = "2"
+	stsIndexer.Update(sts)
+	g.Expect(gate.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(gate.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(m.syncs).To(Equal(5))
+
+	// all components are synced if the gate is disabled
+	var disabled *ComponentSyncGate
+	g.Expect(disabled.Sync(tc, v1alpha1.TiFlashMemberType, m)).To(Succeed())
+	g.Expect(m.syncs).To(Equal(6))
+}
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/retry"
@@ -72,6 +73,11 @@ func NewPodController(deps *controller.Dependencies) *PodController {
 		},
 	})
 
+	tcInformer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters()
+	tcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.enqueueEvictLeaderPods,
+	})
+
 	return c
 }
 
@@ -105,6 +111,34 @@ func (c *PodController) enqueuePod(obj interface{}) {
 	c.queue.Add(key)
 }
 
+// enqueueEvictLeaderPods enqueues the TiKV pods which are requested to evict leader
+// by `spec.tikv.evictLeader`, or were requested before the update.
+func (c *PodController) enqueueEvictLeaderPods(old, cur interface{}) {
+	oldTC, ok := old.(*v1alpha1.TidbCluster)
+	if !ok {
+		return
+	}
+	curTC, ok := cur.(*v1alpha1.TidbCluster)
+	if !ok {
+		return
+	}
+
+	podNames := sets.NewString()
+	for _, tc := range []*v1alpha1.TidbCluster{oldTC, curTC} {
+		if tc.Spec.TiKV != nil {
+			podNames.Insert(tc.Spec.TiKV.EvictLeader...)
+		}
+	}
+	for podName, status := range curTC.Status.TiKV.EvictLeader {
+		if status != nil && status.Source == v1alpha1.EvictLeaderSourceSpec {
+			podNames.Insert(podName)
+		}
+	}
+	for _, podName := range podNames.List() {
+		c.queue.Add(fmt.Sprintf("%s/%s", curTC.Namespace, podName))
+	}
+}
+
 // Run the controller.
 func (c *PodController) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
@@ -113,17 +147,13 @@ func (c *PodController) Run(workers int, stopCh <-chan struct{}) {
 	klog.Info("Starting tidbcluster pod controller")
 	defer klog.Info("Shutting down tidbcluster pod controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
+	// the number of the workers follows the workers flag reloaded from the config file
+	wg := controller.RunWorkers(workers, c.deps.CLIConfig, c.processNextWorkItem, stopCh)
 
 	<-stopCh
-}
-
-// worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *PodController) worker() {
-	for c.processNextWorkItem() {
-	}
+	// wait for the in-flight syncs to finish, so that no operation is left half-done
+	c.queue.ShutDown()
+	wg.Wait()
 }
 
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
@@ -215,6 +245,10 @@ func (c *PodController) getPDClient(tc *v1alpha1.TidbCluster) pdapi.PDClient {
 
 func (c *PodController) syncTiKVPod(ctx context.Context, pod *corev1.Pod, tc *v1alpha1.TidbCluster) (reconcile.Result, error) {
 	key, value, ok := needEvictLeader(pod)
+	if !ok && tc.TiKVPodEvictLeaderRequested(pod.Name) {
+		// the eviction requested by spec never deletes the pod
+		key, value, ok = v1alpha1.EvictLeaderSourceSpec, v1alpha1.EvictLeaderValueNone, true
+	}
 
 	if ok {
 		switch value {
@@ -231,23 +265,22 @@ func (c *PodController) syncTiKVPod(ctx context.Context, pod *corev1.Pod, tc *v1
 			PodCreateTime: pod.CreationTimestamp,
 			BeginTime:     metav1.Now(),
 			Value:         value,
+			Source:        key,
 		}
 		nowStatus := tc.Status.TiKV.EvictLeader[pod.Name]
 		if nowStatus != nil && !nowStatus.BeginTime.IsZero() {
 			evictStatus.BeginTime = nowStatus.BeginTime
+			if nowStatus.PodCreateTime.Equal(&pod.CreationTimestamp) {
+				evictStatus.Completed = nowStatus.Completed
+			}
 		}
 
 		// update status of eviction
 		if nowStatus == nil || *nowStatus != *evictStatus {
-			if tc.Status.TiKV.EvictLeader == nil {
-				tc.Status.TiKV.EvictLeader = make(map[string]*v1alpha1.EvictLeaderStatus)
-			}
-			tc.Status.TiKV.EvictLeader[pod.Name] = evictStatus
 			var err error
-			key := fmt.Sprintf("%s/%s", tc.Namespace, tc.Name)
-			tc, err = c.deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(ctx, tc, metav1.UpdateOptions{})
+			tc, err = c.updateEvictLeaderStatus(ctx, tc, pod.Name, evictStatus)
 			if err != nil {
-				return reconcile.Result{}, perrors.Annotatef(err, "failed to update tc %q status", key)
+				return reconcile.Result{}, err
 			}
 
 			stat := c.getPodStat(pod)
@@ -266,10 +299,37 @@ func (c *PodController) syncTiKVPod(ctx context.Context, pod *corev1.Pod, tc *v1
 			return reconcile.Result{}, perrors.Annotatef(err, "failed to evict leader for store %d (Pod %s/%s)", storeID, pod.Namespace, pod.Name)
 		}
 
+		tlsEnabled := tc.IsTLSClusterEnabled()
+		kvClient := c.deps.TiKVControl.GetTiKVPodClient(tc.Namespace, tc.Name, pod.Name, tlsEnabled)
+
+		// track the progress of eviction requested by spec
+		if key == v1alpha1.EvictLeaderSourceSpec {
+			leaderCount, err := kvClient.GetLeaderCount()
+			if err != nil {
+				return reconcile.Result{}, perrors.Annotatef(err, "failed to get leader count for pod %s/%s", pod.Namespace, pod.Name)
+			}
+
+			klog.Infof("Region leader count is %d for Pod %s/%s", leaderCount, pod.Namespace, pod.Name)
+
+			completed := leaderCount == 0
+			if completed != evictStatus.Completed {
+				evictStatus = evictStatus.DeepCopy()
+				evictStatus.Completed = completed
+				if _, err := c.updateEvictLeaderStatus(ctx, tc, pod.Name, evictStatus); err != nil {
+					return reconcile.Result{}, err
+				}
+				if completed {
+					c.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "EvictLeaderCompleted", "all region leaders are evicted from store %d (Pod %s)", storeID, pod.Name)
+				}
+			}
+			if !completed {
+				// re-check leader count next time
+				return reconcile.Result{RequeueAfter: c.recheckLeaderCountDuration}, nil
+			}
+		}
+
 		// delete pod after eviction finished if needed
 		if value == v1alpha1.EvictLeaderValueDeletePod {
-			tlsEnabled := tc.IsTLSClusterEnabled()
-			kvClient := c.deps.TiKVControl.GetTiKVPodClient(tc.Namespace, tc.Name, pod.Name, tlsEnabled)
 			leaderCount, err := kvClient.GetLeaderCount()
 			if err != nil {
 				return reconcile.Result{}, perrors.Annotatef(err, "failed to get leader count for pod %s/%s", pod.Namespace, pod.Name)
@@ -349,6 +409,19 @@ func (c *PodController) syncTiKVPod(ctx context.Context, pod *corev1.Pod, tc *v1
 	return reconcile.Result{}, nil
 }
 
+// updateEvictLeaderStatus sets the status of eviction for the pod and updates it to api-server
+func (c *PodController) updateEvictLeaderStatus(ctx context.Context, tc *v1alpha1.TidbCluster, podName string, status *v1alpha1.EvictLeaderStatus) (*v1alpha1.TidbCluster, error) {
+	if tc.Status.TiKV.EvictLeader == nil {
+		tc.Status.TiKV.EvictLeader = make(map[string]*v1alpha1.EvictLeaderStatus)
+	}
+	tc.Status.TiKV.EvictLeader[podName] = status
+	updated, err := c.deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(ctx, tc, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, perrors.Annotatef(err, "failed to update tc %q status", fmt.Sprintf("%s/%s", tc.Namespace, tc.Name))
+	}
+	return updated, nil
+}
+
 func needEvictLeader(pod *corev1.Pod) (string, string, bool) {
 	for _, key := range v1alpha1.EvictLeaderAnnKeys {
 		value, exist := pod.Annotations[key]
@@ -31,6 +31,7 @@ import (
 )
 
 type kvClient struct {
+	tikvapi.TiKVClient
 	leaderCount int32
 }
 
@@ -126,6 +127,87 @@ func TestPodControllerSync(t *testing.T) {
 	}, timeout, interval).ShouldNot(Equal(0), "should finish annotation")
 }
 
+func TestPodControllerSyncEvictLeaderBySpec(t *testing.T) {
+	interval := time.Millisecond * 100
+	timeout := time.Minute * 1
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	pod := newTiKVPod(tc)
+	tc.Spec.TiKV.EvictLeader = []string{pod.Name}
+	tc.Status.TiKV = v1alpha1.TiKVStatus{
+		Stores: map[string]v1alpha1.TiKVStore{
+			"0": {
+				PodName: pod.Name,
+				ID:      "0",
+			},
+		},
+	}
+	deps := controller.NewFakeDependencies()
+	fakeTiKVControl := deps.TiKVControl.(*tikvapi.FakeTiKVControl)
+	kvClient := &kvClient{}
+	fakeTiKVControl.SetTiKVPodClient(tc.Namespace, tc.Name, pod.Name, kvClient)
+	c := NewPodController(deps)
+	c.testPDClient = pdapi.NewFakePDClient()
+	c.recheckLeaderCountDuration = time.Millisecond * 100
+
+	stop := make(chan struct{})
+	go func() {
+		deps.KubeInformerFactory.Start(stop)
+	}()
+	deps.KubeInformerFactory.WaitForCacheSync(stop)
+	go func() {
+		deps.InformerFactory.Start(stop)
+	}()
+	deps.InformerFactory.WaitForCacheSync(stop)
+
+	defer close(stop)
+	go func() {
+		c.Run(1, stop)
+	}()
+
+	ctx := context.Background()
+	atomic.StoreInt32(&kvClient.leaderCount, 100)
+	tc, err := deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(ctx, tc, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(func() error {
+		_, err := deps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+		return err
+	}, timeout, interval).Should(Succeed())
+
+	_, err = deps.KubeClientset.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	getEvictStatus := func() *v1alpha1.EvictLeaderStatus {
+		tc, err := deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Get(ctx, tc.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil
+		}
+		return tc.Status.TiKV.EvictLeader[pod.Name]
+	}
+	g.Eventually(getEvictStatus, timeout, interval).ShouldNot(BeNil(), "should begin to evict leader")
+	status := getEvictStatus()
+	g.Expect(status.Source).To(Equal(v1alpha1.EvictLeaderSourceSpec))
+	g.Expect(status.Value).To(Equal(v1alpha1.EvictLeaderValueNone))
+	g.Expect(status.Completed).To(BeFalse())
+
+	atomic.StoreInt32(&kvClient.leaderCount, 0)
+	g.Eventually(func() bool {
+		status := getEvictStatus()
+		return status != nil && status.Completed
+	}, timeout, interval).Should(BeTrue(), "should complete eviction if leader count is 0")
+
+	_, err = deps.KubeClientset.CoreV1().Pods(tc.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	g.Expect(err).Should(Succeed(), "should not delete pod")
+
+	tc, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Get(ctx, tc.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	tc.Spec.TiKV.EvictLeader = nil
+	_, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(ctx, tc, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(getEvictStatus, timeout, interval).Should(BeNil(), "should end eviction after removed from spec")
+}
+
 func TestNeedEvictLeader(t *testing.T) {
 	g := NewGomegaWithT(t)
 
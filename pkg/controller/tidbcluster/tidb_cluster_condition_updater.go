@@ -31,6 +31,11 @@ type tidbClusterConditionUpdater struct {
 
 var _ TidbClusterConditionUpdater = &tidbClusterConditionUpdater{}
 
+// NewTidbClusterConditionUpdater returns the TidbClusterConditionUpdater used by the tidbcluster controller
+func NewTidbClusterConditionUpdater() TidbClusterConditionUpdater {
+	return &tidbClusterConditionUpdater{}
+}
+
 func (u *tidbClusterConditionUpdater) Update(tc *v1alpha1.TidbCluster) error {
 	u.updateReadyCondition(tc)
 	// in the future, we may return error when we need to Kubernetes API, etc.
@@ -50,6 +55,13 @@ func allStatefulSetsAreUpToDate(tc *v1alpha1.TidbCluster) bool {
 		isUpToDate(tc.Status.TiFlash.StatefulSet, false)
 }
 
+// referencedClusterReady returns false only if the referenced cluster is known to be not ready,
+// the condition is not set if the referenced cluster is in another Kubernetes cluster
+func referencedClusterReady(tc *v1alpha1.TidbCluster) bool {
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterReferencedClusterReady)
+	return cond == nil || cond.Status == v1.ConditionTrue
+}
+
 func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbCluster) {
 	status := v1.ConditionFalse
 	reason := ""
@@ -74,6 +86,9 @@ func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbClus
 	case tc.Spec.TiCDC != nil && !tc.TiCDCAllCapturesReady():
 		reason = utiltidbcluster.TiCDCCaptureNotReady
 		message = "TiCDC capture(s) are not up"
+	case tc.Heterogeneous() && !referencedClusterReady(tc):
+		reason = utiltidbcluster.ReferencedClusterNotReady
+		message = "Referenced cluster is not ready"
 	default:
 		status = v1.ConditionTrue
 		reason = utiltidbcluster.Ready
@@ -341,6 +341,32 @@ func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
 			wantReason:  utiltidbcluster.TiCDCCaptureNotReady,
 			wantMessage: "TiCDC capture(s) are not up",
 		},
+		{
+			name: "referenced cluster not ready",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					Cluster: &v1alpha1.TidbClusterRef{Name: "primary"},
+					TiDB:    &v1alpha1.TiDBSpec{},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					TiDB: v1alpha1.TiDBStatus{
+						StatefulSet: &appsv1.StatefulSetStatus{
+							CurrentRevision: "2",
+							UpdateRevision:  "2",
+						},
+					},
+					Conditions: []v1alpha1.TidbClusterCondition{
+						{
+							Type:   v1alpha1.TidbClusterReferencedClusterReady,
+							Status: v1.ConditionFalse,
+						},
+					},
+				},
+			},
+			wantStatus:  v1.ConditionFalse,
+			wantReason:  utiltidbcluster.ReferencedClusterNotReady,
+			wantMessage: "Referenced cluster is not ready",
+		},
 		{
 			name: "all ready",
 			tc: &v1alpha1.TidbCluster{
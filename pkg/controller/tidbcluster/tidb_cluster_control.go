@@ -14,6 +14,12 @@
 package tidbcluster
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/defaulting"
 	v1alpha1validation "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
@@ -21,11 +27,16 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/manager"
 	"github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/upgrader"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/util/slice"
 )
 
 // ControlInterface implements the control logic for updating TidbClusters and their children StatefulSets.
@@ -48,12 +59,35 @@ func NewDefaultTidbClusterControl(
 	orphanPodsCleaner member.OrphanPodsCleaner,
 	pvcCleaner member.PVCCleanerInterface,
 	pvcResizer member.PVCResizerInterface,
+	pvcMigrator member.PVCMigratorInterface,
+	diskWatchdog member.DiskWatchdogInterface,
+	heterogeneousManager member.HeterogeneousManager,
+	tlsSecretChecker member.TLSSecretChecker,
+	schedulingChecker member.SchedulingChecker,
+	tikvStoreRelocator member.TiKVStoreRelocator,
+	purger member.TidbClusterPurger,
 	pumpMemberManager manager.Manager,
 	tiflashMemberManager manager.Manager,
 	ticdcMemberManager manager.Manager,
+	tiproxyMemberManager manager.Manager,
 	discoveryManager member.TidbDiscoveryManager,
+	networkPolicyManager manager.Manager,
+	podMonitorManager manager.Manager,
+	resourceQuotaManager manager.Manager,
+	resourceReportManager manager.Manager,
+	recommendationManager manager.Manager,
+	autoUpgradeManager manager.Manager,
+	sqlProbeManager manager.Manager,
+	eventThrottlingManager manager.Manager,
+	pdScheduleProfileManager manager.Manager,
+	topologyExportManager manager.Manager,
+	externalDNSManager manager.Manager,
+	identityManager manager.Manager,
 	tidbClusterStatusManager manager.Manager,
 	conditionUpdater TidbClusterConditionUpdater,
+	syncDiffRecorder *controller.SyncDiffRecorder,
+	syncGate *ComponentSyncGate,
+	strictValidation bool,
 	recorder record.EventRecorder) ControlInterface {
 	return &defaultTidbClusterControl{
 		tcControl:                tcControl,
@@ -65,12 +99,35 @@ func NewDefaultTidbClusterControl(
 		orphanPodsCleaner:        orphanPodsCleaner,
 		pvcCleaner:               pvcCleaner,
 		pvcResizer:               pvcResizer,
+		pvcMigrator:              pvcMigrator,
+		diskWatchdog:             diskWatchdog,
+		heterogeneousManager:     heterogeneousManager,
+		tlsSecretChecker:         tlsSecretChecker,
+		schedulingChecker:        schedulingChecker,
+		tikvStoreRelocator:       tikvStoreRelocator,
+		purger:                   purger,
 		pumpMemberManager:        pumpMemberManager,
 		tiflashMemberManager:     tiflashMemberManager,
 		ticdcMemberManager:       ticdcMemberManager,
+		tiproxyMemberManager:     tiproxyMemberManager,
 		discoveryManager:         discoveryManager,
+		networkPolicyManager:     networkPolicyManager,
+		podMonitorManager:        podMonitorManager,
+		resourceQuotaManager:     resourceQuotaManager,
+		resourceReportManager:    resourceReportManager,
+		recommendationManager:    recommendationManager,
+		autoUpgradeManager:       autoUpgradeManager,
+		sqlProbeManager:          sqlProbeManager,
+		eventThrottlingManager:   eventThrottlingManager,
+		pdScheduleProfileManager: pdScheduleProfileManager,
+		topologyExportManager:    topologyExportManager,
+		externalDNSManager:       externalDNSManager,
+		identityManager:          identityManager,
 		tidbClusterStatusManager: tidbClusterStatusManager,
 		conditionUpdater:         conditionUpdater,
+		syncDiffRecorder:         syncDiffRecorder,
+		syncGate:                 syncGate,
+		strictValidation:         strictValidation,
 		recorder:                 recorder,
 	}
 }
@@ -85,25 +142,71 @@ type defaultTidbClusterControl struct {
 	orphanPodsCleaner        member.OrphanPodsCleaner
 	pvcCleaner               member.PVCCleanerInterface
 	pvcResizer               member.PVCResizerInterface
+	pvcMigrator              member.PVCMigratorInterface
+	diskWatchdog             member.DiskWatchdogInterface
+	heterogeneousManager     member.HeterogeneousManager
+	tlsSecretChecker         member.TLSSecretChecker
+	schedulingChecker        member.SchedulingChecker
+	tikvStoreRelocator       member.TiKVStoreRelocator
+	purger                   member.TidbClusterPurger
 	pumpMemberManager        manager.Manager
 	tiflashMemberManager     manager.Manager
 	ticdcMemberManager       manager.Manager
+	tiproxyMemberManager     manager.Manager
 	discoveryManager         member.TidbDiscoveryManager
+	networkPolicyManager     manager.Manager
+	podMonitorManager        manager.Manager
+	resourceQuotaManager     manager.Manager
+	resourceReportManager    manager.Manager
+	recommendationManager    manager.Manager
+	autoUpgradeManager       manager.Manager
+	sqlProbeManager          manager.Manager
+	eventThrottlingManager   manager.Manager
+	pdScheduleProfileManager manager.Manager
+	topologyExportManager    manager.Manager
+	externalDNSManager       manager.Manager
+	identityManager          manager.Manager
 	tidbClusterStatusManager manager.Manager
 	conditionUpdater         TidbClusterConditionUpdater
-	recorder                 record.EventRecorder
+	// syncDiffRecorder collects the objects changed by the managers, it's
+	// nil if the changes are not saved in status
+	syncDiffRecorder *controller.SyncDiffRecorder
+	// syncGate skips syncing the components which are not changed, it's nil
+	// if all components are synced every time
+	syncGate *ComponentSyncGate
+	// strictValidation validates tc as the admission webhook does and records the
+	// result in the ValidationFailed condition
+	strictValidation bool
+	recorder         record.EventRecorder
 }
 
 // UpdateStatefulSet executes the core logic loop for a tidbcluster.
 func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster) error {
 	c.defaulting(tc)
-	if !c.validate(tc) {
+	if tc.DeletionTimestamp != nil && slice.ContainsString(tc.Finalizers, label.TidbClusterPurgeFinalizer, nil) {
+		return c.purgeTidbCluster(tc)
+	}
+	if errs := c.validate(tc); len(errs) > 0 {
+		if c.strictValidation {
+			return c.updateValidationCondition(tc, errs)
+		}
 		return nil // fatal error, no need to retry on invalid object
 	}
 
+	if updated, err := c.syncPurgeFinalizer(tc); updated || err != nil {
+		// tc is synced again after the finalizer is updated
+		return err
+	}
+
 	var errs []error
 	oldStatus := tc.Status.DeepCopy()
 
+	if c.strictValidation {
+		setValidationCondition(tc, nil)
+	}
+	c.updateDeprecationCondition(tc)
+	c.updateEffectiveSpec(tc)
+
 	if err := c.updateTidbCluster(tc); err != nil {
 		errs = append(errs, err)
 	}
@@ -112,6 +215,8 @@ func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster)
 		errs = append(errs, err)
 	}
 
+	c.updateSyncDiff(tc)
+
 	if apiequality.Semantic.DeepEqual(&tc.Status, oldStatus) {
 		return errorutils.NewAggregate(errs)
 	}
@@ -122,15 +227,160 @@ func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster)
 	return errorutils.NewAggregate(errs)
 }
 
-func (c *defaultTidbClusterControl) validate(tc *v1alpha1.TidbCluster) bool {
-	errs := v1alpha1validation.ValidateTidbCluster(tc)
+// purgeTidbCluster tears down tc according to spec.deletionPolicy and removes the purge finalizer,
+// the components are not synced any more while tc is being deleted
+func (c *defaultTidbClusterControl) purgeTidbCluster(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.DeletionPolicy == v1alpha1.DeletionPolicyPurge {
+		if err := c.purger.Purge(tc); err != nil {
+			return err
+		}
+		c.recorder.Event(tc, v1.EventTypeNormal, "Purged", "TidbCluster is purged")
+	}
+	return c.patchFinalizers(tc, slice.RemoveString(tc.Finalizers, label.TidbClusterPurgeFinalizer, nil))
+}
+
+// syncPurgeFinalizer adds the purge finalizer to tc if its deletionPolicy is Purge and removes it
+// otherwise, it returns true if the finalizers are updated
+func (c *defaultTidbClusterControl) syncPurgeFinalizer(tc *v1alpha1.TidbCluster) (bool, error) {
+	if tc.DeletionTimestamp != nil {
+		return false, nil
+	}
+	purge := tc.Spec.DeletionPolicy == v1alpha1.DeletionPolicyPurge
+	hasFinalizer := slice.ContainsString(tc.Finalizers, label.TidbClusterPurgeFinalizer, nil)
+	switch {
+	case purge && !hasFinalizer:
+		return true, c.patchFinalizers(tc, append(tc.Finalizers, label.TidbClusterPurgeFinalizer))
+	case !purge && hasFinalizer:
+		return true, c.patchFinalizers(tc, slice.RemoveString(tc.Finalizers, label.TidbClusterPurgeFinalizer, nil))
+	}
+	return false, nil
+}
+
+func (c *defaultTidbClusterControl) patchFinalizers(tc *v1alpha1.TidbCluster, finalizers []string) error {
+	if finalizers == nil {
+		finalizers = []string{}
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": tc.ResourceVersion,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := c.tcControl.Patch(tc, data); err != nil {
+		return fmt.Errorf("failed to update finalizers of TidbCluster %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	tc.Finalizers = finalizers
+	return nil
+}
+
+// updateSyncDiff saves the objects changed by this sync in the status of tc,
+// so that it's easy to find out why the operator keeps updating a component.
+// The status is left as is if nothing is changed.
+func (c *defaultTidbClusterControl) updateSyncDiff(tc *v1alpha1.TidbCluster) {
+	if c.syncDiffRecorder == nil {
+		return
+	}
+	diffs := c.syncDiffRecorder.Take(tc.GetNamespace(), tc.GetName())
+	if len(diffs) == 0 {
+		return
+	}
+	for _, diff := range diffs {
+		klog.Infof("TidbCluster: [%s/%s] %s %s %s, changed fields: %v", tc.GetNamespace(), tc.GetName(), diff.Action, diff.Kind, diff.Name, diff.Fields)
+	}
+	tc.Status.SyncDiff = &v1alpha1.SyncDiffStatus{
+		LastChangeTime: metav1.Now(),
+		Objects:        diffs,
+	}
+}
+
+// updateEffectiveSpec records the defaulted spec in the status of tc.
+// The specs are compared in JSON because the configs are decoded from the
+// status in different types, e.g. float64 instead of int64.
+func (c *defaultTidbClusterControl) updateEffectiveSpec(tc *v1alpha1.TidbCluster) {
+	if tc.Status.EffectiveSpec != nil {
+		current, err := json.Marshal(tc.Status.EffectiveSpec)
+		if err == nil {
+			desired, err := json.Marshal(&tc.Spec)
+			if err == nil && bytes.Equal(current, desired) {
+				return
+			}
+		}
+	}
+	tc.Status.EffectiveSpec = tc.Spec.DeepCopy()
+}
+
+// validate returns the errors of tc, the validation of the admission webhook is run in strict mode,
+// in which the effective spec of the last sync is validated as the old object. The clusters without
+// the effective spec, e.g. the ones created by an older operator, are not validated as new clusters,
+// which may refuse the deprecated fields they still use.
+func (c *defaultTidbClusterControl) validate(tc *v1alpha1.TidbCluster) field.ErrorList {
+	var errs field.ErrorList
+	if !c.strictValidation || tc.Status.EffectiveSpec == nil {
+		errs = v1alpha1validation.ValidateTidbCluster(tc)
+	} else {
+		old := tc.DeepCopy()
+		old.Spec = *tc.Status.EffectiveSpec.DeepCopy()
+		errs = v1alpha1validation.ValidateUpdateTidbCluster(old, tc)
+	}
 	if len(errs) > 0 {
 		aggregatedErr := errs.ToAggregate()
 		klog.Errorf("tidb cluster %s/%s is not valid and must be fixed first, aggregated error: %v", tc.GetNamespace(), tc.GetName(), aggregatedErr)
 		c.recorder.Event(tc, v1.EventTypeWarning, "FailedValidation", aggregatedErr.Error())
-		return false
 	}
-	return true
+	return errs
+}
+
+// updateValidationCondition saves the validation errors in the ValidationFailed condition, as the
+// invalid changes are not refused by the admission webhook in strict mode
+func (c *defaultTidbClusterControl) updateValidationCondition(tc *v1alpha1.TidbCluster, errs field.ErrorList) error {
+	oldStatus := tc.Status.DeepCopy()
+	setValidationCondition(tc, errs)
+	if apiequality.Semantic.DeepEqual(&tc.Status, oldStatus) {
+		return nil
+	}
+	_, err := c.tcControl.UpdateTidbCluster(tc.DeepCopy(), &tc.Status, oldStatus)
+	return err
+}
+
+// setValidationCondition sets the ValidationFailed condition by the validation errors
+func setValidationCondition(tc *v1alpha1.TidbCluster, errs field.ErrorList) {
+	status, reason, msg := v1.ConditionFalse, utiltidbcluster.ValidSpec, "Spec passes the validation"
+	if len(errs) > 0 {
+		status, reason, msg = v1.ConditionTrue, utiltidbcluster.InvalidSpec, errs.ToAggregate().Error()
+	}
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterValidationFailed, status, reason, msg)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	// SetTidbClusterCondition doesn't update the message if the status and the reason are unchanged,
+	// update it in place so that it always shows the latest errors
+	for i := range tc.Status.Conditions {
+		c := &tc.Status.Conditions[i]
+		if c.Type == v1alpha1.TidbClusterValidationFailed && c.Message != msg {
+			c.Message = msg
+			c.LastUpdateTime = metav1.Now()
+		}
+	}
+}
+
+// updateDeprecationCondition sets the DeprecatedFieldsUsed condition if the spec uses the deprecated
+// fields, which are still read by the operator, and emits an event once the fields used are changed
+func (c *defaultTidbClusterControl) updateDeprecationCondition(tc *v1alpha1.TidbCluster) {
+	msgs := upgrader.DeprecatedFields(tc)
+	if len(msgs) == 0 {
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterDeprecatedFieldsUsed)
+		return
+	}
+	msg := strings.Join(msgs, "; ")
+	if cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterDeprecatedFieldsUsed); cond != nil && cond.Message == msg {
+		return
+	}
+	c.recorder.Event(tc, v1.EventTypeWarning, utiltidbcluster.DeprecatedFields, msg)
+	// the message is changed with the same status and reason, which isn't updated by SetTidbClusterCondition
+	utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterDeprecatedFieldsUsed)
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterDeprecatedFieldsUsed, v1.ConditionTrue, utiltidbcluster.DeprecatedFields, msg)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
 }
 
 func (c *defaultTidbClusterControl) defaulting(tc *v1alpha1.TidbCluster) {
@@ -156,11 +406,69 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		}
 	}
 
+	// check the referenced cluster of a heterogeneous cluster and copy its client certificate,
+	// the components are not synced if the referenced cluster is not found or its TLS setting
+	// is different
+	if err := c.heterogeneousManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// the components are not synced until the secrets of their certificates are created if the
+	// TLS between components is enabled
+	if err := c.tlsSecretChecker.Check(tc); err != nil {
+		return err
+	}
+
+	// warn if the replicas of any component exceed the nodes its Pods can be scheduled to
+	if err := c.schedulingChecker.Check(tc); err != nil {
+		return err
+	}
+
+	// replace the TiKV stores whose Pods are pending too long because the nodes of their local PVs
+	// are unavailable
+	if err := c.tikvStoreRelocator.Relocate(tc); err != nil {
+		return err
+	}
+
 	// reconcile TiDB discovery service
 	if err := c.discoveryManager.Reconcile(tc); err != nil {
 		return err
 	}
 
+	// reconcile the NetworkPolicies of the cluster
+	if err := c.networkPolicyManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// reconcile the PodMonitors of the cluster
+	if err := c.podMonitorManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// reconcile the ResourceQuota and the LimitRange of the cluster, the components are not
+	// synced if their summed requests exceed the max of the quota
+	if err := c.resourceQuotaManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// upgrade the cluster to the latest release of the channel of spec.upgradePolicy by changing
+	// spec.version in the maintenance windows, before the components are synced
+	if err := c.autoUpgradeManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// lower the IO rate limits of TiKV and the schedule limits of PD by spec.eventThrottling while
+	// TiKV is scaling or failing over or a restore is running, and restore them afterwards
+	if err := c.eventThrottlingManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// apply the schedule config items of the active profiles of spec.pd.scheduleProfiles to PD,
+	// and revert the items no active profile sets
+	if err := c.pdScheduleProfileManager.Sync(tc); err != nil {
+		return err
+	}
+
 	// works that should be done to make the pd cluster current state match the desired state:
 	//   - create or update the pd service
 	//   - create or update the pd headless service
@@ -176,6 +484,13 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		return err
 	}
 
+	// keep the identities of the cluster in a Secret by spec.persistentIdentity, and restore them
+	// to PD if the cluster is recreated with the data of TiKV retained but the data of PD lost,
+	// before any TiKV store is created to bootstrap the new cluster served by PD
+	if err := c.identityManager.Sync(tc); err != nil {
+		return err
+	}
+
 	// works that should be done to make the tiflash cluster current state match the desired state:
 	//   - waiting for the tidb cluster available
 	//   - create or update tiflash headless service
@@ -185,7 +500,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the tiflash cluster
 	//   - scale out/in the tiflash cluster
 	//   - failover the tiflash cluster
-	if err := c.tiflashMemberManager.Sync(tc); err != nil {
+	if err := c.syncGate.Sync(tc, v1alpha1.TiFlashMemberType, c.tiflashMemberManager); err != nil {
 		return err
 	}
 
@@ -203,7 +518,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	}
 
 	// syncing the pump cluster
-	if err := c.pumpMemberManager.Sync(tc); err != nil {
+	if err := c.syncGate.Sync(tc, v1alpha1.PumpMemberType, c.pumpMemberManager); err != nil {
 		return err
 	}
 
@@ -224,7 +539,16 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - waiting for the tikv cluster available(at least one peer works)
 	//   - create or update ticdc deployment
 	//   - sync ticdc cluster status from pd to TidbCluster object
-	if err := c.ticdcMemberManager.Sync(tc); err != nil {
+	if err := c.syncGate.Sync(tc, v1alpha1.TiCDCMemberType, c.ticdcMemberManager); err != nil {
+		return err
+	}
+
+	// works that should be done to make the tiproxy cluster current state match the desired state:
+	//   - create or update tiproxy services and configmap
+	//   - create the tiproxy statefulset
+	//   - upgrade the tiproxy cluster after the tidb cluster is upgraded
+	//   - scale out/in the tiproxy cluster
+	if err := c.tiproxyMemberManager.Sync(tc); err != nil {
 		return err
 	}
 
@@ -252,6 +576,41 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		return err
 	}
 
+	// migrate the PVCs of TiKV and TiFlash to the storage classes in spec if necessary
+	if err := c.pvcMigrator.Sync(tc); err != nil {
+		return err
+	}
+
+	// watch the disk usage of stores and expand the volumes if necessary
+	if err := c.diskWatchdog.Sync(tc); err != nil {
+		return err
+	}
+
+	// report the requested and used resources of the components
+	if err := c.resourceReportManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// compute the advisory recommendations of scaling TiDB and TiKV
+	if err := c.recommendationManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// run the synthetic SQL probe of spec.sqlProbe against TiDB and set the SQLProbeHealthy condition
+	if err := c.sqlProbeManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// publish the discovery document listing the endpoints of the components by spec.topologyExport
+	if err := c.topologyExportManager.Sync(tc); err != nil {
+		return err
+	}
+
+	// maintain the DNSEndpoints of external-dns for the TiDB and PD Services by spec.dns
+	if err := c.externalDNSManager.Sync(tc); err != nil {
+		return err
+	}
+
 	// syncing the some tidbcluster status attributes
 	// 	- sync tidbmonitor reference
 	return c.tidbClusterStatusManager.Sync(tc)
@@ -278,6 +637,9 @@ func (c *defaultTidbClusterControl) recordMetrics(tc *v1alpha1.TidbCluster) {
 	if tc.Spec.Pump != nil {
 		metrics.ClusterSpecReplicas.WithLabelValues(ns, tcName, "pump").Set(float64(tc.Spec.Pump.Replicas))
 	}
+	if tc.Spec.TiProxy != nil {
+		metrics.ClusterSpecReplicas.WithLabelValues(ns, tcName, "tiproxy").Set(float64(tc.Spec.TiProxy.Replicas))
+	}
 }
 
 var _ ControlInterface = &defaultTidbClusterControl{}
@@ -14,17 +14,23 @@
 package tidbcluster
 
 import (
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -32,6 +38,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 )
 
 func TestTidbClusterControlUpdateTidbCluster(t *testing.T) {
@@ -294,6 +301,204 @@ func TestTidbClusterStatusEquality(t *testing.T) {
 	g.Expect(apiequality.Semantic.DeepEqual(&tcStatus, tcStatusCopy)).To(Equal(false))
 }
 
+func TestTidbClusterControlUpdateEffectiveSpec(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	control := &defaultTidbClusterControl{}
+	tc := newTidbClusterForTidbClusterControl()
+	tc.Spec.TiDB.Config.Set("performance.max-procs", int64(8))
+
+	control.updateEffectiveSpec(tc)
+	g.Expect(tc.Status.EffectiveSpec).NotTo(BeNil())
+	g.Expect(apiequality.Semantic.DeepEqual(tc.Status.EffectiveSpec, &tc.Spec)).To(BeTrue())
+
+	// the status read back from the api server is left as is
+	data, err := json.Marshal(tc.Status.EffectiveSpec)
+	g.Expect(err).NotTo(HaveOccurred())
+	decoded := &v1alpha1.TidbClusterSpec{}
+	g.Expect(json.Unmarshal(data, decoded)).To(Succeed())
+	tc.Status.EffectiveSpec = decoded
+	control.updateEffectiveSpec(tc)
+	g.Expect(tc.Status.EffectiveSpec).To(BeIdenticalTo(decoded))
+
+	tc.Spec.TiDB.Replicas = 3
+	control.updateEffectiveSpec(tc)
+	g.Expect(tc.Status.EffectiveSpec).NotTo(BeIdenticalTo(decoded))
+	g.Expect(tc.Status.EffectiveSpec.TiDB.Replicas).To(Equal(int32(3)))
+}
+
+func TestTidbClusterControlPurge(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cli := fake.NewSimpleClientset()
+	tcInformer := informers.NewSharedInformerFactory(cli, 0).Pingcap().V1alpha1().TidbClusters()
+	purger := mm.NewFakeTidbClusterPurger()
+	control := &defaultTidbClusterControl{
+		tcControl: controller.NewFakeTidbClusterControl(tcInformer),
+		purger:    purger,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	tc := newTidbClusterForTidbClusterControl()
+	updated, err := control.syncPurgeFinalizer(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).To(BeFalse())
+
+	tc.Spec.DeletionPolicy = v1alpha1.DeletionPolicyPurge
+	updated, err = control.syncPurgeFinalizer(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).To(BeTrue())
+	g.Expect(tc.Finalizers).To(ConsistOf(label.TidbClusterPurgeFinalizer))
+
+	tc.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	purger.SetPurgeError(controller.RequeueErrorf("waiting for stores to be tombstone"))
+	g.Expect(control.UpdateTidbCluster(tc)).NotTo(Succeed())
+	g.Expect(tc.Finalizers).To(ConsistOf(label.TidbClusterPurgeFinalizer))
+
+	purger.SetPurgeError(nil)
+	g.Expect(control.UpdateTidbCluster(tc)).To(Succeed())
+	g.Expect(purger.Purged()).To(BeTrue())
+	g.Expect(tc.Finalizers).To(BeEmpty())
+}
+
+func TestTidbClusterControlDeprecationCondition(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	control := &defaultTidbClusterControl{recorder: recorder}
+
+	tc := newTidbClusterForTidbClusterControl()
+	control.updateDeprecationCondition(tc)
+	g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterDeprecatedFieldsUsed)).To(BeNil())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// the deprecated fields are reported but not changed
+	tc.Spec.PD.EnableDashboardInternalProxy = pointer.BoolPtr(true)
+	control.updateDeprecationCondition(tc)
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterDeprecatedFieldsUsed)
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(cond.Message).To(ContainSubstring("spec.pd.enableDashboardInternalProxy"))
+	g.Expect(*tc.Spec.PD.EnableDashboardInternalProxy).To(BeTrue())
+	g.Expect(<-recorder.Events).To(ContainSubstring(utiltidbcluster.DeprecatedFields))
+
+	// the event is emitted only if the fields used are changed
+	control.updateDeprecationCondition(tc)
+	g.Expect(recorder.Events).To(BeEmpty())
+	tc.Spec.TiKV.Image = "pingcap/tikv:v5.4.0"
+	control.updateDeprecationCondition(tc)
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterDeprecatedFieldsUsed)
+	g.Expect(cond.Message).To(ContainSubstring("spec.<component>.image"))
+	g.Expect(recorder.Events).To(HaveLen(1))
+
+	tc.Spec.PD.EnableDashboardInternalProxy = nil
+	tc.Spec.TiKV.Image = ""
+	control.updateDeprecationCondition(tc)
+	g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterDeprecatedFieldsUsed)).To(BeNil())
+}
+
+func TestTidbClusterControlStrictValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cli := fake.NewSimpleClientset()
+	tcInformer := informers.NewSharedInformerFactory(cli, 0).Pingcap().V1alpha1().TidbClusters()
+	control := &defaultTidbClusterControl{
+		tcControl:        controller.NewFakeTidbClusterControl(tcInformer),
+		strictValidation: true,
+		recorder:         record.NewFakeRecorder(10),
+	}
+
+	tc := newTidbClusterForTidbClusterControl()
+	tc.Status.EffectiveSpec = tc.Spec.DeepCopy()
+	g.Expect(tcInformer.Informer().GetIndexer().Add(tc)).To(Succeed())
+
+	// the version can't be removed once it's set, which is refused by the webhook
+	tc.Spec.Version = ""
+	errs := control.validate(tc)
+	g.Expect(errs).NotTo(BeEmpty())
+	g.Expect(control.updateValidationCondition(tc, errs)).To(Succeed())
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterValidationFailed)
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.InvalidSpec))
+	g.Expect(cond.Message).To(ContainSubstring("version must not be empty"))
+	g.Expect(tc.Status.EffectiveSpec.Version).To(Equal("v3.0.8"))
+
+	tc.Spec.Version = "v3.0.8"
+	g.Expect(control.validate(tc)).To(BeEmpty())
+	setValidationCondition(tc, nil)
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterValidationFailed)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.ValidSpec))
+
+	// the cluster without the effective spec is not validated as a new cluster
+	tc.Status.EffectiveSpec = nil
+	tc.Spec.Version = ""
+	g.Expect(control.validate(tc)).To(BeEmpty())
+}
+
+func TestTidbClusterControlRestoreIdentity(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	uint64Bytes := func(v uint64) []byte {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+		return b
+	}
+	now := time.Now()
+	tc := newTidbClusterForTidbClusterControl()
+	tc.CreationTimestamp = metav1.Time{Time: now}
+	tc.Spec.PersistentIdentity = &v1alpha1.PersistentIdentitySpec{}
+	tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 3}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{}
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("test-pd-pd-%d", i)
+		tc.Status.PD.Members[name] = v1alpha1.PDMember{Name: name, ID: fmt.Sprint(i + 1), Health: true}
+	}
+
+	// the cluster 100 is recorded before the TidbCluster is recreated with the PVCs of TiKV retained,
+	// and PD starts as the new cluster 200 not bootstrapped yet
+	deps := controller.NewFakeDependencies()
+	g.Expect(deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer().Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: tc.PersistentIdentitySecretName()},
+		Data: map[string][]byte{
+			"cluster-id": []byte("100"),
+			"alloc-id":   []byte("1000"),
+			"stores":     []byte(`{"test-pd-tikv-0":"5"}`),
+		},
+	})).To(Succeed())
+	g.Expect(deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         tc.Namespace,
+			Name:              "tikv-test-pd-tikv-0",
+			Labels:            label.New().Instance(tc.GetInstanceName()).TiKV().Labels(),
+			CreationTimestamp: metav1.Time{Time: now.Add(-time.Hour)},
+		},
+	})).To(Succeed())
+	etcdClient := pdapi.NewFakePDEtcdClient()
+	etcdClient.KVs["/pd/cluster_id"] = uint64Bytes(200)
+	deps.PDControl.(*pdapi.FakePDControl).SetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, etcdClient)
+
+	control, _, _, _, tikvMemberManager, _, _, _, _ := newFakeTidbClusterControl()
+	control.(*defaultTidbClusterControl).identityManager = mm.NewIdentityManager(deps)
+	tikvMemberManager.SetSyncError(fmt.Errorf("tikv is synced"))
+
+	// the recorded cluster is restored to PD before TiKV is synced to bootstrap the new cluster
+	err := control.UpdateTidbCluster(tc)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("waiting for PD to restart with the restored cluster 100"))
+	g.Expect(err.Error()).NotTo(ContainSubstring("tikv is synced"))
+	g.Expect(etcdClient.KVs["/pd/cluster_id"]).To(Equal(uint64Bytes(100)))
+	g.Expect(etcdClient.KVs).To(HaveKey("/pd/100/raft"))
+	g.Expect(etcdClient.KVs).NotTo(HaveKey("/pd/200/raft"))
+	g.Expect(tc.Status.PersistentIdentity.RecoveryTime).NotTo(BeNil())
+
+	// TiKV is synced once PD serves the restored cluster
+	err = control.UpdateTidbCluster(tc)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("tikv is synced"))
+}
+
 func newFakeTidbClusterControl() (
 	ControlInterface,
 	*meta.FakeReclaimPolicyManager,
@@ -319,9 +524,29 @@ func newFakeTidbClusterControl() (
 	pumpMemberManager := mm.NewFakePumpMemberManager()
 	tiflashMemberManager := mm.NewFakeTiFlashMemberManager()
 	ticdcMemberManager := mm.NewFakeTiCDCMemberManager()
+	tiproxyMemberManager := mm.NewFakeTiProxyMemberManager()
 	discoveryManager := mm.NewFakeDiscoveryManger()
+	networkPolicyManager := mm.NewFakeNetworkPolicyManager()
+	podMonitorManager := mm.NewFakePodMonitorManager()
+	resourceQuotaManager := mm.NewFakeResourceQuotaManager()
+	resourceReportManager := mm.NewFakeResourceReportManager()
+	recommendationManager := mm.NewFakeRecommendationManager()
+	autoUpgradeManager := mm.NewFakeAutoUpgradeManager()
+	sqlProbeManager := mm.NewFakeSQLProbeManager()
+	eventThrottlingManager := mm.NewFakeEventThrottlingManager()
+	pdScheduleProfileManager := mm.NewFakePDScheduleProfileManager()
+	topologyExportManager := mm.NewFakeTopologyExportManager()
+	externalDNSManager := mm.NewFakeExternalDNSManager()
+	identityManager := mm.NewFakeIdentityManager()
 	statusManager := mm.NewFakeTidbClusterStatusManager()
 	pvcResizer := mm.NewFakePVCResizer()
+	pvcMigrator := mm.NewFakePVCMigrator()
+	diskWatchdog := mm.NewFakeDiskWatchdog()
+	heterogeneousManager := mm.NewFakeHeterogeneousManager()
+	tlsSecretChecker := mm.NewFakeTLSSecretChecker()
+	schedulingChecker := mm.NewFakeSchedulingChecker()
+	tikvStoreRelocator := mm.NewFakeTiKVStoreRelocator()
+	purger := mm.NewFakeTidbClusterPurger()
 	control := NewDefaultTidbClusterControl(
 		tcUpdater,
 		pdMemberManager,
@@ -332,12 +557,35 @@ func newFakeTidbClusterControl() (
 		orphanPodCleaner,
 		pvcCleaner,
 		pvcResizer,
+		pvcMigrator,
+		diskWatchdog,
+		heterogeneousManager,
+		tlsSecretChecker,
+		schedulingChecker,
+		tikvStoreRelocator,
+		purger,
 		pumpMemberManager,
 		tiflashMemberManager,
 		ticdcMemberManager,
+		tiproxyMemberManager,
 		discoveryManager,
+		networkPolicyManager,
+		podMonitorManager,
+		resourceQuotaManager,
+		resourceReportManager,
+		recommendationManager,
+		autoUpgradeManager,
+		sqlProbeManager,
+		eventThrottlingManager,
+		pdScheduleProfileManager,
+		topologyExportManager,
+		externalDNSManager,
+		identityManager,
 		statusManager,
 		&tidbClusterConditionUpdater{},
+		nil,
+		nil,
+		false,
 		recorder,
 	)
 
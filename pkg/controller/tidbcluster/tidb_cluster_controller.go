@@ -18,12 +18,17 @@ import (
 	"time"
 
 	perrors "github.com/pingcap/errors"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
 	"github.com/pingcap/tidb-operator/pkg/manager/suspender"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -39,34 +44,24 @@ type Controller struct {
 	// control returns an interface capable of syncing a tidb cluster.
 	// Abstracted out for testing.
 	control ControlInterface
+	// dryRunControl syncs a tidb cluster in dry-run mode, the changes are
+	// recorded to dryRunRecorder instead of being applied.
+	dryRunControl  ControlInterface
+	dryRunRecorder *controller.DryRunRecorder
 	// tidbclusters that need to be synced.
 	queue workqueue.RateLimitingInterface
 }
 
 // NewController creates a tidbcluster controller.
 func NewController(deps *controller.Dependencies) *Controller {
-	suspender := suspender.NewSuspender(deps)
+	syncDiffRecorder := controller.NewSyncDiffRecorder()
+	dryRunRecorder := controller.NewDryRunRecorder()
 
 	c := &Controller{
-		deps: deps,
-		control: NewDefaultTidbClusterControl(
-			deps.TiDBClusterControl,
-			mm.NewPDMemberManager(deps, mm.NewPDScaler(deps), mm.NewPDUpgrader(deps), mm.NewPDFailover(deps), suspender),
-			mm.NewTiKVMemberManager(deps, mm.NewTiKVFailover(deps), mm.NewTiKVScaler(deps), mm.NewTiKVUpgrader(deps), suspender),
-			mm.NewTiDBMemberManager(deps, mm.NewTiDBScaler(deps), mm.NewTiDBUpgrader(deps), mm.NewTiDBFailover(deps), suspender),
-			meta.NewReclaimPolicyManager(deps),
-			meta.NewMetaManager(deps),
-			mm.NewOrphanPodsCleaner(deps),
-			mm.NewRealPVCCleaner(deps),
-			mm.NewPVCResizer(deps),
-			mm.NewPumpMemberManager(deps, mm.NewPumpScaler(deps), suspender),
-			mm.NewTiFlashMemberManager(deps, mm.NewTiFlashFailover(deps), mm.NewTiFlashScaler(deps), mm.NewTiFlashUpgrader(deps), suspender),
-			mm.NewTiCDCMemberManager(deps, mm.NewTiCDCScaler(deps), mm.NewTiCDCUpgrader(deps), suspender),
-			mm.NewTidbDiscoveryManager(deps),
-			mm.NewTidbClusterStatusManager(deps),
-			&tidbClusterConditionUpdater{},
-			deps.Recorder,
-		),
+		deps:           deps,
+		control:        newTidbClusterControl(controller.NewSyncDiffDependencies(deps, syncDiffRecorder), syncDiffRecorder),
+		dryRunControl:  newTidbClusterControl(controller.NewDryRunDependencies(deps, dryRunRecorder), nil),
+		dryRunRecorder: dryRunRecorder,
 		queue: workqueue.NewNamedRateLimitingQueue(
 			controller.NewControllerRateLimiter(1*time.Second, 100*time.Second),
 			"tidbcluster",
@@ -78,9 +73,22 @@ func NewController(deps *controller.Dependencies) *Controller {
 	tidbClusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.enqueueTidbCluster,
 		UpdateFunc: func(old, cur interface{}) {
+			oldTC := old.(*v1alpha1.TidbCluster)
+			curTC := cur.(*v1alpha1.TidbCluster)
+			// the clusters are requeued after each sync by their resync periods instead of on
+			// the periodic resync of the informer, see enqueueAfterResyncPeriod
+			if curTC.ResourceVersion == oldTC.ResourceVersion {
+				return
+			}
 			c.enqueueTidbCluster(cur)
 		},
-		DeleteFunc: c.enqueueTidbCluster,
+		DeleteFunc: func(obj interface{}) {
+			// the limiter of the requests to the deleted cluster is released
+			if key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
+				httputil.ForgetCluster(key)
+			}
+			c.enqueueTidbCluster(obj)
+		},
 	})
 	statefulsetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.addStatefulSet,
@@ -93,6 +101,56 @@ func NewController(deps *controller.Dependencies) *Controller {
 	return c
 }
 
+func newTidbClusterControl(deps *controller.Dependencies, syncDiffRecorder *controller.SyncDiffRecorder) ControlInterface {
+	suspender := suspender.NewSuspender(deps)
+	var syncGate *ComponentSyncGate
+	if deps.CLIConfig.SelectiveSyncPeriod > 0 {
+		syncGate = NewComponentSyncGate(deps.StatefulSetLister, deps.CLIConfig.SelectiveSyncPeriod)
+	}
+
+	return NewDefaultTidbClusterControl(
+		deps.TiDBClusterControl,
+		mm.NewPDMemberManager(deps, mm.NewPDScaler(deps), mm.NewPDUpgrader(deps), mm.NewPDFailover(deps), suspender),
+		mm.NewTiKVMemberManager(deps, mm.NewTiKVFailover(deps), mm.NewTiKVScaler(deps), mm.NewTiKVUpgrader(deps), suspender),
+		mm.NewTiDBMemberManager(deps, mm.NewTiDBScaler(deps), mm.NewTiDBUpgrader(deps), mm.NewTiDBFailover(deps), suspender),
+		meta.NewReclaimPolicyManager(deps),
+		meta.NewMetaManager(deps),
+		mm.NewOrphanPodsCleaner(deps),
+		mm.NewRealPVCCleaner(deps),
+		mm.NewPVCResizer(deps),
+		mm.NewPVCMigrator(deps),
+		mm.NewDiskWatchdog(deps),
+		mm.NewHeterogeneousManager(deps),
+		mm.NewTLSSecretChecker(deps),
+		mm.NewSchedulingChecker(deps),
+		mm.NewTiKVStoreRelocator(deps),
+		mm.NewTidbClusterPurger(deps),
+		mm.NewPumpMemberManager(deps, mm.NewPumpScaler(deps), suspender),
+		mm.NewTiFlashMemberManager(deps, mm.NewTiFlashFailover(deps), mm.NewTiFlashScaler(deps), mm.NewTiFlashUpgrader(deps), suspender),
+		mm.NewTiCDCMemberManager(deps, mm.NewTiCDCScaler(deps), mm.NewTiCDCUpgrader(deps), suspender),
+		mm.NewTiProxyMemberManager(deps, suspender),
+		mm.NewTidbDiscoveryManager(deps),
+		mm.NewNetworkPolicyManager(deps),
+		mm.NewPodMonitorManager(deps),
+		mm.NewResourceQuotaManager(deps),
+		mm.NewResourceReportManager(deps),
+		mm.NewRecommendationManager(deps),
+		mm.NewAutoUpgradeManager(deps),
+		mm.NewSQLProbeManager(deps),
+		mm.NewEventThrottlingManager(deps),
+		mm.NewPDScheduleProfileManager(deps),
+		mm.NewTopologyExportManager(deps),
+		mm.NewExternalDNSManager(deps),
+		mm.NewIdentityManager(deps),
+		mm.NewTidbClusterStatusManager(deps),
+		NewTidbClusterConditionUpdater(),
+		syncDiffRecorder,
+		syncGate,
+		deps.CLIConfig.StrictValidation,
+		deps.Recorder,
+	)
+}
+
 // Run runs the tidbcluster controller.
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
@@ -101,17 +159,13 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	klog.Info("Starting tidbcluster controller")
 	defer klog.Info("Shutting down tidbcluster controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
+	// the number of the workers follows the workers flag reloaded from the config file
+	wg := controller.RunWorkers(workers, c.deps.CLIConfig, c.processNextWorkItem, stopCh)
 
 	<-stopCh
-}
-
-// worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
+	// wait for the in-flight syncs to finish, so that no operation is left half-done
+	c.queue.ShutDown()
+	wg.Wait()
 }
 
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
@@ -131,10 +185,31 @@ func (c *Controller) processNextWorkItem() bool {
 		c.queue.AddRateLimited(key)
 	} else {
 		c.queue.Forget(key)
+		c.enqueueAfterResyncPeriod(key.(string))
 	}
 	return true
 }
 
+// enqueueAfterResyncPeriod requeues the tidbcluster after its resync period, or the resync duration of
+// the operator which may be changed by reloading the config file
+func (c *Controller) enqueueAfterResyncPeriod(key string) {
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	tc, err := c.deps.TiDBClusterLister.TidbClusters(ns).Get(name)
+	if err != nil {
+		return
+	}
+	period := tc.ResyncPeriod()
+	if period <= 0 {
+		period = c.deps.CLIConfig.GetResyncDuration()
+	}
+	if period > 0 {
+		c.queue.AddAfter(key, period)
+	}
+}
+
 // sync syncs the given tidbcluster.
 func (c *Controller) sync(key string) error {
 	startTime := time.Now()
@@ -146,9 +221,14 @@ func (c *Controller) sync(key string) error {
 	if err != nil {
 		return err
 	}
+	if !c.deps.NamespaceSelector.Selected(ns) {
+		klog.V(4).Infof("TidbCluster %q is not in the selected namespaces, skip syncing", key)
+		return nil
+	}
 	tc, err := c.deps.TiDBClusterLister.TidbClusters(ns).Get(name)
 	if errors.IsNotFound(err) {
 		klog.Infof("TidbCluster has been deleted %v", key)
+		metrics.DeleteClusterMetrics(ns, name)
 		return nil
 	}
 	if err != nil {
@@ -159,9 +239,60 @@ func (c *Controller) sync(key string) error {
 }
 
 func (c *Controller) syncTidbCluster(tc *v1alpha1.TidbCluster) error {
+	if c.deps.CLIConfig.DryRun || tc.IsDryRun() {
+		return c.dryRunTidbCluster(tc)
+	}
+
 	return c.control.UpdateTidbCluster(tc)
 }
 
+// dryRunTidbCluster reconciles tc with the dry-run control, the changes which
+// would have been applied are saved in the status of tc, emitted as events and,
+// if requested, saved in a ConfigMap.
+func (c *Controller) dryRunTidbCluster(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	oldStatus := tc.Status.DeepCopy()
+
+	syncErr := c.dryRunControl.UpdateTidbCluster(tc.DeepCopy())
+	changes, diff := c.dryRunRecorder.Take(ns, tcName)
+
+	if oldStatus.DryRun == nil || !apiequality.Semantic.DeepEqual(oldStatus.DryRun.Changes, changes) {
+		for _, change := range changes {
+			c.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "DryRun", "%s %s %s", change.Action, change.Kind, change.Name)
+		}
+	}
+	tc.Status.DryRun = &v1alpha1.DryRunStatus{
+		LastReconcileTime: metav1.Now(),
+		Changes:           changes,
+	}
+	if _, err := c.deps.TiDBClusterControl.UpdateTidbCluster(tc, &tc.Status, oldStatus); err != nil {
+		return err
+	}
+
+	if tc.Annotations[label.AnnDryRunDiffConfigMap] == "true" {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            controller.DryRunDiffConfigMapName(tcName),
+				Namespace:       ns,
+				Labels:          label.New().Instance(tc.GetInstanceName()),
+				OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+			},
+			Data: map[string]string{
+				"diff": diff,
+			},
+		}
+		if _, err := c.deps.TypedControl.CreateOrUpdateConfigMap(tc, cm); err != nil {
+			return err
+		}
+	}
+
+	if syncErr != nil && perrors.Find(syncErr, controller.IsRequeueError) == nil {
+		return syncErr
+	}
+	return nil
+}
+
 // enqueueTidbCluster enqueues the given tidbcluster in the work queue.
 func (c *Controller) enqueueTidbCluster(obj interface{}) {
 	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
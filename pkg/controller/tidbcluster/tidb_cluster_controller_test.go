@@ -20,14 +20,18 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestTidbClusterControllerEnqueueTidbCluster(t *testing.T) {
@@ -264,6 +268,90 @@ func TestTidbClusterControllerSync(t *testing.T) {
 
 }
 
+type dryRunTidbClusterControl struct {
+	deps *controller.Dependencies
+}
+
+func (c *dryRunTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster) error {
+	return c.deps.StatefulSetControl.CreateStatefulSet(tc, newStatefulSet(tc))
+}
+
+func TestTidbClusterControllerDryRun(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	tc.Annotations[label.AnnDryRun] = "true"
+	tc.Annotations[label.AnnDryRunDiffConfigMap] = "true"
+	fakeDeps := controller.NewFakeDependencies()
+	tcc := NewController(fakeDeps)
+	tcc.control = NewFakeTidbClusterControlInterface()
+	tcc.control.(*FakeTidbClusterControlInterface).SetUpdateTCError(fmt.Errorf("should not be called"))
+	tcc.dryRunControl = &dryRunTidbClusterControl{deps: controller.NewDryRunDependencies(fakeDeps, tcc.dryRunRecorder)}
+	tcIndexer := fakeDeps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+	g.Expect(tcIndexer.Add(tc)).To(Succeed())
+
+	g.Expect(tcc.sync(fmt.Sprintf("%s/%s", tc.Namespace, tc.Name))).To(Succeed())
+	_, err := fakeDeps.StatefulSetLister.StatefulSets(tc.Namespace).Get("test-statefulset")
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	updated, err := fakeDeps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Status.DryRun).NotTo(BeNil())
+	g.Expect(updated.Status.DryRun.Changes).To(Equal([]v1alpha1.DryRunChange{
+		{Kind: "StatefulSet", Name: "test-statefulset", Action: controller.ChangeActionCreate},
+	}))
+	recorder := fakeDeps.Recorder.(*record.FakeRecorder)
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring("DryRun"))
+
+	cm := &corev1.ConfigMap{}
+	exist, err := fakeDeps.TypedControl.Exist(client.ObjectKey{Namespace: tc.Namespace, Name: controller.DryRunDiffConfigMapName(tc.Name)}, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeTrue())
+
+	// events are not emitted again for the same changes
+	g.Expect(tcc.sync(fmt.Sprintf("%s/%s", tc.Namespace, tc.Name))).To(Succeed())
+	g.Expect(recorder.Events).To(HaveLen(0))
+}
+
+type blockingTidbClusterControl struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingTidbClusterControl) UpdateTidbCluster(_ *v1alpha1.TidbCluster) error {
+	close(c.started)
+	<-c.release
+	return nil
+}
+
+func TestTidbClusterControllerRunWaitsForInflightSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	fakeDeps := controller.NewFakeDependencies()
+	tcc := NewController(fakeDeps)
+	control := &blockingTidbClusterControl{started: make(chan struct{}), release: make(chan struct{})}
+	tcc.control = control
+	tcIndexer := fakeDeps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+	g.Expect(tcIndexer.Add(tc)).To(Succeed())
+	tcc.enqueueTidbCluster(tc)
+
+	stopCh := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		tcc.Run(1, stopCh)
+		close(stopped)
+	}()
+	<-control.started
+	close(stopCh)
+
+	// the controller is not stopped until the in-flight sync is finished
+	g.Consistently(stopped, 100*time.Millisecond).ShouldNot(BeClosed())
+	close(control.release)
+	g.Eventually(stopped, time.Second).Should(BeClosed())
+}
+
 func newTidbCluster() *v1alpha1.TidbCluster {
 	return &v1alpha1.TidbCluster{
 		TypeMeta: metav1.TypeMeta{
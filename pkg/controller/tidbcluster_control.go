@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	tcinformers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -55,6 +56,10 @@ func NewRealTidbClusterControl(cli versioned.Interface,
 }
 
 func (c *realTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster, newStatus *v1alpha1.TidbClusterStatus, oldStatus *v1alpha1.TidbClusterStatus) (*v1alpha1.TidbCluster, error) {
+	if c.onlyStatusChanged(tc) {
+		return c.patchTidbClusterStatus(tc, newStatus, oldStatus)
+	}
+
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
 
@@ -103,6 +108,42 @@ func (c *realTidbClusterControl) Patch(tc *v1alpha1.TidbCluster, data []byte, su
 	return tc, err
 }
 
+// onlyStatusChanged returns whether only the status of tc is changed compared with the cached one
+func (c *realTidbClusterControl) onlyStatusChanged(tc *v1alpha1.TidbCluster) bool {
+	if c.tcLister == nil {
+		return false
+	}
+	cached, err := c.tcLister.TidbClusters(tc.GetNamespace()).Get(tc.GetName())
+	if err != nil {
+		return false
+	}
+	return cached.ResourceVersion == tc.ResourceVersion &&
+		apiequality.Semantic.DeepEqual(cached.Spec, tc.Spec) &&
+		apiequality.Semantic.DeepEqual(cached.Labels, tc.Labels) &&
+		apiequality.Semantic.DeepEqual(cached.Annotations, tc.Annotations)
+}
+
+// patchTidbClusterStatus patches the changes from oldStatus to newStatus instead of updating the whole
+// TidbCluster, so that the fields changed by others are not overwritten. The patch has no resourceVersion
+// precondition, because it only contains the fields changed by this sync, which are expected to be
+// set to newStatus whatever the latest TidbCluster is.
+func (c *realTidbClusterControl) patchTidbClusterStatus(tc *v1alpha1.TidbCluster, newStatus, oldStatus *v1alpha1.TidbClusterStatus) (*v1alpha1.TidbCluster, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	data, err := StatusMergePatch(oldStatus, newStatus)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := c.cli.PingcapV1alpha1().TidbClusters(ns).Patch(context.TODO(), tcName, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		klog.Errorf("failed to patch TidbCluster: [%s/%s] status, error: %v", ns, tcName, err)
+		return nil, err
+	}
+	klog.V(4).Infof("TidbCluster: [%s/%s] status patched successfully", ns, tcName)
+	return patched, nil
+}
+
 // FakeTidbClusterControl is a fake TidbClusterControlInterface
 type FakeTidbClusterControl struct {
 	TcLister                 listers.TidbClusterLister
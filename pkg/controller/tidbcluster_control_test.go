@@ -14,6 +14,7 @@
 package controller
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -64,3 +65,38 @@ func TestTidbClusterControlUpdateTidbClusterConflictSuccess(t *testing.T) {
 	_, err := control.UpdateTidbCluster(tc, &v1alpha1.TidbClusterStatus{}, &v1alpha1.TidbClusterStatus{})
 	g.Expect(err).To(Succeed())
 }
+
+func TestTidbClusterControlPatchTidbClusterStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+	recorder := record.NewFakeRecorder(10)
+	tc := newTidbCluster()
+	tc.ResourceVersion = "1"
+	fakeClient := &fake.Clientset{}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(tc.DeepCopy())
+	tcLister := listers.NewTidbClusterLister(indexer)
+	control := NewRealTidbClusterControl(fakeClient, tcLister, recorder)
+
+	oldStatus := tc.Status.DeepCopy()
+	tc.Status.PD.Phase = v1alpha1.UpgradePhase
+
+	var patches []map[string]interface{}
+	fakeClient.AddReactor("update", "tidbclusters", func(action core.Action) (bool, runtime.Object, error) {
+		t.Fatalf("TidbCluster is updated instead of patched")
+		return true, nil, nil
+	})
+	fakeClient.AddReactor("patch", "tidbclusters", func(action core.Action) (bool, runtime.Object, error) {
+		patch := map[string]interface{}{}
+		g.Expect(json.Unmarshal(action.(core.PatchAction).GetPatch(), &patch)).To(Succeed())
+		patches = append(patches, patch)
+		return true, tc, nil
+	})
+	_, err := control.UpdateTidbCluster(tc, &tc.Status, oldStatus)
+	g.Expect(err).To(Succeed())
+	// only the changed fields are patched without the resourceVersion precondition
+	g.Expect(patches).To(Equal([]map[string]interface{}{{
+		"status": map[string]interface{}{
+			"pd": map[string]interface{}{"phase": string(v1alpha1.UpgradePhase)},
+		},
+	}}))
+}
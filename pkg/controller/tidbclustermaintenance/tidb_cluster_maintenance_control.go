@@ -0,0 +1,181 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclustermaintenance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	v1alpha1validation "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// ControlInterface provide function about control TidbClusterMaintenance
+type ControlInterface interface {
+	// Reconcile a TidbClusterMaintenance
+	Reconcile(*v1alpha1.TidbClusterMaintenance) error
+
+	// Update the status of a TidbClusterMaintenance
+	Update(*v1alpha1.TidbClusterMaintenance) (*v1alpha1.TidbClusterMaintenance, error)
+}
+
+func NewDefaultTiDBClusterMaintenanceControl(
+	deps *controller.Dependencies,
+	maintenanceMnger manager.TiDBClusterMaintenanceManager,
+	recorder record.EventRecorder,
+) *defaultTiDBClusterMaintenanceControl {
+	return &defaultTiDBClusterMaintenanceControl{
+		deps:             deps,
+		recorder:         recorder,
+		maintenanceMnger: maintenanceMnger,
+	}
+}
+
+type defaultTiDBClusterMaintenanceControl struct {
+	deps     *controller.Dependencies
+	recorder record.EventRecorder
+
+	maintenanceMnger manager.TiDBClusterMaintenanceManager
+}
+
+func (c *defaultTiDBClusterMaintenanceControl) Reconcile(tcm *v1alpha1.TidbClusterMaintenance) error {
+	if !c.validate(tcm) {
+		return nil // fatal error, no need to retry on invalid object
+	}
+
+	var errs []error
+
+	oldStatus := tcm.Status.DeepCopy()
+
+	err := c.reconcile(tcm)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if oldStatus.Phase != tcm.Status.Phase {
+		switch tcm.Status.Phase {
+		case v1alpha1.MaintenancePhaseRunning:
+			c.recorder.Eventf(tcm, v1.EventTypeNormal, "MaintenanceStarted", "%s task started", tcm.Spec.Type)
+		case v1alpha1.MaintenancePhaseComplete:
+			c.recorder.Eventf(tcm, v1.EventTypeNormal, "MaintenanceCompleted", "%s task completed", tcm.Spec.Type)
+		case v1alpha1.MaintenancePhaseFailed:
+			c.recorder.Eventf(tcm, v1.EventTypeWarning, "MaintenanceFailed", "%s task failed: %s", tcm.Spec.Type, tcm.Status.Message)
+		}
+	}
+
+	if apiequality.Semantic.DeepEqual(&tcm.Status, oldStatus) {
+		return errorutils.NewAggregate(errs)
+	}
+
+	_, err = c.Update(tcm.DeepCopy())
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errorutils.NewAggregate(errs)
+}
+
+func (c *defaultTiDBClusterMaintenanceControl) reconcile(tcm *v1alpha1.TidbClusterMaintenance) error {
+	if tcm.DeletionTimestamp != nil {
+		return nil
+	}
+
+	ns := tcm.Spec.Cluster.Namespace
+	if ns == "" {
+		ns = tcm.Namespace
+	}
+	tc, err := c.deps.TiDBClusterLister.TidbClusters(ns).Get(tcm.Spec.Cluster.Name)
+	if err != nil {
+		return fmt.Errorf("get tc %s/%s failed: %s", ns, tcm.Spec.Cluster.Name, err)
+	}
+
+	return c.maintenanceMnger.Sync(tcm, tc)
+}
+
+func (c *defaultTiDBClusterMaintenanceControl) Update(tcm *v1alpha1.TidbClusterMaintenance) (*v1alpha1.TidbClusterMaintenance, error) {
+	var (
+		ns     string                                 = tcm.GetNamespace()
+		name   string                                 = tcm.GetName()
+		status *v1alpha1.TidbClusterMaintenanceStatus = tcm.Status.DeepCopy()
+		update *v1alpha1.TidbClusterMaintenance
+	)
+
+	// don't wait due to limited number of clients, but backoff after the default number of steps
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var updateErr error
+
+		update, updateErr = c.deps.Clientset.PingcapV1alpha1().TidbClusterMaintenances(ns).UpdateStatus(context.TODO(), tcm, metav1.UpdateOptions{})
+		if updateErr == nil {
+			klog.Infof("TidbClusterMaintenance: [%s/%s] updated successfully", ns, name)
+			return nil
+		}
+
+		klog.V(4).Infof("failed to update TidbClusterMaintenance: [%s/%s], error: %v", ns, name, updateErr)
+
+		if updated, err := c.deps.TiDBClusterMaintenanceLister.TidbClusterMaintenances(ns).Get(name); err == nil {
+			// make a copy so we don't mutate the shared cache
+			tcm = updated.DeepCopy()
+			tcm.Status = *status
+		} else {
+			utilruntime.HandleError(fmt.Errorf("error getting updated TidbClusterMaintenance %s/%s from lister: %v", ns, name, err))
+		}
+
+		return updateErr
+	})
+	if err != nil {
+		klog.Errorf("failed to update TidbClusterMaintenance: [%s/%s], error: %v", ns, name, err)
+	}
+	return update, err
+}
+
+func (c *defaultTiDBClusterMaintenanceControl) validate(tcm *v1alpha1.TidbClusterMaintenance) bool {
+	errs := v1alpha1validation.ValidateTidbClusterMaintenance(tcm)
+	if len(errs) > 0 {
+		aggregatedErr := errs.ToAggregate()
+		klog.Errorf("tidb cluster maintenance %s/%s is not valid and must be fixed first, aggregated error: %v", tcm.GetNamespace(), tcm.GetName(), aggregatedErr)
+		c.recorder.Event(tcm, v1.EventTypeWarning, "FailedValidation", aggregatedErr.Error())
+		return false
+	}
+	return true
+}
+
+type FakeTiDBClusterMaintenanceControl struct {
+	reconcile func(*v1alpha1.TidbClusterMaintenance) error
+}
+
+func (c *FakeTiDBClusterMaintenanceControl) MockReconcile(reconcile func(*v1alpha1.TidbClusterMaintenance) error) {
+	c.reconcile = reconcile
+}
+
+func (c *FakeTiDBClusterMaintenanceControl) Reconcile(tcm *v1alpha1.TidbClusterMaintenance) error {
+	if c.reconcile != nil {
+		return c.reconcile(tcm)
+	}
+	return nil
+}
+
+func (c *FakeTiDBClusterMaintenanceControl) Update(tcm *v1alpha1.TidbClusterMaintenance) (*v1alpha1.TidbClusterMaintenance, error) {
+	return tcm, nil
+}
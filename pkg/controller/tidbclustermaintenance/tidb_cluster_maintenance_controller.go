@@ -0,0 +1,133 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclustermaintenance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager/maintenance"
+
+	perrors "github.com/pingcap/errors"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// Controller runs the tasks of TidbClusterMaintenance
+type Controller struct {
+	deps    *controller.Dependencies
+	control ControlInterface
+	queue   workqueue.RateLimitingInterface
+}
+
+func NewController(deps *controller.Dependencies) *Controller {
+	c := &Controller{
+		deps: deps,
+		control: NewDefaultTiDBClusterMaintenanceControl(
+			deps,
+			maintenance.NewMaintenanceManager(deps),
+			deps.Recorder,
+		),
+		queue: workqueue.NewNamedRateLimitingQueue(
+			controller.NewControllerRateLimiter(1*time.Second, 100*time.Second),
+			"tidb-cluster-maintenance",
+		),
+	}
+
+	tcmInformer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusterMaintenances()
+	jobInformer := deps.KubeInformerFactory.Batch().V1().Jobs()
+	controller.WatchForObject(tcmInformer.Informer(), c.queue)
+	controller.WatchForController(
+		jobInformer.Informer(),
+		c.queue,
+		func(ns, name string) (runtime.Object, error) {
+			return c.deps.TiDBClusterMaintenanceLister.TidbClusterMaintenances(ns).Get(name)
+		},
+		nil,
+	)
+
+	return c
+}
+
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Starting tidbclustermaintenance controller")
+	defer klog.Info("Shutting down tidbclustermaintenance controller")
+
+	// the number of the workers follows the workers flag reloaded from the config file
+	wg := controller.RunWorkers(workers, c.deps.CLIConfig, c.processNextWorkItem, stopCh)
+
+	<-stopCh
+	// wait for the in-flight syncs to finish, so that no operation is left half-done
+	c.queue.ShutDown()
+	wg.Wait()
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	keyIface, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(keyIface)
+
+	key := keyIface.(string)
+	err := c.sync(key)
+	if err != nil {
+		if perrors.Find(err, controller.IsRequeueError) != nil {
+			klog.Infof("TidbClusterMaintenance %v still need sync: %v, requeuing", key, err)
+		} else {
+			utilruntime.HandleError(fmt.Errorf("TidbClusterMaintenance %v sync failed, err: %v", key, err))
+		}
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(keyIface)
+	}
+
+	return true
+}
+
+func (c *Controller) sync(key string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing TidbClusterMaintenance %s (%v)", key, time.Since(startTime))
+	}()
+
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if !c.deps.NamespaceSelector.Selected(ns) {
+		klog.V(4).Infof("TidbClusterMaintenance %s is not in the selected namespaces, skip syncing", key)
+		return nil
+	}
+
+	tcm, err := c.deps.TiDBClusterMaintenanceLister.TidbClusterMaintenances(ns).Get(name)
+	if errors.IsNotFound(err) {
+		klog.Infof("TidbClusterMaintenance %s has been deleted", key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.control.Reconcile(tcm.DeepCopy())
+}
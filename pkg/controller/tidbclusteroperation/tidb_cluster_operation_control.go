@@ -0,0 +1,181 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusteroperation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	v1alpha1validation "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// ControlInterface provide function about control TidbClusterOperation
+type ControlInterface interface {
+	// Reconcile a TidbClusterOperation
+	Reconcile(*v1alpha1.TidbClusterOperation) error
+
+	// Update the status of a TidbClusterOperation
+	Update(*v1alpha1.TidbClusterOperation) (*v1alpha1.TidbClusterOperation, error)
+}
+
+func NewDefaultTiDBClusterOperationControl(
+	deps *controller.Dependencies,
+	operationMnger manager.TiDBClusterOperationManager,
+	recorder record.EventRecorder,
+) *defaultTiDBClusterOperationControl {
+	return &defaultTiDBClusterOperationControl{
+		deps:           deps,
+		recorder:       recorder,
+		operationMnger: operationMnger,
+	}
+}
+
+type defaultTiDBClusterOperationControl struct {
+	deps     *controller.Dependencies
+	recorder record.EventRecorder
+
+	operationMnger manager.TiDBClusterOperationManager
+}
+
+func (c *defaultTiDBClusterOperationControl) Reconcile(tco *v1alpha1.TidbClusterOperation) error {
+	if !c.validate(tco) {
+		return nil // fatal error, no need to retry on invalid object
+	}
+
+	var errs []error
+
+	oldStatus := tco.Status.DeepCopy()
+
+	err := c.reconcile(tco)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if oldStatus.Phase != tco.Status.Phase {
+		switch tco.Status.Phase {
+		case v1alpha1.TidbClusterOperationRunning:
+			c.recorder.Eventf(tco, v1.EventTypeNormal, "OperationStarted", "%s operation started", tco.Spec.Type)
+		case v1alpha1.TidbClusterOperationSucceeded:
+			c.recorder.Eventf(tco, v1.EventTypeNormal, "OperationSucceeded", "%s operation succeeded", tco.Spec.Type)
+		case v1alpha1.TidbClusterOperationFailed:
+			c.recorder.Eventf(tco, v1.EventTypeWarning, "OperationFailed", "%s operation failed: %s", tco.Spec.Type, tco.Status.Message)
+		}
+	}
+
+	if apiequality.Semantic.DeepEqual(&tco.Status, oldStatus) {
+		return errorutils.NewAggregate(errs)
+	}
+
+	_, err = c.Update(tco.DeepCopy())
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errorutils.NewAggregate(errs)
+}
+
+func (c *defaultTiDBClusterOperationControl) reconcile(tco *v1alpha1.TidbClusterOperation) error {
+	if tco.DeletionTimestamp != nil {
+		return nil
+	}
+
+	ns := tco.Spec.Cluster.Namespace
+	if ns == "" {
+		ns = tco.Namespace
+	}
+	tc, err := c.deps.TiDBClusterLister.TidbClusters(ns).Get(tco.Spec.Cluster.Name)
+	if err != nil {
+		return fmt.Errorf("get tc %s/%s failed: %s", ns, tco.Spec.Cluster.Name, err)
+	}
+
+	return c.operationMnger.Sync(tco, tc)
+}
+
+func (c *defaultTiDBClusterOperationControl) Update(tco *v1alpha1.TidbClusterOperation) (*v1alpha1.TidbClusterOperation, error) {
+	var (
+		ns     string                               = tco.GetNamespace()
+		name   string                               = tco.GetName()
+		status *v1alpha1.TidbClusterOperationStatus = tco.Status.DeepCopy()
+		update *v1alpha1.TidbClusterOperation
+	)
+
+	// don't wait due to limited number of clients, but backoff after the default number of steps
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var updateErr error
+
+		update, updateErr = c.deps.Clientset.PingcapV1alpha1().TidbClusterOperations(ns).UpdateStatus(context.TODO(), tco, metav1.UpdateOptions{})
+		if updateErr == nil {
+			klog.Infof("TidbClusterOperation: [%s/%s] updated successfully", ns, name)
+			return nil
+		}
+
+		klog.V(4).Infof("failed to update TidbClusterOperation: [%s/%s], error: %v", ns, name, updateErr)
+
+		if updated, err := c.deps.TiDBClusterOperationLister.TidbClusterOperations(ns).Get(name); err == nil {
+			// make a copy so we don't mutate the shared cache
+			tco = updated.DeepCopy()
+			tco.Status = *status
+		} else {
+			utilruntime.HandleError(fmt.Errorf("error getting updated TidbClusterOperation %s/%s from lister: %v", ns, name, err))
+		}
+
+		return updateErr
+	})
+	if err != nil {
+		klog.Errorf("failed to update TidbClusterOperation: [%s/%s], error: %v", ns, name, err)
+	}
+	return update, err
+}
+
+func (c *defaultTiDBClusterOperationControl) validate(tco *v1alpha1.TidbClusterOperation) bool {
+	errs := v1alpha1validation.ValidateTidbClusterOperation(tco)
+	if len(errs) > 0 {
+		aggregatedErr := errs.ToAggregate()
+		klog.Errorf("tidb cluster operation %s/%s is not valid and must be fixed first, aggregated error: %v", tco.GetNamespace(), tco.GetName(), aggregatedErr)
+		c.recorder.Event(tco, v1.EventTypeWarning, "FailedValidation", aggregatedErr.Error())
+		return false
+	}
+	return true
+}
+
+type FakeTiDBClusterOperationControl struct {
+	reconcile func(*v1alpha1.TidbClusterOperation) error
+}
+
+func (c *FakeTiDBClusterOperationControl) MockReconcile(reconcile func(*v1alpha1.TidbClusterOperation) error) {
+	c.reconcile = reconcile
+}
+
+func (c *FakeTiDBClusterOperationControl) Reconcile(tco *v1alpha1.TidbClusterOperation) error {
+	if c.reconcile != nil {
+		return c.reconcile(tco)
+	}
+	return nil
+}
+
+func (c *FakeTiDBClusterOperationControl) Update(tco *v1alpha1.TidbClusterOperation) (*v1alpha1.TidbClusterOperation, error) {
+	return tco, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusterpreflight
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	v1alpha1validation "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// ControlInterface provide function about control TidbClusterPreflight
+type ControlInterface interface {
+	// Reconcile a TidbClusterPreflight
+	Reconcile(*v1alpha1.TidbClusterPreflight) error
+
+	// Update the status of a TidbClusterPreflight
+	Update(*v1alpha1.TidbClusterPreflight) (*v1alpha1.TidbClusterPreflight, error)
+}
+
+func NewDefaultTidbClusterPreflightControl(
+	deps *controller.Dependencies,
+	preflightMnger manager.TiDBClusterPreflightManager,
+	recorder record.EventRecorder,
+) *defaultTidbClusterPreflightControl {
+	return &defaultTidbClusterPreflightControl{
+		deps:           deps,
+		recorder:       recorder,
+		preflightMnger: preflightMnger,
+	}
+}
+
+type defaultTidbClusterPreflightControl struct {
+	deps     *controller.Dependencies
+	recorder record.EventRecorder
+
+	preflightMnger manager.TiDBClusterPreflightManager
+}
+
+func (c *defaultTidbClusterPreflightControl) Reconcile(pf *v1alpha1.TidbClusterPreflight) error {
+	if pf.DeletionTimestamp != nil {
+		// the check Jobs are garbage collected with the TidbClusterPreflight
+		return nil
+	}
+	if !c.validate(pf) {
+		return nil // fatal error, no need to retry on invalid object
+	}
+
+	var errs []error
+
+	oldStatus := pf.Status.DeepCopy()
+
+	err := c.preflightMnger.Sync(pf)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if oldStatus.Phase != pf.Status.Phase {
+		switch pf.Status.Phase {
+		case v1alpha1.PreflightPhaseRunning:
+			c.recorder.Eventf(pf, v1.EventTypeNormal, "PreflightStarted", "checking %d nodes", len(pf.Status.Nodes))
+		case v1alpha1.PreflightPhaseComplete:
+			c.recorder.Eventf(pf, eventType(pf.Status.Result), "PreflightComplete", "the result of the checks is %s", pf.Status.Result)
+		case v1alpha1.PreflightPhaseFailed:
+			c.recorder.Event(pf, v1.EventTypeWarning, "PreflightFailed", pf.Status.Message)
+		}
+	}
+
+	if !apiequality.Semantic.DeepEqual(&pf.Status, oldStatus) {
+		if _, err := c.Update(pf.DeepCopy()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errorutils.NewAggregate(errs)
+}
+
+func eventType(result v1alpha1.PreflightResult) string {
+	if result == v1alpha1.PreflightResultPassed {
+		return v1.EventTypeNormal
+	}
+	return v1.EventTypeWarning
+}
+
+func (c *defaultTidbClusterPreflightControl) Update(pf *v1alpha1.TidbClusterPreflight) (*v1alpha1.TidbClusterPreflight, error) {
+	var (
+		ns     string                               = pf.GetNamespace()
+		name   string                               = pf.GetName()
+		status *v1alpha1.TidbClusterPreflightStatus = pf.Status.DeepCopy()
+		update *v1alpha1.TidbClusterPreflight
+	)
+
+	// don't wait due to limited number of clients, but backoff after the default number of steps
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var updateErr error
+
+		update, updateErr = c.deps.Clientset.PingcapV1alpha1().TidbClusterPreflights(ns).UpdateStatus(context.TODO(), pf, metav1.UpdateOptions{})
+		if updateErr == nil {
+			klog.Infof("TidbClusterPreflight: [%s/%s] updated successfully", ns, name)
+			return nil
+		}
+
+		klog.V(4).Infof("failed to update TidbClusterPreflight: [%s/%s], error: %v", ns, name, updateErr)
+
+		if updated, err := c.deps.TiDBClusterPreflightLister.TidbClusterPreflights(ns).Get(name); err == nil {
+			// make a copy so we don't mutate the shared cache
+			pf = updated.DeepCopy()
+			pf.Status = *status
+		} else {
+			utilruntime.HandleError(fmt.Errorf("error getting updated TidbClusterPreflight %s/%s from lister: %v", ns, name, err))
+		}
+
+		return updateErr
+	})
+	if err != nil {
+		klog.Errorf("failed to update TidbClusterPreflight: [%s/%s], error: %v", ns, name, err)
+	}
+	return update, err
+}
+
+func (c *defaultTidbClusterPreflightControl) validate(pf *v1alpha1.TidbClusterPreflight) bool {
+	errs := v1alpha1validation.ValidateTidbClusterPreflight(pf)
+	if len(errs) > 0 {
+		aggregatedErr := errs.ToAggregate()
+		klog.Errorf("tidb cluster preflight %s/%s is not valid and must be fixed first, aggregated error: %v", pf.GetNamespace(), pf.GetName(), aggregatedErr)
+		c.recorder.Event(pf, v1.EventTypeWarning, "FailedValidation", aggregatedErr.Error())
+		return false
+	}
+	return true
+}
+
+type FakeTidbClusterPreflightControl struct {
+	reconcile func(*v1alpha1.TidbClusterPreflight) error
+}
+
+func (c *FakeTidbClusterPreflightControl) MockReconcile(reconcile func(*v1alpha1.TidbClusterPreflight) error) {
+	c.reconcile = reconcile
+}
+
+func (c *FakeTidbClusterPreflightControl) Reconcile(pf *v1alpha1.TidbClusterPreflight) error {
+	if c.reconcile != nil {
+		return c.reconcile(pf)
+	}
+	return nil
+}
+
+func (c *FakeTidbClusterPreflightControl) Update(pf *v1alpha1.TidbClusterPreflight) (*v1alpha1.TidbClusterPreflight, error) {
+	return pf, nil
+}
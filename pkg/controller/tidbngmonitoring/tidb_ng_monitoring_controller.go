@@ -78,16 +78,13 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	klog.Info("Starting tidbngmonitor controller")
 	defer klog.Info("Shutting down tidbngmonitor controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
+	// the number of the workers follows the workers flag reloaded from the config file
+	wg := controller.RunWorkers(workers, c.deps.CLIConfig, c.processNextWorkItem, stopCh)
 
 	<-stopCh
-}
-
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
+	// wait for the in-flight syncs to finish, so that no operation is left half-done
+	c.queue.ShutDown()
+	wg.Wait()
 }
 
 func (c *Controller) processNextWorkItem() bool {
@@ -123,6 +120,10 @@ func (c *Controller) sync(key string) error {
 	if err != nil {
 		return err
 	}
+	if !c.deps.NamespaceSelector.Selected(ns) {
+		klog.V(4).Infof("TidbNGMonitoring %s is not in the selected namespaces, skip syncing", key)
+		return nil
+	}
 
 	tngm, err := c.deps.TiDBNGMonitoringLister.TidbNGMonitorings(ns).Get(name)
 	if errors.IsNotFound(err) {
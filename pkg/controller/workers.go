@@ -0,0 +1,75 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// RunWorkers starts the workers of a controller calling processNextWorkItem until it returns false,
+// i.e. the queue of the controller is shut down. The number of the workers starts from workers and
+// follows the workers flag once it's changed by reloading the config file, the extra workers exit
+// after their current items when the number is decreased. The returned WaitGroup is done after all
+// the workers exit, i.e. after stopCh is closed and the queue is shut down.
+func RunWorkers(workers int, cfg *CLIConfig, processNextWorkItem func() bool, stopCh <-chan struct{}) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+	var stops []chan struct{}
+	start := func() {
+		stop := make(chan struct{})
+		stops = append(stops, stop)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(func() {
+				for processNextWorkItem() {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+				}
+			}, time.Second, stop)
+		}()
+	}
+	for i := 0; i < workers; i++ {
+		start()
+	}
+
+	// the supervisor is counted in wg too, so that no worker is added after wg is done
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		configured := cfg.GetWorkers()
+		wait.Until(func() {
+			if n := cfg.GetWorkers(); n != configured && n > 0 {
+				configured = n
+				workers = n
+			}
+			for len(stops) < workers {
+				start()
+			}
+			for len(stops) > workers {
+				close(stops[len(stops)-1])
+				stops = stops[:len(stops)-1]
+			}
+		}, time.Second, stopCh)
+		for _, stop := range stops {
+			close(stop)
+		}
+	}()
+	return wg
+}
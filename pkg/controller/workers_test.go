@@ -0,0 +1,71 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestRunWorkers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg := &CLIConfig{Workers: 5}
+	queue := workqueue.New()
+	var running, processed int32
+	processNextWorkItem := func() bool {
+		atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		item, shutdown := queue.Get()
+		if shutdown {
+			return false
+		}
+		atomic.AddInt32(&processed, 1)
+		queue.Done(item)
+		return true
+	}
+	runningWorkers := func() int32 {
+		return atomic.LoadInt32(&running)
+	}
+
+	stopCh := make(chan struct{})
+	wg := RunWorkers(2, cfg, processNextWorkItem, stopCh)
+	g.Eventually(runningWorkers, 5*time.Second, 100*time.Millisecond).Should(BeEquivalentTo(2))
+	// the workers don't follow the flag until it's changed
+	g.Consistently(runningWorkers, 1500*time.Millisecond, 100*time.Millisecond).Should(BeEquivalentTo(2))
+
+	cfg.lock.Lock()
+	cfg.Workers = 4
+	cfg.lock.Unlock()
+	g.Eventually(runningWorkers, 5*time.Second, 100*time.Millisecond).Should(BeEquivalentTo(4))
+
+	cfg.lock.Lock()
+	cfg.Workers = 1
+	cfg.lock.Unlock()
+	// the extra workers exit after their current items
+	g.Eventually(func() int32 {
+		queue.Add(time.Now().UnixNano())
+		return runningWorkers()
+	}, 5*time.Second, 100*time.Millisecond).Should(BeEquivalentTo(1))
+	g.Expect(atomic.LoadInt32(&processed)).To(BeNumerically(">=", 3))
+
+	close(stopCh)
+	queue.ShutDown()
+	wg.Wait()
+	g.Expect(runningWorkers()).To(BeZero())
+}
@@ -19,7 +19,9 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
@@ -30,10 +32,11 @@ import (
 )
 
 // TiDBDiscovery helps new PD and dm-master member to discover all other members in cluster bootstrap phase.
+// The requests sent to the kube-apiserver and the clusters are canceled once the context is done.
 type TiDBDiscovery interface {
-	Discover(string) (string, error)
-	DiscoverDM(string) (string, error)
-	VerifyPDEndpoint(string) (string, error)
+	Discover(context.Context, string) (string, error)
+	DiscoverDM(context.Context, string) (string, error)
+	VerifyPDEndpoint(context.Context, string) (string, error)
 }
 
 type tidbDiscovery struct {
@@ -43,6 +46,18 @@ type tidbDiscovery struct {
 	dmClusters    map[string]*clusterInfo
 	pdControl     pdapi.PDControlInterface
 	masterControl dmapi.MasterControlInterface
+	// tcCache caches the TidbClusters got for verifying PD endpoints by namespace/name,
+	// as the Pods of a cluster are started and verify the endpoints at the same time
+	tcCacheLock sync.Mutex
+	tcCache     map[string]cachedTidbCluster
+}
+
+// tcCacheFreshness is the duration for which a cached TidbCluster is used
+const tcCacheFreshness = 5 * time.Second
+
+type cachedTidbCluster struct {
+	tc        *v1alpha1.TidbCluster
+	fetchedAt time.Time
 }
 
 type clusterInfo struct {
@@ -65,10 +80,11 @@ func NewTiDBDiscovery(pdControl pdapi.PDControlInterface, masterControl dmapi.Ma
 		masterControl: masterControl,
 		clusters:      map[string]*clusterInfo{},
 		dmClusters:    map[string]*clusterInfo{},
+		tcCache:       map[string]cachedTidbCluster{},
 	}
 }
 
-func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
+func (d *tidbDiscovery) Discover(ctx context.Context, advertisePeerUrl string) (string, error) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
@@ -90,7 +106,7 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 	if ns != podNamespace {
 		return "", fmt.Errorf("the peer's namespace: %s is not equal to discovery namespace: %s", ns, podNamespace)
 	}
-	tc, err := d.cli.PingcapV1alpha1().TidbClusters(ns).Get(context.TODO(), tcName, metav1.GetOptions{})
+	tc, err := d.cli.PingcapV1alpha1().TidbClusters(ns).Get(ctx, tcName, metav1.GetOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -126,7 +142,7 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 
 	if tc.Spec.PD != nil {
 		// connect to pd of current cluster
-		pdClients = append(pdClients, d.pdControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled()))
+		pdClients = append(pdClients, d.pdControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled(), pdapi.Context(ctx)))
 	}
 
 	if tc.Heterogeneous() {
@@ -140,12 +156,13 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 				pdapi.TLSCertFromTC(pdapi.Namespace(tc.GetNamespace()), tc.GetName()),
 				pdapi.ClusterRef(tc.Spec.Cluster.ClusterDomain),
 				pdapi.UseHeadlessService(tc.Spec.AcrossK8s),
+				pdapi.Context(ctx),
 			),
 		)
 	}
 
 	for _, pdMember := range tc.Status.PD.PeerMembers {
-		pdClients = append(pdClients, d.pdControl.GetPDClient(pdapi.Namespace(ns), tc.Name, tc.IsTLSClusterEnabled(), pdapi.SpecifyClient(pdMember.ClientURL, pdMember.Name)))
+		pdClients = append(pdClients, d.pdControl.GetPDClient(pdapi.Namespace(ns), tc.Name, tc.IsTLSClusterEnabled(), pdapi.SpecifyClient(pdMember.ClientURL, pdMember.Name), pdapi.Context(ctx)))
 	}
 
 	var membersInfo *pdapi.MembersInfo
@@ -181,7 +198,7 @@ func (d *tidbDiscovery) Discover(advertisePeerUrl string) (string, error) {
 	return fmt.Sprintf("--join=%s", strings.Join(membersArr, ",")), nil
 }
 
-func (d *tidbDiscovery) DiscoverDM(advertisePeerUrl string) (string, error) {
+func (d *tidbDiscovery) DiscoverDM(ctx context.Context, advertisePeerUrl string) (string, error) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
@@ -203,7 +220,7 @@ func (d *tidbDiscovery) DiscoverDM(advertisePeerUrl string) (string, error) {
 	dcName := strings.TrimSuffix(peerServiceName, "-dm-master-peer")
 	ns := os.Getenv("MY_POD_NAMESPACE")
 
-	dc, err := d.cli.PingcapV1alpha1().DMClusters(ns).Get(context.TODO(), dcName, metav1.GetOptions{})
+	dc, err := d.cli.PingcapV1alpha1().DMClusters(ns).Get(ctx, dcName, metav1.GetOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -245,12 +262,12 @@ func (d *tidbDiscovery) DiscoverDM(advertisePeerUrl string) (string, error) {
 	return fmt.Sprintf("--join=%s", strings.Join(mastersArr, ",")), nil
 }
 
-func (d *tidbDiscovery) VerifyPDEndpoint(pdURL string) (string, error) {
+func (d *tidbDiscovery) VerifyPDEndpoint(ctx context.Context, pdURL string) (string, error) {
 	pdEndpoint := parsePDURL(pdURL)
 	klog.Infof("Get PD endpoint URL: %s, scheme is %s, pdMemberName is %s, pdMemberPort is %s, tcName is %s", pdURL, pdEndpoint.scheme, pdEndpoint.pdMemberName, pdEndpoint.pdMemberPort, pdEndpoint.tcName)
 
 	ns := os.Getenv("MY_POD_NAMESPACE")
-	tc, err := d.cli.PingcapV1alpha1().TidbClusters(ns).Get(context.TODO(), pdEndpoint.tcName, metav1.GetOptions{})
+	tc, err := d.getTidbCluster(ctx, ns, pdEndpoint.tcName)
 	if err != nil {
 		klog.Errorf("Failed to get the tidbcluster when verifying PD endpoint, tcName: %s , ns: %s", pdEndpoint.tcName, ns)
 		return pdURL, err
@@ -284,6 +301,27 @@ func (d *tidbDiscovery) VerifyPDEndpoint(pdURL string) (string, error) {
 	return strings.Join(returnPDMembers, ","), nil
 }
 
+// getTidbCluster returns the TidbCluster from the cache if it's fetched in tcCacheFreshness,
+// otherwise it's got from the API server
+func (d *tidbDiscovery) getTidbCluster(ctx context.Context, ns, name string) (*v1alpha1.TidbCluster, error) {
+	key := ns + "/" + name
+	d.tcCacheLock.Lock()
+	cached, ok := d.tcCache[key]
+	d.tcCacheLock.Unlock()
+	if ok && time.Since(cached.fetchedAt) < tcCacheFreshness {
+		return cached.tc, nil
+	}
+
+	tc, err := d.cli.PingcapV1alpha1().TidbClusters(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	d.tcCacheLock.Lock()
+	d.tcCache[key] = cachedTidbCluster{tc: tc, fetchedAt: time.Now()}
+	d.tcCacheLock.Unlock()
+	return tc, nil
+}
+
 // parsePDURL parses pdURL to PDEndpoint related information
 func parsePDURL(pdURL string) pdEndpointURL {
 	// Deal with scheme
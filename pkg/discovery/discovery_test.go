@@ -19,6 +19,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -64,7 +65,7 @@ func TestDiscoveryDiscovery(t *testing.T) {
 		td.(*tidbDiscovery).clusters = test.clusters
 
 		os.Setenv("MY_POD_NAMESPACE", test.ns)
-		re, err := td.Discover(test.url)
+		re, err := td.Discover(context.Background(), test.url)
 		test.expectFn(g, td.(*tidbDiscovery), re, err)
 	}
 	tests := []testcase{
@@ -566,7 +567,7 @@ func TestDiscoveryDMDiscovery(t *testing.T) {
 		td.(*tidbDiscovery).dmClusters = test.dmClusters
 
 		os.Setenv("MY_POD_NAMESPACE", test.ns)
-		re, err := td.DiscoverDM(test.url)
+		re, err := td.DiscoverDM(context.Background(), test.url)
 		test.expectFn(g, td.(*tidbDiscovery), re, err)
 	}
 	tests := []testcase{
@@ -980,7 +981,7 @@ func TestDiscoveryVerifyPDEndpoint(t *testing.T) {
 		td := NewTiDBDiscovery(fakePDControl, fakeMasterControl, cli, kubeCli)
 
 		os.Setenv("MY_POD_NAMESPACE", test.ns)
-		re, err := td.VerifyPDEndpoint(test.url)
+		re, err := td.VerifyPDEndpoint(context.Background(), test.url)
 		test.expectFn(g, td.(*tidbDiscovery), re, err)
 	}
 	tests := []testcase{
@@ -1148,6 +1149,48 @@ func TestDiscoveryVerifyPDEndpoint(t *testing.T) {
 	}
 }
 
+func TestDiscoveryGetTidbClusterCache(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cli := fake.NewSimpleClientset()
+	kubeCli := kubefake.NewSimpleClientset()
+	informer := kubeinformers.NewSharedInformerFactory(kubeCli, 0)
+	fakePDControl := pdapi.NewFakePDControl(informer.Core().V1().Secrets().Lister())
+	fakeMasterControl := dmapi.NewFakeMasterControl(informer.Core().V1().Secrets().Lister())
+	td := NewTiDBDiscovery(fakePDControl, fakeMasterControl, cli, kubeCli).(*tidbDiscovery)
+
+	countGets := func() int {
+		count := 0
+		for _, action := range cli.Actions() {
+			if action.GetVerb() == "get" {
+				count++
+			}
+		}
+		return count
+	}
+
+	// the failures are not cached
+	_, err := td.getTidbCluster(context.Background(), metav1.NamespaceDefault, "demo")
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	_, err = cli.PingcapV1alpha1().TidbClusters(metav1.NamespaceDefault).Create(context.TODO(), newTC(), metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	tc, err := td.getTidbCluster(context.Background(), metav1.NamespaceDefault, "demo")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tc.Name).To(Equal("demo"))
+	g.Expect(countGets()).To(Equal(2))
+
+	_, err = td.getTidbCluster(context.Background(), metav1.NamespaceDefault, "demo")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(countGets()).To(Equal(2))
+
+	// the stale cache is refreshed
+	key := metav1.NamespaceDefault + "/demo"
+	td.tcCache[key] = cachedTidbCluster{tc: tc, fetchedAt: time.Now().Add(-tcCacheFreshness)}
+	_, err = td.getTidbCluster(context.Background(), metav1.NamespaceDefault, "demo")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(countGets()).To(Equal(3))
+}
+
 func newTC() *v1alpha1.TidbCluster {
 	return &v1alpha1.TidbCluster{
 		TypeMeta: metav1.TypeMeta{Kind: "TidbCluster", APIVersion: "v1alpha1"},
@@ -22,8 +22,10 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/pingcap/tidb-operator/pkg/manager/member"
+	tchttputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	"k8s.io/klog/v2"
 )
 
@@ -39,14 +41,16 @@ func buildUrl(tcName string, tlsEnabled bool) *url.URL {
 	return url
 }
 
+// buildProxy returns the proxy to the dashboard of PD, its transport is the one shared by the
+// clients of the components so that the connections are reused across the requests
 func buildProxy(url *url.URL, tlsEnabled bool) (*httputil.ReverseProxy, error) {
 	proxy := httputil.NewSingleHostReverseProxy(url)
+	var tlsConfig *tls.Config
 	if tlsEnabled {
 		// load crt and key
 		certPath := fmt.Sprintf("%s/tls.crt", member.PdTlsCertPath)
 		keyPath := fmt.Sprintf("%s/tls.key", member.PdTlsCertPath)
-		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
-		if err != nil {
+		if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
 			klog.Error(err)
 			return nil, err
 		}
@@ -59,12 +63,20 @@ func buildProxy(url *url.URL, tlsEnabled bool) (*httputil.ReverseProxy, error) {
 			return nil, err
 		}
 		rootCAs.AppendCertsFromPEM(caByte)
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{cert},
-			RootCAs:      rootCAs,
+		tlsConfig = &tls.Config{
+			RootCAs: rootCAs,
+			// the certificate is loaded on every handshake as it's renewed in place
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+				if err != nil {
+					return nil, err
+				}
+				return &cert, nil
+			},
 		}
-		proxy.Transport = &http.Transport{TLSClientConfig: tlsConfig}
 	}
+	// the dashboard responses are streamed, so only the dialing and the headers are bounded
+	proxy.Transport = tchttputil.NewHTTPClient(0, tlsConfig, false).Transport
 	director := proxy.Director
 	proxy.Director = func(req *http.Request) {
 		if strings.HasPrefix(req.RequestURI, "/dashboard") {
@@ -78,6 +90,10 @@ func buildProxy(url *url.URL, tlsEnabled bool) (*httputil.ReverseProxy, error) {
 type proxyServer struct {
 	proxyTo      *url.URL
 	tcTlsEnabled bool
+
+	lock sync.Mutex
+	// proxy is built on the first request and reused, it's rebuilt if it failed to be built
+	proxy *httputil.ReverseProxy
 }
 
 func NewProxyServer(tcName string, tcTlsEnabled bool) Server {
@@ -88,7 +104,7 @@ func NewProxyServer(tcName string, tcTlsEnabled bool) Server {
 }
 
 func (p *proxyServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	proxy, err := buildProxy(p.proxyTo, p.tcTlsEnabled)
+	proxy, err := p.getProxy()
 	if err != nil {
 		msg := fmt.Sprintf("Error Happed, err:%v", err)
 		w.Write([]byte(msg))
@@ -97,6 +113,19 @@ func (p *proxyServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	proxy.ServeHTTP(w, req)
 }
 
+func (p *proxyServer) getProxy() (*httputil.ReverseProxy, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.proxy == nil {
+		proxy, err := buildProxy(p.proxyTo, p.tcTlsEnabled)
+		if err != nil {
+			return nil, err
+		}
+		p.proxy = proxy
+	}
+	return p.proxy, nil
+}
+
 func (p *proxyServer) ListenAndServe(addr string) {
 	klog.Fatal(http.ListenAndServe(addr, p))
 }
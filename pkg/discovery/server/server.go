@@ -19,6 +19,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
 
@@ -30,6 +31,13 @@ import (
 	"k8s.io/klog/v2"
 )
 
+const (
+	// maxVerifyTimeout is the max timeout of the blocking verification of PD endpoints
+	maxVerifyTimeout = 5 * time.Minute
+	// verifyRetryInterval is the interval to retry the blocking verification of PD endpoints
+	verifyRetryInterval = time.Second
+)
+
 type server struct {
 	discovery discovery.TiDBDiscovery
 	container *restful.Container
@@ -50,6 +58,7 @@ func (s *server) registerHandlers() {
 	ws.Route(ws.GET("/new/{advertise-peer-url}").To(s.newHandler))
 	ws.Route(ws.GET("/new/{advertise-peer-url}/{register-type}").To(s.newHandler))
 	ws.Route(ws.GET("/verify/{pd-url}").To(s.newVerifyHandler))
+	ws.Route(ws.GET("/healthz").To(s.healthHandler))
 	s.container.Add(ws)
 }
 
@@ -76,9 +85,9 @@ func (s *server) newHandler(req *restful.Request, resp *restful.Response) {
 	var result string
 	switch registerType {
 	case "pd":
-		result, err = s.discovery.Discover(advertisePeerURL)
+		result, err = s.discovery.Discover(req.Request.Context(), advertisePeerURL)
 	case "dm":
-		result, err = s.discovery.DiscoverDM(advertisePeerURL)
+		result, err = s.discovery.DiscoverDM(req.Request.Context(), advertisePeerURL)
 	default:
 		err = fmt.Errorf("invalid register-type %s", registerType)
 		klog.Errorf("%v", err)
@@ -115,8 +124,39 @@ func (s *server) newVerifyHandler(req *restful.Request, resp *restful.Response)
 	pdPeerURL := string(data)
 	pdPeerURL = strings.Trim(pdPeerURL, "\n")
 
+	// if timeout is specified, the request is blocked until the verification succeeds or times out,
+	// so that the callers don't need to poll the endpoint
+	var timeout time.Duration
+	if t := req.QueryParameter("timeout"); t != "" {
+		timeout, err = time.ParseDuration(t)
+		if err != nil {
+			if werr := resp.WriteError(http.StatusBadRequest, fmt.Errorf("invalid timeout %q: %v", t, err)); werr != nil {
+				klog.Errorf("failed to writeError: %v", werr)
+			}
+			return
+		}
+		if timeout > maxVerifyTimeout {
+			timeout = maxVerifyTimeout
+		}
+	}
+
+	// the verification is stopped once the caller is gone
+	ctx := req.Request.Context()
 	var result string
-	result, err = s.discovery.VerifyPDEndpoint(pdPeerURL)
+	deadline := time.Now().Add(timeout)
+	for {
+		result, err = s.discovery.VerifyPDEndpoint(ctx, pdPeerURL)
+		if err == nil || !time.Now().Add(verifyRetryInterval).Before(deadline) {
+			break
+		}
+		klog.V(4).Infof("failed to verify pd-url: %s, %v, retry in %v", pdPeerURL, err, verifyRetryInterval)
+		select {
+		case <-ctx.Done():
+			klog.Infof("stop verifying pd-url: %s, %v", pdPeerURL, ctx.Err())
+			return
+		case <-time.After(verifyRetryInterval):
+		}
+	}
 	if err != nil {
 		klog.Errorf("failed to verify pd-url: %s, %v", pdPeerURL, err)
 		if werr := resp.WriteError(http.StatusInternalServerError, err); werr != nil {
@@ -131,3 +171,9 @@ func (s *server) newVerifyHandler(req *restful.Request, resp *restful.Response)
 		klog.Errorf("failed to writeString: %s, %v", result, err)
 	}
 }
+
+func (s *server) healthHandler(req *restful.Request, resp *restful.Response) {
+	if _, err := io.WriteString(resp, "ok"); err != nil {
+		klog.Errorf("failed to writeString: %v", err)
+	}
+}
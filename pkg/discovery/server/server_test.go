@@ -265,3 +265,52 @@ func TestVerifyServer(t *testing.T) {
 		t.Errorf("verify pdEndpoint failed: %v", err)
 	}
 }
+
+func TestVerifyServerBlocking(t *testing.T) {
+	os.Setenv("MY_POD_NAMESPACE", "default")
+	cli := fake.NewSimpleClientset()
+	kubeCli := kubefake.NewSimpleClientset()
+	informer := informers.NewSharedInformerFactory(kubeCli, 0)
+	fakePDControl := pdapi.NewFakePDControl(informer.Core().V1().Secrets().Lister())
+	fakeMasterControl := dmapi.NewFakeMasterControl(informer.Core().V1().Secrets().Lister())
+	s := NewServer(fakePDControl, fakeMasterControl, cli, kubeCli)
+
+	httpServer := httptest.NewServer(s.(*server).container.ServeMux)
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status code of /healthz: %d", resp.StatusCode)
+	}
+
+	svc := "foo-pd:2379"
+	url := httpServer.URL + fmt.Sprintf("/verify/%s", base64.StdEncoding.EncodeToString([]byte(svc)))
+	resp, err = http.Get(url + "?timeout=invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unexpected status code for invalid timeout: %d", resp.StatusCode)
+	}
+
+	// the TidbCluster is created after the request is sent
+	go func() {
+		time.Sleep(1500 * time.Millisecond)
+		cli.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	}()
+	resp, err = http.Get(url + "?timeout=10s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(data) != svc {
+		t.Errorf("unexpected response of blocking verification: %d %s", resp.StatusCode, string(data))
+	}
+}
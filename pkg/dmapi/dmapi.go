@@ -14,10 +14,15 @@
 package dmapi
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
@@ -36,13 +41,21 @@ type MasterClient interface {
 	EvictLeader() error
 	DeleteMaster(name string) error
 	DeleteWorker(name string) error
+	// TransferSource binds the source to the worker by the dm-master openapi,
+	// ErrOpenAPIUnavailable is returned if the openapi is not enabled
+	TransferSource(source, worker string) error
 }
 
 var (
 	membersPrefix = "apis/v1alpha1/members"
 	leaderPrefix  = "apis/v1alpha1/leader"
+	sourcesPrefix = "api/v1/sources"
 )
 
+// ErrOpenAPIUnavailable is returned if the openapi of dm-master is not enabled, which
+// requires dm-master to be started with `openapi = true`
+var ErrOpenAPIUnavailable = errors.New("dm-master openapi is unavailable")
+
 type RespHeader struct {
 	Result bool   `json:"result,omitempty"`
 	Msg    string `json:"msg,omitempty"`
@@ -63,6 +76,10 @@ type WorkersInfo struct {
 	Source string `json:"source,omitempty"`
 }
 
+type transferSourceReq struct {
+	WorkerName string `json:"worker_name"`
+}
+
 type MembersMaster struct {
 	Msg     string         `json:"msg,omitempty"`
 	Masters []*MastersInfo `json:"masters,omitempty"`
@@ -110,12 +127,14 @@ type LeaderResp struct {
 type masterClient struct {
 	url        string
 	httpClient *http.Client
+	// ctx cancels the requests once it's done
+	ctx context.Context
 }
 
 func (c *masterClient) GetMasters() ([]*MastersInfo, error) {
 	query := "?master=true"
 	apiURL := fmt.Sprintf("%s/%s%s", c.url, membersPrefix, query)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +156,7 @@ func (c *masterClient) GetMasters() ([]*MastersInfo, error) {
 func (c *masterClient) GetWorkers() ([]*WorkersInfo, error) {
 	query := "?worker=true"
 	apiURL := fmt.Sprintf("%s/%s%s", c.url, membersPrefix, query)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +178,7 @@ func (c *masterClient) GetWorkers() ([]*WorkersInfo, error) {
 func (c *masterClient) GetLeader() (MembersLeader, error) {
 	query := "?leader=true"
 	apiURL := fmt.Sprintf("%s/%s%s", c.url, membersPrefix, query)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return MembersLeader{}, err
 	}
@@ -181,7 +200,7 @@ func (c *masterClient) GetLeader() (MembersLeader, error) {
 func (c *masterClient) EvictLeader() error {
 	query := "/1"
 	apiURL := fmt.Sprintf("%s/%s%s", c.url, leaderPrefix, query)
-	body, err := httputil.PutBodyOK(c.httpClient, apiURL)
+	body, err := httputil.PutBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return err
 	}
@@ -199,7 +218,7 @@ func (c *masterClient) EvictLeader() error {
 
 func (c *masterClient) deleteMember(query string) error {
 	apiURL := fmt.Sprintf("%s/%s%s", c.url, membersPrefix, query)
-	body, err := httputil.DeleteBodyOK(c.httpClient, apiURL)
+	body, err := httputil.DeleteBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return err
 	}
@@ -225,13 +244,40 @@ func (c *masterClient) DeleteWorker(name string) error {
 	return c.deleteMember(query)
 }
 
+func (c *masterClient) TransferSource(source, worker string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s/transfer", c.url, sourcesPrefix, url.PathEscape(source))
+	data, err := json.Marshal(transferSourceReq{WorkerName: worker})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusNotFound {
+		return ErrOpenAPIUnavailable
+	}
+	if res.StatusCode >= 400 {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("unable to transfer source %s to worker %s, response %d, err: %s", source, worker, res.StatusCode, body)
+	}
+	return nil
+}
+
 // NewMasterClient returns a new MasterClient
 func NewMasterClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) MasterClient {
-	return &masterClient{
-		url: url,
-		httpClient: &http.Client{
-			Timeout:   timeout,
-			Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: disableKeepalive},
-		},
-	}
+	return newMasterClient(context.Background(), url, httputil.NewHTTPClient(timeout, tlsConfig, disableKeepalive))
+}
+
+func newMasterClient(ctx context.Context, url string, httpClient *http.Client) MasterClient {
+	return &masterClient{url: url, httpClient: httpClient, ctx: ctx}
 }
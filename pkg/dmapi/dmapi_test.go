@@ -208,3 +208,32 @@ func TestDeleteMember(t *testing.T) {
 		g.Expect(err).NotTo(HaveOccurred())
 	}
 }
+
+func TestTransferSource(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("POST"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s/mysql-replica-01/transfer", sourcesPrefix)), "check url")
+
+		req := transferSourceReq{}
+		g.Expect(json.NewDecoder(request.Body).Decode(&req)).To(Succeed())
+		g.Expect(req.WorkerName).To(Equal("dm-worker-0"))
+		w.WriteHeader(http.StatusOK)
+	})
+	defer svc.Close()
+
+	masterClient := NewMasterClient(svc.URL, DefaultTimeout, &tls.Config{}, false)
+	err := masterClient.TransferSource("mysql-replica-01", "dm-worker-0")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the openapi is not enabled
+	notFound := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer notFound.Close()
+
+	masterClient = NewMasterClient(notFound.URL, DefaultTimeout, &tls.Config{}, false)
+	err = masterClient.TransferSource("mysql-replica-01", "dm-worker-0")
+	g.Expect(err).To(Equal(ErrOpenAPIUnavailable))
+}
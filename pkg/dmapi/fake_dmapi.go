@@ -20,12 +20,13 @@ import (
 type ActionType string
 
 const (
-	GetMastersActionType   ActionType = "GetMasters"
-	GetWorkersActionType   ActionType = "GetWorkers"
-	GetLeaderActionType    ActionType = "GetLeader"
-	EvictLeaderActionType  ActionType = "EvictLeader"
-	DeleteMasterActionType ActionType = "DeleteMaster"
-	DeleteWorkerActionType ActionType = "DeleteWorker"
+	GetMastersActionType     ActionType = "GetMasters"
+	GetWorkersActionType     ActionType = "GetWorkers"
+	GetLeaderActionType      ActionType = "GetLeader"
+	EvictLeaderActionType    ActionType = "EvictLeader"
+	DeleteMasterActionType   ActionType = "DeleteMaster"
+	DeleteWorkerActionType   ActionType = "DeleteWorker"
+	TransferSourceActionType ActionType = "TransferSource"
 )
 
 type NotFoundReaction struct {
@@ -113,3 +114,9 @@ func (c *FakeMasterClient) DeleteWorker(_ string) error {
 	_, err := c.fakeAPI(DeleteWorkerActionType, action)
 	return err
 }
+
+func (c *FakeMasterClient) TransferSource(source, worker string) error {
+	action := &Action{Name: worker, Labels: map[string]string{"source": source}}
+	_, err := c.fakeAPI(TransferSourceActionType, action)
+	return err
+}
@@ -14,12 +14,14 @@
 package dmapi
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"sync"
 
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
@@ -34,14 +36,26 @@ type MasterControlInterface interface {
 
 // defaultMasterControl is the default implementation of MasterControlInterface.
 type defaultMasterControl struct {
+	// ctx is the context of the requests, it's done when tidb-operator is shutting down
+	ctx           context.Context
 	mutex         sync.Mutex
 	secretLister  corelisterv1.SecretLister
 	masterClients map[string]MasterClient
 }
 
-// NewDefaultMasterControl returns a defaultMasterControl instance
-func NewDefaultMasterControl(secretLister corelisterv1.SecretLister) MasterControlInterface {
-	return &defaultMasterControl{secretLister: secretLister, masterClients: map[string]MasterClient{}}
+// NewDefaultMasterControl returns a defaultMasterControl instance whose requests are canceled once ctx is done
+func NewDefaultMasterControl(ctx context.Context, secretLister corelisterv1.SecretLister) MasterControlInterface {
+	return &defaultMasterControl{ctx: ctx, secretLister: secretLister, masterClients: map[string]MasterClient{}}
+}
+
+// newClient returns a MasterClient whose requests share the limiter of the dm cluster
+func (mc *defaultMasterControl) newClient(namespace, dcName, url string, tlsConfig *tls.Config, disableKeepalive bool) MasterClient {
+	ctx := mc.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	httpClient := httputil.NewHTTPClient(DefaultTimeout, tlsConfig, disableKeepalive)
+	return newMasterClient(ctx, url, httputil.LimitCluster(httpClient, fmt.Sprintf("%s/%s", namespace, dcName)))
 }
 
 // GetMasterClient provides a MasterClient of real dm-master cluster, if the MasterClient not existing, it will create new one.
@@ -58,15 +72,15 @@ func (mc *defaultMasterControl) GetMasterClient(namespace string, dcName string,
 		tlsConfig, err = pdapi.GetTLSConfig(mc.secretLister, pdapi.Namespace(namespace), util.DMClientTLSSecretName(dcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for dm cluster %q, master client may not work: %v", dcName, err)
-			return NewMasterClient(MasterClientURL(namespace, dcName, scheme), DefaultTimeout, tlsConfig, true)
+			return mc.newClient(namespace, dcName, MasterClientURL(namespace, dcName, scheme), tlsConfig, true)
 		}
 
-		return NewMasterClient(MasterClientURL(namespace, dcName, scheme), DefaultTimeout, tlsConfig, true)
+		return mc.newClient(namespace, dcName, MasterClientURL(namespace, dcName, scheme), tlsConfig, true)
 	}
 
 	key := masterClientKey(scheme, namespace, dcName)
 	if _, ok := mc.masterClients[key]; !ok {
-		mc.masterClients[key] = NewMasterClient(MasterClientURL(namespace, dcName, scheme), DefaultTimeout, nil, false)
+		mc.masterClients[key] = mc.newClient(namespace, dcName, MasterClientURL(namespace, dcName, scheme), nil, false)
 	}
 	return mc.masterClients[key]
 }
@@ -84,13 +98,13 @@ func (mc *defaultMasterControl) GetMasterPeerClient(namespace string, dcName str
 		tlsConfig, err = pdapi.GetTLSConfig(mc.secretLister, pdapi.Namespace(namespace), util.DMClientTLSSecretName(dcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for dm cluster %q, master client may not work: %v", dcName, err)
-			return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+			return mc.newClient(namespace, dcName, MasterPeerClientURL(namespace, dcName, podName, scheme), tlsConfig, true)
 		}
 
-		return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+		return mc.newClient(namespace, dcName, MasterPeerClientURL(namespace, dcName, podName, scheme), tlsConfig, true)
 	}
 
-	return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+	return mc.newClient(namespace, dcName, MasterPeerClientURL(namespace, dcName, podName, scheme), tlsConfig, true)
 }
 
 // masterClientKey returns the master client key
@@ -25,12 +25,32 @@ import (
 	"k8s.io/klog/v2"
 )
 
+type prerelease string
+
+const (
+	// Alpha features are disabled by default, they may be buggy and changed incompatibly.
+	Alpha = prerelease("ALPHA")
+	// Beta features are well tested, the default may be enabled or disabled.
+	Beta = prerelease("BETA")
+	// GA features are always enabled and can't be disabled.
+	GA = prerelease("")
+)
+
+// FeatureSpec represents the default and the stage of a feature
+type FeatureSpec struct {
+	Default    bool
+	PreRelease prerelease
+	// Dynamic features are checked on every sync, so they can be changed by
+	// reloading the config file of tidb-controller-manager without restarting
+	Dynamic bool
+}
+
 var (
-	allFeatures     = sets.NewString(StableScheduling)
-	defaultFeatures = map[string]bool{
-		StableScheduling:    true,
-		AdvancedStatefulSet: false,
-		AutoScaling:         false,
+	knownFeatures = map[string]FeatureSpec{
+		StableScheduling:    {Default: true, PreRelease: Beta},
+		AdvancedStatefulSet: {Default: false, PreRelease: Alpha},
+		AutoScaling:         {Default: false, PreRelease: Alpha},
+		ServerSideApply:     {Default: false, PreRelease: Alpha, Dynamic: true},
 	}
 	// DefaultFeatureGate is a shared global FeatureGate.
 	DefaultFeatureGate FeatureGate = NewDefaultFeatureGate()
@@ -45,6 +65,9 @@ const (
 
 	// AutoScaling controls whether to use TidbClusterAutoScaler to auto scale-in/out pods
 	AutoScaling string = "AutoScaling"
+
+	// ServerSideApply controls whether to reconcile the managed objects by server-side apply
+	ServerSideApply string = "ServerSideApply"
 )
 
 type FeatureGate interface {
@@ -57,22 +80,52 @@ type FeatureGate interface {
 	Set(value string) error
 	// SetFromMap stores flag gates for enabled features from a map[string]bool
 	SetFromMap(m map[string]bool)
+	// Reload stores the changes of the dynamic features from last to value, both are
+	// formatted as the features flag. The changes of the other features are returned
+	// but not stored, they take effect on restarting.
+	Reload(last, value string) ([]FeatureChange, error)
 	// String returns a string representation of feature gate.
 	String() string
 }
 
+// FeatureChange is a change of a feature found by reloading
+type FeatureChange struct {
+	Feature string
+	Old     bool
+	New     bool
+	// Applied is false if the feature is not dynamic and the change takes effect on restarting
+	Applied bool
+}
+
 var _ flag.Value = &featureGate{}
 
 type featureGate struct {
-	lock            sync.Mutex
+	lock            sync.RWMutex
+	known           map[string]FeatureSpec
 	enabledFeatures map[string]bool
 }
 
 func (f *featureGate) AddFlag(flagset *flag.FlagSet) {
-	flag.Var(f, "features", fmt.Sprintf("A set of key={true,false} pairs to enable/disable features, available features: %s", strings.Join(allFeatures.List(), ",")))
+	flag.Var(f, "features", fmt.Sprintf("A set of key={true,false} pairs to enable/disable features, available features:\n%s", strings.Join(f.knownFeatures(), "\n")))
+}
+
+// knownFeatures returns a slice of strings describing the known features
+func (f *featureGate) knownFeatures() []string {
+	var known []string
+	for k, v := range f.known {
+		if v.PreRelease == GA {
+			continue
+		}
+		known = append(known, fmt.Sprintf("%s=true|false (%s - default=%t)", k, v.PreRelease, v.Default))
+	}
+	sort.Strings(known)
+	return known
 }
 
 func (f *featureGate) Enabled(key string) bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
 	if b, ok := f.enabledFeatures[key]; ok {
 		return b
 	}
@@ -81,6 +134,9 @@ func (f *featureGate) Enabled(key string) bool {
 
 // String returns a string containing all enabled feature gates, formatted as "key1=value1,key2=value2,...".
 func (f *featureGate) String() string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
 	pairs := []string{}
 	for k, v := range f.enabledFeatures {
 		pairs = append(pairs, fmt.Sprintf("%s=%t", k, v))
@@ -90,25 +146,75 @@ func (f *featureGate) String() string {
 }
 
 func (f *featureGate) Set(value string) error {
+	m, err := f.parse(strings.Split(value, ","))
+	if err != nil {
+		return err
+	}
+	f.SetFromMap(m)
+	return nil
+}
+
+// Reload stores the changes of the dynamic features from last to value, the features
+// removed from value are reset to their defaults
+func (f *featureGate) Reload(last, value string) ([]FeatureChange, error) {
+	lastMap, err := f.parse(strings.Split(last, ","))
+	if err != nil {
+		return nil, err
+	}
+	m, err := f.parse(strings.Split(value, ","))
+	if err != nil {
+		return nil, err
+	}
+	for k := range lastMap {
+		if _, ok := m[k]; ok {
+			continue
+		}
+		if spec, ok := f.known[k]; ok {
+			m[k] = spec.Default || spec.PreRelease == GA
+		}
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var changes []FeatureChange
+	for _, k := range sets.StringKeySet(m).List() {
+		old := f.enabledFeatures[k]
+		if m[k] == old {
+			continue
+		}
+		change := FeatureChange{Feature: k, Old: old, New: m[k], Applied: f.known[k].Dynamic}
+		if change.Applied {
+			f.enabledFeatures[k] = m[k]
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// parse parses the pairs like feature1=true, GA features are not allowed to be disabled
+func (f *featureGate) parse(pairs []string) (map[string]bool, error) {
 	m := make(map[string]bool)
-	for _, s := range strings.Split(value, ",") {
+	for _, s := range pairs {
+		s = strings.TrimSpace(s)
 		if len(s) == 0 {
 			continue
 		}
 		arr := strings.SplitN(s, "=", 2)
 		k := strings.TrimSpace(arr[0])
 		if len(arr) != 2 {
-			return fmt.Errorf("missing bool value for %s", k)
+			return nil, fmt.Errorf("missing bool value for %s", k)
 		}
 		v := strings.TrimSpace(arr[1])
 		boolValue, err := strconv.ParseBool(v)
 		if err != nil {
-			return fmt.Errorf("invalid value of %s=%s, err: %v", k, v, err)
+			return nil, fmt.Errorf("invalid value of %s=%s, err: %v", k, v, err)
+		}
+		if spec, ok := f.known[k]; ok && spec.PreRelease == GA && !boolValue {
+			return nil, fmt.Errorf("feature %s is GA and can't be disabled", k)
 		}
 		m[k] = boolValue
 	}
-	f.SetFromMap(m)
-	return nil
+	return m, nil
 }
 
 func (f *featureGate) SetFromMap(m map[string]bool) {
@@ -116,6 +222,9 @@ func (f *featureGate) SetFromMap(m map[string]bool) {
 	defer f.lock.Unlock()
 
 	for k, v := range m {
+		if _, ok := f.known[k]; !ok && len(f.known) > 0 {
+			klog.Warningf("unknown feature %s", k)
+		}
 		f.enabledFeatures[k] = v
 	}
 
@@ -124,13 +233,21 @@ func (f *featureGate) SetFromMap(m map[string]bool) {
 
 func NewFeatureGate() FeatureGate {
 	f := &featureGate{
+		known:           make(map[string]FeatureSpec),
 		enabledFeatures: make(map[string]bool),
 	}
 	return f
 }
 
 func NewDefaultFeatureGate() FeatureGate {
-	f := NewFeatureGate()
-	f.SetFromMap(defaultFeatures)
+	f := &featureGate{
+		known:           knownFeatures,
+		enabledFeatures: make(map[string]bool),
+	}
+	defaults := make(map[string]bool, len(knownFeatures))
+	for k, v := range knownFeatures {
+		defaults[k] = v.Default || v.PreRelease == GA
+	}
+	f.SetFromMap(defaults)
 	return f
 }
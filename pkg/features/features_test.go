@@ -13,7 +13,10 @@
 
 package features
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestSet(t *testing.T) {
 	tests := []struct {
@@ -105,3 +108,65 @@ func TestEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestSetGA(t *testing.T) {
+	gates := &featureGate{
+		known: map[string]FeatureSpec{
+			"a": {Default: true, PreRelease: GA},
+			"b": {Default: false, PreRelease: Alpha},
+		},
+		enabledFeatures: map[string]bool{},
+	}
+	if err := gates.Set("a=false"); err == nil {
+		t.Errorf("want error on disabling GA feature")
+	}
+	if err := gates.Set("a=true,b=true"); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+	if got := gates.String(); got != "a=true,b=true" {
+		t.Errorf("want: a=true,b=true, got %s", got)
+	}
+}
+
+func TestReload(t *testing.T) {
+	gates := &featureGate{
+		known: map[string]FeatureSpec{
+			"a": {PreRelease: Alpha},
+			"b": {PreRelease: Alpha, Dynamic: true},
+		},
+		enabledFeatures: map[string]bool{"a": false, "b": false},
+	}
+
+	// only the dynamic feature is changed
+	changes, err := gates.Reload("", "a=true,b=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FeatureChange{
+		{Feature: "a", Old: false, New: true},
+		{Feature: "b", Old: false, New: true, Applied: true},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("want: %v, got %v", want, changes)
+	}
+	if got := gates.String(); got != "a=false,b=true" {
+		t.Errorf("want: a=false,b=true, got %s", got)
+	}
+
+	// the removed dynamic feature is reset to its default
+	changes, err = gates.Reload("a=true,b=true", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []FeatureChange{{Feature: "b", Old: true, New: false, Applied: true}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("want: %v, got %v", want, changes)
+	}
+	if got := gates.String(); got != "a=false,b=false" {
+		t.Errorf("want: a=false,b=false, got %s", got)
+	}
+
+	if _, err := gates.Reload("", "b=maybe"); err == nil {
+		t.Errorf("want error for the invalid value")
+	}
+}
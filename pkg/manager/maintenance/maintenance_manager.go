@@ -0,0 +1,394 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	"github.com/robfig/cron"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	tikvServerPort = 20160
+
+	defaultCompactDB           = "kv"
+	defaultCompactColumnFamily = "default"
+)
+
+// defaultFlashbackScheduleConfig is the PD defaults of the schedule config
+// items which are disabled by TiDB during `FLASHBACK CLUSTER`
+var defaultFlashbackScheduleConfig = map[string]interface{}{
+	"merge-schedule-limit":        8,
+	"max-snapshot-count":          64,
+	"max-pending-peer-count":      64,
+	"enable-location-replacement": true,
+}
+
+type nowFn func() time.Time
+
+type maintenanceManager struct {
+	deps *controller.Dependencies
+	now  nowFn
+}
+
+// NewMaintenanceManager returns a manager which runs the task of TidbClusterMaintenance
+func NewMaintenanceManager(deps *controller.Dependencies) manager.TiDBClusterMaintenanceManager {
+	return &maintenanceManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (m *maintenanceManager) Sync(tcm *v1alpha1.TidbClusterMaintenance, tc *v1alpha1.TidbCluster) error {
+	if tcm.Status.Phase != v1alpha1.MaintenancePhaseRunning {
+		start, err := m.shouldStart(tcm)
+		if err != nil {
+			return err
+		}
+		if !start {
+			if tcm.Status.Phase == "" {
+				tcm.Status.Phase = v1alpha1.MaintenancePhasePending
+			}
+			return nil
+		}
+		klog.Infof("TidbClusterMaintenance %s/%s starts %s task", tcm.Namespace, tcm.Name, tcm.Spec.Type)
+		tcm.Status.Phase = v1alpha1.MaintenancePhaseRunning
+		tcm.Status.LastScheduleTime = &metav1.Time{Time: m.now()}
+		tcm.Status.CompletionTime = nil
+		tcm.Status.Progress = ""
+		tcm.Status.Message = ""
+		tcm.Status.Stores = nil
+		tcm.Status.PDMembers = nil
+	}
+
+	switch tcm.Spec.Type {
+	case v1alpha1.MaintenanceTypeCompact:
+		return m.syncCompact(tcm, tc)
+	case v1alpha1.MaintenanceTypeScatterRegions:
+		return m.syncScatterRegions(tcm, tc)
+	case v1alpha1.MaintenanceTypeFlashbackCleanup:
+		return m.syncFlashbackCleanup(tcm, tc)
+	case v1alpha1.MaintenanceTypePDDefrag:
+		return m.syncPDDefrag(tcm, tc)
+	default:
+		m.finish(tcm, v1alpha1.MaintenancePhaseFailed, fmt.Sprintf("unknown maintenance type %q", tcm.Spec.Type))
+		return nil
+	}
+}
+
+// shouldStart returns whether a new run of the task should be started
+func (m *maintenanceManager) shouldStart(tcm *v1alpha1.TidbClusterMaintenance) (bool, error) {
+	if tcm.Spec.Schedule == "" {
+		return tcm.Status.LastScheduleTime == nil, nil
+	}
+	if tcm.Spec.Pause {
+		return false, nil
+	}
+
+	sched, err := cron.ParseStandard(tcm.Spec.Schedule)
+	if err != nil {
+		return false, fmt.Errorf("parse TidbClusterMaintenance %s/%s cron format %s failed, err: %v", tcm.Namespace, tcm.Name, tcm.Spec.Schedule, err)
+	}
+
+	earliestTime := tcm.CreationTimestamp.Time
+	if tcm.Status.LastScheduleTime != nil {
+		earliestTime = tcm.Status.LastScheduleTime.Time
+	}
+	return !sched.Next(earliestTime).After(m.now()), nil
+}
+
+func (m *maintenanceManager) finish(tcm *v1alpha1.TidbClusterMaintenance, phase v1alpha1.MaintenancePhase, message string) {
+	tcm.Status.Phase = phase
+	tcm.Status.Message = message
+	tcm.Status.CompletionTime = &metav1.Time{Time: m.now()}
+	klog.Infof("TidbClusterMaintenance %s/%s %s task finished, phase: %s, message: %s", tcm.Namespace, tcm.Name, tcm.Spec.Type, phase, message)
+}
+
+func (m *maintenanceManager) syncScatterRegions(tcm *v1alpha1.TidbClusterMaintenance, tc *v1alpha1.TidbCluster) error {
+	var startKey, endKey string
+	if opts := tcm.Spec.ScatterRegions; opts != nil {
+		startKey, endKey = opts.StartKey, opts.EndKey
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	if err := pdClient.ScatterRegions(startKey, endKey); err != nil {
+		tcm.Status.Message = err.Error()
+		return err
+	}
+	m.finish(tcm, v1alpha1.MaintenancePhaseComplete, "")
+	return nil
+}
+
+func (m *maintenanceManager) syncFlashbackCleanup(tcm *v1alpha1.TidbClusterMaintenance, tc *v1alpha1.TidbCluster) error {
+	config := make(map[string]interface{}, len(defaultFlashbackScheduleConfig))
+	for k, v := range defaultFlashbackScheduleConfig {
+		config[k] = v
+	}
+	if opts := tcm.Spec.FlashbackCleanup; opts != nil {
+		for k, v := range opts.ScheduleConfig {
+			config[k] = pdapi.ParseConfigValue(v)
+		}
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	if err := pdClient.UpdateScheduleConfig(config); err != nil {
+		tcm.Status.Message = err.Error()
+		return err
+	}
+	m.finish(tcm, v1alpha1.MaintenancePhaseComplete, "")
+	return nil
+}
+
+func (m *maintenanceManager) syncCompact(tcm *v1alpha1.TidbClusterMaintenance, tc *v1alpha1.TidbCluster) error {
+	if tcm.Status.Stores == nil {
+		stores, err := compactTargets(tcm, tc)
+		if err != nil {
+			m.finish(tcm, v1alpha1.MaintenancePhaseFailed, err.Error())
+			return nil
+		}
+		tcm.Status.Stores = stores
+	}
+
+	podNames := make([]string, 0, len(tcm.Status.Stores))
+	for podName := range tcm.Status.Stores {
+		podNames = append(podNames, podName)
+	}
+	sort.Strings(podNames)
+
+	// compact the stores one by one to limit the impact on the cluster
+	for _, podName := range podNames {
+		status := tcm.Status.Stores[podName]
+		switch status.Phase {
+		case v1alpha1.MaintenancePhaseComplete, v1alpha1.MaintenancePhaseFailed:
+			continue
+		case v1alpha1.MaintenancePhaseRunning:
+			done, err := m.checkCompactJob(tcm, podName, &status)
+			tcm.Status.Stores[podName] = status
+			if err != nil || !done {
+				updateCompactProgress(tcm)
+				return err
+			}
+			continue
+		default:
+			store, ok := tc.Status.TiKV.Stores[status.StoreID]
+			if !ok {
+				status.Phase = v1alpha1.MaintenancePhaseFailed
+				status.Message = fmt.Sprintf("store %s is not found", status.StoreID)
+				tcm.Status.Stores[podName] = status
+				continue
+			}
+			job := m.makeCompactJob(tcm, tc, podName, store.IP)
+			if err := m.deps.JobControl.CreateJob(tcm, job); err != nil && !errors.IsAlreadyExists(err) {
+				return err
+			}
+			status.Phase = v1alpha1.MaintenancePhaseRunning
+			tcm.Status.Stores[podName] = status
+			updateCompactProgress(tcm)
+			return controller.RequeueErrorf("TidbClusterMaintenance %s/%s is compacting store %s", tcm.Namespace, tcm.Name, podName)
+		}
+	}
+
+	updateCompactProgress(tcm)
+	var failed []string
+	for _, podName := range podNames {
+		if tcm.Status.Stores[podName].Phase == v1alpha1.MaintenancePhaseFailed {
+			failed = append(failed, podName)
+		}
+	}
+	if len(failed) > 0 {
+		m.finish(tcm, v1alpha1.MaintenancePhaseFailed, fmt.Sprintf("failed to compact stores %v", failed))
+	} else {
+		m.finish(tcm, v1alpha1.MaintenancePhaseComplete, "")
+	}
+	return nil
+}
+
+// checkCompactJob checks the compaction job of the store and returns whether it is finished
+func (m *maintenanceManager) checkCompactJob(tcm *v1alpha1.TidbClusterMaintenance, podName string, status *v1alpha1.MaintenanceStoreStatus) (bool, error) {
+	jobName := compactJobName(tcm, podName)
+	job, err := m.deps.JobLister.Jobs(tcm.Namespace).Get(jobName)
+	if errors.IsNotFound(err) {
+		// the job is lost, compact the store again
+		status.Phase = v1alpha1.MaintenancePhasePending
+		return false, controller.RequeueErrorf("compaction job %s/%s is not found", tcm.Namespace, jobName)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			status.Phase = v1alpha1.MaintenancePhaseComplete
+			status.Message = ""
+		case batchv1.JobFailed:
+			status.Phase = v1alpha1.MaintenancePhaseFailed
+			status.Message = fmt.Sprintf("compaction job %s failed: %s", jobName, cond.Message)
+		default:
+			continue
+		}
+		// remove the finished job so that the next run of the task can create it again
+		if err := m.deps.JobControl.DeleteJob(tcm, job); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, controller.RequeueErrorf("TidbClusterMaintenance %s/%s is compacting store %s", tcm.Namespace, tcm.Name, podName)
+}
+
+// compactTargets returns the stores to compact keyed by the pod name
+func compactTargets(tcm *v1alpha1.TidbClusterMaintenance, tc *v1alpha1.TidbCluster) (map[string]v1alpha1.MaintenanceStoreStatus, error) {
+	storeIDs := map[string]string{}
+	for id, store := range tc.Status.TiKV.Stores {
+		storeIDs[store.PodName] = id
+	}
+
+	targets := map[string]v1alpha1.MaintenanceStoreStatus{}
+	if tcm.Spec.Compact != nil && len(tcm.Spec.Compact.Stores) > 0 {
+		for _, podName := range tcm.Spec.Compact.Stores {
+			id, ok := storeIDs[podName]
+			if !ok {
+				return nil, fmt.Errorf("store of pod %s is not found in TidbCluster %s/%s", podName, tc.Namespace, tc.Name)
+			}
+			targets[podName] = v1alpha1.MaintenanceStoreStatus{StoreID: id, Phase: v1alpha1.MaintenancePhasePending}
+		}
+		return targets, nil
+	}
+
+	for podName, id := range storeIDs {
+		if tc.Status.TiKV.Stores[id].State != v1alpha1.TiKVStateUp {
+			continue
+		}
+		targets[podName] = v1alpha1.MaintenanceStoreStatus{StoreID: id, Phase: v1alpha1.MaintenancePhasePending}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no TiKV store is up in TidbCluster %s/%s", tc.Namespace, tc.Name)
+	}
+	return targets, nil
+}
+
+func updateCompactProgress(tcm *v1alpha1.TidbClusterMaintenance) {
+	var finished int
+	for _, status := range tcm.Status.Stores {
+		if status.Phase == v1alpha1.MaintenancePhaseComplete || status.Phase == v1alpha1.MaintenancePhaseFailed {
+			finished++
+		}
+	}
+	tcm.Status.Progress = fmt.Sprintf("%d/%d", finished, len(tcm.Status.Stores))
+}
+
+func compactJobName(tcm *v1alpha1.TidbClusterMaintenance, podName string) string {
+	return fmt.Sprintf("%s-compact-%s", tcm.Name, podName)
+}
+
+func (m *maintenanceManager) makeCompactJob(tcm *v1alpha1.TidbClusterMaintenance, tc *v1alpha1.TidbCluster, podName, host string) *batchv1.Job {
+	opts := tcm.Spec.Compact
+	if opts == nil {
+		opts = &v1alpha1.CompactOptions{}
+	}
+
+	args := []string{"--host", fmt.Sprintf("%s:%d", host, tikvServerPort)}
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if tc.IsTLSClusterEnabled() {
+		args = append(args,
+			"--ca-path", path.Join(util.ClusterClientTLSPath, corev1.ServiceAccountRootCAKey),
+			"--cert-path", path.Join(util.ClusterClientTLSPath, corev1.TLSCertKey),
+			"--key-path", path.Join(util.ClusterClientTLSPath, corev1.TLSPrivateKeyKey),
+		)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name: util.ClusterClientVolName, ReadOnly: true, MountPath: util.ClusterClientTLSPath,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: util.ClusterClientVolName, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.ClusterClientTLSSecretName(tc.Name),
+				},
+			},
+		})
+	}
+
+	db := defaultCompactDB
+	if opts.DB != "" {
+		db = opts.DB
+	}
+	cf := defaultCompactColumnFamily
+	if opts.ColumnFamily != "" {
+		cf = opts.ColumnFamily
+	}
+	args = append(args, "compact", "--db", db, "--column-family", cf)
+	if opts.Threads != nil {
+		args = append(args, "--threads", strconv.Itoa(int(*opts.Threads)))
+	}
+	if opts.BottommostLevelCompaction != "" {
+		args = append(args, "--bottommost", opts.BottommostLevelCompaction)
+	}
+
+	baseTiKVSpec := tc.BaseTiKVSpec()
+	jobLabels := label.NewMaintenance().Instance(tc.Name).Maintenance(tcm.Name)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      compactJobName(tcm, podName),
+			Namespace: tcm.Namespace,
+			Labels:    jobLabels,
+			OwnerReferences: []metav1.OwnerReference{
+				controller.GetTiDBClusterMaintenanceOwnerRef(tcm),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "compact",
+							Image:           tc.Spec.ImagePolicy.ResolveComponentImage(tc.TiKVImage(), baseTiKVSpec.NodeSelector()),
+							ImagePullPolicy: baseTiKVSpec.ImagePullPolicy(),
+							Command:         []string{"/tikv-ctl"},
+							Args:            args,
+							VolumeMounts:    volumeMounts,
+							Resources:       opts.Resources,
+						},
+					},
+					RestartPolicy:    corev1.RestartPolicyNever,
+					ImagePullSecrets: baseTiKVSpec.ImagePullSecrets(),
+					Volumes:          volumes,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaintenanceManagerSyncScatterRegions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeMaintenanceManager()
+	tc := newTidbCluster()
+	tcm := newTidbClusterMaintenance(v1alpha1.MaintenanceTypeScatterRegions)
+	tcm.Spec.ScatterRegions = &v1alpha1.ScatterRegionsOptions{StartKey: "a", EndKey: "b"}
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	var scattered []string
+	pdClient.AddReaction(pdapi.ScatterRegionsActionType, func(action *pdapi.Action) (interface{}, error) {
+		scattered = append(scattered, action.StartKey+"-"+action.EndKey)
+		return nil, nil
+	})
+
+	g.Expect(m.Sync(tcm, tc)).To(Succeed())
+	g.Expect(scattered).To(Equal([]string{"a-b"}))
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhaseComplete))
+	g.Expect(tcm.Status.LastScheduleTime).NotTo(BeNil())
+	g.Expect(tcm.Status.CompletionTime).NotTo(BeNil())
+
+	// a one-shot task never runs again
+	g.Expect(m.Sync(tcm, tc)).To(Succeed())
+	g.Expect(scattered).To(HaveLen(1))
+
+	// failures are retried
+	tcm = newTidbClusterMaintenance(v1alpha1.MaintenanceTypeScatterRegions)
+	pdClient.AddReaction(pdapi.ScatterRegionsActionType, func(action *pdapi.Action) (interface{}, error) {
+		return nil, fmt.Errorf("pd unavailable")
+	})
+	g.Expect(m.Sync(tcm, tc)).NotTo(Succeed())
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhaseRunning))
+	g.Expect(tcm.Status.Message).To(ContainSubstring("pd unavailable"))
+}
+
+func TestMaintenanceManagerSyncSchedule(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeMaintenanceManager()
+	tc := newTidbCluster()
+	tcm := newTidbClusterMaintenance(v1alpha1.MaintenanceTypeFlashbackCleanup)
+	tcm.Spec.Schedule = "0 * * * *"
+	tcm.Spec.FlashbackCleanup = &v1alpha1.FlashbackCleanupOptions{
+		ScheduleConfig: map[string]string{"merge-schedule-limit": "16"},
+	}
+	created := time.Date(2022, 1, 1, 0, 30, 0, 0, time.UTC)
+	tcm.CreationTimestamp = metav1.Time{Time: created}
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	var config map[string]interface{}
+	pdClient.AddReaction(pdapi.UpdateScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		config = action.Config
+		return nil, nil
+	})
+
+	m.now = func() time.Time { return created.Add(10 * time.Minute) }
+	g.Expect(m.Sync(tcm, tc)).To(Succeed())
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhasePending))
+	g.Expect(config).To(BeNil())
+
+	m.now = func() time.Time { return created.Add(40 * time.Minute) }
+	g.Expect(m.Sync(tcm, tc)).To(Succeed())
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhaseComplete))
+	g.Expect(config).To(HaveKeyWithValue("merge-schedule-limit", int64(16)))
+	g.Expect(config).To(HaveKeyWithValue("enable-location-replacement", true))
+
+	tcm.Spec.Pause = true
+	config = nil
+	m.now = func() time.Time { return created.Add(100 * time.Minute) }
+	g.Expect(m.Sync(tcm, tc)).To(Succeed())
+	g.Expect(config).To(BeNil())
+}
+
+func TestMaintenanceManagerSyncCompact(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeMaintenanceManager()
+	tc := newTidbCluster()
+	tcm := newTidbClusterMaintenance(v1alpha1.MaintenanceTypeCompact)
+	jobIndexer := deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer()
+
+	completeJob := func(podName string, condType batchv1.JobConditionType) {
+		obj, exist, err := jobIndexer.GetByKey(fmt.Sprintf("%s/%s", tcm.Namespace, compactJobName(tcm, podName)))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(exist).To(BeTrue())
+		job := obj.(*batchv1.Job).DeepCopy()
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condType, Status: corev1.ConditionTrue}}
+		g.Expect(jobIndexer.Update(job)).To(Succeed())
+	}
+
+	err := m.Sync(tcm, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhaseRunning))
+	g.Expect(tcm.Status.Stores).To(HaveLen(2))
+	g.Expect(tcm.Status.Stores["test-tikv-0"].Phase).To(Equal(v1alpha1.MaintenancePhaseRunning))
+	g.Expect(tcm.Status.Stores["test-tikv-1"].Phase).To(Equal(v1alpha1.MaintenancePhasePending))
+	g.Expect(tcm.Status.Progress).To(Equal("0/2"))
+
+	obj, exist, err := jobIndexer.GetByKey(fmt.Sprintf("%s/%s", tcm.Namespace, compactJobName(tcm, "test-tikv-0")))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeTrue())
+	job := obj.(*batchv1.Job)
+	g.Expect(job.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{
+		"--host", "test-tikv-0.test-tikv-peer.default.svc:20160", "compact", "--db", "kv", "--column-family", "default",
+	}))
+
+	// the job of the first store is still running
+	err = m.Sync(tcm, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcm.Status.Stores["test-tikv-1"].Phase).To(Equal(v1alpha1.MaintenancePhasePending))
+
+	completeJob("test-tikv-0", batchv1.JobComplete)
+	err = m.Sync(tcm, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcm.Status.Stores["test-tikv-0"].Phase).To(Equal(v1alpha1.MaintenancePhaseComplete))
+	g.Expect(tcm.Status.Stores["test-tikv-1"].Phase).To(Equal(v1alpha1.MaintenancePhaseRunning))
+	g.Expect(tcm.Status.Progress).To(Equal("1/2"))
+
+	completeJob("test-tikv-1", batchv1.JobFailed)
+	g.Expect(m.Sync(tcm, tc)).To(Succeed())
+	g.Expect(tcm.Status.Stores["test-tikv-1"].Phase).To(Equal(v1alpha1.MaintenancePhaseFailed))
+	g.Expect(tcm.Status.Progress).To(Equal("2/2"))
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhaseFailed))
+	g.Expect(tcm.Status.Message).To(ContainSubstring("test-tikv-1"))
+}
+
+func newFakeMaintenanceManager() (*maintenanceManager, *controller.Dependencies) {
+	deps := controller.NewFakeDependencies()
+	return &maintenanceManager{deps: deps, now: time.Now}, deps
+}
+
+func newTidbCluster() *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{
+				BaseImage: "pingcap/tikv",
+			},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "test-tikv-0", IP: "test-tikv-0.test-tikv-peer.default.svc", State: v1alpha1.TiKVStateUp},
+					"2": {ID: "2", PodName: "test-tikv-1", IP: "test-tikv-1.test-tikv-peer.default.svc", State: v1alpha1.TiKVStateUp},
+					"3": {ID: "3", PodName: "test-tikv-2", IP: "test-tikv-2.test-tikv-peer.default.svc", State: v1alpha1.TiKVStateDown},
+				},
+			},
+		},
+	}
+}
+
+func newTidbClusterMaintenance(typ v1alpha1.MaintenanceType) *v1alpha1.TidbClusterMaintenance {
+	return &v1alpha1.TidbClusterMaintenance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "maintenance",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: v1alpha1.TidbClusterMaintenanceSpec{
+			Cluster: v1alpha1.TidbClusterRef{Name: "test"},
+			Type:    typ,
+		},
+	}
+}
@@ -0,0 +1,386 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+type nodeMaintenanceManager struct {
+	deps *controller.Dependencies
+}
+
+// NewNodeMaintenanceManager returns a manager which prepares the TiDB clusters of all
+// namespaces synced by the operator for the maintenance of a node:
+//
+//   - the PD leader is transferred to a healthy member out of the node
+//   - the region leaders are evicted from the TiKV stores on the node
+//   - if spec.reschedulePods is true, the pods on the node are deleted one by one
+//     after the node is cordoned
+//
+// The eviction of leaders is ended when the TiKV pod is moved out of the node or
+// the NodeMaintenance is deleted. Only the evictions started by the NodeMaintenance
+// are ended, the ones started by others, e.g. the TiKV upgrader or another
+// NodeMaintenance of the node, are left to them.
+func NewNodeMaintenanceManager(deps *controller.Dependencies) manager.NodeMaintenanceManager {
+	return &nodeMaintenanceManager{
+		deps: deps,
+	}
+}
+
+func (m *nodeMaintenanceManager) Sync(nm *v1alpha1.NodeMaintenance) error {
+	if nm.DeletionTimestamp != nil {
+		return m.complete(nm)
+	}
+
+	podsOnNode, err := m.podsOnNode(nm)
+	if err != nil {
+		return err
+	}
+	if err := m.endEvictionOfMovedStores(nm, podsOnNode); err != nil {
+		return err
+	}
+
+	var waiting []string
+	for _, key := range sortedClusterKeys(podsOnNode) {
+		tc, err := m.getTidbCluster(key)
+		if err != nil {
+			return err
+		}
+		if tc == nil {
+			continue
+		}
+		pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+		onNode := sets.NewString()
+		for _, pod := range podsOnNode[key] {
+			onNode.Insert(pod.Name)
+		}
+
+		for _, pod := range podsOnNode[key] {
+			var done bool
+			switch pod.Labels[label.ComponentLabelKey] {
+			case label.PDLabelVal:
+				done, err = m.transferPDLeader(tc, pdClient, pod, onNode)
+			case label.TiKVLabelVal:
+				done, err = m.evictTiKVLeaders(nm, tc, pdClient, pod)
+			default:
+				continue
+			}
+			if err != nil {
+				nm.Status.Message = err.Error()
+				return err
+			}
+			if !done {
+				waiting = append(waiting, podKey(pod.Namespace, pod.Name))
+			}
+		}
+	}
+
+	if len(waiting) > 0 {
+		nm.Status.Phase = v1alpha1.NodeMaintenancePhasePreparing
+		nm.Status.Message = fmt.Sprintf("waiting for the leaders to be moved out of pods %v", waiting)
+		return controller.RequeueErrorf("NodeMaintenance %s/%s is moving leaders out of node %s", nm.Namespace, nm.Name, nm.Spec.NodeName)
+	}
+
+	if nm.Spec.ReschedulePods {
+		done, err := m.reschedulePods(nm, podsOnNode)
+		if err != nil || !done {
+			nm.Status.Phase = v1alpha1.NodeMaintenancePhasePreparing
+			return err
+		}
+	}
+
+	nm.Status.Phase = v1alpha1.NodeMaintenancePhaseReady
+	nm.Status.Message = ""
+	return nil
+}
+
+// podsOnNode returns the running pods of the TiDB clusters on the node keyed by the
+// namespace and name of the cluster
+func (m *nodeMaintenanceManager) podsOnNode(nm *v1alpha1.NodeMaintenance) (map[string][]*corev1.Pod, error) {
+	selector, err := label.New().Selector()
+	if err != nil {
+		return nil, err
+	}
+	// the node is shared by the clusters of all namespaces
+	pods, err := m.deps.PodLister.List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods for NodeMaintenance %s/%s: %v", nm.Namespace, nm.Name, err)
+	}
+
+	podsOnNode := map[string][]*corev1.Pod{}
+	for _, pod := range pods {
+		tcName := pod.Labels[label.InstanceLabelKey]
+		if pod.Spec.NodeName != nm.Spec.NodeName || tcName == "" || !m.deps.NamespaceSelector.Selected(pod.Namespace) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		key := podKey(pod.Namespace, tcName)
+		podsOnNode[key] = append(podsOnNode[key], pod)
+	}
+	for _, pods := range podsOnNode {
+		sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	}
+	return podsOnNode, nil
+}
+
+func sortedClusterKeys(podsOnNode map[string][]*corev1.Pod) []string {
+	keys := make([]string, 0, len(podsOnNode))
+	for key := range podsOnNode {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// podKey returns the key of a pod or a cluster in the status of NodeMaintenance
+func podKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// getTidbCluster returns the TidbCluster of the key, it returns nil if the TidbCluster is deleted
+func (m *nodeMaintenanceManager) getTidbCluster(key string) (*v1alpha1.TidbCluster, error) {
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+	tc, err := m.deps.TiDBClusterLister.TidbClusters(ns).Get(name)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return tc, err
+}
+
+// transferPDLeader transfers the PD leader out of the node, and returns whether the leader is not on the pod
+func (m *nodeMaintenanceManager) transferPDLeader(tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient, pod *corev1.Pod, onNode sets.String) (bool, error) {
+	leader, err := pdClient.GetPDLeader()
+	if err != nil {
+		return false, fmt.Errorf("failed to get PD leader of TidbCluster %s/%s: %v", tc.Namespace, tc.Name, err)
+	}
+	if pdMemberPodName(leader.GetName()) != pod.Name {
+		return true, nil
+	}
+
+	names := make([]string, 0, len(tc.Status.PD.Members))
+	for name := range tc.Status.PD.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !tc.Status.PD.Members[name].Health || onNode.Has(pdMemberPodName(name)) {
+			continue
+		}
+		klog.Infof("NodeMaintenance: transfer PD leader of TidbCluster %s/%s from %s to %s", tc.Namespace, tc.Name, leader.GetName(), name)
+		if err := pdClient.TransferPDLeader(name); err != nil {
+			return false, fmt.Errorf("failed to transfer PD leader of TidbCluster %s/%s to %s: %v", tc.Namespace, tc.Name, name, err)
+		}
+		return false, nil
+	}
+	klog.Warningf("NodeMaintenance: no healthy PD member of TidbCluster %s/%s out of the node to transfer the leader to", tc.Namespace, tc.Name)
+	return false, nil
+}
+
+// pdMemberPodName returns the pod name of the PD member, the member name is
+// the pod name or the FQDN of the pod if the cluster domain is set
+func pdMemberPodName(memberName string) string {
+	return strings.SplitN(memberName, ".", 2)[0]
+}
+
+// evictTiKVLeaders evicts the region leaders from the TiKV store, and returns whether all leaders are evicted
+func (m *nodeMaintenanceManager) evictTiKVLeaders(nm *v1alpha1.NodeMaintenance, tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient, pod *corev1.Pod) (bool, error) {
+	var storeID string
+	for id, store := range tc.Status.TiKV.Stores {
+		if store.PodName == pod.Name {
+			storeID = id
+			break
+		}
+	}
+	if storeID == "" {
+		// the store is not started yet
+		return true, nil
+	}
+	id, err := strconv.ParseUint(storeID, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid store id %q of pod %s/%s", storeID, pod.Namespace, pod.Name)
+	}
+
+	key := podKey(pod.Namespace, pod.Name)
+	started := nm.Status.EvictedStores[key].StartedEviction
+	schedulers, err := pdClient.GetEvictLeaderSchedulersForStores(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get evict leader scheduler for store %d (Pod %s/%s): %v", id, pod.Namespace, pod.Name, err)
+	}
+	// the eviction started by others is not started again, it's also started if it's ended by others
+	if _, ok := schedulers[id]; !ok {
+		if err := pdClient.BeginEvictLeader(id); err != nil {
+			return false, fmt.Errorf("failed to evict leader for store %d (Pod %s/%s): %v", id, pod.Namespace, pod.Name, err)
+		}
+		started = true
+	}
+	store, err := pdClient.GetStore(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get store %d (Pod %s/%s): %v", id, pod.Namespace, pod.Name, err)
+	}
+	var leaderCount int32
+	if store.Status != nil {
+		leaderCount = int32(store.Status.LeaderCount)
+	}
+
+	if nm.Status.EvictedStores == nil {
+		nm.Status.EvictedStores = map[string]v1alpha1.NodeMaintenanceStore{}
+	}
+	nm.Status.EvictedStores[key] = v1alpha1.NodeMaintenanceStore{
+		Namespace:       tc.Namespace,
+		Cluster:         tc.Name,
+		StoreID:         storeID,
+		LeaderCount:     leaderCount,
+		StartedEviction: started,
+	}
+	return leaderCount == 0, nil
+}
+
+// endEvictionOfMovedStores ends the eviction of leaders for the TiKV pods which are moved out of the node
+func (m *nodeMaintenanceManager) endEvictionOfMovedStores(nm *v1alpha1.NodeMaintenance, podsOnNode map[string][]*corev1.Pod) error {
+	onNode := sets.NewString()
+	for _, pods := range podsOnNode {
+		for _, pod := range pods {
+			onNode.Insert(podKey(pod.Namespace, pod.Name))
+		}
+	}
+	for key := range nm.Status.EvictedStores {
+		if onNode.Has(key) {
+			continue
+		}
+		if err := m.endEviction(nm, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// complete ends the eviction of leaders for all stores evicted by the NodeMaintenance
+func (m *nodeMaintenanceManager) complete(nm *v1alpha1.NodeMaintenance) error {
+	nm.Status.Phase = v1alpha1.NodeMaintenancePhaseCompleting
+	keys := make([]string, 0, len(nm.Status.EvictedStores))
+	for key := range nm.Status.EvictedStores {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := m.endEviction(nm, key); err != nil {
+			nm.Status.Message = err.Error()
+			return err
+		}
+	}
+	nm.Status.Message = ""
+	return nil
+}
+
+// endEviction ends the eviction of leaders for the store of the pod if it's started by the NodeMaintenance
+func (m *nodeMaintenanceManager) endEviction(nm *v1alpha1.NodeMaintenance, key string) error {
+	store := nm.Status.EvictedStores[key]
+	if !store.StartedEviction {
+		delete(nm.Status.EvictedStores, key)
+		return nil
+	}
+	tc, err := m.getTidbCluster(podKey(store.Namespace, store.Cluster))
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(store.StoreID, 10, 64)
+	if tc == nil || err != nil {
+		delete(nm.Status.EvictedStores, key)
+		return nil
+	}
+	if err := controller.GetPDClient(m.deps.PDControl, tc).EndEvictLeader(id); err != nil {
+		return fmt.Errorf("failed to end evicting leader for store %d (Pod %s): %v", id, key, err)
+	}
+	klog.Infof("NodeMaintenance %s/%s: end evicting leader for store %d (Pod %s)", nm.Namespace, nm.Name, id, key)
+	delete(nm.Status.EvictedStores, key)
+	return nil
+}
+
+// reschedulePods deletes the pods on the node one by one after the node is cordoned,
+// it returns whether all pods are moved out of the node
+func (m *nodeMaintenanceManager) reschedulePods(nm *v1alpha1.NodeMaintenance, podsOnNode map[string][]*corev1.Pod) (bool, error) {
+	// wait for the pods deleted before to be recreated
+	for _, key := range nm.Status.RescheduledPods {
+		ns, podName, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return false, err
+		}
+		pod, err := m.deps.PodLister.Pods(ns).Get(podName)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		if pod == nil || pod.Spec.NodeName == nm.Spec.NodeName || !podutil.IsPodReady(pod) {
+			nm.Status.Message = fmt.Sprintf("waiting for pod %s to be ready", key)
+			return false, controller.RequeueErrorf("NodeMaintenance %s/%s is waiting for pod %s to be ready", nm.Namespace, nm.Name, key)
+		}
+	}
+
+	if len(podsOnNode) == 0 {
+		return true, nil
+	}
+	if m.deps.NodeLister == nil {
+		nm.Status.Message = "the permission of reading nodes is required to reschedule pods"
+		return false, nil
+	}
+	node, err := m.deps.NodeLister.Get(nm.Spec.NodeName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get node %s: %v", nm.Spec.NodeName, err)
+	}
+	if !node.Spec.Unschedulable {
+		nm.Status.Message = fmt.Sprintf("waiting for node %s to be cordoned", nm.Spec.NodeName)
+		return false, controller.RequeueErrorf("NodeMaintenance %s/%s is waiting for node %s to be cordoned", nm.Namespace, nm.Name, nm.Spec.NodeName)
+	}
+
+	clusterKey := sortedClusterKeys(podsOnNode)[0]
+	tc, err := m.getTidbCluster(clusterKey)
+	if err != nil || tc == nil {
+		return false, err
+	}
+	pod := podsOnNode[clusterKey][0]
+	key := podKey(pod.Namespace, pod.Name)
+	klog.Infof("NodeMaintenance %s/%s: delete pod %s to reschedule it out of node %s", nm.Namespace, nm.Name, key, nm.Spec.NodeName)
+	if err := m.deps.PodControl.DeletePod(tc, pod); err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to delete pod %s: %v", key, err)
+	}
+	// the pods of Deployments are recreated with new names, so only the pods of
+	// StatefulSets are waited for
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "StatefulSet" {
+		nm.Status.RescheduledPods = append(nm.Status.RescheduledPods, key)
+	}
+	nm.Status.Message = fmt.Sprintf("rescheduling pod %s", key)
+	return false, controller.RequeueErrorf("NodeMaintenance %s/%s is rescheduling pod %s", nm.Namespace, nm.Name, key)
+}
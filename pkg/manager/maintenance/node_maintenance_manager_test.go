@@ -0,0 +1,210 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestNodeMaintenanceManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewNodeMaintenanceManager(deps)
+	tc := newTidbCluster()
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+		"test-pd-0": {Name: "test-pd-0", Health: true},
+		"test-pd-1": {Name: "test-pd-1", Health: true},
+	}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	g.Expect(podIndexer.Add(newNodeMaintenancePod("test-pd-0", label.PDLabelVal, "node-1"))).To(Succeed())
+	g.Expect(podIndexer.Add(newNodeMaintenancePod("test-tikv-0", label.TiKVLabelVal, "node-1"))).To(Succeed())
+	g.Expect(podIndexer.Add(newNodeMaintenancePod("test-tikv-1", label.TiKVLabelVal, "node-2"))).To(Succeed())
+	// the cluster in another namespace whose store on the node is evicted by others
+	otherTc := newTidbCluster()
+	otherTc.Namespace = "other"
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(otherTc)).To(Succeed())
+	otherPod := newNodeMaintenancePod("test-tikv-0", label.TiKVLabelVal, "node-1")
+	otherPod.Namespace = otherTc.Namespace
+	g.Expect(podIndexer.Add(otherPod)).To(Succeed())
+	nm := newNodeMaintenance("node-1")
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	leader := "test-pd-0"
+	pdClient.AddReaction(pdapi.GetPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdpb.Member{Name: leader}, nil
+	})
+	var transferredTo string
+	pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		transferredTo = action.Name
+		return nil, nil
+	})
+	var evicted, ended []uint64
+	schedulers := map[uint64]string{}
+	pdClient.AddReaction(pdapi.GetEvictLeaderSchedulersActionType, func(action *pdapi.Action) (interface{}, error) {
+		return schedulers, nil
+	})
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		evicted = append(evicted, action.ID)
+		schedulers[action.ID] = "evict-leader-scheduler"
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		ended = append(ended, action.ID)
+		delete(schedulers, action.ID)
+		return nil, nil
+	})
+	leaderCount := 10
+	pdClient.AddReaction(pdapi.GetStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoreInfo{Status: &pdapi.StoreStatus{LeaderCount: leaderCount}}, nil
+	})
+
+	otherPDClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), otherTc)
+	otherPDClient.AddReaction(pdapi.GetEvictLeaderSchedulersActionType, func(action *pdapi.Action) (interface{}, error) {
+		return map[uint64]string{1: "evict-leader-scheduler"}, nil
+	})
+	var otherChanged []uint64
+	otherPDClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		otherChanged = append(otherChanged, action.ID)
+		return nil, nil
+	})
+	otherPDClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		otherChanged = append(otherChanged, action.ID)
+		return nil, nil
+	})
+	otherPDClient.AddReaction(pdapi.GetStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoreInfo{Status: &pdapi.StoreStatus{LeaderCount: 0}}, nil
+	})
+
+	// the leaders are being moved
+	err := m.Sync(nm)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(nm.Status.Phase).To(Equal(v1alpha1.NodeMaintenancePhasePreparing))
+	g.Expect(transferredTo).To(Equal("test-pd-1"))
+	g.Expect(evicted).To(Equal([]uint64{1}))
+	g.Expect(nm.Status.EvictedStores).To(Equal(map[string]v1alpha1.NodeMaintenanceStore{
+		"default/test-tikv-0": {Namespace: "default", Cluster: "test", StoreID: "1", LeaderCount: 10, StartedEviction: true},
+		"other/test-tikv-0":   {Namespace: "other", Cluster: "test", StoreID: "1", LeaderCount: 0},
+	}))
+
+	// the eviction is not started again
+	g.Expect(controller.IsRequeueError(m.Sync(nm))).To(BeTrue())
+	g.Expect(evicted).To(Equal([]uint64{1}))
+	g.Expect(nm.Status.EvictedStores["default/test-tikv-0"].StartedEviction).To(BeTrue())
+
+	// the leaders are moved out of the node
+	leader, leaderCount = "test-pd-1", 0
+	g.Expect(m.Sync(nm)).To(Succeed())
+	g.Expect(nm.Status.Phase).To(Equal(v1alpha1.NodeMaintenancePhaseReady))
+	g.Expect(nm.Status.Message).To(BeEmpty())
+	g.Expect(nm.Status.EvictedStores["default/test-tikv-0"].LeaderCount).To(BeZero())
+	g.Expect(ended).To(BeEmpty())
+
+	// the eviction is ended after the NodeMaintenance is deleted
+	nm.DeletionTimestamp = &metav1.Time{}
+	g.Expect(m.Sync(nm)).To(Succeed())
+	g.Expect(nm.Status.Phase).To(Equal(v1alpha1.NodeMaintenancePhaseCompleting))
+	g.Expect(nm.Status.EvictedStores).To(BeEmpty())
+	g.Expect(ended).To(Equal([]uint64{1}))
+	// the eviction started by others is left to them
+	g.Expect(otherChanged).To(BeEmpty())
+}
+
+func TestNodeMaintenanceManagerSyncReschedulePods(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewNodeMaintenanceManager(deps)
+	tc := newTidbCluster()
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)).To(Succeed())
+	pod := newNodeMaintenancePod("test-tidb-0", label.TiDBLabelVal, "node-1")
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	g.Expect(nodeIndexer.Add(node)).To(Succeed())
+	nm := newNodeMaintenance("node-1")
+	nm.Spec.ReschedulePods = true
+
+	// waiting for the node to be cordoned
+	err := m.Sync(nm)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(nm.Status.Phase).To(Equal(v1alpha1.NodeMaintenancePhasePreparing))
+	g.Expect(nm.Status.Message).To(ContainSubstring("cordoned"))
+
+	node = node.DeepCopy()
+	node.Spec.Unschedulable = true
+	g.Expect(nodeIndexer.Update(node)).To(Succeed())
+	err = m.Sync(nm)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(nm.Status.RescheduledPods).To(Equal([]string{"default/test-tidb-0"}))
+	_, err = deps.PodLister.Pods(pod.Namespace).Get(pod.Name)
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	// waiting for the pod to be recreated
+	err = m.Sync(nm)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(nm.Status.Message).To(ContainSubstring("default/test-tidb-0"))
+
+	// the pod is ready on another node
+	pod = pod.DeepCopy()
+	pod.Spec.NodeName = "node-2"
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+	err = m.Sync(nm)
+	g.Expect(controller.IsRequeueError(err)).To(BeFalse())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(nm.Status.Phase).To(Equal(v1alpha1.NodeMaintenancePhaseReady))
+}
+
+func newNodeMaintenance(nodeName string) *v1alpha1.NodeMaintenance {
+	return &v1alpha1.NodeMaintenance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "maintenance",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: v1alpha1.NodeMaintenanceSpec{
+			NodeName: nodeName,
+		},
+	}
+}
+
+func newNodeMaintenancePod(name, component, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Labels:    label.New().Instance("test").Component(component).Labels(),
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "StatefulSet", Name: "test-" + component, Controller: pointer.BoolPtr(true)},
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+
+	"github.com/dustin/go-humanize"
+	"k8s.io/klog/v2"
+)
+
+const defaultPDDefragMinFreePercent = 10
+
+// syncPDDefrag defragments the etcd db of the PD members one by one. A member is only
+// defragmented when all PD members are healthy, and the leaders of PD and etcd are
+// defragmented after the other members, as a member can't serve any requests while
+// it is being defragmented. The run is stopped on the first failure.
+func (m *maintenanceManager) syncPDDefrag(tcm *v1alpha1.TidbClusterMaintenance, tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.PD == nil || len(tc.Status.PD.Members) == 0 {
+		m.finish(tcm, v1alpha1.MaintenancePhaseFailed, fmt.Sprintf("no PD member is found in TidbCluster %s/%s", tc.Namespace, tc.Name))
+		return nil
+	}
+
+	etcdClient, err := m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
+	if err != nil {
+		tcm.Status.Message = err.Error()
+		return err
+	}
+	defer etcdClient.Close()
+
+	if tcm.Status.PDMembers == nil {
+		members, err := pdDefragTargets(tcm, tc, etcdClient)
+		if err != nil {
+			tcm.Status.Message = err.Error()
+			return err
+		}
+		tcm.Status.PDMembers = members
+	}
+
+	var pending []string
+	for name, status := range tcm.Status.PDMembers {
+		if status.Phase != v1alpha1.MaintenancePhasePending {
+			continue
+		}
+		if _, ok := tc.Status.PD.Members[name]; !ok {
+			status.Phase = v1alpha1.MaintenancePhaseComplete
+			status.Message = "skipped, the member is removed"
+			tcm.Status.PDMembers[name] = status
+			continue
+		}
+		pending = append(pending, name)
+	}
+	sort.Strings(pending)
+	updatePDDefragProgress(tcm)
+	if len(pending) == 0 {
+		m.finish(tcm, v1alpha1.MaintenancePhaseComplete, pdDefragSummary(tcm))
+		return nil
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	if err := checkPDMembersHealthy(tc, pdClient); err != nil {
+		tcm.Status.Message = err.Error()
+		return controller.RequeueErrorf("TidbClusterMaintenance %s/%s is waiting to defragment PD: %v", tcm.Namespace, tcm.Name, err)
+	}
+	leader, err := pdClient.GetPDLeader()
+	if err != nil {
+		tcm.Status.Message = err.Error()
+		return err
+	}
+
+	// defragment the leaders last, a member is picked even if it is a leader when
+	// it is the last one
+	name := pending[0]
+	for _, n := range pending {
+		if leader != nil && leader.GetName() == n {
+			continue
+		}
+		status, err := etcdClient.Status(tc.Status.PD.Members[n].ClientURL)
+		if err != nil {
+			tcm.Status.Message = err.Error()
+			return err
+		}
+		if !status.IsLeader {
+			name = n
+			break
+		}
+	}
+
+	status := tcm.Status.PDMembers[name]
+	endpoint := tc.Status.PD.Members[name].ClientURL
+	klog.Infof("TidbClusterMaintenance %s/%s starts to defragment PD member %s", tcm.Namespace, tcm.Name, name)
+	if err := etcdClient.Defragment(endpoint); err != nil {
+		status.Phase = v1alpha1.MaintenancePhaseFailed
+		status.Message = err.Error()
+		tcm.Status.PDMembers[name] = status
+		updatePDDefragProgress(tcm)
+		m.finish(tcm, v1alpha1.MaintenancePhaseFailed, fmt.Sprintf("failed to defragment PD member %s, the remaining members are skipped", name))
+		return nil
+	}
+	if after, err := etcdClient.Status(endpoint); err == nil {
+		status.DBSizeAfter = after.DBSize
+	}
+	status.Phase = v1alpha1.MaintenancePhaseComplete
+	status.Message = ""
+	tcm.Status.PDMembers[name] = status
+	updatePDDefragProgress(tcm)
+	tcm.Status.Message = fmt.Sprintf("PD member %s is defragmented", name)
+	// wait for the member to be healthy before defragmenting the next one
+	return controller.RequeueErrorf("TidbClusterMaintenance %s/%s defragmented PD member %s", tcm.Namespace, tcm.Name, name)
+}
+
+// pdDefragTargets compacts the history if required and returns the PD members keyed by
+// the name, the members with small or unfragmented db are skipped
+func pdDefragTargets(tcm *v1alpha1.TidbClusterMaintenance, tc *v1alpha1.TidbCluster, etcdClient pdapi.PDEtcdClient) (map[string]v1alpha1.MaintenancePDMemberStatus, error) {
+	opts := tcm.Spec.PDDefrag
+	if opts == nil {
+		opts = &v1alpha1.PDDefragOptions{}
+	}
+	minFreePercent := int64(defaultPDDefragMinFreePercent)
+	if opts.MinFreePercent != nil {
+		minFreePercent = int64(*opts.MinFreePercent)
+	}
+
+	names := make([]string, 0, len(tc.Status.PD.Members))
+	for name := range tc.Status.PD.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if opts.Compact {
+		status, err := etcdClient.Status(tc.Status.PD.Members[names[0]].ClientURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := etcdClient.Compact(status.Revision); err != nil {
+			return nil, fmt.Errorf("compact PD etcd until revision %d failed: %v", status.Revision, err)
+		}
+		klog.Infof("TidbClusterMaintenance %s/%s compacted PD etcd until revision %d", tcm.Namespace, tcm.Name, status.Revision)
+	}
+
+	targets := map[string]v1alpha1.MaintenancePDMemberStatus{}
+	for _, name := range names {
+		status, err := etcdClient.Status(tc.Status.PD.Members[name].ClientURL)
+		if err != nil {
+			return nil, fmt.Errorf("get etcd status of PD member %s failed: %v", name, err)
+		}
+		target := v1alpha1.MaintenancePDMemberStatus{
+			MemberID:     tc.Status.PD.Members[name].ID,
+			Phase:        v1alpha1.MaintenancePhasePending,
+			DBSizeBefore: status.DBSize,
+		}
+		if opts.DBSizeThreshold != nil && status.DBSize < opts.DBSizeThreshold.Value() {
+			target.Phase = v1alpha1.MaintenancePhaseComplete
+			target.Message = fmt.Sprintf("skipped, the db size %s is less than %s", formatBytes(status.DBSize), opts.DBSizeThreshold.String())
+		} else if status.DBSize > 0 && status.DBSizeInUse > 0 {
+			// DBSizeInUse is not reported by etcd before v3.4
+			if free := (status.DBSize - status.DBSizeInUse) * 100 / status.DBSize; free < minFreePercent {
+				target.Phase = v1alpha1.MaintenancePhaseComplete
+				target.Message = fmt.Sprintf("skipped, only %d%% of the db is free", free)
+			}
+		}
+		targets[name] = target
+	}
+	return targets, nil
+}
+
+// checkPDMembersHealthy returns an error if PD is upgrading or scaling, or any PD member is unhealthy
+func checkPDMembersHealthy(tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient) error {
+	if tc.Status.PD.Phase != v1alpha1.NormalPhase {
+		return fmt.Errorf("PD is in %s phase", tc.Status.PD.Phase)
+	}
+	healthInfo, err := pdClient.GetHealth()
+	if err != nil {
+		return err
+	}
+	var unhealthy []string
+	for _, member := range healthInfo.Healths {
+		if !member.Health {
+			unhealthy = append(unhealthy, member.Name)
+		}
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("PD members %v are unhealthy", unhealthy)
+	}
+	return nil
+}
+
+func updatePDDefragProgress(tcm *v1alpha1.TidbClusterMaintenance) {
+	var finished int
+	for _, status := range tcm.Status.PDMembers {
+		if status.Phase == v1alpha1.MaintenancePhaseComplete || status.Phase == v1alpha1.MaintenancePhaseFailed {
+			finished++
+		}
+	}
+	tcm.Status.Progress = fmt.Sprintf("%d/%d", finished, len(tcm.Status.PDMembers))
+}
+
+// pdDefragSummary returns the space reclaimed by the defragmentation
+func pdDefragSummary(tcm *v1alpha1.TidbClusterMaintenance) string {
+	var defragmented int
+	var reclaimed int64
+	for _, status := range tcm.Status.PDMembers {
+		if status.DBSizeAfter == 0 {
+			continue
+		}
+		defragmented++
+		reclaimed += status.DBSizeBefore - status.DBSizeAfter
+	}
+	return fmt.Sprintf("%d PD members are defragmented, %s is reclaimed", defragmented, formatBytes(reclaimed))
+}
+
+func formatBytes(size int64) string {
+	if size < 0 {
+		return "-" + humanize.IBytes(uint64(-size))
+	}
+	return humanize.IBytes(uint64(size))
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+const gib = 1 << 30
+
+func newPDDefragTidbCluster() *v1alpha1.TidbCluster {
+	tc := newTidbCluster()
+	tc.Spec.PD = &v1alpha1.PDSpec{}
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{}
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("test-pd-%d", i)
+		tc.Status.PD.Members[name] = v1alpha1.PDMember{
+			Name:      name,
+			ID:        fmt.Sprintf("%d", i+1),
+			ClientURL: fmt.Sprintf("http://%s.test-pd-peer.default.svc:2379", name),
+			Health:    true,
+		}
+	}
+	return tc
+}
+
+func newFakePDEtcdClient(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) *pdapi.FakePDEtcdClient {
+	etcdClient := pdapi.NewFakePDEtcdClient()
+	for name, member := range tc.Status.PD.Members {
+		etcdClient.Statuses[member.ClientURL] = &pdapi.EtcdMemberStatus{
+			Revision:    100,
+			DBSize:      4 * gib,
+			DBSizeInUse: 1 * gib,
+			// test-pd-1 is the etcd leader
+			IsLeader: name == "test-pd-1",
+		}
+	}
+	// test-pd-3 is hardly fragmented
+	etcdClient.Statuses[tc.Status.PD.Members["test-pd-3"].ClientURL].DBSizeInUse = 3900 * (1 << 20)
+	deps.PDControl.(*pdapi.FakePDControl).SetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, etcdClient)
+	return etcdClient
+}
+
+func TestMaintenanceManagerSyncPDDefrag(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeMaintenanceManager()
+	tc := newPDDefragTidbCluster()
+	tcm := newTidbClusterMaintenance(v1alpha1.MaintenanceTypePDDefrag)
+	tcm.Spec.PDDefrag = &v1alpha1.PDDefragOptions{Compact: true}
+	etcdClient := newFakePDEtcdClient(deps, tc)
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	healthy := true
+	pdClient.AddReaction(pdapi.GetHealthActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.HealthInfo{Healths: []pdapi.MemberHealth{
+			{Name: "test-pd-0", Health: true},
+			{Name: "test-pd-1", Health: healthy},
+		}}, nil
+	})
+	// test-pd-0 is the PD leader
+	pdClient.AddReaction(pdapi.GetPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdpb.Member{Name: "test-pd-0"}, nil
+	})
+
+	// the followers are defragmented first
+	g.Expect(controller.IsRequeueError(m.Sync(tcm, tc))).To(BeTrue())
+	g.Expect(etcdClient.Compacted).To(Equal([]int64{100}))
+	g.Expect(etcdClient.Defragmented).To(Equal([]string{tc.Status.PD.Members["test-pd-2"].ClientURL}))
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhaseRunning))
+	g.Expect(tcm.Status.Progress).To(Equal("2/4"))
+	g.Expect(tcm.Status.PDMembers["test-pd-2"]).To(Equal(v1alpha1.MaintenancePDMemberStatus{
+		MemberID:     "3",
+		Phase:        v1alpha1.MaintenancePhaseComplete,
+		DBSizeBefore: 4 * gib,
+		DBSizeAfter:  1 * gib,
+	}))
+	g.Expect(tcm.Status.PDMembers["test-pd-3"].Phase).To(Equal(v1alpha1.MaintenancePhaseComplete))
+	g.Expect(tcm.Status.PDMembers["test-pd-3"].Message).To(ContainSubstring("skipped"))
+
+	// wait for the unhealthy members
+	healthy = false
+	g.Expect(controller.IsRequeueError(m.Sync(tcm, tc))).To(BeTrue())
+	g.Expect(etcdClient.Defragmented).To(HaveLen(1))
+	g.Expect(tcm.Status.Message).To(ContainSubstring("test-pd-1"))
+	healthy = true
+
+	// the leaders are defragmented last
+	g.Expect(controller.IsRequeueError(m.Sync(tcm, tc))).To(BeTrue())
+	g.Expect(controller.IsRequeueError(m.Sync(tcm, tc))).To(BeTrue())
+	g.Expect(m.Sync(tcm, tc)).To(Succeed())
+	g.Expect(etcdClient.Defragmented).To(Equal([]string{
+		tc.Status.PD.Members["test-pd-2"].ClientURL,
+		tc.Status.PD.Members["test-pd-0"].ClientURL,
+		tc.Status.PD.Members["test-pd-1"].ClientURL,
+	}))
+	g.Expect(etcdClient.Compacted).To(HaveLen(1))
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhaseComplete))
+	g.Expect(tcm.Status.Progress).To(Equal("4/4"))
+	g.Expect(tcm.Status.Message).To(Equal("3 PD members are defragmented, 9.0 GiB is reclaimed"))
+}
+
+func TestMaintenanceManagerSyncPDDefragFailed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeMaintenanceManager()
+	tc := newPDDefragTidbCluster()
+	tcm := newTidbClusterMaintenance(v1alpha1.MaintenanceTypePDDefrag)
+	threshold := resource.MustParse("2Gi")
+	tcm.Spec.PDDefrag = &v1alpha1.PDDefragOptions{DBSizeThreshold: &threshold, MinFreePercent: pointer.Int32Ptr(0)}
+	etcdClient := newFakePDEtcdClient(deps, tc)
+	etcdClient.Statuses[tc.Status.PD.Members["test-pd-0"].ClientURL].DBSize = 1 * gib
+	etcdClient.DefragmentErr = fmt.Errorf("context deadline exceeded")
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetHealthActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.HealthInfo{}, nil
+	})
+
+	// the run is stopped on the first failure
+	g.Expect(m.Sync(tcm, tc)).To(Succeed())
+	g.Expect(etcdClient.Compacted).To(BeEmpty())
+	g.Expect(tcm.Status.Phase).To(Equal(v1alpha1.MaintenancePhaseFailed))
+	g.Expect(tcm.Status.Message).To(ContainSubstring("test-pd-2"))
+	g.Expect(tcm.Status.Progress).To(Equal("2/4"))
+	g.Expect(tcm.Status.PDMembers["test-pd-0"].Message).To(ContainSubstring("skipped"))
+	g.Expect(tcm.Status.PDMembers["test-pd-1"].Phase).To(Equal(v1alpha1.MaintenancePhasePending))
+	g.Expect(tcm.Status.PDMembers["test-pd-2"].Phase).To(Equal(v1alpha1.MaintenancePhaseFailed))
+	g.Expect(tcm.Status.PDMembers["test-pd-3"].Phase).To(Equal(v1alpha1.MaintenancePhasePending))
+}
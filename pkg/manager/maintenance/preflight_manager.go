@@ -0,0 +1,604 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// preflightNodeScript prints the observed values of a node as key=value lines
+	// into the termination message, the peers to ping are passed by $PEER_IPS
+	preflightNodeScript = `
+{
+for p in vm/swappiness vm/overcommit_memory fs/file-max net/core/somaxconn net/ipv4/tcp_syncookies; do
+  echo "sysctl.$(echo $p | tr / .)=$(cat /proc/sys/$p)"
+done
+echo "thp=$(sed 's/.*\[\(.*\)\].*/\1/' /sys/kernel/mm/transparent_hugepage/enabled)"
+adjtimex | awk '$1=="status:"{print "clock.status="$2} $1=="esterror:"{print "clock.esterrorUs="$2}'
+iface=$(awk '$2=="00000000"{print $1; exit}' /proc/net/route)
+echo "mtu=$(cat /sys/class/net/$iface/mtu)"
+max=0; maxPeer=; unreachable=
+for ip in $PEER_IPS; do
+  avg=$(ping -c 5 -q $ip 2>/dev/null | awk '/min\/avg\/max/{split($0,a,"= "); split(a[2],v,"/"); print v[2]}')
+  if [ -z "$avg" ]; then unreachable="$unreachable $ip"; continue; fi
+  if awk "BEGIN{exit !($avg > $max)}"; then max=$avg; maxPeer=$ip; fi
+done
+echo "latency.maxMs=$max"
+echo "latency.maxPeer=$maxPeer"
+echo "latency.unreachable=$(echo $unreachable)"
+} | tee /dev/termination-log
+`
+
+	// preflightStorageScript benchmarks the synchronous 4k random writes to the volume,
+	// the write IOPS and the mean latency are the 49th and 81st fields of the terse output
+	preflightStorageScript = `
+fio --name=preflight --filename=/data/preflight --rw=randwrite --bs=4k --size=256m --direct=1 --sync=1 \
+  --runtime=30 --time_based --minimal --terse-version=3 |
+  awk -F';' '{print "storage.writeIOPS="$49; print "storage.writeLatencyUs="$81}' | tee /dev/termination-log
+`
+
+	preflightDataVolumeName = "data"
+	preflightDataMountPath  = "/data"
+
+	// clockUnsync is the STA_UNSYNC bit of the clock status
+	clockUnsync = 0x40
+
+	preflightCheckNode    = "node"
+	preflightCheckClock   = "clock"
+	preflightCheckTHP     = "thp"
+	preflightCheckMTU     = "mtu"
+	preflightCheckLatency = "latency"
+	preflightCheckStorage = "storage"
+)
+
+// preflightSysctl is a kernel parameter checked and its recommended value
+type preflightSysctl struct {
+	name     string
+	value    int64
+	minValue bool // whether the value is the minimum instead of the exact value
+}
+
+var preflightSysctls = []preflightSysctl{
+	{name: "vm.swappiness", value: 0},
+	{name: "vm.overcommit_memory", value: 1},
+	{name: "fs.file-max", value: 1000000, minValue: true},
+	{name: "net.core.somaxconn", value: 32768, minValue: true},
+	{name: "net.ipv4.tcp_syncookies", value: 0},
+}
+
+type preflightManager struct {
+	deps *controller.Dependencies
+	now  nowFn
+}
+
+// NewPreflightManager returns a manager which runs the environment checks of TidbClusterPreflight
+func NewPreflightManager(deps *controller.Dependencies) manager.TiDBClusterPreflightManager {
+	return &preflightManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (m *preflightManager) Sync(pf *v1alpha1.TidbClusterPreflight) error {
+	switch pf.Status.Phase {
+	case v1alpha1.PreflightPhaseComplete, v1alpha1.PreflightPhaseFailed:
+		return nil
+	case "":
+		if err := m.start(pf); err != nil {
+			return err
+		}
+		if pf.Status.Phase == v1alpha1.PreflightPhaseFailed {
+			return nil
+		}
+	}
+	return m.syncChecks(pf)
+}
+
+// start selects the nodes to check
+func (m *preflightManager) start(pf *v1alpha1.TidbClusterPreflight) error {
+	pf.Status.StartTime = &metav1.Time{Time: m.now()}
+	if m.deps.NodeLister == nil {
+		m.finish(pf, v1alpha1.PreflightPhaseFailed, "checking the nodes requires the permission of reading nodes")
+		return nil
+	}
+	nodes, err := m.deps.NodeLister.List(labels.SelectorFromSet(pf.Spec.NodeSelector))
+	if err != nil {
+		return fmt.Errorf("list nodes of TidbClusterPreflight %s/%s failed, err: %v", pf.Namespace, pf.Name, err)
+	}
+	var names []string
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		names = append(names, node.Name)
+	}
+	if len(names) == 0 {
+		m.finish(pf, v1alpha1.PreflightPhaseFailed, "no schedulable node is selected")
+		return nil
+	}
+	sort.Strings(names)
+
+	klog.Infof("TidbClusterPreflight %s/%s starts checking nodes %v", pf.Namespace, pf.Name, names)
+	pf.Status.Phase = v1alpha1.PreflightPhaseRunning
+	pf.Status.Nodes = names
+	return nil
+}
+
+func (m *preflightManager) finish(pf *v1alpha1.TidbClusterPreflight, phase v1alpha1.PreflightPhase, message string) {
+	pf.Status.Phase = phase
+	pf.Status.Message = message
+	pf.Status.CompletionTime = &metav1.Time{Time: m.now()}
+	if phase == v1alpha1.PreflightPhaseFailed {
+		pf.Status.Result = v1alpha1.PreflightResultFailed
+	}
+	klog.Infof("TidbClusterPreflight %s/%s finished, phase: %s, result: %s, message: %s", pf.Namespace, pf.Name, phase, pf.Status.Result, message)
+}
+
+// jobResult is the output of a finished check Job, or the reason it failed
+type jobResult struct {
+	output map[string]string
+	failed string
+}
+
+func (m *preflightManager) syncChecks(pf *v1alpha1.TidbClusterPreflight) error {
+	ips := map[string]string{}
+	for _, name := range pf.Status.Nodes {
+		node, err := m.deps.NodeLister.Get(name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ips[name] = nodeInternalIP(node)
+	}
+
+	results := map[string]*jobResult{}
+	var pending []string
+	for _, name := range pf.Status.Nodes {
+		var peers []string
+		for peer, ip := range ips {
+			if peer != name && ip != "" {
+				peers = append(peers, ip)
+			}
+		}
+		sort.Strings(peers)
+		result, err := m.syncJob(pf, m.makeNodeJob(pf, name, peers))
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			pending = append(pending, name)
+			continue
+		}
+		results[name] = result
+	}
+
+	var storage *jobResult
+	if pf.Spec.Storage != nil {
+		if err := m.syncStorageVolume(pf); err != nil {
+			return err
+		}
+		result, err := m.syncJob(pf, m.makeStorageJob(pf))
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			pending = append(pending, preflightCheckStorage)
+		} else {
+			storage = result
+		}
+	}
+
+	timeout := len(pending) > 0 && !m.now().Before(pf.Status.StartTime.Add(pf.Timeout()))
+	if len(pending) > 0 && !timeout {
+		pf.Status.Message = fmt.Sprintf("waiting for the checks of %v", pending)
+		return controller.RequeueErrorf("TidbClusterPreflight %s/%s is waiting for the checks of %v", pf.Namespace, pf.Name, pending)
+	}
+
+	if pf.Spec.Storage != nil {
+		if err := m.deleteStorageVolume(pf); err != nil {
+			return err
+		}
+	}
+
+	report := evaluateNodes(pf, results, ips)
+	if pf.Spec.Storage != nil {
+		report = append(report, evaluateStorage(pf, storage))
+	}
+	pf.Status.Report = report
+	pf.Status.Result = worstResult(report)
+	if timeout {
+		m.finish(pf, v1alpha1.PreflightPhaseFailed, fmt.Sprintf("the checks of %v are not finished in %s", pending, pf.Timeout()))
+		return nil
+	}
+	var notPassed int
+	for _, r := range report {
+		if r.Result != v1alpha1.PreflightResultPassed {
+			notPassed++
+		}
+	}
+	message := ""
+	if notPassed > 0 {
+		message = fmt.Sprintf("%d of %d checks are not passed", notPassed, len(report))
+	}
+	m.finish(pf, v1alpha1.PreflightPhaseComplete, message)
+	return nil
+}
+
+// syncJob creates the check Job if it doesn't exist, and returns its result once it finishes
+func (m *preflightManager) syncJob(pf *v1alpha1.TidbClusterPreflight, newJob *batchv1.Job) (*jobResult, error) {
+	job, err := m.deps.JobLister.Jobs(pf.Namespace).Get(newJob.Name)
+	if errors.IsNotFound(err) {
+		if err := m.deps.JobControl.CreateJob(pf, newJob); err != nil && !errors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			output, err := m.jobOutput(job)
+			if err != nil {
+				return nil, err
+			}
+			if output == nil {
+				return &jobResult{failed: fmt.Sprintf("the output of Job %s is not found", job.Name)}, nil
+			}
+			return &jobResult{output: output}, nil
+		case batchv1.JobFailed:
+			return &jobResult{failed: fmt.Sprintf("Job %s failed: %s", job.Name, cond.Message)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// jobOutput returns the key=value lines in the termination message of the succeeded Pod of the Job
+func (m *preflightManager) jobOutput(job *batchv1.Job) (map[string]string, error) {
+	pods, err := m.deps.PodLister.Pods(job.Namespace).List(labels.SelectorFromSet(labels.Set{"job-name": job.Name}))
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil {
+				return parsePreflightOutput(status.State.Terminated.Message), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func parsePreflightOutput(message string) map[string]string {
+	output := map[string]string{}
+	for _, line := range strings.Split(message, "\n") {
+		kv := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(kv) == 2 {
+			output[kv[0]] = kv[1]
+		}
+	}
+	return output
+}
+
+func (m *preflightManager) syncStorageVolume(pf *v1alpha1.TidbClusterPreflight) error {
+	_, err := m.deps.PVCLister.PersistentVolumeClaims(pf.Namespace).Get(storageCheckName(pf))
+	if !errors.IsNotFound(err) {
+		return err
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      storageCheckName(pf),
+			Namespace: pf.Namespace,
+			Labels:    label.NewPreflight().Preflight(pf.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				controller.GetTidbClusterPreflightOwnerRef(pf),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: pf.Spec.Storage.StorageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: pf.StorageSize()},
+			},
+		},
+	}
+	return m.deps.PVCControl.CreatePVC(pf, pvc)
+}
+
+// deleteStorageVolume releases the volume benchmarked once the checks finish
+func (m *preflightManager) deleteStorageVolume(pf *v1alpha1.TidbClusterPreflight) error {
+	pvc, err := m.deps.PVCLister.PersistentVolumeClaims(pf.Namespace).Get(storageCheckName(pf))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := m.deps.PVCControl.DeletePVC(pf, pvc); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func nodeCheckJobName(pf *v1alpha1.TidbClusterPreflight, node string) string {
+	// node names may be too long to be a part of the Job name
+	sum := sha256.Sum256([]byte(node))
+	return fmt.Sprintf("%s-node-%x", pf.Name, sum[:4])
+}
+
+func storageCheckName(pf *v1alpha1.TidbClusterPreflight) string {
+	return fmt.Sprintf("%s-storage", pf.Name)
+}
+
+func (m *preflightManager) makeNodeJob(pf *v1alpha1.TidbClusterPreflight, node string, peers []string) *batchv1.Job {
+	job := makePreflightJob(pf, nodeCheckJobName(pf, node), preflightNodeScript)
+	podSpec := &job.Spec.Template.Spec
+	podSpec.NodeName = node
+	podSpec.HostNetwork = true
+	podSpec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	podSpec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "PEER_IPS", Value: strings.Join(peers, " ")},
+	}
+	return job
+}
+
+func (m *preflightManager) makeStorageJob(pf *v1alpha1.TidbClusterPreflight) *batchv1.Job {
+	job := makePreflightJob(pf, storageCheckName(pf), preflightStorageScript)
+	podSpec := &job.Spec.Template.Spec
+	podSpec.NodeSelector = pf.Spec.NodeSelector
+	podSpec.Containers[0].VolumeMounts = []corev1.VolumeMount{
+		{Name: preflightDataVolumeName, MountPath: preflightDataMountPath},
+	}
+	podSpec.Volumes = []corev1.Volume{
+		{
+			Name: preflightDataVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: storageCheckName(pf)},
+			},
+		},
+	}
+	return job
+}
+
+func makePreflightJob(pf *v1alpha1.TidbClusterPreflight, name, script string) *batchv1.Job {
+	jobLabels := label.NewPreflight().Preflight(pf.Name)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: pf.Namespace,
+			Labels:    jobLabels,
+			OwnerReferences: []metav1.OwnerReference{
+				controller.GetTidbClusterPreflightOwnerRef(pf),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          pointer.Int32Ptr(0),
+			ActiveDeadlineSeconds: pointer.Int64Ptr(int64(pf.Timeout().Seconds())),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "preflight",
+							Image:   pf.Spec.Image,
+							Command: []string{"sh", "-c", script},
+						},
+					},
+					RestartPolicy:    corev1.RestartPolicyNever,
+					Tolerations:      pf.Spec.Tolerations,
+					ImagePullSecrets: pf.Spec.ImagePullSecrets,
+				},
+			},
+		},
+	}
+}
+
+func nodeInternalIP(node *corev1.Node) string {
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == corev1.NodeInternalIP {
+			return addr.Address
+		}
+	}
+	return ""
+}
+
+// evaluateNodes returns the results of the checks on the nodes, a node whose check Job
+// is not finished fails all the checks
+func evaluateNodes(pf *v1alpha1.TidbClusterPreflight, results map[string]*jobResult, ips map[string]string) []v1alpha1.PreflightCheckResult {
+	// the MTU of a node is expected to be the same as most of the nodes
+	mtus := map[string]int{}
+	var commonMTU string
+	for _, result := range results {
+		if mtu := result.output["mtu"]; mtu != "" {
+			mtus[mtu]++
+			if mtus[mtu] > mtus[commonMTU] || mtus[mtu] == mtus[commonMTU] && mtu > commonMTU {
+				commonMTU = mtu
+			}
+		}
+	}
+	nodesByIP := map[string]string{}
+	for node, ip := range ips {
+		nodesByIP[ip] = node
+	}
+
+	var report []v1alpha1.PreflightCheckResult
+	for _, node := range pf.Status.Nodes {
+		result, ok := results[node]
+		if !ok {
+			report = append(report, v1alpha1.PreflightCheckResult{
+				Check: preflightCheckNode, Node: node, Result: v1alpha1.PreflightResultFailed,
+				Message: fmt.Sprintf("the check Job is not finished in %s", pf.Timeout()),
+			})
+			continue
+		}
+		if result.failed != "" {
+			report = append(report, v1alpha1.PreflightCheckResult{
+				Check: preflightCheckNode, Node: node, Result: v1alpha1.PreflightResultFailed, Message: result.failed,
+			})
+			continue
+		}
+		for _, r := range evaluateNode(pf, result.output, commonMTU, nodesByIP) {
+			r.Node = node
+			report = append(report, r)
+		}
+	}
+	return report
+}
+
+func evaluateNode(pf *v1alpha1.TidbClusterPreflight, output map[string]string, commonMTU string, nodesByIP map[string]string) []v1alpha1.PreflightCheckResult {
+	var report []v1alpha1.PreflightCheckResult
+	add := func(check string, result v1alpha1.PreflightResult, value, message string) {
+		report = append(report, v1alpha1.PreflightCheckResult{Check: check, Result: result, Value: value, Message: message})
+	}
+
+	for _, sysctl := range preflightSysctls {
+		check := "sysctl." + sysctl.name
+		value := output[check]
+		v, err := strconv.ParseInt(value, 10, 64)
+		switch {
+		case err != nil:
+			add(check, v1alpha1.PreflightResultWarning, value, "the value can't be read")
+		case sysctl.minValue && v < sysctl.value:
+			add(check, v1alpha1.PreflightResultWarning, value, fmt.Sprintf("recommended to be at least %d", sysctl.value))
+		case !sysctl.minValue && v != sysctl.value:
+			add(check, v1alpha1.PreflightResultWarning, value, fmt.Sprintf("recommended to be %d", sysctl.value))
+		default:
+			add(check, v1alpha1.PreflightResultPassed, value, "")
+		}
+	}
+
+	if thp := output[preflightCheckTHP]; thp != "never" {
+		add(preflightCheckTHP, v1alpha1.PreflightResultWarning, thp, "transparent huge pages are recommended to be disabled")
+	} else {
+		add(preflightCheckTHP, v1alpha1.PreflightResultPassed, thp, "")
+	}
+
+	status, statusErr := strconv.ParseInt(output["clock.status"], 10, 64)
+	esterror, esterrorErr := strconv.ParseInt(output["clock.esterrorUs"], 10, 64)
+	clockError := time.Duration(esterror) * time.Microsecond
+	switch {
+	case statusErr != nil || esterrorErr != nil:
+		add(preflightCheckClock, v1alpha1.PreflightResultFailed, "", "the clock status can't be read")
+	case status&clockUnsync != 0:
+		add(preflightCheckClock, v1alpha1.PreflightResultFailed, clockError.String(), "the clock is not synchronized")
+	case clockError > pf.MaxClockError():
+		add(preflightCheckClock, v1alpha1.PreflightResultFailed, clockError.String(), fmt.Sprintf("the estimated error exceeds %s", pf.MaxClockError()))
+	default:
+		add(preflightCheckClock, v1alpha1.PreflightResultPassed, clockError.String(), "")
+	}
+
+	if mtu := output[preflightCheckMTU]; mtu != commonMTU {
+		add(preflightCheckMTU, v1alpha1.PreflightResultFailed, mtu, fmt.Sprintf("differs from the MTU %s of the other nodes", commonMTU))
+	} else {
+		add(preflightCheckMTU, v1alpha1.PreflightResultPassed, mtu, "")
+	}
+
+	peerName := func(ip string) string {
+		if node, ok := nodesByIP[ip]; ok {
+			return node
+		}
+		return ip
+	}
+	maxLatency, err := strconv.ParseFloat(output["latency.maxMs"], 64)
+	latency := time.Duration(math.Round(maxLatency*1000)) * time.Microsecond
+	if unreachable := strings.Fields(output["latency.unreachable"]); len(unreachable) > 0 {
+		for i := range unreachable {
+			unreachable[i] = peerName(unreachable[i])
+		}
+		add(preflightCheckLatency, v1alpha1.PreflightResultFailed, "", fmt.Sprintf("nodes %v are unreachable", unreachable))
+	} else if err != nil {
+		add(preflightCheckLatency, v1alpha1.PreflightResultFailed, "", "the latency can't be read")
+	} else if latency > pf.MaxNetworkLatency() {
+		add(preflightCheckLatency, v1alpha1.PreflightResultWarning, latency.String(),
+			fmt.Sprintf("the round-trip time to node %s exceeds %s", peerName(output["latency.maxPeer"]), pf.MaxNetworkLatency()))
+	} else {
+		add(preflightCheckLatency, v1alpha1.PreflightResultPassed, latency.String(), "")
+	}
+
+	return report
+}
+
+func evaluateStorage(pf *v1alpha1.TidbClusterPreflight, result *jobResult) v1alpha1.PreflightCheckResult {
+	r := v1alpha1.PreflightCheckResult{Check: preflightCheckStorage}
+	if result == nil {
+		r.Result = v1alpha1.PreflightResultFailed
+		r.Message = fmt.Sprintf("the check Job is not finished in %s", pf.Timeout())
+		return r
+	}
+	if result.failed != "" {
+		r.Result = v1alpha1.PreflightResultFailed
+		r.Message = result.failed
+		return r
+	}
+	latencyUs, err := strconv.ParseFloat(result.output["storage.writeLatencyUs"], 64)
+	if err != nil {
+		r.Result = v1alpha1.PreflightResultFailed
+		r.Message = "the write latency can't be read"
+		return r
+	}
+	latency := time.Duration(math.Round(latencyUs)) * time.Microsecond
+	r.Value = fmt.Sprintf("%s (%s IOPS)", latency, result.output["storage.writeIOPS"])
+	if latency > pf.MaxWriteLatency() {
+		r.Result = v1alpha1.PreflightResultWarning
+		r.Message = fmt.Sprintf("the average latency of the synchronous writes exceeds %s", pf.MaxWriteLatency())
+		return r
+	}
+	r.Result = v1alpha1.PreflightResultPassed
+	return r
+}
+
+func worstResult(report []v1alpha1.PreflightCheckResult) v1alpha1.PreflightResult {
+	worst := v1alpha1.PreflightResultPassed
+	for _, r := range report {
+		switch r.Result {
+		case v1alpha1.PreflightResultFailed:
+			return v1alpha1.PreflightResultFailed
+		case v1alpha1.PreflightResultWarning:
+			worst = v1alpha1.PreflightResultWarning
+		}
+	}
+	return worst
+}
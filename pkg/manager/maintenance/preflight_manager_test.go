@@ -0,0 +1,222 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const preflightNodeOutput = `sysctl.vm.swappiness=0
+sysctl.vm.overcommit_memory=1
+sysctl.fs.file-max=9223372036854775807
+sysctl.net.core.somaxconn=32768
+sysctl.net.ipv4.tcp_syncookies=0
+thp=never
+clock.status=8193
+clock.esterrorUs=1000
+mtu=1500
+latency.maxMs=0.215
+latency.maxPeer=10.0.0.2
+latency.unreachable=
+`
+
+func newTidbClusterPreflight() *v1alpha1.TidbClusterPreflight {
+	size := resource.MustParse("1Gi")
+	return &v1alpha1.TidbClusterPreflight{
+		ObjectMeta: metav1.ObjectMeta{Name: "pf", Namespace: corev1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterPreflightSpec{
+			Image:        "busybox",
+			NodeSelector: map[string]string{"dedicated": "tidb"},
+			Storage: &v1alpha1.PreflightStorageCheck{
+				StorageClassName: pointer.StringPtr("local"),
+				Size:             &size,
+			},
+		},
+	}
+}
+
+func TestPreflightManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewPreflightManager(deps).(*preflightManager)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	for i, name := range []string{"node-1", "node-2", "node-3", "node-4"} {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"dedicated": "tidb"}},
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: fmt.Sprintf("10.0.0.%d", i+1)},
+			}},
+		}
+		switch name {
+		case "node-3":
+			node.Spec.Unschedulable = true
+		case "node-4":
+			node.Labels = nil
+		}
+		g.Expect(nodeIndexer.Add(node)).To(Succeed())
+	}
+
+	pf := newTidbClusterPreflight()
+	err := m.Sync(pf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(pf.Status.Phase).To(Equal(v1alpha1.PreflightPhaseRunning))
+	g.Expect(pf.Status.Nodes).To(Equal([]string{"node-1", "node-2"}))
+
+	jobIndexer := deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer()
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pvcIndexer := deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+	getJob := func(name string) *batchv1.Job {
+		obj, exist, err := jobIndexer.GetByKey(fmt.Sprintf("%s/%s", pf.Namespace, name))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(exist).To(BeTrue(), name)
+		return obj.(*batchv1.Job)
+	}
+	finishJob := func(name, output string, condType batchv1.JobConditionType) {
+		job := getJob(name).DeepCopy()
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condType, Status: corev1.ConditionTrue, Message: "deadline exceeded"}}
+		g.Expect(jobIndexer.Update(job)).To(Succeed())
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-abcde", Namespace: pf.Namespace, Labels: map[string]string{"job-name": name}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: output}},
+				}},
+			},
+		}
+		g.Expect(podIndexer.Add(pod)).To(Succeed())
+	}
+
+	// a Job is run on each node, and the volume is benchmarked by a Job
+	job := getJob(nodeCheckJobName(pf, "node-1"))
+	g.Expect(job.Spec.Template.Spec.NodeName).To(Equal("node-1"))
+	g.Expect(job.Spec.Template.Spec.HostNetwork).To(BeTrue())
+	g.Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "PEER_IPS", Value: "10.0.0.2"}))
+	getJob(nodeCheckJobName(pf, "node-2"))
+	getJob(storageCheckName(pf))
+	_, exist, err := pvcIndexer.GetByKey(fmt.Sprintf("%s/%s", pf.Namespace, storageCheckName(pf)))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeTrue())
+
+	// the checks are done once all the Jobs finish
+	finishJob(nodeCheckJobName(pf, "node-1"), preflightNodeOutput, batchv1.JobComplete)
+	err = m.Sync(pf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(pf.Status.Message).To(ContainSubstring("node-2"))
+
+	finishJob(nodeCheckJobName(pf, "node-2"), preflightNodeOutput+"mtu=9000\nthp=always\nclock.status=8257\n", batchv1.JobComplete)
+	finishJob(storageCheckName(pf), "storage.writeIOPS=120\nstorage.writeLatencyUs=8000.5\n", batchv1.JobComplete)
+	g.Expect(m.Sync(pf)).To(Succeed())
+	g.Expect(pf.Status.Phase).To(Equal(v1alpha1.PreflightPhaseComplete))
+	g.Expect(pf.Status.Result).To(Equal(v1alpha1.PreflightResultFailed))
+	g.Expect(pf.Status.CompletionTime).NotTo(BeNil())
+	results := map[string]v1alpha1.PreflightResult{}
+	for _, r := range pf.Status.Report {
+		results[r.Node+"/"+r.Check] = r.Result
+	}
+	g.Expect(results).To(HaveKeyWithValue("node-1/sysctl.fs.file-max", v1alpha1.PreflightResultPassed))
+	g.Expect(results).To(HaveKeyWithValue("node-1/clock", v1alpha1.PreflightResultPassed))
+	g.Expect(results).To(HaveKeyWithValue("node-2/thp", v1alpha1.PreflightResultWarning))
+	g.Expect(results).To(HaveKeyWithValue("node-2/clock", v1alpha1.PreflightResultFailed))
+	g.Expect(results).To(HaveKeyWithValue("/storage", v1alpha1.PreflightResultPassed))
+	_, exist, err = pvcIndexer.GetByKey(fmt.Sprintf("%s/%s", pf.Namespace, storageCheckName(pf)))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeFalse())
+
+	// the checks are never run again
+	report := pf.Status.Report
+	g.Expect(m.Sync(pf)).To(Succeed())
+	g.Expect(pf.Status.Report).To(Equal(report))
+}
+
+func TestPreflightManagerSyncTimeout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewPreflightManager(deps).(*preflightManager)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	pf := newTidbClusterPreflight()
+	g.Expect(m.Sync(pf)).To(Succeed())
+	g.Expect(pf.Status.Phase).To(Equal(v1alpha1.PreflightPhaseFailed))
+	g.Expect(pf.Status.Message).To(Equal("no schedulable node is selected"))
+
+	nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	g.Expect(nodeIndexer.Add(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"dedicated": "tidb"}},
+	})).To(Succeed())
+	pf = newTidbClusterPreflight()
+	pf.Spec.Storage = nil
+	err := m.Sync(pf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+
+	now = now.Add(pf.Timeout())
+	g.Expect(m.Sync(pf)).To(Succeed())
+	g.Expect(pf.Status.Phase).To(Equal(v1alpha1.PreflightPhaseFailed))
+	g.Expect(pf.Status.Result).To(Equal(v1alpha1.PreflightResultFailed))
+	g.Expect(pf.Status.Report).To(Equal([]v1alpha1.PreflightCheckResult{{
+		Check: "node", Node: "node-1", Result: v1alpha1.PreflightResultFailed, Message: "the check Job is not finished in 10m0s",
+	}}))
+}
+
+func TestEvaluateNode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pf := newTidbClusterPreflight()
+	nodesByIP := map[string]string{"10.0.0.2": "node-2"}
+	results := func(output string) map[string]v1alpha1.PreflightCheckResult {
+		m := map[string]v1alpha1.PreflightCheckResult{}
+		for _, r := range evaluateNode(pf, parsePreflightOutput(output), "1500", nodesByIP) {
+			m[r.Check] = r
+		}
+		return m
+	}
+
+	r := results(preflightNodeOutput)
+	for check, result := range r {
+		g.Expect(result.Result).To(Equal(v1alpha1.PreflightResultPassed), check)
+	}
+	g.Expect(r["latency"].Value).To(Equal("215µs"))
+
+	r = results(preflightNodeOutput + "sysctl.vm.swappiness=60\nsysctl.net.core.somaxconn=128\nclock.esterrorUs=600000\n")
+	g.Expect(r["sysctl.vm.swappiness"].Result).To(Equal(v1alpha1.PreflightResultWarning))
+	g.Expect(r["sysctl.net.core.somaxconn"].Message).To(Equal("recommended to be at least 32768"))
+	g.Expect(r["clock"].Result).To(Equal(v1alpha1.PreflightResultFailed))
+	g.Expect(r["clock"].Message).To(Equal("the estimated error exceeds 500ms"))
+
+	r = results(preflightNodeOutput + "latency.maxMs=3.5\n")
+	g.Expect(r["latency"].Result).To(Equal(v1alpha1.PreflightResultWarning))
+	g.Expect(r["latency"].Message).To(Equal("the round-trip time to node node-2 exceeds 2ms"))
+
+	r = results(preflightNodeOutput + "latency.unreachable=10.0.0.2 10.0.0.5\nmtu=9000\n")
+	g.Expect(r["latency"].Result).To(Equal(v1alpha1.PreflightResultFailed))
+	g.Expect(r["latency"].Message).To(Equal("nodes [node-2 10.0.0.5] are unreachable"))
+	g.Expect(r["mtu"].Result).To(Equal(v1alpha1.PreflightResultFailed))
+}
@@ -31,3 +31,19 @@ type DMManager interface {
 type TiDBNGMonitoringManager interface {
 	Sync(*v1alpha1.TidbNGMonitoring, *v1alpha1.TidbCluster) error
 }
+
+type TiDBClusterMaintenanceManager interface {
+	Sync(*v1alpha1.TidbClusterMaintenance, *v1alpha1.TidbCluster) error
+}
+
+type TiDBClusterOperationManager interface {
+	Sync(*v1alpha1.TidbClusterOperation, *v1alpha1.TidbCluster) error
+}
+
+type TiDBClusterPreflightManager interface {
+	Sync(*v1alpha1.TidbClusterPreflight) error
+}
+
+type NodeMaintenanceManager interface {
+	Sync(*v1alpha1.NodeMaintenance) error
+}
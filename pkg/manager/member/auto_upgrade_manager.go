@@ -0,0 +1,423 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	"github.com/robfig/cron"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// autoUpgradeCheckInterval is the interval between two checks of the release metadata
+	autoUpgradeCheckInterval = 10 * time.Minute
+	// releaseMetadataTimeout is the timeout of reading the release metadata from the URL
+	releaseMetadataTimeout = 10 * time.Second
+)
+
+// releaseMetadata is the releases of the channels, e.g. {"channels": {"6.5-lts": ["v6.5.0", "v6.5.1"]}}
+type releaseMetadata struct {
+	Channels map[string][]string `json:"channels"`
+}
+
+type autoUpgradeManager struct {
+	deps *controller.Dependencies
+	// fetchFn reads the release metadata of spec.upgradePolicy
+	fetchFn func(tc *v1alpha1.TidbCluster) (*releaseMetadata, error)
+	now     func() time.Time
+}
+
+// NewAutoUpgradeManager returns a manager which upgrades the cluster to the latest release of the
+// channel of spec.upgradePolicy by changing spec.version in the maintenance windows, and checks
+// whether the upgrade completes in status.autoUpgrade.
+func NewAutoUpgradeManager(deps *controller.Dependencies) manager.Manager {
+	m := &autoUpgradeManager{
+		deps: deps,
+		now:  time.Now,
+	}
+	m.fetchFn = m.fetchReleaseMetadata
+	return m
+}
+
+func (m *autoUpgradeManager) Sync(tc *v1alpha1.TidbCluster) error {
+	policy := tc.Spec.UpgradePolicy
+	if policy == nil {
+		tc.Status.AutoUpgrade = nil
+		return nil
+	}
+	if tc.Status.AutoUpgrade == nil {
+		tc.Status.AutoUpgrade = &v1alpha1.AutoUpgradeStatus{}
+	}
+	status := tc.Status.AutoUpgrade
+	now := m.now()
+
+	if status.Phase == v1alpha1.AutoUpgradeUpgrading {
+		m.postCheck(tc, now)
+		return nil
+	}
+	if now.Sub(status.LastCheckTime.Time) < autoUpgradeCheckInterval {
+		return nil
+	}
+	status.LastCheckTime = metav1.NewTime(now)
+
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	metadata, err := m.fetchFn(tc)
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to read the release metadata: %v", err)
+		klog.Warningf("TidbCluster: [%s/%s] %s", ns, tcName, status.Message)
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, "AutoUpgradeCheckFailed", status.Message)
+		return nil
+	}
+	latest, err := latestRelease(metadata, policy.Channel)
+	if err != nil {
+		status.Message = err.Error()
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, "AutoUpgradeCheckFailed", status.Message)
+		return nil
+	}
+	status.LatestVersion = latest.Original()
+
+	current, err := semver.NewVersion(tc.Spec.Version)
+	if err != nil {
+		status.Message = fmt.Sprintf("spec.version %q is not a semantic version", tc.Spec.Version)
+		return nil
+	}
+	if !latest.GreaterThan(current) {
+		if status.Phase != v1alpha1.AutoUpgradeFailed {
+			status.Message = ""
+		}
+		return nil
+	}
+	target := latest.Original()
+	if status.Phase == v1alpha1.AutoUpgradeFailed && status.TargetVersion == target {
+		// the failed version is not tried again, a newer release is expected
+		return nil
+	}
+
+	inWindow, err := inMaintenanceWindow(policy.MaintenanceWindow, now)
+	if err != nil {
+		status.Message = err.Error()
+		return nil
+	}
+	if !inWindow {
+		status.Message = fmt.Sprintf("%s is available, waiting for the maintenance window", target)
+		return nil
+	}
+	if err := autoUpgradePreCheck(tc); err != nil {
+		status.Message = fmt.Sprintf("%s is available, pre-check failed: %v", target, err)
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, "AutoUpgradePreCheckFailed", status.Message)
+		return nil
+	}
+
+	if err := m.patchVersion(tc, target); err != nil {
+		return err
+	}
+	from := tc.Spec.Version
+	tc.Spec.Version = target
+	tc.Status.AutoUpgrade = &v1alpha1.AutoUpgradeStatus{
+		Phase:         v1alpha1.AutoUpgradeUpgrading,
+		LatestVersion: target,
+		LastCheckTime: metav1.NewTime(now),
+		FromVersion:   from,
+		TargetVersion: target,
+		StartTime:     metav1.NewTime(now),
+	}
+	klog.Infof("TidbCluster: [%s/%s] is upgraded from %s to %s by the upgrade policy", ns, tcName, from, target)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "AutoUpgradeStarted", "upgrade from %s to %s in channel %s", from, target, policy.Channel)
+	return nil
+}
+
+// postCheck completes the upgrade once all the components run the target version and the cluster
+// is ready, or fails it after the timeout
+func (m *autoUpgradeManager) postCheck(tc *v1alpha1.TidbCluster, now time.Time) {
+	status := tc.Status.AutoUpgrade
+	if tc.Spec.Version != status.TargetVersion {
+		m.finishUpgrade(tc, v1alpha1.AutoUpgradeFailed, fmt.Sprintf("spec.version is changed to %s during the upgrade", tc.Spec.Version), now)
+		return
+	}
+	reason := clusterNotStableReason(tc, status.TargetVersion)
+	switch {
+	case reason == "":
+		m.finishUpgrade(tc, v1alpha1.AutoUpgradeComplete, "", now)
+	case now.Sub(status.StartTime.Time) > tc.UpgradePostCheckTimeout():
+		m.finishUpgrade(tc, v1alpha1.AutoUpgradeFailed, fmt.Sprintf("post-check failed in %v: %s", tc.UpgradePostCheckTimeout(), reason), now)
+	default:
+		status.Message = reason
+	}
+}
+
+func (m *autoUpgradeManager) finishUpgrade(tc *v1alpha1.TidbCluster, phase v1alpha1.AutoUpgradePhase, message string, now time.Time) {
+	status := tc.Status.AutoUpgrade
+	status.Phase = phase
+	status.Message = message
+	status.CompletionTime = metav1.NewTime(now)
+	klog.Infof("TidbCluster: [%s/%s] upgrade from %s to %s finished, phase: %s, message: %s",
+		tc.GetNamespace(), tc.GetName(), status.FromVersion, status.TargetVersion, phase, message)
+	if phase == v1alpha1.AutoUpgradeComplete {
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "AutoUpgradeComplete", "upgrade from %s to %s completed", status.FromVersion, status.TargetVersion)
+		return
+	}
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "AutoUpgradeFailed", "upgrade from %s to %s failed: %s", status.FromVersion, status.TargetVersion, message)
+}
+
+// patchVersion changes spec.version by a patch, so that it's not overwritten by the update of the status
+func (m *autoUpgradeManager) patchVersion(tc *v1alpha1.TidbCluster, version string) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"version": version,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = m.deps.Clientset.PingcapV1alpha1().TidbClusters(tc.GetNamespace()).Patch(context.TODO(), tc.GetName(), types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to upgrade TidbCluster %s/%s to %s, error: %v", tc.GetNamespace(), tc.GetName(), version, err)
+	}
+	return nil
+}
+
+// fetchReleaseMetadata reads the release metadata from the URL or the ConfigMap of spec.upgradePolicy
+func (m *autoUpgradeManager) fetchReleaseMetadata(tc *v1alpha1.TidbCluster) (*releaseMetadata, error) {
+	source := tc.Spec.UpgradePolicy.ReleaseMetadata
+	var data []byte
+	if source.ConfigMap != nil {
+		cm, err := m.deps.KubeClientset.CoreV1().ConfigMaps(tc.GetNamespace()).Get(context.TODO(), source.ConfigMap.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		value, ok := cm.Data[source.ConfigMap.Key]
+		if !ok {
+			return nil, fmt.Errorf("key %s is not found in ConfigMap %s", source.ConfigMap.Key, source.ConfigMap.Name)
+		}
+		data = []byte(value)
+	} else {
+		hosts := m.deps.CLIConfig.ReleaseMetadataHosts
+		if err := checkReleaseMetadataURL(source.URL, hosts); err != nil {
+			return nil, err
+		}
+		client := &http.Client{
+			Timeout: releaseMetadataTimeout,
+			// the redirections are restricted to the allowed hosts as well
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 10 {
+					return fmt.Errorf("stopped after 10 redirects")
+				}
+				return checkReleaseMetadataURL(req.URL.String(), hosts)
+			},
+		}
+		resp, err := client.Get(source.URL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %d of %s", resp.StatusCode, source.URL)
+		}
+		if data, err = ioutil.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	}
+	metadata := &releaseMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode the release metadata: %v", err)
+	}
+	return metadata, nil
+}
+
+// checkReleaseMetadataURL checks the URL is an HTTPS URL of one of the comma separated hosts
+func checkReleaseMetadataURL(rawURL, hosts string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("the release metadata URL %s is not an HTTPS URL", rawURL)
+	}
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" && strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the host of the release metadata URL %s is not in the release-metadata-hosts flag of the operator", rawURL)
+}
+
+// latestRelease returns the greatest version of the channel, the pre-releases are ignored
+func latestRelease(metadata *releaseMetadata, channel string) (*semver.Version, error) {
+	releases, ok := metadata.Channels[channel]
+	if !ok {
+		return nil, fmt.Errorf("channel %s is not found in the release metadata", channel)
+	}
+	var latest *semver.Version
+	for _, release := range releases {
+		v, err := semver.NewVersion(release)
+		if err != nil {
+			klog.Warningf("ignore release %q of channel %s: %v", release, channel, err)
+			continue
+		}
+		if v.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no release is found in channel %s", channel)
+	}
+	return latest, nil
+}
+
+// inMaintenanceWindow returns whether now is in a window, a nil window is always open
+func inMaintenanceWindow(window *v1alpha1.MaintenanceWindow, now time.Time) (bool, error) {
+	if window == nil {
+		return true, nil
+	}
+	sched, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return false, fmt.Errorf("parse maintenance window cron format %s failed, err: %v", window.Schedule, err)
+	}
+	return !sched.Next(now.Add(-window.Duration.Duration)).After(now), nil
+}
+
+// autoUpgradePreCheck returns an error if the cluster can't be upgraded by changing spec.version
+func autoUpgradePreCheck(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Paused {
+		return fmt.Errorf("cluster is paused")
+	}
+	if pinned := componentsNotFollowingVersion(tc); len(pinned) > 0 {
+		return fmt.Errorf("%s don't use spec.version", strings.Join(pinned, ", "))
+	}
+	if reason := clusterNotStableReason(tc, tc.Spec.Version); reason != "" {
+		return fmt.Errorf("%s", reason)
+	}
+	return nil
+}
+
+// componentsNotFollowingVersion returns the components whose images are not derived from spec.version.
+// TiProxy is versioned separately.
+func componentsNotFollowingVersion(tc *v1alpha1.TidbCluster) []string {
+	var pinned []string
+	check := func(typ v1alpha1.MemberType, baseImage string, version *string) {
+		if baseImage == "" || version != nil {
+			pinned = append(pinned, typ.String())
+		}
+	}
+	if tc.Spec.PD != nil {
+		check(v1alpha1.PDMemberType, tc.Spec.PD.BaseImage, tc.Spec.PD.Version)
+	}
+	if tc.Spec.TiKV != nil {
+		check(v1alpha1.TiKVMemberType, tc.Spec.TiKV.BaseImage, tc.Spec.TiKV.Version)
+	}
+	if tc.Spec.TiDB != nil {
+		check(v1alpha1.TiDBMemberType, tc.Spec.TiDB.BaseImage, tc.Spec.TiDB.Version)
+	}
+	if tc.Spec.TiFlash != nil {
+		check(v1alpha1.TiFlashMemberType, tc.Spec.TiFlash.BaseImage, tc.Spec.TiFlash.Version)
+	}
+	if tc.Spec.TiCDC != nil {
+		check(v1alpha1.TiCDCMemberType, tc.Spec.TiCDC.BaseImage, tc.Spec.TiCDC.Version)
+	}
+	if tc.Spec.Pump != nil {
+		check(v1alpha1.PumpMemberType, tc.Spec.Pump.BaseImage, tc.Spec.Pump.Version)
+	}
+	return pinned
+}
+
+// clusterNotStableReason returns why the cluster is not stable at the version, it returns an empty
+// string if all the components are normal and rolled to the version, and the cluster is ready
+func clusterNotStableReason(tc *v1alpha1.TidbCluster, version string) string {
+	for _, status := range tc.AllComponentStatus() {
+		typ := status.MemberType()
+		if typ == v1alpha1.TiProxyMemberType {
+			continue
+		}
+		if phase := status.GetPhase(); phase != v1alpha1.NormalPhase {
+			return fmt.Sprintf("%s is in phase %s", typ, phase)
+		}
+		sts := status.GetStatefulSet()
+		if sts == nil || sts.CurrentRevision != sts.UpdateRevision || sts.UpdatedReplicas != sts.Replicas {
+			return fmt.Sprintf("%s is being rolled", typ)
+		}
+	}
+	images := map[v1alpha1.MemberType]string{}
+	if tc.Spec.PD != nil {
+		images[v1alpha1.PDMemberType] = tc.Status.PD.Image
+	}
+	if tc.Spec.TiKV != nil {
+		images[v1alpha1.TiKVMemberType] = tc.Status.TiKV.Image
+	}
+	if tc.Spec.TiDB != nil {
+		images[v1alpha1.TiDBMemberType] = tc.Status.TiDB.Image
+	}
+	if tc.Spec.TiFlash != nil {
+		images[v1alpha1.TiFlashMemberType] = tc.Status.TiFlash.Image
+	}
+	for _, typ := range []v1alpha1.MemberType{v1alpha1.PDMemberType, v1alpha1.TiKVMemberType, v1alpha1.TiDBMemberType, v1alpha1.TiFlashMemberType} {
+		if image, ok := images[typ]; ok && !imageHasVersion(image, version) {
+			return fmt.Sprintf("%s runs image %s instead of %s", typ, image, version)
+		}
+	}
+	if cond := utiltidbcluster.GetTidbClusterReadyCondition(tc.Status); cond == nil || cond.Status != corev1.ConditionTrue {
+		return "cluster is not ready"
+	}
+	return ""
+}
+
+// imageHasVersion returns whether the tag of the image is the version, the tag may have the
+// suffixes appended by spec.imagePolicy, e.g. v6.5.1-fips
+func imageHasVersion(image, version string) bool {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return false
+	}
+	tag := image[i+1:]
+	return tag == version || strings.HasPrefix(tag, version+"-")
+}
+
+type FakeAutoUpgradeManager struct {
+	err error
+}
+
+func NewFakeAutoUpgradeManager() *FakeAutoUpgradeManager {
+	return &FakeAutoUpgradeManager{}
+}
+
+func (m *FakeAutoUpgradeManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeAutoUpgradeManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
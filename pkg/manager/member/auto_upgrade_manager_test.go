@@ -0,0 +1,197 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func newTidbClusterForAutoUpgrade(version string) *v1alpha1.TidbCluster {
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: corev1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			Version: version,
+			PD:      &v1alpha1.PDSpec{BaseImage: "pingcap/pd"},
+			TiKV:    &v1alpha1.TiKVSpec{BaseImage: "pingcap/tikv"},
+			TiDB:    &v1alpha1.TiDBSpec{BaseImage: "pingcap/tidb"},
+			UpgradePolicy: &v1alpha1.UpgradePolicy{
+				Channel:         "6.5-lts",
+				ReleaseMetadata: v1alpha1.ReleaseMetadataSource{URL: "https://example.com/releases.json"},
+				MaintenanceWindow: &v1alpha1.MaintenanceWindow{
+					Schedule: "0 2 * * 6",
+					Duration: metav1.Duration{Duration: 4 * time.Hour},
+				},
+			},
+		},
+	}
+	tc.Status.Conditions = []v1alpha1.TidbClusterCondition{{Type: v1alpha1.TidbClusterReady, Status: corev1.ConditionTrue}}
+	for _, status := range tc.AllComponentStatus() {
+		status.SetPhase(v1alpha1.NormalPhase)
+	}
+	setAutoUpgradeImages(tc, version)
+	return tc
+}
+
+// setAutoUpgradeImages sets the components rolled to the version
+func setAutoUpgradeImages(tc *v1alpha1.TidbCluster, version string) {
+	sts := &apps.StatefulSetStatus{Replicas: 3, UpdatedReplicas: 3, CurrentRevision: version, UpdateRevision: version}
+	tc.Status.PD.StatefulSet = sts.DeepCopy()
+	tc.Status.TiKV.StatefulSet = sts.DeepCopy()
+	tc.Status.TiDB.StatefulSet = sts.DeepCopy()
+	tc.Status.PD.Image = "pingcap/pd:" + version
+	tc.Status.TiKV.Image = "pingcap/tikv:" + version
+	tc.Status.TiDB.Image = "pingcap/tidb:" + version + "-fips"
+}
+
+func TestAutoUpgradeManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewAutoUpgradeManager(deps).(*autoUpgradeManager)
+	fetched := 0
+	metadata := &releaseMetadata{Channels: map[string][]string{
+		"6.5-lts": {"v6.5.0", "v6.5.3", "v6.5.2", "v6.5.4-rc"},
+		"7.1-lts": {"v7.1.0"},
+	}}
+	m.fetchFn = func(_ *v1alpha1.TidbCluster) (*releaseMetadata, error) {
+		fetched++
+		return metadata, nil
+	}
+	// Saturday, 1 AM
+	now := time.Date(2022, 1, 1, 1, 0, 0, 0, time.Local)
+	m.now = func() time.Time { return now }
+
+	tc := newTidbClusterForAutoUpgrade("v6.5.0")
+	_, err := deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the maintenance window is not open
+	g.Expect(m.Sync(tc)).To(Succeed())
+	status := tc.Status.AutoUpgrade
+	g.Expect(status.LatestVersion).To(Equal("v6.5.3"))
+	g.Expect(status.Phase).To(BeEmpty())
+	g.Expect(status.Message).To(ContainSubstring("waiting for the maintenance window"))
+	g.Expect(tc.Spec.Version).To(Equal("v6.5.0"))
+
+	// the release metadata is not read again within the interval
+	now = now.Add(time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(fetched).To(Equal(1))
+
+	// the pre-check fails if any component doesn't use spec.version
+	now = now.Add(2 * time.Hour)
+	tc.Spec.TiDB.Version = pointer.StringPtr("v6.5.0")
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.AutoUpgrade.Message).To(ContainSubstring("pre-check failed: tidb don't use spec.version"))
+	g.Expect(tc.Spec.Version).To(Equal("v6.5.0"))
+
+	// the upgrade starts in the window
+	now = now.Add(autoUpgradeCheckInterval)
+	tc.Spec.TiDB.Version = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	status = tc.Status.AutoUpgrade
+	g.Expect(status.Phase).To(Equal(v1alpha1.AutoUpgradeUpgrading))
+	g.Expect(status.FromVersion).To(Equal("v6.5.0"))
+	g.Expect(status.TargetVersion).To(Equal("v6.5.3"))
+	g.Expect(tc.Spec.Version).To(Equal("v6.5.3"))
+	patched, err := deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Get(context.TODO(), tc.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(patched.Spec.Version).To(Equal("v6.5.3"))
+
+	// the upgrade completes once all the components are rolled and the cluster is ready
+	now = now.Add(10 * time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.AutoUpgrade.Phase).To(Equal(v1alpha1.AutoUpgradeUpgrading))
+	g.Expect(tc.Status.AutoUpgrade.Message).To(Equal("pd runs image pingcap/pd:v6.5.0 instead of v6.5.3"))
+	setAutoUpgradeImages(tc, "v6.5.3")
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.AutoUpgrade.Phase).To(Equal(v1alpha1.AutoUpgradeComplete))
+	g.Expect(tc.Status.AutoUpgrade.CompletionTime.Time).To(Equal(now))
+
+	// the upgrade fails if the cluster is not upgraded in time, and the version is not tried again
+	metadata.Channels["6.5-lts"] = append(metadata.Channels["6.5-lts"], "v6.5.4")
+	now = now.Add(autoUpgradeCheckInterval)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.AutoUpgrade.Phase).To(Equal(v1alpha1.AutoUpgradeUpgrading))
+	g.Expect(tc.Spec.Version).To(Equal("v6.5.4"))
+	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+	now = now.Add(2 * time.Hour)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.AutoUpgrade.Phase).To(Equal(v1alpha1.AutoUpgradeFailed))
+	g.Expect(tc.Status.AutoUpgrade.Message).To(ContainSubstring("tikv is in phase Upgrade"))
+	tc.Spec.Version = "v6.5.3"
+	now = now.Add(autoUpgradeCheckInterval)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.AutoUpgrade.Phase).To(Equal(v1alpha1.AutoUpgradeFailed))
+	g.Expect(tc.Spec.Version).To(Equal("v6.5.3"))
+
+	// the status is removed with the policy
+	tc.Spec.UpgradePolicy = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.AutoUpgrade).To(BeNil())
+}
+
+func TestInMaintenanceWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	window := &v1alpha1.MaintenanceWindow{Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: 4 * time.Hour}}
+	// 2022-01-01 is a Saturday
+	for _, tt := range []struct {
+		now      time.Time
+		expected bool
+	}{
+		{time.Date(2022, 1, 1, 1, 59, 0, 0, time.Local), false},
+		{time.Date(2022, 1, 1, 2, 0, 0, 0, time.Local), true},
+		{time.Date(2022, 1, 1, 5, 59, 0, 0, time.Local), true},
+		{time.Date(2022, 1, 1, 6, 1, 0, 0, time.Local), false},
+		{time.Date(2022, 1, 2, 3, 0, 0, 0, time.Local), false},
+	} {
+		in, err := inMaintenanceWindow(window, tt.now)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(in).To(Equal(tt.expected), tt.now.String())
+	}
+	in, err := inMaintenanceWindow(nil, time.Now())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(in).To(BeTrue())
+}
+
+func TestImageHasVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(imageHasVersion("pingcap/tikv:v6.5.1", "v6.5.1")).To(BeTrue())
+	g.Expect(imageHasVersion("registry:5000/pingcap/tikv:v6.5.1-fips@sha256:abc", "v6.5.1")).To(BeTrue())
+	g.Expect(imageHasVersion("pingcap/tikv:v6.5.10", "v6.5.1")).To(BeFalse())
+	g.Expect(imageHasVersion("registry:5000/pingcap/tikv", "v6.5.1")).To(BeFalse())
+}
+
+func TestCheckReleaseMetadataURL(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	hosts := "releases.example.com, mirror.local"
+	g.Expect(checkReleaseMetadataURL("https://releases.example.com/releases.json", hosts)).To(Succeed())
+	g.Expect(checkReleaseMetadataURL("https://Mirror.local:8443/releases.json", hosts)).To(Succeed())
+	g.Expect(checkReleaseMetadataURL("http://releases.example.com/releases.json", hosts)).NotTo(Succeed())
+	g.Expect(checkReleaseMetadataURL("https://169.254.169.254/latest/meta-data", hosts)).NotTo(Succeed())
+	g.Expect(checkReleaseMetadataURL("https://releases.example.com/releases.json", "")).NotTo(Succeed())
+}
@@ -0,0 +1,38 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkpointReached returns whether the in-flight operation on the pod has finished the step
+func checkpointReached(checkpoint *v1alpha1.OperationCheckpoint, phase v1alpha1.MemberPhase, ordinal int32, step v1alpha1.OperationStep) bool {
+	return checkpoint != nil && checkpoint.Phase == phase && checkpoint.Ordinal == ordinal && checkpoint.Step == step
+}
+
+// setCheckpoint records the finished step of the in-flight operation on the pod, the checkpoint
+// is persisted with the status of the TidbCluster at the end of the sync
+func setCheckpoint(checkpoint **v1alpha1.OperationCheckpoint, phase v1alpha1.MemberPhase, ordinal int32, step v1alpha1.OperationStep) {
+	if checkpointReached(*checkpoint, phase, ordinal, step) {
+		return
+	}
+	*checkpoint = &v1alpha1.OperationCheckpoint{
+		Phase:              phase,
+		Ordinal:            ordinal,
+		Step:               step,
+		LastTransitionTime: metav1.Now(),
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// ComponentRemover removes the components listed in the annotation tidb.pingcap.com/remove-components
+// safely, the component is decommissioned before its StatefulSet and Service are deleted:
+//
+//   - TiFlash: waits until the TiFlash stores of other clusters sharing the PD are enough for the placement
+//     rules on TiFlash, i.e. the TiFlash replicas of all tables are set to 0 if there are no such stores,
+//     then deletes the stores from PD and waits until they're tombstone
+//   - TiCDC: waits until all changefeeds are removed, which can't be replicated without any capture, then
+//     drains the captures gracefully and scales them in one by one until the last one
+//
+// Nothing is done while the cluster is paused. The PVCs and the ConfigMaps are left as they are, they can
+// be collected by the orphan gc controller.
+type ComponentRemover interface {
+	// Remove returns nil once the component is removed, and a requeue error if it's waiting
+	Remove(tc *v1alpha1.TidbCluster, typ v1alpha1.MemberType) error
+}
+
+type componentRemover struct {
+	deps *controller.Dependencies
+}
+
+// NewComponentRemover returns a ComponentRemover
+func NewComponentRemover(deps *controller.Dependencies) ComponentRemover {
+	return &componentRemover{
+		deps: deps,
+	}
+}
+
+func (r *componentRemover) Remove(tc *v1alpha1.TidbCluster, typ v1alpha1.MemberType) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	if tc.Spec.Paused {
+		klog.V(4).Infof("tidb cluster %s/%s is paused, skip removing %s", ns, tcName, typ)
+		return nil
+	}
+
+	var setName, svcName string
+	switch typ {
+	case v1alpha1.TiFlashMemberType:
+		setName, svcName = controller.TiFlashMemberName(tcName), controller.TiFlashPeerMemberName(tcName)
+	case v1alpha1.TiCDCMemberType:
+		setName, svcName = controller.TiCDCMemberName(tcName), controller.TiCDCPeerMemberName(tcName)
+	default:
+		return fmt.Errorf("removing component %s is not supported", typ)
+	}
+
+	set, err := r.deps.StatefulSetLister.StatefulSets(ns).Get(setName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("component remover: failed to get sts %s/%s, error: %v", ns, setName, err)
+	}
+	if err == nil {
+		switch typ {
+		case v1alpha1.TiFlashMemberType:
+			err = r.decommissionTiFlash(tc)
+		case v1alpha1.TiCDCMemberType:
+			err = r.decommissionTiCDC(tc, set)
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.deps.StatefulSetControl.DeleteStatefulSet(tc, set); err != nil {
+			return err
+		}
+		klog.Infof("component remover: %s of tc %s/%s is decommissioned, sts %s is deleted", typ, ns, tcName, setName)
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "ComponentRemoved", "%s is decommissioned and removed", typ)
+	}
+
+	svc, err := r.deps.ServiceLister.Services(ns).Get(svcName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("component remover: failed to get svc %s/%s, error: %v", ns, svcName, err)
+	}
+	if err == nil {
+		if err := r.deps.ServiceControl.DeleteService(tc, svc); err != nil {
+			return err
+		}
+	}
+
+	switch typ {
+	case v1alpha1.TiFlashMemberType:
+		tc.Status.TiFlash = v1alpha1.TiFlashStatus{}
+	case v1alpha1.TiCDCMemberType:
+		tc.Status.TiCDC = v1alpha1.TiCDCStatus{}
+	}
+	return nil
+}
+
+func (r *componentRemover) decommissionTiFlash(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	pdClient := controller.GetPDClient(r.deps.PDControl, tc)
+
+	ids := map[uint64]struct{}{}
+	for _, store := range tc.Status.TiFlash.Stores {
+		id, err := strconv.ParseUint(store.ID, 10, 64)
+		if err != nil {
+			return err
+		}
+		ids[id] = struct{}{}
+	}
+
+	// the PD may be shared with other clusters, e.g. heterogeneous clusters, the learner peers
+	// placed on TiFlash can be moved to their TiFlash stores
+	storesInfo, err := pdClient.GetStores()
+	if err != nil {
+		return fmt.Errorf("component remover: failed to get stores of tc %s/%s, error: %v", ns, tcName, err)
+	}
+	others := 0
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil || !isTiFlashStore(store.Store.Store) || store.Store.GetState() != metapb.StoreState_Up {
+			continue
+		}
+		if _, ok := ids[store.Store.GetId()]; !ok {
+			others++
+		}
+	}
+
+	// the stores never become tombstone if there are learner peers which can't be placed elsewhere
+	rules, err := pdClient.GetPlacementRules()
+	if err != nil {
+		return fmt.Errorf("component remover: failed to get placement rules of tc %s/%s, error: %v", ns, tcName, err)
+	}
+	var ruleIDs []string
+	for _, rule := range rules {
+		if isTiFlashPlacementRule(rule) && rule.Count > others {
+			ruleIDs = append(ruleIDs, rule.GroupID+"/"+rule.ID)
+		}
+	}
+	if len(ruleIDs) > 0 {
+		msg := fmt.Sprintf("TiFlash is not removed until the TiFlash replicas of all tables are at most %d, placement rules on TiFlash: %v", others, ruleIDs)
+		r.deps.Recorder.Event(tc, corev1.EventTypeWarning, "ComponentRemovalBlocked", msg)
+		return controller.RequeueErrorf("tc %s/%s: %s", ns, tcName, msg)
+	}
+
+	remaining, err := offlineStores(pdClient, ids, func(id uint64) {
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "StoreDeleted", "TiFlash store %d is deleted from PD for removing TiFlash", id)
+	})
+	if err != nil {
+		return fmt.Errorf("component remover: failed to delete TiFlash stores of tc %s/%s, error: %v", ns, tcName, err)
+	}
+	if len(remaining) > 0 {
+		return controller.RequeueErrorf("tc %s/%s: waiting for TiFlash stores %v to be tombstone", ns, tcName, remaining)
+	}
+	return nil
+}
+
+func (r *componentRemover) decommissionTiCDC(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	replicas := *set.Spec.Replicas
+	if replicas == 0 {
+		return nil
+	}
+
+	var changefeeds []controller.ChangefeedInfo
+	listed := false
+	for ordinal := int32(0); ordinal < replicas; ordinal++ {
+		cfs, err := r.deps.CDCControl.ListChangefeeds(tc, ordinal)
+		if err != nil {
+			klog.V(4).Infof("component remover: failed to list changefeeds from TiCDC %d of tc %s/%s, error: %v", ordinal, ns, tcName, err)
+			continue
+		}
+		changefeeds, listed = cfs, true
+		break
+	}
+	if !listed {
+		return controller.RequeueErrorf("tc %s/%s: failed to list changefeeds from any TiCDC", ns, tcName)
+	}
+
+	var ids []string
+	for _, cf := range changefeeds {
+		if cf.State != "removed" && cf.State != "finished" {
+			ids = append(ids, ticdcChangefeedKey(cf))
+		}
+	}
+	if len(ids) > 0 {
+		sort.Strings(ids)
+		msg := fmt.Sprintf("TiCDC is not removed until all changefeeds are removed, changefeeds: %v", ids)
+		r.deps.Recorder.Event(tc, corev1.EventTypeWarning, "ComponentRemovalBlocked", msg)
+		return controller.RequeueErrorf("tc %s/%s: %s", ns, tcName, msg)
+	}
+	if replicas == 1 {
+		return nil
+	}
+
+	// The captures are drained and scaled in one by one, the last one can't be drained as there is
+	// no other capture to move its tables to.
+	ordinal := replicas - 1
+	podName := ordinalPodName(v1alpha1.TiCDCMemberType, tcName, ordinal)
+	pod, err := r.deps.PodLister.Pods(ns).Get(podName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("component remover: failed to get pod %s/%s, error: %v", ns, podName, err)
+	}
+	if err == nil {
+		if err := gracefulShutdownTiCDC(tc, r.deps.CDCControl, r.deps.PodControl, pod, ordinal, "Remove"); err != nil {
+			return err
+		}
+	}
+	newSet := set.DeepCopy()
+	newSet.Spec.Replicas = &ordinal
+	if _, err := r.deps.StatefulSetControl.UpdateStatefulSet(tc, newSet); err != nil {
+		return err
+	}
+	return controller.RequeueErrorf("tc %s/%s: TiCDC %s is drained, %d captures remain", ns, tcName, podName, ordinal)
+}
+
+// isTiFlashStore returns whether the store is a TiFlash store
+func isTiFlashStore(store *metapb.Store) bool {
+	for _, l := range store.GetLabels() {
+		if l.GetKey() == "engine" && l.GetValue() == "tiflash" {
+			return true
+		}
+	}
+	return false
+}
+
+// offlineStores deletes the stores of ids which are up from PD, and returns the ids of the stores
+// which are not tombstone yet, onDeleted is called for each store deleted
+func offlineStores(pdClient pdapi.PDClient, ids map[uint64]struct{}, onDeleted func(id uint64)) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	storesInfo, err := pdClient.GetStores()
+	if err != nil {
+		return nil, err
+	}
+	var remaining []string
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil {
+			continue
+		}
+		id := store.Store.GetId()
+		if _, ok := ids[id]; !ok || store.Store.GetState() == metapb.StoreState_Tombstone {
+			continue
+		}
+		if store.Store.GetState() == metapb.StoreState_Up {
+			if err := pdClient.DeleteStore(id); err != nil {
+				return nil, err
+			}
+			onDeleted(id)
+		}
+		remaining = append(remaining, strconv.FormatUint(id, 10))
+	}
+	sort.Strings(remaining)
+	return remaining, nil
+}
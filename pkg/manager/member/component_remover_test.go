@@ -0,0 +1,150 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestComponentRemoverRemoveTiFlash(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	remover := NewComponentRemover(deps)
+
+	tc := newTidbClusterForPD()
+	tc.Annotations = map[string]string{label.AnnRemoveComponents: "tiflash"}
+	tc.Status.TiFlash.Stores = map[string]v1alpha1.TiKVStore{
+		"5": {ID: "5", PodName: "test-tiflash-0", State: v1alpha1.TiKVStateUp},
+	}
+	g.Expect(tc.ComponentRemoving(v1alpha1.TiFlashMemberType)).To(BeTrue())
+	g.Expect(tc.ComponentRemoving(v1alpha1.TiCDCMemberType)).To(BeFalse())
+
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: controller.TiFlashMemberName(tc.Name)},
+		Spec:       apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(1)},
+	}
+	g.Expect(deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(set)).To(Succeed())
+
+	rules := []*pdapi.PlacementRule{{
+		GroupID:          "tiflash",
+		ID:               "table-45-r",
+		Role:             "learner",
+		Count:            1,
+		LabelConstraints: []pdapi.LabelConstraint{{Key: "engine", Op: "in", Values: []string{"tiflash"}}},
+	}}
+	state := metapb.StoreState_Up
+	otherTiFlash := &pdapi.StoreInfo{Store: &pdapi.MetaStore{Store: &metapb.Store{
+		Id:     7,
+		State:  metapb.StoreState_Offline,
+		Labels: []*metapb.StoreLabel{{Key: "engine", Value: "tiflash"}},
+	}}}
+	var deleted []uint64
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetPlacementRulesActionType, func(action *pdapi.Action) (interface{}, error) {
+		return rules, nil
+	})
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{
+			Stores: []*pdapi.StoreInfo{
+				{Store: &pdapi.MetaStore{Store: &metapb.Store{Id: 1, State: metapb.StoreState_Up}}},
+				{Store: &pdapi.MetaStore{Store: &metapb.Store{Id: 5, State: state}}},
+				otherTiFlash,
+			},
+		}, nil
+	})
+	pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		deleted = append(deleted, action.ID)
+		return nil, nil
+	})
+
+	// blocked by the placement rules on TiFlash
+	err := remover.Remove(tc, v1alpha1.TiFlashMemberType)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("tiflash/table-45-r"))
+	g.Expect(deleted).To(BeEmpty())
+
+	// nothing is done while the cluster is paused
+	tc.Spec.Paused = true
+	g.Expect(remover.Remove(tc, v1alpha1.TiFlashMemberType)).To(Succeed())
+	tc.Spec.Paused = false
+
+	// the TiFlash store of another cluster sharing the PD is enough for the placement rules
+	otherTiFlash.Store.State = metapb.StoreState_Up
+	err = remover.Remove(tc, v1alpha1.TiFlashMemberType)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deleted).To(Equal([]uint64{5}))
+
+	state = metapb.StoreState_Tombstone
+	g.Expect(remover.Remove(tc, v1alpha1.TiFlashMemberType)).To(Succeed())
+	g.Expect(tc.Status.TiFlash.Stores).To(BeEmpty())
+}
+
+func TestComponentRemoverRemoveTiCDC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	remover := NewComponentRemover(deps)
+
+	tc := newTidbClusterForCDC()
+	tc.Spec.TiCDC.Replicas = 0
+	tc.Annotations = map[string]string{label.AnnRemoveComponents: "tiflash, ticdc"}
+	tc.Status.TiCDC.Phase = v1alpha1.NormalPhase
+	g.Expect(tc.ComponentRemoving(v1alpha1.TiCDCMemberType)).To(BeTrue())
+
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: controller.TiCDCMemberName(tc.Name)},
+		Spec:       apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(2)},
+	}
+	g.Expect(deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(set)).To(Succeed())
+
+	changefeeds := []controller.ChangefeedInfo{
+		{Namespace: "default", ID: "cf-1", State: "normal"},
+		{Namespace: "default", ID: "cf-2", State: "removed"},
+	}
+	deps.CDCControl.(*controller.FakeTiCDCControl).MockListChangefeeds(func(tc *v1alpha1.TidbCluster, ordinal int32) ([]controller.ChangefeedInfo, error) {
+		if ordinal == 0 {
+			return nil, fmt.Errorf("capture 0 is down")
+		}
+		return changefeeds, nil
+	})
+
+	err := remover.Remove(tc, v1alpha1.TiCDCMemberType)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("cf-1"))
+	g.Expect(err.Error()).NotTo(ContainSubstring("cf-2"))
+
+	// the captures are scaled in one by one until the last one
+	changefeeds = changefeeds[1:]
+	err = remover.Remove(tc, v1alpha1.TiCDCMemberType)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	set, err = deps.StatefulSetLister.StatefulSets(tc.Namespace).Get(set.Name)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(1)))
+	g.Expect(remover.Remove(tc, v1alpha1.TiCDCMemberType)).To(Succeed())
+	g.Expect(tc.Status.TiCDC.Phase).To(BeEmpty())
+
+	g.Expect(remover.Remove(tc, v1alpha1.TiDBMemberType)).NotTo(Succeed())
+}
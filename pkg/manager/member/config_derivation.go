@@ -0,0 +1,119 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// tikvBlockCacheRatio is the same as the default ratio of TiKV, which is
+	// computed from the memory of the host rather than the container
+	tikvBlockCacheRatio = 45
+	// tidbServerMemoryQuotaRatio leaves some memory for the go runtime
+	tidbServerMemoryQuotaRatio = 80
+	// pdServerMemoryLimitRatio is the ratio of the memory limit of the container
+	pdServerMemoryLimitRatio = 0.8
+)
+
+// setDerivedConfig sets the item unless it's set explicitly, the items set are recorded in derived
+func setDerivedConfig(c *config.GenericConfig, derived map[string]string, key string, value interface{}) {
+	if c.Get(key) != nil {
+		return
+	}
+	c.Set(key, value)
+	derived[key] = fmt.Sprint(value)
+}
+
+// deriveTiKVConfig derives the block cache capacity of TiKV from the memory of the container
+func deriveTiKVConfig(tc *v1alpha1.TidbCluster, c *config.GenericConfig, req corev1.ResourceRequirements) map[string]string {
+	if !tc.DeriveConfigFromResources() {
+		return nil
+	}
+	derived := map[string]string{}
+	if memory, ok := containerResourceLimit(req, corev1.ResourceMemory); ok {
+		// TiKV treats MB as MiB
+		setDerivedConfig(c, derived, "storage.block-cache.capacity", fmt.Sprintf("%dMB", memory.Value()*tikvBlockCacheRatio/100>>20))
+	}
+	if len(derived) == 0 {
+		return nil
+	}
+	return derived
+}
+
+// deriveTiDBConfig derives the max procs and the server memory quota of TiDB from the resources of the container
+func deriveTiDBConfig(tc *v1alpha1.TidbCluster, c *config.GenericConfig, req corev1.ResourceRequirements) map[string]string {
+	if !tc.DeriveConfigFromResources() {
+		return nil
+	}
+	derived := map[string]string{}
+	if cpu, ok := containerResourceLimit(req, corev1.ResourceCPU); ok {
+		setDerivedConfig(c, derived, "performance.max-procs", (cpu.MilliValue()+999)/1000)
+	}
+	if memory, ok := containerResourceLimit(req, corev1.ResourceMemory); ok {
+		setDerivedConfig(c, derived, "performance.server-memory-quota", memory.Value()*tidbServerMemoryQuotaRatio/100)
+	}
+	if len(derived) == 0 {
+		return nil
+	}
+	return derived
+}
+
+// derivePDConfig derives the memory limit of PD if the memory of the container is limited,
+// PD measures the ratio against the memory limit of the cgroup
+func derivePDConfig(tc *v1alpha1.TidbCluster, c *config.GenericConfig, req corev1.ResourceRequirements) map[string]string {
+	if !tc.DeriveConfigFromResources() {
+		return nil
+	}
+	derived := map[string]string{}
+	if memory, ok := req.Limits[corev1.ResourceMemory]; ok && !memory.IsZero() {
+		setDerivedConfig(c, derived, "pd-server.server-memory-limit", pdServerMemoryLimitRatio)
+	}
+	if len(derived) == 0 {
+		return nil
+	}
+	return derived
+}
+
+// pdDerivedConfig returns the config items of PD derived from the resources, which are the same
+// as the ones rendered in the ConfigMap
+func pdDerivedConfig(tc *v1alpha1.TidbCluster) map[string]string {
+	if tc.Spec.PD == nil || tc.Spec.PD.Config == nil {
+		return nil
+	}
+	return derivePDConfig(tc, tc.Spec.PD.Config.DeepCopy().GenericConfig, tc.Spec.PD.ResourceRequirements)
+}
+
+// tikvDerivedConfig returns the config items of TiKV derived from the resources, which are the same
+// as the ones rendered in the ConfigMap
+func tikvDerivedConfig(tc *v1alpha1.TidbCluster) map[string]string {
+	if tc.Spec.TiKV == nil || tc.Spec.TiKV.Config == nil {
+		return nil
+	}
+	return deriveTiKVConfig(tc, tc.Spec.TiKV.Config.DeepCopy().GenericConfig, tc.Spec.TiKV.ResourceRequirements)
+}
+
+// tidbDerivedConfig returns the config items of TiDB derived from the resources, which are the same
+// as the ones rendered in the ConfigMap
+func tidbDerivedConfig(tc *v1alpha1.TidbCluster) map[string]string {
+	if tc.Spec.TiDB == nil || tc.Spec.TiDB.Config == nil {
+		return nil
+	}
+	return deriveTiDBConfig(tc, tc.Spec.TiDB.Config.DeepCopy().GenericConfig, tc.Spec.TiDB.ResourceRequirements)
+}
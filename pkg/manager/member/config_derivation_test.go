@@ -0,0 +1,85 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+func TestDeriveConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{}
+	req := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1500m"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+		},
+	}
+
+	// nothing is derived by default
+	tikvConfig := v1alpha1.NewTiKVConfig()
+	g.Expect(deriveTiKVConfig(tc, tikvConfig.GenericConfig, req)).To(BeNil())
+	g.Expect(tikvConfig.Get("storage.block-cache.capacity")).To(BeNil())
+
+	tc.Spec.DeriveConfigFromResources = pointer.BoolPtr(true)
+	g.Expect(deriveTiKVConfig(tc, tikvConfig.GenericConfig, req)).To(Equal(map[string]string{
+		"storage.block-cache.capacity": "3686MB",
+	}))
+	g.Expect(tikvConfig.Get("storage.block-cache.capacity").MustString()).To(Equal("3686MB"))
+
+	// the items set explicitly are not overridden
+	tidbConfig := v1alpha1.NewTiDBConfig()
+	tidbConfig.Set("performance.max-procs", int64(8))
+	g.Expect(deriveTiDBConfig(tc, tidbConfig.GenericConfig, req)).To(Equal(map[string]string{
+		"performance.server-memory-quota": "6871947673",
+	}))
+	g.Expect(tidbConfig.Get("performance.max-procs").MustInt()).To(Equal(int64(8)))
+
+	// PD memory limit is derived only if the memory is limited
+	pdConfig := v1alpha1.NewPDConfig()
+	g.Expect(derivePDConfig(tc, pdConfig.GenericConfig, req)).To(BeNil())
+	req.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")}
+	g.Expect(derivePDConfig(tc, pdConfig.GenericConfig, req)).To(Equal(map[string]string{
+		"pd-server.server-memory-limit": "0.8",
+	}))
+}
+
+func TestDerivedConfigStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.DeriveConfigFromResources = pointer.BoolPtr(true)
+	tc.Spec.TiDB.Config = v1alpha1.NewTiDBConfig()
+	tc.Spec.TiDB.ResourceRequirements = corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+	}
+
+	// rendering the ConfigMap doesn't touch the status
+	cm, err := getTiDBConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("max-procs = 2"))
+	g.Expect(tc.Status.TiDB.DerivedConfig).To(BeNil())
+	g.Expect(tc.Spec.TiDB.Config.Get("performance.max-procs")).To(BeNil())
+
+	g.Expect(tidbDerivedConfig(tc)).To(Equal(map[string]string{"performance.max-procs": "2"}))
+	tc.Spec.TiDB.Config = nil
+	g.Expect(tidbDerivedConfig(tc)).To(BeNil())
+}
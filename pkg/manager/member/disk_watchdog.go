@@ -0,0 +1,403 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	errutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultDiskUsageThresholdPercent = 85
+	defaultDiskAutoExpandStepPercent = 20
+	defaultSoftLimitThresholdPercent = 75
+	defaultSoftLimitAddPeerRate      = 1
+
+	// DiskUsageExceeded is the reason of DiskPressure condition when the disk usage of any store exceeds the threshold
+	DiskUsageExceeded = "DiskUsageExceeded"
+	// DiskUsageNormal is the reason of DiskPressure condition when the disk usage of all stores is below the threshold
+	DiskUsageNormal = "DiskUsageNormal"
+	// DiskAutoExpanded is the reason of the event emitted when a data volume is expanded by the disk watchdog
+	DiskAutoExpanded = "DiskAutoExpanded"
+	// StoreSoftLimited is the reason of the event emitted when the add-peer limit of a store is lowered
+	StoreSoftLimited = "StoreSoftLimited"
+	// StoreSoftLimitRestored is the reason of the event emitted when the add-peer limit of a store is restored
+	StoreSoftLimitRestored = "StoreSoftLimitRestored"
+)
+
+// DiskWatchdogInterface watches the disk usage of TiKV and TiFlash stores reported by PD,
+// and takes the actions configured in spec.diskWatchdog of the tidb cluster:
+//
+//   - raise the DiskPressure condition and emit a warning event if the usage of any store
+//     exceeds the threshold
+//   - expand the data volumes of the stores under pressure if autoExpand is set, the
+//     storage request is increased by stepPercent at a time until maxStorage
+//   - lower the add-peer store limit of the stores approaching their capacity if softLimit
+//     is set, and restore it when the usage drops
+//
+// The storage request in spec is not changed, so the expanded volumes are left as they
+// are by the PVC resizer, which never shrinks volumes.
+type DiskWatchdogInterface interface {
+	Sync(*v1alpha1.TidbCluster) error
+}
+
+type storeDiskUsage struct {
+	storeID     string
+	memberType  v1alpha1.MemberType
+	podName     string
+	usedPercent int
+}
+
+type diskWatchdog struct {
+	deps *controller.Dependencies
+}
+
+// NewDiskWatchdog returns a DiskWatchdogInterface
+func NewDiskWatchdog(deps *controller.Dependencies) DiskWatchdogInterface {
+	return &diskWatchdog{
+		deps: deps,
+	}
+}
+
+func (w *diskWatchdog) Sync(tc *v1alpha1.TidbCluster) error {
+	spec := tc.Spec.DiskWatchdog
+	if spec == nil {
+		// restore the store limits lowered before the disk watchdog is removed
+		return w.syncSoftLimits(tc, nil, nil)
+	}
+	if len(tc.Status.TiKV.Stores) == 0 && len(tc.Status.TiFlash.Stores) == 0 {
+		return nil
+	}
+
+	threshold := int32(defaultDiskUsageThresholdPercent)
+	if spec.UsageThresholdPercent != nil {
+		threshold = *spec.UsageThresholdPercent
+	}
+
+	storesInfo, err := controller.GetPDClient(w.deps.PDControl, tc).GetStores()
+	if err != nil {
+		return fmt.Errorf("disk watchdog: failed to get stores of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+
+	usages := []storeDiskUsage{}
+	pressured := []storeDiskUsage{}
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil || store.Status == nil || store.Status.Capacity == 0 {
+			continue
+		}
+		storeID := fmt.Sprintf("%d", store.Store.GetId())
+		usage := storeDiskUsage{storeID: storeID}
+		if s, ok := tc.Status.TiKV.Stores[storeID]; ok {
+			usage.memberType, usage.podName = v1alpha1.TiKVMemberType, s.PodName
+		} else if s, ok := tc.Status.TiFlash.Stores[storeID]; ok {
+			usage.memberType, usage.podName = v1alpha1.TiFlashMemberType, s.PodName
+		} else {
+			continue
+		}
+		capacity, available := uint64(store.Status.Capacity), uint64(store.Status.Available)
+		if available > capacity {
+			available = capacity
+		}
+		usage.usedPercent = int((capacity - available) * 100 / capacity)
+		usages = append(usages, usage)
+		if usage.usedPercent >= int(threshold) {
+			pressured = append(pressured, usage)
+		}
+	}
+	sort.Slice(pressured, func(i, j int) bool {
+		return pressured[i].podName < pressured[j].podName
+	})
+
+	w.setDiskPressureCondition(tc, pressured, threshold)
+
+	errs := []error{}
+	if err := w.syncSoftLimits(tc, spec.SoftLimit, usages); err != nil {
+		errs = append(errs, err)
+	}
+	if spec.AutoExpand == nil {
+		return errutil.NewAggregate(errs)
+	}
+	for _, usage := range pressured {
+		if err := w.expandVolumes(tc, spec.AutoExpand, usage); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errutil.NewAggregate(errs)
+}
+
+func (w *diskWatchdog) setDiskPressureCondition(tc *v1alpha1.TidbCluster, pressured []storeDiskUsage, threshold int32) {
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterDiskPressure)
+	inPressure := cond != nil && cond.Status == corev1.ConditionTrue
+
+	if len(pressured) == 0 {
+		if !inPressure {
+			return
+		}
+		msg := fmt.Sprintf("disk usage of all stores is below %d%%", threshold)
+		newCond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterDiskPressure, corev1.ConditionFalse, DiskUsageNormal, msg)
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *newCond)
+		w.deps.Recorder.Event(tc, corev1.EventTypeNormal, DiskUsageNormal, msg)
+		return
+	}
+
+	pods := make([]string, 0, len(pressured))
+	usages := make([]string, 0, len(pressured))
+	for _, usage := range pressured {
+		pods = append(pods, usage.podName)
+		usages = append(usages, fmt.Sprintf("%s (%d%%)", usage.podName, usage.usedPercent))
+	}
+	// the usages are not put in the condition to avoid updating the status in every sync
+	msg := fmt.Sprintf("disk usage of stores %s exceeds %d%%", strings.Join(pods, ", "), threshold)
+
+	if !inPressure {
+		newCond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterDiskPressure, corev1.ConditionTrue, DiskUsageExceeded, msg)
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *newCond)
+		w.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, string(v1alpha1.TidbClusterDiskPressure),
+			"disk usage of stores %s exceeds %d%%", strings.Join(usages, ", "), threshold)
+		return
+	}
+	// SetTidbClusterCondition doesn't update the message if the status is unchanged,
+	// update it in place so that it always shows the stores under pressure
+	for i := range tc.Status.Conditions {
+		c := &tc.Status.Conditions[i]
+		if c.Type == v1alpha1.TidbClusterDiskPressure && c.Message != msg {
+			c.Message = msg
+			c.LastUpdateTime = metav1.Now()
+		}
+	}
+}
+
+// syncSoftLimits lowers the add-peer limit of the stores whose usage exceeds the threshold of policy
+// and restores the others recorded in status.softLimitedStores, all of them are restored if policy
+// is nil. The records of the stores removed from the cluster are dropped.
+func (w *diskWatchdog) syncSoftLimits(tc *v1alpha1.TidbCluster, policy *v1alpha1.StoreSoftLimit, usages []storeDiskUsage) error {
+	if policy == nil && len(tc.Status.SoftLimitedStores) == 0 {
+		return nil
+	}
+	pdClient := controller.GetPDClient(w.deps.PDControl, tc)
+
+	threshold := int32(defaultSoftLimitThresholdPercent)
+	rate := int32(defaultSoftLimitAddPeerRate)
+	if policy != nil {
+		if policy.UsageThresholdPercent != nil {
+			threshold = *policy.UsageThresholdPercent
+		}
+		if policy.AddPeerRate != nil {
+			rate = *policy.AddPeerRate
+		}
+	}
+
+	limited := tc.Status.SoftLimitedStores
+	if limited == nil {
+		limited = map[string]v1alpha1.SoftLimitedStore{}
+	}
+	errs := []error{}
+	approaching := map[string]bool{}
+	var limits map[uint64]*pdapi.StoreLimit
+	for _, usage := range usages {
+		if policy == nil || usage.usedPercent < int(threshold) {
+			continue
+		}
+		approaching[usage.storeID] = true
+		if _, ok := limited[usage.storeID]; ok {
+			continue
+		}
+		id, err := strconv.ParseUint(usage.storeID, 10, 64)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if limits == nil {
+			if limits, err = pdClient.GetStoreLimits(); err != nil {
+				return fmt.Errorf("disk watchdog: failed to get store limits of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+			}
+		}
+		limit, ok := limits[id]
+		if !ok {
+			errs = append(errs, fmt.Errorf("disk watchdog: store limit of store %s is not found in PD", usage.storeID))
+			continue
+		}
+		if err := pdClient.SetStoreLimit(id, pdapi.StoreLimitAddPeer, float64(rate)); err != nil {
+			errs = append(errs, fmt.Errorf("disk watchdog: failed to lower the add-peer limit of store %s, error: %v", usage.storeID, err))
+			continue
+		}
+		original := strconv.FormatFloat(limit.AddPeer, 'f', -1, 64)
+		limited[usage.storeID] = v1alpha1.SoftLimitedStore{PodName: usage.podName, OriginalAddPeerRate: original}
+		klog.Infof("disk watchdog: add-peer limit of store %s (%s) of tc %s/%s is lowered from %s to %d",
+			usage.storeID, usage.podName, tc.GetNamespace(), tc.GetName(), original, rate)
+		w.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, StoreSoftLimited, "add-peer limit of store %s (%s) is lowered from %s to %d as its disk usage %d%% exceeds %d%%",
+			usage.storeID, usage.podName, original, rate, usage.usedPercent, threshold)
+	}
+
+	for storeID, store := range limited {
+		if approaching[storeID] {
+			continue
+		}
+		_, isTiKV := tc.Status.TiKV.Stores[storeID]
+		_, isTiFlash := tc.Status.TiFlash.Stores[storeID]
+		if !isTiKV && !isTiFlash {
+			delete(limited, storeID)
+			continue
+		}
+		id, err := strconv.ParseUint(storeID, 10, 64)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		original, err := strconv.ParseFloat(store.OriginalAddPeerRate, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("disk watchdog: invalid original add-peer limit %q of store %s", store.OriginalAddPeerRate, storeID))
+			continue
+		}
+		if err := pdClient.SetStoreLimit(id, pdapi.StoreLimitAddPeer, original); err != nil {
+			errs = append(errs, fmt.Errorf("disk watchdog: failed to restore the add-peer limit of store %s, error: %v", storeID, err))
+			continue
+		}
+		delete(limited, storeID)
+		klog.Infof("disk watchdog: add-peer limit of store %s (%s) of tc %s/%s is restored to %s",
+			storeID, store.PodName, tc.GetNamespace(), tc.GetName(), store.OriginalAddPeerRate)
+		w.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StoreSoftLimitRestored, "add-peer limit of store %s (%s) is restored to %s",
+			storeID, store.PodName, store.OriginalAddPeerRate)
+	}
+
+	if len(limited) == 0 {
+		limited = nil
+	}
+	tc.Status.SoftLimitedStores = limited
+	return errutil.NewAggregate(errs)
+}
+
+func (w *diskWatchdog) expandVolumes(tc *v1alpha1.TidbCluster, policy *v1alpha1.DiskAutoExpand, usage storeDiskUsage) error {
+	var volNames []v1alpha1.StorageVolumeName
+	switch usage.memberType {
+	case v1alpha1.TiKVMemberType:
+		volNames = append(volNames, v1alpha1.GetStorageVolumeName("", v1alpha1.TiKVMemberType))
+	case v1alpha1.TiFlashMemberType:
+		if tc.Spec.TiFlash != nil {
+			for i := range tc.Spec.TiFlash.StorageClaims {
+				volNames = append(volNames, v1alpha1.GetStorageVolumeNameForTiFlash(i))
+			}
+		}
+	}
+
+	step := int64(defaultDiskAutoExpandStepPercent)
+	if policy.StepPercent != nil {
+		step = int64(*policy.StepPercent)
+	}
+
+	errs := []error{}
+	for _, volName := range volNames {
+		pvcName := fmt.Sprintf("%s-%s", volName, usage.podName)
+		pvc, err := w.deps.PVCLister.PersistentVolumeClaims(tc.GetNamespace()).Get(pvcName)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("disk watchdog: failed to get PVC %s/%s, error: %v", tc.GetNamespace(), pvcName, err))
+			continue
+		}
+		if err := w.expandVolume(tc, pvc, step, policy.MaxStorage); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errutil.NewAggregate(errs)
+}
+
+func (w *diskWatchdog) expandVolume(tc *v1alpha1.TidbCluster, pvc *corev1.PersistentVolumeClaim, step int64, maxStorage resource.Quantity) error {
+	pvcID := fmt.Sprintf("%s/%s", pvc.Namespace, pvc.Name)
+
+	currentRequest, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return fmt.Errorf("disk watchdog: expand PVC %s failed: storage request is empty", pvcID)
+	}
+	// expand the volume again only after the previous expansion is finished
+	currentCapacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if !ok || currentRequest.Cmp(currentCapacity) > 0 {
+		klog.V(4).Infof("disk watchdog: PVC %s is resizing, skip expanding it", pvcID)
+		return nil
+	}
+	if currentRequest.Cmp(maxStorage) >= 0 {
+		klog.Warningf("disk watchdog: skip expanding PVC %s: storage request %s reaches the max storage %s",
+			pvcID, currentRequest.String(), maxStorage.String())
+		return nil
+	}
+	if pvc.Spec.StorageClassName == nil {
+		klog.Warningf("disk watchdog: skip expanding PVC %s: PVC have no storage class", pvcID)
+		return nil
+	}
+	if w.deps.StorageClassLister != nil {
+		volumeExpansionSupported, err := isVolumeExpansionSupported(w.deps.StorageClassLister, *pvc.Spec.StorageClassName)
+		if err != nil {
+			return err
+		}
+		if !volumeExpansionSupported {
+			klog.Warningf("disk watchdog: skip expanding PVC %s: storage class %q does not support volume expansion",
+				pvcID, *pvc.Spec.StorageClassName)
+			return nil
+		}
+	}
+
+	desiredRequest := resource.NewQuantity(currentRequest.Value()*(100+step)/100, currentRequest.Format)
+	if desiredRequest.Cmp(maxStorage) > 0 {
+		maxRequest := maxStorage.DeepCopy()
+		desiredRequest = &maxRequest
+	}
+
+	mergePatch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"resources": corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: *desiredRequest,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("disk watchdog: expand PVC %s failed: %s", pvcID, err)
+	}
+	_, err = w.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(context.TODO(), pvc.Name, types.MergePatchType, mergePatch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("disk watchdog: expand PVC %s failed: %s", pvcID, err)
+	}
+
+	klog.Infof("disk watchdog: expand PVC %s of tc %s/%s: storage request is updated from %s to %s",
+		pvcID, tc.GetNamespace(), tc.GetName(), currentRequest.String(), desiredRequest.String())
+	w.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, DiskAutoExpanded, "PVC %s is expanded from %s to %s",
+		pvc.Name, currentRequest.String(), desiredRequest.String())
+	return nil
+}
+
+type fakeDiskWatchdog struct{}
+
+func (f *fakeDiskWatchdog) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
+
+// NewFakeDiskWatchdog returns a fake DiskWatchdogInterface
+func NewFakeDiskWatchdog() DiskWatchdogInterface {
+	return &fakeDiskWatchdog{}
+}
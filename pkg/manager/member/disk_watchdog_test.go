@@ -0,0 +1,233 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	"github.com/tikv/pd/pkg/typeutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestDiskWatchdogSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	scName := "sc"
+
+	tests := []struct {
+		name         string
+		available    uint64
+		autoExpand   *v1alpha1.DiskAutoExpand
+		pvcRequest   string
+		pvcCapacity  string
+		inPressure   bool
+		expectCond   corev1.ConditionStatus
+		expectReason string
+		expectPVC    string
+	}{
+		{
+			name:        "usage is below the threshold",
+			available:   50,
+			pvcRequest:  "100Gi",
+			pvcCapacity: "100Gi",
+			expectPVC:   "100Gi",
+		},
+		{
+			name:         "usage exceeds the threshold without auto expand",
+			available:    10,
+			pvcRequest:   "100Gi",
+			pvcCapacity:  "100Gi",
+			expectCond:   corev1.ConditionTrue,
+			expectReason: DiskUsageExceeded,
+			expectPVC:    "100Gi",
+		},
+		{
+			name:         "usage exceeds the threshold with auto expand",
+			available:    10,
+			autoExpand:   &v1alpha1.DiskAutoExpand{MaxStorage: resource.MustParse("1Ti")},
+			pvcRequest:   "100Gi",
+			pvcCapacity:  "100Gi",
+			expectCond:   corev1.ConditionTrue,
+			expectReason: DiskUsageExceeded,
+			expectPVC:    "120Gi",
+		},
+		{
+			name:         "expansion is limited by max storage",
+			available:    10,
+			autoExpand:   &v1alpha1.DiskAutoExpand{StepPercent: pointer.Int32Ptr(50), MaxStorage: resource.MustParse("110Gi")},
+			pvcRequest:   "100Gi",
+			pvcCapacity:  "100Gi",
+			expectCond:   corev1.ConditionTrue,
+			expectReason: DiskUsageExceeded,
+			expectPVC:    "110Gi",
+		},
+		{
+			name:         "volume is resizing",
+			available:    10,
+			autoExpand:   &v1alpha1.DiskAutoExpand{MaxStorage: resource.MustParse("1Ti")},
+			pvcRequest:   "120Gi",
+			pvcCapacity:  "100Gi",
+			expectCond:   corev1.ConditionTrue,
+			expectReason: DiskUsageExceeded,
+			expectPVC:    "120Gi",
+		},
+		{
+			name:         "disk pressure is relieved",
+			available:    50,
+			pvcRequest:   "120Gi",
+			pvcCapacity:  "120Gi",
+			inPressure:   true,
+			expectCond:   corev1.ConditionFalse,
+			expectReason: DiskUsageNormal,
+			expectPVC:    "120Gi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := newTidbClusterForPD()
+			tc.Spec.DiskWatchdog = &v1alpha1.DiskWatchdog{AutoExpand: tt.autoExpand}
+			podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), 0)
+			tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+				"1": {ID: "1", PodName: podName, State: v1alpha1.TiKVStateUp},
+			}
+			if tt.inPressure {
+				cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterDiskPressure, corev1.ConditionTrue, DiskUsageExceeded, "")
+				utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+			}
+
+			fakeDeps := controller.NewFakeDependencies()
+			watchdog := &diskWatchdog{deps: fakeDeps}
+
+			pvc := newMockPVC("tikv-"+podName, scName, tt.pvcRequest, tt.pvcCapacity)
+			fakeDeps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)
+			fakeDeps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+			fakeDeps.KubeInformerFactory.Storage().V1().StorageClasses().Informer().GetIndexer().Add(newStorageClass(scName, true))
+
+			pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+			pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+				return &pdapi.StoresInfo{
+					Count: 1,
+					Stores: []*pdapi.StoreInfo{
+						{
+							Store:  &pdapi.MetaStore{Store: &metapb.Store{Id: 1}},
+							Status: &pdapi.StoreStatus{Capacity: typeutil.ByteSize(100), Available: typeutil.ByteSize(tt.available)},
+						},
+					},
+				}, nil
+			})
+
+			err := watchdog.Sync(tc)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterDiskPressure)
+			if tt.expectCond == "" {
+				g.Expect(cond).To(BeNil())
+			} else {
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(tt.expectCond))
+				g.Expect(cond.Reason).To(Equal(tt.expectReason))
+			}
+
+			pvc, err = fakeDeps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(context.TODO(), pvc.Name, metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			g.Expect(request.Cmp(resource.MustParse(tt.expectPVC))).To(Equal(0), "storage request: %s", request.String())
+		})
+	}
+}
+
+func TestDiskWatchdogSoftLimit(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.DiskWatchdog = &v1alpha1.DiskWatchdog{
+		SoftLimit: &v1alpha1.StoreSoftLimit{AddPeerRate: pointer.Int32Ptr(2)},
+	}
+	podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), 0)
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: podName, State: v1alpha1.TiKVStateUp},
+	}
+
+	fakeDeps := controller.NewFakeDependencies()
+	watchdog := &diskWatchdog{deps: fakeDeps}
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	available := uint64(50)
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{
+			Count: 1,
+			Stores: []*pdapi.StoreInfo{
+				{
+					Store:  &pdapi.MetaStore{Store: &metapb.Store{Id: 1}},
+					Status: &pdapi.StoreStatus{Capacity: typeutil.ByteSize(100), Available: typeutil.ByteSize(available)},
+				},
+			},
+		}, nil
+	})
+	pdClient.AddReaction(pdapi.GetStoreLimitsActionType, func(action *pdapi.Action) (interface{}, error) {
+		return map[uint64]*pdapi.StoreLimit{1: {AddPeer: 15, RemovePeer: 15}}, nil
+	})
+	var rates []float64
+	pdClient.AddReaction(pdapi.SetStoreLimitActionType, func(action *pdapi.Action) (interface{}, error) {
+		g.Expect(action.ID).To(Equal(uint64(1)))
+		g.Expect(action.LimitType).To(Equal(pdapi.StoreLimitAddPeer))
+		rates = append(rates, action.Rate)
+		return nil, nil
+	})
+
+	// usage is below the threshold
+	g.Expect(watchdog.Sync(tc)).To(Succeed())
+	g.Expect(rates).To(BeEmpty())
+	g.Expect(tc.Status.SoftLimitedStores).To(BeNil())
+
+	// usage exceeds the threshold, the limit is lowered only once
+	available = 20
+	g.Expect(watchdog.Sync(tc)).To(Succeed())
+	g.Expect(watchdog.Sync(tc)).To(Succeed())
+	g.Expect(rates).To(Equal([]float64{2}))
+	g.Expect(tc.Status.SoftLimitedStores).To(Equal(map[string]v1alpha1.SoftLimitedStore{
+		"1": {PodName: podName, OriginalAddPeerRate: "15"},
+	}))
+
+	// usage drops below the threshold
+	available = 50
+	g.Expect(watchdog.Sync(tc)).To(Succeed())
+	g.Expect(rates).To(Equal([]float64{2, 15}))
+	g.Expect(tc.Status.SoftLimitedStores).To(BeNil())
+
+	// the disk watchdog is removed
+	available = 20
+	g.Expect(watchdog.Sync(tc)).To(Succeed())
+	tc.Spec.DiskWatchdog = nil
+	g.Expect(watchdog.Sync(tc)).To(Succeed())
+	g.Expect(rates).To(Equal([]float64{2, 15, 2, 15}))
+	g.Expect(tc.Status.SoftLimitedStores).To(BeNil())
+
+	// the record of the removed store is dropped
+	tc.Status.SoftLimitedStores = map[string]v1alpha1.SoftLimitedStore{
+		"2": {PodName: "removed", OriginalAddPeerRate: "15"},
+	}
+	g.Expect(watchdog.Sync(tc)).To(Succeed())
+	g.Expect(rates).To(HaveLen(4))
+	g.Expect(tc.Status.SoftLimitedStores).To(BeNil())
+}
@@ -240,7 +240,7 @@ func (m *masterMemberManager) syncMasterStatefulSetForDMCluster(dc *v1alpha1.DMC
 	// Perform failover logic if necessary. Note that this will only update
 	// DMCluster status. The actual scaling performs in next sync loop (if a
 	// new replica needs to be added).
-	if m.deps.CLIConfig.AutoFailover {
+	if m.deps.CLIConfig.IsAutoFailover() {
 		if m.shouldRecover(dc) {
 			m.failover.Recover(dc)
 		} else if dc.MasterAllPodsStarted() && !dc.MasterAllMembersReady() || dc.MasterAutoFailovering() {
@@ -649,6 +649,7 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 		VolumeMounts: volMounts,
 		Resources:    controller.ContainerResource(dc.Spec.Master.ResourceRequirements),
 	}
+	setProbes(&masterContainer, dc.Spec.Master.Probes, probeHandlers{port: 8261, statusPort: 8261, statusPath: "/status"})
 	env := []corev1.EnvVar{
 		{
 			Name: "NAMESPACE",
@@ -698,6 +699,7 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 
 	var initContainers []corev1.Container // no default initContainers now
 	podSpec.InitContainers = append(initContainers, baseMasterSpec.InitContainers()...)
+	dc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.DMMasterMemberType.String())
 
 	updateStrategy := apps.StatefulSetUpdateStrategy{}
 	if baseMasterSpec.StatefulSetUpdateStrategy() == apps.OnDeleteStatefulSetStrategyType {
@@ -48,7 +48,7 @@ func (f *workerFailover) Failover(dc *v1alpha1.DMCluster) error {
 			// (before it enters into Offline/Tombstone state)
 			continue
 		}
-		deadline := worker.LastTransitionTime.Add(f.deps.CLIConfig.WorkerFailoverPeriod)
+		deadline := worker.LastTransitionTime.Add(f.deps.CLIConfig.GetFailoverPeriod(v1alpha1.DMWorkerMemberType))
 		exist := false
 		for _, failureWorker := range dc.Status.Worker.FailureMembers {
 			if failureWorker.PodName == podName {
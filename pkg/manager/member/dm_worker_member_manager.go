@@ -226,7 +226,7 @@ func (m *workerMemberManager) syncWorkerStatefulSetForDMCluster(dc *v1alpha1.DMC
 	// Perform failover logic if necessary. Note that this will only update
 	// DMCluster status. The actual scaling performs in next sync loop (if a
 	// new replica needs to be added).
-	if m.deps.CLIConfig.AutoFailover && dc.Spec.Worker.MaxFailoverCount != nil {
+	if m.deps.CLIConfig.IsAutoFailover() && dc.Spec.Worker.MaxFailoverCount != nil {
 		if dc.WorkerAllPodsStarted() && !dc.WorkerAllMembersReady() {
 			if err := m.failover.Failover(dc); err != nil {
 				return err
@@ -460,6 +460,7 @@ func getNewWorkerSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 		VolumeMounts: volMounts,
 		Resources:    controller.ContainerResource(dc.Spec.Worker.ResourceRequirements),
 	}
+	setProbes(&workerContainer, dc.Spec.Worker.Probes, probeHandlers{port: 8262, statusPort: 8262, statusPath: "/status"})
 	env := []corev1.EnvVar{
 		{
 			Name: "NAMESPACE",
@@ -509,6 +510,7 @@ func getNewWorkerSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 
 	var initContainers []corev1.Container // no default initContainers now
 	podSpec.InitContainers = append(initContainers, baseWorkerSpec.InitContainers()...)
+	dc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.DMWorkerMemberType.String())
 
 	workerSet := &apps.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
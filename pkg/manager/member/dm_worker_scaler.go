@@ -21,6 +21,7 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
 	apps "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
@@ -96,6 +97,11 @@ func (s *workerScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, new
 
 	klog.Infof("scaling in dm-worker statefulset %s/%s, ordinal: %d (replicas: %d, delete slots: %v)", oldSet.Namespace, oldSet.Name, ordinal, replicas, deleteSlots.List())
 
+	podName := ordinalPodName(v1alpha1.DMWorkerMemberType, dcName, ordinal)
+	if err := s.transferSourceOut(dc, podName); err != nil {
+		return err
+	}
+
 	pvcName := ordinalPVCName(v1alpha1.DMWorkerMemberType, setName, ordinal)
 	pvc, err := s.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
 	if err != nil {
@@ -121,6 +127,46 @@ func (s *workerScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, new
 	return nil
 }
 
+// transferSourceOut transfers the source bound to the dm-worker to a free dm-worker before the dm-worker
+// is scaled in, so that the replication of the source is moved before the pod is deleted, like the leaders
+// are evicted before a TiKV store is deleted. If there is no free dm-worker or the openapi of dm-master is
+// not enabled, the source is rebound by dm-master after the keepalive lease of the dm-worker is outdated.
+func (s *workerScaler) transferSourceOut(dc *v1alpha1.DMCluster, podName string) error {
+	ns := dc.GetNamespace()
+	dcName := dc.GetName()
+	dmClient := controller.GetMasterClient(s.deps.DMMasterControl, dc)
+	workers, err := dmClient.GetWorkers()
+	if err != nil {
+		return fmt.Errorf("dm-worker.ScaleIn: failed to get dm-workers of cluster %s/%s, error: %v", ns, dcName, err)
+	}
+
+	var bound, free *dmapi.WorkersInfo
+	for _, worker := range workers {
+		if worker.Name == podName {
+			bound = worker
+		} else if worker.Stage == v1alpha1.DMWorkerStateFree && free == nil && isWorkerPodDesired(dc, worker.Name) {
+			free = worker
+		}
+	}
+	if bound == nil || bound.Stage != v1alpha1.DMWorkerStateBound || bound.Source == "" {
+		return nil
+	}
+	if free == nil {
+		klog.Infof("dm-worker.ScaleIn: no free dm-worker to bind source %s of %s in cluster %s/%s", bound.Source, podName, ns, dcName)
+		return nil
+	}
+
+	err = dmClient.TransferSource(bound.Source, free.Name)
+	if err == dmapi.ErrOpenAPIUnavailable {
+		klog.Infof("dm-worker.ScaleIn: openapi of dm-master is not enabled in cluster %s/%s, skip transferring source %s of %s", ns, dcName, bound.Source, podName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("dm-worker.ScaleIn: failed to transfer source %s from %s to %s in cluster %s/%s, error: %v", bound.Source, podName, free.Name, ns, dcName, err)
+	}
+	return controller.RequeueErrorf("dm-worker.ScaleIn: transferring source %s from %s to %s in cluster %s/%s, wait for next round", bound.Source, podName, free.Name, ns, dcName)
+}
+
 type fakeWorkerScaler struct{}
 
 // NewFakeWorkerScaler returns a fake Scaler
@@ -161,6 +161,9 @@ func TestWorkerScalerScaleIn(t *testing.T) {
 		err              bool
 		changed          bool
 		isLeader         bool
+		bound            bool
+		transferErr      error
+		transferred      bool
 	}
 
 	testFn := func(test testcase, t *testing.T) {
@@ -169,7 +172,27 @@ func TestWorkerScalerScaleIn(t *testing.T) {
 		newSet := oldSet.DeepCopy()
 		newSet.Spec.Replicas = pointer.Int32Ptr(3)
 
-		scaler, _, pvcIndexer, pvcControl := newFakeWorkerScaler()
+		scaler, masterControl, pvcIndexer, pvcControl := newFakeWorkerScaler()
+
+		masterClient := dmapi.NewFakeMasterClient()
+		masterControl.SetMasterClient(dc.GetNamespace(), dc.GetName(), masterClient)
+		masterClient.AddReaction(dmapi.GetWorkersActionType, func(action *dmapi.Action) (interface{}, error) {
+			scaleInWorker := &dmapi.WorkersInfo{Name: ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), 4), Stage: v1alpha1.DMWorkerStateFree}
+			if test.bound {
+				scaleInWorker.Stage = v1alpha1.DMWorkerStateBound
+				scaleInWorker.Source = "mysql-replica-01"
+			}
+			return []*dmapi.WorkersInfo{
+				{Name: ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), 0), Stage: v1alpha1.DMWorkerStateFree},
+				scaleInWorker,
+			}, nil
+		})
+		transferred := false
+		masterClient.AddReaction(dmapi.TransferSourceActionType, func(action *dmapi.Action) (interface{}, error) {
+			transferred = true
+			g.Expect(action.Name).To(Equal(ordinalPodName(v1alpha1.DMWorkerMemberType, dc.GetName(), 0)))
+			return nil, test.transferErr
+		})
 
 		if test.hasPVC {
 			pvc := newScaleInPVCForStatefulSet(oldSet, v1alpha1.DMWorkerMemberType, dc.Name)
@@ -196,6 +219,7 @@ func TestWorkerScalerScaleIn(t *testing.T) {
 		} else {
 			g.Expect(int(*newSet.Spec.Replicas)).To(Equal(5))
 		}
+		g.Expect(transferred).To(Equal(test.transferred))
 	}
 
 	tests := []testcase{
@@ -231,6 +255,28 @@ func TestWorkerScalerScaleIn(t *testing.T) {
 			err:              true,
 			changed:          false,
 		},
+		{
+			name:             "transfer source of bound dm-worker",
+			hasPVC:           true,
+			pvcUpdateErr:     false,
+			statusSyncFailed: false,
+			err:              true,
+			changed:          false,
+			isLeader:         true,
+			bound:            true,
+			transferred:      true,
+		},
+		{
+			name:             "dm-master openapi is not enabled",
+			hasPVC:           true,
+			pvcUpdateErr:     false,
+			statusSyncFailed: false,
+			err:              false,
+			changed:          true,
+			bound:            true,
+			transferErr:      dmapi.ErrOpenAPIUnavailable,
+			transferred:      true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,92 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	tmpVolumeName = "tmp"
+	tmpMountPath  = "/tmp"
+	// tidbTmpStorageQuotaRatio leaves some space of /tmp for the other temporary files of TiDB
+	tidbTmpStorageQuotaRatio = 80
+)
+
+// applyEphemeralStorage renders the ephemeral storage of the component to the main container
+// of the pod, and mounts the emptyDir volume of /tmp if its size is limited
+func applyEphemeralStorage(spec *v1alpha1.EphemeralStorage, podSpec *corev1.PodSpec, mainContainer string) {
+	if spec == nil {
+		return
+	}
+	var container *corev1.Container
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == mainContainer {
+			container = &podSpec.Containers[i]
+			break
+		}
+	}
+	if container == nil {
+		return
+	}
+
+	res := &container.Resources
+	if spec.Request != nil {
+		if res.Requests == nil {
+			res.Requests = corev1.ResourceList{}
+		}
+		res.Requests[corev1.ResourceEphemeralStorage] = *spec.Request
+	}
+	if spec.Limit != nil {
+		if res.Limits == nil {
+			res.Limits = corev1.ResourceList{}
+		}
+		res.Limits[corev1.ResourceEphemeralStorage] = *spec.Limit
+	}
+
+	if spec.TmpSizeLimit != nil {
+		sizeLimit := spec.TmpSizeLimit.DeepCopy()
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: tmpVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      tmpVolumeName,
+			MountPath: tmpMountPath,
+		})
+	}
+}
+
+// setTiDBTmpStorageQuota limits the spilled intermediate results of TiDB by the size of /tmp,
+// unless tmp-storage-quota is set or tmp-storage-path is not under /tmp
+func setTiDBTmpStorageQuota(spec *v1alpha1.EphemeralStorage, c *config.GenericConfig) {
+	if spec == nil || spec.TmpSizeLimit == nil || c.Get("tmp-storage-quota") != nil {
+		return
+	}
+	if v := c.Get("tmp-storage-path"); v != nil {
+		p, err := v.AsString()
+		if err != nil || !strings.HasPrefix(path.Clean(p)+"/", tmpMountPath+"/") {
+			return
+		}
+	}
+	c.Set("tmp-storage-quota", spec.TmpSizeLimit.Value()*tidbTmpStorageQuotaRatio/100)
+}
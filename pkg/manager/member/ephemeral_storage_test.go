@@ -0,0 +1,106 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestApplyEphemeralStorage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	request := resource.MustParse("1Gi")
+	limit := resource.MustParse("10Gi")
+	tmpSizeLimit := resource.MustParse("5Gi")
+	spec := &v1alpha1.EphemeralStorage{
+		Request:      &request,
+		Limit:        &limit,
+		TmpSizeLimit: &tmpSizeLimit,
+	}
+	podSpec := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "tidb", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}}},
+			{Name: "slowlog"},
+		},
+	}
+
+	applyEphemeralStorage(spec, podSpec, "tidb")
+	tidb := podSpec.Containers[0]
+	g.Expect(tidb.Resources.Requests).To(Equal(corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("1"),
+		corev1.ResourceEphemeralStorage: request,
+	}))
+	g.Expect(tidb.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceEphemeralStorage: limit}))
+	g.Expect(tidb.VolumeMounts).To(Equal([]corev1.VolumeMount{{Name: tmpVolumeName, MountPath: tmpMountPath}}))
+	g.Expect(podSpec.Volumes).To(HaveLen(1))
+	g.Expect(podSpec.Volumes[0].EmptyDir.SizeLimit.String()).To(Equal("5Gi"))
+	// the sidecar containers are not changed
+	g.Expect(podSpec.Containers[1]).To(Equal(corev1.Container{Name: "slowlog"}))
+
+	// nothing is changed without the spec
+	podSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "tidb"}}}
+	applyEphemeralStorage(nil, podSpec, "tidb")
+	g.Expect(podSpec.Volumes).To(BeEmpty())
+	g.Expect(podSpec.Containers[0]).To(Equal(corev1.Container{Name: "tidb"}))
+}
+
+func TestSetTiDBTmpStorageQuota(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tmpSizeLimit := resource.MustParse("10Gi")
+	spec := &v1alpha1.EphemeralStorage{TmpSizeLimit: &tmpSizeLimit}
+
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		expected interface{}
+	}{
+		{
+			name:     "default tmp-storage-path",
+			config:   map[string]interface{}{},
+			expected: int64(8589934592),
+		},
+		{
+			name:     "tmp-storage-path under /tmp",
+			config:   map[string]interface{}{"tmp-storage-path": "/tmp/tidb"},
+			expected: int64(8589934592),
+		},
+		{
+			name:   "tmp-storage-path not under /tmp",
+			config: map[string]interface{}{"tmp-storage-path": "/var/lib/tidb-tmp"},
+		},
+		{
+			name:     "tmp-storage-quota set explicitly",
+			config:   map[string]interface{}{"tmp-storage-quota": int64(1024)},
+			expected: int64(1024),
+		},
+	}
+	for _, tt := range tests {
+		c := config.New(tt.config)
+		setTiDBTmpStorageQuota(spec, c)
+		v := c.Get("tmp-storage-quota")
+		if tt.expected == nil {
+			g.Expect(v).To(BeNil(), tt.name)
+			continue
+		}
+		g.Expect(v).NotTo(BeNil(), tt.name)
+		g.Expect(v.Interface()).To(Equal(tt.expected), tt.name)
+	}
+}
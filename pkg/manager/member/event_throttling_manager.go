@@ -0,0 +1,379 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	errutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	tikvIORateLimitConfig         = "storage.io-rate-limit.max-bytes-per-sec"
+	tikvCompactionRateLimitConfig = "rocksdb.rate-bytes-per-sec"
+	pdRegionScheduleLimitConfig   = "region-schedule-limit"
+	pdReplicaScheduleLimitConfig  = "replica-schedule-limit"
+
+	// EventThrottlingStarted is the reason of the event emitted when the limits are lowered by spec.eventThrottling
+	EventThrottlingStarted = "EventThrottlingStarted"
+	// EventThrottlingEnded is the reason of the event emitted when the limits lowered by spec.eventThrottling are restored
+	EventThrottlingEnded = "EventThrottlingEnded"
+)
+
+type eventThrottlingManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+	// tikvApplied is the limits set to the TiKV stores, keyed by namespace/name/storeID, with the
+	// values like uid/restarts/items of the Pods, so that the limits are set again only if they are
+	// changed, or the stores are new or restarted
+	tikvApplied sync.Map
+}
+
+// NewEventThrottlingManager returns a manager which lowers the IO rate limits of TiKV and the
+// schedule limits of PD by spec.eventThrottling while the events moving lots of data between
+// the stores are in progress. The original values are recorded in status.eventThrottling, and
+// restored after the cooldown period once all the events end or when the policy is removed.
+func NewEventThrottlingManager(deps *controller.Dependencies) manager.Manager {
+	return &eventThrottlingManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (m *eventThrottlingManager) Sync(tc *v1alpha1.TidbCluster) error {
+	policy := tc.Spec.EventThrottling
+	status := tc.Status.EventThrottling
+	if policy == nil && status == nil {
+		return nil
+	}
+	// the limits can't be changed or restored without PD, they are synced after PD recovers
+	if !tc.PDIsAvailable() {
+		return nil
+	}
+	if policy == nil || tc.Spec.TiKV == nil {
+		if status == nil {
+			return nil
+		}
+		return m.restore(tc)
+	}
+
+	events, err := eventsInProgress(m.deps, tc, tc.EventThrottlingEvents())
+	if err != nil {
+		return err
+	}
+	now := m.now()
+	if len(events) == 0 {
+		if status == nil {
+			return nil
+		}
+		status.Events = nil
+		if now.Before(status.LastEventTime.Add(tc.EventThrottlingCooldownPeriod())) {
+			// keep the limits of the stores started in the cooldown period
+			return m.apply(tc, policy)
+		}
+		return m.restore(tc)
+	}
+
+	if status == nil {
+		status = &v1alpha1.EventThrottlingStatus{StartTime: metav1.Time{Time: now}}
+		tc.Status.EventThrottling = status
+		klog.Infof("event throttling: limits of tc %s/%s are lowered during events %v", tc.GetNamespace(), tc.GetName(), events)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, EventThrottlingStarted, "limits are lowered during events %v", events)
+	}
+	status.Events = events
+	status.LastEventTime = metav1.Time{Time: now}
+	return m.apply(tc, policy)
+}
+
+// eventsInProgress returns the events in progress among the given events
+func eventsInProgress(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, events []v1alpha1.ThrottledEvent) ([]v1alpha1.ThrottledEvent, error) {
+	inProgress := []v1alpha1.ThrottledEvent{}
+	for _, event := range events {
+		var active bool
+		switch event {
+		case v1alpha1.ThrottledEventScale:
+			active = tc.Status.TiKV.Phase == v1alpha1.ScalePhase
+		case v1alpha1.ThrottledEventFailover:
+			active = len(tc.Status.TiKV.FailureStores) > 0
+		case v1alpha1.ThrottledEventRestore:
+			var err error
+			if active, err = restoreRunning(deps, tc); err != nil {
+				return nil, err
+			}
+		}
+		if active {
+			inProgress = append(inProgress, event)
+		}
+	}
+	return inProgress, nil
+}
+
+// restoreRunning returns whether any Restore to the cluster is running
+func restoreRunning(deps *controller.Dependencies, tc *v1alpha1.TidbCluster) (bool, error) {
+	restores, err := deps.RestoreLister.List(labels.Everything())
+	if err != nil {
+		return false, fmt.Errorf("failed to list restores, error: %v", err)
+	}
+	for _, restore := range restores {
+		if restore.Spec.BR == nil || restore.Spec.BR.Cluster != tc.Name {
+			continue
+		}
+		ns := restore.Spec.BR.ClusterNamespace
+		if ns == "" {
+			ns = restore.Namespace
+		}
+		if ns != tc.Namespace {
+			continue
+		}
+		if v1alpha1.IsRestoreRunning(restore) && !v1alpha1.IsRestoreComplete(restore) && !v1alpha1.IsRestoreFailed(restore) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// apply sets the limits of policy to PD if they drift, and to the up TiKV stores which are new or
+// restarted or whose limits are changed. The original values of the items not recorded in status
+// are read and recorded before they are changed.
+func (m *eventThrottlingManager) apply(tc *v1alpha1.TidbCluster, policy *v1alpha1.EventThrottlingPolicy) error {
+	status := tc.Status.EventThrottling
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	stores := upTiKVStores(tc)
+
+	scheduleItems := map[string]uint64{}
+	if policy.RegionScheduleLimit != nil {
+		scheduleItems[pdRegionScheduleLimitConfig] = *policy.RegionScheduleLimit
+	}
+	if policy.ReplicaScheduleLimit != nil {
+		scheduleItems[pdReplicaScheduleLimitConfig] = *policy.ReplicaScheduleLimit
+	}
+	if len(scheduleItems) > 0 {
+		current, err := m.recordScheduleConfig(tc, pdClient, scheduleItems)
+		if err != nil {
+			return err
+		}
+		config := map[string]interface{}{}
+		for name, value := range scheduleItems {
+			if current[name] == nil || *current[name] != value {
+				config[name] = value
+			}
+		}
+		if len(config) > 0 {
+			if err := pdClient.UpdateScheduleConfig(config); err != nil {
+				return fmt.Errorf("event throttling: failed to update the schedule config of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+			}
+		}
+	}
+
+	tikvItems := map[string]string{}
+	if policy.IORateLimit != "" {
+		tikvItems[tikvIORateLimitConfig] = policy.IORateLimit
+	}
+	if policy.CompactionRateLimit != "" {
+		tikvItems[tikvCompactionRateLimitConfig] = policy.CompactionRateLimit
+	}
+	if len(tikvItems) == 0 {
+		return nil
+	}
+	stores, applied := m.storesToApply(tc, stores, tikvItems)
+	if len(stores) == 0 {
+		return nil
+	}
+	var missing []string
+	for name := range tikvItems {
+		if _, ok := status.OriginalTiKVConfig[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		originals, err := m.tikvClient(tc, stores[0]).GetConfigItems(missing...)
+		if err != nil {
+			return fmt.Errorf("event throttling: failed to get the config of store %s (%s), error: %v", stores[0].ID, stores[0].PodName, err)
+		}
+		if status.OriginalTiKVConfig == nil {
+			status.OriginalTiKVConfig = map[string]string{}
+		}
+		for name, value := range originals {
+			status.OriginalTiKVConfig[name] = value
+		}
+	}
+	errs := []error{}
+	for i, store := range stores {
+		if err := m.tikvClient(tc, store).SetConfigItems(tikvItems); err != nil {
+			errs = append(errs, fmt.Errorf("event throttling: failed to set the config of store %s (%s), error: %v", store.ID, store.PodName, err))
+			continue
+		}
+		m.tikvApplied.Store(tikvAppliedKey(tc, store), applied[i])
+	}
+	return errutil.NewAggregate(errs)
+}
+
+// storesToApply returns the stores whose limits are not set since they started, and the values of
+// them in tikvApplied after the limits are set
+func (m *eventThrottlingManager) storesToApply(tc *v1alpha1.TidbCluster, stores []v1alpha1.TiKVStore, items map[string]string) ([]v1alpha1.TiKVStore, []string) {
+	pairs := make([]string, 0, len(items))
+	for name, value := range items {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	itemsStr := strings.Join(pairs, ",")
+
+	var toApply []v1alpha1.TiKVStore
+	var applied []string
+	for _, store := range stores {
+		var uid string
+		var restarts int32
+		// the Pod not found is treated as a new store, its limits are set every time
+		if pod, err := m.deps.PodLister.Pods(tc.GetNamespace()).Get(store.PodName); err == nil {
+			uid = string(pod.UID)
+			for _, status := range pod.Status.ContainerStatuses {
+				if status.Name == v1alpha1.TiKVMemberType.String() {
+					restarts = status.RestartCount
+				}
+			}
+		}
+		value := fmt.Sprintf("%s/%d/%s", uid, restarts, itemsStr)
+		if v, ok := m.tikvApplied.Load(tikvAppliedKey(tc, store)); ok && uid != "" && v.(string) == value {
+			continue
+		}
+		toApply = append(toApply, store)
+		applied = append(applied, value)
+	}
+	return toApply, applied
+}
+
+func tikvAppliedKey(tc *v1alpha1.TidbCluster, store v1alpha1.TiKVStore) string {
+	return fmt.Sprintf("%s/%s/%s", tc.GetNamespace(), tc.GetName(), store.ID)
+}
+
+// recordScheduleConfig records the original values of the schedule config items not recorded in status,
+// and returns the current values of the items
+func (m *eventThrottlingManager) recordScheduleConfig(tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient, items map[string]uint64) (map[string]*uint64, error) {
+	status := tc.Status.EventThrottling
+	config, err := pdClient.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("event throttling: failed to get the config of PD of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	if config.Schedule == nil {
+		return nil, fmt.Errorf("event throttling: schedule config of PD of tc %s/%s is not found", tc.GetNamespace(), tc.GetName())
+	}
+	originals := map[string]*uint64{
+		pdRegionScheduleLimitConfig:  config.Schedule.RegionScheduleLimit,
+		pdReplicaScheduleLimitConfig: config.Schedule.ReplicaScheduleLimit,
+	}
+	if status.OriginalScheduleConfig == nil {
+		status.OriginalScheduleConfig = map[string]string{}
+	}
+	for name := range items {
+		if _, ok := status.OriginalScheduleConfig[name]; ok {
+			continue
+		}
+		if originals[name] == nil {
+			return nil, fmt.Errorf("event throttling: %s is not found in the schedule config of PD of tc %s/%s", name, tc.GetNamespace(), tc.GetName())
+		}
+		status.OriginalScheduleConfig[name] = strconv.FormatUint(*originals[name], 10)
+	}
+	return originals, nil
+}
+
+// restore restores the original values recorded in status and removes the status
+func (m *eventThrottlingManager) restore(tc *v1alpha1.TidbCluster) error {
+	status := tc.Status.EventThrottling
+	if len(status.OriginalScheduleConfig) > 0 {
+		config := make(map[string]interface{}, len(status.OriginalScheduleConfig))
+		for name, value := range status.OriginalScheduleConfig {
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("event throttling: invalid original value %q of %s", value, name)
+			}
+			config[name] = v
+		}
+		if err := controller.GetPDClient(m.deps.PDControl, tc).UpdateScheduleConfig(config); err != nil {
+			return fmt.Errorf("event throttling: failed to restore the schedule config of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+		}
+	}
+	if len(status.OriginalTiKVConfig) > 0 {
+		if err := m.setTiKVConfig(tc, upTiKVStores(tc), status.OriginalTiKVConfig); err != nil {
+			return err
+		}
+	}
+	for _, store := range tc.Status.TiKV.Stores {
+		m.tikvApplied.Delete(tikvAppliedKey(tc, store))
+	}
+
+	tc.Status.EventThrottling = nil
+	klog.Infof("event throttling: limits of tc %s/%s are restored", tc.GetNamespace(), tc.GetName())
+	m.deps.Recorder.Event(tc, corev1.EventTypeNormal, EventThrottlingEnded, "limits are restored")
+	return nil
+}
+
+func (m *eventThrottlingManager) setTiKVConfig(tc *v1alpha1.TidbCluster, stores []v1alpha1.TiKVStore, items map[string]string) error {
+	errs := []error{}
+	for _, store := range stores {
+		if err := m.tikvClient(tc, store).SetConfigItems(items); err != nil {
+			errs = append(errs, fmt.Errorf("event throttling: failed to set the config of store %s (%s), error: %v", store.ID, store.PodName, err))
+		}
+	}
+	return errutil.NewAggregate(errs)
+}
+
+func (m *eventThrottlingManager) tikvClient(tc *v1alpha1.TidbCluster, store v1alpha1.TiKVStore) tikvapi.TiKVClient {
+	return m.deps.TiKVControl.GetTiKVPodClient(tc.GetNamespace(), tc.GetName(), store.PodName, tc.IsTLSClusterEnabled())
+}
+
+// upTiKVStores returns the up TiKV stores of the cluster sorted by the pod names
+func upTiKVStores(tc *v1alpha1.TidbCluster) []v1alpha1.TiKVStore {
+	stores := []v1alpha1.TiKVStore{}
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.State == v1alpha1.TiKVStateUp {
+			stores = append(stores, store)
+		}
+	}
+	sort.Slice(stores, func(i, j int) bool {
+		return stores[i].PodName < stores[j].PodName
+	})
+	return stores
+}
+
+type FakeEventThrottlingManager struct {
+	err error
+}
+
+func NewFakeEventThrottlingManager() *FakeEventThrottlingManager {
+	return &FakeEventThrottlingManager{}
+}
+
+func (m *FakeEventThrottlingManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeEventThrottlingManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
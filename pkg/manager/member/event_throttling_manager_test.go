@@ -0,0 +1,151 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventThrottlingManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	limit := uint64(4)
+	tc.Spec.EventThrottling = &v1alpha1.EventThrottlingPolicy{
+		IORateLimit:         "100MiB",
+		RegionScheduleLimit: &limit,
+	}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{"test-pd-0": {Name: "test-pd-0", Health: true}}
+	tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 1}
+	podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), 0)
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: podName, State: v1alpha1.TiKVStateUp},
+		"2": {ID: "2", PodName: "offline", State: v1alpha1.TiKVStateOffline},
+	}
+
+	fakeDeps := controller.NewFakeDependencies()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: podName, UID: "uid-0"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: v1alpha1.TiKVMemberType.String()},
+		}},
+	}
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+	m := NewEventThrottlingManager(fakeDeps).(*eventThrottlingManager)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	regionScheduleLimit := uint64(2048)
+	pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		current := regionScheduleLimit
+		return &pdapi.PDConfigFromAPI{Schedule: &pdapi.PDScheduleConfig{RegionScheduleLimit: &current}}, nil
+	})
+	var scheduleConfigs []map[string]interface{}
+	pdClient.AddReaction(pdapi.UpdateScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		scheduleConfigs = append(scheduleConfigs, action.Config)
+		regionScheduleLimit = action.Config[pdRegionScheduleLimitConfig].(uint64)
+		return nil, nil
+	})
+	tikvClient := tikvapi.NewFakeTiKVClient()
+	fakeDeps.TiKVControl.(*tikvapi.FakeTiKVControl).SetTiKVPodClient(tc.Namespace, tc.Name, podName, tikvClient)
+	tikvClient.AddReaction(tikvapi.GetConfigItemsActionType, func(action *tikvapi.Action) (interface{}, error) {
+		g.Expect(action.Names).To(Equal([]string{tikvIORateLimitConfig}))
+		return map[string]string{tikvIORateLimitConfig: "0KiB"}, nil
+	})
+	var tikvConfigs []map[string]string
+	tikvClient.AddReaction(tikvapi.SetConfigItemsActionType, func(action *tikvapi.Action) (interface{}, error) {
+		tikvConfigs = append(tikvConfigs, action.Items)
+		return nil, nil
+	})
+
+	// no event is in progress
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.EventThrottling).To(BeNil())
+	g.Expect(scheduleConfigs).To(BeEmpty())
+
+	// the limits are lowered during the scaling of TiKV
+	tc.Status.TiKV.Phase = v1alpha1.ScalePhase
+	g.Expect(m.Sync(tc)).To(Succeed())
+	status := tc.Status.EventThrottling
+	g.Expect(status).NotTo(BeNil())
+	g.Expect(status.Events).To(Equal([]v1alpha1.ThrottledEvent{v1alpha1.ThrottledEventScale}))
+	g.Expect(status.OriginalScheduleConfig).To(Equal(map[string]string{pdRegionScheduleLimitConfig: "2048"}))
+	g.Expect(status.OriginalTiKVConfig).To(Equal(map[string]string{tikvIORateLimitConfig: "0KiB"}))
+	g.Expect(scheduleConfigs).To(Equal([]map[string]interface{}{{pdRegionScheduleLimitConfig: uint64(4)}}))
+	g.Expect(tikvConfigs).To(Equal([]map[string]string{{tikvIORateLimitConfig: "100MiB"}}))
+
+	// a running restore to the cluster
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	restore := &v1alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "restore", Name: "restore"},
+		Spec:       v1alpha1.RestoreSpec{BR: &v1alpha1.BRConfig{Cluster: tc.Name, ClusterNamespace: tc.Namespace}},
+		Status: v1alpha1.RestoreStatus{Conditions: []v1alpha1.RestoreCondition{
+			{Type: v1alpha1.RestoreRunning, Status: corev1.ConditionTrue},
+		}},
+	}
+	g.Expect(fakeDeps.InformerFactory.Pingcap().V1alpha1().Restores().Informer().GetIndexer().Add(restore)).To(Succeed())
+	now = now.Add(time.Hour)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.EventThrottling.Events).To(Equal([]v1alpha1.ThrottledEvent{v1alpha1.ThrottledEventRestore}))
+	g.Expect(tc.Status.EventThrottling.LastEventTime.Time).To(Equal(now))
+	// the limits set are not set again
+	g.Expect(scheduleConfigs).To(HaveLen(1))
+	g.Expect(tikvConfigs).To(HaveLen(1))
+
+	// the limits are set again if they drift or the store restarts
+	regionScheduleLimit = 2048
+	pod.Status.ContainerStatuses[0].RestartCount = 1
+	g.Expect(podIndexer.Update(pod)).To(Succeed())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(scheduleConfigs).To(HaveLen(2))
+	g.Expect(scheduleConfigs[1]).To(Equal(map[string]interface{}{pdRegionScheduleLimitConfig: uint64(4)}))
+	g.Expect(tikvConfigs).To(HaveLen(2))
+	g.Expect(tikvConfigs[1]).To(Equal(map[string]string{tikvIORateLimitConfig: "100MiB"}))
+	g.Expect(tc.Status.EventThrottling.OriginalScheduleConfig).To(Equal(map[string]string{pdRegionScheduleLimitConfig: "2048"}))
+
+	// the limits are kept in the cooldown period after the events end
+	g.Expect(fakeDeps.InformerFactory.Pingcap().V1alpha1().Restores().Informer().GetIndexer().Delete(restore)).To(Succeed())
+	now = now.Add(5 * time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.EventThrottling).NotTo(BeNil())
+	g.Expect(tc.Status.EventThrottling.Events).To(BeEmpty())
+
+	// the limits are restored after the cooldown period
+	now = now.Add(5 * time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.EventThrottling).To(BeNil())
+	g.Expect(scheduleConfigs[len(scheduleConfigs)-1]).To(Equal(map[string]interface{}{pdRegionScheduleLimitConfig: uint64(2048)}))
+	g.Expect(tikvConfigs[len(tikvConfigs)-1]).To(Equal(map[string]string{tikvIORateLimitConfig: "0KiB"}))
+
+	// the limits are restored immediately when the policy is removed
+	tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{"1": {PodName: podName, StoreID: "1"}}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.EventThrottling.Events).To(Equal([]v1alpha1.ThrottledEvent{v1alpha1.ThrottledEventFailover}))
+	tc.Spec.EventThrottling = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.EventThrottling).To(BeNil())
+	g.Expect(tikvConfigs[len(tikvConfigs)-1]).To(Equal(map[string]string{tikvIORateLimitConfig: "0KiB"}))
+}
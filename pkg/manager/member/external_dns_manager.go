@@ -0,0 +1,270 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	externalDNSTTLAnnotation      = "external-dns.alpha.kubernetes.io/ttl"
+)
+
+// dnsEndpointGVK is the kind of DNSEndpoint of external-dns, which is not registered in
+// the scheme, so DNSEndpoints are handled as unstructured objects
+var dnsEndpointGVK = schema.GroupVersionKind{Group: "externaldns.k8s.io", Version: "v1alpha1", Kind: "DNSEndpoint"}
+
+// externalDNSAnnotations returns the annotations of external-dns of the Service of the component
+// if spec.dns publishes it by annotations
+func externalDNSAnnotations(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType) map[string]string {
+	if tc.Spec.DNS == nil || tc.DNSMode() != v1alpha1.DNSModeAnnotation {
+		return nil
+	}
+	name, err := tc.DNSName(component)
+	if err != nil {
+		klog.Warningf("failed to render the DNS name of %s of tc %s/%s: %v", component, tc.GetNamespace(), tc.GetName(), err)
+		return nil
+	}
+	if name == "" {
+		return nil
+	}
+	annotations := map[string]string{externalDNSHostnameAnnotation: name}
+	if tc.Spec.DNS.TTL != nil {
+		annotations[externalDNSTTLAnnotation] = strconv.FormatInt(*tc.Spec.DNS.TTL, 10)
+	}
+	return annotations
+}
+
+// setExternalDNSAnnotations sets the annotations of external-dns of the Service of the component
+func setExternalDNSAnnotations(svc *corev1.Service, tc *v1alpha1.TidbCluster, component v1alpha1.MemberType) {
+	annotations := externalDNSAnnotations(tc, component)
+	if len(annotations) == 0 {
+		return
+	}
+	if svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+	for k, v := range annotations {
+		svc.Annotations[k] = v
+	}
+}
+
+// externalDNSAnnotationsEqual returns whether the annotations of external-dns of the Services are equal
+func externalDNSAnnotationsEqual(a, b *corev1.Service) bool {
+	for _, k := range []string{externalDNSHostnameAnnotation, externalDNSTTLAnnotation} {
+		if a.Annotations[k] != b.Annotations[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// copyExternalDNSAnnotations copies the annotations of external-dns from the desired Service, the
+// annotations not in the desired Service are removed
+func copyExternalDNSAnnotations(svc, desired *corev1.Service) {
+	annotations := make(map[string]string, len(svc.Annotations))
+	for k, v := range svc.Annotations {
+		annotations[k] = v
+	}
+	for _, k := range []string{externalDNSHostnameAnnotation, externalDNSTTLAnnotation} {
+		if v, ok := desired.Annotations[k]; ok {
+			annotations[k] = v
+		} else {
+			delete(annotations, k)
+		}
+	}
+	svc.Annotations = annotations
+}
+
+type externalDNSManager struct {
+	deps *controller.Dependencies
+}
+
+// NewExternalDNSManager returns a manager which maintains the DNSEndpoints of external-dns for the
+// TiDB and PD Services if spec.dns publishes the names by DNSEndpoints, the targets are the addresses
+// of the load balancers or the cluster IPs of the Services. The names of the DNSEndpoints are recorded
+// in status.dnsEndpoints, and the DNSEndpoint of a component is deleted if it's not published any more.
+// The annotations of the Services are set by the member managers.
+func NewExternalDNSManager(deps *controller.Dependencies) manager.Manager {
+	return &externalDNSManager{
+		deps: deps,
+	}
+}
+
+func (m *externalDNSManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if !m.deps.DNSEndpointSupported {
+		if tc.Spec.DNS != nil && tc.DNSMode() == v1alpha1.DNSModeDNSEndpoint {
+			klog.V(4).Infof("the DNSEndpoint CRD is not installed, skip generating DNSEndpoints for cluster %s/%s", tc.GetNamespace(), tc.GetName())
+		}
+		return nil
+	}
+
+	published := sets.NewString(tc.Status.DNSEndpoints...)
+	names := sets.NewString()
+	for _, component := range []struct {
+		memberType v1alpha1.MemberType
+		service    string
+		deployed   bool
+	}{
+		{memberType: v1alpha1.TiDBMemberType, service: controller.TiDBMemberName(tc.Name), deployed: tc.Spec.TiDB != nil && tc.Spec.TiDB.Service != nil},
+		{memberType: v1alpha1.PDMemberType, service: controller.PDMemberName(tc.Name), deployed: tc.Spec.PD != nil},
+	} {
+		var name string
+		if tc.Spec.DNS != nil && tc.DNSMode() == v1alpha1.DNSModeDNSEndpoint && component.deployed {
+			var err error
+			if name, err = tc.DNSName(component.memberType); err != nil {
+				return fmt.Errorf("failed to render the DNS name of %s of tc %s/%s: %v", component.memberType, tc.GetNamespace(), tc.GetName(), err)
+			}
+		}
+		if name == "" {
+			continue
+		}
+		// the DNSEndpoint published is kept while the Service has no address
+		epName := dnsEndpointName(tc, component.memberType)
+		if published.Has(epName) {
+			names.Insert(epName)
+		}
+
+		svc, err := m.deps.ServiceLister.Services(tc.GetNamespace()).Get(component.service)
+		if errors.IsNotFound(err) {
+			// the Service is not created yet
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get svc %s for cluster %s/%s, error: %s", component.service, tc.GetNamespace(), tc.GetName(), err)
+		}
+		recordType, targets := dnsEndpointTargets(svc)
+		if len(targets) == 0 {
+			klog.V(4).Infof("svc %s/%s has no address yet, skip generating DNSEndpoint", svc.Namespace, svc.Name)
+			continue
+		}
+		ep := getDNSEndpoint(tc, component.memberType, name, recordType, targets)
+		_, err = m.deps.GenericControl.CreateOrUpdate(tc, ep, func(existing, desired client.Object) error {
+			existingEP := existing.(*unstructured.Unstructured)
+			desiredEP := desired.(*unstructured.Unstructured)
+
+			existingEP.SetLabels(desiredEP.GetLabels())
+			existingEP.Object["spec"] = desiredEP.Object["spec"]
+			return nil
+		}, true)
+		if err != nil {
+			return controller.RequeueErrorf("error creating or updating dns endpoint %s/%s: %v", ep.GetNamespace(), ep.GetName(), err)
+		}
+		names.Insert(epName)
+	}
+
+	// only the DNSEndpoints published before are deleted, there is nothing to do if the
+	// feature is not used
+	for _, name := range tc.Status.DNSEndpoints {
+		if names.Has(name) {
+			continue
+		}
+		ep := &unstructured.Unstructured{}
+		ep.SetGroupVersionKind(dnsEndpointGVK)
+		ep.SetNamespace(tc.GetNamespace())
+		ep.SetName(name)
+		if err := m.deps.GenericControl.Delete(tc, ep); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete dns endpoint %s/%s: %v", ep.GetNamespace(), ep.GetName(), err)
+		}
+	}
+	if names.Len() == 0 {
+		tc.Status.DNSEndpoints = nil
+	} else {
+		tc.Status.DNSEndpoints = names.List()
+	}
+	return nil
+}
+
+func dnsEndpointName(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType) string {
+	return fmt.Sprintf("%s-%s", tc.GetName(), component)
+}
+
+// dnsEndpointTargets returns the record type and the targets of the Service, the IPv4 addresses
+// of the load balancer are preferred, then the hostname of the load balancer and the cluster IP
+func dnsEndpointTargets(svc *corev1.Service) (string, []interface{}) {
+	var ips []interface{}
+	var hostname string
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ip := net.ParseIP(ingress.IP); ip != nil && ip.To4() != nil {
+			ips = append(ips, ingress.IP)
+		} else if ingress.Hostname != "" && hostname == "" {
+			hostname = ingress.Hostname
+		}
+	}
+	switch {
+	case len(ips) > 0:
+		return "A", ips
+	case hostname != "":
+		return "CNAME", []interface{}{hostname}
+	}
+	if ip := net.ParseIP(svc.Spec.ClusterIP); ip != nil && ip.To4() != nil {
+		return "A", []interface{}{svc.Spec.ClusterIP}
+	}
+	return "", nil
+}
+
+func getDNSEndpoint(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType, name, recordType string, targets []interface{}) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"dnsName":    name,
+		"recordType": recordType,
+		"targets":    targets,
+	}
+	if tc.Spec.DNS.TTL != nil {
+		endpoint["recordTTL"] = *tc.Spec.DNS.TTL
+	}
+	ep := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"endpoints": []interface{}{endpoint},
+			},
+		},
+	}
+	ep.SetGroupVersionKind(dnsEndpointGVK)
+	ep.SetName(dnsEndpointName(tc, component))
+	ep.SetNamespace(tc.GetNamespace())
+	ep.SetLabels(label.New().Instance(tc.GetInstanceName()).Component(component.String()).Labels())
+	return ep
+}
+
+type FakeExternalDNSManager struct {
+	err error
+}
+
+func NewFakeExternalDNSManager() *FakeExternalDNSManager {
+	return &FakeExternalDNSManager{}
+}
+
+func (m *FakeExternalDNSManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeExternalDNSManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
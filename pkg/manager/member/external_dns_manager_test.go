@@ -0,0 +1,146 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestExternalDNSAnnotations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.Service = &v1alpha1.TiDBServiceSpec{}
+	g.Expect(getNewTiDBServiceOrNil(tc).Annotations).NotTo(HaveKey(externalDNSHostnameAnnotation))
+
+	tc.Spec.DNS = &v1alpha1.DNSSpec{
+		DomainTemplate: "{{ .Component }}.{{ .Cluster }}.{{ .Namespace }}.db.example.com.",
+		TTL:            pointer.Int64Ptr(60),
+	}
+	svc := getNewTiDBServiceOrNil(tc)
+	g.Expect(svc.Annotations).To(HaveKeyWithValue(externalDNSHostnameAnnotation, "tidb.test.default.db.example.com"))
+	g.Expect(svc.Annotations).To(HaveKeyWithValue(externalDNSTTLAnnotation, "60"))
+	g.Expect(externalDNSAnnotations(tc, v1alpha1.PDMemberType)).To(HaveKeyWithValue(externalDNSHostnameAnnotation, "pd.test.default.db.example.com"))
+
+	// the annotations are not set for the components not published or in the DNSEndpoint mode
+	tc.Spec.DNS.Components = []v1alpha1.MemberType{v1alpha1.TiDBMemberType}
+	g.Expect(externalDNSAnnotations(tc, v1alpha1.PDMemberType)).To(BeEmpty())
+	tc.Spec.DNS.Mode = v1alpha1.DNSModeDNSEndpoint
+	g.Expect(externalDNSAnnotations(tc, v1alpha1.TiDBMemberType)).To(BeEmpty())
+
+	// only the annotations of external-dns are copied
+	old := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		"foo":                         "bar",
+		externalDNSHostnameAnnotation: "old.example.com",
+		externalDNSTTLAnnotation:      "60",
+	}}}
+	desired := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		externalDNSHostnameAnnotation: "new.example.com",
+	}}}
+	g.Expect(externalDNSAnnotationsEqual(old, desired)).To(BeFalse())
+	copyExternalDNSAnnotations(old, desired)
+	g.Expect(old.Annotations).To(Equal(map[string]string{"foo": "bar", externalDNSHostnameAnnotation: "new.example.com"}))
+	g.Expect(externalDNSAnnotationsEqual(old, desired)).To(BeTrue())
+}
+
+func TestExternalDNSManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	ctrl := fakeDeps.GenericControl.(*controller.FakeGenericControl)
+	svcIndexer := fakeDeps.KubeInformerFactory.Core().V1().Services().Informer().GetIndexer()
+	m := NewExternalDNSManager(fakeDeps)
+
+	getDNSEndpoint := func(name string) (*unstructured.Unstructured, bool) {
+		ep := &unstructured.Unstructured{}
+		ep.SetGroupVersionKind(dnsEndpointGVK)
+		exist, err := ctrl.Exist(client.ObjectKey{Namespace: "default", Name: name}, ep)
+		g.Expect(err).NotTo(HaveOccurred())
+		return ep, exist
+	}
+	getEndpoint := func(ep *unstructured.Unstructured) map[string]interface{} {
+		endpoints, _, err := unstructured.NestedSlice(ep.Object, "spec", "endpoints")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(endpoints).To(HaveLen(1))
+		return endpoints[0].(map[string]interface{})
+	}
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.Service = &v1alpha1.TiDBServiceSpec{}
+	tc.Spec.PD = &v1alpha1.PDSpec{}
+	tc.Spec.DNS = &v1alpha1.DNSSpec{
+		DomainTemplate: "{{ .Component }}.{{ .Cluster }}.example.com",
+		Mode:           v1alpha1.DNSModeDNSEndpoint,
+		TTL:            pointer.Int64Ptr(60),
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tidb", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.1"},
+	}
+	g.Expect(svcIndexer.Add(svc)).To(Succeed())
+
+	// the DNSEndpoints are not generated if the CRD is not installed
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, exist := getDNSEndpoint("test-tidb")
+	g.Expect(exist).To(BeFalse())
+	g.Expect(tc.Status.DNSEndpoints).To(BeEmpty())
+
+	// the cluster IP is the target before the load balancer is provisioned
+	fakeDeps.DNSEndpointSupported = true
+	g.Expect(m.Sync(tc)).To(Succeed())
+	ep, exist := getDNSEndpoint("test-tidb")
+	g.Expect(exist).To(BeTrue())
+	endpoint := getEndpoint(ep)
+	g.Expect(endpoint).To(HaveKeyWithValue("dnsName", "tidb.test.example.com"))
+	g.Expect(endpoint).To(HaveKeyWithValue("recordType", "A"))
+	g.Expect(endpoint).To(HaveKeyWithValue("targets", ConsistOf("10.0.0.1")))
+	g.Expect(endpoint).To(HaveKeyWithValue("recordTTL", BeNumerically("==", 60)))
+	// the Service of PD is not created yet
+	_, exist = getDNSEndpoint("test-pd")
+	g.Expect(exist).To(BeFalse())
+	g.Expect(tc.Status.DNSEndpoints).To(Equal([]string{"test-tidb"}))
+
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "tidb.elb.amazonaws.com"}}
+	g.Expect(svcIndexer.Update(svc)).To(Succeed())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	ep, _ = getDNSEndpoint("test-tidb")
+	endpoint = getEndpoint(ep)
+	g.Expect(endpoint).To(HaveKeyWithValue("recordType", "CNAME"))
+	g.Expect(endpoint).To(HaveKeyWithValue("targets", ConsistOf("tidb.elb.amazonaws.com")))
+
+	// the DNSEndpoint published is kept while the Service has no address
+	svc.Status.LoadBalancer.Ingress = nil
+	svc.Spec.ClusterIP = corev1.ClusterIPNone
+	g.Expect(svcIndexer.Update(svc)).To(Succeed())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, exist = getDNSEndpoint("test-tidb")
+	g.Expect(exist).To(BeTrue())
+	g.Expect(tc.Status.DNSEndpoints).To(Equal([]string{"test-tidb"}))
+
+	// the DNSEndpoint is deleted if the names are published by annotations
+	tc.Spec.DNS.Mode = v1alpha1.DNSModeAnnotation
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, exist = getDNSEndpoint("test-tidb")
+	g.Expect(exist).To(BeFalse())
+	g.Expect(tc.Status.DNSEndpoints).To(BeNil())
+}
@@ -0,0 +1,265 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ReferencedClusterNotFound is the reason of ReferencedClusterReady condition when the referenced cluster doesn't exist
+	ReferencedClusterNotFound = "ReferencedClusterNotFound"
+	// ReferencedClusterTLSMismatch is the reason of ReferencedClusterReady condition when the TLS between
+	// the components is enabled in only one of the clusters
+	ReferencedClusterTLSMismatch = "ReferencedClusterTLSMismatch"
+	// ReferencedClusterVersionMismatch is the reason of the event emitted when the components of the cluster
+	// are in a different minor version from the referenced cluster
+	ReferencedClusterVersionMismatch = "ReferencedClusterVersionMismatch"
+)
+
+// HeterogeneousManager checks the cluster referenced by spec.cluster of a heterogeneous cluster:
+//
+//   - the referenced cluster must exist and enable the TLS between the components if and only if
+//     the heterogeneous cluster enables it, otherwise the components are not synced
+//   - a warning event is emitted when the versions of the components become incompatible with the
+//     referenced cluster
+//   - the client certificate of the referenced cluster is copied if the heterogeneous cluster
+//     doesn't have one, it's used by TiDB Operator to talk to the PD of the referenced cluster.
+//     The certificate of a cluster in another namespace is only copied if the namespace is listed
+//     in the annotation tidb.pingcap.com/share-cluster-client-secret of the referenced cluster
+//   - the ReferencedClusterReady condition reflects the Ready condition of the referenced cluster
+//
+// The referenced cluster in another Kubernetes cluster is not checked.
+type HeterogeneousManager interface {
+	Sync(*v1alpha1.TidbCluster) error
+}
+
+type heterogeneousManager struct {
+	deps *controller.Dependencies
+	// versionMismatches records the last mismatched versions of each cluster, so that the warning
+	// event is only emitted when they change
+	versionMismatches sync.Map
+}
+
+// NewHeterogeneousManager returns a HeterogeneousManager
+func NewHeterogeneousManager(deps *controller.Dependencies) HeterogeneousManager {
+	return &heterogeneousManager{
+		deps: deps,
+	}
+}
+
+func (m *heterogeneousManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if !tc.Heterogeneous() {
+		return nil
+	}
+	ref := tc.Spec.Cluster
+	refNamespace := ref.Namespace
+	if refNamespace == "" {
+		refNamespace = tc.Namespace
+	}
+	refID := fmt.Sprintf("%s/%s", refNamespace, ref.Name)
+
+	refTC, err := m.deps.TiDBClusterLister.TidbClusters(refNamespace).Get(ref.Name)
+	if errors.IsNotFound(err) {
+		if ref.ClusterDomain != "" && ref.ClusterDomain != tc.Spec.ClusterDomain {
+			klog.V(4).Infof("referenced cluster %s of tc %s/%s may be in another Kubernetes cluster, skip checking it", refID, tc.Namespace, tc.Name)
+			return nil
+		}
+		msg := fmt.Sprintf("referenced cluster %s is not found", refID)
+		m.setReferencedClusterCondition(tc, corev1.ConditionFalse, ReferencedClusterNotFound, msg)
+		return controller.RequeueErrorf("tc %s/%s: %s", tc.Namespace, tc.Name, msg)
+	}
+	if err != nil {
+		return fmt.Errorf("heterogeneous manager: failed to get referenced cluster %s of tc %s/%s, error: %v", refID, tc.Namespace, tc.Name, err)
+	}
+
+	if tc.IsTLSClusterEnabled() != refTC.IsTLSClusterEnabled() {
+		msg := fmt.Sprintf("TLS between components is enabled: %t, but it's %t in referenced cluster %s",
+			tc.IsTLSClusterEnabled(), refTC.IsTLSClusterEnabled(), refID)
+		m.setReferencedClusterCondition(tc, corev1.ConditionFalse, ReferencedClusterTLSMismatch, msg)
+		return controller.RequeueErrorf("tc %s/%s: %s", tc.Namespace, tc.Name, msg)
+	}
+
+	m.checkVersions(tc, refTC, refID)
+
+	if tc.IsTLSClusterEnabled() {
+		if err := m.syncClusterClientSecret(tc, refTC); err != nil {
+			return err
+		}
+	}
+
+	refReady := utiltidbcluster.GetTidbClusterCondition(refTC.Status, v1alpha1.TidbClusterReady)
+	if refReady == nil || refReady.Status != corev1.ConditionTrue {
+		msg := fmt.Sprintf("referenced cluster %s is not ready", refID)
+		if refReady != nil && refReady.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, refReady.Message)
+		}
+		m.setReferencedClusterCondition(tc, corev1.ConditionFalse, utiltidbcluster.ReferencedClusterNotReady, msg)
+		return nil
+	}
+	m.setReferencedClusterCondition(tc, corev1.ConditionTrue, utiltidbcluster.Ready, fmt.Sprintf("referenced cluster %s is ready", refID))
+	return nil
+}
+
+// setReferencedClusterCondition sets the ReferencedClusterReady condition and emits an event when it turns false
+func (m *heterogeneousManager) setReferencedClusterCondition(tc *v1alpha1.TidbCluster, status corev1.ConditionStatus, reason, msg string) {
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterReferencedClusterReady)
+	if status == corev1.ConditionFalse && (cond == nil || cond.Status != status || cond.Reason != reason) {
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, reason, msg)
+	}
+	newCond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterReferencedClusterReady, status, reason, msg)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *newCond)
+	// SetTidbClusterCondition doesn't update the message if the status and the reason are unchanged,
+	// update it in place so that it always shows the latest state of the referenced cluster
+	for i := range tc.Status.Conditions {
+		c := &tc.Status.Conditions[i]
+		if c.Type == v1alpha1.TidbClusterReferencedClusterReady && c.Message != msg {
+			c.Message = msg
+			c.LastUpdateTime = metav1.Now()
+		}
+	}
+}
+
+// checkVersions emits a warning event when any component turns to be not in the same minor version as the
+// referenced cluster, the versions which are not semantic versions, e.g. latest, are not checked
+func (m *heterogeneousManager) checkVersions(tc, refTC *v1alpha1.TidbCluster, refID string) {
+	refVersion := refTC.PDVersion()
+	if refTC.Spec.PD == nil {
+		refVersion = refTC.TiKVVersion()
+	}
+	refVer, err := semver.NewVersion(refVersion)
+	if err != nil {
+		return
+	}
+
+	versions := map[v1alpha1.MemberType]string{
+		v1alpha1.TiKVMemberType:    tc.TiKVVersion(),
+		v1alpha1.TiFlashMemberType: tc.TiFlashVersion(),
+		v1alpha1.TiDBMemberType:    imageVersion(tc.TiDBImage()),
+	}
+	var mismatched []string
+	for _, typ := range []v1alpha1.MemberType{v1alpha1.TiKVMemberType, v1alpha1.TiFlashMemberType, v1alpha1.TiDBMemberType} {
+		ver, err := semver.NewVersion(versions[typ])
+		if err != nil {
+			continue
+		}
+		if ver.Major() != refVer.Major() || ver.Minor() != refVer.Minor() {
+			mismatched = append(mismatched, fmt.Sprintf("%s %s", typ, versions[typ]))
+		}
+	}
+	key := fmt.Sprintf("%s/%s", tc.Namespace, tc.Name)
+	if len(mismatched) == 0 {
+		m.versionMismatches.Delete(key)
+		return
+	}
+	msg := fmt.Sprintf("%s are not compatible with referenced cluster %s of version %s", strings.Join(mismatched, ", "), refID, refVersion)
+	if last, ok := m.versionMismatches.Load(key); ok && last.(string) == msg {
+		return
+	}
+	m.versionMismatches.Store(key, msg)
+	m.deps.Recorder.Event(tc, corev1.EventTypeWarning, ReferencedClusterVersionMismatch, msg)
+}
+
+// syncClusterClientSecret copies the client certificate of the referenced cluster if the cluster doesn't
+// have its own, the copy is owned by the cluster and updated when the certificate is renewed.
+// The certificate is not copied across namespaces unless the referenced cluster shares it with the namespace.
+func (m *heterogeneousManager) syncClusterClientSecret(tc, refTC *v1alpha1.TidbCluster) error {
+	name := util.ClusterClientTLSSecretName(tc.Name)
+	secret, err := m.deps.SecretLister.Secrets(tc.Namespace).Get(name)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("heterogeneous manager: failed to get secret %s/%s, error: %v", tc.Namespace, name, err)
+	}
+	if err == nil {
+		if owner := metav1.GetControllerOf(secret); owner == nil || owner.UID != tc.UID {
+			// the certificate is provided by the user
+			return nil
+		}
+	}
+
+	refName := util.ClusterClientTLSSecretName(refTC.Name)
+	if refTC.Namespace != tc.Namespace && !clientSecretSharedWith(refTC, tc.Namespace) {
+		klog.V(4).Infof("client certificate %s/%s of referenced cluster is not shared with namespace %s, skip copying it to tc %s/%s",
+			refTC.Namespace, refName, tc.Namespace, tc.Namespace, tc.Name)
+		return nil
+	}
+	refSecret, err := m.deps.SecretLister.Secrets(refTC.Namespace).Get(refName)
+	if errors.IsNotFound(err) {
+		klog.Warningf("client certificate %s/%s of referenced cluster is not found, skip copying it to tc %s/%s", refTC.Namespace, refName, tc.Namespace, tc.Name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("heterogeneous manager: failed to get secret %s/%s, error: %v", refTC.Namespace, refName, err)
+	}
+
+	newSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: tc.Namespace,
+			Labels:    label.New().Instance(tc.GetInstanceName()).Labels(),
+		},
+		Type: refSecret.Type,
+		Data: refSecret.Data,
+	}
+	_, err = m.deps.TypedControl.CreateOrUpdateSecret(tc, newSecret)
+	return err
+}
+
+// clientSecretSharedWith returns whether the client certificate of the cluster can be copied to the namespace
+func clientSecretSharedWith(tc *v1alpha1.TidbCluster, namespace string) bool {
+	for _, ns := range strings.Split(tc.Annotations[label.AnnShareClusterClientSecret], ",") {
+		if strings.TrimSpace(ns) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// imageVersion returns the tag of the image
+func imageVersion(image string) string {
+	colonIdx := strings.LastIndexByte(image, ':')
+	if colonIdx >= 0 {
+		return image[colonIdx+1:]
+	}
+	return "latest"
+}
+
+type FakeHeterogeneousManager struct {
+	err error
+}
+
+func NewFakeHeterogeneousManager() *FakeHeterogeneousManager {
+	return &FakeHeterogeneousManager{}
+}
+
+func (m *FakeHeterogeneousManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeHeterogeneousManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestHeterogeneousManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	hm := &heterogeneousManager{deps: deps}
+	tcIndexer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+
+	tc := newTidbClusterForPD()
+	tc.Spec.PD = nil
+	tc.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: "primary"}
+	g.Expect(hm.Sync(tc)).NotTo(Succeed())
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterReferencedClusterReady)
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal(ReferencedClusterNotFound))
+
+	primary := newTidbClusterForPD()
+	primary.Name = "primary"
+	primary.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	g.Expect(tcIndexer.Add(primary)).To(Succeed())
+	g.Expect(hm.Sync(tc)).NotTo(Succeed())
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterReferencedClusterReady)
+	g.Expect(cond.Reason).To(Equal(ReferencedClusterTLSMismatch))
+
+	// the client certificate of the primary cluster is copied
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	g.Expect(secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: primary.Namespace, Name: util.ClusterClientTLSSecretName(primary.Name)},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	})).To(Succeed())
+	g.Expect(hm.Sync(tc)).To(Succeed())
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: util.ClusterClientTLSSecretName(tc.Name)}}
+	fakeCli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	g.Expect(fakeCli.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	g.Expect(secret.Data[corev1.TLSCertKey]).To(Equal([]byte("cert")))
+	g.Expect(metav1.IsControlledBy(secret, tc)).To(BeTrue())
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterReferencedClusterReady)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.ReferencedClusterNotReady))
+
+	primary.Status.Conditions = []v1alpha1.TidbClusterCondition{
+		*utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterReady, corev1.ConditionTrue, utiltidbcluster.Ready, ""),
+	}
+	g.Expect(tcIndexer.Update(primary)).To(Succeed())
+	g.Expect(hm.Sync(tc)).To(Succeed())
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterReferencedClusterReady)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+}
+
+func TestHeterogeneousManagerSyncClusterClientSecret(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	hm := &heterogeneousManager{deps: deps}
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	fakeCli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+
+	primary := newTidbClusterForPD()
+	primary.Namespace = "primary-ns"
+	primary.Name = "primary"
+	g.Expect(secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: primary.Namespace, Name: util.ClusterClientTLSSecretName(primary.Name)},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	})).To(Succeed())
+	tc := newTidbClusterForPD()
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: util.ClusterClientTLSSecretName(tc.Name)}}
+
+	// the certificate is not shared with the namespace of the cluster
+	g.Expect(hm.syncClusterClientSecret(tc, primary)).To(Succeed())
+	g.Expect(fakeCli.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).NotTo(Succeed())
+
+	primary.Annotations = map[string]string{label.AnnShareClusterClientSecret: "other, " + tc.Namespace}
+	g.Expect(hm.syncClusterClientSecret(tc, primary)).To(Succeed())
+	g.Expect(fakeCli.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	g.Expect(secret.Data[corev1.TLSCertKey]).To(Equal([]byte("cert")))
+}
+
+func TestHeterogeneousManagerCheckVersions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	hm := &heterogeneousManager{deps: deps}
+	recorder := deps.Recorder.(*record.FakeRecorder)
+
+	primary := newTidbClusterForPD()
+	primary.Spec.PD.Image = "pingcap/pd:v6.1.0"
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV.Image = "pingcap/tikv:v6.1.2"
+	hm.checkVersions(tc, primary, "default/primary")
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	tc.Spec.TiKV.Image = "pingcap/tikv:v5.4.0"
+	hm.checkVersions(tc, primary, "default/primary")
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring(ReferencedClusterVersionMismatch))
+	// the event is not emitted again until the versions change
+	hm.checkVersions(tc, primary, "default/primary")
+	g.Expect(recorder.Events).To(BeEmpty())
+	tc.Spec.TiKV.Image = "pingcap/tikv:v5.3.0"
+	hm.checkVersions(tc, primary, "default/primary")
+	g.Expect(recorder.Events).To(HaveLen(1))
+	<-recorder.Events
+
+	// latest is not checked
+	tc.Spec.TiKV.Image = "pingcap/tikv:latest"
+	hm.checkVersions(tc, primary, "default/primary")
+	g.Expect(recorder.Events).To(BeEmpty())
+}
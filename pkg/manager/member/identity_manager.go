@@ -0,0 +1,448 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	identityClusterIDKey = "cluster-id"
+	identityAllocIDKey   = "alloc-id"
+	identityPDMembersKey = "pd-members"
+	identityStoresKey    = "stores"
+
+	// identityRecordInterval is the interval to record the max ID allocated by PD, which grows
+	// without any members or stores changed
+	identityRecordInterval = 10 * time.Minute
+	// identityAllocIDMargin is added to the recorded max allocated ID when it's restored, as PD
+	// may allocate more IDs after it's recorded, it's what pd-recover recommends too
+	identityAllocIDMargin = 100000000
+	// identityMaxPeerCount is the max peer count of the cluster meta restored, which is the
+	// default of pd-recover, the replicas are scheduled by the replication config of PD
+	identityMaxPeerCount = 3
+
+	pdClusterIDPath = "/pd/cluster_id"
+
+	identityMismatchReason  = "IdentityMismatch"
+	identityRecoveredReason = "IdentityRecovered"
+)
+
+// clusterIdentity is the identities of the cluster kept in the Secret
+type clusterIdentity struct {
+	ClusterID string
+	// AllocID is the max ID allocated by PD
+	AllocID uint64
+	// PDMembers are the member IDs of PD keyed by the pod names
+	PDMembers map[string]string
+	// Stores are the store IDs of TiKV and TiFlash keyed by the pod names
+	Stores map[string]string
+}
+
+// identityManager keeps the identities of the cluster in a Secret not owned by the TidbCluster,
+// and restores them to PD if the TidbCluster is recreated with the data of TiKV retained but
+// the data of PD lost
+type identityManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewIdentityManager returns a manager keeping the identities of the cluster by spec.persistentIdentity
+func NewIdentityManager(deps *controller.Dependencies) manager.Manager {
+	return &identityManager{deps: deps, now: time.Now}
+}
+
+func (m *identityManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.PersistentIdentity == nil {
+		// the Secret is kept on purpose, it's deleted by the users
+		tc.Status.PersistentIdentity = nil
+		return nil
+	}
+	if tc.Spec.PD == nil {
+		return nil
+	}
+	// TiKV is not synced until all the PD pods restart to serve the restored cluster
+	if status := tc.Status.PersistentIdentity; status != nil && status.RecoveryTime != nil {
+		if err := m.waitForPDRestart(tc, status.RecoveryTime.Time); err != nil {
+			return err
+		}
+	}
+	if !tc.PDIsAvailable() {
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	name := tc.PersistentIdentitySecretName()
+	status := tc.Status.PersistentIdentity
+	if status == nil || status.SecretName != name {
+		status = &v1alpha1.PersistentIdentityStatus{SecretName: name}
+		tc.Status.PersistentIdentity = status
+	}
+
+	var recorded *clusterIdentity
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(name)
+	if err == nil {
+		if recorded, err = parseClusterIdentity(secret); err != nil {
+			return fmt.Errorf("failed to parse the identities of tc %s/%s in secret %s: %v", ns, tc.GetName(), name, err)
+		}
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get identity secret %s/%s: %v", ns, name, err)
+	}
+
+	current := currentClusterIdentity(tc, recorded)
+	if recorded != nil && recorded.ClusterID == tc.Status.ClusterID &&
+		reflect.DeepEqual(recorded.PDMembers, current.PDMembers) && reflect.DeepEqual(recorded.Stores, current.Stores) &&
+		status.LastUpdateTime != nil && m.now().Sub(status.LastUpdateTime.Time) < identityRecordInterval {
+		return nil
+	}
+
+	etcdClient, err := m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(ns), tc.GetName(), tc.IsTLSClusterEnabled())
+	if err != nil {
+		return err
+	}
+	defer etcdClient.Close()
+	clusterID, allocID, bootstrapped, err := readPDIdentity(etcdClient)
+	if err != nil {
+		return fmt.Errorf("failed to read the identities of tc %s/%s from PD: %v", ns, tc.GetName(), err)
+	}
+	if recorded != nil && recorded.ClusterID != strconv.FormatUint(clusterID, 10) {
+		return m.recover(tc, etcdClient, recorded, clusterID, bootstrapped)
+	}
+	if !bootstrapped {
+		// nothing to record until TiKV bootstraps the cluster
+		return nil
+	}
+
+	current.ClusterID = strconv.FormatUint(clusterID, 10)
+	current.AllocID = allocID
+	data, err := current.data()
+	if err != nil {
+		return err
+	}
+	desired := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    label.New().Instance(tc.GetInstanceName()).Identity().Labels(),
+		},
+		Data: data,
+	}
+	// the Secret is not owned by the TidbCluster, so that it's kept after the TidbCluster is deleted
+	_, err = m.deps.GenericControl.CreateOrUpdate(tc, desired, func(existing, desired client.Object) error {
+		existing.(*corev1.Secret).Data = desired.(*corev1.Secret).Data
+		existing.SetLabels(desired.GetLabels())
+		return nil
+	}, false)
+	if err != nil {
+		return controller.RequeueErrorf("error creating or updating identity secret %s/%s: %v", ns, name, err)
+	}
+	status.ClusterID = current.ClusterID
+	status.LastUpdateTime = &metav1.Time{Time: m.now()}
+	return nil
+}
+
+// recover restores the recorded cluster ID and allocated ID to PD if PD serves a new cluster not
+// bootstrapped yet and the PVCs of TiKV are retained, which are the steps of pd-recover. A requeue
+// error is returned until PD restarts with the restored cluster, so that TiKV is not synced to
+// bootstrap the new cluster in the meantime. The new cluster bootstrapped already, e.g. by a TiKV
+// store without retained data, is only reported, as its data would be lost by the restore.
+func (m *identityManager) recover(tc *v1alpha1.TidbCluster, etcdClient pdapi.PDEtcdClient, recorded *clusterIdentity, clusterID uint64, bootstrapped bool) error {
+	ns := tc.GetNamespace()
+	name := tc.PersistentIdentitySecretName()
+	if bootstrapped {
+		msg := fmt.Sprintf("PD serves the bootstrapped cluster %d but cluster %s is recorded in Secret %s, delete the Secret if the cluster is expected to start from scratch",
+			clusterID, recorded.ClusterID, name)
+		klog.Warningf("tc %s/%s: %s", ns, tc.GetName(), msg)
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, identityMismatchReason, msg)
+		return nil
+	}
+	retained, err := m.tikvDataRetained(tc)
+	if err != nil {
+		return err
+	}
+	if !retained {
+		klog.Infof("tc %s/%s: PD serves the new cluster %d, the recorded cluster %s is not restored as no PVCs of TiKV are retained",
+			ns, tc.GetName(), clusterID, recorded.ClusterID)
+		return nil
+	}
+
+	recordedID, err := strconv.ParseUint(recorded.ClusterID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid cluster id %q in identity secret %s/%s: %v", recorded.ClusterID, ns, name, err)
+	}
+	allocID := recorded.maxID() + identityAllocIDMargin
+	kvs, err := pdRecoveryKVs(recordedID, allocID, m.now())
+	if err != nil {
+		return err
+	}
+	// the transaction fails if TiKV bootstraps either cluster in the meantime
+	guards := []string{pdRaftPath(clusterID), pdRaftPath(recordedID)}
+	ok, err := etcdClient.PutKeysIfNotExist(guards, kvs)
+	if err != nil {
+		return fmt.Errorf("failed to restore cluster %d of tc %s/%s to PD: %v", recordedID, ns, tc.GetName(), err)
+	}
+	if !ok {
+		return controller.RequeueErrorf("tc %s/%s: the cluster is bootstrapped while restoring cluster %d to PD", ns, tc.GetName(), recordedID)
+	}
+	tc.Status.PersistentIdentity.RecoveryTime = &metav1.Time{Time: m.now()}
+
+	// PD loads the cluster ID when it starts, restart all the members to serve the restored one
+	pods, err := m.pdPods(tc)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		if err := m.deps.PodControl.DeletePod(tc, pod); err != nil {
+			return err
+		}
+	}
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, identityRecoveredReason,
+		"Restored cluster %d with allocated ID %d from Secret %s to PD, which served the new cluster %d", recordedID, allocID, name, clusterID)
+	return controller.RequeueErrorf("tc %s/%s: waiting for PD to restart with the restored cluster %d", ns, tc.GetName(), recordedID)
+}
+
+// waitForPDRestart returns a requeue error if any PD pod is created before the recovery, which
+// may still serve the new cluster that TiKV could bootstrap
+func (m *identityManager) waitForPDRestart(tc *v1alpha1.TidbCluster, recoveryTime time.Time) error {
+	pods, err := m.pdPods(tc)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		if pod.CreationTimestamp.Time.Before(recoveryTime) {
+			return controller.RequeueErrorf("tc %s/%s: waiting for pd pod %s to restart with the restored cluster", tc.GetNamespace(), tc.GetName(), pod.Name)
+		}
+	}
+	return nil
+}
+
+func (m *identityManager) pdPods(tc *v1alpha1.TidbCluster) ([]*corev1.Pod, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).PD().Selector()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := m.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pd pods of tc %s/%s: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	return pods, nil
+}
+
+// tikvDataRetained returns whether any PVCs of TiKV are created before the TidbCluster,
+// which are retained from the deleted TidbCluster
+func (m *identityManager) tikvDataRetained(tc *v1alpha1.TidbCluster) (bool, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiKV().Selector()
+	if err != nil {
+		return false, err
+	}
+	pvcs, err := m.deps.PVCLister.PersistentVolumeClaims(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return false, fmt.Errorf("failed to list tikv pvcs of tc %s/%s: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	for _, pvc := range pvcs {
+		if pvc.CreationTimestamp.Before(&tc.CreationTimestamp) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// currentClusterIdentity returns the member IDs and store IDs in the status of the cluster, the
+// recorded ones of the desired pods not in the status yet are kept, e.g. after the cluster is recreated
+func currentClusterIdentity(tc *v1alpha1.TidbCluster, recorded *clusterIdentity) *clusterIdentity {
+	identity := &clusterIdentity{PDMembers: map[string]string{}, Stores: map[string]string{}}
+	desired := sets.NewString()
+	for _, ordinal := range tc.PDStsDesiredOrdinals(false).List() {
+		desired.Insert(ordinalPodName(v1alpha1.PDMemberType, tc.GetName(), ordinal))
+	}
+	if tc.Spec.TiKV != nil {
+		for _, ordinal := range tc.TiKVStsDesiredOrdinals(false).List() {
+			desired.Insert(ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), ordinal))
+		}
+	}
+	if tc.Spec.TiFlash != nil {
+		for _, ordinal := range tc.TiFlashStsDesiredOrdinals(false).List() {
+			desired.Insert(ordinalPodName(v1alpha1.TiFlashMemberType, tc.GetName(), ordinal))
+		}
+	}
+	if recorded != nil {
+		for pod, id := range recorded.PDMembers {
+			if desired.Has(pod) {
+				identity.PDMembers[pod] = id
+			}
+		}
+		for pod, id := range recorded.Stores {
+			if desired.Has(pod) {
+				identity.Stores[pod] = id
+			}
+		}
+	}
+
+	for name, member := range tc.Status.PD.Members {
+		identity.PDMembers[name] = member.ID
+	}
+	for _, store := range tc.Status.TiKV.Stores {
+		identity.Stores[store.PodName] = store.ID
+	}
+	for _, store := range tc.Status.TiFlash.Stores {
+		identity.Stores[store.PodName] = store.ID
+	}
+	return identity
+}
+
+func parseClusterIdentity(secret *corev1.Secret) (*clusterIdentity, error) {
+	identity := &clusterIdentity{
+		ClusterID: string(secret.Data[identityClusterIDKey]),
+		PDMembers: map[string]string{},
+		Stores:    map[string]string{},
+	}
+	if identity.ClusterID == "" {
+		return nil, fmt.Errorf("%s is empty", identityClusterIDKey)
+	}
+	if v := secret.Data[identityAllocIDKey]; len(v) > 0 {
+		allocID, err := strconv.ParseUint(string(v), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", identityAllocIDKey, err)
+		}
+		identity.AllocID = allocID
+	}
+	if v := secret.Data[identityPDMembersKey]; len(v) > 0 {
+		if err := json.Unmarshal(v, &identity.PDMembers); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", identityPDMembersKey, err)
+		}
+	}
+	if v := secret.Data[identityStoresKey]; len(v) > 0 {
+		if err := json.Unmarshal(v, &identity.Stores); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", identityStoresKey, err)
+		}
+	}
+	return identity, nil
+}
+
+func (i *clusterIdentity) data() (map[string][]byte, error) {
+	members, err := json.Marshal(i.PDMembers)
+	if err != nil {
+		return nil, err
+	}
+	stores, err := json.Marshal(i.Stores)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		identityClusterIDKey: []byte(i.ClusterID),
+		identityAllocIDKey:   []byte(strconv.FormatUint(i.AllocID, 10)),
+		identityPDMembersKey: members,
+		identityStoresKey:    stores,
+	}, nil
+}
+
+// maxID returns the max ID recorded, the store IDs are allocated by PD too
+func (i *clusterIdentity) maxID() uint64 {
+	max := i.AllocID
+	for _, id := range i.Stores {
+		if v, err := strconv.ParseUint(id, 10, 64); err == nil && v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// readPDIdentity reads the cluster ID and the max allocated ID from PD, and whether the cluster is bootstrapped
+func readPDIdentity(etcdClient pdapi.PDEtcdClient) (clusterID, allocID uint64, bootstrapped bool, err error) {
+	kvs, err := etcdClient.Get(pdClusterIDPath, false)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if len(kvs) == 0 || len(kvs[0].Value) != 8 {
+		return 0, 0, false, fmt.Errorf("invalid %s", pdClusterIDPath)
+	}
+	clusterID = binary.BigEndian.Uint64(kvs[0].Value)
+
+	kvs, err = etcdClient.Get(pdAllocIDPath(clusterID), false)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if len(kvs) > 0 && len(kvs[0].Value) == 8 {
+		allocID = binary.BigEndian.Uint64(kvs[0].Value)
+	}
+
+	kvs, err = etcdClient.Get(pdRaftPath(clusterID), false)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return clusterID, allocID, len(kvs) > 0, nil
+}
+
+// pdRecoveryKVs returns the kvs pd-recover puts to restore the cluster
+func pdRecoveryKVs(clusterID, allocID uint64, now time.Time) ([]*pdapi.KeyValue, error) {
+	meta, err := (&metapb.Cluster{Id: clusterID, MaxPeerCount: identityMaxPeerCount}).Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return []*pdapi.KeyValue{
+		{Key: pdClusterIDPath, Value: uint64ToBytes(clusterID)},
+		{Key: pdAllocIDPath(clusterID), Value: uint64ToBytes(allocID)},
+		{Key: pdRaftPath(clusterID), Value: meta},
+		{Key: pdRaftPath(clusterID) + "/status/raft_bootstrap_time", Value: uint64ToBytes(uint64(now.UnixNano()))},
+	}, nil
+}
+
+func pdAllocIDPath(clusterID uint64) string {
+	return fmt.Sprintf("/pd/%d/alloc_id", clusterID)
+}
+
+func pdRaftPath(clusterID uint64) string {
+	return fmt.Sprintf("/pd/%d/raft", clusterID)
+}
+
+func uint64ToBytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+type FakeIdentityManager struct {
+	err error
+}
+
+func NewFakeIdentityManager() *FakeIdentityManager {
+	return &FakeIdentityManager{}
+}
+
+func (m *FakeIdentityManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeIdentityManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
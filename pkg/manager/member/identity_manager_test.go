@@ -0,0 +1,169 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestIdentityManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	ctrl := deps.GenericControl.(*controller.FakeGenericControl)
+	recorder := deps.Recorder.(*record.FakeRecorder)
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pvcIndexer := deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+	m := NewIdentityManager(deps).(*identityManager)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	newTC := func() *v1alpha1.TidbCluster {
+		tc := newTidbClusterForTiKV()
+		tc.CreationTimestamp = metav1.Time{Time: now}
+		tc.Spec.PD.Replicas = 1
+		tc.Spec.TiKV.Replicas = 1
+		tc.Spec.PersistentIdentity = &v1alpha1.PersistentIdentitySpec{}
+		tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 1}
+		tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+			"test-pd-0": {Name: "test-pd-0", ID: "11", Health: true},
+		}
+		return tc
+	}
+	setPD := func(etcdClient *pdapi.FakePDEtcdClient, clusterID, allocID uint64, bootstrapped bool) {
+		etcdClient.KVs = map[string][]byte{
+			pdClusterIDPath:          uint64ToBytes(clusterID),
+			pdAllocIDPath(clusterID): uint64ToBytes(allocID),
+		}
+		if bootstrapped {
+			etcdClient.KVs[pdRaftPath(clusterID)] = []byte("meta")
+		}
+	}
+	getSecret := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		exist, err := ctrl.Exist(client.ObjectKey{Namespace: "default", Name: "test-identity"}, secret)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(exist).To(BeTrue())
+		return secret
+	}
+
+	tc := newTC()
+	etcdClient := pdapi.NewFakePDEtcdClient()
+	deps.PDControl.(*pdapi.FakePDControl).SetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, etcdClient)
+
+	// nothing is recorded until the cluster is bootstrapped
+	setPD(etcdClient, 100, 0, false)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	exist, err := ctrl.Exist(client.ObjectKey{Namespace: "default", Name: "test-identity"}, &corev1.Secret{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeFalse())
+
+	// the identities are recorded in the Secret not owned by the TidbCluster
+	setPD(etcdClient, 100, 1000, true)
+	tc.Status.ClusterID = "100"
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{"5": {ID: "5", PodName: "test-tikv-0"}}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	secret := getSecret()
+	g.Expect(secret.OwnerReferences).To(BeEmpty())
+	g.Expect(secret.Labels).To(Equal(label.New().Instance(tc.GetInstanceName()).Identity().Labels()))
+	g.Expect(string(secret.Data[identityClusterIDKey])).To(Equal("100"))
+	g.Expect(string(secret.Data[identityAllocIDKey])).To(Equal("1000"))
+	g.Expect(string(secret.Data[identityPDMembersKey])).To(Equal(`{"test-pd-0":"11"}`))
+	g.Expect(string(secret.Data[identityStoresKey])).To(Equal(`{"test-tikv-0":"5"}`))
+	g.Expect(tc.Status.PersistentIdentity.ClusterID).To(Equal("100"))
+	g.Expect(tc.Status.PersistentIdentity.LastUpdateTime.Time).To(Equal(now))
+	g.Expect(secretIndexer.Add(secret)).To(Succeed())
+
+	// the max allocated ID is recorded again after the interval
+	setPD(etcdClient, 100, 2000, true)
+	now = now.Add(time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(string(getSecret().Data[identityAllocIDKey])).To(Equal("1000"))
+	now = now.Add(identityRecordInterval)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	secret = getSecret()
+	g.Expect(string(secret.Data[identityAllocIDKey])).To(Equal("2000"))
+	g.Expect(secretIndexer.Update(secret)).To(Succeed())
+
+	// the TidbCluster is recreated with the PVCs of TiKV retained, and PD starts as a new cluster
+	now = now.Add(time.Hour)
+	tc = newTC()
+	setPD(etcdClient, 200, 1000, false)
+	pdPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pd-0",
+		Namespace: "default",
+		Labels:    label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+	}}
+	g.Expect(podIndexer.Add(pdPod)).To(Succeed())
+
+	// the recorded cluster is not restored if no PVCs of TiKV are retained
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(etcdClient.KVs[pdClusterIDPath]).To(Equal(uint64ToBytes(200)))
+	g.Expect(tc.Status.PersistentIdentity.RecoveryTime).To(BeNil())
+
+	g.Expect(pvcIndexer.Add(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name:              "tikv-test-tikv-0",
+		Namespace:         "default",
+		Labels:            label.New().Instance(tc.GetInstanceName()).TiKV().Labels(),
+		CreationTimestamp: metav1.Time{Time: now.Add(-time.Hour)},
+	}})).To(Succeed())
+	err = m.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("waiting for PD to restart"))
+	g.Expect(etcdClient.KVs[pdClusterIDPath]).To(Equal(uint64ToBytes(100)))
+	g.Expect(etcdClient.KVs[pdAllocIDPath(100)]).To(Equal(uint64ToBytes(2000 + identityAllocIDMargin)))
+	g.Expect(etcdClient.KVs).To(HaveKey(pdRaftPath(100)))
+	g.Expect(etcdClient.KVs).To(HaveKey(pdRaftPath(100) + "/status/raft_bootstrap_time"))
+	g.Expect(tc.Status.PersistentIdentity.RecoveryTime.Time).To(Equal(now))
+	_, exist, err = podIndexer.Get(pdPod)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeFalse())
+	g.Expect(<-recorder.Events).To(ContainSubstring(identityRecoveredReason))
+
+	// TiKV is not synced until the PD pods created before the recovery restart
+	pdPod.CreationTimestamp = metav1.Time{Time: now.Add(-time.Minute)}
+	g.Expect(podIndexer.Add(pdPod)).To(Succeed())
+	tc.Status.PD.Members = nil
+	err = m.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("waiting for pd pod test-pd-0 to restart"))
+	pdPod.CreationTimestamp = metav1.Time{Time: now.Add(time.Minute)}
+	g.Expect(podIndexer.Update(pdPod)).To(Succeed())
+	tc.Status.PD.Members = newTC().Status.PD.Members
+
+	// the recorded identities are not overwritten if PD serves another bootstrapped cluster
+	setPD(etcdClient, 300, 10, true)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(<-recorder.Events).To(ContainSubstring(identityMismatchReason))
+	g.Expect(string(getSecret().Data[identityClusterIDKey])).To(Equal("100"))
+
+	// the Secret is kept when the persistent identity is disabled
+	tc.Spec.PersistentIdentity = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PersistentIdentity).To(BeNil())
+	getSecret()
+}
@@ -0,0 +1,193 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// operatorStatusPorts are the ports of the components accessed by tidb-controller-manager
+var operatorStatusPorts = []int{
+	2379,  // PD client port
+	20180, // TiKV status port
+	10080, // TiDB status port
+	20292, // TiFlash proxy status port
+	8301,  // TiCDC port
+}
+
+type networkPolicyManager struct {
+	deps *controller.Dependencies
+}
+
+// NewNetworkPolicyManager returns a manager which maintains the NetworkPolicies of the tidb cluster
+// according to spec.networkPolicy:
+//
+//   - <cluster>-peer selects all pods of the cluster, and allows the ingress from the pods of the
+//     cluster and spec.networkPolicy.peerFrom, and the ingress to the status ports from
+//     tidb-controller-manager
+//   - <cluster>-tidb-sql selects the TiDB pods, and allows the ingress to the MySQL port from
+//     spec.networkPolicy.sqlFrom
+//
+// The names of the NetworkPolicies are recorded in status.networkPolicies, and they are deleted
+// if spec.networkPolicy is removed.
+func NewNetworkPolicyManager(deps *controller.Dependencies) manager.Manager {
+	return &networkPolicyManager{
+		deps: deps,
+	}
+}
+
+func (m *networkPolicyManager) Sync(tc *v1alpha1.TidbCluster) error {
+	desired := []*networkingv1.NetworkPolicy{}
+	if tc.Spec.NetworkPolicy != nil {
+		desired = append(desired, m.getPeerNetworkPolicy(tc))
+		if tc.Spec.TiDB != nil {
+			desired = append(desired, m.getTiDBSQLNetworkPolicy(tc))
+		}
+	}
+
+	names := sets.NewString()
+	for _, np := range desired {
+		if _, err := m.deps.TypedControl.CreateOrUpdateNetworkPolicy(tc, np); err != nil {
+			return controller.RequeueErrorf("error creating or updating network policy %s/%s: %v", np.Namespace, np.Name, err)
+		}
+		names.Insert(np.Name)
+	}
+
+	// only the NetworkPolicies generated before are deleted, there is nothing to do if the
+	// feature is not used
+	for _, name := range tc.Status.NetworkPolicies {
+		if names.Has(name) {
+			continue
+		}
+		np := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: tc.GetNamespace(), Name: name}}
+		if err := m.deps.TypedControl.Delete(tc, np); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete network policy %s/%s: %v", np.Namespace, np.Name, err)
+		}
+	}
+	if names.Len() == 0 {
+		tc.Status.NetworkPolicies = nil
+	} else {
+		tc.Status.NetworkPolicies = names.List()
+	}
+	return nil
+}
+
+func (m *networkPolicyManager) getPeerNetworkPolicy(tc *v1alpha1.TidbCluster) *networkingv1.NetworkPolicy {
+	instanceLabel := label.New().Instance(tc.GetInstanceName())
+	from := []networkingv1.NetworkPolicyPeer{
+		{PodSelector: instanceLabel.LabelSelector()},
+	}
+	if tc.Spec.NetworkPolicy != nil {
+		from = append(from, tc.Spec.NetworkPolicy.PeerFrom...)
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-peer", tc.GetName()),
+			Namespace: tc.GetNamespace(),
+			Labels:    instanceLabel.Labels(),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *instanceLabel.LabelSelector(),
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{From: from},
+				m.getOperatorIngressRule(tc),
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// getOperatorIngressRule allows tidb-controller-manager to access the status ports of the components
+func (m *networkPolicyManager) getOperatorIngressRule(tc *v1alpha1.TidbCluster) networkingv1.NetworkPolicyIngressRule {
+	protocol := corev1.ProtocolTCP
+	ports := make([]networkingv1.NetworkPolicyPort, 0, len(operatorStatusPorts))
+	for _, p := range operatorStatusPorts {
+		port := intstr.FromInt(p)
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+	}
+	peer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{label.ComponentLabelKey: "controller-manager"},
+		},
+	}
+	// the pod selector only selects the pods in the namespace of the NetworkPolicy without namespaceSelector,
+	// the namespace name label is set on all namespaces since Kubernetes v1.21
+	if ns := m.deps.Namespace; ns != "" && ns != tc.GetNamespace() {
+		peer.NamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"kubernetes.io/metadata.name": ns},
+		}
+	}
+	return networkingv1.NetworkPolicyIngressRule{
+		Ports: ports,
+		From:  []networkingv1.NetworkPolicyPeer{peer},
+	}
+}
+
+func (m *networkPolicyManager) getTiDBSQLNetworkPolicy(tc *v1alpha1.TidbCluster) *networkingv1.NetworkPolicy {
+	tidbLabel := label.New().Instance(tc.GetInstanceName()).TiDB()
+	protocol := corev1.ProtocolTCP
+	// the named container port of the MySQL protocol, the port of the Service may differ
+	port := intstr.FromString("server")
+	rule := networkingv1.NetworkPolicyIngressRule{
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &protocol, Port: &port},
+		},
+	}
+	// the port is accessible from any source if From is empty
+	if tc.Spec.NetworkPolicy != nil {
+		rule.From = tc.Spec.NetworkPolicy.SQLFrom
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-tidb-sql", tc.GetName()),
+			Namespace: tc.GetNamespace(),
+			Labels:    tidbLabel.Labels(),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *tidbLabel.LabelSelector(),
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{rule},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+type FakeNetworkPolicyManager struct {
+	err error
+}
+
+func NewFakeNetworkPolicyManager() *FakeNetworkPolicyManager {
+	return &FakeNetworkPolicyManager{}
+}
+
+func (m *FakeNetworkPolicyManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeNetworkPolicyManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
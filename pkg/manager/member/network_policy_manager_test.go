@@ -0,0 +1,84 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNetworkPolicyManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	fakeDeps.Namespace = "tidb-admin"
+	ctrl := fakeDeps.GenericControl.(*controller.FakeGenericControl)
+	m := NewNetworkPolicyManager(fakeDeps)
+
+	listPolicies := func() map[string]networkingv1.NetworkPolicy {
+		list := &networkingv1.NetworkPolicyList{}
+		g.Expect(ctrl.FakeCli.List(context.TODO(), list)).To(Succeed())
+		policies := map[string]networkingv1.NetworkPolicy{}
+		for _, np := range list.Items {
+			policies[np.Name] = np
+		}
+		return policies
+	}
+
+	tc := newTidbClusterForTiDB()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(listPolicies()).To(BeEmpty())
+
+	appSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	monitorSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "monitor"}}
+	tc.Spec.NetworkPolicy = &v1alpha1.NetworkPolicySpec{
+		SQLFrom:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: appSelector}},
+		PeerFrom: []networkingv1.NetworkPolicyPeer{{PodSelector: monitorSelector}},
+	}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	policies := listPolicies()
+	g.Expect(policies).To(HaveLen(2))
+
+	peer := policies["test-peer"]
+	g.Expect(peer.Spec.PodSelector.MatchLabels).To(Equal(label.New().Instance(tc.GetInstanceName()).Labels()))
+	g.Expect(peer.Spec.Ingress).To(HaveLen(2))
+	g.Expect(peer.Spec.Ingress[0].Ports).To(BeEmpty())
+	g.Expect(peer.Spec.Ingress[0].From).To(HaveLen(2))
+	g.Expect(peer.Spec.Ingress[0].From[1].PodSelector).To(Equal(monitorSelector))
+	// tidb-controller-manager is allowed to access the status ports
+	g.Expect(peer.Spec.Ingress[1].Ports).To(HaveLen(len(operatorStatusPorts)))
+	g.Expect(peer.Spec.Ingress[1].From).To(HaveLen(1))
+	g.Expect(peer.Spec.Ingress[1].From[0].PodSelector.MatchLabels).To(HaveKeyWithValue(label.ComponentLabelKey, "controller-manager"))
+	g.Expect(peer.Spec.Ingress[1].From[0].NamespaceSelector.MatchLabels).To(HaveKeyWithValue("kubernetes.io/metadata.name", "tidb-admin"))
+
+	sql := policies["test-tidb-sql"]
+	g.Expect(sql.Spec.PodSelector.MatchLabels).To(HaveKeyWithValue(label.ComponentLabelKey, label.TiDBLabelVal))
+	g.Expect(sql.Spec.Ingress).To(HaveLen(1))
+	g.Expect(sql.Spec.Ingress[0].Ports).To(HaveLen(1))
+	g.Expect(sql.Spec.Ingress[0].Ports[0].Port.String()).To(Equal("server"))
+	g.Expect(sql.Spec.Ingress[0].From).To(Equal(tc.Spec.NetworkPolicy.SQLFrom))
+	g.Expect(tc.Status.NetworkPolicies).To(Equal([]string{"test-peer", "test-tidb-sql"}))
+
+	tc.Spec.NetworkPolicy = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(listPolicies()).To(BeEmpty())
+	g.Expect(tc.Status.NetworkPolicies).To(BeEmpty())
+}
@@ -37,7 +37,21 @@ func getNodeLabels(nodeLister corelisterv1.NodeLister, nodeName string, storeLab
 				labels[storeLabel] = host
 			}
 		}
+		if storeLabel == "zone" {
+			if zone, found := nodeZone(ls); found {
+				labels[storeLabel] = zone
+			}
+		}
 
 	}
 	return labels, nil
 }
+
+// nodeZone returns the zone of the node by the well-known labels
+func nodeZone(ls map[string]string) (string, bool) {
+	if zone, found := ls[corev1.LabelTopologyZone]; found {
+		return zone, true
+	}
+	zone, found := ls[corev1.LabelFailureDomainBetaZone]
+	return zone, found
+}
@@ -101,7 +101,7 @@ func (f *pdFailover) tryToMarkAPeerAsFailure(tc *v1alpha1.TidbCluster) error {
 		if tc.Status.PD.FailureMembers == nil {
 			tc.Status.PD.FailureMembers = map[string]v1alpha1.PDFailureMember{}
 		}
-		failoverDeadline := pdMember.LastTransitionTime.Add(f.deps.CLIConfig.PDFailoverPeriod)
+		failoverDeadline := pdMember.LastTransitionTime.Add(f.deps.CLIConfig.GetFailoverPeriod(v1alpha1.PDMemberType))
 		_, exist := tc.Status.PD.FailureMembers[pdName]
 
 		if pdMember.Health || time.Now().Before(failoverDeadline) || exist {
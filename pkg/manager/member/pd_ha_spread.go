@@ -0,0 +1,191 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+// pdHASpreadTopologyKeys returns the topology keys to spread PD Pods across
+func pdHASpreadTopologyKeys(tc *v1alpha1.TidbCluster) []string {
+	if len(tc.Spec.PD.HASpread.TopologyKeys) == 0 {
+		return []string{corev1.LabelHostname}
+	}
+	return tc.Spec.PD.HASpread.TopologyKeys
+}
+
+// maxPDPodsPerTopology returns the max number of PD Pods in one topology, the majority of
+// PD members must not be lost when a topology is down
+func maxPDPodsPerTopology(replicas int32) int {
+	max := int((replicas+1)/2) - 1
+	if max <= 0 {
+		max = 1
+	}
+	return max
+}
+
+// pdHASpreadConstraints returns the topology spread constraints of PD Pods for the topology keys
+// of HASpread, the keys already in tscs are skipped. The constraints are enforced by kube-scheduler
+// together with all its other filters, e.g. the resources, the (anti-)affinity and the host ports.
+func pdHASpreadConstraints(tc *v1alpha1.TidbCluster, tscs []corev1.TopologySpreadConstraint) []corev1.TopologySpreadConstraint {
+	existing := sets.NewString()
+	for _, tsc := range tscs {
+		existing.Insert(tsc.TopologyKey)
+	}
+	var constraints []corev1.TopologySpreadConstraint
+	for _, key := range pdHASpreadTopologyKeys(tc) {
+		if existing.Has(key) {
+			continue
+		}
+		constraints = append(constraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       key,
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+			},
+		})
+	}
+	return constraints
+}
+
+// checkPDHASpread warns if the majority of PD members may be lost with one topology of the first
+// topology key of HASpread. kube-scheduler only counts the topologies of the nodes available to
+// PD Pods, so the PD Pods are spread evenly but may be too many in one topology if the available
+// nodes are in too few topologies. The running PD Pods are checked as well since the nodes may be
+// relabeled after the Pods are scheduled.
+func (m *pdMemberManager) checkPDHASpread(tc *v1alpha1.TidbCluster, podSpec *corev1.PodSpec) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	if m.deps.NodeLister == nil {
+		klog.V(4).Infof("checkPDHASpread: the permission for nodes is required to check the topologies of PD pods of cluster %s/%s", ns, tcName)
+		return
+	}
+	replicas := tc.PDStsDesiredReplicas()
+	if replicas == 0 {
+		return
+	}
+	nodes, err := m.deps.NodeLister.List(labels.Everything())
+	if err != nil {
+		klog.Warningf("checkPDHASpread: failed to list nodes, error: %v", err)
+		return
+	}
+
+	key := pdHASpreadTopologyKeys(tc)[0]
+	maxPods := maxPDPodsPerTopology(replicas)
+	nodeTopology := make(map[string]string, len(nodes))
+	available := sets.NewString()
+	for _, node := range nodes {
+		topology, ok := node.Labels[key]
+		if !ok {
+			continue
+		}
+		nodeTopology[node.Name] = topology
+		if nodeAvailableForPod(podSpec, node) {
+			available.Insert(topology)
+		}
+	}
+	if available.Len() == 0 || (int(replicas)+available.Len()-1)/available.Len() > maxPods {
+		m.reportPDHASpread(tc, fmt.Sprintf("%d PD pods can't be spread across %d topologies of %s with at most %d pods in each topology",
+			replicas, available.Len(), key, maxPods))
+		return
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).PD().Selector()
+	if err != nil {
+		klog.Warningf("checkPDHASpread: failed to get the selector of PD pods of cluster %s/%s, error: %v", ns, tcName, err)
+		return
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		klog.Warningf("checkPDHASpread: failed to list PD pods of cluster %s/%s, error: %v", ns, tcName, err)
+		return
+	}
+	desiredOrdinals := tc.PDStsDesiredOrdinals(false)
+	podsOfTopology := make(map[string][]string)
+	for _, pod := range pods {
+		ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+		if err != nil || !desiredOrdinals.Has(ordinal) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if topology, ok := nodeTopology[pod.Spec.NodeName]; ok {
+			podsOfTopology[topology] = append(podsOfTopology[topology], pod.Name)
+		}
+	}
+	var crowded []string
+	for topology, names := range podsOfTopology {
+		if len(names) > maxPods {
+			sort.Strings(names)
+			crowded = append(crowded, fmt.Sprintf("%s (%s)", topology, strings.Join(names, ", ")))
+		}
+	}
+	if len(crowded) > 0 {
+		sort.Strings(crowded)
+		m.reportPDHASpread(tc, fmt.Sprintf("more than %d PD pods are running in the topologies of %s: %s",
+			maxPods, key, strings.Join(crowded, "; ")))
+	}
+}
+
+func (m *pdMemberManager) reportPDHASpread(tc *v1alpha1.TidbCluster, reason string) {
+	msg := fmt.Sprintf("%s, the majority of PD members would be lost if one topology is down", reason)
+	klog.Warningf("checkPDHASpread: cluster %s/%s: %s", tc.GetNamespace(), tc.GetName(), msg)
+	m.deps.Recorder.Event(tc, corev1.EventTypeWarning, "HASpreadUnsatisfiable", msg)
+}
+
+// nodeAvailableForPod checks the filters of kube-scheduler depending on the node only, i.e. the
+// unschedulable mark, the node selector, the required node affinity and the taints, which are the
+// filters deciding the topologies counted by the topology spread constraints
+func nodeAvailableForPod(podSpec *corev1.PodSpec, node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	if !labels.SelectorFromSet(podSpec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	if podSpec.Affinity != nil && podSpec.Affinity.NodeAffinity != nil && podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !nodeMatchesTerms(node, podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) {
+			return false
+		}
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !v1helper.TolerationsTolerateTaint(podSpec.Tolerations, taint) {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeMatchesTerms checks if the node matches any of the node selector terms by both the
+// match expressions of the labels and the match fields
+func nodeMatchesTerms(node *corev1.Node, terms []corev1.NodeSelectorTerm) bool {
+	return v1helper.MatchNodeSelectorTerms(terms, labels.Set(node.Labels), fields.Set{"metadata.name": node.Name})
+}
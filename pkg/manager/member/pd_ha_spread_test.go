@@ -0,0 +1,140 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestGetNewPDSetWithHASpread(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.PD.HASpread = &v1alpha1.PDHASpread{
+		TopologyKeys: []string{corev1.LabelZoneFailureDomain, corev1.LabelHostname},
+	}
+	// the keys set by the users are not added again
+	tc.Spec.PD.TopologySpreadConstraints = []v1alpha1.TopologySpreadConstraint{{TopologyKey: corev1.LabelHostname}}
+	set, err := getNewPDSetForTidbCluster(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	podSpec := set.Spec.Template.Spec
+	g.Expect(podSpec.SchedulerName).To(BeEmpty())
+	g.Expect(podSpec.TopologySpreadConstraints).To(HaveLen(2))
+	tsc := podSpec.TopologySpreadConstraints[1]
+	g.Expect(podSpec.TopologySpreadConstraints[0].TopologyKey).To(Equal(corev1.LabelHostname))
+	g.Expect(tsc.TopologyKey).To(Equal(corev1.LabelZoneFailureDomain))
+	g.Expect(tsc.MaxSkew).To(Equal(int32(1)))
+	g.Expect(tsc.WhenUnsatisfiable).To(Equal(corev1.DoNotSchedule))
+	g.Expect(tsc.LabelSelector.MatchLabels).To(Equal(label.New().Instance(tc.GetInstanceName()).PD().Labels()))
+
+	// defaults to the hostname
+	tc.Spec.PD.TopologySpreadConstraints = nil
+	tc.Spec.PD.HASpread = &v1alpha1.PDHASpread{}
+	set, err = getNewPDSetForTidbCluster(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Spec.Template.Spec.TopologySpreadConstraints).To(HaveLen(1))
+	g.Expect(set.Spec.Template.Spec.TopologySpreadConstraints[0].TopologyKey).To(Equal(corev1.LabelHostname))
+}
+
+func TestPDMemberManagerCheckPDHASpread(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pmm, podIndexer, _ := newFakePDMemberManager()
+	pmm.deps.NodeLister = pmm.deps.KubeInformerFactory.Core().V1().Nodes().Lister()
+	nodeIndexer := pmm.deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	recorder := pmm.deps.Recorder.(*record.FakeRecorder)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.PD.Replicas = 3
+	tc.Spec.PD.HASpread = &v1alpha1.PDHASpread{
+		TopologyKeys: []string{corev1.LabelZoneFailureDomain, corev1.LabelHostname},
+	}
+	set, err := getNewPDSetForTidbCluster(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	podSpec := &set.Spec.Template.Spec
+
+	newNode := func(name, zone string) *corev1.Node {
+		node := &corev1.Node{}
+		node.Name = name
+		node.Labels = map[string]string{corev1.LabelZoneFailureDomain: zone, corev1.LabelHostname: name}
+		return node
+	}
+	// the only node of zone-c is tainted, so 2 of 3 PD Pods would be in one zone
+	nodeC1 := newNode("node-c1", "zone-c")
+	nodeC1.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "tikv", Effect: corev1.TaintEffectNoSchedule}}
+	for _, node := range []*corev1.Node{
+		newNode("node-a1", "zone-a"),
+		newNode("node-a2", "zone-a"),
+		newNode("node-b1", "zone-b"),
+		nodeC1,
+	} {
+		g.Expect(nodeIndexer.Add(node)).To(Succeed())
+	}
+	pmm.checkPDHASpread(tc, podSpec)
+	events := collectEvents(recorder.Events)
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(ContainSubstring("HASpreadUnsatisfiable"))
+	g.Expect(events[0]).To(ContainSubstring("3 PD pods can't be spread across 2 topologies"))
+
+	// the taint is tolerated
+	podSpec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "tikv", Effect: corev1.TaintEffectNoSchedule}}
+	pmm.checkPDHASpread(tc, podSpec)
+	g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+
+	// the nodes excluded by the node affinity are not counted
+	podSpec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"node-b1"}}},
+			}},
+		},
+	}}
+	pmm.checkPDHASpread(tc, podSpec)
+	g.Expect(collectEvents(recorder.Events)).To(HaveLen(1))
+	podSpec.Affinity = nil
+
+	// the running PD Pods are in one zone after the nodes are relabeled
+	for i, nodeName := range []string{"node-a1", "node-a2", "node-b1"} {
+		pod := &corev1.Pod{}
+		pod.Namespace = tc.Namespace
+		pod.Name = PdPodName(tc.Name, int32(i))
+		pod.Labels = label.New().Instance(tc.GetInstanceName()).PD().Labels()
+		pod.Spec.NodeName = nodeName
+		g.Expect(podIndexer.Add(pod)).To(Succeed())
+	}
+	pmm.checkPDHASpread(tc, podSpec)
+	events = collectEvents(recorder.Events)
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(ContainSubstring("zone-a (test-pd-0, test-pd-1)"))
+
+	// nothing is checked without the permission for nodes
+	pmm.deps.NodeLister = nil
+	pmm.checkPDHASpread(tc, podSpec)
+	g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+}
+
+func TestMaxPDPodsPerTopology(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for replicas, want := range map[int32]int{1: 1, 2: 1, 3: 1, 4: 1, 5: 2, 7: 3} {
+		g.Expect(maxPDPodsPerTopology(replicas)).To(Equal(want), "replicas %d", replicas)
+	}
+}
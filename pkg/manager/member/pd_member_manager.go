@@ -132,9 +132,10 @@ func (m *pdMemberManager) syncPDServiceForTidbCluster(tc *v1alpha1.TidbCluster)
 	if err != nil {
 		return err
 	}
-	if !equal {
+	if !equal || !externalDNSAnnotationsEqual(newSvc, oldSvc) {
 		svc := *oldSvc
 		svc.Spec = newSvc.Spec
+		copyExternalDNSAnnotations(&svc, newSvc)
 		// TODO add unit test
 		err = controller.SetServiceLastAppliedConfigAnnotation(&svc)
 		if err != nil {
@@ -217,6 +218,9 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	if err != nil {
 		return err
 	}
+	if tc.Spec.PD.HASpread != nil {
+		m.checkPDHASpread(tc, &newPDSet.Spec.Template.Spec)
+	}
 	if setNotExist {
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newPDSet)
 		if err != nil {
@@ -252,7 +256,7 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return err
 	}
 
-	if m.deps.CLIConfig.AutoFailover {
+	if m.deps.CLIConfig.IsAutoFailover() {
 		if m.shouldRecover(tc) {
 			m.failover.Recover(tc)
 		} else if tc.Spec.PD.MaxFailoverCount != nil && *tc.Spec.PD.MaxFailoverCount > 0 && (tc.PDAllPodsStarted() && !tc.PDAllMembersReady() || tc.PDAutoFailovering()) {
@@ -330,6 +334,8 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 		tc.Status.PD.Phase = v1alpha1.UpgradePhase
 	} else {
 		tc.Status.PD.Phase = v1alpha1.NormalPhase
+		// no operation is in flight
+		tc.Status.PD.InFlightOperation = nil
 	}
 
 	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
@@ -424,6 +430,7 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 
 // syncPDConfigMap syncs the configmap of PD
 func (m *pdMemberManager) syncPDConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
+	tc.Status.PD.DerivedConfig = pdDerivedConfig(tc)
 	// For backward compatibility, only sync tidb configmap when .pd.config is non-nil
 	if tc.Spec.PD.Config == nil {
 		return nil, nil
@@ -494,6 +501,7 @@ func (m *pdMemberManager) getNewPDServiceForTidbCluster(tc *v1alpha1.TidbCluster
 			pdService.Spec.Ports[0].Name = *svcSpec.PortName
 		}
 	}
+	setExternalDNSAnnotations(pdService, tc, v1alpha1.PDMemberType)
 	return pdService
 }
 
@@ -659,6 +667,10 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	storageVolMounts, additionalPVCs := util.BuildStorageVolumeAndVolumeMount(tc.Spec.PD.StorageVolumes, tc.Spec.PD.StorageClassName, v1alpha1.PDMemberType)
 	volMounts = append(volMounts, storageVolMounts...)
 	volMounts = append(volMounts, tc.Spec.PD.AdditionalVolumeMounts...)
+	if hooksMount, hooksVolume := startScriptHooksMountVolume(tc.Spec.PD.StartScriptHooks); hooksMount != nil {
+		volMounts = append(volMounts, *hooksMount)
+		vols = append(vols, *hooksVolume)
+	}
 
 	sysctls := "sysctl -w"
 	var initContainers []corev1.Container
@@ -688,6 +700,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 					// ref:https://kubernetes.io/docs/concepts/workloads/pods/init-containers/#resources
 					Resources: controller.ContainerResource(tc.Spec.PD.ResourceRequirements),
 				})
+				setHelperInitializer(tc, &initContainers[len(initContainers)-1])
 			}
 		}
 	}
@@ -735,6 +748,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 		VolumeMounts: volMounts,
 		Resources:    controller.ContainerResource(tc.Spec.PD.ResourceRequirements),
 	}
+	setProbes(&pdContainer, tc.Spec.PD.Probes, probeHandlers{port: 2379, statusPort: 2379, statusPath: "/pd/api/v1/ping"})
 	env := []corev1.EnvVar{
 		{
 			Name: "NAMESPACE",
@@ -787,6 +801,11 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	}
 	podSpec.SecurityContext = podSecurityContext
 	podSpec.InitContainers = append(initContainers, basePDSpec.InitContainers()...)
+	tc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.PDMemberType.String())
+	applyEphemeralStorage(basePDSpec.EphemeralStorage(), &podSpec, v1alpha1.PDMemberType.String())
+	if tc.Spec.PD.HASpread != nil {
+		podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, pdHASpreadConstraints(tc, podSpec.TopologySpreadConstraints)...)
+	}
 
 	updateStrategy := apps.StatefulSetUpdateStrategy{}
 	if basePDSpec.StatefulSetUpdateStrategy() == apps.OnDeleteStatefulSetStrategyType {
@@ -859,6 +878,10 @@ func getPDConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 	if tc.Spec.PD.EnableDashboardInternalProxy != nil {
 		config.Set("dashboard.internal-proxy", *tc.Spec.PD.EnableDashboardInternalProxy)
 	}
+	if tc.Spec.TopologyProfile != nil {
+		setPDTopologyProfileConfig(tc.Spec.TopologyProfile, config.GenericConfig)
+	}
+	derivePDConfig(tc, config.GenericConfig, tc.Spec.PD.ResourceRequirements)
 
 	confText, err := config.MarshalTOML()
 	if err != nil {
@@ -876,6 +899,7 @@ func getPDConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 	if tc.Spec.PD.StartUpScriptVersion == "v1" {
 		sm.CheckDomainScript = checkDNSV1
 	}
+	setStartScriptHooks(&sm.CommonModel, tc.Spec.PD.StartScriptHooks)
 
 	startScript, err := RenderPDStartScript(sm)
 	if err != nil {
@@ -23,6 +23,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
@@ -98,6 +99,12 @@ func (s *pdScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 		return nil
 	}
 
+	// the member is deleted before tidb-controller-manager restarts, resume from the checkpoint
+	if checkpointReached(tc.Status.PD.InFlightOperation, v1alpha1.ScalePhase, ordinal, v1alpha1.OperationStepMemberDeleted) {
+		klog.Infof("pdScaler.ScaleIn: member %s is already deleted", memberName)
+		return s.deferDeletingPVCs(tc, pdPodName, newSet, replicas, deleteSlots)
+	}
+
 	pdClient := controller.GetPDClient(s.deps.PDControl, tc)
 	leader, err := pdClient.GetPDLeader()
 	if err != nil {
@@ -141,7 +148,15 @@ func (s *pdScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 		return err
 	}
 	klog.Infof("pdScaler.ScaleIn: delete member %s successfully", memberName)
+	setCheckpoint(&tc.Status.PD.InFlightOperation, v1alpha1.ScalePhase, ordinal, v1alpha1.OperationStepMemberDeleted)
+
+	return s.deferDeletingPVCs(tc, pdPodName, newSet, replicas, deleteSlots)
+}
 
+// deferDeletingPVCs adds the defer deleting annotation to the PVCs of the pod being scaled in and reduces the replicas
+func (s *pdScaler) deferDeletingPVCs(tc *v1alpha1.TidbCluster, pdPodName string, newSet *apps.StatefulSet, replicas int32, deleteSlots sets.Int32) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
 	pod, err := s.deps.PodLister.Pods(ns).Get(pdPodName)
 	if err != nil {
 		return fmt.Errorf("pdScaler.ScaleIn: failed to get pod %s/%s for pd in tc %s/%s, error: %s", ns, pdPodName, ns, tcName, err)
@@ -381,6 +381,62 @@ func TestPDScalerScaleIn(t *testing.T) {
 	}
 }
 
+func TestPDScalerScaleInResumeFromCheckpoint(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Status.PD.Synced = true
+	oldSet := newStatefulSetForPDScale()
+	newSet := oldSet.DeepCopy()
+	newSet.Spec.Replicas = pointer.Int32Ptr(3)
+
+	scaler, pdControl, pvcIndexer, podIndexer, pvcControl := newFakePDScaler()
+	pvc := newScaleInPVCForStatefulSet(oldSet, v1alpha1.PDMemberType, tc.Name)
+	pvcIndexer.Add(pvc)
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PdPodName(tc.GetName(), 4),
+			Namespace: corev1.NamespaceDefault,
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+				},
+			}},
+		},
+	}
+	podIndexer.Add(pod)
+
+	deleted := 0
+	pdClient := controller.NewFakePDClient(pdControl, tc)
+	pdClient.AddReaction(pdapi.GetPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdpb.Member{Name: PdPodName(tc.GetName(), 0)}, nil
+	})
+	pdClient.AddReaction(pdapi.DeleteMemberActionType, func(action *pdapi.Action) (interface{}, error) {
+		deleted++
+		return nil, nil
+	})
+
+	// the member is deleted but the PVC is failed to update
+	pvcControl.SetUpdatePVCError(errors.NewInternalError(fmt.Errorf("API server failed")), 0)
+	err := scaler.ScaleIn(tc, oldSet, newSet)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(deleted).To(Equal(1))
+	g.Expect(tc.Status.PD.InFlightOperation).NotTo(BeNil())
+	g.Expect(tc.Status.PD.InFlightOperation.Ordinal).To(Equal(int32(4)))
+	g.Expect(tc.Status.PD.InFlightOperation.Step).To(Equal(v1alpha1.OperationStepMemberDeleted))
+
+	// resume from the checkpoint without deleting the member again
+	pvcControl.SetUpdatePVCError(nil, 0)
+	newSet.Spec.Replicas = pointer.Int32Ptr(3)
+	err = scaler.ScaleIn(tc, oldSet, newSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deleted).To(Equal(1))
+	g.Expect(int(*newSet.Spec.Replicas)).To(Equal(4))
+}
+
 func TestPDScalerScaleInBlockByOtherComponents(t *testing.T) {
 	// check if PD scale in is blocked when other components are using PD
 	g := NewGomegaWithT(t)
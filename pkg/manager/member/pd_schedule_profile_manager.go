@@ -0,0 +1,288 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PDScheduleProfilesChanged is the reason of the event emitted when the active schedule profiles change
+	PDScheduleProfilesChanged = "PDScheduleProfilesChanged"
+	// PDScheduleDrifted is the reason of the event emitted when an item set by the schedule profiles
+	// is changed outside the operator
+	PDScheduleDrifted = "PDScheduleDrifted"
+	// PDScheduleUnknownItems is the reason of the event emitted when the items set by the active
+	// profiles are not found in the schedule config of PD
+	PDScheduleUnknownItems = "PDScheduleUnknownItems"
+)
+
+type pdScheduleProfileManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewPDScheduleProfileManager returns a manager which applies the schedule config items of the
+// active profiles of spec.pd.scheduleProfiles to PD, and reverts the items to the values before
+// they were changed once no active profile sets them. The items changed outside the operator are
+// reported, and reverted or kept by spec.pd.scheduleDriftPolicy.
+func NewPDScheduleProfileManager(deps *controller.Dependencies) manager.Manager {
+	return &pdScheduleProfileManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (m *pdScheduleProfileManager) Sync(tc *v1alpha1.TidbCluster) error {
+	var profiles []v1alpha1.PDScheduleProfile
+	driftPolicy := v1alpha1.ScheduleDriftPolicyRevert
+	if tc.Spec.PD != nil {
+		profiles = tc.Spec.PD.ScheduleProfiles
+		if tc.Spec.PD.ScheduleDriftPolicy != "" {
+			driftPolicy = tc.Spec.PD.ScheduleDriftPolicy
+		}
+	}
+	status := tc.Status.PD.ScheduleProfiles
+	if len(profiles) == 0 && status == nil {
+		return nil
+	}
+	// the items can't be changed or reverted without PD, they are synced after PD recovers
+	if !tc.PDIsAvailable() {
+		return nil
+	}
+
+	now := m.now()
+	active, desired, err := m.activeProfiles(tc, profiles, now)
+	if err != nil {
+		return err
+	}
+	// the items lowered by spec.eventThrottling are left to it until they are restored
+	held := map[string]bool{}
+	if throttling := tc.Status.EventThrottling; throttling != nil {
+		for name := range throttling.OriginalScheduleConfig {
+			held[name] = true
+			delete(desired, name)
+		}
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	current, err := pdClient.GetScheduleConfig()
+	if err != nil {
+		return fmt.Errorf("schedule profiles: failed to get the schedule config of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	if status == nil {
+		status = &v1alpha1.PDScheduleProfilesStatus{}
+	}
+	if status.Original == nil {
+		status.Original = map[string]string{}
+	}
+	activeChanged := !stringSliceEqual(active, status.ActiveProfiles)
+
+	drifted := map[string]v1alpha1.PDScheduleDrift{}
+	for _, name := range sortedKeys(status.Applied) {
+		expected := status.Applied[name]
+		actual, ok := current[name]
+		if held[name] || !ok || configValueEqual(expected, actual) {
+			continue
+		}
+		drift := v1alpha1.PDScheduleDrift{Name: name, Expected: expected, Actual: actual, DetectedTime: metav1.Time{Time: now}}
+		if old := findScheduleDrift(status.Drifts, name); old != nil && old.Expected == expected && old.Actual == actual {
+			drift.DetectedTime = old.DetectedTime
+		} else {
+			klog.Warningf("schedule profiles: %s of tc %s/%s is changed to %s outside the operator, expected %s", name, tc.GetNamespace(), tc.GetName(), actual, expected)
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDScheduleDrifted, "%s is changed to %s outside the operator, expected %s", name, actual, expected)
+		}
+		drifted[name] = drift
+	}
+
+	// the unknown items, e.g. the items of other PD versions, are skipped so that the others are still set
+	var unknown []string
+	for _, name := range sortedKeys(desired) {
+		if _, ok := current[name]; !ok {
+			unknown = append(unknown, name)
+			delete(desired, name)
+		}
+	}
+	if len(unknown) > 0 && !stringSliceEqual(unknown, status.UnknownItems) {
+		klog.Warningf("schedule profiles: %v of tc %s/%s are not found in the schedule config, skip them", unknown, tc.GetNamespace(), tc.GetName())
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PDScheduleUnknownItems, "%v are not found in the schedule config of PD, skip them", unknown)
+	}
+	status.UnknownItems = unknown
+
+	update := map[string]interface{}{}
+	for _, name := range sortedKeys(desired) {
+		value := desired[name]
+		actual := current[name]
+		if _, ok := status.Original[name]; !ok {
+			status.Original[name] = actual
+		}
+		if configValueEqual(value, actual) {
+			continue
+		}
+		// the drifted items are kept until the active profiles change
+		if _, ok := drifted[name]; ok && driftPolicy == v1alpha1.ScheduleDriftPolicyKeep && !activeChanged && status.Applied[name] == value {
+			continue
+		}
+		update[name] = pdapi.ParseConfigValue(value)
+	}
+	var reverted []string
+	for _, name := range sortedKeys(status.Original) {
+		if _, ok := desired[name]; ok || held[name] {
+			continue
+		}
+		if actual, ok := current[name]; !ok || !configValueEqual(status.Original[name], actual) {
+			update[name] = pdapi.ParseConfigValue(status.Original[name])
+		}
+		reverted = append(reverted, name)
+	}
+	if len(update) > 0 {
+		if err := pdClient.UpdateScheduleConfig(update); err != nil {
+			return fmt.Errorf("schedule profiles: failed to update the schedule config of tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+		}
+	}
+	for _, name := range reverted {
+		delete(status.Original, name)
+	}
+
+	if activeChanged {
+		klog.Infof("schedule profiles: active profiles of tc %s/%s are changed from %v to %v", tc.GetNamespace(), tc.GetName(), status.ActiveProfiles, active)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PDScheduleProfilesChanged, "active profiles are changed from %v to %v", status.ActiveProfiles, active)
+	}
+	status.ActiveProfiles = active
+	status.Applied = desired
+	status.Drifts = nil
+	for _, name := range sortedKeys(status.Applied) {
+		drift, ok := drifted[name]
+		if _, updated := update[name]; ok && !updated {
+			status.Drifts = append(status.Drifts, drift)
+		}
+	}
+	if len(status.Applied) == 0 {
+		status.Applied = nil
+	}
+	if len(status.Original) == 0 {
+		status.Original = nil
+	}
+	if len(profiles) == 0 && status.Original == nil {
+		tc.Status.PD.ScheduleProfiles = nil
+		return nil
+	}
+	tc.Status.PD.ScheduleProfiles = status
+	return nil
+}
+
+// activeProfiles returns the names of the active profiles and the items set by them, the later
+// profiles override the earlier ones
+func (m *pdScheduleProfileManager) activeProfiles(tc *v1alpha1.TidbCluster, profiles []v1alpha1.PDScheduleProfile, now time.Time) ([]string, map[string]string, error) {
+	var active []string
+	desired := map[string]string{}
+	for _, profile := range profiles {
+		inWindow, err := inMaintenanceWindow(profile.Window, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("schedule profiles: invalid window of profile %s, error: %v", profile.Name, err)
+		}
+		if !inWindow {
+			continue
+		}
+		if len(profile.Events) > 0 {
+			events, err := eventsInProgress(m.deps, tc, profile.Events)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(events) == 0 {
+				continue
+			}
+		}
+		active = append(active, profile.Name)
+		for name, value := range profile.Config {
+			desired[name] = value
+		}
+	}
+	return active, desired, nil
+}
+
+func findScheduleDrift(drifts []v1alpha1.PDScheduleDrift, name string) *v1alpha1.PDScheduleDrift {
+	for i := range drifts {
+		if drifts[i].Name == name {
+			return &drifts[i]
+		}
+	}
+	return nil
+}
+
+// configValueEqual returns whether two values of a config item are equal, the numbers and
+// durations are compared by their values, e.g. 64 equals to 64.0 and 30m equals to 30m0s
+func configValueEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		y, err := strconv.ParseFloat(b, 64)
+		return err == nil && x == y
+	}
+	if x, err := time.ParseDuration(a); err == nil {
+		y, err := time.ParseDuration(b)
+		return err == nil && x == y
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringSliceEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type FakePDScheduleProfileManager struct {
+	err error
+}
+
+func NewFakePDScheduleProfileManager() *FakePDScheduleProfileManager {
+	return &FakePDScheduleProfileManager{}
+}
+
+func (m *FakePDScheduleProfileManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakePDScheduleProfileManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestPDScheduleProfileManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	tc.Spec.PD = &v1alpha1.PDSpec{
+		ScheduleProfiles: []v1alpha1.PDScheduleProfile{
+			{
+				Name:   "scale",
+				Events: []v1alpha1.ThrottledEvent{v1alpha1.ThrottledEventScale},
+				Config: map[string]string{"replica-schedule-limit": "64"},
+			},
+			{
+				Name:   "night",
+				Window: &v1alpha1.MaintenanceWindow{Schedule: "0 1 * * *", Duration: metav1.Duration{Duration: 6 * time.Hour}},
+				Config: map[string]string{"replica-schedule-limit": "8", "max-store-down-time": "1h"},
+			},
+		},
+	}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{"test-pd-0": {Name: "test-pd-0", Health: true}}
+	tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 1}
+
+	fakeDeps := controller.NewFakeDependencies()
+	recorder := fakeDeps.Recorder.(*record.FakeRecorder)
+	m := NewPDScheduleProfileManager(fakeDeps).(*pdScheduleProfileManager)
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.Local)
+	m.now = func() time.Time { return now }
+
+	config := map[string]string{"replica-schedule-limit": "5", "max-store-down-time": "30m0s"}
+	updates := 0
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		items := map[string]string{}
+		for name, value := range config {
+			items[name] = value
+		}
+		return items, nil
+	})
+	pdClient.AddReaction(pdapi.UpdateScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		updates++
+		for name, value := range action.Config {
+			config[name] = fmt.Sprint(value)
+		}
+		return nil, nil
+	})
+
+	// no profile is active
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(updates).To(Equal(0))
+	g.Expect(tc.Status.PD.ScheduleProfiles.ActiveProfiles).To(BeEmpty())
+
+	// the profile is active during the scaling of TiKV
+	tc.Status.TiKV.Phase = v1alpha1.ScalePhase
+	g.Expect(m.Sync(tc)).To(Succeed())
+	status := tc.Status.PD.ScheduleProfiles
+	g.Expect(status.ActiveProfiles).To(Equal([]string{"scale"}))
+	g.Expect(status.Applied).To(Equal(map[string]string{"replica-schedule-limit": "64"}))
+	g.Expect(status.Original).To(Equal(map[string]string{"replica-schedule-limit": "5"}))
+	g.Expect(config["replica-schedule-limit"]).To(Equal("64"))
+	g.Expect(<-recorder.Events).To(ContainSubstring(PDScheduleProfilesChanged))
+
+	// the changes outside the operator are reverted by default
+	config["replica-schedule-limit"] = "32"
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(config["replica-schedule-limit"]).To(Equal("64"))
+	g.Expect(tc.Status.PD.ScheduleProfiles.Drifts).To(BeEmpty())
+	g.Expect(<-recorder.Events).To(ContainSubstring(PDScheduleDrifted))
+
+	// the changes are kept and reported until the active profiles change
+	tc.Spec.PD.ScheduleDriftPolicy = v1alpha1.ScheduleDriftPolicyKeep
+	config["replica-schedule-limit"] = "32"
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(config["replica-schedule-limit"]).To(Equal("32"))
+	drifts := tc.Status.PD.ScheduleProfiles.Drifts
+	g.Expect(drifts).To(HaveLen(1))
+	g.Expect(drifts[0].Name).To(Equal("replica-schedule-limit"))
+	g.Expect(drifts[0].Expected).To(Equal("64"))
+	g.Expect(drifts[0].Actual).To(Equal("32"))
+	g.Expect(<-recorder.Events).To(ContainSubstring(PDScheduleDrifted))
+	detected := drifts[0].DetectedTime
+	now = now.Add(time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PD.ScheduleProfiles.Drifts[0].DetectedTime).To(Equal(detected))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// the later profile wins, and the same values in different formats are not updated
+	now = time.Date(2022, 1, 2, 2, 0, 0, 0, time.Local)
+	config["max-store-down-time"] = "1h0m0s"
+	g.Expect(m.Sync(tc)).To(Succeed())
+	status = tc.Status.PD.ScheduleProfiles
+	g.Expect(status.ActiveProfiles).To(Equal([]string{"scale", "night"}))
+	g.Expect(status.Drifts).To(BeEmpty())
+	g.Expect(status.Original).To(Equal(map[string]string{"replica-schedule-limit": "5", "max-store-down-time": "1h0m0s"}))
+	g.Expect(config["replica-schedule-limit"]).To(Equal("8"))
+
+	// the items are reverted once no active profile sets them, and the status is removed with the profiles
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	tc.Spec.PD.ScheduleProfiles = nil
+	config["max-store-down-time"] = "2h"
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(config).To(Equal(map[string]string{"replica-schedule-limit": "5", "max-store-down-time": "1h0m0s"}))
+	g.Expect(tc.Status.PD.ScheduleProfiles).To(BeNil())
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+
+	// the unknown items are skipped and reported, the others are still set
+	tc.Spec.PD.ScheduleProfiles = []v1alpha1.PDScheduleProfile{
+		{Name: "always", Config: map[string]string{"replica-schedule-limit": "16", "unknown-limit": "1"}},
+	}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(config["replica-schedule-limit"]).To(Equal("16"))
+	status = tc.Status.PD.ScheduleProfiles
+	g.Expect(status.UnknownItems).To(Equal([]string{"unknown-limit"}))
+	g.Expect(status.Applied).To(Equal(map[string]string{"replica-schedule-limit": "16"}))
+	g.Expect(<-recorder.Events).To(ContainSubstring(PDScheduleUnknownItems))
+	g.Expect(<-recorder.Events).To(ContainSubstring(PDScheduleProfilesChanged))
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+}
+
+func TestConfigValueEqual(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(configValueEqual("64", "64.0")).To(BeTrue())
+	g.Expect(configValueEqual("30m", "30m0s")).To(BeTrue())
+	g.Expect(configValueEqual("true", "true")).To(BeTrue())
+	g.Expect(configValueEqual("64", "32")).To(BeFalse())
+	g.Expect(configValueEqual("64", "64m")).To(BeFalse())
+	g.Expect(configValueEqual("on", "off")).To(BeFalse())
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+const (
+	hugePagesVolumeName = "hugepages"
+	hugePagesMountPath  = "/dev/hugepages"
+
+	// PerformanceProfileSatisfiable is the reason of the condition when the performance profiles can be satisfied
+	PerformanceProfileSatisfiable = "PerformanceProfileSatisfiable"
+	// PerformanceProfileUnsatisfiable is the reason of the condition and the event when no schedulable
+	// node can allocate the resources required by a performance profile
+	PerformanceProfileUnsatisfiable = "PerformanceProfileUnsatisfiable"
+)
+
+// hugePagesResourceName returns the resource name of the hugepages with the page size, e.g. hugepages-2Mi
+func hugePagesResourceName(pageSize string) corev1.ResourceName {
+	return corev1.ResourceName(corev1.ResourceHugePagesPrefix + pageSize)
+}
+
+// dedicatedCPUs returns the number of CPUs dedicated to the container, which is
+// the CPU request rounded up to an integer
+func dedicatedCPUs(profile *v1alpha1.PerformanceProfile, req corev1.ResourceRequirements) (int64, bool) {
+	if profile == nil || !profile.DedicatedCPUs {
+		return 0, false
+	}
+	cpu, ok := req.Requests[corev1.ResourceCPU]
+	if !ok {
+		cpu, ok = req.Limits[corev1.ResourceCPU]
+	}
+	if !ok || cpu.IsZero() {
+		return 0, false
+	}
+	return (cpu.MilliValue() + 999) / 1000, true
+}
+
+// applyPerformanceProfile renders the performance profile to the main container of the component,
+// the hugepages volume is appended to vols.
+func applyPerformanceProfile(profile *v1alpha1.PerformanceProfile, container *corev1.Container, vols []corev1.Volume) []corev1.Volume {
+	if profile == nil {
+		return vols
+	}
+	res := &container.Resources
+	if res.Requests == nil {
+		res.Requests = corev1.ResourceList{}
+	}
+	if res.Limits == nil {
+		res.Limits = corev1.ResourceList{}
+	}
+
+	// the static CPU manager policy only allocates exclusive CPUs to the containers of
+	// Guaranteed Pods with integral CPU requests
+	if cpus, ok := dedicatedCPUs(profile, *res); ok {
+		quantity := *resource.NewQuantity(cpus, resource.DecimalSI)
+		res.Requests[corev1.ResourceCPU] = quantity
+		res.Limits[corev1.ResourceCPU] = quantity
+		if memory, ok := res.Requests[corev1.ResourceMemory]; ok {
+			res.Limits[corev1.ResourceMemory] = memory
+		} else if memory, ok := res.Limits[corev1.ResourceMemory]; ok {
+			res.Requests[corev1.ResourceMemory] = memory
+		}
+	}
+
+	if profile.HugePages != nil {
+		// hugepages can not be overcommitted, so the requests must be equal to the limits
+		name := hugePagesResourceName(profile.HugePages.PageSize)
+		res.Requests[name] = profile.HugePages.Size
+		res.Limits[name] = profile.HugePages.Size
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      hugePagesVolumeName,
+			MountPath: hugePagesMountPath,
+		})
+		vols = append(vols, corev1.Volume{
+			Name: hugePagesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMedium(string(corev1.StorageMediumHugePages) + "-" + profile.HugePages.PageSize),
+				},
+			},
+		})
+	}
+	return vols
+}
+
+// checkNodesForPerformanceProfile checks whether there is a node matching the scheduling constraints of
+// the component which is able to allocate the hugepages and dedicated CPUs required by the performance profile
+func checkNodesForPerformanceProfile(nodes []*corev1.Node, spec v1alpha1.ComponentAccessor, profile *v1alpha1.PerformanceProfile, req corev1.ResourceRequirements) error {
+	if profile == nil {
+		return nil
+	}
+	cpus, dedicated := dedicatedCPUs(profile, req)
+	if profile.HugePages == nil && !dedicated {
+		return nil
+	}
+
+	for _, node := range nodes {
+		if !nodeSchedulableFor(node, spec) {
+			continue
+		}
+		allocatable := node.Status.Allocatable
+		if profile.HugePages != nil {
+			hugePages, ok := allocatable[hugePagesResourceName(profile.HugePages.PageSize)]
+			if !ok || hugePages.Cmp(profile.HugePages.Size) < 0 {
+				continue
+			}
+		}
+		if dedicated {
+			cpu, ok := allocatable[corev1.ResourceCPU]
+			if !ok || cpu.MilliValue() < cpus*1000 {
+				continue
+			}
+		}
+		return nil
+	}
+
+	if profile.HugePages != nil {
+		return fmt.Errorf("no schedulable node can allocate %s of %s hugepages and %d dedicated CPUs",
+			profile.HugePages.Size.String(), profile.HugePages.PageSize, cpus)
+	}
+	return fmt.Errorf("no schedulable node can allocate %d dedicated CPUs", cpus)
+}
+
+// syncPerformanceProfileCondition sets the PerformanceProfileSatisfiable condition by whether the performance
+// profiles of TiKV and TiFlash can be satisfied by any schedulable node, a warning event is emitted when the
+// condition turns false. The Pods are still created, they're pending until such a node is available.
+func syncPerformanceProfileCondition(tc *v1alpha1.TidbCluster, nodes []*corev1.Node, recorder record.EventRecorder) {
+	var profiled bool
+	var msgs []string
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.PerformanceProfile != nil {
+		profiled = true
+		if err := checkNodesForPerformanceProfile(nodes, tc.BaseTiKVSpec(), tc.Spec.TiKV.PerformanceProfile, tc.Spec.TiKV.ResourceRequirements); err != nil {
+			msgs = append(msgs, fmt.Sprintf("tikv: %v", err))
+		}
+	}
+	if tc.Spec.TiFlash != nil && tc.Spec.TiFlash.PerformanceProfile != nil {
+		profiled = true
+		if err := checkNodesForPerformanceProfile(nodes, tc.BaseTiFlashSpec(), tc.Spec.TiFlash.PerformanceProfile, tc.Spec.TiFlash.ResourceRequirements); err != nil {
+			msgs = append(msgs, fmt.Sprintf("tiflash: %v", err))
+		}
+	}
+	if !profiled {
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterPerformanceProfileSatisfiable)
+		return
+	}
+
+	if len(msgs) == 0 {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterPerformanceProfileSatisfiable, corev1.ConditionTrue,
+			PerformanceProfileSatisfiable, "the performance profiles can be satisfied by the schedulable nodes")
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return
+	}
+	msg := strings.Join(msgs, "; ")
+	if current := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterPerformanceProfileSatisfiable); current == nil || current.Status != corev1.ConditionFalse {
+		klog.Warningf("tc %s/%s: %s", tc.Namespace, tc.Name, msg)
+		recorder.Event(tc, corev1.EventTypeWarning, PerformanceProfileUnsatisfiable, msg)
+	}
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterPerformanceProfileSatisfiable, corev1.ConditionFalse,
+		PerformanceProfileUnsatisfiable, msg)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestApplyPerformanceProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	profile := &v1alpha1.PerformanceProfile{
+		HugePages: &v1alpha1.HugePages{
+			PageSize: "2Mi",
+			Size:     resource.MustParse("1Gi"),
+		},
+		DedicatedCPUs: true,
+	}
+	container := &corev1.Container{
+		Name: "tikv",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3500m"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+
+	vols := applyPerformanceProfile(profile, container, nil)
+	g.Expect(container.Resources.Requests.Cpu().String()).To(Equal("4"))
+	g.Expect(container.Resources.Limits.Cpu().String()).To(Equal("4"))
+	g.Expect(container.Resources.Limits.Memory().String()).To(Equal("8Gi"))
+	hugePages := container.Resources.Limits[corev1.ResourceName("hugepages-2Mi")]
+	g.Expect(hugePages.String()).To(Equal("1Gi"))
+	g.Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: hugePagesVolumeName, MountPath: hugePagesMountPath}))
+	g.Expect(vols).To(HaveLen(1))
+	g.Expect(vols[0].EmptyDir.Medium).To(Equal(corev1.StorageMedium("HugePages-2Mi")))
+
+	// nothing is changed without the profile
+	container = &corev1.Container{Name: "tikv"}
+	vols = applyPerformanceProfile(nil, container, nil)
+	g.Expect(vols).To(BeEmpty())
+	g.Expect(container.Resources.Requests).To(BeEmpty())
+}
+
+func TestCheckNodesForPerformanceProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"dedicated": "tikv"}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "tikv", Effect: corev1.TaintEffectNoSchedule}}},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:                   resource.MustParse("8"),
+					corev1.ResourceName("hugepages-2Mi"): resource.MustParse("2Gi"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("32"),
+				},
+			},
+		},
+	}
+
+	tc := newTidbClusterForTiKV()
+	tc.Spec.TiKV.ResourceRequirements = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+		},
+	}
+	profile := &v1alpha1.PerformanceProfile{
+		HugePages:     &v1alpha1.HugePages{PageSize: "2Mi", Size: resource.MustParse("1Gi")},
+		DedicatedCPUs: true,
+	}
+	check := func() error {
+		return checkNodesForPerformanceProfile(nodes, tc.BaseTiKVSpec(), profile, tc.Spec.TiKV.ResourceRequirements)
+	}
+
+	// only node-1 has hugepages but its taint is not tolerated
+	g.Expect(check()).NotTo(Succeed())
+	tc.Spec.TiKV.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "tikv"}}
+	g.Expect(check()).To(Succeed())
+
+	// no node has 1Gi pages
+	profile.HugePages.PageSize = "1Gi"
+	g.Expect(check()).NotTo(Succeed())
+
+	// only node-2 can allocate 16 CPUs but it's not selected
+	profile.HugePages = nil
+	tc.Spec.TiKV.ResourceRequirements.Requests[corev1.ResourceCPU] = resource.MustParse("16")
+	g.Expect(check()).To(Succeed())
+	tc.Spec.TiKV.NodeSelector = map[string]string{"dedicated": "tikv"}
+	g.Expect(check()).NotTo(Succeed())
+}
+
+func TestSyncPerformanceProfileCondition(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	nodes := []*corev1.Node{{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")}},
+	}}
+	tc := newTidbClusterForTiKV()
+	tc.Spec.TiKV.ResourceRequirements = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("16")},
+	}
+
+	// no condition without performance profiles
+	syncPerformanceProfileCondition(tc, nodes, recorder)
+	g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterPerformanceProfileSatisfiable)).To(BeNil())
+
+	// the event is only emitted when the condition turns false
+	tc.Spec.TiKV.PerformanceProfile = &v1alpha1.PerformanceProfile{DedicatedCPUs: true}
+	syncPerformanceProfileCondition(tc, nodes, recorder)
+	syncPerformanceProfileCondition(tc, nodes, recorder)
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterPerformanceProfileSatisfiable)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(cond.Message).To(Equal("tikv: no schedulable node can allocate 16 dedicated CPUs"))
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring(PerformanceProfileUnsatisfiable))
+
+	tc.Spec.TiKV.ResourceRequirements.Requests[corev1.ResourceCPU] = resource.MustParse("8")
+	syncPerformanceProfileCondition(tc, nodes, recorder)
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterPerformanceProfileSatisfiable)
+	g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	tc.Spec.TiKV.PerformanceProfile = nil
+	syncPerformanceProfileCondition(tc, nodes, recorder)
+	g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterPerformanceProfileSatisfiable)).To(BeNil())
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podMonitorGVK is the kind of PodMonitor of prometheus-operator, which is not registered in
+// the scheme, so PodMonitors are handled as unstructured objects
+var podMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+
+// podMonitorComponent is a component whose metrics are scraped by a PodMonitor
+type podMonitorComponent struct {
+	memberType v1alpha1.MemberType
+	label      label.Label
+	ports      []int32
+	deployed   bool
+}
+
+type podMonitorManager struct {
+	deps *controller.Dependencies
+}
+
+// NewPodMonitorManager returns a manager which maintains the PodMonitors of prometheus-operator
+// for the components of the tidb cluster according to spec.podMonitor. The PodMonitor of a
+// component is deleted if spec.podMonitor is removed or the component is not deployed.
+// The existing PodMonitors are read from the informer, so only the changes hit the api-server.
+func NewPodMonitorManager(deps *controller.Dependencies) manager.Manager {
+	return &podMonitorManager{
+		deps: deps,
+	}
+}
+
+func (m *podMonitorManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if !m.deps.PodMonitorSupported {
+		if tc.Spec.PodMonitor != nil {
+			klog.V(4).Infof("the PodMonitor CRD is not installed, skip generating PodMonitors for cluster %s/%s", tc.GetNamespace(), tc.GetName())
+		}
+		return nil
+	}
+
+	for _, component := range podMonitorComponents(tc) {
+		pm := m.getPodMonitor(tc, component)
+		obj, err := m.deps.PodMonitorLister.ByNamespace(pm.GetNamespace()).Get(pm.GetName())
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get pod monitor %s/%s: %v", pm.GetNamespace(), pm.GetName(), err)
+		}
+		existing, _ := obj.(*unstructured.Unstructured)
+
+		if tc.Spec.PodMonitor == nil || !component.deployed {
+			if existing == nil {
+				continue
+			}
+			if err := m.deps.GenericControl.Delete(tc, pm); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete pod monitor %s/%s: %v", pm.GetNamespace(), pm.GetName(), err)
+			}
+			continue
+		}
+		if existing != nil && apiequality.Semantic.DeepEqual(existing.GetLabels(), pm.GetLabels()) &&
+			apiequality.Semantic.DeepEqual(existing.Object["spec"], pm.Object["spec"]) {
+			continue
+		}
+		_, err = m.deps.GenericControl.CreateOrUpdate(tc, pm, func(existing, desired client.Object) error {
+			existingPM := existing.(*unstructured.Unstructured)
+			desiredPM := desired.(*unstructured.Unstructured)
+
+			existingPM.SetLabels(desiredPM.GetLabels())
+			existingPM.Object["spec"] = desiredPM.Object["spec"]
+			return nil
+		}, true)
+		if err != nil {
+			return controller.RequeueErrorf("error creating or updating pod monitor %s/%s: %v", pm.GetNamespace(), pm.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// podMonitorComponents returns the components of the cluster and the ports of their metrics
+func podMonitorComponents(tc *v1alpha1.TidbCluster) []podMonitorComponent {
+	instanceLabel := func() label.Label {
+		return label.New().Instance(tc.GetInstanceName())
+	}
+	return []podMonitorComponent{
+		{memberType: v1alpha1.PDMemberType, label: instanceLabel().PD(), ports: []int32{2379}, deployed: tc.Spec.PD != nil},
+		{memberType: v1alpha1.TiKVMemberType, label: instanceLabel().TiKV(), ports: []int32{20180}, deployed: tc.Spec.TiKV != nil},
+		{memberType: v1alpha1.TiDBMemberType, label: instanceLabel().TiDB(), ports: []int32{10080}, deployed: tc.Spec.TiDB != nil},
+		{memberType: v1alpha1.TiFlashMemberType, label: instanceLabel().TiFlash(), ports: []int32{8234, 20292}, deployed: tc.Spec.TiFlash != nil},
+		{memberType: v1alpha1.TiCDCMemberType, label: instanceLabel().TiCDC(), ports: []int32{8301}, deployed: tc.Spec.TiCDC != nil},
+		{memberType: v1alpha1.PumpMemberType, label: instanceLabel().Pump(), ports: []int32{8250}, deployed: tc.Spec.Pump != nil},
+	}
+}
+
+func (m *podMonitorManager) getPodMonitor(tc *v1alpha1.TidbCluster, component podMonitorComponent) *unstructured.Unstructured {
+	var endpoints []interface{}
+	for _, port := range component.ports {
+		endpoint := map[string]interface{}{
+			"targetPort": int64(port),
+			"path":       "/metrics",
+		}
+		if tc.Spec.PodMonitor != nil && tc.Spec.PodMonitor.Interval != "" {
+			endpoint["interval"] = tc.Spec.PodMonitor.Interval
+		}
+		// the metrics are scraped with the client certificate of the cluster
+		if tc.IsTLSClusterEnabled() {
+			secretName := util.ClusterClientTLSSecretName(tc.GetName())
+			endpoint["scheme"] = "https"
+			endpoint["tlsConfig"] = map[string]interface{}{
+				"ca":        map[string]interface{}{"secret": map[string]interface{}{"name": secretName, "key": tlsSecretRootCAKey}},
+				"cert":      map[string]interface{}{"secret": map[string]interface{}{"name": secretName, "key": corev1.TLSCertKey}},
+				"keySecret": map[string]interface{}{"name": secretName, "key": corev1.TLSPrivateKeyKey},
+			}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	matchLabels := map[string]interface{}{}
+	for k, v := range component.label.Labels() {
+		matchLabels[k] = v
+	}
+	pm := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector":            map[string]interface{}{"matchLabels": matchLabels},
+				"namespaceSelector":   map[string]interface{}{"matchNames": []interface{}{tc.GetNamespace()}},
+				"podMetricsEndpoints": endpoints,
+			},
+		},
+	}
+	pm.SetGroupVersionKind(podMonitorGVK)
+	pm.SetName(fmt.Sprintf("%s-%s", tc.GetName(), component.memberType))
+	pm.SetNamespace(tc.GetNamespace())
+	labels := component.label.Labels()
+	if tc.Spec.PodMonitor != nil {
+		labels = util.CombineStringMap(labels, tc.Spec.PodMonitor.Labels)
+	}
+	pm.SetLabels(labels)
+	return pm
+}
+
+type FakePodMonitorManager struct {
+	err error
+}
+
+func NewFakePodMonitorManager() *FakePodMonitorManager {
+	return &FakePodMonitorManager{}
+}
+
+func (m *FakePodMonitorManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakePodMonitorManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
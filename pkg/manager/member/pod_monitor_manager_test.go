@@ -0,0 +1,112 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestPodMonitorManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	ctrl := fakeDeps.GenericControl.(*controller.FakeGenericControl)
+	m := NewPodMonitorManager(fakeDeps)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	fakeDeps.PodMonitorLister = cache.NewGenericLister(indexer, controller.PodMonitorGVR.GroupResource())
+
+	getPodMonitor := func(name string) (*unstructured.Unstructured, bool) {
+		pm := &unstructured.Unstructured{}
+		pm.SetGroupVersionKind(podMonitorGVK)
+		exist, err := ctrl.Exist(client.ObjectKey{Namespace: "default", Name: name}, pm)
+		g.Expect(err).NotTo(HaveOccurred())
+		return pm, exist
+	}
+	// sync syncs the cluster and then the informer
+	sync := func(tc *v1alpha1.TidbCluster) {
+		g.Expect(m.Sync(tc)).To(Succeed())
+		for _, component := range podMonitorComponents(tc) {
+			pm, exist := getPodMonitor("test-" + component.memberType.String())
+			if exist {
+				g.Expect(indexer.Update(pm)).To(Succeed())
+			} else {
+				pm.SetNamespace("default")
+				pm.SetName("test-" + component.memberType.String())
+				g.Expect(indexer.Delete(pm)).To(Succeed())
+			}
+		}
+	}
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.PodMonitor = &v1alpha1.PodMonitorSpec{
+		Labels:   map[string]string{"release": "kube-prometheus-stack"},
+		Interval: "30s",
+	}
+
+	// the PodMonitors are not generated if the CRD is not installed
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, exist := getPodMonitor("test-tidb")
+	g.Expect(exist).To(BeFalse())
+
+	fakeDeps.PodMonitorSupported = true
+	sync(tc)
+	pm, exist := getPodMonitor("test-tidb")
+	g.Expect(exist).To(BeTrue())
+	g.Expect(pm.GetLabels()).To(HaveKeyWithValue("release", "kube-prometheus-stack"))
+	g.Expect(pm.GetLabels()).To(HaveKeyWithValue(label.ComponentLabelKey, label.TiDBLabelVal))
+	matchLabels, _, err := unstructured.NestedStringMap(pm.Object, "spec", "selector", "matchLabels")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(matchLabels).To(Equal(label.New().Instance(tc.GetInstanceName()).TiDB().Labels()))
+	endpoints, _, err := unstructured.NestedSlice(pm.Object, "spec", "podMetricsEndpoints")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(endpoints).To(HaveLen(1))
+	g.Expect(endpoints[0]).To(HaveKeyWithValue("targetPort", BeNumerically("==", 10080)))
+	g.Expect(endpoints[0]).To(HaveKeyWithValue("interval", "30s"))
+	_, exist = getPodMonitor("test-tiflash")
+	g.Expect(exist).To(BeFalse())
+
+	// the unchanged PodMonitors are not updated
+	ctrl.SetCreateOrUpdateError(fmt.Errorf("unexpected update"), 0)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	ctrl.SetCreateOrUpdateError(nil, 0)
+
+	// the PodMonitors are updated
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	sync(tc)
+	pm, _ = getPodMonitor("test-tidb")
+	endpoints, _, _ = unstructured.NestedSlice(pm.Object, "spec", "podMetricsEndpoints")
+	g.Expect(endpoints[0]).To(HaveKeyWithValue("scheme", "https"))
+
+	// the PodMonitor is deleted if the component is removed
+	tc.Spec.TiDB = nil
+	sync(tc)
+	_, exist = getPodMonitor("test-tidb")
+	g.Expect(exist).To(BeFalse())
+
+	tc.Spec.PodMonitor = nil
+	sync(tc)
+	for _, component := range podMonitorComponents(tc) {
+		_, exist := getPodMonitor("test-" + component.memberType.String())
+		g.Expect(exist).To(BeFalse())
+	}
+}
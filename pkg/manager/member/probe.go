@@ -0,0 +1,100 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// probeHandlers are the handlers of a component for each type of the probes
+type probeHandlers struct {
+	// port is the client port connected by the tcp probe
+	port int
+	// statusPort and statusPath are requested by the http probe
+	statusPort int
+	statusPath string
+	// command is run by the command probe, empty if the component doesn't support it
+	command []string
+}
+
+func (h probeHandlers) handler(tp *string) corev1.Handler {
+	if tp != nil {
+		switch *tp {
+		case v1alpha1.HTTPProbeType:
+			return corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: h.statusPath,
+					Port: intstr.FromInt(h.statusPort),
+				},
+			}
+		case v1alpha1.CommandProbeType:
+			if len(h.command) > 0 {
+				return corev1.Handler{
+					Exec: &corev1.ExecAction{
+						Command: h.command,
+					},
+				}
+			}
+		}
+	}
+
+	// fall to default case v1alpha1.TCPProbeType
+	return corev1.Handler{
+		TCPSocket: &corev1.TCPSocketAction{
+			Port: intstr.FromInt(h.port),
+		},
+	}
+}
+
+func buildProbe(p *v1alpha1.Probe, h probeHandlers) *corev1.Probe {
+	probe := &corev1.Probe{
+		Handler: h.handler(p.Type),
+	}
+	if p.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *p.InitialDelaySeconds
+	}
+	if p.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *p.TimeoutSeconds
+	}
+	if p.PeriodSeconds != nil {
+		probe.PeriodSeconds = *p.PeriodSeconds
+	}
+	if p.SuccessThreshold != nil {
+		probe.SuccessThreshold = *p.SuccessThreshold
+	}
+	if p.FailureThreshold != nil {
+		probe.FailureThreshold = *p.FailureThreshold
+	}
+	return probe
+}
+
+// setProbes sets the probes in `spec.<component>.probes` to the main container of the component,
+// the probes that are not set are left as they are
+func setProbes(c *corev1.Container, probes *v1alpha1.Probes, h probeHandlers) {
+	if probes == nil {
+		return
+	}
+	if probes.Readiness != nil {
+		c.ReadinessProbe = buildProbe(probes.Readiness, h)
+	}
+	if probes.Liveness != nil {
+		c.LivenessProbe = buildProbe(probes.Liveness, h)
+	}
+	if probes.Startup != nil {
+		c.StartupProbe = buildProbe(probes.Startup, h)
+	}
+}
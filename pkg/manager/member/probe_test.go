@@ -0,0 +1,83 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestSetProbes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	handlers := probeHandlers{port: 4000, statusPort: 10080, statusPath: "/status", command: []string{"curl", "http://127.0.0.1:10080/status"}}
+	readiness := &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(4000)},
+		},
+		InitialDelaySeconds: 10,
+	}
+
+	c := &corev1.Container{ReadinessProbe: readiness}
+	setProbes(c, nil, handlers)
+	g.Expect(c.ReadinessProbe).To(Equal(readiness))
+	g.Expect(c.LivenessProbe).To(BeNil())
+	g.Expect(c.StartupProbe).To(BeNil())
+
+	setProbes(c, &v1alpha1.Probes{
+		Liveness: &v1alpha1.Probe{
+			Type:          pointer.StringPtr(v1alpha1.HTTPProbeType),
+			PeriodSeconds: pointer.Int32Ptr(5),
+		},
+		Startup: &v1alpha1.Probe{
+			Type:             pointer.StringPtr(v1alpha1.CommandProbeType),
+			TimeoutSeconds:   pointer.Int32Ptr(3),
+			FailureThreshold: pointer.Int32Ptr(60),
+		},
+	}, handlers)
+	g.Expect(c.ReadinessProbe).To(Equal(readiness))
+	g.Expect(c.LivenessProbe).To(Equal(&corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/status", Port: intstr.FromInt(10080)},
+		},
+		PeriodSeconds: 5,
+	}))
+	g.Expect(c.StartupProbe).To(Equal(&corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{Command: handlers.command},
+		},
+		TimeoutSeconds:   3,
+		FailureThreshold: 60,
+	}))
+
+	// fall back to tcp if the command probe is not supported
+	handlers.command = nil
+	setProbes(c, &v1alpha1.Probes{
+		Readiness: &v1alpha1.Probe{
+			Type:             pointer.StringPtr(v1alpha1.CommandProbeType),
+			SuccessThreshold: pointer.Int32Ptr(2),
+		},
+	}, handlers)
+	g.Expect(c.ReadinessProbe).To(Equal(&corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(4000)},
+		},
+		SuccessThreshold: 2,
+	}))
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// prometheusQueryQPS is the rate at which the queries of the clusters are started
+	prometheusQueryQPS = 1
+	// prometheusQueryBacklog is the number of the clusters whose queries wait to be started
+	prometheusQueryBacklog = 128
+)
+
+// prometheusQueryTask runs the queries of a cluster and returns the result
+type prometheusQueryTask struct {
+	key string
+	run func() interface{}
+}
+
+// prometheusQueryWorker runs the Prometheus queries of the clusters in a background goroutine
+// at a limited rate, so that a slow or unreachable Prometheus doesn't block the sync of the
+// clusters. The result of a cluster is picked up by a later sync of the cluster.
+type prometheusQueryWorker struct {
+	limiter *rate.Limiter
+	tasks   chan prometheusQueryTask
+
+	lock    sync.Mutex
+	pending map[string]bool
+	results map[string]interface{}
+}
+
+func newPrometheusQueryWorker() *prometheusQueryWorker {
+	return &prometheusQueryWorker{
+		limiter: rate.NewLimiter(rate.Limit(prometheusQueryQPS), 1),
+		tasks:   make(chan prometheusQueryTask, prometheusQueryBacklog),
+		pending: map[string]bool{},
+		results: map[string]interface{}{},
+	}
+}
+
+// startPrometheusQueryWorker returns a worker whose goroutine is started
+func startPrometheusQueryWorker() *prometheusQueryWorker {
+	w := newPrometheusQueryWorker()
+	go func() {
+		for w.processNextTask() {
+		}
+	}()
+	return w
+}
+
+// submit queues the queries of the cluster unless they are already queued or running.
+// The queries are dropped if the backlog is full, they are submitted again by the next sync.
+func (w *prometheusQueryWorker) submit(key string, run func() interface{}) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.pending[key] {
+		return
+	}
+	select {
+	case w.tasks <- prometheusQueryTask{key: key, run: run}:
+		w.pending[key] = true
+	default:
+		klog.Warningf("too many clusters waiting for the Prometheus queries, skip querying for %s", key)
+	}
+}
+
+// result returns the result of the finished queries of the cluster, it's returned only once
+func (w *prometheusQueryWorker) result(key string) (interface{}, bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	result, ok := w.results[key]
+	delete(w.results, key)
+	return result, ok
+}
+
+// forget drops the result of the cluster and the result of its running queries
+func (w *prometheusQueryWorker) forget(key string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	delete(w.pending, key)
+	delete(w.results, key)
+}
+
+// processNextTask waits for the rate limiter and runs the next queued queries,
+// it returns false if the worker is stopped
+func (w *prometheusQueryWorker) processNextTask() bool {
+	task, ok := <-w.tasks
+	if !ok {
+		return false
+	}
+	w.lock.Lock()
+	pending := w.pending[task.key]
+	w.lock.Unlock()
+	if !pending {
+		return true
+	}
+	if err := w.limiter.Wait(context.TODO()); err != nil {
+		klog.Warningf("failed to wait for the Prometheus query rate limiter: %v", err)
+	}
+	result := task.run()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	// the cluster is forgotten while its queries are running
+	if !w.pending[task.key] {
+		return true
+	}
+	delete(w.pending, task.key)
+	w.results[task.key] = result
+	return true
+}
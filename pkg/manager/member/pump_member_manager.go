@@ -425,6 +425,7 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 			},
 		},
 	}
+	setProbes(&containers[0], tc.Spec.Pump.Probes, probeHandlers{port: 8250, statusPort: 8250, statusPath: "/status"})
 
 	// Keep backward compatibility for pump created by helm
 	volumes := []corev1.Volume{
@@ -487,6 +488,8 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 	podSpec.ServiceAccountName = serviceAccountName
 	// TODO: change to set field in BuildPodSpec
 	podSpec.InitContainers = spec.InitContainers()
+	tc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.PumpMemberType.String())
+	applyEphemeralStorage(spec.EphemeralStorage(), &podSpec, v1alpha1.PumpMemberType.String())
 	// TODO: change to set field in BuildPodSpec
 	podSpec.DNSPolicy = spec.DnsPolicy()
 
@@ -551,8 +554,9 @@ func getPumpStartScript(tc *v1alpha1.TidbCluster) (string, error) {
 
 	return RenderPumpStartScript(&PumpStartScriptModel{
 		CommonModel: CommonModel{
-			AcrossK8s:     tc.AcrossK8s(),
-			ClusterDomain: tc.Spec.ClusterDomain,
+			AcrossK8s:              tc.AcrossK8s(),
+			BlockingPDVerification: tc.BlockingPDVerification(),
+			ClusterDomain:          tc.Spec.ClusterDomain,
 		},
 		Scheme:      scheme,
 		ClusterName: tc.Name,
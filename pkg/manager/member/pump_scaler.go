@@ -197,6 +197,14 @@ func (s *pumpScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 			return controller.RequeueErrorf("Pump %s/%s is still in cluster, state: %s", ns, podName, node.State)
 		} else if node.State == "offline" {
 			klog.Infof("Pump %s/%s becomes offline", ns, podName)
+			// the binlogs not consumed by drainers are lost after the pump is removed
+			consumed, err := client.IsPumpConsumed(context.TODO(), addr)
+			if err != nil {
+				return err
+			}
+			if !consumed {
+				return controller.RequeueErrorf("Pump %s/%s is offline, wait for drainers to consume its binlogs", ns, podName)
+			}
 			pvcs, err := util.ResolvePVCFromPod(pod, s.deps.PVCLister)
 			if err != nil {
 				return fmt.Errorf("pumpScaler.ScaleIn: failed to get pvcs for pod %s/%s in tc %s/%s, error: %s", ns, pod.Name, ns, tcName, err)
@@ -219,7 +227,7 @@ func (s *pumpScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 	// 2. Pump pod is not ready, such as in pending state.
 	//    In this situation we should delete this Pump pod immediately to avoid blocking the subsequent operations.
 	if !podutil.IsPodReady(pod) {
-		safeTimeDeadline := pod.CreationTimestamp.Add(5 * s.deps.CLIConfig.ResyncDuration)
+		safeTimeDeadline := pod.CreationTimestamp.Add(5 * s.deps.CLIConfig.GetResyncDuration())
 		if time.Now().Before(safeTimeDeadline) {
 			// Wait for 5 resync periods to ensure that the following situation does not occur:
 			//
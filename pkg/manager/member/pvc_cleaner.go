@@ -16,6 +16,8 @@ package member
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -64,7 +66,123 @@ func (c *realPVCCleaner) Clean(meta metav1.Object) (map[string]string, error) {
 	if skipReason, err := c.cleanScheduleLock(meta); err != nil {
 		return skipReason, err
 	}
-	return c.reclaimPV(meta)
+	skipReason, err := c.reclaimPV(meta)
+	if err != nil {
+		return skipReason, err
+	}
+	return skipReason, c.deleteDeferDeletingPVCs(meta)
+}
+
+// deleteDeferDeletingPVCs deletes the defer deleting PVCs according to the pvcDeletePolicy of
+// their components, and records the PVCs pending deletion in the status of TidbCluster.
+func (c *realPVCCleaner) deleteDeferDeletingPVCs(meta metav1.Object) error {
+	cluster, ok := meta.(v1alpha1.Cluster)
+	if !ok {
+		return nil
+	}
+	ns := meta.GetNamespace()
+	metaName := meta.GetName()
+
+	pvcs, err := c.listAllPVCs(meta)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var pending []v1alpha1.DeferDeletingPVC
+	for _, pvc := range pvcs {
+		deferDeleting := pvc.Annotations[label.AnnPVCDeferDeleting]
+		if deferDeleting == "" || pvc.DeletionTimestamp != nil {
+			continue
+		}
+		memberType := v1alpha1.MemberType(pvc.Labels[label.ComponentLabelKey])
+		spec := cluster.ComponentSpec(memberType)
+		if spec == nil {
+			continue
+		}
+		deferDeletingTime, err := time.Parse(time.RFC3339, deferDeleting)
+		if err != nil {
+			klog.Warningf("cluster %s/%s pvc %s has invalid annotation %s: %q, skip it", ns, metaName, pvc.Name, label.AnnPVCDeferDeleting, deferDeleting)
+			continue
+		}
+
+		item := v1alpha1.DeferDeletingPVC{
+			Name:              pvc.Name,
+			Component:         memberType,
+			DeferDeletingTime: metav1.NewTime(deferDeletingTime),
+		}
+		switch spec.PVCDeletePolicy() {
+		case v1alpha1.PVCDeletePolicyDelete:
+			item.DeleteTime = &item.DeferDeletingTime
+		case v1alpha1.PVCDeletePolicyDeferWithTTL:
+			deleteTime := metav1.NewTime(deferDeletingTime.Add(spec.PVCDeleteTTL()))
+			item.DeleteTime = &deleteTime
+		}
+		if item.DeleteTime == nil || now.Before(item.DeleteTime.Time) {
+			pending = append(pending, item)
+			continue
+		}
+
+		deleted, err := c.deletePVCIfPodNotExist(meta, pvc)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			pending = append(pending, item)
+		}
+	}
+
+	if tc, ok := meta.(*v1alpha1.TidbCluster); ok {
+		sort.Slice(pending, func(i, j int) bool {
+			return pending[i].Name < pending[j].Name
+		})
+		tc.Status.DeferDeletingPVCs = pending
+	}
+	return nil
+}
+
+// deletePVCIfPodNotExist deletes the PVC if its pod doesn't exist, it returns whether the PVC is deleted.
+func (c *realPVCCleaner) deletePVCIfPodNotExist(meta metav1.Object, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	ns := meta.GetNamespace()
+	metaName := meta.GetName()
+	pvcName := pvc.GetName()
+
+	podName, exist := pvc.Annotations[label.AnnPodNameKey]
+	if !exist {
+		return false, nil
+	}
+	_, err := c.deps.PodLister.Pods(ns).Get(podName)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, fmt.Errorf("cluster %s/%s get pvc %s pod %s from local cache failed, err: %v", ns, metaName, pvcName, podName, err)
+	}
+	// if pod not found in cache, re-check from apiserver directly to make sure the pod really not exist
+	_, err = c.deps.KubeClientset.CoreV1().Pods(ns).Get(context.TODO(), podName, metav1.GetOptions{})
+	if err == nil {
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, fmt.Errorf("cluster %s/%s get pvc %s pod %s from apiserver failed, err: %v", ns, metaName, pvcName, podName, err)
+	}
+
+	apiPVC, err := c.deps.KubeClientset.CoreV1().PersistentVolumeClaims(ns).Get(context.TODO(), pvcName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("cluster %s/%s get pvc %s failed, err: %v", ns, metaName, pvcName, err)
+	}
+	if apiPVC.UID != pvc.UID || apiPVC.ResourceVersion != pvc.ResourceVersion {
+		return false, nil
+	}
+
+	if err := c.deps.PVCControl.DeletePVC(meta.(runtime.Object), pvc); err != nil {
+		return false, fmt.Errorf("cluster %s/%s delete pvc %s failed, err: %v", ns, metaName, pvcName, err)
+	}
+	klog.Infof("cluster %s/%s delete defer deleting pvc %s of pod %s", ns, metaName, pvcName, podName)
+	return true, nil
 }
 
 // reclaimPV reclaims PV used by tidb cluster if necessary.
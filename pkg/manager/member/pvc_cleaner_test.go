@@ -22,6 +22,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -1169,6 +1170,114 @@ func TestPVCCleanerCleanScheduleLock(t *testing.T) {
 	}
 }
 
+func TestPVCCleanerDeleteDeferDeletingPVCs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name           string
+		policy         v1alpha1.PVCDeletePolicy
+		ttl            time.Duration
+		deferDeleting  time.Duration
+		podExist       bool
+		expectDeleted  bool
+		expectDeleteIn *time.Duration
+	}
+	hour := time.Hour
+	zero := time.Duration(0)
+
+	testFn := func(test *testcase) {
+		tc := newTidbClusterForPD()
+		tc.Spec.PD.PVCDeletePolicy = test.policy
+		if test.ttl != 0 {
+			tc.Spec.PD.PVCDeleteTTL = &metav1.Duration{Duration: test.ttl}
+		}
+		pcc, fakeCli, podIndexer, pvcIndexer, _, _, _ := newFakePVCCleaner()
+
+		deferDeletingTime := time.Now().Add(-test.deferDeleting).Truncate(time.Second)
+		pvc := &corev1.PersistentVolumeClaim{
+			TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       metav1.NamespaceDefault,
+				Name:            "pd-test-pd-2",
+				Labels:          label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+				ResourceVersion: "1",
+				Annotations: map[string]string{
+					label.AnnPodNameKey:       "test-pd-2",
+					label.AnnPVCDeferDeleting: deferDeletingTime.Format(time.RFC3339),
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		}
+		pvcIndexer.Add(pvc)
+		fakeCli.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+		if test.podExist {
+			pod := &corev1.Pod{
+				TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pd-2", Namespace: metav1.NamespaceDefault},
+			}
+			podIndexer.Add(pod)
+		}
+
+		err := pcc.deleteDeferDeletingPVCs(tc)
+		g.Expect(err).NotTo(HaveOccurred(), test.name)
+		_, err = pcc.deps.PVCLister.PersistentVolumeClaims(metav1.NamespaceDefault).Get(pvc.Name)
+		if test.expectDeleted {
+			g.Expect(errors.IsNotFound(err)).To(BeTrue(), test.name)
+			g.Expect(tc.Status.DeferDeletingPVCs).To(BeEmpty(), test.name)
+			return
+		}
+		g.Expect(err).NotTo(HaveOccurred(), test.name)
+		g.Expect(tc.Status.DeferDeletingPVCs).To(HaveLen(1), test.name)
+		pending := tc.Status.DeferDeletingPVCs[0]
+		g.Expect(pending.Name).To(Equal(pvc.Name), test.name)
+		g.Expect(pending.Component).To(Equal(v1alpha1.PDMemberType), test.name)
+		g.Expect(pending.DeferDeletingTime.Time.Equal(deferDeletingTime)).To(BeTrue(), test.name)
+		if test.expectDeleteIn == nil {
+			g.Expect(pending.DeleteTime).To(BeNil(), test.name)
+		} else {
+			g.Expect(pending.DeleteTime.Time.Equal(deferDeletingTime.Add(*test.expectDeleteIn))).To(BeTrue(), test.name)
+		}
+	}
+
+	tests := []testcase{
+		{
+			name:          "retain by default",
+			deferDeleting: 48 * time.Hour,
+			expectDeleted: false,
+		},
+		{
+			name:          "delete after the pod is removed",
+			policy:        v1alpha1.PVCDeletePolicyDelete,
+			expectDeleted: true,
+		},
+		{
+			name:           "delete but the pod exists",
+			policy:         v1alpha1.PVCDeletePolicyDelete,
+			podExist:       true,
+			expectDeleted:  false,
+			expectDeleteIn: &zero,
+		},
+		{
+			name:           "defer with ttl not expired",
+			policy:         v1alpha1.PVCDeletePolicyDeferWithTTL,
+			ttl:            time.Hour,
+			deferDeleting:  10 * time.Minute,
+			expectDeleted:  false,
+			expectDeleteIn: &hour,
+		},
+		{
+			name:          "defer with ttl expired",
+			policy:        v1alpha1.PVCDeletePolicyDeferWithTTL,
+			ttl:           time.Hour,
+			deferDeleting: 2 * time.Hour,
+			expectDeleted: true,
+		},
+	}
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
+
 func newFakePVCCleaner() (*realPVCCleaner, *kubefake.Clientset, cache.Indexer, cache.Indexer, *controller.FakePVCControl, cache.Indexer, *controller.FakePVControl) {
 	fakeDeps := controller.NewFakeDependencies()
 	rpc := &realPVCCleaner{deps: fakeDeps}
@@ -0,0 +1,371 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// StorageClassMigrationBlocked is the reason of the event emitted when a store can't be migrated
+	// as there are not more stores than max-replicas
+	StorageClassMigrationBlocked = "StorageClassMigrationBlocked"
+	// StorageClassMigrationStarted is the reason of the event emitted when a store is deleted to migrate its pod
+	StorageClassMigrationStarted = "StorageClassMigrationStarted"
+	// StorageClassMigrationCompleted is the reason of the event emitted when all pods of a component are migrated
+	StorageClassMigrationCompleted = "StorageClassMigrationCompleted"
+
+	// defaultMaxReplicas is the default max-replicas of PD if it's not found in the config
+	defaultMaxReplicas = 3
+)
+
+// PVCMigratorInterface migrates the PVCs of TiKV and TiFlash to the StorageClasses in spec if the
+// tidb cluster is annotated with label.AnnStorageClassMigration. See
+// docs/design-proposals/2022-05-20-storage-class-migration.md for more details.
+//
+// The StorageClass of a PVC is immutable, so the data is re-replicated by PD one pod at a time,
+// in the descending order of the ordinals:
+//
+//   - the StatefulSet is deleted with the orphan propagation policy if its volumeClaimTemplates
+//     use other StorageClasses, the member manager recreates it with the new templates
+//   - the store of the pod is deleted from PD, which moves its regions to the other stores
+//   - after the store becomes Tombstone, the pod and its PVCs are deleted, and the StatefulSet
+//     recreates them with the new StorageClasses
+//   - the pod is migrated when the new store is Up
+//
+// A pod is only started to migrate if the component is in Normal phase and there are more Up
+// stores than max-replicas, so that the replicas of the deleted store can be moved.
+type PVCMigratorInterface interface {
+	Sync(*v1alpha1.TidbCluster) error
+}
+
+type pvcMigrator struct {
+	deps *controller.Dependencies
+}
+
+// NewPVCMigrator returns a PVCMigratorInterface
+func NewPVCMigrator(deps *controller.Dependencies) PVCMigratorInterface {
+	return &pvcMigrator{
+		deps: deps,
+	}
+}
+
+func (m *pvcMigrator) Sync(tc *v1alpha1.TidbCluster) error {
+	// the migrating pod is still migrated after the annotation is removed, as its store is deleted
+	enabled := tc.Annotations[label.AnnStorageClassMigration] == label.AnnStorageClassMigrationVal
+
+	errs := []error{}
+	if tc.Spec.TiKV != nil {
+		if err := m.migrate(tc, v1alpha1.TiKVMemberType, enabled); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if tc.Spec.TiFlash != nil {
+		if err := m.migrate(tc, v1alpha1.TiFlashMemberType, enabled); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errutil.NewAggregate(errs)
+}
+
+// storeComponent abstracts the status of TiKV and TiFlash used by the migration
+type storeComponent struct {
+	memberType v1alpha1.MemberType
+	phase      v1alpha1.MemberPhase
+	stores     map[string]v1alpha1.TiKVStore
+	migration  **v1alpha1.StorageClassMigrationStatus
+	// desired is the desired StorageClasses of the volumes, the volumes without StorageClass
+	// in spec are not migrated
+	desired map[v1alpha1.StorageVolumeName]string
+}
+
+func newStoreComponent(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) *storeComponent {
+	c := &storeComponent{
+		memberType: memberType,
+		desired:    map[v1alpha1.StorageVolumeName]string{},
+	}
+	switch memberType {
+	case v1alpha1.TiKVMemberType:
+		c.phase = tc.Status.TiKV.Phase
+		c.stores = tc.Status.TiKV.Stores
+		c.migration = &tc.Status.TiKV.StorageClassMigration
+		if sc := tc.Spec.TiKV.StorageClassName; sc != nil && *sc != "" {
+			c.desired[v1alpha1.GetStorageVolumeName("", memberType)] = *sc
+		}
+		for _, sv := range tc.Spec.TiKV.StorageVolumes {
+			sc := sv.StorageClassName
+			if sc == nil || *sc == "" {
+				sc = tc.Spec.TiKV.StorageClassName
+			}
+			if sc != nil && *sc != "" {
+				c.desired[v1alpha1.GetStorageVolumeName(sv.Name, memberType)] = *sc
+			}
+		}
+	case v1alpha1.TiFlashMemberType:
+		c.phase = tc.Status.TiFlash.Phase
+		c.stores = tc.Status.TiFlash.Stores
+		c.migration = &tc.Status.TiFlash.StorageClassMigration
+		for i, claim := range tc.Spec.TiFlash.StorageClaims {
+			if sc := claim.StorageClassName; sc != nil && *sc != "" {
+				c.desired[v1alpha1.GetStorageVolumeNameForTiFlash(i)] = *sc
+			}
+		}
+	}
+	return c
+}
+
+// pvcMigrated returns whether the StorageClass of the PVC is the desired one
+func (c *storeComponent) pvcMigrated(name v1alpha1.StorageVolumeName, pvc *corev1.PersistentVolumeClaim) bool {
+	desired, ok := c.desired[name]
+	if !ok {
+		return true
+	}
+	return pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName == desired
+}
+
+// storeOf returns the store of the pod in status
+func (c *storeComponent) storeOf(podName string) (v1alpha1.TiKVStore, bool) {
+	for _, store := range c.stores {
+		if store.PodName == podName {
+			return store, true
+		}
+	}
+	return v1alpha1.TiKVStore{}, false
+}
+
+func (m *pvcMigrator) migrate(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, enabled bool) error {
+	ns := tc.GetNamespace()
+	comp := newStoreComponent(tc, memberType)
+	migrating := *comp.migration != nil && (*comp.migration).MigratingPod != ""
+	if len(comp.desired) == 0 || (!enabled && !migrating) {
+		*comp.migration = nil
+		return nil
+	}
+	id := fmt.Sprintf("%s/%s:%s", ns, tc.GetName(), memberType)
+
+	sts, err := m.deps.StatefulSetLister.StatefulSets(ns).Get(controller.MemberName(tc.GetName(), memberType))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get sts for %s: %v", id, err)
+	}
+	for _, vct := range sts.Spec.VolumeClaimTemplates {
+		if comp.pvcMigrated(v1alpha1.StorageVolumeName(vct.Name), &vct) {
+			continue
+		}
+		orphan := metav1.DeletePropagationOrphan
+		err := m.deps.KubeClientset.AppsV1().StatefulSets(ns).Delete(context.TODO(), sts.Name, metav1.DeleteOptions{PropagationPolicy: &orphan})
+		if err != nil {
+			return fmt.Errorf("delete sts %s/%s for %s failed: %v", ns, sts.Name, id, err)
+		}
+		// the member manager recreates the sts with the new volumeClaimTemplates in the next sync
+		return controller.RequeueErrorf("recreate sts %s/%s for the storage class migration of %s", ns, sts.Name, id)
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).Component(memberType.String()).Selector()
+	if err != nil {
+		return err
+	}
+	podVolumes, err := (&pvcResizer{deps: m.deps}).collectAcutalStatus(ns, selector)
+	if err != nil {
+		return err
+	}
+	migrated := func(podVolume *podVolumeContext) bool {
+		for _, vol := range podVolume.volumes {
+			if !comp.pvcMigrated(vol.name, vol.pvc) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if *comp.migration == nil {
+		*comp.migration = &v1alpha1.StorageClassMigrationStatus{}
+	}
+	status := *comp.migration
+
+	if status.MigratingPod != "" {
+		var podVolume *podVolumeContext
+		for _, pv := range podVolumes {
+			if pv.pod.Name == status.MigratingPod {
+				podVolume = pv
+			}
+		}
+		switch status.Phase {
+		case v1alpha1.StorageClassMigrationOffline:
+			if store, ok := comp.stores[status.StoreID]; ok {
+				return controller.RequeueErrorf("%s: store %s of pod %s is %s, waiting for it to become Tombstone",
+					id, status.StoreID, status.MigratingPod, store.State)
+			}
+			if err := m.replacePod(tc, comp, status.MigratingPod); err != nil {
+				return err
+			}
+			status.Phase = v1alpha1.StorageClassMigrationReplacing
+			return controller.RequeueErrorf("%s: pod %s and its PVCs are deleted", id, status.MigratingPod)
+		case v1alpha1.StorageClassMigrationReplacing:
+			store, ok := comp.storeOf(status.MigratingPod)
+			if podVolume == nil || !migrated(podVolume) || !ok || store.ID == status.StoreID || store.State != v1alpha1.TiKVStateUp {
+				return controller.RequeueErrorf("%s: waiting for the new store of pod %s to be Up", id, status.MigratingPod)
+			}
+			klog.Infof("%s: pod %s is migrated to the desired storage classes", id, status.MigratingPod)
+			status.MigratedPods = append(status.MigratedPods, status.MigratingPod)
+			status.MigratingPod = ""
+			status.StoreID = ""
+			status.Phase = ""
+		}
+	}
+
+	if !enabled {
+		klog.Infof("%s: storage class migration is disabled", id)
+		*comp.migration = nil
+		return nil
+	}
+
+	var next *podVolumeContext
+	var nextOrdinal int32 = -1
+	for _, podVolume := range podVolumes {
+		if migrated(podVolume) {
+			continue
+		}
+		ordinal, err := util.GetOrdinalFromPodName(podVolume.pod.Name)
+		if err != nil {
+			return err
+		}
+		if ordinal > nextOrdinal {
+			next, nextOrdinal = podVolume, ordinal
+		}
+	}
+	if next == nil {
+		if len(status.MigratedPods) > 0 {
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StorageClassMigrationCompleted,
+				"%d pods of %s are migrated to the desired storage classes", len(status.MigratedPods), memberType)
+		}
+		*comp.migration = nil
+		return nil
+	}
+	if comp.phase != v1alpha1.NormalPhase {
+		klog.Infof("%s: phase is %s, wait to migrate pod %s", id, comp.phase, next.pod.Name)
+		return nil
+	}
+	return m.offlineStore(tc, comp, next.pod.Name)
+}
+
+// offlineStore deletes the store of the pod from PD if there are more Up stores than max-replicas
+func (m *pvcMigrator) offlineStore(tc *v1alpha1.TidbCluster, comp *storeComponent, podName string) error {
+	id := fmt.Sprintf("%s/%s:%s", tc.GetNamespace(), tc.GetName(), comp.memberType)
+	store, ok := comp.storeOf(podName)
+	if !ok || store.State != v1alpha1.TiKVStateUp {
+		return controller.RequeueErrorf("%s: store of pod %s is not Up, wait to migrate it", id, podName)
+	}
+	storeID, err := strconv.ParseUint(store.ID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	upNumber := 0
+	for _, s := range comp.stores {
+		if s.State == v1alpha1.TiKVStateUp {
+			upNumber++
+		}
+	}
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	config, err := pdClient.GetConfig()
+	if err != nil {
+		return err
+	}
+	maxReplicas := defaultMaxReplicas
+	if config.Replication != nil && config.Replication.MaxReplicas != nil {
+		maxReplicas = int(*config.Replication.MaxReplicas)
+	}
+	if upNumber <= maxReplicas {
+		msg := fmt.Sprintf("%d stores of %s are Up, not more than max-replicas %d, scale out to migrate pod %s",
+			upNumber, comp.memberType, maxReplicas, podName)
+		klog.Warningf("%s: %s", id, msg)
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, StorageClassMigrationBlocked, msg)
+		return nil
+	}
+
+	if err := pdClient.DeleteStore(storeID); err != nil {
+		return fmt.Errorf("%s: failed to delete store %d of pod %s: %v", id, storeID, podName, err)
+	}
+	status := *comp.migration
+	status.MigratingPod = podName
+	status.StoreID = store.ID
+	status.Phase = v1alpha1.StorageClassMigrationOffline
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StorageClassMigrationStarted,
+		"store %d of pod %s is deleted to migrate its PVCs to the desired storage classes", storeID, podName)
+	return controller.RequeueErrorf("%s: store %d of pod %s is deleted", id, storeID, podName)
+}
+
+// replacePod deletes the pod and its PVCs whose StorageClasses differ from the desired ones,
+// the StatefulSet recreates them with the new volumeClaimTemplates
+func (m *pvcMigrator) replacePod(tc *v1alpha1.TidbCluster, comp *storeComponent, podName string) error {
+	ns := tc.GetNamespace()
+	// The order of old PVC deleting and the new Pod creating is not guaranteed by Kubernetes,
+	// the new Pod pending on the deleted PVCs is deleted by OrphanPodsCleaner, see
+	// tryToDeleteAFailureMember in pd_failover.go for details.
+	pod, err := m.deps.PodLister.Pods(ns).Get(podName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get pod %s/%s: %v", ns, podName, err)
+	}
+	if err == nil && pod.DeletionTimestamp == nil {
+		if err := m.deps.PodControl.DeletePod(tc, pod); err != nil {
+			return err
+		}
+	}
+	for name := range comp.desired {
+		// the PVCs are named after the volumeClaimTemplates and the pod by the StatefulSet
+		pvcName := fmt.Sprintf("%s-%s", name, podName)
+		pvc, err := m.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get pvc %s/%s: %v", ns, pvcName, err)
+		}
+		if comp.pvcMigrated(name, pvc) || pvc.DeletionTimestamp != nil {
+			continue
+		}
+		if err := m.deps.PVCControl.DeletePVC(tc, pvc); err != nil {
+			return err
+		}
+	}
+	klog.Infof("tc %s/%s: pod %s and its PVCs are deleted to migrate the storage classes", ns, tc.GetName(), podName)
+	return nil
+}
+
+type fakePVCMigrator struct{}
+
+func (f *fakePVCMigrator) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
+
+// NewFakePVCMigrator returns a fake PVCMigratorInterface
+func NewFakePVCMigrator() PVCMigratorInterface {
+	return &fakePVCMigrator{}
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func newPVCMigratorTest(tc *v1alpha1.TidbCluster, templateSC string, replicas int) (*pvcMigrator, *controller.Dependencies, *pdapi.FakePDClient) {
+	fakeDeps := controller.NewFakeDependencies()
+	setName := controller.TiKVMemberName(tc.GetName())
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.GetNamespace(), Name: setName},
+		Spec: appsv1.StatefulSetSpec{
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "tikv"},
+					Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.StringPtr(templateSC)},
+				},
+			},
+		},
+	}
+	fakeDeps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(sts)
+	fakeDeps.KubeClientset.AppsV1().StatefulSets(sts.Namespace).Create(context.TODO(), sts, metav1.CreateOptions{})
+
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{}
+	for i := 0; i < replicas; i++ {
+		podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), int32(i))
+		addPVCMigratorPod(fakeDeps, tc, podName, "old")
+		id := fmt.Sprintf("%d", i+1)
+		tc.Status.TiKV.Stores[id] = v1alpha1.TiKVStore{ID: id, PodName: podName, State: v1alpha1.TiKVStateUp}
+	}
+
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.PDConfigFromAPI{Replication: &pdapi.PDReplicationConfig{MaxReplicas: func() *uint64 { i := uint64(3); return &i }()}}, nil
+	})
+	return &pvcMigrator{deps: fakeDeps}, fakeDeps, pdClient
+}
+
+func addPVCMigratorPod(fakeDeps *controller.Dependencies, tc *v1alpha1.TidbCluster, podName, sc string) {
+	labels := label.New().Instance(tc.GetInstanceName()).TiKV().Labels()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.GetNamespace(), Name: podName, Labels: labels},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{
+					Name: "tikv",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "tikv-" + podName},
+					},
+				},
+			},
+		},
+	}
+	pvc := newMockPVC("tikv-"+podName, sc, "100Gi", "100Gi")
+	pvc.Labels = labels
+	fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+	fakeDeps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)
+}
+
+func TestPVCMigratorSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Annotations = map[string]string{label.AnnStorageClassMigration: label.AnnStorageClassMigrationVal}
+	tc.Spec.TiKV.StorageClassName = pointer.StringPtr("new")
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	migrator, fakeDeps, pdClient := newPVCMigratorTest(tc, "new", 4)
+
+	var deleted []uint64
+	pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		deleted = append(deleted, action.ID)
+		return nil, nil
+	})
+	podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), 3)
+
+	// the store of the pod with the highest ordinal is deleted
+	err := migrator.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deleted).To(Equal([]uint64{4}))
+	status := tc.Status.TiKV.StorageClassMigration
+	g.Expect(status).NotTo(BeNil())
+	g.Expect(status.MigratingPod).To(Equal(podName))
+	g.Expect(status.StoreID).To(Equal("4"))
+	g.Expect(status.Phase).To(Equal(v1alpha1.StorageClassMigrationOffline))
+
+	// wait for the store to become tombstone
+	err = migrator.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get(podName)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the pod and its PVC are deleted after the store becomes tombstone
+	delete(tc.Status.TiKV.Stores, "4")
+	err = migrator.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(status.Phase).To(Equal(v1alpha1.StorageClassMigrationReplacing))
+	_, err = fakeDeps.PodLister.Pods(tc.Namespace).Get(podName)
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+	_, err = fakeDeps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("tikv-" + podName)
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	// wait for the new store to be up
+	err = migrator.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deleted).To(Equal([]uint64{4}))
+
+	// the next pod is migrated after the new store is up
+	addPVCMigratorPod(fakeDeps, tc, podName, "new")
+	tc.Status.TiKV.Stores["5"] = v1alpha1.TiKVStore{ID: "5", PodName: podName, State: v1alpha1.TiKVStateUp}
+	err = migrator.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deleted).To(Equal([]uint64{4, 3}))
+	g.Expect(status.MigratedPods).To(Equal([]string{podName}))
+	g.Expect(status.MigratingPod).To(Equal(ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), 2)))
+	g.Expect(status.Phase).To(Equal(v1alpha1.StorageClassMigrationOffline))
+}
+
+func TestPVCMigratorBlocked(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Annotations = map[string]string{label.AnnStorageClassMigration: label.AnnStorageClassMigrationVal}
+	tc.Spec.TiKV.StorageClassName = pointer.StringPtr("new")
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	migrator, _, pdClient := newPVCMigratorTest(tc, "new", 3)
+	pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		t.Fatalf("store %d is deleted while there are not more stores than max-replicas", action.ID)
+		return nil, nil
+	})
+
+	g.Expect(migrator.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.StorageClassMigration.MigratingPod).To(BeEmpty())
+
+	// the migration is not started without the annotation
+	tc.Annotations = nil
+	g.Expect(migrator.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.StorageClassMigration).To(BeNil())
+}
+
+func TestPVCMigratorRecreateStatefulSet(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Annotations = map[string]string{label.AnnStorageClassMigration: label.AnnStorageClassMigrationVal}
+	tc.Spec.TiKV.StorageClassName = pointer.StringPtr("new")
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	migrator, fakeDeps, _ := newPVCMigratorTest(tc, "old", 4)
+
+	err := migrator.Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	_, err = fakeDeps.KubeClientset.AppsV1().StatefulSets(tc.Namespace).Get(context.TODO(), controller.TiKVMemberName(tc.GetName()), metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+	g.Expect(tc.Status.TiKV.StorageClassMigration).To(BeNil())
+}
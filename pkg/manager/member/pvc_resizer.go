@@ -816,16 +816,14 @@ func updateResizeAnnForTiKVPod(client kubernetes.Interface, need bool, pod *core
 			pod.Annotations = map[string]string{}
 		}
 		pod.Annotations[v1alpha1.EvictLeaderAnnKeyForResize] = v1alpha1.EvictLeaderValueNone
-		_, err := client.CoreV1().Pods(pod.Namespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
-		if err != nil {
+		if err := patchPodMeta(client, pod); err != nil {
 			return false, fmt.Errorf("add leader eviction annotation to pod %s/%s failed: %s", pod.Namespace, pod.Name, err)
 		}
 		return true, nil
 	}
 	if !need && exist {
 		delete(pod.Annotations, v1alpha1.EvictLeaderAnnKeyForResize)
-		_, err := client.CoreV1().Pods(pod.Namespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
-		if err != nil {
+		if err := patchPodMeta(client, pod); err != nil {
 			return false, fmt.Errorf("remove leader eviction annotation from pod %s/%s failed: %s", pod.Namespace, pod.Name, err)
 		}
 		return true, nil
@@ -834,6 +832,16 @@ func updateResizeAnnForTiKVPod(client kubernetes.Interface, need bool, pod *core
 	return false, nil
 }
 
+// patchPodMeta writes the labels and annotations of the pod read from the stripped cache
+func patchPodMeta(client kubernetes.Interface, pod *corev1.Pod) error {
+	data, err := controller.MetaPatch(pod)
+	if err != nil {
+		return err
+	}
+	_, err = client.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.JSONPatchType, data, metav1.PatchOptions{})
+	return err
+}
+
 type fakePVCResizer struct {
 }
 
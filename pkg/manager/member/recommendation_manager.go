@@ -0,0 +1,197 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+type recommendationManager struct {
+	deps *controller.Dependencies
+	// queryFn runs an instant query on the Prometheus, it returns false if the result is empty
+	queryFn func(prometheusURL, query string) (float64, bool, error)
+	worker  *prometheusQueryWorker
+}
+
+// NewRecommendationManager returns a manager which computes the recommended replicas of TiDB
+// and the storage headroom of TiKV in status.recommendations according to spec.recommendations.
+// The cluster is never scaled by the recommendations.
+func NewRecommendationManager(deps *controller.Dependencies) manager.Manager {
+	return &recommendationManager{
+		deps:    deps,
+		queryFn: queryPrometheus,
+		worker:  startPrometheusQueryWorker(),
+	}
+}
+
+// Sync publishes the recommendations computed in the background and queues the queries of the next
+// recommendations if the last ones are older than the interval
+func (m *recommendationManager) Sync(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	key := fmt.Sprintf("%s/%s", ns, tc.GetName())
+	if tc.Spec.Recommendations == nil {
+		tc.Status.Recommendations = nil
+		m.worker.forget(key)
+		return nil
+	}
+
+	if result, ok := m.worker.result(key); ok {
+		recommendations := result.(*v1alpha1.Recommendations)
+		if recommendations.Message != "" {
+			klog.Warningf("failed to compute the recommendations of cluster %s: %s", key, recommendations.Message)
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "RecommendationFailed", "failed to query the metrics: %s", recommendations.Message)
+		}
+		tc.Status.Recommendations = recommendations
+	}
+	if last := tc.Status.Recommendations; last != nil && time.Since(last.LastUpdateTime.Time) < tc.RecommendationsInterval() {
+		return nil
+	}
+
+	prometheusURL := tc.Spec.Recommendations.PrometheusURL
+	if prometheusURL == "" {
+		prometheusURL = fmt.Sprintf("http://%s-prometheus.%s:9090", tc.Spec.Recommendations.MonitorName, ns)
+	}
+	// tc is modified by the following syncs while the queries are running
+	tcCopy := tc.DeepCopy()
+	m.worker.submit(key, func() interface{} {
+		return m.recommend(tcCopy, prometheusURL)
+	})
+	return nil
+}
+
+// recommend queries the metrics of the cluster and computes the recommendations
+func (m *recommendationManager) recommend(tc *v1alpha1.TidbCluster, prometheusURL string) *v1alpha1.Recommendations {
+	recommendations := &v1alpha1.Recommendations{
+		Window: tc.RecommendationsWindow(),
+	}
+	var errs []string
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.Replicas > 0 {
+		r, err := m.recommendTiDB(tc, prometheusURL)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		recommendations.TiDB = r
+	}
+	// the stores holding witness or learner replicas don't serve the data of users
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.Replicas > 0 && tc.Spec.TiKV.StoreRole == "" {
+		r, err := m.recommendTiKV(tc, prometheusURL)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		recommendations.TiKV = r
+	}
+	recommendations.Message = strings.Join(errs, "; ")
+	recommendations.LastUpdateTime = metav1.Now()
+	return recommendations
+}
+
+// recommendTiDB recommends the replicas of TiDB which keep the average CPU utilization in the
+// window around the target, the CPU quota is the GOMAXPROCS of the TiDB servers
+func (m *recommendationManager) recommendTiDB(tc *v1alpha1.TidbCluster, prometheusURL string) (*v1alpha1.TiDBReplicasRecommendation, error) {
+	selector := recommendationSelector(tc, v1alpha1.TiDBMemberType)
+	used, ok, err := m.queryFn(prometheusURL, fmt.Sprintf("sum(rate(process_cpu_seconds_total{%s}[%s]))", selector, tc.RecommendationsWindow()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cpu used by tidb: %v", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	quota, ok, err := m.queryFn(prometheusURL, fmt.Sprintf("sum(tidb_server_maxprocs{%s})", selector))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cpu quota of tidb: %v", err)
+	}
+	if !ok || quota <= 0 {
+		return nil, nil
+	}
+
+	current := tc.Spec.TiDB.Replicas
+	utilization := int32(math.Round(used * 100 / quota))
+	target := tc.TiDBTargetCPUUtilization()
+	recommended := (current*utilization + target - 1) / target
+	if recommended < 1 {
+		recommended = 1
+	}
+	return &v1alpha1.TiDBReplicasRecommendation{
+		CurrentReplicas:     current,
+		RecommendedReplicas: recommended,
+		CPUUtilization:      utilization,
+	}, nil
+}
+
+// recommendTiKV recommends the replicas of TiKV which keep the available storage above the minimum
+// headroom, assuming the capacity of the new stores is the same as the existing ones. TiKV is never
+// recommended to be scaled in.
+func (m *recommendationManager) recommendTiKV(tc *v1alpha1.TidbCluster, prometheusURL string) (*v1alpha1.TiKVStorageRecommendation, error) {
+	selector := recommendationSelector(tc, v1alpha1.TiKVMemberType)
+	capacity, ok, err := m.queryFn(prometheusURL, fmt.Sprintf(`sum(tikv_store_size_bytes{%s,type="capacity"})`, selector))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query storage capacity of tikv: %v", err)
+	}
+	if !ok || capacity <= 0 {
+		return nil, nil
+	}
+	available, ok, err := m.queryFn(prometheusURL, fmt.Sprintf(`sum(tikv_store_size_bytes{%s,type="available"})`, selector))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query storage available in tikv: %v", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	current := tc.Spec.TiKV.Replicas
+	usable := 1 - float64(tc.TiKVMinStorageHeadroom())/100
+	recommended := int32(math.Ceil(float64(current) * (capacity - available) / (capacity * usable)))
+	if recommended < current {
+		recommended = current
+	}
+	return &v1alpha1.TiKVStorageRecommendation{
+		CurrentReplicas:     current,
+		RecommendedReplicas: recommended,
+		Capacity:            *resource.NewQuantity(int64(capacity), resource.BinarySI),
+		Available:           *resource.NewQuantity(int64(available), resource.BinarySI),
+		Headroom:            int32(math.Floor(available * 100 / capacity)),
+	}, nil
+}
+
+func recommendationSelector(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) string {
+	return fmt.Sprintf(`kubernetes_namespace="%s",cluster="%s",component="%s"`, tc.GetNamespace(), tc.GetInstanceName(), memberType)
+}
+
+type FakeRecommendationManager struct {
+	err error
+}
+
+func NewFakeRecommendationManager() *FakeRecommendationManager {
+	return &FakeRecommendationManager{}
+}
+
+func (m *FakeRecommendationManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeRecommendationManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
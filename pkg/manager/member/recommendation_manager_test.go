@@ -0,0 +1,110 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestRecommendationManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewRecommendationManager(deps).(*recommendationManager)
+	m.worker = newPrometheusQueryWorker()
+	m.worker.limiter = rate.NewLimiter(rate.Inf, 1)
+	recorder := deps.Recorder.(*record.FakeRecorder)
+	var queried []string
+	var queryErr error
+	metrics := map[string]float64{
+		"process_cpu_seconds_total": 2.4,
+		"tidb_server_maxprocs":      6,
+		`type="capacity"`:           300 << 30,
+		`type="available"`:          30 << 30,
+	}
+	m.queryFn = func(prometheusURL, query string) (float64, bool, error) {
+		g.Expect(prometheusURL).To(Equal("http://prometheus.monitoring:9090"))
+		queried = append(queried, query)
+		for k, v := range metrics {
+			if strings.Contains(query, k) {
+				return v, true, queryErr
+			}
+		}
+		return 0, false, nil
+	}
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiKV.Replicas = 3
+	tc.Spec.Recommendations = &v1alpha1.RecommendationsSpec{PrometheusURL: "http://prometheus.monitoring:9090"}
+	// the queries run in the background
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.Recommendations).To(BeNil())
+	g.Expect(queried).To(BeEmpty())
+	g.Expect(m.worker.processNextTask()).To(BeTrue())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	r := tc.Status.Recommendations
+	g.Expect(r).NotTo(BeNil())
+	g.Expect(r.Window).To(Equal("1h"))
+	g.Expect(r.Message).To(BeEmpty())
+	// 40% of the CPU quota is used, 2 replicas keep the utilization at 60%
+	g.Expect(*r.TiDB).To(Equal(v1alpha1.TiDBReplicasRecommendation{CurrentReplicas: 3, RecommendedReplicas: 2, CPUUtilization: 40}))
+	// 270Gi of 300Gi is used, 4 replicas keep the headroom above 20%
+	g.Expect(r.TiKV.Headroom).To(Equal(int32(10)))
+	g.Expect(r.TiKV.RecommendedReplicas).To(Equal(int32(4)))
+	g.Expect(r.TiKV.Capacity.String()).To(Equal("300Gi"))
+	g.Expect(r.TiKV.Available.String()).To(Equal("30Gi"))
+	g.Expect(queried).To(ContainElement(`sum(rate(process_cpu_seconds_total{kubernetes_namespace="default",cluster="test",component="tidb"}[1h]))`))
+
+	// the recommendations are not refreshed within the interval
+	queried = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(queried).To(BeEmpty())
+
+	// TiKV is never recommended to be scaled in
+	tc.Status.Recommendations.LastUpdateTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	tc.Spec.Recommendations.TiDBTargetCPUUtilization = pointer.Int32Ptr(30)
+	metrics[`type="available"`] = 200 << 30
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(m.worker.processNextTask()).To(BeTrue())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	r = tc.Status.Recommendations
+	g.Expect(r.TiDB.RecommendedReplicas).To(Equal(int32(4)))
+	g.Expect(r.TiKV.Headroom).To(Equal(int32(66)))
+	g.Expect(r.TiKV.RecommendedReplicas).To(Equal(int32(3)))
+
+	// the failure of the queries doesn't block the sync
+	tc.Status.Recommendations.LastUpdateTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	queryErr = fmt.Errorf("connection refused")
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(m.worker.processNextTask()).To(BeTrue())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(tc.Status.Recommendations.Message).To(ContainSubstring("connection refused"))
+	g.Expect(tc.Status.Recommendations.TiDB).To(BeNil())
+
+	tc.Spec.Recommendations = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.Recommendations).To(BeNil())
+}
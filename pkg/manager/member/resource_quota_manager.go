@@ -0,0 +1,327 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultResourceQuotaHeadroomPercent = 10
+
+	// ResourceQuotaExceeded is the reason of the event emitted when the summed requests of the
+	// components exceed the max of spec.resourceQuota
+	ResourceQuotaExceeded = "ResourceQuotaExceeded"
+)
+
+var (
+	// defaultResourceQuotaRequests are the default requests of the containers without requests if
+	// spec.resourceQuota.defaultRequests is not set, as the Pods without the requests of cpu or
+	// memory are rejected once they are limited by the quota
+	defaultResourceQuotaRequests = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+		corev1.ResourceMemory: resource.MustParse("128Mi"),
+	}
+
+	// tidbClusterComponents are the components counted by TidbCluster.ComponentRequests
+	tidbClusterComponents = sets.NewString(
+		label.PDLabelVal,
+		label.TiKVLabelVal,
+		label.TiDBLabelVal,
+		label.TiFlashLabelVal,
+		label.TiCDCLabelVal,
+		label.PumpLabelVal,
+		label.TiProxyLabelVal,
+	)
+)
+
+// resourceQuotaNames maps the resources requested by the components to the resources of the quota
+var resourceQuotaNames = map[corev1.ResourceName]corev1.ResourceName{
+	corev1.ResourceCPU:     corev1.ResourceRequestsCPU,
+	corev1.ResourceMemory:  corev1.ResourceRequestsMemory,
+	corev1.ResourceStorage: corev1.ResourceRequestsStorage,
+}
+
+type resourceQuotaManager struct {
+	deps *controller.Dependencies
+	// exceeded records the clusters whose summed requests exceed the max, so that the event
+	// is emitted once when they start to exceed it
+	exceeded sync.Map
+}
+
+// NewResourceQuotaManager returns a manager which maintains the ResourceQuota and the LimitRange of
+// the tidb cluster according to spec.resourceQuota:
+//
+//   - <cluster>-quota limits the requests of the namespace. A ResourceQuota applies to all the Pods
+//     and PVCs of the namespace, so the hard limits are the summed requests of the components of the
+//     cluster, including the failover Pods, the sidecars and the init containers, plus the headroom
+//     and capped by spec.resourceQuota.max, plus the summed requests of the components of the other
+//     TidbClusters in the namespace, plus the current requests of the other Pods and PVCs managed by
+//     tidb-operator in the namespace, e.g. the discovery, TidbMonitor and the Jobs of the backups
+//   - <cluster>-limits sets spec.resourceQuota.defaultRequests, or 100m cpu and 128Mi memory if it's
+//     not set, as the default requests of the containers without requests
+//
+// The workloads not managed by tidb-operator in the namespace are not counted, they are expected to
+// fit in the headroom. The updates making the summed requests of the cluster exceed the max are refused
+// by the admission webhook. If the summed requests still exceed the max, e.g. after the failover, a
+// warning event is emitted and the components are synced as usual. The objects are deleted if
+// spec.resourceQuota is removed.
+func NewResourceQuotaManager(deps *controller.Dependencies) manager.Manager {
+	return &resourceQuotaManager{
+		deps: deps,
+	}
+}
+
+func (m *resourceQuotaManager) Sync(tc *v1alpha1.TidbCluster) error {
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-quota", tc.GetName()),
+			Namespace: tc.GetNamespace(),
+			Labels:    label.New().Instance(tc.GetInstanceName()).Labels(),
+		},
+	}
+	lr := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-limits", tc.GetName()),
+			Namespace: tc.GetNamespace(),
+			Labels:    label.New().Instance(tc.GetInstanceName()).Labels(),
+		},
+	}
+
+	spec := tc.Spec.ResourceQuota
+	if spec == nil {
+		m.exceeded.Delete(fmt.Sprintf("%s/%s", tc.GetNamespace(), tc.GetName()))
+		if err := m.deleteResourceQuotaIfExist(tc, quota); err != nil {
+			return err
+		}
+		return m.deleteLimitRangeIfExist(tc, lr)
+	}
+
+	requested, err := tc.ComponentRequests()
+	if err != nil {
+		return err
+	}
+	m.checkMax(tc, requested)
+
+	headroom := int64(defaultResourceQuotaHeadroomPercent)
+	if spec.HeadroomPercent != nil {
+		headroom = int64(*spec.HeadroomPercent)
+	}
+	others, err := m.otherRequests(tc)
+	if err != nil {
+		return err
+	}
+	quota.Spec.Hard = corev1.ResourceList{}
+	for name, q := range requested {
+		if q.IsZero() {
+			continue
+		}
+		hard := multiplyQuantity(name, q, 100+headroom, 100)
+		if max, ok := spec.Max[name]; ok && hard.Cmp(max) > 0 {
+			hard = max.DeepCopy()
+		}
+		if other, ok := others[name]; ok {
+			hard.Add(other)
+		}
+		quota.Spec.Hard[resourceQuotaNames[name]] = hard
+	}
+	if _, err := m.deps.TypedControl.CreateOrUpdateResourceQuota(tc, quota); err != nil {
+		return controller.RequeueErrorf("error creating or updating resource quota %s/%s: %v", quota.Namespace, quota.Name, err)
+	}
+	klog.V(4).Infof("tidbcluster: [%s/%s]'s resource quota is synced, hard: %v", tc.GetNamespace(), tc.GetName(), quota.Spec.Hard)
+
+	defaultRequests := spec.DefaultRequests
+	if len(defaultRequests) == 0 {
+		defaultRequests = defaultResourceQuotaRequests
+	}
+	lr.Spec.Limits = []corev1.LimitRangeItem{
+		{
+			Type:           corev1.LimitTypeContainer,
+			DefaultRequest: defaultRequests,
+		},
+	}
+	if _, err := m.deps.TypedControl.CreateOrUpdateLimitRange(tc, lr); err != nil {
+		return controller.RequeueErrorf("error creating or updating limit range %s/%s: %v", lr.Namespace, lr.Name, err)
+	}
+	return nil
+}
+
+// otherRequests returns the requests in the namespace not made by the components of tc, i.e. the
+// summed requests of the components of the other TidbClusters and the current requests of the other
+// Pods and PVCs managed by tidb-operator
+func (m *resourceQuotaManager) otherRequests(tc *v1alpha1.TidbCluster) (corev1.ResourceList, error) {
+	ns := tc.GetNamespace()
+	others := corev1.ResourceList{}
+	addTo := func(list corev1.ResourceList) {
+		for name, q := range list {
+			total := others[name]
+			total.Add(q)
+			others[name] = total
+		}
+	}
+
+	tcs, err := m.deps.TiDBClusterLister.TidbClusters(ns).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tidb clusters in namespace %s: %v", ns, err)
+	}
+	instances := sets.NewString()
+	for _, other := range tcs {
+		instances.Insert(other.GetInstanceName())
+		if other.GetName() == tc.GetName() {
+			continue
+		}
+		requested, err := other.ComponentRequests()
+		if err != nil {
+			klog.Warningf("tidbcluster: [%s/%s]'s resource quota doesn't count the requests of tidbcluster %s: %v", ns, tc.GetName(), other.GetName(), err)
+			continue
+		}
+		addTo(requested)
+	}
+	// the Pods and PVCs of the components are counted by the requests of the TidbClusters above,
+	// including the ones being created
+	counted := func(l map[string]string) bool {
+		return tidbClusterComponents.Has(l[label.ComponentLabelKey]) && instances.Has(l[label.InstanceLabelKey])
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{label.ManagedByLabelKey: label.TiDBOperator})
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %v", ns, err)
+	}
+	for _, pod := range pods {
+		if counted(pod.Labels) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		addTo(podRequestsForQuota(pod))
+	}
+	pvcs, err := m.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pvcs in namespace %s: %v", ns, err)
+	}
+	for _, pvc := range pvcs {
+		if counted(pvc.Labels) {
+			continue
+		}
+		if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			addTo(corev1.ResourceList{corev1.ResourceStorage: q})
+		}
+	}
+	return others, nil
+}
+
+// podRequestsForQuota returns the cpu and memory requests of the pod counted by ResourceQuota, the
+// larger of the summed requests of the containers and the requests of each init container
+func podRequestsForQuota(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		var q resource.Quantity
+		for _, c := range pod.Spec.Containers {
+			if r, ok := c.Resources.Requests[name]; ok {
+				q.Add(r)
+			}
+		}
+		for _, c := range pod.Spec.InitContainers {
+			if r, ok := c.Resources.Requests[name]; ok && r.Cmp(q) > 0 {
+				q = r.DeepCopy()
+			}
+		}
+		if !q.IsZero() {
+			requests[name] = q
+		}
+	}
+	return requests
+}
+
+// checkMax emits a warning event when the summed requests start to exceed the max, the hard limits
+// are capped by the max anyway
+func (m *resourceQuotaManager) checkMax(tc *v1alpha1.TidbCluster, requested corev1.ResourceList) {
+	key := fmt.Sprintf("%s/%s", tc.GetNamespace(), tc.GetName())
+	var msgs []string
+	for name, max := range tc.Spec.ResourceQuota.Max {
+		if q, ok := requested[name]; ok && q.Cmp(max) > 0 {
+			msgs = append(msgs, fmt.Sprintf("summed %s requests %s of the components exceed the max %s of the resource quota", name, q.String(), max.String()))
+		}
+	}
+	if len(msgs) == 0 {
+		m.exceeded.Delete(key)
+		return
+	}
+	if _, loaded := m.exceeded.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	for _, msg := range msgs {
+		klog.Warningf("tidbcluster: [%s/%s]'s %s", tc.GetNamespace(), tc.GetName(), msg)
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, ResourceQuotaExceeded, msg)
+	}
+}
+
+func (m *resourceQuotaManager) deleteResourceQuotaIfExist(tc *v1alpha1.TidbCluster, quota *corev1.ResourceQuota) error {
+	_, err := m.deps.ResourceQuotaLister.ResourceQuotas(quota.Namespace).Get(quota.Name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get resource quota %s/%s: %v", quota.Namespace, quota.Name, err)
+	}
+	return m.deps.TypedControl.Delete(tc, quota)
+}
+
+func (m *resourceQuotaManager) deleteLimitRangeIfExist(tc *v1alpha1.TidbCluster, lr *corev1.LimitRange) error {
+	_, err := m.deps.LimitRangeLister.LimitRanges(lr.Namespace).Get(lr.Name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get limit range %s/%s: %v", lr.Namespace, lr.Name, err)
+	}
+	return m.deps.TypedControl.Delete(tc, lr)
+}
+
+// multiplyQuantity returns q * numerator / denominator, cpu is calculated in millicores
+func multiplyQuantity(name corev1.ResourceName, q resource.Quantity, numerator, denominator int64) resource.Quantity {
+	if name == corev1.ResourceCPU {
+		return *resource.NewMilliQuantity(q.MilliValue()*numerator/denominator, q.Format)
+	}
+	return *resource.NewQuantity(q.Value()*numerator/denominator, q.Format)
+}
+
+type FakeResourceQuotaManager struct {
+	err error
+}
+
+func NewFakeResourceQuotaManager() *FakeResourceQuotaManager {
+	return &FakeResourceQuotaManager{}
+}
+
+func (m *FakeResourceQuotaManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeResourceQuotaManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestResourceQuotaManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeDeps := controller.NewFakeDependencies()
+	ctrl := fakeDeps.GenericControl.(*controller.FakeGenericControl)
+	recorder := fakeDeps.Recorder.(*record.FakeRecorder)
+	quotaIndexer := fakeDeps.LabelFilterKubeInformerFactory.Core().V1().ResourceQuotas().Informer().GetIndexer()
+	lrIndexer := fakeDeps.LabelFilterKubeInformerFactory.Core().V1().LimitRanges().Informer().GetIndexer()
+	tcIndexer := fakeDeps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	m := NewResourceQuotaManager(fakeDeps)
+
+	getQuota := func() (*corev1.ResourceQuota, error) {
+		quota := &corev1.ResourceQuota{}
+		err := ctrl.FakeCli.Get(context.TODO(), client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: "test-quota"}, quota)
+		return quota, err
+	}
+	getLimitRange := func() (*corev1.LimitRange, error) {
+		lr := &corev1.LimitRange{}
+		err := ctrl.FakeCli.Get(context.TODO(), client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: "test-limits"}, lr)
+		return lr, err
+	}
+	expectQuantity := func(list corev1.ResourceList, name corev1.ResourceName, expected string) {
+		q := list[name]
+		g.Expect(q.Cmp(resource.MustParse(expected))).To(BeZero(), "%s: %s != %s", name, q.String(), expected)
+	}
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiKV.Replicas = 3
+	tc.Spec.TiKV.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:     resource.MustParse("2"),
+		corev1.ResourceMemory:  resource.MustParse("4Gi"),
+		corev1.ResourceStorage: resource.MustParse("100Gi"),
+	}
+	tc.Spec.TiKV.StorageVolumes = []v1alpha1.StorageVolume{{Name: "raftlog", StorageSize: "10Gi"}}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, err := getQuota()
+	g.Expect(err).To(HaveOccurred())
+
+	tc.Spec.ResourceQuota = &v1alpha1.ResourceQuotaSpec{
+		Max: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("9500m"),
+		},
+		HeadroomPercent: pointer.Int32Ptr(50),
+		DefaultRequests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("100m"),
+		},
+	}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	quota, err := getQuota()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(quota.Spec.Hard).To(HaveLen(3))
+	// the summed cpu 9 plus the headroom is capped by the max
+	expectQuantity(quota.Spec.Hard, corev1.ResourceRequestsCPU, "9500m")
+	expectQuantity(quota.Spec.Hard, corev1.ResourceRequestsMemory, "27Gi")
+	expectQuantity(quota.Spec.Hard, corev1.ResourceRequestsStorage, "495Gi")
+	lr, err := getLimitRange()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(lr.Spec.Limits).To(HaveLen(1))
+	g.Expect(lr.Spec.Limits[0].Type).To(Equal(corev1.LimitTypeContainer))
+	g.Expect(lr.Spec.Limits[0].DefaultRequest).To(Equal(tc.Spec.ResourceQuota.DefaultRequests))
+	g.Expect(quotaIndexer.Add(quota)).To(Succeed())
+	g.Expect(lrIndexer.Add(lr)).To(Succeed())
+
+	// the cluster exceeding the max is still synced, the event is emitted once
+	tc.Spec.TiDB.Replicas = 4
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring(ResourceQuotaExceeded))
+	quota, err = getQuota()
+	g.Expect(err).NotTo(HaveOccurred())
+	expectQuantity(quota.Spec.Hard, corev1.ResourceRequestsCPU, "9500m")
+
+	// the failover Pods and the sidecars are counted
+	tc.Spec.TiDB.Replicas = 3
+	tc.Spec.TiDB.SlowLogTailer = &v1alpha1.TiDBSlowLogTailerSpec{
+		ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+	}
+	tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{"1": {PodName: "test-tikv-1"}}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	quota, err = getQuota()
+	g.Expect(err).NotTo(HaveOccurred())
+	// (4 * 4Gi + 3 * 2Gi + 3 * 1Gi) * 1.5
+	expectQuantity(quota.Spec.Hard, corev1.ResourceRequestsMemory, "37.5Gi")
+
+	// the other clusters and the other workloads managed by tidb-operator in the namespace are counted
+	other := newTidbClusterForTiDB()
+	other.Name = "other"
+	other.Spec.TiDB.Replicas = 1
+	other.Spec.TiDB.InitContainers = []corev1.Container{{
+		Name:      "init",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}},
+	}}
+	g.Expect(tcIndexer.Add(tc)).To(Succeed())
+	g.Expect(tcIndexer.Add(other)).To(Succeed())
+	newPod := func(name string, l label.Label, memory string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		pod.Namespace = corev1.NamespaceDefault
+		pod.Name = name
+		pod.Labels = l.Labels()
+		pod.Spec.Containers = []corev1.Container{{
+			Name:      "main",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)}},
+		}}
+		return pod
+	}
+	// the Pods of the components are counted by the requests of the clusters
+	g.Expect(podIndexer.Add(newPod("test-tidb-0", label.New().Instance("test").TiDB(), "2Gi"))).To(Succeed())
+	g.Expect(podIndexer.Add(newPod("other-tidb-0", label.New().Instance("other").TiDB(), "2Gi"))).To(Succeed())
+	g.Expect(podIndexer.Add(newPod("test-discovery", label.New().Instance("test").Discovery(), "512Mi"))).To(Succeed())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	quota, err = getQuota()
+	g.Expect(err).NotTo(HaveOccurred())
+	// 37.5Gi + the init container of the other cluster 4Gi + the discovery 512Mi
+	expectQuantity(quota.Spec.Hard, corev1.ResourceRequestsMemory, "42Gi")
+
+	// the default requests are set if defaultRequests is not set
+	tc.Spec.ResourceQuota.DefaultRequests = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	lr, err = getLimitRange()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(lr.Spec.Limits[0].DefaultRequest).To(Equal(defaultResourceQuotaRequests))
+
+	tc.Spec.ResourceQuota = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	_, err = getQuota()
+	g.Expect(err).To(HaveOccurred())
+	_, err = getLimitRange()
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,294 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// rightSizingLowUtilization is the utilization of CPU and memory under which a component is oversized
+	rightSizingLowUtilization = 0.3
+	// rightSizingHighUtilization is the utilization of CPU and memory above which a component is undersized
+	rightSizingHighUtilization = 0.9
+	// rightSizingHighStorageUtilization is the utilization of storage above which a component is undersized
+	rightSizingHighStorageUtilization = 0.8
+
+	prometheusQueryTimeout = 10 * time.Second
+)
+
+// resourceReportComponent is a component whose resources are reported
+type resourceReportComponent struct {
+	memberType v1alpha1.MemberType
+	replicas   int32
+	requests   corev1.ResourceList
+	// storage is whether the storage used is reported
+	storage bool
+}
+
+type resourceReportManager struct {
+	deps *controller.Dependencies
+	// queryFn runs an instant query on the Prometheus, it returns false if the result is empty
+	queryFn func(prometheusURL, query string) (float64, bool, error)
+	worker  *prometheusQueryWorker
+}
+
+// NewResourceReportManager returns a manager which reports the requested and used resources
+// of PD, TiKV and TiDB in status.resourceReport according to spec.resourceReport
+func NewResourceReportManager(deps *controller.Dependencies) manager.Manager {
+	return &resourceReportManager{
+		deps:    deps,
+		queryFn: queryPrometheus,
+		worker:  startPrometheusQueryWorker(),
+	}
+}
+
+// Sync publishes the report finished in the background and queues the queries of the next report
+// if the last one is older than the interval
+func (m *resourceReportManager) Sync(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	key := fmt.Sprintf("%s/%s", ns, tcName)
+	if tc.Spec.ResourceReport == nil {
+		if tc.Status.ResourceReport != nil {
+			metrics.DeleteClusterResourceMetrics(ns, tcName)
+		}
+		tc.Status.ResourceReport = nil
+		m.worker.forget(key)
+		return nil
+	}
+
+	if result, ok := m.worker.result(key); ok {
+		report := result.(*v1alpha1.ResourceReport)
+		for memberType, componentReport := range report.Components {
+			for name, q := range componentReport.Requested {
+				metrics.ClusterResourceRequested.WithLabelValues(ns, tcName, memberType.String(), string(name)).Set(quantityValue(name, q))
+			}
+			for name, q := range componentReport.Used {
+				metrics.ClusterResourceUsed.WithLabelValues(ns, tcName, memberType.String(), string(name)).Set(quantityValue(name, q))
+			}
+		}
+		if report.Message != "" {
+			klog.Warningf("failed to report resources of cluster %s: %s", key, report.Message)
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "ResourceReportFailed", "failed to query the resource usage: %s", report.Message)
+		}
+		tc.Status.ResourceReport = report
+	}
+	if last := tc.Status.ResourceReport; last != nil && time.Since(last.LastReportTime.Time) < tc.ResourceReportInterval() {
+		return nil
+	}
+
+	prometheusURL := tc.Spec.ResourceReport.PrometheusURL
+	if prometheusURL == "" {
+		prometheusURL = fmt.Sprintf("http://%s-prometheus.%s:9090", tc.Spec.ResourceReport.MonitorName, ns)
+	}
+	// tc is modified by the following syncs while the queries are running
+	tcCopy := tc.DeepCopy()
+	m.worker.submit(key, func() interface{} {
+		return m.report(tcCopy, prometheusURL)
+	})
+	return nil
+}
+
+// report queries the resources used by the components of the cluster
+func (m *resourceReportManager) report(tc *v1alpha1.TidbCluster, prometheusURL string) *v1alpha1.ResourceReport {
+	report := &v1alpha1.ResourceReport{
+		Window:     tc.ResourceReportWindow(),
+		Components: map[v1alpha1.MemberType]v1alpha1.ComponentResourceReport{},
+	}
+	var errs []string
+	for _, component := range resourceReportComponents(tc) {
+		componentReport, err := m.reportComponent(tc, prometheusURL, component)
+		if err != nil {
+			// the usage is partially reported, the report of the other components goes on
+			errs = append(errs, err.Error())
+		}
+		report.Components[component.memberType] = componentReport
+	}
+	report.Message = strings.Join(errs, "; ")
+	report.LastReportTime = metav1.Now()
+	return report
+}
+
+// resourceReportComponents returns the deployed components whose resources are reported
+func resourceReportComponents(tc *v1alpha1.TidbCluster) []resourceReportComponent {
+	var components []resourceReportComponent
+	if tc.Spec.PD != nil {
+		components = append(components, resourceReportComponent{
+			memberType: v1alpha1.PDMemberType,
+			replicas:   tc.Spec.PD.Replicas,
+			requests:   tc.Spec.PD.Requests,
+		})
+	}
+	if tc.Spec.TiKV != nil {
+		components = append(components, resourceReportComponent{
+			memberType: v1alpha1.TiKVMemberType,
+			replicas:   tc.Spec.TiKV.Replicas,
+			requests:   tc.Spec.TiKV.Requests,
+			storage:    true,
+		})
+	}
+	if tc.Spec.TiDB != nil {
+		components = append(components, resourceReportComponent{
+			memberType: v1alpha1.TiDBMemberType,
+			replicas:   tc.Spec.TiDB.Replicas,
+			requests:   tc.Spec.TiDB.Requests,
+		})
+	}
+	return components
+}
+
+func (m *resourceReportManager) reportComponent(tc *v1alpha1.TidbCluster, prometheusURL string, component resourceReportComponent) (v1alpha1.ComponentResourceReport, error) {
+	report := v1alpha1.ComponentResourceReport{
+		Replicas:  component.replicas,
+		Requested: corev1.ResourceList{},
+		Used:      corev1.ResourceList{},
+	}
+	names := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+	if component.storage {
+		names = append(names, corev1.ResourceStorage)
+	}
+	for _, name := range names {
+		if q, ok := component.requests[name]; ok {
+			if name == corev1.ResourceCPU {
+				report.Requested[name] = *resource.NewMilliQuantity(q.MilliValue()*int64(component.replicas), q.Format)
+			} else {
+				report.Requested[name] = *resource.NewQuantity(q.Value()*int64(component.replicas), q.Format)
+			}
+		}
+	}
+
+	selector := fmt.Sprintf(`kubernetes_namespace="%s",cluster="%s",component="%s"`, tc.GetNamespace(), tc.GetInstanceName(), component.memberType)
+	window := tc.ResourceReportWindow()
+	queries := map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    fmt.Sprintf("sum(rate(process_cpu_seconds_total{%s}[%s]))", selector, window),
+		corev1.ResourceMemory: fmt.Sprintf("sum(max_over_time(process_resident_memory_bytes{%s}[%s]))", selector, window),
+	}
+	if component.storage {
+		queries[corev1.ResourceStorage] = fmt.Sprintf(`sum(tikv_store_size_bytes{%s,type="used"})`, selector)
+	}
+	for _, name := range names {
+		value, ok, err := m.queryFn(prometheusURL, queries[name])
+		if err != nil {
+			return report, fmt.Errorf("failed to query %s used by %s: %v", name, component.memberType, err)
+		}
+		if !ok {
+			continue
+		}
+		if name == corev1.ResourceCPU {
+			report.Used[name] = *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI)
+		} else {
+			report.Used[name] = *resource.NewQuantity(int64(value), resource.BinarySI)
+		}
+	}
+	report.Suggestion = rightSizingSuggestion(report.Requested, report.Used)
+	return report, nil
+}
+
+// rightSizingSuggestion suggests downsizing a component if both CPU and memory are rarely used,
+// and upsizing it if any of the resources is close to the requests
+func rightSizingSuggestion(requested, used corev1.ResourceList) v1alpha1.RightSizingSuggestion {
+	utilization := map[corev1.ResourceName]float64{}
+	for name, req := range requested {
+		u, ok := used[name]
+		if !ok || req.IsZero() {
+			continue
+		}
+		utilization[name] = float64(u.MilliValue()) / float64(req.MilliValue())
+	}
+	cpu, cpuOK := utilization[corev1.ResourceCPU]
+	memory, memoryOK := utilization[corev1.ResourceMemory]
+	if !cpuOK || !memoryOK {
+		return ""
+	}
+	if cpu > rightSizingHighUtilization || memory > rightSizingHighUtilization {
+		return v1alpha1.RightSizingUndersized
+	}
+	if storage, ok := utilization[corev1.ResourceStorage]; ok && storage > rightSizingHighStorageUtilization {
+		return v1alpha1.RightSizingUndersized
+	}
+	if cpu < rightSizingLowUtilization && memory < rightSizingLowUtilization {
+		return v1alpha1.RightSizingOversized
+	}
+	return v1alpha1.RightSizingFit
+}
+
+// quantityValue returns the value of the quantity in cores for CPU and in bytes for the others
+func quantityValue(name corev1.ResourceName, q resource.Quantity) float64 {
+	if name == corev1.ResourceCPU {
+		return float64(q.MilliValue()) / 1000
+	}
+	return float64(q.Value())
+}
+
+// queryPrometheus runs an instant query on the Prometheus and returns the value of the first sample
+func queryPrometheus(prometheusURL, query string) (float64, bool, error) {
+	client := &http.Client{Timeout: prometheusQueryTimeout}
+	resp, err := client.Get(fmt.Sprintf("%s/api/v1/query?query=%s", strings.TrimSuffix(prometheusURL, "/"), url.QueryEscape(query)))
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string       `json:"resultType"`
+			Result     model.Vector `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, false, fmt.Errorf("failed to decode the response of %q, status code %d: %v", query, resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return 0, false, fmt.Errorf("query %q failed: %s", query, result.Error)
+	}
+	if len(result.Data.Result) == 0 {
+		return 0, false, nil
+	}
+	return float64(result.Data.Result[0].Value), true, nil
+}
+
+type FakeResourceReportManager struct {
+	err error
+}
+
+func NewFakeResourceReportManager() *FakeResourceReportManager {
+	return &FakeResourceReportManager{}
+}
+
+func (m *FakeResourceReportManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeResourceReportManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
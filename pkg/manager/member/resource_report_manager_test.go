@@ -0,0 +1,151 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestResourceReportManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewResourceReportManager(deps).(*resourceReportManager)
+	m.worker = newPrometheusQueryWorker()
+	m.worker.limiter = rate.NewLimiter(rate.Inf, 1)
+	recorder := deps.Recorder.(*record.FakeRecorder)
+	var queried []string
+	var queryErr error
+	m.queryFn = func(prometheusURL, query string) (float64, bool, error) {
+		g.Expect(prometheusURL).To(Equal("http://basic-prometheus.default:9090"))
+		queried = append(queried, query)
+		if !strings.Contains(query, `component="tidb"`) {
+			return 0, false, nil
+		}
+		if strings.Contains(query, "process_cpu_seconds_total") {
+			return 0.6, true, queryErr
+		}
+		return 1 << 30, true, queryErr
+	}
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.ResourceReport = &v1alpha1.ResourceReportSpec{MonitorName: "basic"}
+	// the queries run in the background
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.ResourceReport).To(BeNil())
+	g.Expect(queried).To(BeEmpty())
+	// the running queries are not queued again
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(m.worker.tasks).To(HaveLen(1))
+	g.Expect(m.worker.processNextTask()).To(BeTrue())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	report := tc.Status.ResourceReport
+	g.Expect(report).NotTo(BeNil())
+	g.Expect(report.Window).To(Equal("1h"))
+	g.Expect(report.Message).To(BeEmpty())
+	g.Expect(report.Components).To(HaveLen(3))
+	tidb := report.Components[v1alpha1.TiDBMemberType]
+	g.Expect(tidb.Replicas).To(Equal(int32(3)))
+	g.Expect(tidb.Requested.Cpu().String()).To(Equal("3"))
+	g.Expect(tidb.Requested.Memory().String()).To(Equal("6Gi"))
+	g.Expect(tidb.Used.Cpu().String()).To(Equal("600m"))
+	g.Expect(tidb.Used.Memory().String()).To(Equal("1Gi"))
+	g.Expect(tidb.Suggestion).To(Equal(v1alpha1.RightSizingOversized))
+	g.Expect(report.Components[v1alpha1.TiKVMemberType].Suggestion).To(BeEmpty())
+	g.Expect(queried).To(ContainElement(`sum(tikv_store_size_bytes{kubernetes_namespace="default",cluster="test",component="tikv",type="used"})`))
+
+	// the report is not refreshed within the interval
+	queried = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(queried).To(BeEmpty())
+
+	// the failure of the queries doesn't block the sync
+	tc.Status.ResourceReport.LastReportTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	queryErr = fmt.Errorf("connection refused")
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(m.worker.processNextTask()).To(BeTrue())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(tc.Status.ResourceReport.Message).To(ContainSubstring("connection refused"))
+	g.Expect(tc.Status.ResourceReport.Components[v1alpha1.TiDBMemberType].Requested.Cpu().String()).To(Equal("3"))
+
+	tc.Spec.ResourceReport = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.ResourceReport).To(BeNil())
+	g.Expect(testutil.CollectAndCount(metrics.ClusterResourceRequested)).To(Equal(0))
+}
+
+func TestRightSizingSuggestion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	requested := corev1.ResourceList{
+		corev1.ResourceCPU:     resource.MustParse("4"),
+		corev1.ResourceMemory:  resource.MustParse("8Gi"),
+		corev1.ResourceStorage: resource.MustParse("100Gi"),
+	}
+	tests := []struct {
+		name string
+		used corev1.ResourceList
+		want v1alpha1.RightSizingSuggestion
+	}{
+		{
+			name: "usage unknown",
+			used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			want: "",
+		},
+		{
+			name: "oversized",
+			used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+			want: v1alpha1.RightSizingOversized,
+		},
+		{
+			name: "undersized cpu",
+			used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3800m"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+			want: v1alpha1.RightSizingUndersized,
+		},
+		{
+			name: "undersized storage",
+			used: corev1.ResourceList{
+				corev1.ResourceCPU:     resource.MustParse("2"),
+				corev1.ResourceMemory:  resource.MustParse("4Gi"),
+				corev1.ResourceStorage: resource.MustParse("90Gi"),
+			},
+			want: v1alpha1.RightSizingUndersized,
+		},
+		{
+			name: "fit",
+			used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+			want: v1alpha1.RightSizingFit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g.Expect(rightSizingSuggestion(requested, tt.used)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// InsufficientNodes is the reason of the event emitted when the replicas of a component exceed the
+// number of the schedulable nodes matching its scheduling constraints
+const InsufficientNodes = "InsufficientNodes"
+
+// SchedulingChecker emits a warning event for each component whose replicas turn to exceed the number of
+// the schedulable nodes matching its node selector, required node affinity and tolerations, the Pods
+// beyond the number may be pending if they're spread across the nodes. It also sets the
+// PerformanceProfileSatisfiable condition by whether the schedulable nodes can allocate the resources
+// required by the performance profiles of TiKV and TiFlash.
+//
+// The nodes are not checked if tidb-controller-manager has no permission for nodes.
+type SchedulingChecker interface {
+	Check(tc *v1alpha1.TidbCluster) error
+}
+
+type schedulingChecker struct {
+	deps *controller.Dependencies
+	// insufficient records the last message of the insufficient nodes of each component, so that
+	// the warning event is only emitted when it changes
+	insufficient sync.Map
+}
+
+// NewSchedulingChecker returns a SchedulingChecker
+func NewSchedulingChecker(deps *controller.Dependencies) SchedulingChecker {
+	return &schedulingChecker{
+		deps: deps,
+	}
+}
+
+func (c *schedulingChecker) Check(tc *v1alpha1.TidbCluster) error {
+	if c.deps.NodeLister == nil {
+		return nil
+	}
+	nodes, err := c.deps.NodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("scheduling checker: failed to list nodes, error: %v", err)
+	}
+
+	for _, comp := range schedulingComponents(tc) {
+		if comp.replicas == 0 {
+			continue
+		}
+		matched := 0
+		for _, node := range nodes {
+			if nodeSchedulableFor(node, comp.spec) {
+				matched++
+			}
+		}
+		key := fmt.Sprintf("%s/%s/%s", tc.Namespace, tc.Name, comp.typ)
+		if int(comp.replicas) <= matched {
+			c.insufficient.Delete(key)
+			continue
+		}
+		msg := fmt.Sprintf("%d replicas of %s exceed %d schedulable nodes matching its node selector, node affinity and tolerations", comp.replicas, comp.typ, matched)
+		if last, ok := c.insufficient.Load(key); ok && last.(string) == msg {
+			continue
+		}
+		c.insufficient.Store(key, msg)
+		klog.Warningf("tc %s/%s: %d replicas of %s exceed %d schedulable nodes", tc.Namespace, tc.Name, comp.replicas, comp.typ, matched)
+		c.deps.Recorder.Event(tc, corev1.EventTypeWarning, InsufficientNodes, msg)
+	}
+	syncPerformanceProfileCondition(tc, nodes, c.deps.Recorder)
+	return nil
+}
+
+type schedulingComponent struct {
+	typ      v1alpha1.MemberType
+	replicas int32
+	spec     v1alpha1.ComponentAccessor
+}
+
+func schedulingComponents(tc *v1alpha1.TidbCluster) []schedulingComponent {
+	var comps []schedulingComponent
+	if tc.Spec.PD != nil {
+		comps = append(comps, schedulingComponent{v1alpha1.PDMemberType, tc.Spec.PD.Replicas, tc.BasePDSpec()})
+	}
+	if tc.Spec.TiKV != nil {
+		comps = append(comps, schedulingComponent{v1alpha1.TiKVMemberType, tc.Spec.TiKV.Replicas, tc.BaseTiKVSpec()})
+	}
+	if tc.Spec.TiDB != nil {
+		comps = append(comps, schedulingComponent{v1alpha1.TiDBMemberType, tc.Spec.TiDB.Replicas, tc.BaseTiDBSpec()})
+	}
+	if tc.Spec.TiFlash != nil {
+		comps = append(comps, schedulingComponent{v1alpha1.TiFlashMemberType, tc.Spec.TiFlash.Replicas, tc.BaseTiFlashSpec()})
+	}
+	if tc.Spec.TiCDC != nil {
+		comps = append(comps, schedulingComponent{v1alpha1.TiCDCMemberType, tc.Spec.TiCDC.Replicas, tc.BaseTiCDCSpec()})
+	}
+	if tc.Spec.Pump != nil {
+		comps = append(comps, schedulingComponent{v1alpha1.PumpMemberType, tc.Spec.Pump.Replicas, tc.BasePumpSpec()})
+	}
+	if tc.Spec.TiProxy != nil {
+		comps = append(comps, schedulingComponent{v1alpha1.TiProxyMemberType, tc.Spec.TiProxy.Replicas, tc.BaseTiProxySpec()})
+	}
+	return comps
+}
+
+// nodeSchedulableFor returns whether the Pods of the component can be scheduled to the node, it only
+// checks the scheduling constraints, see podFitsNode for a more complete version
+func nodeSchedulableFor(node *corev1.Node, spec v1alpha1.ComponentAccessor) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	if !labels.SelectorFromSet(spec.NodeSelector()).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	if affinity := spec.Affinity(); affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !nodeMatchesTerms(node, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) {
+			return false
+		}
+	}
+	tolerations := spec.Tolerations()
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+type FakeSchedulingChecker struct {
+	err error
+}
+
+func NewFakeSchedulingChecker() *FakeSchedulingChecker {
+	return &FakeSchedulingChecker{}
+}
+
+func (c *FakeSchedulingChecker) SetCheckError(err error) {
+	c.err = err
+}
+
+func (c *FakeSchedulingChecker) Check(_ *v1alpha1.TidbCluster) error {
+	return c.err
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSchedulingCheckerCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	checker := NewSchedulingChecker(deps)
+	recorder := deps.Recorder.(*record.FakeRecorder)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{Replicas: 3},
+			TiKV: &v1alpha1.TiKVSpec{Replicas: 3, ComponentSpec: v1alpha1.ComponentSpec{NodeSelector: map[string]string{"dedicated": "tikv"}}},
+			TiDB: &v1alpha1.TiDBSpec{Replicas: 0},
+		},
+	}
+
+	// no permission for nodes
+	deps.NodeLister = nil
+	g.Expect(checker.Check(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	deps.NodeLister = deps.KubeInformerFactory.Core().V1().Nodes().Lister()
+	addNode := func(name string, labels map[string]string, taints ...corev1.Taint) {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+		g.Expect(deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(node)).To(Succeed())
+	}
+	tikvNode := map[string]string{"dedicated": "tikv"}
+	addNode("node-1", nil)
+	addNode("node-2", nil)
+	addNode("node-3", tikvNode)
+	addNode("node-4", tikvNode, corev1.Taint{Key: "dedicated", Value: "tikv", Effect: corev1.TaintEffectNoSchedule})
+	addNode("node-5", tikvNode, corev1.Taint{Key: "dedicated", Value: "tikv", Effect: corev1.TaintEffectNoSchedule})
+
+	// only node-3 is schedulable for TiKV as the taints are not tolerated
+	g.Expect(checker.Check(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	event := <-recorder.Events
+	g.Expect(event).To(ContainSubstring(InsufficientNodes))
+	g.Expect(event).To(ContainSubstring("3 replicas of tikv exceed 1 schedulable nodes"))
+	// the event is not emitted again until the number changes
+	g.Expect(checker.Check(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	tc.Spec.TiKV.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "tikv"}}
+	g.Expect(checker.Check(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	tc.Spec.PD.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "dedicated", Operator: corev1.NodeSelectorOpDoesNotExist}},
+				}},
+			},
+		},
+	}
+	g.Expect(checker.Check(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring("3 replicas of pd exceed 2 schedulable nodes"))
+}
@@ -0,0 +1,302 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/sqlprobe"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// sqlProbeStatusTimeout is the max time reading the status of the SQL probe takes
+	sqlProbeStatusTimeout = 5 * time.Second
+)
+
+// sqlProbeStatusResult is the result of reading the status of the SQL probe
+type sqlProbeStatusResult struct {
+	status *sqlprobe.Status
+	err    error
+}
+
+type sqlProbeManager struct {
+	deps *controller.Dependencies
+	// getStatus reads the status of the SQL probe of the cluster
+	getStatus func(tc *v1alpha1.TidbCluster) (*sqlprobe.Status, error)
+	// goFn runs the reading of the status in the background
+	goFn func(fn func())
+	// statuses is the last sqlProbeStatusResult of the clusters, keyed by namespace/name
+	statuses sync.Map
+	// reading is the clusters whose status is being read
+	reading sync.Map
+}
+
+// NewSQLProbeManager returns a manager which runs the SQL probe of spec.sqlProbe in a Deployment,
+// and sets the SQLProbeHealthy condition by the status of the probe. The Deployment and the
+// condition are removed with spec.sqlProbe.
+func NewSQLProbeManager(deps *controller.Dependencies) manager.Manager {
+	httpClient := &http.Client{Timeout: sqlProbeStatusTimeout}
+	return &sqlProbeManager{
+		deps: deps,
+		goFn: func(fn func()) { go fn() },
+		getStatus: func(tc *v1alpha1.TidbCluster) (*sqlprobe.Status, error) {
+			url := fmt.Sprintf("http://%s.%s:%d%s", controller.SQLProbeMemberName(tc.Name), tc.Namespace, sqlprobe.Port, sqlprobe.StatusPath)
+			return sqlprobe.GetStatus(context.TODO(), httpClient, url)
+		},
+	}
+}
+
+func (m *sqlProbeManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.SQLProbe == nil || tc.Spec.TiDB == nil {
+		utiltidbcluster.RemoveTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterSQLProbeHealthy)
+		return m.cleanup(tc)
+	}
+
+	deploy, err := m.deps.TypedControl.CreateOrUpdateDeployment(tc, m.getSQLProbeDeployment(tc))
+	if err != nil {
+		return controller.RequeueErrorf("error creating or updating SQL probe deployment: %v", err)
+	}
+	if _, err := m.deps.TypedControl.CreateOrUpdateService(tc, getSQLProbeService(tc, deploy)); err != nil {
+		return controller.RequeueErrorf("error creating or updating SQL probe service: %v", err)
+	}
+
+	m.syncCondition(tc, deploy)
+	return nil
+}
+
+// syncCondition sets the SQLProbeHealthy condition by the status of the probe
+func (m *sqlProbeManager) syncCondition(tc *v1alpha1.TidbCluster, deploy *appsv1.Deployment) {
+	var cond *v1alpha1.TidbClusterCondition
+	if deploy.Status.AvailableReplicas == 0 {
+		cond = utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterSQLProbeHealthy, corev1.ConditionUnknown,
+			utiltidbcluster.SQLProbeUnknown, "the SQL probe is not available")
+	} else if result := m.readStatus(tc); result == nil {
+		cond = utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterSQLProbeHealthy, corev1.ConditionUnknown,
+			utiltidbcluster.SQLProbeUnknown, "the status of the SQL probe has not been read")
+	} else if status, err := result.status, result.err; err != nil {
+		cond = utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterSQLProbeHealthy, corev1.ConditionUnknown,
+			utiltidbcluster.SQLProbeUnknown, fmt.Sprintf("failed to read the status of the SQL probe: %v", err))
+	} else if status.LastProbeTime == nil {
+		cond = utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterSQLProbeHealthy, corev1.ConditionUnknown,
+			utiltidbcluster.SQLProbeUnknown, "the SQL probe has not run")
+	} else if status.Healthy {
+		cond = utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterSQLProbeHealthy, corev1.ConditionTrue,
+			utiltidbcluster.SQLProbeSucceeded, "")
+	} else {
+		cond = utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterSQLProbeHealthy, corev1.ConditionFalse,
+			utiltidbcluster.SQLProbeFailed, fmt.Sprintf("the SQL probe failed at step %s: %s", status.FailedStep, status.LastError))
+	}
+
+	old := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterSQLProbeHealthy)
+	if old == nil || old.Status != cond.Status {
+		switch cond.Status {
+		case corev1.ConditionFalse:
+			m.deps.Recorder.Event(tc, corev1.EventTypeWarning, utiltidbcluster.SQLProbeFailed, cond.Message)
+		case corev1.ConditionTrue:
+			if old != nil && old.Status == corev1.ConditionFalse {
+				m.deps.Recorder.Event(tc, corev1.EventTypeNormal, utiltidbcluster.SQLProbeSucceeded, "the SQL probe succeeds again")
+			}
+		}
+	}
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+}
+
+// readStatus starts reading the status of the probe in the background unless it's being read,
+// and returns the last status read, so that an unreachable probe doesn't block the sync of the
+// cluster. The status read is picked up by a later sync. nil is returned if none is read yet.
+func (m *sqlProbeManager) readStatus(tc *v1alpha1.TidbCluster) *sqlProbeStatusResult {
+	key := fmt.Sprintf("%s/%s", tc.GetNamespace(), tc.GetName())
+	if _, loaded := m.reading.LoadOrStore(key, true); !loaded {
+		tc := tc.DeepCopy()
+		m.goFn(func() {
+			defer m.reading.Delete(key)
+			status, err := m.getStatus(tc)
+			m.statuses.Store(key, &sqlProbeStatusResult{status: status, err: err})
+		})
+	}
+	if result, ok := m.statuses.Load(key); ok {
+		return result.(*sqlProbeStatusResult)
+	}
+	return nil
+}
+
+func (m *sqlProbeManager) cleanup(tc *v1alpha1.TidbCluster) error {
+	m.statuses.Delete(fmt.Sprintf("%s/%s", tc.GetNamespace(), tc.GetName()))
+	meta, _ := getSQLProbeMeta(tc)
+	for _, obj := range []client.Object{
+		&appsv1.Deployment{ObjectMeta: meta},
+		&corev1.Service{ObjectMeta: meta},
+	} {
+		exist, err := m.deps.TypedControl.Exist(client.ObjectKey{Namespace: meta.Namespace, Name: meta.Name}, obj.DeepCopyObject().(client.Object))
+		if err != nil {
+			return fmt.Errorf("failed to check SQL probe %T %s/%s: %v", obj, meta.Namespace, meta.Name, err)
+		}
+		if !exist {
+			continue
+		}
+		if err := m.deps.TypedControl.Delete(tc, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getSQLProbeMeta(tc *v1alpha1.TidbCluster) (metav1.ObjectMeta, label.Label) {
+	probeLabel := label.New().Instance(tc.GetInstanceName()).SQLProbe()
+	return metav1.ObjectMeta{
+		Name:            controller.SQLProbeMemberName(tc.Name),
+		Namespace:       tc.Namespace,
+		Labels:          probeLabel,
+		OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+	}, probeLabel
+}
+
+func getSQLProbeService(tc *v1alpha1.TidbCluster, deploy *appsv1.Deployment) *corev1.Service {
+	meta, _ := getSQLProbeMeta(tc)
+	return &corev1.Service{
+		ObjectMeta: meta,
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "metrics",
+					Port:       sqlprobe.Port,
+					TargetPort: intstr.FromInt(sqlprobe.Port),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Selector: deploy.Spec.Template.Labels,
+		},
+	}
+}
+
+func (m *sqlProbeManager) getSQLProbeDeployment(tc *v1alpha1.TidbCluster) *appsv1.Deployment {
+	spec := tc.Spec.SQLProbe
+	meta, l := getSQLProbeMeta(tc)
+
+	args := []string{
+		fmt.Sprintf("--host=%s.%s.svc", controller.TiDBMemberName(tc.Name), tc.Namespace),
+		fmt.Sprintf("--tidb-port=%d", tc.Spec.TiDB.GetServicePort()),
+		fmt.Sprintf("--database=%s", tc.SQLProbeDatabase()),
+		fmt.Sprintf("--interval=%s", tc.SQLProbeInterval()),
+		fmt.Sprintf("--timeout=%s", tc.SQLProbeTimeout()),
+		fmt.Sprintf("--failure-threshold=%d", tc.SQLProbeFailureThreshold()),
+	}
+	envs := []corev1.EnvVar{
+		{
+			Name:  "TZ",
+			Value: tc.Timezone(),
+		},
+	}
+	if spec.PasswordSecret != nil {
+		for _, env := range []struct{ name, key string }{
+			{"TIDB_USER", "user"},
+			{"TIDB_PASSWORD", "password"},
+		} {
+			envs = append(envs, corev1.EnvVar{
+				Name: env.name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: *spec.PasswordSecret},
+						Key:                  env.key,
+					},
+				},
+			})
+		}
+	}
+
+	container := corev1.Container{
+		Name:            "sql-probe",
+		Image:           m.deps.CLIConfig.TiDBDiscoveryImage,
+		ImagePullPolicy: tc.Spec.ImagePullPolicy,
+		Command:         []string{"/usr/local/bin/tidb-sql-probe"},
+		Args:            args,
+		Env:             envs,
+		Resources:       controller.ContainerResource(spec.ResourceRequirements),
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "metrics",
+				Protocol:      corev1.ProtocolTCP,
+				ContainerPort: sqlprobe.Port,
+			},
+		},
+	}
+	podSpec := corev1.PodSpec{
+		ImagePullSecrets: tc.Spec.ImagePullSecrets,
+	}
+	if tc.Spec.TiDB.IsTLSClientEnabled() && !tc.SkipTLSWhenConnectTiDB() {
+		container.Args = append(container.Args, "--tls=true")
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name: "tidb-client-tls", ReadOnly: true, MountPath: util.TiDBClientTLSPath,
+		})
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "tidb-client-tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.TiDBClientTLSSecretName(tc.Name),
+				},
+			},
+		})
+	}
+	podSpec.Containers = []corev1.Container{container}
+
+	return &appsv1.Deployment{
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Replicas: pointer.Int32Ptr(1),
+			Selector: l.LabelSelector(),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      l.Labels(),
+					Annotations: controller.AnnProm(sqlprobe.Port),
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+}
+
+type FakeSQLProbeManager struct {
+	err error
+}
+
+func NewFakeSQLProbeManager() *FakeSQLProbeManager {
+	return &FakeSQLProbeManager{}
+}
+
+func (m *FakeSQLProbeManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeSQLProbeManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
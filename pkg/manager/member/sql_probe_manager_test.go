@@ -0,0 +1,123 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/sqlprobe"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSQLProbeManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	ctrl := deps.GenericControl.(*controller.FakeGenericControl)
+	recorder := deps.Recorder.(*record.FakeRecorder)
+	m := NewSQLProbeManager(deps).(*sqlProbeManager)
+	var status *sqlprobe.Status
+	var statusErr error
+	m.getStatus = func(_ *v1alpha1.TidbCluster) (*sqlprobe.Status, error) {
+		return status, statusErr
+	}
+	var reads []func()
+	m.goFn = func(fn func()) {
+		reads = append(reads, fn)
+	}
+	// readAll runs the pending reads of the status, so the next sync picks up their results
+	readAll := func() {
+		for _, fn := range reads {
+			fn()
+		}
+		reads = nil
+	}
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.SQLProbe = &v1alpha1.SQLProbeSpec{PasswordSecret: pointer.StringPtr("probe-secret")}
+	key := client.ObjectKey{Namespace: tc.Namespace, Name: "test-sql-probe"}
+	condition := func() *v1alpha1.TidbClusterCondition {
+		return utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterSQLProbeHealthy)
+	}
+
+	// the status is unknown until the probe is available
+	g.Expect(m.Sync(tc)).To(Succeed())
+	deploy := &appsv1.Deployment{}
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), key, deploy)).To(Succeed())
+	container := deploy.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Command).To(Equal([]string{"/usr/local/bin/tidb-sql-probe"}))
+	g.Expect(container.Args).To(ContainElement("--host=test-tidb.default.svc"))
+	g.Expect(container.Args).To(ContainElement("--failure-threshold=3"))
+	g.Expect(container.Env[len(container.Env)-1].ValueFrom.SecretKeyRef.Name).To(Equal("probe-secret"))
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), key, &corev1.Service{})).To(Succeed())
+	g.Expect(condition().Status).To(Equal(corev1.ConditionUnknown))
+
+	deploy.Status.AvailableReplicas = 1
+	g.Expect(ctrl.FakeCli.Update(context.TODO(), deploy)).To(Succeed())
+	statusErr = fmt.Errorf("connection refused")
+	// the status is read in the background, the sync doesn't wait for it
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(condition().Message).To(Equal("the status of the SQL probe has not been read"))
+	g.Expect(reads).To(HaveLen(1))
+	// no more reads are started while one is running
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(reads).To(HaveLen(1))
+	readAll()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(condition().Status).To(Equal(corev1.ConditionUnknown))
+	g.Expect(condition().Message).To(ContainSubstring("connection refused"))
+
+	// the probe is healthy
+	now := time.Now()
+	statusErr = nil
+	status = &sqlprobe.Status{Healthy: true, LastProbeTime: &now}
+	readAll()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(condition().Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// the probe fails
+	status = &sqlprobe.Status{ConsecutiveFailures: 3, LastProbeTime: &now, FailedStep: sqlprobe.StepWrite, LastError: "region is unavailable"}
+	readAll()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(condition().Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition().Reason).To(Equal(utiltidbcluster.SQLProbeFailed))
+	g.Expect(condition().Message).To(Equal("the SQL probe failed at step write: region is unavailable"))
+	g.Expect(<-recorder.Events).To(ContainSubstring("Warning SQLProbeFailed"))
+
+	// the probe recovers
+	status = &sqlprobe.Status{Healthy: true, LastProbeTime: &now}
+	readAll()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(condition().Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(<-recorder.Events).To(ContainSubstring("Normal SQLProbeSucceeded"))
+
+	// the probe and the condition are removed with spec.sqlProbe
+	tc.Spec.SQLProbe = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), key, &appsv1.Deployment{})).NotTo(Succeed())
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), key, &corev1.Service{})).NotTo(Succeed())
+	g.Expect(condition()).To(BeNil())
+}
@@ -22,6 +22,11 @@ import (
 type CommonModel struct {
 	AcrossK8s     bool   // same as tc.spec.acrossK8s
 	ClusterDomain string // same as tc.spec.clusterDomain
+
+	BlockingPDVerification bool // whether the PD endpoints are verified by a blocking request to the discovery service
+
+	PreStartHook      string // path of the preStart hook in spec.<component>.startScriptHooks
+	PostDiscoveryHook string // path of the postDiscovery hook in spec.<component>.startScriptHooks
 }
 
 func (c CommonModel) FormatClusterDomain() string {
@@ -31,6 +36,15 @@ func (c CommonModel) FormatClusterDomain() string {
 	return ""
 }
 
+// VerifyPDEndpointRequest returns the wget arguments to verify the PD endpoints with the discovery service,
+// the blocking request is only used if it's enabled as changing it causes a rolling-update
+func (c CommonModel) VerifyPDEndpointRequest() string {
+	if c.BlockingPDVerification {
+		return `-T 40 "http://${discovery_url}/verify/${encoded_domain_url}?timeout=30s"`
+	}
+	return "-T 3 http://${discovery_url}/verify/${encoded_domain_url}"
+}
+
 // TODO(aylei): it is hard to maintain script in go literal, we should figure out a better solution
 // tidbStartScriptTpl is the template string of tidb start script
 // Note: changing this will cause a rolling-update of tidb-servers
@@ -58,14 +72,16 @@ if [[ X${runmode} == Xdebug ]]
 then
     echo "entering debug mode."
     tail -f /dev/null
-fi
+fi{{ if .PreStartHook }}
+
+source {{ .PreStartHook }}{{ end }}
 
 # Use HOSTNAME if POD_NAME is unset for backward compatibility.
 POD_NAME=${POD_NAME:-$HOSTNAME}{{ if .AcrossK8s }}
 pd_url="{{ .Path }}"
 encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
 discovery_url="${CLUSTER_NAME}-discovery.${NAMESPACE}:10261"
-until result=$(wget -qO- -T 3 http://${discovery_url}/verify/${encoded_domain_url} 2>/dev/null | sed 's/http:\/\///g'); do
+until result=$(wget -qO- {{ .VerifyPDEndpointRequest }} 2>/dev/null | sed 's/http:\/\///g'); do
 echo "waiting for the verification of PD endpoints ..."
 sleep $((RANDOM % 5))
 done
@@ -95,7 +111,9 @@ fi
 
 {{- if .EnablePlugin }}
 ARGS="${ARGS}  --plugin-dir  {{ .PluginDirectory  }} --plugin-load {{ .PluginList }}  "
-{{- end }}
+{{- end }}{{ if .PostDiscoveryHook }}
+
+source {{ .PostDiscoveryHook }}{{ end }}
 
 echo "start tidb-server ..."
 echo "/tidb-server ${ARGS}"
@@ -143,7 +161,9 @@ if [[ X${runmode} == Xdebug ]]
 then
     echo "entering debug mode."
     tail -f /dev/null
-fi
+fi{{ if .PreStartHook }}
+
+source {{ .PreStartHook }}{{ end }}
 
 # Use HOSTNAME if POD_NAME is unset for backward compatibility.
 POD_NAME=${POD_NAME:-$HOSTNAME}
@@ -201,7 +221,9 @@ echo "waiting for discovery service to return start args ..."
 sleep $((RANDOM % 5))
 done
 ARGS="${ARGS}${result}"
-fi
+fi{{ if .PostDiscoveryHook }}
+
+source {{ .PostDiscoveryHook }}{{ end }}
 
 echo "starting pd-server ..."
 sleep $((RANDOM % 10))
@@ -265,7 +287,9 @@ if [[ X${runmode} == Xdebug ]]
 then
 	echo "entering debug mode."
 	tail -f /dev/null
-fi
+fi{{ if .PreStartHook }}
+
+source {{ .PreStartHook }}{{ end }}
 
 # Use HOSTNAME if POD_NAME is unset for backward compatibility.
 POD_NAME=${POD_NAME:-$HOSTNAME}{{ if .AcrossK8s }}
@@ -273,7 +297,7 @@ pd_url="{{ .PDAddress }}"
 encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
 discovery_url="${CLUSTER_NAME}-discovery.${NAMESPACE}:10261"
 
-until result=$(wget -qO- -T 3 http://${discovery_url}/verify/${encoded_domain_url} 2>/dev/null); do
+until result=$(wget -qO- {{ .VerifyPDEndpointRequest }} 2>/dev/null); do
 echo "waiting for the verification of PD endpoints ..."
 sleep $((RANDOM % 5))
 done
@@ -293,7 +317,9 @@ ARGS="--pd={{ .PDAddress }} \{{ end }}
 if [ ! -z "${STORE_LABELS:-}" ]; then
   LABELS=" --labels ${STORE_LABELS} "
   ARGS="${ARGS}${LABELS}"
-fi
+fi{{ if .PostDiscoveryHook }}
+
+source {{ .PostDiscoveryHook }}{{ end }}
 
 echo "starting tikv-server ..."
 echo "/tikv-server ${ARGS}"
@@ -313,13 +339,45 @@ func RenderTiKVStartScript(model *TiKVStartScriptModel) (string, error) {
 	return renderTemplateFunc(tikvStartScriptTpl, model)
 }
 
+// tiflashInitScriptTpl is the script of the TiFlash init container, which renders the
+// config files and verifies the PD endpoints with the discovery service when across Kubernetes
+// Note: changing this will cause a rolling-update of TiFlash cluster
+var tiflashInitScriptTpl = template.Must(template.New("tiflash-init-script").Parse(`{{ if .PreStartHook }}source {{ .PreStartHook }}
+{{ end }}set -ex;ordinal=` + "`" + `echo ${POD_NAME} | awk -F- '{print $NF}'` + "`" + `;sed s/POD_NUM/${ordinal}/g /etc/tiflash/config_templ.toml > /data0/config.toml;sed s/POD_NUM/${ordinal}/g /etc/tiflash/proxy_templ.toml > /data0/proxy.toml{{ if .AcrossK8s }}
+pd_url="{{ .PDAddr }}"
+set +e
+encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
+discovery_url="{{ .ClusterName }}-discovery.{{ .Namespace }}:10261"
+until result=$(wget -qO- {{ .VerifyPDEndpointRequest }} 2>/dev/null | sed 's/http:\/\///g' | sed 's/https:\/\///g'); do
+echo "waiting for the verification of PD endpoints ..."
+sleep 2
+done
+
+
+sed -i s/PD_ADDR/${result}/g /data0/config.toml
+sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
+{{ end }}{{ if .PostDiscoveryHook }}
+source {{ .PostDiscoveryHook }}{{ end }}`))
+
+type TiFlashInitScriptModel struct {
+	CommonModel
+
+	PDAddr      string
+	ClusterName string
+	Namespace   string
+}
+
+func RenderTiFlashInitScript(model *TiFlashInitScriptModel) (string, error) {
+	return renderTemplateFunc(tiflashInitScriptTpl, model)
+}
+
 // pumpStartScriptTpl is the template string of pump start script
 // Note: changing this will cause a rolling-update of pump cluster
 var pumpStartScriptTpl = template.Must(template.New("pump-start-script").Parse(`{{ if .AcrossK8s }}
 pd_url="{{ .PDAddr }}"
 encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
 discovery_url="{{ .ClusterName }}-discovery.{{ .Namespace }}:10261"
-until result=$(wget -qO- -T 3 http://${discovery_url}/verify/${encoded_domain_url} 2>/dev/null); do
+until result=$(wget -qO- {{ .VerifyPDEndpointRequest }} 2>/dev/null); do
 echo "waiting for the verification of PD endpoints ..."
 sleep $((RANDOM % 5))
 done
@@ -15,6 +15,7 @@ package member
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -1183,6 +1184,127 @@ exec /pd-server ${ARGS}
 	}
 }
 
+func TestRenderTiFlashInitScript(t *testing.T) {
+	tests := []struct {
+		name      string
+		acrossK8s bool
+		blocking  bool
+		preStart  string
+		postDisc  string
+		result    string
+	}{
+		{
+			name: "basic",
+			result: "set -ex;ordinal=`echo ${POD_NAME} | awk -F- '{print $NF}'`;sed s/POD_NUM/${ordinal}/g /etc/tiflash/config_templ.toml > /data0/config.toml;" +
+				"sed s/POD_NUM/${ordinal}/g /etc/tiflash/proxy_templ.toml > /data0/proxy.toml",
+		},
+		{
+			name:      "across k8s",
+			acrossK8s: true,
+			result: "set -ex;ordinal=`echo ${POD_NAME} | awk -F- '{print $NF}'`;sed s/POD_NUM/${ordinal}/g /etc/tiflash/config_templ.toml > /data0/config.toml;" +
+				"sed s/POD_NUM/${ordinal}/g /etc/tiflash/proxy_templ.toml > /data0/proxy.toml" + `
+pd_url="https://demo-pd:2379"
+set +e
+encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
+discovery_url="demo-discovery.demo-ns:10261"
+until result=$(wget -qO- -T 3 http://${discovery_url}/verify/${encoded_domain_url} 2>/dev/null | sed 's/http:\/\///g' | sed 's/https:\/\///g'); do
+echo "waiting for the verification of PD endpoints ..."
+sleep 2
+done
+
+
+sed -i s/PD_ADDR/${result}/g /data0/config.toml
+sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
+`,
+		},
+		{
+			name:      "across k8s with blocking verification",
+			acrossK8s: true,
+			blocking:  true,
+			result: "set -ex;ordinal=`echo ${POD_NAME} | awk -F- '{print $NF}'`;sed s/POD_NUM/${ordinal}/g /etc/tiflash/config_templ.toml > /data0/config.toml;" +
+				"sed s/POD_NUM/${ordinal}/g /etc/tiflash/proxy_templ.toml > /data0/proxy.toml" + `
+pd_url="https://demo-pd:2379"
+set +e
+encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
+discovery_url="demo-discovery.demo-ns:10261"
+until result=$(wget -qO- -T 40 "http://${discovery_url}/verify/${encoded_domain_url}?timeout=30s" 2>/dev/null | sed 's/http:\/\///g' | sed 's/https:\/\///g'); do
+echo "waiting for the verification of PD endpoints ..."
+sleep 2
+done
+
+
+sed -i s/PD_ADDR/${result}/g /data0/config.toml
+sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
+`,
+		},
+		{
+			name:     "with hooks",
+			preStart: "/etc/startup-script-hooks/pre_start.sh",
+			postDisc: "/etc/startup-script-hooks/post_discovery.sh",
+			result: "source /etc/startup-script-hooks/pre_start.sh\n" +
+				"set -ex;ordinal=`echo ${POD_NAME} | awk -F- '{print $NF}'`;sed s/POD_NUM/${ordinal}/g /etc/tiflash/config_templ.toml > /data0/config.toml;" +
+				"sed s/POD_NUM/${ordinal}/g /etc/tiflash/proxy_templ.toml > /data0/proxy.toml\n" +
+				"source /etc/startup-script-hooks/post_discovery.sh",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := TiFlashInitScriptModel{
+				CommonModel: CommonModel{
+					AcrossK8s:              tt.acrossK8s,
+					BlockingPDVerification: tt.blocking,
+					PreStartHook:           tt.preStart,
+					PostDiscoveryHook:      tt.postDisc,
+				},
+				PDAddr:      "https://demo-pd:2379",
+				ClusterName: "demo",
+				Namespace:   "demo-ns",
+			}
+			script, err := RenderTiFlashInitScript(&model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.result, script); diff != "" {
+				t.Errorf("unexpected (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRenderStartScriptHooks(t *testing.T) {
+	hooks := CommonModel{
+		PreStartHook:      "/etc/startup-script-hooks/pre_start.sh",
+		PostDiscoveryHook: "/etc/startup-script-hooks/post_discovery.sh",
+	}
+	render := map[string]func() (string, error){
+		"pd": func() (string, error) {
+			return RenderPDStartScript(&PDStartScriptModel{CommonModel: hooks, Scheme: "http", DataDir: "/var/lib/pd"})
+		},
+		"tikv": func() (string, error) {
+			return RenderTiKVStartScript(&TiKVStartScriptModel{CommonModel: hooks, PDAddress: "http://${CLUSTER_NAME}-pd:2379", DataDir: "/var/lib/tikv"})
+		},
+		"tidb": func() (string, error) {
+			return RenderTiDBStartScript(&TidbStartScriptModel{CommonModel: hooks, Path: "${CLUSTER_NAME}-pd:2379"})
+		},
+	}
+
+	for name, f := range render {
+		t.Run(name, func(t *testing.T) {
+			script, err := f()
+			if err != nil {
+				t.Fatal(err)
+			}
+			preStart := strings.Index(script, "\n\nsource /etc/startup-script-hooks/pre_start.sh\n\n# Use HOSTNAME")
+			postDiscovery := strings.Index(script, "\n\nsource /etc/startup-script-hooks/post_discovery.sh\n\necho \"start")
+			start := strings.Index(script, "exec /")
+			if preStart < 0 || postDiscovery < 0 || !(preStart < postDiscovery && postDiscovery < start) {
+				t.Errorf("unexpected hook points in the script: %s", script)
+			}
+		})
+	}
+}
+
 func TestRenderPumpStartScript(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -0,0 +1,175 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// syncChangefeeds creates the changefeeds of spec.ticdc.changefeeds and updates them when their
+// rendered sink URIs are changed, e.g. the referenced Secrets are rotated. The failures are
+// reported in the status of the changefeeds and don't block the sync of the cluster.
+func (m *ticdcMemberManager) syncChangefeeds(tc *v1alpha1.TidbCluster) {
+	if len(tc.Spec.TiCDC.Changefeeds) == 0 {
+		tc.Status.TiCDC.Changefeeds = nil
+		return
+	}
+	if tc.Spec.Paused {
+		return
+	}
+	ordinal, ok := readyCaptureOrdinal(tc)
+	if !ok {
+		klog.Infof("TidbCluster: [%s/%s], no TiCDC capture is ready, skip syncing the changefeeds", tc.GetNamespace(), tc.GetName())
+		return
+	}
+
+	statuses := make(map[string]v1alpha1.TiCDCChangefeedStatus, len(tc.Spec.TiCDC.Changefeeds))
+	for _, changefeed := range tc.Spec.TiCDC.Changefeeds {
+		status := tc.Status.TiCDC.Changefeeds[changefeed.ID]
+		m.syncChangefeed(tc, ordinal, changefeed, &status)
+		statuses[changefeed.ID] = status
+	}
+	tc.Status.TiCDC.Changefeeds = statuses
+}
+
+func (m *ticdcMemberManager) syncChangefeed(tc *v1alpha1.TidbCluster, ordinal int32, changefeed v1alpha1.TiCDCChangefeed, status *v1alpha1.TiCDCChangefeedStatus) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	status.SinkURI = v1alpha1.MaskSinkURI(changefeed.Sink.URI)
+	// the message is only reported when it's changed, so that the events are not flooded
+	fail := func(reason, msg string) {
+		if msg != status.Message {
+			klog.Warningf("TidbCluster: [%s/%s], failed to sync changefeed %s: %s", ns, tcName, changefeed.ID, msg)
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, reason, "changefeed %s: %s", changefeed.ID, msg)
+		}
+		status.Message = msg
+	}
+
+	sinkURI, passwords, err := m.renderSinkURI(ns, changefeed.Sink)
+	if err != nil {
+		fail("SinkSecretNotReady", err.Error())
+		return
+	}
+	digest := fmt.Sprintf("%x", sha256.Sum256([]byte(sinkURI)))
+	if digest == status.SinkCredentialsDigest {
+		status.Message = ""
+		return
+	}
+
+	existing, err := m.deps.CDCControl.GetChangefeed(tc, ordinal, changefeed.ID)
+	if err != nil {
+		fail("FailedSyncChangefeed", maskPasswords(err.Error(), passwords))
+		return
+	}
+	if existing == nil {
+		if err := m.deps.CDCControl.CreateChangefeed(tc, ordinal, changefeed.ID, sinkURI); err != nil {
+			fail("FailedSyncChangefeed", maskPasswords(err.Error(), passwords))
+			return
+		}
+		klog.Infof("TidbCluster: [%s/%s], changefeed %s is created with sink %s", ns, tcName, changefeed.ID, status.SinkURI)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "ChangefeedCreated", "changefeed %s is created with sink %s", changefeed.ID, status.SinkURI)
+	} else {
+		if err := m.deps.CDCControl.UpdateChangefeedSink(tc, ordinal, changefeed.ID, sinkURI); err != nil {
+			fail("FailedSyncChangefeed", maskPasswords(err.Error(), passwords))
+			return
+		}
+		klog.Infof("TidbCluster: [%s/%s], the sink of changefeed %s is updated to %s", ns, tcName, changefeed.ID, status.SinkURI)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "SinkCredentialsUpdated", "the sink of changefeed %s is updated to %s", changefeed.ID, status.SinkURI)
+	}
+	status.SinkCredentialsDigest = digest
+	status.Message = ""
+}
+
+// renderSinkURI returns the sink URI with the credentials read from the Secret and the passwords
+// rendered, which must be masked in the errors
+func (m *ticdcMemberManager) renderSinkURI(ns string, sink v1alpha1.ChangefeedSink) (string, []string, error) {
+	ref := sink.CredentialsSecret
+	if ref == nil {
+		return sink.URI, nil, nil
+	}
+	u, err := url.Parse(sink.URI)
+	if err != nil {
+		// the error of url.Parse contains the URI
+		return "", nil, fmt.Errorf("invalid sink URI %s", v1alpha1.MaskSinkURI(sink.URI))
+	}
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(ref.Name)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get secret %s: %v", ref.Name, err)
+	}
+	user, ok := secret.Data[ref.GetUserKey()]
+	if !ok {
+		return "", nil, fmt.Errorf("key %s is not found in secret %s", ref.GetUserKey(), ref.Name)
+	}
+	password, ok := secret.Data[ref.GetPasswordKey()]
+	if !ok {
+		return "", nil, fmt.Errorf("key %s is not found in secret %s", ref.GetPasswordKey(), ref.Name)
+	}
+
+	switch u.Scheme {
+	case "mysql", "tidb":
+		u.User = url.UserPassword(string(user), string(password))
+	case "kafka":
+		query := u.Query()
+		query.Set("sasl-user", string(user))
+		query.Set("sasl-password", string(password))
+		u.RawQuery = query.Encode()
+	default:
+		return "", nil, fmt.Errorf("credentials are not supported by the sink scheme %s", u.Scheme)
+	}
+	return u.String(), []string{string(password)}, nil
+}
+
+// maskPasswords replaces the passwords and their escaped forms in msg, e.g. the sink URIs echoed
+// by the errors of TiCDC
+func maskPasswords(msg string, passwords []string) string {
+	for _, password := range passwords {
+		if password == "" {
+			continue
+		}
+		for _, s := range []string{password, url.QueryEscape(password), url.PathEscape(password)} {
+			msg = strings.ReplaceAll(msg, s, v1alpha1.MaskedSinkPassword)
+		}
+	}
+	return msg
+}
+
+// readyCaptureOrdinal returns the least ordinal of the ready captures, the open API of TiCDC can
+// be requested from any capture
+func readyCaptureOrdinal(tc *v1alpha1.TidbCluster) (int32, bool) {
+	var ordinal int32
+	found := false
+	for podName, capture := range tc.Status.TiCDC.Captures {
+		if !capture.Ready || capture.GracefulShutdown != nil {
+			continue
+		}
+		o, err := util.GetOrdinalFromPodName(podName)
+		if err != nil {
+			continue
+		}
+		if !found || o < ordinal {
+			ordinal = o
+			found = true
+		}
+	}
+	return ordinal, found
+}
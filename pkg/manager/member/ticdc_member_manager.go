@@ -26,6 +26,7 @@ import (
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/cmpver"
 
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	apps "k8s.io/api/apps/v1"
@@ -44,12 +45,18 @@ const (
 	ticdcCertVolumeMount = "ticdc-tls"
 )
 
+var (
+	// the first version that TiCDC stores the sort files in the data dir rather than the sort dir
+	ticdcEqualOrGreaterThanV600, _ = cmpver.NewConstraint(cmpver.GreaterOrEqual, "v6.0.0")
+)
+
 // ticdcMemberManager implements manager.Manager.
 type ticdcMemberManager struct {
 	deps                     *controller.Dependencies
 	scaler                   Scaler
 	ticdcUpgrader            Upgrader
 	suspender                suspender.Suspender
+	remover                  ComponentRemover
 	statefulSetIsUpgradingFn func(corelisters.PodLister, pdapi.PDControlInterface, *apps.StatefulSet, *v1alpha1.TidbCluster) (bool, error)
 }
 
@@ -93,6 +100,7 @@ func NewTiCDCMemberManager(deps *controller.Dependencies, scaler Scaler, ticdcUp
 		scaler:        scaler,
 		ticdcUpgrader: ticdcUpgrader,
 		suspender:     spder,
+		remover:       NewComponentRemover(deps),
 	}
 	m.statefulSetIsUpgradingFn = ticdcStatefulSetIsUpgrading
 	return m
@@ -126,6 +134,9 @@ func (m *ticdcMemberManager) syncTiCDCConfigMap(tc *v1alpha1.TidbCluster, set *a
 
 // Sync fulfills the manager.Manager interface
 func (m *ticdcMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.ComponentRemoving(v1alpha1.TiCDCMemberType) {
+		return m.remover.Remove(tc, v1alpha1.TiCDCMemberType)
+	}
 	if tc.Spec.TiCDC == nil {
 		return nil
 	}
@@ -158,7 +169,12 @@ func (m *ticdcMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
-	return m.syncStatefulSet(tc)
+	if err := m.syncStatefulSet(tc); err != nil {
+		return err
+	}
+
+	m.syncChangefeeds(tc)
+	return nil
 }
 
 func (m *ticdcMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
@@ -239,7 +255,9 @@ func (m *ticdcMemberManager) syncTiCDCStatus(tc *v1alpha1.TidbCluster, sts *apps
 		tc.Status.TiCDC.Synced = false
 		return err
 	}
-	if upgrading {
+	if tc.TiCDCDeployDesiredReplicas() != *sts.Spec.Replicas {
+		tc.Status.TiCDC.Phase = v1alpha1.ScalePhase
+	} else if upgrading {
 		tc.Status.TiCDC.Phase = v1alpha1.UpgradePhase
 	} else {
 		tc.Status.TiCDC.Phase = v1alpha1.NormalPhase
@@ -250,7 +268,7 @@ func (m *ticdcMemberManager) syncTiCDCStatus(tc *v1alpha1.TidbCluster, sts *apps
 	for id := range helper.GetPodOrdinals(tc.Status.TiCDC.StatefulSet.Replicas, sts) {
 		podName := fmt.Sprintf("%s-%d", controller.TiCDCMemberName(tc.GetName()), id)
 
-		_, err := m.deps.PodLister.Pods(tc.GetNamespace()).Get(podName)
+		pod, err := m.deps.PodLister.Pods(tc.GetNamespace()).Get(podName)
 		if err != nil {
 			klog.Warningf("Failed to get Pod %s of [%s/%s], error: %v", podName, ns, tcName, err)
 			continue
@@ -270,6 +288,12 @@ func (m *ticdcMemberManager) syncTiCDCStatus(tc *v1alpha1.TidbCluster, sts *apps
 			capture.IsOwner = status.IsOwner
 			capture.Ready = true
 		}
+		// keep the progress until the graceful shutdown of the pod is finished
+		if _, ok := pod.Annotations[label.AnnTiCDCGracefulShutdownBeginTime]; ok {
+			if prev, ok := tc.Status.TiCDC.Captures[podName]; ok && prev.GracefulShutdown != nil {
+				capture.GracefulShutdown = prev.GracefulShutdown
+			}
+		}
 
 		ticdcCaptures[podName] = capture
 	}
@@ -431,6 +455,30 @@ func getNewTiCDCStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*ap
 	volMounts = append(volMounts, storageVolMounts...)
 	volMounts = append(volMounts, tc.Spec.TiCDC.AdditionalVolumeMounts...)
 
+	sortFilesDir := ""
+	if sortDirVolumeName := tc.Spec.TiCDC.SortDirVolumeName; sortDirVolumeName != "" {
+		sortDir := ""
+		volMountName := fmt.Sprintf("%s-%s", v1alpha1.TiCDCMemberType.String(), sortDirVolumeName)
+		for _, volMount := range storageVolMounts {
+			if volMount.Name == volMountName {
+				sortDir = volMount.MountPath
+				break
+			}
+		}
+		if sortDir == "" {
+			return nil, fmt.Errorf("failed to get sortDirVolume %s for cluster %s/%s", sortDirVolumeName, ns, tcName)
+		}
+		// the sort files are put in a sub dir, so that the other files in the volume, e.g. lost+found,
+		// are kept when the sort files are purged
+		if ok, err := ticdcEqualOrGreaterThanV600.Check(tc.TiCDCVersion()); err == nil && !ok {
+			sortFilesDir = path.Join(sortDir, "sorter")
+			cmdArgs = append(cmdArgs, fmt.Sprintf("--sort-dir=%s", sortFilesDir))
+		} else {
+			sortFilesDir = path.Join(sortDir, "tmp", "sorter")
+			cmdArgs = append(cmdArgs, fmt.Sprintf("--data-dir=%s", sortDir))
+		}
+	}
+
 	var script string
 
 	if tc.AcrossK8s() {
@@ -446,14 +494,20 @@ func getNewTiCDCStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*ap
 pd_url="%s"
 encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
 discovery_url="%s-discovery.${NAMESPACE}:10261"
-until result=$(wget -qO- -T 3 http://${discovery_url}/verify/${encoded_domain_url} 2>/dev/null); do
+until result=$(wget -qO- -T 40 "http://${discovery_url}/verify/${encoded_domain_url}?timeout=30s" 2>/dev/null); do
 echo "waiting for the verification of PD endpoints ..."
 sleep 2
 done
 `
 
-		script += fmt.Sprintf(str, pdAddr, tc.GetName())
-		script += "\n" + strings.Join(append([]string{"exec"}, cmdArgs...), " ")
+		script += fmt.Sprintf(str, pdAddr, tc.GetName()) + "\n"
+	}
+	if sortFilesDir != "" {
+		// purge the sort files left by the last TiCDC server, which may exit uncleanly
+		script += fmt.Sprintf("rm -rf %s\n", sortFilesDir)
+	}
+	if script != "" {
+		script += strings.Join(append([]string{"exec"}, cmdArgs...), " ")
 	} else {
 		script = strings.Join(cmdArgs, " ")
 	}
@@ -502,6 +556,7 @@ done
 		Env:          util.AppendEnv(envs, baseTiCDCSpec.Env()),
 		EnvFrom:      baseTiCDCSpec.EnvFrom(),
 	}
+	setProbes(&ticdcContainer, tc.Spec.TiCDC.Probes, probeHandlers{port: 8301, statusPort: 8301, statusPath: "/status"})
 	if cm != nil {
 		ticdcContainer.VolumeMounts = append(ticdcContainer.VolumeMounts, corev1.VolumeMount{
 			Name: "config", ReadOnly: true, MountPath: "/etc/ticdc",
@@ -525,6 +580,8 @@ done
 	podSpec.Volumes = append(vols, baseTiCDCSpec.AdditionalVolumes()...)
 	podSpec.ServiceAccountName = tc.Spec.TiCDC.ServiceAccount
 	podSpec.InitContainers = append(podSpec.InitContainers, baseTiCDCSpec.InitContainers()...)
+	tc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.TiCDCMemberType.String())
+	applyEphemeralStorage(baseTiCDCSpec.EphemeralStorage(), &podSpec, v1alpha1.TiCDCMemberType.String())
 	if podSpec.ServiceAccountName == "" {
 		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
 	}
@@ -399,6 +399,20 @@ func TestTiCDCMemberManagerSyncTidbClusterStatus(t *testing.T) {
 				g.Expect(tc.Status.TiCDC.Phase).To(Equal(v1alpha1.NormalPhase))
 			},
 		},
+		{
+			name: "statefulset is scaling to zero",
+			updateTC: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiCDC.Replicas = 0
+			},
+			upgradingFn: func(lister corelisters.PodLister, pc pdapi.PDControlInterface, set *apps.StatefulSet, cluster *v1alpha1.TidbCluster) (bool, error) {
+				return true, nil
+			},
+			healthInfo:  map[string]bool{},
+			errExpectFn: nil,
+			tcExpectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Status.TiCDC.Phase).To(Equal(v1alpha1.ScalePhase))
+			},
+		},
 		{
 			name:     "get health empty",
 			updateTC: nil,
@@ -580,6 +594,64 @@ func TestGetNewTiCDCStatefulSet(t *testing.T) {
 			},
 			testSts: testAdditionalVolumes(t, []corev1.Volume{{Name: "test", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}),
 		},
+		{
+			name: "TiCDC sort dir volume",
+			tc: v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tc",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					Version: "v6.1.0",
+					TiCDC: &v1alpha1.TiCDCSpec{
+						BaseImage: "pingcap/ticdc",
+						StorageVolumes: []v1alpha1.StorageVolume{
+							{
+								Name:        "sort-dir",
+								StorageSize: "2Gi",
+								MountPath:   "/var/lib/sort-dir",
+							},
+						},
+						SortDirVolumeName: "sort-dir",
+					},
+				},
+			},
+			testSts: func(sts *apps.StatefulSet) {
+				g := NewGomegaWithT(t)
+				script := sts.Spec.Template.Spec.Containers[0].Command[2]
+				g.Expect(script).To(HavePrefix("rm -rf /var/lib/sort-dir/tmp/sorter\nexec /cdc server"))
+				g.Expect(script).To(ContainSubstring(" --data-dir=/var/lib/sort-dir"))
+			},
+		},
+		{
+			name: "TiCDC sort dir volume before v6.0.0",
+			tc: v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tc",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					Version: "v5.4.0",
+					TiCDC: &v1alpha1.TiCDCSpec{
+						BaseImage: "pingcap/ticdc",
+						StorageVolumes: []v1alpha1.StorageVolume{
+							{
+								Name:        "sort-dir",
+								StorageSize: "2Gi",
+								MountPath:   "/var/lib/sort-dir",
+							},
+						},
+						SortDirVolumeName: "sort-dir",
+					},
+				},
+			},
+			testSts: func(sts *apps.StatefulSet) {
+				g := NewGomegaWithT(t)
+				script := sts.Spec.Template.Spec.Containers[0].Command[2]
+				g.Expect(script).To(HavePrefix("rm -rf /var/lib/sort-dir/sorter\nexec /cdc server"))
+				g.Expect(script).To(ContainSubstring(" --sort-dir=/var/lib/sort-dir/sorter"))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -14,6 +14,7 @@
 package member
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -95,11 +96,17 @@ func (s *ticdcScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newS
 	}
 	tc, _ := meta.(*v1alpha1.TidbCluster)
 
-	err = gracefulShutdownTiCDC(tc, s.deps.CDCControl, s.deps.PodControl, pod, ordinal, "ScaleIn")
-	if err != nil {
-		return err
+	// The last capture can't be drained as there is no other capture to move its tables to,
+	// the changefeeds resume from their checkpoints once TiCDC is scaled out again.
+	if replicas == 0 {
+		klog.Infof("ticdcScaler.ScaleIn: %s is the last capture in cluster %s/%s, skip graceful shutdown", podName, meta.GetNamespace(), meta.GetName())
+	} else {
+		err = gracefulShutdownTiCDC(tc, s.deps.CDCControl, s.deps.PodControl, pod, ordinal, "ScaleIn")
+		if err != nil {
+			return err
+		}
+		klog.Infof("ticdcScaler.ScaleIn: %s has graceful shutdown in cluster %s/%s", podName, meta.GetNamespace(), meta.GetName())
 	}
-	klog.Infof("ticdcScaler.ScaleIn: %s has graceful shutdown in cluster %s/%s", podName, meta.GetNamespace(), meta.GetName())
 
 	pvcs, err := util.ResolvePVCFromPod(pod, s.deps.PVCLister)
 	if err != nil && !errors.IsNotFound(err) {
@@ -132,9 +139,34 @@ func gracefulShutdownTiCDC(
 	}
 	podName := pod.GetName()
 
+	// The capture has left the TiCDC cluster, e.g. the pod is crashed,
+	// there is nothing to move out of it.
+	alive, err := cdcCtl.IsCaptureAlive(tc, ordinal)
+	if err != nil {
+		return err
+	}
+	if !alive {
+		klog.Infof("ticdc.%s: capture of %s is not alive in cluster %s/%s, skip graceful shutdown",
+			action, podName, tc.GetNamespace(), tc.GetName())
+		return nil
+	}
+
+	// The capture has been drained in the previous reconciliation,
+	// resume to wait for the changefeeds to be rebalanced.
+	if _, ok := pod.Annotations[label.AnnTiCDCDrainCheckpoints]; ok {
+		updateTiCDCGracefulShutdownStatus(tc, podName, func(status *v1alpha1.TiCDCGracefulShutdownStatus) {
+			status.Phase = v1alpha1.TiCDCWaitingForCheckpoints
+			status.RemainingTables = 0
+		})
+		return waitTiCDCChangefeedsRebalanced(tc, cdcCtl, pod, ordinal, action)
+	}
+
 	// To graceful shutdown a TiCDC pod, we need to
 	//
 	// 1. Remove ownership from the capture.
+	updateTiCDCGracefulShutdownStatus(tc, podName, func(status *v1alpha1.TiCDCGracefulShutdownStatus) {
+		status.Phase = v1alpha1.TiCDCResigningOwner
+	})
 	resigned, err := cdcCtl.ResignOwner(tc, ordinal)
 	if err != nil {
 		return err
@@ -145,10 +177,27 @@ func gracefulShutdownTiCDC(
 			action, tc.GetNamespace(), tc.GetName(), podName)
 	}
 	// 2. Drain the capture, move out all its tables.
+	//    The drain is not retried until DrainRetryInterval has passed since the last one,
+	//    the tables are being moved in the meantime.
+	if lastDrainTime := tiCDCLastDrainTime(tc, podName); lastDrainTime != nil {
+		if wait := lastDrainTime.Add(tc.TiCDCDrainRetryInterval()).Sub(time.Now()); wait > 0 {
+			return controller.RequeueErrorf(
+				"ticdc.%s: cluster %s/%s %s was drained at %s, wait %s to drain again",
+				action, tc.GetNamespace(), tc.GetName(), podName, lastDrainTime.Format(time.RFC3339), wait.Round(time.Second))
+		}
+	}
+	updateTiCDCGracefulShutdownStatus(tc, podName, func(status *v1alpha1.TiCDCGracefulShutdownStatus) {
+		status.Phase = v1alpha1.TiCDCDraining
+	})
 	tableCount, retry, err := cdcCtl.DrainCapture(tc, ordinal)
 	if err != nil {
 		return err
 	}
+	updateTiCDCGracefulShutdownStatus(tc, podName, func(status *v1alpha1.TiCDCGracefulShutdownStatus) {
+		now := metav1.Now()
+		status.LastDrainTime = &now
+		status.RemainingTables = int32(tableCount)
+	})
 	if retry {
 		return controller.RequeueErrorf(
 			"ticdc.%s: cluster %s/%s %s needs to retry drain capture",
@@ -159,9 +208,119 @@ func gracefulShutdownTiCDC(
 			"ticdc.%s: cluster %s/%s %s still has %d tables, wait draining",
 			action, tc.GetNamespace(), tc.GetName(), podName, tableCount)
 	}
+	// 3. Wait for the changefeeds to be rebalanced, i.e. their checkpoints
+	//    advance after the tables are moved to other captures.
+	checkpoints, err := getTiCDCChangefeedCheckpoints(tc, cdcCtl, ordinal)
+	if err != nil {
+		return err
+	}
+	if len(checkpoints) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+	// Persist the checkpoints, so that the following reconciliations resume
+	// to wait for the checkpoints instead of draining the capture again.
+	pod.Annotations[label.AnnTiCDCDrainCheckpoints] = string(data)
+	if _, err := podCtl.UpdatePod(tc, pod); err != nil {
+		klog.Errorf("ticdc.%s: failed to set pod %s in cluster %s/%s annotation %s to %s, error: %v",
+			action, podName, tc.GetNamespace(), tc.GetName(), label.AnnTiCDCDrainCheckpoints, data, err)
+		return err
+	}
+	updateTiCDCGracefulShutdownStatus(tc, podName, func(status *v1alpha1.TiCDCGracefulShutdownStatus) {
+		status.Phase = v1alpha1.TiCDCWaitingForCheckpoints
+	})
+	return controller.RequeueErrorf(
+		"ticdc.%s: cluster %s/%s %s is drained, wait for the checkpoints of changefeeds to advance",
+		action, tc.GetNamespace(), tc.GetName(), podName)
+}
+
+// getTiCDCChangefeedCheckpoints returns the checkpoints of the normal changefeeds, which are
+// expected to advance, keyed by the namespace and id of the changefeeds
+func getTiCDCChangefeedCheckpoints(
+	tc *v1alpha1.TidbCluster,
+	cdcCtl controller.TiCDCControlInterface,
+	ordinal int32,
+) (map[string]uint64, error) {
+	changefeeds, err := cdcCtl.ListChangefeeds(tc, ordinal)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := map[string]uint64{}
+	for _, cf := range changefeeds {
+		if cf.State != controller.ChangefeedStateNormal {
+			continue
+		}
+		checkpoints[ticdcChangefeedKey(cf)] = cf.CheckpointTSO
+	}
+	return checkpoints, nil
+}
+
+// updateTiCDCGracefulShutdownStatus updates the graceful shutdown progress of the capture of the pod
+// in TidbCluster status, the captures not in status are skipped.
+func updateTiCDCGracefulShutdownStatus(tc *v1alpha1.TidbCluster, podName string, update func(status *v1alpha1.TiCDCGracefulShutdownStatus)) {
+	capture, ok := tc.Status.TiCDC.Captures[podName]
+	if !ok {
+		return
+	}
+	if capture.GracefulShutdown == nil {
+		capture.GracefulShutdown = &v1alpha1.TiCDCGracefulShutdownStatus{}
+	}
+	update(capture.GracefulShutdown)
+	tc.Status.TiCDC.Captures[podName] = capture
+}
+
+// tiCDCLastDrainTime returns the last time the capture of the pod was drained, nil if it's never drained.
+func tiCDCLastDrainTime(tc *v1alpha1.TidbCluster, podName string) *metav1.Time {
+	capture, ok := tc.Status.TiCDC.Captures[podName]
+	if !ok || capture.GracefulShutdown == nil {
+		return nil
+	}
+	return capture.GracefulShutdown.LastDrainTime
+}
+
+// waitTiCDCChangefeedsRebalanced returns a requeue error until the checkpoints of the changefeeds
+// advance the ones recorded when the capture was drained
+func waitTiCDCChangefeedsRebalanced(
+	tc *v1alpha1.TidbCluster,
+	cdcCtl controller.TiCDCControlInterface,
+	pod *corev1.Pod,
+	ordinal int32,
+	action string,
+) error {
+	podName := pod.GetName()
+	drained := map[string]uint64{}
+	if err := json.Unmarshal([]byte(pod.Annotations[label.AnnTiCDCDrainCheckpoints]), &drained); err != nil {
+		klog.Errorf("ticdc.%s: parse annotation:[%s] \"%s\" failed, skip waiting for changefeeds",
+			action, label.AnnTiCDCDrainCheckpoints, pod.Annotations[label.AnnTiCDCDrainCheckpoints])
+		return nil
+	}
+	current, err := getTiCDCChangefeedCheckpoints(tc, cdcCtl, ordinal)
+	if err != nil {
+		return err
+	}
+	for key, checkpoint := range current {
+		// the changefeeds created after draining or removed are not waited for
+		if prev, ok := drained[key]; ok && checkpoint <= prev {
+			return controller.RequeueErrorf(
+				"ticdc.%s: cluster %s/%s %s is drained, checkpoint of changefeed %s has not advanced %d",
+				action, tc.GetNamespace(), tc.GetName(), podName, key, prev)
+		}
+	}
+	klog.Infof("ticdc.%s: changefeeds are rebalanced after draining %s in cluster %s/%s",
+		action, podName, tc.GetNamespace(), tc.GetName())
 	return nil
 }
 
+func ticdcChangefeedKey(cf controller.ChangefeedInfo) string {
+	if cf.Namespace == "" {
+		return cf.ID
+	}
+	return cf.Namespace + "/" + cf.ID
+}
+
 func checkTiCDCGracefulShutdownTimeout(
 	tc *v1alpha1.TidbCluster,
 	podCtl controller.PodControlInterface,
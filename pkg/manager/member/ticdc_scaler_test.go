@@ -312,6 +312,45 @@ func TestTiCDCScalerScaleIn(t *testing.T) {
 	}
 }
 
+func TestTiCDCScalerScaleInLastCapture(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiCDC = &v1alpha1.TiCDCSpec{Replicas: 0}
+	oldSet := newStatefulSetForPDScale()
+	oldSet.Spec.Replicas = pointer.Int32Ptr(1)
+	newSet := oldSet.DeepCopy()
+	newSet.Spec.Replicas = pointer.Int32Ptr(0)
+
+	scaler, _, podIndexer, _ := newFakeTiCDCScaler()
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ticdcPodName(tc.GetName(), 0),
+			Namespace: corev1.NamespaceDefault,
+		},
+	}
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+
+	// the last capture is removed without draining it
+	shutdown := false
+	scaler.deps.CDCControl = &cdcCtlMock{
+		resignOwner: func(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+			shutdown = true
+			return true, nil
+		},
+	}
+	scaler.deps.PodControl = &podCtlMock{
+		updatePod: func(_ runtime.Object, p *corev1.Pod) (*corev1.Pod, error) {
+			shutdown = true
+			return p, nil
+		},
+	}
+	g.Expect(scaler.ScaleIn(tc, oldSet, newSet)).To(Succeed())
+	g.Expect(*newSet.Spec.Replicas).To(Equal(int32(0)))
+	g.Expect(shutdown).To(BeFalse())
+}
+
 func newFakeTiCDCScaler(resyncDuration ...time.Duration) (*ticdcScaler, cache.Indexer, cache.Indexer, *controller.FakePVCControl) {
 	fakeDeps := controller.NewFakeDependencies()
 	if len(resyncDuration) > 0 {
@@ -325,8 +364,10 @@ func newFakeTiCDCScaler(resyncDuration ...time.Duration) (*ticdcScaler, cache.In
 
 type cdcCtlMock struct {
 	controller.TiCDCControlInterface
-	drainCapture func(tc *v1alpha1.TidbCluster, ordinal int32) (tableCount int, retry bool, err error)
-	resignOwner  func(tc *v1alpha1.TidbCluster, ordinal int32) (ok bool, err error)
+	drainCapture   func(tc *v1alpha1.TidbCluster, ordinal int32) (tableCount int, retry bool, err error)
+	resignOwner    func(tc *v1alpha1.TidbCluster, ordinal int32) (ok bool, err error)
+	isCaptureAlive func(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error)
+	changefeeds    []controller.ChangefeedInfo
 }
 
 func (c *cdcCtlMock) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error) {
@@ -335,6 +376,15 @@ func (c *cdcCtlMock) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int32) (int,
 func (c *cdcCtlMock) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
 	return c.resignOwner(tc, ordinal)
 }
+func (c *cdcCtlMock) IsCaptureAlive(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	if c.isCaptureAlive == nil {
+		return true, nil
+	}
+	return c.isCaptureAlive(tc, ordinal)
+}
+func (c *cdcCtlMock) ListChangefeeds(tc *v1alpha1.TidbCluster, ordinal int32) ([]controller.ChangefeedInfo, error) {
+	return c.changefeeds, nil
+}
 
 type podCtlMock struct {
 	controller.PodControlInterface
@@ -506,6 +556,23 @@ func TestTiCDCGracefulShutdown(t *testing.T) {
 				g.Expect(controller.IsRequeueError(err)).Should(BeTrue(), name)
 			},
 		},
+		{
+			caseName: "shutdown capture not alive",
+			cdcCtl: &cdcCtlMock{
+				isCaptureAlive: func(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+					return false, nil
+				},
+			},
+			podCtl: &podCtlMock{
+				updatePod: func(_ runtime.Object, p *corev1.Pod) (*corev1.Pod, error) {
+					return p, nil
+				},
+			},
+			pod: newPod,
+			expectedErr: func(err error, name string) {
+				g.Expect(err).Should(BeNil(), name)
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -514,3 +581,130 @@ func TestTiCDCGracefulShutdown(t *testing.T) {
 		c.expectedErr(err, c.caseName)
 	}
 }
+
+func TestTiCDCGracefulShutdownWaitChangefeeds(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiCDC = &v1alpha1.TiCDCSpec{}
+	newPod := func(checkpoints string) *corev1.Pod {
+		pod := &corev1.Pod{
+			TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ticdcPodName(tc.GetName(), 1),
+				Namespace: corev1.NamespaceDefault,
+				Annotations: map[string]string{
+					label.AnnTiCDCGracefulShutdownBeginTime: time.Now().Format(time.RFC3339),
+				},
+			},
+		}
+		if checkpoints != "" {
+			pod.Annotations[label.AnnTiCDCDrainCheckpoints] = checkpoints
+		}
+		return pod
+	}
+	podCtl := &podCtlMock{
+		updatePod: func(_ runtime.Object, p *corev1.Pod) (*corev1.Pod, error) {
+			return p, nil
+		},
+	}
+	changefeeds := func(checkpoint uint64) []controller.ChangefeedInfo {
+		return []controller.ChangefeedInfo{
+			{Namespace: "default", ID: "cf1", State: controller.ChangefeedStateNormal, CheckpointTSO: checkpoint},
+			{Namespace: "default", ID: "cf2", State: "stopped", CheckpointTSO: 1},
+		}
+	}
+
+	// the checkpoints are recorded after the capture is drained
+	pod := newPod("")
+	cdcCtl := &cdcCtlMock{
+		drainCapture: func(tc *v1alpha1.TidbCluster, ordinal int32) (tableCount int, retry bool, err error) {
+			return 0, false, nil
+		},
+		resignOwner: func(tc *v1alpha1.TidbCluster, ordinal int32) (ok bool, err error) {
+			return true, nil
+		},
+		changefeeds: changefeeds(10),
+	}
+	err := gracefulShutdownTiCDC(tc, cdcCtl, podCtl, pod, 1, "test")
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(pod.Annotations[label.AnnTiCDCDrainCheckpoints]).To(Equal(`{"default/cf1":10}`))
+
+	// the capture is not drained again and waits for the checkpoints to advance
+	cdcCtl = &cdcCtlMock{changefeeds: changefeeds(10)}
+	err = gracefulShutdownTiCDC(tc, cdcCtl, podCtl, newPod(`{"default/cf1":10}`), 1, "test")
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+
+	cdcCtl = &cdcCtlMock{changefeeds: changefeeds(11)}
+	err = gracefulShutdownTiCDC(tc, cdcCtl, podCtl, newPod(`{"default/cf1":10}`), 1, "test")
+	g.Expect(err).To(Succeed())
+}
+
+func TestTiCDCGracefulShutdownProgress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiCDC = &v1alpha1.TiCDCSpec{}
+	podName := ticdcPodName(tc.GetName(), 1)
+	tc.Status.TiCDC.Captures = map[string]v1alpha1.TiCDCCapture{
+		podName: {PodName: podName, IsOwner: true, Ready: true},
+	}
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: corev1.NamespaceDefault,
+			Annotations: map[string]string{
+				label.AnnTiCDCGracefulShutdownBeginTime: time.Now().Format(time.RFC3339),
+			},
+		},
+	}
+	podCtl := &podCtlMock{
+		updatePod: func(_ runtime.Object, p *corev1.Pod) (*corev1.Pod, error) {
+			return p, nil
+		},
+	}
+	drained := 0
+	cdcCtl := &cdcCtlMock{
+		drainCapture: func(tc *v1alpha1.TidbCluster, ordinal int32) (tableCount int, retry bool, err error) {
+			drained++
+			return 3, false, nil
+		},
+		resignOwner: func(tc *v1alpha1.TidbCluster, ordinal int32) (ok bool, err error) {
+			return true, nil
+		},
+	}
+
+	// the remaining tables are reported after draining
+	err := gracefulShutdownTiCDC(tc, cdcCtl, podCtl, pod, 1, "test")
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(drained).To(Equal(1))
+	status := tc.Status.TiCDC.Captures[podName].GracefulShutdown
+	g.Expect(status).NotTo(BeNil())
+	g.Expect(status.Phase).To(Equal(v1alpha1.TiCDCDraining))
+	g.Expect(status.RemainingTables).To(Equal(int32(3)))
+	g.Expect(status.LastDrainTime).NotTo(BeNil())
+
+	// the capture is not drained again within the retry interval
+	err = gracefulShutdownTiCDC(tc, cdcCtl, podCtl, pod, 1, "test")
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("to drain again"))
+	g.Expect(drained).To(Equal(1))
+
+	// the capture is drained again after the retry interval
+	lastDrainTime := metav1.NewTime(time.Now().Add(-tc.TiCDCDrainRetryInterval()))
+	tc.Status.TiCDC.Captures[podName].GracefulShutdown.LastDrainTime = &lastDrainTime
+	cdcCtl.drainCapture = func(tc *v1alpha1.TidbCluster, ordinal int32) (tableCount int, retry bool, err error) {
+		drained++
+		return 0, false, nil
+	}
+	cdcCtl.changefeeds = []controller.ChangefeedInfo{
+		{Namespace: "default", ID: "cf1", State: controller.ChangefeedStateNormal, CheckpointTSO: 10},
+	}
+	err = gracefulShutdownTiCDC(tc, cdcCtl, podCtl, pod, 1, "test")
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(drained).To(Equal(2))
+	status = tc.Status.TiCDC.Captures[podName].GracefulShutdown
+	g.Expect(status.Phase).To(Equal(v1alpha1.TiCDCWaitingForCheckpoints))
+	g.Expect(status.RemainingTables).To(BeZero())
+}
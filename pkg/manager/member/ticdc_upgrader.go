@@ -20,7 +20,6 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
 
-	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/klog/v2"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
@@ -39,9 +38,9 @@ func NewTiCDCUpgrader(deps *controller.Dependencies) Upgrader {
 
 func (u *ticdcUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
 
-	// return nil when scale replicas to 0
+	// return nil when scale replicas to 0, the Pods being scaled in are not upgraded
 	if tc.Spec.TiCDC.Replicas == int32(0) {
-		return nil
+		return keepTemplateUntilScaledToZero(oldSet, newSet)
 	}
 
 	ns := tc.GetNamespace()
@@ -86,7 +85,7 @@ func (u *ticdcUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulS
 	}
 
 	mngerutils.SetUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
-	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
+	podOrdinals := mngerutils.GetUpgradeOrdinals(oldSet, newSet)
 	for i := len(podOrdinals) - 1; i >= 0; i-- {
 		ordinal := podOrdinals[i]
 		podName := ticdcPodName(tcName, ordinal)
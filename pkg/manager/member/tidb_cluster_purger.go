@@ -0,0 +1,192 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// TidbClusterPurger tears down a TidbCluster whose deletionPolicy is Purge before it's deleted
+type TidbClusterPurger interface {
+	// Purge returns nil once the cluster is torn down, and a requeue error
+	// if it's waiting for the stores to be tombstone
+	Purge(tc *v1alpha1.TidbCluster) error
+}
+
+type tidbClusterPurger struct {
+	deps *controller.Dependencies
+}
+
+// NewTidbClusterPurger returns a TidbClusterPurger
+func NewTidbClusterPurger(deps *controller.Dependencies) TidbClusterPurger {
+	return &tidbClusterPurger{
+		deps: deps,
+	}
+}
+
+func (p *tidbClusterPurger) Purge(tc *v1alpha1.TidbCluster) error {
+	// the PD of the cluster is deleted with it if it's not shared with other clusters,
+	// so the stores and the members are only removed from a shared PD
+	if tc.WithoutLocalPD() || len(tc.Spec.PDAddresses) > 0 {
+		if err := p.removeStores(tc); err != nil {
+			return err
+		}
+		if err := p.removePDMembers(tc); err != nil {
+			return err
+		}
+	}
+	if err := p.deletePVCs(tc); err != nil {
+		return err
+	}
+	return p.unregisterFromMonitors(tc)
+}
+
+// removeStores offlines the TiKV and TiFlash stores of the cluster and waits until they're tombstone,
+// the Pods are still running as the TidbCluster is not deleted yet, so the regions are moved out
+func (p *tidbClusterPurger) removeStores(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	ids := map[uint64]struct{}{}
+	for _, stores := range []map[string]v1alpha1.TiKVStore{tc.Status.TiKV.Stores, tc.Status.TiFlash.Stores} {
+		for _, store := range stores {
+			id, err := strconv.ParseUint(store.ID, 10, 64)
+			if err != nil {
+				klog.Warningf("tc %s/%s: invalid store id %q of pod %s", ns, tcName, store.ID, store.PodName)
+				continue
+			}
+			ids[id] = struct{}{}
+		}
+	}
+	remaining, err := offlineStores(controller.GetPDClient(p.deps.PDControl, tc), ids, func(id uint64) {
+		p.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "StoreDeleted", "Store %d is deleted from PD for purging the cluster", id)
+	})
+	if err != nil {
+		return fmt.Errorf("tc %s/%s: failed to delete stores, error: %v", ns, tcName, err)
+	}
+	if len(remaining) > 0 {
+		return controller.RequeueErrorf("tc %s/%s: waiting for stores %v to be tombstone", ns, tcName, remaining)
+	}
+	return nil
+}
+
+// removePDMembers removes the PD members of the cluster from the PD cluster it joins
+func (p *tidbClusterPurger) removePDMembers(tc *v1alpha1.TidbCluster) error {
+	if tc.WithoutLocalPD() || len(tc.Status.PD.Members) == 0 {
+		return nil
+	}
+	pdClient := controller.GetPDClient(p.deps.PDControl, tc)
+	for name := range tc.Status.PD.Members {
+		if err := pdClient.DeleteMember(name); err != nil {
+			return fmt.Errorf("tc %s/%s: failed to delete PD member %s, error: %v", tc.GetNamespace(), tc.GetName(), name, err)
+		}
+	}
+	return nil
+}
+
+// deletePVCs deletes the PVCs of the cluster, the PVs are then retained or deleted according to
+// `spec.pvReclaimPolicy`, and a PVC is only removed after the Pods using it are deleted with the cluster
+func (p *tidbClusterPurger) deletePVCs(tc *v1alpha1.TidbCluster) error {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return err
+	}
+	pvcs, err := p.deps.PVCLister.PersistentVolumeClaims(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return fmt.Errorf("tc %s/%s: failed to list PVCs, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	for _, pvc := range pvcs {
+		if pvc.DeletionTimestamp != nil {
+			continue
+		}
+		if err := p.deps.PVCControl.DeletePVC(tc, pvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unregisterFromMonitors removes the cluster from the TidbMonitors monitoring other clusters too,
+// a TidbMonitor only monitoring the cluster is left to the user
+func (p *tidbClusterPurger) unregisterFromMonitors(tc *v1alpha1.TidbCluster) error {
+	monitors, err := p.deps.TiDBMonitorLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("tc %s/%s: failed to list TidbMonitors, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	for _, tm := range monitors {
+		var clusters []v1alpha1.TidbClusterRef
+		for _, ref := range tm.Spec.Clusters {
+			if !refersTo(ref, tm.GetNamespace(), tc) {
+				clusters = append(clusters, ref)
+			}
+		}
+		if len(clusters) == len(tm.Spec.Clusters) || len(clusters) == 0 {
+			continue
+		}
+		tm = tm.DeepCopy()
+		tm.Spec.Clusters = clusters
+		if _, err := p.deps.Clientset.PingcapV1alpha1().TidbMonitors(tm.GetNamespace()).Update(context.TODO(), tm, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("tc %s/%s: failed to remove it from TidbMonitor %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), tm.GetNamespace(), tm.GetName(), err)
+		}
+		klog.Infof("tc %s/%s: removed from TidbMonitor %s/%s", tc.GetNamespace(), tc.GetName(), tm.GetNamespace(), tm.GetName())
+	}
+	return nil
+}
+
+// refersTo returns whether ref of an object in namespace ns refers to tc
+func refersTo(ref v1alpha1.TidbClusterRef, ns string, tc *v1alpha1.TidbCluster) bool {
+	if ref.Namespace != "" {
+		ns = ref.Namespace
+	}
+	if ref.ClusterDomain != "" && ref.ClusterDomain != tc.Spec.ClusterDomain {
+		return false
+	}
+	return ns == tc.GetNamespace() && ref.Name == tc.GetName()
+}
+
+type FakeTidbClusterPurger struct {
+	err    error
+	purged bool
+}
+
+func NewFakeTidbClusterPurger() *FakeTidbClusterPurger {
+	return &FakeTidbClusterPurger{}
+}
+
+func (p *FakeTidbClusterPurger) SetPurgeError(err error) {
+	p.err = err
+}
+
+func (p *FakeTidbClusterPurger) Purged() bool {
+	return p.purged
+}
+
+func (p *FakeTidbClusterPurger) Purge(_ *v1alpha1.TidbCluster) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.purged = true
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTidbClusterPurgerPurge(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	purger := &tidbClusterPurger{deps: deps}
+
+	tc := newTidbClusterForPD()
+	tc.Spec.PD = nil
+	tc.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: "primary", Namespace: tc.Namespace}
+	tc.Spec.DeletionPolicy = v1alpha1.DeletionPolicyPurge
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+	}
+
+	state := metapb.StoreState_Up
+	var deleted []uint64
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{
+			Stores: []*pdapi.StoreInfo{
+				{Store: &pdapi.MetaStore{Store: &metapb.Store{Id: 1, State: state}}},
+				// the store of the primary cluster is not touched
+				{Store: &pdapi.MetaStore{Store: &metapb.Store{Id: 2, State: metapb.StoreState_Up}}},
+			},
+		}, nil
+	})
+	pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		deleted = append(deleted, action.ID)
+		return nil, nil
+	})
+
+	pvc := newMockPVC("tikv-test-tikv-0", "sc", "100Gi", "100Gi")
+	pvc.Labels = label.New().Instance(tc.GetInstanceName()).TiKV().Labels()
+	g.Expect(deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)).To(Succeed())
+
+	monitor := &v1alpha1.TidbMonitor{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: "monitor"},
+		Spec: v1alpha1.TidbMonitorSpec{
+			Clusters: []v1alpha1.TidbClusterRef{{Name: "primary"}, {Name: tc.Name}},
+		},
+	}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbMonitors().Informer().GetIndexer().Add(monitor)).To(Succeed())
+	_, err := deps.Clientset.PingcapV1alpha1().TidbMonitors(tc.Namespace).Create(context.TODO(), monitor, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// wait for the store to be tombstone
+	err = purger.Purge(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deleted).To(Equal([]uint64{1}))
+
+	state = metapb.StoreState_Offline
+	err = purger.Purge(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deleted).To(HaveLen(1))
+
+	state = metapb.StoreState_Tombstone
+	g.Expect(purger.Purge(tc)).To(Succeed())
+	_, err = deps.PVCLister.PersistentVolumeClaims(pvc.Namespace).Get(pvc.Name)
+	g.Expect(err).To(HaveOccurred())
+	monitor, err = deps.Clientset.PingcapV1alpha1().TidbMonitors(tc.Namespace).Get(context.TODO(), "monitor", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(monitor.Spec.Clusters).To(Equal([]v1alpha1.TidbClusterRef{{Name: "primary"}}))
+}
+
+func TestRefersTo(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"}}
+	g.Expect(refersTo(v1alpha1.TidbClusterRef{Name: "test"}, corev1.NamespaceDefault, tc)).To(BeTrue())
+	g.Expect(refersTo(v1alpha1.TidbClusterRef{Name: "test"}, "monitoring", tc)).To(BeFalse())
+	g.Expect(refersTo(v1alpha1.TidbClusterRef{Name: "test", Namespace: corev1.NamespaceDefault}, "monitoring", tc)).To(BeTrue())
+	g.Expect(refersTo(v1alpha1.TidbClusterRef{Name: "test", ClusterDomain: "cluster2.local"}, corev1.NamespaceDefault, tc)).To(BeFalse())
+}
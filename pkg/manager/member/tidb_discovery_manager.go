@@ -162,10 +162,11 @@ func getTidbDiscoveryService(obj metav1.Object, deploy *appsv1.Deployment) *core
 
 func (m *realTidbDiscoveryManager) getTidbDiscoveryDeployment(obj metav1.Object) (*appsv1.Deployment, error) {
 	var (
-		resources corev1.ResourceRequirements
-		timezone  string
-		baseSpec  v1alpha1.ComponentAccessor
-		podSpec   corev1.PodSpec
+		resources   corev1.ResourceRequirements
+		timezone    string
+		baseSpec    v1alpha1.ComponentAccessor
+		podSpec     corev1.PodSpec
+		imagePolicy *v1alpha1.ImagePolicy
 	)
 
 	switch cluster := obj.(type) {
@@ -174,11 +175,13 @@ func (m *realTidbDiscoveryManager) getTidbDiscoveryDeployment(obj metav1.Object)
 		timezone = cluster.Timezone()
 		baseSpec = cluster.BaseDiscoverySpec()
 		podSpec = baseSpec.BuildPodSpec()
+		imagePolicy = cluster.Spec.ImagePolicy
 	case *v1alpha1.DMCluster:
 		resources = cluster.Spec.Discovery.ResourceRequirements
 		timezone = cluster.Timezone()
 		baseSpec = cluster.BaseDiscoverySpec()
 		podSpec = baseSpec.BuildPodSpec()
+		imagePolicy = cluster.Spec.ImagePolicy
 	default:
 		panic(fmt.Sprintf("unsupported type %T for discovery meta", obj))
 	}
@@ -238,6 +241,8 @@ func (m *realTidbDiscoveryManager) getTidbDiscoveryDeployment(obj metav1.Object)
 	}
 
 	podSpec.InitContainers = append(podSpec.InitContainers, baseSpec.InitContainers()...)
+	// the discovery image is the image of TiDB Operator, the tag suffixes of the components don't apply to it
+	imagePolicy.ResolvePodImages(&podSpec, "")
 
 	podSpec.ServiceAccountName = meta.Name
 
@@ -96,6 +96,23 @@ func TestTidbDiscoveryManager_Reconcile(t *testing.T) {
 			},
 			errOnCreateOrUpdate: false,
 		},
+		{
+			name: "Resolving discovery image by image policy",
+			prepare: func(tc *v1alpha1.TidbCluster, ctrl *controller.FakeGenericControl) {
+				tc.Spec.ImagePolicy = &v1alpha1.ImagePolicy{
+					RegistryMirrors: map[string]string{"docker.io": "registry.local/mirror"},
+					TagSuffix:       "-fips",
+				}
+			},
+			expect: func(deploys []appsv1.Deployment, tc *v1alpha1.TidbCluster, err error) {
+				g.Expect(err).To(Succeed())
+				g.Expect(deploys).To(HaveLen(1))
+				image := deploys[0].Spec.Template.Spec.Containers[0].Image
+				g.Expect(image).To(HavePrefix("registry.local/mirror/"))
+				g.Expect(image).NotTo(HaveSuffix("-fips"))
+			},
+			errOnCreateOrUpdate: false,
+		},
 		{
 			name: "Create or update resource error",
 			expect: func(deploys []appsv1.Deployment, tc *v1alpha1.TidbCluster, err error) {
@@ -17,6 +17,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -56,12 +57,18 @@ type InitManager interface {
 }
 
 type tidbInitManager struct {
-	deps *controller.Dependencies
+	deps           *controller.Dependencies
+	syncSettingsFn func(deps *controller.Dependencies, ti *v1alpha1.TidbInitializer, tc *v1alpha1.TidbCluster) ([]string, error)
+	now            func() time.Time
 }
 
 // NewTiDBInitManager return tidbInitManager
 func NewTiDBInitManager(deps *controller.Dependencies) InitManager {
-	return &tidbInitManager{deps: deps}
+	return &tidbInitManager{
+		deps:           deps,
+		syncSettingsFn: syncTiDBSettings,
+		now:            time.Now,
+	}
 }
 
 func (m *tidbInitManager) Sync(ti *v1alpha1.TidbInitializer) error {
@@ -84,10 +91,10 @@ func (m *tidbInitManager) Sync(ti *v1alpha1.TidbInitializer) error {
 	if err != nil {
 		return err
 	}
-	return m.updateStatus(ti.DeepCopy())
+	return m.updateStatus(ti.DeepCopy(), tc)
 }
 
-func (m *tidbInitManager) updateStatus(ti *v1alpha1.TidbInitializer) error {
+func (m *tidbInitManager) updateStatus(ti *v1alpha1.TidbInitializer, tc *v1alpha1.TidbCluster) error {
 	name := controller.TiDBInitializerMemberName(ti.Spec.Clusters.Name)
 	ns := ti.Namespace
 	job, err := m.deps.JobLister.Jobs(ns).Get(name)
@@ -118,6 +125,13 @@ func (m *tidbInitManager) updateStatus(ti *v1alpha1.TidbInitializer) error {
 		ti.Status.Phase = phase
 		update = true
 	}
+	if phase == v1alpha1.InitializePhaseCompleted {
+		oldSettings := ti.Status.Settings.DeepCopy()
+		m.syncTiDBInitSettings(ti, tc)
+		if !apiequality.Semantic.DeepEqual(oldSettings, ti.Status.Settings) {
+			update = true
+		}
+	}
 	if update {
 		_, err = m.updateInitializer(ti)
 		return err
@@ -16,6 +16,7 @@ package member
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -82,7 +83,7 @@ func TestTiDBInitManagerSync(t *testing.T) {
 			if err != nil {
 				return err
 			}
-			err = tim.updateStatus(ti.DeepCopy())
+			err = tim.updateStatus(ti.DeepCopy(), test.tc)
 			*/
 			return err
 		}()
@@ -132,7 +133,7 @@ func newFakeTiDBInitManager() (*tidbInitManager, *tidbMemberManager, *fakeIndexe
 	tmm, _, _, indexers := newFakeTiDBMemberManager()
 	indexers.job = tmm.deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer()
 	indexers.ti = tmm.deps.InformerFactory.Pingcap().V1alpha1().TidbInitializers().Informer().GetIndexer()
-	return &tidbInitManager{deps: tmm.deps}, tmm, indexers
+	return &tidbInitManager{deps: tmm.deps, syncSettingsFn: syncTiDBSettings, now: time.Now}, tmm, indexers
 }
 
 func newTidbInitializerForTiDB() *v1alpha1.TidbInitializer {
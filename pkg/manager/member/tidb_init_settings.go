@@ -0,0 +1,224 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// settingsSyncTimeout is the max time to check and set the settings of a TiDB cluster
+	settingsSyncTimeout = time.Minute
+)
+
+// settingNamePattern is the pattern of the names of the global variables and the cluster configs,
+// the names are not able to be passed as the parameters of the statements
+var settingNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+
+// syncTiDBInitSettings checks the global variables and the cluster configs at the interval and sets
+// the drifted ones again, the result is recorded in the status of the TidbInitializer
+func (m *tidbInitManager) syncTiDBInitSettings(ti *v1alpha1.TidbInitializer, tc *v1alpha1.TidbCluster) {
+	if !ti.HasSettings() {
+		ti.Status.Settings = nil
+		return
+	}
+	now := m.now()
+	if ti.Status.Settings != nil && now.Sub(ti.Status.Settings.LastSyncTime.Time) < ti.SettingsSyncInterval() {
+		return
+	}
+
+	status := &v1alpha1.InitializerSettingsStatus{LastSyncTime: metav1.NewTime(now)}
+	corrected, err := m.syncSettingsFn(m.deps, ti, tc)
+	status.Corrected = corrected
+	if err != nil {
+		klog.Errorf("failed to sync settings of TidbInitializer %s/%s, error: %v", ti.Namespace, ti.Name, err)
+		m.deps.Recorder.Eventf(ti, corev1.EventTypeWarning, "SyncSettingsFailed", "failed to sync settings: %v", err)
+		status.Message = err.Error()
+	}
+	if len(corrected) > 0 {
+		klog.Infof("settings %v of TidbInitializer %s/%s are corrected", corrected, ti.Namespace, ti.Name)
+		m.deps.Recorder.Eventf(ti, corev1.EventTypeNormal, "SettingsCorrected", "settings %s are set to the desired values", strings.Join(corrected, ", "))
+	}
+	ti.Status.Settings = status
+}
+
+// syncTiDBSettings connects to the TiDB cluster as root and sets the global variables and the cluster
+// configs which drift from the desired values, it returns the settings set in this round
+func syncTiDBSettings(deps *controller.Dependencies, ti *v1alpha1.TidbInitializer, tc *v1alpha1.TidbCluster) ([]string, error) {
+	var password string
+	if ti.Spec.PasswordSecret != nil {
+		secret, err := deps.SecretLister.Secrets(ti.Namespace).Get(*ti.Spec.PasswordSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret %s/%s, error: %v", ti.Namespace, *ti.Spec.PasswordSecret, err)
+		}
+		password = string(secret.Data[constants.TidbRootKey])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), settingsSyncTimeout)
+	defer cancel()
+	db, err := util.OpenDB(ctx, util.GetDSN(tc, password))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var corrected []string
+	var errs []error
+	names := make([]string, 0, len(ti.Spec.GlobalVariables))
+	for name := range ti.Spec.GlobalVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		set, err := syncGlobalVariable(ctx, db, name, ti.Spec.GlobalVariables[name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if set {
+			corrected = append(corrected, name)
+		}
+	}
+	for _, item := range ti.Spec.ClusterConfigs {
+		set, err := syncClusterConfig(ctx, db, item)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if set {
+			corrected = append(corrected, fmt.Sprintf("%s:%s", item.Type, item.Name))
+		}
+	}
+	return corrected, errorutils.NewAggregate(errs)
+}
+
+// syncGlobalVariable sets the global variable if it differs from the desired value
+func syncGlobalVariable(ctx context.Context, db *sql.DB, name, value string) (bool, error) {
+	if !settingNamePattern.MatchString(name) {
+		return false, fmt.Errorf("invalid name of global variable %q", name)
+	}
+	var varName, actual string
+	row := db.QueryRowContext(ctx, "SHOW GLOBAL VARIABLES WHERE Variable_name = ?", name)
+	if err := row.Scan(&varName, &actual); err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("unknown global variable %s", name)
+		}
+		return false, fmt.Errorf("failed to get global variable %s, error: %v", name, err)
+	}
+	if settingValueEqual(actual, value) {
+		return false, nil
+	}
+	stmt := fmt.Sprintf("SET GLOBAL %s = %s", name, quoteSQLString(value))
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		return false, fmt.Errorf("failed to run %q, error: %v", stmt, err)
+	}
+	return true, nil
+}
+
+// syncClusterConfig sets the config of the component if it differs from the desired value on any instance
+func syncClusterConfig(ctx context.Context, db *sql.DB, item v1alpha1.ClusterConfigItem) (bool, error) {
+	if !settingNamePattern.MatchString(item.Type) || !settingNamePattern.MatchString(item.Name) {
+		return false, fmt.Errorf("invalid cluster config %s:%s", item.Type, item.Name)
+	}
+	rows, err := db.QueryContext(ctx, "SHOW CONFIG WHERE type = ? AND name = ?", item.Type, item.Name)
+	if err != nil {
+		return false, fmt.Errorf("failed to get config %s of %s, error: %v", item.Name, item.Type, err)
+	}
+	defer rows.Close()
+	found, drifted := false, false
+	for rows.Next() {
+		var typ, instance, name, actual string
+		if err := rows.Scan(&typ, &instance, &name, &actual); err != nil {
+			return false, fmt.Errorf("failed to get config %s of %s, error: %v", item.Name, item.Type, err)
+		}
+		found = true
+		if !settingValueEqual(actual, item.Value) {
+			klog.V(4).Infof("config %s of %s %s is %s, desired: %s", item.Name, item.Type, instance, actual, item.Value)
+			drifted = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to get config %s of %s, error: %v", item.Name, item.Type, err)
+	}
+	if !found {
+		return false, fmt.Errorf("unknown config %s of %s", item.Name, item.Type)
+	}
+	if !drifted {
+		return false, nil
+	}
+	stmt := fmt.Sprintf("SET CONFIG %s `%s` = %s", item.Type, item.Name, configValueLiteral(item.Value))
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		return false, fmt.Errorf("failed to run %q, error: %v", stmt, err)
+	}
+	return true, nil
+}
+
+// settingValueEqual compares the values of a setting, the boolean values and the numbers
+// are compared regardless of the representations, e.g. ON and 1
+func settingValueEqual(actual, desired string) bool {
+	a, d := normalizeSettingValue(actual), normalizeSettingValue(desired)
+	if a == d {
+		return true
+	}
+	af, errA := strconv.ParseFloat(a, 64)
+	df, errD := strconv.ParseFloat(d, 64)
+	return errA == nil && errD == nil && af == df
+}
+
+func normalizeSettingValue(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "on", "true":
+		return "1"
+	case "off", "false":
+		return "0"
+	}
+	return value
+}
+
+// configValueLiteral returns the literal of the config value in `SET CONFIG`, the booleans and the
+// numbers are not quoted to keep their types
+func configValueLiteral(value string) string {
+	switch strings.ToLower(value) {
+	case "true", "false":
+		return strings.ToLower(value)
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return quoteSQLString(value)
+}
+
+func quoteSQLString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
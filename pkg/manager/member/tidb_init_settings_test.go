@@ -0,0 +1,87 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"k8s.io/utils/pointer"
+)
+
+func TestSyncTiDBInitSettings(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tim, _, _ := newFakeTiDBInitManager()
+	now := time.Now()
+	tim.now = func() time.Time { return now }
+	var synced int
+	var corrected []string
+	var syncErr error
+	tim.syncSettingsFn = func(_ *controller.Dependencies, _ *v1alpha1.TidbInitializer, _ *v1alpha1.TidbCluster) ([]string, error) {
+		synced++
+		return corrected, syncErr
+	}
+
+	ti := newTidbInitializerForTiDB()
+	tc := newTidbClusterForTiDB()
+	tim.syncTiDBInitSettings(ti, tc)
+	g.Expect(synced).To(Equal(0))
+	g.Expect(ti.Status.Settings).To(BeNil())
+
+	ti.Spec.GlobalVariables = map[string]string{"tidb_gc_life_time": "24h"}
+	ti.Spec.SettingsSyncInterval = pointer.StringPtr("5m")
+	corrected = []string{"tidb_gc_life_time"}
+	tim.syncTiDBInitSettings(ti, tc)
+	g.Expect(synced).To(Equal(1))
+	g.Expect(ti.Status.Settings.LastSyncTime.Time.Equal(now)).To(BeTrue())
+	g.Expect(ti.Status.Settings.Corrected).To(Equal(corrected))
+
+	// the settings are not checked within the interval
+	now = now.Add(time.Minute)
+	tim.syncTiDBInitSettings(ti, tc)
+	g.Expect(synced).To(Equal(1))
+
+	now = now.Add(5 * time.Minute)
+	corrected = nil
+	syncErr = fmt.Errorf("unknown global variable tidb_gc_life_time")
+	tim.syncTiDBInitSettings(ti, tc)
+	g.Expect(synced).To(Equal(2))
+	g.Expect(ti.Status.Settings.Corrected).To(BeEmpty())
+	g.Expect(ti.Status.Settings.Message).To(ContainSubstring("unknown global variable"))
+}
+
+func TestSettingValueEqual(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(settingValueEqual("ON", "1")).To(BeTrue())
+	g.Expect(settingValueEqual("OFF", "false")).To(BeTrue())
+	g.Expect(settingValueEqual("10m0s", "10m0s")).To(BeTrue())
+	g.Expect(settingValueEqual("0.80", "0.8")).To(BeTrue())
+	g.Expect(settingValueEqual("10m0s", "24h")).To(BeFalse())
+	g.Expect(settingValueEqual("2", "1")).To(BeFalse())
+}
+
+func TestConfigValueLiteral(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(configValueLiteral("1000")).To(Equal("1000"))
+	g.Expect(configValueLiteral("True")).To(Equal("true"))
+	g.Expect(configValueLiteral("96MiB")).To(Equal("'96MiB'"))
+	g.Expect(configValueLiteral(`it's`)).To(Equal(`'it\'s'`))
+}
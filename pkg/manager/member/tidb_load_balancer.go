@@ -0,0 +1,112 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// loadBalancerServingReason is the reason of TiDBLoadBalancerServing condition when the Pod is in service
+	loadBalancerServingReason = "Serving"
+	// loadBalancerDeregisteringReason is the reason of TiDBLoadBalancerServing condition when the Pod
+	// is taken out of service for upgrading
+	loadBalancerDeregisteringReason = "Deregistering"
+)
+
+// tidbLoadBalancerReadinessGates returns the readiness gates of the TiDB Pods
+func tidbLoadBalancerReadinessGates(tc *v1alpha1.TidbCluster) []corev1.PodReadinessGate {
+	lb := tc.Spec.TiDB.LoadBalancerReadiness
+	if lb == nil {
+		return nil
+	}
+	gates := []corev1.PodReadinessGate{{ConditionType: v1alpha1.TiDBLoadBalancerServing}}
+	for _, conditionType := range lb.ReadinessGates {
+		gates = append(gates, corev1.PodReadinessGate{ConditionType: conditionType})
+	}
+	return gates
+}
+
+// syncTiDBLoadBalancerServing puts the TiDB Pods in service of the load balancers, except
+// the ones being deregistered for upgrading
+func (m *tidbMemberManager) syncTiDBLoadBalancerServing(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiDB.LoadBalancerReadiness == nil {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiDB().Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncTiDBLoadBalancerServing: failed to list pods for cluster %s/%s, selector %s, error: %v", ns, tc.GetName(), selector, err)
+	}
+	for _, pod := range pods {
+		_, cond := podutil.GetPodCondition(&pod.Status, v1alpha1.TiDBLoadBalancerServing)
+		if cond != nil && cond.Status == corev1.ConditionTrue {
+			continue
+		}
+		// the Pod being deregistered is kept out of service until it's upgraded
+		if cond != nil && cond.Reason == loadBalancerDeregisteringReason && tc.Status.TiDB.Phase == v1alpha1.UpgradePhase {
+			continue
+		}
+		_, err := m.deps.PodControl.UpdatePodCondition(tc, pod, corev1.PodCondition{
+			Type:               v1alpha1.TiDBLoadBalancerServing,
+			Status:             corev1.ConditionTrue,
+			Reason:             loadBalancerServingReason,
+			LastTransitionTime: metav1.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("syncTiDBLoadBalancerServing: failed to put pod %s/%s in service, error: %v", ns, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// deregisterTiDBPod takes the TiDB Pod out of service of the load balancers and waits for the
+// deregistration delay, it returns a requeue error until the Pod is safe to be restarted
+func deregisterTiDBPod(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, pod *corev1.Pod) error {
+	ns := tc.GetNamespace()
+	_, cond := podutil.GetPodCondition(&pod.Status, v1alpha1.TiDBLoadBalancerServing)
+	if cond == nil || cond.Status != corev1.ConditionFalse || cond.Reason != loadBalancerDeregisteringReason {
+		_, err := deps.PodControl.UpdatePodCondition(tc, pod, corev1.PodCondition{
+			Type:               v1alpha1.TiDBLoadBalancerServing,
+			Status:             corev1.ConditionFalse,
+			Reason:             loadBalancerDeregisteringReason,
+			Message:            "the pod is taken out of service for upgrading",
+			LastTransitionTime: metav1.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to take pod %s/%s out of service, error: %v", ns, pod.Name, err)
+		}
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb pod: [%s] is being deregistered from load balancers", ns, tc.GetName(), pod.Name)
+	}
+
+	delay := tc.TiDBLoadBalancerDeregistrationDelay()
+	if elapsed := time.Since(cond.LastTransitionTime.Time); elapsed < delay {
+		return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb pod: [%s] is being deregistered from load balancers, %s left",
+			ns, tc.GetName(), pod.Name, (delay - elapsed).Round(time.Second))
+	}
+	return nil
+}
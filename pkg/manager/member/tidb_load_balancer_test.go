@@ -0,0 +1,85 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	"k8s.io/utils/pointer"
+)
+
+func TestTiDBLoadBalancerReadiness(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tmm, _, _, indexers := newFakeTiDBMemberManager()
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.LoadBalancerReadiness = &v1alpha1.LoadBalancerReadiness{
+		ReadinessGates:      []corev1.PodConditionType{"target-health.elbv2.k8s.aws/test"},
+		DeregistrationDelay: pointer.StringPtr("1m"),
+	}
+	g.Expect(tidbLoadBalancerReadinessGates(tc)).To(Equal([]corev1.PodReadinessGate{
+		{ConditionType: v1alpha1.TiDBLoadBalancerServing},
+		{ConditionType: "target-health.elbv2.k8s.aws/test"},
+	}))
+
+	indexers.pod.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-tidb-0",
+			Namespace: tc.Namespace,
+			Labels:    label.New().Instance(tc.GetInstanceName()).TiDB().Labels(),
+		},
+	})
+	getCondition := func() *corev1.PodCondition {
+		pod, err := tmm.deps.PodLister.Pods(tc.Namespace).Get("test-tidb-0")
+		g.Expect(err).NotTo(HaveOccurred())
+		_, cond := podutil.GetPodCondition(&pod.Status, v1alpha1.TiDBLoadBalancerServing)
+		return cond
+	}
+
+	g.Expect(tmm.syncTiDBLoadBalancerServing(tc)).To(Succeed())
+	g.Expect(getCondition().Status).To(Equal(corev1.ConditionTrue))
+
+	// the pod is taken out of service before upgrading
+	tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+	pod, _ := tmm.deps.PodLister.Pods(tc.Namespace).Get("test-tidb-0")
+	err := deregisterTiDBPod(tmm.deps, tc, pod)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(getCondition().Status).To(Equal(corev1.ConditionFalse))
+
+	// the pod is kept out of service during the upgrade
+	g.Expect(tmm.syncTiDBLoadBalancerServing(tc)).To(Succeed())
+	g.Expect(getCondition().Status).To(Equal(corev1.ConditionFalse))
+
+	pod, _ = tmm.deps.PodLister.Pods(tc.Namespace).Get("test-tidb-0")
+	err = deregisterTiDBPod(tmm.deps, tc, pod)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+
+	// the deregistration delay is passed
+	pod = pod.DeepCopy()
+	pod.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	g.Expect(deregisterTiDBPod(tmm.deps, tc, pod)).To(Succeed())
+
+	// the pod is put back in service if the upgrade is aborted
+	tc.Status.TiDB.Phase = v1alpha1.NormalPhase
+	g.Expect(tmm.syncTiDBLoadBalancerServing(tc)).To(Succeed())
+	g.Expect(getCondition().Status).To(Equal(corev1.ConditionTrue))
+}
@@ -21,6 +21,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
@@ -70,6 +71,10 @@ type tidbMemberManager struct {
 	suspender    suspender.Suspender
 
 	tidbStatefulSetIsUpgradingFn func(corelisters.PodLister, *apps.StatefulSet, *v1alpha1.TidbCluster) (bool, error)
+	tidbWarmUpFn                 func(*controller.Dependencies, *v1alpha1.TidbCluster, *corev1.Pod) error
+
+	// serverZoneLabels records the Pods, the restarts and the zones the zone labels of the TiDB servers are set for
+	serverZoneLabels sync.Map
 }
 
 // NewTiDBMemberManager returns a *tidbMemberManager
@@ -81,6 +86,7 @@ func NewTiDBMemberManager(deps *controller.Dependencies, scaler Scaler, tidbUpgr
 		tidbFailover:                 tidbFailover,
 		suspender:                    spder,
 		tidbStatefulSetIsUpgradingFn: tidbStatefulSetIsUpgrading,
+		tidbWarmUpFn:                 warmUpTiDB,
 	}
 }
 
@@ -122,6 +128,18 @@ func (m *tidbMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
+	if err := m.syncTiDBTopologyAwareRouting(tc); err != nil {
+		return err
+	}
+
+	if err := m.syncTiDBLoadBalancerServing(tc); err != nil {
+		return err
+	}
+
+	if err := m.syncTiDBWarmUp(tc); err != nil {
+		return err
+	}
+
 	if tc.Spec.TiDB.IsTLSClientEnabled() {
 		if err := m.checkTLSClientCert(tc); err != nil {
 			return err
@@ -228,6 +246,7 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 	if err != nil {
 		return err
 	}
+	useNativeSidecars(m.deps, newTiDBSet, oldTiDBSet, v1alpha1.ContainerSlowLogTailer.String())
 
 	if setNotExist {
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newTiDBSet)
@@ -251,7 +270,7 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		return err
 	}
 
-	if m.deps.CLIConfig.AutoFailover {
+	if m.deps.CLIConfig.IsAutoFailover() {
 		if m.shouldRecover(tc) {
 			m.tidbFailover.Recover(tc)
 		} else if tc.TiDBAllPodsStarted() && !tc.TiDBAllMembersReady() {
@@ -418,7 +437,11 @@ func (m *tidbMemberManager) syncTiDBService(tc *v1alpha1.TidbCluster) error {
 	if newSvc == nil {
 		return nil
 	}
+	return m.createOrUpdateTiDBService(tc, newSvc)
+}
 
+// createOrUpdateTiDBService creates the TiDB service or updates it if it's changed
+func (m *tidbMemberManager) createOrUpdateTiDBService(tc *v1alpha1.TidbCluster, newSvc *corev1.Service) error {
 	ns := newSvc.Namespace
 
 	oldSvcTmp, err := m.deps.ServiceLister.Services(ns).Get(newSvc.Name)
@@ -484,6 +507,7 @@ func (m *tidbMemberManager) syncTiDBService(tc *v1alpha1.TidbCluster) error {
 // syncTiDBConfigMap syncs the configmap of tidb
 func (m *tidbMemberManager) syncTiDBConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
 
+	tc.Status.TiDB.DerivedConfig = tidbDerivedConfig(tc)
 	// For backward compatibility, only sync tidb configmap when .tidb.config is non-nil
 	if tc.Spec.TiDB.Config == nil {
 		return nil, nil
@@ -529,6 +553,8 @@ func getTiDBConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		config.Set("security.ssl-cert", path.Join(serverCertPath, corev1.TLSCertKey))
 		config.Set("security.ssl-key", path.Join(serverCertPath, corev1.TLSPrivateKeyKey))
 	}
+	setTiDBTmpStorageQuota(tc.Spec.TiDB.EphemeralStorage, config.GenericConfig)
+	deriveTiDBConfig(tc, config.GenericConfig, tc.Spec.TiDB.ResourceRequirements)
 	confText, err := config.MarshalTOML()
 	if err != nil {
 		return nil, err
@@ -537,13 +563,15 @@ func getTiDBConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 	plugins := tc.Spec.TiDB.Plugins
 	tidbStartScriptModel := &TidbStartScriptModel{
 		CommonModel: CommonModel{
-			AcrossK8s:     tc.AcrossK8s(),
-			ClusterDomain: tc.Spec.ClusterDomain,
+			AcrossK8s:              tc.AcrossK8s(),
+			BlockingPDVerification: tc.BlockingPDVerification(),
+			ClusterDomain:          tc.Spec.ClusterDomain,
 		},
 		EnablePlugin:    len(plugins) > 0,
 		PluginDirectory: "/plugins",
 		PluginList:      strings.Join(plugins, ","),
 	}
+	setStartScriptHooks(&tidbStartScriptModel.CommonModel, tc.Spec.TiDB.StartScriptHooks)
 
 	tidbStartScriptModel.Path = "${CLUSTER_NAME}-pd:2379"
 	if tc.AcrossK8s() {
@@ -638,6 +666,15 @@ func getNewTiDBServiceOrNil(tc *v1alpha1.TidbCluster) *corev1.Service {
 	if svcSpec.ClusterIP != nil {
 		tidbSvc.Spec.ClusterIP = *svcSpec.ClusterIP
 	}
+	if routing := svcSpec.TopologyAwareRouting; routing != nil && routing.Mode == v1alpha1.TopologyAwareRoutingHints {
+		if _, ok := tidbSvc.Annotations[topologyAwareHintsAnnotation]; !ok {
+			if tidbSvc.Annotations == nil {
+				tidbSvc.Annotations = map[string]string{}
+			}
+			tidbSvc.Annotations[topologyAwareHintsAnnotation] = "auto"
+		}
+	}
+	setExternalDNSAnnotations(tidbSvc, tc, v1alpha1.TiDBMemberType)
 	return tidbSvc
 }
 
@@ -768,6 +805,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 					// ref:https://kubernetes.io/docs/concepts/workloads/pods/init-containers/#resources
 					Resources: controller.ContainerResource(tc.Spec.TiDB.ResourceRequirements),
 				})
+				setHelperInitializer(tc, &initContainers[len(initContainers)-1])
 			}
 		}
 	}
@@ -783,6 +821,10 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	storageVolMounts, additionalPVCs := util.BuildStorageVolumeAndVolumeMount(tc.Spec.TiDB.StorageVolumes, tc.Spec.TiDB.StorageClassName, v1alpha1.TiDBMemberType)
 	volMounts = append(volMounts, storageVolMounts...)
 	volMounts = append(volMounts, tc.Spec.TiDB.AdditionalVolumeMounts...)
+	if hooksMount, hooksVolume := startScriptHooksMountVolume(tc.Spec.TiDB.StartScriptHooks); hooksMount != nil {
+		volMounts = append(volMounts, *hooksMount)
+		vols = append(vols, *hooksVolume)
+	}
 
 	var containers []corev1.Container
 	slowLogFileEnvVal := ""
@@ -914,6 +956,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 			c.ReadinessProbe.PeriodSeconds = *tc.Spec.TiDB.ReadinessProbe.PeriodSeconds
 		}
 	}
+	setProbes(&c, tc.Spec.TiDB.Probes, probeHandlers{port: 4000, statusPort: 10080, statusPath: "/status", command: buildTiDBProbeCommand(tc)})
 
 	containers = append(containers, c)
 
@@ -928,10 +971,14 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	podSpec.Volumes = append(vols, baseTiDBSpec.AdditionalVolumes()...)
 	podSpec.SecurityContext = podSecurityContext
 	podSpec.InitContainers = append(initContainers, baseTiDBSpec.InitContainers()...)
+	tc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.TiDBMemberType.String())
+	applyEphemeralStorage(baseTiDBSpec.EphemeralStorage(), &podSpec, v1alpha1.TiDBMemberType.String())
 	podSpec.ServiceAccountName = tc.Spec.TiDB.ServiceAccount
 	if podSpec.ServiceAccountName == "" {
 		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
 	}
+	podSpec.ReadinessGates = append(podSpec.ReadinessGates, tidbLoadBalancerReadinessGates(tc)...)
+	podSpec.ReadinessGates = append(podSpec.ReadinessGates, tidbWarmUpReadinessGates(tc)...)
 
 	stsLabels := label.New().Instance(instanceName).TiDB()
 	podLabels := util.CombineStringMap(stsLabels, baseTiDBSpec.Labels())
@@ -981,6 +1028,22 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	return tidbSet, nil
 }
 
+// isSchemaLoaded returns whether the tidb server has loaded the schema, a tidb server responding
+// to the status probe is not able to serve the SQL requests before the schema is loaded. The
+// server is regarded as loaded if the schema loading state is unknown to respect the probe result.
+func (m *tidbMemberManager) isSchemaLoaded(tc *v1alpha1.TidbCluster, podName string) bool {
+	client := m.deps.TiDBControl.GetTiDBPodClient(tc, podName)
+	loaded, err := client.IsSchemaLoaded()
+	if err != nil {
+		klog.V(4).Infof("failed to get the schema loading state of tidb %s/%s, error: %v", tc.GetNamespace(), podName, err)
+		return true
+	}
+	if !loaded {
+		klog.Infof("tidb %s/%s has not loaded the schema, regard it as unhealthy", tc.GetNamespace(), podName)
+	}
+	return loaded
+}
+
 func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
 	if set == nil {
 		// skip if not created yet
@@ -1010,12 +1073,17 @@ func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 		if err != nil {
 			return err
 		}
+		oldTidbMember, exist := tc.Status.TiDB.Members[name]
+		// the schema is loaded once after the tidb server starts, so it's only checked until the
+		// member turns healthy, a restarted tidb server fails the status probe before loading it
+		if health && !(exist && oldTidbMember.Health) {
+			health = m.isSchemaLoaded(tc, name)
+		}
 
 		newTidbMember := v1alpha1.TiDBMember{
 			Name:   name,
 			Health: health,
 		}
-		oldTidbMember, exist := tc.Status.TiDB.Members[name]
 
 		newTidbMember.LastTransitionTime = metav1.Now()
 		if exist {
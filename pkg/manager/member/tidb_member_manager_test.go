@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/manager/suspender"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/tidbapi"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -822,6 +823,23 @@ type fakeIndexers struct {
 	ti     cache.Indexer
 }
 
+func TestTiDBMemberManagerIsSchemaLoaded(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForPD()
+	tmm, _, _, _ := newFakeTiDBMemberManager()
+	podName := controller.TiDBMemberName(tc.GetName()) + "-0"
+
+	// the schema loading state is unknown
+	g.Expect(tmm.isSchemaLoaded(tc, podName)).To(BeTrue())
+
+	client := tidbapi.NewFakeTiDBClient()
+	client.AddReaction(tidbapi.IsSchemaLoadedActionType, func(action *tidbapi.Action) (interface{}, error) {
+		return false, nil
+	})
+	tmm.deps.TiDBControl.(*controller.FakeTiDBControl).SetTiDBPodClient(podName, client)
+	g.Expect(tmm.isSchemaLoaded(tc, podName)).To(BeFalse())
+}
+
 func newFakeTiDBMemberManager() (*tidbMemberManager, *controller.FakeStatefulSetControl, *controller.FakeTiDBControl, *fakeIndexers) {
 	fakeDeps := controller.NewFakeDependencies()
 	tmm := &tidbMemberManager{
@@ -830,6 +848,7 @@ func newFakeTiDBMemberManager() (*tidbMemberManager, *controller.FakeStatefulSet
 		tidbUpgrader:                 NewFakeTiDBUpgrader(),
 		tidbFailover:                 NewFakeTiDBFailover(),
 		tidbStatefulSetIsUpgradingFn: tidbStatefulSetIsUpgrading,
+		tidbWarmUpFn:                 warmUpTiDB,
 		suspender:                    suspender.NewFakeSuspender(),
 	}
 	indexers := &fakeIndexers{
@@ -0,0 +1,144 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// topologyAwareHintsAnnotation enables the topology aware hints of a Service
+const topologyAwareHintsAnnotation = "service.kubernetes.io/topology-aware-hints"
+
+// syncTiDBTopologyAwareRouting labels the TiDB Pods with their zones and syncs the Services of the zones,
+// the zones are also set to the TiDB servers for the topology profile
+func (m *tidbMemberManager) syncTiDBTopologyAwareRouting(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Paused {
+		return nil
+	}
+	var routing *v1alpha1.TopologyAwareRouting
+	if tc.Spec.TiDB.Service != nil {
+		routing = tc.Spec.TiDB.Service.TopologyAwareRouting
+	}
+
+	if routing != nil || tc.Spec.TopologyProfile != nil {
+		if err := m.syncTiDBZoneLabels(tc); err != nil {
+			return err
+		}
+	}
+	if tc.Spec.TopologyProfile != nil {
+		m.syncTiDBServerZoneLabels(tc)
+	}
+
+	zones := sets.NewString()
+	if routing != nil && routing.Mode == v1alpha1.TopologyAwareRoutingZoneServices {
+		zones.Insert(routing.Zones...)
+	}
+	for _, zone := range zones.List() {
+		if err := m.createOrUpdateTiDBService(tc, getNewTiDBZoneService(tc, zone)); err != nil {
+			return err
+		}
+	}
+	return m.deleteTiDBZoneServices(tc, zones)
+}
+
+// syncTiDBZoneLabels labels the scheduled TiDB Pods with the zones of their nodes
+func (m *tidbMemberManager) syncTiDBZoneLabels(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	if !m.deps.CLIConfig.HasNodePermission() {
+		klog.V(4).Infof("TidbCluster: [%s/%s], no permission for nodes, skip labeling TiDB pods with zones", ns, tc.GetName())
+		return nil
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiDB().Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncTiDBZoneLabels: failed to list pods for cluster %s/%s, selector %s, error: %v", ns, tc.GetName(), selector, err)
+	}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		node, err := m.deps.NodeLister.Get(pod.Spec.NodeName)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("syncTiDBZoneLabels: failed to get node %s for pod %s/%s, error: %v", pod.Spec.NodeName, ns, pod.Name, err)
+		}
+		zone, ok := nodeZone(node.Labels)
+		if !ok || pod.Labels[label.ZoneLabelKey] == zone {
+			continue
+		}
+
+		newPod := pod.DeepCopy()
+		if newPod.Labels == nil {
+			newPod.Labels = map[string]string{}
+		}
+		newPod.Labels[label.ZoneLabelKey] = zone
+		if _, err := m.deps.PodControl.UpdatePod(tc, newPod); err != nil {
+			return fmt.Errorf("syncTiDBZoneLabels: failed to label pod %s/%s with zone %s, error: %v", ns, pod.Name, zone, err)
+		}
+	}
+	return nil
+}
+
+// deleteTiDBZoneServices deletes the Services of the zones not in zones
+func (m *tidbMemberManager) deleteTiDBZoneServices(tc *v1alpha1.TidbCluster, zones sets.String) error {
+	ns := tc.GetNamespace()
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiDB().UsedByEndUser().Selector()
+	if err != nil {
+		return err
+	}
+	svcs, err := m.deps.ServiceLister.Services(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("deleteTiDBZoneServices: failed to list services for cluster %s/%s, selector %s, error: %v", ns, tc.GetName(), selector, err)
+	}
+	for _, svc := range svcs {
+		zone, ok := svc.Labels[label.ZoneLabelKey]
+		if !ok || zones.Has(zone) || svc.Name != controller.TiDBZoneServiceName(tc.GetName(), zone) {
+			continue
+		}
+		if err := m.deps.ServiceControl.DeleteService(tc, svc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getNewTiDBZoneService returns the Service which only selects the TiDB Pods in the zone,
+// it's the same as the TiDB Service except the node ports and the IPs
+func getNewTiDBZoneService(tc *v1alpha1.TidbCluster, zone string) *corev1.Service {
+	svc := getNewTiDBServiceOrNil(tc)
+	svc.Name = controller.TiDBZoneServiceName(tc.GetName(), zone)
+	svc.Labels[label.ZoneLabelKey] = zone
+	svc.Spec.Selector = label.New().Instance(tc.GetInstanceName()).TiDB().Zone(zone).Labels()
+	svc.Spec.ClusterIP = ""
+	svc.Spec.LoadBalancerIP = ""
+	for i := range svc.Spec.Ports {
+		svc.Spec.Ports[i].NodePort = 0
+	}
+	return svc
+}
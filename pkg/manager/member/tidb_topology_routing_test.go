@@ -0,0 +1,85 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncTiDBTopologyAwareRouting(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tmm, _, _, indexers := newFakeTiDBMemberManager()
+	tc := newTidbClusterForTiDB()
+	mysqlNodePort := 30000
+	tc.Spec.TiDB.Service = &v1alpha1.TiDBServiceSpec{
+		ServiceSpec:   v1alpha1.ServiceSpec{Type: corev1.ServiceTypeNodePort},
+		MySQLNodePort: &mysqlNodePort,
+		TopologyAwareRouting: &v1alpha1.TopologyAwareRouting{
+			Mode:  v1alpha1.TopologyAwareRoutingZoneServices,
+			Zones: []string{"zone-a", "zone-b"},
+		},
+	}
+
+	nodeIndexer := tmm.deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	nodeIndexer.Add(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{corev1.LabelTopologyZone: "zone-a"}},
+	})
+	podLabels := label.New().Instance(tc.GetInstanceName()).TiDB().Labels()
+	indexers.pod.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tidb-0", Namespace: tc.Namespace, Labels: podLabels},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+	})
+	indexers.pod.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tidb-1", Namespace: tc.Namespace, Labels: podLabels},
+	})
+
+	g.Expect(tmm.syncTiDBTopologyAwareRouting(tc)).To(Succeed())
+
+	pod, err := tmm.deps.PodLister.Pods(tc.Namespace).Get("test-tidb-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pod.Labels[label.ZoneLabelKey]).To(Equal("zone-a"))
+	pod, err = tmm.deps.PodLister.Pods(tc.Namespace).Get("test-tidb-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pod.Labels).NotTo(HaveKey(label.ZoneLabelKey))
+
+	for _, zone := range []string{"zone-a", "zone-b"} {
+		svc, err := tmm.deps.ServiceLister.Services(tc.Namespace).Get("test-tidb-" + zone)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(svc.Spec.Selector).To(HaveKeyWithValue(label.ZoneLabelKey, zone))
+		g.Expect(svc.Spec.Ports[0].NodePort).To(BeZero())
+	}
+}
+
+func TestGetNewTiDBServiceWithTopologyAwareHints(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.Service = &v1alpha1.TiDBServiceSpec{
+		TopologyAwareRouting: &v1alpha1.TopologyAwareRouting{Mode: v1alpha1.TopologyAwareRoutingHints},
+	}
+	svc := getNewTiDBServiceOrNil(tc)
+	g.Expect(svc.Annotations).To(HaveKeyWithValue(topologyAwareHintsAnnotation, "auto"))
+
+	// the annotation set by users is respected
+	tc.Spec.TiDB.Service.Annotations = map[string]string{topologyAwareHintsAnnotation: "disabled"}
+	svc = getNewTiDBServiceOrNil(tc)
+	g.Expect(svc.Annotations).To(HaveKeyWithValue(topologyAwareHintsAnnotation, "disabled"))
+}
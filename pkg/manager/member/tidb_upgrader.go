@@ -20,12 +20,16 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
 
-	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 )
 
+// MaxResignDDLOwnerCount is the max number of attempts to move the ddl owner out of a tidb pod
+// before upgrading it, the pod is upgraded anyway after that
+const MaxResignDDLOwnerCount = 3
+
 type tidbUpgrader struct {
 	deps *controller.Dependencies
 }
@@ -40,8 +44,10 @@ func NewTiDBUpgrader(deps *controller.Dependencies) Upgrader {
 func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSet, newSet *apps.StatefulSet) error {
 	// when scale replica to 0 , all nodes crash and tidb is in upgrade phase, this method will throw error about pod is upgrade.
 	// so  directly return nil when scale replica to 0.
+	// The Pods being scaled in are not upgraded, the new template is applied after all of them
+	// are removed, so that the Pods are created with it when scaling out again.
 	if tc.Spec.TiDB.Replicas == int32(0) {
-		return nil
+		return keepTemplateUntilScaledToZero(oldSet, newSet)
 	}
 
 	ns := tc.GetNamespace()
@@ -86,7 +92,7 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 	}
 
 	mngerutils.SetUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
-	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
+	podOrdinals := mngerutils.GetUpgradeOrdinals(oldSet, newSet)
 	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
 		i := podOrdinals[_i]
 		podName := tidbPodName(tcName, i)
@@ -108,17 +114,65 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 			}
 			continue
 		}
-		return u.upgradeTiDBPod(tc, i, newSet)
+		return u.upgradeTiDBPod(tc, pod, i, newSet)
 	}
 
 	return nil
 }
 
-func (u *tidbUpgrader) upgradeTiDBPod(tc *v1alpha1.TidbCluster, ordinal int32, newSet *apps.StatefulSet) error {
+func (u *tidbUpgrader) upgradeTiDBPod(tc *v1alpha1.TidbCluster, pod *corev1.Pod, ordinal int32, newSet *apps.StatefulSet) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	// the ddl owner can not be moved out of the only tidb server
+	if member, exist := tc.Status.TiDB.Members[pod.Name]; exist && member.Health && tc.TiDBStsDesiredReplicas() > 1 {
+		resigned, err := u.resignDDLOwner(tc, pod.Name)
+		if !resigned && tc.Status.TiDB.ResignDDLOwnerRetryCount < MaxResignDDLOwnerCount {
+			tc.Status.TiDB.ResignDDLOwnerRetryCount++
+			if err != nil {
+				return fmt.Errorf("tidbcluster: [%s/%s]'s tidb pod: [%s] failed to resign ddl owner, error: %v", ns, tcName, pod.Name, err)
+			}
+			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb pod: [%s] is the ddl owner, wait for the ownership to be transferred", ns, tcName, pod.Name)
+		}
+		if !resigned {
+			klog.Warningf("tidbcluster: [%s/%s]'s tidb pod: [%s] is upgraded without resigning ddl owner after %d attempts, error: %v",
+				ns, tcName, pod.Name, tc.Status.TiDB.ResignDDLOwnerRetryCount, err)
+		}
+	}
+	tc.Status.TiDB.ResignDDLOwnerRetryCount = 0
+
+	if tc.Spec.TiDB.LoadBalancerReadiness != nil {
+		if err := deregisterTiDBPod(u.deps, tc, pod); err != nil {
+			return err
+		}
+	}
 	mngerutils.SetUpgradePartition(newSet, ordinal)
 	return nil
 }
 
+// resignDDLOwner resigns the ddl owner if the tidb pod is the owner, so that the running ddl
+// jobs are taken over by another tidb server before the pod is restarted instead of waiting
+// for the lease of the owner to expire. It returns true once the pod is not the ddl owner.
+func (u *tidbUpgrader) resignDDLOwner(tc *v1alpha1.TidbCluster, podName string) (bool, error) {
+	client := u.deps.TiDBControl.GetTiDBPodClient(tc, podName)
+	info, err := client.GetInfo()
+	if err != nil {
+		return false, err
+	}
+	if !info.IsOwner {
+		return true, nil
+	}
+	resigned, err := client.ResignDDLOwner()
+	if err != nil {
+		return false, err
+	}
+	if resigned {
+		klog.Infof("tidbcluster: [%s/%s]'s tidb pod: [%s] resigned ddl owner", tc.GetNamespace(), tc.GetName(), podName)
+	}
+	// wait for the next round to check the ownership is transferred, another
+	// tidb server is elected as the owner
+	return false, nil
+}
+
 type fakeTiDBUpgrader struct{}
 
 // NewFakeTiDBUpgrader returns a fake tidb upgrader
@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/tidbapi"
 
 	. "github.com/onsi/gomega"
 	apps "k8s.io/api/apps/v1"
@@ -38,14 +39,17 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 		changeFn                func(*v1alpha1.TidbCluster)
 		changePods              func(pods []*corev1.Pod)
 		getLastAppliedConfigErr bool
+		ddlOwner                string
+		resignExpect            bool
 		errorExpect             bool
 		changeOldSet            func(set *apps.StatefulSet)
+		changeNewSet            func(set *apps.StatefulSet)
 		expectFn                func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet)
 	}
 
 	testFn := func(test *testcase, t *testing.T) {
 		t.Log(test.name)
-		upgrader, _, podInformer := newTiDBUpgrader()
+		upgrader, tidbControl, podInformer := newTiDBUpgrader()
 		tc := newTidbClusterForTiDBUpgrader()
 		if test.changeFn != nil {
 			test.changeFn(tc)
@@ -54,8 +58,20 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 		if test.changePods != nil {
 			test.changePods(pods)
 		}
+		resigned := map[string]bool{}
 		for _, pod := range pods {
 			podInformer.Informer().GetIndexer().Add(pod)
+
+			podName := pod.Name
+			client := tidbapi.NewFakeTiDBClient()
+			client.AddReaction(tidbapi.GetInfoActionType, func(action *tidbapi.Action) (interface{}, error) {
+				return &tidbapi.DBInfo{IsOwner: podName == test.ddlOwner}, nil
+			})
+			client.AddReaction(tidbapi.ResignDDLOwnerActionType, func(action *tidbapi.Action) (interface{}, error) {
+				resigned[podName] = true
+				return true, nil
+			})
+			tidbControl.SetTiDBPodClient(podName, client)
 		}
 
 		oldSet := newStatefulSetForTiDBUpgrader()
@@ -64,6 +80,9 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 		}
 
 		newSet := oldSet.DeepCopy()
+		if test.changeNewSet != nil {
+			test.changeNewSet(newSet)
+		}
 		if test.getLastAppliedConfigErr {
 			oldSet.SetAnnotations(map[string]string{LastAppliedConfigAnnotation: "fake apply config"})
 		} else {
@@ -76,6 +95,9 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 			g.Expect(err).NotTo(HaveOccurred())
 		}
 		test.expectFn(g, tc, newSet)
+		if test.ddlOwner != "" {
+			g.Expect(resigned[test.ddlOwner]).To(Equal(test.resignExpect))
+		}
 	}
 
 	tests := []*testcase{
@@ -91,6 +113,53 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
 			},
 		},
+		{
+			name: "resign ddl owner before upgrading",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+			},
+			getLastAppliedConfigErr: false,
+			ddlOwner:                "upgrader-tidb-0",
+			resignExpect:            true,
+			errorExpect:             true,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(tc.Status.TiDB.ResignDDLOwnerRetryCount).To(Equal(int32(1)))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
+			},
+		},
+		{
+			name: "upgrade ddl owner after max attempts of resigning",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Status.TiDB.ResignDDLOwnerRetryCount = MaxResignDDLOwnerCount
+			},
+			getLastAppliedConfigErr: false,
+			ddlOwner:                "upgrader-tidb-0",
+			resignExpect:            true,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(tc.Status.TiDB.ResignDDLOwnerRetryCount).To(Equal(int32(0)))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
+			},
+		},
+		{
+			name: "skip resigning ddl owner of the only tidb",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.PD.Phase = v1alpha1.NormalPhase
+				tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+				tc.Spec.TiDB.Replicas = 1
+			},
+			getLastAppliedConfigErr: false,
+			ddlOwner:                "upgrader-tidb-0",
+			resignExpect:            false,
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).To(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(0)))
+			},
+		},
 		{
 			name: "normal with notReady pod",
 			changePods: func(pods []*corev1.Pod) {
@@ -227,6 +296,35 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 				g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(1)))
 			},
 		},
+		{
+			name: "keep the template while scaling to zero",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiDB.Replicas = 0
+			},
+			changeNewSet: func(set *apps.StatefulSet) {
+				set.Spec.Template.Spec.Containers[0].Image = "tidb-test-image-2"
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).NotTo(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tidb-test-image"))
+			},
+		},
+		{
+			name: "apply the template after scaled to zero",
+			changeFn: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiDB.Replicas = 0
+			},
+			changeOldSet: func(set *apps.StatefulSet) {
+				set.Spec.Replicas = pointer.Int32Ptr(0)
+			},
+			changeNewSet: func(set *apps.StatefulSet) {
+				set.Spec.Template.Spec.Containers[0].Image = "tidb-test-image-2"
+			},
+			expectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster, newSet *apps.StatefulSet) {
+				g.Expect(tc.Status.TiDB.Phase).NotTo(Equal(v1alpha1.UpgradePhase))
+				g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tidb-test-image-2"))
+			},
+		},
 	}
 
 	for _, test := range tests {
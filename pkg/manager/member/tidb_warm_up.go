@@ -0,0 +1,125 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// warmedUpReason is the reason of TiDBWarmedUp condition when the warm-up statements succeed
+	warmedUpReason = "WarmedUp"
+	// warmUpFailedReason is the reason of TiDBWarmedUp condition when the warm-up statements fail,
+	// the Pod is put in service anyway
+	warmUpFailedReason = "WarmUpFailed"
+)
+
+// tidbWarmUpReadinessGates returns the readiness gates of the TiDB Pods for the warm-up
+func tidbWarmUpReadinessGates(tc *v1alpha1.TidbCluster) []corev1.PodReadinessGate {
+	if tc.Spec.TiDB.WarmUp == nil {
+		return nil
+	}
+	return []corev1.PodReadinessGate{{ConditionType: v1alpha1.TiDBWarmedUp}}
+}
+
+// syncTiDBWarmUp runs the warm-up statements on the TiDB Pods whose containers are ready but
+// not warmed up yet, and sets the TiDBWarmedUp condition of the Pods to put them in service.
+func (m *tidbMemberManager) syncTiDBWarmUp(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiDB.WarmUp == nil {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiDB().Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncTiDBWarmUp: failed to list pods for cluster %s/%s, selector %s, error: %v", ns, tc.GetName(), selector, err)
+	}
+	for _, pod := range pods {
+		_, cond := podutil.GetPodCondition(&pod.Status, v1alpha1.TiDBWarmedUp)
+		if cond != nil && cond.Status == corev1.ConditionTrue {
+			continue
+		}
+		// the tidb server is not able to serve the statements until the readiness probe succeeds
+		_, containersReady := podutil.GetPodCondition(&pod.Status, corev1.ContainersReady)
+		if containersReady == nil || containersReady.Status != corev1.ConditionTrue {
+			klog.V(4).Infof("syncTiDBWarmUp: containers of pod %s/%s are not ready, skip warming up", ns, pod.Name)
+			continue
+		}
+
+		newCond := corev1.PodCondition{
+			Type:               v1alpha1.TiDBWarmedUp,
+			Status:             corev1.ConditionTrue,
+			Reason:             warmedUpReason,
+			LastTransitionTime: metav1.Now(),
+		}
+		if err := m.tidbWarmUpFn(m.deps, tc, pod); err != nil {
+			klog.Warningf("syncTiDBWarmUp: failed to warm up pod %s/%s, put it in service anyway, error: %v", ns, pod.Name, err)
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, warmUpFailedReason, "failed to warm up pod %s: %v", pod.Name, err)
+			newCond.Reason = warmUpFailedReason
+			newCond.Message = err.Error()
+		}
+		if _, err := m.deps.PodControl.UpdatePodCondition(tc, pod, newCond); err != nil {
+			return fmt.Errorf("syncTiDBWarmUp: failed to put pod %s/%s in service, error: %v", ns, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// warmUpTiDB connects to the tidb server of the Pod directly and runs the warm-up statements
+func warmUpTiDB(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, pod *corev1.Pod) error {
+	warmUp := tc.Spec.TiDB.WarmUp
+	user := warmUp.User
+	if user == "" {
+		user = "root"
+	}
+	var password string
+	if warmUp.SecretName != "" {
+		secret, err := deps.SecretLister.Secrets(tc.GetNamespace()).Get(warmUp.SecretName)
+		if err != nil {
+			return fmt.Errorf("failed to get secret %s/%s, error: %v", tc.GetNamespace(), warmUp.SecretName, err)
+		}
+		password = string(secret.Data[constants.TidbPasswordKey])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tc.TiDBWarmUpTimeout())
+	defer cancel()
+	dsn := fmt.Sprintf("%s:%s@tcp(%s.%s.%s:4000)/?charset=utf8mb4,utf8",
+		user, password, pod.GetName(), controller.TiDBPeerMemberName(tc.GetName()), tc.GetNamespace())
+	db, err := util.OpenDB(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	for _, sql := range warmUp.SQLs {
+		if _, err := db.ExecContext(ctx, sql); err != nil {
+			return fmt.Errorf("failed to run %q, error: %v", sql, err)
+		}
+	}
+	return nil
+}
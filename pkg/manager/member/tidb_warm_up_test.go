@@ -0,0 +1,85 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+func TestTiDBWarmUp(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tmm, _, _, indexers := newFakeTiDBMemberManager()
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.WarmUp = &v1alpha1.TiDBWarmUp{SQLs: []string{"SELECT * FROM test.t WHERE id = 1"}}
+	g.Expect(tidbWarmUpReadinessGates(tc)).To(Equal([]corev1.PodReadinessGate{{ConditionType: v1alpha1.TiDBWarmedUp}}))
+
+	warmedUp := map[string]bool{}
+	var warmUpErr error
+	tmm.tidbWarmUpFn = func(_ *controller.Dependencies, _ *v1alpha1.TidbCluster, pod *corev1.Pod) error {
+		warmedUp[pod.Name] = true
+		return warmUpErr
+	}
+
+	for i, ready := range []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse} {
+		indexers.pod.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("test-tidb-%d", i),
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.GetInstanceName()).TiDB().Labels(),
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: ready}},
+			},
+		})
+	}
+	getCondition := func(podName string) *corev1.PodCondition {
+		pod, err := tmm.deps.PodLister.Pods(tc.Namespace).Get(podName)
+		g.Expect(err).NotTo(HaveOccurred())
+		_, cond := podutil.GetPodCondition(&pod.Status, v1alpha1.TiDBWarmedUp)
+		return cond
+	}
+
+	// only the pod whose containers are ready is warmed up
+	g.Expect(tmm.syncTiDBWarmUp(tc)).To(Succeed())
+	g.Expect(warmedUp).To(Equal(map[string]bool{"test-tidb-0": true}))
+	g.Expect(getCondition("test-tidb-0").Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(getCondition("test-tidb-0").Reason).To(Equal(warmedUpReason))
+	g.Expect(getCondition("test-tidb-1")).To(BeNil())
+
+	// the pod is not warmed up again
+	warmedUp = map[string]bool{}
+	g.Expect(tmm.syncTiDBWarmUp(tc)).To(Succeed())
+	g.Expect(warmedUp).To(BeEmpty())
+
+	// the pod is put in service even if the warm-up fails
+	pod, _ := tmm.deps.PodLister.Pods(tc.Namespace).Get("test-tidb-1")
+	pod = pod.DeepCopy()
+	pod.Status.Conditions[0].Status = corev1.ConditionTrue
+	indexers.pod.Update(pod)
+	warmUpErr = fmt.Errorf("table test.t doesn't exist")
+	g.Expect(tmm.syncTiDBWarmUp(tc)).To(Succeed())
+	g.Expect(warmedUp).To(Equal(map[string]bool{"test-tidb-1": true}))
+	g.Expect(getCondition("test-tidb-1").Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(getCondition("test-tidb-1").Reason).To(Equal(warmUpFailedReason))
+}
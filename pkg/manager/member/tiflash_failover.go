@@ -61,7 +61,7 @@ func (f *tiflashFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			// (before it enters into Offline/Tombstone state)
 			continue
 		}
-		deadline := store.LastTransitionTime.Add(f.deps.CLIConfig.TiFlashFailoverPeriod)
+		deadline := store.LastTransitionTime.Add(f.deps.CLIConfig.GetFailoverPeriod(v1alpha1.TiFlashMemberType))
 		exist := false
 		for _, failureStore := range tc.Status.TiFlash.FailureStores {
 			if failureStore.PodName == podName {
@@ -54,6 +54,7 @@ type tiflashMemberManager struct {
 	scaler                   Scaler
 	upgrader                 Upgrader
 	suspender                suspender.Suspender
+	remover                  ComponentRemover
 	statefulSetIsUpgradingFn func(corelisters.PodLister, pdapi.PDControlInterface, *apps.StatefulSet, *v1alpha1.TidbCluster) (bool, error)
 }
 
@@ -65,6 +66,7 @@ func NewTiFlashMemberManager(deps *controller.Dependencies, tiflashFailover Fail
 		scaler:    tiflashScaler,
 		upgrader:  tiflashUpgrader,
 		suspender: spder,
+		remover:   NewComponentRemover(deps),
 	}
 	m.statefulSetIsUpgradingFn = tiflashStatefulSetIsUpgrading
 	return &m
@@ -72,6 +74,9 @@ func NewTiFlashMemberManager(deps *controller.Dependencies, tiflashFailover Fail
 
 // Sync fulfills the manager.Manager interface
 func (m *tiflashMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.ComponentRemoving(v1alpha1.TiFlashMemberType) {
+		return m.remover.Remove(tc, v1alpha1.TiFlashMemberType)
+	}
 	if tc.Spec.TiFlash == nil {
 		return nil
 	}
@@ -199,6 +204,7 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 	if err != nil {
 		return err
 	}
+	useNativeSidecars(m.deps, newSet, oldSet, tiflashLogTailers...)
 	if setNotExist {
 		if !tc.PDIsAvailable() {
 			klog.Infof("TidbCluster: %s/%s, waiting for PD cluster running", ns, tcName)
@@ -229,7 +235,7 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
-	if m.deps.CLIConfig.AutoFailover && tc.Spec.TiFlash.MaxFailoverCount != nil {
+	if m.deps.CLIConfig.IsAutoFailover() && tc.Spec.TiFlash.MaxFailoverCount != nil {
 		if tc.TiFlashAllPodsStarted() && !tc.TiFlashAllStoresReady() {
 			if err := m.failover.Failover(tc); err != nil {
 				return err
@@ -400,6 +406,7 @@ func getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.St
 					// ref:https://kubernetes.io/docs/concepts/workloads/pods/init-containers/#resources
 					Resources: controller.ContainerResource(tc.Spec.TiFlash.ResourceRequirements),
 				})
+				setHelperInitializer(tc, &initContainers[len(initContainers)-1])
 			}
 		}
 	}
@@ -426,30 +433,23 @@ func getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.St
 			},
 		},
 	}
-	script := "set -ex;ordinal=`echo ${POD_NAME} | awk -F- '{print $NF}'`;sed s/POD_NUM/${ordinal}/g /etc/tiflash/config_templ.toml > /data0/config.toml;sed s/POD_NUM/${ordinal}/g /etc/tiflash/proxy_templ.toml > /data0/proxy.toml"
-
-	if tc.AcrossK8s() {
-		var pdAddr string
-		if tc.IsTLSClusterEnabled() {
-			pdAddr = fmt.Sprintf("https://%s-pd:2379", tcName)
-		} else {
-			pdAddr = fmt.Sprintf("http://%s-pd:2379", tcName)
-		}
-		str := `pd_url="%s"
-set +e
-encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
-discovery_url="%s-discovery.%s:10261"
-until result=$(wget -qO- -T 3 http://${discovery_url}/verify/${encoded_domain_url} 2>/dev/null | sed 's/http:\/\///g' | sed 's/https:\/\///g'); do
-echo "waiting for the verification of PD endpoints ..."
-sleep 2
-done
-
-
-sed -i s/PD_ADDR/${result}/g /data0/config.toml
-sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
-`
-		script += "\n"
-		script += fmt.Sprintf(str, pdAddr, tc.GetName(), tc.GetNamespace())
+	initModel := &TiFlashInitScriptModel{
+		CommonModel: CommonModel{
+			AcrossK8s:              tc.AcrossK8s(),
+			BlockingPDVerification: tc.BlockingPDVerification(),
+		},
+		PDAddr:      fmt.Sprintf("%s://%s-pd:2379", tc.Scheme(), tcName),
+		ClusterName: tc.GetName(),
+		Namespace:   tc.GetNamespace(),
+	}
+	setStartScriptHooks(&initModel.CommonModel, spec.StartScriptHooks)
+	if hooksMount, hooksVolume := startScriptHooksMountVolume(spec.StartScriptHooks); hooksMount != nil {
+		initVolMounts = append(initVolMounts, *hooksMount)
+		vols = append(vols, *hooksVolume)
+	}
+	script, err := RenderTiFlashInitScript(initModel)
+	if err != nil {
+		return nil, err
 	}
 
 	initializer := corev1.Container{
@@ -463,9 +463,7 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 		Env:          initEnv,
 		VolumeMounts: initVolMounts,
 	}
-	if spec.Initializer != nil {
-		initializer.Resources = controller.ContainerResource(spec.Initializer.ResourceRequirements)
-	}
+	setHelperInitializer(tc, &initializer, spec.Initializer)
 	initContainers = append(initContainers, initializer)
 
 	stsLabels := labelTiFlash(tc)
@@ -474,7 +472,7 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 	podAnnotations := util.CombineStringMap(controller.AnnProm(8234), baseTiFlashSpec.Annotations())
 	podAnnotations = util.CombineStringMap(controller.AnnAdditionalProm("tiflash.proxy", 20292), podAnnotations)
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiFlashLabelVal)
-	capacity := controller.TiKVCapacity(tc.Spec.TiFlash.Limits)
+	capacity := controller.StoreCapacity(tc.Spec.TiFlash.StoreCapacity, tc.Spec.TiFlash.Limits)
 	headlessSvcName := controller.TiFlashPeerMemberName(tcName)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
@@ -551,6 +549,8 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 		VolumeMounts: volMounts,
 		Resources:    controller.ContainerResource(tc.Spec.TiFlash.ResourceRequirements),
 	}
+	setProbes(&tiflashContainer, tc.Spec.TiFlash.Probes, probeHandlers{port: 3930, statusPort: 20292, statusPath: "/status"})
+	vols = applyPerformanceProfile(tc.Spec.TiFlash.PerformanceProfile, &tiflashContainer, vols)
 	podSpec := baseTiFlashSpec.BuildPodSpec()
 	if baseTiFlashSpec.HostNetwork() {
 		env = append(env, corev1.EnvVar{
@@ -577,6 +577,8 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 	if err != nil {
 		return nil, fmt.Errorf("failed to merge containers spec for TiFlash of [%s/%s], err: %v", ns, tcName, err)
 	}
+	tc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.TiFlashMemberType.String())
+	applyEphemeralStorage(baseTiFlashSpec.EphemeralStorage(), &podSpec, v1alpha1.TiFlashMemberType.String())
 
 	podSpec.ServiceAccountName = tc.Spec.TiFlash.ServiceAccount
 	if podSpec.ServiceAccountName == "" {
@@ -643,6 +645,7 @@ func flashVolumeClaimTemplate(storageClaims []v1alpha1.StorageClaim) ([]corev1.P
 
 func getTiFlashConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 	config := GetTiFlashConfig(tc)
+	setTiFlashComputeResourcesDefault(config.Common, tc.Spec.TiFlash)
 
 	configText, err := config.Common.MarshalTOML()
 	if err != nil {
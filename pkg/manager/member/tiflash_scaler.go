@@ -15,12 +15,16 @@ package member
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	apps "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
@@ -94,6 +98,11 @@ func (s *tiflashScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, ne
 				return err
 			}
 			if state != v1alpha1.TiKVStateOffline {
+				// the store never becomes tombstone if the learner peers of the placement
+				// rules can't be moved to the remaining stores
+				if err := s.checkPlacementRules(tc, store.ID); err != nil {
+					return err
+				}
 				if err := controller.GetPDClient(s.deps.PDControl, tc).DeleteStore(id); err != nil {
 					klog.Errorf("tiflash scale in: failed to delete store %d, %v", id, err)
 					return err
@@ -110,7 +119,16 @@ func (s *tiflashScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, ne
 				return err
 			}
 
-			// TODO: double check if store is really not in Up/Offline/Down state
+			// double check the state in PD as the status may be stale, the data of the
+			// store is not safe to be deleted until all its peers are removed
+			storeInfo, err := controller.GetPDClient(s.deps.PDControl, tc).GetStore(id)
+			if err != nil {
+				if e, ok := err.(*httputil.StatusError); !ok || e.StatusCode != http.StatusNotFound {
+					return err
+				}
+			} else if storeInfo.Store != nil && storeInfo.Store.GetState() != metapb.StoreState_Tombstone {
+				return controller.RequeueErrorf("TiFlash %s/%s store %d is not tombstone in PD, state: %s", ns, podName, id, storeInfo.Store.StateName)
+			}
 			klog.Infof("TiFlash %s/%s store %d becomes tombstone", ns, podName, id)
 
 			err = s.updateDeferDeletingPVC(tc, v1alpha1.TiFlashMemberType, ordinal)
@@ -129,7 +147,7 @@ func (s *tiflashScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, ne
 	// 2. This can happen when TiFlash pod has not been successfully registered in the cluster, such as always pending.
 	//    In this situation we should delete this TiFlash pod immediately to avoid blocking the subsequent operations.
 	if !podutil.IsPodReady(pod) {
-		safeTimeDeadline := pod.CreationTimestamp.Add(5 * s.deps.CLIConfig.ResyncDuration)
+		safeTimeDeadline := pod.CreationTimestamp.Add(5 * s.deps.CLIConfig.GetResyncDuration())
 		if time.Now().Before(safeTimeDeadline) {
 			// Wait for 5 resync periods to ensure that the following situation does not occur:
 			//
@@ -142,7 +160,7 @@ func (s *tiflashScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, ne
 			return fmt.Errorf("TiFlash %s/%s is not ready, wait for some resync periods to synced its status", ns, podName)
 		}
 		klog.Infof("Pod %s/%s not ready for more than %v and no store for it, scale in it",
-			ns, podName, 5*s.deps.CLIConfig.ResyncDuration)
+			ns, podName, 5*s.deps.CLIConfig.GetResyncDuration())
 		err = s.updateDeferDeletingPVC(tc, v1alpha1.TiFlashMemberType, ordinal)
 		if err != nil {
 			return err
@@ -153,6 +171,43 @@ func (s *tiflashScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, ne
 	return fmt.Errorf("tiflash %s/%s no store found in cluster", ns, podName)
 }
 
+// checkPlacementRules returns an error if the TiFlash stores except the given one are not
+// enough for the replicas required by the placement rules of TiFlash
+func (s *tiflashScaler) checkPlacementRules(tc *v1alpha1.TidbCluster, storeID string) error {
+	rules, err := controller.GetPDClient(s.deps.PDControl, tc).GetPlacementRules()
+	if err != nil {
+		return err
+	}
+	remaining := 0
+	for id, store := range tc.Status.TiFlash.Stores {
+		if id != storeID && store.State == v1alpha1.TiKVStateUp {
+			remaining++
+		}
+	}
+	for _, rule := range rules {
+		if isTiFlashPlacementRule(rule) && rule.Count > remaining {
+			return fmt.Errorf("placement rule %s/%s of TidbCluster %s/%s requires %d TiFlash replicas, but only %d TiFlash stores remain after scaling in",
+				rule.GroupID, rule.ID, tc.Namespace, tc.Name, rule.Count, remaining)
+		}
+	}
+	return nil
+}
+
+// isTiFlashPlacementRule returns whether the peers of the rule are placed on TiFlash stores
+func isTiFlashPlacementRule(rule *pdapi.PlacementRule) bool {
+	for _, c := range rule.LabelConstraints {
+		if c.Key != "engine" || c.Op != "in" {
+			continue
+		}
+		for _, v := range c.Values {
+			if v == "tiflash" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type fakeTiFlashScaler struct{}
 
 // NewFakeTiFlashScaler returns a fake tiflash Scaler
@@ -0,0 +1,185 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestTiFlashScalerScaleIn(t *testing.T) {
+	g := NewGomegaWithT(t)
+	type testcase struct {
+		name         string
+		storeFun     func(tc *v1alpha1.TidbCluster)
+		rules        []*pdapi.PlacementRule
+		pdStoreState metapb.StoreState
+		errExpectFn  func(*GomegaWithT, error)
+		storeDeleted bool
+		changed      bool
+	}
+
+	tiflashRule := func(count int) *pdapi.PlacementRule {
+		return &pdapi.PlacementRule{
+			GroupID: "tiflash",
+			ID:      "table-45-r",
+			Role:    "learner",
+			Count:   count,
+			LabelConstraints: []pdapi.LabelConstraint{
+				{Key: "engine", Op: "in", Values: []string{"tiflash"}},
+			},
+		}
+	}
+
+	testFn := func(test testcase, t *testing.T) {
+		tc := newTidbClusterForPD()
+		test.storeFun(tc)
+
+		oldSet := newStatefulSetForPDScale()
+		newSet := oldSet.DeepCopy()
+		newSet.Spec.Replicas = pointer.Int32Ptr(4)
+
+		pod := &corev1.Pod{
+			TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              ordinalPodName(v1alpha1.TiFlashMemberType, tc.GetName(), 4),
+				Namespace:         corev1.NamespaceDefault,
+				CreationTimestamp: metav1.Time{Time: time.Now().Add(-1 * time.Hour)},
+				Labels:            map[string]string{label.StoreIDLabelKey: "1"},
+			},
+		}
+		readyPodFunc(pod)
+
+		fakeDeps := controller.NewFakeDependencies()
+		scaler := &tiflashScaler{generalScaler{deps: fakeDeps}}
+		fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+		pvc := newScaleInPVCForStatefulSet(oldSet, v1alpha1.TiFlashMemberType, tc.Name)
+		fakeDeps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)
+
+		pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+		pdClient.AddReaction(pdapi.GetPlacementRulesActionType, func(action *pdapi.Action) (interface{}, error) {
+			return test.rules, nil
+		})
+		pdClient.AddReaction(pdapi.GetStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+			return &pdapi.StoreInfo{
+				Store: &pdapi.MetaStore{
+					Store:     &metapb.Store{Id: action.ID, State: test.pdStoreState},
+					StateName: test.pdStoreState.String(),
+				},
+			}, nil
+		})
+		storeDeleted := false
+		pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+			storeDeleted = true
+			return nil, nil
+		})
+
+		err := scaler.ScaleIn(tc, oldSet, newSet)
+		test.errExpectFn(g, err)
+		g.Expect(storeDeleted).To(Equal(test.storeDeleted))
+		if test.changed {
+			g.Expect(int(*newSet.Spec.Replicas)).To(Equal(4))
+		} else {
+			g.Expect(int(*newSet.Spec.Replicas)).To(Equal(5))
+		}
+	}
+
+	tests := []testcase{
+		{
+			name:         "up store is deleted",
+			storeFun:     normalTiFlashStoreFun,
+			rules:        []*pdapi.PlacementRule{tiflashRule(2)},
+			errExpectFn:  errExpectRequeue,
+			storeDeleted: true,
+			changed:      false,
+		},
+		{
+			name:     "not enough stores for the placement rules",
+			storeFun: normalTiFlashStoreFun,
+			rules:    []*pdapi.PlacementRule{tiflashRule(5)},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("requires 5 TiFlash replicas"))
+			},
+			storeDeleted: false,
+			changed:      false,
+		},
+		{
+			name:     "rules of other engines are ignored",
+			storeFun: normalTiFlashStoreFun,
+			rules: []*pdapi.PlacementRule{
+				{GroupID: "pd", ID: "default", Role: "voter", Count: 5},
+			},
+			errExpectFn:  errExpectRequeue,
+			storeDeleted: true,
+			changed:      false,
+		},
+		{
+			name:         "tombstone store is not tombstone in PD",
+			storeFun:     tombstoneTiFlashStoreFun,
+			pdStoreState: metapb.StoreState_Offline,
+			errExpectFn:  errExpectRequeue,
+			storeDeleted: false,
+			changed:      false,
+		},
+		{
+			name:         "tombstone store is confirmed in PD",
+			storeFun:     tombstoneTiFlashStoreFun,
+			pdStoreState: metapb.StoreState_Tombstone,
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			storeDeleted: false,
+			changed:      true,
+		},
+	}
+
+	for i := range tests {
+		t.Logf("test: %s", tests[i].name)
+		testFn(tests[i], t)
+	}
+}
+
+func normalTiFlashStoreFun(tc *v1alpha1.TidbCluster) {
+	tc.Status.TiFlash.Stores = map[string]v1alpha1.TiKVStore{}
+	for i, id := range []string{"10", "11", "12", "13", "1"} {
+		tc.Status.TiFlash.Stores[id] = v1alpha1.TiKVStore{
+			ID:      id,
+			PodName: ordinalPodName(v1alpha1.TiFlashMemberType, tc.GetName(), int32(i)),
+			State:   v1alpha1.TiKVStateUp,
+		}
+	}
+}
+
+func tombstoneTiFlashStoreFun(tc *v1alpha1.TidbCluster) {
+	normalTiFlashStoreFun(tc)
+	delete(tc.Status.TiFlash.Stores, "1")
+	tc.Status.TiFlash.TombstoneStores = map[string]v1alpha1.TiKVStore{
+		"1": {
+			ID:      "1",
+			PodName: ordinalPodName(v1alpha1.TiFlashMemberType, tc.GetName(), 4),
+			State:   v1alpha1.TiKVStateTombstone,
+		},
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/util/cmpver"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
@@ -32,6 +33,9 @@ const (
 	defaultServerLog  = "/data0/logs/server.log"
 )
 
+// tiflashLogTailers are the names of the sidecar containers tailing the logs of TiFlash
+var tiflashLogTailers = []string{"serverlog", "errorlog", "clusterlog"}
+
 var (
 	// the first version that tiflash change default config
 	tiflashEqualOrGreaterThanV540, _ = cmpver.NewConstraint(cmpver.GreaterOrEqual, "v5.4.0")
@@ -114,6 +118,51 @@ func GetTiFlashConfig(tc *v1alpha1.TidbCluster) *v1alpha1.TiFlashConfigWraper {
 	return getTiFlashConfig(tc)
 }
 
+// setTiFlashComputeResourcesDefault sets the resource limits of TiFlash according to spec.ComputeResources,
+// the unset ones are derived from the resources of the TiFlash container, because the defaults of TiFlash
+// are based on the resources of the host rather than the cgroup limits.
+func setTiFlashComputeResourcesDefault(config *v1alpha1.TiFlashCommonConfigWraper, spec *v1alpha1.TiFlashSpec) {
+	resources := spec.ComputeResources
+	if resources == nil {
+		return
+	}
+
+	if resources.MaxMemoryUsageForAllQueries != nil {
+		config.SetIfNil("profiles.default.max_memory_usage_for_all_queries", resources.MaxMemoryUsageForAllQueries.Value())
+	} else if memory, ok := containerResourceLimit(spec.ResourceRequirements, corev1.ResourceMemory); ok {
+		config.SetIfNil("profiles.default.max_memory_usage_for_all_queries", memory.Value()/10*8)
+	}
+
+	var maxThreads int64
+	if resources.MaxThreads != nil {
+		maxThreads = int64(*resources.MaxThreads)
+	} else if cpus, ok := dedicatedCPUs(spec.PerformanceProfile, spec.ResourceRequirements); ok {
+		maxThreads = cpus
+	} else if cpu, ok := containerResourceLimit(spec.ResourceRequirements, corev1.ResourceCPU); ok {
+		maxThreads = (cpu.MilliValue() + 999) / 1000
+	}
+	if maxThreads > 0 {
+		config.SetIfNil("profiles.default.max_threads", maxThreads)
+	}
+
+	if resources.BackgroundPoolSize != nil {
+		config.SetIfNil("profiles.default.background_pool_size", int64(*resources.BackgroundPoolSize))
+	} else if maxThreads > 0 {
+		config.SetIfNil("profiles.default.background_pool_size", maxThreads)
+	}
+}
+
+// containerResourceLimit returns the limit of the resource, or the request if the limit is not set
+func containerResourceLimit(req corev1.ResourceRequirements, name corev1.ResourceName) (resource.Quantity, bool) {
+	if q, ok := req.Limits[name]; ok && !q.IsZero() {
+		return q, true
+	}
+	if q, ok := req.Requests[name]; ok && !q.IsZero() {
+		return q, true
+	}
+	return resource.Quantity{}, false
+}
+
 func getTiFlashConfigV2(tc *v1alpha1.TidbCluster) *v1alpha1.TiFlashConfigWraper {
 	config := tc.Spec.TiFlash.Config.DeepCopy()
 	if config == nil {
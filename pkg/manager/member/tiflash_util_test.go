@@ -1897,3 +1897,51 @@ func mustFromOldConfig(old *v1alpha1.TiFlashConfig) *v1alpha1.TiFlashConfigWrape
 
 	return config
 }
+
+func TestSetTiFlashComputeResourcesDefault(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	spec := &v1alpha1.TiFlashSpec{
+		ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3500m"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+		},
+	}
+
+	// nothing is derived if computeResources is not set
+	config := v1alpha1.NewTiFlashCommonConfig()
+	setTiFlashComputeResourcesDefault(config, spec)
+	g.Expect(config.Get("profiles.default.max_threads")).To(BeNil())
+
+	spec.ComputeResources = &v1alpha1.TiFlashComputeResources{}
+	config = v1alpha1.NewTiFlashCommonConfig()
+	setTiFlashComputeResourcesDefault(config, spec)
+	g.Expect(config.Get("profiles.default.max_memory_usage_for_all_queries").MustInt()).To(Equal(int64(10 << 30 / 10 * 8)))
+	g.Expect(config.Get("profiles.default.max_threads").MustInt()).To(Equal(int64(4)))
+	g.Expect(config.Get("profiles.default.background_pool_size").MustInt()).To(Equal(int64(4)))
+
+	// the dedicated CPUs are rounded up from the requests
+	spec.PerformanceProfile = &v1alpha1.PerformanceProfile{DedicatedCPUs: true}
+	config = v1alpha1.NewTiFlashCommonConfig()
+	setTiFlashComputeResourcesDefault(config, spec)
+	g.Expect(config.Get("profiles.default.max_threads").MustInt()).To(Equal(int64(2)))
+
+	// the fields of computeResources and the config take precedence
+	maxMemory := resource.MustParse("4Gi")
+	spec.ComputeResources = &v1alpha1.TiFlashComputeResources{
+		MaxMemoryUsageForAllQueries: &maxMemory,
+		MaxThreads:                  pointer.Int32Ptr(8),
+	}
+	config = v1alpha1.NewTiFlashCommonConfig()
+	config.Set("profiles.default.background_pool_size", int64(1))
+	setTiFlashComputeResourcesDefault(config, spec)
+	g.Expect(config.Get("profiles.default.max_memory_usage_for_all_queries").MustInt()).To(Equal(int64(4 << 30)))
+	g.Expect(config.Get("profiles.default.max_threads").MustInt()).To(Equal(int64(8)))
+	g.Expect(config.Get("profiles.default.background_pool_size").MustInt()).To(Equal(int64(1)))
+}
@@ -60,7 +60,7 @@ func (f *tikvFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			// (before it enters into Offline/Tombstone state)
 			continue
 		}
-		deadline := store.LastTransitionTime.Add(f.deps.CLIConfig.TiKVFailoverPeriod)
+		deadline := store.LastTransitionTime.Add(f.deps.CLIConfig.GetFailoverPeriod(v1alpha1.TiKVMemberType))
 		exist := false
 		for _, failureStore := range tc.Status.TiKV.FailureStores {
 			if failureStore.PodName == podName {
@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -63,6 +64,10 @@ type tikvMemberManager struct {
 	upgrader                 TiKVUpgrader
 	suspender                suspender.Suspender
 	statefulSetIsUpgradingFn func(corelisters.PodLister, pdapi.PDControlInterface, *apps.StatefulSet, *v1alpha1.TidbCluster) (bool, error)
+	// storeRoleRulesDeleted records the PDs whose placement rules of the store roles are deleted
+	storeRoleRulesDeleted sync.Map
+	// topologyRulesDeleted records the clusters whose placement rules of the topology profile are deleted
+	topologyRulesDeleted sync.Map
 }
 
 // NewTiKVMemberManager returns a *tikvMemberManager
@@ -188,6 +193,9 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	if err := m.syncTiKVClusterStatus(tc, oldSet); err != nil {
 		return err
 	}
+	if err := m.syncTiKVSysctlStatus(tc); err != nil {
+		return err
+	}
 
 	if tc.Spec.Paused {
 		klog.V(4).Infof("tikv cluster %s/%s is paused, skip syncing for tikv statefulset", tc.GetNamespace(), tc.GetName())
@@ -213,6 +221,7 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	if err != nil {
 		return err
 	}
+	useNativeSidecars(m.deps, newSet, oldSet, v1alpha1.ContainerRocksDBLogTailer.String(), v1alpha1.ContainerRaftLogTailer.String())
 	if setNotExist {
 		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newSet)
 		if err != nil {
@@ -226,10 +235,19 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return nil
 	}
 
+	// the location labels of the topology profile are set before the store labels
+	if err := m.syncTopologyProfile(tc); err != nil {
+		return err
+	}
+
 	if _, err := m.setStoreLabelsForTiKV(tc); err != nil {
 		return err
 	}
 
+	if err := m.syncPlacementRulesForStoreRole(tc); err != nil {
+		return err
+	}
+
 	// Scaling takes precedence over upgrading because:
 	// - if a store fails in the upgrading, users may want to delete it or add
 	//   new replicas
@@ -242,7 +260,7 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	// Perform failover logic if necessary. Note that this will only update
 	// TidbCluster status. The actual scaling performs in next sync loop (if a
 	// new replica needs to be added).
-	if m.deps.CLIConfig.AutoFailover && tc.Spec.TiKV.MaxFailoverCount != nil {
+	if m.deps.CLIConfig.IsAutoFailover() && tc.Spec.TiKV.MaxFailoverCount != nil {
 		if tc.TiKVAllPodsStarted() && !tc.TiKVAllStoresReady() {
 			if err := m.failover.Failover(tc); err != nil {
 				return err
@@ -256,10 +274,19 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		}
 	}
 
+	// The drift of sysctls is remediated only if the statefulset is not upgrading,
+	// the pods recreated by the upgrade apply the sysctls again.
+	if templateEqual(newSet, oldSet) && tc.Status.TiKV.Phase == v1alpha1.NormalPhase {
+		if err := m.remediateTiKVSysctlDrift(tc); err != nil {
+			return err
+		}
+	}
+
 	return mngerutils.UpdateStatefulSetWithPrecheck(m.deps, tc, "FailedUpdateTiKVSTS", newSet, oldSet)
 }
 
 func (m *tikvMemberManager) syncTiKVConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
+	tc.Status.TiKV.DerivedConfig = tikvDerivedConfig(tc)
 	// For backward compatibility, only sync tidb configmap when .tikv.config is non-nil
 	if tc.Spec.TiKV.Config == nil {
 		return nil, nil
@@ -397,6 +424,10 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	// handle StorageVolumes and AdditionalVolumeMounts in ComponentSpec
 	storageVolMounts, additionalPVCs := util.BuildStorageVolumeAndVolumeMount(tc.Spec.TiKV.StorageVolumes, tc.Spec.TiKV.StorageClassName, v1alpha1.TiKVMemberType)
 	volMounts = append(volMounts, storageVolMounts...)
+	if hooksMount, hooksVolume := startScriptHooksMountVolume(tc.Spec.TiKV.StartScriptHooks); hooksMount != nil {
+		volMounts = append(volMounts, *hooksMount)
+		vols = append(vols, *hooksVolume)
+	}
 
 	sysctls := "sysctl -w"
 	var initContainers []corev1.Container
@@ -426,6 +457,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 					// ref:https://kubernetes.io/docs/concepts/workloads/pods/init-containers/#resources
 					Resources: controller.ContainerResource(tc.Spec.TiKV.ResourceRequirements),
 				})
+				setHelperInitializer(tc, &initContainers[len(initContainers)-1])
 			}
 		}
 	}
@@ -444,10 +476,13 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 
 	stsLabels := labelTiKV(tc)
 	podLabels := util.CombineStringMap(stsLabels.Labels(), baseTiKVSpec.Labels())
+	if tc.Spec.TiKV.StoreRole != "" {
+		podLabels[label.StoreRoleLabelKey] = storeRoleLabelValue(tc.Spec.TiKV.StoreRole)
+	}
 	setName := controller.TiKVMemberName(tcName)
 	podAnnotations := util.CombineStringMap(controller.AnnProm(20180), baseTiKVSpec.Annotations())
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiKVLabelVal)
-	capacity := controller.TiKVCapacity(tc.Spec.TiKV.Limits)
+	capacity := controller.StoreCapacity(tc.Spec.TiKV.StoreCapacity, tc.Spec.TiKV.Limits)
 	headlessSvcName := controller.TiKVPeerMemberName(tcName)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
@@ -599,6 +634,8 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 		VolumeMounts: volMounts,
 		Resources:    controller.ContainerResource(tc.Spec.TiKV.ResourceRequirements),
 	}
+	vols = applyPerformanceProfile(tc.Spec.TiKV.PerformanceProfile, &tikvContainer, vols)
+	setProbes(&tikvContainer, tc.Spec.TiKV.Probes, probeHandlers{port: 20160, statusPort: 20180, statusPath: "/status"})
 
 	if tc.Spec.TiKV.EnableNamedStatusPort {
 		kvStatusPort := corev1.ContainerPort{
@@ -633,6 +670,8 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to merge containers spec for TiKV of [%s/%s], error: %v", ns, tcName, err)
 	}
+	tc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.TiKVMemberType.String())
+	applyEphemeralStorage(baseTiKVSpec.EphemeralStorage(), &podSpec, v1alpha1.TiKVMemberType.String())
 
 	podSpec.ServiceAccountName = tc.Spec.TiKV.ServiceAccount
 	if podSpec.ServiceAccountName == "" {
@@ -728,8 +767,9 @@ func getTikVConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 
 	scriptModel := &TiKVStartScriptModel{
 		CommonModel: CommonModel{
-			AcrossK8s:     tc.AcrossK8s(),
-			ClusterDomain: tc.Spec.ClusterDomain,
+			AcrossK8s:              tc.AcrossK8s(),
+			BlockingPDVerification: tc.BlockingPDVerification(),
+			ClusterDomain:          tc.Spec.ClusterDomain,
 		},
 		EnableAdvertiseStatusAddr: false,
 		DataDir:                   filepath.Join(tikvDataVolumeMountPath, tc.Spec.TiKV.DataSubDir),
@@ -739,6 +779,8 @@ func getTikVConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		scriptModel.EnableAdvertiseStatusAddr = true
 	}
 
+	setStartScriptHooks(&scriptModel.CommonModel, tc.Spec.TiKV.StartScriptHooks)
+
 	scriptModel.PDAddress = tc.Scheme() + "://${CLUSTER_NAME}-pd:2379"
 	if tc.AcrossK8s() {
 		scriptModel.PDAddress = tc.Scheme() + "://${CLUSTER_NAME}-pd:2379" // get pd addr from discovery in startup script
@@ -797,6 +839,8 @@ func (m *tikvMemberManager) syncTiKVClusterStatus(tc *v1alpha1.TidbCluster, set
 		tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
 	} else {
 		tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+		// no operation is in flight
+		tc.Status.TiKV.InFlightOperation = nil
 	}
 
 	previousStores := tc.Status.TiKV.Stores
@@ -18,6 +18,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
@@ -116,12 +117,16 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 			if err != nil {
 				return err
 			}
-			if state != v1alpha1.TiKVStateOffline {
+			// the store is deleted before tidb-controller-manager restarts, resume from the checkpoint
+			// instead of deleting it again, e.g. when the status of the store is not refreshed yet
+			deleted := checkpointReached(tc.Status.TiKV.InFlightOperation, v1alpha1.ScalePhase, ordinal, v1alpha1.OperationStepStoreDeleted)
+			if state != v1alpha1.TiKVStateOffline && !deleted {
 				if err := controller.GetPDClient(s.deps.PDControl, tc).DeleteStore(id); err != nil {
 					klog.Errorf("tikvScaler.ScaleIn: failed to delete store %d, %v", id, err)
 					return err
 				}
 				klog.Infof("tikvScaler.ScaleIn: delete store %d for tikv %s/%s successfully", id, ns, podName)
+				setCheckpoint(&tc.Status.TiKV.InFlightOperation, v1alpha1.ScalePhase, ordinal, v1alpha1.OperationStepStoreDeleted)
 			}
 			return controller.RequeueErrorf("TiKV %s/%s store %d is still in cluster, state: %s", ns, podName, id, state)
 		}
@@ -171,7 +176,7 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 	//    In this situation we should delete this TiKV pod immediately to avoid blocking the subsequent operations.
 	if !podutil.IsPodReady(pod) {
 		if tc.TiKVBootStrapped() {
-			safeTimeDeadline := pod.CreationTimestamp.Add(5 * s.deps.CLIConfig.ResyncDuration)
+			safeTimeDeadline := pod.CreationTimestamp.Add(5 * s.deps.CLIConfig.GetResyncDuration())
 			if time.Now().Before(safeTimeDeadline) {
 				// Wait for 5 resync periods to ensure that the following situation does not occur:
 				//
@@ -213,7 +218,8 @@ func (s *tikvScaler) preCheckUpStores(tc *v1alpha1.TidbCluster, podName string)
 	// get the number of stores whose state is up
 	upNumber := 0
 
-	storesInfo, err := pdClient.GetStores()
+	// the down stores are also in the Up state of PD
+	storesInfo, err := pdClient.GetStoresByState(metapb.StoreState_Up)
 	if err != nil {
 		return false, fmt.Errorf("failed to get stores info in TidbCluster %s/%s", tc.GetNamespace(), tc.GetName())
 	}
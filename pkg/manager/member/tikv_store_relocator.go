@@ -0,0 +1,266 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// StoreStuckOnUnavailableNode is the reason of the event emitted when a TiKV Pod is pending
+	// because the nodes of its local PVs are unavailable
+	StoreStuckOnUnavailableNode = "StoreStuckOnUnavailableNode"
+	// StoreRelocationStarted is the reason of the event emitted when a store is relocated
+	StoreRelocationStarted = "StoreRelocationStarted"
+)
+
+// TiKVStoreRelocator finds the TiKV Pods which are pending because the only nodes their local PVs
+// can be attached to are cordoned or not ready, and replaces their stores by TidbClusterOperations
+// of type ReplaceDisk once the Pods have been pending for longer than the threshold. The stores are
+// relocated one by one, and a store is relocated at most once each time its Pod gets stuck.
+//
+// If the relocation is disabled, the warning event is emitted once each time a Pod gets stuck.
+//
+// The Pods are not checked if tidb-controller-manager has no permission for nodes or persistent volumes.
+type TiKVStoreRelocator interface {
+	Relocate(tc *v1alpha1.TidbCluster) error
+}
+
+type tikvStoreRelocator struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+	// stuck records since when the Pods reported by the warning event are pending, keyed by namespace/pod
+	stuck sync.Map
+}
+
+// NewTiKVStoreRelocator returns a TiKVStoreRelocator
+func NewTiKVStoreRelocator(deps *controller.Dependencies) TiKVStoreRelocator {
+	return &tikvStoreRelocator{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (r *tikvStoreRelocator) Relocate(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiKV == nil || tc.Spec.TiKV.StoreRelocation == nil {
+		return nil
+	}
+	if r.deps.NodeLister == nil || r.deps.PVLister == nil {
+		return nil
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiKV().Selector()
+	if err != nil {
+		return fmt.Errorf("store relocator: failed to get selector for tc %s/%s, error: %v", tc.Namespace, tc.Name, err)
+	}
+	pods, err := r.deps.PodLister.Pods(tc.Namespace).List(selector)
+	if err != nil {
+		return fmt.Errorf("store relocator: failed to list pods for tc %s/%s, error: %v", tc.Namespace, tc.Name, err)
+	}
+	nodes, err := r.deps.NodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("store relocator: failed to list nodes, error: %v", err)
+	}
+	ops, err := r.replaceDiskOperations(tc)
+	if err != nil {
+		return err
+	}
+
+	threshold := tc.TiKVStoreRelocationPendingThreshold()
+	for _, pod := range pods {
+		since, reason, err := r.stuckReason(pod, nodes)
+		if err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		if reason == "" {
+			r.stuck.Delete(key)
+			continue
+		}
+		if r.now().Sub(since) < threshold {
+			continue
+		}
+		if !tc.Spec.TiKV.StoreRelocation.Enabled {
+			if last, ok := r.stuck.Load(key); ok && last.(time.Time).Equal(since) {
+				continue
+			}
+			r.stuck.Store(key, since)
+			r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, StoreStuckOnUnavailableNode,
+				"Pod %s has been pending since %s, %s", pod.Name, since.Format(time.RFC3339), reason)
+			continue
+		}
+
+		relocated := false
+		for _, op := range ops {
+			if op.Spec.PodName == pod.Name && !op.CreationTimestamp.Time.Before(since) {
+				relocated = true
+			}
+		}
+		if relocated {
+			continue
+		}
+		for _, op := range ops {
+			if op.Status.Phase != v1alpha1.TidbClusterOperationSucceeded && op.Status.Phase != v1alpha1.TidbClusterOperationFailed {
+				klog.Infof("tc %s/%s: skip relocating the store of Pod %s, TidbClusterOperation %s is running", tc.Namespace, tc.Name, pod.Name, op.Name)
+				return nil
+			}
+		}
+		return r.relocate(tc, pod, since, reason)
+	}
+	return nil
+}
+
+// replaceDiskOperations returns the ReplaceDisk operations of the cluster
+func (r *tikvStoreRelocator) replaceDiskOperations(tc *v1alpha1.TidbCluster) ([]*v1alpha1.TidbClusterOperation, error) {
+	all, err := r.deps.TiDBClusterOperationLister.TidbClusterOperations(tc.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("store relocator: failed to list TidbClusterOperations for tc %s/%s, error: %v", tc.Namespace, tc.Name, err)
+	}
+	var ops []*v1alpha1.TidbClusterOperation
+	for _, op := range all {
+		if op.Spec.Type != v1alpha1.TidbClusterOperationReplaceDisk || op.Spec.Cluster.Name != tc.Name {
+			continue
+		}
+		if op.Spec.Cluster.Namespace != "" && op.Spec.Cluster.Namespace != tc.Namespace {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// stuckReason returns since when the Pod is pending and why it can't be scheduled, an empty reason
+// is returned if it's not pending because of the nodes of its local PVs
+func (r *tikvStoreRelocator) stuckReason(pod *corev1.Pod, nodes []*corev1.Node) (time.Time, string, error) {
+	if pod.Spec.NodeName != "" || pod.Status.Phase != corev1.PodPending || pod.DeletionTimestamp != nil {
+		return time.Time{}, "", nil
+	}
+	since := pod.CreationTimestamp.Time
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			since = cond.LastTransitionTime.Time
+		}
+	}
+
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := r.deps.PVCLister.PersistentVolumeClaims(pod.Namespace).Get(vol.PersistentVolumeClaim.ClaimName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return since, "", fmt.Errorf("store relocator: failed to get pvc %s/%s, error: %v", pod.Namespace, vol.PersistentVolumeClaim.ClaimName, err)
+		}
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := r.deps.PVLister.Get(pvc.Spec.VolumeName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return since, "", fmt.Errorf("store relocator: failed to get pv %s, error: %v", pvc.Spec.VolumeName, err)
+		}
+		if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+			continue
+		}
+
+		var unavailable []string
+		available := false
+		for _, node := range nodes {
+			if !nodeMatchesTerms(node, pv.Spec.NodeAffinity.Required.NodeSelectorTerms) {
+				continue
+			}
+			if reason := nodeUnavailableReason(node); reason != "" {
+				unavailable = append(unavailable, fmt.Sprintf("node %s %s", node.Name, reason))
+				continue
+			}
+			available = true
+		}
+		if available {
+			continue
+		}
+		if len(unavailable) == 0 {
+			return since, fmt.Sprintf("no node matches the node affinity of PV %s", pv.Name), nil
+		}
+		return since, fmt.Sprintf("PV %s can only be attached to %v", pv.Name, unavailable), nil
+	}
+	return since, "", nil
+}
+
+func nodeUnavailableReason(node *corev1.Node) string {
+	if node.Spec.Unschedulable {
+		return "is cordoned"
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+			return ""
+		}
+	}
+	return "is not ready"
+}
+
+// relocate creates a TidbClusterOperation to replace the store of the Pod
+func (r *tikvStoreRelocator) relocate(tc *v1alpha1.TidbCluster, pod *corev1.Pod, since time.Time, reason string) error {
+	op := &v1alpha1.TidbClusterOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-relocation-%d", pod.Name, r.now().Unix()),
+			Namespace:       tc.Namespace,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: v1alpha1.TidbClusterOperationSpec{
+			Cluster: v1alpha1.TidbClusterRef{Name: tc.Name},
+			Type:    v1alpha1.TidbClusterOperationReplaceDisk,
+			PodName: pod.Name,
+		},
+	}
+	if _, err := r.deps.Clientset.PingcapV1alpha1().TidbClusterOperations(tc.Namespace).Create(context.TODO(), op, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("store relocator: failed to create TidbClusterOperation %s/%s, error: %v", op.Namespace, op.Name, err)
+	}
+	klog.Infof("tc %s/%s: relocating the store of Pod %s by TidbClusterOperation %s, %s", tc.Namespace, tc.Name, pod.Name, op.Name, reason)
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StoreRelocationStarted,
+		"Pod %s has been pending since %s, %s, relocating its store by TidbClusterOperation %s", pod.Name, since.Format(time.RFC3339), reason, op.Name)
+	return nil
+}
+
+type FakeTiKVStoreRelocator struct {
+	err error
+}
+
+func NewFakeTiKVStoreRelocator() *FakeTiKVStoreRelocator {
+	return &FakeTiKVStoreRelocator{}
+}
+
+func (r *FakeTiKVStoreRelocator) SetRelocateError(err error) {
+	r.err = err
+}
+
+func (r *FakeTiKVStoreRelocator) Relocate(_ *v1alpha1.TidbCluster) error {
+	return r.err
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTiKVStoreRelocatorRelocate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	deps.NodeLister = deps.KubeInformerFactory.Core().V1().Nodes().Lister()
+	deps.PVLister = deps.KubeInformerFactory.Core().V1().PersistentVolumes().Lister()
+	recorder := deps.Recorder.(*record.FakeRecorder)
+	now := time.Now()
+	relocator := &tikvStoreRelocator{deps: deps, now: func() time.Time { return now }}
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{Replicas: 3},
+		},
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{corev1.LabelHostname: "node-1"}},
+		Spec:       corev1.NodeSpec{Unschedulable: true},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}},
+	}
+	g.Expect(deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(node)).To(Succeed())
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-pv-1"},
+		Spec: corev1.PersistentVolumeSpec{
+			NodeAffinity: &corev1.VolumeNodeAffinity{
+				Required: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}}},
+					}},
+				},
+			},
+		},
+	}
+	g.Expect(deps.KubeInformerFactory.Core().V1().PersistentVolumes().Informer().GetIndexer().Add(pv)).To(Succeed())
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "tikv-test-tikv-0"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "local-pv-1"},
+	}
+	g.Expect(deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)).To(Succeed())
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: corev1.NamespaceDefault,
+			Name:      "test-tikv-0",
+			Labels:    label.New().Instance(tc.GetInstanceName()).TiKV().Labels(),
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         "tikv",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "tikv-test-tikv-0"}},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodScheduled,
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Minute)),
+			}},
+		},
+	}
+	g.Expect(deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)).To(Succeed())
+
+	listOperations := func() []v1alpha1.TidbClusterOperation {
+		list, err := deps.Clientset.PingcapV1alpha1().TidbClusterOperations(corev1.NamespaceDefault).List(context.TODO(), metav1.ListOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return list.Items
+	}
+
+	// store relocation is not configured
+	g.Expect(relocator.Relocate(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// pending for less than the threshold
+	tc.Spec.TiKV.StoreRelocation = &v1alpha1.TiKVStoreRelocation{PendingThreshold: &metav1.Duration{Duration: 30 * time.Minute}}
+	g.Expect(relocator.Relocate(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// only report the event if store relocation is disabled
+	now = now.Add(30 * time.Minute)
+	g.Expect(relocator.Relocate(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	event := <-recorder.Events
+	g.Expect(event).To(ContainSubstring(StoreStuckOnUnavailableNode))
+	g.Expect(event).To(ContainSubstring("node node-1 is cordoned"))
+	g.Expect(listOperations()).To(BeEmpty())
+
+	// the event is emitted only once while the Pod is stuck
+	now = now.Add(time.Minute)
+	g.Expect(relocator.Relocate(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// the store is not relocated if the node is available again
+	node.Spec.Unschedulable = false
+	g.Expect(deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Update(node)).To(Succeed())
+	tc.Spec.TiKV.StoreRelocation.Enabled = true
+	g.Expect(relocator.Relocate(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// the node is not ready
+	node.Status.Conditions[0].Status = corev1.ConditionUnknown
+	g.Expect(deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Update(node)).To(Succeed())
+	g.Expect(relocator.Relocate(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	event = <-recorder.Events
+	g.Expect(event).To(ContainSubstring(StoreRelocationStarted))
+	g.Expect(event).To(ContainSubstring("node node-1 is not ready"))
+	ops := listOperations()
+	g.Expect(ops).To(HaveLen(1))
+	g.Expect(ops[0].Spec.Type).To(Equal(v1alpha1.TidbClusterOperationReplaceDisk))
+	g.Expect(ops[0].Spec.PodName).To(Equal("test-tikv-0"))
+	g.Expect(ops[0].Spec.Cluster.Name).To(Equal("test"))
+
+	// the store is relocated only once while the Pod is stuck
+	op := ops[0].DeepCopy()
+	op.CreationTimestamp = metav1.NewTime(now)
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusterOperations().Informer().GetIndexer().Add(op)).To(Succeed())
+	now = now.Add(time.Minute)
+	g.Expect(relocator.Relocate(tc)).To(Succeed())
+	g.Expect(recorder.Events).To(BeEmpty())
+	g.Expect(listOperations()).To(HaveLen(1))
+}
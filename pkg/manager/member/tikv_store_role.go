@@ -0,0 +1,277 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util/cmpver"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// storeRoleRuleGroupID is the placement rule group managed for the TiKV store roles. It
+	// overrides the default rule group of PD, whose index is 0, so that the voters are kept
+	// away from the stores of the roles. The groups of TiFlash and the placement policies of
+	// TiDB have larger indexes and are not affected.
+	storeRoleRuleGroupID    = "tidb-operator-store-role"
+	storeRoleRuleGroupIndex = 1
+	// storeRoleVotersRuleID is the id of the rule of the voters holding the data
+	storeRoleVotersRuleID = "voters"
+)
+
+var (
+	// witness replicas are supported since v6.6.0
+	tikvEqualOrGreaterThanV660, _ = cmpver.NewConstraint(cmpver.GreaterOrEqual, "v6.6.0")
+
+	allStoreRoles = []v1alpha1.TiKVStoreRole{v1alpha1.TiKVStoreRoleWitness, v1alpha1.TiKVStoreRoleLearner}
+)
+
+// storeRoleLabelValue returns the value of the store label and the Pod label of the store role
+func storeRoleLabelValue(role v1alpha1.TiKVStoreRole) string {
+	return strings.ToLower(string(role))
+}
+
+// syncPlacementRulesForStoreRole makes sure the replicas of the store roles are placed on the stores
+// of the clusters by the placement rules. One replica of each region is placed on the stores of each
+// role, and the voters holding the data are placed on the other stores. The rules are shared by all
+// the clusters of the same PD, so they are derived from the roles of all these clusters, and the
+// rules of a role are deleted once no cluster of the role remains.
+func (m *tikvMemberManager) syncPlacementRulesForStoreRole(tc *v1alpha1.TidbCluster) error {
+	if !tc.TiKVBootStrapped() {
+		return nil
+	}
+	role := tc.Spec.TiKV.StoreRole
+	if role == v1alpha1.TiKVStoreRoleWitness {
+		if ok, err := tikvEqualOrGreaterThanV660.Check(tc.TiKVVersion()); err == nil && !ok {
+			return fmt.Errorf("syncPlacementRulesForStoreRole: witness is not supported by TiKV %s of cluster %s/%s, v6.6.0 or later is required",
+				tc.TiKVVersion(), tc.Namespace, tc.Name)
+		}
+	}
+
+	roles, err := m.storeRolesOfPD(tc)
+	if err != nil {
+		return err
+	}
+	key := storeRolePDKey(tc)
+	if len(roles) == 0 {
+		// no rule is left after the last cleanup, and no rule is set until a cluster of a role is created
+		if _, ok := m.storeRoleRulesDeleted.Load(key); ok {
+			return nil
+		}
+	} else {
+		m.storeRoleRulesDeleted.Delete(key)
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	current, err := getPlacementRulesOfGroup(pdClient, storeRoleRuleGroupID)
+	if err != nil {
+		return err
+	}
+
+	// delete the rules of the roles no cluster has
+	for _, r := range allStoreRoles {
+		id := storeRoleLabelValue(r)
+		if _, ok := current[id]; !ok || roles[r] {
+			continue
+		}
+		if err := pdClient.DeletePlacementRule(storeRoleRuleGroupID, id); err != nil {
+			return err
+		}
+		delete(current, id)
+		klog.Infof("syncPlacementRulesForStoreRole: placement rule %s/%s is deleted as no cluster of the role remains, cluster %s/%s",
+			storeRoleRuleGroupID, id, tc.Namespace, tc.Name)
+	}
+	if len(roles) == 0 {
+		if _, ok := current[storeRoleVotersRuleID]; ok {
+			if err := pdClient.DeletePlacementRule(storeRoleRuleGroupID, storeRoleVotersRuleID); err != nil {
+				return err
+			}
+			klog.Infof("syncPlacementRulesForStoreRole: placement rule %s/%s is deleted as no cluster of the store roles remains, cluster %s/%s",
+				storeRoleRuleGroupID, storeRoleVotersRuleID, tc.Namespace, tc.Name)
+		}
+		m.storeRoleRulesDeleted.Store(key, struct{}{})
+		return nil
+	}
+
+	config, err := pdClient.GetConfig()
+	if err != nil {
+		return err
+	}
+	maxReplicas := defaultMaxReplicas
+	var locationLabels []string
+	if config.Replication != nil {
+		if config.Replication.MaxReplicas != nil {
+			maxReplicas = int(*config.Replication.MaxReplicas)
+		}
+		locationLabels = config.Replication.LocationLabels
+	}
+
+	var rules []*pdapi.PlacementRule
+	var witnessRule *pdapi.PlacementRule
+	for _, r := range allStoreRoles {
+		if !roles[r] {
+			continue
+		}
+		rule := storeRolePlacementRule(r, locationLabels)
+		if rule.IsWitness {
+			witnessRule = rule
+			if !reflect.DeepEqual(current[rule.ID], rule) {
+				// witness replicas are not created by PD until it is enabled
+				if err := pdClient.UpdateScheduleConfig(map[string]interface{}{"enable-witness": "true"}); err != nil {
+					return err
+				}
+			}
+		}
+		rules = append(rules, rule)
+	}
+	rules = append(rules, storeRoleVotersPlacementRule(maxReplicas, witnessRule, locationLabels))
+
+	group := &pdapi.PlacementRuleGroup{
+		ID:       storeRoleRuleGroupID,
+		Index:    storeRoleRuleGroupIndex,
+		Override: true,
+	}
+	set, err := setPlacementRules(pdClient, group, current, rules)
+	for _, id := range set {
+		klog.Infof("syncPlacementRulesForStoreRole: placement rule %s/%s is set for the store roles, cluster %s/%s",
+			storeRoleRuleGroupID, id, tc.Namespace, tc.Name)
+	}
+	return err
+}
+
+// storeRolePDKey returns the key of the cluster owning the PD of the cluster, a heterogeneous
+// cluster shares the PD of the cluster it refers to
+func storeRolePDKey(tc *v1alpha1.TidbCluster) string {
+	if tc.Heterogeneous() {
+		ns := tc.Spec.Cluster.Namespace
+		if ns == "" {
+			ns = tc.Namespace
+		}
+		return fmt.Sprintf("%s/%s", ns, tc.Spec.Cluster.Name)
+	}
+	return fmt.Sprintf("%s/%s", tc.Namespace, tc.Name)
+}
+
+// storeRolesOfPD returns the store roles of the clusters sharing the PD of the cluster. The clusters
+// being deleted are excluded. Clusters in other Kubernetes clusters are not visible and have to keep
+// a cluster of the same role in this Kubernetes cluster to keep the rules.
+func (m *tikvMemberManager) storeRolesOfPD(tc *v1alpha1.TidbCluster) (map[v1alpha1.TiKVStoreRole]bool, error) {
+	tcs, err := m.deps.TiDBClusterLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("storeRolesOfPD: failed to list TidbClusters for cluster %s/%s, error: %v", tc.Namespace, tc.Name, err)
+	}
+	key := storeRolePDKey(tc)
+	roles := map[v1alpha1.TiKVStoreRole]bool{}
+	for _, other := range tcs {
+		if other.DeletionTimestamp != nil || other.Spec.TiKV == nil || other.Spec.TiKV.StoreRole == "" {
+			continue
+		}
+		if storeRolePDKey(other) == key {
+			roles[other.Spec.TiKV.StoreRole] = true
+		}
+	}
+	if tc.DeletionTimestamp == nil && tc.Spec.TiKV.StoreRole != "" {
+		roles[tc.Spec.TiKV.StoreRole] = true
+	}
+	return roles, nil
+}
+
+// getPlacementRulesOfGroup returns the placement rules of the group keyed by the ids
+func getPlacementRulesOfGroup(pdClient pdapi.PDClient, groupID string) (map[string]*pdapi.PlacementRule, error) {
+	rules, err := pdClient.GetPlacementRules()
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]*pdapi.PlacementRule{}
+	for _, rule := range rules {
+		if rule.GroupID == groupID {
+			current[rule.ID] = rule
+		}
+	}
+	return current, nil
+}
+
+// setPlacementRules sets the rules which differ from the current rules of the group, the group is
+// set before the first rule is set. It returns the ids of the rules set.
+func setPlacementRules(pdClient pdapi.PDClient, group *pdapi.PlacementRuleGroup, current map[string]*pdapi.PlacementRule, rules []*pdapi.PlacementRule) ([]string, error) {
+	var set []string
+	for _, rule := range rules {
+		if old, ok := current[rule.ID]; ok && reflect.DeepEqual(old, rule) {
+			continue
+		}
+		if len(set) == 0 {
+			if err := pdClient.SetPlacementRuleGroup(group); err != nil {
+				return set, err
+			}
+		}
+		if err := pdClient.SetPlacementRule(rule); err != nil {
+			return set, err
+		}
+		set = append(set, rule.ID)
+	}
+	return set, nil
+}
+
+// storeRolePlacementRule returns the rule placing one replica of the role on the stores of the role
+func storeRolePlacementRule(role v1alpha1.TiKVStoreRole, locationLabels []string) *pdapi.PlacementRule {
+	value := storeRoleLabelValue(role)
+	rule := &pdapi.PlacementRule{
+		GroupID: storeRoleRuleGroupID,
+		ID:      value,
+		Role:    "voter",
+		Count:   1,
+		LabelConstraints: []pdapi.LabelConstraint{
+			{Key: v1alpha1.TiKVStoreRoleLabelKey, Op: "in", Values: []string{value}},
+		},
+		LocationLabels: locationLabels,
+	}
+	switch role {
+	case v1alpha1.TiKVStoreRoleWitness:
+		rule.IsWitness = true
+	case v1alpha1.TiKVStoreRoleLearner:
+		rule.Role = "learner"
+	}
+	return rule
+}
+
+// storeRoleVotersPlacementRule returns the rule placing the voters holding the data on the stores
+// without roles, the witness replica takes the place of one of the voters
+func storeRoleVotersPlacementRule(maxReplicas int, witnessRule *pdapi.PlacementRule, locationLabels []string) *pdapi.PlacementRule {
+	count := maxReplicas
+	if witnessRule != nil {
+		count -= witnessRule.Count
+	}
+	values := make([]string, 0, len(allStoreRoles))
+	for _, role := range allStoreRoles {
+		values = append(values, storeRoleLabelValue(role))
+	}
+	return &pdapi.PlacementRule{
+		GroupID: storeRoleRuleGroupID,
+		ID:      storeRoleVotersRuleID,
+		Role:    "voter",
+		Count:   count,
+		LabelConstraints: []pdapi.LabelConstraint{
+			{Key: v1alpha1.TiKVStoreRoleLabelKey, Op: "notIn", Values: values},
+		},
+		LocationLabels: locationLabels,
+	}
+}
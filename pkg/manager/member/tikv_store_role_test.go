@@ -0,0 +1,220 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+)
+
+func TestTiKVMemberManagerSyncPlacementRulesForStoreRole(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type result struct {
+		groups         []*pdapi.PlacementRuleGroup
+		rules          []*pdapi.PlacementRule
+		deleted        []string
+		enabledWitness bool
+	}
+	tests := []struct {
+		name        string
+		role        v1alpha1.TiKVStoreRole
+		image       string
+		others      []v1alpha1.TiKVStoreRole
+		existing    []*pdapi.PlacementRule
+		errExpected bool
+		expect      func(res *result)
+	}{
+		{
+			name:  "witness",
+			role:  v1alpha1.TiKVStoreRoleWitness,
+			image: "tikv:v6.6.0",
+			expect: func(res *result) {
+				g.Expect(res.groups).To(HaveLen(1))
+				g.Expect(res.groups[0].Override).To(BeTrue())
+				g.Expect(res.enabledWitness).To(BeTrue())
+				g.Expect(res.rules).To(HaveLen(2))
+				g.Expect(res.rules[0].ID).To(Equal("witness"))
+				g.Expect(res.rules[0].IsWitness).To(BeTrue())
+				g.Expect(res.rules[0].LocationLabels).To(Equal([]string{"zone", "host"}))
+				g.Expect(res.rules[1].ID).To(Equal("voters"))
+				g.Expect(res.rules[1].Count).To(Equal(2))
+			},
+		},
+		{
+			name:        "witness is not supported",
+			role:        v1alpha1.TiKVStoreRoleWitness,
+			image:       "tikv:v6.5.0",
+			errExpected: true,
+			expect: func(res *result) {
+				g.Expect(res.rules).To(BeEmpty())
+			},
+		},
+		{
+			name:  "learner",
+			role:  v1alpha1.TiKVStoreRoleLearner,
+			image: "tikv:v6.5.0",
+			expect: func(res *result) {
+				g.Expect(res.enabledWitness).To(BeFalse())
+				g.Expect(res.rules).To(HaveLen(2))
+				g.Expect(res.rules[0].ID).To(Equal("learner"))
+				g.Expect(res.rules[0].Role).To(Equal("learner"))
+				g.Expect(res.rules[1].Count).To(Equal(3))
+			},
+		},
+		{
+			name:   "learner with the witness rule",
+			role:   v1alpha1.TiKVStoreRoleLearner,
+			image:  "tikv:v6.6.0",
+			others: []v1alpha1.TiKVStoreRole{v1alpha1.TiKVStoreRoleWitness},
+			existing: []*pdapi.PlacementRule{
+				storeRolePlacementRule(v1alpha1.TiKVStoreRoleWitness, []string{"zone", "host"}),
+				storeRoleVotersPlacementRule(3, storeRolePlacementRule(v1alpha1.TiKVStoreRoleWitness, nil), []string{"zone", "host"}),
+			},
+			expect: func(res *result) {
+				// the voters rule is not changed
+				g.Expect(res.deleted).To(BeEmpty())
+				g.Expect(res.rules).To(HaveLen(1))
+				g.Expect(res.rules[0].ID).To(Equal("learner"))
+			},
+		},
+		{
+			name:  "learner after the witness cluster is deleted",
+			role:  v1alpha1.TiKVStoreRoleLearner,
+			image: "tikv:v6.6.0",
+			existing: []*pdapi.PlacementRule{
+				storeRolePlacementRule(v1alpha1.TiKVStoreRoleWitness, []string{"zone", "host"}),
+				storeRoleVotersPlacementRule(3, storeRolePlacementRule(v1alpha1.TiKVStoreRoleWitness, nil), []string{"zone", "host"}),
+			},
+			expect: func(res *result) {
+				g.Expect(res.deleted).To(Equal([]string{"witness"}))
+				g.Expect(res.rules).To(HaveLen(2))
+				g.Expect(res.rules[0].ID).To(Equal("learner"))
+				g.Expect(res.rules[1].ID).To(Equal("voters"))
+				g.Expect(res.rules[1].Count).To(Equal(3))
+			},
+		},
+		{
+			name:  "no cluster of the roles remains",
+			image: "tikv:v6.6.0",
+			existing: []*pdapi.PlacementRule{
+				storeRolePlacementRule(v1alpha1.TiKVStoreRoleWitness, []string{"zone", "host"}),
+				storeRoleVotersPlacementRule(3, storeRolePlacementRule(v1alpha1.TiKVStoreRoleWitness, nil), []string{"zone", "host"}),
+			},
+			expect: func(res *result) {
+				g.Expect(res.deleted).To(Equal([]string{"witness", "voters"}))
+				g.Expect(res.rules).To(BeEmpty())
+			},
+		},
+		{
+			name:  "rules are up to date",
+			role:  v1alpha1.TiKVStoreRoleWitness,
+			image: "tikv:v6.6.0",
+			existing: []*pdapi.PlacementRule{
+				{GroupID: "pd", ID: "default", Role: "voter", Count: 3},
+				storeRolePlacementRule(v1alpha1.TiKVStoreRoleWitness, []string{"zone", "host"}),
+				storeRoleVotersPlacementRule(3, storeRolePlacementRule(v1alpha1.TiKVStoreRoleWitness, nil), []string{"zone", "host"}),
+			},
+			expect: func(res *result) {
+				g.Expect(res.groups).To(BeEmpty())
+				g.Expect(res.rules).To(BeEmpty())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+
+		tc := newTidbClusterForTiKV()
+		tc.Spec.TiKV.Image = tt.image
+		tc.Spec.TiKV.StoreRole = tt.role
+		tc.Status.TiKV.BootStrapped = true
+		tmm, _, _, pdClient, _, _ := newFakeTiKVMemberManager(tc)
+		tcIndexer := tmm.deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+		for _, role := range tt.others {
+			other := newTidbClusterForTiKV()
+			other.Name = storeRoleLabelValue(role)
+			other.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: tc.Name}
+			other.Spec.TiKV.StoreRole = role
+			g.Expect(tcIndexer.Add(other)).To(Succeed())
+		}
+
+		res := &result{}
+		maxReplicas := uint64(3)
+		pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+			return &pdapi.PDConfigFromAPI{Replication: &pdapi.PDReplicationConfig{
+				MaxReplicas:    &maxReplicas,
+				LocationLabels: []string{"zone", "host"},
+			}}, nil
+		})
+		pdClient.AddReaction(pdapi.GetPlacementRulesActionType, func(action *pdapi.Action) (interface{}, error) {
+			return tt.existing, nil
+		})
+		pdClient.AddReaction(pdapi.SetPlacementRuleGroupActionType, func(action *pdapi.Action) (interface{}, error) {
+			res.groups = append(res.groups, action.RuleGroup)
+			return nil, nil
+		})
+		pdClient.AddReaction(pdapi.SetPlacementRuleActionType, func(action *pdapi.Action) (interface{}, error) {
+			res.rules = append(res.rules, action.Rule)
+			return nil, nil
+		})
+		pdClient.AddReaction(pdapi.DeletePlacementRuleActionType, func(action *pdapi.Action) (interface{}, error) {
+			res.deleted = append(res.deleted, action.Rule.ID)
+			return nil, nil
+		})
+		pdClient.AddReaction(pdapi.UpdateScheduleConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+			res.enabledWitness = action.Config["enable-witness"] == "true"
+			return nil, nil
+		})
+
+		err := tmm.syncPlacementRulesForStoreRole(tc)
+		if tt.errExpected {
+			g.Expect(err).To(HaveOccurred())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		tt.expect(res)
+	}
+}
+
+func TestTiKVMemberManagerSyncPlacementRulesForStoreRoleDeleted(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	tc.Status.TiKV.BootStrapped = true
+	tmm, _, _, pdClient, _, _ := newFakeTiKVMemberManager(tc)
+
+	gets := 0
+	pdClient.AddReaction(pdapi.GetPlacementRulesActionType, func(action *pdapi.Action) (interface{}, error) {
+		gets++
+		return []*pdapi.PlacementRule{}, nil
+	})
+
+	// the rules are checked once until a cluster of a role is created
+	g.Expect(tmm.syncPlacementRulesForStoreRole(tc)).To(Succeed())
+	g.Expect(tmm.syncPlacementRulesForStoreRole(tc)).To(Succeed())
+	g.Expect(gets).To(Equal(1))
+
+	learner := newTidbClusterForTiKV()
+	learner.Name = "learner"
+	learner.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: tc.Name}
+	learner.Spec.TiKV.StoreRole = v1alpha1.TiKVStoreRoleLearner
+	tcIndexer := tmm.deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+	g.Expect(tcIndexer.Add(learner)).To(Succeed())
+	g.Expect(tmm.syncPlacementRulesForStoreRole(tc)).To(Succeed())
+	g.Expect(gets).To(Equal(2))
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+// sysctlInitContainerName is the name of the init container applying the sysctls,
+// see the `tidb.pingcap.com/sysctl-init` annotation
+const sysctlInitContainerName = "init"
+
+// appliedSysctls returns the sysctls applied to the pod when it started, by the
+// security context or by the init container
+func appliedSysctls(pod *corev1.Pod) map[string]string {
+	sysctls := map[string]string{}
+	if pod.Spec.SecurityContext != nil {
+		for _, sysctl := range pod.Spec.SecurityContext.Sysctls {
+			sysctls[sysctl.Name] = sysctl.Value
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name != sysctlInitContainerName || len(c.Command) != 3 {
+			continue
+		}
+		// sh -c "sysctl -w name1=value1 name2=value2", followed by the extra commands
+		// of the initializer in the next lines
+		fields := strings.Fields(strings.SplitN(c.Command[2], "\n", 2)[0])
+		if len(fields) < 2 || fields[0] != "sysctl" || fields[1] != "-w" {
+			continue
+		}
+		for _, field := range fields[2:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) == 2 {
+				sysctls[kv[0]] = kv[1]
+			}
+		}
+	}
+	return sysctls
+}
+
+// sysctlDriftReasons returns the reasons why the sysctls applied to the pod drift from
+// the desired ones, or the kernel of the node changes after they are applied
+func sysctlDriftReasons(desired []corev1.Sysctl, pod *corev1.Pod, node *corev1.Node) []string {
+	var reasons []string
+	applied := appliedSysctls(pod)
+	for _, sysctl := range desired {
+		value, ok := applied[sysctl.Name]
+		if !ok {
+			reasons = append(reasons, fmt.Sprintf("%s is not applied", sysctl.Name))
+		} else if value != sysctl.Value {
+			reasons = append(reasons, fmt.Sprintf("%s is %s, expected %s", sysctl.Name, value, sysctl.Value))
+		}
+	}
+	if node != nil {
+		recorded, ok := pod.Annotations[label.AnnNodeKernelVersion]
+		current := node.Status.NodeInfo.KernelVersion
+		if ok && recorded != current {
+			reasons = append(reasons, fmt.Sprintf("kernel of node %s changed from %s to %s", node.Name, recorded, current))
+		}
+	}
+	return reasons
+}
+
+// syncTiKVSysctlStatus reports the compliance of the sysctls of each TiKV pod in
+// status.tikv.sysctls, and records the kernel version of the node on the pods.
+func (m *tikvMemberManager) syncTiKVSysctlStatus(tc *v1alpha1.TidbCluster) error {
+	desired := tc.BaseTiKVSpec().PodSecurityContext()
+	if desired == nil || len(desired.Sysctls) == 0 {
+		tc.Status.TiKV.Sysctls = nil
+		return nil
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiKV().Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := m.deps.PodLister.Pods(tc.Namespace).List(selector)
+	if err != nil {
+		return fmt.Errorf("syncTiKVSysctlStatus: failed to list pods for cluster %s/%s, error: %v", tc.Namespace, tc.Name, err)
+	}
+
+	status := map[string]v1alpha1.PodSysctlStatus{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			// not scheduled yet
+			continue
+		}
+		var node *corev1.Node
+		if m.deps.NodeLister != nil {
+			node, err = m.deps.NodeLister.Get(pod.Spec.NodeName)
+			if err != nil {
+				klog.Warningf("syncTiKVSysctlStatus: failed to get node %s of pod %s/%s, error: %v", pod.Spec.NodeName, pod.Namespace, pod.Name, err)
+				node = nil
+			}
+		}
+
+		reasons := sysctlDriftReasons(desired.Sysctls, pod, node)
+		status[pod.Name] = v1alpha1.PodSysctlStatus{
+			Compliant: len(reasons) == 0,
+			Reason:    strings.Join(reasons, "; "),
+		}
+		old, exist := tc.Status.TiKV.Sysctls[pod.Name]
+		if len(reasons) > 0 && (!exist || old.Compliant) {
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, "SysctlDrifted", "sysctls of pod %s drifted: %s", pod.Name, status[pod.Name].Reason)
+		}
+
+		// record the kernel version once the sysctls are applied
+		if _, ok := pod.Annotations[label.AnnNodeKernelVersion]; !ok && node != nil && podutil.IsPodReady(pod) {
+			pod = pod.DeepCopy()
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[label.AnnNodeKernelVersion] = node.Status.NodeInfo.KernelVersion
+			if _, err := m.deps.PodControl.UpdatePod(tc, pod); err != nil {
+				return fmt.Errorf("syncTiKVSysctlStatus: failed to set annotation %s of pod %s/%s, error: %v", label.AnnNodeKernelVersion, pod.Namespace, pod.Name, err)
+			}
+		}
+	}
+	tc.Status.TiKV.Sysctls = status
+	return nil
+}
+
+// remediateTiKVSysctlDrift recreates the TiKV pods whose sysctls drift one by one if
+// `spec.tikv.sysctlDriftPolicy` is RollingRestart. The region leaders are evicted before
+// the pod is deleted by the pod controller, and the next pod is not handled until the
+// eviction of the previous one ends.
+func (m *tikvMemberManager) remediateTiKVSysctlDrift(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiKV.SysctlDriftPolicy != v1alpha1.SysctlDriftPolicyRollingRestart || len(tc.Status.TiKV.Sysctls) == 0 {
+		return nil
+	}
+	for _, status := range tc.Status.TiKV.EvictLeader {
+		if status != nil && status.Source == v1alpha1.EvictLeaderAnnKeyForSysctl {
+			klog.V(4).Infof("TiKV of cluster %s/%s is restarting a pod for the sysctls drift", tc.Namespace, tc.Name)
+			return nil
+		}
+	}
+	if !tc.TiKVAllStoresReady() {
+		klog.Infof("TiKV of cluster %s/%s is not ready, skip remediating the sysctls drift", tc.Namespace, tc.Name)
+		return nil
+	}
+
+	podNames := make([]string, 0, len(tc.Status.TiKV.Sysctls))
+	for podName, status := range tc.Status.TiKV.Sysctls {
+		if !status.Compliant {
+			podNames = append(podNames, podName)
+		}
+	}
+	if len(podNames) == 0 {
+		return nil
+	}
+	sort.Strings(podNames)
+
+	pod, err := m.deps.PodLister.Pods(tc.Namespace).Get(podNames[0])
+	if err != nil {
+		return fmt.Errorf("remediateTiKVSysctlDrift: failed to get pod %s/%s, error: %v", tc.Namespace, podNames[0], err)
+	}
+	if _, ok := pod.Annotations[v1alpha1.EvictLeaderAnnKeyForSysctl]; ok {
+		return nil
+	}
+	pod = pod.DeepCopy()
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[v1alpha1.EvictLeaderAnnKeyForSysctl] = v1alpha1.EvictLeaderValueDeletePod
+	if _, err := m.deps.PodControl.UpdatePod(tc, pod); err != nil {
+		return fmt.Errorf("remediateTiKVSysctlDrift: failed to add leader eviction annotation to pod %s/%s, error: %v", pod.Namespace, pod.Name, err)
+	}
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "SysctlDriftRemediation", "restart pod %s to apply the sysctls again", pod.Name)
+	return nil
+}
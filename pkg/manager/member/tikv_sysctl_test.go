@@ -0,0 +1,118 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAppliedSysctls(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "32768"}},
+			},
+			InitContainers: []corev1.Container{
+				{Name: "init", Command: []string{"sh", "-c", "sysctl -w net.ipv4.tcp_syncookies=0 kernel.core_uses_pid=1\necho vm.max_map_count=262144"}},
+				{Name: "other", Command: []string{"sh", "-c", "sysctl -w vm.swappiness=0"}},
+			},
+		},
+	}
+	g.Expect(appliedSysctls(pod)).To(Equal(map[string]string{
+		"net.core.somaxconn":      "32768",
+		"net.ipv4.tcp_syncookies": "0",
+		"kernel.core_uses_pid":    "1",
+	}))
+}
+
+func TestSyncTiKVSysctlStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	tc.Spec.TiKV.PodSecurityContext = &corev1.PodSecurityContext{
+		Sysctls: []corev1.Sysctl{
+			{Name: "net.core.somaxconn", Value: "32768"},
+			{Name: "net.ipv4.tcp_syncookies", Value: "0"},
+		},
+	}
+	tc.Spec.TiKV.SysctlDriftPolicy = v1alpha1.SysctlDriftPolicyRollingRestart
+	tc.Spec.TiKV.Replicas = 2
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+		"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp},
+	}
+	tkmm, _, _, _, podIndexer, nodeIndexer := newFakeTiKVMemberManager(tc)
+
+	newPod := func(name string, sysctls []corev1.Sysctl) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: corev1.NamespaceDefault,
+				Labels:    label.New().Instance(tc.Name).TiKV().Labels(),
+			},
+			Spec: corev1.PodSpec{
+				NodeName:        "node-1",
+				SecurityContext: &corev1.PodSecurityContext{Sysctls: sysctls},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	node.Status.NodeInfo.KernelVersion = "5.4.0"
+	g.Expect(nodeIndexer.Add(node)).To(Succeed())
+	g.Expect(podIndexer.Add(newPod("test-tikv-0", tc.Spec.TiKV.PodSecurityContext.Sysctls))).To(Succeed())
+	g.Expect(podIndexer.Add(newPod("test-tikv-1", tc.Spec.TiKV.PodSecurityContext.Sysctls[:1]))).To(Succeed())
+
+	g.Expect(tkmm.syncTiKVSysctlStatus(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.Sysctls).To(Equal(map[string]v1alpha1.PodSysctlStatus{
+		"test-tikv-0": {Compliant: true},
+		"test-tikv-1": {Compliant: false, Reason: "net.ipv4.tcp_syncookies is not applied"},
+	}))
+	pod, err := tkmm.deps.PodLister.Pods(corev1.NamespaceDefault).Get("test-tikv-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pod.Annotations[label.AnnNodeKernelVersion]).To(Equal("5.4.0"))
+
+	// the kernel of the node is upgraded
+	node = node.DeepCopy()
+	node.Status.NodeInfo.KernelVersion = "5.10.0"
+	g.Expect(nodeIndexer.Update(node)).To(Succeed())
+	g.Expect(tkmm.syncTiKVSysctlStatus(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.Sysctls["test-tikv-0"]).To(Equal(v1alpha1.PodSysctlStatus{
+		Compliant: false,
+		Reason:    "kernel of node node-1 changed from 5.4.0 to 5.10.0",
+	}))
+
+	// the drifted pods are restarted one by one
+	g.Expect(tkmm.remediateTiKVSysctlDrift(tc)).To(Succeed())
+	pod, err = tkmm.deps.PodLister.Pods(corev1.NamespaceDefault).Get("test-tikv-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pod.Annotations[v1alpha1.EvictLeaderAnnKeyForSysctl]).To(Equal(v1alpha1.EvictLeaderValueDeletePod))
+	tc.Status.TiKV.EvictLeader = map[string]*v1alpha1.EvictLeaderStatus{
+		"test-tikv-0": {Source: v1alpha1.EvictLeaderAnnKeyForSysctl, Value: v1alpha1.EvictLeaderValueDeletePod},
+	}
+	g.Expect(tkmm.remediateTiKVSysctlDrift(tc)).To(Succeed())
+	pod, err = tkmm.deps.PodLister.Pods(corev1.NamespaceDefault).Get("test-tikv-1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pod.Annotations).NotTo(HaveKey(v1alpha1.EvictLeaderAnnKeyForSysctl))
+}
@@ -18,7 +18,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
@@ -107,7 +106,7 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 	}
 
 	mngerutils.SetUpgradePartition(newSet, *oldSet.Spec.UpdateStrategy.RollingUpdate.Partition)
-	podOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).List()
+	podOrdinals := mngerutils.GetUpgradeOrdinals(oldSet, newSet)
 	for _i := len(podOrdinals) - 1; _i >= 0; _i-- {
 		i := podOrdinals[_i]
 		store := getStoreByOrdinal(meta.GetName(), *status, i)
@@ -134,7 +133,11 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] is not all ready", ns, tcName, podName)
 			}
 
-			// If pods recreated successfully, endEvictLeader for the store on this Pod.
+			// If pods recreated successfully, endEvictLeader for the store on this Pod,
+			// unless the eviction is requested by `spec.tikv.evictLeader`.
+			if tc.TiKVPodEvictLeaderRequested(podName) {
+				continue
+			}
 			storeID, err := strconv.ParseUint(store.ID, 10, 64)
 			if err != nil {
 				return err
@@ -170,7 +173,7 @@ func (u *tikvUpgrader) upgradeTiKVPod(tc *v1alpha1.TidbCluster, ordinal int32, n
 
 	_, evicting := upgradePod.Annotations[EvictLeaderBeginTime]
 	if !evicting {
-		return u.beginEvictLeader(tc, storeID, upgradePod)
+		return u.beginEvictLeader(tc, storeID, ordinal, upgradePod)
 	}
 
 	if u.readyToUpgrade(upgradePod, tc) {
@@ -213,22 +216,29 @@ func (u *tikvUpgrader) readyToUpgrade(upgradePod *corev1.Pod, tc *v1alpha1.TidbC
 	return false
 }
 
-func (u *tikvUpgrader) beginEvictLeader(tc *v1alpha1.TidbCluster, storeID uint64, pod *corev1.Pod) error {
+func (u *tikvUpgrader) beginEvictLeader(tc *v1alpha1.TidbCluster, storeID uint64, ordinal int32, pod *corev1.Pod) error {
 	ns := tc.GetNamespace()
 	podName := pod.GetName()
-	err := controller.GetPDClient(u.deps.PDControl, tc).BeginEvictLeader(storeID)
-	if err != nil {
-		klog.Errorf("tikv upgrader: failed to begin evict leader: %d, %s/%s, %v",
-			storeID, ns, podName, err)
-		return err
+	// the eviction is begun before tidb-controller-manager restarts, resume from the checkpoint
+	// so that the eviction timeout is counted from the time it's begun
+	checkpoint := tc.Status.TiKV.InFlightOperation
+	if !checkpointReached(checkpoint, v1alpha1.UpgradePhase, ordinal, v1alpha1.OperationStepLeaderEvictionBegun) {
+		err := controller.GetPDClient(u.deps.PDControl, tc).BeginEvictLeader(storeID)
+		if err != nil {
+			klog.Errorf("tikv upgrader: failed to begin evict leader: %d, %s/%s, %v",
+				storeID, ns, podName, err)
+			return err
+		}
+		klog.Infof("tikv upgrader: begin evict leader: %d, %s/%s successfully", storeID, ns, podName)
+		setCheckpoint(&tc.Status.TiKV.InFlightOperation, v1alpha1.UpgradePhase, ordinal, v1alpha1.OperationStepLeaderEvictionBegun)
+		checkpoint = tc.Status.TiKV.InFlightOperation
 	}
-	klog.Infof("tikv upgrader: begin evict leader: %d, %s/%s successfully", storeID, ns, podName)
 	if pod.Annotations == nil {
 		pod.Annotations = map[string]string{}
 	}
-	now := time.Now().Format(time.RFC3339)
+	now := checkpoint.LastTransitionTime.Format(time.RFC3339)
 	pod.Annotations[EvictLeaderBeginTime] = now
-	_, err = u.deps.PodControl.UpdatePod(tc, pod)
+	_, err := u.deps.PodControl.UpdatePod(tc, pod)
 	if err != nil {
 		klog.Errorf("tikv upgrader: failed to set pod %s/%s annotation %s to %s, %v",
 			ns, podName, EvictLeaderBeginTime, now, err)
@@ -244,6 +254,10 @@ func endEvictLeaderForAllStore(deps *controller.Dependencies, tc *v1alpha1.TidbC
 	storeIDs := make([]uint64, 0, len(tc.Status.TiKV.Stores)+len(tc.Status.TiKV.TombstoneStores))
 	for _, stores := range []map[string]v1alpha1.TiKVStore{tc.Status.TiKV.Stores, tc.Status.TiKV.TombstoneStores} {
 		for _, store := range stores {
+			if tc.TiKVPodEvictLeaderRequested(store.PodName) {
+				// keep the eviction requested by `spec.tikv.evictLeader`
+				continue
+			}
 			storeID, err := strconv.ParseUint(store.ID, 10, 64)
 			if err != nil {
 				return fmt.Errorf("parse store id %s to uint64 failed: %v", store.ID, err)
@@ -287,6 +301,10 @@ func endEvictLeader(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, ord
 		klog.Errorf("tikv: no store found for TiKV ordinal %v of %s/%s", ordinal, tc.Namespace, tc.Name)
 		return nil
 	}
+	if tc.TiKVPodEvictLeaderRequested(store.PodName) {
+		klog.Infof("tikv: keep evicting leader for store %s of %s/%s as requested by spec", store.ID, tc.Namespace, tc.Name)
+		return nil
+	}
 	storeID, err := strconv.ParseUint(store.ID, 10, 64)
 	if err != nil {
 		return err
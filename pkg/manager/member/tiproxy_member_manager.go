@@ -0,0 +1,533 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/manager/suspender"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// tiproxySQLPort is the port which the clients connect to
+	tiproxySQLPort = 6000
+	// tiproxyAPIPort is the port of the HTTP API and the metrics
+	tiproxyAPIPort = 3080
+
+	tiproxyConfigPath = "/etc/proxy"
+	// tiproxyCertVolumeMount mounts the certificate of the cluster to talk to PD and TiDB status port
+	tiproxyCertVolumeMount = "tiproxy-tls"
+	tiproxyCertPath        = "/var/lib/tiproxy-tls"
+	// tiproxyServerCertVolumeMount mounts the certificate of TiDB server to serve the clients
+	tiproxyServerCertVolumeMount = "tidb-server-tls"
+	tiproxyServerCertPath        = "/var/lib/tidb-server-tls"
+)
+
+type tiproxyMemberManager struct {
+	deps      *controller.Dependencies
+	suspender suspender.Suspender
+}
+
+// NewTiProxyMemberManager returns a controller to reconcile TiProxy servers,
+// TiProxy pools the client connections in front of the TiDB servers.
+func NewTiProxyMemberManager(deps *controller.Dependencies, spder suspender.Suspender) manager.Manager {
+	return &tiproxyMemberManager{
+		deps:      deps,
+		suspender: spder,
+	}
+}
+
+func (m *tiproxyMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiProxy == nil {
+		return nil
+	}
+
+	// skip sync if tiproxy is suspended
+	component := v1alpha1.TiProxyMemberType
+	needSuspend, err := m.suspender.SuspendComponent(tc, component)
+	if err != nil {
+		return fmt.Errorf("suspend %s failed: %v", component, err)
+	}
+	if needSuspend {
+		klog.Infof("component %s for cluster %s/%s is suspended, skip syncing", component, tc.GetNamespace(), tc.GetName())
+		return nil
+	}
+
+	if err := m.syncService(tc, getNewTiProxyService(tc), "syncTiProxyService"); err != nil {
+		return err
+	}
+	if err := m.syncService(tc, getNewTiProxyHeadlessService(tc), "syncTiProxyHeadlessService"); err != nil {
+		return err
+	}
+	return m.syncStatefulSet(tc)
+}
+
+func (m *tiproxyMemberManager) syncService(tc *v1alpha1.TidbCluster, newSvc *corev1.Service, fn string) error {
+	if tc.Spec.Paused {
+		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for tiproxy service %s", tc.GetNamespace(), tc.GetName(), newSvc.Name)
+		return nil
+	}
+
+	oldSvcTmp, err := m.deps.ServiceLister.Services(newSvc.Namespace).Get(newSvc.Name)
+	if errors.IsNotFound(err) {
+		err = controller.SetServiceLastAppliedConfigAnnotation(newSvc)
+		if err != nil {
+			return err
+		}
+		return m.deps.ServiceControl.CreateService(tc, newSvc)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to get svc %s/%s for cluster %s/%s, error: %s", fn, newSvc.Namespace, newSvc.Name, tc.GetNamespace(), tc.GetName(), err)
+	}
+
+	oldSvc := oldSvcTmp.DeepCopy()
+	equal, err := controller.ServiceEqual(newSvc, oldSvc)
+	if err != nil {
+		return err
+	}
+	if !equal {
+		svc := *oldSvc
+		svc.Spec = newSvc.Spec
+		err = controller.SetServiceLastAppliedConfigAnnotation(&svc)
+		if err != nil {
+			return err
+		}
+		if newSvc.Spec.ClusterIP == "" {
+			svc.Spec.ClusterIP = oldSvc.Spec.ClusterIP
+		}
+		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
+		return err
+	}
+	return nil
+}
+
+func (m *tiproxyMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	oldSetTmp, err := m.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiProxyMemberName(tcName))
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("syncTiProxyStatefulSet: failed to get sts %s for cluster %s/%s, error: %s", controller.TiProxyMemberName(tcName), ns, tcName, err)
+	}
+	notFound := errors.IsNotFound(err)
+	oldSet := oldSetTmp.DeepCopy()
+
+	if err := m.syncTidbClusterStatus(tc, oldSet); err != nil {
+		klog.Errorf("failed to sync TidbCluster: [%s/%s]'s status, error: %v", ns, tcName, err)
+		return err
+	}
+
+	if tc.Spec.Paused {
+		klog.V(4).Infof("tidb cluster %s/%s is paused, skip syncing for tiproxy statefulset", ns, tcName)
+		return nil
+	}
+
+	cm, err := m.syncConfigMap(tc, oldSet)
+	if err != nil {
+		return err
+	}
+
+	newSet, err := getNewTiProxyStatefulSet(tc, cm)
+	if err != nil {
+		return err
+	}
+	if notFound {
+		err = mngerutils.SetStatefulSetLastAppliedConfigAnnotation(newSet)
+		if err != nil {
+			return err
+		}
+		return m.deps.StatefulSetControl.CreateStatefulSet(tc, newSet)
+	}
+
+	// TiProxy migrates the sessions away from the TiDB servers being upgraded,
+	// do not restart TiProxy at the same time to keep the client connections
+	if tc.Status.TiDB.Phase == v1alpha1.UpgradePhase {
+		klog.Infof("TidbCluster: [%s/%s]'s tidb status is %s, can not upgrade tiproxy", ns, tcName, tc.Status.TiDB.Phase)
+		return nil
+	}
+
+	return mngerutils.UpdateStatefulSetWithPrecheck(m.deps, tc, "FailedUpdateTiProxySTS", newSet, oldSet)
+}
+
+func (m *tiproxyMemberManager) syncConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
+	newCm, err := getNewTiProxyConfigMap(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	var inUseName string
+	if set != nil {
+		inUseName = mngerutils.FindConfigMapVolume(&set.Spec.Template.Spec, func(name string) bool {
+			return strings.HasPrefix(name, controller.TiProxyMemberName(tc.Name))
+		})
+	}
+
+	err = mngerutils.UpdateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BaseTiProxySpec().ConfigUpdateStrategy(), inUseName, newCm)
+	if err != nil {
+		return nil, err
+	}
+	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
+}
+
+func (m *tiproxyMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
+	if set == nil {
+		// skip if not created yet
+		return nil
+	}
+
+	tc.Status.TiProxy.StatefulSet = &set.Status
+
+	upgrading, err := m.statefulSetIsUpgrading(set, tc)
+	if err != nil {
+		tc.Status.TiProxy.Synced = false
+		return err
+	}
+	if upgrading {
+		tc.Status.TiProxy.Phase = v1alpha1.UpgradePhase
+	} else {
+		tc.Status.TiProxy.Phase = v1alpha1.NormalPhase
+	}
+
+	tc.Status.TiProxy.Synced = set.Status.ReadyReplicas == tc.Spec.TiProxy.Replicas && !upgrading
+	return nil
+}
+
+func (m *tiproxyMemberManager) statefulSetIsUpgrading(set *apps.StatefulSet, tc *v1alpha1.TidbCluster) (bool, error) {
+	if mngerutils.StatefulSetIsUpgrading(set) {
+		return true, nil
+	}
+	selector, err := label.New().
+		Instance(tc.GetInstanceName()).
+		TiProxy().
+		Selector()
+	if err != nil {
+		return false, err
+	}
+	pods, err := m.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return false, fmt.Errorf("tiproxyStatefulSetIsUpgrading: failed to list pods for cluster %s/%s, selector %s, error: %s", tc.GetNamespace(), tc.GetName(), selector, err)
+	}
+	for _, pod := range pods {
+		revisionHash, exist := pod.Labels[apps.ControllerRevisionHashLabelKey]
+		if !exist {
+			return false, nil
+		}
+		if revisionHash != tc.Status.TiProxy.StatefulSet.UpdateRevision {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func getTiProxyMeta(tc *v1alpha1.TidbCluster, nameFunc func(string) string) (metav1.ObjectMeta, label.Label) {
+	tiproxyLabel := label.New().Instance(tc.GetInstanceName()).TiProxy()
+
+	objMeta := metav1.ObjectMeta{
+		Name:            nameFunc(tc.Name),
+		Namespace:       tc.Namespace,
+		Labels:          tiproxyLabel,
+		OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+	}
+	return objMeta, tiproxyLabel
+}
+
+// getNewTiProxyService returns the service which the clients connect to
+func getNewTiProxyService(tc *v1alpha1.TidbCluster) *corev1.Service {
+	objMeta, tiproxyLabel := getTiProxyMeta(tc, controller.TiProxyMemberName)
+	objMeta.Labels = tiproxyLabel.Copy().UsedByEndUser().Labels()
+
+	svc := &corev1.Service{
+		ObjectMeta: objMeta,
+		Spec: corev1.ServiceSpec{
+			Type: controller.GetServiceType(tc.Spec.Services, v1alpha1.TiProxyMemberType.String()),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "mysql-client",
+					Port:       tiproxySQLPort,
+					TargetPort: intstr.FromInt(tiproxySQLPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Selector: tiproxyLabel.Labels(),
+		},
+	}
+
+	// override fields with user-defined ServiceSpec
+	svcSpec := tc.Spec.TiProxy.Service
+	if svcSpec != nil {
+		if svcSpec.Type != "" {
+			svc.Spec.Type = svcSpec.Type
+		}
+		svc.ObjectMeta.Annotations = util.CopyStringMap(svcSpec.Annotations)
+		svc.ObjectMeta.Labels = util.CombineStringMap(svc.ObjectMeta.Labels, svcSpec.Labels)
+		if svcSpec.LoadBalancerIP != nil {
+			svc.Spec.LoadBalancerIP = *svcSpec.LoadBalancerIP
+		}
+		if svcSpec.LoadBalancerSourceRanges != nil {
+			svc.Spec.LoadBalancerSourceRanges = svcSpec.LoadBalancerSourceRanges
+		}
+		if svcSpec.ClusterIP != nil {
+			svc.Spec.ClusterIP = *svcSpec.ClusterIP
+		}
+		if svcSpec.PortName != nil {
+			svc.Spec.Ports[0].Name = *svcSpec.PortName
+		}
+		if svcSpec.Port != nil {
+			svc.Spec.Ports[0].Port = *svcSpec.Port
+		}
+	}
+	return svc
+}
+
+func getNewTiProxyHeadlessService(tc *v1alpha1.TidbCluster) *corev1.Service {
+	objMeta, tiproxyLabel := getTiProxyMeta(tc, controller.TiProxyPeerMemberName)
+	objMeta.Labels = tiproxyLabel.Copy().UsedByPeer().Labels()
+
+	return &corev1.Service{
+		ObjectMeta: objMeta,
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "None",
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "api",
+					Port:       tiproxyAPIPort,
+					TargetPort: intstr.FromInt(tiproxyAPIPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Selector:                 tiproxyLabel.Labels(),
+			PublishNotReadyAddresses: true,
+		},
+	}
+}
+
+// getNewTiProxyConfigMap returns a configMap for TiProxy, the addresses and the certificates
+// are managed by the operator and override the user-defined ones
+func getNewTiProxyConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
+	objMeta, _ := getTiProxyMeta(tc, controller.TiProxyMemberName)
+
+	cfg := config.New(map[string]interface{}{})
+	if tc.Spec.TiProxy.Config != nil {
+		cfg = tc.Spec.TiProxy.Config.DeepCopy()
+	}
+
+	pdName := tc.Name
+	if tc.Heterogeneous() && tc.WithoutLocalPD() {
+		pdName = tc.Spec.Cluster.Name // use pd of reference cluster
+	}
+	cfg.Set("proxy.addr", fmt.Sprintf("0.0.0.0:%d", tiproxySQLPort))
+	cfg.Set("proxy.pd-addrs", fmt.Sprintf("%s:2379", controller.PDMemberName(pdName)))
+	cfg.Set("api.addr", fmt.Sprintf("0.0.0.0:%d", tiproxyAPIPort))
+
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled() {
+		// serve the clients with the certificate of TiDB server and require TLS to TiDB
+		cfg.Set("security.server-tls.ca", path.Join(tiproxyServerCertPath, corev1.ServiceAccountRootCAKey))
+		cfg.Set("security.server-tls.cert", path.Join(tiproxyServerCertPath, corev1.TLSCertKey))
+		cfg.Set("security.server-tls.key", path.Join(tiproxyServerCertPath, corev1.TLSPrivateKeyKey))
+		cfg.Set("security.sql-tls.ca", path.Join(tiproxyServerCertPath, corev1.ServiceAccountRootCAKey))
+		cfg.Set("proxy.require-backend-tls", true)
+	}
+	if tc.IsTLSClusterEnabled() {
+		cfg.Set("security.cluster-tls.ca", path.Join(tiproxyCertPath, corev1.ServiceAccountRootCAKey))
+		cfg.Set("security.cluster-tls.cert", path.Join(tiproxyCertPath, corev1.TLSCertKey))
+		cfg.Set("security.cluster-tls.key", path.Join(tiproxyCertPath, corev1.TLSPrivateKeyKey))
+	}
+
+	confText, err := cfg.MarshalTOML()
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: objMeta,
+		Data: map[string]string{
+			"config-file": string(confText),
+		},
+	}, nil
+}
+
+func getNewTiProxyStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.StatefulSet, error) {
+	spec := tc.BaseTiProxySpec()
+	objMeta, stsLabels := getTiProxyMeta(tc, controller.TiProxyMemberName)
+	replicas := tc.Spec.TiProxy.Replicas
+	podLabels := util.CombineStringMap(stsLabels.Labels(), spec.Labels())
+	promAnnos := controller.AnnProm(tiproxyAPIPort)
+	promAnnos["prometheus.io/path"] = "/api/metrics"
+	podAnnos := util.CombineStringMap(promAnnos, spec.Annotations())
+
+	var envs []corev1.EnvVar
+	if spec.HostNetwork() {
+		// For backward compatibility, set HOSTNAME to POD_NAME in hostNetwork mode
+		envs = append(envs, corev1.EnvVar{
+			Name: "HOSTNAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		})
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{Name: "config", ReadOnly: true, MountPath: tiproxyConfigPath},
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cm.Name,
+					},
+					Items: []corev1.KeyToPath{{Key: "config-file", Path: "proxy.toml"}},
+				},
+			},
+		},
+	}
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled() {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name: tiproxyServerCertVolumeMount, ReadOnly: true, MountPath: tiproxyServerCertPath,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: tiproxyServerCertVolumeMount, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.TiDBServerTLSSecretName(tc.Name),
+				},
+			},
+		})
+	}
+	if tc.IsTLSClusterEnabled() {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name: tiproxyCertVolumeMount, ReadOnly: true, MountPath: tiproxyCertPath,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: tiproxyCertVolumeMount, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: util.ClusterTLSSecretName(tc.Name, label.TiProxyLabelVal),
+				},
+			},
+		})
+	}
+
+	containers := []corev1.Container{
+		{
+			Name:            v1alpha1.TiProxyMemberType.String(),
+			Image:           tc.TiProxyImage(),
+			ImagePullPolicy: spec.ImagePullPolicy(),
+			Command: []string{
+				"/bin/tiproxy",
+				"--config",
+				path.Join(tiproxyConfigPath, "proxy.toml"),
+			},
+			Ports: []corev1.ContainerPort{
+				{
+					Name:          "mysql-client",
+					ContainerPort: tiproxySQLPort,
+					Protocol:      corev1.ProtocolTCP,
+				},
+				{
+					Name:          "api",
+					ContainerPort: tiproxyAPIPort,
+					Protocol:      corev1.ProtocolTCP,
+				},
+			},
+			Resources:    controller.ContainerResource(tc.Spec.TiProxy.ResourceRequirements),
+			Env:          util.AppendEnv(envs, spec.Env()),
+			EnvFrom:      spec.EnvFrom(),
+			VolumeMounts: volumeMounts,
+			ReadinessProbe: &corev1.Probe{
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{
+						Port: intstr.FromInt(tiproxySQLPort),
+					},
+				},
+			},
+		},
+	}
+	setProbes(&containers[0], tc.Spec.TiProxy.Probes, probeHandlers{port: tiproxySQLPort, statusPort: tiproxyAPIPort, statusPath: "/api/debug/health"})
+
+	serviceAccountName := tc.Spec.TiProxy.ServiceAccount
+	if serviceAccountName == "" {
+		serviceAccountName = tc.Spec.ServiceAccount
+	}
+
+	podSpec := spec.BuildPodSpec()
+	var err error
+	podSpec.Containers, err = MergePatchContainers(containers, tc.Spec.TiProxy.AdditionalContainers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge containers spec for TiProxy of [%s/%s], error: %v", objMeta.Namespace, objMeta.Name, err)
+	}
+	podSpec.Volumes = append(volumes, tc.Spec.TiProxy.AdditionalVolumes...)
+	podSpec.ServiceAccountName = serviceAccountName
+	podSpec.InitContainers = spec.InitContainers()
+	tc.Spec.ImagePolicy.ResolvePodImages(&podSpec, v1alpha1.TiProxyMemberType.String())
+	applyEphemeralStorage(spec.EphemeralStorage(), &podSpec, v1alpha1.TiProxyMemberType.String())
+	podSpec.DNSPolicy = spec.DnsPolicy()
+
+	return &apps.StatefulSet{
+		ObjectMeta: objMeta,
+		Spec: apps.StatefulSetSpec{
+			Selector:    stsLabels.LabelSelector(),
+			ServiceName: controller.TiProxyPeerMemberName(tc.Name),
+			Replicas:    &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: podAnnos,
+					Labels:      podLabels,
+				},
+				Spec: podSpec,
+			},
+			// TiProxy servers are stateless and can be started at the same time
+			PodManagementPolicy: apps.ParallelPodManagement,
+			UpdateStrategy: apps.StatefulSetUpdateStrategy{
+				Type: apps.RollingUpdateStatefulSetStrategyType,
+			},
+		},
+	}, nil
+}
+
+type FakeTiProxyMemberManager struct {
+	err error
+}
+
+func NewFakeTiProxyMemberManager() *FakeTiProxyMemberManager {
+	return &FakeTiProxyMemberManager{}
+}
+
+func (m *FakeTiProxyMemberManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeTiProxyMemberManager) Sync(*v1alpha1.TidbCluster) error {
+	if m.err != nil {
+		return m.err
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager/suspender"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestTiProxyMemberManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiProxy()
+	ns, tcName := tc.Namespace, tc.Name
+	tpm := newFakeTiProxyMemberManager()
+
+	g.Expect(tpm.Sync(tc)).To(Succeed())
+	svc, err := tpm.deps.ServiceLister.Services(ns).Get(controller.TiProxyMemberName(tcName))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+	g.Expect(svc.Spec.Ports[0].Port).To(Equal(int32(4000)))
+	g.Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(tiproxySQLPort))
+	_, err = tpm.deps.ServiceLister.Services(ns).Get(controller.TiProxyPeerMemberName(tcName))
+	g.Expect(err).NotTo(HaveOccurred())
+	set, err := tpm.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiProxyMemberName(tcName))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*set.Spec.Replicas).To(Equal(int32(2)))
+	g.Expect(set.Spec.Template.Spec.Containers[0].Image).To(Equal("pingcap/tiproxy:v0.1.0"))
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: controller.TiProxyMemberName(tcName)}}
+	fakeCli := tpm.deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	g.Expect(fakeCli.Get(context.TODO(), client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`pd-addrs = "test-pd:2379"`))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(`max-connections = 1000`))
+
+	// the upgrade waits for the upgrading of TiDB
+	tc.Spec.TiProxy.Version = pointer.StringPtr("v0.1.1")
+	tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+	g.Expect(tpm.Sync(tc)).To(Succeed())
+	set, err = tpm.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiProxyMemberName(tcName))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Spec.Template.Spec.Containers[0].Image).To(Equal("pingcap/tiproxy:v0.1.0"))
+
+	tc.Status.TiDB.Phase = v1alpha1.NormalPhase
+	g.Expect(tpm.Sync(tc)).To(Succeed())
+	set, err = tpm.deps.StatefulSetLister.StatefulSets(ns).Get(controller.TiProxyMemberName(tcName))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Spec.Template.Spec.Containers[0].Image).To(Equal("pingcap/tiproxy:v0.1.1"))
+
+	tc.Spec.TiProxy = nil
+	g.Expect(tpm.Sync(tc)).To(Succeed())
+}
+
+func TestGetNewTiProxyConfigMapWithTLS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiProxy()
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	tc.Spec.TiDB.TLSClient = &v1alpha1.TiDBTLSClient{Enabled: true}
+	cm, err := getNewTiProxyConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring("require-backend-tls = true"))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(tiproxyServerCertPath))
+	g.Expect(cm.Data["config-file"]).To(ContainSubstring(tiproxyCertPath))
+
+	set, err := getNewTiProxyStatefulSet(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	var secrets []string
+	for _, vol := range set.Spec.Template.Spec.Volumes {
+		if vol.Secret != nil {
+			secrets = append(secrets, vol.Secret.SecretName)
+		}
+	}
+	g.Expect(secrets).To(ConsistOf("test-tidb-server-secret", "test-tiproxy-cluster-secret"))
+}
+
+func newFakeTiProxyMemberManager() *tiproxyMemberManager {
+	return &tiproxyMemberManager{
+		deps:      controller.NewFakeDependencies(),
+		suspender: suspender.NewFakeSuspender(),
+	}
+}
+
+func newTidbClusterForTiProxy() *v1alpha1.TidbCluster {
+	updateStrategy := v1alpha1.ConfigUpdateStrategyInPlace
+	return &v1alpha1.TidbCluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TidbCluster",
+			APIVersion: "pingcap.com/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: corev1.NamespaceDefault,
+			UID:       "test",
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{},
+			TiKV: &v1alpha1.TiKVSpec{},
+			TiDB: &v1alpha1.TiDBSpec{},
+			TiProxy: &v1alpha1.TiProxySpec{
+				ComponentSpec: v1alpha1.ComponentSpec{
+					Version:              pointer.StringPtr("v0.1.0"),
+					ConfigUpdateStrategy: &updateStrategy,
+				},
+				Replicas:  2,
+				BaseImage: "pingcap/tiproxy",
+				Service: &v1alpha1.ServiceSpec{
+					Type: corev1.ServiceTypeLoadBalancer,
+					Port: pointer.Int32Ptr(4000),
+				},
+				Config: config.New(map[string]interface{}{
+					"proxy": map[string]interface{}{
+						"max-connections": 1000,
+					},
+				}),
+			},
+		},
+	}
+}
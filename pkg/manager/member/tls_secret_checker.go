@@ -0,0 +1,130 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// TLSSecretMissing is the reason of the event emitted when the secrets required by the TLS between
+// components don't exist
+const TLSSecretMissing = "TLSSecretMissing"
+
+// TLSSecretChecker checks that the secrets of the certificates mounted by the components exist if the
+// TLS between components is enabled, the components are not synced until they're created, otherwise
+// the new Pods are stuck in ContainerCreating.
+//
+// If the certificates are issued by cert-manager, i.e. the annotation tidb.pingcap.com/tls-cert-manager
+// is "true", the secrets may be created after the cluster, they're waited for without warning events.
+type TLSSecretChecker interface {
+	Check(tc *v1alpha1.TidbCluster) error
+}
+
+type tlsSecretChecker struct {
+	deps *controller.Dependencies
+}
+
+// NewTLSSecretChecker returns a TLSSecretChecker
+func NewTLSSecretChecker(deps *controller.Dependencies) TLSSecretChecker {
+	return &tlsSecretChecker{
+		deps: deps,
+	}
+}
+
+func (c *tlsSecretChecker) Check(tc *v1alpha1.TidbCluster) error {
+	if !tc.IsTLSClusterEnabled() {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	var missing []string
+	for _, name := range tlsClusterSecretNames(tc) {
+		secret, err := c.deps.SecretLister.Secrets(ns).Get(name)
+		if errors.IsNotFound(err) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("tls secret checker: failed to get secret %s/%s, error: %v", ns, name, err)
+		}
+		if _, ok := secret.Data[corev1.TLSCertKey]; !ok {
+			missing = append(missing, name)
+		} else if _, ok := secret.Data[corev1.TLSPrivateKeyKey]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("TLS between components is enabled, but secrets %v don't exist or don't contain %s and %s",
+		missing, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	if tc.Annotations[label.AnnTLSCertManager] == "true" {
+		klog.Infof("tc %s/%s: waiting for cert-manager to issue certificates, %s", ns, tcName, msg)
+	} else {
+		c.deps.Recorder.Event(tc, corev1.EventTypeWarning, TLSSecretMissing, msg)
+	}
+	return controller.RequeueErrorf("tc %s/%s: %s", ns, tcName, msg)
+}
+
+// tlsClusterSecretNames returns the names of the secrets mounted by the components in the spec and
+// the client certificate used by TiDB Operator, the components being removed are skipped
+func tlsClusterSecretNames(tc *v1alpha1.TidbCluster) []string {
+	components := []struct {
+		typ      v1alpha1.MemberType
+		labelVal string
+		exists   bool
+	}{
+		{v1alpha1.PDMemberType, label.PDLabelVal, tc.Spec.PD != nil},
+		{v1alpha1.TiKVMemberType, label.TiKVLabelVal, tc.Spec.TiKV != nil},
+		{v1alpha1.TiDBMemberType, label.TiDBLabelVal, tc.Spec.TiDB != nil},
+		{v1alpha1.TiFlashMemberType, label.TiFlashLabelVal, tc.Spec.TiFlash != nil},
+		{v1alpha1.TiCDCMemberType, label.TiCDCLabelVal, tc.Spec.TiCDC != nil},
+		{v1alpha1.PumpMemberType, label.PumpLabelVal, tc.Spec.Pump != nil},
+		{v1alpha1.TiProxyMemberType, label.TiProxyLabelVal, tc.Spec.TiProxy != nil},
+	}
+	names := []string{util.ClusterClientTLSSecretName(tc.Name)}
+	for _, c := range components {
+		if c.exists && !tc.ComponentRemoving(c.typ) {
+			names = append(names, util.ClusterTLSSecretName(tc.Name, c.labelVal))
+		}
+	}
+	return names
+}
+
+type FakeTLSSecretChecker struct {
+	err error
+}
+
+func NewFakeTLSSecretChecker() *FakeTLSSecretChecker {
+	return &FakeTLSSecretChecker{}
+}
+
+func (c *FakeTLSSecretChecker) SetCheckError(err error) {
+	c.err = err
+}
+
+func (c *FakeTLSSecretChecker) Check(_ *v1alpha1.TidbCluster) error {
+	return c.err
+}
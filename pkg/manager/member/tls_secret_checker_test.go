@@ -0,0 +1,80 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTLSSecretCheckerCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	checker := NewTLSSecretChecker(deps)
+	recorder := deps.Recorder.(*record.FakeRecorder)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "test"},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:         &v1alpha1.PDSpec{},
+			TiKV:       &v1alpha1.TiKVSpec{},
+			TiDB:       &v1alpha1.TiDBSpec{},
+			TLSCluster: &v1alpha1.TLSCluster{Enabled: true},
+		},
+	}
+	addSecret := func(name string, data map[string][]byte) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: name},
+			Data:       data,
+		}
+		g.Expect(deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer().Add(secret)).To(Succeed())
+	}
+	certs := map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")}
+	addSecret("test-cluster-client-secret", certs)
+	addSecret("test-pd-cluster-secret", certs)
+	addSecret("test-tikv-cluster-secret", certs)
+	// the key is missing
+	addSecret("test-tidb-cluster-secret", map[string][]byte{corev1.TLSCertKey: []byte("cert")})
+
+	err := checker.Check(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("test-tidb-cluster-secret"))
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring(TLSSecretMissing))
+
+	// the certificates are issued by cert-manager
+	tc.Annotations = map[string]string{label.AnnTLSCertManager: "true"}
+	g.Expect(controller.IsRequeueError(checker.Check(tc))).To(BeTrue())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	addSecret("test-tidb-cluster-secret", certs)
+	g.Expect(checker.Check(tc)).To(Succeed())
+
+	// TiFlash is being removed
+	tc.Spec.TiFlash = &v1alpha1.TiFlashSpec{}
+	tc.Annotations[label.AnnRemoveComponents] = "tiflash"
+	g.Expect(checker.Check(tc)).To(Succeed())
+
+	tc.Spec.TLSCluster = nil
+	tc.Spec.TiCDC = &v1alpha1.TiCDCSpec{}
+	g.Expect(checker.Check(tc)).To(Succeed())
+}
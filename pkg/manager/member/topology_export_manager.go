@@ -0,0 +1,319 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/topology"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// topologyPort is a port served by the instances of a component
+type topologyPort struct {
+	name     string
+	port     int32
+	protocol string
+	path     string
+	tls      bool
+}
+
+// topologyComponent is a component listed in the discovery document
+type topologyComponent struct {
+	memberType v1alpha1.MemberType
+	// service is the Service load balancing the instances, it's empty if there is no such Service
+	service      string
+	servicePorts []topologyPort
+	peerService  string
+	ordinals     sets.Int32
+	ports        []topologyPort
+}
+
+type topologyExportManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewTopologyExportManager returns a manager which publishes the discovery document of the cluster
+// in the ConfigMap of spec.topologyExport, the document lists the endpoints of the components and
+// their instances. The ConfigMap is deleted with spec.topologyExport.
+func NewTopologyExportManager(deps *controller.Dependencies) manager.Manager {
+	return &topologyExportManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (m *topologyExportManager) Sync(tc *v1alpha1.TidbCluster) error {
+	status := tc.Status.TopologyExport
+	name := tc.TopologyExportConfigMapName()
+	if status != nil && (tc.Spec.TopologyExport == nil || status.ConfigMapName != name) {
+		if err := m.deleteConfigMap(tc, status.ConfigMapName); err != nil {
+			return err
+		}
+		tc.Status.TopologyExport = nil
+		status = nil
+	}
+	if tc.Spec.TopologyExport == nil {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(getTopologyDocument(tc), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the topology document of tc %s/%s: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	changed := true
+	existing, err := m.deps.ConfigMapLister.ConfigMaps(tc.GetNamespace()).Get(name)
+	if err == nil {
+		changed = existing.Data[topology.DocumentKey] != string(data)
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get topology configmap %s/%s: %v", tc.GetNamespace(), name, err)
+	}
+
+	if changed {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: getTopologyConfigMapMeta(tc, name),
+			Data:       map[string]string{topology.DocumentKey: string(data)},
+		}
+		if _, err := m.deps.TypedControl.CreateOrUpdateConfigMap(tc, cm); err != nil {
+			return controller.RequeueErrorf("error creating or updating topology configmap %s/%s: %v", tc.GetNamespace(), name, err)
+		}
+	}
+	if status == nil {
+		status = &v1alpha1.TopologyExportStatus{ConfigMapName: name}
+		tc.Status.TopologyExport = status
+	}
+	if changed || status.LastUpdateTime == nil {
+		status.LastUpdateTime = &metav1.Time{Time: m.now()}
+	}
+	return nil
+}
+
+func (m *topologyExportManager) deleteConfigMap(tc *v1alpha1.TidbCluster, name string) error {
+	cm := &corev1.ConfigMap{ObjectMeta: getTopologyConfigMapMeta(tc, name)}
+	exist, err := m.deps.TypedControl.Exist(client.ObjectKey{Namespace: cm.Namespace, Name: cm.Name}, &corev1.ConfigMap{})
+	if err != nil {
+		return fmt.Errorf("failed to check topology configmap %s/%s: %v", cm.Namespace, cm.Name, err)
+	}
+	if !exist {
+		return nil
+	}
+	return m.deps.TypedControl.Delete(tc, cm)
+}
+
+func getTopologyConfigMapMeta(tc *v1alpha1.TidbCluster, name string) metav1.ObjectMeta {
+	var labels map[string]string
+	if tc.Spec.TopologyExport != nil {
+		labels = tc.Spec.TopologyExport.Labels
+	}
+	return metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       tc.Namespace,
+		Labels:          util.CombineStringMap(labels, label.New().Instance(tc.GetInstanceName()).Topology().Labels()),
+		OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+	}
+}
+
+// getTopologyDocument returns the discovery document of the cluster, the instances are listed
+// by the desired ordinals of the StatefulSets, including the pods created by failover
+func getTopologyDocument(tc *v1alpha1.TidbCluster) *topology.Document {
+	clusterDomain := controller.FormatClusterDomain(tc.Spec.ClusterDomain)
+	doc := &topology.Document{
+		Version:       topology.Version,
+		Cluster:       tc.GetName(),
+		Namespace:     tc.GetNamespace(),
+		ClusterDomain: tc.Spec.ClusterDomain,
+		TLS: topology.TLS{
+			Cluster:     tc.IsTLSClusterEnabled(),
+			MySQLClient: tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled(),
+		},
+		Components: []topology.Component{},
+	}
+	if doc.TLS.Cluster {
+		doc.TLS.ClientSecretName = util.ClusterClientTLSSecretName(tc.GetName())
+	}
+
+	toEndpoints := func(host string, ports []topologyPort) []topology.Endpoint {
+		endpoints := make([]topology.Endpoint, 0, len(ports))
+		for _, port := range ports {
+			endpoints = append(endpoints, topology.Endpoint{
+				Name:     port.name,
+				Address:  fmt.Sprintf("%s:%d", host, port.port),
+				Protocol: port.protocol,
+				Path:     port.path,
+				TLS:      port.tls,
+			})
+		}
+		return endpoints
+	}
+	for _, c := range topologyComponents(tc, doc.TLS) {
+		component := topology.Component{Name: c.memberType.String(), Instances: []topology.Instance{}}
+		if c.service != "" {
+			component.Endpoints = toEndpoints(fmt.Sprintf("%s.%s.svc%s", c.service, tc.GetNamespace(), clusterDomain), c.servicePorts)
+		}
+		for _, ordinal := range c.ordinals.List() {
+			podName := ordinalPodName(c.memberType, tc.GetName(), ordinal)
+			host := fmt.Sprintf("%s.%s.%s.svc%s", podName, c.peerService, tc.GetNamespace(), clusterDomain)
+			component.Instances = append(component.Instances, topology.Instance{Name: podName, Endpoints: toEndpoints(host, c.ports)})
+		}
+		doc.Components = append(doc.Components, component)
+	}
+	return doc
+}
+
+// topologyComponents returns the deployed components of the cluster and their endpoints
+func topologyComponents(tc *v1alpha1.TidbCluster, tls topology.TLS) []topologyComponent {
+	metrics := func(port int32) topologyPort {
+		return topologyPort{name: "metrics", port: port, protocol: topology.ProtocolHTTP, path: "/metrics", tls: tls.Cluster}
+	}
+	replicaOrdinals := func(replicas int32) sets.Int32 {
+		ordinals := sets.NewInt32()
+		for i := int32(0); i < replicas; i++ {
+			ordinals.Insert(i)
+		}
+		return ordinals
+	}
+
+	var components []topologyComponent
+	if tc.Spec.PD != nil {
+		client := topologyPort{name: "client", port: 2379, protocol: topology.ProtocolHTTP, tls: tls.Cluster}
+		components = append(components, topologyComponent{
+			memberType:   v1alpha1.PDMemberType,
+			service:      controller.PDMemberName(tc.Name),
+			servicePorts: []topologyPort{client},
+			peerService:  controller.PDPeerMemberName(tc.Name),
+			ordinals:     tc.PDStsDesiredOrdinals(false),
+			ports:        []topologyPort{client, metrics(2379)},
+		})
+	}
+	if tc.Spec.TiKV != nil {
+		components = append(components, topologyComponent{
+			memberType:  v1alpha1.TiKVMemberType,
+			peerService: controller.TiKVPeerMemberName(tc.Name),
+			ordinals:    tc.TiKVStsDesiredOrdinals(false),
+			ports: []topologyPort{
+				{name: "server", port: 20160, protocol: topology.ProtocolGRPC, tls: tls.Cluster},
+				{name: "status", port: 20180, protocol: topology.ProtocolHTTP, tls: tls.Cluster},
+				metrics(20180),
+			},
+		})
+	}
+	if tc.Spec.TiFlash != nil {
+		proxyMetrics := metrics(20292)
+		proxyMetrics.name = "proxy-metrics"
+		components = append(components, topologyComponent{
+			memberType:  v1alpha1.TiFlashMemberType,
+			peerService: controller.TiFlashPeerMemberName(tc.Name),
+			ordinals:    tc.TiFlashStsDesiredOrdinals(false),
+			ports: []topologyPort{
+				{name: "flash", port: 3930, protocol: topology.ProtocolGRPC, tls: tls.Cluster},
+				{name: "proxy", port: 20170, protocol: topology.ProtocolGRPC, tls: tls.Cluster},
+				metrics(8234),
+				proxyMetrics,
+			},
+		})
+	}
+	if tc.Spec.TiDB != nil {
+		status := topologyPort{name: "status", port: 10080, protocol: topology.ProtocolHTTP, tls: tls.Cluster}
+		component := topologyComponent{
+			memberType:  v1alpha1.TiDBMemberType,
+			peerService: controller.TiDBPeerMemberName(tc.Name),
+			ordinals:    tc.TiDBStsDesiredOrdinals(false),
+			ports: []topologyPort{
+				{name: "sql", port: 4000, protocol: topology.ProtocolMySQL, tls: tls.MySQLClient},
+				status,
+				metrics(10080),
+			},
+		}
+		// the Service of TiDB is only created if spec.tidb.service is set
+		if svc := tc.Spec.TiDB.Service; svc != nil {
+			component.service = controller.TiDBMemberName(tc.Name)
+			component.servicePorts = []topologyPort{{name: "sql", port: tc.Spec.TiDB.GetServicePort(), protocol: topology.ProtocolMySQL, tls: tls.MySQLClient}}
+			if svc.ShouldExposeStatus() {
+				component.servicePorts = append(component.servicePorts, status)
+			}
+		}
+		components = append(components, component)
+	}
+	if tc.Spec.TiProxy != nil {
+		sql := topologyPort{name: "sql", port: tiproxySQLPort, protocol: topology.ProtocolMySQL, tls: tls.MySQLClient}
+		servicePort := sql
+		if svc := tc.Spec.TiProxy.Service; svc != nil && svc.Port != nil {
+			servicePort.port = *svc.Port
+		}
+		components = append(components, topologyComponent{
+			memberType:   v1alpha1.TiProxyMemberType,
+			service:      controller.TiProxyMemberName(tc.Name),
+			servicePorts: []topologyPort{servicePort},
+			peerService:  controller.TiProxyPeerMemberName(tc.Name),
+			ordinals:     replicaOrdinals(tc.Spec.TiProxy.Replicas),
+			ports: []topologyPort{
+				sql,
+				{name: "api", port: tiproxyAPIPort, protocol: topology.ProtocolHTTP},
+				{name: "metrics", port: tiproxyAPIPort, protocol: topology.ProtocolHTTP, path: "/api/metrics"},
+			},
+		})
+	}
+	if tc.Spec.TiCDC != nil {
+		components = append(components, topologyComponent{
+			memberType:  v1alpha1.TiCDCMemberType,
+			peerService: controller.TiCDCPeerMemberName(tc.Name),
+			ordinals:    replicaOrdinals(tc.Spec.TiCDC.Replicas),
+			ports: []topologyPort{
+				{name: "client", port: 8301, protocol: topology.ProtocolHTTP, tls: tls.Cluster},
+				metrics(8301),
+			},
+		})
+	}
+	if tc.Spec.Pump != nil {
+		components = append(components, topologyComponent{
+			memberType:  v1alpha1.PumpMemberType,
+			peerService: controller.PumpPeerMemberName(tc.Name),
+			ordinals:    replicaOrdinals(tc.Spec.Pump.Replicas),
+			ports: []topologyPort{
+				{name: "pump", port: 8250, protocol: topology.ProtocolGRPC, tls: tls.Cluster},
+				metrics(8250),
+			},
+		})
+	}
+	return components
+}
+
+type FakeTopologyExportManager struct {
+	err error
+}
+
+func NewFakeTopologyExportManager() *FakeTopologyExportManager {
+	return &FakeTopologyExportManager{}
+}
+
+func (m *FakeTopologyExportManager) SetSyncError(err error) {
+	m.err = err
+}
+
+func (m *FakeTopologyExportManager) Sync(_ *v1alpha1.TidbCluster) error {
+	return m.err
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/topology"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestTopologyExportManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	ctrl := deps.GenericControl.(*controller.FakeGenericControl)
+	indexer := deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	m := NewTopologyExportManager(deps).(*topologyExportManager)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: corev1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:             &v1alpha1.PDSpec{Replicas: 1},
+			TiKV:           &v1alpha1.TiKVSpec{Replicas: 2},
+			TiDB:           &v1alpha1.TiDBSpec{Replicas: 1, Service: &v1alpha1.TiDBServiceSpec{}},
+			TLSCluster:     &v1alpha1.TLSCluster{Enabled: true},
+			TopologyExport: &v1alpha1.TopologyExportSpec{Labels: map[string]string{"dns": "enabled"}},
+		},
+	}
+	getDocument := func(name string) *topology.Document {
+		cm := &corev1.ConfigMap{}
+		g.Expect(ctrl.FakeCli.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: name}, cm)).To(Succeed())
+		g.Expect(cm.Labels).To(HaveKeyWithValue("dns", "enabled"))
+		g.Expect(indexer.Update(cm)).To(Succeed())
+		doc, err := topology.Parse([]byte(cm.Data[topology.DocumentKey]))
+		g.Expect(err).NotTo(HaveOccurred())
+		return doc
+	}
+
+	g.Expect(m.Sync(tc)).To(Succeed())
+	doc := getDocument("test-topology")
+	g.Expect(doc.TLS).To(Equal(topology.TLS{Cluster: true, ClientSecretName: "test-cluster-client-secret"}))
+	g.Expect(doc.Components).To(HaveLen(3))
+	pd := doc.Components[0]
+	g.Expect(pd.Name).To(Equal("pd"))
+	g.Expect(pd.Endpoints).To(Equal([]topology.Endpoint{{Name: "client", Address: "test-pd.default.svc:2379", Protocol: topology.ProtocolHTTP, TLS: true}}))
+	g.Expect(pd.Instances[0].Name).To(Equal("test-pd-0"))
+	g.Expect(pd.Instances[0].Endpoints[1]).To(Equal(topology.Endpoint{
+		Name: "metrics", Address: "test-pd-0.test-pd-peer.default.svc:2379", Protocol: topology.ProtocolHTTP, Path: "/metrics", TLS: true,
+	}))
+	tikv := doc.Components[1]
+	g.Expect(tikv.Endpoints).To(BeEmpty())
+	g.Expect(tikv.Instances).To(HaveLen(2))
+	g.Expect(tikv.Instances[1].Endpoints[0].Address).To(Equal("test-tikv-1.test-tikv-peer.default.svc:20160"))
+	tidb := doc.Components[2]
+	g.Expect(tidb.Endpoints[0]).To(Equal(topology.Endpoint{Name: "sql", Address: "test-tidb.default.svc:4000", Protocol: topology.ProtocolMySQL}))
+	g.Expect(tidb.Endpoints[1].Name).To(Equal("status"))
+	updated := tc.Status.TopologyExport.LastUpdateTime
+
+	// the document is only updated when the topology changes, the ConfigMap isn't written otherwise
+	now = now.Add(time.Minute)
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: "test-topology"}}
+	g.Expect(ctrl.FakeCli.Delete(context.TODO(), cm)).To(Succeed())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TopologyExport.LastUpdateTime).To(Equal(updated))
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: "test-topology"}, cm)).NotTo(Succeed())
+	tc.Spec.TiKV.Replicas = 3
+	tc.Spec.ClusterDomain = "cluster.local"
+	port := int32(6001)
+	tc.Spec.TiProxy = &v1alpha1.TiProxySpec{Replicas: 2, Service: &v1alpha1.ServiceSpec{Port: &port}}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TopologyExport.LastUpdateTime.Time).To(Equal(now))
+	doc = getDocument("test-topology")
+	g.Expect(doc.Components[1].Instances[2].Endpoints[0].Address).To(Equal("test-tikv-2.test-tikv-peer.default.svc.cluster.local:20160"))
+	tiproxy := doc.Components[3]
+	g.Expect(tiproxy.Name).To(Equal("tiproxy"))
+	g.Expect(tiproxy.Endpoints).To(Equal([]topology.Endpoint{{Name: "sql", Address: "test-tiproxy.default.svc.cluster.local:6001", Protocol: topology.ProtocolMySQL}}))
+	g.Expect(tiproxy.Instances).To(HaveLen(2))
+	g.Expect(tiproxy.Instances[1].Endpoints[0].Address).To(Equal("test-tiproxy-1.test-tiproxy-peer.default.svc.cluster.local:6000"))
+
+	// the ConfigMap is moved with its name and removed with spec.topologyExport
+	tc.Spec.TopologyExport.ConfigMapName = "topology"
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TopologyExport.ConfigMapName).To(Equal("topology"))
+	getDocument("topology")
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: "test-topology"}, &corev1.ConfigMap{})).NotTo(Succeed())
+	tc.Spec.TopologyExport = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TopologyExport).To(BeNil())
+	g.Expect(ctrl.FakeCli.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: "topology"}, &corev1.ConfigMap{})).NotTo(Succeed())
+}
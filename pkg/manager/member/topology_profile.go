@@ -0,0 +1,270 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// topologyRuleGroupID is the placement rule group managed for the topology profile, it
+	// overrides the default rule group of PD. Its index is larger than the index of the group of
+	// the TiKV store roles, so the rules of the profile take precedence if both are used.
+	topologyRuleGroupID    = "tidb-operator-topology"
+	topologyRuleGroupIndex = 2
+
+	zoneLocationLabel = "zone"
+)
+
+// topologyLocationLabels are the location labels of PD for the topology profile, the zone of
+// a store is the zone of its node
+var topologyLocationLabels = []string{zoneLocationLabel, "host"}
+
+func threeDCScheme(profile *v1alpha1.TopologyProfile) v1alpha1.ThreeDCScheme {
+	if profile.Scheme == "" {
+		return v1alpha1.ThreeDCScheme221
+	}
+	return profile.Scheme
+}
+
+// threeDCVoters returns the number of the voters of each region in the scheme
+func threeDCVoters(scheme v1alpha1.ThreeDCScheme) int64 {
+	if scheme == v1alpha1.ThreeDCScheme32 {
+		return 3
+	}
+	return 5
+}
+
+// setPDTopologyProfileConfig sets the replication config of PD for the topology profile,
+// the items set explicitly are not overridden
+func setPDTopologyProfileConfig(profile *v1alpha1.TopologyProfile, c *config.GenericConfig) {
+	if c.Get("replication.location-labels") == nil {
+		c.Set("replication.location-labels", topologyLocationLabels)
+	}
+	if c.Get("replication.max-replicas") == nil {
+		c.Set("replication.max-replicas", threeDCVoters(threeDCScheme(profile)))
+	}
+	if c.Get("replication.enable-placement-rules") == nil {
+		c.Set("replication.enable-placement-rules", true)
+	}
+}
+
+// syncTopologyProfile makes sure the zones of the topology profile exist on the nodes, and sets the
+// location labels and the placement rules of the profile to PD. The location labels are set at runtime
+// as well, as the replication config in the config file only takes effect when PD is bootstrapped.
+// The placement rules are deleted after the profile is removed, the location labels are kept.
+func (m *tikvMemberManager) syncTopologyProfile(tc *v1alpha1.TidbCluster) error {
+	profile := tc.Spec.TopologyProfile
+	if !tc.TiKVBootStrapped() {
+		return nil
+	}
+	if profile == nil {
+		// the profile can only be set for the main cluster, which owns the rules
+		if tc.Heterogeneous() {
+			return nil
+		}
+		return m.deleteTopologyPlacementRules(tc)
+	}
+	m.topologyRulesDeleted.Delete(fmt.Sprintf("%s/%s", tc.Namespace, tc.Name))
+	if err := m.checkTopologyZones(tc); err != nil {
+		m.deps.Recorder.Event(tc, corev1.EventTypeWarning, "TopologyZonesNotFound", err.Error())
+		return err
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	pdConfig, err := pdClient.GetConfig()
+	if err != nil {
+		return err
+	}
+	var locationLabels []string
+	if pdConfig.Replication != nil {
+		locationLabels = pdConfig.Replication.LocationLabels
+	}
+	if !sets.NewString(locationLabels...).Has(zoneLocationLabel) {
+		locationLabels = topologyLocationLabels
+		if tc.Spec.PD != nil && tc.Spec.PD.Config != nil {
+			if v := tc.Spec.PD.Config.Get("replication.location-labels"); v != nil {
+				if ls, err := v.AsStringSlice(); err == nil {
+					locationLabels = ls
+				}
+			}
+		}
+		if err := pdClient.UpdateReplicationConfig(pdapi.PDReplicationConfig{LocationLabels: locationLabels}); err != nil {
+			return err
+		}
+		klog.Infof("syncTopologyProfile: location labels of PD are set to %v for cluster %s/%s", locationLabels, tc.Namespace, tc.Name)
+	}
+
+	current, err := getPlacementRulesOfGroup(pdClient, topologyRuleGroupID)
+	if err != nil {
+		return err
+	}
+	rules := threeDCPlacementRules(profile, locationLabels)
+	group := &pdapi.PlacementRuleGroup{
+		ID:       topologyRuleGroupID,
+		Index:    topologyRuleGroupIndex,
+		Override: true,
+	}
+	set, err := setPlacementRules(pdClient, group, current, rules)
+	for _, id := range set {
+		klog.Infof("syncTopologyProfile: placement rule %s/%s is set for cluster %s/%s", topologyRuleGroupID, id, tc.Namespace, tc.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	// delete the rules of the former scheme
+	for _, rule := range rules {
+		delete(current, rule.ID)
+	}
+	for id := range current {
+		if err := pdClient.DeletePlacementRule(topologyRuleGroupID, id); err != nil {
+			return err
+		}
+		klog.Infof("syncTopologyProfile: placement rule %s/%s is deleted for cluster %s/%s", topologyRuleGroupID, id, tc.Namespace, tc.Name)
+	}
+	return nil
+}
+
+// deleteTopologyPlacementRules deletes the placement rules of the topology profile. The rules are checked
+// once after the profile is removed or the operator is restarted.
+func (m *tikvMemberManager) deleteTopologyPlacementRules(tc *v1alpha1.TidbCluster) error {
+	key := fmt.Sprintf("%s/%s", tc.Namespace, tc.Name)
+	if _, ok := m.topologyRulesDeleted.Load(key); ok {
+		return nil
+	}
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	current, err := getPlacementRulesOfGroup(pdClient, topologyRuleGroupID)
+	if err != nil {
+		return err
+	}
+	for id := range current {
+		if err := pdClient.DeletePlacementRule(topologyRuleGroupID, id); err != nil {
+			return err
+		}
+		klog.Infof("deleteTopologyPlacementRules: placement rule %s/%s is deleted as the topology profile is removed, cluster %s/%s",
+			topologyRuleGroupID, id, tc.Namespace, tc.Name)
+	}
+	m.topologyRulesDeleted.Store(key, struct{}{})
+	return nil
+}
+
+// checkTopologyZones returns an error if any zone of the topology profile is not the zone of any node
+func (m *tikvMemberManager) checkTopologyZones(tc *v1alpha1.TidbCluster) error {
+	if !m.deps.CLIConfig.HasNodePermission() {
+		klog.V(4).Infof("TidbCluster: [%s/%s], no permission for nodes, skip checking the zones of the topology profile", tc.Namespace, tc.Name)
+		return nil
+	}
+	nodes, err := m.deps.NodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("checkTopologyZones: failed to list nodes, error: %v", err)
+	}
+	zones := sets.NewString()
+	for _, node := range nodes {
+		if zone, ok := nodeZone(node.Labels); ok {
+			zones.Insert(zone)
+		}
+	}
+	if missing := sets.NewString(tc.Spec.TopologyProfile.Zones...).Difference(zones); missing.Len() > 0 {
+		return fmt.Errorf("zones %v of the topology profile of cluster %s/%s are not found on any node", missing.List(), tc.Namespace, tc.Name)
+	}
+	return nil
+}
+
+// threeDCPlacementRules returns the placement rules of the scheme of the three-dc profile, the leaders
+// are placed in the first zone and the replica in the third zone never becomes the leader
+func threeDCPlacementRules(profile *v1alpha1.TopologyProfile, locationLabels []string) []*pdapi.PlacementRule {
+	zones := profile.Zones
+	rule := func(id, role string, count int, zone string) *pdapi.PlacementRule {
+		return &pdapi.PlacementRule{
+			GroupID: topologyRuleGroupID,
+			ID:      id,
+			Role:    role,
+			Count:   count,
+			LabelConstraints: []pdapi.LabelConstraint{
+				{Key: zoneLocationLabel, Op: "in", Values: []string{zone}},
+			},
+			LocationLabels: locationLabels,
+		}
+	}
+	if threeDCScheme(profile) == v1alpha1.ThreeDCScheme32 {
+		return []*pdapi.PlacementRule{
+			rule("zone-0-leader", "leader", 1, zones[0]),
+			rule("zone-1-voter", "voter", 1, zones[1]),
+			rule("zone-2-follower", "follower", 1, zones[2]),
+			rule("zone-0-learner", "learner", 1, zones[0]),
+			rule("zone-1-learner", "learner", 1, zones[1]),
+		}
+	}
+	return []*pdapi.PlacementRule{
+		rule("zone-0-leader", "leader", 1, zones[0]),
+		rule("zone-0-voter", "voter", 1, zones[0]),
+		rule("zone-1-voter", "voter", 2, zones[1]),
+		rule("zone-2-follower", "follower", 1, zones[2]),
+	}
+}
+
+// syncTiDBServerZoneLabels sets the zones of the TiDB Pods to the labels of the TiDB servers, so that
+// the closest replicas are read from. The labels are lost after TiDB restarts, so the Pods, the restarts
+// of the TiDB containers and the zones the labels are set for are recorded, and the labels are only set
+// again when they change. The failures are only logged as TiDB may be restarting.
+func (m *tidbMemberManager) syncTiDBServerZoneLabels(tc *v1alpha1.TidbCluster) {
+	ns := tc.GetNamespace()
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiDB().Selector()
+	if err != nil {
+		klog.Warningf("syncTiDBServerZoneLabels: failed to get selector for cluster %s/%s, error: %v", ns, tc.GetName(), err)
+		return
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		klog.Warningf("syncTiDBServerZoneLabels: failed to list pods for cluster %s/%s, error: %v", ns, tc.GetName(), err)
+		return
+	}
+	for _, pod := range pods {
+		zone, ok := pod.Labels[label.ZoneLabelKey]
+		if !ok || !podutil.IsPodReady(pod) {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", ns, pod.Name)
+		var restarts int32
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == v1alpha1.TiDBMemberType.String() {
+				restarts = status.RestartCount
+			}
+		}
+		labeled := fmt.Sprintf("%s/%d/%s", pod.UID, restarts, zone)
+		if v, ok := m.serverZoneLabels.Load(key); ok && v.(string) == labeled {
+			continue
+		}
+		client := m.deps.TiDBControl.GetTiDBPodClient(tc, pod.Name)
+		if err := client.SetLabels(map[string]string{zoneLocationLabel: zone}); err != nil {
+			klog.Warningf("syncTiDBServerZoneLabels: failed to set zone %s to TiDB %s/%s, error: %v", zone, ns, pod.Name, err)
+			continue
+		}
+		m.serverZoneLabels.Store(key, labeled)
+		klog.Infof("syncTiDBServerZoneLabels: zone %s is set to TiDB %s/%s", zone, ns, pod.Name)
+	}
+}
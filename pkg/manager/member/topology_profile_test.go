@@ -0,0 +1,186 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tidbapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newThreeDCTopologyProfile() *v1alpha1.TopologyProfile {
+	return &v1alpha1.TopologyProfile{
+		Type:  v1alpha1.TopologyProfileThreeDC,
+		Zones: []string{"dc-1", "dc-2", "dc-3"},
+	}
+}
+
+func TestSetPDTopologyProfileConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := config.New(map[string]interface{}{})
+	setPDTopologyProfileConfig(newThreeDCTopologyProfile(), c)
+	g.Expect(c.Get("replication.location-labels").MustStringSlice()).To(Equal([]string{"zone", "host"}))
+	g.Expect(c.Get("replication.max-replicas").MustInt()).To(Equal(int64(5)))
+	g.Expect(c.Get("replication.enable-placement-rules").Interface()).To(Equal(true))
+
+	// the items set explicitly are not overridden
+	profile := newThreeDCTopologyProfile()
+	profile.Scheme = v1alpha1.ThreeDCScheme32
+	c = config.New(map[string]interface{}{})
+	c.Set("replication.location-labels", []string{"zone", "rack", "host"})
+	setPDTopologyProfileConfig(profile, c)
+	g.Expect(c.Get("replication.location-labels").MustStringSlice()).To(Equal([]string{"zone", "rack", "host"}))
+	g.Expect(c.Get("replication.max-replicas").MustInt()).To(Equal(int64(3)))
+}
+
+func TestTiKVMemberManagerSyncTopologyProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	tc.Spec.TopologyProfile = newThreeDCTopologyProfile()
+	tc.Status.TiKV.BootStrapped = true
+	tmm, _, _, pdClient, _, nodeIndexer := newFakeTiKVMemberManager(tc)
+	for _, zone := range []string{"dc-1", "dc-2"} {
+		nodeIndexer.Add(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-" + zone, Labels: map[string]string{corev1.LabelTopologyZone: zone}},
+		})
+	}
+
+	var replication *pdapi.PDReplicationConfig
+	var set, deleted []string
+	pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.PDConfigFromAPI{Replication: &pdapi.PDReplicationConfig{LocationLabels: []string{"host"}}}, nil
+	})
+	pdClient.AddReaction(pdapi.UpdateReplicationActionType, func(action *pdapi.Action) (interface{}, error) {
+		replication = &action.Replication
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.GetPlacementRulesActionType, func(action *pdapi.Action) (interface{}, error) {
+		return []*pdapi.PlacementRule{
+			{GroupID: "pd", ID: "default", Role: "voter", Count: 3},
+			{GroupID: topologyRuleGroupID, ID: "zone-0-learner", Role: "learner", Count: 1},
+		}, nil
+	})
+	pdClient.AddReaction(pdapi.SetPlacementRuleActionType, func(action *pdapi.Action) (interface{}, error) {
+		set = append(set, action.Rule.ID)
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.DeletePlacementRuleActionType, func(action *pdapi.Action) (interface{}, error) {
+		deleted = append(deleted, action.Rule.GroupID+"/"+action.Rule.ID)
+		return nil, nil
+	})
+
+	// dc-3 is not found on the nodes
+	err := tmm.syncTopologyProfile(tc)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("dc-3"))
+	g.Expect(set).To(BeEmpty())
+
+	nodeIndexer.Add(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-dc-3", Labels: map[string]string{corev1.LabelFailureDomainBetaZone: "dc-3"}},
+	})
+	g.Expect(tmm.syncTopologyProfile(tc)).To(Succeed())
+	g.Expect(replication).NotTo(BeNil())
+	g.Expect([]string(replication.LocationLabels)).To(Equal([]string{"zone", "host"}))
+	g.Expect(set).To(Equal([]string{"zone-0-leader", "zone-0-voter", "zone-1-voter", "zone-2-follower"}))
+	// the rule of the former scheme is deleted
+	g.Expect(deleted).To(Equal([]string{topologyRuleGroupID + "/zone-0-learner"}))
+
+	// the rules are deleted once after the profile is removed
+	deleted = nil
+	tc.Spec.TopologyProfile = nil
+	g.Expect(tmm.syncTopologyProfile(tc)).To(Succeed())
+	g.Expect(tmm.syncTopologyProfile(tc)).To(Succeed())
+	g.Expect(deleted).To(Equal([]string{topologyRuleGroupID + "/zone-0-learner"}))
+}
+
+func TestThreeDCPlacementRules(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	count := func(rules []*pdapi.PlacementRule, zone string) map[string]int {
+		roles := map[string]int{}
+		for _, rule := range rules {
+			if rule.LabelConstraints[0].Values[0] == zone {
+				roles[rule.Role] += rule.Count
+			}
+		}
+		return roles
+	}
+
+	profile := newThreeDCTopologyProfile()
+	rules := threeDCPlacementRules(profile, topologyLocationLabels)
+	g.Expect(count(rules, "dc-1")).To(Equal(map[string]int{"leader": 1, "voter": 1}))
+	g.Expect(count(rules, "dc-2")).To(Equal(map[string]int{"voter": 2}))
+	g.Expect(count(rules, "dc-3")).To(Equal(map[string]int{"follower": 1}))
+
+	profile.Scheme = v1alpha1.ThreeDCScheme32
+	rules = threeDCPlacementRules(profile, topologyLocationLabels)
+	g.Expect(count(rules, "dc-1")).To(Equal(map[string]int{"leader": 1, "learner": 1}))
+	g.Expect(count(rules, "dc-2")).To(Equal(map[string]int{"voter": 1, "learner": 1}))
+	g.Expect(count(rules, "dc-3")).To(Equal(map[string]int{"follower": 1}))
+}
+
+func TestSyncTiDBServerZoneLabels(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tmm, _, _, indexers := newFakeTiDBMemberManager()
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TopologyProfile = newThreeDCTopologyProfile()
+
+	podLabels := label.New().Instance(tc.GetInstanceName()).TiDB().Labels()
+	ready := corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-tidb-0", Namespace: tc.Namespace}, Status: ready},
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-tidb-1", Namespace: tc.Namespace}, Status: ready},
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-tidb-2", Namespace: tc.Namespace}},
+	}
+	zones := map[string]string{"test-tidb-0": "dc-1", "test-tidb-1": "dc-2", "test-tidb-2": "dc-3"}
+	setZones := map[string]string{}
+	for _, pod := range pods {
+		pod := pod
+		pod.Labels = label.Label(podLabels).Copy()
+		pod.Labels[label.ZoneLabelKey] = zones[pod.Name]
+		indexers.pod.Add(pod)
+
+		client := tidbapi.NewFakeTiDBClient()
+		client.AddReaction(tidbapi.SetLabelsActionType, func(action *tidbapi.Action) (interface{}, error) {
+			setZones[pod.Name] = action.Labels["zone"]
+			return nil, nil
+		})
+		tmm.deps.TiDBControl.(*controller.FakeTiDBControl).SetTiDBPodClient(pod.Name, client)
+	}
+
+	tmm.syncTiDBServerZoneLabels(tc)
+	// the labels of the unready TiDB are not set
+	g.Expect(setZones).To(Equal(map[string]string{"test-tidb-0": "dc-1", "test-tidb-1": "dc-2"}))
+
+	// the labels are not set again until TiDB restarts
+	setZones = map[string]string{}
+	tmm.syncTiDBServerZoneLabels(tc)
+	g.Expect(setZones).To(BeEmpty())
+
+	restarted := pods[0].DeepCopy()
+	restarted.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "tidb", RestartCount: 1}}
+	indexers.pod.Update(restarted)
+	tmm.syncTiDBServerZoneLabels(tc)
+	g.Expect(setZones).To(Equal(map[string]string{"test-tidb-0": "dc-1"}))
+}
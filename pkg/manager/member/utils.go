@@ -27,10 +27,11 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/apis/util/toml"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -75,6 +76,55 @@ func annotationsMountVolume() (corev1.VolumeMount, corev1.Volume) {
 	return m, v
 }
 
+const (
+	startScriptHooksVolumeName = "startup-script-hooks"
+	startScriptHooksMountPath  = "/etc/startup-script-hooks"
+	preStartHookPath           = "pre_start.sh"
+	postDiscoveryHookPath      = "post_discovery.sh"
+)
+
+// startScriptHooksMountVolume returns the volume mount and the volume of the ConfigMap holding
+// the hooks of the startup script, or nil if no hook is set so that the Pods are not changed.
+func startScriptHooksMountVolume(hooks *v1alpha1.StartScriptHooks) (*corev1.VolumeMount, *corev1.Volume) {
+	if hooks == nil || (hooks.PreStart == "" && hooks.PostDiscovery == "") {
+		return nil, nil
+	}
+	var items []corev1.KeyToPath
+	if hooks.PreStart != "" {
+		items = append(items, corev1.KeyToPath{Key: hooks.PreStart, Path: preStartHookPath})
+	}
+	if hooks.PostDiscovery != "" {
+		items = append(items, corev1.KeyToPath{Key: hooks.PostDiscovery, Path: postDiscoveryHookPath})
+	}
+	m := &corev1.VolumeMount{Name: startScriptHooksVolumeName, ReadOnly: true, MountPath: startScriptHooksMountPath}
+	v := &corev1.Volume{
+		Name: startScriptHooksVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: hooks.ConfigMapName,
+				},
+				Items: items,
+			},
+		},
+	}
+	return m, v
+}
+
+// setStartScriptHooks sets the paths of the hooks mounted by startScriptHooksMountVolume
+// in the model of the startup script
+func setStartScriptHooks(model *CommonModel, hooks *v1alpha1.StartScriptHooks) {
+	if hooks == nil {
+		return
+	}
+	if hooks.PreStart != "" {
+		model.PreStartHook = path.Join(startScriptHooksMountPath, preStartHookPath)
+	}
+	if hooks.PostDiscovery != "" {
+		model.PostDiscoveryHook = path.Join(startScriptHooksMountPath, postDiscoveryHookPath)
+	}
+}
+
 // GetLastAppliedConfig get last applied config info from Statefulset's annotation and the podTemplate's annotation
 func GetLastAppliedConfig(set *apps.StatefulSet) (*apps.StatefulSetSpec, *corev1.PodSpec, error) {
 	specAppliedConfig, ok := set.Annotations[LastAppliedConfigAnnotation]
@@ -100,11 +150,27 @@ func templateEqual(new *apps.StatefulSet, old *apps.StatefulSet) bool {
 			klog.Errorf("unmarshal PodTemplate: [%s/%s]'s applied config failed,error: %v", old.GetNamespace(), old.GetName(), err)
 			return false
 		}
-		return apiequality.Semantic.DeepEqual(oldStsSpec.Template.Spec, new.Spec.Template.Spec)
+		// the pod spec only reordered is kept by UpdateStatefulSetWithPrecheck
+		return mngerutils.PodSpecEquivalent(&oldStsSpec.Template.Spec, &new.Spec.Template.Spec)
 	}
 	return false
 }
 
+// keepTemplateUntilScaledToZero keeps the last applied pod template in the new statefulset while
+// the old one still has replicas, it's used when a stateless component is scaled to zero so that
+// the Pods being removed are not rolled, and the new template is applied once all of them are removed
+func keepTemplateUntilScaledToZero(oldSet, newSet *apps.StatefulSet) error {
+	if oldSet.Spec.Replicas == nil || *oldSet.Spec.Replicas == 0 {
+		return nil
+	}
+	_, podSpec, err := GetLastAppliedConfig(oldSet)
+	if err != nil {
+		return err
+	}
+	newSet.Spec.Template.Spec = *podSpec
+	return nil
+}
+
 func MemberPodName(controllerName, controllerKind string, ordinal int32, memberType v1alpha1.MemberType) (string, error) {
 	switch controllerKind {
 	case v1alpha1.TiDBClusterKind:
@@ -233,6 +299,10 @@ func getTikVConfigMapForTiKVSpec(tikvSpec *v1alpha1.TiKVSpec, tc *v1alpha1.TidbC
 		config.Set("security.cert-path", path.Join(tikvClusterCertPath, corev1.TLSCertKey))
 		config.Set("security.key-path", path.Join(tikvClusterCertPath, corev1.TLSPrivateKeyKey))
 	}
+	if tikvSpec.StoreRole != "" {
+		config.Set("server.labels."+v1alpha1.TiKVStoreRoleLabelKey, storeRoleLabelValue(tikvSpec.StoreRole))
+	}
+	deriveTiKVConfig(tc, config.GenericConfig, tikvSpec.ResourceRequirements)
 	confText, err := config.MarshalTOML()
 	if err != nil {
 		return nil, err
@@ -536,3 +606,98 @@ func MergePatchContainers(base, patches []corev1.Container) ([]corev1.Container,
 
 	return out, nil
 }
+
+// setHelperInitializer customizes the init container running with the helper image by
+// `spec.helper.initializer` and the initializers in args in order, the latter takes
+// higher priority. The fields not set in any initializer are kept, so that the init
+// container is not changed if no initializer is set.
+func setHelperInitializer(tc *v1alpha1.TidbCluster, c *corev1.Container, initializers ...*v1alpha1.InitContainerSpec) {
+	initializers = append([]*v1alpha1.InitContainerSpec{tc.GetHelperSpec().Initializer}, initializers...)
+	var extraCommands []string
+	for _, initializer := range initializers {
+		if initializer == nil {
+			continue
+		}
+		if initializer.Image != nil {
+			c.Image = *initializer.Image
+		}
+		if initializer.ImagePullPolicy != nil {
+			c.ImagePullPolicy = *initializer.ImagePullPolicy
+		}
+		if len(initializer.Requests) > 0 || len(initializer.Limits) > 0 {
+			c.Resources = controller.ContainerResource(initializer.ResourceRequirements)
+		}
+		if len(initializer.ExtraCommands) > 0 {
+			extraCommands = initializer.ExtraCommands
+		}
+	}
+	// the command of the init containers is always `sh -c <script>`
+	if len(extraCommands) > 0 && len(c.Command) == 3 {
+		c.Command[2] = c.Command[2] + "\n" + strings.Join(extraCommands, "\n")
+	}
+}
+
+// useNativeSidecars renders the named sidecar containers of the new StatefulSet as native sidecars, i.e.
+// init containers whose restartPolicy is Always, which are started before and stopped after the main
+// container, so the shutdown of the main container is not blocked and its last log lines are not lost.
+// A new StatefulSet uses native sidecars if the api-server supports them, while an existing StatefulSet
+// keeps its layout to avoid rolling its pods when the operator or Kubernetes is upgraded.
+//
+// The additional containers, e.g. the helpers of a service mesh, are rendered as native sidecars if their
+// names are listed in the annotation tidb.pingcap.com/native-sidecars of the component, which rolls the
+// pods of an existing StatefulSet as requested.
+//
+// Native sidecars are never used with Advanced StatefulSets, whose pods are created from the typed pod
+// template which drops the restartPolicy of init containers, so the sidecars would block the main container.
+func useNativeSidecars(deps *controller.Dependencies, newSet, oldSet *apps.StatefulSet, names ...string) {
+	requested := sets.NewString()
+	if v := newSet.Spec.Template.Annotations[label.AnnNativeSidecars]; v != "" {
+		requested.Insert(strings.Split(v, ",")...)
+	}
+	delete(newSet.Spec.Template.Annotations, label.AnnNativeSidecars)
+	if features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
+		if requested.Len() > 0 {
+			klog.Warningf("native sidecars %v of StatefulSet %s/%s are ignored, they are not supported by Advanced StatefulSet",
+				requested.List(), newSet.Namespace, newSet.Name)
+		}
+		return
+	}
+
+	sidecars := sets.NewString()
+	if oldSet == nil {
+		if !deps.NativeSidecarSupported {
+			return
+		}
+		sidecars.Insert(names...)
+		sidecars = sidecars.Union(requested)
+	} else {
+		old := sets.NewString()
+		if v := oldSet.Spec.Template.Annotations[label.AnnNativeSidecars]; v != "" {
+			old.Insert(strings.Split(v, ",")...)
+		}
+		if !deps.NativeSidecarSupported && old.Len() == 0 {
+			return
+		}
+		// the log tailers rendered as native sidecars before are kept, while the requested ones follow the annotation
+		sidecars = old.Intersection(sets.NewString(names...)).Union(requested)
+	}
+
+	podSpec := &newSet.Spec.Template.Spec
+	var containers []corev1.Container
+	var moved []string
+	for _, c := range podSpec.Containers {
+		if sidecars.Has(c.Name) {
+			podSpec.InitContainers = append(podSpec.InitContainers, c)
+			moved = append(moved, c.Name)
+			continue
+		}
+		containers = append(containers, c)
+	}
+	if len(moved) == 0 {
+		return
+	}
+	podSpec.Containers = containers
+	newSet.Spec.Template.Annotations = util.CombineStringMap(map[string]string{
+		label.AnnNativeSidecars: strings.Join(moved, ","),
+	}, newSet.Spec.Template.Annotations)
+}
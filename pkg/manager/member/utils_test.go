@@ -24,12 +24,17 @@ import (
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 )
 
 func TestGetStsAnnotations(t *testing.T) {
@@ -978,3 +983,121 @@ func TestMergePatchContainersOrderPreserved(t *testing.T) {
 		}
 	}
 }
+
+func TestSetHelperInitializer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newContainer := func() corev1.Container {
+		return corev1.Container{
+			Name:      "init",
+			Image:     "busybox:1.26.2",
+			Command:   []string{"sh", "-c", "sysctl -w net.core.somaxconn=32768"},
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}},
+		}
+	}
+
+	// nothing is changed without initializers
+	tc := &v1alpha1.TidbCluster{}
+	c := newContainer()
+	setHelperInitializer(tc, &c, nil)
+	g.Expect(c).To(Equal(newContainer()))
+
+	pullPolicy := corev1.PullIfNotPresent
+	tc.Spec.Helper = &v1alpha1.HelperSpec{
+		Initializer: &v1alpha1.InitContainerSpec{
+			Image:         pointer.StringPtr("registry.local/busybox:1.34.1"),
+			ExtraCommands: []string{"echo helper"},
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			},
+		},
+	}
+	c = newContainer()
+	setHelperInitializer(tc, &c)
+	g.Expect(c.Image).To(Equal("registry.local/busybox:1.34.1"))
+	g.Expect(c.ImagePullPolicy).To(BeEmpty())
+	g.Expect(c.Command[2]).To(Equal("sysctl -w net.core.somaxconn=32768\necho helper"))
+	g.Expect(c.Resources.Requests.Cpu().String()).To(Equal("100m"))
+
+	// the initializer of the component takes higher priority
+	c = newContainer()
+	setHelperInitializer(tc, &c, &v1alpha1.InitContainerSpec{
+		ImagePullPolicy: &pullPolicy,
+		ExtraCommands:   []string{"echo tiflash"},
+	})
+	g.Expect(c.Image).To(Equal("registry.local/busybox:1.34.1"))
+	g.Expect(c.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+	g.Expect(c.Command[2]).To(Equal("sysctl -w net.core.somaxconn=32768\necho tiflash"))
+	g.Expect(c.Resources.Requests.Cpu().String()).To(Equal("100m"))
+}
+
+func TestUseNativeSidecars(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newSet := func() *apps.StatefulSet {
+		return &apps.StatefulSet{
+			Spec: apps.StatefulSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						InitContainers: []corev1.Container{{Name: "init"}},
+						Containers:     []corev1.Container{{Name: "tidb"}, {Name: "slowlog"}},
+					},
+				},
+			},
+		}
+	}
+	deps := controller.NewFakeDependencies()
+
+	// not supported by the api-server
+	set := newSet()
+	useNativeSidecars(deps, set, nil, "slowlog")
+	g.Expect(set.Spec.Template.Spec.Containers).To(HaveLen(2))
+	g.Expect(controller.HasNativeSidecars(set)).To(BeFalse())
+
+	// a new StatefulSet
+	deps.NativeSidecarSupported = true
+	set = newSet()
+	useNativeSidecars(deps, set, nil, "slowlog")
+	g.Expect(set.Spec.Template.Spec.Containers).To(Equal([]corev1.Container{{Name: "tidb"}}))
+	g.Expect(set.Spec.Template.Spec.InitContainers).To(Equal([]corev1.Container{{Name: "init"}, {Name: "slowlog"}}))
+	g.Expect(set.Spec.Template.Annotations).To(HaveKeyWithValue(label.AnnNativeSidecars, "slowlog"))
+
+	// an existing StatefulSet keeps its layout
+	oldSet := newSet()
+	set = newSet()
+	useNativeSidecars(deps, set, oldSet, "slowlog")
+	g.Expect(set.Spec.Template.Spec.Containers).To(HaveLen(2))
+	g.Expect(controller.HasNativeSidecars(set)).To(BeFalse())
+
+	deps.NativeSidecarSupported = false
+	oldSet.Spec.Template.Annotations = map[string]string{label.AnnNativeSidecars: "slowlog"}
+	set = newSet()
+	useNativeSidecars(deps, set, oldSet, "slowlog")
+	g.Expect(set.Spec.Template.Spec.Containers).To(Equal([]corev1.Container{{Name: "tidb"}}))
+	g.Expect(controller.HasNativeSidecars(set)).To(BeTrue())
+
+	// the additional containers requested by the annotation of the component, e.g. a mesh helper
+	deps.NativeSidecarSupported = true
+	oldSet = newSet()
+	set = newSet()
+	set.Spec.Template.Spec.Containers = append(set.Spec.Template.Spec.Containers, corev1.Container{Name: "mesh"})
+	set.Spec.Template.Annotations = map[string]string{label.AnnNativeSidecars: "mesh"}
+	useNativeSidecars(deps, set, oldSet, "slowlog")
+	g.Expect(set.Spec.Template.Spec.Containers).To(Equal([]corev1.Container{{Name: "tidb"}, {Name: "slowlog"}}))
+	g.Expect(set.Spec.Template.Spec.InitContainers).To(Equal([]corev1.Container{{Name: "init"}, {Name: "mesh"}}))
+	g.Expect(set.Spec.Template.Annotations).To(HaveKeyWithValue(label.AnnNativeSidecars, "mesh"))
+
+	// never used with Advanced StatefulSet
+	saved := features.DefaultFeatureGate.String()
+	features.DefaultFeatureGate.Set("AdvancedStatefulSet=true")
+	defer features.DefaultFeatureGate.Set(saved)
+	oldSet.Spec.Template.Annotations = map[string]string{label.AnnNativeSidecars: "slowlog"}
+	for _, old := range []*apps.StatefulSet{nil, oldSet} {
+		set = newSet()
+		set.Spec.Template.Spec.Containers = append(set.Spec.Template.Spec.Containers, corev1.Container{Name: "mesh"})
+		set.Spec.Template.Annotations = map[string]string{label.AnnNativeSidecars: "mesh"}
+		useNativeSidecars(deps, set, old, "slowlog")
+		g.Expect(set.Spec.Template.Spec.Containers).To(HaveLen(3))
+		g.Expect(controller.HasNativeSidecars(set)).To(BeFalse())
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+type nowFn func() time.Time
+
+// failedError is returned when an operation can't go on, the operation is finished as failed
+type failedError struct {
+	message string
+}
+
+func (e *failedError) Error() string {
+	return e.message
+}
+
+func failedf(format string, a ...interface{}) error {
+	return &failedError{fmt.Sprintf(format, a...)}
+}
+
+type operationManager struct {
+	deps *controller.Dependencies
+	now  nowFn
+}
+
+// NewOperationManager returns a manager which runs the operations of TidbClusterOperation
+func NewOperationManager(deps *controller.Dependencies) manager.TiDBClusterOperationManager {
+	return &operationManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (m *operationManager) Sync(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster) error {
+	switch tco.Status.Phase {
+	case v1alpha1.TidbClusterOperationSucceeded, v1alpha1.TidbClusterOperationFailed:
+		// an operation is only run once
+		return nil
+	case v1alpha1.TidbClusterOperationRunning:
+	default:
+		tco.Status.Phase = v1alpha1.TidbClusterOperationRunning
+		tco.Status.StartTime = &metav1.Time{Time: m.now()}
+		m.record(tco, tc, fmt.Sprintf("%s operation started", tco.Spec.Type))
+	}
+
+	var err error
+	switch tco.Spec.Type {
+	case v1alpha1.TidbClusterOperationEvictLeader:
+		err = m.syncEvictLeader(tco, tc)
+	case v1alpha1.TidbClusterOperationReplaceDisk:
+		err = m.syncReplaceDisk(tco, tc)
+	case v1alpha1.TidbClusterOperationRecoverFailover:
+		err = m.syncRecoverFailover(tco, tc)
+	case v1alpha1.TidbClusterOperationForceUpgrade:
+		err = m.syncForceUpgrade(tco, tc)
+	default:
+		err = failedf("unknown operation type %q", tco.Spec.Type)
+	}
+
+	var failed *failedError
+	if errors.As(err, &failed) {
+		m.finish(tco, tc, v1alpha1.TidbClusterOperationFailed, failed.message)
+		return nil
+	}
+	return err
+}
+
+// record appends a step to the audit trail of the operation, and emits an event to
+// the TidbCluster so that the step can be found by `kubectl describe tc` as well
+func (m *operationManager) record(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster, message string) {
+	tco.Status.Message = message
+	tco.Status.Records = append(tco.Status.Records, v1alpha1.TidbClusterOperationRecord{
+		Time:    metav1.Time{Time: m.now()},
+		Phase:   tco.Status.Phase,
+		Message: message,
+	})
+
+	eventType := corev1.EventTypeNormal
+	if tco.Status.Phase == v1alpha1.TidbClusterOperationFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	m.deps.Recorder.Eventf(tc, eventType, string(tco.Spec.Type), "TidbClusterOperation %s: %s", tco.Name, message)
+	klog.Infof("TidbClusterOperation %s/%s %s: %s", tco.Namespace, tco.Name, tco.Status.Phase, message)
+}
+
+func (m *operationManager) finish(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster, phase v1alpha1.TidbClusterOperationPhase, message string) {
+	tco.Status.Phase = phase
+	tco.Status.CompletionTime = &metav1.Time{Time: m.now()}
+	m.record(tco, tc, message)
+}
+
+// getTiKVPod returns the target Pod of the operation, which must be a TiKV Pod of the cluster
+func (m *operationManager) getTiKVPod(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster) (*corev1.Pod, error) {
+	pod, err := m.deps.PodLister.Pods(tc.Namespace).Get(tco.Spec.PodName)
+	if apierrors.IsNotFound(err) {
+		if tco.Status.PodUID == "" {
+			return nil, failedf("Pod %s is not found", tco.Spec.PodName)
+		}
+		return nil, controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for Pod %s to be recreated", tco.Namespace, tco.Name, tco.Spec.PodName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pod.Labels[label.InstanceLabelKey] != tc.Name || pod.Labels[label.ComponentLabelKey] != label.TiKVLabelVal {
+		return nil, failedf("Pod %s is not a TiKV Pod of TidbCluster %s", pod.Name, tc.Name)
+	}
+	return pod, nil
+}
+
+// syncEvictLeader annotates the Pod to evict the region leaders before it's deleted,
+// and waits for the recreated Pod to be ready and the evict-leader scheduler to be removed
+func (m *operationManager) syncEvictLeader(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster) error {
+	pod, err := m.getTiKVPod(tco, tc)
+	if err != nil {
+		return err
+	}
+	if tco.Status.PodUID == "" {
+		tco.Status.PodUID = pod.UID
+	}
+
+	if pod.UID == tco.Status.PodUID {
+		if pod.Annotations[v1alpha1.EvictLeaderAnnKey] != v1alpha1.EvictLeaderValueDeletePod {
+			pod = pod.DeepCopy()
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[v1alpha1.EvictLeaderAnnKey] = v1alpha1.EvictLeaderValueDeletePod
+			if _, err := m.deps.PodControl.UpdatePod(tc, pod); err != nil {
+				return err
+			}
+			m.record(tco, tc, fmt.Sprintf("evicting the region leaders of Pod %s before restarting it", pod.Name))
+		}
+		return controller.RequeueErrorf("TidbClusterOperation %s/%s is evicting the region leaders of Pod %s", tco.Namespace, tco.Name, pod.Name)
+	}
+
+	if !podutil.IsPodReady(pod) || tc.Status.TiKV.EvictLeader[pod.Name] != nil {
+		return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for Pod %s to be ready", tco.Namespace, tco.Name, pod.Name)
+	}
+	m.finish(tco, tc, v1alpha1.TidbClusterOperationSucceeded, fmt.Sprintf("Pod %s is restarted", pod.Name))
+	return nil
+}
+
+// syncReplaceDisk deletes the store of the Pod to move its regions out, then deletes
+// the PVCs and the Pod once the store is tombstone, and waits for the recreated Pod
+// to join the cluster as a new store
+func (m *operationManager) syncReplaceDisk(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster) error {
+	// the Pod is not recreated if it's scaled in by the replicas or the delete slots
+	ordinal, err := util.GetOrdinalFromPodName(tco.Spec.PodName)
+	if err != nil {
+		return failedf("invalid Pod name %s", tco.Spec.PodName)
+	}
+	ordinals, err := util.GetPodOrdinals(tc, v1alpha1.TiKVMemberType)
+	if err != nil {
+		return err
+	}
+	if !ordinals.Has(ordinal) {
+		return failedf("Pod %s is scaled in by the replicas or the delete slots of TiKV", tco.Spec.PodName)
+	}
+
+	if tco.Status.StoreID == "" {
+		return m.deleteStore(tco, tc)
+	}
+
+	if store, ok := tc.Status.TiKV.Stores[tco.Status.StoreID]; ok && store.State != v1alpha1.TiKVStateTombstone {
+		storeID, err := strconv.ParseUint(store.ID, 10, 64)
+		if err != nil {
+			return err
+		}
+		info, err := controller.GetPDClient(m.deps.PDControl, tc).GetStore(storeID)
+		if err != nil {
+			return err
+		}
+		if info.Status != nil {
+			tco.Status.Progress = fmt.Sprintf("%d regions left", info.Status.RegionCount)
+		}
+		return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for store %s to be tombstone, state: %s", tco.Namespace, tco.Name, store.ID, store.State)
+	}
+
+	pod, err := m.getTiKVPod(tco, tc)
+	if err != nil {
+		return err
+	}
+	if pod.UID == tco.Status.PodUID {
+		return m.deletePodAndPVCs(tco, tc, pod)
+	}
+
+	moveOutOfNode := tco.Spec.ReplaceDisk != nil && tco.Spec.ReplaceDisk.MoveOutOfNode
+	if moveOutOfNode && pod.Spec.NodeName != "" && pod.Spec.NodeName == tco.Status.NodeName {
+		return failedf("Pod %s is recreated on node %s again", pod.Name, pod.Spec.NodeName)
+	}
+	store, ok := findStore(tc.Status.TiKV.Stores, pod.Name)
+	if !podutil.IsPodReady(pod) || !ok || store.ID == tco.Status.StoreID || store.State != v1alpha1.TiKVStateUp {
+		tco.Status.Progress = fmt.Sprintf("waiting for Pod %s to join the cluster", pod.Name)
+		return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for Pod %s to join the cluster as a new store", tco.Namespace, tco.Name, pod.Name)
+	}
+	tco.Status.Progress = ""
+	m.finish(tco, tc, v1alpha1.TidbClusterOperationSucceeded, fmt.Sprintf("Pod %s joined the cluster as store %s on node %s", pod.Name, store.ID, pod.Spec.NodeName))
+	return nil
+}
+
+// deleteStore starts the ReplaceDisk operation by deleting the store of the Pod
+func (m *operationManager) deleteStore(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster) error {
+	pod, err := m.getTiKVPod(tco, tc)
+	if err != nil {
+		return err
+	}
+	store, ok := findStore(tc.Status.TiKV.Stores, pod.Name)
+	if !ok {
+		return failedf("the store of Pod %s is not found", pod.Name)
+	}
+	// a Down store can be replaced as well, e.g. the Pod is pending because its node is unavailable
+	if store.State == v1alpha1.TiKVStateOffline || store.State == v1alpha1.TiKVStateTombstone {
+		return failedf("store %s of Pod %s is %s already", store.ID, pod.Name, store.State)
+	}
+	storeID, err := strconv.ParseUint(store.ID, 10, 64)
+	if err != nil {
+		return failedf("invalid store id %s of Pod %s", store.ID, pod.Name)
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	config, err := pdClient.GetConfig()
+	if err != nil {
+		return err
+	}
+	upStores := 0
+	for _, s := range tc.Status.TiKV.Stores {
+		if s.ID != store.ID && s.State == v1alpha1.TiKVStateUp {
+			upStores++
+		}
+	}
+	if config.Replication != nil && config.Replication.MaxReplicas != nil && uint64(upStores) < *config.Replication.MaxReplicas {
+		return failedf("the regions of store %s can't be moved out, %d other stores are up and max-replicas is %d", store.ID, upStores, *config.Replication.MaxReplicas)
+	}
+
+	if err := pdClient.DeleteStore(storeID); err != nil {
+		return err
+	}
+	tco.Status.PodUID = pod.UID
+	tco.Status.StoreID = store.ID
+	tco.Status.NodeName = pod.Spec.NodeName
+	m.record(tco, tc, fmt.Sprintf("deleting store %s of Pod %s to move its regions out", store.ID, pod.Name))
+	return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for store %s to be tombstone", tco.Namespace, tco.Name, store.ID)
+}
+
+// deletePodAndPVCs deletes the PVCs and the Pod of the tombstone store, if the Pod is
+// to be moved out of its node, they are deleted only after the node is cordoned
+func (m *operationManager) deletePodAndPVCs(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster, pod *corev1.Pod) error {
+	if opts := tco.Spec.ReplaceDisk; opts != nil && opts.MoveOutOfNode && tco.Status.NodeName != "" {
+		if m.deps.NodeLister == nil {
+			return failedf("the permission of reading nodes is required to move Pod %s out of node %s", pod.Name, tco.Status.NodeName)
+		}
+		node, err := m.deps.NodeLister.Get(tco.Status.NodeName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if node != nil && !node.Spec.Unschedulable {
+			tco.Status.Progress = fmt.Sprintf("waiting for node %s to be cordoned", node.Name)
+			return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for node %s to be cordoned", tco.Namespace, tco.Name, node.Name)
+		}
+	}
+
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := m.deps.PVCLister.PersistentVolumeClaims(pod.Namespace).Get(vol.PersistentVolumeClaim.ClaimName)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if pvc.DeletionTimestamp == nil {
+			if err := m.deps.PVCControl.DeletePVC(tc, pvc); err != nil {
+				return err
+			}
+		}
+	}
+	if err := m.deps.PodControl.DeletePod(tc, pod); err != nil {
+		return err
+	}
+	tco.Status.Progress = ""
+	m.record(tco, tc, fmt.Sprintf("deleted the PVCs and Pod %s of tombstone store %s", pod.Name, tco.Status.StoreID))
+	return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for Pod %s to be recreated", tco.Namespace, tco.Name, pod.Name)
+}
+
+func findStore(stores map[string]v1alpha1.TiKVStore, podName string) (v1alpha1.TiKVStore, bool) {
+	for _, store := range stores {
+		if store.PodName == podName {
+			return store, true
+		}
+	}
+	return v1alpha1.TiKVStore{}, false
+}
+
+// syncRecoverFailover sets `failover.recoverByUID` of the component to the current failover UID,
+// and waits for the failure stores to be cleared
+func (m *operationManager) syncRecoverFailover(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster) error {
+	var (
+		failoverUID   types.UID
+		failureStores map[string]v1alpha1.TiKVFailureStore
+		recoverByUID  types.UID
+	)
+	switch tco.Spec.Component {
+	case v1alpha1.TiKVMemberType:
+		if tc.Spec.TiKV == nil {
+			return failedf("TiKV is not deployed")
+		}
+		failoverUID, failureStores = tc.Status.TiKV.FailoverUID, tc.Status.TiKV.FailureStores
+		if tc.Spec.TiKV.Failover != nil {
+			recoverByUID = tc.Spec.TiKV.Failover.RecoverByUID
+		}
+	case v1alpha1.TiFlashMemberType:
+		if tc.Spec.TiFlash == nil {
+			return failedf("TiFlash is not deployed")
+		}
+		failoverUID, failureStores = tc.Status.TiFlash.FailoverUID, tc.Status.TiFlash.FailureStores
+		if tc.Spec.TiFlash.Failover != nil {
+			recoverByUID = tc.Spec.TiFlash.Failover.RecoverByUID
+		}
+	default:
+		return failedf("failover of component %s can't be recovered", tco.Spec.Component)
+	}
+
+	if len(failureStores) == 0 {
+		m.finish(tco, tc, v1alpha1.TidbClusterOperationSucceeded, fmt.Sprintf("no failover of %s to recover", tco.Spec.Component))
+		return nil
+	}
+	if failoverUID == "" || recoverByUID == failoverUID {
+		return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for the failover of %s to be recovered", tco.Namespace, tco.Name, tco.Spec.Component)
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			string(tco.Spec.Component): map[string]interface{}{
+				"failover": map[string]interface{}{
+					"recoverByUID": failoverUID,
+				},
+			},
+		},
+	}
+	if err := m.patchTidbCluster(tc, patch); err != nil {
+		return err
+	}
+	m.record(tco, tc, fmt.Sprintf("recovering the failover %s of %s", failoverUID, tco.Spec.Component))
+	return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for the failover of %s to be recovered", tco.Namespace, tco.Name, tco.Spec.Component)
+}
+
+// syncForceUpgrade sets the force upgrade annotation of the TidbCluster, and removes
+// it after all PD Pods are upgraded
+func (m *operationManager) syncForceUpgrade(tco *v1alpha1.TidbClusterOperation, tc *v1alpha1.TidbCluster) error {
+	if tc.Annotations[label.AnnForceUpgradeKey] != label.AnnForceUpgradeVal {
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					label.AnnForceUpgradeKey: label.AnnForceUpgradeVal,
+				},
+			},
+		}
+		if err := m.patchTidbCluster(tc, patch); err != nil {
+			return err
+		}
+		m.record(tco, tc, "upgrading PD forcedly")
+		return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for PD to be upgraded", tco.Namespace, tco.Name)
+	}
+
+	sts := tc.Status.PD.StatefulSet
+	if tc.Status.PD.Phase == v1alpha1.UpgradePhase || sts == nil || sts.CurrentRevision != sts.UpdateRevision {
+		return controller.RequeueErrorf("TidbClusterOperation %s/%s is waiting for PD to be upgraded", tco.Namespace, tco.Name)
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				label.AnnForceUpgradeKey: nil,
+			},
+		},
+	}
+	if err := m.patchTidbCluster(tc, patch); err != nil {
+		return err
+	}
+	m.finish(tco, tc, v1alpha1.TidbClusterOperationSucceeded, "PD is upgraded")
+	return nil
+}
+
+func (m *operationManager) patchTidbCluster(tc *v1alpha1.TidbCluster, patch map[string]interface{}) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = m.deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Patch(context.TODO(), tc.Name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("patch TidbCluster %s/%s failed, err: %v", tc.Namespace, tc.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestOperationManagerSyncEvictLeader(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeOperationManager()
+	tc := newTidbCluster()
+	tco := newTidbClusterOperation(v1alpha1.TidbClusterOperationEvictLeader)
+	tco.Spec.PodName = "test-tikv-0"
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pod := newTiKVPod("test-tikv-0", "uid-0")
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+
+	// the Pod is annotated to evict the leaders
+	err := m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationRunning))
+	g.Expect(tco.Status.PodUID).To(BeEquivalentTo("uid-0"))
+	g.Expect(tco.Status.Records).To(HaveLen(2))
+	pod, err = deps.PodLister.Pods(tc.Namespace).Get("test-tikv-0")
+	g.Expect(err).To(Succeed())
+	g.Expect(pod.Annotations[v1alpha1.EvictLeaderAnnKey]).To(Equal(v1alpha1.EvictLeaderValueDeletePod))
+
+	// the Pod is recreated but not ready
+	g.Expect(podIndexer.Delete(pod)).To(Succeed())
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	pod = newTiKVPod("test-tikv-0", "uid-1")
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tco.Status.Records).To(HaveLen(2))
+
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	g.Expect(podIndexer.Update(pod)).To(Succeed())
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationSucceeded))
+	g.Expect(tco.Status.CompletionTime).NotTo(BeNil())
+	g.Expect(tco.Status.Records).To(HaveLen(3))
+
+	// an operation is only run once
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Records).To(HaveLen(3))
+
+	// not a TiKV Pod
+	tco = newTidbClusterOperation(v1alpha1.TidbClusterOperationEvictLeader)
+	tco.Spec.PodName = "test-tidb-0"
+	tidbPod := newTiKVPod("test-tidb-0", "uid-2")
+	tidbPod.Labels[label.ComponentLabelKey] = label.TiDBLabelVal
+	g.Expect(podIndexer.Add(tidbPod)).To(Succeed())
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationFailed))
+	g.Expect(tco.Status.Message).To(ContainSubstring("not a TiKV Pod"))
+}
+
+func TestOperationManagerSyncReplaceDisk(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeOperationManager()
+	tc := newTidbCluster()
+	tco := newTidbClusterOperation(v1alpha1.TidbClusterOperationReplaceDisk)
+	tco.Spec.PodName = "test-tikv-0"
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pod := newTiKVPod("test-tikv-0", "uid-0")
+	pod.Spec.NodeName = "node-1"
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nodeIndexer := deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	g.Expect(nodeIndexer.Add(node)).To(Succeed())
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	maxReplicas := uint64(3)
+	pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.PDConfigFromAPI{
+			Replication: &pdapi.PDReplicationConfig{MaxReplicas: &maxReplicas},
+		}, nil
+	})
+	var deleted []uint64
+	pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		deleted = append(deleted, action.ID)
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.GetStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoreInfo{Status: &pdapi.StoreStatus{RegionCount: 100}}, nil
+	})
+
+	// the regions can't be moved out of the store
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationFailed))
+	g.Expect(deleted).To(BeEmpty())
+
+	tco = newTidbClusterOperation(v1alpha1.TidbClusterOperationReplaceDisk)
+	tco.Spec.PodName = "test-tikv-0"
+	tco.Spec.ReplaceDisk = &v1alpha1.ReplaceDiskOptions{MoveOutOfNode: true}
+	tc.Status.TiKV.Stores["4"] = v1alpha1.TiKVStore{ID: "4", PodName: "test-tikv-3", State: v1alpha1.TiKVStateUp}
+	err := m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deleted).To(Equal([]uint64{1}))
+	g.Expect(tco.Status.StoreID).To(Equal("1"))
+	g.Expect(tco.Status.NodeName).To(Equal("node-1"))
+
+	// the Pod is kept until the store is tombstone
+	store := tc.Status.TiKV.Stores["1"]
+	store.State = v1alpha1.TiKVStateOffline
+	tc.Status.TiKV.Stores["1"] = store
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tco.Status.Progress).To(Equal("100 regions left"))
+	_, err = deps.PodLister.Pods(tc.Namespace).Get("test-tikv-0")
+	g.Expect(err).To(Succeed())
+
+	// the Pod is kept until the node is cordoned
+	store.State = v1alpha1.TiKVStateTombstone
+	tc.Status.TiKV.Stores["1"] = store
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tco.Status.Progress).To(ContainSubstring("cordoned"))
+	_, err = deps.PodLister.Pods(tc.Namespace).Get("test-tikv-0")
+	g.Expect(err).To(Succeed())
+
+	node.Spec.Unschedulable = true
+	g.Expect(nodeIndexer.Update(node)).To(Succeed())
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	_, err = deps.PodLister.Pods(tc.Namespace).Get("test-tikv-0")
+	g.Expect(err).NotTo(Succeed())
+
+	// the recreated Pod joins the cluster as a new store on another node
+	pod = newTiKVPod("test-tikv-0", "uid-1")
+	pod.Spec.NodeName = "node-2"
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	delete(tc.Status.TiKV.Stores, "1")
+	tc.Status.TiKV.Stores["5"] = v1alpha1.TiKVStore{ID: "5", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp}
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationSucceeded))
+	g.Expect(tco.Status.Message).To(ContainSubstring("store 5"))
+
+	// the Pod scaled in by the delete slots is not replaced
+	tco = newTidbClusterOperation(v1alpha1.TidbClusterOperationReplaceDisk)
+	tco.Spec.PodName = "test-tikv-1"
+	tc.Annotations = map[string]string{label.AnnTiKVDeleteSlots: "[1]"}
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationFailed))
+	g.Expect(tco.Status.Message).To(ContainSubstring("delete slots"))
+}
+
+func TestOperationManagerSyncRecoverFailover(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeOperationManager()
+	tc := newTidbCluster()
+	tco := newTidbClusterOperation(v1alpha1.TidbClusterOperationRecoverFailover)
+	tco.Spec.Component = v1alpha1.TiKVMemberType
+
+	// nothing to recover
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationSucceeded))
+
+	tco = newTidbClusterOperation(v1alpha1.TidbClusterOperationRecoverFailover)
+	tco.Spec.Component = v1alpha1.TiKVMemberType
+	tc.Status.TiKV.FailoverUID = "failover-uid"
+	tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{
+		"3": {PodName: "test-tikv-2", StoreID: "3"},
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	g.Expect(err).To(Succeed())
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	tc, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Get(context.TODO(), tc.Name, metav1.GetOptions{})
+	g.Expect(err).To(Succeed())
+	g.Expect(tc.Spec.TiKV.Failover.RecoverByUID).To(BeEquivalentTo("failover-uid"))
+
+	// waiting for the failure stores to be cleared
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tco.Status.Records).To(HaveLen(2))
+	tc.Status.TiKV.FailureStores = nil
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationSucceeded))
+}
+
+func TestOperationManagerSyncForceUpgrade(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m, deps := newFakeOperationManager()
+	tc := newTidbCluster()
+	tc.Status.PD.Phase = v1alpha1.UpgradePhase
+	tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{CurrentRevision: "1", UpdateRevision: "2"}
+	tco := newTidbClusterOperation(v1alpha1.TidbClusterOperationForceUpgrade)
+	_, err := deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{})
+	g.Expect(err).To(Succeed())
+
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	tc, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Get(context.TODO(), tc.Name, metav1.GetOptions{})
+	g.Expect(err).To(Succeed())
+	g.Expect(tc.Annotations).To(HaveKeyWithValue(label.AnnForceUpgradeKey, label.AnnForceUpgradeVal))
+
+	err = m.Sync(tco, tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+
+	// the annotation is removed after PD is upgraded
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	tc.Status.PD.StatefulSet.CurrentRevision = "2"
+	g.Expect(m.Sync(tco, tc)).To(Succeed())
+	g.Expect(tco.Status.Phase).To(Equal(v1alpha1.TidbClusterOperationSucceeded))
+	tc, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Get(context.TODO(), tc.Name, metav1.GetOptions{})
+	g.Expect(err).To(Succeed())
+	g.Expect(tc.Annotations).NotTo(HaveKey(label.AnnForceUpgradeKey))
+}
+
+func newFakeOperationManager() (*operationManager, *controller.Dependencies) {
+	deps := controller.NewFakeDependencies()
+	return &operationManager{deps: deps, now: time.Now}, deps
+}
+
+func newTidbCluster() *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{
+				BaseImage: "pingcap/tikv",
+				Replicas:  3,
+			},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+					"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp},
+					"3": {ID: "3", PodName: "test-tikv-2", State: v1alpha1.TiKVStateUp},
+				},
+			},
+		},
+	}
+}
+
+func newTiKVPod(name, uid string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			UID:       types.UID(uid),
+			Labels:    label.New().Instance("test").TiKV().Labels(),
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name: "tikv",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "tikv-" + name},
+				},
+			}},
+		},
+	}
+}
+
+func newTidbClusterOperation(typ v1alpha1.TidbClusterOperationType) *v1alpha1.TidbClusterOperation {
+	return &v1alpha1.TidbClusterOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "operation",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: v1alpha1.TidbClusterOperationSpec{
+			Cluster: v1alpha1.TidbClusterRef{Name: "test"},
+			Type:    typ,
+		},
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PodTemplateChanged is the reason of the event emitted when the pod template of a StatefulSet is changed
+	PodTemplateChanged = "PodTemplateChanged"
+
+	// maxPodTemplateChangeEventFields is the max number of the changed fields listed in the event
+	maxPodTemplateChangeEventFields = 10
+)
+
+var (
+	quantityType    = reflect.TypeOf(resource.Quantity{})
+	intOrStringType = reflect.TypeOf(intstr.IntOrString{})
+	timeType        = reflect.TypeOf(metav1.Time{})
+)
+
+// PodSpecEquivalent returns whether the pod specs are the same regardless of the order of the
+// list items whose order is insignificant, e.g. volumes and volume mounts. The operator of a new
+// version may generate these items in another order, which must not cause rolling updates.
+// The order of env is significant because an env may refer to the former ones.
+func PodSpecEquivalent(a, b *corev1.PodSpec) bool {
+	if apiequality.Semantic.DeepEqual(a, b) {
+		return true
+	}
+	return apiequality.Semantic.DeepEqual(normalizePodSpec(a), normalizePodSpec(b))
+}
+
+// normalizePodSpec returns a copy of spec whose orderless lists are sorted
+func normalizePodSpec(spec *corev1.PodSpec) *corev1.PodSpec {
+	spec = spec.DeepCopy()
+	sort.SliceStable(spec.Volumes, func(i, j int) bool {
+		return spec.Volumes[i].Name < spec.Volumes[j].Name
+	})
+	sort.SliceStable(spec.ImagePullSecrets, func(i, j int) bool {
+		return spec.ImagePullSecrets[i].Name < spec.ImagePullSecrets[j].Name
+	})
+	sort.SliceStable(spec.Tolerations, func(i, j int) bool {
+		return spec.Tolerations[i].String() < spec.Tolerations[j].String()
+	})
+	sort.SliceStable(spec.HostAliases, func(i, j int) bool {
+		return spec.HostAliases[i].IP < spec.HostAliases[j].IP
+	})
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			c := &containers[i]
+			sort.SliceStable(c.VolumeMounts, func(i, j int) bool {
+				return c.VolumeMounts[i].MountPath < c.VolumeMounts[j].MountPath
+			})
+			sort.SliceStable(c.Ports, func(i, j int) bool {
+				return c.Ports[i].ContainerPort < c.Ports[j].ContainerPort
+			})
+			sort.SliceStable(c.EnvFrom, func(i, j int) bool {
+				return c.EnvFrom[i].String() < c.EnvFrom[j].String()
+			})
+		}
+	}
+	return spec
+}
+
+// keepEquivalentPodSpec keeps the last applied pod spec in newSet if the new one is equivalent
+// to it, so that the StatefulSet is not updated only because of the order of the items
+func keepEquivalentPodSpec(newSet, oldSet *apps.StatefulSet) {
+	oldTemplate, ok := lastAppliedTemplate(oldSet)
+	if !ok || apiequality.Semantic.DeepEqual(oldTemplate.Spec, newSet.Spec.Template.Spec) {
+		return
+	}
+	if PodSpecEquivalent(&oldTemplate.Spec, &newSet.Spec.Template.Spec) {
+		klog.V(4).Infof("pod spec of StatefulSet %s/%s is only reordered, keep the last applied one", newSet.GetNamespace(), newSet.GetName())
+		newSet.Spec.Template.Spec = oldTemplate.Spec
+	}
+}
+
+// explainPodTemplateChange records the fields of the pod template changed from oldSet to newSet in
+// status.podTemplateChanges of tc, and emits an event when the hash of the new template is not the
+// one recorded, so that the event is not repeated if the StatefulSet fails to update.
+func explainPodTemplateChange(tc *v1alpha1.TidbCluster, recorder record.EventRecorder, newSet, oldSet *apps.StatefulSet, now time.Time) {
+	oldTemplate, ok := lastAppliedTemplate(oldSet)
+	if !ok || apiequality.Semantic.DeepEqual(*oldTemplate, newSet.Spec.Template) {
+		return
+	}
+	hash, err := Sha256Sum(newSet.Spec.Template)
+	if err != nil {
+		klog.Errorf("failed to hash the pod template of StatefulSet %s/%s: %v", newSet.GetNamespace(), newSet.GetName(), err)
+		return
+	}
+	hash = hash[0:10]
+
+	index := -1
+	for i := range tc.Status.PodTemplateChanges {
+		if tc.Status.PodTemplateChanges[i].StatefulSet == newSet.GetName() {
+			index = i
+			break
+		}
+	}
+	if index >= 0 && tc.Status.PodTemplateChanges[index].TemplateHash == hash {
+		return
+	}
+
+	fields := PodTemplateDiffFields(oldTemplate, &newSet.Spec.Template)
+	change := v1alpha1.PodTemplateChange{
+		StatefulSet:  newSet.GetName(),
+		TemplateHash: hash,
+		ChangeTime:   metav1.NewTime(now),
+		Fields:       fields,
+	}
+	if index >= 0 {
+		tc.Status.PodTemplateChanges[index] = change
+	} else {
+		tc.Status.PodTemplateChanges = append(tc.Status.PodTemplateChanges, change)
+		sort.Slice(tc.Status.PodTemplateChanges, func(i, j int) bool {
+			return tc.Status.PodTemplateChanges[i].StatefulSet < tc.Status.PodTemplateChanges[j].StatefulSet
+		})
+	}
+
+	listed := fields
+	if len(listed) > maxPodTemplateChangeEventFields {
+		listed = append(listed[:maxPodTemplateChangeEventFields:maxPodTemplateChangeEventFields],
+			fmt.Sprintf("and %d more", len(fields)-maxPodTemplateChangeEventFields))
+	}
+	recorder.Eventf(tc, corev1.EventTypeNormal, PodTemplateChanged, "pod template of StatefulSet %s is changed, the pods will be rolled: %s",
+		newSet.GetName(), strings.Join(listed, ", "))
+}
+
+// PodTemplateDiffFields returns the paths of the leaf fields which differ between the pod templates,
+// e.g. spec.containers[tikv].resources.limits[memory]. The items of the lists are identified by their
+// names if they have, otherwise by their indexes.
+func PodTemplateDiffFields(actual, desired *corev1.PodTemplateSpec) []string {
+	var fields []string
+	diffFields("metadata.labels", reflect.ValueOf(actual.Labels), reflect.ValueOf(desired.Labels), &fields)
+	diffFields("metadata.annotations", reflect.ValueOf(actual.Annotations), reflect.ValueOf(desired.Annotations), &fields)
+	diffFields("spec", reflect.ValueOf(actual.Spec), reflect.ValueOf(desired.Spec), &fields)
+	return fields
+}
+
+func diffFields(path string, a, b reflect.Value, fields *[]string) {
+	if apiequality.Semantic.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			*fields = append(*fields, path)
+			return
+		}
+		diffFields(path, a.Elem(), b.Elem(), fields)
+	case reflect.Struct:
+		if a.Type() == quantityType || a.Type() == intOrStringType || a.Type() == timeType {
+			*fields = append(*fields, path)
+			return
+		}
+		for i := 0; i < a.NumField(); i++ {
+			field := a.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			tag := field.Tag.Get("json")
+			name := strings.Split(tag, ",")[0]
+			fieldPath := path
+			if !field.Anonymous && !strings.Contains(tag, ",inline") {
+				if name == "" {
+					name = field.Name
+				}
+				fieldPath = path + "." + name
+			}
+			diffFields(fieldPath, a.Field(i), b.Field(i), fields)
+		}
+	case reflect.Slice:
+		if keyOf := sliceItemKey(a.Type().Elem()); keyOf != nil && uniqueKeys(a, keyOf) && uniqueKeys(b, keyOf) {
+			diffNamedItems(path, a, b, keyOf, fields)
+			return
+		}
+		if a.Len() != b.Len() {
+			*fields = append(*fields, path)
+			return
+		}
+		for i := 0; i < a.Len(); i++ {
+			diffFields(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i), fields)
+		}
+	case reflect.Map:
+		if a.Type().Key().Kind() != reflect.String {
+			*fields = append(*fields, path)
+			return
+		}
+		keys := map[string]bool{}
+		for _, k := range a.MapKeys() {
+			keys[k.String()] = true
+		}
+		for _, k := range b.MapKeys() {
+			keys[k.String()] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			key := reflect.ValueOf(k).Convert(a.Type().Key())
+			av, bv := a.MapIndex(key), b.MapIndex(key)
+			itemPath := fmt.Sprintf("%s[%s]", path, k)
+			if !av.IsValid() || !bv.IsValid() {
+				*fields = append(*fields, itemPath)
+				continue
+			}
+			diffFields(itemPath, av, bv, fields)
+		}
+	default:
+		*fields = append(*fields, path)
+	}
+}
+
+// sliceItemKey returns the function to get the key identifying an item of the slice, which is the
+// name of the item, or the mount path of a volume mount. It returns nil if the items have no key.
+func sliceItemKey(typ reflect.Type) func(reflect.Value) string {
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, name := range []string{"MountPath", "Name"} {
+		if field, ok := typ.FieldByName(name); ok && field.Type.Kind() == reflect.String {
+			index := field.Index
+			return func(v reflect.Value) string {
+				return v.FieldByIndex(index).String()
+			}
+		}
+	}
+	return nil
+}
+
+// uniqueKeys returns whether the keys of the items are non-empty and unique, e.g. the names of
+// the container ports are optional
+func uniqueKeys(v reflect.Value, keyOf func(reflect.Value) string) bool {
+	keys := map[string]bool{}
+	for i := 0; i < v.Len(); i++ {
+		key := keyOf(v.Index(i))
+		if key == "" || keys[key] {
+			return false
+		}
+		keys[key] = true
+	}
+	return true
+}
+
+// diffNamedItems compares the items of the slices by their keys, the changes of the order are ignored
+func diffNamedItems(path string, a, b reflect.Value, keyOf func(reflect.Value) string, fields *[]string) {
+	actual := map[string]reflect.Value{}
+	for i := 0; i < a.Len(); i++ {
+		actual[keyOf(a.Index(i))] = a.Index(i)
+	}
+	seen := map[string]bool{}
+	var changed []string
+	for i := 0; i < b.Len(); i++ {
+		key := keyOf(b.Index(i))
+		seen[key] = true
+		itemPath := fmt.Sprintf("%s[%s]", path, key)
+		av, ok := actual[key]
+		if !ok {
+			changed = append(changed, itemPath)
+			continue
+		}
+		diffFields(itemPath, av, b.Index(i), &changed)
+	}
+	for key := range actual {
+		if !seen[key] {
+			changed = append(changed, fmt.Sprintf("%s[%s]", path, key))
+		}
+	}
+	if len(changed) == 0 {
+		// only the order of the items is changed
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+	*fields = append(*fields, changed...)
+}
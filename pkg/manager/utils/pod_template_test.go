@@ -0,0 +1,164 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func newPodTemplateForTest() corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"app.kubernetes.io/component": "tikv"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "tikv",
+					Image: "tikv:v1",
+					Env: []corev1.EnvVar{
+						{Name: "NAMESPACE", Value: "default"},
+						{Name: "CAPACITY", Value: "100GB"},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "tikv", MountPath: "/var/lib/tikv"},
+						{Name: "config", MountPath: "/etc/tikv"},
+					},
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "config"},
+				{Name: "startup-script"},
+			},
+		},
+	}
+}
+
+func TestPodSpecEquivalent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	a := newPodTemplateForTest().Spec
+	b := newPodTemplateForTest().Spec
+	g.Expect(PodSpecEquivalent(&a, &b)).To(BeTrue())
+
+	// the volumes and volume mounts are reordered
+	b.Volumes[0], b.Volumes[1] = b.Volumes[1], b.Volumes[0]
+	mounts := b.Containers[0].VolumeMounts
+	mounts[0], mounts[1] = mounts[1], mounts[0]
+	g.Expect(PodSpecEquivalent(&a, &b)).To(BeTrue())
+	g.Expect(a.Volumes[0].Name).To(Equal("config"), "the pod spec must not be changed")
+
+	// the order of env is significant
+	env := b.Containers[0].Env
+	env[0], env[1] = env[1], env[0]
+	g.Expect(PodSpecEquivalent(&a, &b)).To(BeFalse())
+}
+
+func TestPodTemplateDiffFields(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	actual := newPodTemplateForTest()
+	desired := newPodTemplateForTest()
+	g.Expect(PodTemplateDiffFields(&actual, &desired)).To(BeEmpty())
+
+	desired.Labels["new"] = "true"
+	desired.Spec.Containers[0].Image = "tikv:v2"
+	desired.Spec.Containers[0].Env[1].Value = "80GB"
+	desired.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory] = resource.MustParse("16Gi")
+	desired.Spec.Containers[0].VolumeMounts[1].ReadOnly = true
+	desired.Spec.Containers = append(desired.Spec.Containers, corev1.Container{Name: "log"})
+	desired.Spec.TerminationGracePeriodSeconds = pointer.Int64Ptr(60)
+	desired.Spec.Volumes = desired.Spec.Volumes[:1]
+	g.Expect(PodTemplateDiffFields(&actual, &desired)).To(Equal([]string{
+		"metadata.labels[new]",
+		"spec.volumes[startup-script]",
+		"spec.containers[log]",
+		"spec.containers[tikv].env[CAPACITY].value",
+		"spec.containers[tikv].image",
+		"spec.containers[tikv].resources.limits[memory]",
+		"spec.containers[tikv].volumeMounts[/etc/tikv].readOnly",
+		"spec.terminationGracePeriodSeconds",
+	}))
+
+	// only the order of env is changed
+	desired = newPodTemplateForTest()
+	env := desired.Spec.Containers[0].Env
+	env[0], env[1] = env[1], env[0]
+	g.Expect(PodTemplateDiffFields(&actual, &desired)).To(Equal([]string{"spec.containers[tikv].env"}))
+}
+
+func TestUpdateStatefulSetPodTemplate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+	}
+	recorder := record.NewFakeRecorder(10)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	oldSet := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tikv", Namespace: metav1.NamespaceDefault},
+		Spec: apps.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Template: newPodTemplateForTest(),
+		},
+	}
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+
+	// the reordered pod spec is kept
+	newSet := oldSet.DeepCopy()
+	volumes := newSet.Spec.Template.Spec.Volumes
+	volumes[0], volumes[1] = volumes[1], volumes[0]
+	keepEquivalentPodSpec(newSet, oldSet)
+	g.Expect(apiequality.Semantic.DeepEqual(newSet.Spec.Template, oldSet.Spec.Template)).To(BeTrue())
+	explainPodTemplateChange(tc, recorder, newSet, oldSet, now)
+	g.Expect(tc.Status.PodTemplateChanges).To(BeEmpty())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// the change is explained once
+	newSet.Spec.Template.Spec.Containers[0].Image = "tikv:v2"
+	keepEquivalentPodSpec(newSet, oldSet)
+	g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v2"))
+	explainPodTemplateChange(tc, recorder, newSet, oldSet, now)
+	explainPodTemplateChange(tc, recorder, newSet, oldSet, now.Add(time.Minute))
+	g.Expect(tc.Status.PodTemplateChanges).To(HaveLen(1))
+	change := tc.Status.PodTemplateChanges[0]
+	g.Expect(change.StatefulSet).To(Equal("test-tikv"))
+	g.Expect(change.TemplateHash).To(HaveLen(10))
+	g.Expect(change.ChangeTime.Time).To(Equal(now))
+	g.Expect(change.Fields).To(Equal([]string{"spec.containers[tikv].image"}))
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring("spec.containers[tikv].image"))
+
+	// another change of the same StatefulSet replaces the last one
+	newSet.Spec.Template.Spec.Containers[0].Image = "tikv:v3"
+	explainPodTemplateChange(tc, recorder, newSet, oldSet, now.Add(time.Hour))
+	g.Expect(tc.Status.PodTemplateChanges).To(HaveLen(1))
+	g.Expect(tc.Status.PodTemplateChanges[0].TemplateHash).NotTo(Equal(change.TemplateHash))
+	g.Expect(recorder.Events).To(HaveLen(1))
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+const (
+	rolloutBudgetWindow = time.Hour
+
+	// RolloutBudgetExhausted is the reason of RolloutStorm condition when the rollout budget is exhausted
+	RolloutBudgetExhausted = "RolloutBudgetExhausted"
+	// RolloutStormAcknowledged is the reason of RolloutStorm condition when the storm is acknowledged
+	RolloutStormAcknowledged = "RolloutStormAcknowledged"
+)
+
+// lastAppliedTemplate returns the pod template in the last applied config of the StatefulSet
+func lastAppliedTemplate(set *apps.StatefulSet) (*corev1.PodTemplateSpec, bool) {
+	lastAppliedConfig, ok := set.Annotations[LastAppliedConfigAnnotation]
+	if !ok {
+		return nil, false
+	}
+	spec := apps.StatefulSetSpec{}
+	if err := json.Unmarshal([]byte(lastAppliedConfig), &spec); err != nil {
+		klog.Errorf("unmarshal Statefulset: [%s/%s]'s applied config failed,error: %v", set.GetNamespace(), set.GetName(), err)
+		return nil, false
+	}
+	// the template may include LastAppliedConfigAnnotation to keep backward compatibility
+	delete(spec.Template.Annotations, LastAppliedConfigAnnotation)
+	return &spec.Template, true
+}
+
+// checkRolloutBudget checks whether the change of pod template from oldSet to newSet is allowed by
+// the rollout budget of tc. If it's not allowed, the template of newSet is reverted to the last
+// applied one, so that the other changes, e.g. scaling, are still applied.
+func checkRolloutBudget(tc *v1alpha1.TidbCluster, recorder record.EventRecorder, newSet, oldSet *apps.StatefulSet, now time.Time) {
+	if tc.Spec.RolloutBudget == nil {
+		return
+	}
+	acknowledgeRolloutStorm(tc, recorder, now)
+
+	oldTemplate, ok := lastAppliedTemplate(oldSet)
+	if !ok || apiequality.Semantic.DeepEqual(*oldTemplate, newSet.Spec.Template) {
+		return
+	}
+
+	status := tc.Status.RolloutBudget
+	if status == nil || now.Sub(status.WindowStart.Time) >= rolloutBudgetWindow {
+		status = &v1alpha1.RolloutBudgetStatus{WindowStart: metav1.NewTime(now)}
+		tc.Status.RolloutBudget = status
+	}
+
+	storm := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterRolloutStorm)
+	inStorm := storm != nil && storm.Status == corev1.ConditionTrue
+	if !inStorm && status.Restarts >= tc.Spec.RolloutBudget.MaxRestartsPerHour {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterRolloutStorm, corev1.ConditionTrue, RolloutBudgetExhausted, "")
+		cond.Message = fmt.Sprintf("%d pods are restarted by rolling updates since %s, the budget is %d per hour, set annotation %s=%s to resume",
+			status.Restarts, status.WindowStart.UTC().Format(time.RFC3339), tc.Spec.RolloutBudget.MaxRestartsPerHour,
+			label.AnnRolloutStormAck, rolloutStormID(cond))
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		recorder.Event(tc, corev1.EventTypeWarning, string(v1alpha1.TidbClusterRolloutStorm), cond.Message)
+		inStorm = true
+	}
+	if inStorm {
+		klog.Warningf("TidbCluster: [%s/%s] rollout budget is exhausted, skip the change of pod template of StatefulSet %s",
+			tc.GetNamespace(), tc.GetName(), newSet.GetName())
+		newSet.Spec.Template = *oldTemplate
+		return
+	}
+
+	if newSet.Spec.Replicas != nil {
+		status.Restarts += *newSet.Spec.Replicas
+	}
+}
+
+// rolloutStormID identifies a rollout storm, it's used to acknowledge the storm so that
+// a stale acknowledgement doesn't resume the changes of the next storm
+func rolloutStormID(storm *v1alpha1.TidbClusterCondition) string {
+	return storm.LastTransitionTime.UTC().Format(time.RFC3339)
+}
+
+// acknowledgeRolloutStorm resumes the changes of pod template if the RolloutStorm condition is
+// acknowledged by the annotation
+func acknowledgeRolloutStorm(tc *v1alpha1.TidbCluster, recorder record.EventRecorder, now time.Time) {
+	storm := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterRolloutStorm)
+	if storm == nil || storm.Status != corev1.ConditionTrue {
+		return
+	}
+	if ack, ok := tc.Annotations[label.AnnRolloutStormAck]; !ok || ack != rolloutStormID(storm) {
+		return
+	}
+
+	msg := "rollout storm is acknowledged, the rollout budget is reset"
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterRolloutStorm, corev1.ConditionFalse, RolloutStormAcknowledged, msg)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	tc.Status.RolloutBudget = &v1alpha1.RolloutBudgetStatus{WindowStart: metav1.NewTime(now)}
+	recorder.Event(tc, corev1.EventTypeNormal, RolloutStormAcknowledged, msg)
+}
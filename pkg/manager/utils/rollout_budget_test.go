@@ -0,0 +1,98 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestCheckRolloutBudget(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+		Spec: v1alpha1.TidbClusterSpec{
+			RolloutBudget: &v1alpha1.RolloutBudget{MaxRestartsPerHour: 5},
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newSets := func(image string) (*apps.StatefulSet, *apps.StatefulSet) {
+		oldSet := &apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-tikv", Namespace: metav1.NamespaceDefault},
+			Spec: apps.StatefulSetSpec{
+				Replicas: pointer.Int32Ptr(3),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "tikv", Image: "tikv:v1"}}},
+				},
+			},
+		}
+		g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+		newSet := oldSet.DeepCopy()
+		newSet.Spec.Replicas = pointer.Int32Ptr(4)
+		newSet.Spec.Template.Spec.Containers[0].Image = image
+		return newSet, oldSet
+	}
+
+	// the template is not changed
+	newSet, oldSet := newSets("tikv:v1")
+	checkRolloutBudget(tc, recorder, newSet, oldSet, now)
+	g.Expect(tc.Status.RolloutBudget).To(BeNil())
+
+	newSet, oldSet = newSets("tikv:v2")
+	checkRolloutBudget(tc, recorder, newSet, oldSet, now)
+	g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v2"))
+	g.Expect(tc.Status.RolloutBudget.Restarts).To(Equal(int32(4)))
+
+	newSet, oldSet = newSets("tikv:v3")
+	checkRolloutBudget(tc, recorder, newSet, oldSet, now.Add(10*time.Minute))
+	g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v3"))
+	g.Expect(tc.Status.RolloutBudget.Restarts).To(Equal(int32(8)))
+
+	// the budget is exhausted, the template is reverted but scaling is still applied
+	newSet, oldSet = newSets("tikv:v4")
+	checkRolloutBudget(tc, recorder, newSet, oldSet, now.Add(20*time.Minute))
+	g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v1"))
+	g.Expect(*newSet.Spec.Replicas).To(Equal(int32(4)))
+	storm := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterRolloutStorm)
+	g.Expect(storm).NotTo(BeNil())
+	g.Expect(storm.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(<-recorder.Events).To(ContainSubstring(string(v1alpha1.TidbClusterRolloutStorm)))
+
+	// the storm lasts until it's acknowledged, even if the window is passed
+	tc.Annotations = map[string]string{label.AnnRolloutStormAck: "stale"}
+	newSet, oldSet = newSets("tikv:v4")
+	checkRolloutBudget(tc, recorder, newSet, oldSet, now.Add(2*time.Hour))
+	g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v1"))
+
+	tc.Annotations[label.AnnRolloutStormAck] = rolloutStormID(storm)
+	newSet, oldSet = newSets("tikv:v4")
+	checkRolloutBudget(tc, recorder, newSet, oldSet, now.Add(2*time.Hour))
+	g.Expect(newSet.Spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v4"))
+	storm = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterRolloutStorm)
+	g.Expect(storm.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(tc.Status.RolloutBudget.Restarts).To(Equal(int32(4)))
+}
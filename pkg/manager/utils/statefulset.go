@@ -15,7 +15,9 @@ package utils
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
@@ -44,7 +46,9 @@ func StatefulSetIsUpgrading(set *apps.StatefulSet) bool {
 	return false
 }
 
-// SetStatefulSetLastAppliedConfigAnnotation set last applied config to Statefulset's annotation
+// SetStatefulSetLastAppliedConfigAnnotation set last applied config to Statefulset's annotation.
+// The whole config is recorded even if the StatefulSets are reconciled by server-side apply, because
+// the upgraders and the rollout budget read the last applied pod template from it.
 func SetStatefulSetLastAppliedConfigAnnotation(set *apps.StatefulSet) error {
 	setApply, err := util.Encode(set.Spec)
 	if err != nil {
@@ -54,6 +58,11 @@ func SetStatefulSetLastAppliedConfigAnnotation(set *apps.StatefulSet) error {
 		set.Annotations = map[string]string{}
 	}
 	set.Annotations[LastAppliedConfigAnnotation] = setApply
+	if controller.ServerSideApplyEnabled() {
+		// StatefulSetEqual compares the hash of the config
+		return util.SetLastAppliedConfig(set, util.StatefulSetAppliedConfig(set.Spec))
+	}
+	delete(set.Annotations, util.LastAppliedHashAnnotation)
 	return nil
 }
 
@@ -101,6 +110,18 @@ func UpdateStatefulSetWithPrecheck(
 		return fmt.Errorf("contains volumeMounts that do not have matched volume: %v", notExistMount)
 	}
 
+	// Keep the pod template if it's only reordered, e.g. by a new version of the operator,
+	// so that the pods are not rolled unexpectedly.
+	keepEquivalentPodSpec(newTiDBSet, oldTiDBSet)
+
+	// Pause the changes of pod template if the rollout budget is exhausted, so that flapping
+	// configs don't restart the pods again and again.
+	now := time.Now()
+	checkRolloutBudget(tc, deps.Recorder, newTiDBSet, oldTiDBSet, now)
+
+	// Explain which fields of the pod template are changed before rolling the pods.
+	explainPodTemplateChange(tc, deps.Recorder, newTiDBSet, oldTiDBSet, now)
+
 	return UpdateStatefulSet(deps.StatefulSetControl, tc, newTiDBSet, oldTiDBSet)
 }
 
@@ -163,3 +184,12 @@ func SetUpgradePartition(set *apps.StatefulSet, upgradeOrdinal int32) {
 	set.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{Partition: &upgradeOrdinal}
 	klog.Infof("set %s/%s partition to %d", set.GetNamespace(), set.GetName(), upgradeOrdinal)
 }
+
+// GetUpgradeOrdinals returns the ordinals of the pods to upgrade in ascending order.
+// The ordinals which are not desired by the new statefulset, i.e. in its delete slots or
+// out of its replicas, are skipped, because these pods are being deleted by scaling in and
+// waiting for them to be upgraded stalls the rolling upgrade.
+func GetUpgradeOrdinals(oldSet, newSet *apps.StatefulSet) []int32 {
+	newOrdinals := helper.GetPodOrdinals(*newSet.Spec.Replicas, newSet)
+	return helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet).Intersection(newOrdinals).List()
+}
@@ -14,12 +14,17 @@
 package utils
 
 import (
+	"encoding/json"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/util"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestStatefulSetIsUpgrading(t *testing.T) {
@@ -96,6 +101,62 @@ func TestStatefulSetIsUpgrading(t *testing.T) {
 	}
 }
 
+func TestGetUpgradeOrdinals(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newSet := func(replicas int32, deleteSlots string) *apps.StatefulSet {
+		set := &apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test",
+				Namespace:   metav1.NamespaceDefault,
+				Annotations: map[string]string{},
+			},
+			Spec: apps.StatefulSetSpec{
+				Replicas: pointer.Int32Ptr(replicas),
+			},
+		}
+		if deleteSlots != "" {
+			set.Annotations[helper.DeleteSlotsAnn] = deleteSlots
+		}
+		return set
+	}
+
+	tests := []struct {
+		name   string
+		oldSet *apps.StatefulSet
+		newSet *apps.StatefulSet
+		expect []int32
+	}{
+		{
+			name:   "no delete slots",
+			oldSet: newSet(3, ""),
+			newSet: newSet(3, ""),
+			expect: []int32{0, 1, 2},
+		},
+		{
+			name:   "same delete slots",
+			oldSet: newSet(3, "[1]"),
+			newSet: newSet(3, "[1]"),
+			expect: []int32{0, 2, 3},
+		},
+		{
+			name:   "scale in a delete slot",
+			oldSet: newSet(3, ""),
+			newSet: newSet(2, "[1]"),
+			expect: []int32{0, 2},
+		},
+		{
+			name:   "scale in by replicas",
+			oldSet: newSet(4, "[0]"),
+			newSet: newSet(3, "[0]"),
+			expect: []int32{1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		g.Expect(GetUpgradeOrdinals(tt.oldSet, tt.newSet)).To(Equal(tt.expect), tt.name)
+	}
+}
+
 func TestNotExistMount(t *testing.T) {
 	oldSTS := &apps.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -167,3 +228,36 @@ func TestNotExistMount(t *testing.T) {
 	mp = notExistMount(newSTS, oldSTS)
 	g.Expect(mp).ShouldNot(BeEmpty())
 }
+
+func TestSetStatefulSetLastAppliedConfigAnnotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	saved := features.DefaultFeatureGate.String()
+	defer features.DefaultFeatureGate.Set(saved) // reset features on exit
+
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tikv", Namespace: metav1.NamespaceDefault},
+		Spec: apps.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "tikv", Image: "tikv:v5.4.0"}}},
+			},
+		},
+	}
+
+	// the whole config is still recorded for the upgraders under server-side apply
+	g.Expect(features.DefaultFeatureGate.Set("ServerSideApply=true")).To(Succeed())
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(set)).To(Succeed())
+	g.Expect(set.Annotations).To(HaveKey(util.LastAppliedHashAnnotation))
+	g.Expect(set.Annotations).To(HaveKey(LastAppliedConfigAnnotation))
+	spec := apps.StatefulSetSpec{}
+	g.Expect(json.Unmarshal([]byte(set.Annotations[LastAppliedConfigAnnotation]), &spec)).To(Succeed())
+	g.Expect(spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v5.4.0"))
+
+	// the hash is removed once server-side apply is disabled, so it's not stale when enabled again
+	g.Expect(features.DefaultFeatureGate.Set("ServerSideApply=false")).To(Succeed())
+	set.Spec.Template.Spec.Containers[0].Image = "tikv:v6.1.0"
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(set)).To(Succeed())
+	g.Expect(set.Annotations).NotTo(HaveKey(util.LastAppliedHashAnnotation))
+	g.Expect(json.Unmarshal([]byte(set.Annotations[LastAppliedConfigAnnotation]), &spec)).To(Succeed())
+	g.Expect(spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v6.1.0"))
+}
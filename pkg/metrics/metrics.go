@@ -20,6 +20,10 @@ import (
 // RegisterMetrics registers all metrics of tidb-operator.
 func RegisterMetrics() {
 	prometheus.MustRegister(ClusterSpecReplicas)
+	prometheus.MustRegister(ClusterResourceRequested)
+	prometheus.MustRegister(ClusterResourceUsed)
+	prometheus.MustRegister(OrphanObjects)
+	prometheus.MustRegister(Leader)
 }
 
 // Label constants.
@@ -27,4 +31,6 @@ const (
 	LabelNamespace = "namespace"
 	LabelName      = "name"
 	LabelComponent = "component"
+	LabelResource  = "resource"
+	LabelKind      = "kind"
 )
@@ -25,4 +25,49 @@ var (
 			Name:      "spec_replicas",
 			Help:      "Desired replicas of each component in TidbCluster",
 		}, []string{LabelNamespace, LabelName, LabelComponent})
+
+	ClusterResourceRequested = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "cluster",
+			Name:      "resource_requested",
+			Help:      "Resources requested by each component in TidbCluster, CPU in cores and memory and storage in bytes",
+		}, []string{LabelNamespace, LabelName, LabelComponent, LabelResource})
+
+	ClusterResourceUsed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "cluster",
+			Name:      "resource_used",
+			Help:      "Resources used by each component in TidbCluster, CPU in cores and memory and storage in bytes",
+		}, []string{LabelNamespace, LabelName, LabelComponent, LabelResource})
+
+	OrphanObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "orphan_gc",
+			Name:      "objects",
+			Help:      "Objects left by deleted TidbClusters or their removed components that are not deleted",
+		}, []string{LabelNamespace, LabelName, LabelComponent, LabelKind})
 )
+
+// clusterComponents are the values of the component label of the metrics of TidbCluster
+var clusterComponents = []string{"pd", "tikv", "tidb", "tiflash", "ticdc", "pump", "tiproxy"}
+
+// DeleteClusterMetrics deletes the series of the deleted TidbCluster
+func DeleteClusterMetrics(ns, name string) {
+	for _, component := range clusterComponents {
+		ClusterSpecReplicas.DeleteLabelValues(ns, name, component)
+	}
+	DeleteClusterResourceMetrics(ns, name)
+}
+
+// DeleteClusterResourceMetrics deletes the series of the resources reported for the TidbCluster
+func DeleteClusterResourceMetrics(ns, name string) {
+	for _, component := range clusterComponents {
+		for _, resource := range []string{"cpu", "memory", "storage"} {
+			ClusterResourceRequested.DeleteLabelValues(ns, name, component, resource)
+			ClusterResourceUsed.DeleteLabelValues(ns, name, component, resource)
+		}
+	}
+}
@@ -70,11 +70,6 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	if monitor.DeletionTimestamp != nil {
 		return nil
 	}
-	if len(monitor.Spec.Clusters) < 1 && (monitor.Spec.DM == nil || len(monitor.Spec.DM.Clusters) < 1) {
-		klog.Errorf("tm[%s/%s] does not configure the target tidbcluster", monitor.Namespace, monitor.Name)
-		return nil
-	}
-
 	defaultTidbMonitor(monitor)
 	if !m.validate(monitor) {
 		return nil // fatal error, no need to retry on invalid object
@@ -141,6 +136,10 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	if err != nil {
 		return err
 	}
+	if err := m.checkThanosObjectStorageConfig(monitor); err != nil {
+		m.deps.Recorder.Event(monitor, corev1.EventTypeWarning, FailedSync, err.Error())
+		return err
+	}
 
 	// Sync Service
 	if err := m.syncTidbMonitorService(monitor); err != nil {
@@ -813,6 +812,27 @@ func buildEtcdValue(host string, port int) (string, error) {
 	return string(data), nil
 }
 
+// checkThanosObjectStorageConfig checks that the secret of the object storage config of Thanos exists and
+// contains the key, otherwise the Pods of Prometheus are stuck in CreateContainerConfigError
+func (m *MonitorManager) checkThanosObjectStorageConfig(monitor *v1alpha1.TidbMonitor) error {
+	thanos := monitor.Spec.Thanos
+	if thanos == nil || thanos.ObjectStorageConfig == nil || thanos.ObjectStorageConfigFile != nil {
+		return nil
+	}
+	ref := thanos.ObjectStorageConfig
+	if ref.Optional != nil && *ref.Optional {
+		return nil
+	}
+	secret, err := m.deps.SecretLister.Secrets(monitor.Namespace).Get(ref.Name)
+	if err != nil {
+		return fmt.Errorf("get tm[%s/%s]'s thanos object storage config secret %s failed, err: %v", monitor.Namespace, monitor.Name, ref.Name, err)
+	}
+	if _, ok := secret.Data[ref.Key]; !ok {
+		return fmt.Errorf("tm[%s/%s]'s thanos object storage config secret %s doesn't contain key %s", monitor.Namespace, monitor.Name, ref.Name, ref.Key)
+	}
+	return nil
+}
+
 func (m *MonitorManager) syncBasicAuth(monitor *v1alpha1.TidbMonitor, store *Store) error {
 	for i, remoteWrite := range monitor.Spec.Prometheus.RemoteWrite {
 		if err := store.AddBasicAuth(monitor.Namespace, remoteWrite.BasicAuth, fmt.Sprintf("remoteWrite/%d", i)); err != nil {
@@ -625,6 +625,38 @@ func TestTidbMonitorSyncUpdate(t *testing.T) {
 	}
 }
 
+func TestCheckThanosObjectStorageConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tmm := newFakeTidbMonitorManager()
+	tm := newTidbMonitor(v1alpha1.TidbClusterRef{Name: "foo", Namespace: "ns"})
+	g.Expect(tmm.checkThanosObjectStorageConfig(tm)).To(Succeed())
+
+	tm.Spec.Thanos = &v1alpha1.ThanosSpec{
+		ObjectStorageConfig: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "thanos-objstore-config"},
+			Key:                  "objstore.yml",
+		},
+	}
+	err := tmm.checkThanosObjectStorageConfig(tm)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("thanos-objstore-config"))
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "thanos-objstore-config", Namespace: "ns"},
+		Data:       map[string][]byte{"thanos.yml": []byte("type: S3")},
+	}
+	indexer := tmm.deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	g.Expect(indexer.Add(secret)).To(Succeed())
+	err = tmm.checkThanosObjectStorageConfig(tm)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("doesn't contain key objstore.yml"))
+
+	secret.Data["objstore.yml"] = []byte("type: S3")
+	g.Expect(indexer.Update(secret)).To(Succeed())
+	g.Expect(tmm.checkThanosObjectStorageConfig(tm)).To(Succeed())
+}
+
 func newTidbMonitor(cluster v1alpha1.TidbClusterRef) *v1alpha1.TidbMonitor {
 	return &v1alpha1.TidbMonitor{
 		ObjectMeta: metav1.ObjectMeta{
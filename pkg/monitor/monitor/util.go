@@ -1259,6 +1259,7 @@ func getMonitorStatefulSet(sa *core.ServiceAccount, secret *core.Secret, monitor
 	if monitor.Spec.ImagePullSecrets != nil {
 		statefulSet.Spec.Template.Spec.ImagePullSecrets = monitor.Spec.ImagePullSecrets
 	}
+	monitor.Spec.ImagePolicy.ResolvePodImages(&statefulSet.Spec.Template.Spec, "")
 
 	return statefulSet, nil
 }
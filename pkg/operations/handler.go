@@ -0,0 +1,335 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+var (
+	groupPath        = "/apis/" + GroupName
+	groupVersionPath = groupPath + "/" + Version
+
+	// the components in the order they are listed in the spec
+	allComponents = []v1alpha1.MemberType{
+		v1alpha1.PDMemberType,
+		v1alpha1.TiKVMemberType,
+		v1alpha1.TiFlashMemberType,
+		v1alpha1.TiDBMemberType,
+		v1alpha1.TiCDCMemberType,
+		v1alpha1.PumpMemberType,
+		v1alpha1.TiProxyMemberType,
+	}
+)
+
+// operationFunc applies an operation to the copy of the TidbCluster
+type operationFunc func(tc *v1alpha1.TidbCluster) error
+
+type handler struct {
+	cli versioned.Interface
+}
+
+// NewHandler returns the handler serving the discovery and the subresources of the operations API,
+// the requests must be authenticated and authorized before they reach the handler
+func NewHandler(cli versioned.Interface) http.Handler {
+	return &handler{cli: cli}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch path {
+	case groupPath:
+		writeJSON(w, http.StatusOK, apiGroup())
+		return
+	case groupVersionPath:
+		writeJSON(w, http.StatusOK, apiResourceList())
+		return
+	}
+
+	// namespaces/{namespace}/tidbclusters/{name}/{operation}
+	parts := strings.Split(strings.TrimPrefix(path, groupVersionPath+"/"), "/")
+	if !strings.HasPrefix(path, groupVersionPath+"/") || len(parts) != 5 || parts[0] != "namespaces" || parts[2] != "tidbclusters" {
+		writeError(w, apierrors.NewNotFound(SchemeGroupVersion.WithResource("tidbclusters").GroupResource(), ""))
+		return
+	}
+	ns, name, op := parts[1], parts[3], parts[4]
+	if r.Method != http.MethodPost {
+		writeError(w, apierrors.NewMethodNotSupported(SchemeGroupVersion.WithResource("tidbclusters/"+op).GroupResource(), r.Method))
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	tc, err := h.operate(ns, name, op, body)
+	if err != nil {
+		klog.Infof("operation %s of TidbCluster %s/%s failed: %v", op, ns, name, err)
+		writeError(w, err)
+		return
+	}
+	klog.Infof("operation %s of TidbCluster %s/%s is applied", op, ns, name)
+	writeJSON(w, http.StatusOK, tc)
+}
+
+// operate applies the operation to the TidbCluster, the TidbCluster is updated with the
+// resourceVersion which is read, so a concurrent change fails the request with a conflict
+func (h *handler) operate(ns, name, op string, body []byte) (*v1alpha1.TidbCluster, error) {
+	apply, err := decodeOperation(op, body)
+	if err != nil {
+		return nil, err
+	}
+	old, err := h.cli.PingcapV1alpha1().TidbClusters(ns).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if old.DeletionTimestamp != nil {
+		return nil, apierrors.NewConflict(v1alpha1.Resource("tidbclusters"), name, fmt.Errorf("TidbCluster is being deleted"))
+	}
+	tc := old.DeepCopy()
+	if err := apply(tc); err != nil {
+		return nil, err
+	}
+	if errs := validation.ValidateUpdateTidbCluster(old, tc); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.TiDBClusterKind).GroupKind(), name, errs)
+	}
+	return h.cli.PingcapV1alpha1().TidbClusters(ns).Update(context.TODO(), tc, metav1.UpdateOptions{})
+}
+
+func decodeOperation(op string, body []byte) (operationFunc, error) {
+	switch op {
+	case OperationScale:
+		req := &ScaleRequest{}
+		if err := decode(body, req); err != nil {
+			return nil, err
+		}
+		return func(tc *v1alpha1.TidbCluster) error {
+			return scale(tc, req.Component, req.Replicas)
+		}, nil
+	case OperationSuspend, OperationResume:
+		req := &ComponentRequest{}
+		if err := decode(body, req); err != nil {
+			return nil, err
+		}
+		return func(tc *v1alpha1.TidbCluster) error {
+			return suspend(tc, req.Components, op == OperationSuspend)
+		}, nil
+	case OperationRecover:
+		req := &ComponentRequest{}
+		if err := decode(body, req); err != nil {
+			return nil, err
+		}
+		return func(tc *v1alpha1.TidbCluster) error {
+			return recoverFailover(tc, req.Components)
+		}, nil
+	case OperationPauseUpgrade, OperationResumeUpgrade:
+		return func(tc *v1alpha1.TidbCluster) error {
+			tc.Spec.Paused = op == OperationPauseUpgrade
+			return nil
+		}, nil
+	}
+	return nil, apierrors.NewNotFound(SchemeGroupVersion.WithResource("tidbclusters/"+op).GroupResource(), "")
+}
+
+func decode(body []byte, req interface{}) error {
+	if len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, req); err != nil {
+		return apierrors.NewBadRequest(fmt.Sprintf("invalid request body: %v", err))
+	}
+	return nil
+}
+
+func scale(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, replicas int32) error {
+	if replicas < 0 {
+		return apierrors.NewBadRequest(fmt.Sprintf("replicas %d must be non-negative", replicas))
+	}
+	_, current, err := componentOf(tc, memberType)
+	if err != nil {
+		return err
+	}
+	*current = replicas
+	return nil
+}
+
+func suspend(tc *v1alpha1.TidbCluster, components []v1alpha1.MemberType, suspended bool) error {
+	if len(components) == 0 {
+		components = deployedComponents(tc)
+	}
+	for _, memberType := range components {
+		spec, _, err := componentOf(tc, memberType)
+		if err != nil {
+			return err
+		}
+		if spec.SuspendAction == nil {
+			spec.SuspendAction = &v1alpha1.SuspendAction{}
+		}
+		spec.SuspendAction.SuspendStatefulSet = suspended
+	}
+	return nil
+}
+
+// recoverFailover sets `failover.recoverByUID` to the current failover UID of the components,
+// so the field doesn't need to be reset after the recovery
+func recoverFailover(tc *v1alpha1.TidbCluster, components []v1alpha1.MemberType) error {
+	all := len(components) == 0
+	if all {
+		components = []v1alpha1.MemberType{v1alpha1.TiKVMemberType, v1alpha1.TiFlashMemberType}
+	}
+	for _, memberType := range components {
+		var (
+			failoverUID types.UID
+			failover    **v1alpha1.Failover
+		)
+		switch memberType {
+		case v1alpha1.TiKVMemberType:
+			if tc.Spec.TiKV == nil {
+				if all {
+					continue
+				}
+				return apierrors.NewBadRequest("component tikv is not deployed")
+			}
+			failoverUID, failover = tc.Status.TiKV.FailoverUID, &tc.Spec.TiKV.Failover
+		case v1alpha1.TiFlashMemberType:
+			if tc.Spec.TiFlash == nil {
+				if all {
+					continue
+				}
+				return apierrors.NewBadRequest("component tiflash is not deployed")
+			}
+			failoverUID, failover = tc.Status.TiFlash.FailoverUID, &tc.Spec.TiFlash.Failover
+		default:
+			return apierrors.NewBadRequest(fmt.Sprintf("failover of component %s can't be recovered", memberType))
+		}
+		if failoverUID == "" {
+			continue
+		}
+		if *failover == nil {
+			*failover = &v1alpha1.Failover{}
+		}
+		(*failover).RecoverByUID = failoverUID
+	}
+	return nil
+}
+
+func deployedComponents(tc *v1alpha1.TidbCluster) []v1alpha1.MemberType {
+	var components []v1alpha1.MemberType
+	for _, memberType := range allComponents {
+		if _, _, err := componentOf(tc, memberType); err == nil {
+			components = append(components, memberType)
+		}
+	}
+	return components
+}
+
+// componentOf returns the ComponentSpec and the replicas of the component in the spec
+func componentOf(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) (*v1alpha1.ComponentSpec, *int32, error) {
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		if tc.Spec.PD != nil {
+			return &tc.Spec.PD.ComponentSpec, &tc.Spec.PD.Replicas, nil
+		}
+	case v1alpha1.TiKVMemberType:
+		if tc.Spec.TiKV != nil {
+			return &tc.Spec.TiKV.ComponentSpec, &tc.Spec.TiKV.Replicas, nil
+		}
+	case v1alpha1.TiFlashMemberType:
+		if tc.Spec.TiFlash != nil {
+			return &tc.Spec.TiFlash.ComponentSpec, &tc.Spec.TiFlash.Replicas, nil
+		}
+	case v1alpha1.TiDBMemberType:
+		if tc.Spec.TiDB != nil {
+			return &tc.Spec.TiDB.ComponentSpec, &tc.Spec.TiDB.Replicas, nil
+		}
+	case v1alpha1.TiCDCMemberType:
+		if tc.Spec.TiCDC != nil {
+			return &tc.Spec.TiCDC.ComponentSpec, &tc.Spec.TiCDC.Replicas, nil
+		}
+	case v1alpha1.PumpMemberType:
+		if tc.Spec.Pump != nil {
+			return &tc.Spec.Pump.ComponentSpec, &tc.Spec.Pump.Replicas, nil
+		}
+	case v1alpha1.TiProxyMemberType:
+		if tc.Spec.TiProxy != nil {
+			return &tc.Spec.TiProxy.ComponentSpec, &tc.Spec.TiProxy.Replicas, nil
+		}
+	default:
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("unknown component %q", memberType))
+	}
+	return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("component %s is not deployed", memberType))
+}
+
+func apiGroup() *metav1.APIGroup {
+	gv := metav1.GroupVersionForDiscovery{GroupVersion: SchemeGroupVersion.String(), Version: Version}
+	return &metav1.APIGroup{
+		TypeMeta:         metav1.TypeMeta{Kind: "APIGroup", APIVersion: "v1"},
+		Name:             GroupName,
+		Versions:         []metav1.GroupVersionForDiscovery{gv},
+		PreferredVersion: gv,
+	}
+}
+
+func apiResourceList() *metav1.APIResourceList {
+	list := &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: SchemeGroupVersion.String(),
+	}
+	for _, op := range []string{OperationScale, OperationSuspend, OperationResume, OperationRecover, OperationPauseUpgrade, OperationResumeUpgrade} {
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:       "tidbclusters/" + op,
+			Namespaced: true,
+			Kind:       v1alpha1.TiDBClusterKind,
+			Verbs:      metav1.Verbs{"create"},
+		})
+	}
+	return list
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok {
+		status = apierrors.NewInternalError(err)
+	}
+	s := status.Status()
+	s.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	writeJSON(w, int(s.Code), &s)
+}
+
+func writeJSON(w http.ResponseWriter, code int, obj interface{}) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		klog.Errorf("failed to marshal the response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(data); err != nil {
+		klog.Errorf("failed to write the response: %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestHandlerDiscovery(t *testing.T) {
+	g := NewGomegaWithT(t)
+	h := NewHandler(fake.NewSimpleClientset())
+
+	rec := serve(h, http.MethodGet, "/apis/operations.tidb.pingcap.com/v1alpha1", "")
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	list := &metav1.APIResourceList{}
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), list)).To(Succeed())
+	g.Expect(list.GroupVersion).To(Equal("operations.tidb.pingcap.com/v1alpha1"))
+	g.Expect(list.APIResources).To(HaveLen(6))
+	g.Expect(list.APIResources[0].Name).To(Equal("tidbclusters/scale"))
+
+	rec = serve(h, http.MethodGet, "/apis/operations.tidb.pingcap.com", "")
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	group := &metav1.APIGroup{}
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), group)).To(Succeed())
+	g.Expect(group.PreferredVersion.Version).To(Equal("v1alpha1"))
+}
+
+func TestHandlerOperations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name   string
+		method string
+		op     string
+		body   string
+		update func(tc *v1alpha1.TidbCluster)
+		code   int
+		expect func(tc *v1alpha1.TidbCluster)
+	}{
+		{
+			name: "scale",
+			op:   OperationScale,
+			body: `{"component":"tidb","replicas":3}`,
+			code: http.StatusOK,
+			expect: func(tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Spec.TiDB.Replicas).To(BeEquivalentTo(3))
+			},
+		},
+		{
+			name: "scale a component not deployed",
+			op:   OperationScale,
+			body: `{"component":"tiflash","replicas":3}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "scale to negative replicas",
+			op:   OperationScale,
+			body: `{"component":"tidb","replicas":-1}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "suspend all components",
+			op:   OperationSuspend,
+			code: http.StatusOK,
+			expect: func(tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Spec.PD.SuspendAction.SuspendStatefulSet).To(BeTrue())
+				g.Expect(tc.Spec.TiKV.SuspendAction.SuspendStatefulSet).To(BeTrue())
+				g.Expect(tc.Spec.TiDB.SuspendAction.SuspendStatefulSet).To(BeTrue())
+			},
+		},
+		{
+			name: "resume a component",
+			op:   OperationResume,
+			body: `{"components":["tidb"]}`,
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiDB.SuspendAction = &v1alpha1.SuspendAction{SuspendStatefulSet: true}
+				tc.Spec.TiKV.SuspendAction = &v1alpha1.SuspendAction{SuspendStatefulSet: true}
+			},
+			code: http.StatusOK,
+			expect: func(tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Spec.TiDB.SuspendAction.SuspendStatefulSet).To(BeFalse())
+				g.Expect(tc.Spec.TiKV.SuspendAction.SuspendStatefulSet).To(BeTrue())
+			},
+		},
+		{
+			name: "recover the failover",
+			op:   OperationRecover,
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiKV.FailoverUID = "failover-uid"
+			},
+			code: http.StatusOK,
+			expect: func(tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Spec.TiKV.Failover.RecoverByUID).To(BeEquivalentTo("failover-uid"))
+			},
+		},
+		{
+			name: "recover the failover of TiDB",
+			op:   OperationRecover,
+			body: `{"components":["tidb"]}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "pause upgrade",
+			op:   OperationPauseUpgrade,
+			code: http.StatusOK,
+			expect: func(tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Spec.Paused).To(BeTrue())
+			},
+		},
+		{
+			name: "resume upgrade",
+			op:   OperationResumeUpgrade,
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.Paused = true
+			},
+			code: http.StatusOK,
+			expect: func(tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Spec.Paused).To(BeFalse())
+			},
+		},
+		{
+			name: "unknown operation",
+			op:   "unknown",
+			code: http.StatusNotFound,
+		},
+		{
+			name:   "get an operation",
+			method: http.MethodGet,
+			op:     OperationScale,
+			code:   http.StatusMethodNotAllowed,
+		},
+		{
+			name: "invalid body",
+			op:   OperationScale,
+			body: `{"component":`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "invalid cluster",
+			op:   OperationPauseUpgrade,
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.Requests = nil
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "deleting cluster",
+			op:   OperationPauseUpgrade,
+			update: func(tc *v1alpha1.TidbCluster) {
+				now := metav1.Now()
+				tc.DeletionTimestamp = &now
+			},
+			code: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := newTidbCluster()
+			if tt.update != nil {
+				tt.update(tc)
+			}
+			cli := fake.NewSimpleClientset(tc)
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			rec := serve(NewHandler(cli), method, "/apis/operations.tidb.pingcap.com/v1alpha1/namespaces/default/tidbclusters/test/"+tt.op, tt.body)
+			g.Expect(rec.Code).To(Equal(tt.code), rec.Body.String())
+			if tt.expect != nil {
+				tc, err := cli.PingcapV1alpha1().TidbClusters("default").Get(context.TODO(), "test", metav1.GetOptions{})
+				g.Expect(err).NotTo(HaveOccurred())
+				tt.expect(tc)
+			}
+		})
+	}
+}
+
+func TestHandlerNotFound(t *testing.T) {
+	g := NewGomegaWithT(t)
+	h := NewHandler(fake.NewSimpleClientset())
+
+	rec := serve(h, http.MethodPost, "/apis/operations.tidb.pingcap.com/v1alpha1/namespaces/default/tidbclusters/test/pauseupgrade", "")
+	g.Expect(rec.Code).To(Equal(http.StatusNotFound))
+	status := &metav1.Status{}
+	g.Expect(json.Unmarshal(rec.Body.Bytes(), status)).To(Succeed())
+	g.Expect(status.Reason).To(Equal(metav1.StatusReasonNotFound))
+
+	rec = serve(h, http.MethodPost, "/apis/operations.tidb.pingcap.com/v1alpha1/namespaces/default/pods/test/pauseupgrade", "")
+	g.Expect(rec.Code).To(Equal(http.StatusNotFound))
+}
+
+func TestHandlerConflict(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cli := fake.NewSimpleClientset(newTidbCluster())
+	cli.PrependReactor("update", "tidbclusters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(schema.GroupResource{Group: "pingcap.com", Resource: "tidbclusters"}, "test", nil)
+	})
+
+	rec := serve(NewHandler(cli), http.MethodPost, "/apis/operations.tidb.pingcap.com/v1alpha1/namespaces/default/tidbclusters/test/pauseupgrade", "")
+	g.Expect(rec.Code).To(Equal(http.StatusConflict))
+}
+
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func newTidbCluster() *v1alpha1.TidbCluster {
+	storage := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse("10Gi"),
+		},
+	}
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{ResourceRequirements: storage},
+			TiKV: &v1alpha1.TiKVSpec{ResourceRequirements: *storage.DeepCopy()},
+			TiDB: &v1alpha1.TiDBSpec{},
+		},
+	}
+}
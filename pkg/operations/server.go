@@ -0,0 +1,82 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/scheme"
+	"github.com/pingcap/tidb-operator/pkg/version"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	apimachineryversion "k8s.io/apimachinery/pkg/version"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+)
+
+// Options are the options of the operations API server
+type Options struct {
+	// SecurePort is the port to serve HTTPS
+	SecurePort int
+	// CertFile and KeyFile are the serving certificate and key,
+	// a self-signed certificate is generated if they are empty
+	CertFile string
+	KeyFile  string
+}
+
+// Run serves the operations API until the stopCh is closed. The requests are authenticated and
+// authorized by the delegated authentication and authorization of kube-apiserver, so the callers
+// are granted the subresources of `tidbclusters` in the group `operations.tidb.pingcap.com` by RBAC
+func Run(opts Options, cli versioned.Interface, stopCh <-chan struct{}) error {
+	secureServing := genericoptions.NewSecureServingOptions().WithLoopback()
+	secureServing.BindPort = opts.SecurePort
+	secureServing.ServerCert.CertKey.CertFile = opts.CertFile
+	secureServing.ServerCert.CertKey.KeyFile = opts.KeyFile
+	if err := secureServing.MaybeDefaultWithSelfSignedCerts("localhost", nil, []net.IP{net.ParseIP("127.0.0.1")}); err != nil {
+		return fmt.Errorf("failed to create the self-signed certificates: %v", err)
+	}
+	authentication := genericoptions.NewDelegatingAuthenticationOptions()
+	authorization := genericoptions.NewDelegatingAuthorizationOptions()
+
+	config := genericapiserver.NewRecommendedConfig(serializer.NewCodecFactory(scheme.Scheme))
+	if err := secureServing.ApplyTo(&config.SecureServing, &config.LoopbackClientConfig); err != nil {
+		return err
+	}
+	if err := authentication.ApplyTo(&config.Authentication, config.SecureServing, config.OpenAPIConfig); err != nil {
+		return err
+	}
+	if err := authorization.ApplyTo(&config.Authorization); err != nil {
+		return err
+	}
+	info := version.Get()
+	config.Version = &apimachineryversion.Info{
+		GitVersion:   info.GitVersion,
+		GitCommit:    info.GitCommit,
+		GitTreeState: info.GitTreeState,
+		BuildDate:    info.BuildDate,
+		GoVersion:    info.GoVersion,
+		Compiler:     info.Compiler,
+		Platform:     info.Platform,
+	}
+
+	server, err := config.Complete().New("tidb-operations", genericapiserver.NewEmptyDelegate())
+	if err != nil {
+		return err
+	}
+	handler := NewHandler(cli)
+	server.Handler.NonGoRestfulMux.Handle(groupPath, handler)
+	server.Handler.NonGoRestfulMux.HandlePrefix(groupPath+"/", handler)
+	return server.PrepareRun().Run(stopCh)
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// GroupName is the group of the operations API
+	GroupName = "operations.tidb.pingcap.com"
+	// Version is the version of the operations API
+	Version = "v1alpha1"
+)
+
+// SchemeGroupVersion is the group version of the operations API
+var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: Version}
+
+// The subresources of tidbclusters served by the operations API
+const (
+	OperationScale         = "scale"
+	OperationSuspend       = "suspend"
+	OperationResume        = "resume"
+	OperationRecover       = "recover"
+	OperationPauseUpgrade  = "pauseupgrade"
+	OperationResumeUpgrade = "resumeupgrade"
+)
+
+// ScaleRequest is the request to scale a component to the replicas
+type ScaleRequest struct {
+	metav1.TypeMeta `json:",inline"`
+	Component       v1alpha1.MemberType `json:"component"`
+	Replicas        int32               `json:"replicas"`
+}
+
+// ComponentRequest is the request of the operations applied to components
+type ComponentRequest struct {
+	metav1.TypeMeta `json:",inline"`
+	// Components are the components to operate, all components if empty
+	// +optional
+	Components []v1alpha1.MemberType `json:"components,omitempty"`
+}
@@ -15,6 +15,8 @@ package pdapi
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -29,6 +31,7 @@ const (
 	GetMembersActionType                        ActionType = "GetMembers"
 	GetStoresActionType                         ActionType = "GetStores"
 	GetTombStoneStoresActionType                ActionType = "GetTombStoneStores"
+	GetStoresByStateActionType                  ActionType = "GetStoresByState"
 	GetStoreActionType                          ActionType = "GetStore"
 	DeleteStoreActionType                       ActionType = "DeleteStore"
 	SetStoreStateActionType                     ActionType = "SetStoreState"
@@ -43,6 +46,15 @@ const (
 	GetPDLeaderActionType                       ActionType = "GetPDLeader"
 	TransferPDLeaderActionType                  ActionType = "TransferPDLeader"
 	GetAutoscalingPlansActionType               ActionType = "GetAutoscalingPlans"
+	ScatterRegionsActionType                    ActionType = "ScatterRegions"
+	UpdateScheduleConfigActionType              ActionType = "UpdateScheduleConfig"
+	GetScheduleConfigActionType                 ActionType = "GetScheduleConfig"
+	GetPlacementRulesActionType                 ActionType = "GetPlacementRules"
+	SetPlacementRuleActionType                  ActionType = "SetPlacementRule"
+	SetPlacementRuleGroupActionType             ActionType = "SetPlacementRuleGroup"
+	DeletePlacementRuleActionType               ActionType = "DeletePlacementRule"
+	GetStoreLimitsActionType                    ActionType = "GetStoreLimits"
+	SetStoreLimitActionType                     ActionType = "SetStoreLimit"
 )
 
 type NotFoundReaction struct {
@@ -58,6 +70,13 @@ type Action struct {
 	Name        string
 	Labels      map[string]string
 	Replication PDReplicationConfig
+	StartKey    string
+	EndKey      string
+	Config      map[string]interface{}
+	Rule        *PlacementRule
+	RuleGroup   *PlacementRuleGroup
+	LimitType   StoreLimitType
+	Rate        float64
 }
 
 type Reaction func(action *Action) (interface{}, error)
@@ -75,6 +94,16 @@ func (c *FakePDClient) AddReaction(actionType ActionType, reaction Reaction) {
 	c.reactions[actionType] = reaction
 }
 
+// RemoveReaction removes the reaction of the action type
+func (c *FakePDClient) RemoveReaction(actionType ActionType) {
+	delete(c.reactions, actionType)
+}
+
+// Reaction returns the reaction of the action type, or nil if it is not set
+func (c *FakePDClient) Reaction(actionType ActionType) Reaction {
+	return c.reactions[actionType]
+}
+
 // fakeAPI is a small helper for fake API calls
 func (c *FakePDClient) fakeAPI(actionType ActionType, action *Action) (interface{}, error) {
 	if reaction, ok := c.reactions[actionType]; ok {
@@ -141,6 +170,33 @@ func (c *FakePDClient) GetTombStoneStores() (*StoresInfo, error) {
 	return result.(*StoresInfo), nil
 }
 
+// GetStoresByState returns the result of the GetStoresByState reaction, or filters the
+// stores returned by the GetStores reaction if it's not added
+func (c *FakePDClient) GetStoresByState(states ...metapb.StoreState) (*StoresInfo, error) {
+	if _, ok := c.reactions[GetStoresByStateActionType]; ok {
+		result, err := c.fakeAPI(GetStoresByStateActionType, &Action{})
+		if err != nil {
+			return nil, err
+		}
+		return result.(*StoresInfo), nil
+	}
+	storesInfo, err := c.GetStores()
+	if err != nil {
+		return nil, err
+	}
+	filtered := &StoresInfo{}
+	for _, store := range storesInfo.Stores {
+		for _, state := range states {
+			if store.Store != nil && store.Store.GetState() == state {
+				filtered.Stores = append(filtered.Stores, store)
+				break
+			}
+		}
+	}
+	filtered.Count = len(filtered.Stores)
+	return filtered, nil
+}
+
 func (c *FakePDClient) GetStore(id uint64) (*StoreInfo, error) {
 	action := &Action{
 		ID: id,
@@ -270,3 +326,176 @@ func (c *FakePDClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	}
 	return nil, nil
 }
+
+func (c *FakePDClient) ScatterRegions(startKey, endKey string) error {
+	if reaction, ok := c.reactions[ScatterRegionsActionType]; ok {
+		action := &Action{StartKey: startKey, EndKey: endKey}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) UpdateScheduleConfig(config map[string]interface{}) error {
+	if reaction, ok := c.reactions[UpdateScheduleConfigActionType]; ok {
+		action := &Action{Config: config}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetScheduleConfig() (map[string]string, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetScheduleConfigActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]string), nil
+}
+
+func (c *FakePDClient) GetPlacementRules() ([]*PlacementRule, error) {
+	if reaction, ok := c.reactions[GetPlacementRulesActionType]; ok {
+		action := &Action{}
+		result, err := reaction(action)
+		if err != nil {
+			return nil, err
+		}
+		return result.([]*PlacementRule), nil
+	}
+	return nil, nil
+}
+
+func (c *FakePDClient) SetPlacementRule(rule *PlacementRule) error {
+	if reaction, ok := c.reactions[SetPlacementRuleActionType]; ok {
+		action := &Action{Rule: rule}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) SetPlacementRuleGroup(group *PlacementRuleGroup) error {
+	if reaction, ok := c.reactions[SetPlacementRuleGroupActionType]; ok {
+		action := &Action{RuleGroup: group}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) DeletePlacementRule(groupID, ruleID string) error {
+	if reaction, ok := c.reactions[DeletePlacementRuleActionType]; ok {
+		action := &Action{Rule: &PlacementRule{GroupID: groupID, ID: ruleID}}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetStoreLimits() (map[uint64]*StoreLimit, error) {
+	if reaction, ok := c.reactions[GetStoreLimitsActionType]; ok {
+		action := &Action{}
+		result, err := reaction(action)
+		if err != nil {
+			return nil, err
+		}
+		return result.(map[uint64]*StoreLimit), nil
+	}
+	return nil, nil
+}
+
+func (c *FakePDClient) SetStoreLimit(storeID uint64, limitType StoreLimitType, rate float64) error {
+	if reaction, ok := c.reactions[SetStoreLimitActionType]; ok {
+		action := &Action{ID: storeID, LimitType: limitType, Rate: rate}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+// FakePDEtcdClient implements a fake version of PDEtcdClient.
+type FakePDEtcdClient struct {
+	// Statuses are the statuses of the members keyed by the endpoints
+	Statuses map[string]*EtcdMemberStatus
+	// DefragmentErr is returned by Defragment if it is set
+	DefragmentErr error
+	// Defragmented are the endpoints defragmented in order
+	Defragmented []string
+	// Compacted are the revisions compacted in order
+	Compacted []int64
+	// KVs are the kvs stored
+	KVs map[string][]byte
+}
+
+func NewFakePDEtcdClient() *FakePDEtcdClient {
+	return &FakePDEtcdClient{Statuses: map[string]*EtcdMemberStatus{}, KVs: map[string][]byte{}}
+}
+
+func (c *FakePDEtcdClient) Get(key string, prefix bool) ([]*KeyValue, error) {
+	var kvs []*KeyValue
+	for k, v := range c.KVs {
+		if k == key || (prefix && strings.HasPrefix(k, key)) {
+			kvs = append(kvs, &KeyValue{Key: k, Value: v})
+		}
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs, nil
+}
+
+func (c *FakePDEtcdClient) PutKey(key, value string) error {
+	if c.KVs == nil {
+		c.KVs = map[string][]byte{}
+	}
+	c.KVs[key] = []byte(value)
+	return nil
+}
+
+func (c *FakePDEtcdClient) PutTTLKey(key, value string, ttl int64) error {
+	return nil
+}
+
+func (c *FakePDEtcdClient) DeleteKey(key string) error {
+	delete(c.KVs, key)
+	return nil
+}
+
+func (c *FakePDEtcdClient) PutKeysIfNotExist(guards []string, kvs []*KeyValue) (bool, error) {
+	for _, key := range guards {
+		if _, ok := c.KVs[key]; ok {
+			return false, nil
+		}
+	}
+	for _, kv := range kvs {
+		c.PutKey(kv.Key, string(kv.Value))
+	}
+	return true, nil
+}
+
+func (c *FakePDEtcdClient) Status(endpoint string) (*EtcdMemberStatus, error) {
+	status, ok := c.Statuses[endpoint]
+	if !ok {
+		return nil, fmt.Errorf("endpoint %s is unavailable", endpoint)
+	}
+	return status, nil
+}
+
+func (c *FakePDEtcdClient) Defragment(endpoint string) error {
+	if c.DefragmentErr != nil {
+		return c.DefragmentErr
+	}
+	c.Defragmented = append(c.Defragmented, endpoint)
+	if status, ok := c.Statuses[endpoint]; ok && status.DBSizeInUse > 0 {
+		status.DBSize = status.DBSizeInUse
+	}
+	return nil
+}
+
+func (c *FakePDEtcdClient) Compact(revision int64) error {
+	c.Compacted = append(c.Compacted, revision)
+	return nil
+}
+
+func (c *FakePDEtcdClient) Close() error {
+	return nil
+}
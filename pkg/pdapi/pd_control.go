@@ -14,12 +14,14 @@
 package pdapi
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
-	"net/http"
 	"sync"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/util"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	"k8s.io/client-go/kubernetes"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
@@ -69,6 +71,21 @@ func UseHeadlessService(headless bool) Option {
 	}
 }
 
+// Timeout sets the timeout of the requests of the PD client, defaults to DefaultTimeout.
+func Timeout(timeout time.Duration) Option {
+	return func(c *clientConfig) {
+		c.timeout = timeout
+	}
+}
+
+// Context binds the requests of the client to ctx, e.g. the context of the request being served,
+// the requests are bound to the context of the PDControl by default.
+func Context(ctx context.Context) Option {
+	return func(c *clientConfig) {
+		c.ctx = ctx
+	}
+}
+
 // PDControlInterface is an interface that knows how to manage and get tidb cluster's PD client
 type PDControlInterface interface {
 	// GetPDClient provides PDClient of the tidb cluster.
@@ -87,6 +104,10 @@ type clientConfig struct {
 	clientURL string
 	// clientKey is client name. If it is empty, will generate from target TC
 	clientKey string
+	// timeout is the timeout of the requests, the clients of different timeouts are cached separately
+	timeout time.Duration
+	// ctx cancels the requests of the client once it's done
+	ctx context.Context
 
 	tlsEnable          bool
 	tlsSecretNamespace Namespace
@@ -116,6 +137,11 @@ func (c *clientConfig) completeForPDClient(namespace Namespace, tcName string) {
 	if c.clientKey == "" {
 		c.clientKey = genClientKey(scheme, namespace, tcName, c.clusterDomain)
 	}
+	if c.timeout <= 0 {
+		c.timeout = DefaultTimeout
+	} else if c.timeout != DefaultTimeout {
+		c.clientKey = fmt.Sprintf("%s?timeout=%s", c.clientKey, c.timeout)
+	}
 }
 
 // completeForEtcdClient populate and correct config for pd etcd client
@@ -137,6 +163,8 @@ func (c *clientConfig) completeForEtcdClient(namespace Namespace, tcName string)
 
 // defaultPDControl is the default implementation of PDControlInterface.
 type defaultPDControl struct {
+	// ctx is the default context of the requests, it's done when tidb-operator is shutting down
+	ctx          context.Context
 	secretLister corelisterv1.SecretLister
 
 	mutex     sync.Mutex
@@ -154,14 +182,14 @@ func (c *noOpClose) Close() error {
 	return nil
 }
 
-// NewDefaultPDControl returns a defaultPDControl instance
-func NewDefaultPDControl(secretLister corelisterv1.SecretLister) PDControlInterface {
-	return &defaultPDControl{secretLister: secretLister, pdClients: map[string]PDClient{}, pdEtcdClients: map[string]PDEtcdClient{}}
+// NewDefaultPDControl returns a defaultPDControl instance whose requests are canceled once ctx is done
+func NewDefaultPDControl(ctx context.Context, secretLister corelisterv1.SecretLister) PDControlInterface {
+	return &defaultPDControl{ctx: ctx, secretLister: secretLister, pdClients: map[string]PDClient{}, pdEtcdClients: map[string]PDEtcdClient{}}
 }
 
 // NewDefaultPDControl returns a defaultPDControl instance
-func NewDefaultPDControlByCli(kubeCli kubernetes.Interface) PDControlInterface {
-	return &defaultPDControl{pdClients: map[string]PDClient{}, pdEtcdClients: map[string]PDEtcdClient{}}
+func NewDefaultPDControlByCli(ctx context.Context, kubeCli kubernetes.Interface) PDControlInterface {
+	return &defaultPDControl{ctx: ctx, pdClients: map[string]PDClient{}, pdEtcdClients: map[string]PDEtcdClient{}}
 }
 
 func (pdc *defaultPDControl) GetEndpoints(namespace Namespace, tcName string, tlsEnabled bool, opts ...Option) (endpoints []string, tlsConfig *tls.Config, err error) {
@@ -223,6 +251,15 @@ func (pdc *defaultPDControl) GetPDClient(namespace Namespace, tcName string, tls
 
 	config.completeForPDClient(namespace, tcName)
 
+	ctx := pdc.ctx
+	if config.ctx != nil {
+		ctx = config.ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cluster := fmt.Sprintf("%s/%s", namespace, tcName)
+
 	pdc.mutex.Lock()
 	defer pdc.mutex.Unlock()
 
@@ -230,15 +267,14 @@ func (pdc *defaultPDControl) GetPDClient(namespace Namespace, tcName string, tls
 		tlsConfig, err := GetTLSConfig(pdc.secretLister, config.tlsSecretNamespace, config.tlsSecretName)
 		if err != nil {
 			klog.Errorf("Unable to get tls config for tidb cluster %q in %s, pd client may not work: %v", tcName, namespace, err)
-			return &pdClient{url: config.clientURL, httpClient: &http.Client{Timeout: DefaultTimeout}}
+			tlsConfig = nil
 		}
-
-		return NewPDClient(config.clientURL, DefaultTimeout, tlsConfig)
+		return newPDClient(ctx, config.clientURL, httputil.LimitCluster(httputil.NewHTTPClient(config.timeout, tlsConfig, true), cluster))
 	}
 	if _, ok := pdc.pdClients[config.clientKey]; !ok {
-		pdc.pdClients[config.clientKey] = NewPDClient(config.clientURL, DefaultTimeout, nil)
+		pdc.pdClients[config.clientKey] = newPDClient(ctx, config.clientURL, httputil.LimitCluster(httputil.NewHTTPClient(config.timeout, nil, false), cluster))
 	}
-	return pdc.pdClients[config.clientKey]
+	return withContext(pdc.pdClients[config.clientKey], ctx)
 }
 
 func genClientKey(scheme string, namespace Namespace, clusterName string, clusterDomain string) string {
@@ -289,7 +325,7 @@ type FakePDControl struct {
 
 func NewFakePDControl(secretLister corelisterv1.SecretLister) *FakePDControl {
 	return &FakePDControl{
-		defaultPDControl{secretLister: secretLister, pdClients: map[string]PDClient{}},
+		defaultPDControl{secretLister: secretLister, pdClients: map[string]PDClient{}, pdEtcdClients: map[string]PDEtcdClient{}},
 	}
 }
 
@@ -304,3 +340,7 @@ func (fpc *FakePDControl) SetPDClientWithClusterDomain(namespace Namespace, tcNa
 func (fpc *FakePDControl) SetPDClientWithAddress(peerURL string, pdclient PDClient) {
 	fpc.defaultPDControl.pdClients[peerURL] = pdclient
 }
+
+func (fpc *FakePDControl) SetPDEtcdClient(namespace Namespace, tcName string, etcdClient PDEtcdClient) {
+	fpc.defaultPDControl.pdEtcdClients[genEtcdClientKey(namespace, tcName, "", false)] = etcdClient
+}
@@ -15,6 +15,7 @@ package pdapi
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 )
@@ -185,6 +186,33 @@ func TestPDControl(t *testing.T) {
 					g.Expect(string(etcdClient.tlsSecretNamespace)).To(Equal("test-namespace"))
 				},
 			},
+			{
+				name: "set timeout",
+				options: []Option{
+					Timeout(30 * time.Second),
+				},
+				tcName: "target-cluster",
+				tcNS:   "target-namespace",
+				expectConfig: func(pdClient *clientConfig, etcdClient *clientConfig) {
+					g.Expect(pdClient.clientURL).To(Equal("http://target-cluster-pd.target-namespace:2379"))
+					g.Expect(pdClient.clientKey).To(Equal("http.target-cluster.target-namespace?timeout=30s"))
+					g.Expect(pdClient.timeout).To(Equal(30 * time.Second))
+
+					g.Expect(etcdClient.clientKey).To(Equal("target-cluster.target-namespace.false"))
+				},
+			},
+			{
+				name: "set default timeout",
+				options: []Option{
+					Timeout(0),
+				},
+				tcName: "target-cluster",
+				tcNS:   "target-namespace",
+				expectConfig: func(pdClient *clientConfig, etcdClient *clientConfig) {
+					g.Expect(pdClient.clientKey).To(Equal("http.target-cluster.target-namespace"))
+					g.Expect(pdClient.timeout).To(Equal(DefaultTimeout))
+				},
+			},
 		}
 
 		for _, c := range cases {
@@ -15,11 +15,14 @@ package pdapi
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -62,6 +65,9 @@ type PDClient interface {
 	GetStores() (*StoresInfo, error)
 	// GetTombStoneStores lists all tombstone stores from cluster
 	GetTombStoneStores() (*StoresInfo, error)
+	// GetStoresByState lists the stores in the given states from cluster, which is
+	// filtered by PD to avoid transferring all the stores in a huge cluster
+	GetStoresByState(states ...metapb.StoreState) (*StoresInfo, error)
 	// GetStore gets a TiKV store for a specific store id from cluster
 	GetStore(storeID uint64) (*StoreInfo, error)
 	// storeLabelsEqualNodeLabels compares store labels with node labels
@@ -92,6 +98,24 @@ type PDClient interface {
 	TransferPDLeader(name string) error
 	// GetAutoscalingPlans returns the scaling plan for the cluster
 	GetAutoscalingPlans(strategy Strategy) ([]Plan, error)
+	// ScatterRegions scatters the regions in the given key range
+	ScatterRegions(startKey, endKey string) error
+	// UpdateScheduleConfig updates the schedule config items
+	UpdateScheduleConfig(config map[string]interface{}) error
+	// GetScheduleConfig returns the schedule config items, the values are formatted as strings
+	GetScheduleConfig() (map[string]string, error)
+	// GetPlacementRules lists all the placement rules of the cluster
+	GetPlacementRules() ([]*PlacementRule, error)
+	// SetPlacementRule creates or updates a placement rule
+	SetPlacementRule(rule *PlacementRule) error
+	// SetPlacementRuleGroup creates or updates a placement rule group
+	SetPlacementRuleGroup(group *PlacementRuleGroup) error
+	// DeletePlacementRule deletes a placement rule, it's ok if the rule doesn't exist
+	DeletePlacementRule(groupID, ruleID string) error
+	// GetStoreLimits returns the store limits of all the stores keyed by the store IDs
+	GetStoreLimits() (map[uint64]*StoreLimit, error)
+	// SetStoreLimit sets the rate of the given type of store limit for a store
+	SetStoreLimit(storeID uint64, limitType StoreLimitType, rate float64) error
 }
 
 var (
@@ -99,12 +123,18 @@ var (
 	membersPrefix          = "pd/api/v1/members"
 	storesPrefix           = "pd/api/v1/stores"
 	storePrefix            = "pd/api/v1/store"
+	storesLimitPrefix      = "pd/api/v1/stores/limit"
 	configPrefix           = "pd/api/v1/config"
+	scheduleConfigPrefix   = "pd/api/v1/config/schedule"
 	clusterIDPrefix        = "pd/api/v1/cluster"
 	schedulersPrefix       = "pd/api/v1/schedulers"
 	pdLeaderPrefix         = "pd/api/v1/leader"
 	pdLeaderTransferPrefix = "pd/api/v1/leader/transfer"
 	pdReplicationPrefix    = "pd/api/v1/config/replicate"
+	regionsScatterPrefix   = "pd/api/v1/regions/scatter"
+	placementRulesPrefix   = "pd/api/v1/config/rules"
+	placementRulePrefix    = "pd/api/v1/config/rule"
+	placementGroupPrefix   = "pd/api/v1/config/rule_group"
 	// evictLeaderSchedulerConfigPrefix is the prefix of evict-leader-scheduler
 	// config API, available since PD v3.1.0.
 	evictLeaderSchedulerConfigPrefix = "pd/api/v1/scheduler-config/evict-leader-scheduler/list"
@@ -115,6 +145,8 @@ var (
 type pdClient struct {
 	url        string
 	httpClient *http.Client
+	// ctx cancels the requests once it's done
+	ctx context.Context
 }
 
 // NewPDClient returns a new PDClient
@@ -123,13 +155,33 @@ func NewPDClient(url string, timeout time.Duration, tlsConfig *tls.Config) PDCli
 	if tlsConfig != nil {
 		disableKeepalive = true
 	}
-	return &pdClient{
-		url: url,
-		httpClient: &http.Client{
-			Timeout:   timeout,
-			Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: disableKeepalive},
-		},
+	return newPDClient(context.Background(), url, httputil.NewHTTPClient(timeout, tlsConfig, disableKeepalive))
+}
+
+func newPDClient(ctx context.Context, url string, httpClient *http.Client) *pdClient {
+	return &pdClient{url: url, httpClient: httpClient, ctx: ctx}
+}
+
+// withContext returns a copy of the client whose requests are bound to ctx, the clients
+// other than pdClient, e.g. the fake ones, are returned as they are
+func withContext(client PDClient, ctx context.Context) PDClient {
+	c, ok := client.(*pdClient)
+	if !ok || c.ctx == ctx {
+		return client
+	}
+	return newPDClient(ctx, c.url, c.httpClient)
+}
+
+// do sends the request whose response is handled by the caller
+func (c *pdClient) do(method, apiURL string, data []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, apiURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
 	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return httputil.Do(c.ctx, c.httpClient, req)
 }
 
 // following struct definitions are copied from github.com/pingcap/pd/server/api/store
@@ -176,6 +228,24 @@ type StoreInfo struct {
 	Status *StoreStatus `json:"status"`
 }
 
+// StoreLimitType is the type of store limit, which limits the speed of adding or removing
+// the peers of a store
+type StoreLimitType string
+
+const (
+	// StoreLimitAddPeer limits the speed of adding peers to a store
+	StoreLimitAddPeer StoreLimitType = "add-peer"
+	// StoreLimitRemovePeer limits the speed of removing peers from a store
+	StoreLimitRemovePeer StoreLimitType = "remove-peer"
+)
+
+// StoreLimit is the store limit returned from PD RESTful interface, the rates are in the
+// count of peers per minute
+type StoreLimit struct {
+	AddPeer    float64 `json:"add-peer"`
+	RemovePeer float64 `json:"remove-peer"`
+}
+
 // StoresInfo is stores info returned from PD RESTful interface
 type StoresInfo struct {
 	Count  int          `json:"count"`
@@ -240,6 +310,36 @@ type Plan struct {
 	Labels       map[string]string `json:"labels"`
 }
 
+// below copied from github.com/tikv/pd/server/schedule/placement
+
+// LabelConstraint is used to filter the stores by the labels.
+type LabelConstraint struct {
+	Key    string   `json:"key"`
+	Op     string   `json:"op"`
+	Values []string `json:"values"`
+}
+
+// PlacementRule is the placement rule of the replicas of the regions in a key range.
+type PlacementRule struct {
+	GroupID          string            `json:"group_id"`
+	ID               string            `json:"id"`
+	StartKeyHex      string            `json:"start_key"`
+	EndKeyHex        string            `json:"end_key"`
+	Role             string            `json:"role"`
+	IsWitness        bool              `json:"is_witness,omitempty"`
+	Count            int               `json:"count"`
+	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"`
+	LocationLabels   []string          `json:"location_labels,omitempty"`
+}
+
+// PlacementRuleGroup is the group of placement rules, the rules of a group with a larger index
+// override the rules of the groups with smaller indexes if Override is true.
+type PlacementRuleGroup struct {
+	ID       string `json:"id"`
+	Index    int    `json:"index,omitempty"`
+	Override bool   `json:"override,omitempty"`
+}
+
 type schedulerInfo struct {
 	Name    string `json:"name"`
 	StoreID uint64 `json:"store_id"`
@@ -247,7 +347,7 @@ type schedulerInfo struct {
 
 func (c *pdClient) GetHealth() (*HealthInfo, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, healthPrefix)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -263,7 +363,7 @@ func (c *pdClient) GetHealth() (*HealthInfo, error) {
 
 func (c *pdClient) GetConfig() (*PDConfigFromAPI, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -277,7 +377,7 @@ func (c *pdClient) GetConfig() (*PDConfigFromAPI, error) {
 
 func (c *pdClient) GetCluster() (*metapb.Cluster, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, clusterIDPrefix)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -291,7 +391,7 @@ func (c *pdClient) GetCluster() (*metapb.Cluster, error) {
 
 func (c *pdClient) GetMembers() (*MembersInfo, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, membersPrefix)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -304,7 +404,7 @@ func (c *pdClient) GetMembers() (*MembersInfo, error) {
 }
 
 func (c *pdClient) getStores(apiURL string) (*StoresInfo, error) {
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -328,12 +428,27 @@ func (c *pdClient) GetStores() (*StoresInfo, error) {
 }
 
 func (c *pdClient) GetTombStoneStores() (*StoresInfo, error) {
-	return c.getStores(fmt.Sprintf("%s/%s?state=%d", c.url, storesPrefix, metapb.StoreState_Tombstone))
+	return c.GetStoresByState(metapb.StoreState_Tombstone)
+}
+
+func (c *pdClient) GetStoresByState(states ...metapb.StoreState) (*StoresInfo, error) {
+	query := url.Values{}
+	for _, state := range states {
+		query.Add("state", strconv.Itoa(int(state)))
+	}
+	storesInfo, err := c.getStores(fmt.Sprintf("%s/%s?%s", c.url, storesPrefix, query.Encode()))
+	if err != nil {
+		if strings.HasSuffix(err.Error(), tiKVNotBootstrapped+"\n") {
+			err = TiKVNotBootstrappedErrorf(err.Error())
+		}
+		return nil, err
+	}
+	return storesInfo, nil
 }
 
 func (c *pdClient) GetStore(storeID uint64) (*StoreInfo, error) {
 	apiURL := fmt.Sprintf("%s/%s/%d", c.url, storePrefix, storeID)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -346,26 +461,36 @@ func (c *pdClient) GetStore(storeID uint64) (*StoreInfo, error) {
 }
 
 func (c *pdClient) DeleteStore(storeID uint64) error {
-	var exist bool
-	stores, err := c.GetStores()
+	// only get the store to be deleted instead of listing all the stores
+	apiURL := fmt.Sprintf("%s/%s/%d", c.url, storePrefix, storeID)
+	res, err := c.do("GET", apiURL, nil)
 	if err != nil {
 		return err
 	}
-	for _, store := range stores.Stores {
-		if store.Store.GetId() == storeID {
-			exist = true
-			break
-		}
+	body, err := ioutil.ReadAll(res.Body)
+	httputil.DeferClose(res.Body)
+	if err != nil {
+		return err
 	}
-	if !exist {
+	if res.StatusCode == http.StatusNotFound {
 		return nil
 	}
-	apiURL := fmt.Sprintf("%s/%s/%d", c.url, storePrefix, storeID)
+	if res.StatusCode >= 400 {
+		return fmt.Errorf("failed to get store %d: %v", storeID, string(body))
+	}
+	store := &StoreInfo{}
+	if err := json.Unmarshal(body, store); err != nil {
+		return err
+	}
+	if store.Store.GetState() == metapb.StoreState_Tombstone {
+		return nil
+	}
+
 	req, err := http.NewRequest("DELETE", apiURL, nil)
 	if err != nil {
 		return err
 	}
-	res, err := c.httpClient.Do(req)
+	res, err = httputil.Do(c.ctx, c.httpClient, req)
 	if err != nil {
 		return err
 	}
@@ -375,7 +500,7 @@ func (c *pdClient) DeleteStore(storeID uint64) error {
 	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusNotFound {
 		return nil
 	}
-	body, err := ioutil.ReadAll(res.Body)
+	body, err = ioutil.ReadAll(res.Body)
 	if err != nil {
 		return err
 	}
@@ -390,7 +515,7 @@ func (c *pdClient) SetStoreState(storeID uint64, state string) error {
 	if err != nil {
 		return err
 	}
-	res, err := c.httpClient.Do(req)
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
 	if err != nil {
 		return err
 	}
@@ -427,7 +552,7 @@ func (c *pdClient) DeleteMemberByID(memberID uint64) error {
 	if err != nil {
 		return err
 	}
-	res, err := c.httpClient.Do(req)
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
 	if err != nil {
 		return err
 	}
@@ -459,7 +584,7 @@ func (c *pdClient) DeleteMember(name string) error {
 	if err != nil {
 		return err
 	}
-	res, err := c.httpClient.Do(req)
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
 	if err != nil {
 		return err
 	}
@@ -477,7 +602,7 @@ func (c *pdClient) SetStoreLabels(storeID uint64, labels map[string]string) (boo
 	if err != nil {
 		return false, err
 	}
-	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	res, err := c.do("POST", apiURL, data)
 	if err != nil {
 		return false, err
 	}
@@ -495,7 +620,7 @@ func (c *pdClient) UpdateReplicationConfig(config PDReplicationConfig) error {
 	if err != nil {
 		return err
 	}
-	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	res, err := c.do("POST", apiURL, data)
 	if err != nil {
 		return err
 	}
@@ -514,7 +639,7 @@ func (c *pdClient) BeginEvictLeader(storeID uint64) error {
 	if err != nil {
 		return err
 	}
-	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	res, err := c.do("POST", apiURL, data)
 	if err != nil {
 		return err
 	}
@@ -551,7 +676,7 @@ func (c *pdClient) EndEvictLeader(storeID uint64) error {
 	if err != nil {
 		return err
 	}
-	res, err := c.httpClient.Do(req)
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
 	if err != nil {
 		return err
 	}
@@ -588,7 +713,7 @@ func (c *pdClient) EndEvictLeader(storeID uint64) error {
 
 func (c *pdClient) GetEvictLeaderSchedulers() ([]string, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, schedulersPrefix)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -641,7 +766,7 @@ func (c *pdClient) GetEvictLeaderSchedulersForStores(storeIDs ...uint64) (map[ui
 // In the previous versions, PD API returns 404 and this function will return an error.
 func (c *pdClient) getEvictLeaderSchedulerConfig() (*evictLeaderSchedulerConfig, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, evictLeaderSchedulerConfigPrefix)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -678,7 +803,7 @@ func (c *pdClient) filterLeaderEvictScheduler(evictLeaderSchedulers []string) ([
 
 func (c *pdClient) GetPDLeader() (*pdpb.Member, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, pdLeaderPrefix)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, err
 	}
@@ -696,7 +821,7 @@ func (c *pdClient) TransferPDLeader(memberName string) error {
 	if err != nil {
 		return err
 	}
-	res, err := c.httpClient.Do(req)
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
 	if err != nil {
 		return err
 	}
@@ -714,7 +839,7 @@ func (c *pdClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	if err != nil {
 		return nil, err
 	}
-	body, err := httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data))
+	body, err := httputil.PostBodyOK(c.ctx, c.httpClient, apiURL, bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
@@ -726,6 +851,168 @@ func (c *pdClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	return plans, nil
 }
 
+type scatterRegionsInfo struct {
+	StartKey string `json:"start_key"`
+	EndKey   string `json:"end_key"`
+}
+
+func (c *pdClient) ScatterRegions(startKey, endKey string) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, regionsScatterPrefix)
+	data, err := json.Marshal(&scatterRegionsInfo{StartKey: startKey, EndKey: endKey})
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.ctx, c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to scatter regions in range [%q, %q): %v", startKey, endKey, err)
+	}
+	return nil
+}
+
+func (c *pdClient) UpdateScheduleConfig(config map[string]interface{}) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.ctx, c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to update schedule config: %v", err)
+	}
+	return nil
+}
+
+func (c *pdClient) GetScheduleConfig() (map[string]string, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, scheduleConfigPrefix)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	config := map[string]interface{}{}
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]string, len(config))
+	for name, value := range config {
+		switch v := value.(type) {
+		case string, bool, json.Number:
+			items[name] = fmt.Sprint(v)
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			items[name] = string(data)
+		}
+	}
+	return items, nil
+}
+
+// ParseConfigValue converts the value of a config item to the json type expected by PD
+func ParseConfigValue(v string) interface{} {
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b
+	}
+	return v
+}
+
+func (c *pdClient) GetPlacementRules() ([]*PlacementRule, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, placementRulesPrefix)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*PlacementRule
+	if err := json.Unmarshal(body, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (c *pdClient) SetPlacementRule(rule *PlacementRule) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, placementRulePrefix)
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.ctx, c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to set placement rule %s/%s: %v", rule.GroupID, rule.ID, err)
+	}
+	return nil
+}
+
+func (c *pdClient) SetPlacementRuleGroup(group *PlacementRuleGroup) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, placementGroupPrefix)
+	data, err := json.Marshal(group)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.ctx, c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to set placement rule group %s: %v", group.ID, err)
+	}
+	return nil
+}
+
+func (c *pdClient) DeletePlacementRule(groupID, ruleID string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s/%s", c.url, placementRulePrefix, groupID, ruleID)
+	req, err := http.NewRequest("DELETE", apiURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	err2 := httputil.ReadErrorBody(res.Body)
+	return fmt.Errorf("failed %v to delete placement rule %s/%s: %v", res.StatusCode, groupID, ruleID, err2)
+}
+
+func (c *pdClient) GetStoreLimits() (map[uint64]*StoreLimit, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, storesLimitPrefix)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	limits := map[uint64]*StoreLimit{}
+	if err := json.Unmarshal(body, &limits); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+type storeLimitInfo struct {
+	Rate float64        `json:"rate"`
+	Type StoreLimitType `json:"type"`
+}
+
+func (c *pdClient) SetStoreLimit(storeID uint64, limitType StoreLimitType, rate float64) error {
+	apiURL := fmt.Sprintf("%s/%s/%d/limit", c.url, storePrefix, storeID)
+	data, err := json.Marshal(&storeLimitInfo{Rate: rate, Type: limitType})
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.ctx, c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to set %s limit of store %d to %v: %v", limitType, storeID, rate, err)
+	}
+	return nil
+}
+
 func getLeaderEvictSchedulerInfo(storeID uint64) *schedulerInfo {
 	return &schedulerInfo{"evict-leader-scheduler", storeID}
 }
@@ -549,20 +549,20 @@ func TestDeleteStore(t *testing.T) {
 		Store:  &MetaStore{Store: &metapb.Store{Id: storeID, State: metapb.StoreState_Up}},
 		Status: &StoreStatus{},
 	}
-	stores := &StoresInfo{
-		Count: 1,
-		Stores: []*StoreInfo{
-			store,
-		},
+	storeBytes, err := json.Marshal(store)
+	g.Expect(err).NotTo(HaveOccurred())
+	tombstoneStore := &StoreInfo{
+		Store:  &MetaStore{Store: &metapb.Store{Id: storeID, State: metapb.StoreState_Tombstone}},
+		Status: &StoreStatus{},
 	}
-
-	storesBytes, err := json.Marshal(stores)
+	tombstoneStoreBytes, err := json.Marshal(tombstoneStore)
 	g.Expect(err).NotTo(HaveOccurred())
 
 	tcs := []struct {
 		caseName  string
 		prePath   string
 		preMethod string
+		preStatus int
 		preResp   []byte
 		exist     bool
 		path      string
@@ -570,30 +570,38 @@ func TestDeleteStore(t *testing.T) {
 		want      bool
 	}{{
 		caseName:  "success_DeleteStore",
-		prePath:   fmt.Sprintf("/%s", storesPrefix),
+		prePath:   fmt.Sprintf("/%s/%d", storePrefix, storeID),
 		preMethod: "GET",
-		preResp:   storesBytes,
+		preStatus: http.StatusOK,
+		preResp:   storeBytes,
 		exist:     true,
 		path:      fmt.Sprintf("/%s/%d", storePrefix, storeID),
 		method:    "DELETE",
 		want:      true,
 	}, {
 		caseName:  "failed_DeleteStore",
-		prePath:   fmt.Sprintf("/%s", storesPrefix),
+		prePath:   fmt.Sprintf("/%s/%d", storePrefix, storeID),
 		preMethod: "GET",
-		preResp:   storesBytes,
+		preStatus: http.StatusOK,
+		preResp:   storeBytes,
 		exist:     true,
 		path:      fmt.Sprintf("/%s/%d", storePrefix, storeID),
 		method:    "DELETE",
 		want:      false,
 	}, {
 		caseName:  "delete_not_exist_store",
-		prePath:   fmt.Sprintf("/%s", storesPrefix),
+		prePath:   fmt.Sprintf("/%s/%d", storePrefix, storeID),
 		preMethod: "GET",
-		preResp:   storesBytes,
-		exist:     true,
-		path:      fmt.Sprintf("/%s/%d", storePrefix, storeID),
-		method:    "DELETE",
+		preStatus: http.StatusNotFound,
+		exist:     false,
+		want:      true,
+	}, {
+		caseName:  "delete_tombstone_store",
+		prePath:   fmt.Sprintf("/%s/%d", storePrefix, storeID),
+		preMethod: "GET",
+		preStatus: http.StatusOK,
+		preResp:   tombstoneStoreBytes,
+		exist:     false,
 		want:      true,
 	},
 	}
@@ -605,7 +613,7 @@ func TestDeleteStore(t *testing.T) {
 				g.Expect(request.Method).To(Equal(tc.preMethod), "check method")
 				g.Expect(request.URL.Path).To(Equal(tc.prePath), "check url")
 				w.Header().Set("Content-Type", ContentTypeJSON)
-				w.WriteHeader(http.StatusOK)
+				w.WriteHeader(tc.preStatus)
 				w.Write(tc.preResp)
 				count++
 				return
@@ -634,6 +642,118 @@ func TestDeleteStore(t *testing.T) {
 	}
 }
 
+func TestGetStoresByState(t *testing.T) {
+	g := NewGomegaWithT(t)
+	stores := &StoresInfo{
+		Count: 1,
+		Stores: []*StoreInfo{{
+			Store:  &MetaStore{Store: &metapb.Store{Id: 1, State: metapb.StoreState_Offline}},
+			Status: &StoreStatus{},
+		}},
+	}
+	storesBytes, err := json.Marshal(stores)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", storesPrefix)), "check url")
+		g.Expect(request.URL.Query()["state"]).To(Equal([]string{"0", "1"}), "check query")
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Write(storesBytes)
+	})
+	defer svc.Close()
+
+	pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+	result, err := pdClient.GetStoresByState(metapb.StoreState_Up, metapb.StoreState_Offline)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Count).To(Equal(1))
+	g.Expect(result.Stores[0].Store.GetId()).To(Equal(uint64(1)))
+}
+
+func TestGetPlacementRules(t *testing.T) {
+	g := NewGomegaWithT(t)
+	rules := []*PlacementRule{
+		{GroupID: "pd", ID: "default", Role: "voter", Count: 3},
+		{
+			GroupID: "tiflash",
+			ID:      "table-45-r",
+			Role:    "learner",
+			Count:   2,
+			LabelConstraints: []LabelConstraint{
+				{Key: "engine", Op: "in", Values: []string{"tiflash"}},
+			},
+		},
+	}
+	rulesBytes, err := json.Marshal(rules)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", placementRulesPrefix)), "check url")
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Write(rulesBytes)
+	})
+	defer svc.Close()
+
+	pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+	result, err := pdClient.GetPlacementRules()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(rules))
+}
+
+func TestStoreLimits(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case "GET":
+			g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", storesLimitPrefix)), "check url")
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			w.Write([]byte(`{"1":{"add-peer":15,"remove-peer":15},"4":{"add-peer":1.5,"remove-peer":15}}`))
+		case "POST":
+			g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s/4/limit", storePrefix)), "check url")
+			info := &storeLimitInfo{}
+			g.Expect(readJSON(request.Body, info)).To(Succeed())
+			g.Expect(info).To(Equal(&storeLimitInfo{Rate: 1, Type: StoreLimitAddPeer}))
+		}
+	})
+	defer svc.Close()
+
+	pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+	limits, err := pdClient.GetStoreLimits()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(limits).To(Equal(map[uint64]*StoreLimit{
+		1: {AddPeer: 15, RemovePeer: 15},
+		4: {AddPeer: 1.5, RemovePeer: 15},
+	}))
+	g.Expect(pdClient.SetStoreLimit(4, StoreLimitAddPeer, 1)).To(Succeed())
+}
+
+func TestGetScheduleConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", scheduleConfigPrefix)), "check url")
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Write([]byte(`{"replica-schedule-limit":64,"low-space-ratio":0.8,"enable-witness":false,"max-store-down-time":"30m0s","schedulers-v2":[{"type":"balance-region"}]}`))
+	})
+	defer svc.Close()
+
+	pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+	config, err := pdClient.GetScheduleConfig()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config).To(Equal(map[string]string{
+		"replica-schedule-limit": "64",
+		"low-space-ratio":        "0.8",
+		"enable-witness":         "false",
+		"max-store-down-time":    "30m0s",
+		"schedulers-v2":          `[{"type":"balance-region"}]`,
+	}))
+}
+
 func TestGetEvictLeaderSchedulersForStores(t *testing.T) {
 	g := NewGomegaWithT(t)
 
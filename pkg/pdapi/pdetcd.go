@@ -20,13 +20,32 @@ import (
 
 	etcdclientv3 "go.etcd.io/etcd/clientv3"
 	etcdclientv3util "go.etcd.io/etcd/clientv3/clientv3util"
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
 )
 
+// defragmentTimeout is the timeout of defragmenting the db of a member, which
+// takes much longer than the other requests for a large db
+const defragmentTimeout = 5 * time.Minute
+
 type KeyValue struct {
 	Key   string
 	Value []byte
 }
 
+// EtcdMemberStatus is the status of an etcd member
+type EtcdMemberStatus struct {
+	MemberID uint64
+	// IsLeader is true if the member is the raft leader of the etcd cluster
+	IsLeader bool
+	// Revision is the current revision of the etcd cluster
+	Revision int64
+	// DBSize is the physical size of the db in bytes
+	DBSize int64
+	// DBSizeInUse is the logical size of the db in bytes, the rest of the db is
+	// freed by compaction and can be reclaimed by defragmentation
+	DBSizeInUse int64
+}
+
 type PDEtcdClient interface {
 	// Get the specific kvs.
 	// if prefix is true will return all kvs with the specified key as prefix
@@ -37,6 +56,16 @@ type PDEtcdClient interface {
 	PutTTLKey(key, value string, ttl int64) error
 	// DeleteKey will delete key from the target pd etcd cluster
 	DeleteKey(key string) error
+	// PutKeysIfNotExist puts the kvs in a transaction only if none of the guard keys exists,
+	// it returns false without putting any kvs if any of them exists
+	PutKeysIfNotExist(guards []string, kvs []*KeyValue) (bool, error)
+	// Status returns the status of the etcd member serving the endpoint
+	Status(endpoint string) (*EtcdMemberStatus, error)
+	// Defragment defragments the db of the etcd member serving the endpoint,
+	// the member can't serve any requests until it is finished
+	Defragment(endpoint string) error
+	// Compact compacts the history of the keys until the revision
+	Compact(revision int64) error
 	// Close will close the etcd connection
 	Close() error
 }
@@ -121,3 +150,57 @@ func (c *pdEtcdClient) DeleteKey(key string) error {
 	}
 	return nil
 }
+
+func (c *pdEtcdClient) PutKeysIfNotExist(guards []string, kvs []*KeyValue) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	kvc := etcdclientv3.NewKV(c.etcdClient)
+
+	var cmps []etcdclientv3.Cmp
+	for _, key := range guards {
+		cmps = append(cmps, etcdclientv3util.KeyMissing(key))
+	}
+	var ops []etcdclientv3.Op
+	for _, kv := range kvs {
+		ops = append(ops, etcdclientv3.OpPut(kv.Key, string(kv.Value)))
+	}
+	resp, err := kvc.Txn(ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return false, err
+	}
+	return resp.Succeeded, nil
+}
+
+func (c *pdEtcdClient) Status(endpoint string) (*EtcdMemberStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	resp, err := c.etcdClient.Status(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &EtcdMemberStatus{
+		MemberID:    resp.Header.MemberId,
+		IsLeader:    resp.Leader == resp.Header.MemberId,
+		Revision:    resp.Header.Revision,
+		DBSize:      resp.DbSize,
+		DBSizeInUse: resp.DbSizeInUse,
+	}, nil
+}
+
+func (c *pdEtcdClient) Defragment(endpoint string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defragmentTimeout)
+	defer cancel()
+	_, err := c.etcdClient.Defragment(ctx, endpoint)
+	return err
+}
+
+func (c *pdEtcdClient) Compact(revision int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	_, err := c.etcdClient.Compact(ctx, revision, etcdclientv3.WithCompactPhysical())
+	// the revision has been compacted by PD already
+	if err == rpctypes.ErrCompacted {
+		return nil
+	}
+	return err
+}
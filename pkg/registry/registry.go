@@ -16,5 +16,7 @@ package registry
 var (
 	Strategies = []CreateUpdateStrategy{
 		TidbClusterStrategy{},
+		BackupStrategy{},
+		RestoreStrategy{},
 	}
 )
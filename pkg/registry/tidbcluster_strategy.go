@@ -52,7 +52,8 @@ func (TidbClusterStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.
 	oldTc, oldOk := castTidbCluster(old)
 	tc, ok := castTidbCluster(obj)
 	if ok && oldOk {
-		return validation.ValidateUpdateTidbCluster(oldTc, tc)
+		allErrs := validation.ValidateUpdateTidbCluster(oldTc, tc)
+		return append(allErrs, validation.ValidateResourceQuotaMax(oldTc, tc)...)
 	}
 	return field.ErrorList{}
 }
@@ -0,0 +1,55 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlprobe
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ProbeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "sql_probe",
+			Name:      "duration_seconds",
+			Help:      "Bucketed histogram of the time each step of the SQL probes takes",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{LabelStep})
+
+	ProbeFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "sql_probe",
+			Name:      "failures_total",
+			Help:      "Total number of the failed SQL probes by the failed step",
+		}, []string{LabelStep})
+
+	ProbeHealthy = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "sql_probe",
+			Name:      "healthy",
+			Help:      "Whether the SQL probes succeed, 1 if they do and 0 if the failures in a row reach the threshold",
+		})
+)
+
+// LabelStep is the label of the step of a probe
+const LabelStep = "step"
+
+// RegisterMetrics registers all metrics of the SQL probe.
+func RegisterMetrics() {
+	prometheus.MustRegister(ProbeDuration)
+	prometheus.MustRegister(ProbeFailures)
+	prometheus.MustRegister(ProbeHealthy)
+}
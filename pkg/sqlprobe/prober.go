@@ -0,0 +1,220 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlprobe
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Port is the port the metrics and the status of the probe are served on
+	Port = 10263
+	// StatusPath is the path the status of the probe is served at
+	StatusPath = "/status"
+
+	// StepConnect opens a new connection to TiDB
+	StepConnect = "connect"
+	// StepWrite writes a row to the health table, which is created if it doesn't exist
+	StepWrite = "write"
+	// StepRead reads the row written back
+	StepRead = "read"
+
+	healthTable = "sql_probe"
+)
+
+// Status is the result of the latest probes
+type Status struct {
+	// Healthy is false until a probe succeeds, and after the failures in a row reach the threshold
+	Healthy bool `json:"healthy"`
+	// ConsecutiveFailures is the number of the latest failed probes in a row
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// LastProbeTime is the time the latest probe finished
+	LastProbeTime *time.Time `json:"lastProbeTime,omitempty"`
+	// FailedStep and LastError are the step and the error of the latest failed probe
+	FailedStep string `json:"failedStep,omitempty"`
+	LastError  string `json:"lastError,omitempty"`
+}
+
+// Config is the configuration of a Prober
+type Config struct {
+	// DSN is the data source name of TiDB, without the database
+	DSN string
+	// Database is the database of the health table
+	Database string
+	// ID is the key of the row written by the prober, e.g. the name of the Pod
+	ID string
+
+	Interval         time.Duration
+	Timeout          time.Duration
+	FailureThreshold int
+}
+
+// Prober runs the synthetic workload against TiDB and records the results
+type Prober struct {
+	cfg    Config
+	openFn func(dsn string) (*sql.DB, error)
+	now    func() time.Time
+
+	mu           sync.Mutex
+	status       Status
+	tableCreated bool
+}
+
+// NewProber returns a Prober
+func NewProber(cfg Config) *Prober {
+	return &Prober{
+		cfg: cfg,
+		openFn: func(dsn string) (*sql.DB, error) {
+			return sql.Open("mysql", dsn)
+		},
+		now: time.Now,
+	}
+}
+
+// Run probes TiDB every interval until stopCh is closed
+func (p *Prober) Run(stopCh <-chan struct{}) {
+	wait.Until(p.Probe, p.cfg.Interval, stopCh)
+}
+
+// Probe runs the workload once and records the result
+func (p *Prober) Probe() {
+	step, err := p.probe()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.status.LastProbeTime = &now
+	if err == nil {
+		if !p.status.Healthy {
+			klog.Infof("SQL probe succeeds after %d failures", p.status.ConsecutiveFailures)
+		}
+		p.status.Healthy = true
+		p.status.ConsecutiveFailures = 0
+		ProbeHealthy.Set(1)
+		return
+	}
+
+	klog.Warningf("SQL probe failed at step %s: %v", step, err)
+	ProbeFailures.WithLabelValues(step).Inc()
+	p.status.ConsecutiveFailures++
+	p.status.FailedStep = step
+	p.status.LastError = err.Error()
+	if p.status.ConsecutiveFailures >= p.cfg.FailureThreshold {
+		p.status.Healthy = false
+		ProbeHealthy.Set(0)
+	}
+}
+
+// probe returns the failed step and the error if the workload fails
+func (p *Prober) probe() (string, error) {
+	// a new pool is opened for every probe, so that a new connection is established every time
+	db, err := p.openFn(p.cfg.DSN)
+	if err != nil {
+		return StepConnect, err
+	}
+	defer db.Close()
+
+	if err := p.step(StepConnect, db.PingContext); err != nil {
+		return StepConnect, err
+	}
+
+	table := fmt.Sprintf("`%s`.`%s`", p.cfg.Database, healthTable)
+	ts := p.now().UnixNano()
+	err = p.step(StepWrite, func(ctx context.Context) error {
+		if !p.tableCreated {
+			if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", p.cfg.Database)); err != nil {
+				return err
+			}
+			if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id VARCHAR(255) PRIMARY KEY, ts BIGINT NOT NULL)", table)); err != nil {
+				return err
+			}
+			p.tableCreated = true
+		}
+		_, err := db.ExecContext(ctx, fmt.Sprintf("REPLACE INTO %s (id, ts) VALUES (?, ?)", table), p.cfg.ID, ts) // nolint: gosec
+		return err
+	})
+	if err != nil {
+		return StepWrite, err
+	}
+
+	err = p.step(StepRead, func(ctx context.Context) error {
+		var read int64
+		if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT ts FROM %s WHERE id = ?", table), p.cfg.ID).Scan(&read); err != nil { // nolint: gosec
+			return err
+		}
+		if read != ts {
+			return fmt.Errorf("read %d from the health table, expected %d", read, ts)
+		}
+		return nil
+	})
+	if err != nil {
+		return StepRead, err
+	}
+	return "", nil
+}
+
+// step runs fn with the timeout and observes the time it takes
+func (p *Prober) step(name string, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := fn(ctx)
+	ProbeDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// Status returns the result of the latest probes
+func (p *Prober) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// ServeHTTP serves the status as JSON
+func (p *Prober) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(p.Status()); err != nil {
+		klog.Errorf("failed to write the status of SQL probe: %v", err)
+	}
+}
+
+// GetStatus reads the status of the probe served at url
+func GetStatus(ctx context.Context, client *http.Client, url string) (*Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returns %s", url, resp.Status)
+	}
+	status := &Status{}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, fmt.Errorf("failed to decode the status of SQL probe: %v", err)
+	}
+	return status, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlprobe
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestProberStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	p := NewProber(Config{DSN: "root@tcp(127.0.0.1:4000)/", Database: "tidb_operator", ID: "probe", Interval: time.Second, Timeout: time.Second, FailureThreshold: 2})
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	p.openFn = func(dsn string) (*sql.DB, error) {
+		return nil, fmt.Errorf("connection refused")
+	}
+	g.Expect(p.Status().Healthy).To(BeFalse())
+	g.Expect(p.Status().LastProbeTime).To(BeNil())
+
+	// the probe is unhealthy once the failures in a row reach the threshold
+	p.status.Healthy = true
+	p.Probe()
+	status := p.Status()
+	g.Expect(status.Healthy).To(BeTrue())
+	g.Expect(status.ConsecutiveFailures).To(Equal(1))
+	g.Expect(status.FailedStep).To(Equal(StepConnect))
+	g.Expect(status.LastError).To(Equal("connection refused"))
+	g.Expect(*status.LastProbeTime).To(Equal(now))
+	p.Probe()
+	g.Expect(p.Status().Healthy).To(BeFalse())
+	g.Expect(p.Status().ConsecutiveFailures).To(Equal(2))
+
+	// the status is served as JSON
+	server := httptest.NewServer(p)
+	defer server.Close()
+	served, err := GetStatus(context.TODO(), http.DefaultClient, server.URL+StatusPath)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*served).To(Equal(p.Status()))
+}
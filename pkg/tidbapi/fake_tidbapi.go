@@ -0,0 +1,115 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbapi
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb/config"
+)
+
+type ActionType string
+
+const (
+	GetStatusActionType      ActionType = "GetStatus"
+	GetInfoActionType        ActionType = "GetInfo"
+	GetSettingsActionType    ActionType = "GetSettings"
+	IsSchemaLoadedActionType ActionType = "IsSchemaLoaded"
+	ResignDDLOwnerActionType ActionType = "ResignDDLOwner"
+	SetLabelsActionType      ActionType = "SetLabels"
+)
+
+type NotFoundReaction struct {
+	actionType ActionType
+}
+
+func (nfr *NotFoundReaction) Error() string {
+	return fmt.Sprintf("not found %s reaction. Please add the reaction", nfr.actionType)
+}
+
+type Action struct {
+	Labels map[string]string
+}
+
+type Reaction func(action *Action) (interface{}, error)
+
+// FakeTiDBClient implements a fake version of TiDBClient.
+type FakeTiDBClient struct {
+	reactions map[ActionType]Reaction
+}
+
+func NewFakeTiDBClient() *FakeTiDBClient {
+	return &FakeTiDBClient{reactions: map[ActionType]Reaction{}}
+}
+
+func (c *FakeTiDBClient) AddReaction(actionType ActionType, reaction Reaction) {
+	c.reactions[actionType] = reaction
+}
+
+// fakeAPI is a small helper for fake API calls
+func (c *FakeTiDBClient) fakeAPI(actionType ActionType, action *Action) (interface{}, error) {
+	if reaction, ok := c.reactions[actionType]; ok {
+		result, err := reaction(action)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	return nil, &NotFoundReaction{actionType}
+}
+
+func (c *FakeTiDBClient) GetStatus() (*Status, error) {
+	result, err := c.fakeAPI(GetStatusActionType, &Action{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Status), nil
+}
+
+func (c *FakeTiDBClient) GetInfo() (*DBInfo, error) {
+	result, err := c.fakeAPI(GetInfoActionType, &Action{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*DBInfo), nil
+}
+
+func (c *FakeTiDBClient) GetSettings() (*config.Config, error) {
+	result, err := c.fakeAPI(GetSettingsActionType, &Action{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*config.Config), nil
+}
+
+func (c *FakeTiDBClient) IsSchemaLoaded() (bool, error) {
+	result, err := c.fakeAPI(IsSchemaLoadedActionType, &Action{})
+	if err != nil {
+		return false, err
+	}
+	return result.(bool), nil
+}
+
+func (c *FakeTiDBClient) SetLabels(labels map[string]string) error {
+	_, err := c.fakeAPI(SetLabelsActionType, &Action{Labels: labels})
+	return err
+}
+
+func (c *FakeTiDBClient) ResignDDLOwner() (bool, error) {
+	result, err := c.fakeAPI(ResignDDLOwnerActionType, &Action{})
+	if err != nil {
+		return false, err
+	}
+	return result.(bool), nil
+}
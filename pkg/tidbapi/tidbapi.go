@@ -0,0 +1,193 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+	"github.com/pingcap/tidb/config"
+)
+
+const (
+	DefaultTimeout = 5 * time.Second
+
+	// https://github.com/pingcap/tidb/blob/master/owner/manager.go#L183
+	// NotDDLOwnerError is the error message which was returned when the tidb node is not a ddl owner
+	NotDDLOwnerError = "This node is not a ddl owner, can't be resigned."
+
+	statusPrefix         = "status"
+	infoPrefix           = "info"
+	settingsPrefix       = "settings"
+	schemaPrefix         = "schema"
+	resignDDLOwnerPrefix = "ddl/owner/resign"
+	labelsPrefix         = "labels"
+)
+
+// Status is the response of the /status api of tidb server
+type Status struct {
+	Connections int    `json:"connections"`
+	Version     string `json:"version"`
+	GitHash     string `json:"git_hash"`
+}
+
+// DBInfo is the response of the /info api of tidb server
+type DBInfo struct {
+	IsOwner bool   `json:"is_owner"`
+	Version string `json:"version"`
+	GitHash string `json:"git_hash"`
+	DDLID   string `json:"ddl_id"`
+	// Labels are the labels of the tidb server, e.g. the zone for the follower reads
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// TiDBClient provides tidb server's status api
+type TiDBClient interface {
+	// GetStatus returns the status of the tidb server
+	GetStatus() (*Status, error)
+	// GetInfo returns the server info of the tidb server
+	GetInfo() (*DBInfo, error)
+	// GetSettings returns the config of the tidb server
+	GetSettings() (*config.Config, error)
+	// IsSchemaLoaded returns whether the tidb server has loaded the schema
+	// and is able to serve the SQL requests
+	IsSchemaLoaded() (bool, error)
+	// ResignDDLOwner resigns the ddl owner if the tidb server is the ddl owner,
+	// it returns false if the tidb server is not the ddl owner
+	ResignDDLOwner() (bool, error)
+	// SetLabels sets the labels of the tidb server, the labels not in the request are kept.
+	// The labels set are lost after the tidb server restarts unless they are in the config.
+	SetLabels(labels map[string]string) error
+}
+
+// tidbClient is default implementation of TiDBClient
+type tidbClient struct {
+	url        string
+	httpClient *http.Client
+	// ctx cancels the requests once it's done
+	ctx context.Context
+}
+
+func (c *tidbClient) GetStatus() (*Status, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, statusPrefix)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{}
+	if err := json.Unmarshal(body, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (c *tidbClient) GetInfo() (*DBInfo, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, infoPrefix)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	info := &DBInfo{}
+	if err := json.Unmarshal(body, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (c *tidbClient) GetSettings() (*config.Config, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, settingsPrefix)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config.Config{}
+	if err := json.Unmarshal(body, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *tidbClient) IsSchemaLoaded() (bool, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, schemaPrefix)
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
+	if err != nil {
+		return false, err
+	}
+	defer httputil.DeferClose(res.Body)
+	// the schema api responds with an error before the info schema is loaded
+	return res.StatusCode == http.StatusOK, nil
+}
+
+func (c *tidbClient) ResignDDLOwner() (bool, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, resignDDLOwnerPrefix)
+	req, err := http.NewRequest(http.MethodPost, apiURL, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := httputil.Do(c.ctx, c.httpClient, req)
+	if err != nil {
+		return false, err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return true, nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+	if strings.Contains(string(body), NotDDLOwnerError) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to resign ddl owner, response %d URL %s, body response: %s", res.StatusCode, apiURL, string(body))
+}
+
+func (c *tidbClient) SetLabels(labels map[string]string) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, labelsPrefix)
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	if _, err := httputil.PostBodyOK(c.ctx, c.httpClient, apiURL, bytes.NewBuffer(data)); err != nil {
+		return fmt.Errorf("failed to set labels %v: %v", labels, err)
+	}
+	return nil
+}
+
+// NewTiDBClient returns a new TiDBClient
+func NewTiDBClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) TiDBClient {
+	return NewTiDBClientWithHTTPClient(context.Background(), url, httputil.NewHTTPClient(timeout, tlsConfig, disableKeepalive))
+}
+
+// NewTiDBClientWithHTTPClient returns a new TiDBClient sending the requests by the http client,
+// the requests are canceled once ctx is done
+func NewTiDBClientWithHTTPClient(ctx context.Context, url string, httpClient *http.Client) TiDBClient {
+	return &tidbClient{
+		url:        url,
+		httpClient: httpClient,
+		ctx:        ctx,
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func getClientServer(h func(http.ResponseWriter, *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(h))
+}
+
+func TestGetStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", statusPrefix)), "check url")
+		w.Write([]byte(`{"connections":3,"version":"5.7.25-TiDB-v5.4.0","git_hash":"abc"}`))
+	})
+	defer svc.Close()
+
+	status, err := NewTiDBClient(svc.URL, DefaultTimeout, nil, true).GetStatus()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(&Status{Connections: 3, Version: "5.7.25-TiDB-v5.4.0", GitHash: "abc"}))
+}
+
+func TestIsSchemaLoaded(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tcs := []struct {
+		caseName string
+		code     int
+		want     bool
+	}{
+		{caseName: "loaded", code: http.StatusOK, want: true},
+		{caseName: "not loaded", code: http.StatusInternalServerError, want: false},
+	}
+	for _, tc := range tcs {
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", schemaPrefix)), "check url")
+			w.WriteHeader(tc.code)
+		})
+		defer svc.Close()
+
+		loaded, err := NewTiDBClient(svc.URL, DefaultTimeout, nil, true).IsSchemaLoaded()
+		g.Expect(err).NotTo(HaveOccurred(), tc.caseName)
+		g.Expect(loaded).To(Equal(tc.want), tc.caseName)
+	}
+}
+
+func TestResignDDLOwner(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tcs := []struct {
+		caseName string
+		code     int
+		body     string
+		want     bool
+		wantErr  bool
+	}{
+		{caseName: "owner", code: http.StatusOK, body: "success!", want: true},
+		{caseName: "not owner", code: http.StatusBadRequest, body: NotDDLOwnerError, want: false},
+		{caseName: "error", code: http.StatusInternalServerError, body: "internal error", wantErr: true},
+	}
+	for _, tc := range tcs {
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal("POST"), "check method")
+			g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", resignDDLOwnerPrefix)), "check url")
+			w.WriteHeader(tc.code)
+			w.Write([]byte(tc.body))
+		})
+		defer svc.Close()
+
+		resigned, err := NewTiDBClient(svc.URL, DefaultTimeout, nil, true).ResignDDLOwner()
+		if tc.wantErr {
+			g.Expect(err).To(HaveOccurred(), tc.caseName)
+			continue
+		}
+		g.Expect(err).NotTo(HaveOccurred(), tc.caseName)
+		g.Expect(resigned).To(Equal(tc.want), tc.caseName)
+	}
+}
+
+func TestSetLabels(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("POST"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", labelsPrefix)), "check url")
+		body, err := ioutil.ReadAll(request.Body)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(body)).To(Equal(`{"zone":"dc-1"}`))
+		w.Write([]byte("success!"))
+	})
+	defer svc.Close()
+
+	err := NewTiDBClient(svc.URL, DefaultTimeout, nil, true).SetLabels(map[string]string{"zone": "dc-1"})
+	g.Expect(err).NotTo(HaveOccurred())
+}
@@ -14,6 +14,7 @@
 package tiflashapi
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"sync"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 )
@@ -37,13 +39,25 @@ type TiFlashControlInterface interface {
 
 // defaultTiFlashControl is the default implementation of TiFlashControlInterface.
 type defaultTiFlashControl struct {
+	// ctx is the context of the requests, it's done when tidb-operator is shutting down
+	ctx          context.Context
 	mutex        sync.Mutex
 	secretLister corelisterv1.SecretLister
 }
 
-// NewDefaultTiFlashControl returns a defaultTiFlashControl instance
-func NewDefaultTiFlashControl(secretLister corelisterv1.SecretLister) TiFlashControlInterface {
-	return &defaultTiFlashControl{secretLister: secretLister}
+// NewDefaultTiFlashControl returns a defaultTiFlashControl instance whose requests are canceled once ctx is done
+func NewDefaultTiFlashControl(ctx context.Context, secretLister corelisterv1.SecretLister) TiFlashControlInterface {
+	return &defaultTiFlashControl{ctx: ctx, secretLister: secretLister}
+}
+
+// newClient returns a TiFlashClient whose requests share the limiter of the tidb cluster
+func (tc *defaultTiFlashControl) newClient(namespace, tcName, url string, tlsConfig *tls.Config) TiFlashClient {
+	ctx := tc.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	httpClient := httputil.NewHTTPClient(DefaultTimeout, tlsConfig, true)
+	return newTiFlashClient(ctx, url, httputil.LimitCluster(httpClient, fmt.Sprintf("%s/%s", namespace, tcName)))
 }
 
 func (tc *defaultTiFlashControl) GetTiFlashPodClient(namespace string, tcName string, podName string, tlsEnabled bool) TiFlashClient {
@@ -59,13 +73,13 @@ func (tc *defaultTiFlashControl) GetTiFlashPodClient(namespace string, tcName st
 		tlsConfig, err = pdapi.GetTLSConfig(tc.secretLister, pdapi.Namespace(namespace), util.ClusterClientTLSSecretName(tcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for TiFlash cluster %q, tiflash client may not work: %v", tcName, err)
-			return NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+			return tc.newClient(namespace, tcName, TiFlashPodClientURL(namespace, tcName, podName, scheme), tlsConfig)
 		}
 
-		return NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+		return tc.newClient(namespace, tcName, TiFlashPodClientURL(namespace, tcName, podName, scheme), tlsConfig)
 	}
 
-	return NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+	return tc.newClient(namespace, tcName, TiFlashPodClientURL(namespace, tcName, podName, scheme), tlsConfig)
 }
 
 func tiflashPodClientKey(schema, namespace, clusterName, podName string) string {
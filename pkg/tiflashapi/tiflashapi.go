@@ -14,9 +14,9 @@
 package tiflashapi
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
-	"net"
 	"net/http"
 	"time"
 
@@ -44,30 +44,22 @@ type TiFlashClient interface {
 type tiflashClient struct {
 	url        string
 	httpClient *http.Client
+	// ctx cancels the requests once it's done
+	ctx context.Context
 }
 
 // NewTiFlashClient returns a new TiFlashClient
 func NewTiFlashClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) TiFlashClient {
-	return &tiflashClient{
-		url: url,
-		httpClient: &http.Client{
-			Timeout: timeout,
-			Transport: &http.Transport{
-				TLSClientConfig:       tlsConfig,
-				DisableKeepAlives:     disableKeepalive,
-				ResponseHeaderTimeout: 10 * time.Second,
-				TLSHandshakeTimeout:   10 * time.Second,
-				DialContext: (&net.Dialer{
-					Timeout: 10 * time.Second,
-				}).DialContext,
-			},
-		},
-	}
+	return newTiFlashClient(context.Background(), url, httputil.NewHTTPClient(timeout, tlsConfig, disableKeepalive))
+}
+
+func newTiFlashClient(ctx context.Context, url string, httpClient *http.Client) TiFlashClient {
+	return &tiflashClient{url: url, httpClient: httpClient, ctx: ctx}
 }
 
 func (c *tiflashClient) GetStoreStatus() (Status, error) {
 	apiURL := fmt.Sprintf("%s/%s", c.url, storeStatusPath)
-	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
 	if err != nil {
 		return "", err
 	}
@@ -21,6 +21,8 @@ type ActionType string
 
 const (
 	GetLeaderCountActionType ActionType = "GetLeaderCount"
+	GetConfigItemsActionType ActionType = "GetConfigItems"
+	SetConfigItemsActionType ActionType = "SetConfigItems"
 )
 
 type NotFoundReaction struct {
@@ -35,6 +37,8 @@ type Action struct {
 	ID     uint64
 	Name   string
 	Labels map[string]string
+	Names  []string
+	Items  map[string]string
 }
 
 type Reaction func(action *Action) (interface{}, error)
@@ -72,3 +76,18 @@ func (c *FakeTiKVClient) GetLeaderCount() (int, error) {
 	}
 	return result.(int), nil
 }
+
+func (c *FakeTiKVClient) GetConfigItems(names ...string) (map[string]string, error) {
+	action := &Action{Names: names}
+	result, err := c.fakeAPI(GetConfigItemsActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]string), nil
+}
+
+func (c *FakeTiKVClient) SetConfigItems(items map[string]string) error {
+	action := &Action{Items: items}
+	_, err := c.fakeAPI(SetConfigItemsActionType, action)
+	return err
+}
@@ -14,12 +14,14 @@
 package tikvapi
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"sync"
 
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 )
@@ -32,14 +34,26 @@ type TiKVControlInterface interface {
 
 // defaultTiKVControl is the default implementation of TiKVControlInterface.
 type defaultTiKVControl struct {
+	// ctx is the context of the requests, it's done when tidb-operator is shutting down
+	ctx          context.Context
 	mutex        sync.Mutex
 	secretLister corelisterv1.SecretLister
 	tikvClients  map[string]TiKVClient
 }
 
-// NewDefaultTiKVControl returns a defaultTiKVControl instance
-func NewDefaultTiKVControl(secretLister corelisterv1.SecretLister) TiKVControlInterface {
-	return &defaultTiKVControl{secretLister: secretLister, tikvClients: map[string]TiKVClient{}}
+// NewDefaultTiKVControl returns a defaultTiKVControl instance whose requests are canceled once ctx is done
+func NewDefaultTiKVControl(ctx context.Context, secretLister corelisterv1.SecretLister) TiKVControlInterface {
+	return &defaultTiKVControl{ctx: ctx, secretLister: secretLister, tikvClients: map[string]TiKVClient{}}
+}
+
+// newClient returns a TiKVClient whose requests share the limiter of the tidb cluster
+func (tc *defaultTiKVControl) newClient(namespace, tcName, url string, tlsConfig *tls.Config) TiKVClient {
+	ctx := tc.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	httpClient := httputil.NewHTTPClient(DefaultTimeout, tlsConfig, true)
+	return newTiKVClient(ctx, url, httputil.LimitCluster(httpClient, fmt.Sprintf("%s/%s", namespace, tcName)))
 }
 
 func (tc *defaultTiKVControl) GetTiKVPodClient(namespace string, tcName string, podName string, tlsEnabled bool) TiKVClient {
@@ -55,13 +69,13 @@ func (tc *defaultTiKVControl) GetTiKVPodClient(namespace string, tcName string,
 		tlsConfig, err = pdapi.GetTLSConfig(tc.secretLister, pdapi.Namespace(namespace), util.ClusterClientTLSSecretName(tcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for TiKV cluster %q, tikv client may not work: %v", tcName, err)
-			return NewTiKVClient(TiKVPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+			return tc.newClient(namespace, tcName, TiKVPodClientURL(namespace, tcName, podName, scheme), tlsConfig)
 		}
 
-		return NewTiKVClient(TiKVPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+		return tc.newClient(namespace, tcName, TiKVPodClientURL(namespace, tcName, podName, scheme), tlsConfig)
 	}
 
-	return NewTiKVClient(TiKVPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+	return tc.newClient(namespace, tcName, TiKVPodClientURL(namespace, tcName, podName, scheme), tlsConfig)
 }
 
 func tikvPodClientKey(schema, namespace, clusterName, podName string) string {
@@ -14,13 +14,17 @@
 package tikvapi
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
-	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/prom2json"
 	"k8s.io/klog/v2"
@@ -31,17 +35,25 @@ const (
 	metricNameRegionCount = "tikv_raftstore_region_count"
 	labelNameLeaderCount  = "leader"
 	metricsPrefix         = "metrics"
+	configPrefix          = "config"
 )
 
 // TiKVClient provides tikv server's api
 type TiKVClient interface {
 	GetLeaderCount() (int, error)
+	// GetConfigItems returns the values of the config items in the dotted names,
+	// e.g. storage.io-rate-limit.max-bytes-per-sec
+	GetConfigItems(names ...string) (map[string]string, error)
+	// SetConfigItems changes the online config items keyed by the dotted names
+	SetConfigItems(items map[string]string) error
 }
 
 // tikvClient is default implementation of TiKVClient
 type tikvClient struct {
 	url        string
 	httpClient *http.Client
+	// ctx cancels the requests once it's done
+	ctx context.Context
 }
 
 // GetLeaderCount gets region leader count from the URL
@@ -74,21 +86,53 @@ func (c *tikvClient) GetLeaderCount() (int, error) {
 	return 0, fmt.Errorf("metric %s{type=\"%s\"} not found for %s", metricNameRegionCount, labelNameLeaderCount, apiURL)
 }
 
+// GetConfigItems gets the values of the config items from the current config of TiKV
+func (c *tikvClient) GetConfigItems(names ...string) (map[string]string, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
+	body, err := httputil.GetBodyOK(c.ctx, c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	config := map[string]interface{}{}
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]string, len(names))
+	for _, name := range names {
+		var value interface{} = config
+		for _, key := range strings.Split(name, ".") {
+			section, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("config item %s is not found in %s", name, apiURL)
+			}
+			if value, ok = section[key]; !ok {
+				return nil, fmt.Errorf("config item %s is not found in %s", name, apiURL)
+			}
+		}
+		items[name] = fmt.Sprint(value)
+	}
+	return items, nil
+}
+
+// SetConfigItems changes the online config items of TiKV
+func (c *tikvClient) SetConfigItems(items map[string]string) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.ctx, c.httpClient, apiURL, bytes.NewBuffer(data))
+	return err
+}
+
 // NewTiKVClient returns a new TiKVClient
 func NewTiKVClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) TiKVClient {
-	return &tikvClient{
-		url: url,
-		httpClient: &http.Client{
-			Timeout: timeout,
-			Transport: &http.Transport{
-				TLSClientConfig:       tlsConfig,
-				DisableKeepAlives:     disableKeepalive,
-				ResponseHeaderTimeout: 10 * time.Second,
-				TLSHandshakeTimeout:   10 * time.Second,
-				DialContext: (&net.Dialer{
-					Timeout: 10 * time.Second,
-				}).DialContext,
-			},
-		},
-	}
+	return newTiKVClient(context.Background(), url, httputil.NewHTTPClient(timeout, tlsConfig, disableKeepalive))
+}
+
+func newTiKVClient(ctx context.Context, url string, httpClient *http.Client) TiKVClient {
+	return &tikvClient{url: url, httpClient: httpClient, ctx: ctx}
 }
@@ -0,0 +1,108 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package topology defines the discovery document of a TiDB cluster published by
+// spec.topologyExport, which lists the endpoints of all the components of the cluster.
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// DocumentKey is the key of the document in the ConfigMap
+	DocumentKey = "topology.json"
+	// Version is the version of the schema of the document, it's increased on incompatible changes
+	Version = 1
+)
+
+// Protocols of the endpoints
+const (
+	ProtocolMySQL = "mysql"
+	ProtocolHTTP  = "http"
+	ProtocolGRPC  = "grpc"
+)
+
+// Document is the discovery document of a cluster
+type Document struct {
+	// Version is the version of the schema
+	Version int `json:"version"`
+	// Cluster is the name of the TidbCluster
+	Cluster string `json:"cluster"`
+	// Namespace is the namespace of the TidbCluster
+	Namespace string `json:"namespace"`
+	// ClusterDomain is the Kubernetes cluster domain used in the addresses, it's empty if the
+	// addresses are not fully qualified
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+	// TLS describes the TLS of the endpoints
+	TLS TLS `json:"tls"`
+	// Components are the deployed components of the cluster
+	Components []Component `json:"components"`
+}
+
+// TLS describes the TLS of the endpoints of the cluster
+type TLS struct {
+	// Cluster is whether TLS is enabled between the components, the clients of the endpoints
+	// other than SQL must present a certificate signed by the CA of the cluster
+	Cluster bool `json:"cluster"`
+	// ClientSecretName is the Secret of the client certificate of the cluster
+	ClientSecretName string `json:"clientSecretName,omitempty"`
+	// MySQLClient is whether TLS is enabled for the MySQL clients
+	MySQLClient bool `json:"mysqlClient"`
+}
+
+// Component is a component of the cluster, e.g. pd, tikv or tidb
+type Component struct {
+	// Name is the name of the component
+	Name string `json:"name"`
+	// Endpoints are the endpoints of the Service load balancing the instances, e.g. the
+	// SQL endpoint of TiDB, it's empty if the component is only reached by the instances
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+	// Instances are the instances of the component sorted by the names
+	Instances []Instance `json:"instances"`
+}
+
+// Instance is an instance of a component
+type Instance struct {
+	// Name is the name of the Pod
+	Name string `json:"name"`
+	// Endpoints are the endpoints of the instance
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is an endpoint of a component or an instance
+type Endpoint struct {
+	// Name is the name of the endpoint, e.g. sql, status, client or metrics
+	Name string `json:"name"`
+	// Address is the address of the endpoint in the form of host:port
+	Address string `json:"address"`
+	// Protocol is the protocol of the endpoint, one of mysql, http and grpc
+	Protocol string `json:"protocol"`
+	// Path is the HTTP path of the endpoint, e.g. /metrics
+	Path string `json:"path,omitempty"`
+	// TLS is whether the endpoint is served with TLS, TLS is optional for the SQL endpoints
+	TLS bool `json:"tls"`
+}
+
+// Parse parses a document read from the ConfigMap
+func Parse(data []byte) (*Document, error) {
+	doc := &Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != Version {
+		return nil, fmt.Errorf("unsupported version %d of the topology document", doc.Version)
+	}
+	return doc, nil
+}
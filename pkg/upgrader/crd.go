@@ -0,0 +1,159 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrader
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// maxReportedFields is the max number of the missing fields of a CRD in the log
+const maxReportedFields = 10
+
+// checkedCRDs are the CRDs whose schemas are checked against the types of the running operator
+var checkedCRDs = []struct {
+	name string
+	obj  interface{}
+}{
+	{"backups.pingcap.com", v1alpha1.Backup{}},
+	{"backupschedules.pingcap.com", v1alpha1.BackupSchedule{}},
+	{"dmclusters.pingcap.com", v1alpha1.DMCluster{}},
+	{"nodemaintenances.pingcap.com", v1alpha1.NodeMaintenance{}},
+	{"restores.pingcap.com", v1alpha1.Restore{}},
+	{"restoreschedules.pingcap.com", v1alpha1.RestoreSchedule{}},
+	{"tidbclusterautoscalers.pingcap.com", v1alpha1.TidbClusterAutoScaler{}},
+	{"tidbclustermaintenances.pingcap.com", v1alpha1.TidbClusterMaintenance{}},
+	{"tidbclusteroperations.pingcap.com", v1alpha1.TidbClusterOperation{}},
+	{"tidbclusterpreflights.pingcap.com", v1alpha1.TidbClusterPreflight{}},
+	{"tidbclusters.pingcap.com", v1alpha1.TidbCluster{}},
+	{"tidbinitializers.pingcap.com", v1alpha1.TidbInitializer{}},
+	{"tidbmonitors.pingcap.com", v1alpha1.TidbMonitor{}},
+	{"tidbngmonitorings.pingcap.com", v1alpha1.TidbNGMonitoring{}},
+}
+
+// checkCRDSchemas detects the CRDs which are not upgraded with the operator. kube-apiserver
+// prunes the fields unknown to the schema of a CRD silently, so the new fields of the spec
+// set by the users and the new fields of the status set by the operator would be lost. The
+// CRDs missing any field of the running operator are reported, which doesn't stop the operator
+// as the existing fields keep working.
+func checkCRDSchemas(extCli apiextensionsclientset.Interface) error {
+	if extCli == nil {
+		return nil
+	}
+	outdated := 0
+	for _, c := range checkedCRDs {
+		crd, err := extCli.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), c.name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+				klog.Warningf("Upgrader: skip checking the schema of CRD %s, error: %v", c.name, err)
+				continue
+			}
+			return err
+		}
+		missing := missingCRDFields(crd, v1alpha1.SchemeGroupVersion.Version, reflect.TypeOf(c.obj))
+		if len(missing) == 0 {
+			continue
+		}
+		outdated++
+		if len(missing) > maxReportedFields {
+			missing = append(missing[:maxReportedFields], "...")
+		}
+		klog.Warningf("Upgrader: CRD %s is outdated, fields %s are not found in the schema and would be dropped by kube-apiserver, please upgrade the CRDs",
+			c.name, strings.Join(missing, ", "))
+	}
+	if outdated == 0 {
+		klog.Infof("Upgrader: the schemas of all CRDs are up to date")
+	}
+	return nil
+}
+
+// missingCRDFields returns the sorted paths of the fields of t which are not found in the
+// schema of the version of crd. The fields are only checked if their parent has properties
+// in the schema, e.g. the fields of the types with custom marshalling like resource.Quantity
+// and the schemaless ones like config.GenericConfig are not checked.
+func missingCRDFields(crd *apiextensionsv1.CustomResourceDefinition, version string, t reflect.Type) []string {
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version {
+			continue
+		}
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			return nil
+		}
+		var missing []string
+		walkSchema(v.Schema.OpenAPIV3Schema, t, "", &missing)
+		sort.Strings(missing)
+		return missing
+	}
+	return []string{"version " + version}
+}
+
+func walkSchema(schema *apiextensionsv1.JSONSchemaProps, t reflect.Type, path string, missing *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if schema.Items != nil && schema.Items.Schema != nil {
+			walkSchema(schema.Items.Schema, t.Elem(), path+"[]", missing)
+		}
+	case reflect.Map:
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			walkSchema(schema.AdditionalProperties.Schema, t.Elem(), path+"[*]", missing)
+		}
+	case reflect.Struct:
+		if len(schema.Properties) == 0 {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" && f.Anonymous {
+				// the fields of the embedded struct are inlined
+				walkSchema(schema, f.Type, path, missing)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			prop, ok := schema.Properties[name]
+			if !ok {
+				*missing = append(*missing, fieldPath)
+				continue
+			}
+			walkSchema(&prop, f.Type, fieldPath, missing)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrader
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/config"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+type testObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              testSpec `json:"spec"`
+}
+
+type testSpec struct {
+	Replicas int32                 `json:"replicas"`
+	Storage  resource.Quantity     `json:"storage,omitempty"`
+	Config   *config.GenericConfig `json:"config,omitempty"`
+	Labels   map[string]string     `json:"labels,omitempty"`
+	Items    []testItem            `json:"items,omitempty"`
+	New      *bool                 `json:"new,omitempty"`
+}
+
+type testItem struct {
+	Name string `json:"name"`
+	New  string `json:"new,omitempty"`
+}
+
+func TestMissingCRDFields(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"apiVersion": {Type: "string"},
+			"kind":       {Type: "string"},
+			"metadata":   {Type: "object"},
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"replicas": {Type: "integer"},
+					"storage":  {XIntOrString: true},
+					"config":   {Type: "object", XPreserveUnknownFields: pointer.BoolPtr(true)},
+					"labels": {
+						Type:                 "object",
+						AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}},
+					},
+					"items": {
+						Type: "array",
+						Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+							Type:       "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{"name": {Type: "string"}},
+						}},
+					},
+				},
+			},
+		},
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "tests.pingcap.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: schema}},
+			},
+		},
+	}
+	typ := reflect.TypeOf(testObject{})
+
+	g.Expect(missingCRDFields(crd, "v1alpha1", typ)).To(Equal([]string{"spec.items[].new", "spec.new"}))
+	g.Expect(missingCRDFields(crd, "v1beta1", typ)).To(Equal([]string{"version v1beta1"}))
+
+	spec := schema.Properties["spec"]
+	spec.Properties["new"] = apiextensionsv1.JSONSchemaProps{Type: "boolean"}
+	items := spec.Properties["items"]
+	items.Items.Schema.Properties["new"] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+	g.Expect(missingCRDFields(crd, "v1alpha1", typ)).To(BeEmpty())
+}
+
+func TestCheckCRDSchemas(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// the CRDs not found are skipped
+	g.Expect(checkCRDSchemas(apiextensionsfake.NewSimpleClientset())).To(Succeed())
+	g.Expect(checkCRDSchemas(nil)).To(Succeed())
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// tidbClusterDeprecation is a deprecated field of TidbCluster. The deprecated fields
+// are still read by the operator, so the spec is never rewritten by the operator, the
+// users are told to move to the replacement by the events and the status instead.
+type tidbClusterDeprecation struct {
+	field       string
+	replacement string
+	used        func(tc *v1alpha1.TidbCluster) bool
+}
+
+var tidbClusterDeprecations = []tidbClusterDeprecation{
+	{
+		field:       "spec.services",
+		replacement: "spec.<component>.service",
+		used: func(tc *v1alpha1.TidbCluster) bool {
+			return len(tc.Spec.Services) > 0
+		},
+	},
+	{
+		field:       "spec.<component>.image",
+		replacement: "spec.<component>.baseImage and spec.<component>.version",
+		used: func(tc *v1alpha1.TidbCluster) bool {
+			return (tc.Spec.PD != nil && tc.Spec.PD.Image != "") ||
+				(tc.Spec.TiKV != nil && tc.Spec.TiKV.Image != "") ||
+				(tc.Spec.TiDB != nil && tc.Spec.TiDB.Image != "") ||
+				(tc.Spec.TiFlash != nil && tc.Spec.TiFlash.Image != "") ||
+				(tc.Spec.TiCDC != nil && tc.Spec.TiCDC.Image != "") ||
+				(tc.Spec.Pump != nil && tc.Spec.Pump.Image != "")
+		},
+	},
+	{
+		field:       "spec.pd.enableDashboardInternalProxy",
+		replacement: "dashboard.internal-proxy of spec.pd.config",
+		used: func(tc *v1alpha1.TidbCluster) bool {
+			return tc.Spec.PD != nil && tc.Spec.PD.EnableDashboardInternalProxy != nil
+		},
+	},
+	{
+		field:       "spec.tidb.slowLogTailer.image and spec.tidb.slowLogTailer.imagePullPolicy",
+		replacement: "spec.helper",
+		used: func(tc *v1alpha1.TidbCluster) bool {
+			if tc.Spec.TiDB == nil || tc.Spec.TiDB.SlowLogTailer == nil {
+				return false
+			}
+			tailer := tc.Spec.TiDB.SlowLogTailer
+			return tailer.Image != nil || tailer.ImagePullPolicy != nil
+		},
+	},
+}
+
+// DeprecatedFields returns the messages of the deprecated fields used by tc, the
+// messages are sorted in the order of tidbClusterDeprecations.
+func DeprecatedFields(tc *v1alpha1.TidbCluster) []string {
+	var msgs []string
+	for _, d := range tidbClusterDeprecations {
+		if d.used(tc) {
+			msgs = append(msgs, fmt.Sprintf("%s is deprecated, use %s instead", d.field, d.replacement))
+		}
+	}
+	return msgs
+}
+
+// reportDeprecatedFields reports the TidbClusters in ns using the deprecated fields as
+// warning events. The deprecated fields keep working, the TidbClusters are reconciled
+// as usual and the DeprecatedFieldsUsed condition is set by the controller.
+func reportDeprecatedFields(cli versioned.Interface, recorder record.EventRecorder, ns string) error {
+	tcList, err := cli.PingcapV1alpha1().TidbClusters(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	count := 0
+	for i := range tcList.Items {
+		tc := &tcList.Items[i]
+		msgs := DeprecatedFields(tc)
+		if len(msgs) == 0 {
+			continue
+		}
+		count++
+		msg := strings.Join(msgs, "; ")
+		klog.Warningf("Upgrader: TidbCluster %s/%s uses deprecated fields: %s", tc.Namespace, tc.Name, msg)
+		recorder.Event(tc, corev1.EventTypeWarning, "DeprecatedFields", msg)
+	}
+	klog.Infof("Upgrader: %d of %d TidbClusters use deprecated fields", count, len(tcList.Items))
+	return nil
+}
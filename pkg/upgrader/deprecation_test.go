@@ -0,0 +1,118 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrader
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	versionedfake "github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestDeprecatedFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		tc     func() *v1alpha1.TidbCluster
+		fields []string
+	}{
+		{
+			name: "no deprecated fields",
+			tc: func() *v1alpha1.TidbCluster {
+				tc := &v1alpha1.TidbCluster{}
+				tc.Spec.PD = &v1alpha1.PDSpec{}
+				tc.Spec.TiDB = &v1alpha1.TiDBSpec{SlowLogTailer: &v1alpha1.TiDBSlowLogTailerSpec{}}
+				return tc
+			},
+		},
+		{
+			name: "enableDashboardInternalProxy and image",
+			tc: func() *v1alpha1.TidbCluster {
+				tc := &v1alpha1.TidbCluster{}
+				tc.Spec.PD = &v1alpha1.PDSpec{EnableDashboardInternalProxy: pointer.BoolPtr(true)}
+				tc.Spec.TiKV = &v1alpha1.TiKVSpec{}
+				tc.Spec.TiKV.Image = "pingcap/tikv:v5.4.0"
+				return tc
+			},
+			fields: []string{"spec.<component>.image", "spec.pd.enableDashboardInternalProxy"},
+		},
+		{
+			name: "slow log tailer image",
+			tc: func() *v1alpha1.TidbCluster {
+				pullPolicy := corev1.PullAlways
+				tc := &v1alpha1.TidbCluster{}
+				tc.Spec.TiDB = &v1alpha1.TiDBSpec{
+					SlowLogTailer: &v1alpha1.TiDBSlowLogTailerSpec{ImagePullPolicy: &pullPolicy},
+				}
+				return tc
+			},
+			fields: []string{"spec.tidb.slowLogTailer.image"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := tt.tc()
+			spec := tc.Spec.DeepCopy()
+			msgs := DeprecatedFields(tc)
+			if len(msgs) != len(tt.fields) {
+				t.Fatalf("expected %d deprecated fields, got %v", len(tt.fields), msgs)
+			}
+			for i, field := range tt.fields {
+				if !strings.HasPrefix(msgs[i], field) {
+					t.Errorf("expected message of %s, got %s", field, msgs[i])
+				}
+			}
+			if !apiequality.Semantic.DeepEqual(spec, &tc.Spec) {
+				t.Errorf("expected the spec not to be changed")
+			}
+		})
+	}
+}
+
+func TestReportDeprecatedFields(t *testing.T) {
+	cli := versionedfake.NewSimpleClientset()
+	for _, name := range []string{"tc1", "tc2"} {
+		tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+		tc.Spec.PD = &v1alpha1.PDSpec{}
+		if name == "tc1" {
+			tc.Spec.PD.EnableDashboardInternalProxy = pointer.BoolPtr(false)
+		}
+		if _, err := cli.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(context.TODO(), tc, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	if err := reportDeprecatedFields(cli, recorder, metav1.NamespaceAll); err != nil {
+		t.Fatalf("expected no err, got %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected 1 event of the deprecated fields, got %d", len(recorder.Events))
+	}
+	if e := <-recorder.Events; !strings.Contains(e, "spec.pd.enableDashboardInternalProxy") {
+		t.Errorf("unexpected event %s", e)
+	}
+	for _, action := range cli.Actions() {
+		if action.GetVerb() != "list" && action.GetVerb() != "create" {
+			t.Errorf("expected the TidbClusters not to be changed, got %s", action.GetVerb())
+		}
+	}
+}
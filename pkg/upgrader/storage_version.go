@@ -0,0 +1,88 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrader
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+const tidbClusterCRD = "tidbclusters.pingcap.com"
+
+// migrateStorageVersion rewrites the TidbClusters stored in the versions other than the
+// storage version of the CRD, e.g. the ones stored in v1alpha1 after the storage version
+// is switched to v1beta1, then only the storage version is left in status.storedVersions,
+// so that the old versions can be removed from the CRD safely.
+//
+// All TidbClusters must be rewritten, so it's skipped if the operator is not cluster scoped.
+func migrateStorageVersion(extCli apiextensionsclientset.Interface, cli versioned.Interface, ns string) error {
+	if extCli == nil || ns != metav1.NamespaceAll {
+		return nil
+	}
+	crd, err := extCli.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), tidbClusterCRD, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			klog.Warningf("Upgrader: skip migrating the storage version of TidbClusters, error: %v", err)
+			return nil
+		}
+		return err
+	}
+	storageVersion := ""
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			storageVersion = v.Name
+		}
+	}
+	if storageVersion == "" {
+		return fmt.Errorf("Upgrader: no storage version is found in CRD %s", tidbClusterCRD)
+	}
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion {
+		return nil
+	}
+
+	klog.Infof("Upgrader: migrating TidbClusters stored in %v to the storage version %s", crd.Status.StoredVersions, storageVersion)
+	tcList, err := cli.PingcapV1alpha1().TidbClusters(ns).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range tcList.Items {
+		tc := &tcList.Items[i]
+		// writing back the object unchanged makes kube-apiserver encode it in the storage version,
+		// a conflict means it's written by others after being listed, which is encoded in the storage version too
+		_, err := cli.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(context.TODO(), tc, metav1.UpdateOptions{})
+		if err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("Upgrader: failed to migrate TidbCluster %s/%s to the storage version %s, error: %v", tc.Namespace, tc.Name, storageVersion, err)
+		}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		crd, err := extCli.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), tidbClusterCRD, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		crd.Status.StoredVersions = []string{storageVersion}
+		_, err = extCli.ApiextensionsV1().CustomResourceDefinitions().UpdateStatus(context.TODO(), crd, metav1.UpdateOptions{})
+		if err == nil {
+			klog.Infof("Upgrader: %d TidbClusters are migrated to the storage version %s", len(tcList.Items), storageVersion)
+		}
+		return err
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrader
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	versionedfake "github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMigrateStorageVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newCRD := func(storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: tidbClusterCRD},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha1", Served: true},
+					{Name: "v1beta1", Served: true, Storage: true},
+				},
+			},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+		}
+	}
+	tcs := []*v1alpha1.TidbCluster{
+		{ObjectMeta: metav1.ObjectMeta{Name: "tc1", Namespace: "ns1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "tc2", Namespace: "ns2"}},
+	}
+
+	// the objects are rewritten and the stored versions are updated
+	extCli := apiextensionsfake.NewSimpleClientset(newCRD("v1alpha1", "v1beta1"))
+	cli := versionedfake.NewSimpleClientset(tcs[0], tcs[1])
+	g.Expect(migrateStorageVersion(extCli, cli, metav1.NamespaceAll)).To(Succeed())
+	updates := 0
+	for _, action := range cli.Actions() {
+		if action.GetVerb() == "update" {
+			updates++
+		}
+	}
+	g.Expect(updates).To(Equal(2))
+	crd, err := extCli.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), tidbClusterCRD, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(crd.Status.StoredVersions).To(Equal([]string{"v1beta1"}))
+
+	// nothing is done if only the storage version is stored
+	cli.ClearActions()
+	g.Expect(migrateStorageVersion(extCli, cli, metav1.NamespaceAll)).To(Succeed())
+	g.Expect(cli.Actions()).To(BeEmpty())
+
+	// skipped if the operator is not cluster scoped
+	extCli = apiextensionsfake.NewSimpleClientset(newCRD("v1alpha1", "v1beta1"))
+	cli = versionedfake.NewSimpleClientset(tcs[0], tcs[1])
+	g.Expect(migrateStorageVersion(extCli, cli, "ns1")).To(Succeed())
+	g.Expect(cli.Actions()).To(BeEmpty())
+	g.Expect(migrateStorageVersion(nil, cli, metav1.NamespaceAll)).To(Succeed())
+	g.Expect(cli.Actions()).To(BeEmpty())
+}
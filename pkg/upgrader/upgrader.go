@@ -27,9 +27,11 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/util"
 	utildiscovery "github.com/pingcap/tidb-operator/pkg/util/discovery"
 	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 )
 
@@ -52,12 +54,30 @@ type upgrader struct {
 	kubeCli kubernetes.Interface
 	cli     versioned.Interface
 	asCli   asclientset.Interface
-	ns      string
+	// extCli is used to check the schemas of the CRDs and migrate the storage version of TidbClusters,
+	// it's nil if the operator is not cluster scoped
+	extCli apiextensionsclientset.Interface
+	ns     string
+	// recorder reports the TidbClusters using the deprecated fields
+	recorder record.EventRecorder
 }
 
 var _ Interface = &upgrader{}
 
 func (u *upgrader) Upgrade() error {
+	if err := u.upgradeStatefulSets(); err != nil {
+		return err
+	}
+	if err := checkCRDSchemas(u.extCli); err != nil {
+		return err
+	}
+	if err := migrateStorageVersion(u.extCli, u.cli, u.ns); err != nil {
+		return err
+	}
+	return reportDeprecatedFields(u.cli, u.recorder, u.ns)
+}
+
+func (u *upgrader) upgradeStatefulSets() error {
 	if features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
 		klog.Infof("Upgrader: migrating Kubernetes StatefulSets to Advanced StatefulSets")
 		stsList, err := u.kubeCli.AppsV1().StatefulSets(u.ns).List(context.Background(), metav1.ListOptions{})
@@ -93,6 +113,14 @@ func (u *upgrader) Upgrade() error {
 				return fmt.Errorf("Upgrader: TidbCluster %s/%s has delete slot annotations %v, please remove them before enabling AdvancedStatefulSet feature", tc.Namespace, tc.Name, anns)
 			}
 		}
+		for i := range stsToMigrate {
+			// the restartPolicy of init containers is dropped by Advanced StatefulSet, the native sidecars
+			// would never exit and block the main containers of the new pods
+			if sts := &stsToMigrate[i]; sts.Spec.Template.Annotations[label.AnnNativeSidecars] != "" {
+				return fmt.Errorf("Upgrader: StatefulSet %s/%s has native sidecars %s, which are not supported by Advanced StatefulSet, please disable AdvancedStatefulSet feature",
+					sts.Namespace, sts.Name, sts.Spec.Template.Annotations[label.AnnNativeSidecars])
+			}
+		}
 		klog.Infof("Upgrader: found %d Kubernetes StatefulSets owned by TidbCluster, trying to migrate one by one", len(stsToMigrate))
 		for i := range stsToMigrate {
 			sts := stsToMigrate[i]
@@ -145,6 +173,6 @@ func deleteSlotAnns(tc *v1alpha1.TidbCluster) map[string]string {
 	return anns
 }
 
-func NewUpgrader(kubeCli kubernetes.Interface, cli versioned.Interface, asCli asclientset.Interface, ns string) Interface {
-	return &upgrader{kubeCli, cli, asCli, ns}
+func NewUpgrader(kubeCli kubernetes.Interface, cli versioned.Interface, asCli asclientset.Interface, extCli apiextensionsclientset.Interface, ns string, recorder record.EventRecorder) Interface {
+	return &upgrader{kubeCli, cli, asCli, extCli, ns, recorder}
 }
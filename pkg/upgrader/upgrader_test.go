@@ -28,8 +28,10 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 )
 
@@ -392,6 +394,53 @@ func TestUpgrade(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "should not upgrade if sts has native sidecars",
+			statefulsets: []appsv1.StatefulSet{
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "StatefulSet",
+						APIVersion: "apps/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sts1",
+						Namespace:       "sts",
+						OwnerReferences: validOwnerRefs,
+					},
+					Spec: appsv1.StatefulSetSpec{
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Annotations: map[string]string{label.AnnNativeSidecars: "slowlog"},
+							},
+						},
+					},
+				},
+			},
+			feature:                  "AdvancedStatefulSet=true",
+			ns:                       metav1.NamespaceAll,
+			wantErr:                  true,
+			wantAdvancedStatefulsets: nil,
+			wantStatefulsets: []appsv1.StatefulSet{
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "StatefulSet",
+						APIVersion: "apps/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sts1",
+						Namespace:       "sts",
+						OwnerReferences: validOwnerRefs,
+					},
+					Spec: appsv1.StatefulSetSpec{
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Annotations: map[string]string{label.AnnNativeSidecars: "slowlog"},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "should upgrade if tc has delete slot annotations but does not own Kubernetes StatefulSets",
 			tidbClusters: []v1alpha1.TidbCluster{
@@ -662,7 +711,7 @@ func TestUpgrade(t *testing.T) {
 			}
 		}
 
-		operatorUpgrader := NewUpgrader(kubeCli, cli, asCli, tt.ns)
+		operatorUpgrader := NewUpgrader(kubeCli, cli, asCli, nil, tt.ns, record.NewFakeRecorder(10))
 		err = operatorUpgrader.Upgrade()
 		if tt.wantErr {
 			if err == nil {
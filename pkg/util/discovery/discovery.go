@@ -16,6 +16,7 @@ package discovery
 import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/discovery"
 )
 
@@ -75,3 +76,21 @@ func IsAPIGroupVersionResourceSupported(discoveryCli discovery.DiscoveryInterfac
 	}
 	return false, nil
 }
+
+// IsServerVersionAtLeast checks if the version of the api-server is at least the given version,
+// the suffix of the server version, e.g. "-eks-1234", is ignored.
+func IsServerVersionAtLeast(discoveryCli discovery.DiscoveryInterface, version string) (bool, error) {
+	info, err := discoveryCli.ServerVersion()
+	if err != nil {
+		return false, err
+	}
+	serverVersion, err := utilversion.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return false, err
+	}
+	minVersion, err := utilversion.ParseGeneric(version)
+	if err != nil {
+		return false, err
+	}
+	return serverVersion.AtLeast(minVersion), nil
+}
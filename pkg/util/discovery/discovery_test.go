@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
 	discoveryfake "k8s.io/client-go/discovery/fake"
 	k8stesting "k8s.io/client-go/testing"
 )
@@ -115,3 +116,43 @@ func TestIsAPIGroupSupported(t *testing.T) {
 		})
 	}
 }
+
+func TestIsServerVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		name          string
+		serverVersion string
+		wantOK        bool
+	}{
+		{
+			name:          "newer",
+			serverVersion: "v1.30.1",
+			wantOK:        true,
+		},
+		{
+			name:          "equal with suffix",
+			serverVersion: "v1.29.0-eks-1234",
+			wantOK:        true,
+		},
+		{
+			name:          "older",
+			serverVersion: "v1.28.3",
+			wantOK:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discoveryClient := &discoveryfake.FakeDiscovery{
+				Fake:               &k8stesting.Fake{},
+				FakedServerVersion: &version.Info{GitVersion: tt.serverVersion},
+			}
+			ok, err := IsServerVersionAtLeast(discoveryClient, "v1.29.0")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Errorf("got %v, want %v", ok, tt.wantOK)
+			}
+		})
+	}
+}
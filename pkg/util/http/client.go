@@ -0,0 +1,174 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultDeadline bounds the total time of a request including its retries,
+	// so that a worker is not blocked for long by an unavailable component
+	DefaultDeadline = 30 * time.Second
+	// DefaultMaxConnsPerHost caps the concurrent requests to a component of a cluster,
+	// the requests exceeding the cap wait for an idle connection
+	DefaultMaxConnsPerHost = 16
+	// DefaultClusterConcurrency caps the concurrent requests to all the components of a cluster,
+	// so that the workers syncing an unavailable cluster don't pile up the requests to it
+	DefaultClusterConcurrency = 32
+)
+
+// DefaultBackoff is the jittered backoff of retrying the requests failed by transient errors
+var DefaultBackoff = wait.Backoff{
+	Duration: 100 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.5,
+	Steps:    3,
+	Cap:      2 * time.Second,
+}
+
+// StatusError is returned if the response status code is not okay
+type StatusError struct {
+	StatusCode int
+	URL        string
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Error response %v URL %s,body response: %s", e.StatusCode, e.URL, string(e.Body[:]))
+}
+
+// IsRetriable returns whether err is a transient error, i.e. the request is failed to be sent
+// or the server is temporarily unavailable
+func IsRetriable(err error) bool {
+	switch e := err.(type) {
+	case *url.Error:
+		return e.Err != context.Canceled && e.Err != context.DeadlineExceeded
+	case *StatusError:
+		return e.StatusCode == http.StatusBadGateway ||
+			e.StatusCode == http.StatusServiceUnavailable ||
+			e.StatusCode == http.StatusGatewayTimeout
+	}
+	return false
+}
+
+// RetryOnError calls fn until it succeeds, the error is not retriable, the backoff steps are
+// exhausted or ctx is done, the last error is returned
+func RetryOnError(ctx context.Context, backoff wait.Backoff, retriable func(error) bool, fn func() error) error {
+	for {
+		err := fn()
+		if err == nil || !retriable(err) || backoff.Steps <= 1 {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// NewHTTPClient returns a http client used to call the apis of the components, the time
+// of dialing, TLS handshake and waiting for the response header are bounded.
+func NewHTTPClient(timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig:       tlsConfig,
+			DisableKeepAlives:     disableKeepalive,
+			MaxConnsPerHost:       DefaultMaxConnsPerHost,
+			IdleConnTimeout:       90 * time.Second,
+			ResponseHeaderTimeout: 10 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			DialContext: (&net.Dialer{
+				Timeout: 10 * time.Second,
+			}).DialContext,
+		},
+	}
+}
+
+var clusterLimiters = struct {
+	sync.Mutex
+	sems map[string]chan struct{}
+}{sems: map[string]chan struct{}{}}
+
+// LimitCluster returns a copy of httpClient whose concurrent requests are capped by the limiter of
+// the cluster, the limiter is shared by the clients of all the components of the cluster. The
+// requests waiting for the limiter are canceled once their contexts are done.
+func LimitCluster(httpClient *http.Client, cluster string) *http.Client {
+	clusterLimiters.Lock()
+	sem, ok := clusterLimiters.sems[cluster]
+	if !ok {
+		sem = make(chan struct{}, DefaultClusterConcurrency)
+		clusterLimiters.sems[cluster] = sem
+	}
+	clusterLimiters.Unlock()
+
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limited := *httpClient
+	limited.Transport = &limitedTransport{base: base, sem: sem}
+	return &limited
+}
+
+// ForgetCluster removes the limiter of the deleted cluster
+func ForgetCluster(cluster string) {
+	clusterLimiters.Lock()
+	defer clusterLimiters.Unlock()
+	delete(clusterLimiters.sems, cluster)
+}
+
+type limitedTransport struct {
+	base http.RoundTripper
+	sem  chan struct{}
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		<-t.sem
+		return nil, err
+	}
+	// the connection is in use until the body is closed
+	res.Body = &releaseOnClose{ReadCloser: res.Body, release: func() { <-t.sem }}
+	return res, nil
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	defer b.once.Do(b.release)
+	return b.ReadCloser.Close()
+}
@@ -14,6 +14,8 @@
 package httputil
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -41,43 +43,94 @@ func ReadErrorBody(body io.Reader) (err error) {
 }
 
 // GetBodyOK returns the body or an error if the response is not okay
-func GetBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
-	return DoBodyOK(httpClient, apiURL, "GET", nil)
+func GetBodyOK(ctx context.Context, httpClient *http.Client, apiURL string) ([]byte, error) {
+	return DoBodyOK(ctx, httpClient, apiURL, "GET", nil)
 }
 
 // PutBodyOK will PUT and returns the body or an error if the response is not okay
-func PutBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
-	return DoBodyOK(httpClient, apiURL, "PUT", nil)
+func PutBodyOK(ctx context.Context, httpClient *http.Client, apiURL string) ([]byte, error) {
+	return DoBodyOK(ctx, httpClient, apiURL, "PUT", nil)
 }
 
 // DeleteBodyOK will DELETE and returns the body or an error if the response is not okay
-func DeleteBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
-	return DoBodyOK(httpClient, apiURL, "DELETE", nil)
+func DeleteBodyOK(ctx context.Context, httpClient *http.Client, apiURL string) ([]byte, error) {
+	return DoBodyOK(ctx, httpClient, apiURL, "DELETE", nil)
 }
 
 // PostBodyOK will POST and returns the body or an error if the response is not okay
-func PostBodyOK(httpClient *http.Client, apiURL string, reqBody io.Reader) ([]byte, error) {
-	return DoBodyOK(httpClient, apiURL, "POST", reqBody)
+func PostBodyOK(ctx context.Context, httpClient *http.Client, apiURL string, reqBody io.Reader) ([]byte, error) {
+	return DoBodyOK(ctx, httpClient, apiURL, "POST", reqBody)
 }
 
-// DoBodyOK returns the body or an error if the response is not okay(StatusCode >= 400)
-func DoBodyOK(httpClient *http.Client, apiURL, method string, reqBody io.Reader) ([]byte, error) {
-	req, err := http.NewRequest(method, apiURL, reqBody)
-	if err != nil {
-		return nil, err
+// DoBodyOK returns the body or an error if the response is not okay(StatusCode >= 400).
+// The request including its retries is bounded by DefaultDeadline and canceled once ctx is done,
+// the idempotent requests are retried with DefaultBackoff if they are failed by transient errors.
+func DoBodyOK(ctx context.Context, httpClient *http.Client, apiURL, method string, reqBody io.Reader) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultDeadline)
+	defer cancel()
+
+	var data []byte
+	if reqBody != nil {
+		var err error
+		if data, err = ioutil.ReadAll(reqBody); err != nil {
+			return nil, err
+		}
 	}
-	res, err := httpClient.Do(req)
+	backoff := DefaultBackoff
+	if method == http.MethodPost {
+		backoff.Steps = 1
+	}
+
+	var body []byte
+	err := RetryOnError(ctx, backoff, IsRetriable, func() error {
+		var reader io.Reader
+		if data != nil {
+			reader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+		if err != nil {
+			return err
+		}
+		res, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer DeferClose(res.Body)
+		body, err = ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		if res.StatusCode >= 400 {
+			return &StatusError{StatusCode: res.StatusCode, URL: apiURL, Body: body}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer DeferClose(res.Body)
-	body, err := ioutil.ReadAll(res.Body)
+	return body, nil
+}
+
+// Do sends the request with the context bounded by DefaultDeadline and canceled once ctx is done,
+// it's used by the requests whose responses are handled by the callers. The context is canceled
+// once the body of the response is closed.
+func Do(ctx context.Context, httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultDeadline)
+	res, err := httpClient.Do(req.WithContext(ctx))
 	if err != nil {
+		cancel()
 		return nil, err
 	}
-	if res.StatusCode >= 400 {
-		errMsg := fmt.Errorf("Error response %v URL %s,body response: %s", res.StatusCode, apiURL, string(body[:]))
-		return nil, errMsg
-	}
-	return body, err
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
 }
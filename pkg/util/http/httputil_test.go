@@ -15,6 +15,7 @@ package httputil
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -66,31 +67,111 @@ func TestDoBodyOK(t *testing.T) {
 
 	// test normal
 	reqBody := bytes.NewReader([]byte("ok"))
-	data, err := DoBodyOK(cli, ts.URL+"/ok", "GET", reqBody)
+	data, err := DoBodyOK(context.Background(), cli, ts.URL+"/ok", "GET", reqBody)
 	g.Expect(err).Should(BeNil())
 	g.Expect(data).Should(Equal([]byte("ok")))
 
 	// test error status code
-	_, err = DoBodyOK(cli, ts.URL+"/server_error", "GET", reqBody)
+	_, err = DoBodyOK(context.Background(), cli, ts.URL+"/server_error", "GET", reqBody)
 	g.Expect(err).ShouldNot(BeNil())
 
 	// test GetBodyOK
-	data, err = GetBodyOK(cli, ts.URL+"/ok")
+	data, err = GetBodyOK(context.Background(), cli, ts.URL+"/ok")
 	g.Expect(err).Should(BeNil())
 	g.Expect(data).Should(Equal([]byte("")))
 
 	// test PutBodyOK
-	data, err = PutBodyOK(cli, ts.URL+"/ok")
+	data, err = PutBodyOK(context.Background(), cli, ts.URL+"/ok")
 	g.Expect(err).Should(BeNil())
 	g.Expect(data).Should(Equal([]byte("")))
 
 	// test DeleteBodyOK
-	data, err = DeleteBodyOK(cli, ts.URL+"/ok")
+	data, err = DeleteBodyOK(context.Background(), cli, ts.URL+"/ok")
 	g.Expect(err).Should(BeNil())
 	g.Expect(data).Should(Equal([]byte("")))
 
 	// test PostBodyOK
-	data, err = PostBodyOK(cli, ts.URL+"/ok", bytes.NewReader([]byte("ok")))
+	data, err = PostBodyOK(context.Background(), cli, ts.URL+"/ok", bytes.NewReader([]byte("ok")))
+	g.Expect(err).Should(BeNil())
+	g.Expect(data).Should(Equal([]byte("ok")))
+}
+
+func TestDoBodyOKRetry(t *testing.T) {
+	g := NewGomegaWithT(t)
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		w.Write(data)
+	}))
+	defer ts.Close()
+	cli := ts.Client()
+
+	// the idempotent request is retried with the same body
+	data, err := DoBodyOK(context.Background(), cli, ts.URL, "PUT", bytes.NewReader([]byte("ok")))
+	g.Expect(err).Should(BeNil())
+	g.Expect(data).Should(Equal([]byte("ok")))
+	g.Expect(requests).Should(Equal(2))
+
+	// the POST request is not retried
+	requests = 0
+	_, err = PostBodyOK(context.Background(), cli, ts.URL, bytes.NewReader([]byte("ok")))
+	g.Expect(err).ShouldNot(BeNil())
+	g.Expect(IsRetriable(err)).Should(BeTrue())
+	g.Expect(requests).Should(Equal(1))
+
+	// the non transient error is not retried
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	_, err = GetBodyOK(context.Background(), cli, notFound.URL)
+	g.Expect(err).ShouldNot(BeNil())
+	g.Expect(IsRetriable(err)).Should(BeFalse())
+}
+
+func TestLimitCluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+	defer ForgetCluster("ns/tc")
+
+	cli := LimitCluster(ts.Client(), "ns/tc")
+	// the clients of the same cluster share the limiter
+	other := LimitCluster(ts.Client(), "ns/tc")
+
+	done := make(chan error, DefaultClusterConcurrency)
+	for i := 0; i < DefaultClusterConcurrency; i++ {
+		go func() {
+			_, err := GetBodyOK(context.Background(), cli, ts.URL)
+			done <- err
+		}()
+	}
+	g.Eventually(func() int {
+		clusterLimiters.Lock()
+		defer clusterLimiters.Unlock()
+		return len(clusterLimiters.sems["ns/tc"])
+	}).Should(Equal(DefaultClusterConcurrency))
+
+	// the request waiting for the limiter is canceled once its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := GetBodyOK(ctx, other, ts.URL)
+	g.Expect(err).ShouldNot(BeNil())
+
+	close(release)
+	for i := 0; i < DefaultClusterConcurrency; i++ {
+		g.Expect(<-done).Should(BeNil())
+	}
+	// the limiter is released once the bodies are closed
+	data, err := GetBodyOK(context.Background(), other, ts.URL)
 	g.Expect(err).Should(BeNil())
 	g.Expect(data).Should(Equal([]byte("ok")))
 }
@@ -36,6 +36,20 @@ const (
 	TiFlashStoreNotUp = "TiFlashStoreNotUp"
 	// TiCDCCaptureNotReady is added when one of ticdc capture is not ready.
 	TiCDCCaptureNotReady = "TiCDCCaptureNotReady"
+	// ReferencedClusterNotReady is added when the cluster referenced by a heterogeneous cluster is not ready.
+	ReferencedClusterNotReady = "ReferencedClusterNotReady"
+	// InvalidSpec is added when the spec is refused by the strict validation.
+	InvalidSpec = "InvalidSpec"
+	// ValidSpec is added when the spec passes the strict validation.
+	ValidSpec = "ValidSpec"
+	// SQLProbeSucceeded is added when the SQL probe succeeds.
+	SQLProbeSucceeded = "SQLProbeSucceeded"
+	// SQLProbeFailed is added when the failed SQL probes in a row reach the threshold.
+	SQLProbeFailed = "SQLProbeFailed"
+	// SQLProbeUnknown is added when the status of the SQL probe can't be read.
+	SQLProbeUnknown = "SQLProbeUnknown"
+	// DeprecatedFields is added when the spec uses the deprecated fields.
+	DeprecatedFields = "DeprecatedFields"
 )
 
 // NewTidbClusterCondition creates a new tidbcluster condition.
@@ -76,6 +90,14 @@ func SetTidbClusterCondition(status *v1alpha1.TidbClusterStatus, condition v1alp
 	status.Conditions = append(newConditions, condition)
 }
 
+// RemoveTidbClusterCondition removes the condition with the provided type from the tidb cluster.
+func RemoveTidbClusterCondition(status *v1alpha1.TidbClusterStatus, condType v1alpha1.TidbClusterConditionType) {
+	if GetTidbClusterCondition(*status, condType) == nil {
+		return
+	}
+	status.Conditions = filterOutCondition(status.Conditions, condType)
+}
+
 // filterOutCondition returns a new slice of tidbcluster conditions without conditions with the provided type.
 func filterOutCondition(conditions []v1alpha1.TidbClusterCondition, condType v1alpha1.TidbClusterConditionType) []v1alpha1.TidbClusterCondition {
 	var newConditions []v1alpha1.TidbClusterCondition
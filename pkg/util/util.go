@@ -57,6 +57,9 @@ var (
 const (
 	// LastAppliedConfigAnnotation is annotation key of last applied configuration
 	LastAppliedConfigAnnotation = "pingcap.com/last-applied-configuration"
+	// LastAppliedHashAnnotation is annotation key of the hash of last applied configuration, it's recorded
+	// instead of LastAppliedConfigAnnotation if the objects are reconciled by server-side apply
+	LastAppliedHashAnnotation = "pingcap.com/last-applied-hash"
 )
 
 func GetOrdinalFromPodName(podName string) (int32, error) {
@@ -372,18 +375,82 @@ func MatchLabelFromStoreLabels(storeLabels []*metapb.StoreLabel, componentLabel
 	return storeKind == componentLabel
 }
 
+// SetLastAppliedConfig records the last applied config of the object in LastAppliedConfigAnnotation.
+// If the objects are reconciled by server-side apply, only the hash of the config is recorded in
+// LastAppliedHashAnnotation, so that the size of the annotations doesn't grow with the config.
+// The hash is removed otherwise, since it's not updated any more and would be stale when server-side
+// apply is enabled again.
+func SetLastAppliedConfig(obj metav1.Object, config interface{}) error {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	anns := obj.GetAnnotations()
+	if anns == nil {
+		anns = map[string]string{}
+	}
+	if features.DefaultFeatureGate.Enabled(features.ServerSideApply) {
+		anns[LastAppliedHashAnnotation] = v1alpha1.HashContents(b)
+	} else {
+		anns[LastAppliedConfigAnnotation] = string(b)
+		delete(anns, LastAppliedHashAnnotation)
+	}
+	obj.SetAnnotations(anns)
+	return nil
+}
+
+// LastAppliedHashEqual compares the hash of the config with the hash recorded in the old object,
+// ok is false if the objects are not reconciled by server-side apply or the hash is not recorded,
+// e.g. the object is updated before server-side apply is enabled.
+func LastAppliedHashEqual(old metav1.Object, config interface{}) (equal bool, ok bool, err error) {
+	if !features.DefaultFeatureGate.Enabled(features.ServerSideApply) {
+		return false, false, nil
+	}
+	hash, ok := old.GetAnnotations()[LastAppliedHashAnnotation]
+	if !ok {
+		return false, false, nil
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		return false, true, err
+	}
+	return hash == v1alpha1.HashContents(b), true, nil
+}
+
+// StatefulSetAppliedConfig returns the fields of the StatefulSet spec compared by StatefulSetEqual
+func StatefulSetAppliedConfig(spec apps.StatefulSetSpec) interface{} {
+	template := spec.Template.DeepCopy()
+	delete(template.Annotations, LastAppliedConfigAnnotation)
+	return struct {
+		Replicas       *int32                         `json:"replicas,omitempty"`
+		Template       corev1.PodTemplateSpec         `json:"template"`
+		UpdateStrategy apps.StatefulSetUpdateStrategy `json:"updateStrategy,omitempty"`
+	}{
+		Replicas:       spec.Replicas,
+		Template:       *template,
+		UpdateStrategy: spec.UpdateStrategy,
+	}
+}
+
 // statefulSetEqual compares the new Statefulset's spec with old Statefulset's last applied config
 func StatefulSetEqual(new apps.StatefulSet, old apps.StatefulSet) bool {
 	// The annotations in old sts may include LastAppliedConfigAnnotation
 	tmpAnno := map[string]string{}
 	for k, v := range old.Annotations {
-		if k != LastAppliedConfigAnnotation && k != label.AnnStsLastSyncTimestamp {
+		if k != LastAppliedConfigAnnotation && k != LastAppliedHashAnnotation && k != label.AnnStsLastSyncTimestamp {
 			tmpAnno[k] = v
 		}
 	}
 	if !apiequality.Semantic.DeepEqual(new.Annotations, tmpAnno) {
 		return false
 	}
+	if equal, ok, err := LastAppliedHashEqual(&old, StatefulSetAppliedConfig(new.Spec)); ok {
+		if err != nil {
+			klog.Errorf("hash Statefulset: [%s/%s]'s applied config failed,error: %v", new.GetNamespace(), new.GetName(), err)
+			return false
+		}
+		return equal
+	}
 	oldConfig := apps.StatefulSetSpec{}
 	if lastAppliedConfig, ok := old.Annotations[LastAppliedConfigAnnotation]; ok {
 		err := json.Unmarshal([]byte(lastAppliedConfig), &oldConfig)
@@ -26,6 +26,8 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -524,6 +526,40 @@ func TestRetainManagedFields(t *testing.T) {
 	}
 }
 
+func TestStatefulSetEqualWithServerSideApply(t *testing.T) {
+	g := NewGomegaWithT(t)
+	saved := features.DefaultFeatureGate.String()
+	features.DefaultFeatureGate.Set("ServerSideApply=true")
+	defer features.DefaultFeatureGate.Set(saved) // reset features on exit
+
+	newSet := func(image string) apps.StatefulSet {
+		return apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-tikv", Namespace: metav1.NamespaceDefault},
+			Spec: apps.StatefulSetSpec{
+				Replicas: pointer.Int32Ptr(3),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "tikv", Image: image}}},
+				},
+			},
+		}
+	}
+
+	old := newSet("tikv:v5.4.0")
+	g.Expect(SetLastAppliedConfig(&old, StatefulSetAppliedConfig(old.Spec))).To(Succeed())
+	g.Expect(old.Annotations).To(HaveKey(LastAppliedHashAnnotation))
+	g.Expect(old.Annotations).NotTo(HaveKey(LastAppliedConfigAnnotation))
+
+	g.Expect(StatefulSetEqual(newSet("tikv:v5.4.0"), old)).To(BeTrue())
+	g.Expect(StatefulSetEqual(newSet("tikv:v6.1.0"), old)).To(BeFalse())
+
+	// the config recorded before enabling server-side apply is still compared
+	features.DefaultFeatureGate.Set("ServerSideApply=false")
+	legacy := newSet("tikv:v5.4.0")
+	g.Expect(SetLastAppliedConfig(&legacy, legacy.Spec)).To(Succeed())
+	features.DefaultFeatureGate.Set("ServerSideApply=true")
+	g.Expect(StatefulSetEqual(newSet("tikv:v5.4.0"), legacy)).To(BeTrue())
+}
+
 func TestBuildAdditionalVolumeAndVolumeMount(t *testing.T) {
 	tests := []struct {
 		name             string
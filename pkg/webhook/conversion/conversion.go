@@ -0,0 +1,115 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1beta1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// ServeConversion serves the ConversionReview of TidbCluster sent by kube-apiserver
+func ServeConversion(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read the request: %v", err), http.StatusBadRequest)
+		return
+	}
+	review := &apiextensionsv1.ConversionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("failed to decode the ConversionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	review.Response = convert(review.Request)
+	review.Request = nil
+	data, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode the ConversionReview: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		klog.Errorf("failed to write the ConversionReview %s, error: %v", review.Response.UID, err)
+	}
+}
+
+func convert(req *apiextensionsv1.ConversionRequest) *apiextensionsv1.ConversionResponse {
+	resp := &apiextensionsv1.ConversionResponse{UID: req.UID}
+	for _, obj := range req.Objects {
+		converted, err := convertObject(obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			klog.Errorf("failed to convert the object to %s, error: %v", req.DesiredAPIVersion, err)
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			resp.ConvertedObjects = nil
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+// convertObject converts a TidbCluster between v1alpha1 and v1beta1 through the hub version v1alpha1
+func convertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.Kind != "TidbCluster" {
+		return nil, fmt.Errorf("unsupported kind %s", typeMeta.Kind)
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	hub := &v1alpha1.TidbCluster{}
+	switch typeMeta.APIVersion {
+	case v1alpha1.SchemeGroupVersion.String():
+		if err := json.Unmarshal(raw, hub); err != nil {
+			return nil, err
+		}
+	case v1beta1.SchemeGroupVersion.String():
+		tc := &v1beta1.TidbCluster{}
+		if err := json.Unmarshal(raw, tc); err != nil {
+			return nil, err
+		}
+		if err := tc.ConvertTo(hub); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported version %s", typeMeta.APIVersion)
+	}
+
+	switch desiredAPIVersion {
+	case v1alpha1.SchemeGroupVersion.String():
+		return json.Marshal(hub)
+	case v1beta1.SchemeGroupVersion.String():
+		tc := &v1beta1.TidbCluster{}
+		if err := tc.ConvertFrom(hub); err != nil {
+			return nil, err
+		}
+		return json.Marshal(tc)
+	default:
+		return nil, fmt.Errorf("unsupported version %s", desiredAPIVersion)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1beta1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestServeConversion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "TidbCluster", APIVersion: "pingcap.com/v1alpha1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: "default"},
+		Spec: v1alpha1.TidbClusterSpec{
+			Version: "v5.4.0",
+			TiKV: &v1alpha1.TiKVSpec{
+				Replicas: 3,
+				ComponentSpec: v1alpha1.ComponentSpec{
+					Image: "pingcap/tikv:v5.3.0",
+				},
+				StoreLabels: []string{"zone"},
+			},
+		},
+	}
+	raw, err := json.Marshal(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	review := func(desired string, raw []byte) *apiextensionsv1.ConversionResponse {
+		req := &apiextensionsv1.ConversionReview{
+			TypeMeta: metav1.TypeMeta{Kind: "ConversionReview", APIVersion: "apiextensions.k8s.io/v1"},
+			Request: &apiextensionsv1.ConversionRequest{
+				UID:               types.UID("uid"),
+				DesiredAPIVersion: desired,
+				Objects:           []runtime.RawExtension{{Raw: raw}},
+			},
+		}
+		data, err := json.Marshal(req)
+		g.Expect(err).NotTo(HaveOccurred())
+		w := httptest.NewRecorder()
+		ServeConversion(w, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(data)))
+		g.Expect(w.Code).To(Equal(http.StatusOK))
+		resp := &apiextensionsv1.ConversionReview{}
+		g.Expect(json.Unmarshal(w.Body.Bytes(), resp)).To(Succeed())
+		g.Expect(resp.Response.UID).To(Equal(types.UID("uid")))
+		return resp.Response
+	}
+
+	resp := review("pingcap.com/v1beta1", raw)
+	g.Expect(resp.Result.Status).To(Equal(metav1.StatusSuccess))
+	g.Expect(resp.ConvertedObjects).To(HaveLen(1))
+	beta := &v1beta1.TidbCluster{}
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[0].Raw, beta)).To(Succeed())
+	g.Expect(beta.APIVersion).To(Equal("pingcap.com/v1beta1"))
+	g.Expect(beta.Spec.TiKV.Image).To(Equal("pingcap/tikv"))
+	g.Expect(*beta.Spec.TiKV.Version).To(Equal("v5.3.0"))
+	g.Expect(beta.Annotations).To(HaveKey(v1beta1.ConversionDataAnnotation))
+
+	resp = review("pingcap.com/v1alpha1", resp.ConvertedObjects[0].Raw)
+	g.Expect(resp.Result.Status).To(Equal(metav1.StatusSuccess))
+	hub := &v1alpha1.TidbCluster{}
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[0].Raw, hub)).To(Succeed())
+	g.Expect(hub.APIVersion).To(Equal("pingcap.com/v1alpha1"))
+	g.Expect(hub.Annotations).NotTo(HaveKey(v1beta1.ConversionDataAnnotation))
+	g.Expect(hub.TiKVImage()).To(Equal("pingcap/tikv:v5.3.0"))
+	g.Expect(hub.Spec.TiKV.StoreLabels).To(Equal([]string{"zone"}))
+
+	resp = review("pingcap.com/v1", raw)
+	g.Expect(resp.Result.Status).To(Equal(metav1.StatusFailure))
+	g.Expect(resp.ConvertedObjects).To(BeEmpty())
+}
+
+func TestEnsureCRD(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: tidbClusterCRD},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true, Storage: true},
+				{Name: "v1beta1", Served: false},
+			},
+		},
+	}
+	cli := fake.NewSimpleClientset(crd)
+	opts := Options{Namespace: "tidb-admin", ServiceName: "tidb-admission-webhook", ServicePort: 9443}
+	g.Expect(EnsureCRD(cli, opts, []byte("ca"))).To(Succeed())
+
+	updated, err := cli.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), tidbClusterCRD, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Spec.Versions[1].Served).To(BeTrue())
+	g.Expect(updated.Spec.Conversion.Strategy).To(Equal(apiextensionsv1.WebhookConverter))
+	g.Expect(*updated.Spec.Conversion.Webhook.ClientConfig.Service.Path).To(Equal(Path))
+	g.Expect(updated.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal([]byte("ca")))
+
+	// nothing is updated if the CRD is up to date
+	cli.ClearActions()
+	g.Expect(EnsureCRD(cli, opts, []byte("ca"))).To(Succeed())
+	g.Expect(cli.Actions()).To(HaveLen(1))
+
+	// the CRD is not upgraded
+	updated.Spec.Versions = updated.Spec.Versions[:1]
+	_, err = cli.ApiextensionsV1().CustomResourceDefinitions().Update(context.TODO(), updated, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(EnsureCRD(cli, opts, []byte("ca"))).To(MatchError(ContainSubstring("please upgrade the CRDs")))
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1beta1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Path is the path of the conversion webhook of TidbCluster
+	Path = "/tidbclusterconversions"
+
+	tidbClusterCRD = "tidbclusters.pingcap.com"
+)
+
+// Options are the options of the conversion webhook server
+type Options struct {
+	// SecurePort is the port to serve HTTPS
+	SecurePort int
+	// CertFile and KeyFile are the serving certificate and key
+	CertFile string
+	KeyFile  string
+	// CAFile is the CA of the serving certificate, which is set to the CRD
+	CAFile string
+	// Namespace, ServiceName and ServicePort are the Service of the webhook server
+	Namespace   string
+	ServiceName string
+	ServicePort int32
+}
+
+// Run serves v1beta1 of TidbCluster with the conversion webhook until the stopCh is closed. The
+// requests are sent by kube-apiserver, which verifies the serving certificate by the CA set to the CRD.
+func Run(opts Options, cli apiextensionsclientset.Interface, stopCh <-chan struct{}) error {
+	if opts.CertFile == "" || opts.KeyFile == "" || opts.CAFile == "" {
+		return fmt.Errorf("the serving certificate, key and CA are required by the conversion webhook")
+	}
+	caBundle, err := ioutil.ReadFile(opts.CAFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the CA file %s: %v", opts.CAFile, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, ServeConversion)
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", opts.SecurePort),
+		Handler: mux,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServeTLS(opts.CertFile, opts.KeyFile)
+	}()
+
+	// the webhook is registered after the server is started, so that the conversion
+	// doesn't fail once v1beta1 is served
+	if err := EnsureCRD(cli, opts, caBundle); err != nil {
+		server.Close()
+		return err
+	}
+	klog.Infof("serve v1beta1 of TidbCluster with the conversion webhook on port %d", opts.SecurePort)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-stopCh:
+		return server.Shutdown(context.Background())
+	}
+}
+
+// EnsureCRD serves v1beta1 of TidbCluster and sets the conversion webhook to the CRD
+func EnsureCRD(cli apiextensionsclientset.Interface, opts Options, caBundle []byte) error {
+	path := Path
+	conversion := &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Namespace: opts.Namespace,
+					Name:      opts.ServiceName,
+					Path:      &path,
+					Port:      &opts.ServicePort,
+				},
+				CABundle: caBundle,
+			},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		crd, err := cli.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), tidbClusterCRD, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		found, served := false, false
+		for i := range crd.Spec.Versions {
+			if crd.Spec.Versions[i].Name == v1beta1.SchemeGroupVersion.Version {
+				found, served = true, crd.Spec.Versions[i].Served
+				crd.Spec.Versions[i].Served = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("version %s is not found in CRD %s, please upgrade the CRDs", v1beta1.SchemeGroupVersion.Version, tidbClusterCRD)
+		}
+		if served && apiequality.Semantic.DeepEqual(crd.Spec.Conversion, conversion) {
+			return nil
+		}
+		crd.Spec.Conversion = conversion
+		_, err = cli.ApiextensionsV1().CustomResourceDefinitions().Update(context.TODO(), crd, metav1.UpdateOptions{})
+		if err == nil {
+			klog.Infof("CRD %s is updated to serve %s with the conversion webhook %s/%s", tidbClusterCRD, v1beta1.SchemeGroupVersion.Version, opts.Namespace, opts.ServiceName)
+		}
+		return err
+	})
+}
@@ -105,7 +105,7 @@ func NewOperatorActions(cli versioned.Interface,
 		framework:    f,
 		cli:          cli,
 		kubeCli:      kubeCli,
-		pdControl:    pdapi.NewDefaultPDControl(secretLister),
+		pdControl:    pdapi.NewDefaultPDControl(context.Background(), secretLister),
 		asCli:        asCli,
 		aggrCli:      aggrCli,
 		apiExtCli:    apiExtCli,
@@ -121,7 +121,7 @@ func NewOperatorActions(cli versioned.Interface,
 		framework.ExpectNoError(err, "failed to load config")
 		oa.tidbControl = proxiedtidbclient.NewProxiedTiDBClient(fw, kubeCfg.TLSClientConfig.CAData)
 	} else {
-		oa.tidbControl = controller.NewDefaultTiDBControl(secretLister)
+		oa.tidbControl = controller.NewDefaultTiDBControl(context.Background(), secretLister)
 	}
 	oa.clusterEvents = make(map[string]*clusterEvent)
 
@@ -431,6 +431,7 @@ func CreateDMSources(fw portforward.PortForward, ns, masterSvcName string) error
 		defer cancel()
 
 		body, err := httputil.DoBodyOK(
+			context.Background(),
 			&http.Client{Transport: &http.Transport{}},
 			fmt.Sprintf("http://%s:%d%s", localHost, localPort, apiPath),
 			"PUT",
@@ -583,6 +584,7 @@ func StartDMTask(fw portforward.PortForward, ns, masterSvcName, taskConf, errSub
 		defer cancel()
 
 		body, err := httputil.DoBodyOK(
+			context.Background(),
 			&http.Client{Transport: &http.Transport{}},
 			fmt.Sprintf("http://%s:%d%s", localHost, localPort, apiPath),
 			"POST",
@@ -620,6 +622,7 @@ func GetDMOpenAPISpec(fw portforward.PortForward, ns, masterSvcName, taskConf, e
 		defer cancel()
 
 		_, err = httputil.GetBodyOK(
+			context.Background(),
 			&http.Client{Transport: &http.Transport{}},
 			fmt.Sprintf("http://%s:%d%s", localHost, localPort, apiPath))
 		if err != nil {
@@ -740,6 +743,7 @@ func ShowDMSource(fw portforward.PortForward, ns, masterSvcName string) (string,
 	defer cancel()
 
 	body, err := httputil.DoBodyOK(
+		context.Background(),
 		&http.Client{Transport: &http.Transport{}},
 		fmt.Sprintf("http://%s:%d%s", localHost, localPort, apiPath),
 		"PUT",
@@ -773,6 +777,7 @@ func QueryDMStatus(fw portforward.PortForward, ns, masterSvcName string) (string
 	defer cancel()
 
 	body, err := httputil.DoBodyOK(
+		context.Background(),
 		&http.Client{Transport: &http.Transport{}},
 		fmt.Sprintf("http://%s:%d%s", localHost, localPort, apiPath),
 		"GET",
@@ -793,7 +798,7 @@ func GetDMMasters(fw portforward.PortForward, ns, masterSvcName string) ([]*dmap
 	}
 	defer cancel()
 
-	body, err := httputil.GetBodyOK(&http.Client{Transport: &http.Transport{}},
+	body, err := httputil.GetBodyOK(context.Background(), &http.Client{Transport: &http.Transport{}},
 		fmt.Sprintf("http://%s:%d%s", localHost, localPort, apiPath))
 	if err != nil {
 		return nil, err
@@ -819,7 +824,7 @@ func GetDMWorkers(fw portforward.PortForward, ns, masterSvcName string) ([]*dmap
 	}
 	defer cancel()
 
-	body, err := httputil.GetBodyOK(&http.Client{Transport: &http.Transport{}},
+	body, err := httputil.GetBodyOK(context.Background(), &http.Client{Transport: &http.Transport{}},
 		fmt.Sprintf("http://%s:%d%s", localHost, localPort, apiPath))
 	if err != nil {
 		return nil, err
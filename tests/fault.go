@@ -84,7 +84,7 @@ func NewFaultTriggerAction(cli versioned.Interface, kubeCli kubernetes.Interface
 	return &faultTriggerActions{
 		cli:       cli,
 		kubeCli:   kubeCli,
-		pdControl: pdapi.NewDefaultPDControl(secretLister),
+		pdControl: pdapi.NewDefaultPDControl(context.Background(), secretLister),
 		cfg:       cfg,
 	}
 }